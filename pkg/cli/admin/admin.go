@@ -3,9 +3,6 @@ package admin
 import (
 	"fmt"
 
-	"k8s.io/kubectl/pkg/cmd/certificates"
-	"k8s.io/kubectl/pkg/cmd/taint"
-
 	"github.com/spf13/cobra"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -13,27 +10,47 @@ import (
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	ktemplates "k8s.io/kubectl/pkg/util/templates"
 
+	"github.com/openshift/oc/pkg/cli/admin/audit"
 	"github.com/openshift/oc/pkg/cli/admin/buildchain"
+	"github.com/openshift/oc/pkg/cli/admin/buildstats"
 	"github.com/openshift/oc/pkg/cli/admin/catalog"
+	"github.com/openshift/oc/pkg/cli/admin/certificate"
+	"github.com/openshift/oc/pkg/cli/admin/checkdisruption"
+	"github.com/openshift/oc/pkg/cli/admin/clean"
+	"github.com/openshift/oc/pkg/cli/admin/clusteroperator"
+	"github.com/openshift/oc/pkg/cli/admin/compliance"
 	"github.com/openshift/oc/pkg/cli/admin/createbootstrapprojecttemplate"
 	"github.com/openshift/oc/pkg/cli/admin/createerrortemplate"
 	"github.com/openshift/oc/pkg/cli/admin/createkubeconfig"
 	"github.com/openshift/oc/pkg/cli/admin/createlogintemplate"
 	"github.com/openshift/oc/pkg/cli/admin/createproviderselectiontemplate"
+	"github.com/openshift/oc/pkg/cli/admin/deprecatedapis"
+	"github.com/openshift/oc/pkg/cli/admin/diagnosenamespace"
+	"github.com/openshift/oc/pkg/cli/admin/etcd"
 	"github.com/openshift/oc/pkg/cli/admin/groups"
+	"github.com/openshift/oc/pkg/cli/admin/ingress"
 	"github.com/openshift/oc/pkg/cli/admin/inspect"
+	"github.com/openshift/oc/pkg/cli/admin/mcp"
 	"github.com/openshift/oc/pkg/cli/admin/migrate"
 	migratetemplateinstances "github.com/openshift/oc/pkg/cli/admin/migrate/templateinstances"
+	"github.com/openshift/oc/pkg/cli/admin/mirrorconfig"
 	"github.com/openshift/oc/pkg/cli/admin/mustgather"
 	"github.com/openshift/oc/pkg/cli/admin/network"
 	"github.com/openshift/oc/pkg/cli/admin/node"
+	"github.com/openshift/oc/pkg/cli/admin/ocpcertificates"
 	"github.com/openshift/oc/pkg/cli/admin/policy"
 	"github.com/openshift/oc/pkg/cli/admin/project"
+	"github.com/openshift/oc/pkg/cli/admin/projecttemplate"
 	"github.com/openshift/oc/pkg/cli/admin/prune"
 	"github.com/openshift/oc/pkg/cli/admin/release"
+	"github.com/openshift/oc/pkg/cli/admin/resetuserpassword"
+	"github.com/openshift/oc/pkg/cli/admin/tokens"
 	"github.com/openshift/oc/pkg/cli/admin/top"
 	"github.com/openshift/oc/pkg/cli/admin/upgrade"
+	"github.com/openshift/oc/pkg/cli/admin/usage"
 	"github.com/openshift/oc/pkg/cli/admin/verifyimagesignature"
+	"github.com/openshift/oc/pkg/cli/admin/waitforstablecluster"
+	"github.com/openshift/oc/pkg/cli/admin/whypending"
 	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 )
 
@@ -56,7 +73,14 @@ func NewCommandAdmin(f kcmdutil.Factory, streams genericclioptions.IOStreams) *c
 			Message: "Cluster Management:",
 			Commands: []*cobra.Command{
 				upgrade.New(f, streams),
+				deprecatedapis.NewCmdDeprecatedAPIs(f, streams),
+				waitforstablecluster.NewCmdWaitForStableCluster(f, streams),
+				clusteroperator.NewCmdClusterOperator(f, streams),
+				etcd.NewCmdEtcd(f, streams),
+				checkdisruption.NewCmdCheckDisruption(f, streams),
 				top.NewCommandTop(f, streams),
+				usage.NewCmdUsage(f, streams),
+				buildstats.NewCmdBuildStats(f, streams),
 				mustgather.NewMustGatherCommand(f, streams),
 				inspect.NewCmdInspect(streams),
 			},
@@ -64,11 +88,20 @@ func NewCommandAdmin(f kcmdutil.Factory, streams genericclioptions.IOStreams) *c
 		{
 			Message: "Node Management:",
 			Commands: []*cobra.Command{
-				cmdutil.ReplaceCommandName("kubectl", "oc adm", drain.NewCmdDrain(f, streams)),
+				cmdutil.ReplaceCommandName("kubectl", "oc adm", node.NewCmdDrain(f, streams)),
+				whypending.NewCmdSimulateDrain(f, streams),
 				cmdutil.ReplaceCommandName("kubectl", "oc adm", ktemplates.Normalize(drain.NewCmdCordon(f, streams))),
 				cmdutil.ReplaceCommandName("kubectl", "oc adm", ktemplates.Normalize(drain.NewCmdUncordon(f, streams))),
-				cmdutil.ReplaceCommandName("kubectl", "oc adm", ktemplates.Normalize(taint.NewCmdTaint(f, streams))),
+				cmdutil.ReplaceCommandName("kubectl", "oc adm", node.NewCmdTaint(f, streams)),
 				node.NewCmdLogs(f, streams),
+				whypending.NewCmdWhyPending(f, streams),
+				node.NewCmdCopyToNode(f, streams),
+				node.NewCmdCopyFromNode(f, streams),
+				node.NewCmdRestartKubelet(f, streams),
+				node.NewCmdRestartCrio(f, streams),
+				mcp.NewCommandMCP(f, streams),
+				mcp.NewCmdRebootMachineConfigPool(f, streams),
+				clean.NewCmdClean(f, streams),
 			},
 		},
 		{
@@ -76,15 +109,24 @@ func NewCommandAdmin(f kcmdutil.Factory, streams genericclioptions.IOStreams) *c
 			Commands: []*cobra.Command{
 				project.NewCmdNewProject(f, streams),
 				policy.NewCmdPolicy(f, streams),
+				audit.NewCmdAudit(f, streams),
 				groups.NewCmdGroups(f, streams),
-				withShortDescription(cmdutil.ReplaceCommandName("kubectl", "oc adm", ktemplates.Normalize(certificates.NewCmdCertificate(f, streams))), "Approve or reject certificate requests"),
+				resetuserpassword.NewCmdResetUserPassword(f, streams),
+				tokens.NewCmdTokens(f, streams),
+				compliance.NewCmdCompliance(f, streams),
+				withShortDescription(cmdutil.ReplaceCommandName("kubectl", "oc adm", ktemplates.Normalize(certificate.NewCmdCertificate(f, streams))), "Approve or reject certificate requests"),
+				ocpcertificates.NewCmdOCPCertificates(f, streams),
 				network.NewCmdPodNetwork(f, streams),
+				network.NewCmdNetwork(f, streams),
+				network.NewCmdIPUsage(f, streams),
+				ingress.NewCmdIngress(f, streams),
 			},
 		},
 		{
 			Message: "Maintenance:",
 			Commands: []*cobra.Command{
 				prune.NewCommandPrune(f, streams),
+				diagnosenamespace.NewCmdDiagnoseNamespace(f, streams),
 				migrate.NewCommandMigrate(f, streams,
 					// Migration commands
 					migratetemplateinstances.NewCmdMigrateTemplateInstances(f, streams),
@@ -97,6 +139,7 @@ func NewCommandAdmin(f kcmdutil.Factory, streams genericclioptions.IOStreams) *c
 				createkubeconfig.NewCommandCreateKubeConfig(streams),
 
 				createbootstrapprojecttemplate.NewCommandCreateBootstrapProjectTemplate(f, streams),
+				projecttemplate.NewCmdProjectTemplate(f, streams),
 
 				createlogintemplate.NewCommandCreateLoginTemplate(f, streams),
 				createproviderselectiontemplate.NewCommandCreateProviderSelectionTemplate(f, streams),
@@ -109,6 +152,7 @@ func NewCommandAdmin(f kcmdutil.Factory, streams genericclioptions.IOStreams) *c
 
 	cmds.AddCommand(
 		release.NewCmd(f, streams),
+		mirrorconfig.NewCmdMirrorConfig(f, streams),
 		buildchain.NewCmdBuildChain(f, streams),
 		verifyimagesignature.NewCmdVerifyImageSignature(f, streams),
 	)
@@ -0,0 +1,406 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	auditLong = templates.LongDesc(`
+		Query the API server audit logs
+
+		The audit logs of the API servers are read from the control plane nodes through the node logs endpoint,
+		filtered, and printed in time order. Only the events of the ResponseComplete stage are shown unless
+		--stage is given, so that every request appears once.
+
+		Users are matched with shell wildcards. Resources are given as RESOURCE[.GROUP][/SUBRESOURCE]; without a
+		subresource the requests for any subresource match too.
+
+		Audit logs may contain sensitive information and so are limited to privileged node administrators, like
+		node-logs.
+	`)
+
+	auditExample = templates.Examples(`
+		# Show who deleted a namespace in the last day
+		oc adm audit --verb=delete --resource=namespaces --since=24h
+
+		# Show the requests of all service accounts of a project, including the rotated logs
+		oc adm audit --user='system:serviceaccount:my-project:*' --rotated
+
+		# Print the matching events of the OpenShift API server as JSON lines
+		oc adm audit --service=openshift-apiserver --resource=routes.route.openshift.io -o json
+	`)
+)
+
+// AuditOptions holds the options of oc adm audit.
+type AuditOptions struct {
+	Nodes    []string
+	Role     string
+	Services []string
+	Rotated  bool
+
+	Users              []string
+	Verbs              []string
+	Resources          []string
+	ResourceNamespaces []string
+	Stages             []string
+	Since              time.Duration
+	SinceTime          string
+	UntilTime          string
+	Output             string
+
+	filter *eventFilter
+
+	Client kubernetes.Interface
+	// OpenLog returns the content of file in the node logs of node.
+	OpenLog func(node, file string) (io.ReadCloser, error)
+
+	genericclioptions.IOStreams
+}
+
+func NewAuditOptions(streams genericclioptions.IOStreams) *AuditOptions {
+	return &AuditOptions{
+		Role:      "master",
+		Services:  []string{"kube-apiserver"},
+		Stages:    []string{string(auditv1.StageResponseComplete)},
+		IOStreams: streams,
+	}
+}
+
+// NewCmdAudit creates the command to query the API server audit logs.
+func NewCmdAudit(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewAuditOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "audit [NODE...]",
+		Short:   "Query the API server audit logs",
+		Long:    auditLong,
+		Example: auditExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Role, "role", o.Role, "Read the audit logs of the nodes with this role, unless nodes are given.")
+	cmd.Flags().StringSliceVar(&o.Services, "service", o.Services, "API servers to read the audit logs of: kube-apiserver, openshift-apiserver or oauth-apiserver.")
+	cmd.Flags().BoolVar(&o.Rotated, "rotated", o.Rotated, "If true, also read the rotated audit log files.")
+	cmd.Flags().StringSliceVar(&o.Users, "user", o.Users, "Only show the requests of these users. Shell wildcards are allowed.")
+	cmd.Flags().StringSliceVar(&o.Verbs, "verb", o.Verbs, "Only show requests with these verbs.")
+	cmd.Flags().StringSliceVar(&o.Resources, "resource", o.Resources, "Only show requests for these resources, given as RESOURCE[.GROUP][/SUBRESOURCE].")
+	cmd.Flags().StringSliceVar(&o.ResourceNamespaces, "resource-namespace", o.ResourceNamespaces, "Only show requests for resources in these namespaces.")
+	cmd.Flags().StringSliceVar(&o.Stages, "stage", o.Stages, "Only show the events of these stages. Pass --stage='' to show all stages.")
+	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Only show requests newer than a relative duration like 5s, 2m, or 3h.")
+	cmd.Flags().StringVar(&o.SinceTime, "since-time", o.SinceTime, "Only show requests after a specific date (RFC3339).")
+	cmd.Flags().StringVar(&o.UntilTime, "until-time", o.UntilTime, "Only show requests before a specific date (RFC3339).")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json. The json output is one audit event per line, as in the audit log.")
+
+	return cmd
+}
+
+func (o *AuditOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.Nodes = args
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.OpenLog = func(node, file string) (io.ReadCloser, error) {
+		return o.Client.CoreV1().RESTClient().Get().
+			Resource("nodes").Name(node).SubResource("proxy", "logs").Suffix(file).
+			SetHeader("Accept", "text/plain, */*").
+			Stream(context.TODO())
+	}
+	return nil
+}
+
+func (o *AuditOptions) Validate() error {
+	if len(o.Nodes) == 0 && len(o.Role) == 0 {
+		return fmt.Errorf("at least one node name or a --role must be specified")
+	}
+	if o.Since != 0 && len(o.SinceTime) > 0 {
+		return fmt.Errorf("--since and --since-time are mutually exclusive")
+	}
+	switch o.Output {
+	case "", "json":
+	default:
+		return fmt.Errorf("--output must be json or empty")
+	}
+
+	var err error
+	o.filter, err = newEventFilter(o)
+	return err
+}
+
+// eventFilter matches audit events against the command line filters.
+type eventFilter struct {
+	users      []string
+	verbs      sets.String
+	resources  []resourceFilter
+	namespaces sets.String
+	stages     sets.String
+	since      time.Time
+	until      time.Time
+}
+
+// resourceFilter matches requests for a resource, and for one of its subresources when subresource is set.
+type resourceFilter struct {
+	schema.GroupResource
+	subresource string
+}
+
+func newEventFilter(o *AuditOptions) (*eventFilter, error) {
+	f := &eventFilter{
+		users:      o.Users,
+		verbs:      sets.NewString(o.Verbs...),
+		namespaces: sets.NewString(o.ResourceNamespaces...),
+		stages:     sets.NewString(),
+	}
+	for _, stage := range o.Stages {
+		if len(stage) > 0 {
+			f.stages.Insert(stage)
+		}
+	}
+	for _, user := range o.Users {
+		if _, err := path.Match(user, ""); err != nil {
+			return nil, fmt.Errorf("invalid user pattern %q: %v", user, err)
+		}
+	}
+	for _, resource := range o.Resources {
+		filter := resourceFilter{}
+		if parts := strings.SplitN(resource, "/", 2); len(parts) == 2 {
+			resource, filter.subresource = parts[0], parts[1]
+		}
+		filter.GroupResource = schema.ParseGroupResource(resource)
+		if len(filter.Resource) == 0 {
+			return nil, fmt.Errorf("invalid resource %q, expected RESOURCE[.GROUP][/SUBRESOURCE]", resource)
+		}
+		f.resources = append(f.resources, filter)
+	}
+	if o.Since != 0 {
+		f.since = time.Now().Add(-o.Since)
+	}
+	if len(o.SinceTime) > 0 {
+		since, err := time.Parse(time.RFC3339, o.SinceTime)
+		if err != nil {
+			return nil, fmt.Errorf("--since-time must be an RFC3339 timestamp: %v", err)
+		}
+		f.since = since
+	}
+	if len(o.UntilTime) > 0 {
+		until, err := time.Parse(time.RFC3339, o.UntilTime)
+		if err != nil {
+			return nil, fmt.Errorf("--until-time must be an RFC3339 timestamp: %v", err)
+		}
+		f.until = until
+	}
+	return f, nil
+}
+
+func (f *eventFilter) matches(event *auditv1.Event) bool {
+	if f.stages.Len() > 0 && !f.stages.Has(string(event.Stage)) {
+		return false
+	}
+	received := event.RequestReceivedTimestamp.Time
+	if !f.since.IsZero() && received.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && received.After(f.until) {
+		return false
+	}
+	if f.verbs.Len() > 0 && !f.verbs.Has(event.Verb) {
+		return false
+	}
+	if len(f.users) > 0 {
+		matched := false
+		for _, user := range f.users {
+			if ok, _ := path.Match(user, event.User.Username); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.namespaces.Len() > 0 && (event.ObjectRef == nil || !f.namespaces.Has(event.ObjectRef.Namespace)) {
+		return false
+	}
+	if len(f.resources) > 0 {
+		if event.ObjectRef == nil {
+			return false
+		}
+		matched := false
+		for _, resource := range f.resources {
+			if resource.Resource == event.ObjectRef.Resource && resource.Group == event.ObjectRef.APIGroup &&
+				(len(resource.subresource) == 0 || resource.subresource == event.ObjectRef.Subresource) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// readEvents returns the events of the audit log in that match the filter.
+func readEvents(in io.Reader, filter *eventFilter) ([]auditv1.Event, error) {
+	var events []auditv1.Event
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		event := auditv1.Event{}
+		if err := json.Unmarshal(line, &event); err != nil {
+			return events, fmt.Errorf("unable to parse audit event: %v", err)
+		}
+		if filter.matches(&event) {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+var (
+	reLink     = regexp.MustCompile(`href="([^"]+)"`)
+	reAuditLog = regexp.MustCompile(`^audit.*\.log$`)
+)
+
+// logFiles returns the audit log files of service on node.
+func (o *AuditOptions) logFiles(node, service string) ([]string, error) {
+	if !o.Rotated {
+		return []string{service + "/audit.log"}, nil
+	}
+	in, err := o.OpenLog(node, service+"/")
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	listing, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, match := range reLink.FindAllSubmatch(listing, -1) {
+		if name := string(match[1]); reAuditLog.MatchString(name) {
+			files = append(files, service+"/"+name)
+		}
+	}
+	return files, nil
+}
+
+func (o *AuditOptions) nodes() ([]string, error) {
+	if len(o.Nodes) > 0 {
+		return o.Nodes, nil
+	}
+	list, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: "node-role.kubernetes.io/" + o.Role})
+	if err != nil {
+		return nil, err
+	}
+	var nodes []string
+	for _, node := range list.Items {
+		nodes = append(nodes, node.Name)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no nodes with the role %s found", o.Role)
+	}
+	return nodes, nil
+}
+
+func (o *AuditOptions) Run() error {
+	nodes, err := o.nodes()
+	if err != nil {
+		return err
+	}
+
+	var events []auditv1.Event
+	errs := []error{}
+	for _, node := range nodes {
+		for _, service := range o.Services {
+			files, err := o.logFiles(node, service)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("unable to list the audit logs of %s on node %s: %v", service, node, err))
+				continue
+			}
+			for _, file := range files {
+				in, err := o.OpenLog(node, file)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("unable to read %s on node %s: %v", file, node, err))
+					continue
+				}
+				matched, err := readEvents(in, o.filter)
+				in.Close()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("unable to read %s on node %s: %v", file, node, err))
+				}
+				events = append(events, matched...)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].RequestReceivedTimestamp.Before(&events[j].RequestReceivedTimestamp)
+	})
+
+	if o.Output == "json" {
+		for i := range events {
+			data, err := json.Marshal(&events[i])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(o.Out, "%s\n", data)
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintf(w, "TIME\tUSER\tVERB\tRESOURCE\tNAMESPACE\tNAME\tCODE\n")
+	for _, event := range events {
+		resource, namespace, name := "", "", ""
+		if ref := event.ObjectRef; ref != nil {
+			resource = schema.GroupResource{Group: ref.APIGroup, Resource: ref.Resource}.String()
+			if len(ref.Subresource) > 0 {
+				resource += "/" + ref.Subresource
+			}
+			namespace, name = ref.Namespace, ref.Name
+		}
+		if len(resource) == 0 {
+			resource = event.RequestURI
+		}
+		code := ""
+		if event.ResponseStatus != nil {
+			code = strconv.Itoa(int(event.ResponseStatus.Code))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", event.RequestReceivedTimestamp.UTC().Format(time.RFC3339), event.User.Username, event.Verb, resource, namespace, name, code)
+	}
+	w.Flush()
+	return utilerrors.NewAggregate(errs)
+}
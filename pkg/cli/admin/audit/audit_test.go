@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	masterALog = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"1","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/dev","verb":"delete","user":{"username":"alice"},"objectRef":{"resource":"namespaces","name":"dev","apiVersion":"v1"},"responseStatus":{"metadata":{},"code":200},"requestReceivedTimestamp":"2022-06-01T10:00:02.000000Z","stageTimestamp":"2022-06-01T10:00:02.100000Z"}
+{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"2","stage":"RequestReceived","requestURI":"/api/v1/namespaces/dev/pods/web/exec","verb":"create","user":{"username":"system:serviceaccount:dev:builder"},"objectRef":{"resource":"pods","namespace":"dev","name":"web","apiVersion":"v1","subresource":"exec"},"requestReceivedTimestamp":"2022-06-01T10:00:03.000000Z","stageTimestamp":"2022-06-01T10:00:03.000000Z"}
+{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"2","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/dev/pods/web/exec","verb":"create","user":{"username":"system:serviceaccount:dev:builder"},"objectRef":{"resource":"pods","namespace":"dev","name":"web","apiVersion":"v1","subresource":"exec"},"responseStatus":{"metadata":{},"code":403},"requestReceivedTimestamp":"2022-06-01T10:00:03.000000Z","stageTimestamp":"2022-06-01T10:00:03.200000Z"}
+`
+	masterBLog = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"3","stage":"ResponseComplete","requestURI":"/apis/apps/v1/namespaces/dev/deployments/web","verb":"update","user":{"username":"bob"},"objectRef":{"resource":"deployments","namespace":"dev","name":"web","apiGroup":"apps","apiVersion":"v1"},"responseStatus":{"metadata":{},"code":200},"requestReceivedTimestamp":"2022-06-01T10:00:01.000000Z","stageTimestamp":"2022-06-01T10:00:01.100000Z"}
+`
+	rotatedLog = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"0","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/dev/secrets","verb":"list","user":{"username":"alice"},"objectRef":{"resource":"secrets","namespace":"dev","apiVersion":"v1"},"responseStatus":{"metadata":{},"code":200},"requestReceivedTimestamp":"2022-05-31T10:00:00.000000Z","stageTimestamp":"2022-05-31T10:00:00.100000Z"}
+`
+)
+
+func TestAudit(t *testing.T) {
+	files := map[string]string{
+		"master-a/kube-apiserver/audit.log":                         masterALog,
+		"master-a/kube-apiserver/audit-2022-05-31T10-00-00.000.log": rotatedLog,
+		"master-a/kube-apiserver/":                                  `<pre><a href="audit-2022-05-31T10-00-00.000.log">audit-2022-05-31T10-00-00.000.log</a><a href="audit.log">audit.log</a><a href="termination.log">termination.log</a></pre>`,
+		"master-b/kube-apiserver/audit.log":                         masterBLog,
+		"master-b/kube-apiserver/":                                  `<pre><a href="audit.log">audit.log</a></pre>`,
+	}
+
+	tests := []struct {
+		name     string
+		options  AuditOptions
+		expected string
+	}{
+		{
+			name:    "all nodes in time order",
+			options: AuditOptions{Stages: []string{"ResponseComplete"}},
+			expected: `TIME                   USER                                VERB     RESOURCE           NAMESPACE   NAME   CODE
+2022-06-01T10:00:01Z   bob                                 update   deployments.apps   dev         web    200
+2022-06-01T10:00:02Z   alice                               delete   namespaces                     dev    200
+2022-06-01T10:00:03Z   system:serviceaccount:dev:builder   create   pods/exec          dev         web    403
+`,
+		},
+		{
+			name:    "all stages",
+			options: AuditOptions{Users: []string{"system:serviceaccount:dev:*"}},
+			expected: `TIME                   USER                                VERB     RESOURCE    NAMESPACE   NAME   CODE
+2022-06-01T10:00:03Z   system:serviceaccount:dev:builder   create   pods/exec   dev         web    
+2022-06-01T10:00:03Z   system:serviceaccount:dev:builder   create   pods/exec   dev         web    403
+`,
+		},
+		{
+			name:    "resource and namespace",
+			options: AuditOptions{Resources: []string{"pods", "deployments.apps/scale"}, ResourceNamespaces: []string{"dev"}, Stages: []string{"ResponseComplete"}},
+			expected: `TIME                   USER                                VERB     RESOURCE    NAMESPACE   NAME   CODE
+2022-06-01T10:00:03Z   system:serviceaccount:dev:builder   create   pods/exec   dev         web    403
+`,
+		},
+		{
+			name:    "rotated files and time range",
+			options: AuditOptions{Rotated: true, Users: []string{"alice"}, SinceTime: "2022-05-31T00:00:00Z", UntilTime: "2022-06-01T00:00:00Z"},
+			expected: `TIME                   USER    VERB   RESOURCE   NAMESPACE   NAME   CODE
+2022-05-31T10:00:00Z   alice   list   secrets    dev                200
+`,
+		},
+		{
+			name:    "json",
+			options: AuditOptions{Verbs: []string{"update"}, Output: "json"},
+			expected: masterBLog,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := test.options
+			o.Role = "master"
+			o.Services = []string{"kube-apiserver"}
+			o.Client = fake.NewSimpleClientset(
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master-a", Labels: map[string]string{"node-role.kubernetes.io/master": ""}}},
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master-b", Labels: map[string]string{"node-role.kubernetes.io/master": ""}}},
+				&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}},
+			)
+			o.OpenLog = func(node, file string) (io.ReadCloser, error) {
+				content, ok := files[node+"/"+file]
+				if !ok {
+					return nil, fmt.Errorf("%s not found", file)
+				}
+				return io.NopCloser(strings.NewReader(content)), nil
+			}
+			o.IOStreams = genericclioptions.IOStreams{Out: out, ErrOut: out}
+			if err := o.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, out.String())
+			}
+		})
+	}
+}
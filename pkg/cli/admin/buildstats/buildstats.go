@@ -0,0 +1,387 @@
+package buildstats
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	buildStatsLong = templates.LongDesc(`
+		Report the performance of the builds of the cluster
+
+		The builds of all projects are aggregated by project and build strategy into the number of
+		builds by phase, the failure rate of the finished builds, their average and longest
+		duration, and the average time they waited to start, which grows when the nodes lack
+		capacity for builds.
+
+		The nodes that ran the builds are listed by number of builds, to find the hot spots where
+		builds concentrate. The node of a build is the node of its pod, so the builds whose pod was
+		deleted are not counted in the nodes.
+
+		The builds are selected by creation time with --since, or --since-time and --until-time.
+		Only the builds that were not pruned yet are reported.
+
+		The report can be exported as JSON or YAML with --output, or as CSV, which holds the
+		statistics by project and strategy only, to size the builder capacity.
+	`)
+
+	buildStatsExample = templates.Examples(`
+		# Report the performance of the builds of the last week
+		oc adm build-stats --since=168h
+
+		# Export the statistics of the builds of September as CSV
+		oc adm build-stats --since-time=2023-09-01T00:00:00Z --until-time=2023-10-01T00:00:00Z -o csv
+	`)
+)
+
+// BuildStatsOptions holds the options to report the performance of the builds.
+type BuildStatsOptions struct {
+	Since     time.Duration
+	SinceTime string
+	UntilTime string
+	Output    string
+
+	since time.Time
+	until time.Time
+
+	Client      kubernetes.Interface
+	BuildClient buildv1client.BuildV1Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewBuildStatsOptions(streams genericclioptions.IOStreams) *BuildStatsOptions {
+	return &BuildStatsOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdBuildStats creates a command that reports the performance of the builds of the cluster.
+func NewCmdBuildStats(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewBuildStatsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "build-stats",
+		Short:   "Report the performance of the builds of the cluster",
+		Long:    buildStatsLong,
+		Example: buildStatsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Only report the builds created within a relative duration like 24h.")
+	cmd.Flags().StringVar(&o.SinceTime, "since-time", o.SinceTime, "Only report the builds created after a specific date (RFC3339).")
+	cmd.Flags().StringVar(&o.UntilTime, "until-time", o.UntilTime, "Only report the builds created before a specific date (RFC3339).")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: csv|json|yaml.")
+
+	return cmd
+}
+
+func (o *BuildStatsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	o.BuildClient, err = buildv1client.NewForConfig(config)
+	return err
+}
+
+func (o *BuildStatsOptions) Validate() error {
+	if o.Since < 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	if o.Since != 0 && len(o.SinceTime) > 0 {
+		return fmt.Errorf("--since and --since-time are mutually exclusive")
+	}
+	if o.Since != 0 {
+		o.since = time.Now().Add(-o.Since)
+	}
+	if len(o.SinceTime) > 0 {
+		since, err := time.Parse(time.RFC3339, o.SinceTime)
+		if err != nil {
+			return fmt.Errorf("--since-time must be an RFC3339 timestamp: %v", err)
+		}
+		o.since = since
+	}
+	if len(o.UntilTime) > 0 {
+		until, err := time.Parse(time.RFC3339, o.UntilTime)
+		if err != nil {
+			return fmt.Errorf("--until-time must be an RFC3339 timestamp: %v", err)
+		}
+		o.until = until
+	}
+	switch o.Output {
+	case "", "csv", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("--output must be one of: csv, json, yaml")
+}
+
+func (o *BuildStatsOptions) Run() error {
+	builds, err := o.BuildClient.Builds(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := o.Client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{LabelSelector: buildv1.BuildLabel})
+	if err != nil {
+		return err
+	}
+	report := buildStats(time.Now(), builds.Items, pods.Items, o.since, o.until)
+
+	switch o.Output {
+	case "csv":
+		return printCSV(o.Out, report.Projects)
+	case "json", "yaml":
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, report)
+	}
+
+	if len(report.Projects) == 0 {
+		fmt.Fprintln(o.Out, "No builds found")
+		return nil
+	}
+	printBuildStats(o.Out, report)
+	return nil
+}
+
+// buildStatsReport is the performance of the builds by project and strategy, and by node.
+type buildStatsReport struct {
+	Projects []projectBuildStats `json:"projects"`
+	Nodes    []nodeBuildStats    `json:"nodes"`
+	// WithoutPod is the number of builds whose pod was deleted, which are not counted in the nodes.
+	WithoutPod int `json:"withoutPod"`
+}
+
+// projectBuildStats is the performance of the builds of a strategy in a project. The durations are in seconds.
+type projectBuildStats struct {
+	Namespace string `json:"namespace"`
+	Strategy  string `json:"strategy"`
+	Builds    int    `json:"builds"`
+	Complete  int    `json:"complete"`
+	// Failed counts the builds of phase Failed or Error.
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+	// Active counts the builds of phase New, Pending or Running.
+	Active int `json:"active"`
+	// FailureRate is the percentage of the complete and failed builds that failed.
+	FailureRate            int   `json:"failureRate"`
+	AverageDurationSeconds int64 `json:"averageDurationSeconds"`
+	MaxDurationSeconds     int64 `json:"maxDurationSeconds"`
+	AverageWaitSeconds     int64 `json:"averageWaitSeconds"`
+
+	finishedDuration time.Duration
+	wait             time.Duration
+	started          int
+}
+
+// nodeBuildStats is the builds run on a node.
+type nodeBuildStats struct {
+	Node   string `json:"node"`
+	Builds int    `json:"builds"`
+	Active int    `json:"active"`
+	Failed int    `json:"failed"`
+	// Share is the percentage of the builds run on nodes that ran on this node.
+	Share int `json:"share"`
+	// BuildSeconds is the time spent running builds on the node.
+	BuildSeconds int64 `json:"buildSeconds"`
+}
+
+// buildStats aggregates the builds created between since and until, when they are set.
+func buildStats(now time.Time, builds []buildv1.Build, pods []corev1.Pod, since, until time.Time) buildStatsReport {
+	podNodes := map[string]string{}
+	for _, pod := range pods {
+		if len(pod.Spec.NodeName) > 0 {
+			podNodes[pod.Namespace+"/"+pod.Name] = pod.Spec.NodeName
+		}
+	}
+
+	report := buildStatsReport{Projects: []projectBuildStats{}, Nodes: []nodeBuildStats{}}
+	projects := map[string]*projectBuildStats{}
+	nodes := map[string]*nodeBuildStats{}
+	onNodes := 0
+	for i := range builds {
+		build := &builds[i]
+		created := build.CreationTimestamp.Time
+		if (!since.IsZero() && created.Before(since)) || (!until.IsZero() && !created.Before(until)) {
+			continue
+		}
+
+		strategy := string(build.Spec.Strategy.Type)
+		key := build.Namespace + "/" + strategy
+		project, ok := projects[key]
+		if !ok {
+			project = &projectBuildStats{Namespace: build.Namespace, Strategy: strategy}
+			projects[key] = project
+		}
+		project.Builds++
+		active, failed := false, false
+		switch build.Status.Phase {
+		case buildv1.BuildPhaseComplete:
+			project.Complete++
+		case buildv1.BuildPhaseFailed, buildv1.BuildPhaseError:
+			project.Failed++
+			failed = true
+		case buildv1.BuildPhaseCancelled:
+			project.Cancelled++
+		default:
+			project.Active++
+			active = true
+		}
+		run := buildDuration(now, build)
+		if build.Status.Phase == buildv1.BuildPhaseComplete || failed {
+			project.finishedDuration += run
+			if seconds := int64(run.Seconds()); seconds > project.MaxDurationSeconds {
+				project.MaxDurationSeconds = seconds
+			}
+		}
+		if build.Status.StartTimestamp != nil {
+			project.wait += build.Status.StartTimestamp.Sub(created)
+			project.started++
+		}
+
+		nodeName, ok := podNodes[build.Namespace+"/"+build.Annotations[buildv1.BuildPodNameAnnotation]]
+		if !ok {
+			if !active {
+				report.WithoutPod++
+			}
+			continue
+		}
+		node, ok := nodes[nodeName]
+		if !ok {
+			node = &nodeBuildStats{Node: nodeName}
+			nodes[nodeName] = node
+		}
+		onNodes++
+		node.Builds++
+		if active {
+			node.Active++
+		}
+		if failed {
+			node.Failed++
+		}
+		node.BuildSeconds += int64(run.Seconds())
+	}
+
+	for _, project := range projects {
+		if finished := project.Complete + project.Failed; finished > 0 {
+			project.FailureRate = project.Failed * 100 / finished
+			project.AverageDurationSeconds = int64(project.finishedDuration.Seconds()) / int64(finished)
+		}
+		if project.started > 0 {
+			project.AverageWaitSeconds = int64(project.wait.Seconds()) / int64(project.started)
+		}
+		report.Projects = append(report.Projects, *project)
+	}
+	sort.Slice(report.Projects, func(i, j int) bool {
+		a, b := report.Projects[i], report.Projects[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Strategy < b.Strategy
+	})
+
+	for _, node := range nodes {
+		node.Share = node.Builds * 100 / onNodes
+		report.Nodes = append(report.Nodes, *node)
+	}
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		a, b := report.Nodes[i], report.Nodes[j]
+		if a.Builds != b.Builds {
+			return a.Builds > b.Builds
+		}
+		return a.Node < b.Node
+	})
+	return report
+}
+
+// buildDuration returns how long the build ran, until now for a running build.
+func buildDuration(now time.Time, build *buildv1.Build) time.Duration {
+	if build.Status.Duration > 0 {
+		return build.Status.Duration
+	}
+	if build.Status.StartTimestamp == nil {
+		return 0
+	}
+	if build.Status.CompletionTimestamp != nil {
+		return build.Status.CompletionTimestamp.Sub(build.Status.StartTimestamp.Time)
+	}
+	return now.Sub(build.Status.StartTimestamp.Time)
+}
+
+// seconds formats a number of seconds as a human readable duration.
+func seconds(s int64) string {
+	if s == 0 {
+		return "-"
+	}
+	return duration.HumanDuration(time.Duration(s) * time.Second)
+}
+
+// printBuildStats prints a table of the statistics by project and strategy, followed by a table of the nodes.
+func printBuildStats(out io.Writer, report buildStatsReport) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tSTRATEGY\tBUILDS\tCOMPLETE\tFAILED\tCANCELLED\tACTIVE\tFAILURE RATE\tAVG DURATION\tMAX DURATION\tAVG WAIT")
+	for _, p := range report.Projects {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d%%\t%s\t%s\t%s\n", p.Namespace, p.Strategy, p.Builds, p.Complete, p.Failed, p.Cancelled, p.Active, p.FailureRate,
+			seconds(p.AverageDurationSeconds), seconds(p.MaxDurationSeconds), seconds(p.AverageWaitSeconds))
+	}
+	w.Flush()
+
+	if len(report.Nodes) == 0 {
+		fmt.Fprintln(out, "\nNo build pods found on the nodes")
+		return
+	}
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tBUILDS\tSHARE\tACTIVE\tFAILED\tBUILD TIME")
+	for _, n := range report.Nodes {
+		fmt.Fprintf(w, "%s\t%d\t%d%%\t%d\t%d\t%s\n", n.Node, n.Builds, n.Share, n.Active, n.Failed, seconds(n.BuildSeconds))
+	}
+	w.Flush()
+	if report.WithoutPod > 0 {
+		fmt.Fprintf(out, "\n%d build(s) whose pod was deleted are not counted in the nodes\n", report.WithoutPod)
+	}
+}
+
+// printCSV prints the statistics by project and strategy as CSV with a header row.
+func printCSV(out io.Writer, projects []projectBuildStats) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"namespace", "strategy", "builds", "complete", "failed", "cancelled", "active", "failure_rate", "average_duration_seconds", "max_duration_seconds", "average_wait_seconds"}); err != nil {
+		return err
+	}
+	for _, p := range projects {
+		if err := w.Write([]string{p.Namespace, p.Strategy, strconv.Itoa(p.Builds), strconv.Itoa(p.Complete), strconv.Itoa(p.Failed), strconv.Itoa(p.Cancelled), strconv.Itoa(p.Active), strconv.Itoa(p.FailureRate),
+			strconv.FormatInt(p.AverageDurationSeconds, 10), strconv.FormatInt(p.MaxDurationSeconds, 10), strconv.FormatInt(p.AverageWaitSeconds, 10)}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
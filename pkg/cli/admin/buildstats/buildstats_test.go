@@ -0,0 +1,124 @@
+package buildstats
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+)
+
+func testBuild(namespace, name string, strategy buildv1.BuildStrategyType, phase buildv1.BuildPhase, age, wait, run time.Duration) *buildv1.Build {
+	created := time.Now().Add(-age)
+	build := &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations:       map[string]string{buildv1.BuildPodNameAnnotation: name + "-build"},
+		},
+		Spec:   buildv1.BuildSpec{CommonSpec: buildv1.CommonSpec{Strategy: buildv1.BuildStrategy{Type: strategy}}},
+		Status: buildv1.BuildStatus{Phase: phase},
+	}
+	if wait > 0 {
+		started := metav1.NewTime(created.Add(wait))
+		build.Status.StartTimestamp = &started
+	}
+	if phase != buildv1.BuildPhaseRunning && build.Status.StartTimestamp != nil {
+		build.Status.Duration = run
+	}
+	return build
+}
+
+func testBuildPod(namespace, build, node string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: build + "-build", Labels: map[string]string{buildv1.BuildLabel: build}},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+}
+
+func testOptions() (*BuildStatsOptions, *bytes.Buffer) {
+	day := 24 * time.Hour
+	buildClient := buildfake.NewSimpleClientset(
+		testBuild("web", "web-1", buildv1.SourceBuildStrategyType, buildv1.BuildPhaseComplete, 3*day, time.Minute, 4*time.Minute),
+		testBuild("web", "web-2", buildv1.SourceBuildStrategyType, buildv1.BuildPhaseFailed, 2*day, 3*time.Minute, 2*time.Minute),
+		testBuild("web", "web-3", buildv1.SourceBuildStrategyType, buildv1.BuildPhaseRunning, time.Hour, 2*time.Minute, 0),
+		testBuild("web", "web-4", buildv1.SourceBuildStrategyType, buildv1.BuildPhaseComplete, day, time.Minute, 6*time.Minute),
+		testBuild("api", "api-1", buildv1.DockerBuildStrategyType, buildv1.BuildPhaseComplete, 2*day, 30*time.Second, 10*time.Minute),
+		testBuild("api", "api-2", buildv1.DockerBuildStrategyType, buildv1.BuildPhaseCancelled, 2*day, 0, 0),
+		testBuild("old", "old-1", buildv1.DockerBuildStrategyType, buildv1.BuildPhaseComplete, 30*day, time.Minute, time.Minute),
+	)
+	client := fake.NewSimpleClientset(
+		testBuildPod("web", "web-2", "worker-1"),
+		testBuildPod("web", "web-3", "worker-1"),
+		testBuildPod("web", "web-4", "worker-2"),
+		testBuildPod("api", "api-1", "worker-1"),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "web-1-build"}, Spec: corev1.PodSpec{NodeName: "worker-3"}},
+	)
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &BuildStatsOptions{Since: 7 * day, Client: client, BuildClient: buildClient.BuildV1(), IOStreams: streams}
+	return o, out
+}
+
+func TestRun(t *testing.T) {
+	o, out := testOptions()
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "NAMESPACE   STRATEGY   BUILDS   COMPLETE   FAILED   CANCELLED   ACTIVE   FAILURE RATE   AVG DURATION   MAX DURATION   AVG WAIT\n" +
+		"api         Docker     2        1          0        1           0        0%             10m            10m            30s\n" +
+		"web         Source     4        2          1        0           1        33%            4m             6m             105s\n" +
+		"\n" +
+		"NODE       BUILDS   SHARE   ACTIVE   FAILED   BUILD TIME\n" +
+		"worker-1   3        75%     1        1        70m\n" +
+		"worker-2   1        25%     0        0        6m\n" +
+		"\n" +
+		"2 build(s) whose pod was deleted are not counted in the nodes\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRun_csv(t *testing.T) {
+	o, out := testOptions()
+	o.Output = "csv"
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "namespace,strategy,builds,complete,failed,cancelled,active,failure_rate,average_duration_seconds,max_duration_seconds,average_wait_seconds\n" +
+		"api,Docker,2,1,0,1,0,0,600,600,30\n" +
+		"web,Source,4,2,1,0,1,33,240,360,105\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options BuildStatsOptions
+	}{
+		{name: "since and since time", options: BuildStatsOptions{Since: time.Hour, SinceTime: "2023-09-01T00:00:00Z"}},
+		{name: "invalid until time", options: BuildStatsOptions{UntilTime: "yesterday"}},
+		{name: "invalid output", options: BuildStatsOptions{Output: "wide"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.Validate(); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// packageFilter selects the bundles of a catalog to mirror by package and channel. A nil filter selects every bundle.
+type packageFilter struct {
+	packages sets.String
+	channels []channelSelector
+}
+
+// channelSelector selects a channel of one package, or of every package if package is empty.
+type channelSelector struct {
+	pkg     string
+	channel string
+}
+
+// newPackageFilter returns a filter for the packages and channels, given as CHANNEL or PACKAGE:CHANNEL. It
+// returns nil if neither is given.
+func newPackageFilter(packages, channels []string) (*packageFilter, error) {
+	if len(packages) == 0 && len(channels) == 0 {
+		return nil, nil
+	}
+	f := &packageFilter{packages: sets.NewString()}
+	for _, pkg := range packages {
+		if len(pkg) == 0 {
+			return nil, fmt.Errorf("--packages may not contain empty package names")
+		}
+		f.packages.Insert(pkg)
+	}
+	for _, channel := range channels {
+		selector := channelSelector{channel: channel}
+		if i := strings.Index(channel, ":"); i >= 0 {
+			selector.pkg, selector.channel = channel[:i], channel[i+1:]
+			if len(selector.pkg) == 0 {
+				return nil, fmt.Errorf("--channels must be CHANNEL or PACKAGE:CHANNEL: %q", channel)
+			}
+			if f.packages.Len() > 0 && !f.packages.Has(selector.pkg) {
+				return nil, fmt.Errorf("--channels selects %q of package %q, which is not in --packages", selector.channel, selector.pkg)
+			}
+		}
+		if len(selector.channel) == 0 {
+			return nil, fmt.Errorf("--channels must be CHANNEL or PACKAGE:CHANNEL: %q", channel)
+		}
+		f.channels = append(f.channels, selector)
+	}
+	return f, nil
+}
+
+// includes returns true if a bundle of pkg in the channels should be mirrored. Channel selectors of other packages
+// do not apply to pkg, and all the channels of a package without any applicable selector are mirrored.
+func (f *packageFilter) includes(pkg string, channels sets.String) bool {
+	if f == nil {
+		return true
+	}
+	if f.packages.Len() > 0 && !f.packages.Has(pkg) {
+		return false
+	}
+	applicable := false
+	for _, selector := range f.channels {
+		if len(selector.pkg) > 0 && selector.pkg != pkg {
+			continue
+		}
+		applicable = true
+		if channels.Has(selector.channel) {
+			return true
+		}
+	}
+	return !applicable
+}
+
+// checkPackages returns an error if the filter selects packages that are not in the catalog.
+func (f *packageFilter) checkPackages(found sets.String) error {
+	if f == nil {
+		return nil
+	}
+	if missing := f.packages.Difference(found); missing.Len() > 0 {
+		return fmt.Errorf("the catalog does not contain the packages: %s", strings.Join(missing.List(), ", "))
+	}
+	return nil
+}
+
+// bundleChannels are the package and channels of a bundle.
+type bundleChannels struct {
+	pkg      string
+	channels sets.String
+}
+
+// includedBundles returns the names of the bundles that the filter selects.
+func (f *packageFilter) includedBundles(bundles map[string]*bundleChannels) (sets.String, error) {
+	included := sets.NewString()
+	found := sets.NewString()
+	for name, bundle := range bundles {
+		found.Insert(bundle.pkg)
+		if f.includes(bundle.pkg, bundle.channels) {
+			included.Insert(name)
+		}
+	}
+	if err := f.checkPackages(found); err != nil {
+		return nil, err
+	}
+	return included, nil
+}
@@ -3,6 +3,7 @@ package catalog
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
@@ -18,6 +19,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/klog/v2"
@@ -66,6 +68,10 @@ var (
 		A mapping.txt file is also created that is compatible with "oc image mirror". This may be used to further
 		customize the mirroring configuration, but should not be needed in normal circumstances.
 
+		--packages and --channels limit the mirrored images, and the generated image content source policy, to
+		the operators a cluster needs. The catalog image itself is mirrored unchanged, so it still lists the
+		packages that were not mirrored.
+
 	` + prefixLines(sqliteDeprecationNotice, "\t\t\t"))
 	mirrorExample = templates.Examples(`
 		# Mirror an operator-registry image and its contents to a registry
@@ -78,6 +84,9 @@ var (
 		oc adm catalog mirror quay.io/my/image:latest file:///local/index
 		oc adm catalog mirror file:///local/index/my/image:latest my-airgapped-registry.com
 
+		# Mirror only the stable channel of two operators
+		oc adm catalog mirror quay.io/my/image:latest myregistry.com --packages=etcd,prometheus --channels=stable
+
 		# Configure a cluster to use a mirrored registry
 		oc apply -f manifests/imageContentSourcePolicy.yaml
 
@@ -118,6 +127,9 @@ type MirrorCatalogOptions struct {
 
 	IcspScope string
 
+	Packages []string
+	Channels []string
+
 	SecurityOptions imagemanifest.SecurityOptions
 	FilterOptions   imagemanifest.FilterOptions
 	ParallelOptions imagemanifest.ParallelOptions
@@ -175,6 +187,8 @@ func NewMirrorCatalog(f kcmdutil.Factory, streams genericclioptions.IOStreams) *
 	flags.StringVar(&o.IcspScope, "icsp-scope", o.IcspScope, "Scope of registry mirrors in imagecontentsourcepolicy file. Allowed values: repository, registry. Defaults to: repository")
 	flags.IntVar(&o.MaxICSPSize, "max-icsp-size", maxICSPSize, "The maximum number of bytes for the generated ICSP yaml(s). Defaults to 250000")
 	flags.BoolVar(&o.ContinueOnError, "continue-on-error", true, "If an error occurs while mirroring, keep going and attempt to mirror as much as possible.")
+	flags.StringSliceVar(&o.Packages, "packages", o.Packages, "Only mirror the images of these packages of the catalog. Defaults to all packages.")
+	flags.StringSliceVar(&o.Channels, "channels", o.Channels, "Only mirror the images of the bundles in these channels, given as CHANNEL for every package or PACKAGE:CHANNEL. Packages without a selected channel are mirrored entirely.")
 	return cmd
 }
 
@@ -338,12 +352,16 @@ func (o *MirrorCatalogOptions) Complete(cmd *cobra.Command, args []string) error
 		}
 	}
 	o.ImageMirrorer = mirrorer
+	filter, err := newPackageFilter(o.Packages, o.Channels)
+	if err != nil {
+		return err
+	}
 	if _, ok := image.Config.Config.Labels[ConfigsLocationLabelKey]; ok {
 		o.IndexExtractor = o.newDeclcfgExtractor(cmd)
-		o.RelatedImagesParser = &declcfgRelatedImagesParser{}
+		o.RelatedImagesParser = &declcfgRelatedImagesParser{filter: filter}
 	} else {
 		o.IndexExtractor = o.newSqliteExtractor(cmd)
-		o.RelatedImagesParser = &sqliteRelatedImagesParser{}
+		o.RelatedImagesParser = &sqliteRelatedImagesParser{filter: filter}
 	}
 
 	return nil
@@ -432,34 +450,61 @@ func (o *MirrorCatalogOptions) newDeclcfgExtractor(cmd *cobra.Command) IndexExtr
 	})
 }
 
-type sqliteRelatedImagesParser struct{}
+type sqliteRelatedImagesParser struct {
+	filter *packageFilter
+}
 
-func (_ sqliteRelatedImagesParser) Parse(file string) (map[string]struct{}, error) {
+func (p sqliteRelatedImagesParser) Parse(file string) (map[string]struct{}, error) {
 	db, err := sqlittle.Open(file)
 	if err != nil {
 		return nil, err
 	}
 
+	var errs = make([]error, 0)
+
+	// select the bundles in the filtered packages and channels
+	var included sets.String
+	if p.filter != nil {
+		bundles := make(map[string]*bundleChannels)
+		reader := func(r sqlittle.Row) {
+			var channel, pkg, bundle string
+			if err := r.Scan(&channel, &pkg, &bundle); err != nil {
+				errs = append(errs, err)
+				return
+			}
+			if bundles[bundle] == nil {
+				bundles[bundle] = &bundleChannels{pkg: pkg, channels: sets.NewString()}
+			}
+			bundles[bundle].channels.Insert(channel)
+		}
+		if err := db.Select("channel_entry", reader, "channel_name", "package_name", "operatorbundle_name"); err != nil {
+			errs = append(errs, err)
+			return nil, errors.NewAggregate(errs)
+		}
+		if included, err = p.filter.includedBundles(bundles); err != nil {
+			return nil, err
+		}
+	}
+
 	// get all images
 	var images = make(map[string]struct{}, 0)
-	var errs = make([]error, 0)
 	reader := func(r sqlittle.Row) {
-		var image string
-		if err := r.Scan(&image); err != nil {
+		var image, bundle string
+		if err := r.Scan(&image, &bundle); err != nil {
 			errs = append(errs, err)
 			return
 		}
-		if image != "" {
+		if image != "" && (included == nil || included.Has(bundle)) {
 			images[image] = struct{}{}
 		}
 	}
-	if err := db.Select("related_image", reader, "image"); err != nil {
+	if err := db.Select("related_image", reader, "image", "operatorbundle_name"); err != nil {
 		errs = append(errs, err)
 		return nil, errors.NewAggregate(errs)
 	}
 
 	// get all bundlepaths
-	if err := db.Select("operatorbundle", reader, "bundlepath"); err != nil {
+	if err := db.Select("operatorbundle", reader, "bundlepath", "name"); err != nil {
 		errs = append(errs, err)
 		return nil, errors.NewAggregate(errs)
 	}
@@ -468,8 +513,21 @@ func (_ sqliteRelatedImagesParser) Parse(file string) (map[string]struct{}, erro
 
 type declcfgMeta struct {
 	Schema        string                `json:"schema"`
+	Name          string                `json:"name"`
+	Package       string                `json:"package"`
 	Image         string                `json:"image"`
 	RelatedImages []declcfgRelatedImage `json:"relatedImages,omitempty"`
+	Properties    []declcfgProperty     `json:"properties,omitempty"`
+	Entries       []declcfgChannelEntry `json:"entries,omitempty"`
+}
+
+type declcfgProperty struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+type declcfgChannelEntry struct {
+	Name string `json:"name"`
 }
 
 type declcfgRelatedImage struct {
@@ -477,13 +535,15 @@ type declcfgRelatedImage struct {
 	Image string `json:"image"`
 }
 
-type declcfgRelatedImagesParser struct{}
+type declcfgRelatedImagesParser struct {
+	filter *packageFilter
+}
 
 const (
 	indexIgnoreFilename = ".indexignore"
 )
 
-func (_ declcfgRelatedImagesParser) Parse(root string) (map[string]struct{}, error) {
+func (p declcfgRelatedImagesParser) Parse(root string) (map[string]struct{}, error) {
 	rootFS := os.DirFS(root)
 
 	matcher, err := ignore.NewMatcher(rootFS, indexIgnoreFilename)
@@ -492,6 +552,9 @@ func (_ declcfgRelatedImagesParser) Parse(root string) (map[string]struct{}, err
 	}
 
 	relatedImages := map[string]struct{}{}
+	// with a filter, the images of each bundle are only added once the channels of all bundles are known
+	bundles := make(map[string]*bundleChannels)
+	bundleImages := make(map[string][]string)
 	if err := fs.WalkDir(rootFS, ".", func(path string, entry fs.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -513,6 +576,32 @@ func (_ declcfgRelatedImagesParser) Parse(root string) (map[string]struct{}, err
 				}
 				return err
 			}
+			if p.filter != nil {
+				switch blob.Schema {
+				case "olm.bundle":
+					bundle := declcfgBundle(bundles, blob.Name, blob.Package)
+					for _, property := range blob.Properties {
+						if property.Type != "olm.channel" {
+							continue
+						}
+						var channel declcfgChannelEntry
+						if err := json.Unmarshal(property.Value, &channel); err != nil {
+							return fmt.Errorf("invalid olm.channel property of bundle %s: %v", blob.Name, err)
+						}
+						bundle.channels.Insert(channel.Name)
+					}
+					bundleImages[blob.Name] = append(bundleImages[blob.Name], blob.Image)
+					for _, ri := range blob.RelatedImages {
+						bundleImages[blob.Name] = append(bundleImages[blob.Name], ri.Image)
+					}
+					continue
+				case "olm.channel":
+					for _, entry := range blob.Entries {
+						declcfgBundle(bundles, entry.Name, blob.Package).channels.Insert(blob.Name)
+					}
+					continue
+				}
+			}
 			relatedImages[blob.Image] = struct{}{}
 			for _, ri := range blob.RelatedImages {
 				relatedImages[ri.Image] = struct{}{}
@@ -522,10 +611,29 @@ func (_ declcfgRelatedImagesParser) Parse(root string) (map[string]struct{}, err
 	}); err != nil {
 		return nil, err
 	}
+	if p.filter != nil {
+		included, err := p.filter.includedBundles(bundles)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range included.List() {
+			for _, image := range bundleImages[name] {
+				relatedImages[image] = struct{}{}
+			}
+		}
+	}
 	delete(relatedImages, "")
 	return relatedImages, nil
 }
 
+// declcfgBundle returns the channels of the bundle, adding it if it is new.
+func declcfgBundle(bundles map[string]*bundleChannels, name, pkg string) *bundleChannels {
+	if bundles[name] == nil {
+		bundles[name] = &bundleChannels{pkg: pkg, channels: sets.NewString()}
+	}
+	return bundles[name]
+}
+
 func (o *MirrorCatalogOptions) Validate() error {
 	if o.IndexPath == "" {
 		return fmt.Errorf("must specify path for index")
@@ -614,3 +614,94 @@ func ElementsMatch(listA, listB []string) error {
 	}
 	return nil
 }
+
+func TestRelatedImagesParserPackageFilter(t *testing.T) {
+	etcd := []string{
+		"quay.io/test/etcd.0.9.0",
+		"quay.io/test/etcd.0.9.2",
+		"quay.io/coreos/etcd-operator@sha256:db563baa8194fcfe39d1df744ed70024b0f1f9e9b55b5923c2f3a413c44dc6b8",
+		"quay.io/coreos/etcd-operator@sha256:c0301e4686c3ed4206e370b42de5a3bd2229b9fb4906cf85f3f30650424abec2",
+	}
+	prometheusStable := []string{
+		"quay.io/test/prometheus.0.14.0",
+		"quay.io/test/prometheus.0.15.0",
+		"quay.io/coreos/prometheus-operator@sha256:5037b4e90dbb03ebdefaa547ddf6a1f748c8eeebeedf6b9d9f0913ad662b5731",
+		"quay.io/coreos/prometheus-operator@sha256:0e92dd9b5789c4b13d53e1319d0a6375bcca4caaf0d698af61198061222a576d",
+	}
+
+	tests := []struct {
+		name     string
+		packages []string
+		channels []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "package",
+			packages: []string{"etcd"},
+			want:     etcd,
+		},
+		{
+			name:     "channel of a package",
+			packages: []string{"etcd", "prometheus"},
+			channels: []string{"prometheus:stable"},
+			want:     append(append([]string{}, etcd...), prometheusStable...),
+		},
+		{
+			name:     "channel of every package",
+			channels: []string{"preview"},
+			want: []string{
+				"quay.io/test/prometheus.0.14.0",
+				"quay.io/test/prometheus.0.15.0",
+				"quay.io/test/prometheus.0.22.2",
+				"quay.io/coreos/prometheus-operator@sha256:5037b4e90dbb03ebdefaa547ddf6a1f748c8eeebeedf6b9d9f0913ad662b5731",
+				"quay.io/coreos/prometheus-operator@sha256:0e92dd9b5789c4b13d53e1319d0a6375bcca4caaf0d698af61198061222a576d",
+				"quay.io/coreos/prometheus-operator@sha256:3daa69a8c6c2f1d35dcf1fe48a7cd8b230e55f5229a1ded438f687debade5bcf",
+			},
+		},
+		{
+			name:     "missing package",
+			packages: []string{"etcd", "missing"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		filter, err := newPackageFilter(tt.packages, tt.channels)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		parsers := map[string]func() (map[string]struct{}, error){
+			"sqlite":  func() (map[string]struct{}, error) { return sqliteRelatedImagesParser{filter: filter}.Parse("testdata/test.db") },
+			"declcfg": func() (map[string]struct{}, error) { return declcfgRelatedImagesParser{filter: filter}.Parse("testdata/test-declcfg") },
+		}
+		for format, parse := range parsers {
+			t.Run(tt.name+" "+format, func(t *testing.T) {
+				images, err := parse()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if tt.wantErr {
+					return
+				}
+				want := map[string]struct{}{}
+				for _, image := range tt.want {
+					want[image] = struct{}{}
+				}
+				if !reflect.DeepEqual(images, want) {
+					t.Errorf("unexpected images (-want +got):\n%s", cmp.Diff(want, images))
+				}
+			})
+		}
+	}
+}
+
+func TestNewPackageFilter(t *testing.T) {
+	for _, invalid := range [][]string{{":stable"}, {"etcd:"}, {""}, {"other:stable"}} {
+		if _, err := newPackageFilter([]string{"etcd"}, invalid); err == nil {
+			t.Errorf("expected --channels %v to be rejected", invalid)
+		}
+	}
+	if filter, err := newPackageFilter(nil, nil); err != nil || filter != nil {
+		t.Errorf("expected no filter, got %v, %v", filter, err)
+	}
+}
@@ -0,0 +1,340 @@
+package certificate
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/kubectl/pkg/cmd/certificates"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	bulkApproveLong = templates.LongDesc(`
+		With --all-pending every pending certificate signing request issued by one of the allowed signers is
+		approved, instead of the named ones. By default only the kubelet client and serving signers are allowed,
+		so that scaling up a cluster does not require approving every node CSR by name while CSRs for other
+		signers are never approved by accident. --node-prefix further limits the approval to the CSRs of nodes
+		whose name starts with the prefix.
+
+		Kubelet CSRs are only approved if they request what a kubelet may request: client certificates must be
+		requested by the node bootstrapper or by the node itself, serving certificates by the node itself, for
+		the node's own name and addresses only. Other kubelet CSRs are skipped with a warning.
+
+		The CSRs to approve are listed first; they are only approved with --confirm.
+	`)
+
+	bulkApproveExample = templates.Examples(`
+		# List the pending kubelet CSRs that would be approved
+		oc adm certificate approve --all-pending
+
+		# Approve the pending kubelet CSRs of the new worker nodes
+		oc adm certificate approve --all-pending --node-prefix=worker- --confirm
+	`)
+)
+
+// defaultSignerNames are the signers whose CSRs --all-pending approves unless --signer-name is given.
+var defaultSignerNames = []string{
+	certificatesv1.KubeAPIServerClientKubeletSignerName,
+	certificatesv1.KubeletServingSignerName,
+}
+
+// nodeUserPrefix is the prefix of the user names of nodes, and of the common names they request.
+const nodeUserPrefix = "system:node:"
+
+const (
+	// nodesGroup is the organization of the certificates of the nodes.
+	nodesGroup = "system:nodes"
+	// nodeBootstrapperUser is the service account that requests the first client certificate of a node.
+	nodeBootstrapperUser = "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper"
+)
+
+// BulkApproveOptions holds the options of oc adm certificate approve --all-pending.
+type BulkApproveOptions struct {
+	AllPending  bool
+	NodePrefix  string
+	SignerNames []string
+	Confirm     bool
+
+	Client kubernetes.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewBulkApproveOptions(streams genericclioptions.IOStreams) *BulkApproveOptions {
+	return &BulkApproveOptions{
+		SignerNames: defaultSignerNames,
+		IOStreams:   streams,
+	}
+}
+
+// NewCmdCertificate returns the kubectl certificate command, with approve extended to approve pending CSRs in bulk.
+func NewCmdCertificate(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := certificates.NewCmdCertificate(f, streams)
+	for _, subcommand := range cmd.Commands() {
+		if subcommand.Name() == "approve" {
+			extendApprove(f, subcommand, streams)
+		}
+	}
+	return cmd
+}
+
+// extendApprove adds --all-pending to the kubectl approve command. Without it the command is unchanged.
+func extendApprove(f kcmdutil.Factory, cmd *cobra.Command, streams genericclioptions.IOStreams) {
+	o := NewBulkApproveOptions(streams)
+	cmd.Use = "approve (-f FILENAME | NAME | --all-pending)"
+	cmd.Long = cmd.Long + "\n\n" + bulkApproveLong
+	cmd.Example = cmd.Example + "\n\n" + bulkApproveExample
+
+	approveByName := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if !o.AllPending {
+			if len(o.NodePrefix) > 0 || cmd.Flags().Changed("signer-name") {
+				kcmdutil.CheckErr(kcmdutil.UsageErrorf(cmd, "--node-prefix and --signer-name require --all-pending"))
+			}
+			approveByName(cmd, args)
+			return
+		}
+		kcmdutil.CheckErr(o.Complete(f, cmd, args))
+		kcmdutil.CheckErr(o.Validate())
+		kcmdutil.CheckErr(o.Run())
+	}
+
+	cmd.Flags().BoolVar(&o.AllPending, "all-pending", o.AllPending, "If true, approve all pending CSRs of the allowed signers instead of the named ones.")
+	cmd.Flags().StringVar(&o.NodePrefix, "node-prefix", o.NodePrefix, "Only approve the CSRs of the nodes whose name starts with this prefix. Requires --all-pending.")
+	cmd.Flags().StringSliceVar(&o.SignerNames, "signer-name", o.SignerNames, "Signers whose pending CSRs may be approved. Requires --all-pending.")
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, approve the CSRs. Defaults to false, listing what would be approved without approving anything. Requires --all-pending.")
+}
+
+func (o *BulkApproveOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 || cmd.Flags().Changed("filename") {
+		return kcmdutil.UsageErrorf(cmd, "--all-pending may not be combined with CSR names or files")
+	}
+	var err error
+	o.Client, err = f.KubernetesClientSet()
+	return err
+}
+
+func (o *BulkApproveOptions) Validate() error {
+	if len(o.SignerNames) == 0 {
+		return fmt.Errorf("at least one --signer-name is required")
+	}
+	return nil
+}
+
+// pendingCSR is a pending CSR and the node it was requested for, if any.
+type pendingCSR struct {
+	csr  *certificatesv1.CertificateSigningRequest
+	node string
+}
+
+func (o *BulkApproveOptions) Run() error {
+	list, err := o.Client.CertificatesV1().CertificateSigningRequests().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	signers := sets.NewString(o.SignerNames...)
+	var approve []pendingCSR
+	skippedSigners := map[string]int{}
+	for i := range list.Items {
+		csr := &list.Items[i]
+		if !IsPending(csr) {
+			continue
+		}
+		if !signers.Has(csr.Spec.SignerName) {
+			skippedSigners[csr.Spec.SignerName]++
+			continue
+		}
+		node := NodeName(csr)
+		if len(o.NodePrefix) > 0 && (len(node) == 0 || !strings.HasPrefix(node, o.NodePrefix)) {
+			continue
+		}
+		if err := o.validateKubeletCSR(csr, node); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: skipping CSR %s: %v\n", csr.Name, err)
+			continue
+		}
+		approve = append(approve, pendingCSR{csr: csr, node: node})
+	}
+	sort.Slice(approve, func(i, j int) bool { return approve[i].csr.Name < approve[j].csr.Name })
+
+	for _, signer := range sets.StringKeySet(skippedSigners).List() {
+		fmt.Fprintf(o.ErrOut, "warning: skipping %d pending CSR(s) of signer %s, use --signer-name to approve them\n", skippedSigners[signer], signer)
+	}
+	if len(approve) == 0 {
+		fmt.Fprintf(o.Out, "No pending certificate signing requests to approve\n")
+		return nil
+	}
+
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintf(w, "NAME\tSIGNER\tREQUESTOR\tNODE\tAGE\n")
+	for _, p := range approve {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.csr.Name, p.csr.Spec.SignerName, p.csr.Spec.Username, p.node, duration.HumanDuration(metav1.Now().Sub(p.csr.CreationTimestamp.Time)))
+	}
+	w.Flush()
+
+	if !o.Confirm {
+		fmt.Fprintf(o.Out, "\n%d certificate signing request(s) would be approved, use --confirm to approve them\n", len(approve))
+		return nil
+	}
+
+	errs := []error{}
+	approved := 0
+	for _, p := range approve {
+		if err := o.approve(p.csr.Name); err != nil {
+			errs = append(errs, fmt.Errorf("unable to approve %s: %v", p.csr.Name, err))
+			continue
+		}
+		approved++
+	}
+	fmt.Fprintf(o.Out, "\n%d certificate signing request(s) approved\n", approved)
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateKubeletCSR returns an error unless the CSR of a kubelet signer is requested by someone allowed to request
+// a certificate for the node, with the usages and names a kubelet requests. CSRs of other signers are not checked.
+func (o *BulkApproveOptions) validateKubeletCSR(csr *certificatesv1.CertificateSigningRequest, node string) error {
+	var requestors, requiredUsages []string
+	switch csr.Spec.SignerName {
+	case certificatesv1.KubeAPIServerClientKubeletSignerName:
+		requestors = []string{nodeBootstrapperUser, nodeUserPrefix + node}
+		requiredUsages = []string{string(certificatesv1.UsageClientAuth)}
+	case certificatesv1.KubeletServingSignerName:
+		requestors = []string{nodeUserPrefix + node}
+		requiredUsages = []string{string(certificatesv1.UsageServerAuth)}
+	default:
+		return nil
+	}
+
+	if len(node) == 0 {
+		return fmt.Errorf("not requested for a node")
+	}
+	if !sets.NewString(requestors...).Has(csr.Spec.Username) {
+		return fmt.Errorf("requestor %s may not request a certificate for node %s", csr.Spec.Username, node)
+	}
+	request, err := parseCSR(csr)
+	if err != nil {
+		return err
+	}
+	if request.Subject.CommonName != nodeUserPrefix+node || len(request.Subject.Organization) != 1 || request.Subject.Organization[0] != nodesGroup {
+		return fmt.Errorf("subject must be CN=%s%s, O=%s", nodeUserPrefix, node, nodesGroup)
+	}
+
+	usages := sets.NewString()
+	for _, usage := range csr.Spec.Usages {
+		usages.Insert(string(usage))
+	}
+	allowedUsages := sets.NewString(string(certificatesv1.UsageDigitalSignature), string(certificatesv1.UsageKeyEncipherment)).Insert(requiredUsages...)
+	if !usages.HasAll(requiredUsages...) || !allowedUsages.IsSuperset(usages) {
+		return fmt.Errorf("usages %s are not allowed, expected %s", strings.Join(usages.List(), ", "), strings.Join(allowedUsages.List(), ", "))
+	}
+
+	if len(request.EmailAddresses) > 0 || len(request.URIs) > 0 {
+		return fmt.Errorf("email and URI subject alternative names are not allowed")
+	}
+	if csr.Spec.SignerName == certificatesv1.KubeAPIServerClientKubeletSignerName {
+		if len(request.DNSNames) > 0 || len(request.IPAddresses) > 0 {
+			return fmt.Errorf("subject alternative names are not allowed in client certificates")
+		}
+		return nil
+	}
+
+	// serving certificates may only be requested for the addresses of the node
+	if len(request.DNSNames) == 0 && len(request.IPAddresses) == 0 {
+		return fmt.Errorf("no DNS name or IP address requested")
+	}
+	n, err := o.Client.CoreV1().Nodes().Get(context.TODO(), node, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to verify the addresses of node %s: %v", node, err)
+	}
+	dnsNames, ips := sets.NewString(), sets.NewString()
+	for _, address := range n.Status.Addresses {
+		switch address.Type {
+		case corev1.NodeHostName, corev1.NodeInternalDNS, corev1.NodeExternalDNS:
+			dnsNames.Insert(address.Address)
+		case corev1.NodeInternalIP, corev1.NodeExternalIP:
+			ips.Insert(address.Address)
+		}
+	}
+	for _, name := range request.DNSNames {
+		if !dnsNames.Has(name) {
+			return fmt.Errorf("DNS name %s is not an address of node %s", name, node)
+		}
+	}
+	for _, ip := range request.IPAddresses {
+		if !ips.Has(ip.String()) {
+			return fmt.Errorf("IP address %s is not an address of node %s", ip, node)
+		}
+	}
+	return nil
+}
+
+// parseCSR returns the certificate request of the CSR.
+func parseCSR(csr *certificatesv1.CertificateSigningRequest) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(csr.Spec.Request)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("the request is not a PEM encoded certificate request")
+	}
+	return x509.ParseCertificateRequest(block.Bytes)
+}
+
+// approve adds the approved condition to the CSR unless it was approved or denied in the meantime.
+func (o *BulkApproveOptions) approve(name string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		csr, err := o.Client.CertificatesV1().CertificateSigningRequests().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !IsPending(csr) {
+			return fmt.Errorf("no longer pending")
+		}
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:           certificatesv1.CertificateApproved,
+			Status:         corev1.ConditionTrue,
+			Reason:         "OcAdmBulkApprove",
+			Message:        "This CSR was approved by oc adm certificate approve --all-pending.",
+			LastUpdateTime: metav1.Now(),
+		})
+		_, err = o.Client.CertificatesV1().CertificateSigningRequests().UpdateApproval(context.TODO(), name, csr, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// IsPending returns true if the CSR was neither approved nor denied.
+func IsPending(csr *certificatesv1.CertificateSigningRequest) bool {
+	for _, condition := range csr.Status.Conditions {
+		if condition.Type == certificatesv1.CertificateApproved || condition.Type == certificatesv1.CertificateDenied {
+			return false
+		}
+	}
+	return true
+}
+
+// NodeName returns the node a kubelet CSR is for: the requestor for serving certificates, the requested common
+// name for client certificates requested by the bootstrapper. Other CSRs have no node.
+func NodeName(csr *certificatesv1.CertificateSigningRequest) string {
+	if strings.HasPrefix(csr.Spec.Username, nodeUserPrefix) {
+		return strings.TrimPrefix(csr.Spec.Username, nodeUserPrefix)
+	}
+	request, err := parseCSR(csr)
+	if err != nil || !strings.HasPrefix(request.Subject.CommonName, nodeUserPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(request.Subject.CommonName, nodeUserPrefix)
+}
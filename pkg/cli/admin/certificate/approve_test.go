@@ -0,0 +1,281 @@
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"strings"
+	"testing"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBulkApprove(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(commonName string, dnsNames ...string) []byte {
+		template := &x509.CertificateRequest{Subject: pkix.Name{CommonName: commonName, Organization: []string{"system:nodes"}}, DNSNames: dnsNames}
+		der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	}
+	csr := func(name, signer, username string, req []byte, conditions ...certificatesv1.RequestConditionType) *certificatesv1.CertificateSigningRequest {
+		usages := []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageKeyEncipherment, certificatesv1.UsageClientAuth}
+		if signer == certificatesv1.KubeletServingSignerName {
+			usages = []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageKeyEncipherment, certificatesv1.UsageServerAuth}
+		}
+		csr := &certificatesv1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Now()},
+			Spec:       certificatesv1.CertificateSigningRequestSpec{SignerName: signer, Username: username, Request: req, Usages: usages},
+		}
+		for _, condition := range conditions {
+			csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{Type: condition, Status: corev1.ConditionTrue})
+		}
+		return csr
+	}
+	bootstrapper := "system:serviceaccount:openshift-machine-config-operator:node-bootstrapper"
+	objects := func() []*certificatesv1.CertificateSigningRequest {
+		return []*certificatesv1.CertificateSigningRequest{
+			csr("csr-client-worker-1", certificatesv1.KubeAPIServerClientKubeletSignerName, bootstrapper, request("system:node:worker-1")),
+			csr("csr-serving-worker-1", certificatesv1.KubeletServingSignerName, "system:node:worker-1", request("system:node:worker-1", "worker-1")),
+			csr("csr-serving-infra-1", certificatesv1.KubeletServingSignerName, "system:node:infra-1", request("system:node:infra-1", "infra-1")),
+			csr("csr-approved", certificatesv1.KubeletServingSignerName, "system:node:worker-2", request("system:node:worker-2", "worker-2"), certificatesv1.CertificateApproved),
+			csr("csr-denied", certificatesv1.KubeletServingSignerName, "system:node:worker-3", request("system:node:worker-3", "worker-3"), certificatesv1.CertificateDenied),
+			csr("csr-other", "example.com/signer", "alice", request("alice")),
+		}
+	}
+	node := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     corev1.NodeStatus{Addresses: []corev1.NodeAddress{{Type: corev1.NodeHostName, Address: name}, {Type: corev1.NodeInternalIP, Address: "10.0.0.1"}}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		options          BulkApproveOptions
+		expectedApproved []string
+		expectedOut      []string
+		expectedErrOut   string
+	}{
+		{
+			name:             "list only",
+			options:          BulkApproveOptions{SignerNames: defaultSignerNames},
+			expectedOut:      []string{"csr-client-worker-1", "csr-serving-infra-1", "csr-serving-worker-1", "3 certificate signing request(s) would be approved, use --confirm"},
+			expectedApproved: []string{"csr-approved"},
+			expectedErrOut:   "warning: skipping 1 pending CSR(s) of signer example.com/signer, use --signer-name to approve them\n",
+		},
+		{
+			name:             "node prefix",
+			options:          BulkApproveOptions{SignerNames: defaultSignerNames, NodePrefix: "worker-", Confirm: true},
+			expectedOut:      []string{"csr-client-worker-1", "csr-serving-worker-1", "2 certificate signing request(s) approved"},
+			expectedApproved: []string{"csr-approved", "csr-client-worker-1", "csr-serving-worker-1"},
+			expectedErrOut:   "warning: skipping 1 pending CSR(s) of signer example.com/signer, use --signer-name to approve them\n",
+		},
+		{
+			name:             "signer name",
+			options:          BulkApproveOptions{SignerNames: []string{"example.com/signer"}, Confirm: true},
+			expectedOut:      []string{"csr-other", "1 certificate signing request(s) approved"},
+			expectedApproved: []string{"csr-approved", "csr-other"},
+			expectedErrOut: "warning: skipping 1 pending CSR(s) of signer kubernetes.io/kube-apiserver-client-kubelet, use --signer-name to approve them\n" +
+				"warning: skipping 2 pending CSR(s) of signer kubernetes.io/kubelet-serving, use --signer-name to approve them\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(node("worker-1"), node("infra-1"))
+			for _, csr := range objects() {
+				if _, err := client.CertificatesV1().CertificateSigningRequests().Create(context.TODO(), csr, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			o := test.options
+			o.Client = client
+			o.IOStreams = genericclioptions.IOStreams{Out: out, ErrOut: errOut}
+			if err := o.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, expected := range test.expectedOut {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+				}
+			}
+			if errOut.String() != test.expectedErrOut {
+				t.Errorf("expected warnings:\n%s\ngot:\n%s", test.expectedErrOut, errOut.String())
+			}
+
+			list, err := client.CertificatesV1().CertificateSigningRequests().List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var approved []string
+			for _, csr := range list.Items {
+				for _, condition := range csr.Status.Conditions {
+					if condition.Type == certificatesv1.CertificateApproved {
+						approved = append(approved, csr.Name)
+					}
+				}
+			}
+			if strings.Join(approved, ",") != strings.Join(test.expectedApproved, ",") {
+				t.Errorf("expected approved CSRs %v, got %v", test.expectedApproved, approved)
+			}
+		})
+	}
+}
+
+func TestValidateKubeletCSR(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := func(template *x509.CertificateRequest) []byte {
+		der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	}
+	nodeSubject := pkix.Name{CommonName: "system:node:worker-1", Organization: []string{"system:nodes"}}
+	clientUsages := []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageKeyEncipherment, certificatesv1.UsageClientAuth}
+	servingUsages := []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageKeyEncipherment, certificatesv1.UsageServerAuth}
+
+	tests := []struct {
+		name     string
+		signer   string
+		username string
+		usages   []certificatesv1.KeyUsage
+		request  *x509.CertificateRequest
+		err      string
+	}{
+		{
+			name:     "bootstrap client",
+			signer:   certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username: nodeBootstrapperUser,
+			usages:   clientUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject},
+		},
+		{
+			name:     "client renewal",
+			signer:   certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username: "system:node:worker-1",
+			usages:   clientUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject},
+		},
+		{
+			name:     "client requested by another user",
+			signer:   certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username: "mallory",
+			usages:   clientUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject},
+			err:      "requestor mallory may not request a certificate for node worker-1",
+		},
+		{
+			name:     "client requested by another node",
+			signer:   certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username: "system:node:worker-2",
+			usages:   clientUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject},
+			err:      "subject must be CN=system:node:worker-2, O=system:nodes",
+		},
+		{
+			name:     "client with another group",
+			signer:   certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username: nodeBootstrapperUser,
+			usages:   clientUsages,
+			request:  &x509.CertificateRequest{Subject: pkix.Name{CommonName: "system:node:worker-1", Organization: []string{"system:masters"}}},
+			err:      "subject must be CN=system:node:worker-1, O=system:nodes",
+		},
+		{
+			name:     "client with server usage",
+			signer:   certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username: nodeBootstrapperUser,
+			usages:   append(clientUsages, certificatesv1.UsageServerAuth),
+			request:  &x509.CertificateRequest{Subject: nodeSubject},
+			err:      "usages client auth, digital signature, key encipherment, server auth are not allowed",
+		},
+		{
+			name:     "client with names",
+			signer:   certificatesv1.KubeAPIServerClientKubeletSignerName,
+			username: nodeBootstrapperUser,
+			usages:   clientUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject, DNSNames: []string{"worker-1"}},
+			err:      "subject alternative names are not allowed in client certificates",
+		},
+		{
+			name:     "serving",
+			signer:   certificatesv1.KubeletServingSignerName,
+			username: "system:node:worker-1",
+			usages:   servingUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject, DNSNames: []string{"worker-1"}, IPAddresses: []net.IP{net.ParseIP("10.0.0.1")}},
+		},
+		{
+			name:     "serving requested by the bootstrapper",
+			signer:   certificatesv1.KubeletServingSignerName,
+			username: nodeBootstrapperUser,
+			usages:   servingUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject, DNSNames: []string{"worker-1"}},
+			err:      "may not request a certificate for node worker-1",
+		},
+		{
+			name:     "serving for another name",
+			signer:   certificatesv1.KubeletServingSignerName,
+			username: "system:node:worker-1",
+			usages:   servingUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject, DNSNames: []string{"api.example.com"}},
+			err:      "DNS name api.example.com is not an address of node worker-1",
+		},
+		{
+			name:     "serving for another address",
+			signer:   certificatesv1.KubeletServingSignerName,
+			username: "system:node:worker-1",
+			usages:   servingUsages,
+			request:  &x509.CertificateRequest{Subject: nodeSubject, IPAddresses: []net.IP{net.ParseIP("10.0.0.2")}},
+			err:      "IP address 10.0.0.2 is not an address of node worker-1",
+		},
+		{
+			name:     "other signer",
+			signer:   "example.com/signer",
+			username: "alice",
+			request:  &x509.CertificateRequest{Subject: pkix.Name{CommonName: "alice"}},
+		},
+	}
+	o := &BulkApproveOptions{Client: fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Status:     corev1.NodeStatus{Addresses: []corev1.NodeAddress{{Type: corev1.NodeHostName, Address: "worker-1"}, {Type: corev1.NodeInternalIP, Address: "10.0.0.1"}}},
+	})}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			csr := &certificatesv1.CertificateSigningRequest{
+				Spec: certificatesv1.CertificateSigningRequestSpec{SignerName: test.signer, Username: test.username, Usages: test.usages, Request: request(test.request)},
+			}
+			err := o.validateKubeletCSR(csr, NodeName(csr))
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
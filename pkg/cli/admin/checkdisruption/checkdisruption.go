@@ -0,0 +1,312 @@
+package checkdisruption
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	checkDisruptionLong = templates.LongDesc(`
+		Report the pod disruption budgets that would block node drains and upgrades.
+
+		Every pod disruption budget is evaluated against the pods it selects and the nodes they
+		run on. A budget is reported when:
+
+		* Blocking: it allows no disruption, so the eviction of its pods fails and a drain waits
+		  until the budget allows a disruption again, which never happens when the budget allows
+		  no disruption even with all its pods healthy.
+		* Misconfigured: its selector is invalid or selects no pod, or its pods are also selected
+		  by another budget, which makes their eviction fail.
+		* Warning: all its scheduled pods run on the same node, so draining that node disrupts
+		  the whole workload.
+
+		The budgets of all namespaces are evaluated, unless a namespace is given with --namespace.
+		Only the budgets with a problem are printed, unless --all is given.
+	`)
+
+	checkDisruptionExample = templates.Examples(`
+		# Report the pod disruption budgets that would block drains
+		oc adm check-disruption
+
+		# Report all the pod disruption budgets of a namespace
+		oc adm check-disruption -n my-project --all
+
+		# Report the pod disruption budgets that would block drains as JSON
+		oc adm check-disruption -o json
+	`)
+)
+
+// The status of a budget, from the most to the least severe.
+const (
+	statusMisconfigured = "Misconfigured"
+	statusBlocking      = "Blocking"
+	statusWarning       = "Warning"
+	statusOK            = "OK"
+)
+
+var statusSeverity = map[string]int{
+	statusMisconfigured: 3,
+	statusBlocking:      2,
+	statusWarning:       1,
+	statusOK:            0,
+}
+
+// CheckDisruptionOptions holds the options to check the pod disruption budgets.
+type CheckDisruptionOptions struct {
+	All    bool
+	Output string
+
+	Namespace         string
+	ExplicitNamespace bool
+
+	Client kubernetes.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewCheckDisruptionOptions(streams genericclioptions.IOStreams) *CheckDisruptionOptions {
+	return &CheckDisruptionOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdCheckDisruption creates a command that reports the pod disruption budgets that would block drains.
+func NewCmdCheckDisruption(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCheckDisruptionOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "check-disruption",
+		Short:   "Report the pod disruption budgets that would block drains and upgrades",
+		Long:    checkDisruptionLong,
+		Example: checkDisruptionExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, print all the pod disruption budgets, including those without a problem.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+
+	return cmd
+}
+
+func (o *CheckDisruptionOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	if o.Namespace, o.ExplicitNamespace, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+	if !o.ExplicitNamespace {
+		o.Namespace = metav1.NamespaceAll
+	}
+	o.Client, err = f.KubernetesClientSet()
+	return err
+}
+
+func (o *CheckDisruptionOptions) Validate() error {
+	switch o.Output {
+	case "", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("--output must be one of: json, yaml")
+}
+
+func (o *CheckDisruptionOptions) Run() error {
+	budgets, err := o.Client.PolicyV1().PodDisruptionBudgets(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := o.Client.CoreV1().Pods(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	reports := evaluateBudgets(budgets.Items, pods.Items)
+	problems := 0
+	for _, report := range reports {
+		if report.Status != statusOK {
+			problems++
+		}
+	}
+	if !o.All {
+		reports = reports[:problems]
+	}
+
+	if len(o.Output) > 0 {
+		if reports == nil {
+			reports = []*budgetReport{}
+		}
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, reports)
+	}
+
+	if len(reports) > 0 {
+		printReports(o.Out, reports)
+		fmt.Fprintln(o.Out)
+	}
+	fmt.Fprintf(o.Out, "%d of %d pod disruption budgets have a problem\n", problems, len(budgets.Items))
+	return nil
+}
+
+// budgetReport is the evaluation of a pod disruption budget.
+type budgetReport struct {
+	Namespace      string `json:"namespace"`
+	Name           string `json:"name"`
+	MinAvailable   string `json:"minAvailable,omitempty"`
+	MaxUnavailable string `json:"maxUnavailable,omitempty"`
+	// Pods is the number of pods selected by the budget.
+	Pods               int      `json:"pods"`
+	CurrentHealthy     int32    `json:"currentHealthy"`
+	DesiredHealthy     int32    `json:"desiredHealthy"`
+	DisruptionsAllowed int32    `json:"disruptionsAllowed"`
+	Nodes              []string `json:"nodes"`
+	Status             string   `json:"status"`
+	Reasons            []string `json:"reasons,omitempty"`
+}
+
+// addProblem adds the reason of a problem to the report, whose status becomes the most severe of its problems.
+func (r *budgetReport) addProblem(status, reason string) {
+	if statusSeverity[status] > statusSeverity[r.Status] {
+		r.Status = status
+	}
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// evaluateBudgets evaluates the budgets against the pods and returns their reports, the most severe first.
+// Terminated pods are ignored, as the disruption controller does.
+func evaluateBudgets(budgets []policyv1.PodDisruptionBudget, pods []corev1.Pod) []*budgetReport {
+	podsByNamespace := map[string][]*corev1.Pod{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podsByNamespace[pod.Namespace] = append(podsByNamespace[pod.Namespace], pod)
+	}
+
+	var reports []*budgetReport
+	// budgetsByPod are the budgets that select a pod, by namespace/name of the pod
+	budgetsByPod := map[string][]string{}
+	selected := map[*budgetReport][]*corev1.Pod{}
+	scheduled := map[*budgetReport]int{}
+	for i := range budgets {
+		budget := &budgets[i]
+		report := &budgetReport{
+			Namespace:          budget.Namespace,
+			Name:               budget.Name,
+			CurrentHealthy:     budget.Status.CurrentHealthy,
+			DesiredHealthy:     budget.Status.DesiredHealthy,
+			DisruptionsAllowed: budget.Status.DisruptionsAllowed,
+			Nodes:              []string{},
+			Status:             statusOK,
+		}
+		if budget.Spec.MinAvailable != nil {
+			report.MinAvailable = budget.Spec.MinAvailable.String()
+		}
+		if budget.Spec.MaxUnavailable != nil {
+			report.MaxUnavailable = budget.Spec.MaxUnavailable.String()
+		}
+		reports = append(reports, report)
+
+		selector, err := metav1.LabelSelectorAsSelector(budget.Spec.Selector)
+		if err != nil {
+			report.addProblem(statusMisconfigured, fmt.Sprintf("the selector is invalid: %v", err))
+			continue
+		}
+		nodes := sets.NewString()
+		for _, pod := range podsByNamespace[budget.Namespace] {
+			if !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			selected[report] = append(selected[report], pod)
+			budgetsByPod[pod.Namespace+"/"+pod.Name] = append(budgetsByPod[pod.Namespace+"/"+pod.Name], budget.Name)
+			if len(pod.Spec.NodeName) > 0 {
+				nodes.Insert(pod.Spec.NodeName)
+				scheduled[report]++
+			}
+		}
+		report.Pods = len(selected[report])
+		report.Nodes = nodes.List()
+	}
+
+	for _, report := range reports {
+		if len(report.Reasons) > 0 {
+			// the selector is invalid
+			continue
+		}
+		if report.Pods == 0 {
+			report.addProblem(statusMisconfigured, "the selector matches no pod")
+			continue
+		}
+
+		others := sets.NewString()
+		for _, pod := range selected[report] {
+			for _, name := range budgetsByPod[pod.Namespace+"/"+pod.Name] {
+				if name != report.Name {
+					others.Insert(name)
+				}
+			}
+		}
+		if others.Len() > 0 {
+			report.addProblem(statusMisconfigured, fmt.Sprintf("its pods are also selected by %s, their eviction fails", strings.Join(others.List(), ", ")))
+		}
+
+		if report.DisruptionsAllowed <= 0 {
+			switch unhealthy := report.Pods - int(report.CurrentHealthy); {
+			case int(report.DesiredHealthy) >= report.Pods:
+				report.addProblem(statusBlocking, "no disruption is allowed even with all pods healthy")
+			case unhealthy > 0:
+				report.addProblem(statusBlocking, fmt.Sprintf("no disruption is allowed while %d pod(s) are unhealthy", unhealthy))
+			default:
+				report.addProblem(statusBlocking, "no disruption is allowed")
+			}
+		}
+
+		if scheduled[report] > 1 && len(report.Nodes) == 1 {
+			report.addProblem(statusWarning, fmt.Sprintf("all %d scheduled pods run on node %s", scheduled[report], report.Nodes[0]))
+		}
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		a, b := reports[i], reports[j]
+		if statusSeverity[a.Status] != statusSeverity[b.Status] {
+			return statusSeverity[a.Status] > statusSeverity[b.Status]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return reports
+}
+
+// printReports prints a table of the reports.
+func printReports(out io.Writer, reports []*budgetReport) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tPODS\tHEALTHY\tALLOWED\tNODES\tSTATUS\tREASONS")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d/%d\t%d\t%d\t%s\t%s\n", r.Namespace, r.Name, r.Pods, r.CurrentHealthy, r.DesiredHealthy, r.DisruptionsAllowed, len(r.Nodes), r.Status, strings.Join(r.Reasons, "; "))
+	}
+	w.Flush()
+}
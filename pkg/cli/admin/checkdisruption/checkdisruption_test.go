@@ -0,0 +1,142 @@
+package checkdisruption
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testBudget(namespace, name string, selector *metav1.LabelSelector, current, desired, allowed int32) *policyv1.PodDisruptionBudget {
+	minAvailable := intstr.FromInt(int(desired))
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: selector, MinAvailable: &minAvailable},
+		Status:     policyv1.PodDisruptionBudgetStatus{CurrentHealthy: current, DesiredHealthy: desired, DisruptionsAllowed: allowed},
+	}
+}
+
+func testPod(namespace, name, app, node string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"app": app}},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func app(name string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}}
+}
+
+func TestEvaluateBudgets(t *testing.T) {
+	budgets := []policyv1.PodDisruptionBudget{
+		*testBudget("a", "web", app("web"), 3, 2, 1),
+		*testBudget("a", "db", app("db"), 2, 2, 0),
+		*testBudget("a", "cache", app("cache"), 1, 1, 0),
+		*testBudget("a", "queue", app("queue"), 2, 1, 1),
+		*testBudget("a", "none", app("missing"), 0, 1, 0),
+		*testBudget("a", "all", &metav1.LabelSelector{}, 8, 1, 7),
+		*testBudget("b", "invalid", &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}}, 0, 0, 0),
+		*testBudget("b", "web", app("web"), 1, 0, 1),
+	}
+	pods := []corev1.Pod{
+		*testPod("a", "web-1", "web", "node-1", corev1.PodRunning),
+		*testPod("a", "web-2", "web", "node-2", corev1.PodRunning),
+		*testPod("a", "web-3", "web", "node-3", corev1.PodRunning),
+		*testPod("a", "web-old", "web", "node-3", corev1.PodSucceeded),
+		*testPod("a", "db-1", "db", "node-1", corev1.PodRunning),
+		*testPod("a", "db-2", "db", "node-2", corev1.PodRunning),
+		*testPod("a", "cache-1", "cache", "node-1", corev1.PodRunning),
+		*testPod("a", "cache-2", "cache", "", corev1.PodPending),
+		*testPod("a", "queue-1", "queue", "node-2", corev1.PodRunning),
+		*testPod("a", "queue-2", "queue", "node-2", corev1.PodRunning),
+		*testPod("b", "web-1", "web", "node-1", corev1.PodRunning),
+	}
+
+	reports := evaluateBudgets(budgets, pods)
+	type summary struct {
+		name    string
+		pods    int
+		nodes   []string
+		status  string
+		reasons []string
+	}
+	var got []summary
+	for _, r := range reports {
+		got = append(got, summary{r.Namespace + "/" + r.Name, r.Pods, r.Nodes, r.Status, r.Reasons})
+	}
+	expected := []summary{
+		{"a/all", 9, []string{"node-1", "node-2", "node-3"}, statusMisconfigured, []string{"its pods are also selected by cache, db, queue, web, their eviction fails"}},
+		{"a/cache", 2, []string{"node-1"}, statusMisconfigured, []string{"its pods are also selected by all, their eviction fails", "no disruption is allowed while 1 pod(s) are unhealthy"}},
+		{"a/db", 2, []string{"node-1", "node-2"}, statusMisconfigured, []string{"its pods are also selected by all, their eviction fails", "no disruption is allowed even with all pods healthy"}},
+		{"a/none", 0, []string{}, statusMisconfigured, []string{"the selector matches no pod"}},
+		{"a/queue", 2, []string{"node-2"}, statusMisconfigured, []string{"its pods are also selected by all, their eviction fails", "all 2 scheduled pods run on node node-2"}},
+		{"a/web", 3, []string{"node-1", "node-2", "node-3"}, statusMisconfigured, []string{"its pods are also selected by all, their eviction fails"}},
+		{"b/invalid", 0, []string{}, statusMisconfigured, []string{`the selector is invalid: "Bogus" is not a valid pod selector operator`}},
+		{"b/web", 1, []string{"node-1"}, statusOK, nil},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected reports:\n%v\nexpected:\n%v", got, expected)
+	}
+}
+
+func TestEvaluateBudgets_status(t *testing.T) {
+	budgets := []policyv1.PodDisruptionBudget{
+		*testBudget("a", "db", app("db"), 2, 2, 0),
+		*testBudget("a", "queue", app("queue"), 2, 1, 1),
+		*testBudget("a", "web", app("web"), 2, 1, 1),
+	}
+	pods := []corev1.Pod{
+		*testPod("a", "db-1", "db", "node-1", corev1.PodRunning),
+		*testPod("a", "db-2", "db", "node-2", corev1.PodRunning),
+		*testPod("a", "queue-1", "queue", "node-2", corev1.PodRunning),
+		*testPod("a", "queue-2", "queue", "node-2", corev1.PodRunning),
+		*testPod("a", "web-1", "web", "node-1", corev1.PodRunning),
+		*testPod("a", "web-2", "web", "node-2", corev1.PodRunning),
+	}
+	var got []string
+	for _, r := range evaluateBudgets(budgets, pods) {
+		got = append(got, r.Name+"="+r.Status)
+	}
+	if expected := []string{"db=Blocking", "queue=Warning", "web=OK"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected statuses %v, expected %v", got, expected)
+	}
+}
+
+func TestRun(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		testBudget("a", "db", app("db"), 2, 2, 0),
+		testBudget("a", "web", app("web"), 2, 1, 1),
+		testPod("a", "db-1", "db", "node-1", corev1.PodRunning),
+		testPod("a", "db-2", "db", "node-2", corev1.PodRunning),
+		testPod("a", "web-1", "web", "node-1", corev1.PodRunning),
+		testPod("a", "web-2", "web", "node-2", corev1.PodRunning),
+	)
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &CheckDisruptionOptions{Client: client, IOStreams: streams}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "NAMESPACE   NAME   PODS   HEALTHY   ALLOWED   NODES   STATUS     REASONS\n" +
+		"a           db     2      2/2       0         2       Blocking   no disruption is allowed even with all pods healthy\n" +
+		"\n" +
+		"1 of 2 pod disruption budgets have a problem\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+
+	out.Reset()
+	o.All, o.Output = true, "json"
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte(`"status": "OK"`)) || !bytes.Contains(out.Bytes(), []byte(`"minAvailable": "2"`)) {
+		t.Errorf("unexpected JSON output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,29 @@
+package clean
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	cleanLong = templates.LongDesc(`
+		Clean up orphaned resources
+
+		This command finds the resources left behind by removed cluster components and deletes them.`)
+)
+
+func NewCmdClean(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "clean",
+		Short: "Clean up orphaned resources",
+		Long:  cleanLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdCleanNode(f, streams))
+	return cmds
+}
@@ -0,0 +1,351 @@
+package clean
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc/pkg/cli/admin/certificate"
+)
+
+var (
+	cleanNodeLong = templates.LongDesc(`
+		Find and delete the resources left behind by removed nodes.
+
+		The following resources are orphaned:
+
+		* Nodes that have not been ready for longer than --stale-after and that no machine refers
+		  to. Nodes with a machine are left to the machine API.
+		* Pending kubelet certificate signing requests older than --stale-after whose node does
+		  not exist or is stale.
+		* Machines whose node does not exist anymore and that have not been updated for longer
+		  than --stale-after. Control plane machines are never deleted, since deleting a machine
+		  also deprovisions its instance.
+		* Pods stuck terminating on a node that does not exist, which are deleted without waiting
+		  for the kubelet to confirm that their containers stopped. Pods on a stale node are left
+		  until the node is deleted, since its kubelet may still be running them.
+
+		The orphaned resources are listed first; they are only deleted with --confirm.
+	`)
+
+	cleanNodeExample = templates.Examples(`
+		# List the resources left behind by removed nodes
+		oc adm clean node
+
+		# Delete the resources left behind by nodes that have not been ready for a week
+		oc adm clean node --stale-after=168h --confirm
+	`)
+)
+
+var machinesResource = schema.GroupVersionResource{Group: "machine.openshift.io", Version: "v1beta1", Resource: "machines"}
+
+// machineRoleLabels are the labels that hold the role of a machine.
+var machineRoleLabels = []string{"machine.openshift.io/cluster-api-machine-role", "machine.openshift.io/cluster-api-machine-type"}
+
+// CleanNodeOptions holds the options to clean up the resources left behind by removed nodes.
+type CleanNodeOptions struct {
+	StaleAfter time.Duration
+	Confirm    bool
+
+	Client        kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewCleanNodeOptions(streams genericclioptions.IOStreams) *CleanNodeOptions {
+	return &CleanNodeOptions{
+		StaleAfter: 24 * time.Hour,
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdCleanNode creates a command that cleans up the resources left behind by removed nodes.
+func NewCmdCleanNode(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCleanNodeOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "node",
+		Short:   "Delete the resources left behind by removed nodes",
+		Long:    cleanNodeLong,
+		Example: cleanNodeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.StaleAfter, "stale-after", o.StaleAfter, "The time after which a node that is not ready, a pending certificate signing request, or a machine whose node does not exist, is stale.")
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, delete the orphaned resources. Defaults to false, listing what would be deleted without deleting anything.")
+
+	return cmd
+}
+
+func (o *CleanNodeOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.DynamicClient, err = dynamic.NewForConfig(config); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *CleanNodeOptions) Validate() error {
+	if o.StaleAfter <= 0 {
+		return fmt.Errorf("--stale-after must be greater than 0")
+	}
+	return nil
+}
+
+func (o *CleanNodeOptions) Run() error {
+	nodes, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	csrs, err := o.Client.CertificatesV1().CertificateSigningRequests().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := o.Client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var machines []unstructured.Unstructured
+	machineAPI := true
+	machineList, err := o.DynamicClient.Resource(machinesResource).Namespace(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		klog.V(2).Infof("The machine API is not available: %v", err)
+		machineAPI = false
+	case err != nil:
+		return err
+	default:
+		machines = machineList.Items
+	}
+
+	orphans := findOrphans(nodes.Items, csrs.Items, machines, machineAPI, pods.Items, time.Now(), o.StaleAfter)
+	if len(orphans) == 0 {
+		fmt.Fprintf(o.Out, "No orphaned resources found\n")
+		return nil
+	}
+	printOrphans(o.Out, orphans, time.Now())
+
+	if !o.Confirm {
+		fmt.Fprintf(o.Out, "\n%d orphaned resource(s) would be deleted, use --confirm to delete them\n", len(orphans))
+		return nil
+	}
+
+	errs := []error{}
+	deleted := 0
+	for _, orphan := range orphans {
+		if err := o.delete(orphan); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("unable to delete %s: %v", orphan.name(), err))
+			continue
+		}
+		deleted++
+	}
+	fmt.Fprintf(o.Out, "\n%d orphaned resource(s) deleted\n", deleted)
+	return utilerrors.NewAggregate(errs)
+}
+
+// delete deletes the orphaned resource. Pods are deleted immediately, since their node will never confirm that
+// their containers stopped.
+func (o *CleanNodeOptions) delete(orphan orphan) error {
+	switch orphan.Kind {
+	case "Pod":
+		zero := int64(0)
+		return o.Client.CoreV1().Pods(orphan.Namespace).Delete(context.TODO(), orphan.Name, metav1.DeleteOptions{GracePeriodSeconds: &zero})
+	case "CertificateSigningRequest":
+		return o.Client.CertificatesV1().CertificateSigningRequests().Delete(context.TODO(), orphan.Name, metav1.DeleteOptions{})
+	case "Machine":
+		return o.DynamicClient.Resource(machinesResource).Namespace(orphan.Namespace).Delete(context.TODO(), orphan.Name, metav1.DeleteOptions{})
+	case "Node":
+		return o.Client.CoreV1().Nodes().Delete(context.TODO(), orphan.Name, metav1.DeleteOptions{})
+	}
+	return fmt.Errorf("unknown kind %s", orphan.Kind)
+}
+
+// orphan is a resource left behind by a removed node.
+type orphan struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Created   time.Time
+	Reason    string
+}
+
+func (o orphan) name() string {
+	if len(o.Namespace) > 0 {
+		return fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+	}
+	return fmt.Sprintf("%s %s", o.Kind, o.Name)
+}
+
+// kindOrder is the order in which the orphaned resources are deleted: the resources that refer to a node first.
+var kindOrder = map[string]int{"Pod": 0, "CertificateSigningRequest": 1, "Machine": 2, "Node": 3}
+
+// findOrphans returns the orphaned resources at now. Without the machine API, nodes are stale regardless of
+// machines.
+func findOrphans(nodes []corev1.Node, csrs []certificatesv1.CertificateSigningRequest, machines []unstructured.Unstructured, machineAPI bool, pods []corev1.Pod, now time.Time, staleAfter time.Duration) []orphan {
+	var orphans []orphan
+
+	machineNodes := sets.NewString()
+	for _, machine := range machines {
+		if nodeName, _, _ := unstructured.NestedString(machine.Object, "status", "nodeRef", "name"); len(nodeName) > 0 {
+			machineNodes.Insert(nodeName)
+		}
+	}
+
+	existing, stale := sets.NewString(), sets.NewString()
+	for i := range nodes {
+		node := &nodes[i]
+		existing.Insert(node.Name)
+		notReady, since := notReadySince(node)
+		if !notReady || now.Sub(since) < staleAfter {
+			continue
+		}
+		stale.Insert(node.Name)
+		if machineNodes.Has(node.Name) {
+			continue
+		}
+		reason := fmt.Sprintf("not ready for %s", duration.HumanDuration(now.Sub(since)))
+		if machineAPI {
+			reason += ", no machine refers to it"
+		}
+		orphans = append(orphans, orphan{Kind: "Node", Name: node.Name, Created: node.CreationTimestamp.Time, Reason: reason})
+	}
+	// gone returns the reason a node is gone, or an empty string if it is not
+	gone := func(nodeName string) string {
+		switch {
+		case !existing.Has(nodeName):
+			return fmt.Sprintf("node %s does not exist", nodeName)
+		case stale.Has(nodeName):
+			return fmt.Sprintf("node %s is stale", nodeName)
+		}
+		return ""
+	}
+
+	for i := range csrs {
+		csr := &csrs[i]
+		nodeName := certificate.NodeName(csr)
+		if len(nodeName) == 0 || !certificate.IsPending(csr) || now.Sub(csr.CreationTimestamp.Time) < staleAfter {
+			continue
+		}
+		if reason := gone(nodeName); len(reason) > 0 {
+			orphans = append(orphans, orphan{Kind: "CertificateSigningRequest", Name: csr.Name, Created: csr.CreationTimestamp.Time, Reason: "pending, " + reason})
+		}
+	}
+
+	for i := range machines {
+		machine := &machines[i]
+		nodeName, _, _ := unstructured.NestedString(machine.Object, "status", "nodeRef", "name")
+		if len(nodeName) == 0 || machine.GetDeletionTimestamp() != nil || existing.Has(nodeName) || isControlPlaneMachine(machine) {
+			continue
+		}
+		if now.Sub(machineUpdated(machine)) < staleAfter {
+			continue
+		}
+		orphans = append(orphans, orphan{Kind: "Machine", Namespace: machine.GetNamespace(), Name: machine.GetName(), Created: machine.GetCreationTimestamp().Time, Reason: fmt.Sprintf("node %s does not exist", nodeName)})
+	}
+
+	// pods are deleted without waiting for their kubelet, which is only safe once their node is gone
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp == nil || len(pod.Spec.NodeName) == 0 || now.Before(pod.DeletionTimestamp.Time) || existing.Has(pod.Spec.NodeName) {
+			continue
+		}
+		orphans = append(orphans, orphan{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name, Created: pod.CreationTimestamp.Time, Reason: fmt.Sprintf("terminating, node %s does not exist", pod.Spec.NodeName)})
+	}
+
+	sort.SliceStable(orphans, func(i, j int) bool {
+		a, b := orphans[i], orphans[j]
+		if kindOrder[a.Kind] != kindOrder[b.Kind] {
+			return kindOrder[a.Kind] < kindOrder[b.Kind]
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return orphans
+}
+
+// isControlPlaneMachine returns true if the machine runs the control plane.
+func isControlPlaneMachine(machine *unstructured.Unstructured) bool {
+	labels := machine.GetLabels()
+	for _, label := range machineRoleLabels {
+		if role := labels[label]; role == "master" || role == "control-plane" {
+			return true
+		}
+	}
+	return false
+}
+
+// machineUpdated returns when the machine API last updated the status of the machine, or its creation if it never
+// did.
+func machineUpdated(machine *unstructured.Unstructured) time.Time {
+	if value, _, _ := unstructured.NestedString(machine.Object, "status", "lastUpdated"); len(value) > 0 {
+		if updated, err := time.Parse(time.RFC3339, value); err == nil {
+			return updated
+		}
+	}
+	return machine.GetCreationTimestamp().Time
+}
+
+// notReadySince returns whether the node is not ready, and since when: the last heartbeat of its Ready condition,
+// or its creation if it never reported it.
+func notReadySince(node *corev1.Node) (bool, time.Time) {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		if condition.Status == corev1.ConditionTrue {
+			return false, time.Time{}
+		}
+		if !condition.LastHeartbeatTime.IsZero() {
+			return true, condition.LastHeartbeatTime.Time
+		}
+		break
+	}
+	return true, node.CreationTimestamp.Time
+}
+
+// printOrphans prints a table of the orphaned resources.
+func printOrphans(out io.Writer, orphans []orphan, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAMESPACE\tNAME\tAGE\tREASON")
+	for _, o := range orphans {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", o.Kind, o.Namespace, o.Name, duration.HumanDuration(now.Sub(o.Created)), o.Reason)
+	}
+	w.Flush()
+}
@@ -0,0 +1,167 @@
+package clean
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testNow = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+func testNode(name string, ready corev1.ConditionStatus, heartbeat time.Time) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(testNow.Add(-30 * 24 * time.Hour))},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: ready, LastHeartbeatTime: metav1.NewTime(heartbeat)},
+		}},
+	}
+}
+
+func testCSR(name, node string, created time.Time, approved bool) *certificatesv1.CertificateSigningRequest {
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       certificatesv1.CertificateSigningRequestSpec{SignerName: certificatesv1.KubeletServingSignerName, Username: "system:node:" + node},
+	}
+	if approved {
+		csr.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{{Type: certificatesv1.CertificateApproved, Status: corev1.ConditionTrue}}
+	}
+	return csr
+}
+
+func testMachine(name, node string, updated time.Time, role string) *unstructured.Unstructured {
+	machine := &unstructured.Unstructured{}
+	machine.SetAPIVersion("machine.openshift.io/v1beta1")
+	machine.SetKind("Machine")
+	machine.SetNamespace("openshift-machine-api")
+	machine.SetName(name)
+	machine.SetCreationTimestamp(metav1.NewTime(testNow.Add(-30 * 24 * time.Hour)))
+	machine.SetLabels(map[string]string{"machine.openshift.io/cluster-api-machine-role": role})
+	unstructured.SetNestedField(machine.Object, updated.Format(time.RFC3339), "status", "lastUpdated")
+	if len(node) > 0 {
+		unstructured.SetNestedField(machine.Object, node, "status", "nodeRef", "name")
+	}
+	return machine
+}
+
+func testPod(name, node string, deleted *time.Time) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: name, CreationTimestamp: metav1.NewTime(testNow.Add(-10 * 24 * time.Hour))},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+	if deleted != nil {
+		pod.DeletionTimestamp = &metav1.Time{Time: *deleted}
+	}
+	return pod
+}
+
+func TestFindOrphans(t *testing.T) {
+	old, recent, grace := testNow.Add(-48*time.Hour), testNow.Add(-time.Hour), testNow.Add(time.Minute)
+	nodes := []corev1.Node{
+		*testNode("ready", corev1.ConditionTrue, recent),
+		*testNode("stale", corev1.ConditionUnknown, old),
+		*testNode("stale-machine", corev1.ConditionUnknown, old),
+		*testNode("not-ready", corev1.ConditionFalse, recent),
+	}
+	csrs := []certificatesv1.CertificateSigningRequest{
+		*testCSR("csr-gone", "gone", old, false),
+		*testCSR("csr-stale", "stale", old, false),
+		*testCSR("csr-recent", "gone", recent, false),
+		*testCSR("csr-approved", "gone", old, true),
+		*testCSR("csr-ready", "ready", old, false),
+	}
+	machines := []unstructured.Unstructured{
+		*testMachine("machine-gone", "gone", old, "worker"),
+		*testMachine("machine-gone-recently", "gone", recent, "worker"),
+		*testMachine("machine-master", "gone", old, "master"),
+		*testMachine("machine-stale", "stale-machine", old, "worker"),
+		*testMachine("machine-provisioning", "", old, "worker"),
+	}
+	pods := []corev1.Pod{
+		*testPod("terminating-gone", "gone", &old),
+		*testPod("terminating-stale", "stale", &old),
+		*testPod("terminating-ready", "ready", &old),
+		*testPod("terminating-grace", "gone", &grace),
+		*testPod("running-gone", "gone", nil),
+	}
+
+	summarize := func(orphans []orphan) []string {
+		var names []string
+		for _, o := range orphans {
+			names = append(names, o.name()+": "+o.Reason)
+		}
+		return names
+	}
+
+	expected := []string{
+		"Pod app/terminating-gone: terminating, node gone does not exist",
+		"CertificateSigningRequest csr-gone: pending, node gone does not exist",
+		"CertificateSigningRequest csr-stale: pending, node stale is stale",
+		"Machine openshift-machine-api/machine-gone: node gone does not exist",
+		"Node stale: not ready for 2d, no machine refers to it",
+	}
+	if got := summarize(findOrphans(nodes, csrs, machines, true, pods, testNow, 24*time.Hour)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected orphans:\n%v\nexpected:\n%v", got, expected)
+	}
+
+	// without the machine API, every stale node is orphaned
+	expected = []string{
+		"Pod app/terminating-gone: terminating, node gone does not exist",
+		"CertificateSigningRequest csr-gone: pending, node gone does not exist",
+		"CertificateSigningRequest csr-stale: pending, node stale is stale",
+		"Node stale: not ready for 2d",
+		"Node stale-machine: not ready for 2d",
+	}
+	if got := summarize(findOrphans(nodes, csrs, nil, false, pods, testNow, 24*time.Hour)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected orphans without the machine API:\n%v\nexpected:\n%v", got, expected)
+	}
+}
+
+func TestRun(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	client := fake.NewSimpleClientset(
+		testNode("stale", corev1.ConditionUnknown, old),
+		testCSR("csr-stale", "stale", old, false),
+		testPod("terminating-stale", "stale", &old),
+		testPod("terminating-gone", "gone", &old),
+	)
+	scheme := runtime.NewScheme()
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{machinesResource: "MachineList"}, testMachine("machine-gone", "gone", old, "worker"))
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &CleanNodeOptions{StaleAfter: 24 * time.Hour, Client: client, DynamicClient: dynamicClient, IOStreams: streams}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(client.Actions()); got != 3 {
+		t.Errorf("expected only the 3 lists without --confirm, got %d actions", got)
+	}
+
+	out.Reset()
+	o.Confirm = true
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var deleted []string
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "delete" {
+			deleted = append(deleted, action.GetResource().Resource)
+		}
+	}
+	if expected := []string{"pods", "certificatesigningrequests", "nodes"}; !reflect.DeepEqual(deleted, expected) {
+		t.Errorf("deleted %v, expected %v", deleted, expected)
+	}
+	if _, err := dynamicClient.Resource(machinesResource).Namespace("openshift-machine-api").Get(context.TODO(), "machine-gone", metav1.GetOptions{}); err == nil {
+		t.Errorf("the machine was not deleted")
+	}
+}
@@ -0,0 +1,31 @@
+package clusteroperator
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	clusterOperatorLong = templates.LongDesc(`
+		Inspect cluster operators
+
+		These commands gather the state of a cluster operator from its status, events and pods
+		to help the triage of a degraded or unavailable operator.`)
+)
+
+func NewCmdClusterOperator(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:     "co",
+		Aliases: []string{"clusteroperator"},
+		Short:   "Inspect cluster operators",
+		Long:    clusterOperatorLong,
+		Run:     kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdStatus(f, streams))
+	return cmds
+}
@@ -0,0 +1,357 @@
+package clusteroperator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	eventhelpers "github.com/openshift/oc/pkg/helpers/events"
+)
+
+var (
+	statusLong = templates.LongDesc(`
+		Print the state of a cluster operator in one view.
+
+		The view is made of:
+
+		* The versions and the conditions of the operator, the most recently changed first.
+		* The changes of the conditions reported by the operator with OperatorStatusChanged events,
+		  which give the history of the conditions while the events are retained.
+		* The objects related to the operator.
+		* The most recent events of the namespaces of the related objects.
+		* The last error lines logged by the operator pods, which are the pods of the related
+		  namespaces whose namespace or name contains "operator".
+
+		The pod logs are not read with --error-lines=0.
+	`)
+
+	statusExample = templates.Examples(`
+		# Print the state of the authentication operator
+		oc adm co status authentication
+
+		# Print the state of the etcd operator with the 30 most recent events
+		oc adm co status etcd --events=30
+	`)
+)
+
+const (
+	// statusChangedReason is the reason of the events emitted by the operators when their conditions change.
+	statusChangedReason = "OperatorStatusChanged"
+	// logScanLines is the number of the last lines of the logs of an operator container searched for errors.
+	logScanLines = 2000
+)
+
+// errorLineRegexp matches the lines logged with an error or fatal severity by klog and logrus.
+var errorLineRegexp = regexp.MustCompile(`^[EF]\d{4} |level=(error|fatal)|"level":"(error|fatal)"`)
+
+// StatusOptions holds the options to print the state of a cluster operator.
+type StatusOptions struct {
+	Name       string
+	Events     int
+	ErrorLines int
+
+	ConfigClient configv1client.Interface
+	KubeClient   kubernetes.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewStatusOptions(streams genericclioptions.IOStreams) *StatusOptions {
+	return &StatusOptions{
+		Events:     10,
+		ErrorLines: 5,
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdStatus creates a command that prints the state of a cluster operator.
+func NewCmdStatus(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewStatusOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "status NAME",
+		Short:   "Print the conditions, related objects, events and errors of a cluster operator",
+		Long:    statusLong,
+		Example: statusExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().IntVar(&o.Events, "events", o.Events, "The number of most recent events to print.")
+	cmd.Flags().IntVar(&o.ErrorLines, "error-lines", o.ErrorLines, "The number of last error lines to print for each operator container.")
+
+	return cmd
+}
+
+func (o *StatusOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "exactly one cluster operator name is required")
+	}
+	o.Name = args[0]
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.ConfigClient, err = configv1client.NewForConfig(config); err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(config)
+	return err
+}
+
+func (o *StatusOptions) Validate() error {
+	if o.Events < 0 {
+		return fmt.Errorf("--events may not be negative")
+	}
+	if o.ErrorLines < 0 {
+		return fmt.Errorf("--error-lines may not be negative")
+	}
+	return nil
+}
+
+func (o *StatusOptions) Run() error {
+	ctx := context.TODO()
+	co, err := o.ConfigClient.ConfigV1().ClusterOperators().Get(ctx, o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	namespaces := relatedNamespaces(co)
+	var events []corev1.Event
+	for _, namespace := range namespaces {
+		list, err := o.KubeClient.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to list the events of namespace %s: %v", namespace, err)
+		}
+		events = append(events, list.Items...)
+	}
+	changes, others := splitStatusChanges(co.Name, events)
+
+	now := time.Now()
+	printOperator(o.Out, co, now)
+
+	fmt.Fprintf(o.Out, "\nCondition changes:\n")
+	if len(changes) == 0 {
+		fmt.Fprintf(o.Out, "  No %s event retained.\n", statusChangedReason)
+	} else {
+		w := tabwriter.NewWriter(o.Out, 0, 8, 3, ' ', 0)
+		fmt.Fprintln(w, "  LAST SEEN\tCHANGE")
+		for _, event := range changes {
+			fmt.Fprintf(w, "  %s\t%s\n", age(now, eventhelpers.LastTime(&event)), statusChange(co.Name, event.Message))
+		}
+		w.Flush()
+	}
+
+	fmt.Fprintf(o.Out, "\nRelated objects:\n")
+	if len(co.Status.RelatedObjects) == 0 {
+		fmt.Fprintf(o.Out, "  None.\n")
+	} else {
+		w := tabwriter.NewWriter(o.Out, 0, 8, 3, ' ', 0)
+		fmt.Fprintln(w, "  RESOURCE\tNAMESPACE\tNAME")
+		for _, ref := range co.Status.RelatedObjects {
+			resource := ref.Resource
+			if len(ref.Group) > 0 {
+				resource += "." + ref.Group
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", resource, ref.Namespace, ref.Name)
+		}
+		w.Flush()
+	}
+
+	if o.Events > 0 {
+		if len(others) > o.Events {
+			others = others[len(others)-o.Events:]
+		}
+		fmt.Fprintf(o.Out, "\nEvents:\n")
+		if len(others) == 0 {
+			fmt.Fprintf(o.Out, "  No event in the related namespaces.\n")
+		} else {
+			printEvents(o.Out, others, now)
+		}
+	}
+
+	if o.ErrorLines > 0 {
+		fmt.Fprintf(o.Out, "\nOperator logs:\n")
+		return o.printOperatorErrors(ctx, namespaces)
+	}
+	return nil
+}
+
+// printOperatorErrors prints the last error lines of the containers of the operator pods.
+func (o *StatusOptions) printOperatorErrors(ctx context.Context, namespaces []string) error {
+	var pods []corev1.Pod
+	for _, namespace := range namespaces {
+		list, err := o.KubeClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to list the pods of namespace %s: %v", namespace, err)
+		}
+		for _, pod := range list.Items {
+			if strings.Contains(pod.Namespace, "operator") || strings.Contains(pod.Name, "operator") {
+				pods = append(pods, pod)
+			}
+		}
+	}
+	if len(pods) == 0 {
+		fmt.Fprintf(o.Out, "  No operator pod in the related namespaces.\n")
+		return nil
+	}
+
+	tailLines := int64(logScanLines)
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			fmt.Fprintf(o.Out, "  pod/%s -n %s -c %s: ", pod.Name, pod.Namespace, container.Name)
+			logs, err := o.KubeClient.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container.Name, TailLines: &tailLines}).Stream(ctx)
+			if err != nil {
+				fmt.Fprintf(o.Out, "unable to read the logs: %v\n", err)
+				continue
+			}
+			lines, err := lastErrorLines(logs, o.ErrorLines)
+			logs.Close()
+			if err != nil {
+				fmt.Fprintf(o.Out, "unable to read the logs: %v\n", err)
+				continue
+			}
+			if len(lines) == 0 {
+				fmt.Fprintf(o.Out, "no error in the last %d lines\n", logScanLines)
+				continue
+			}
+			fmt.Fprintf(o.Out, "last %d error line(s)\n", len(lines))
+			for _, line := range lines {
+				fmt.Fprintf(o.Out, "    %s\n", line)
+			}
+		}
+	}
+	return nil
+}
+
+// relatedNamespaces returns the namespaces of the objects related to the operator, sorted.
+func relatedNamespaces(co *configv1.ClusterOperator) []string {
+	namespaces := sets.NewString()
+	for _, ref := range co.Status.RelatedObjects {
+		switch {
+		case ref.Group == "" && ref.Resource == "namespaces":
+			namespaces.Insert(ref.Name)
+		case len(ref.Namespace) > 0:
+			namespaces.Insert(ref.Namespace)
+		}
+	}
+	return namespaces.List()
+}
+
+// splitStatusChanges returns the events reporting a change of the conditions of the operator, and the other events,
+// both sorted from the oldest to the most recent.
+func splitStatusChanges(name string, events []corev1.Event) ([]corev1.Event, []corev1.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventhelpers.LastTime(&events[i]).Before(eventhelpers.LastTime(&events[j]))
+	})
+	var changes, others []corev1.Event
+	for _, event := range events {
+		if event.Reason == statusChangedReason {
+			if strings.Contains(event.Message, "clusteroperator/"+name+" ") {
+				changes = append(changes, event)
+			}
+			continue
+		}
+		others = append(others, event)
+	}
+	return changes, others
+}
+
+// statusChange returns the change reported by an OperatorStatusChanged event, without the operator name.
+func statusChange(name, message string) string {
+	prefix := fmt.Sprintf("Status for clusteroperator/%s changed: ", name)
+	return strings.TrimSpace(strings.TrimPrefix(message, prefix))
+}
+
+// lastErrorLines returns the last max lines of the logs logged with an error severity.
+func lastErrorLines(logs io.Reader, max int) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(logs)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !errorLineRegexp.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) > max {
+			lines = lines[1:]
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// printOperator prints the versions and the conditions of the operator, the most recently changed first.
+func printOperator(out io.Writer, co *configv1.ClusterOperator, now time.Time) {
+	fmt.Fprintf(out, "Name: %s\n", co.Name)
+	var versions []string
+	for _, version := range co.Status.Versions {
+		versions = append(versions, version.Name+"="+version.Version)
+	}
+	if len(versions) == 0 {
+		versions = []string{"<none>"}
+	}
+	fmt.Fprintf(out, "Versions: %s\n", strings.Join(versions, ", "))
+
+	fmt.Fprintf(out, "\nConditions:\n")
+	if len(co.Status.Conditions) == 0 {
+		fmt.Fprintf(out, "  None reported.\n")
+		return
+	}
+	conditions := append([]configv1.ClusterOperatorStatusCondition(nil), co.Status.Conditions...)
+	sort.SliceStable(conditions, func(i, j int) bool {
+		return conditions[i].LastTransitionTime.After(conditions[j].LastTransitionTime.Time)
+	})
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tSINCE\tREASON\tMESSAGE")
+	for _, condition := range conditions {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", condition.Type, condition.Status, age(now, condition.LastTransitionTime.Time), condition.Reason, oneLine(condition.Message))
+	}
+	w.Flush()
+}
+
+// printEvents prints a table of the events.
+func printEvents(out io.Writer, events []corev1.Event, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "  LAST SEEN\tNAMESPACE\tTYPE\tREASON\tOBJECT\tMESSAGE")
+	for _, event := range events {
+		object := strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n", age(now, eventhelpers.LastTime(&event)), event.Namespace, event.Type, event.Reason, object, oneLine(event.Message))
+	}
+	w.Flush()
+}
+
+// age returns how long ago the time was, or <unknown> if it is not set.
+func age(now, t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(now.Sub(t))
+}
+
+// oneLine joins the lines of a message so that it fits in a table row.
+func oneLine(message string) string {
+	return strings.Join(strings.Fields(message), " ")
+}
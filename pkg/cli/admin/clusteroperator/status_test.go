@@ -0,0 +1,144 @@
+package clusteroperator
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestRelatedNamespaces(t *testing.T) {
+	co := &configv1.ClusterOperator{
+		Status: configv1.ClusterOperatorStatus{
+			RelatedObjects: []configv1.ObjectReference{
+				{Resource: "namespaces", Name: "openshift-authentication-operator"},
+				{Resource: "namespaces", Name: "openshift-authentication"},
+				{Group: "operator.openshift.io", Resource: "authentications", Name: "cluster"},
+				{Resource: "secrets", Namespace: "openshift-config", Name: "v4-0-config-system-session"},
+				{Resource: "configmaps", Namespace: "openshift-authentication", Name: "v4-0-config-system-cliconfig"},
+			},
+		},
+	}
+	want := []string{"openshift-authentication", "openshift-authentication-operator", "openshift-config"}
+	if got := relatedNamespaces(co); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func testEvent(reason, message string, at time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "openshift-authentication-operator"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Name: "authentication-operator"},
+		Type:           corev1.EventTypeNormal,
+		Reason:         reason,
+		Message:        message,
+		LastTimestamp:  metav1.NewTime(at),
+	}
+}
+
+func TestSplitStatusChanges(t *testing.T) {
+	now := time.Now()
+	events := []corev1.Event{
+		testEvent(statusChangedReason, "Status for clusteroperator/authentication changed: Available changed from False to True", now.Add(-time.Minute)),
+		testEvent("LeaderElection", "authentication-operator became leader", now.Add(-time.Hour)),
+		testEvent(statusChangedReason, "Status for clusteroperator/authentication changed: Degraded changed from False to True (\"OAuthServerDeployment_Unavailable\")", now.Add(-2*time.Hour)),
+		testEvent(statusChangedReason, "Status for clusteroperator/authentication-other changed: Degraded changed from True to False", now.Add(-3*time.Hour)),
+	}
+	changes, others := splitStatusChanges("authentication", events)
+
+	var got []string
+	for _, event := range changes {
+		got = append(got, statusChange("authentication", event.Message))
+	}
+	want := []string{
+		"Degraded changed from False to True (\"OAuthServerDeployment_Unavailable\")",
+		"Available changed from False to True",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got changes %q, want %q", got, want)
+	}
+	if len(others) != 1 || others[0].Reason != "LeaderElection" {
+		t.Errorf("unexpected other events: %v", others)
+	}
+}
+
+func TestLastErrorLines(t *testing.T) {
+	logs := `I1015 10:00:00.000000       1 controller.go:10] started
+E1015 10:00:01.000000       1 controller.go:20] first error
+W1015 10:00:02.000000       1 controller.go:30] a warning
+E1015 10:00:03.000000       1 controller.go:40] second error
+time="2023-10-15T10:00:04Z" level=error msg="third error"
+{"level":"info","msg":"an error that is not one"}
+F1015 10:00:05.000000       1 main.go:50] fatal error
+`
+	got, err := lastErrorLines(strings.NewReader(logs), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"E1015 10:00:03.000000       1 controller.go:40] second error",
+		`time="2023-10-15T10:00:04Z" level=error msg="third error"`,
+		"F1015 10:00:05.000000       1 main.go:50] fatal error",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintOperator(t *testing.T) {
+	now := time.Now()
+	co := &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: "authentication"},
+		Status: configv1.ClusterOperatorStatus{
+			Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorAvailable, Status: configv1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-48 * time.Hour)), Reason: "AsExpected", Message: "All is well"},
+				{Type: configv1.OperatorDegraded, Status: configv1.ConditionTrue, LastTransitionTime: metav1.NewTime(now.Add(-5 * time.Minute)), Reason: "OAuthServerDeployment_Unavailable", Message: "the deployment\nhas no available replica"},
+			},
+			Versions: []configv1.OperandVersion{{Name: "operator", Version: "4.14.0"}, {Name: "oauth-openshift", Version: "4.14.0_openshift"}},
+		},
+	}
+	out := &bytes.Buffer{}
+	printOperator(out, co, now)
+	want := `Name: authentication
+Versions: operator=4.14.0, oauth-openshift=4.14.0_openshift
+
+Conditions:
+  TYPE        STATUS   SINCE   REASON                              MESSAGE
+  Degraded    True     5m      OAuthServerDeployment_Unavailable   the deployment has no available replica
+  Available   True     2d      AsExpected                          All is well
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPrintOperatorErrors(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-etcd-operator", Name: "etcd-operator-abc"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "etcd-operator"}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "openshift-etcd", Name: "etcd-master-0"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "etcd"}}},
+		},
+	)
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &StatusOptions{ErrorLines: 5, KubeClient: client, IOStreams: streams}
+	if err := o.printOperatorErrors(context.TODO(), []string{"openshift-etcd", "openshift-etcd-operator"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "  pod/etcd-operator-abc -n openshift-etcd-operator -c etcd-operator: no error in the last 2000 lines\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,32 @@
+package compliance
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	complianceLong = templates.LongDesc(`
+		Capture the security settings of the cluster
+
+		These commands gather the settings that make the security posture of a cluster, such as
+		who may use the security context constraints, the admission plugins, the audit and TLS
+		profiles of the API servers and the network policies of the namespaces, so that they can
+		be reviewed and compared between clusters or over time.`)
+)
+
+func NewCmdCompliance(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "compliance",
+		Short: "Capture the security settings of the cluster",
+		Long:  complianceLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdSnapshot(f, streams))
+	return cmds
+}
@@ -0,0 +1,477 @@
+package compliance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/component-helpers/auth/rbac/validation"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	securityv1 "github.com/openshift/api/security/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	securityv1client "github.com/openshift/client-go/security/clientset/versioned/typed/security/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	snapshotLong = templates.LongDesc(`
+		Capture the security settings of the cluster as a JSON or YAML document
+
+		The snapshot holds:
+
+		* the security context constraints, with their main settings and the users, groups and
+		  role bindings that allow to use them
+		* the admission plugins enabled and disabled on the Kubernetes API server, and their
+		  configuration
+		* the audit profile and the effective TLS security profile of the API servers
+		* for every namespace, its network policies and whether they deny the ingress or egress
+		  traffic by default
+
+		The lists are sorted so that two snapshots can be compared with diff, between clusters or
+		over time. The settings that cannot be read with the permissions of the current user are
+		left out of the snapshot and listed in its warnings. The snapshot is printed as JSON unless
+		-o yaml is given.
+	`)
+
+	snapshotExample = templates.Examples(`
+		# Capture the security settings of the cluster
+		oc adm compliance snapshot > snapshot.json
+
+		# Compare the security settings of two clusters
+		oc adm compliance snapshot --context=prod > prod.json
+		oc adm compliance snapshot --context=staging > staging.json
+		diff prod.json staging.json
+	`)
+)
+
+const (
+	// kubeAPIServerNamespace and kubeAPIServerConfigMap hold the configuration of the Kubernetes API servers
+	kubeAPIServerNamespace = "openshift-kube-apiserver"
+	kubeAPIServerConfigMap = "config"
+)
+
+// SnapshotOptions holds the options to capture the security settings of the cluster.
+type SnapshotOptions struct {
+	Client         kubernetes.Interface
+	ConfigClient   configv1client.Interface
+	SecurityClient securityv1client.SecurityV1Interface
+
+	Output string
+
+	genericclioptions.IOStreams
+}
+
+func NewSnapshotOptions(streams genericclioptions.IOStreams) *SnapshotOptions {
+	return &SnapshotOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdSnapshot creates a command that captures the security settings of the cluster.
+func NewCmdSnapshot(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewSnapshotOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "snapshot",
+		Short:   "Capture the security settings of the cluster as JSON or YAML",
+		Long:    snapshotLong,
+		Example: snapshotExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+	return cmd
+}
+
+func (o *SnapshotOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.ConfigClient, err = configv1client.NewForConfig(config); err != nil {
+		return err
+	}
+	o.SecurityClient, err = securityv1client.NewForConfig(config)
+	return err
+}
+
+func (o *SnapshotOptions) Validate() error {
+	switch o.Output {
+	case "", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("--output must be one of: json, yaml")
+}
+
+// snapshot is the security settings of a cluster.
+type snapshot struct {
+	ClusterID                  string                    `json:"clusterID,omitempty"`
+	CapturedAt                 time.Time                 `json:"capturedAt"`
+	SecurityContextConstraints []sccSettings             `json:"securityContextConstraints"`
+	Admission                  *admissionSettings        `json:"admission,omitempty"`
+	AuditProfile               configv1.AuditProfileType `json:"auditProfile,omitempty"`
+	TLSSecurityProfile         *tlsSettings              `json:"tlsSecurityProfile,omitempty"`
+	NetworkPolicies            []namespacePolicies       `json:"networkPolicies"`
+	// Warnings are the settings that could not be captured.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// sccSettings are the main settings of security context constraints and who may use them.
+type sccSettings struct {
+	Name                     string                                `json:"name"`
+	Priority                 *int32                                `json:"priority,omitempty"`
+	AllowPrivilegedContainer bool                                  `json:"allowPrivilegedContainer"`
+	AllowPrivilegeEscalation *bool                                 `json:"allowPrivilegeEscalation,omitempty"`
+	AllowHostNetwork         bool                                  `json:"allowHostNetwork"`
+	AllowHostPorts           bool                                  `json:"allowHostPorts"`
+	AllowHostPID             bool                                  `json:"allowHostPID"`
+	AllowHostIPC             bool                                  `json:"allowHostIPC"`
+	RunAsUser                securityv1.RunAsUserStrategyType      `json:"runAsUser"`
+	SELinuxContext           securityv1.SELinuxContextStrategyType `json:"seLinuxContext"`
+	Volumes                  []securityv1.FSType                   `json:"volumes,omitempty"`
+	// Users and Groups are the users and groups that are given the constraints directly.
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	// Bindings are the role bindings that allow their subjects to use the constraints.
+	Bindings []sccBinding `json:"bindings,omitempty"`
+}
+
+// sccBinding is a role binding whose role allows to use security context constraints.
+type sccBinding struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace,omitempty"`
+	Name      string   `json:"name"`
+	Role      string   `json:"role"`
+	Subjects  []string `json:"subjects"`
+}
+
+// admissionSettings are the admission plugins of the Kubernetes API server.
+type admissionSettings struct {
+	EnabledPlugins  []string                                  `json:"enabledPlugins,omitempty"`
+	DisabledPlugins []string                                  `json:"disabledPlugins,omitempty"`
+	PluginConfig    map[string]configv1.AdmissionPluginConfig `json:"pluginConfig,omitempty"`
+}
+
+// tlsSettings is the effective TLS security profile of the API servers.
+type tlsSettings struct {
+	Type          configv1.TLSProfileType     `json:"type"`
+	MinTLSVersion configv1.TLSProtocolVersion `json:"minTLSVersion,omitempty"`
+	Ciphers       []string                    `json:"ciphers,omitempty"`
+}
+
+// namespacePolicies are the network policies of a namespace.
+type namespacePolicies struct {
+	Namespace          string   `json:"namespace"`
+	Policies           []string `json:"policies,omitempty"`
+	DefaultDenyIngress bool     `json:"defaultDenyIngress"`
+	DefaultDenyEgress  bool     `json:"defaultDenyEgress"`
+}
+
+func (o *SnapshotOptions) Run() error {
+	s := &snapshot{CapturedAt: time.Now().UTC()}
+
+	clusterVersion, err := o.ConfigClient.ConfigV1().ClusterVersions().Get(context.TODO(), "version", metav1.GetOptions{})
+	if err == nil {
+		s.ClusterID = string(clusterVersion.Spec.ClusterID)
+	} else if err := s.skip("the cluster ID", err); err != nil {
+		return err
+	}
+
+	apiServer, err := o.ConfigClient.ConfigV1().APIServers().Get(context.TODO(), "cluster", metav1.GetOptions{})
+	if err == nil {
+		s.AuditProfile, s.TLSSecurityProfile = apiServerSettings(apiServer)
+	} else if err := s.skip("the audit and TLS security profiles", err); err != nil {
+		return err
+	}
+
+	configMap, err := o.Client.CoreV1().ConfigMaps(kubeAPIServerNamespace).Get(context.TODO(), kubeAPIServerConfigMap, metav1.GetOptions{})
+	if err == nil {
+		if s.Admission, err = admission(configMap); err != nil {
+			return err
+		}
+	} else if err := s.skip("the admission plugins", err); err != nil {
+		return err
+	}
+
+	if err := o.captureSCCs(s); err != nil {
+		if err := s.skip("the security context constraints", err); err != nil {
+			return err
+		}
+	}
+
+	if err := o.captureNetworkPolicies(s); err != nil {
+		if err := s.skip("the network policies", err); err != nil {
+			return err
+		}
+	}
+
+	for _, warning := range s.Warnings {
+		fmt.Fprintf(o.ErrOut, "warning: %s\n", warning)
+	}
+	return cmdutil.PrintJSONOrYAML(o.Out, o.Output, s)
+}
+
+// skip records a warning for the settings the current user cannot read, and returns the other errors.
+func (s *snapshot) skip(settings string, err error) error {
+	if !kerrors.IsForbidden(err) && !kerrors.IsNotFound(err) {
+		return err
+	}
+	s.Warnings = append(s.Warnings, fmt.Sprintf("unable to capture %s: %v", settings, err))
+	return nil
+}
+
+func (o *SnapshotOptions) captureSCCs(s *snapshot) error {
+	sccs, err := o.SecurityClient.SecurityContextConstraints().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	roles, err := o.Client.RbacV1().Roles(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	clusterRoles, err := o.Client.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	roleBindings, err := o.Client.RbacV1().RoleBindings(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	clusterRoleBindings, err := o.Client.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	s.SecurityContextConstraints = sccAssignments(sccs.Items, roles.Items, clusterRoles.Items, roleBindings.Items, clusterRoleBindings.Items)
+	return nil
+}
+
+func (o *SnapshotOptions) captureNetworkPolicies(s *snapshot) error {
+	namespaces, err := o.Client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	policies, err := o.Client.NetworkingV1().NetworkPolicies(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	s.NetworkPolicies = networkPolicies(namespaces.Items, policies.Items)
+	return nil
+}
+
+// apiServerSettings returns the audit profile and the effective TLS security profile of the API servers, which
+// default to the Default and Intermediate profiles.
+func apiServerSettings(apiServer *configv1.APIServer) (configv1.AuditProfileType, *tlsSettings) {
+	auditProfile := apiServer.Spec.Audit.Profile
+	if len(auditProfile) == 0 {
+		auditProfile = configv1.DefaultAuditProfileType
+	}
+
+	tls := &tlsSettings{Type: configv1.TLSProfileIntermediateType}
+	profile := apiServer.Spec.TLSSecurityProfile
+	if profile != nil && len(profile.Type) > 0 {
+		tls.Type = profile.Type
+	}
+	spec := configv1.TLSProfiles[tls.Type]
+	if tls.Type == configv1.TLSProfileCustomType && profile.Custom != nil {
+		spec = &profile.Custom.TLSProfileSpec
+	}
+	if spec != nil {
+		tls.MinTLSVersion = spec.MinTLSVersion
+		tls.Ciphers = spec.Ciphers
+	}
+	return auditProfile, tls
+}
+
+// admission returns the admission plugins of the configuration of the Kubernetes API servers.
+func admission(configMap *corev1.ConfigMap) (*admissionSettings, error) {
+	data, ok := configMap.Data["config.yaml"]
+	if !ok {
+		return nil, fmt.Errorf("config map %s/%s has no config.yaml", configMap.Namespace, configMap.Name)
+	}
+	var config struct {
+		APIServerArguments map[string][]string      `json:"apiServerArguments"`
+		Admission          configv1.AdmissionConfig `json:"admission"`
+	}
+	if err := yaml.Unmarshal([]byte(data), &config); err != nil {
+		return nil, fmt.Errorf("unable to parse the configuration of config map %s/%s: %v", configMap.Namespace, configMap.Name, err)
+	}
+
+	settings := &admissionSettings{
+		EnabledPlugins:  append(config.APIServerArguments["enable-admission-plugins"], config.Admission.EnabledAdmissionPlugins...),
+		DisabledPlugins: append(config.APIServerArguments["disable-admission-plugins"], config.Admission.DisabledAdmissionPlugins...),
+		PluginConfig:    config.Admission.PluginConfig,
+	}
+	sort.Strings(settings.EnabledPlugins)
+	sort.Strings(settings.DisabledPlugins)
+	return settings, nil
+}
+
+// sccAssignments returns the settings of the security context constraints, with the role bindings whose roles allow
+// to use them.
+func sccAssignments(sccs []securityv1.SecurityContextConstraints, roles []rbacv1.Role, clusterRoles []rbacv1.ClusterRole, roleBindings []rbacv1.RoleBinding, clusterRoleBindings []rbacv1.ClusterRoleBinding) []sccSettings {
+	roleRules := map[string][]rbacv1.PolicyRule{}
+	for _, role := range roles {
+		roleRules[role.Namespace+"/"+role.Name] = role.Rules
+	}
+	clusterRoleRules := map[string][]rbacv1.PolicyRule{}
+	for _, role := range clusterRoles {
+		clusterRoleRules[role.Name] = role.Rules
+	}
+	sort.Slice(clusterRoleBindings, func(i, j int) bool { return clusterRoleBindings[i].Name < clusterRoleBindings[j].Name })
+	sort.Slice(roleBindings, func(i, j int) bool {
+		if roleBindings[i].Namespace != roleBindings[j].Namespace {
+			return roleBindings[i].Namespace < roleBindings[j].Namespace
+		}
+		return roleBindings[i].Name < roleBindings[j].Name
+	})
+
+	var settings []sccSettings
+	for _, scc := range sccs {
+		s := sccSettings{
+			Name:                     scc.Name,
+			Priority:                 scc.Priority,
+			AllowPrivilegedContainer: scc.AllowPrivilegedContainer,
+			AllowPrivilegeEscalation: scc.AllowPrivilegeEscalation,
+			AllowHostNetwork:         scc.AllowHostNetwork,
+			AllowHostPorts:           scc.AllowHostPorts,
+			AllowHostPID:             scc.AllowHostPID,
+			AllowHostIPC:             scc.AllowHostIPC,
+			RunAsUser:                scc.RunAsUser.Type,
+			SELinuxContext:           scc.SELinuxContext.Type,
+			Volumes:                  scc.Volumes,
+			Users:                    sortedCopy(scc.Users),
+			Groups:                   sortedCopy(scc.Groups),
+		}
+
+		use := []rbacv1.PolicyRule{{
+			Verbs:         []string{"use"},
+			APIGroups:     []string{securityv1.GroupName},
+			Resources:     []string{"securitycontextconstraints"},
+			ResourceNames: []string{scc.Name},
+		}}
+		for _, binding := range clusterRoleBindings {
+			if binding.RoleRef.Kind != "ClusterRole" || len(binding.Subjects) == 0 {
+				continue
+			}
+			if covers, _ := validation.Covers(clusterRoleRules[binding.RoleRef.Name], use); covers {
+				s.Bindings = append(s.Bindings, newSCCBinding("ClusterRoleBinding", "", binding.Name, binding.RoleRef, binding.Subjects))
+			}
+		}
+		for _, binding := range roleBindings {
+			if len(binding.Subjects) == 0 {
+				continue
+			}
+			rules := clusterRoleRules[binding.RoleRef.Name]
+			if binding.RoleRef.Kind == "Role" {
+				rules = roleRules[binding.Namespace+"/"+binding.RoleRef.Name]
+			}
+			if covers, _ := validation.Covers(rules, use); covers {
+				s.Bindings = append(s.Bindings, newSCCBinding("RoleBinding", binding.Namespace, binding.Name, binding.RoleRef, binding.Subjects))
+			}
+		}
+		settings = append(settings, s)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings
+}
+
+func newSCCBinding(kind, namespace, name string, roleRef rbacv1.RoleRef, subjects []rbacv1.Subject) sccBinding {
+	binding := sccBinding{Kind: kind, Namespace: namespace, Name: name, Role: roleRef.Kind + "/" + roleRef.Name}
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind {
+			subjectNamespace := subject.Namespace
+			if len(subjectNamespace) == 0 {
+				subjectNamespace = namespace
+			}
+			binding.Subjects = append(binding.Subjects, fmt.Sprintf("%s %s/%s", subject.Kind, subjectNamespace, subject.Name))
+			continue
+		}
+		binding.Subjects = append(binding.Subjects, fmt.Sprintf("%s %s", subject.Kind, subject.Name))
+	}
+	sort.Strings(binding.Subjects)
+	return binding
+}
+
+// networkPolicies returns the network policies of every namespace, and whether they deny the traffic by default: a
+// policy that selects all the pods of the namespace without rule for a policy type denies all the traffic of that type
+// that other policies do not allow.
+func networkPolicies(namespaces []corev1.Namespace, policies []networkingv1.NetworkPolicy) []namespacePolicies {
+	byNamespace := map[string]*namespacePolicies{}
+	var result []namespacePolicies
+	for _, namespace := range namespaces {
+		result = append(result, namespacePolicies{Namespace: namespace.Name})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Namespace < result[j].Namespace })
+	for i := range result {
+		byNamespace[result[i].Namespace] = &result[i]
+	}
+
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+	for _, policy := range policies {
+		namespace, ok := byNamespace[policy.Namespace]
+		if !ok {
+			continue
+		}
+		namespace.Policies = append(namespace.Policies, policy.Name)
+		if len(policy.Spec.PodSelector.MatchLabels) > 0 || len(policy.Spec.PodSelector.MatchExpressions) > 0 {
+			continue
+		}
+		ingress, egress := policyTypes(&policy)
+		if ingress && len(policy.Spec.Ingress) == 0 {
+			namespace.DefaultDenyIngress = true
+		}
+		if egress && len(policy.Spec.Egress) == 0 {
+			namespace.DefaultDenyEgress = true
+		}
+	}
+	return result
+}
+
+// policyTypes returns whether the policy applies to the ingress and to the egress traffic. Without policy types, a
+// policy applies to the ingress traffic, and to the egress traffic if it has egress rules.
+func policyTypes(policy *networkingv1.NetworkPolicy) (ingress, egress bool) {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return true, len(policy.Spec.Egress) > 0
+	}
+	for _, t := range policy.Spec.PolicyTypes {
+		switch t {
+		case networkingv1.PolicyTypeIngress:
+			ingress = true
+		case networkingv1.PolicyTypeEgress:
+			egress = true
+		}
+	}
+	return ingress, egress
+}
+
+func sortedCopy(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
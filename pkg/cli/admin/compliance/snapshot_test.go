@@ -0,0 +1,182 @@
+package compliance
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	securityv1 "github.com/openshift/api/security/v1"
+)
+
+func TestSCCAssignments(t *testing.T) {
+	scc := func(name string, privileged bool, users ...string) securityv1.SecurityContextConstraints {
+		return securityv1.SecurityContextConstraints{
+			ObjectMeta:               metav1.ObjectMeta{Name: name},
+			AllowPrivilegedContainer: privileged,
+			RunAsUser:                securityv1.RunAsUserStrategyOptions{Type: securityv1.RunAsUserStrategyMustRunAsRange},
+			Users:                    users,
+		}
+	}
+	useRule := func(names ...string) []rbacv1.PolicyRule {
+		return []rbacv1.PolicyRule{{Verbs: []string{"use"}, APIGroups: []string{"security.openshift.io"}, Resources: []string{"securitycontextconstraints"}, ResourceNames: names}}
+	}
+	sccs := []securityv1.SecurityContextConstraints{
+		scc("restricted", false),
+		scc("privileged", true, "system:admin", "system:serviceaccount:openshift-infra:build-controller"),
+	}
+	clusterRoles := []rbacv1.ClusterRole{
+		{ObjectMeta: metav1.ObjectMeta{Name: "system:openshift:scc:privileged"}, Rules: useRule("privileged")},
+		{ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "view"}, Rules: []rbacv1.PolicyRule{{Verbs: []string{"get"}, APIGroups: []string{"*"}, Resources: []string{"*"}}}},
+	}
+	roles := []rbacv1.Role{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "use-restricted"}, Rules: useRule("restricted")},
+	}
+	clusterRoleBindings := []rbacv1.ClusterRoleBinding{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-admins"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"},
+			Subjects:   []rbacv1.Subject{{Kind: "Group", Name: "system:cluster-admins"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewers"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{{Kind: "Group", Name: "system:authenticated"}},
+		},
+	}
+	roleBindings := []rbacv1.RoleBinding{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "privileged"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "system:openshift:scc:privileged"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "agent"}, {Kind: "User", Name: "alice"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "restricted"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "use-restricted"},
+			Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Namespace: "other", Name: "default"}},
+		},
+	}
+
+	got := sccAssignments(sccs, roles, clusterRoles, roleBindings, clusterRoleBindings)
+	clusterAdmins := sccBinding{Kind: "ClusterRoleBinding", Name: "cluster-admins", Role: "ClusterRole/cluster-admin", Subjects: []string{"Group system:cluster-admins"}}
+	want := []sccSettings{
+		{
+			Name:                     "privileged",
+			AllowPrivilegedContainer: true,
+			RunAsUser:                securityv1.RunAsUserStrategyMustRunAsRange,
+			Users:                    []string{"system:admin", "system:serviceaccount:openshift-infra:build-controller"},
+			Bindings: []sccBinding{
+				clusterAdmins,
+				{Kind: "RoleBinding", Namespace: "app", Name: "privileged", Role: "ClusterRole/system:openshift:scc:privileged", Subjects: []string{"ServiceAccount app/agent", "User alice"}},
+			},
+		},
+		{
+			Name:      "restricted",
+			RunAsUser: securityv1.RunAsUserStrategyMustRunAsRange,
+			Bindings: []sccBinding{
+				clusterAdmins,
+				{Kind: "RoleBinding", Namespace: "app", Name: "restricted", Role: "Role/use-restricted", Subjects: []string{"ServiceAccount other/default"}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+}
+
+func TestAPIServerSettings(t *testing.T) {
+	intermediate := configv1.TLSProfiles[configv1.TLSProfileIntermediateType]
+	custom := configv1.TLSProfileSpec{Ciphers: []string{"ECDHE-ECDSA-AES128-GCM-SHA256"}, MinTLSVersion: configv1.VersionTLS12}
+	tests := []struct {
+		name      string
+		spec      configv1.APIServerSpec
+		wantAudit configv1.AuditProfileType
+		wantTLS   *tlsSettings
+	}{
+		{
+			name:      "defaults",
+			wantAudit: configv1.DefaultAuditProfileType,
+			wantTLS:   &tlsSettings{Type: configv1.TLSProfileIntermediateType, MinTLSVersion: intermediate.MinTLSVersion, Ciphers: intermediate.Ciphers},
+		},
+		{
+			name: "custom",
+			spec: configv1.APIServerSpec{
+				Audit:              configv1.Audit{Profile: configv1.WriteRequestBodiesAuditProfileType},
+				TLSSecurityProfile: &configv1.TLSSecurityProfile{Type: configv1.TLSProfileCustomType, Custom: &configv1.CustomTLSProfile{TLSProfileSpec: custom}},
+			},
+			wantAudit: configv1.WriteRequestBodiesAuditProfileType,
+			wantTLS:   &tlsSettings{Type: configv1.TLSProfileCustomType, MinTLSVersion: configv1.VersionTLS12, Ciphers: custom.Ciphers},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit, tls := apiServerSettings(&configv1.APIServer{Spec: tt.spec})
+			if audit != tt.wantAudit {
+				t.Errorf("got audit profile %q, want %q", audit, tt.wantAudit)
+			}
+			if !reflect.DeepEqual(tls, tt.wantTLS) {
+				t.Errorf("got TLS profile %#v, want %#v", tls, tt.wantTLS)
+			}
+		})
+	}
+}
+
+func TestAdmission(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: kubeAPIServerNamespace, Name: kubeAPIServerConfigMap},
+		Data: map[string]string{"config.yaml": `{
+			"apiServerArguments": {"enable-admission-plugins": ["PodSecurity", "NodeRestriction"]},
+			"admission": {"pluginConfig": {"PodSecurity": {"configuration": {"defaults": {"enforce": "restricted"}}}}}
+		}`},
+	}
+	settings, err := admission(configMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"NodeRestriction", "PodSecurity"}; !reflect.DeepEqual(settings.EnabledPlugins, want) {
+		t.Errorf("got enabled plugins %q, want %q", settings.EnabledPlugins, want)
+	}
+	if got, want := string(settings.PluginConfig["PodSecurity"].Configuration.Raw), `{"defaults":{"enforce":"restricted"}}`; got != want {
+		t.Errorf("got PodSecurity configuration %s, want %s", got, want)
+	}
+
+	if _, err := admission(&corev1.ConfigMap{}); err == nil {
+		t.Errorf("expected an error for a config map without config.yaml")
+	}
+}
+
+func TestNetworkPolicies(t *testing.T) {
+	namespace := func(name string) corev1.Namespace {
+		return corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	policy := func(namespace, name string, spec networkingv1.NetworkPolicySpec) networkingv1.NetworkPolicy {
+		return networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Spec: spec}
+	}
+	policies := []networkingv1.NetworkPolicy{
+		policy("app", "deny-all", networkingv1.NetworkPolicySpec{PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}}),
+		policy("app", "allow-dns", networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      []networkingv1.NetworkPolicyEgressRule{{}},
+		}),
+		policy("web", "default-deny", networkingv1.NetworkPolicySpec{}),
+		policy("web", "db-only", networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+		}),
+	}
+
+	got := networkPolicies([]corev1.Namespace{namespace("web"), namespace("default"), namespace("app")}, policies)
+	want := []namespacePolicies{
+		{Namespace: "app", Policies: []string{"allow-dns", "deny-all"}, DefaultDenyIngress: true, DefaultDenyEgress: true},
+		{Namespace: "default"},
+		{Namespace: "web", Policies: []string{"db-only", "default-deny"}, DefaultDenyIngress: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+}
@@ -0,0 +1,266 @@
+package deprecatedapis
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	apiserverv1 "github.com/openshift/api/apiserver/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	deprecatedAPIsLong = templates.LongDesc(`
+		Report the workloads that call deprecated APIs
+
+		The API servers count the requests for every API in the APIRequestCount resources, by user
+		and user agent, for the last 24 hours. This command lists the users and user agents that
+		called the APIs that are removed in a later Kubernetes version, with the namespace of the
+		service accounts, so that the workloads can be updated before the cluster is upgraded.
+
+		With --next-version, only the APIs removed in the Kubernetes version that follows the
+		version of the cluster are reported, which are the APIs that block the next upgrade.
+	`)
+
+	deprecatedAPIsExample = templates.Examples(`
+		# Report the workloads that call deprecated APIs
+		oc adm deprecated-apis
+
+		# Report the workloads that call APIs removed in the next Kubernetes version as JSON
+		oc adm deprecated-apis --next-version -o json
+	`)
+)
+
+var apiRequestCountsResource = apiserverv1.GroupVersion.WithResource("apirequestcounts")
+
+// DeprecatedAPIsOptions holds the options to report the workloads that call deprecated APIs.
+type DeprecatedAPIsOptions struct {
+	NextVersion bool
+	Output      string
+
+	DynamicClient   dynamic.Interface
+	DiscoveryClient discovery.ServerVersionInterface
+
+	genericclioptions.IOStreams
+}
+
+func NewDeprecatedAPIsOptions(streams genericclioptions.IOStreams) *DeprecatedAPIsOptions {
+	return &DeprecatedAPIsOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdDeprecatedAPIs creates a command that reports the workloads that call deprecated APIs.
+func NewCmdDeprecatedAPIs(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewDeprecatedAPIsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "deprecated-apis",
+		Short:   "Report the workloads that call deprecated APIs",
+		Long:    deprecatedAPIsLong,
+		Example: deprecatedAPIsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.NextVersion, "next-version", o.NextVersion, "Only report the APIs removed in the Kubernetes version that follows the version of the cluster.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+
+	return cmd
+}
+
+func (o *DeprecatedAPIsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	var err error
+	if o.DynamicClient, err = f.DynamicClient(); err != nil {
+		return err
+	}
+	o.DiscoveryClient, err = f.ToDiscoveryClient()
+	return err
+}
+
+func (o *DeprecatedAPIsOptions) Validate() error {
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be one of: json, yaml")
+	}
+	return nil
+}
+
+// apiUsage is the requests of a user agent of a user for a deprecated API in the last 24 hours.
+type apiUsage struct {
+	API              string `json:"api"`
+	RemovedInRelease string `json:"removedInRelease"`
+	// Namespace is the namespace of the service account of the user, if the user is a service account.
+	Namespace string `json:"namespace,omitempty"`
+	User      string `json:"user"`
+	UserAgent string `json:"userAgent"`
+	Requests  int64  `json:"requests"`
+}
+
+func (o *DeprecatedAPIsOptions) Run() error {
+	var removedIn string
+	if o.NextVersion {
+		var err error
+		if removedIn, err = o.nextVersion(); err != nil {
+			return err
+		}
+	}
+
+	list, err := o.DynamicClient.Resource(apiRequestCountsResource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var counts []apiserverv1.APIRequestCount
+	for _, item := range list.Items {
+		count := apiserverv1.APIRequestCount{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredContent(), &count); err != nil {
+			return fmt.Errorf("unable to decode apirequestcount %s: %v", item.GetName(), err)
+		}
+		counts = append(counts, count)
+	}
+	usages := deprecatedAPIUsages(counts, removedIn)
+
+	if len(o.Output) > 0 {
+		if usages == nil {
+			usages = []apiUsage{}
+		}
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, usages)
+	}
+
+	if len(usages) == 0 {
+		if len(removedIn) > 0 {
+			fmt.Fprintf(o.Out, "No API removed in Kubernetes %s was called in the last 24 hours\n", removedIn)
+		} else {
+			fmt.Fprintln(o.Out, "No deprecated API was called in the last 24 hours")
+		}
+		return nil
+	}
+	printUsages(o.Out, usages)
+	return nil
+}
+
+// nextVersion returns the Kubernetes version that follows the version of the cluster, as MAJOR.MINOR.
+func (o *DeprecatedAPIsOptions) nextVersion() (string, error) {
+	version, err := o.DiscoveryClient.ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	minor, err := strconv.Atoi(strings.TrimSuffix(version.Minor, "+"))
+	if err != nil {
+		return "", fmt.Errorf("unable to parse the minor version %q of the cluster: %v", version.Minor, err)
+	}
+	return fmt.Sprintf("%s.%d", version.Major, minor+1), nil
+}
+
+// deprecatedAPIUsages returns the requests of the last 24 hours for the APIs that are removed in a release, or in
+// the given release if it is set, by user and user agent.
+func deprecatedAPIUsages(counts []apiserverv1.APIRequestCount, removedIn string) []apiUsage {
+	var usages []apiUsage
+	for _, count := range counts {
+		if len(count.Status.RemovedInRelease) == 0 || (len(removedIn) > 0 && count.Status.RemovedInRelease != removedIn) {
+			continue
+		}
+		api := apiName(count.Name)
+		byUser := map[[2]string]int64{}
+		for _, hour := range count.Status.Last24h {
+			for _, node := range hour.ByNode {
+				for _, user := range node.ByUser {
+					byUser[[2]string{user.UserName, user.UserAgent}] += user.RequestCount
+				}
+			}
+		}
+		for key, requests := range byUser {
+			usage := apiUsage{
+				API:              api,
+				RemovedInRelease: count.Status.RemovedInRelease,
+				User:             key[0],
+				UserAgent:        key[1],
+				Requests:         requests,
+			}
+			if namespace, _, err := serviceaccount.SplitUsername(key[0]); err == nil {
+				usage.Namespace = namespace
+			}
+			usages = append(usages, usage)
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.RemovedInRelease != b.RemovedInRelease {
+			return a.RemovedInRelease < b.RemovedInRelease
+		}
+		if a.API != b.API {
+			return a.API < b.API
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		return a.UserAgent < b.UserAgent
+	})
+	return usages
+}
+
+// apiName returns the name of the API of an APIRequestCount, named RESOURCE.VERSION.GROUP, as RESOURCE.GROUP/VERSION.
+func apiName(name string) string {
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) < 2 {
+		return name
+	}
+	gvr := schema.GroupVersionResource{Resource: parts[0], Version: parts[1]}
+	if len(parts) == 3 {
+		gvr.Group = parts[2]
+	}
+	return gvr.GroupResource().String() + "/" + gvr.Version
+}
+
+// userAgentName returns the name of the program of a user agent, without its version and platform.
+func userAgentName(userAgent string) string {
+	name := strings.Fields(userAgent)
+	if len(name) == 0 {
+		return "-"
+	}
+	return name[0]
+}
+
+func printUsages(out io.Writer, usages []apiUsage) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "API\tREMOVED IN\tNAMESPACE\tUSER\tUSER AGENT\tREQUESTS (24H)")
+	apis, users := sets.NewString(), sets.NewString()
+	for _, u := range usages {
+		namespace := u.Namespace
+		if len(namespace) == 0 {
+			namespace = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\n", u.API, u.RemovedInRelease, namespace, u.User, userAgentName(u.UserAgent), u.Requests)
+		apis.Insert(u.API)
+		users.Insert(u.User)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n%d deprecated API(s) called by %d user(s) in the last 24 hours\n", apis.Len(), users.Len())
+}
@@ -0,0 +1,114 @@
+package deprecatedapis
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	apiserverv1 "github.com/openshift/api/apiserver/v1"
+)
+
+func testRequestCount(t *testing.T, name, removedIn string, users ...apiserverv1.PerUserAPIRequestCount) *unstructured.Unstructured {
+	count := &apiserverv1.APIRequestCount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiserverv1.GroupVersion.String(), Kind: "APIRequestCount"},
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: apiserverv1.APIRequestCountStatus{
+			RemovedInRelease: removedIn,
+			Last24h: []apiserverv1.PerResourceAPIRequestLog{
+				{ByNode: []apiserverv1.PerNodeAPIRequestLog{{NodeName: "master-0", ByUser: users}}},
+				{ByNode: []apiserverv1.PerNodeAPIRequestLog{{NodeName: "master-1", ByUser: users}}},
+			},
+		},
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(count)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &unstructured.Unstructured{Object: content}
+}
+
+func testOptions(t *testing.T) (*DeprecatedAPIsOptions, interface{ String() string }) {
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{apiRequestCountsResource: "APIRequestCountList"},
+		testRequestCount(t, "flowschemas.v1beta1.flowcontrol.apiserver.k8s.io", "1.26",
+			apiserverv1.PerUserAPIRequestCount{UserName: "system:serviceaccount:monitoring:prometheus", UserAgent: "prometheus/v2.39.1", RequestCount: 4},
+			apiserverv1.PerUserAPIRequestCount{UserName: "system:kube-controller-manager", UserAgent: "kube-controller-manager/v1.25.0 (linux/amd64) kubernetes/abcdef", RequestCount: 1},
+		),
+		testRequestCount(t, "cronjobs.v1beta1.batch", "1.25",
+			apiserverv1.PerUserAPIRequestCount{UserName: "system:serviceaccount:backup:scheduler", UserAgent: "backup-operator/v1.0.0 (linux/amd64)", RequestCount: 10},
+		),
+		testRequestCount(t, "podsecuritypolicies.v1beta1.policy", "1.25"),
+		testRequestCount(t, "pods.v1", "", apiserverv1.PerUserAPIRequestCount{UserName: "alice", UserAgent: "oc/4.12.0", RequestCount: 3}),
+	)
+	discovery := kubefake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	discovery.FakedServerVersion = &version.Info{Major: "1", Minor: "25+"}
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	return &DeprecatedAPIsOptions{DynamicClient: dynamicClient, DiscoveryClient: discovery, IOStreams: streams}, out
+}
+
+func TestRun(t *testing.T) {
+	o, out := testOptions(t)
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "API                                                REMOVED IN   NAMESPACE    USER                                          USER AGENT                        REQUESTS (24H)\n" +
+		"cronjobs.batch/v1beta1                             1.25         backup       system:serviceaccount:backup:scheduler        backup-operator/v1.0.0            20\n" +
+		"flowschemas.flowcontrol.apiserver.k8s.io/v1beta1   1.26         monitoring   system:serviceaccount:monitoring:prometheus   prometheus/v2.39.1                8\n" +
+		"flowschemas.flowcontrol.apiserver.k8s.io/v1beta1   1.26         -            system:kube-controller-manager                kube-controller-manager/v1.25.0   2\n" +
+		"\n" +
+		"2 deprecated API(s) called by 3 user(s) in the last 24 hours\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRun_nextVersion(t *testing.T) {
+	o, out := testOptions(t)
+	o.NextVersion = true
+	o.Output = "json"
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `[
+  {
+    "api": "flowschemas.flowcontrol.apiserver.k8s.io/v1beta1",
+    "removedInRelease": "1.26",
+    "namespace": "monitoring",
+    "user": "system:serviceaccount:monitoring:prometheus",
+    "userAgent": "prometheus/v2.39.1",
+    "requests": 8
+  },
+  {
+    "api": "flowschemas.flowcontrol.apiserver.k8s.io/v1beta1",
+    "removedInRelease": "1.26",
+    "user": "system:kube-controller-manager",
+    "userAgent": "kube-controller-manager/v1.25.0 (linux/amd64) kubernetes/abcdef",
+    "requests": 2
+  }
+]
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestAPIName(t *testing.T) {
+	tests := map[string]string{
+		"cronjobs.v1beta1.batch": "cronjobs.batch/v1beta1",
+		"pods.v1":                "pods/v1",
+		"flowschemas.v1beta1.flowcontrol.apiserver.k8s.io": "flowschemas.flowcontrol.apiserver.k8s.io/v1beta1",
+	}
+	for name, expected := range tests {
+		if got := apiName(name); got != expected {
+			t.Errorf("apiName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
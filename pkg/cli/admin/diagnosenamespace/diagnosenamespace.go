@@ -0,0 +1,303 @@
+package diagnosenamespace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	diagnoseNamespaceLong = templates.LongDesc(`
+		Diagnose why a namespace is stuck terminating
+
+		A namespace is deleted once all its resources are deleted and its finalizers are removed.
+		This command shows the finalizers left on a terminating namespace, the conditions the
+		namespace controller reports, the API groups whose discovery fails, which prevents the
+		deletion of their resources, and the resources still present with their finalizers.
+
+		The usual causes are an aggregated API server that is unavailable, or resources whose
+		finalizers are not removed because their controller or operator was uninstalled first.
+		Fix the cause when possible: restore the API service, or let the controller finish.
+
+		As a last resort, --remove-finalizers removes the finalizers of the namespace so that it
+		is deleted at once. It requires --force, because the remaining resources and the external
+		state they manage, like cloud load balancers or volumes, are then never cleaned up.
+	`)
+
+	diagnoseNamespaceExample = templates.Examples(`
+		# Diagnose why the namespace my-project is stuck terminating
+		oc adm diagnose-namespace my-project
+
+		# Remove the finalizers of the namespace, leaving its remaining resources behind
+		oc adm diagnose-namespace my-project --remove-finalizers --force
+	`)
+)
+
+// namespacedResourceFinder finds the namespaced resources of the server.
+// it exists to allow for easier testability.
+type namespacedResourceFinder interface {
+	ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error)
+}
+
+// DiagnoseNamespaceOptions holds the options to diagnose a terminating namespace.
+type DiagnoseNamespaceOptions struct {
+	Namespace        string
+	RemoveFinalizers bool
+	Force            bool
+
+	Client          kubernetes.Interface
+	DynamicClient   dynamic.Interface
+	DiscoveryClient namespacedResourceFinder
+
+	genericclioptions.IOStreams
+}
+
+func NewDiagnoseNamespaceOptions(streams genericclioptions.IOStreams) *DiagnoseNamespaceOptions {
+	return &DiagnoseNamespaceOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdDiagnoseNamespace creates a command that diagnoses a namespace stuck terminating.
+func NewCmdDiagnoseNamespace(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewDiagnoseNamespaceOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "diagnose-namespace NAMESPACE",
+		Short:   "Diagnose why a namespace is stuck terminating",
+		Long:    diagnoseNamespaceLong,
+		Example: diagnoseNamespaceExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.RemoveFinalizers, "remove-finalizers", o.RemoveFinalizers, "Remove the finalizers of the terminating namespace so that it is deleted without its remaining resources. Requires --force.")
+	cmd.Flags().BoolVar(&o.Force, "force", o.Force, "Acknowledge that --remove-finalizers leaves the remaining resources of the namespace and their external state behind.")
+
+	return cmd
+}
+
+func (o *DiagnoseNamespaceOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "exactly one namespace is required")
+	}
+	o.Namespace = args[0]
+
+	var err error
+	if o.Client, err = f.KubernetesClientSet(); err != nil {
+		return err
+	}
+	if o.DynamicClient, err = f.DynamicClient(); err != nil {
+		return err
+	}
+	discoveryClient, err := f.ToDiscoveryClient()
+	if err != nil {
+		return err
+	}
+	// the resources of a namespace stuck terminating may come from API servers that were just removed
+	discoveryClient.Invalidate()
+	o.DiscoveryClient = discoveryClient
+	return nil
+}
+
+func (o *DiagnoseNamespaceOptions) Validate() error {
+	if o.RemoveFinalizers && !o.Force {
+		return errors.New("--remove-finalizers requires --force: the remaining resources of the namespace and the external state they manage are never cleaned up")
+	}
+	return nil
+}
+
+// remainingResource is a resource left in a terminating namespace.
+type remainingResource struct {
+	Resource   string
+	Name       string
+	Deleting   bool
+	Finalizers []string
+}
+
+// namespaceContent is what is left in a terminating namespace.
+type namespaceContent struct {
+	Resources []remainingResource
+	// FailedGroups are the errors of the API group versions whose discovery fails, by group version.
+	FailedGroups map[string]string
+	// ListErrors are the errors listing the resources, by resource.
+	ListErrors map[string]string
+}
+
+func (o *DiagnoseNamespaceOptions) Run() error {
+	ns, err := o.Client.CoreV1().Namespaces().Get(context.TODO(), o.Namespace, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if ns.DeletionTimestamp == nil {
+		fmt.Fprintf(o.Out, "Namespace %s is %s, it is not being deleted.\n", ns.Name, ns.Status.Phase)
+		if o.RemoveFinalizers {
+			return fmt.Errorf("namespace %s is not terminating, its finalizers are not removed", ns.Name)
+		}
+		return nil
+	}
+	fmt.Fprintf(o.Out, "Namespace %s is %s, its deletion was requested %s ago.\n", ns.Name, ns.Status.Phase, duration.HumanDuration(time.Since(ns.DeletionTimestamp.Time)))
+
+	content, err := o.findContent()
+	if err != nil {
+		return err
+	}
+	printDiagnosis(o.Out, ns, content)
+
+	if o.RemoveFinalizers {
+		return o.removeFinalizers()
+	}
+	return nil
+}
+
+// findContent lists the resources left in the namespace.
+func (o *DiagnoseNamespaceOptions) findContent() (*namespaceContent, error) {
+	content := &namespaceContent{FailedGroups: map[string]string{}, ListErrors: map[string]string{}}
+	lists, err := o.DiscoveryClient.ServerPreferredNamespacedResources()
+	if err != nil {
+		var failed *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &failed) {
+			return nil, err
+		}
+		for gv, err := range failed.Groups {
+			content.FailedGroups[gv.String()] = err.Error()
+		}
+	}
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range list.APIResources {
+			if strings.Contains(resource.Name, "/") || !sets.NewString(resource.Verbs...).Has("list") {
+				continue
+			}
+			gvr := gv.WithResource(resource.Name)
+			name := gvr.GroupResource().String()
+			items, err := o.DynamicClient.Resource(gvr).Namespace(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				content.ListErrors[name] = err.Error()
+				continue
+			}
+			for _, item := range items.Items {
+				content.Resources = append(content.Resources, remainingResource{
+					Resource:   name,
+					Name:       item.GetName(),
+					Deleting:   item.GetDeletionTimestamp() != nil,
+					Finalizers: item.GetFinalizers(),
+				})
+			}
+		}
+	}
+	sort.Slice(content.Resources, func(i, j int) bool {
+		if content.Resources[i].Resource != content.Resources[j].Resource {
+			return content.Resources[i].Resource < content.Resources[j].Resource
+		}
+		return content.Resources[i].Name < content.Resources[j].Name
+	})
+	return content, nil
+}
+
+func printDiagnosis(out io.Writer, ns *corev1.Namespace, content *namespaceContent) {
+	if len(ns.Spec.Finalizers) > 0 || len(ns.Finalizers) > 0 {
+		fmt.Fprintln(out, "\nFinalizers of the namespace:")
+		for _, finalizer := range ns.Spec.Finalizers {
+			fmt.Fprintf(out, "  %s (spec)\n", finalizer)
+		}
+		for _, finalizer := range ns.Finalizers {
+			fmt.Fprintf(out, "  %s (metadata)\n", finalizer)
+		}
+	}
+
+	var conditions []corev1.NamespaceCondition
+	for _, condition := range ns.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue {
+			conditions = append(conditions, condition)
+		}
+	}
+	if len(conditions) > 0 {
+		fmt.Fprintln(out, "\nConditions:")
+		for _, condition := range conditions {
+			fmt.Fprintf(out, "  %s: %s\n", condition.Type, condition.Message)
+		}
+	}
+
+	if len(content.FailedGroups) > 0 {
+		fmt.Fprintln(out, "\nAPI groups whose discovery fails, their resources cannot be deleted:")
+		for _, gv := range sets.StringKeySet(content.FailedGroups).List() {
+			fmt.Fprintf(out, "  %s: %s\n", gv, content.FailedGroups[gv])
+		}
+	}
+	if len(content.ListErrors) > 0 {
+		fmt.Fprintln(out, "\nResources that cannot be listed:")
+		for _, resource := range sets.StringKeySet(content.ListErrors).List() {
+			fmt.Fprintf(out, "  %s: %s\n", resource, content.ListErrors[resource])
+		}
+	}
+
+	if len(content.Resources) == 0 {
+		fmt.Fprintln(out, "\nNo resources remain in the namespace.")
+		return
+	}
+	fmt.Fprintln(out, "\nRemaining resources:")
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tNAME\tDELETING\tFINALIZERS")
+	for _, r := range content.Resources {
+		finalizers := strings.Join(r.Finalizers, ",")
+		if len(finalizers) == 0 {
+			finalizers = "<none>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", r.Resource, r.Name, r.Deleting, finalizers)
+	}
+	w.Flush()
+}
+
+// removeFinalizers removes the metadata finalizers of the namespace, then the spec finalizers through the finalize
+// subresource, after which the namespace is deleted.
+func (o *DiagnoseNamespaceOptions) removeFinalizers() error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ns, err := o.Client.CoreV1().Namespaces().Get(context.TODO(), o.Namespace, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if len(ns.Finalizers) > 0 {
+			ns.Finalizers = nil
+			if ns, err = o.Client.CoreV1().Namespaces().Update(context.TODO(), ns, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+		}
+		if len(ns.Spec.Finalizers) > 0 {
+			ns.Spec.Finalizers = nil
+			_, err = o.Client.CoreV1().Namespaces().Finalize(context.TODO(), ns, metav1.UpdateOptions{})
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to remove the finalizers of namespace %s: %v", o.Namespace, err)
+	}
+	fmt.Fprintf(o.Out, "\nnamespace/%s finalizers removed\n", o.Namespace)
+	return nil
+}
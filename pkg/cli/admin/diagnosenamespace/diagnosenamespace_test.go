@@ -0,0 +1,160 @@
+package diagnosenamespace
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+type fakeResourceFinder struct {
+	lists []*metav1.APIResourceList
+	err   error
+}
+
+func (f *fakeResourceFinder) ServerPreferredNamespacedResources() ([]*metav1.APIResourceList, error) {
+	return f.lists, f.err
+}
+
+var widgetsResource = schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+func testOptions(ns *corev1.Namespace) (*DiagnoseNamespaceOptions, *fake.Clientset, *bytes.Buffer) {
+	widget := &unstructured.Unstructured{}
+	widget.SetAPIVersion("example.com/v1")
+	widget.SetKind("Widget")
+	widget.SetNamespace("stuck")
+	widget.SetName("w1")
+	widget.SetFinalizers([]string{"example.com/cleanup"})
+	deleted := metav1.Now()
+	widget.SetDeletionTimestamp(&deleted)
+
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace("stuck")
+	configMap.SetName("settings")
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		widgetsResource:                         "WidgetList",
+		{Version: "v1", Resource: "configmaps"}: "ConfigMapList",
+		{Version: "v1", Resource: "secrets"}:    "SecretList",
+	}, widget, configMap)
+
+	finder := &fakeResourceFinder{
+		lists: []*metav1.APIResourceList{
+			{GroupVersion: "v1", APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "secrets", Namespaced: true, Verbs: []string{"list", "delete"}},
+				{Name: "pods/log", Namespaced: true, Verbs: []string{"get"}},
+				{Name: "bindings", Namespaced: true, Verbs: []string{"create"}},
+			}},
+			{GroupVersion: "example.com/v1", APIResources: []metav1.APIResource{
+				{Name: "widgets", Namespaced: true, Verbs: []string{"list", "delete"}},
+			}},
+		},
+		err: &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+			{Group: "metrics.k8s.io", Version: "v1beta1"}: errors.New("the server is currently unable to handle the request"),
+		}},
+	}
+
+	client := fake.NewSimpleClientset(ns)
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &DiagnoseNamespaceOptions{Namespace: ns.Name, Client: client, DynamicClient: dynamicClient, DiscoveryClient: finder, IOStreams: streams}
+	return o, client, out
+}
+
+func terminatingNamespace() *corev1.Namespace {
+	deleted := metav1.NewTime(time.Now().Add(-3 * time.Hour))
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", DeletionTimestamp: &deleted, Finalizers: []string{"example.com/namespace"}},
+		Spec:       corev1.NamespaceSpec{Finalizers: []corev1.FinalizerName{corev1.FinalizerKubernetes}},
+		Status: corev1.NamespaceStatus{
+			Phase: corev1.NamespaceTerminating,
+			Conditions: []corev1.NamespaceCondition{
+				{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionTrue, Message: "Discovery failed for some groups, 1 failing: metrics.k8s.io/v1beta1"},
+				{Type: corev1.NamespaceDeletionContentFailure, Status: corev1.ConditionFalse, Message: "All content successfully deleted"},
+				{Type: corev1.NamespaceFinalizersRemaining, Status: corev1.ConditionTrue, Message: "Some content in the namespace has finalizers remaining: example.com/cleanup in 1 resource instances"},
+			},
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	o, _, out := testOptions(terminatingNamespace())
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "Namespace stuck is Terminating, its deletion was requested 3h ago.\n" +
+		"\n" +
+		"Finalizers of the namespace:\n" +
+		"  kubernetes (spec)\n" +
+		"  example.com/namespace (metadata)\n" +
+		"\n" +
+		"Conditions:\n" +
+		"  NamespaceDeletionDiscoveryFailure: Discovery failed for some groups, 1 failing: metrics.k8s.io/v1beta1\n" +
+		"  NamespaceFinalizersRemaining: Some content in the namespace has finalizers remaining: example.com/cleanup in 1 resource instances\n" +
+		"\n" +
+		"API groups whose discovery fails, their resources cannot be deleted:\n" +
+		"  metrics.k8s.io/v1beta1: the server is currently unable to handle the request\n" +
+		"\n" +
+		"Remaining resources:\n" +
+		"RESOURCE              NAME       DELETING   FINALIZERS\n" +
+		"configmaps            settings   false      <none>\n" +
+		"widgets.example.com   w1         true       example.com/cleanup\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRun_notTerminating(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "stuck"}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}}
+	o, _, out := testOptions(ns)
+	o.RemoveFinalizers, o.Force = true, true
+	if err := o.Run(); err == nil {
+		t.Errorf("expected the finalizers of an active namespace to be kept")
+	}
+	if out.String() != "Namespace stuck is Active, it is not being deleted.\n" {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRemoveFinalizers(t *testing.T) {
+	o, client, _ := testOptions(terminatingNamespace())
+	o.RemoveFinalizers = true
+	if err := o.Validate(); err == nil {
+		t.Fatalf("expected --remove-finalizers without --force to be rejected")
+	}
+	o.Force = true
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	var finalized *corev1.Namespace
+	client.PrependReactor("create", "namespaces", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "finalize" {
+			return false, nil, nil
+		}
+		finalized = action.(clienttesting.CreateAction).GetObject().(*corev1.Namespace)
+		return true, finalized, nil
+	})
+	if err := o.removeFinalizers(); err != nil {
+		t.Fatal(err)
+	}
+	if finalized == nil || len(finalized.Spec.Finalizers) > 0 {
+		t.Fatalf("expected the spec finalizers to be removed through the finalize subresource, got %#v", finalized)
+	}
+	if len(finalized.Finalizers) > 0 {
+		t.Errorf("expected the metadata finalizers to be removed, got %q", finalized.Finalizers)
+	}
+}
@@ -0,0 +1,261 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+
+	"github.com/openshift/oc/pkg/helpers/hostpod"
+)
+
+// clusterBackupScript is the script of the control plane nodes that takes a snapshot of etcd and saves the static pod
+// resources of the control plane.
+const clusterBackupScript = "/usr/local/bin/cluster-backup.sh"
+
+var (
+	backupLong = templates.LongDesc(`
+		Take a backup of etcd and download it.
+
+		The backup is taken on a control plane node with the cluster-backup.sh script of the node,
+		which saves a snapshot of etcd and the static pod resources of the control plane, as
+		documented for the backup of etcd. The script runs in a privileged pod in a temporary
+		namespace, and the backup is downloaded to --dest-dir and then removed from the node.
+
+		The backup is taken on the node of a ready etcd pod, unless --node is given.
+	`)
+
+	backupExample = templates.Examples(`
+		# Take a backup of etcd and download it to the current directory
+		oc adm etcd backup
+
+		# Take a backup of etcd on the node master-0 and download it to the directory backups
+		oc adm etcd backup --node=master-0 --dest-dir=backups
+	`)
+)
+
+// BackupOptions holds the options to take a backup of etcd.
+type BackupOptions struct {
+	NodeName string
+	DestDir  string
+	Image    string
+	Timeout  time.Duration
+
+	Client      kubernetes.Interface
+	ImageClient imagev1client.ImageV1Interface
+	Config      *rest.Config
+
+	genericclioptions.IOStreams
+}
+
+func NewBackupOptions(streams genericclioptions.IOStreams) *BackupOptions {
+	return &BackupOptions{
+		DestDir:   ".",
+		Timeout:   5 * time.Minute,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdBackup creates a command that takes a backup of etcd and downloads it.
+func NewCmdBackup(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewBackupOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "backup",
+		Short:   "Take a backup of etcd and download it",
+		Long:    backupLong,
+		Example: backupExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.NodeName, "node", o.NodeName, "The control plane node on which the backup is taken. Defaults to the node of a ready etcd pod.")
+	cmd.Flags().StringVar(&o.DestDir, "dest-dir", o.DestDir, "The local directory to download the backup to.")
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "The image of the backup pod. Defaults to the openshift/tools image stream, or the RHEL support tools if it does not exist.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The time to wait for the backup pod to start.")
+
+	return cmd
+}
+
+func (o *BackupOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	if o.Config, err = f.ToRESTConfig(); err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	if o.ImageClient, err = imagev1client.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	if len(o.Image) == 0 {
+		o.Image = hostpod.Image(o.ImageClient)
+	}
+	return nil
+}
+
+func (o *BackupOptions) Validate() error {
+	if len(o.DestDir) == 0 {
+		return fmt.Errorf("--dest-dir is required")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be greater than 0")
+	}
+	return nil
+}
+
+func (o *BackupOptions) Run() error {
+	nodeName, err := o.backupNode()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.DestDir, 0755); err != nil {
+		return err
+	}
+
+	namespace, cleanup, err := hostpod.CreateNamespace(o.Client, "oc adm etcd backup", o.ErrOut)
+	if err != nil {
+		return err
+	}
+
+	return interrupt.New(nil, cleanup).Run(func() error {
+		pod, err := hostpod.Start(o.Client, namespace, nodeName, "etcd-backup", o.Image, o.Timeout)
+		if err != nil {
+			return err
+		}
+
+		out := &bytes.Buffer{}
+		if err := o.exec(pod, out, "mktemp", "-d", "/var/tmp/etcd-backup-XXXXXX"); err != nil {
+			return fmt.Errorf("unable to create the backup directory on node %s: %v", nodeName, err)
+		}
+		dir := strings.TrimSpace(out.String())
+		defer func() {
+			if err := o.exec(pod, ioutil.Discard, "rm", "-rf", dir); err != nil {
+				fmt.Fprintf(o.ErrOut, "warning: unable to remove the backup directory %s of node %s: %v\n", dir, nodeName, err)
+			}
+		}()
+
+		fmt.Fprintf(o.ErrOut, "Taking a backup of etcd on node %s...\n", nodeName)
+		if err := o.exec(pod, o.ErrOut, clusterBackupScript, dir); err != nil {
+			return fmt.Errorf("the backup of etcd failed on node %s: %v", nodeName, err)
+		}
+
+		out.Reset()
+		if err := o.exec(pod, out, "ls", "-1", dir); err != nil {
+			return fmt.Errorf("unable to list the backup files on node %s: %v", nodeName, err)
+		}
+		files := backupFiles(out.String())
+		if len(files) == 0 {
+			return fmt.Errorf("the backup of etcd on node %s created no file", nodeName)
+		}
+		for _, file := range files {
+			if err := o.download(pod, path.Join(dir, file), filepath.Join(o.DestDir, file)); err != nil {
+				return fmt.Errorf("unable to download the backup file %s of node %s: %v", file, nodeName, err)
+			}
+		}
+		fmt.Fprintf(o.Out, "Downloaded the backup of etcd to %s\n", o.DestDir)
+		return nil
+	})
+}
+
+// backupNode returns the node on which the backup is taken, which must run a ready etcd pod.
+func (o *BackupOptions) backupNode() (string, error) {
+	pods, err := etcdPods(o.Client)
+	if err != nil {
+		return "", err
+	}
+	if len(o.NodeName) == 0 {
+		return pods[0].Spec.NodeName, nil
+	}
+	if _, err := o.Client.CoreV1().Nodes().Get(context.TODO(), o.NodeName, metav1.GetOptions{}); err != nil {
+		return "", err
+	}
+	for _, pod := range pods {
+		if pod.Spec.NodeName == o.NodeName {
+			return o.NodeName, nil
+		}
+	}
+	return "", fmt.Errorf("no etcd pod is ready on node %s", o.NodeName)
+}
+
+// exec runs command on the node of the host pod.
+func (o *BackupOptions) exec(pod *corev1.Pod, out io.Writer, command ...string) error {
+	errOut := &bytes.Buffer{}
+	if err := hostpod.Exec(o.Client, o.Config, pod, append([]string{"chroot", "/host"}, command...), nil, out, errOut); err != nil {
+		return hostpod.RemoteError(err, errOut)
+	}
+	return nil
+}
+
+// download copies the file of the node to the local file, which must not exist.
+func (o *BackupOptions) download(pod *corev1.Pod, remote, local string) error {
+	f, err := os.OpenFile(local, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	counter := &countingWriter{w: f}
+	err = o.exec(pod, counter, "cat", remote)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := os.Remove(local); removeErr != nil {
+			klog.V(2).Infof("Unable to remove %s: %v", local, removeErr)
+		}
+		return err
+	}
+	fmt.Fprintf(o.ErrOut, "Downloaded %s (%s)\n", local, units.BytesSize(float64(counter.n)))
+	return nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// backupFiles returns the names of the files listed by ls -1.
+func backupFiles(ls string) []string {
+	var files []string
+	for _, line := range strings.Split(ls, "\n") {
+		file := strings.TrimSpace(line)
+		if len(file) == 0 || strings.Contains(file, "/") {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files
+}
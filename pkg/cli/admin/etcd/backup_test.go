@@ -0,0 +1,14 @@
+package etcd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_backupFiles(t *testing.T) {
+	files := backupFiles("snapshot_2024-05-01_120000.db\nstatic_kuberesources_2024-05-01_120000.tar.gz\n\n")
+	expected := []string{"snapshot_2024-05-01_120000.db", "static_kuberesources_2024-05-01_120000.tar.gz"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("backupFiles() = %v, expected %v", files, expected)
+	}
+}
@@ -0,0 +1,113 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	kexec "k8s.io/kubectl/pkg/cmd/exec"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+const (
+	// etcdNamespace is the namespace of the etcd pods.
+	etcdNamespace = "openshift-etcd"
+	// etcdPodSelector selects the etcd pods, one per control plane node.
+	etcdPodSelector = "app=etcd"
+	// etcdctlContainer is the container of the etcd pods with etcdctl configured to reach the cluster.
+	etcdctlContainer = "etcdctl"
+)
+
+var (
+	etcdLong = templates.LongDesc(`
+		Manage the etcd cluster
+
+		This command checks the health of the etcd members and takes backups of etcd.`)
+)
+
+func NewCmdEtcd(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "etcd",
+		Short: "Manage the etcd cluster",
+		Long:  etcdLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdHealth(f, streams))
+	cmds.AddCommand(NewCmdBackup(f, streams))
+	return cmds
+}
+
+// etcdPods returns the running etcd pods, whose containers are all ready.
+func etcdPods(client kubernetes.Interface) ([]corev1.Pod, error) {
+	list, err := client.CoreV1().Pods(etcdNamespace).List(context.TODO(), metav1.ListOptions{LabelSelector: etcdPodSelector})
+	if err != nil {
+		return nil, err
+	}
+	var pods []corev1.Pod
+	for _, pod := range list.Items {
+		if podReady(&pod) {
+			pods = append(pods, pod)
+		}
+	}
+	if len(pods) == 0 {
+		return nil, fmt.Errorf("no etcd pod is ready in the namespace %s", etcdNamespace)
+	}
+	return pods, nil
+}
+
+// podReady returns true if the pod is running and its containers are ready.
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if !status.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// etcdctl runs etcdctl with args in the etcdctl container of the pod and returns its output.
+func etcdctl(client kubernetes.Interface, config *rest.Config, pod *corev1.Pod, args ...string) ([]byte, error) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	command := append([]string{"etcdctl"}, args...)
+	klog.V(3).Infof("Running command in pod %s/%s: %s", pod.Namespace, pod.Name, strings.Join(command, " "))
+	execOptions := &kexec.ExecOptions{
+		StreamOptions: kexec.StreamOptions{
+			Namespace:     pod.Namespace,
+			PodName:       pod.Name,
+			ContainerName: etcdctlContainer,
+			IOStreams: genericclioptions.IOStreams{
+				Out:    out,
+				ErrOut: errOut,
+			},
+		},
+		Executor:  &kexec.DefaultRemoteExecutor{},
+		PodClient: client.CoreV1(),
+		Config:    config,
+		Command:   command,
+	}
+	if err := execOptions.Validate(); err != nil {
+		return nil, err
+	}
+	err := execOptions.Run()
+	if err != nil {
+		if msg := strings.TrimSpace(errOut.String()); len(msg) > 0 {
+			err = fmt.Errorf("%v: %s", err, msg)
+		}
+	}
+	return out.Bytes(), err
+}
@@ -0,0 +1,321 @@
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	units "github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	healthLong = templates.LongDesc(`
+		Check the health of the etcd members.
+
+		The members, their health and their status are queried with etcdctl in one of the etcd pods.
+		The health, leadership, version, database size and raft index of every member are printed.
+		The database size is the size allocated on disk, and the size in use excludes the space that
+		defragmentation would release.
+
+		The command fails if a member is unhealthy or if the cluster has no leader.
+	`)
+
+	healthExample = templates.Examples(`
+		# Check the health of the etcd members
+		oc adm etcd health
+
+		# Print the health and status of the etcd members as JSON
+		oc adm etcd health -o json
+	`)
+)
+
+// HealthOptions holds the options to check the health of the etcd members.
+type HealthOptions struct {
+	Output string
+
+	Client kubernetes.Interface
+	Config *rest.Config
+
+	genericclioptions.IOStreams
+}
+
+func NewHealthOptions(streams genericclioptions.IOStreams) *HealthOptions {
+	return &HealthOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdHealth creates a command that checks the health of the etcd members.
+func NewCmdHealth(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewHealthOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "health",
+		Short:   "Check the health of the etcd members",
+		Long:    healthLong,
+		Example: healthExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+
+	return cmd
+}
+
+func (o *HealthOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	if o.Config, err = f.ToRESTConfig(); err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *HealthOptions) Validate() error {
+	switch o.Output {
+	case "", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("--output must be one of: json, yaml")
+}
+
+func (o *HealthOptions) Run() error {
+	pods, err := etcdPods(o.Client)
+	if err != nil {
+		return err
+	}
+	pod := &pods[0]
+
+	members, err := etcdctl(o.Client, o.Config, pod, "member", "list", "-w", "json")
+	if err != nil {
+		return fmt.Errorf("unable to list the etcd members: %v", err)
+	}
+	// endpoint status and health print the endpoints they reached, and fail if any endpoint is unreachable or
+	// unhealthy, which is reported for the member
+	status, err := etcdctl(o.Client, o.Config, pod, "endpoint", "status", "--cluster", "-w", "json")
+	if err != nil && len(status) == 0 {
+		return fmt.Errorf("unable to get the status of the etcd members: %v", err)
+	}
+	health, err := etcdctl(o.Client, o.Config, pod, "endpoint", "health", "--cluster", "-w", "json")
+	if err != nil && len(health) == 0 {
+		return fmt.Errorf("unable to check the health of the etcd members: %v", err)
+	}
+
+	report, err := newHealthReport(members, status, health)
+	if err != nil {
+		return err
+	}
+
+	if len(o.Output) > 0 {
+		if err := cmdutil.PrintJSONOrYAML(o.Out, o.Output, report); err != nil {
+			return err
+		}
+	} else {
+		printHealthReport(o.Out, report)
+	}
+
+	unhealthy := 0
+	for _, member := range report.Members {
+		if !member.Healthy {
+			unhealthy++
+		}
+	}
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d etcd members are unhealthy", unhealthy, len(report.Members))
+	}
+	if len(report.Leader) == 0 {
+		return fmt.Errorf("the etcd cluster has no leader")
+	}
+	return nil
+}
+
+// healthReport is the health and status of the etcd members.
+type healthReport struct {
+	// Leader is the name of the leader, empty if no member reported a leader.
+	Leader  string         `json:"leader"`
+	Members []memberHealth `json:"members"`
+}
+
+// memberHealth is the health and status of an etcd member.
+type memberHealth struct {
+	Name        string `json:"name"`
+	ID          string `json:"id"`
+	Endpoint    string `json:"endpoint"`
+	Healthy     bool   `json:"healthy"`
+	Leader      bool   `json:"leader"`
+	Learner     bool   `json:"learner"`
+	Version     string `json:"version,omitempty"`
+	DBSize      int64  `json:"dbSize"`
+	DBSizeInUse int64  `json:"dbSizeInUse"`
+	RaftTerm    uint64 `json:"raftTerm"`
+	RaftIndex   uint64 `json:"raftIndex"`
+	Took        string `json:"took,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// etcdMemberList is the output of etcdctl member list -w json.
+type etcdMemberList struct {
+	Members []struct {
+		ID         uint64   `json:"ID"`
+		Name       string   `json:"name"`
+		ClientURLs []string `json:"clientURLs"`
+		IsLearner  bool     `json:"isLearner"`
+	} `json:"members"`
+}
+
+// etcdEndpointStatus is an item of the output of etcdctl endpoint status -w json.
+type etcdEndpointStatus struct {
+	Endpoint string `json:"Endpoint"`
+	Status   struct {
+		Version     string   `json:"version"`
+		DBSize      int64    `json:"dbSize"`
+		DBSizeInUse int64    `json:"dbSizeInUse"`
+		Leader      uint64   `json:"leader"`
+		RaftIndex   uint64   `json:"raftIndex"`
+		RaftTerm    uint64   `json:"raftTerm"`
+		Errors      []string `json:"errors"`
+	} `json:"Status"`
+}
+
+// etcdEndpointHealth is an item of the output of etcdctl endpoint health -w json.
+type etcdEndpointHealth struct {
+	Endpoint string `json:"endpoint"`
+	Health   bool   `json:"health"`
+	Took     string `json:"took"`
+	Error    string `json:"error"`
+}
+
+// newHealthReport combines the JSON output of etcdctl member list, endpoint status and endpoint health. Members
+// without status or health are unhealthy.
+func newHealthReport(memberList, status, health []byte) (*healthReport, error) {
+	var members etcdMemberList
+	if err := json.Unmarshal(memberList, &members); err != nil {
+		return nil, fmt.Errorf("unable to parse the etcd members: %v", err)
+	}
+	var statuses []etcdEndpointStatus
+	if err := json.Unmarshal(status, &statuses); err != nil {
+		return nil, fmt.Errorf("unable to parse the status of the etcd members: %v", err)
+	}
+	var healths []etcdEndpointHealth
+	if err := json.Unmarshal(health, &healths); err != nil {
+		return nil, fmt.Errorf("unable to parse the health of the etcd members: %v", err)
+	}
+
+	statusByEndpoint := map[string]etcdEndpointStatus{}
+	var leader uint64
+	for _, s := range statuses {
+		statusByEndpoint[s.Endpoint] = s
+		if s.Status.Leader != 0 {
+			leader = s.Status.Leader
+		}
+	}
+	healthByEndpoint := map[string]etcdEndpointHealth{}
+	for _, h := range healths {
+		healthByEndpoint[h.Endpoint] = h
+	}
+
+	report := &healthReport{}
+	for _, m := range members.Members {
+		member := memberHealth{
+			Name:    m.Name,
+			ID:      strconv.FormatUint(m.ID, 16),
+			Leader:  m.ID == leader,
+			Learner: m.IsLearner,
+		}
+		if member.Leader {
+			report.Leader = m.Name
+		}
+
+		if len(m.ClientURLs) > 0 {
+			member.Endpoint = m.ClientURLs[0]
+		}
+		var status *etcdEndpointStatus
+		for _, endpoint := range m.ClientURLs {
+			if s, ok := statusByEndpoint[endpoint]; ok {
+				member.Endpoint, status = endpoint, &s
+				break
+			}
+		}
+		switch {
+		case len(m.ClientURLs) == 0:
+			member.Error = "the member has no client URL"
+		case status == nil:
+			member.Error = "unable to get the status of the member"
+		default:
+			member.Version = status.Status.Version
+			member.DBSize = status.Status.DBSize
+			member.DBSizeInUse = status.Status.DBSizeInUse
+			member.RaftTerm = status.Status.RaftTerm
+			member.RaftIndex = status.Status.RaftIndex
+			member.Error = strings.Join(status.Status.Errors, "; ")
+		}
+
+		h, ok := healthByEndpoint[member.Endpoint]
+		switch {
+		case !ok && len(member.Error) == 0:
+			member.Error = "the health of the member is unknown"
+		case len(h.Error) > 0:
+			// the reason the member is unhealthy is more precise than the failure to get its status
+			member.Error = h.Error
+		}
+		member.Healthy = ok && h.Health && len(member.Error) == 0
+		member.Took = h.Took
+		report.Members = append(report.Members, member)
+	}
+	sort.Slice(report.Members, func(i, j int) bool { return report.Members[i].Name < report.Members[j].Name })
+	return report, nil
+}
+
+// printHealthReport prints a table of the members, followed by their errors.
+func printHealthReport(out io.Writer, report *healthReport) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NAME\tENDPOINT\tHEALTHY\tLEADER\tVERSION\tDB SIZE\tIN USE\tRAFT INDEX\tTOOK")
+	for _, m := range report.Members {
+		dbSize, inUse := "", ""
+		if m.DBSize > 0 {
+			dbSize = units.BytesSize(float64(m.DBSize))
+			inUse = fmt.Sprintf("%s (%d%%)", units.BytesSize(float64(m.DBSizeInUse)), m.DBSizeInUse*100/m.DBSize)
+		}
+		leader := ""
+		if m.Leader {
+			leader = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%s\t%s\t%d\t%s\n", m.Name, m.Endpoint, m.Healthy, leader, m.Version, dbSize, inUse, m.RaftIndex, m.Took)
+	}
+	w.Flush()
+
+	var problems []string
+	for _, m := range report.Members {
+		if len(m.Error) > 0 {
+			problems = append(problems, fmt.Sprintf("error: member %s: %s", m.Name, m.Error))
+		}
+	}
+	if len(report.Leader) == 0 {
+		problems = append(problems, "warning: no member reported a leader")
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(out, "\n%s\n", strings.Join(problems, "\n"))
+	}
+}
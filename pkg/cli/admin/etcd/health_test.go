@@ -0,0 +1,114 @@
+package etcd
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const (
+	testMemberList = `{"header":{"cluster_id":14841639068965178418,"member_id":10276657743932975437,"raft_term":8},"members":[
+		{"ID":10276657743932975437,"name":"master-0","peerURLs":["https://10.0.0.3:2380"],"clientURLs":["https://10.0.0.3:2379"]},
+		{"ID":2454811297357394437,"name":"master-1","peerURLs":["https://10.0.0.4:2380"],"clientURLs":["https://10.0.0.4:2379"]},
+		{"ID":17134734003429618823,"name":"master-2","peerURLs":["https://10.0.0.5:2380"],"clientURLs":["https://10.0.0.5:2379"]}]}`
+
+	testEndpointStatus = `[
+		{"Endpoint":"https://10.0.0.3:2379","Status":{"header":{"member_id":10276657743932975437,"revision":123456,"raft_term":8},"version":"3.5.9","dbSize":104857600,"leader":2454811297357394437,"raftIndex":2000,"raftTerm":8,"raftAppliedIndex":2000,"dbSizeInUse":52428800}},
+		{"Endpoint":"https://10.0.0.4:2379","Status":{"header":{"member_id":2454811297357394437,"revision":123456,"raft_term":8},"version":"3.5.9","dbSize":104857600,"leader":2454811297357394437,"raftIndex":2001,"raftTerm":8,"raftAppliedIndex":2001,"dbSizeInUse":78643200,"errors":["memberID:2454811297357394437 alarm:NOSPACE "]}}]`
+
+	testEndpointHealth = `[
+		{"endpoint":"https://10.0.0.3:2379","health":true,"took":"9.1ms"},
+		{"endpoint":"https://10.0.0.4:2379","health":true,"took":"10.2ms"},
+		{"endpoint":"https://10.0.0.5:2379","health":false,"took":"5s","error":"context deadline exceeded"}]`
+)
+
+func Test_newHealthReport(t *testing.T) {
+	report, err := newHealthReport([]byte(testMemberList), []byte(testEndpointStatus), []byte(testEndpointHealth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &healthReport{
+		Leader: "master-1",
+		Members: []memberHealth{
+			{
+				Name:        "master-0",
+				ID:          "8e9e05c52164694d",
+				Endpoint:    "https://10.0.0.3:2379",
+				Healthy:     true,
+				Version:     "3.5.9",
+				DBSize:      104857600,
+				DBSizeInUse: 52428800,
+				RaftTerm:    8,
+				RaftIndex:   2000,
+				Took:        "9.1ms",
+			},
+			{
+				Name:        "master-1",
+				ID:          "22113dde699a7205",
+				Endpoint:    "https://10.0.0.4:2379",
+				Leader:      true,
+				Version:     "3.5.9",
+				DBSize:      104857600,
+				DBSizeInUse: 78643200,
+				RaftTerm:    8,
+				RaftIndex:   2001,
+				Took:        "10.2ms",
+				Error:       "memberID:2454811297357394437 alarm:NOSPACE ",
+			},
+			{
+				Name:     "master-2",
+				ID:       "edcacdcadb3ab487",
+				Endpoint: "https://10.0.0.5:2379",
+				Took:     "5s",
+				Error:    "context deadline exceeded",
+			},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("unexpected report:\n%#v\nexpected:\n%#v", report, expected)
+	}
+}
+
+func Test_newHealthReport_unknownHealth(t *testing.T) {
+	report, err := newHealthReport([]byte(testMemberList), []byte(testEndpointStatus), []byte(`[]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, member := range report.Members {
+		if member.Healthy {
+			t.Errorf("member %s is healthy, but its health is unknown", member.Name)
+		}
+	}
+	if report.Members[0].Error != "the health of the member is unknown" {
+		t.Errorf("unexpected error %q", report.Members[0].Error)
+	}
+	if report.Members[2].Error != "unable to get the status of the member" {
+		t.Errorf("unexpected error %q", report.Members[2].Error)
+	}
+}
+
+func Test_newHealthReport_invalid(t *testing.T) {
+	if _, err := newHealthReport([]byte(testMemberList), []byte("Failed to get the status of endpoint"), []byte(testEndpointHealth)); err == nil {
+		t.Errorf("expected an error for an invalid status")
+	}
+}
+
+func Test_printHealthReport(t *testing.T) {
+	report := &healthReport{
+		Members: []memberHealth{
+			{Name: "master-0", Endpoint: "https://10.0.0.3:2379", Healthy: true, Version: "3.5.9", DBSize: 104857600, DBSizeInUse: 52428800, RaftIndex: 2000, Took: "9.1ms"},
+			{Name: "master-1", Endpoint: "https://10.0.0.4:2379", Took: "5s", Error: "context deadline exceeded"},
+		},
+	}
+	out := &bytes.Buffer{}
+	printHealthReport(out, report)
+	expected := "NAME       ENDPOINT                HEALTHY   LEADER   VERSION   DB SIZE   IN USE        RAFT INDEX   TOOK\n" +
+		"master-0   https://10.0.0.3:2379   true               3.5.9     100MiB    50MiB (50%)   2000         9.1ms\n" +
+		"master-1   https://10.0.0.4:2379   false                                                0            5s\n" +
+		"\n" +
+		"error: member master-1: context deadline exceeded\n" +
+		"warning: no member reported a leader\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
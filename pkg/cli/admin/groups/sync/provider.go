@@ -0,0 +1,59 @@
+package sync
+
+import (
+	"github.com/openshift/oc/pkg/helpers/groupsync"
+	"github.com/openshift/oc/pkg/helpers/groupsync/interfaces"
+	"github.com/openshift/oc/pkg/helpers/groupsync/provider"
+)
+
+var _ SyncBuilder = &ProviderBuilder{}
+var _ PruneBuilder = &ProviderBuilder{}
+
+// ProviderBuilder builds the parts of a group sync job with a group provider that is not an LDAP server
+type ProviderBuilder struct {
+	Config *provider.GroupSyncConfig
+
+	provider provider.Interface
+}
+
+func (b *ProviderBuilder) GetGroupLister() (interfaces.LDAPGroupLister, error) {
+	return b.getProvider()
+}
+
+func (b *ProviderBuilder) GetGroupNameMapper() (interfaces.LDAPGroupNameMapper, error) {
+	return b.getProvider()
+}
+
+func (b *ProviderBuilder) GetUserNameMapper() (interfaces.LDAPUserNameMapper, error) {
+	return syncgroups.NewUserNameMapper(b.Config.UserNameAttributes()), nil
+}
+
+func (b *ProviderBuilder) GetGroupMemberExtractor() (interfaces.LDAPMemberExtractor, error) {
+	return b.getProvider()
+}
+
+func (b *ProviderBuilder) GetGroupDetector() (interfaces.LDAPGroupDetector, error) {
+	return b.getProvider()
+}
+
+// Host returns the host:port of the provider
+func (b *ProviderBuilder) Host() (string, error) {
+	p, err := b.getProvider()
+	if err != nil {
+		return "", err
+	}
+	return p.Host(), nil
+}
+
+func (b *ProviderBuilder) getProvider() (provider.Interface, error) {
+	if b.provider != nil {
+		return b.provider, nil
+	}
+
+	p, err := provider.New(b.Config)
+	if err != nil {
+		return nil, err
+	}
+	b.provider = p
+	return b.provider, nil
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/openshift/library-go/pkg/security/ldapclient"
 	"github.com/openshift/oc/pkg/helpers/groupsync"
 	"github.com/openshift/oc/pkg/helpers/groupsync/ldap"
+	"github.com/openshift/oc/pkg/helpers/groupsync/provider"
 )
 
 var (
@@ -51,6 +52,8 @@ type PruneOptions struct {
 	// Config is the LDAP sync config read from file
 	Config     *legacyconfigv1.LDAPSyncConfig
 	ConfigFile string
+	// ProviderConfig is the sync config of a provider that is not an LDAP server read from file, instead of Config
+	ProviderConfig *provider.GroupSyncConfig
 
 	// Whitelist are the names of OpenShift group or LDAP group UIDs to use for syncing
 	Whitelist     []string
@@ -105,17 +108,17 @@ func NewCmdPruneGroups(name, fullName string, f kcmdutil.Factory, streams generi
 
 func (o *PruneOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
 	var err error
-	o.Config, err = decodeSyncConfigFromFile(o.ConfigFile)
+	o.Config, o.ProviderConfig, err = decodeSyncConfigFromFile(o.ConfigFile)
 	if err != nil {
 		return err
 	}
 
-	o.Whitelist, err = buildOpenShiftGroupNameList(args, o.WhitelistFile, o.Config.LDAPGroupUIDToOpenShiftGroupNameMapping)
+	o.Whitelist, err = buildOpenShiftGroupNameList(args, o.WhitelistFile, o.GetGroupNameMappings())
 	if err != nil {
 		return err
 	}
 
-	o.Blacklist, err = buildOpenShiftGroupNameList([]string{}, o.BlacklistFile, o.Config.LDAPGroupUIDToOpenShiftGroupNameMapping)
+	o.Blacklist, err = buildOpenShiftGroupNameList([]string{}, o.BlacklistFile, o.GetGroupNameMappings())
 	if err != nil {
 		return err
 	}
@@ -133,12 +136,15 @@ func (o *PruneOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []s
 }
 
 func (o *PruneOptions) Validate() error {
-	results := ldap.ValidateLDAPSyncConfig(o.Config)
+	results := validateSyncConfig(o.Config, o.ProviderConfig)
 	if o.GroupClient == nil {
 		results.Errors = append(results.Errors, field.Required(field.NewPath("groupInterface"), ""))
 	}
 	// TODO(skuznets): pretty-print validation results
 	if len(results.Errors) > 0 {
+		if o.ProviderConfig != nil {
+			return fmt.Errorf("validation of group sync config failed: %v", results.Errors.ToAggregate())
+		}
 		return fmt.Errorf("validation of LDAP sync config failed: %v", results.Errors.ToAggregate())
 	}
 	return nil
@@ -147,23 +153,14 @@ func (o *PruneOptions) Validate() error {
 // Run creates the GroupSyncer specified and runs it to sync groups
 // the arguments are only here because its the only way to get the printer we need
 func (o *PruneOptions) Run() error {
-	bindPassword, err := ldap.ResolveStringValue(o.Config.BindPassword)
-	if err != nil {
-		return err
-	}
-	clientConfig, err := ldapclient.NewLDAPClientConfig(o.Config.URL, o.Config.BindDN, bindPassword, o.Config.CA, o.Config.Insecure)
-	if err != nil {
-		return fmt.Errorf("could not determine LDAP client configuration: %v", err)
-	}
-
-	pruneBuilder, err := buildPruneBuilder(clientConfig, o.Config)
+	host, pruneBuilder, err := o.newPruneBuilder()
 	if err != nil {
 		return err
 	}
 
 	// populate schema-independent pruner fields
 	pruner := &syncgroups.LDAPGroupPruner{
-		Host:        clientConfig.Host(),
+		Host:        host,
 		GroupClient: o.GroupClient.Groups(),
 		DryRun:      !o.Confirm,
 
@@ -171,7 +168,7 @@ func (o *PruneOptions) Run() error {
 		Err: o.ErrOut,
 	}
 
-	listerMapper, err := getOpenShiftGroupListerMapper(clientConfig.Host(), o)
+	listerMapper, err := getOpenShiftGroupListerMapper(host, o)
 	if err != nil {
 		return err
 	}
@@ -189,6 +186,33 @@ func (o *PruneOptions) Run() error {
 
 }
 
+// newPruneBuilder returns the host:port of the provider of the sync config and the builder of the prune job
+func (o *PruneOptions) newPruneBuilder() (string, PruneBuilder, error) {
+	if o.ProviderConfig != nil {
+		builder := &ProviderBuilder{Config: o.ProviderConfig}
+		host, err := builder.Host()
+		if err != nil {
+			return "", nil, err
+		}
+		return host, builder, nil
+	}
+
+	bindPassword, err := ldap.ResolveStringValue(o.Config.BindPassword)
+	if err != nil {
+		return "", nil, err
+	}
+	clientConfig, err := ldapclient.NewLDAPClientConfig(o.Config.URL, o.Config.BindDN, bindPassword, o.Config.CA, o.Config.Insecure)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not determine LDAP client configuration: %v", err)
+	}
+
+	pruneBuilder, err := buildPruneBuilder(clientConfig, o.Config)
+	if err != nil {
+		return "", nil, err
+	}
+	return clientConfig.Host(), pruneBuilder, nil
+}
+
 func buildPruneBuilder(clientConfig ldapclient.Config, pruneConfig *legacyconfigv1.LDAPSyncConfig) (PruneBuilder, error) {
 	switch {
 	case pruneConfig.RFC2307Config != nil:
@@ -217,5 +241,8 @@ func (o *PruneOptions) GetClient() userv1typedclient.GroupInterface {
 }
 
 func (o *PruneOptions) GetGroupNameMappings() map[string]string {
+	if o.ProviderConfig != nil {
+		return o.ProviderConfig.GroupUIDToOpenShiftGroupNameMapping
+	}
 	return o.Config.LDAPGroupUIDToOpenShiftGroupNameMapping
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrs "k8s.io/apimachinery/pkg/util/errors"
@@ -21,13 +22,16 @@ import (
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
 
 	legacyconfigv1 "github.com/openshift/api/legacyconfig/v1"
 	userv1typedclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	"github.com/openshift/library-go/pkg/config/validation"
 	"github.com/openshift/library-go/pkg/security/ldapclient"
 	syncgroups "github.com/openshift/oc/pkg/helpers/groupsync"
 	"github.com/openshift/oc/pkg/helpers/groupsync/interfaces"
 	"github.com/openshift/oc/pkg/helpers/groupsync/ldap"
+	"github.com/openshift/oc/pkg/helpers/groupsync/provider"
 	"github.com/openshift/oc/pkg/helpers/groupsync/syncerror"
 )
 
@@ -44,6 +48,12 @@ var (
 		requested from the external record store and migrated to OpenShift records. Default behavior is to do a dry-run
 		without changing OpenShift records. Passing '--confirm' will sync all groups from the LDAP server returned by the
 		LDAP query templates.
+
+		Groups may also be synced with a SCIM 2.0 service provider, the admin API of a Keycloak realm or the Microsoft
+		Graph API of an Azure Active Directory tenant, with a sync configuration file of kind GroupSyncConfig. The groups
+		of these providers are identified by their id in the provider, which whitelist and blacklist entries refer to
+		with '--type=ldap', and the members of a group are mapped to OpenShift users with the userNameAttributes of the
+		provider.
 	`)
 
 	syncExamples = templates.Examples(`
@@ -61,6 +71,17 @@ var (
 
 		# Sync specific OpenShift groups if they have been synced previously with an LDAP server
 		oc adm groups sync groups/group1 groups/group2 groups/group3 --sync-config=/path/to/sync-config.yaml --confirm
+
+		# Sync all groups with a Keycloak realm, using a sync config such as:
+		#   kind: GroupSyncConfig
+		#   apiVersion: v1
+		#   url: https://keycloak.example.com
+		#   keycloak:
+		#     realm: example
+		#     clientID: openshift-group-sync
+		#     clientSecret:
+		#       file: /path/to/client-secret
+		oc adm groups sync --sync-config=/path/to/keycloak-sync-config.yaml --confirm
 	`)
 )
 
@@ -86,6 +107,8 @@ type SyncOptions struct {
 	// Config is the LDAP sync config read from file
 	Config     *legacyconfigv1.LDAPSyncConfig
 	ConfigFile string
+	// ProviderConfig is the sync config of a provider that is not an LDAP server read from file, instead of Config
+	ProviderConfig *provider.GroupSyncConfig
 
 	// Whitelist are the names of OpenShift group or LDAP group UIDs to use for syncing
 	Whitelist     []string
@@ -156,17 +179,17 @@ func (o *SyncOptions) Complete(f kcmdutil.Factory, args []string) error {
 	}
 
 	var err error
-	o.Config, err = decodeSyncConfigFromFile(o.ConfigFile)
+	o.Config, o.ProviderConfig, err = decodeSyncConfigFromFile(o.ConfigFile)
 	if err != nil {
 		return err
 	}
 
 	if o.Source == GroupSyncSourceOpenShift {
-		o.Whitelist, err = buildOpenShiftGroupNameList(args, o.WhitelistFile, o.Config.LDAPGroupUIDToOpenShiftGroupNameMapping)
+		o.Whitelist, err = buildOpenShiftGroupNameList(args, o.WhitelistFile, o.GetGroupNameMappings())
 		if err != nil {
 			return err
 		}
-		o.Blacklist, err = buildOpenShiftGroupNameList([]string{}, o.BlacklistFile, o.Config.LDAPGroupUIDToOpenShiftGroupNameMapping)
+		o.Blacklist, err = buildOpenShiftGroupNameList([]string{}, o.BlacklistFile, o.GetGroupNameMappings())
 		if err != nil {
 			return err
 		}
@@ -244,22 +267,45 @@ func buildNameList(args []string, file string) ([]string, error) {
 	return list, nil
 }
 
-func decodeSyncConfigFromFile(configFile string) (*legacyconfigv1.LDAPSyncConfig, error) {
+// decodeSyncConfigFromFile decodes either an LDAP sync config, or the sync config of a provider that is not an LDAP
+// server if the kind of the file is GroupSyncConfig
+func decodeSyncConfigFromFile(configFile string) (*legacyconfigv1.LDAPSyncConfig, *provider.GroupSyncConfig, error) {
 	yamlConfig, err := ioutil.ReadFile(configFile)
 	if err != nil {
-		return nil, fmt.Errorf("could not read file %s: %v", configFile, err)
+		return nil, nil, fmt.Errorf("could not read file %s: %v", configFile, err)
+	}
+
+	typeMeta := metav1.TypeMeta{}
+	if err := yaml.Unmarshal(yamlConfig, &typeMeta); err == nil && typeMeta.Kind == provider.GroupSyncConfigKind {
+		providerConfig := &provider.GroupSyncConfig{}
+		if err := yaml.UnmarshalStrict(yamlConfig, providerConfig); err != nil {
+			return nil, nil, fmt.Errorf("could not parse file %s: %v", configFile, err)
+		}
+		if err := helpers.ResolvePaths(provider.GetStringSourceFileReferences(providerConfig), configFile); err != nil {
+			return nil, nil, fmt.Errorf("could not relativize files %s: %v", configFile, err)
+		}
+		return nil, providerConfig, nil
 	}
+
 	uncast, err := helpers.ReadYAML(bytes.NewBuffer([]byte(yamlConfig)), legacyconfigv1.InstallLegacy)
 	if err != nil {
-		return nil, fmt.Errorf("could not parse file %s: %v", configFile, err)
+		return nil, nil, fmt.Errorf("could not parse file %s: %v", configFile, err)
 	}
 	ldapConfig := uncast.(*legacyconfigv1.LDAPSyncConfig)
 
 	if err := helpers.ResolvePaths(ldap.GetStringSourceFileReferences(&ldapConfig.BindPassword), configFile); err != nil {
-		return nil, fmt.Errorf("could not relativize files %s: %v", configFile, err)
+		return nil, nil, fmt.Errorf("could not relativize files %s: %v", configFile, err)
 	}
 
-	return ldapConfig, nil
+	return ldapConfig, nil, nil
+}
+
+// validateSyncConfig validates either sync config
+func validateSyncConfig(ldapConfig *legacyconfigv1.LDAPSyncConfig, providerConfig *provider.GroupSyncConfig) validation.ValidationResults {
+	if providerConfig != nil {
+		return provider.ValidateGroupSyncConfig(providerConfig)
+	}
+	return ldap.ValidateLDAPSyncConfig(ldapConfig)
 }
 
 // openshiftGroupNamesOnlyBlacklist returns back a list that contains only the names of the groups.
@@ -316,12 +362,15 @@ func (o *SyncOptions) Validate() error {
 		return fmt.Errorf("sync source must be one of the following: %v", strings.Join(AllowedSourceTypes, ","))
 	}
 
-	results := ldap.ValidateLDAPSyncConfig(o.Config)
+	results := validateSyncConfig(o.Config, o.ProviderConfig)
 	if o.GroupClient == nil {
 		results.Errors = append(results.Errors, field.Required(field.NewPath("groupInterface"), ""))
 	}
 	// TODO(skuznets): pretty-print validation results
 	if len(results.Errors) > 0 {
+		if o.ProviderConfig != nil {
+			return fmt.Errorf("validation of group sync config failed: %v", results.Errors.ToAggregate())
+		}
 		return fmt.Errorf("validation of LDAP sync config failed: %v", results.Errors.ToAggregate())
 	}
 	return nil
@@ -345,25 +394,14 @@ func (o *SyncOptions) CreateErrorHandler() syncerror.Handler {
 // Run creates the GroupSyncer specified and runs it to sync groups
 // the arguments are only here because its the only way to get the printer we need
 func (o *SyncOptions) Run() error {
-	bindPassword, err := ldap.ResolveStringValue(o.Config.BindPassword)
-	if err != nil {
-		return err
-	}
-	clientConfig, err := ldapclient.NewLDAPClientConfig(o.Config.URL, o.Config.BindDN, bindPassword, o.Config.CA, o.Config.Insecure)
-	if err != nil {
-		return fmt.Errorf("could not determine LDAP client configuration: %v", err)
-	}
-
-	errorHandler := o.CreateErrorHandler()
-
-	syncBuilder, err := buildSyncBuilder(clientConfig, o.Config, errorHandler)
+	host, syncBuilder, err := o.newSyncBuilder()
 	if err != nil {
 		return err
 	}
 
 	// populate schema-independent syncer fields
 	syncer := &syncgroups.LDAPGroupSyncer{
-		Host:        clientConfig.Host(),
+		Host:        host,
 		GroupClient: o.GroupClient.Groups(),
 		DryRun:      !o.Confirm,
 
@@ -375,7 +413,7 @@ func (o *SyncOptions) Run() error {
 	case GroupSyncSourceOpenShift:
 		// when your source of ldapGroupUIDs is from an openshift group, the mapping of ldapGroupUID to openshift group name is logically
 		// pinned by the existing mapping.
-		listerMapper, err := getOpenShiftGroupListerMapper(clientConfig.Host(), o)
+		listerMapper, err := getOpenShiftGroupListerMapper(host, o)
 		if err != nil {
 			return err
 		}
@@ -435,6 +473,35 @@ func (o *SyncOptions) Run() error {
 	return kerrs.NewAggregate(syncErrors)
 }
 
+// newSyncBuilder returns the host:port of the provider of the sync config and the builder of the sync job
+func (o *SyncOptions) newSyncBuilder() (string, SyncBuilder, error) {
+	if o.ProviderConfig != nil {
+		builder := &ProviderBuilder{Config: o.ProviderConfig}
+		host, err := builder.Host()
+		if err != nil {
+			return "", nil, err
+		}
+		return host, builder, nil
+	}
+
+	bindPassword, err := ldap.ResolveStringValue(o.Config.BindPassword)
+	if err != nil {
+		return "", nil, err
+	}
+	clientConfig, err := ldapclient.NewLDAPClientConfig(o.Config.URL, o.Config.BindDN, bindPassword, o.Config.CA, o.Config.Insecure)
+	if err != nil {
+		return "", nil, fmt.Errorf("could not determine LDAP client configuration: %v", err)
+	}
+
+	errorHandler := o.CreateErrorHandler()
+
+	syncBuilder, err := buildSyncBuilder(clientConfig, o.Config, errorHandler)
+	if err != nil {
+		return "", nil, err
+	}
+	return clientConfig.Host(), syncBuilder, nil
+}
+
 func buildSyncBuilder(clientConfig ldapclient.Config, syncConfig *legacyconfigv1.LDAPSyncConfig, errorHandler syncerror.Handler) (SyncBuilder, error) {
 	switch {
 	case syncConfig.RFC2307Config != nil:
@@ -508,5 +575,8 @@ func (o *SyncOptions) GetClient() userv1typedclient.GroupInterface {
 }
 
 func (o *SyncOptions) GetGroupNameMappings() map[string]string {
+	if o.ProviderConfig != nil {
+		return o.ProviderConfig.GroupUIDToOpenShiftGroupNameMapping
+	}
 	return o.Config.LDAPGroupUIDToOpenShiftGroupNameMapping
 }
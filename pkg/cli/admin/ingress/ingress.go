@@ -0,0 +1,32 @@
+package ingress
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	ingressLong = templates.LongDesc(`
+		Report on the ingress controllers and their routes
+
+		The ingress controllers of the cluster expose the routes of the projects. These commands
+		report how the routes are distributed between the ingress controllers, and the problems of
+		the routes that prevent them from being served, such as invalid certificates or host names
+		claimed by several projects.`)
+)
+
+func NewCmdIngress(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "ingress",
+		Short: "Report on the ingress controllers and their routes",
+		Long:  ingressLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdReport(f, streams))
+	return cmds
+}
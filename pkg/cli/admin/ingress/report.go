@@ -0,0 +1,348 @@
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	certutil "k8s.io/client-go/util/cert"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	operatorclient "github.com/openshift/client-go/operator/clientset/versioned"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	reportLong = templates.LongDesc(`
+		Report the routes of the ingress controllers and their problems
+
+		The report lists:
+
+		* for every ingress controller, its domain, its namespace ownership policy and the number
+		  of routes it admitted and rejected
+		* the certificates of the routes that expire within --expiring-within, or that are
+		  invalid: expired, not matching the host or the key of the route, or self-signed
+		* the host names claimed by routes of several namespaces, which the ingress controllers
+		  with the Strict namespace ownership policy reject, along with the routes rejected for
+		  a host already claimed
+
+		Only the certificates set on the routes are inspected, the routes without certificate are
+		served with the default certificate of their ingress controller.
+	`)
+
+	reportExample = templates.Examples(`
+		# Report the routes of the ingress controllers and their problems
+		oc adm ingress report
+
+		# Report the route certificates that expire within 60 days as JSON
+		oc adm ingress report --expiring-within=1440h -o json
+	`)
+)
+
+// ingressOperatorNamespace holds the ingress controllers.
+const ingressOperatorNamespace = "openshift-ingress-operator"
+
+// ReportOptions holds the options to report the routes of the ingress controllers.
+type ReportOptions struct {
+	ExpiringWithin time.Duration
+	Output         string
+
+	OperatorClient operatorclient.Interface
+	RouteClient    routev1client.RouteV1Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewReportOptions(streams genericclioptions.IOStreams) *ReportOptions {
+	return &ReportOptions{
+		ExpiringWithin: 30 * 24 * time.Hour,
+		IOStreams:      streams,
+	}
+}
+
+// NewCmdReport creates a command that reports the routes of the ingress controllers and their problems.
+func NewCmdReport(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewReportOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "report",
+		Short:   "Report the routes of the ingress controllers and their problems",
+		Long:    reportLong,
+		Example: reportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.ExpiringWithin, "expiring-within", o.ExpiringWithin, "Report the route certificates that expire within this duration.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+
+	return cmd
+}
+
+func (o *ReportOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.OperatorClient, err = operatorclient.NewForConfig(config); err != nil {
+		return err
+	}
+	o.RouteClient, err = routev1client.NewForConfig(config)
+	return err
+}
+
+func (o *ReportOptions) Validate() error {
+	if o.ExpiringWithin < 0 {
+		return fmt.Errorf("--expiring-within must not be negative")
+	}
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be one of: json, yaml")
+	}
+	return nil
+}
+
+// ingressReport is the routes of the ingress controllers and their problems.
+type ingressReport struct {
+	IngressControllers []controllerRoutes `json:"ingressControllers"`
+	Certificates       []routeCertificate `json:"certificates"`
+	HostConflicts      []hostConflict     `json:"hostConflicts"`
+}
+
+// controllerRoutes is the routes of an ingress controller.
+type controllerRoutes struct {
+	Name               string `json:"name"`
+	Domain             string `json:"domain"`
+	NamespaceOwnership string `json:"namespaceOwnership"`
+	Admitted           int    `json:"admitted"`
+	Rejected           int    `json:"rejected"`
+	// TLS counts the admitted routes that terminate TLS with their own certificate.
+	TLS int `json:"tls"`
+}
+
+// routeCertificate is the certificate of a route that expires soon or is invalid.
+type routeCertificate struct {
+	Route    string    `json:"route"`
+	Host     string    `json:"host"`
+	NotAfter time.Time `json:"notAfter,omitempty"`
+	Problems []string  `json:"problems"`
+}
+
+// hostConflict is a host claimed by routes of several namespaces.
+type hostConflict struct {
+	Host   string   `json:"host"`
+	Routes []string `json:"routes"`
+	// Rejected are the routes rejected because another namespace claimed the host.
+	Rejected []string `json:"rejected,omitempty"`
+}
+
+func (o *ReportOptions) Run() error {
+	controllers, err := o.OperatorClient.OperatorV1().IngressControllers(ingressOperatorNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	routes, err := o.RouteClient.Routes(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	report := buildReport(time.Now(), controllers.Items, routes.Items, o.ExpiringWithin)
+
+	if len(o.Output) > 0 {
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, report)
+	}
+	printReport(o.Out, report, o.ExpiringWithin)
+	return nil
+}
+
+// buildReport counts the routes of every ingress controller, and finds the certificates that expire within the
+// duration or are invalid, and the hosts claimed by several namespaces.
+func buildReport(now time.Time, controllers []operatorv1.IngressController, routes []routev1.Route, expiringWithin time.Duration) ingressReport {
+	report := ingressReport{
+		IngressControllers: []controllerRoutes{},
+		Certificates:       []routeCertificate{},
+		HostConflicts:      []hostConflict{},
+	}
+	byName := map[string]*controllerRoutes{}
+	for _, controller := range controllers {
+		c := controllerRoutes{Name: controller.Name, Domain: controller.Status.Domain, NamespaceOwnership: string(operatorv1.StrictNamespaceOwnershipCheck)}
+		if len(c.Domain) == 0 {
+			c.Domain = controller.Spec.Domain
+		}
+		if controller.Spec.RouteAdmission != nil && len(controller.Spec.RouteAdmission.NamespaceOwnership) > 0 {
+			c.NamespaceOwnership = string(controller.Spec.RouteAdmission.NamespaceOwnership)
+		}
+		report.IngressControllers = append(report.IngressControllers, c)
+	}
+	sort.Slice(report.IngressControllers, func(i, j int) bool { return report.IngressControllers[i].Name < report.IngressControllers[j].Name })
+	for i := range report.IngressControllers {
+		byName[report.IngressControllers[i].Name] = &report.IngressControllers[i]
+	}
+
+	hostRoutes := map[string][]*routev1.Route{}
+	for i := range routes {
+		route := &routes[i]
+		hasCertificate := route.Spec.TLS != nil && len(route.Spec.TLS.Certificate) > 0
+		for _, ingress := range route.Status.Ingress {
+			controller, ok := byName[ingress.RouterName]
+			if !ok {
+				continue
+			}
+			switch admitted, _ := admission(&ingress); admitted {
+			case corev1.ConditionTrue:
+				controller.Admitted++
+				if hasCertificate {
+					controller.TLS++
+				}
+			case corev1.ConditionFalse:
+				controller.Rejected++
+			}
+		}
+		if len(route.Spec.Host) > 0 {
+			hostRoutes[route.Spec.Host] = append(hostRoutes[route.Spec.Host], route)
+		}
+		if !hasCertificate {
+			continue
+		}
+		if cert := inspectCertificate(now, route, expiringWithin); cert != nil {
+			report.Certificates = append(report.Certificates, *cert)
+		}
+	}
+	sort.Slice(report.Certificates, func(i, j int) bool { return report.Certificates[i].Route < report.Certificates[j].Route })
+
+	for host, routes := range hostRoutes {
+		namespaces := sets.NewString()
+		conflict := hostConflict{Host: host}
+		for _, route := range routes {
+			namespaces.Insert(route.Namespace)
+			name := route.Namespace + "/" + route.Name
+			conflict.Routes = append(conflict.Routes, name)
+			for i := range route.Status.Ingress {
+				if admitted, reason := admission(&route.Status.Ingress[i]); admitted == corev1.ConditionFalse && reason == "HostAlreadyClaimed" {
+					conflict.Rejected = append(conflict.Rejected, name)
+					break
+				}
+			}
+		}
+		if namespaces.Len() < 2 {
+			continue
+		}
+		sort.Strings(conflict.Routes)
+		sort.Strings(conflict.Rejected)
+		report.HostConflicts = append(report.HostConflicts, conflict)
+	}
+	sort.Slice(report.HostConflicts, func(i, j int) bool { return report.HostConflicts[i].Host < report.HostConflicts[j].Host })
+	return report
+}
+
+// admission returns the status and reason of the Admitted condition of the route by an ingress controller.
+func admission(ingress *routev1.RouteIngress) (corev1.ConditionStatus, string) {
+	for _, condition := range ingress.Conditions {
+		if condition.Type == routev1.RouteAdmitted {
+			return condition.Status, condition.Reason
+		}
+	}
+	return corev1.ConditionUnknown, ""
+}
+
+// inspectCertificate returns the certificate of the route if it expires within the duration or is invalid, nil
+// otherwise.
+func inspectCertificate(now time.Time, route *routev1.Route, expiringWithin time.Duration) *routeCertificate {
+	cert := &routeCertificate{Route: route.Namespace + "/" + route.Name, Host: route.Spec.Host}
+	certs, err := certutil.ParseCertsPEM([]byte(route.Spec.TLS.Certificate))
+	if err != nil {
+		cert.Problems = append(cert.Problems, fmt.Sprintf("invalid certificate: %v", err))
+		return cert
+	}
+	leaf := certs[0]
+	cert.NotAfter = leaf.NotAfter
+
+	switch {
+	case !now.Before(leaf.NotAfter):
+		cert.Problems = append(cert.Problems, fmt.Sprintf("expired %s ago", duration.HumanDuration(now.Sub(leaf.NotAfter))))
+	case leaf.NotAfter.Before(now.Add(expiringWithin)):
+		cert.Problems = append(cert.Problems, fmt.Sprintf("expires in %s", duration.HumanDuration(leaf.NotAfter.Sub(now))))
+	}
+	if now.Before(leaf.NotBefore) {
+		cert.Problems = append(cert.Problems, fmt.Sprintf("not valid before %s", leaf.NotBefore.UTC().Format(time.RFC3339)))
+	}
+	if len(route.Spec.Host) > 0 {
+		if err := leaf.VerifyHostname(route.Spec.Host); err != nil {
+			cert.Problems = append(cert.Problems, fmt.Sprintf("does not match the host: %v", err))
+		}
+	}
+	if len(route.Spec.TLS.Key) > 0 {
+		if _, err := tls.X509KeyPair([]byte(route.Spec.TLS.Certificate), []byte(route.Spec.TLS.Key)); err != nil {
+			cert.Problems = append(cert.Problems, fmt.Sprintf("does not match the key: %v", err))
+		}
+	}
+	if bytes.Equal(leaf.RawIssuer, leaf.RawSubject) && leaf.CheckSignatureFrom(leaf) == nil {
+		cert.Problems = append(cert.Problems, "self-signed")
+	}
+
+	if len(cert.Problems) == 0 {
+		return nil
+	}
+	return cert
+}
+
+func printReport(out io.Writer, report ingressReport, expiringWithin time.Duration) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "INGRESS CONTROLLER\tDOMAIN\tNAMESPACE OWNERSHIP\tADMITTED\tREJECTED\tTLS")
+	for _, c := range report.IngressControllers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\n", c.Name, c.Domain, c.NamespaceOwnership, c.Admitted, c.Rejected, c.TLS)
+	}
+	w.Flush()
+
+	if len(report.Certificates) == 0 {
+		fmt.Fprintf(out, "\nNo route certificate is invalid or expires within %s\n", duration.HumanDuration(expiringWithin))
+	} else {
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+		fmt.Fprintln(w, "ROUTE\tHOST\tNOT AFTER\tPROBLEMS")
+		for _, cert := range report.Certificates {
+			notAfter := "-"
+			if !cert.NotAfter.IsZero() {
+				notAfter = cert.NotAfter.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cert.Route, cert.Host, notAfter, strings.Join(cert.Problems, "; "))
+		}
+		w.Flush()
+	}
+
+	if len(report.HostConflicts) == 0 {
+		fmt.Fprintln(out, "\nNo host is claimed by several namespaces")
+		return
+	}
+	fmt.Fprintln(out, "\nHosts claimed by several namespaces:")
+	for _, conflict := range report.HostConflicts {
+		fmt.Fprintf(out, "  %s: %s", conflict.Host, strings.Join(conflict.Routes, ", "))
+		if len(conflict.Rejected) > 0 {
+			fmt.Fprintf(out, " (rejected: %s)", strings.Join(conflict.Rejected, ", "))
+		}
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,170 @@
+package ingress
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// testIssuer is a certificate and its key that sign other certificates.
+type testIssuer struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// testCertificate returns a PEM encoded certificate for host that expires at notAfter, and its key. The certificate
+// is signed by issuer, or self-signed when issuer is nil.
+func testCertificate(t *testing.T, host string, notAfter time.Time, issuer *testIssuer) (string, string, *testIssuer) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  issuer == nil,
+		BasicConstraintsValid: true,
+	}
+	parent, signer := template, key
+	if issuer != nil {
+		parent, signer = issuer.cert, issuer.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+		&testIssuer{cert: cert, key: key}
+}
+
+func testRoute(namespace, name, host string, tls *routev1.TLSConfig, ingresses ...routev1.RouteIngress) routev1.Route {
+	return routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       routev1.RouteSpec{Host: host, TLS: tls},
+		Status:     routev1.RouteStatus{Ingress: ingresses},
+	}
+}
+
+func admitted(router string, status corev1.ConditionStatus, reason string) routev1.RouteIngress {
+	return routev1.RouteIngress{RouterName: router, Conditions: []routev1.RouteIngressCondition{{Type: routev1.RouteAdmitted, Status: status, Reason: reason}}}
+}
+
+func TestBuildReport(t *testing.T) {
+	year := testNow.Add(365 * 24 * time.Hour)
+	_, _, ca := testCertificate(t, "ingress-ca", year.Add(time.Hour), nil)
+	validCert, validKey, _ := testCertificate(t, "shop.apps.example.com", year, ca)
+	expiringCert, _, _ := testCertificate(t, "api.apps.example.com", testNow.Add(10*24*time.Hour), ca)
+	selfSignedCert, _, _ := testCertificate(t, "admin.apps.example.com", year, nil)
+	otherCert, _, _ := testCertificate(t, "other.example.com", year, ca)
+
+	controllers := []operatorv1.IngressController{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Status: operatorv1.IngressControllerStatus{Domain: "apps.example.com"}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal"},
+			Spec: operatorv1.IngressControllerSpec{
+				Domain:         "internal.example.com",
+				RouteAdmission: &operatorv1.RouteAdmissionPolicy{NamespaceOwnership: operatorv1.InterNamespaceAllowedOwnershipCheck},
+			},
+		},
+	}
+	routes := []routev1.Route{
+		testRoute("shop", "shop", "shop.apps.example.com", &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: validCert, Key: validKey},
+			admitted("default", corev1.ConditionTrue, "")),
+		testRoute("api", "api", "api.apps.example.com", &routev1.TLSConfig{Termination: routev1.TLSTerminationReencrypt, Certificate: expiringCert, Key: validKey},
+			admitted("default", corev1.ConditionTrue, "")),
+		testRoute("admin", "admin", "admin.apps.example.com", &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: selfSignedCert},
+			admitted("default", corev1.ConditionFalse, "ExtendedValidationFailed")),
+		testRoute("other", "other", "wrong.apps.example.com", &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: otherCert},
+			admitted("default", corev1.ConditionTrue, "")),
+		testRoute("broken", "broken", "broken.apps.example.com", &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: "not a certificate"}),
+		testRoute("shop", "shop-internal", "shop.apps.example.com", nil, admitted("internal", corev1.ConditionTrue, "")),
+		testRoute("squatter", "shop", "shop.apps.example.com", nil, admitted("default", corev1.ConditionFalse, "HostAlreadyClaimed"), admitted("internal", corev1.ConditionTrue, "")),
+		testRoute("web", "web", "web.apps.example.com", &routev1.TLSConfig{Termination: routev1.TLSTerminationPassthrough}, admitted("sharded", corev1.ConditionTrue, "")),
+	}
+
+	report := buildReport(testNow, controllers, routes, 30*24*time.Hour)
+
+	wantControllers := []controllerRoutes{
+		{Name: "default", Domain: "apps.example.com", NamespaceOwnership: "Strict", Admitted: 3, Rejected: 2, TLS: 3},
+		{Name: "internal", Domain: "internal.example.com", NamespaceOwnership: "InterNamespaceAllowed", Admitted: 2},
+	}
+	if !reflect.DeepEqual(report.IngressControllers, wantControllers) {
+		t.Errorf("got ingress controllers %#v\nwant %#v", report.IngressControllers, wantControllers)
+	}
+
+	var gotProblems []string
+	for _, cert := range report.Certificates {
+		gotProblems = append(gotProblems, cert.Route)
+		gotProblems = append(gotProblems, cert.Problems...)
+	}
+	wantProblems := []string{
+		"admin/admin", "self-signed",
+		"api/api", "expires in 10d", "does not match the key: tls: private key does not match public key",
+		"broken/broken", "invalid certificate: data does not contain any valid RSA or ECDSA certificates",
+		"other/other", "does not match the host: x509: certificate is valid for other.example.com, not wrong.apps.example.com",
+	}
+	if !reflect.DeepEqual(gotProblems, wantProblems) {
+		t.Errorf("got certificate problems %q\nwant %q", gotProblems, wantProblems)
+	}
+
+	wantConflicts := []hostConflict{
+		{Host: "shop.apps.example.com", Routes: []string{"shop/shop", "shop/shop-internal", "squatter/shop"}, Rejected: []string{"squatter/shop"}},
+	}
+	if !reflect.DeepEqual(report.HostConflicts, wantConflicts) {
+		t.Errorf("got host conflicts %#v\nwant %#v", report.HostConflicts, wantConflicts)
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	report := ingressReport{
+		IngressControllers: []controllerRoutes{{Name: "default", Domain: "apps.example.com", NamespaceOwnership: "Strict", Admitted: 3, Rejected: 1, TLS: 2}},
+		Certificates: []routeCertificate{
+			{Route: "api/api", Host: "api.apps.example.com", NotAfter: testNow, Problems: []string{"expires in 10d", "self-signed"}},
+			{Route: "broken/broken", Host: "broken.apps.example.com", Problems: []string{"invalid certificate"}},
+		},
+		HostConflicts: []hostConflict{{Host: "shop.apps.example.com", Routes: []string{"shop/shop", "squatter/shop"}, Rejected: []string{"squatter/shop"}}},
+	}
+	out := &bytes.Buffer{}
+	printReport(out, report, 30*24*time.Hour)
+	want := `INGRESS CONTROLLER   DOMAIN             NAMESPACE OWNERSHIP   ADMITTED   REJECTED   TLS
+default              apps.example.com   Strict                3          1          2
+
+ROUTE           HOST                      NOT AFTER              PROBLEMS
+api/api         api.apps.example.com      2024-05-01T12:00:00Z   expires in 10d; self-signed
+broken/broken   broken.apps.example.com   -                      invalid certificate
+
+Hosts claimed by several namespaces:
+  shop.apps.example.com: shop/shop, squatter/shop (rejected: squatter/shop)
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,131 @@
+package inspect
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// objectFilter decides which objects are collected, from --since, --since-time, --field-selector and --exclude.
+// The zero value keeps everything.
+type objectFilter struct {
+	// since drops events last seen and pods that terminated before it, unless it is zero
+	since time.Time
+	// fieldSelector drops the objects that have all the selected fields but do not match it
+	fieldSelector fields.Selector
+	// exclude are patterns matched against RESOURCE[.GROUP] and RESOURCE[.GROUP]/NAME
+	exclude []string
+}
+
+func newObjectFilter(since time.Time, fieldSelector string, exclude []string) (*objectFilter, error) {
+	f := &objectFilter{since: since}
+	if len(fieldSelector) > 0 {
+		selector, err := fields.ParseSelector(fieldSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid field selector %q: %v", fieldSelector, err)
+		}
+		f.fieldSelector = selector
+	}
+	for _, pattern := range exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %v", pattern, err)
+		}
+		f.exclude = append(f.exclude, pattern)
+	}
+	return f, nil
+}
+
+// excluded returns true if resource, or the named object of resource when name is set, matches an exclude pattern.
+func (f *objectFilter) excluded(resource, name string) bool {
+	for _, pattern := range f.exclude {
+		target := resource
+		if strings.Contains(pattern, "/") {
+			if len(name) == 0 {
+				continue
+			}
+			target = resource + "/" + name
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// keep returns true if obj, an object of resource, is to be collected.
+func (f *objectFilter) keep(resource string, obj *unstructured.Unstructured) bool {
+	if f.excluded(resource, obj.GetName()) {
+		return false
+	}
+	if !f.since.IsZero() {
+		if last, ok := lastActive(obj); ok && last.Before(f.since) {
+			return false
+		}
+	}
+	if f.fieldSelector != nil {
+		set := fields.Set{}
+		for _, requirement := range f.fieldSelector.Requirements() {
+			value, found, err := unstructured.NestedFieldNoCopy(obj.Object, strings.Split(requirement.Field, ".")...)
+			if !found || err != nil {
+				// selectors only apply to the objects that have the selected fields
+				return true
+			}
+			set[requirement.Field] = fmt.Sprint(value)
+		}
+		return f.fieldSelector.Matches(set)
+	}
+	return true
+}
+
+// filterList returns the items of list that are to be collected.
+func (f *objectFilter) filterList(resource string, list *unstructured.UnstructuredList) *unstructured.UnstructuredList {
+	filtered := list.DeepCopy()
+	filtered.Items = nil
+	for _, item := range list.Items {
+		if f.keep(resource, &item) {
+			filtered.Items = append(filtered.Items, item)
+		}
+	}
+	return filtered
+}
+
+// lastActive returns when an event was last seen or a pod terminated. Other objects, and pods still running, have
+// no such time.
+func lastActive(obj *unstructured.Unstructured) (time.Time, bool) {
+	var paths [][]string
+	switch obj.GetKind() {
+	case "Event":
+		paths = [][]string{{"series", "lastObservedTime"}, {"lastTimestamp"}, {"eventTime"}, {"metadata", "creationTimestamp"}}
+	case "Pod":
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		if phase != "Succeeded" && phase != "Failed" {
+			return time.Time{}, false
+		}
+		statuses, _, _ := unstructured.NestedSlice(obj.Object, "status", "containerStatuses")
+		var last time.Time
+		for _, status := range statuses {
+			status, ok := status.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			finishedAt, _, _ := unstructured.NestedString(status, "state", "terminated", "finishedAt")
+			if t, err := time.Parse(time.RFC3339, finishedAt); err == nil && t.After(last) {
+				last = t
+			}
+		}
+		return last, !last.IsZero()
+	default:
+		return time.Time{}, false
+	}
+	for _, p := range paths {
+		value, _, _ := unstructured.NestedString(obj.Object, p...)
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
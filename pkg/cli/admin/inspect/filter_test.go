@@ -0,0 +1,91 @@
+package inspect
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestObjectFilter(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	event := func(name string, lastTimestamp time.Time) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":          "Event",
+			"metadata":      map[string]interface{}{"name": name},
+			"type":          "Normal",
+			"lastTimestamp": lastTimestamp.Format(time.RFC3339),
+		}}
+	}
+	pod := func(name, phase string, finishedAt time.Time) *unstructured.Unstructured {
+		status := map[string]interface{}{"phase": phase}
+		if !finishedAt.IsZero() {
+			status["containerStatuses"] = []interface{}{
+				map[string]interface{}{"state": map[string]interface{}{"terminated": map[string]interface{}{"finishedAt": finishedAt.Format(time.RFC3339)}}},
+			}
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":     "Pod",
+			"metadata": map[string]interface{}{"name": name},
+			"status":   status,
+		}}
+	}
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "ConfigMap",
+		"metadata": map[string]interface{}{"name": "config"},
+	}}
+
+	tests := []struct {
+		name          string
+		since         time.Time
+		fieldSelector string
+		exclude       []string
+		resource      string
+		obj           *unstructured.Unstructured
+		expected      bool
+	}{
+		{name: "no filter", resource: "events", obj: event("old", now.Add(-48*time.Hour)), expected: true},
+		{name: "old event", since: now.Add(-time.Hour), resource: "events", obj: event("old", now.Add(-48*time.Hour)), expected: false},
+		{name: "recent event", since: now.Add(-time.Hour), resource: "events", obj: event("new", now.Add(-time.Minute)), expected: true},
+		{name: "pod terminated before since", since: now.Add(-time.Hour), resource: "pods", obj: pod("done", "Succeeded", now.Add(-2*time.Hour)), expected: false},
+		{name: "pod terminated after since", since: now.Add(-time.Hour), resource: "pods", obj: pod("done", "Failed", now.Add(-time.Minute)), expected: true},
+		{name: "running pod", since: now.Add(-time.Hour), resource: "pods", obj: pod("running", "Running", time.Time{}), expected: true},
+		{name: "configmaps are not aged", since: now.Add(-time.Hour), resource: "configmaps", obj: configMap, expected: true},
+		{name: "field selector matches", fieldSelector: "status.phase!=Succeeded", resource: "pods", obj: pod("running", "Running", time.Time{}), expected: true},
+		{name: "field selector does not match", fieldSelector: "status.phase!=Succeeded", resource: "pods", obj: pod("done", "Succeeded", now), expected: false},
+		{name: "field selector without the field", fieldSelector: "status.phase!=Succeeded", resource: "configmaps", obj: configMap, expected: true},
+		{name: "excluded resource", exclude: []string{"configmaps"}, resource: "configmaps", obj: configMap, expected: false},
+		{name: "excluded name", exclude: []string{"pods/build-*"}, resource: "pods", obj: pod("build-1", "Running", time.Time{}), expected: false},
+		{name: "not excluded name", exclude: []string{"pods/build-*"}, resource: "pods", obj: pod("web-1", "Running", time.Time{}), expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := newObjectFilter(test.since, test.fieldSelector, test.exclude)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := f.keep(test.resource, test.obj); actual != test.expected {
+				t.Errorf("expected keep to return %v, got %v", test.expected, actual)
+			}
+		})
+	}
+
+	list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{*event("old", now.Add(-48*time.Hour)), *event("new", now)}}
+	f, err := newObjectFilter(now.Add(-time.Hour), "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filtered := f.filterList("events", list); len(filtered.Items) != 1 || filtered.Items[0].GetName() != "new" {
+		t.Errorf("expected only the new event to be kept, got %v", filtered.Items)
+	}
+	if len(list.Items) != 2 {
+		t.Errorf("expected the original list to be left alone, got %v", list.Items)
+	}
+
+	if _, err := newObjectFilter(time.Time{}, "status.phase", nil); err == nil {
+		t.Errorf("expected an invalid field selector to be rejected")
+	}
+	if _, err := newObjectFilter(time.Time{}, "", []string{"pods/["}); err == nil {
+		t.Errorf("expected an invalid exclude pattern to be rejected")
+	}
+}
@@ -40,6 +40,10 @@ var (
 		This command downloads the specified resource and any related
 		resources for the purpose of gathering debugging information.
 
+		The collection can be narrowed down: --since and --since-time skip events last seen and pods
+		that terminated before the given time along with older logs, --field-selector skips the objects
+		that do not match it, and --exclude skips resources or objects by name.
+
 		Experimental: This command is under active development and may change without notice.
 	`)
 
@@ -55,6 +59,9 @@ var (
 
 		# Collect debugging data for all clusteroperators and clusterversions
 		oc adm inspect clusteroperators,clusterversions
+
+		# Collect the last day of debugging data for a namespace, without secrets or completed pods
+		oc adm inspect ns/my-project --since=24h --exclude=secrets --field-selector=status.phase!=Succeeded
 	`)
 )
 
@@ -77,6 +84,9 @@ type InspectOptions struct {
 	rotatedPodLogs bool
 	sinceInt       int64
 	sinceTimestamp metav1.Time
+	fieldSelector  string
+	exclude        []string
+	filter         *objectFilter
 
 	// directory where all gathered data will be stored
 	DestDir string
@@ -96,10 +106,18 @@ func NewInspectOptions(streams genericclioptions.IOStreams) *InspectOptions {
 		printFlags:  printFlags,
 		configFlags: genericclioptions.NewConfigFlags(true),
 		overwrite:   true,
+		filter:      &objectFilter{},
 		IOStreams:   streams,
 	}
 }
 
+// SetLogsSince limits the collected logs, events and terminated pods like --since and --since-time do. It must be
+// called before Complete.
+func (o *InspectOptions) SetLogsSince(since time.Duration, sinceTime string) {
+	o.since = since
+	o.sinceTime = sinceTime
+}
+
 func NewCmdInspect(streams genericclioptions.IOStreams) *cobra.Command {
 	o := NewInspectOptions(streams)
 	cmd := &cobra.Command{
@@ -117,8 +135,10 @@ func NewCmdInspect(streams genericclioptions.IOStreams) *cobra.Command {
 	cmd.Flags().StringVar(&o.DestDir, "dest-dir", o.DestDir, "Root directory used for storing all gathered cluster operator data. Defaults to $(PWD)/inspect.local.<rand>")
 	cmd.Flags().StringVar(&o.eventFile, "events-file", o.eventFile, "A path to an events.json file to create a HTML page from")
 	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", o.allNamespaces, "If present, list the requested object(s) across all namespaces. Namespace in current context is ignored even if specified with --namespace.")
-	cmd.Flags().StringVar(&o.sinceTime, "since-time", o.sinceTime, "Only return logs after a specific date (RFC3339). Defaults to all logs. Only one of since-time / since may be used.")
-	cmd.Flags().DurationVar(&o.since, "since", o.since, "Only return logs newer than a relative duration like 5s, 2m, or 3h. Defaults to all logs. Only one of since-time / since may be used.")
+	cmd.Flags().StringVar(&o.sinceTime, "since-time", o.sinceTime, "Only return logs, events and terminated pods after a specific date (RFC3339). Defaults to all. Only one of since-time / since may be used.")
+	cmd.Flags().DurationVar(&o.since, "since", o.since, "Only return logs, events and terminated pods newer than a relative duration like 5s, 2m, or 3h. Defaults to all. Only one of since-time / since may be used.")
+	cmd.Flags().StringVar(&o.fieldSelector, "field-selector", o.fieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='. Only applies to objects that have the selected fields, e.g. --field-selector=status.phase!=Succeeded skips completed pods.")
+	cmd.Flags().StringSliceVar(&o.exclude, "exclude", o.exclude, "Skip the resources matching these patterns, given as RESOURCE[.GROUP] or RESOURCE[.GROUP]/NAME with shell wildcards, e.g. secrets or pods/build-*.")
 	cmd.Flags().BoolVar(&o.rotatedPodLogs, "rotated-pod-logs", o.rotatedPodLogs, "Experimental: If present, retrieve rotated log files that are available for selected pods. This can significantly increase the collected logs size. since/since-time is ignored for rotated logs.")
 
 	// The rotated-pod-logs option should be removed once support for retrieving rotated logs is added to kubelet
@@ -168,11 +188,18 @@ func (o *InspectOptions) Complete(args []string) error {
 	if o.since != 0 {
 		o.sinceInt = (int64(o.since.Round(time.Second).Seconds()))
 	}
+	var since time.Time
 	if len(o.sinceTime) > 0 {
 		o.sinceTimestamp, err = util.ParseRFC3339(o.sinceTime, metav1.Now)
 		if err != nil {
 			return err
 		}
+		since = o.sinceTimestamp.Time
+	} else if o.since > 0 {
+		since = time.Now().Add(-o.since)
+	}
+	if o.filter, err = newObjectFilter(since, o.fieldSelector, o.exclude); err != nil {
+		return err
 	}
 
 	printer, err := o.printFlags.ToPrinter()
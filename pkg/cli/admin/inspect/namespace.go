@@ -77,6 +77,10 @@ func (o *InspectOptions) gatherNamespaceData(baseDir, namespace string) error {
 	// gather specific pod data
 	if pods := resourcesToStore[corev1.SchemeGroupVersion.WithResource("pods")]; pods != nil {
 		for _, pod := range pods.(*unstructured.UnstructuredList).Items {
+			if !o.filter.keep("pods", &pod) {
+				klog.V(1).Infof("        Skipping filtered pod %q\n", pod.GetName())
+				continue
+			}
 			klog.V(1).Infof("        Gathering data for pod %q\n", pod.GetName())
 			structuredPod := &corev1.Pod{}
 			runtime.DefaultUnstructuredConverter.FromUnstructured(pod.Object, structuredPod)
@@ -33,6 +33,12 @@ func InspectResource(info *resource.Info, context *resourceContext, o *InspectOp
 	}
 	context.visited.Insert(infoToContextKey(info))
 
+	resourceName := info.ResourceMapping().Resource.GroupResource().String()
+	if unstr, ok := info.Object.(*unstructured.Unstructured); ok && !o.filter.keep(resourceName, unstr) {
+		klog.V(1).Infof("Skipping filtered resource: %q ...", infoToContextKey(info))
+		return nil
+	}
+
 	switch info.ResourceMapping().Resource.GroupResource() {
 	case configv1.GroupVersion.WithResource("clusteroperators").GroupResource():
 		unstr, ok := info.Object.(*unstructured.Unstructured)
@@ -108,6 +114,16 @@ func InspectResource(info *resource.Info, context *resourceContext, o *InspectOp
 			}
 		}
 
+		// drop the filtered items of lists
+		obj := info.Object
+		if list, ok := obj.(*unstructured.UnstructuredList); ok {
+			if o.filter.excluded(resourceName, "") {
+				klog.V(1).Infof("Skipping filtered resource: %q ...", infoToContextKey(info))
+				return nil
+			}
+			obj = o.filter.filterList(resourceName, list)
+		}
+
 		// save the current object to disk
 		dirPath := dirPathForInfo(o.DestDir, info)
 		filename := filenameForInfo(info)
@@ -116,7 +132,7 @@ func InspectResource(info *resource.Info, context *resourceContext, o *InspectOp
 			return err
 		}
 
-		return o.fileWriter.WriteFromResource(path.Join(dirPath, filename), info.Object)
+		return o.fileWriter.WriteFromResource(path.Join(dirPath, filename), obj)
 	}
 }
 
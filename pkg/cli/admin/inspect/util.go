@@ -284,6 +284,21 @@ func CreateEventFilterPage(rootDir string) error {
 	return createEventFilterPage(events, rootDir)
 }
 
+// GetAllEvents reads all events in rootDir recursively.
+func GetAllEvents(rootDir string) (*corev1.EventList, error) {
+	return getAllEventsRecursive(rootDir)
+}
+
+// CreateEventFilterPageWithEvents is like CreateEventFilterPage, with additional events that are no longer in rootDir.
+func CreateEventFilterPageWithEvents(rootDir string, additional *corev1.EventList) error {
+	events, err := getAllEventsRecursive(rootDir)
+	if err != nil {
+		return err
+	}
+	events.Items = append(events.Items, additional.Items...)
+	return createEventFilterPage(events, rootDir)
+}
+
 var (
 	coreScheme = runtime.NewScheme()
 	coreCodecs = serializer.NewCodecFactory(coreScheme)
@@ -0,0 +1,28 @@
+package mcp
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var mcpLong = templates.LongDesc(`
+	Inspect machine config pools
+
+	The commands here help administrators follow the rollout of machine configurations to
+	the nodes of each machine config pool.`)
+
+func NewCommandMCP(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "mcp",
+		Short: "Inspect machine config pools",
+		Long:  mcpLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdStatus(f, streams))
+	return cmds
+}
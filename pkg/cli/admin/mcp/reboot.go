@@ -0,0 +1,425 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc/pkg/helpers/conditions"
+)
+
+var (
+	rebootLong = templates.LongDesc(`
+		Reboot the nodes of a machine config pool, one rollout at a time.
+
+		The reboot is performed by the machine config operator: a machine config named
+		95-oc-initiated-reboot-POOL, that only writes the time of the request to
+		/etc/oc-initiated-reboot, is created or updated for the pool. The operator then renders a
+		new configuration for the pool and rolls it out like any other change, draining and
+		rebooting at most maxUnavailable nodes of the pool at a time.
+
+		The progress of every node is reported until the whole pool was rebooted and is updated.
+		--max-unavailable overrides the maxUnavailable of the pool until the reboot completes or
+		the command is interrupted, when the previous value is restored. The reboot itself goes on
+		if the command is interrupted.
+
+		The machine config is selected by the machine config selector of the pool. The nodes of
+		other pools that select the same machine configs, like custom pools of workers, are
+		rebooted as well.
+	`)
+
+	rebootExample = templates.Examples(`
+		# Reboot the workers
+		oc adm reboot-machine-config-pool worker
+
+		# Reboot the workers, two at a time, and give up waiting after 2 hours
+		oc adm reboot-machine-config-pool mcp/worker --max-unavailable=2 --timeout=2h
+	`)
+)
+
+const (
+	// rebootFile is the file that the reboot machine config writes on the nodes.
+	rebootFile = "/etc/oc-initiated-reboot"
+	// rebootMachineConfigPrefix is the name of the reboot machine config of a pool, without the pool.
+	rebootMachineConfigPrefix = "95-oc-initiated-reboot-"
+)
+
+var machineConfigsResource = schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigs"}
+
+type RebootOptions struct {
+	Pool           string
+	MaxUnavailable string
+	Timeout        time.Duration
+	Interval       time.Duration
+
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewRebootOptions(streams genericclioptions.IOStreams) *RebootOptions {
+	return &RebootOptions{
+		Interval:  10 * time.Second,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdRebootMachineConfigPool creates a command that reboots the nodes of a machine config pool.
+func NewCmdRebootMachineConfigPool(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewRebootOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "reboot-machine-config-pool POOL",
+		Short:   "Reboot the nodes of a machine config pool through the machine config operator",
+		Long:    rebootLong,
+		Example: rebootExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.MaxUnavailable, "max-unavailable", o.MaxUnavailable, "The number or percentage of nodes of the pool that may be rebooted at the same time, until the reboot completes. Defaults to the maxUnavailable of the pool.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The maximum time to wait for the pool to be rebooted. Defaults to no limit.")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "How often the progress of the reboot is checked.")
+
+	return cmd
+}
+
+func (o *RebootOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "exactly one machine config pool is required")
+	}
+	o.Pool = args[0]
+	for _, prefix := range []string{"mcp/", "machineconfigpool/", "machineconfigpools/"} {
+		o.Pool = strings.TrimPrefix(o.Pool, prefix)
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.DynamicClient, err = dynamic.NewForConfig(config); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *RebootOptions) Validate() error {
+	if len(o.Pool) == 0 || strings.Contains(o.Pool, "/") {
+		return fmt.Errorf("invalid machine config pool %q", o.Pool)
+	}
+	if len(o.MaxUnavailable) > 0 {
+		if _, err := parseMaxUnavailable(o.MaxUnavailable); err != nil {
+			return err
+		}
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("--timeout must be greater than or equal to 0")
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0")
+	}
+	return nil
+}
+
+// parseMaxUnavailable parses a positive number or percentage of nodes.
+func parseMaxUnavailable(value string) (intstr.IntOrString, error) {
+	maxUnavailable := intstr.Parse(value)
+	if maxUnavailable.Type == intstr.String {
+		percent := strings.TrimSuffix(maxUnavailable.StrVal, "%")
+		if percent == maxUnavailable.StrVal {
+			return maxUnavailable, fmt.Errorf("--max-unavailable must be a number or a percentage, not %q", value)
+		}
+		if v := intstr.Parse(percent); v.Type != intstr.Int || v.IntVal <= 0 || v.IntVal > 100 {
+			return maxUnavailable, fmt.Errorf("--max-unavailable must be a percentage between 1%% and 100%%, not %q", value)
+		}
+		return maxUnavailable, nil
+	}
+	if maxUnavailable.IntVal <= 0 {
+		return maxUnavailable, fmt.Errorf("--max-unavailable must be greater than 0")
+	}
+	return maxUnavailable, nil
+}
+
+func (o *RebootOptions) Run() error {
+	pool, err := o.getPool()
+	if err != nil {
+		return err
+	}
+	if pool.Spec.Paused {
+		return fmt.Errorf("the machine config pool %s is paused, its nodes would not be rebooted", pool.Name)
+	}
+	labels, err := machineConfigLabels(pool)
+	if err != nil {
+		return err
+	}
+	previous := pool.Spec.Configuration.Name
+
+	restore := func() {}
+	if len(o.MaxUnavailable) > 0 {
+		maxUnavailable, _ := parseMaxUnavailable(o.MaxUnavailable)
+		if err := o.patchMaxUnavailable(&maxUnavailable); err != nil {
+			return fmt.Errorf("unable to set the maxUnavailable of the pool: %v", err)
+		}
+		fmt.Fprintf(o.Out, "Set the maxUnavailable of machineconfigpool/%s to %s for the reboot\n", pool.Name, maxUnavailable.String())
+		restore = func() {
+			if err := o.patchMaxUnavailable(pool.Spec.MaxUnavailable); err != nil {
+				fmt.Fprintf(o.ErrOut, "error: unable to restore the maxUnavailable of machineconfigpool/%s: %v\n", pool.Name, err)
+				return
+			}
+			fmt.Fprintf(o.Out, "Restored the maxUnavailable of machineconfigpool/%s\n", pool.Name)
+		}
+	}
+
+	return interrupt.New(nil, restore).Run(func() error {
+		if err := o.applyRebootMachineConfig(labels); err != nil {
+			return err
+		}
+		return o.waitForReboot(previous)
+	})
+}
+
+func (o *RebootOptions) getPool() (*machineConfigPool, error) {
+	item, err := o.DynamicClient.Resource(machineConfigPoolsResource).Get(context.TODO(), o.Pool, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return toMachineConfigPool(item)
+}
+
+// patchMaxUnavailable sets the maxUnavailable of the pool, or removes it if it is nil.
+func (o *RebootOptions) patchMaxUnavailable(maxUnavailable *intstr.IntOrString) error {
+	patch, err := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"maxUnavailable": maxUnavailable}})
+	if err != nil {
+		return err
+	}
+	_, err = o.DynamicClient.Resource(machineConfigPoolsResource).Patch(context.TODO(), o.Pool, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// machineConfigLabels returns labels that the machine config selector of the pool selects.
+func machineConfigLabels(pool *machineConfigPool) (map[string]string, error) {
+	selector := pool.Spec.MachineConfigSelector
+	if selector == nil {
+		return nil, fmt.Errorf("the machine config pool %s has no machine config selector", pool.Name)
+	}
+	labels := map[string]string{}
+	for k, v := range selector.MatchLabels {
+		labels[k] = v
+	}
+	for _, requirement := range selector.MatchExpressions {
+		if requirement.Operator != metav1.LabelSelectorOpIn || len(requirement.Values) == 0 {
+			return nil, fmt.Errorf("the machine config selector of the pool %s is not supported, only equality and In requirements are", pool.Name)
+		}
+		// prefer the value named after the pool, like the role of a custom pool
+		value := requirement.Values[0]
+		for _, v := range requirement.Values {
+			if v == pool.Name {
+				value = v
+			}
+		}
+		labels[requirement.Key] = value
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("the machine config pool %s selects every machine config", pool.Name)
+	}
+	return labels, nil
+}
+
+// newRebootMachineConfig returns a machine config that writes the time of the reboot request to the nodes, so that
+// every request changes the rendered configuration of the pool.
+func newRebootMachineConfig(pool string, labels map[string]string, requested time.Time) *unstructured.Unstructured {
+	contents := "data:," + url.PathEscape(requested.UTC().Format(time.RFC3339)+"\n")
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfig",
+		"metadata": map[string]interface{}{
+			"name": rebootMachineConfigPrefix + pool,
+			"annotations": map[string]interface{}{
+				"oc.openshift.io/command": "oc adm reboot-machine-config-pool",
+			},
+		},
+		"spec": map[string]interface{}{
+			"config": map[string]interface{}{
+				"ignition": map[string]interface{}{"version": "3.2.0"},
+				"storage": map[string]interface{}{
+					"files": []interface{}{
+						map[string]interface{}{
+							"path":      rebootFile,
+							"mode":      int64(0644),
+							"overwrite": true,
+							"contents":  map[string]interface{}{"source": contents},
+						},
+					},
+				},
+			},
+		},
+	}}
+	l := map[string]string{}
+	for k, v := range labels {
+		l[k] = v
+	}
+	obj.SetLabels(l)
+	return obj
+}
+
+// applyRebootMachineConfig creates or updates the reboot machine config of the pool.
+func (o *RebootOptions) applyRebootMachineConfig(labels map[string]string) error {
+	mc := newRebootMachineConfig(o.Pool, labels, time.Now())
+	client := o.DynamicClient.Resource(machineConfigsResource)
+	existing, err := client.Get(context.TODO(), mc.GetName(), metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		if _, err := client.Create(context.TODO(), mc, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "machineconfig/%s created\n", mc.GetName())
+	case err != nil:
+		return err
+	default:
+		mc.SetResourceVersion(existing.GetResourceVersion())
+		if _, err := client.Update(context.TODO(), mc, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "machineconfig/%s updated\n", mc.GetName())
+	}
+	return nil
+}
+
+// waitForReboot waits for the pool to be updated to a configuration that replaces previous, reporting the progress
+// of every node.
+func (o *RebootOptions) waitForReboot(previous string) error {
+	timeout := o.Timeout
+	if timeout == 0 {
+		timeout = time.Duration(1<<63 - 1)
+	}
+
+	var target string
+	phases := map[string]string{}
+	err := wait.PollImmediate(o.Interval, timeout, func() (bool, error) {
+		pool, err := o.getPool()
+		if err != nil {
+			klog.V(2).Infof("Unable to get machine config pool %s: %v", o.Pool, err)
+			return false, nil
+		}
+		if pool.Spec.Configuration.Name == previous {
+			// the new configuration is not rendered yet
+			return false, nil
+		}
+		if target != pool.Spec.Configuration.Name {
+			target = pool.Spec.Configuration.Name
+			fmt.Fprintf(o.Out, "Rolling out %s to the nodes of machineconfigpool/%s\n", target, o.Pool)
+		}
+
+		nodes, err := o.KubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			klog.V(2).Infof("Unable to list nodes: %v", err)
+			return false, nil
+		}
+		// every pool is needed to tell apart the nodes of custom pools from the workers
+		pools, err := listMachineConfigPools(o.DynamicClient)
+		if err != nil {
+			klog.V(2).Infof("Unable to list machine config pools: %v", err)
+			return false, nil
+		}
+		statuses, err := poolStatuses(pools, nodes.Items)
+		if err != nil {
+			return false, err
+		}
+		var status *poolStatus
+		for i := range statuses {
+			if statuses[i].Name == o.Pool {
+				status = &statuses[i]
+			}
+		}
+		if status == nil {
+			return false, fmt.Errorf("the machine config pool %s no longer exists", o.Pool)
+		}
+		nodesByName := map[string]corev1.Node{}
+		for _, node := range nodes.Items {
+			nodesByName[node.Name] = node
+		}
+
+		done := true
+		for _, status := range status.Nodes {
+			phase := rebootPhase(nodesByName[status.Name], target)
+			if phase != phases[status.Name] {
+				fmt.Fprintf(o.Out, "%s node/%s %s\n", time.Now().Format(time.RFC3339), status.Name, phase)
+				phases[status.Name] = phase
+			}
+			if strings.HasPrefix(phase, "degraded") {
+				return false, fmt.Errorf("node %s is degraded, the reboot of the pool is blocked", status.Name)
+			}
+			if phase != "rebooted" {
+				done = false
+			}
+		}
+		if status.State == "Degraded" {
+			return false, fmt.Errorf("the machine config pool %s is degraded", o.Pool)
+		}
+		return done && status.State == "Updated" && pool.Status.Configuration.Name == target, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("the nodes of the machine config pool %s were not rebooted within %s, the reboot goes on", o.Pool, o.Timeout)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "The nodes of machineconfigpool/%s were rebooted\n", o.Pool)
+	return nil
+}
+
+// rebootPhase describes the progress of the reboot of a node to the target configuration.
+func rebootPhase(node corev1.Node, target string) string {
+	current := node.Annotations[currentConfigAnnotation]
+	desired := node.Annotations[desiredConfigAnnotation]
+	state := node.Annotations[stateAnnotation]
+	ready := conditions.NodeReady(&node)
+
+	switch {
+	case state == "Degraded" || state == "Unreconcilable":
+		if reason := node.Annotations[reasonAnnotation]; len(reason) > 0 {
+			return "degraded: " + reason
+		}
+		return "degraded"
+	case current == target && state == "Done" && ready && !node.Spec.Unschedulable:
+		return "rebooted"
+	case desired != target:
+		return "pending"
+	case !ready:
+		return "rebooting"
+	case current == target:
+		// the node rebooted with the target configuration and is about to be uncordoned
+		return "uncordoning"
+	case node.Spec.Unschedulable:
+		return "draining"
+	default:
+		return "updating"
+	}
+}
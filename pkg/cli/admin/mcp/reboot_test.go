@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRebootPhase(t *testing.T) {
+	ready := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionTrue}
+	notReady := corev1.NodeCondition{Type: corev1.NodeReady, Status: corev1.ConditionFalse}
+	tests := []struct {
+		name          string
+		node          corev1.Node
+		condition     corev1.NodeCondition
+		unschedulable bool
+		want          string
+	}{
+		{name: "not selected yet", node: node("a", nil, "old", "old", "Done"), condition: ready, want: "pending"},
+		{name: "draining", node: node("a", nil, "old", "new", "Working"), condition: ready, unschedulable: true, want: "draining"},
+		{name: "updating", node: node("a", nil, "old", "new", "Working"), condition: ready, want: "updating"},
+		{name: "rebooting", node: node("a", nil, "old", "new", "Working"), condition: notReady, unschedulable: true, want: "rebooting"},
+		{name: "uncordoning", node: node("a", nil, "new", "new", "Done"), condition: ready, unschedulable: true, want: "uncordoning"},
+		{name: "rebooted", node: node("a", nil, "new", "new", "Done"), condition: ready, want: "rebooted"},
+		{name: "degraded", node: node("a", nil, "old", "new", "Degraded"), condition: ready, want: "degraded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.node.Status.Conditions = []corev1.NodeCondition{tt.condition}
+			tt.node.Spec.Unschedulable = tt.unschedulable
+			if got := rebootPhase(tt.node, "new"); got != tt.want {
+				t.Errorf("rebootPhase() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	degraded := node("a", nil, "old", "new", "Degraded")
+	degraded.Annotations[reasonAnnotation] = "failed to drain"
+	if got := rebootPhase(degraded, "new"); got != "degraded: failed to drain" {
+		t.Errorf("rebootPhase() = %q, want the reason of the degraded node", got)
+	}
+}
+
+func TestMachineConfigLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "match labels",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"machineconfiguration.openshift.io/role": "worker"}},
+			want:     map[string]string{"machineconfiguration.openshift.io/role": "worker"},
+		},
+		{
+			name: "custom pool",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "machineconfiguration.openshift.io/role", Operator: metav1.LabelSelectorOpIn, Values: []string{"worker", "infra"}},
+			}},
+			want: map[string]string{"machineconfiguration.openshift.io/role": "infra"},
+		},
+		{
+			name: "unsupported operator",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "machineconfiguration.openshift.io/role", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"master"}},
+			}},
+			wantErr: true,
+		},
+		{name: "empty", selector: &metav1.LabelSelector{}, wantErr: true},
+		{name: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pool("infra", "infra", "", "")
+			p.Spec.MachineConfigSelector = tt.selector
+			got, err := machineConfigLabels(&p)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("machineConfigLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) && !tt.wantErr {
+				t.Errorf("machineConfigLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRebootMachineConfig(t *testing.T) {
+	labels := map[string]string{"machineconfiguration.openshift.io/role": "worker"}
+	mc := newRebootMachineConfig("worker", labels, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	if mc.GetName() != "95-oc-initiated-reboot-worker" {
+		t.Errorf("name = %s", mc.GetName())
+	}
+	if !reflect.DeepEqual(mc.GetLabels(), labels) {
+		t.Errorf("labels = %v, want %v", mc.GetLabels(), labels)
+	}
+	files, _, err := unstructured.NestedSlice(mc.Object, "spec", "config", "storage", "files")
+	if err != nil || len(files) != 1 {
+		t.Fatalf("files = %v, %v", files, err)
+	}
+	source, _, _ := unstructured.NestedString(files[0].(map[string]interface{}), "contents", "source")
+	if want := "data:,2021-03-04T05:06:07Z%0A"; source != want {
+		t.Errorf("contents = %s, want %s", source, want)
+	}
+}
+
+func TestParseMaxUnavailable(t *testing.T) {
+	for value, valid := range map[string]bool{"1": true, "3": true, "25%": true, "100%": true, "0": false, "-1": false, "0%": false, "101%": false, "a": false, "a%": false} {
+		if _, err := parseMaxUnavailable(value); (err == nil) != valid {
+			t.Errorf("parseMaxUnavailable(%q) error = %v, want valid %v", value, err, valid)
+		}
+	}
+}
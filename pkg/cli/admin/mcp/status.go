@@ -0,0 +1,404 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	statusLong = templates.LongDesc(`
+		Show the rollout status of machine config pools.
+
+		For every pool, the number of machines that are updated, updating and degraded is shown along
+		with the rendered configuration currently applied to the pool and, while a rollout is in progress,
+		the configuration the pool is being updated to. Every node of the pools is then listed with its
+		current and desired rendered configuration and the state reported by the machine config daemon.
+
+		Only the given pools are shown if any pool names are passed. With --watch, the status is printed
+		again whenever it changes, until the command is interrupted.
+	`)
+
+	statusExample = templates.Examples(`
+		# Show the status of every machine config pool and its nodes
+		oc adm mcp status
+
+		# Follow the rollout of the worker pool
+		oc adm mcp status worker --watch
+	`)
+)
+
+const (
+	currentConfigAnnotation = "machineconfiguration.openshift.io/currentConfig"
+	desiredConfigAnnotation = "machineconfiguration.openshift.io/desiredConfig"
+	stateAnnotation         = "machineconfiguration.openshift.io/state"
+	reasonAnnotation        = "machineconfiguration.openshift.io/reason"
+
+	// workerPool is the pool of every worker, which custom pools of workers take nodes from.
+	workerPool = "worker"
+)
+
+var machineConfigPoolsResource = schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigpools"}
+
+// machineConfigPool holds the fields of a machine config pool that are reported.
+type machineConfigPool struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   machineConfigPoolSpec   `json:"spec,omitempty"`
+	Status machineConfigPoolStatus `json:"status,omitempty"`
+}
+
+type machineConfigPoolSpec struct {
+	MachineConfigSelector *metav1.LabelSelector `json:"machineConfigSelector,omitempty"`
+	NodeSelector          *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	Paused                bool                  `json:"paused,omitempty"`
+	MaxUnavailable        *intstr.IntOrString   `json:"maxUnavailable,omitempty"`
+	Configuration         configurationSource   `json:"configuration,omitempty"`
+}
+
+type machineConfigPoolStatus struct {
+	Configuration        configurationSource `json:"configuration,omitempty"`
+	MachineCount         int32               `json:"machineCount,omitempty"`
+	UpdatedMachineCount  int32               `json:"updatedMachineCount,omitempty"`
+	DegradedMachineCount int32               `json:"degradedMachineCount,omitempty"`
+	Conditions           []poolCondition     `json:"conditions,omitempty"`
+}
+
+type configurationSource struct {
+	Name string `json:"name,omitempty"`
+}
+
+type poolCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type StatusOptions struct {
+	Pools    []string
+	Watch    bool
+	Interval time.Duration
+
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewStatusOptions(streams genericclioptions.IOStreams) *StatusOptions {
+	return &StatusOptions{
+		Interval:  5 * time.Second,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdStatus creates a command that shows the rollout status of machine config pools.
+func NewCmdStatus(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewStatusOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "status [POOL...]",
+		Short:   "Show the rollout status of machine config pools and their nodes",
+		Long:    statusLong,
+		Example: statusExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "After printing the status, print it again whenever it changes.")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "How often the status is checked with --watch.")
+
+	return cmd
+}
+
+func (o *StatusOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.Pools = args
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.DynamicClient, err = dynamic.NewForConfig(config); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *StatusOptions) Validate() error {
+	if o.Interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0")
+	}
+	return nil
+}
+
+func (o *StatusOptions) Run() error {
+	if !o.Watch {
+		status, err := o.status()
+		if err != nil {
+			return err
+		}
+		_, err = o.Out.Write(status)
+		return err
+	}
+
+	var last []byte
+	return wait.PollImmediateInfinite(o.Interval, func() (bool, error) {
+		status, err := o.status()
+		if err != nil {
+			return false, err
+		}
+		if !bytes.Equal(status, last) {
+			if last != nil {
+				fmt.Fprintln(o.Out)
+			}
+			fmt.Fprintf(o.Out, "%s\n", time.Now().Format(time.RFC3339))
+			if _, err := o.Out.Write(status); err != nil {
+				return false, err
+			}
+			last = status
+		}
+		return false, nil
+	})
+}
+
+// status retrieves the pools and nodes and returns their formatted status.
+func (o *StatusOptions) status() ([]byte, error) {
+	pools, err := listMachineConfigPools(o.DynamicClient)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.Pools) > 0 {
+		found := sets.NewString()
+		for _, pool := range pools {
+			found.Insert(pool.Name)
+		}
+		if missing := sets.NewString(o.Pools...).Difference(found); missing.Len() > 0 {
+			return nil, fmt.Errorf("machine config pools not found: %v", missing.List())
+		}
+	}
+
+	nodes, err := o.KubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %v", err)
+	}
+
+	statuses, err := poolStatuses(pools, nodes.Items)
+	if err != nil {
+		return nil, err
+	}
+	if len(o.Pools) > 0 {
+		selected := sets.NewString(o.Pools...)
+		var filtered []poolStatus
+		for _, status := range statuses {
+			if selected.Has(status.Name) {
+				filtered = append(filtered, status)
+			}
+		}
+		statuses = filtered
+	}
+
+	buf := &bytes.Buffer{}
+	if err := printStatus(buf, statuses); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// listMachineConfigPools returns every machine config pool.
+func listMachineConfigPools(client dynamic.Interface) ([]machineConfigPool, error) {
+	list, err := client.Resource(machineConfigPoolsResource).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list machine config pools: %v", err)
+	}
+	var pools []machineConfigPool
+	for _, item := range list.Items {
+		pool, err := toMachineConfigPool(&item)
+		if err != nil {
+			return nil, err
+		}
+		pools = append(pools, *pool)
+	}
+	return pools, nil
+}
+
+func toMachineConfigPool(item *unstructured.Unstructured) (*machineConfigPool, error) {
+	pool := &machineConfigPool{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, pool); err != nil {
+		return nil, fmt.Errorf("unable to read machine config pool %s: %v", item.GetName(), err)
+	}
+	return pool, nil
+}
+
+// poolStatus is the rollout status of a pool and its nodes.
+type poolStatus struct {
+	Name     string
+	State    string
+	Config   string
+	Target   string
+	Machines int32
+	Updated  int32
+	Updating int32
+	Degraded int32
+	Nodes    []nodeStatus
+}
+
+// nodeStatus is the rollout status of a node.
+type nodeStatus struct {
+	Name    string
+	Current string
+	Desired string
+	State   string
+	Reason  string
+}
+
+// poolStatuses correlates the pools with their nodes, sorted by name. A node that matches a custom pool as well as
+// the worker pool belongs to the custom pool.
+func poolStatuses(pools []machineConfigPool, nodes []corev1.Node) ([]poolStatus, error) {
+	selectors := map[string]labels.Selector{}
+	for _, pool := range pools {
+		if pool.Spec.NodeSelector == nil {
+			selectors[pool.Name] = labels.Nothing()
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pool.Spec.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector of machine config pool %s: %v", pool.Name, err)
+		}
+		selectors[pool.Name] = selector
+	}
+
+	poolNodes := map[string][]corev1.Node{}
+	for _, node := range nodes {
+		var matches []string
+		for _, pool := range pools {
+			if selectors[pool.Name].Matches(labels.Set(node.Labels)) {
+				matches = append(matches, pool.Name)
+			}
+		}
+		if len(matches) > 1 {
+			custom := matches[:0]
+			for _, name := range matches {
+				if name != workerPool {
+					custom = append(custom, name)
+				}
+			}
+			matches = custom
+		}
+		for _, name := range matches {
+			poolNodes[name] = append(poolNodes[name], node)
+		}
+	}
+
+	var statuses []poolStatus
+	for _, pool := range pools {
+		status := poolStatus{
+			Name:     pool.Name,
+			State:    poolState(pool),
+			Config:   pool.Status.Configuration.Name,
+			Target:   pool.Spec.Configuration.Name,
+			Machines: pool.Status.MachineCount,
+			Updated:  pool.Status.UpdatedMachineCount,
+			Degraded: pool.Status.DegradedMachineCount,
+		}
+		for _, node := range poolNodes[pool.Name] {
+			n := nodeStatus{
+				Name:    node.Name,
+				Current: node.Annotations[currentConfigAnnotation],
+				Desired: node.Annotations[desiredConfigAnnotation],
+				State:   node.Annotations[stateAnnotation],
+				Reason:  node.Annotations[reasonAnnotation],
+			}
+			switch {
+			case n.State == "Degraded" || n.State == "Unreconcilable":
+			case n.State == "Working" || n.Current != n.Desired:
+				status.Updating++
+			case len(status.Target) > 0 && n.Current != status.Target:
+				n.State = "Pending"
+			}
+			if len(n.State) == 0 {
+				n.State = "Unknown"
+			}
+			if node.Spec.Unschedulable {
+				n.State += ",SchedulingDisabled"
+			}
+			status.Nodes = append(status.Nodes, n)
+		}
+		sort.Slice(status.Nodes, func(i, j int) bool { return status.Nodes[i].Name < status.Nodes[j].Name })
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses, nil
+}
+
+// poolState summarizes the conditions of the pool.
+func poolState(pool machineConfigPool) string {
+	conditions := map[string]string{}
+	for _, condition := range pool.Status.Conditions {
+		conditions[condition.Type] = condition.Status
+	}
+	switch {
+	case conditions["Degraded"] == "True":
+		return "Degraded"
+	case pool.Spec.Paused:
+		return "Paused"
+	case conditions["Updating"] == "True":
+		return "Updating"
+	case conditions["Updated"] == "True":
+		return "Updated"
+	default:
+		return "Unknown"
+	}
+}
+
+// printStatus prints a table of the pools followed by a table of their nodes.
+func printStatus(out io.Writer, statuses []poolStatus) error {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "POOL\tSTATE\tMACHINES\tUPDATED\tUPDATING\tDEGRADED\tCONFIG\tTARGET")
+	for _, status := range statuses {
+		target := status.Target
+		if target == status.Config {
+			target = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\n", status.Name, status.State, status.Machines, status.Updated, status.Updating, status.Degraded, status.Config, target)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tPOOL\tSTATE\tCURRENT\tDESIRED\tREASON")
+	for _, status := range statuses {
+		for _, node := range status.Nodes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", node.Name, status.Name, node.State, node.Current, node.Desired, node.Reason)
+		}
+	}
+	return w.Flush()
+}
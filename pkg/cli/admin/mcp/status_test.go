@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func pool(name, role, config, target string, conditions ...poolCondition) machineConfigPool {
+	p := machineConfigPool{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	p.Spec.NodeSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/" + role: ""}}
+	p.Spec.Configuration.Name = target
+	p.Status.Configuration.Name = config
+	p.Status.Conditions = conditions
+	return p
+}
+
+func node(name string, roles []string, current, desired, state string) corev1.Node {
+	n := corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   name,
+		Labels: map[string]string{},
+		Annotations: map[string]string{
+			currentConfigAnnotation: current,
+			desiredConfigAnnotation: desired,
+			stateAnnotation:         state,
+		},
+	}}
+	for _, role := range roles {
+		n.Labels["node-role.kubernetes.io/"+role] = ""
+	}
+	return n
+}
+
+func TestPoolStatuses(t *testing.T) {
+	pools := []machineConfigPool{
+		pool("worker", "worker", "rendered-worker-1", "rendered-worker-2", poolCondition{Type: "Updating", Status: "True"}),
+		pool("master", "master", "rendered-master-1", "rendered-master-1", poolCondition{Type: "Updated", Status: "True"}),
+		pool("infra", "infra", "rendered-infra-1", "rendered-infra-1", poolCondition{Type: "Degraded", Status: "True"}),
+	}
+	nodes := []corev1.Node{
+		node("worker-1", []string{"worker"}, "rendered-worker-1", "rendered-worker-1", "Done"),
+		node("worker-0", []string{"worker"}, "rendered-worker-1", "rendered-worker-2", "Working"),
+		node("worker-2", []string{"worker"}, "rendered-worker-2", "rendered-worker-2", "Done"),
+		node("infra-0", []string{"worker", "infra"}, "rendered-infra-1", "rendered-infra-1", "Degraded"),
+		node("master-0", []string{"master"}, "rendered-master-1", "rendered-master-1", "Done"),
+	}
+	nodes[1].Spec.Unschedulable = true
+
+	statuses, err := poolStatuses(pools, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []poolStatus{
+		{Name: "infra", State: "Degraded", Config: "rendered-infra-1", Target: "rendered-infra-1", Nodes: []nodeStatus{
+			{Name: "infra-0", Current: "rendered-infra-1", Desired: "rendered-infra-1", State: "Degraded"},
+		}},
+		{Name: "master", State: "Updated", Config: "rendered-master-1", Target: "rendered-master-1", Nodes: []nodeStatus{
+			{Name: "master-0", Current: "rendered-master-1", Desired: "rendered-master-1", State: "Done"},
+		}},
+		{Name: "worker", State: "Updating", Config: "rendered-worker-1", Target: "rendered-worker-2", Updating: 1, Nodes: []nodeStatus{
+			{Name: "worker-0", Current: "rendered-worker-1", Desired: "rendered-worker-2", State: "Working,SchedulingDisabled"},
+			{Name: "worker-1", Current: "rendered-worker-1", Desired: "rendered-worker-1", State: "Pending"},
+			{Name: "worker-2", Current: "rendered-worker-2", Desired: "rendered-worker-2", State: "Done"},
+		}},
+	}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected:\n%#v\ngot:\n%#v", expected, statuses)
+	}
+
+	out := &bytes.Buffer{}
+	if err := printStatus(out, statuses[1:2]); err != nil {
+		t.Fatal(err)
+	}
+	expectedOut := `POOL     STATE     MACHINES   UPDATED   UPDATING   DEGRADED   CONFIG              TARGET
+master   Updated   0          0         0          0          rendered-master-1   -
+
+NODE       POOL     STATE   CURRENT             DESIRED             REASON
+master-0   master   Done    rendered-master-1   rendered-master-1   
+`
+	if out.String() != expectedOut {
+		t.Errorf("expected:\n%s\ngot:\n%s", expectedOut, out.String())
+	}
+}
+
+func TestPoolState(t *testing.T) {
+	paused := pool("worker", "worker", "a", "b", poolCondition{Type: "Updating", Status: "True"})
+	paused.Spec.Paused = true
+	if state := poolState(paused); state != "Paused" {
+		t.Errorf("expected Paused, got %s", state)
+	}
+	if state := poolState(pool("worker", "worker", "a", "a")); state != "Unknown" {
+		t.Errorf("expected Unknown, got %s", state)
+	}
+}
@@ -0,0 +1,405 @@
+package mirrorconfig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/openshift/oc/pkg/cli/image/mirror"
+)
+
+var (
+	generateLong = templates.LongDesc(`
+		Generate ImageDigestMirrorSet and ImageTagMirrorSet objects from mirror mappings.
+
+		The mapping files have one SRC=DST mapping per line, like the mapping.txt file written by
+		"oc adm catalog mirror" or the files accepted by "oc image mirror --filename". The images
+		mirrored by digest are added to an ImageDigestMirrorSet and the images mirrored by tag to an
+		ImageTagMirrorSet, both named after --name. By default each source repository is mirrored by
+		its destination repository. With --scope=registry whole registries are mirrored instead, which
+		requires every image to keep its repository path in the mirror registry. The mappings to a
+		file or to another destination that is not a registry are skipped.
+
+		Existing objects can be given with --merge. The mirrors of the object with the same kind and
+		name are kept, and the new mirrors are added after them. A warning is printed for the sources
+		that are also mirrored by the other objects.
+	`)
+
+	generateExample = templates.Examples(`
+		# Generate the mirror configuration for the images mirrored by a catalog mirror
+		oc adm mirror-config generate -f manifests-redhat-operator-index/mapping.txt
+
+		# Mirror whole registries and never pull from the sources
+		oc adm mirror-config generate -f mapping.txt --scope=registry --source-policy=NeverContactSource
+
+		# Add the mirrors of a new mapping to the objects that are already configured
+		oc get imagedigestmirrorsets,imagetagmirrorsets -o yaml > existing.yaml
+		oc adm mirror-config generate -f mapping.txt --merge existing.yaml | oc apply -f -
+	`)
+)
+
+const (
+	digestMirrorSetKind = "ImageDigestMirrorSet"
+	tagMirrorSetKind    = "ImageTagMirrorSet"
+
+	repositoryScope = "repository"
+	registryScope   = "registry"
+)
+
+// GenerateOptions holds the options to generate the image mirror configuration from mirror mappings.
+type GenerateOptions struct {
+	Filenames      []string
+	MergeFilenames []string
+
+	Name         string
+	Scope        string
+	SourcePolicy string
+
+	genericclioptions.IOStreams
+}
+
+func NewGenerateOptions(streams genericclioptions.IOStreams) *GenerateOptions {
+	return &GenerateOptions{
+		Name:      "image-mirrors",
+		Scope:     repositoryScope,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdGenerate creates a command that generates the image mirror configuration from mirror mappings.
+func NewCmdGenerate(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewGenerateOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "generate -f FILENAME",
+		Short:   "Generate ImageDigestMirrorSet and ImageTagMirrorSet objects from mirror mappings",
+		Long:    generateLong,
+		Example: generateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames, "A file of SRC=DST mappings, one per line. Use - to read from stdin. May be specified more than once.")
+	flags.StringSliceVar(&o.MergeFilenames, "merge", o.MergeFilenames, "A file of existing ImageDigestMirrorSet and ImageTagMirrorSet objects to merge the mirrors with. May be specified more than once.")
+	flags.StringVar(&o.Name, "name", o.Name, "The name of the objects to generate.")
+	flags.StringVar(&o.Scope, "scope", o.Scope, "Mirror each 'repository' or each 'registry' of the sources.")
+	flags.StringVar(&o.SourcePolicy, "source-policy", o.SourcePolicy, "The policy when the images cannot be pulled from the mirrors, AllowContactingSource or NeverContactSource. Defaults to the policy of the cluster.")
+
+	return cmd
+}
+
+func (o *GenerateOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	return nil
+}
+
+func (o *GenerateOptions) Validate() error {
+	if len(o.Filenames) == 0 {
+		return errors.New("at least one mapping file must be provided with --filename")
+	}
+	if len(o.Name) == 0 {
+		return errors.New("--name must be provided")
+	}
+	switch o.Scope {
+	case repositoryScope, registryScope:
+	default:
+		return fmt.Errorf("--scope must be %q or %q", repositoryScope, registryScope)
+	}
+	switch configv1.MirrorSourcePolicy(o.SourcePolicy) {
+	case "", configv1.AllowContactingSource, configv1.NeverContactSource:
+	default:
+		return fmt.Errorf("--source-policy must be %s or %s", configv1.AllowContactingSource, configv1.NeverContactSource)
+	}
+	return nil
+}
+
+func (o *GenerateOptions) Run() error {
+	var mappings []mirror.Mapping
+	for _, filename := range o.Filenames {
+		fileMappings, err := mirror.ParseMappingFile(filename, o.In)
+		if err != nil {
+			return err
+		}
+		mappings = append(mappings, fileMappings...)
+	}
+	var existing []existingMirrorSet
+	for _, filename := range o.MergeFilenames {
+		sets, err := readMirrorSets(filename)
+		if err != nil {
+			return err
+		}
+		existing = append(existing, sets...)
+	}
+
+	objects, warnings, err := o.generate(mappings, existing)
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(o.ErrOut, "warning: %s\n", warning)
+	}
+	for _, object := range objects {
+		data, err := marshalObject(object)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "---\n%s", data)
+	}
+	return nil
+}
+
+// existingMirrorSet is an ImageDigestMirrorSet or ImageTagMirrorSet given with --merge. The mirrors of both kinds
+// are held as ImageDigestMirrors, which have the same fields as ImageTagMirrors.
+type existingMirrorSet struct {
+	Kind    string
+	Meta    metav1.ObjectMeta
+	Mirrors []configv1.ImageDigestMirrors
+}
+
+// mirrorSet holds the mirrors of the sources of the object to generate, in the order of their priority.
+type mirrorSet struct {
+	Meta     metav1.ObjectMeta
+	Mirrors  map[string][]configv1.ImageMirror
+	Policies map[string]configv1.MirrorSourcePolicy
+}
+
+func newMirrorSet(name string) *mirrorSet {
+	return &mirrorSet{
+		Meta:     metav1.ObjectMeta{Name: name},
+		Mirrors:  map[string][]configv1.ImageMirror{},
+		Policies: map[string]configv1.MirrorSourcePolicy{},
+	}
+}
+
+// add adds the mirror of the source after the existing mirrors, unless it is already one of them.
+func (s *mirrorSet) add(source string, mirror configv1.ImageMirror, policy configv1.MirrorSourcePolicy) {
+	found := false
+	for _, existing := range s.Mirrors[source] {
+		found = found || existing == mirror
+	}
+	if !found {
+		s.Mirrors[source] = append(s.Mirrors[source], mirror)
+	}
+	if len(policy) > 0 {
+		s.Policies[source] = policy
+	}
+}
+
+// mirrors returns the mirrors of the set sorted by source.
+func (s *mirrorSet) mirrors() []configv1.ImageDigestMirrors {
+	var sources []string
+	for source := range s.Mirrors {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	var mirrors []configv1.ImageDigestMirrors
+	for _, source := range sources {
+		mirrors = append(mirrors, configv1.ImageDigestMirrors{
+			Source:             source,
+			Mirrors:            s.Mirrors[source],
+			MirrorSourcePolicy: s.Policies[source],
+		})
+	}
+	return mirrors
+}
+
+// generate returns the ImageDigestMirrorSet and ImageTagMirrorSet with the mirrors of the mappings merged with those
+// of the existing object of the same kind and name, and the warnings about the mappings.
+func (o *GenerateOptions) generate(mappings []mirror.Mapping, existing []existingMirrorSet) ([]runtime.Object, []string, error) {
+	sets := map[string]*mirrorSet{
+		digestMirrorSetKind: newMirrorSet(o.Name),
+		tagMirrorSetKind:    newMirrorSet(o.Name),
+	}
+	// mirroredBy holds, for each kind, the other objects mirroring each source
+	mirroredBy := map[string]map[string]string{
+		digestMirrorSetKind: {},
+		tagMirrorSetKind:    {},
+	}
+	for _, e := range existing {
+		if e.Meta.Name != o.Name {
+			for _, m := range e.Mirrors {
+				mirroredBy[e.Kind][m.Source] = e.Meta.Name
+			}
+			continue
+		}
+		set := sets[e.Kind]
+		set.Meta.Labels = e.Meta.Labels
+		set.Meta.Annotations = e.Meta.Annotations
+		for _, m := range e.Mirrors {
+			for _, mirror := range m.Mirrors {
+				set.add(m.Source, mirror, m.MirrorSourcePolicy)
+			}
+		}
+	}
+
+	var warnings []string
+	for _, m := range mappings {
+		if m.Source.Type != imagesource.DestinationRegistry || m.Destination.Type != imagesource.DestinationRegistry {
+			warnings = append(warnings, fmt.Sprintf("skipping %s=%s, only the images mirrored from a registry to a registry can be configured", m.Source, m.Destination))
+			continue
+		}
+		source, mirror, err := mirrorOf(m, o.Scope)
+		if err != nil {
+			return nil, nil, err
+		}
+		set := sets[tagMirrorSetKind]
+		if len(m.Source.Ref.ID) > 0 {
+			set = sets[digestMirrorSetKind]
+		}
+		set.add(source, configv1.ImageMirror(mirror), configv1.MirrorSourcePolicy(o.SourcePolicy))
+	}
+
+	var objects []runtime.Object
+	for _, kind := range []string{digestMirrorSetKind, tagMirrorSetKind} {
+		set := sets[kind]
+		mirrors := set.mirrors()
+		if len(mirrors) == 0 {
+			continue
+		}
+		for _, m := range mirrors {
+			if name, ok := mirroredBy[kind][m.Source]; ok {
+				warnings = append(warnings, fmt.Sprintf("%s is also mirrored by %s %s", m.Source, kind, name))
+			}
+		}
+		if kind == digestMirrorSetKind {
+			objects = append(objects, &configv1.ImageDigestMirrorSet{
+				TypeMeta:   metav1.TypeMeta{APIVersion: configv1.GroupVersion.String(), Kind: kind},
+				ObjectMeta: set.Meta,
+				Spec:       configv1.ImageDigestMirrorSetSpec{ImageDigestMirrors: mirrors},
+			})
+			continue
+		}
+		tagMirrors := make([]configv1.ImageTagMirrors, 0, len(mirrors))
+		for _, m := range mirrors {
+			tagMirrors = append(tagMirrors, configv1.ImageTagMirrors(m))
+		}
+		objects = append(objects, &configv1.ImageTagMirrorSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: configv1.GroupVersion.String(), Kind: kind},
+			ObjectMeta: set.Meta,
+			Spec:       configv1.ImageTagMirrorSetSpec{ImageTagMirrors: tagMirrors},
+		})
+	}
+	if len(objects) == 0 {
+		return nil, nil, errors.New("no image is mirrored from a registry to a registry, there is nothing to configure")
+	}
+	return objects, warnings, nil
+}
+
+// mirrorOf returns the source and the mirror of a mapping between registries, which are the repositories of the
+// images or, for the registry scope, their registries.
+func mirrorOf(m mirror.Mapping, scope string) (string, string, error) {
+	var source, mirror string
+	switch scope {
+	case registryScope:
+		if m.Source.Ref.RepositoryName() != m.Destination.Ref.RepositoryName() {
+			return "", "", fmt.Errorf("%s is mirrored to %s, the registry scope requires the mirror to keep the repository path", m.Source, m.Destination)
+		}
+		source, mirror = m.Source.Ref.DockerClientDefaults().Registry, m.Destination.Ref.Registry
+	default:
+		source, mirror = m.Source.Ref.DockerClientDefaults().AsRepository().String(), m.Destination.Ref.AsRepository().String()
+	}
+	if source == mirror {
+		return "", "", fmt.Errorf("%s is mirrored to %s, a source cannot be its own mirror", m.Source, m.Destination)
+	}
+	return source, mirror, nil
+}
+
+// readMirrorSets reads the ImageDigestMirrorSet and ImageTagMirrorSet objects of a file of YAML or JSON documents,
+// which may be lists.
+func readMirrorSets(filename string) ([]existingMirrorSet, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sets []existingMirrorSet
+	decoder := kyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		object := &unstructured.Unstructured{}
+		if err := decoder.Decode(&object.Object); err != nil {
+			if err == io.EOF {
+				return sets, nil
+			}
+			return nil, fmt.Errorf("unable to read %s: %v", filename, err)
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+		objects := []*unstructured.Unstructured{object}
+		if object.IsList() {
+			list, err := object.ToList()
+			if err != nil {
+				return nil, fmt.Errorf("unable to read %s: %v", filename, err)
+			}
+			objects = nil
+			for i := range list.Items {
+				objects = append(objects, &list.Items[i])
+			}
+		}
+		for _, object := range objects {
+			set, err := toMirrorSet(object)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", filename, err)
+			}
+			sets = append(sets, set)
+		}
+	}
+}
+
+// toMirrorSet converts an ImageDigestMirrorSet or ImageTagMirrorSet object.
+func toMirrorSet(object *unstructured.Unstructured) (existingMirrorSet, error) {
+	set := existingMirrorSet{Kind: object.GetKind()}
+	switch set.Kind {
+	case digestMirrorSetKind:
+		idms := &configv1.ImageDigestMirrorSet{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, idms); err != nil {
+			return set, fmt.Errorf("%s %s is invalid: %v", set.Kind, object.GetName(), err)
+		}
+		set.Meta = idms.ObjectMeta
+		set.Mirrors = idms.Spec.ImageDigestMirrors
+	case tagMirrorSetKind:
+		itms := &configv1.ImageTagMirrorSet{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, itms); err != nil {
+			return set, fmt.Errorf("%s %s is invalid: %v", set.Kind, object.GetName(), err)
+		}
+		set.Meta = itms.ObjectMeta
+		for _, m := range itms.Spec.ImageTagMirrors {
+			set.Mirrors = append(set.Mirrors, configv1.ImageDigestMirrors(m))
+		}
+	default:
+		return set, fmt.Errorf("%s %s is not an %s or an %s", set.Kind, object.GetName(), digestMirrorSetKind, tagMirrorSetKind)
+	}
+	return set, nil
+}
+
+// marshalObject returns the YAML of a generated object, without the creation timestamp and the status.
+func marshalObject(object runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, fmt.Errorf("error converting to unstructured: %v", err)
+	}
+	delete(content["metadata"].(map[string]interface{}), "creationTimestamp")
+	delete(content, "status")
+	return yaml.Marshal(content)
+}
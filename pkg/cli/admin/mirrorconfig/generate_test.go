@@ -0,0 +1,194 @@
+package mirrorconfig
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/openshift/oc/pkg/cli/image/mirror"
+)
+
+const testMappings = `
+# images mirrored by digest
+quay.io/openshift/origin-cli@sha256:0000000000000000000000000000000000000000000000000000000000000001=mirror.example.com/openshift/origin-cli:1
+quay.io/openshift/origin-cli@sha256:0000000000000000000000000000000000000000000000000000000000000002=mirror.example.com/openshift/origin-cli:2
+registry.redhat.io/ubi8/ubi@sha256:0000000000000000000000000000000000000000000000000000000000000003=mirror.example.com/ubi8/ubi:3
+# images mirrored by tag
+docker.io/library/busybox:latest=mirror.example.com/library/busybox:latest
+registry.redhat.io/ubi8/ubi:8.6=file://ubi8/ubi:8.6
+`
+
+func TestGenerate(t *testing.T) {
+	existing := `apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  name: image-mirrors
+  labels:
+    team: platform
+  resourceVersion: "12"
+  uid: 7d1a7d8e-9b55-4e1f-a8a5-3bd8a6e1d7c2
+spec:
+  imageDigestMirrors:
+  - source: quay.io/openshift/origin-cli
+    mirrors:
+    - backup.example.com/openshift/origin-cli
+    mirrorSourcePolicy: NeverContactSource
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: config.openshift.io/v1
+  kind: ImageTagMirrorSet
+  metadata:
+    name: other
+  spec:
+    imageTagMirrors:
+    - source: docker.io/library/busybox
+      mirrors:
+      - other.example.com/library/busybox
+`
+	dir, err := ioutil.TempDir("", "mirror-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	existingFile := filepath.Join(dir, "existing.yaml")
+	if err := ioutil.WriteFile(existingFile, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := NewGenerateOptions(genericclioptions.IOStreams{In: strings.NewReader(testMappings), Out: out, ErrOut: errOut})
+	o.Filenames = []string{"-"}
+	o.MergeFilenames = []string{existingFile}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `---
+apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  labels:
+    team: platform
+  name: image-mirrors
+spec:
+  imageDigestMirrors:
+  - mirrorSourcePolicy: NeverContactSource
+    mirrors:
+    - backup.example.com/openshift/origin-cli
+    - mirror.example.com/openshift/origin-cli
+    source: quay.io/openshift/origin-cli
+  - mirrors:
+    - mirror.example.com/ubi8/ubi
+    source: registry.redhat.io/ubi8/ubi
+---
+apiVersion: config.openshift.io/v1
+kind: ImageTagMirrorSet
+metadata:
+  name: image-mirrors
+spec:
+  imageTagMirrors:
+  - mirrors:
+    - mirror.example.com/library/busybox
+    source: docker.io/library/busybox
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+	wantWarnings := `warning: skipping registry.redhat.io/ubi8/ubi:8.6=file://ubi8/ubi:8.6, only the images mirrored from a registry to a registry can be configured
+warning: docker.io/library/busybox is also mirrored by ImageTagMirrorSet other
+`
+	if errOut.String() != wantWarnings {
+		t.Errorf("unexpected warnings:\n%s\nwant:\n%s", errOut.String(), wantWarnings)
+	}
+}
+
+func TestMirrorOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		mapping    string
+		scope      string
+		wantSource string
+		wantMirror string
+		wantErr    string
+	}{
+		{
+			name:       "repository",
+			mapping:    "quay.io/openshift/origin-cli:4.12=mirror.example.com/mirrors/origin-cli:4.12",
+			scope:      repositoryScope,
+			wantSource: "quay.io/openshift/origin-cli",
+			wantMirror: "mirror.example.com/mirrors/origin-cli",
+		},
+		{
+			name:       "repository without registry",
+			mapping:    "busybox:latest=mirror.example.com/library/busybox:latest",
+			scope:      repositoryScope,
+			wantSource: "docker.io/library/busybox",
+			wantMirror: "mirror.example.com/library/busybox",
+		},
+		{
+			name:       "registry",
+			mapping:    "quay.io/openshift/origin-cli:4.12=mirror.example.com:5000/openshift/origin-cli:4.12",
+			scope:      registryScope,
+			wantSource: "quay.io",
+			wantMirror: "mirror.example.com:5000",
+		},
+		{
+			name:    "registry with another path",
+			mapping: "quay.io/openshift/origin-cli:4.12=mirror.example.com/mirrors/origin-cli:4.12",
+			scope:   registryScope,
+			wantErr: "quay.io/openshift/origin-cli:4.12 is mirrored to mirror.example.com/mirrors/origin-cli:4.12, the registry scope requires the mirror to keep the repository path",
+		},
+		{
+			name:    "own mirror",
+			mapping: "quay.io/openshift/origin-cli:4.12=quay.io/openshift/origin-cli:latest",
+			scope:   repositoryScope,
+			wantErr: "quay.io/openshift/origin-cli:4.12 is mirrored to quay.io/openshift/origin-cli:latest, a source cannot be its own mirror",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mappings, err := parseTestMappings(tt.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+			source, mirror, err := mirrorOf(mappings[0], tt.scope)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if source != tt.wantSource || mirror != tt.wantMirror {
+				t.Errorf("got %s mirrored by %s, want %s mirrored by %s", source, mirror, tt.wantSource, tt.wantMirror)
+			}
+		})
+	}
+}
+
+func TestGenerateNothingToConfigure(t *testing.T) {
+	mappings, err := parseTestMappings("registry.redhat.io/ubi8/ubi:8.6=file://ubi8/ubi:8.6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := NewGenerateOptions(genericclioptions.NewTestIOStreamsDiscard())
+	if _, _, err := o.generate(mappings, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func parseTestMappings(mappings string) ([]mirror.Mapping, error) {
+	return mirror.ParseMappingFile("-", strings.NewReader(mappings))
+}
@@ -0,0 +1,32 @@
+package mirrorconfig
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	mirrorConfigLong = templates.LongDesc(`
+		Manage the image mirror configuration of the cluster
+
+		The image mirror configuration tells the nodes of the cluster to pull images from mirror
+		repositories, with ImageDigestMirrorSet objects for images pulled by digest and
+		ImageTagMirrorSet objects for images pulled by tag. These commands generate the objects
+		from the mappings of mirrored images.`)
+)
+
+func NewCmdMirrorConfig(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "mirror-config",
+		Short: "Generate the image mirror configuration of the cluster",
+		Long:  mirrorConfigLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdGenerate(f, streams))
+	return cmds
+}
@@ -0,0 +1,130 @@
+package mustgather
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+// zstdCommand is the command that compresses the archive, reading the tarball on its standard input.
+var zstdCommand = []string{"zstd", "--quiet", "--stdout", "-T0"}
+
+// archive streams gathered output into a zstd compressed tarball, so that the output of a gatherer only occupies
+// local disk space between its download and its addition to the archive. It is safe for concurrent use.
+type archive struct {
+	lock sync.Mutex
+	// root is the destination directory, entries are named relative to its parent so that extracting the archive
+	// recreates it.
+	root  string
+	file  *os.File
+	zstd  *exec.Cmd
+	stdin io.WriteCloser
+	tw    *tar.Writer
+}
+
+// archiveName returns the name of the archive holding the contents of destDir.
+func archiveName(destDir string) string {
+	return filepath.Clean(destDir) + ".tar.zst"
+}
+
+// checkArchiver returns an error if the command compressing the archive is not available.
+func checkArchiver() error {
+	if _, err := exec.LookPath(zstdCommand[0]); err != nil {
+		return fmt.Errorf("--compress requires the %s command: %v", zstdCommand[0], err)
+	}
+	return nil
+}
+
+// newArchive creates the archive for destDir, replacing an existing one. The errors of the compression are written
+// to errOut.
+func newArchive(destDir string, errOut io.Writer) (*archive, error) {
+	file, err := os.Create(archiveName(destDir))
+	if err != nil {
+		return nil, err
+	}
+	zstd := exec.Command(zstdCommand[0], zstdCommand[1:]...)
+	zstd.Stdout = file
+	zstd.Stderr = errOut
+	stdin, err := zstd.StdinPipe()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := zstd.Start(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to start %s: %v", zstdCommand[0], err)
+	}
+	return &archive{root: filepath.Clean(destDir), file: file, zstd: zstd, stdin: stdin, tw: tar.NewWriter(stdin)}, nil
+}
+
+// add appends dir, which must be inside the destination directory, to the archive and removes it from disk.
+func (a *archive) add(dir string) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	base := filepath.Dir(a.root)
+	err := filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(base, file)
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(file); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := a.tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(a.tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add %s to %s: %v", dir, a.file.Name(), err)
+	}
+	return os.RemoveAll(dir)
+}
+
+// Close adds what is left of the destination directory to the archive and completes it.
+func (a *archive) Close() error {
+	if err := a.add(a.root); err != nil {
+		a.file.Close()
+		return err
+	}
+	if err := a.tw.Close(); err != nil {
+		a.stdin.Close()
+		a.zstd.Wait()
+		a.file.Close()
+		return err
+	}
+	if err := a.stdin.Close(); err != nil {
+		a.zstd.Wait()
+		a.file.Close()
+		return err
+	}
+	if err := a.zstd.Wait(); err != nil {
+		a.file.Close()
+		return fmt.Errorf("unable to compress %s: %v", a.file.Name(), err)
+	}
+	return a.file.Close()
+}
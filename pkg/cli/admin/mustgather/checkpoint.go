@@ -0,0 +1,95 @@
+package mustgather
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// checkpointFile lists the gatherers whose output was downloaded completely, so that a later run with --resume
+// only repeats the ones that did not finish.
+const checkpointFile = ".must-gather-checkpoint.json"
+
+// gatherer is a single must-gather pod: a plug-in image, optionally pinned to a node.
+type gatherer struct {
+	image string
+	node  string
+}
+
+// key identifies the gatherer in the checkpoint.
+func (g gatherer) key() string {
+	if len(g.node) == 0 {
+		return g.image
+	}
+	return g.node + "/" + g.image
+}
+
+func (g gatherer) String() string {
+	if len(g.node) == 0 {
+		return g.image
+	}
+	return fmt.Sprintf("%s on node %s", g.image, g.node)
+}
+
+// checkpoint records completed gatherers in the destination directory. It is safe for concurrent use.
+type checkpoint struct {
+	lock      sync.Mutex
+	path      string
+	completed sets.String
+}
+
+type checkpointData struct {
+	Completed []string `json:"completed"`
+}
+
+// newCheckpoint returns an empty checkpoint for dir. It replaces any checkpoint left there by an earlier run as
+// soon as the first gatherer completes.
+func newCheckpoint(dir string) *checkpoint {
+	return &checkpoint{path: path.Join(dir, checkpointFile), completed: sets.NewString()}
+}
+
+// loadCheckpoint reads the checkpoint of dir. A missing checkpoint has no completed gatherers.
+func loadCheckpoint(dir string) (*checkpoint, error) {
+	c := newCheckpoint(dir)
+	data, err := os.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	saved := checkpointData{}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("unable to read checkpoint %s: %v", c.path, err)
+	}
+	c.completed.Insert(saved.Completed...)
+	return c, nil
+}
+
+// isCompleted returns true if the output of the gatherer was downloaded by an earlier run.
+func (c *checkpoint) isCompleted(g gatherer) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.completed.Has(g.key())
+}
+
+// complete records the gatherer as completed. The checkpoint is replaced atomically so that an interrupted write
+// never loses earlier entries.
+func (c *checkpoint) complete(g gatherer) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.completed.Insert(g.key())
+	data, err := json.Marshal(checkpointData{Completed: c.completed.List()})
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
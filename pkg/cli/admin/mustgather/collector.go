@@ -0,0 +1,73 @@
+package mustgather
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// cliToolsResource is the catalog of tools published by the CLI manager. Tools that ship a must-gather plug-in
+// image are collectors.
+var cliToolsResource = schema.GroupVersionResource{Group: "config.openshift.io", Version: "v1", Resource: "clitools"}
+
+const (
+	// collectorImageAnnotation is the must-gather plug-in image of a CLITool.
+	collectorImageAnnotation = "must-gather.openshift.io/image"
+	// collectorNameAnnotation is the short name of the collector of a CLITool, which defaults to the name of the
+	// tool.
+	collectorNameAnnotation = "must-gather.openshift.io/collector"
+)
+
+// collectors returns the must-gather plug-in images of the CLITools, by short name.
+func collectors(tools []unstructured.Unstructured) map[string]string {
+	images := map[string]string{}
+	for _, tool := range tools {
+		annotations := tool.GetAnnotations()
+		image := strings.TrimSpace(annotations[collectorImageAnnotation])
+		if len(image) == 0 {
+			continue
+		}
+		name := tool.GetName()
+		if short := strings.TrimSpace(annotations[collectorNameAnnotation]); len(short) > 0 {
+			name = short
+		}
+		images[name] = image
+	}
+	return images
+}
+
+// resolveCollectors returns the plug-in images of the collectors, which are looked up in the CLITool catalog.
+func (o *MustGatherOptions) resolveCollectors() ([]string, error) {
+	list, err := o.DynamicClient.Resource(cliToolsResource).List(context.TODO(), metav1.ListOptions{})
+	if kerrors.IsNotFound(err) {
+		return nil, fmt.Errorf("--collector requires the CLITool catalog of the CLI manager, which is not installed")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the collectors of the CLITool catalog: %v", err)
+	}
+	available := collectors(list.Items)
+
+	var images []string
+	for _, name := range o.Collectors {
+		image, ok := available[name]
+		if !ok {
+			names := make([]string, 0, len(available))
+			for name := range available {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if len(names) == 0 {
+				return nil, fmt.Errorf("unknown collector %q, the CLITool catalog publishes no collector", name)
+			}
+			return nil, fmt.Errorf("unknown collector %q, the CLITool catalog publishes: %s", name, strings.Join(names, ", "))
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
@@ -0,0 +1,72 @@
+package mustgather
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+)
+
+func testCLITool(name string, annotations map[string]string) *unstructured.Unstructured {
+	tool := &unstructured.Unstructured{}
+	tool.SetAPIVersion("config.openshift.io/v1")
+	tool.SetKind("CLITool")
+	tool.SetName(name)
+	tool.SetAnnotations(annotations)
+	return tool
+}
+
+func TestResolveCollectors(t *testing.T) {
+	client := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{cliToolsResource: "CLIToolList"},
+		testCLITool("istioctl", map[string]string{
+			collectorImageAnnotation: "registry.example.com/servicemesh/must-gather:2.4",
+			collectorNameAnnotation:  "servicemesh",
+		}),
+		testCLITool("virtctl", map[string]string{collectorImageAnnotation: "registry.example.com/cnv/must-gather:4.14"}),
+		testCLITool("kn", nil),
+	)
+
+	tests := []struct {
+		name       string
+		collectors []string
+		expected   []string
+		wantErr    string
+	}{
+		{
+			name:       "short names",
+			collectors: []string{"servicemesh", "virtctl"},
+			expected:   []string{"registry.example.com/servicemesh/must-gather:2.4", "registry.example.com/cnv/must-gather:4.14"},
+		},
+		{
+			name:       "tool without collector",
+			collectors: []string{"kn"},
+			wantErr:    `unknown collector "kn", the CLITool catalog publishes: servicemesh, virtctl`,
+		},
+		{
+			name:       "name of a tool with a short name",
+			collectors: []string{"istioctl"},
+			wantErr:    `unknown collector "istioctl", the CLITool catalog publishes: servicemesh, virtctl`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := &MustGatherOptions{DynamicClient: client, Collectors: test.collectors}
+			images, err := o.resolveCollectors()
+			if len(test.wantErr) > 0 {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("expected error %q, got %v", test.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(images, test.expected) {
+				t.Errorf("resolveCollectors() = %v, expected %v", images, test.expected)
+			}
+		})
+	}
+}
@@ -2,31 +2,37 @@ package mustgather
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"math/rand"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	kexec "k8s.io/kubectl/pkg/cmd/exec"
 	"k8s.io/kubectl/pkg/cmd/logs"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/polymorphichelpers"
@@ -53,6 +59,21 @@ var (
 		This command will launch a pod in a temporary namespace on your cluster that gathers
 		debugging information and then downloads the gathered information.
 
+		Each plug-in image runs in its own pod, in parallel with the others. The gatherers whose output was
+		downloaded completely are recorded in the destination directory, and --resume runs only the remaining
+		ones again, for example after a node or network failure stopped a gather pod.
+
+		Gathers can produce a lot of data. With --compress the output of every gatherer is added to
+		DEST_DIR.tar.zst as soon as it is downloaded and removed from the destination directory, which requires
+		the zstd command. With --max-size the output of a gatherer is only downloaded if it fits in what is
+		left of the given size; the gatherers that were not downloaded are listed at the end. --since and --since-time are passed on to the gatherers in the
+		MUST_GATHER_SINCE and MUST_GATHER_SINCE_TIME environment variables, and to the fallback inspection.
+
+		Additional plug-in images published in the CLITool catalog of the CLI manager can be run by their
+		short name with --collector. A CLITool publishes a collector with the annotation
+		must-gather.openshift.io/image set to its plug-in image; the short name is the name of the tool, or
+		the value of the annotation must-gather.openshift.io/collector.
+
 		Experimental: This command is under active development and may change without notice.
 	`)
 
@@ -72,8 +93,17 @@ var (
 		# Gather information using a specific image stream plug-in
 		  oc adm must-gather --image-stream=openshift/must-gather:latest
 
+		# Gather information using the default plug-in and the service mesh collector of the CLITool catalog
+		  oc adm must-gather --image-stream=openshift/must-gather:latest --collector=servicemesh
+
 		# Gather information using a specific image, command, and pod-dir
 		  oc adm must-gather --image=my/image:tag --source-dir=/pod/directory -- myspecial-command.sh
+
+		# Repeat only the gatherers that did not complete in an earlier run
+		  oc adm must-gather --image=quay.io/kubevirt/must-gather --image=quay.io/openshift/origin-must-gather --resume=must-gather.local.5421342344627712289
+
+		# Gather at most 5GiB of logs from the last two hours into a compressed archive
+		  oc adm must-gather --compress --max-size=5Gi --since=2h
 	`)
 )
 
@@ -96,8 +126,14 @@ func NewMustGatherCommand(f kcmdutil.Factory, streams genericclioptions.IOStream
 	cmd.Flags().BoolVar(&o.HostNetwork, "host-network", o.HostNetwork, "Run must-gather pods as hostNetwork: true - relevant if a specific command and image needs to capture host-level data")
 	cmd.Flags().StringSliceVar(&o.Images, "image", o.Images, "Specify a must-gather plugin image to run. If not specified, OpenShift's default must-gather image will be used.")
 	cmd.Flags().StringSliceVar(&o.ImageStreams, "image-stream", o.ImageStreams, "Specify an image stream (namespace/name:tag) containing a must-gather plugin image to run.")
+	cmd.Flags().StringSliceVar(&o.Collectors, "collector", o.Collectors, "Specify the short name of a must-gather plugin published in the CLITool catalog to run.")
 	cmd.Flags().StringVar(&o.DestDir, "dest-dir", o.DestDir, "Set a specific directory on the local machine to write gathered data to.")
+	cmd.Flags().StringVar(&o.Resume, "resume", o.Resume, "Continue an earlier gather into its destination directory, skipping the gatherers that completed.")
 	cmd.Flags().StringVar(&o.SourceDir, "source-dir", o.SourceDir, "Set the specific directory on the pod copy the gathered data from.")
+	cmd.Flags().BoolVar(&o.Compress, "compress", o.Compress, "If true, add the output of every gatherer to DEST_DIR.tar.zst as soon as it is downloaded, keeping only the archive. Requires the zstd command.")
+	cmd.Flags().StringVar(&o.maxSizeStr, "max-size", o.maxSizeStr, "Download at most this much gatherer output, like 500Mi or 10Gi. The output of a gatherer that does not fit is not downloaded. Defaults to no limit.")
+	cmd.Flags().DurationVar(&o.Since, "since", o.Since, "Only return logs newer than a relative duration like 5s, 2m, or 3h. Passed to the gatherers as MUST_GATHER_SINCE.")
+	cmd.Flags().StringVar(&o.SinceTime, "since-time", o.SinceTime, "Only return logs after a specific date (RFC3339). Passed to the gatherers as MUST_GATHER_SINCE_TIME.")
 	cmd.Flags().StringVar(&o.timeoutStr, "timeout", "10m", "The length of time to gather data, like 5s, 2m, or 3h, higher than zero. Defaults to 10 minutes.")
 	cmd.Flags().StringVar(&o.RunNamespace, "run-namespace", o.RunNamespace, "An existing namespace where must-gather pods should run. If not specified a temporary namespace will be generated.")
 	cmd.Flags().MarkHidden("run-namespace")
@@ -132,6 +168,11 @@ func (o *MustGatherOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, arg
 	if o.ImageClient, err = imagev1client.NewForConfig(o.Config); err != nil {
 		return err
 	}
+	if len(o.Collectors) > 0 {
+		if o.DynamicClient, err = dynamic.NewForConfig(o.Config); err != nil {
+			return err
+		}
+	}
 	if i := cmd.ArgsLenAtDash(); i != -1 && i < len(args) {
 		o.Command = args[i:]
 	} else {
@@ -152,6 +193,19 @@ func (o *MustGatherOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, arg
 			o.Timeout = time.Duration(i) * time.Second
 		}
 	}
+	if len(o.maxSizeStr) > 0 {
+		maxSize, err := resource.ParseQuantity(o.maxSizeStr)
+		if err != nil {
+			return fmt.Errorf(`invalid argument %q for "--max-size" flag: %v`, o.maxSizeStr, err)
+		}
+		o.MaxSize = maxSize.Value()
+	}
+	if len(o.Resume) > 0 {
+		if len(o.DestDir) > 0 && o.DestDir != o.Resume {
+			return fmt.Errorf("--resume and --dest-dir are mutually exclusive")
+		}
+		o.DestDir = o.Resume
+	}
 	if len(o.DestDir) == 0 {
 		o.DestDir = fmt.Sprintf("must-gather.local.%06d", rand.Int63())
 	}
@@ -181,6 +235,13 @@ func (o *MustGatherOptions) completeImages() error {
 			return fmt.Errorf("unable to resolve image stream '%v': %v", imageStream, err)
 		}
 	}
+	if len(o.Collectors) > 0 {
+		images, err := o.resolveCollectors()
+		if err != nil {
+			return err
+		}
+		o.Images = append(o.Images, images...)
+	}
 	if len(o.Images) == 0 {
 		var image string
 		var err error
@@ -235,18 +296,28 @@ type MustGatherOptions struct {
 	Client           kubernetes.Interface
 	ConfigClient     configclient.Interface
 	ImageClient      imagev1client.ImageV1Interface
+	DynamicClient    dynamic.Interface
 	RESTClientGetter genericclioptions.RESTClientGetter
 
 	NodeName     string
 	NodeSelector string
 	HostNetwork  bool
 	DestDir      string
+	Resume       string
 	SourceDir    string
 	Images       []string
 	ImageStreams []string
+	Collectors   []string
 	Command      []string
 	Timeout      time.Duration
 	timeoutStr   string
+	Since        time.Duration
+	SinceTime    string
+	// Compress adds the output to an archive named after DestDir instead of leaving it in DestDir.
+	Compress bool
+	// MaxSize is the number of bytes of output after which no more is downloaded, zero is unlimited.
+	MaxSize      int64
+	maxSizeStr   string
 	RunNamespace string
 	Keep         bool
 
@@ -273,6 +344,33 @@ func (o *MustGatherOptions) Validate() error {
 	if strings.Contains(o.DestDir, ":") {
 		return fmt.Errorf("--dest-dir may not contain special characters such as colon(:)")
 	}
+	if len(o.Resume) > 0 {
+		if info, err := os.Stat(o.Resume); err != nil || !info.IsDir() {
+			return fmt.Errorf("--resume must be the destination directory of an earlier gather")
+		}
+		if o.Compress {
+			return fmt.Errorf("--resume and --compress are mutually exclusive")
+		}
+	}
+	if o.Since != 0 && len(o.SinceTime) > 0 {
+		return fmt.Errorf("--since and --since-time are mutually exclusive")
+	}
+	if o.Since < 0 {
+		return fmt.Errorf("--since must be greater than zero")
+	}
+	if len(o.SinceTime) > 0 {
+		if _, err := time.Parse(time.RFC3339, o.SinceTime); err != nil {
+			return fmt.Errorf("--since-time must be an RFC3339 timestamp: %v", err)
+		}
+	}
+	if o.MaxSize < 0 {
+		return fmt.Errorf("--max-size must not be negative")
+	}
+	if o.Compress {
+		if err := checkArchiver(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -288,6 +386,22 @@ func (o *MustGatherOptions) Run() error {
 		o.PrintBasicClusterState(context.TODO())
 	}()
 
+	// The archive is completed last, once the backup collection and the timestamps were written to DestDir.
+	var compressed *archive
+	if o.Compress {
+		var err error
+		if compressed, err = newArchive(o.DestDir, o.ErrOut); err != nil {
+			return err
+		}
+		defer func() {
+			if err := compressed.Close(); err != nil {
+				fmt.Fprintf(o.ErrOut, "error compressing gathered data: %v\n", err)
+				return
+			}
+			o.log("gathered data written to %s", archiveName(o.DestDir))
+		}()
+	}
+
 	// Due to 'stack unwiding', this should happen after 'clusterState' printing, to ensure that we always
 	//  print our ClusterState information.
 	runBackCollection := true
@@ -298,7 +412,43 @@ func (o *MustGatherOptions) Run() error {
 		o.BackupGathering(context.TODO(), errs)
 	}()
 
-	// Get or create "working" namespace ...
+	// Determine the gatherers to run, skipping those completed by an earlier run ...
+	gatherers, err := o.gatherers()
+	if err != nil {
+		// ensure the errors bubble up to BackupGathering method for display
+		errs = []error{err}
+		return err
+	}
+	if err := os.MkdirAll(o.DestDir, os.ModePerm); err != nil {
+		// ensure the errors bubble up to BackupGathering method for display
+		errs = []error{err}
+		return err
+	}
+	// only a resumed run skips the gatherers that completed before, a fresh run into the same directory
+	// gathers everything again
+	checkpoint := newCheckpoint(o.DestDir)
+	if len(o.Resume) > 0 {
+		if checkpoint, err = loadCheckpoint(o.DestDir); err != nil {
+			// ensure the errors bubble up to BackupGathering method for display
+			errs = []error{err}
+			return err
+		}
+	}
+	var pending []gatherer
+	for _, g := range gatherers {
+		if checkpoint.isCompleted(g) {
+			o.log("gatherer %s: completed by an earlier run, skipping", g)
+			continue
+		}
+		pending = append(pending, g)
+	}
+	if len(gatherers) > 0 && len(pending) == 0 {
+		o.log("all gatherers completed by an earlier run into %s", o.DestDir)
+		runBackCollection = false
+		return nil
+	}
+
+	// ... get or create "working" namespace ...
 	ns, cleanupNamespace, err := o.getNamespace()
 	if err != nil {
 		// ensure the errors bubble up to BackupGathering method for display
@@ -313,59 +463,22 @@ func (o *MustGatherOptions) Run() error {
 
 	// ... and create must-gather pod(s)
 	var pods []*corev1.Pod
-	for _, image := range o.Images {
-		_, err := imagereference.Parse(image)
+	for _, g := range pending {
+		pod, err := o.Client.CoreV1().Pods(ns.Name).Create(context.TODO(), o.newPod(g.node, g.image), metav1.CreateOptions{})
 		if err != nil {
-			line := fmt.Sprintf("unable to parse image reference %s: %v", image, err)
-			o.log(line)
 			// ensure the errors bubble up to BackupGathering method for display
-			errs = []error{fmt.Errorf(line)}
+			errs = []error{err}
 			return err
 		}
 		if o.NodeSelector != "" {
-			nodes, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
-				LabelSelector: o.NodeSelector,
-			})
-			if err != nil {
-				// ensure the errors bubble up to BackupGathering method for display
-				errs = []error{err}
-				return err
-			}
-			for _, node := range nodes.Items {
-				pod, err := o.Client.CoreV1().Pods(ns.Name).Create(context.TODO(), o.newPod(node.Name, image), metav1.CreateOptions{})
-				if err != nil {
-					// ensure the errors bubble up to BackupGathering method for display
-					errs = []error{err}
-					return err
-				}
-				o.log("pod: %s on node: %s for plug-in image %s created", pod.Name, node.Name, image)
-				pods = append(pods, pod)
-			}
+			o.log("pod: %s on node: %s for plug-in image %s created", pod.Name, g.node, g.image)
 		} else {
-			if o.NodeName != "" {
-				if _, err := o.Client.CoreV1().Nodes().Get(context.TODO(), o.NodeName, metav1.GetOptions{}); err != nil {
-					// ensure the errors bubble up to BackupGathering method for display
-					errs = []error{err}
-					return err
-				}
-			}
-			pod, err := o.Client.CoreV1().Pods(ns.Name).Create(context.TODO(), o.newPod(o.NodeName, image), metav1.CreateOptions{})
-			if err != nil {
-				// ensure the errors bubble up to BackupGathering method for display
-				errs = []error{err}
-				return err
-			}
-			o.log("pod for plug-in image %s created", image)
-			pods = append(pods, pod)
+			o.log("pod for plug-in image %s created", g.image)
 		}
+		pods = append(pods, pod)
 	}
 
 	// log timestamps...
-	if err := os.MkdirAll(o.DestDir, os.ModePerm); err != nil {
-		// ensure the errors bubble up to BackupGathering method for display
-		errs = []error{err}
-		return err
-	}
 	if err := o.logTimestamp(); err != nil {
 		// ensure the errors bubble up to BackupGathering method for display
 		errs = []error{err}
@@ -373,14 +486,20 @@ func (o *MustGatherOptions) Run() error {
 	}
 	defer o.logTimestamp()
 
+	budget := &sizeBudget{max: o.MaxSize}
+	// the events of the archived output, which is gone from DestDir when the event filter page is created
+	var eventsLock sync.Mutex
+	archivedEvents := &corev1.EventList{}
 	var wg sync.WaitGroup
 	wg.Add(len(pods))
 	errCh := make(chan error, len(pods))
-	for _, pod := range pods {
-		go func(pod *corev1.Pod) {
+	statuses := make([]string, len(pods))
+	for i, pod := range pods {
+		go func(i int, pod *corev1.Pod) {
 			defer wg.Done()
 
 			log := newPodOutLogger(o.Out, pod.Name)
+			statuses[i] = "failed"
 
 			// wait for gather container to be running (gather is running)
 			if err := o.waitForGatherContainerRunning(pod); err != nil {
@@ -405,24 +524,78 @@ func (o *MustGatherOptions) Run() error {
 				return
 			}
 
-			// copy the gathered files into the local destination dir
-			log("downloading gather output")
 			pod, err = o.Client.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
 			if err != nil {
 				log("gather output not downloaded: %v\n", err)
 				errCh <- fmt.Errorf("unable to download output from pod %s: %s", pod.Name, err)
 				return
 			}
-			if err := o.copyFilesFromPod(pod); err != nil {
+
+			// copy the gathered files into the local destination dir, if they fit in --max-size
+			var reserved int64
+			if budget.max > 0 {
+				size, err := o.remoteOutputSize(pod)
+				if err != nil {
+					log("gather output not downloaded: unable to determine its size for --max-size: %v", err)
+					errCh <- fmt.Errorf("unable to determine the size of the output of pod %s: %s", pod.Name, err)
+					return
+				}
+				if !budget.reserve(size) {
+					log("gather output of %s not downloaded: --max-size reached", units.BytesSize(float64(size)))
+					statuses[i] = statusTruncated
+					return
+				}
+				reserved = size
+			}
+			log("downloading gather output")
+			destDir, err := o.copyFilesFromPod(pod)
+			if err != nil {
 				log("gather output not downloaded: %v\n", err)
 				errCh <- fmt.Errorf("unable to download output from pod %s: %s", pod.Name, err)
 				return
 			}
-		}(pod)
+			size, err := dirSize(destDir)
+			if err != nil {
+				log("unable to determine the size of the gather output: %v", err)
+				size = reserved
+			}
+			budget.use(size - reserved)
+			if compressed != nil {
+				// read the events before the output is archived, for the event filter page
+				if events, err := inspect.GetAllEvents(destDir); err != nil {
+					log("unable to read the gathered events: %v", err)
+				} else {
+					eventsLock.Lock()
+					archivedEvents.Items = append(archivedEvents.Items, events.Items...)
+					eventsLock.Unlock()
+				}
+				if err := compressed.add(destDir); err != nil {
+					log("gather output not compressed: %v", err)
+					errCh <- fmt.Errorf("unable to compress output from pod %s: %s", pod.Name, err)
+					return
+				}
+			}
+			statuses[i] = "completed"
+			if err := checkpoint.complete(pending[i]); err != nil {
+				log("unable to record completed gather: %v", err)
+			}
+		}(i, pod)
 	}
 	wg.Wait()
 	close(errCh)
 
+	var truncated []string
+	for i, g := range pending {
+		o.log("gatherer %s: %s", g, statuses[i])
+		if statuses[i] == statusTruncated {
+			truncated = append(truncated, g.String())
+		}
+	}
+	if len(truncated) > 0 {
+		o.log("--max-size of %s reached after downloading %s, the output of %d gatherer(s) was not downloaded: %s",
+			units.BytesSize(float64(budget.max)), units.BytesSize(float64(budget.size())), len(truncated), strings.Join(truncated, ", "))
+	}
+
 	for i := range errCh {
 		errs = append(errs, i)
 	}
@@ -437,13 +610,44 @@ func (o *MustGatherOptions) Run() error {
 	}
 
 	// now gather all the events into a single file and produce a unified file
-	if err := inspect.CreateEventFilterPage(o.DestDir); err != nil {
+	if err := inspect.CreateEventFilterPageWithEvents(o.DestDir, archivedEvents); err != nil {
 		errs = append(errs, err)
 	}
 
 	return errors.NewAggregate(errs)
 }
 
+// gatherers returns a gatherer for every image, and for every node matching NodeSelector when it is set.
+func (o *MustGatherOptions) gatherers() ([]gatherer, error) {
+	var gatherers []gatherer
+	for _, image := range o.Images {
+		if _, err := imagereference.Parse(image); err != nil {
+			line := fmt.Sprintf("unable to parse image reference %s: %v", image, err)
+			o.log(line)
+			return nil, fmt.Errorf(line)
+		}
+		if o.NodeSelector != "" {
+			nodes, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{
+				LabelSelector: o.NodeSelector,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, node := range nodes.Items {
+				gatherers = append(gatherers, gatherer{image: image, node: node.Name})
+			}
+			continue
+		}
+		if o.NodeName != "" {
+			if _, err := o.Client.CoreV1().Nodes().Get(context.TODO(), o.NodeName, metav1.GetOptions{}); err != nil {
+				return nil, err
+			}
+		}
+		gatherers = append(gatherers, gatherer{image: image, node: o.NodeName})
+	}
+	return gatherers, nil
+}
+
 func newPodOutLogger(out io.Writer, podName string) func(string, ...interface{}) {
 	writer := newPrefixWriter(out, fmt.Sprintf("[%s] OUT", podName))
 	return func(format string, a ...interface{}) {
@@ -464,7 +668,8 @@ func (o *MustGatherOptions) logTimestamp() error {
 	return err
 }
 
-func (o *MustGatherOptions) copyFilesFromPod(pod *corev1.Pod) error {
+// copyFilesFromPod downloads the output of the gather pod and returns the directory it was written to.
+func (o *MustGatherOptions) copyFilesFromPod(pod *corev1.Pod) (string, error) {
 	streams := o.IOStreams
 	streams.Out = newPrefixWriter(streams.Out, fmt.Sprintf("[%s] OUT", pod.Name))
 	imageFolder := regexp.MustCompile("[^A-Za-z0-9]+").ReplaceAllString(pod.Status.ContainerStatuses[0].ImageID, "-")
@@ -475,7 +680,7 @@ func (o *MustGatherOptions) copyFilesFromPod(pod *corev1.Pod) error {
 		destDir = path.Join(o.DestDir, imageFolder)
 	}
 	if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
-		return err
+		return "", err
 	}
 	rsyncOptions := &rsync.RsyncOptions{
 		Namespace:     pod.Namespace,
@@ -495,7 +700,91 @@ func (o *MustGatherOptions) copyFilesFromPod(pod *corev1.Pod) error {
 		// re-try copying data before letting it go
 		err = rsyncOptions.RunRsync()
 	}
-	return err
+	return destDir, err
+}
+
+// statusTruncated is the status of a gatherer whose output was not downloaded because of --max-size.
+const statusTruncated = "not downloaded, --max-size reached"
+
+// sizeBudget tracks the size of the downloaded output against --max-size. It is safe for concurrent use.
+type sizeBudget struct {
+	lock sync.Mutex
+	max  int64
+	used int64
+}
+
+// reserve uses size bytes of the budget before they are downloaded and returns true, or returns false without
+// using anything if there is a limit and they do not fit in it, so that concurrent downloads never exceed it.
+func (b *sizeBudget) reserve(size int64) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.max > 0 && b.used+size > b.max {
+		return false
+	}
+	b.used += size
+	return true
+}
+
+func (b *sizeBudget) use(size int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.used += size
+}
+
+func (b *sizeBudget) size() int64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.used
+}
+
+// remoteOutputSize returns the size of the output left in the copy container of the gather pod, rounded up to
+// kilobytes.
+func (o *MustGatherOptions) remoteOutputSize(pod *corev1.Pod) (int64, error) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	execOptions := &kexec.ExecOptions{
+		StreamOptions: kexec.StreamOptions{
+			Namespace:     pod.Namespace,
+			PodName:       pod.Name,
+			ContainerName: "copy",
+			IOStreams:     genericclioptions.IOStreams{Out: out, ErrOut: errOut},
+		},
+		Executor:  &kexec.DefaultRemoteExecutor{},
+		PodClient: o.Client.CoreV1(),
+		Config:    o.Config,
+		Command:   []string{"du", "-sk", path.Clean(o.SourceDir)},
+	}
+	if err := execOptions.Run(); err != nil {
+		return 0, fmt.Errorf("%v: %s", err, strings.TrimSpace(errOut.String()))
+	}
+	return parseDiskUsage(out.String())
+}
+
+// parseDiskUsage returns the size in bytes of the output of du -sk.
+func parseDiskUsage(output string) (int64, error) {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected disk usage %q", output)
+	}
+	kilobytes, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected disk usage %q", output)
+	}
+	return kilobytes * 1024, nil
+}
+
+// dirSize returns the total size of the regular files in dir.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
 }
 
 func (o *MustGatherOptions) getGatherContainerLogs(pod *corev1.Pod) error {
@@ -788,6 +1077,12 @@ func (o *MustGatherOptions) newPod(node, image string) *corev1.Pod {
 			},
 		},
 	}
+	if o.Since != 0 {
+		ret.Spec.Containers[0].Env = append(ret.Spec.Containers[0].Env, corev1.EnvVar{Name: "MUST_GATHER_SINCE", Value: o.Since.String()})
+	}
+	if len(o.SinceTime) > 0 {
+		ret.Spec.Containers[0].Env = append(ret.Spec.Containers[0].Env, corev1.EnvVar{Name: "MUST_GATHER_SINCE_TIME", Value: o.SinceTime})
+	}
 	if len(o.Command) > 0 {
 		// always force disk flush to ensure that all data gathered is accessible in the copy container
 		ret.Spec.Containers[0].Command = []string{"/bin/bash", "-c", fmt.Sprintf("%s; sync", strings.Join(o.Command, " "))}
@@ -819,6 +1114,7 @@ func (o *MustGatherOptions) BackupGathering(ctx context.Context, errs []error) {
 	inspectOptions := inspect.NewInspectOptions(o.IOStreams)
 	inspectOptions.RESTConfig = rest.CopyConfig(o.Config)
 	inspectOptions.DestDir = path.Join(o.DestDir, fmt.Sprintf("inspect.local.%06d", rand.Int63()))
+	inspectOptions.SetLogsSince(o.Since, o.SinceTime)
 
 	if err := inspectOptions.Complete([]string{"clusteroperators.v1.config.openshift.io"}); err != nil {
 		fmt.Fprintf(o.ErrOut, "error completing backup collection: %v\n", err)
@@ -1,7 +1,13 @@
 package mustgather
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -179,3 +185,173 @@ func TestGetNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckpoint(t *testing.T) {
+	o := MustGatherOptions{
+		Client: fake.NewSimpleClientset(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-a", Labels: map[string]string{"gather": "true"}}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-b", Labels: map[string]string{"gather": "true"}}},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "master"}},
+		),
+		Images:       []string{"registry.test/one:latest", "registry.test/two:latest"},
+		NodeSelector: "gather=true",
+		LogOut:       io.Discard,
+	}
+	gatherers, err := o.gatherers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []gatherer{
+		{image: "registry.test/one:latest", node: "worker-a"},
+		{image: "registry.test/one:latest", node: "worker-b"},
+		{image: "registry.test/two:latest", node: "worker-a"},
+		{image: "registry.test/two:latest", node: "worker-b"},
+	}
+	if !reflect.DeepEqual(gatherers, expected) {
+		t.Fatalf("expected gatherers %v, got %v", expected, gatherers)
+	}
+
+	dir := t.TempDir()
+	c, err := loadCheckpoint(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.complete(gatherers[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.complete(gatherers[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	// a resumed run only sees the gatherers recorded by the earlier one
+	resumed, err := loadCheckpoint(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, g := range gatherers {
+		if completed := i == 1 || i == 2; resumed.isCompleted(g) != completed {
+			t.Errorf("gatherer %s: expected completed %t", g, completed)
+		}
+	}
+
+	// a fresh run into the same directory starts over and replaces the old checkpoint
+	fresh := newCheckpoint(dir)
+	for _, g := range gatherers {
+		if fresh.isCompleted(g) {
+			t.Errorf("gatherer %s: expected a fresh run to gather it again", g)
+		}
+	}
+	if err := fresh.complete(gatherers[0]); err != nil {
+		t.Fatal(err)
+	}
+	if resumed, err = loadCheckpoint(dir); err != nil {
+		t.Fatal(err)
+	}
+	for i, g := range gatherers {
+		if completed := i == 0; resumed.isCompleted(g) != completed {
+			t.Errorf("gatherer %s: expected completed %t after a fresh run", g, completed)
+		}
+	}
+}
+
+func TestArchiveAndSizeBudget(t *testing.T) {
+	destDir := filepath.Join(t.TempDir(), "must-gather.local.1")
+	gathered := filepath.Join(destDir, "quay-io-one")
+	if err := os.MkdirAll(filepath.Join(gathered, "namespaces"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gathered, "namespaces", "pods.yaml"), []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(destDir, "timestamp"), []byte("now\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := dirSize(gathered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 10 {
+		t.Errorf("expected a size of 10, got %d", size)
+	}
+	budget := &sizeBudget{max: 15}
+	if !budget.reserve(size) {
+		t.Errorf("expected %d bytes to fit in the budget", size)
+	}
+	if budget.reserve(size) {
+		t.Errorf("expected %d more bytes not to fit in the budget after %d bytes", size, budget.size())
+	}
+	if !budget.reserve(5) || budget.size() != 15 {
+		t.Errorf("expected the rest of the budget to be usable, used %d bytes", budget.size())
+	}
+	if !(&sizeBudget{used: size}).reserve(size) {
+		t.Errorf("expected a budget without a limit never to be exhausted")
+	}
+
+	for output, expected := range map[string]int64{"12\t/must-gather\n": 12 * 1024, "0 /must-gather": 0} {
+		if size, err := parseDiskUsage(output); err != nil || size != expected {
+			t.Errorf("expected %q to be %d bytes, got %d: %v", output, expected, size, err)
+		}
+	}
+	if _, err := parseDiskUsage("du: /must-gather: No such file or directory"); err == nil {
+		t.Errorf("expected an error for unexpected disk usage")
+	}
+
+	if err := checkArchiver(); err != nil {
+		t.Skip(err)
+	}
+	a, err := newArchive(destDir, os.Stderr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.add(gathered); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(gathered); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed once archived, got %v", gathered, err)
+	}
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(destDir); !os.IsNotExist(err) {
+		t.Errorf("expected %s to be removed once archived, got %v", destDir, err)
+	}
+
+	f, err := os.Open(archiveName(destDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zstd := exec.Command("zstd", "--decompress", "--stdout")
+	zstd.Stdin = f
+	decompressed, err := zstd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(bytes.NewReader(decompressed))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(data)
+	}
+	expected := map[string]string{
+		"must-gather.local.1/quay-io-one/namespaces/pods.yaml": "0123456789",
+		"must-gather.local.1/timestamp":                        "now\n",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected archive contents %v, got %v", expected, files)
+	}
+}
@@ -0,0 +1,663 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	kexec "k8s.io/kubectl/pkg/cmd/exec"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/templates"
+	admissionapi "k8s.io/pod-security-admission/api"
+
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+	"github.com/openshift/oc/pkg/helpers/conditions"
+)
+
+var (
+	checkLong = templates.LongDesc(`
+		Check the connectivity of the pod network
+
+		A probe pod is started on every Ready node, or on the nodes selected with --selector, in a
+		temporary namespace, along with a service in front of each probe pod. Every probe pod then
+		checks that it can:
+
+		* connect to every probe pod (pod-to-pod)
+		* connect to the service of every probe pod (pod-to-service)
+		* connect to the kubernetes API service (pod-to-api)
+		* resolve the kubernetes API service through the cluster DNS (cluster-dns)
+		* resolve --egress-host through the cluster DNS (egress-dns)
+
+		The report lists, for every node, how many of the probes from its pod succeeded, followed by
+		every failed probe. The command fails if a probe failed or a probe pod did not start. Set
+		--egress-host to an empty string to skip the egress DNS check, for instance in a disconnected
+		cluster.
+	`)
+
+	checkExample = templates.Examples(`
+		# Check the pod network of the whole cluster
+		oc adm network check
+
+		# Check the pod network between the workers, resolving a host of the internal network
+		oc adm network check -l node-role.kubernetes.io/worker --egress-host=registry.example.com
+
+		# Check the pod network and print the result of every probe as JSON
+		oc adm network check -o json
+	`)
+)
+
+const (
+	checkPodToPod     = "pod-to-pod"
+	checkPodToService = "pod-to-service"
+	checkPodToAPI     = "pod-to-api"
+	checkClusterDNS   = "cluster-dns"
+	checkEgressDNS    = "egress-dns"
+
+	// probeLabel selects a probe pod for its service.
+	probeLabel = "network-check.openshift.io/probe"
+	// probePort is the port that probe pods listen on.
+	probePort = 8080
+)
+
+// checks are the checks run by every probe pod, in the order of the report.
+var checks = []string{checkPodToPod, checkPodToService, checkPodToAPI, checkClusterDNS, checkEgressDNS}
+
+type CheckOptions struct {
+	Selector     string
+	Image        string
+	EgressHost   string
+	Timeout      time.Duration
+	ProbeTimeout time.Duration
+	Output       string
+
+	Client      kubernetes.Interface
+	ImageClient imagev1client.ImageV1Interface
+	Config      *rest.Config
+
+	genericclioptions.IOStreams
+}
+
+func NewCheckOptions(streams genericclioptions.IOStreams) *CheckOptions {
+	return &CheckOptions{
+		EgressHost:   "quay.io",
+		Timeout:      5 * time.Minute,
+		ProbeTimeout: 5 * time.Second,
+		IOStreams:    streams,
+	}
+}
+
+// NewCmdCheck creates a command that checks the connectivity of the pod network between nodes.
+func NewCmdCheck(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCheckOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "check [-l SELECTOR]",
+		Short:   "Check the connectivity of the pod network between nodes",
+		Long:    checkLong,
+		Example: checkExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) of the nodes to check. Defaults to every node.")
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "The image of the probe pods, which must provide ncat, getent and timeout. Defaults to the openshift/tools image stream, or the RHEL support tools if it does not exist.")
+	cmd.Flags().StringVar(&o.EgressHost, "egress-host", o.EgressHost, "A host outside of the cluster that the cluster DNS must resolve. The check is skipped if empty.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The time to wait for the probe pods to start.")
+	cmd.Flags().DurationVar(&o.ProbeTimeout, "probe-timeout", o.ProbeTimeout, "The time after which a single probe fails.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml. Prints the result of every probe.")
+
+	return cmd
+}
+
+func (o *CheckOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	var err error
+	if o.Config, err = f.ToRESTConfig(); err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	if o.ImageClient, err = imagev1client.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	if len(o.Image) == 0 {
+		istag, err := o.ImageClient.ImageStreamTags("openshift").Get(context.TODO(), "tools:latest", metav1.GetOptions{})
+		if err != nil {
+			klog.V(2).Infof("Unable to resolve image stream 'openshift/tools:latest': %v", err)
+			o.Image = "registry.redhat.io/rhel8/support-tools"
+		} else {
+			o.Image = istag.Image.DockerImageReference
+		}
+	}
+	return nil
+}
+
+func (o *CheckOptions) Validate() error {
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be greater than 0")
+	}
+	if o.ProbeTimeout < time.Second {
+		return fmt.Errorf("--probe-timeout must be at least 1s")
+	}
+	if strings.ContainsAny(o.EgressHost, "| \t") {
+		return fmt.Errorf("invalid --egress-host %q", o.EgressHost)
+	}
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be one of json or yaml")
+	}
+	return nil
+}
+
+// probe is a probe pod and its service.
+type probe struct {
+	Node      string
+	Name      string
+	PodIP     string
+	ServiceIP string
+}
+
+// probeResult is the result of a probe from the pod on a node to a target.
+type probeResult struct {
+	Node    string `json:"node"`
+	Check   string `json:"check"`
+	Target  string `json:"target"`
+	Success bool   `json:"success"`
+}
+
+// skippedNode is a node on which no probe pod ran.
+type skippedNode struct {
+	Node   string `json:"node"`
+	Reason string `json:"reason"`
+}
+
+// checkReport is the result of the check.
+type checkReport struct {
+	Nodes   []string      `json:"nodes"`
+	Skipped []skippedNode `json:"skipped,omitempty"`
+	// Failed are the nodes on which the probe pod did not start.
+	Failed  []skippedNode `json:"failed,omitempty"`
+	Results []probeResult `json:"results"`
+}
+
+// failures returns the number of failed probes and probe pods.
+func (r checkReport) failures() int {
+	failures := len(r.Failed)
+	for _, result := range r.Results {
+		if !result.Success {
+			failures++
+		}
+	}
+	return failures
+}
+
+func (o *CheckOptions) Run() error {
+	// progress is reported on the standard error when the report is printed in a format
+	log := o.Out
+	if len(o.Output) > 0 {
+		log = o.ErrOut
+	}
+
+	report := checkReport{}
+	nodes, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: o.Selector})
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, node := range nodes.Items {
+		switch {
+		case node.Labels[corev1.LabelOSStable] == "windows":
+			report.Skipped = append(report.Skipped, skippedNode{Node: node.Name, Reason: "Windows node"})
+		case !conditions.NodeReady(&node):
+			report.Skipped = append(report.Skipped, skippedNode{Node: node.Name, Reason: "node is not Ready"})
+		default:
+			names = append(names, node.Name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no Ready nodes to check")
+	}
+	sort.Strings(names)
+
+	api, err := o.Client.CoreV1().Services("default").Get(context.TODO(), "kubernetes", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get the kubernetes API service: %v", err)
+	}
+
+	namespace, cleanup, err := o.createNamespace(log)
+	if err != nil {
+		return err
+	}
+
+	return interrupt.New(nil, cleanup).Run(func() error {
+		probes, failed, err := o.startProbes(namespace, names, log)
+		if err != nil {
+			return err
+		}
+		report.Failed = failed
+		for _, p := range probes {
+			report.Nodes = append(report.Nodes, p.Node)
+		}
+
+		fmt.Fprintf(log, "Checking the network from %d nodes...\n", len(probes))
+		targets := probeTargets(probes, api, o.EgressHost)
+		report.Results = o.runProbes(namespace, probes, targets)
+
+		if len(o.Output) > 0 {
+			if err := printReportAs(o.Out, report, o.Output); err != nil {
+				return err
+			}
+		} else if err := printReport(o.Out, report); err != nil {
+			return err
+		}
+		if failures := report.failures(); failures > 0 {
+			return fmt.Errorf("the network check failed: %d failures", failures)
+		}
+		return nil
+	})
+}
+
+// createNamespace creates the temporary namespace of the probe pods and returns the function that removes it.
+func (o *CheckOptions) createNamespace(log io.Writer) (string, func(), error) {
+	ns, err := o.Client.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "openshift-network-check-",
+			Labels: map[string]string{
+				admissionapi.EnforceLevelLabel:                   string(admissionapi.LevelPrivileged),
+				admissionapi.AuditLevelLabel:                     string(admissionapi.LevelPrivileged),
+				admissionapi.WarnLevelLabel:                      string(admissionapi.LevelPrivileged),
+				"security.openshift.io/scc.podSecurityLabelSync": "false",
+			},
+			Annotations: map[string]string{
+				"oc.openshift.io/command":    "oc adm network check",
+				"openshift.io/node-selector": "",
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create temporary namespace: %v", err)
+	}
+	fmt.Fprintf(log, "Temporary namespace %s is created for the probe pods...\n", ns.Name)
+
+	cleanup := func() {
+		if err := o.Client.CoreV1().Namespaces().Delete(context.TODO(), ns.Name, metav1.DeleteOptions{}); err != nil {
+			klog.V(2).Infof("Unable to delete temporary namespace %s: %v", ns.Name, err)
+		} else {
+			fmt.Fprintf(log, "Temporary namespace %s was removed.\n", ns.Name)
+		}
+	}
+	return ns.Name, cleanup, nil
+}
+
+// startProbes creates a probe pod and its service for every node, and waits for the pods to run and be endpoints of
+// their services. The nodes on which a probe pod did not start are returned as failed.
+func (o *CheckOptions) startProbes(namespace string, nodes []string, log io.Writer) ([]probe, []skippedNode, error) {
+	var probes []probe
+	for i, node := range nodes {
+		name := fmt.Sprintf("probe-%d", i)
+		if _, err := o.Client.CoreV1().Pods(namespace).Create(context.TODO(), newProbePod(node, name, o.Image), metav1.CreateOptions{}); err != nil {
+			return nil, nil, err
+		}
+		service, err := o.Client.CoreV1().Services(namespace).Create(context.TODO(), newProbeService(name), metav1.CreateOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		probes = append(probes, probe{Node: node, Name: name, ServiceIP: service.Spec.ClusterIP})
+	}
+	fmt.Fprintf(log, "Waiting for %d probe pods to start...\n", len(probes))
+
+	reasons := map[string]string{}
+	err := wait.PollImmediate(2*time.Second, o.Timeout, func() (bool, error) {
+		pods, err := o.Client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			klog.V(2).Infof("Unable to list the probe pods: %v", err)
+			return false, nil
+		}
+		endpoints, err := o.Client.CoreV1().Endpoints(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			klog.V(2).Infof("Unable to list the endpoints of the probe services: %v", err)
+			return false, nil
+		}
+		ready := map[string]bool{}
+		for _, e := range endpoints.Items {
+			for _, subset := range e.Subsets {
+				ready[e.Name] = ready[e.Name] || len(subset.Addresses) > 0
+			}
+		}
+
+		done := true
+		for _, pod := range pods.Items {
+			name := pod.Labels[probeLabel]
+			if len(reasons[name]) > 0 {
+				continue
+			}
+			if reason := probePodFailure(&pod); len(reason) > 0 {
+				reasons[name] = reason
+				continue
+			}
+			for i := range probes {
+				if probes[i].Name == name && len(pod.Status.PodIP) > 0 {
+					probes[i].PodIP = pod.Status.PodIP
+				}
+			}
+			if pod.Status.Phase != corev1.PodRunning || len(pod.Status.PodIP) == 0 || !ready[name] {
+				done = false
+			}
+		}
+		return done, nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		return nil, nil, err
+	}
+
+	var running []probe
+	var failed []skippedNode
+	for _, p := range probes {
+		switch {
+		case len(reasons[p.Name]) > 0:
+			failed = append(failed, skippedNode{Node: p.Node, Reason: reasons[p.Name]})
+		case len(p.PodIP) == 0:
+			failed = append(failed, skippedNode{Node: p.Node, Reason: fmt.Sprintf("the probe pod did not start within %s", o.Timeout)})
+		default:
+			running = append(running, p)
+		}
+	}
+	if len(running) == 0 {
+		return nil, nil, fmt.Errorf("no probe pod started: %s", failed[0].Reason)
+	}
+	return running, failed, nil
+}
+
+// probePodFailure returns why the probe pod cannot run, or an empty string if it may still run.
+func probePodFailure(pod *corev1.Pod) string {
+	if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
+		return fmt.Sprintf("the probe pod terminated: %s", pod.Status.Phase)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil {
+			switch status.State.Waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "CrashLoopBackOff":
+				return fmt.Sprintf("the probe pod did not start: %s: %s", status.State.Waiting.Reason, status.State.Waiting.Message)
+			}
+		}
+	}
+	return ""
+}
+
+// newProbePod creates a pod on the node that accepts connections on the probe port.
+func newProbePod(nodeName, name, image string) *corev1.Pod {
+	zero := int64(0)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{probeLabel: name},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                      nodeName,
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: &zero,
+			Tolerations: []corev1.Toleration{
+				{
+					// tolerate every taint, the pod must run on the node
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "probe",
+					Image:   image,
+					Command: []string{"ncat", "--keep-open", "--listen", strconv.Itoa(probePort), "--sh-exec", "echo ok"},
+					Ports:   []corev1.ContainerPort{{ContainerPort: probePort, Protocol: corev1.ProtocolTCP}},
+				},
+			},
+		},
+	}
+}
+
+// newProbeService creates the service of a probe pod.
+func newProbeService(name string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{probeLabel: name},
+			Ports: []corev1.ServicePort{
+				{Port: probePort, TargetPort: intstr.FromInt(probePort), Protocol: corev1.ProtocolTCP},
+			},
+		},
+	}
+}
+
+// probeTarget is a target of the probes of every pod.
+type probeTarget struct {
+	Check  string
+	Target string
+	Host   string
+	Port   int32
+}
+
+// probeTargets returns the targets of the probes: every probe pod and service, the kubernetes API service, and the
+// names resolved through the cluster DNS.
+func probeTargets(probes []probe, api *corev1.Service, egressHost string) []probeTarget {
+	var targets []probeTarget
+	for _, p := range probes {
+		targets = append(targets, probeTarget{Check: checkPodToPod, Target: p.Node, Host: p.PodIP, Port: probePort})
+	}
+	for _, p := range probes {
+		targets = append(targets, probeTarget{Check: checkPodToService, Target: p.Node, Host: p.ServiceIP, Port: probePort})
+	}
+	var apiPort int32 = 443
+	if len(api.Spec.Ports) > 0 {
+		apiPort = api.Spec.Ports[0].Port
+	}
+	targets = append(targets,
+		probeTarget{Check: checkPodToAPI, Target: "kubernetes.default", Host: api.Spec.ClusterIP, Port: apiPort},
+		probeTarget{Check: checkClusterDNS, Target: "kubernetes.default", Host: "kubernetes.default.svc"},
+	)
+	if len(egressHost) > 0 {
+		targets = append(targets, probeTarget{Check: checkEgressDNS, Target: egressHost, Host: egressHost})
+	}
+	return targets
+}
+
+// probeScript runs every probe given as an argument concurrently, and prints the exit code of each one. A probe
+// argument is the check, the target, the host and the port, separated by |.
+const probeScript = `
+probe() {
+	case "$1" in
+	*-dns) timeout %[1]d getent hosts "$3" ;;
+	*) timeout %[1]d ncat --wait %[1]d --recv-only "$3" "$4" ;;
+	esac
+}
+for target in "$@"; do
+	(
+		IFS='|'
+		set -- $target
+		probe "$@" >/dev/null 2>&1
+		echo "$1|$2|$?"
+	) &
+done
+wait
+`
+
+// runProbes runs the probes to every target from every probe pod concurrently.
+func (o *CheckOptions) runProbes(namespace string, probes []probe, targets []probeTarget) []probeResult {
+	command := []string{"/bin/sh", "-c", fmt.Sprintf(probeScript, int(o.ProbeTimeout.Seconds())), "sh"}
+	for _, t := range targets {
+		command = append(command, fmt.Sprintf("%s|%s|%s|%d", t.Check, t.Target, t.Host, t.Port))
+	}
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	var results []probeResult
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p probe) {
+			defer wg.Done()
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			err := o.exec(namespace, p.Name, command, out, errOut)
+			if err != nil {
+				klog.V(2).Infof("Unable to run the probes from the pod on node %s: %v: %s", p.Node, err, errOut.String())
+			}
+			nodeResults := parseProbeOutput(p.Node, out.String(), targets)
+			lock.Lock()
+			defer lock.Unlock()
+			results = append(results, nodeResults...)
+		}(p)
+	}
+	wg.Wait()
+
+	order := map[string]int{}
+	for i, check := range checks {
+		order[check] = i
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Node != b.Node {
+			return a.Node < b.Node
+		}
+		if a.Check != b.Check {
+			return order[a.Check] < order[b.Check]
+		}
+		return a.Target < b.Target
+	})
+	return results
+}
+
+// parseProbeOutput returns the result of every target from the output of the probe script on a node. The targets
+// missing from the output, because the script was interrupted, failed.
+func parseProbeOutput(node, out string, targets []probeTarget) []probeResult {
+	succeeded := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), "|")
+		if len(parts) != 3 {
+			continue
+		}
+		succeeded[parts[0]+"|"+parts[1]] = parts[2] == "0"
+	}
+	var results []probeResult
+	for _, t := range targets {
+		results = append(results, probeResult{Node: node, Check: t.Check, Target: t.Target, Success: succeeded[t.Check+"|"+t.Target]})
+	}
+	return results
+}
+
+// exec runs command in the probe pod.
+func (o *CheckOptions) exec(namespace, pod string, command []string, out, errOut io.Writer) error {
+	execOptions := &kexec.ExecOptions{
+		StreamOptions: kexec.StreamOptions{
+			Namespace:     namespace,
+			PodName:       pod,
+			ContainerName: "probe",
+			IOStreams: genericclioptions.IOStreams{
+				Out:    out,
+				ErrOut: errOut,
+			},
+		},
+		Executor:  &kexec.DefaultRemoteExecutor{},
+		PodClient: o.Client.CoreV1(),
+		Config:    o.Config,
+		Command:   command,
+	}
+	if err := execOptions.Validate(); err != nil {
+		return err
+	}
+	return execOptions.Run()
+}
+
+// printReport prints a matrix of the succeeded probes of every node and check, followed by the failures.
+func printReport(out io.Writer, report checkReport) error {
+	type counts struct{ succeeded, total int }
+	matrix := map[string]map[string]*counts{}
+	for _, result := range report.Results {
+		if matrix[result.Node] == nil {
+			matrix[result.Node] = map[string]*counts{}
+		}
+		c := matrix[result.Node][result.Check]
+		if c == nil {
+			c = &counts{}
+			matrix[result.Node][result.Check] = c
+		}
+		c.total++
+		if result.Success {
+			c.succeeded++
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintf(w, "NODE\t%s\n", strings.ToUpper(strings.Join(checks, "\t")))
+	for _, node := range report.Nodes {
+		fmt.Fprint(w, node)
+		for _, check := range checks {
+			if c := matrix[node][check]; c != nil {
+				fmt.Fprintf(w, "\t%d/%d", c.succeeded, c.total)
+			} else {
+				fmt.Fprint(w, "\t-")
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if report.failures() > 0 {
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+		fmt.Fprintln(w, "FAILED\tCHECK\tTARGET")
+		for _, failed := range report.Failed {
+			fmt.Fprintf(w, "%s\tprobe-pod\t%s\n", failed.Node, failed.Reason)
+		}
+		for _, result := range report.Results {
+			if !result.Success {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", result.Node, result.Check, result.Target)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(report.Skipped) > 0 {
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+		fmt.Fprintln(w, "SKIPPED\tREASON")
+		for _, skipped := range report.Skipped {
+			fmt.Fprintf(w, "%s\t%s\n", skipped.Node, skipped.Reason)
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+// printReportAs writes the report in the output format, json or yaml.
+func printReportAs(out io.Writer, report checkReport, format string) error {
+	return cmdutil.PrintJSONOrYAML(out, format, report)
+}
@@ -0,0 +1,101 @@
+package network
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestProbeTargets(t *testing.T) {
+	probes := []probe{
+		{Node: "a", Name: "probe-0", PodIP: "10.128.0.5", ServiceIP: "172.30.0.10"},
+		{Node: "b", Name: "probe-1", PodIP: "10.129.0.7", ServiceIP: "172.30.0.11"},
+	}
+	api := &corev1.Service{Spec: corev1.ServiceSpec{ClusterIP: "172.30.0.1", Ports: []corev1.ServicePort{{Port: 443}}}}
+
+	got := probeTargets(probes, api, "quay.io")
+	want := []probeTarget{
+		{Check: checkPodToPod, Target: "a", Host: "10.128.0.5", Port: probePort},
+		{Check: checkPodToPod, Target: "b", Host: "10.129.0.7", Port: probePort},
+		{Check: checkPodToService, Target: "a", Host: "172.30.0.10", Port: probePort},
+		{Check: checkPodToService, Target: "b", Host: "172.30.0.11", Port: probePort},
+		{Check: checkPodToAPI, Target: "kubernetes.default", Host: "172.30.0.1", Port: 443},
+		{Check: checkClusterDNS, Target: "kubernetes.default", Host: "kubernetes.default.svc"},
+		{Check: checkEgressDNS, Target: "quay.io", Host: "quay.io"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("probeTargets() = %v, want %v", got, want)
+	}
+
+	if got := probeTargets(probes, api, ""); got[len(got)-1].Check == checkEgressDNS {
+		t.Errorf("probeTargets() includes the egress DNS check without an egress host")
+	}
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	targets := []probeTarget{
+		{Check: checkPodToPod, Target: "a"},
+		{Check: checkPodToPod, Target: "b"},
+		{Check: checkPodToAPI, Target: "kubernetes.default"},
+		{Check: checkClusterDNS, Target: "kubernetes.default"},
+	}
+	// the probes run concurrently and print in any order, and the output of the last one is missing
+	out := "pod-to-pod|b|1\npod-to-api|kubernetes.default|0\npod-to-pod|a|0\n"
+
+	got := parseProbeOutput("a", out, targets)
+	want := []probeResult{
+		{Node: "a", Check: checkPodToPod, Target: "a", Success: true},
+		{Node: "a", Check: checkPodToPod, Target: "b", Success: false},
+		{Node: "a", Check: checkPodToAPI, Target: "kubernetes.default", Success: true},
+		{Node: "a", Check: checkClusterDNS, Target: "kubernetes.default", Success: false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProbeOutput() = %v, want %v", got, want)
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	report := checkReport{
+		Nodes:   []string{"a", "b"},
+		Skipped: []skippedNode{{Node: "c", Reason: "node is not Ready"}},
+		Failed:  []skippedNode{{Node: "d", Reason: "the probe pod did not start: ErrImagePull: not found"}},
+		Results: []probeResult{
+			{Node: "a", Check: checkPodToPod, Target: "a", Success: true},
+			{Node: "a", Check: checkPodToPod, Target: "b", Success: false},
+			{Node: "a", Check: checkPodToAPI, Target: "kubernetes.default", Success: true},
+			{Node: "b", Check: checkPodToPod, Target: "a", Success: true},
+			{Node: "b", Check: checkPodToPod, Target: "b", Success: true},
+			{Node: "b", Check: checkPodToAPI, Target: "kubernetes.default", Success: true},
+		},
+	}
+	if failures := report.failures(); failures != 2 {
+		t.Errorf("failures() = %d, want 2", failures)
+	}
+
+	out := &bytes.Buffer{}
+	if err := printReport(out, report); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	for _, want := range [][]string{
+		{"NODE", "POD-TO-POD", "POD-TO-SERVICE", "POD-TO-API", "CLUSTER-DNS", "EGRESS-DNS"},
+		{"a", "1/2", "-", "1/1", "-", "-"},
+		{"b", "2/2", "-", "1/1", "-", "-"},
+		{"a", "pod-to-pod", "b"},
+		{"d", "probe-pod", "the probe pod did not start: ErrImagePull: not found"},
+		{"c", "node is not Ready"},
+	} {
+		found := false
+		for _, line := range lines {
+			if strings.Join(strings.Fields(line), " ") == strings.Join(want, " ") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing line %q in:\n%s", strings.Join(want, " "), out.String())
+		}
+	}
+}
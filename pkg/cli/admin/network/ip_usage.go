@@ -0,0 +1,419 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	configv1 "github.com/openshift/api/config/v1"
+	networkv1 "github.com/openshift/api/network/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	networkv1typedclient "github.com/openshift/client-go/network/clientset/versioned/typed/network/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	ipUsageLong = templates.LongDesc(`
+		Report the usage of the pod and service IP addresses
+
+		Every node is given a host subnet of the cluster network, sized by its host prefix, from
+		which its pods get their IP addresses. The report lists:
+
+		* for every cluster network, how many of its host subnets are given to nodes, which limits
+		  the number of nodes of the cluster
+		* for every service network, how many of its addresses are given to services
+		* for every node, how many of the addresses of its host subnet are used by pods, which
+		  limits the number of pods of the node regardless of its max pods
+
+		The networks are projected to be full at the rate their nodes and services were created
+		during the last --projection-window. A warning is printed for every network and host subnet
+		whose usage is above --threshold percent.
+	`)
+
+	ipUsageExample = templates.Examples(`
+		# Report the usage of the pod and service IP addresses
+		oc adm ip-usage
+
+		# Warn about the host subnets above 60% and project the growth of the last 7 days
+		oc adm ip-usage --threshold=60 --projection-window=168h
+	`)
+)
+
+// ovnNodeSubnetsAnnotation holds the host subnets that OVN-Kubernetes gives to a node.
+const ovnNodeSubnetsAnnotation = "k8s.ovn.org/node-subnets"
+
+type IPUsageOptions struct {
+	Threshold        int
+	ProjectionWindow time.Duration
+	Output           string
+
+	Client       kubernetes.Interface
+	ConfigClient configv1client.Interface
+	NetClient    networkv1typedclient.NetworkV1Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewIPUsageOptions(streams genericclioptions.IOStreams) *IPUsageOptions {
+	return &IPUsageOptions{
+		Threshold:        80,
+		ProjectionWindow: 30 * 24 * time.Hour,
+		IOStreams:        streams,
+	}
+}
+
+// NewCmdIPUsage creates a command that reports the usage of the pod and service IP addresses.
+func NewCmdIPUsage(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewIPUsageOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "ip-usage",
+		Short:   "Report the usage of the pod and service IP addresses",
+		Long:    ipUsageLong,
+		Example: ipUsageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().IntVar(&o.Threshold, "threshold", o.Threshold, "The usage percentage above which a network or host subnet is reported.")
+	cmd.Flags().DurationVar(&o.ProjectionWindow, "projection-window", o.ProjectionWindow, "The period whose node and service creations are projected.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml")
+
+	return cmd
+}
+
+func (o *IPUsageOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.ConfigClient, err = configv1client.NewForConfig(config); err != nil {
+		return err
+	}
+	o.NetClient, err = networkv1typedclient.NewForConfig(config)
+	return err
+}
+
+func (o *IPUsageOptions) Validate() error {
+	if o.Threshold < 0 || o.Threshold > 100 {
+		return fmt.Errorf("--threshold must be between 0 and 100")
+	}
+	if o.ProjectionWindow <= 0 {
+		return fmt.Errorf("--projection-window must be positive")
+	}
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be 'json' or 'yaml'")
+	}
+	return nil
+}
+
+// ipUsageReport is the usage of the cluster and service networks, and of the host subnets of the nodes.
+type ipUsageReport struct {
+	ClusterNetworks []networkUsage `json:"clusterNetworks"`
+	ServiceNetworks []networkUsage `json:"serviceNetworks"`
+	Nodes           []nodeIPUsage  `json:"nodes"`
+	Warnings        []string       `json:"warnings,omitempty"`
+}
+
+// networkUsage is the usage of a cluster network, in host subnets, or of a service network, in addresses.
+type networkUsage struct {
+	CIDR       string `json:"cidr"`
+	HostPrefix uint32 `json:"hostPrefix,omitempty"`
+	Capacity   int64  `json:"capacity"`
+	Used       int64  `json:"used"`
+	// Added is how many host subnets or addresses were given during the projection window.
+	Added int64 `json:"added"`
+	// FullIn is how long until the network is full at the rate of the projection window, nil if it does not grow.
+	FullIn *time.Duration `json:"fullIn,omitempty"`
+}
+
+// nodeIPUsage is the usage of the host subnet of a node by its pods.
+type nodeIPUsage struct {
+	Node     string `json:"node"`
+	Subnet   string `json:"subnet"`
+	Capacity int64  `json:"capacity"`
+	Used     int64  `json:"used"`
+}
+
+func (o *IPUsageOptions) Run() error {
+	network, err := o.ConfigClient.ConfigV1().Networks().Get(context.TODO(), "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	nodes, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods, err := o.Client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	services, err := o.Client.CoreV1().Services(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var hostSubnets []networkv1.HostSubnet
+	if network.Status.NetworkType == "OpenShiftSDN" {
+		list, err := o.NetClient.HostSubnets().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		hostSubnets = list.Items
+	}
+
+	report := ipUsage(time.Now(), network, nodes.Items, pods.Items, services.Items, hostSubnets, o.Threshold, o.ProjectionWindow)
+
+	switch o.Output {
+	case "json", "yaml":
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, report)
+	}
+
+	printIPUsage(o.Out, report, o.Threshold, o.ProjectionWindow)
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(o.ErrOut, "warning: %s\n", warning)
+	}
+	return nil
+}
+
+// ipUsage computes the usage of the networks of the cluster from its nodes, pods and services, and warns about the
+// networks and host subnets above the threshold.
+func ipUsage(now time.Time, network *configv1.Network, nodes []corev1.Node, pods []corev1.Pod, services []corev1.Service, hostSubnets []networkv1.HostSubnet, threshold int, window time.Duration) ipUsageReport {
+	report := ipUsageReport{}
+	clusterNetworks, serviceNetworks := network.Status.ClusterNetwork, network.Status.ServiceNetwork
+	if len(clusterNetworks) == 0 {
+		clusterNetworks, serviceNetworks = network.Spec.ClusterNetwork, network.Spec.ServiceNetwork
+	}
+
+	sdnSubnets := map[string]string{}
+	for _, hostSubnet := range hostSubnets {
+		sdnSubnets[hostSubnet.Host] = hostSubnet.Subnet
+	}
+	podsByNode := map[string][]corev1.Pod{}
+	for _, pod := range pods {
+		if pod.Spec.HostNetwork || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podsByNode[pod.Spec.NodeName] = append(podsByNode[pod.Spec.NodeName], pod)
+	}
+
+	// the subnets of the nodes, with their creation time to project the growth of the cluster networks
+	type nodeSubnet struct {
+		subnet  *net.IPNet
+		created time.Time
+	}
+	var subnets []nodeSubnet
+	for _, node := range nodes {
+		for _, cidr := range nodeSubnets(&node, sdnSubnets) {
+			_, subnet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("node %s has an invalid host subnet %q", node.Name, cidr))
+				continue
+			}
+			subnets = append(subnets, nodeSubnet{subnet: subnet, created: node.CreationTimestamp.Time})
+
+			usage := nodeIPUsage{Node: node.Name, Subnet: subnet.String(), Capacity: subnetAddresses(subnet) - 3}
+			for _, pod := range podsByNode[node.Name] {
+				for _, podIP := range pod.Status.PodIPs {
+					if ip := net.ParseIP(podIP.IP); ip != nil && subnet.Contains(ip) {
+						usage.Used++
+					}
+				}
+			}
+			report.Nodes = append(report.Nodes, usage)
+			if percent(usage.Used, usage.Capacity) >= threshold {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("node %s uses %d of the %d pod IPs of host subnet %s", node.Name, usage.Used, usage.Capacity, usage.Subnet))
+			}
+		}
+	}
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		if report.Nodes[i].Node != report.Nodes[j].Node {
+			return report.Nodes[i].Node < report.Nodes[j].Node
+		}
+		return report.Nodes[i].Subnet < report.Nodes[j].Subnet
+	})
+
+	for _, entry := range clusterNetworks {
+		_, cidr, err := net.ParseCIDR(entry.CIDR)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("the cluster network %q is invalid", entry.CIDR))
+			continue
+		}
+		ones, _ := cidr.Mask.Size()
+		usage := networkUsage{CIDR: cidr.String(), HostPrefix: entry.HostPrefix, Capacity: powerOfTwo(int(entry.HostPrefix) - ones)}
+		for _, s := range subnets {
+			if cidr.Contains(s.subnet.IP) {
+				usage.Used++
+				if now.Sub(s.created) < window {
+					usage.Added++
+				}
+			}
+		}
+		usage.FullIn = fullIn(usage, window)
+		report.ClusterNetworks = append(report.ClusterNetworks, usage)
+		if percent(usage.Used, usage.Capacity) >= threshold {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("cluster network %s gives %d of its %d host subnets to nodes", usage.CIDR, usage.Used, usage.Capacity))
+		}
+	}
+
+	for _, serviceNetwork := range serviceNetworks {
+		_, cidr, err := net.ParseCIDR(serviceNetwork)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("the service network %q is invalid", serviceNetwork))
+			continue
+		}
+		// the network and broadcast addresses are not given to services
+		usage := networkUsage{CIDR: cidr.String(), Capacity: subnetAddresses(cidr) - 2}
+		for _, service := range services {
+			for _, clusterIP := range service.Spec.ClusterIPs {
+				if ip := net.ParseIP(clusterIP); ip != nil && cidr.Contains(ip) {
+					usage.Used++
+					if now.Sub(service.CreationTimestamp.Time) < window {
+						usage.Added++
+					}
+				}
+			}
+		}
+		usage.FullIn = fullIn(usage, window)
+		report.ServiceNetworks = append(report.ServiceNetworks, usage)
+		if percent(usage.Used, usage.Capacity) >= threshold {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("service network %s gives %d of its %d addresses to services", usage.CIDR, usage.Used, usage.Capacity))
+		}
+	}
+	return report
+}
+
+// nodeSubnets returns the host subnets of the node given by OVN-Kubernetes, OpenShift SDN, or the node controller.
+func nodeSubnets(node *corev1.Node, sdnSubnets map[string]string) []string {
+	if annotation, ok := node.Annotations[ovnNodeSubnetsAnnotation]; ok {
+		// older versions of OVN-Kubernetes give a single subnet per network
+		subnets := map[string][]string{}
+		if err := json.Unmarshal([]byte(annotation), &subnets); err == nil {
+			return subnets["default"]
+		}
+		subnet := map[string]string{}
+		if err := json.Unmarshal([]byte(annotation), &subnet); err == nil && len(subnet["default"]) > 0 {
+			return []string{subnet["default"]}
+		}
+	}
+	if subnet, ok := sdnSubnets[node.Name]; ok {
+		return []string{subnet}
+	}
+	if len(node.Spec.PodCIDRs) > 0 {
+		return node.Spec.PodCIDRs
+	}
+	if len(node.Spec.PodCIDR) > 0 {
+		return []string{node.Spec.PodCIDR}
+	}
+	return nil
+}
+
+// subnetAddresses returns the number of addresses of the subnet, capped to the largest int64.
+func subnetAddresses(subnet *net.IPNet) int64 {
+	ones, bits := subnet.Mask.Size()
+	return powerOfTwo(bits - ones)
+}
+
+// powerOfTwo returns 2 to the power of n, capped to the largest int64.
+func powerOfTwo(n int) int64 {
+	switch {
+	case n < 0:
+		return 0
+	case n > 62:
+		return math.MaxInt64
+	}
+	return 1 << uint(n)
+}
+
+// percent returns the usage percentage, rounded down.
+func percent(used, capacity int64) int {
+	if capacity <= 0 {
+		return 100
+	}
+	return int(float64(used) * 100 / float64(capacity))
+}
+
+// fullIn returns how long until the network is full if it keeps growing as during the window, or nil if it did not
+// grow.
+func fullIn(usage networkUsage, window time.Duration) *time.Duration {
+	if usage.Added == 0 {
+		return nil
+	}
+	remaining := float64(usage.Capacity - usage.Used)
+	if remaining < 0 {
+		remaining = 0
+	}
+	d := time.Duration(math.MaxInt64)
+	if projected := remaining / float64(usage.Added) * float64(window); projected < float64(math.MaxInt64) {
+		d = time.Duration(projected)
+	}
+	return &d
+}
+
+// printIPUsage prints the tables of the network and host subnet usages.
+func printIPUsage(out io.Writer, report ipUsageReport, threshold int, window time.Duration) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	added := fmt.Sprintf("ADDED IN %s", duration.HumanDuration(window))
+	fmt.Fprintf(w, "CLUSTER NETWORK\tHOST PREFIX\tHOST SUBNETS\tUSAGE\t%s\tFULL IN\n", added)
+	for _, usage := range report.ClusterNetworks {
+		fmt.Fprintf(w, "%s\t/%d\t%d/%d\t%s\t%d\t%s\n", usage.CIDR, usage.HostPrefix, usage.Used, usage.Capacity, formatUsage(usage.Used, usage.Capacity, threshold), usage.Added, formatFullIn(usage.FullIn))
+	}
+	w.Flush()
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(w, "SERVICE NETWORK\tADDRESSES\tUSAGE\t%s\tFULL IN\n", added)
+	for _, usage := range report.ServiceNetworks {
+		fmt.Fprintf(w, "%s\t%d/%d\t%s\t%d\t%s\n", usage.CIDR, usage.Used, usage.Capacity, formatUsage(usage.Used, usage.Capacity, threshold), usage.Added, formatFullIn(usage.FullIn))
+	}
+	w.Flush()
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(w, "NODE\tHOST SUBNET\tPOD IPS\tUSAGE")
+	for _, usage := range report.Nodes {
+		fmt.Fprintf(w, "%s\t%s\t%d/%d\t%s\n", usage.Node, usage.Subnet, usage.Used, usage.Capacity, formatUsage(usage.Used, usage.Capacity, threshold))
+	}
+	w.Flush()
+}
+
+// formatUsage returns the usage percentage, marked when it is above the threshold.
+func formatUsage(used, capacity int64, threshold int) string {
+	p := percent(used, capacity)
+	if p >= threshold {
+		return fmt.Sprintf("%d%% (!)", p)
+	}
+	return fmt.Sprintf("%d%%", p)
+}
+
+// formatFullIn returns how long until a network is full, or - when it does not grow.
+func formatFullIn(d *time.Duration) string {
+	if d == nil {
+		return "-"
+	}
+	return duration.HumanDuration(*d)
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	networkv1 "github.com/openshift/api/network/v1"
+)
+
+func TestIPUsage(t *testing.T) {
+	now := time.Date(2023, 10, 15, 12, 0, 0, 0, time.UTC)
+	created := func(name string, age time.Duration) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))}
+	}
+
+	network := &configv1.Network{Status: configv1.NetworkStatus{
+		ClusterNetwork: []configv1.ClusterNetworkEntry{{CIDR: "10.128.0.0/21", HostPrefix: 28}},
+		ServiceNetwork: []string{"172.30.0.0/28"},
+	}}
+	ovnNode := corev1.Node{ObjectMeta: created("ovn", 100*24*time.Hour)}
+	ovnNode.Annotations = map[string]string{ovnNodeSubnetsAnnotation: `{"default":["10.128.0.0/28"]}`}
+	oldOVNNode := corev1.Node{ObjectMeta: created("old-ovn", 100*24*time.Hour)}
+	oldOVNNode.Annotations = map[string]string{ovnNodeSubnetsAnnotation: `{"default":"10.128.0.16/28"}`}
+	sdnNode := corev1.Node{ObjectMeta: created("sdn", 10*24*time.Hour)}
+	nodes := []corev1.Node{ovnNode, oldOVNNode, sdnNode}
+	hostSubnets := []networkv1.HostSubnet{{Host: "sdn", Subnet: "10.128.0.32/28"}}
+
+	pod := func(node, ip string, phase corev1.PodPhase, hostNetwork bool) corev1.Pod {
+		return corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: node, HostNetwork: hostNetwork},
+			Status: corev1.PodStatus{Phase: phase, PodIPs: []corev1.PodIP{{IP: ip}}},
+		}
+	}
+	var pods []corev1.Pod
+	for i := 0; i < 12; i++ {
+		pods = append(pods, pod("ovn", fmt.Sprintf("10.128.0.%d", i+1), corev1.PodRunning, false))
+	}
+	pods = append(pods,
+		pod("ovn", "192.168.0.10", corev1.PodRunning, true),
+		pod("old-ovn", "10.128.0.20", corev1.PodRunning, false),
+		pod("old-ovn", "10.128.0.21", corev1.PodSucceeded, false),
+	)
+
+	service := func(name string, age time.Duration, ips ...string) corev1.Service {
+		return corev1.Service{ObjectMeta: created(name, age), Spec: corev1.ServiceSpec{ClusterIPs: ips}}
+	}
+	services := []corev1.Service{
+		service("kubernetes", 100*24*time.Hour, "172.30.0.1"),
+		service("router", 100*24*time.Hour, "172.30.0.2"),
+		service("app", 5*24*time.Hour, "172.30.0.3"),
+		service("headless", 5*24*time.Hour, "None"),
+	}
+
+	report := ipUsage(now, network, nodes, pods, services, hostSubnets, 80, 30*24*time.Hour)
+
+	want := ipUsageReport{
+		ClusterNetworks: []networkUsage{{CIDR: "10.128.0.0/21", HostPrefix: 28, Capacity: 128, Used: 3, Added: 1, FullIn: durationPtr(125 * 30 * 24 * time.Hour)}},
+		ServiceNetworks: []networkUsage{{CIDR: "172.30.0.0/28", Capacity: 14, Used: 3, Added: 1, FullIn: durationPtr(11 * 30 * 24 * time.Hour)}},
+		Nodes: []nodeIPUsage{
+			{Node: "old-ovn", Subnet: "10.128.0.16/28", Capacity: 13, Used: 1},
+			{Node: "ovn", Subnet: "10.128.0.0/28", Capacity: 13, Used: 12},
+			{Node: "sdn", Subnet: "10.128.0.32/28", Capacity: 13},
+		},
+		Warnings: []string{"node ovn uses 12 of the 13 pod IPs of host subnet 10.128.0.0/28"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got %#v\nwant %#v", report, want)
+	}
+
+	out := &bytes.Buffer{}
+	printIPUsage(out, report, 80, 30*24*time.Hour)
+	wantOut := `CLUSTER NETWORK   HOST PREFIX   HOST SUBNETS   USAGE   ADDED IN 30d   FULL IN
+10.128.0.0/21     /28           3/128          2%      1              10y
+
+SERVICE NETWORK   ADDRESSES   USAGE   ADDED IN 30d   FULL IN
+172.30.0.0/28     3/14        21%     1              330d
+
+NODE      HOST SUBNET      POD IPS   USAGE
+old-ovn   10.128.0.16/28   1/13      7%
+ovn       10.128.0.0/28    12/13     92% (!)
+sdn       10.128.0.32/28   0/13      0%
+`
+	if out.String() != wantOut {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), wantOut)
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
@@ -0,0 +1,29 @@
+package network
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	networkLong = templates.LongDesc(`
+		Diagnose the cluster network
+
+		This command provides network diagnostics for administrators.`)
+)
+
+func NewCmdNetwork(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "network",
+		Short: "Diagnose the cluster network",
+		Long:  networkLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdCheck(f, streams))
+	return cmds
+}
@@ -0,0 +1,411 @@
+package node
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+
+	"github.com/openshift/oc/pkg/helpers/hostpod"
+	s2ifs "github.com/openshift/oc/pkg/helpers/source-to-image/fs"
+	s2itar "github.com/openshift/oc/pkg/helpers/source-to-image/tar"
+)
+
+var (
+	copyToNodeLong = templates.LongDesc(`
+		Copy files from the local machine to a node.
+
+		A privileged pod is started on the node in a temporary namespace, with the file system of
+		the node mounted at /host. The files are sent as a tar stream that is extracted on the node
+		by the tar command of the node, run with chroot, so the copied files are owned by root. The
+		pod and its namespace are removed once the copy completes or is interrupted.
+
+		DESTINATION is the path of the copy on the node. If it ends with a slash, SOURCE is copied
+		into that directory. Missing parent directories are created. Directories are only copied
+		with --recursive.
+	`)
+
+	copyToNodeExample = templates.Examples(`
+		# Copy a file to a node
+		oc adm copy-to-node worker-0 ./kubelet.conf /etc/kubernetes/kubelet.conf
+
+		# Copy a directory into /var/tmp on a node
+		oc adm copy-to-node worker-0 --recursive ./scripts /var/tmp/
+	`)
+
+	copyFromNodeLong = templates.LongDesc(`
+		Copy files from a node to the local machine.
+
+		A privileged pod is started on the node in a temporary namespace, with the file system of
+		the node mounted at /host. The files are archived by the tar command of the node, run with
+		chroot, and extracted locally. The pod and its namespace are removed once the copy completes
+		or is interrupted.
+
+		DESTINATION is the local path of the copy. If it is an existing directory or ends with a
+		path separator, SOURCE is copied into that directory. Directories are only copied with
+		--recursive.
+	`)
+
+	copyFromNodeExample = templates.Examples(`
+		# Copy the kubelet configuration of a node to the current directory
+		oc adm copy-from-node master-0 /etc/kubernetes/kubelet.conf .
+
+		# Copy the static pod manifests of a node
+		oc adm copy-from-node master-0 --recursive /etc/kubernetes/manifests ./master-0-manifests
+	`)
+)
+
+// copyProgressInterval is how often the progress of a copy is reported.
+const copyProgressInterval = 5 * time.Second
+
+// CopyOptions holds the options to copy files to or from a node.
+type CopyOptions struct {
+	// ToNode is true when the files are copied from the local machine to the node.
+	ToNode bool
+
+	NodeName    string
+	Source      string
+	Destination string
+	Recursive   bool
+	Quiet       bool
+	Image       string
+	Timeout     time.Duration
+
+	Client      kubernetes.Interface
+	ImageClient imagev1client.ImageV1Interface
+	Config      *rest.Config
+
+	genericclioptions.IOStreams
+}
+
+func NewCopyOptions(streams genericclioptions.IOStreams, toNode bool) *CopyOptions {
+	return &CopyOptions{
+		ToNode:    toNode,
+		Timeout:   5 * time.Minute,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdCopyToNode creates a command that copies local files to a node.
+func NewCmdCopyToNode(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCopyOptions(streams, true)
+	cmd := &cobra.Command{
+		Use:                   "copy-to-node NODE SOURCE DESTINATION",
+		DisableFlagsInUseLine: true,
+		Short:                 "Copy local files to a node",
+		Long:                  copyToNodeLong,
+		Example:               copyToNodeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+// NewCmdCopyFromNode creates a command that copies files of a node locally.
+func NewCmdCopyFromNode(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCopyOptions(streams, false)
+	cmd := &cobra.Command{
+		Use:                   "copy-from-node NODE SOURCE DESTINATION",
+		DisableFlagsInUseLine: true,
+		Short:                 "Copy files of a node to the local machine",
+		Long:                  copyFromNodeLong,
+		Example:               copyFromNodeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *CopyOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", o.Recursive, "Copy directories and their contents.")
+	cmd.Flags().BoolVarP(&o.Quiet, "quiet", "q", o.Quiet, "Do not report the progress of the copy.")
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "The image of the copy pod. Defaults to the openshift/tools image stream, or the RHEL support tools if it does not exist.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The time to wait for the copy pod to start.")
+}
+
+func (o *CopyOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 3 {
+		return kcmdutil.UsageErrorf(cmd, "NODE, SOURCE and DESTINATION are required")
+	}
+	o.NodeName, o.Source, o.Destination = args[0], args[1], args[2]
+
+	var err error
+	if o.Config, err = f.ToRESTConfig(); err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	if o.ImageClient, err = imagev1client.NewForConfig(o.Config); err != nil {
+		return err
+	}
+
+	if len(o.Image) == 0 {
+		o.Image = hostpod.Image(o.ImageClient)
+	}
+	return nil
+}
+
+func (o *CopyOptions) Validate() error {
+	if len(o.NodeName) == 0 || len(o.Source) == 0 || len(o.Destination) == 0 {
+		return fmt.Errorf("NODE, SOURCE and DESTINATION may not be empty")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be greater than 0")
+	}
+	remote, local := o.Source, o.Destination
+	if o.ToNode {
+		remote, local = o.Destination, o.Source
+	}
+	if !path.IsAbs(remote) {
+		return fmt.Errorf("the path on the node must be absolute: %s", remote)
+	}
+	if path.Clean(remote) == "/" {
+		return fmt.Errorf("the root directory of the node may not be copied to or from")
+	}
+	if !o.ToNode {
+		return nil
+	}
+	info, err := os.Stat(local)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() && !o.Recursive {
+		return fmt.Errorf("%s is a directory, use --recursive to copy it", local)
+	}
+	return nil
+}
+
+func (o *CopyOptions) Run() error {
+	node, err := o.Client.CoreV1().Nodes().Get(context.TODO(), o.NodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if node.Labels[corev1.LabelOSStable] == "windows" {
+		// Windows nodes don't yet support privileged containers
+		return fmt.Errorf("can't copy files of Windows nodes")
+	}
+
+	command := "oc adm copy-from-node"
+	if o.ToNode {
+		command = "oc adm copy-to-node"
+	}
+	log := o.ErrOut
+	if o.Quiet {
+		log = ioutil.Discard
+	}
+	namespace, cleanup, err := hostpod.CreateNamespace(o.Client, command, log)
+	if err != nil {
+		return err
+	}
+
+	return interrupt.New(nil, cleanup).Run(func() error {
+		pod, err := hostpod.Start(o.Client, namespace, node.Name, "copy", o.Image, o.Timeout)
+		if err != nil {
+			return err
+		}
+		if o.ToNode {
+			return o.copyToNode(pod)
+		}
+		return o.copyFromNode(pod)
+	})
+}
+
+// copyToNode streams the source as a tar archive to the tar command of the node, which extracts it in the parent
+// directory of the destination.
+func (o *CopyOptions) copyToNode(pod *corev1.Pod) error {
+	destination := o.Destination
+	if strings.HasSuffix(destination, "/") {
+		destination = path.Join(destination, filepath.Base(o.Source))
+	}
+	destination = path.Clean(destination)
+
+	progress := newCopyProgress(o.ErrOut, o.Quiet)
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(writeTar(progress.Writer(w), o.Source, path.Base(destination), progress.Files()))
+	}()
+	defer r.Close()
+
+	// the directory and the archive are passed as arguments to the script to avoid quoting them
+	command := []string{"chroot", "/host", "/bin/sh", "-c", `mkdir -p "$1" && exec tar --no-same-owner -C "$1" -xf -`, "sh", path.Dir(destination)}
+	errOut := &bytes.Buffer{}
+	err := hostpod.Exec(o.Client, o.Config, pod, command, r, ioutil.Discard, errOut)
+	if err != nil {
+		err = hostpod.RemoteError(err, errOut)
+	}
+	progress.Stop(err, fmt.Sprintf("to node/%s:%s", o.NodeName, destination))
+	return err
+}
+
+// copyFromNode extracts the tar archive of the source created by the tar command of the node in the parent
+// directory of the destination.
+func (o *CopyOptions) copyFromNode(pod *corev1.Pod) error {
+	source := path.Clean(o.Source)
+	destination := o.Destination
+	if info, err := os.Stat(destination); (err == nil && info.IsDir()) || strings.HasSuffix(destination, string(filepath.Separator)) {
+		destination = filepath.Join(destination, path.Base(source))
+	}
+	destination = filepath.Clean(destination)
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return err
+	}
+
+	progress := newCopyProgress(o.ErrOut, o.Quiet)
+
+	r, w := io.Pipe()
+	errOut := &bytes.Buffer{}
+	execErr := make(chan error, 1)
+	go func() {
+		// the paths are passed as arguments to the script to avoid quoting them
+		script := `cd "$1" || exit 1
+if [ -d "$2" ] && [ "$3" != "true" ]; then echo "$1/$2 is a directory, use --recursive to copy it" >&2; exit 1; fi
+exec tar -cf - "$2"`
+		command := []string{"chroot", "/host", "/bin/sh", "-c", script, "sh", path.Dir(source), path.Base(source), fmt.Sprintf("%t", o.Recursive)}
+		err := hostpod.Exec(o.Client, o.Config, pod, command, nil, progress.Writer(w), errOut)
+		w.CloseWithError(err)
+		execErr <- err
+	}()
+
+	err := extractTar(r, path.Base(source), destination, progress.Files())
+	r.CloseWithError(err)
+	if remoteErr := <-execErr; remoteErr != nil {
+		err = hostpod.RemoteError(remoteErr, errOut)
+	}
+	progress.Stop(err, fmt.Sprintf("from node/%s:%s", o.NodeName, source))
+	return err
+}
+
+// writeTar writes a tar archive of source, that may be a file or a directory, to w, with the name of source replaced
+// by name. The name of every file is written to files.
+func writeTar(w io.Writer, source, name string, files io.Writer) error {
+	tarHelper := s2itar.New(s2ifs.NewFileSystem())
+	tarHelper.SetExclusionPattern(nil)
+	source = filepath.Clean(source)
+	tw := tar.NewWriter(w)
+	if err := tarHelper.CreateTarStreamToTarWriter(source, true, s2itar.RenameAdapter{Writer: tw, Old: filepath.Base(source), New: name}, files); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extractTar extracts the tar archive of a file or a directory named name from r to destination. The name of every
+// file is written to files.
+func extractTar(r io.Reader, name, destination string, files io.Writer) error {
+	tarHelper := s2itar.New(s2ifs.NewFileSystem())
+	return tarHelper.ExtractTarStreamFromTarReader(filepath.Dir(destination), &renameReader{Reader: tar.NewReader(r), old: name, new: filepath.Base(destination)}, files)
+}
+
+// renameReader renames files and directories inline as a tar archive is being read.
+type renameReader struct {
+	*tar.Reader
+	old string
+	new string
+}
+
+func (r *renameReader) Next() (*tar.Header, error) {
+	hdr, err := r.Reader.Next()
+	if err != nil {
+		return hdr, err
+	}
+	if hdr.Name == r.old || strings.TrimSuffix(hdr.Name, "/") == r.old {
+		hdr.Name = r.new
+	} else if strings.HasPrefix(hdr.Name, r.old+"/") {
+		hdr.Name = r.new + hdr.Name[len(r.old):]
+	} else {
+		return nil, fmt.Errorf("unexpected file in archive: %s", hdr.Name)
+	}
+	return hdr, nil
+}
+
+// copyProgress counts the bytes and files of a copy and reports them periodically until it is stopped.
+type copyProgress struct {
+	out   io.Writer
+	start time.Time
+	done  chan struct{}
+	bytes int64
+	files int64
+}
+
+func newCopyProgress(out io.Writer, quiet bool) *copyProgress {
+	p := &copyProgress{out: out, start: time.Now(), done: make(chan struct{})}
+	if quiet {
+		p.out = ioutil.Discard
+		return p
+	}
+	go func() {
+		ticker := time.NewTicker(copyProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				fmt.Fprintf(p.out, "Copied %d files (%s)...\n", atomic.LoadInt64(&p.files), units.BytesSize(float64(atomic.LoadInt64(&p.bytes))))
+			}
+		}
+	}()
+	return p
+}
+
+// Writer counts the bytes written to w.
+func (p *copyProgress) Writer(w io.Writer) io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		n, err := w.Write(b)
+		atomic.AddInt64(&p.bytes, int64(n))
+		return n, err
+	})
+}
+
+// Files counts the lines, one per file, written to it.
+func (p *copyProgress) Files() io.Writer {
+	return writerFunc(func(b []byte) (int, error) {
+		atomic.AddInt64(&p.files, int64(bytes.Count(b, []byte("\n"))))
+		return len(b), nil
+	})
+}
+
+// Stop stops reporting the progress and prints a summary of the copy if it succeeded.
+func (p *copyProgress) Stop(err error, description string) {
+	close(p.done)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(p.out, "Copied %d files (%s) %s in %s\n", atomic.LoadInt64(&p.files), units.BytesSize(float64(atomic.LoadInt64(&p.bytes))), description, time.Since(p.start).Round(time.Second))
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (fn writerFunc) Write(b []byte) (int, error) {
+	return fn(b)
+}
@@ -0,0 +1,87 @@
+package node
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_writeTar_extractTar(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "dir", "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"file": "a", "dir/one": "b", "dir/sub/two": "c"} {
+		if err := ioutil.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   map[string]string
+		files  int
+	}{
+		{name: "file", source: "file", want: map[string]string{"": "a"}, files: 1},
+		{name: "directory", source: "dir", want: map[string]string{"one": "b", "sub/two": "c"}, files: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := &bytes.Buffer{}
+			progress := newCopyProgress(ioutil.Discard, true)
+			if err := writeTar(archive, filepath.Join(src, tt.source), "copy", progress.Files()); err != nil {
+				t.Fatalf("writeTar() error = %v", err)
+			}
+			if progress.files != int64(tt.files) {
+				t.Errorf("writeTar() counted %d files, want %d", progress.files, tt.files)
+			}
+
+			// the archive is extracted under another name, as if it was created by tar on the node
+			destination := filepath.Join(t.TempDir(), "extracted")
+			if err := extractTar(archive, "copy", destination, nil); err != nil {
+				t.Fatalf("extractTar() error = %v", err)
+			}
+			for name, content := range tt.want {
+				data, err := ioutil.ReadFile(filepath.Join(destination, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != content {
+					t.Errorf("%s = %q, want %q", name, data, content)
+				}
+			}
+		})
+	}
+}
+
+func Test_renameReader(t *testing.T) {
+	archive := &bytes.Buffer{}
+	tw := tar.NewWriter(archive)
+	for _, name := range []string{"manifests/", "manifests/etcd.yaml", "other"} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tw.Close()
+
+	r := &renameReader{Reader: tar.NewReader(archive), old: "manifests", new: "copy"}
+	var names []string
+	for {
+		hdr, err := r.Next()
+		if err != nil {
+			if !strings.Contains(err.Error(), "unexpected file in archive: other") {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+	if got := strings.Join(names, ","); got != "copy,copy/etcd.yaml" {
+		t.Errorf("renamed %s, want copy,copy/etcd.yaml", got)
+	}
+}
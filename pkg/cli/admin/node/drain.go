@@ -0,0 +1,481 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/kubernetes"
+	kcmddrain "k8s.io/kubectl/pkg/cmd/drain"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/drain"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	drainOrchestrationLong = templates.LongDesc(`
+		Several nodes are drained together when --max-parallel or --pause-between is given. All the
+		named or selected nodes are cordoned first, so that evicted pods are not scheduled on nodes
+		that are drained later, and then up to --max-parallel nodes are drained at a time. Nodes with
+		pods covered by the same PodDisruptionBudget are only drained at the same time if the budget
+		allows the disruption of the pods of all of them, so that a batch does not block on evictions
+		that the budget would refuse. With --pause-between, the drain of another node starts only once
+		that time has passed since the last node was drained. A summary of each node is printed at the
+		end; nodes that fail to drain do not stop the drain of the others.
+	`)
+
+	drainOrchestrationExample = templates.Examples(`
+		# Drain the workers of a zone, two nodes at a time
+		oc adm drain -l topology.kubernetes.io/zone=us-east-1a,node-role.kubernetes.io/worker --max-parallel=2 --ignore-daemonsets --delete-emptydir-data
+
+		# Drain nodes one at a time, waiting 5 minutes after each node for the workloads to settle
+		oc adm drain worker-0 worker-1 worker-2 --pause-between=5m --ignore-daemonsets
+	`)
+)
+
+// DrainOptions holds the options to drain several nodes together.
+type DrainOptions struct {
+	NodeNames    []string
+	Selector     string
+	MaxParallel  int
+	PauseBetween time.Duration
+
+	DryRunStrategy kcmdutil.DryRunStrategy
+
+	Client  kubernetes.Interface
+	Drainer *drain.Helper
+
+	genericclioptions.IOStreams
+}
+
+func NewDrainOptions(streams genericclioptions.IOStreams) *DrainOptions {
+	return &DrainOptions{
+		MaxParallel: 1,
+		IOStreams:   streams,
+	}
+}
+
+// NewCmdDrain extends the kubectl drain command with the orchestrated drain of several nodes.
+func NewCmdDrain(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewDrainOptions(streams)
+	cmd := kcmddrain.NewCmdDrain(f, streams)
+	cmd.Use = "drain [NODE...]"
+	cmd.Long = cmd.Long + "\n\n" + drainOrchestrationLong
+	cmd.Example = cmd.Example + "\n\n" + drainOrchestrationExample
+
+	kubectlRun := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if !cmd.Flags().Changed("max-parallel") && !cmd.Flags().Changed("pause-between") {
+			kubectlRun(cmd, args)
+			return
+		}
+		kcmdutil.CheckErr(o.Complete(f, cmd, args))
+		kcmdutil.CheckErr(o.Validate())
+		kcmdutil.CheckErr(o.Run())
+	}
+
+	cmd.Flags().IntVar(&o.MaxParallel, "max-parallel", o.MaxParallel, "The maximum number of nodes drained at the same time.")
+	cmd.Flags().DurationVar(&o.PauseBetween, "pause-between", o.PauseBetween, "The time to wait after a node was drained before the drain of another node starts.")
+
+	return cmd
+}
+
+// Complete builds the drain helper from the flags of the kubectl drain command.
+func (o *DrainOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.NodeNames = args
+	o.Selector = kcmdutil.GetFlagString(cmd, "selector")
+
+	var err error
+	if o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd); err != nil {
+		return err
+	}
+	if o.Client, err = f.KubernetesClientSet(); err != nil {
+		return err
+	}
+	dynamicClient, err := f.DynamicClient()
+	if err != nil {
+		return err
+	}
+
+	o.Drainer = &drain.Helper{
+		Ctx:                             context.TODO(),
+		Client:                          o.Client,
+		Force:                           kcmdutil.GetFlagBool(cmd, "force"),
+		GracePeriodSeconds:              kcmdutil.GetFlagInt(cmd, "grace-period"),
+		IgnoreAllDaemonSets:             kcmdutil.GetFlagBool(cmd, "ignore-daemonsets"),
+		Timeout:                         kcmdutil.GetFlagDuration(cmd, "timeout"),
+		DeleteEmptyDirData:              kcmdutil.GetFlagBool(cmd, "delete-emptydir-data"),
+		Selector:                        o.Selector,
+		PodSelector:                     kcmdutil.GetFlagString(cmd, "pod-selector"),
+		ChunkSize:                       kcmdutil.GetFlagInt64(cmd, "chunk-size"),
+		DisableEviction:                 kcmdutil.GetFlagBool(cmd, "disable-eviction"),
+		SkipWaitForDeleteTimeoutSeconds: kcmdutil.GetFlagInt(cmd, "skip-wait-for-delete-timeout"),
+		Out:                             o.Out,
+		ErrOut:                          o.ErrOut,
+		DryRunStrategy:                  o.DryRunStrategy,
+		DryRunVerifier:                  resource.NewQueryParamVerifier(dynamicClient, f.OpenAPIGetter(), resource.QueryParamDryRun),
+	}
+	return nil
+}
+
+func (o *DrainOptions) Validate() error {
+	if len(o.NodeNames) == 0 && len(o.Selector) == 0 {
+		return fmt.Errorf("at least one node name or a --selector is required")
+	}
+	if len(o.NodeNames) > 0 && len(o.Selector) > 0 {
+		return fmt.Errorf("node names and --selector may not both be specified")
+	}
+	if o.MaxParallel < 1 {
+		return fmt.Errorf("--max-parallel must be at least 1")
+	}
+	if o.PauseBetween < 0 {
+		return fmt.Errorf("--pause-between may not be negative")
+	}
+	if len(o.Drainer.PodSelector) > 0 {
+		if _, err := labels.Parse(o.Drainer.PodSelector); err != nil {
+			return errors.New("--pod-selector=<pod_selector> must be a valid label selector")
+		}
+	}
+	return nil
+}
+
+func (o *DrainOptions) Run() error {
+	nodes, err := o.nodes()
+	if err != nil {
+		return err
+	}
+
+	if o.DryRunStrategy != kcmdutil.DryRunClient {
+		for _, node := range nodes {
+			if err := o.cordon(node); err != nil {
+				return err
+			}
+		}
+	}
+
+	budgets, err := o.disruptionBudgets()
+	if err != nil {
+		return err
+	}
+
+	var drains []*nodeDrain
+	var ready []*nodeDrain
+	for _, node := range nodes {
+		d := &nodeDrain{Name: node.Name}
+		drains = append(drains, d)
+		list, errs := o.Drainer.GetPodsForDeletion(node.Name)
+		if errs != nil {
+			d.Err = utilerrors.NewAggregate(errs)
+			fmt.Fprintf(o.ErrOut, "error: unable to drain node %q: %v\n", node.Name, d.Err)
+			continue
+		}
+		if warnings := list.Warnings(); warnings != "" {
+			fmt.Fprintf(o.ErrOut, "WARNING: node %s: %s\n", node.Name, warnings)
+		}
+		d.Pods = list.Pods()
+		d.Budgets = budgets.podCounts(d.Pods)
+		ready = append(ready, d)
+	}
+
+	var outLock sync.Mutex
+	drainNodes(ready, budgets, o.MaxParallel, o.PauseBetween, func(d *nodeDrain) error {
+		return o.drain(d, &outLock)
+	})
+
+	printDrainSummary(o.Out, drains, o.DryRunStrategy == kcmdutil.DryRunClient)
+
+	var errs []error
+	for _, d := range drains {
+		if d.Err != nil {
+			errs = append(errs, fmt.Errorf("unable to drain node %q: %v", d.Name, d.Err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// nodes returns the named or selected nodes, sorted by name.
+func (o *DrainOptions) nodes() ([]*corev1.Node, error) {
+	return getNodes(o.Client, o.NodeNames, o.Selector)
+}
+
+// getNodes returns the named nodes, or the nodes matching the selector if no node is named, sorted by name.
+func getNodes(client kubernetes.Interface, names []string, selector string) ([]*corev1.Node, error) {
+	var nodes []*corev1.Node
+	if len(names) == 0 {
+		list, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, err
+		}
+		if len(list.Items) == 0 {
+			return nil, fmt.Errorf("no nodes match the selector %q", selector)
+		}
+		for i := range list.Items {
+			nodes = append(nodes, &list.Items[i])
+		}
+	} else {
+		for _, name := range sets.NewString(names...).List() {
+			node, err := client.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes, nil
+}
+
+// cordon marks the node unschedulable.
+func (o *DrainOptions) cordon(node *corev1.Node) error {
+	c := drain.NewCordonHelper(node)
+	if !c.UpdateIfRequired(true) {
+		fmt.Fprintf(o.Out, "node/%s already cordoned\n", node.Name)
+		return nil
+	}
+	err, patchErr := c.PatchOrReplace(o.Client, o.DryRunStrategy == kcmdutil.DryRunServer)
+	if patchErr != nil {
+		fmt.Fprintf(o.ErrOut, "error: unable to cordon node %q: %v\n", node.Name, patchErr)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to cordon node %q: %v", node.Name, err)
+	}
+	fmt.Fprintf(o.Out, "node/%s cordoned\n", node.Name)
+	return nil
+}
+
+// disruptionBudgets returns the PodDisruptionBudgets of the cluster.
+func (o *DrainOptions) disruptionBudgets() (*disruptionBudgets, error) {
+	list, err := o.Client.PolicyV1().PodDisruptionBudgets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the pod disruption budgets: %v", err)
+	}
+	return newDisruptionBudgets(list.Items)
+}
+
+// drain evicts or deletes the pods of the node. Since nodes are drained at the same time, the output of the drain is
+// prefixed with the name of the node.
+func (o *DrainOptions) drain(d *nodeDrain, outLock *sync.Mutex) error {
+	prefix := fmt.Sprintf("node/%s: ", d.Name)
+	out := &linePrefixWriter{out: o.Out, prefix: prefix, lock: outLock}
+	errOut := &linePrefixWriter{out: o.ErrOut, prefix: prefix, lock: outLock}
+
+	if o.DryRunStrategy == kcmdutil.DryRunClient {
+		for _, pod := range d.Pods {
+			fmt.Fprintf(out, "evicting pod %s/%s (dry run)\n", pod.Namespace, pod.Name)
+		}
+		return nil
+	}
+
+	drainer := *o.Drainer
+	drainer.Out, drainer.ErrOut = out, errOut
+	drainer.OnPodDeletedOrEvicted = func(pod *corev1.Pod, usingEviction bool) {
+		verb := "deleted"
+		if usingEviction {
+			verb = "evicted"
+		}
+		fmt.Fprintf(out, "pod/%s %s\n", pod.Name, verb)
+	}
+	// the pods are listed again, since they might have changed while other nodes were drained
+	if err := drain.RunNodeDrain(&drainer, d.Name); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "drained\n")
+	return nil
+}
+
+// linePrefixWriter prefixes every line written to out, and serializes the writes of several writers with lock.
+type linePrefixWriter struct {
+	out    io.Writer
+	prefix string
+	lock   *sync.Mutex
+}
+
+func (w *linePrefixWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for _, line := range strings.SplitAfter(string(p), "\n") {
+		if len(line) == 0 {
+			continue
+		}
+		if _, err := io.WriteString(w.out, w.prefix+line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// nodeDrain is the drain of a node.
+type nodeDrain struct {
+	Name string
+	// Pods are the pods evicted or deleted from the node.
+	Pods []corev1.Pod
+	// Budgets are the number of pods of the node covered by each PodDisruptionBudget, by namespace/name.
+	Budgets map[string]int
+
+	Duration time.Duration
+	Err      error
+	started  bool
+}
+
+// disruptionBudgets tracks the disruptions of the PodDisruptionBudgets caused by the nodes being drained.
+type disruptionBudgets struct {
+	budgets []policyv1.PodDisruptionBudget
+	// selectors are the selectors of the budgets, nil for budgets that select no pods.
+	selectors []labels.Selector
+	// inFlight is the number of pods covered by each budget on the nodes being drained.
+	inFlight map[string]int
+}
+
+func newDisruptionBudgets(budgets []policyv1.PodDisruptionBudget) (*disruptionBudgets, error) {
+	b := &disruptionBudgets{budgets: budgets, inFlight: map[string]int{}}
+	for _, budget := range budgets {
+		var selector labels.Selector
+		if budget.Spec.Selector != nil {
+			var err error
+			if selector, err = metav1.LabelSelectorAsSelector(budget.Spec.Selector); err != nil {
+				return nil, fmt.Errorf("invalid selector of the pod disruption budget %s/%s: %v", budget.Namespace, budget.Name, err)
+			}
+		}
+		b.selectors = append(b.selectors, selector)
+	}
+	return b, nil
+}
+
+// podCounts returns the number of pods covered by each budget.
+func (b *disruptionBudgets) podCounts(pods []corev1.Pod) map[string]int {
+	counts := map[string]int{}
+	for _, pod := range pods {
+		for i, budget := range b.budgets {
+			if budget.Namespace != pod.Namespace || b.selectors[i] == nil || !b.selectors[i].Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			counts[budget.Namespace+"/"+budget.Name]++
+		}
+	}
+	return counts
+}
+
+// allowed returns the number of disruptions the budget allowed when it was listed.
+func (b *disruptionBudgets) allowed(key string) int {
+	for _, budget := range b.budgets {
+		if budget.Namespace+"/"+budget.Name == key {
+			return int(budget.Status.DisruptionsAllowed)
+		}
+	}
+	return 0
+}
+
+// admits returns true if the node can be drained at the same time as the nodes being drained: either none of them
+// has pods covered by the budgets of the node, or the budgets allow the disruption of all of these pods.
+func (b *disruptionBudgets) admits(d *nodeDrain) bool {
+	for key, count := range d.Budgets {
+		if inFlight := b.inFlight[key]; inFlight > 0 && inFlight+count > b.allowed(key) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *disruptionBudgets) start(d *nodeDrain) {
+	for key, count := range d.Budgets {
+		b.inFlight[key] += count
+	}
+}
+
+func (b *disruptionBudgets) finish(d *nodeDrain) {
+	for key, count := range d.Budgets {
+		b.inFlight[key] -= count
+	}
+}
+
+// drainNodes drains up to maxParallel nodes at a time in order, skipping ahead to the next node the budgets admit.
+// A node is only started once pause has passed since the last node was drained.
+func drainNodes(nodes []*nodeDrain, budgets *disruptionBudgets, maxParallel int, pause time.Duration, drainFn func(*nodeDrain) error) {
+	done := make(chan *nodeDrain)
+	pending := append([]*nodeDrain{}, nodes...)
+	running := 0
+	var lastDrained time.Time
+
+	for len(pending) > 0 || running > 0 {
+		var wait <-chan time.Time
+		if len(pending) > 0 && running < maxParallel {
+			if remaining := time.Until(lastDrained.Add(pause)); remaining > 0 {
+				wait = time.After(remaining)
+			} else if i := nextAdmitted(pending, budgets); i >= 0 {
+				d := pending[i]
+				pending = append(pending[:i], pending[i+1:]...)
+				budgets.start(d)
+				d.started = true
+				running++
+				go func() {
+					start := time.Now()
+					d.Err = drainFn(d)
+					d.Duration = time.Since(start)
+					done <- d
+				}()
+				continue
+			}
+		}
+
+		select {
+		case d := <-done:
+			running--
+			budgets.finish(d)
+			lastDrained = time.Now()
+		case <-wait:
+		}
+	}
+}
+
+// nextAdmitted returns the index of the first pending node the budgets admit, or -1.
+func nextAdmitted(pending []*nodeDrain, budgets *disruptionBudgets) int {
+	for i, d := range pending {
+		if budgets.admits(d) {
+			return i
+		}
+	}
+	return -1
+}
+
+// printDrainSummary prints the result of the drain of each node.
+func printDrainSummary(out io.Writer, drains []*nodeDrain, dryRun bool) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "NODE\tSTATUS\tPODS\tDURATION\tERROR")
+	for _, d := range drains {
+		status := "Drained"
+		switch {
+		case d.Err != nil && !d.started:
+			status = "NotDrained"
+		case d.Err != nil:
+			status = "Failed"
+		case dryRun:
+			status = "Drained (dry run)"
+		}
+		message := ""
+		if d.Err != nil {
+			message = strings.SplitN(d.Err.Error(), "\n", 2)[0]
+		}
+		duration := ""
+		if d.started {
+			duration = d.Duration.Round(time.Second).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", d.Name, status, len(d.Pods), duration, message)
+	}
+}
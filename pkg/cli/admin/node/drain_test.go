@@ -0,0 +1,199 @@
+package node
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testBudget(namespace, name string, allowed int32, selector *metav1.LabelSelector) policyv1.PodDisruptionBudget {
+	return policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       policyv1.PodDisruptionBudgetSpec{Selector: selector},
+		Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+	}
+}
+
+func testPod(namespace, app string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Labels: map[string]string{"app": app}}}
+}
+
+func Test_disruptionBudgets_podCounts(t *testing.T) {
+	budgets, err := newDisruptionBudgets([]policyv1.PodDisruptionBudget{
+		testBudget("a", "web", 1, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}),
+		testBudget("a", "all", 1, &metav1.LabelSelector{}),
+		testBudget("a", "none", 1, nil),
+		testBudget("b", "web", 1, &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := budgets.podCounts([]corev1.Pod{testPod("a", "web"), testPod("a", "web"), testPod("a", "db"), testPod("c", "web")})
+	want := map[string]int{"a/web": 2, "a/all": 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("podCounts() = %v, want %v", got, want)
+	}
+}
+
+func Test_disruptionBudgets_admits(t *testing.T) {
+	budgets, err := newDisruptionBudgets([]policyv1.PodDisruptionBudget{
+		testBudget("a", "web", 2, nil),
+		testBudget("a", "db", 0, nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := &nodeDrain{Name: "web", Budgets: map[string]int{"a/web": 1}}
+	db := &nodeDrain{Name: "db", Budgets: map[string]int{"a/db": 1}}
+	none := &nodeDrain{Name: "none"}
+
+	// a node is always admitted alone, even if its budget allows no disruption
+	for _, d := range []*nodeDrain{web, db, none} {
+		if !budgets.admits(d) {
+			t.Errorf("%s is not admitted alone", d.Name)
+		}
+	}
+
+	budgets.start(web)
+	budgets.start(db)
+	if !budgets.admits(web) {
+		t.Errorf("web is not admitted, but the budget allows two disruptions")
+	}
+	if budgets.admits(db) {
+		t.Errorf("db is admitted, but the budget allows no disruption")
+	}
+	if !budgets.admits(none) {
+		t.Errorf("a node without budgets is not admitted")
+	}
+	budgets.start(web)
+	if budgets.admits(web) {
+		t.Errorf("web is admitted, but the budget allows only two disruptions")
+	}
+
+	budgets.finish(db)
+	if !budgets.admits(db) {
+		t.Errorf("db is not admitted once the other node was drained")
+	}
+}
+
+func Test_drainNodes(t *testing.T) {
+	budgets, err := newDisruptionBudgets([]policyv1.PodDisruptionBudget{testBudget("a", "db", 0, nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []*nodeDrain{
+		{Name: "node-0", Budgets: map[string]int{"a/db": 1}},
+		{Name: "node-1", Budgets: map[string]int{"a/db": 1}},
+		{Name: "node-2"},
+		{Name: "node-3"},
+		{Name: "node-4"},
+	}
+
+	var lock sync.Mutex
+	running, maxRunning := map[string]bool{}, 0
+	var order []string
+	drainNodes(nodes, budgets, 2, 0, func(d *nodeDrain) error {
+		lock.Lock()
+		running[d.Name] = true
+		if len(running) > maxRunning {
+			maxRunning = len(running)
+		}
+		if running["node-0"] && running["node-1"] {
+			t.Errorf("node-0 and node-1 are drained at the same time, but their budget allows no disruption")
+		}
+		order = append(order, d.Name)
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		delete(running, d.Name)
+		lock.Unlock()
+		if d.Name == "node-3" {
+			return errors.New("eviction failed")
+		}
+		return nil
+	})
+
+	if maxRunning != 2 {
+		t.Errorf("at most %d nodes were drained at the same time, expected 2", maxRunning)
+	}
+	if len(order) != len(nodes) {
+		t.Errorf("drained %v, expected all nodes", order)
+	}
+	for _, d := range nodes {
+		if !d.started || d.Duration == 0 {
+			t.Errorf("%s was not drained", d.Name)
+		}
+		if (d.Err != nil) != (d.Name == "node-3") {
+			t.Errorf("unexpected error of %s: %v", d.Name, d.Err)
+		}
+	}
+}
+
+func Test_drainNodes_pause(t *testing.T) {
+	budgets, err := newDisruptionBudgets(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []*nodeDrain{{Name: "node-0"}, {Name: "node-1"}, {Name: "node-2"}}
+
+	var finished time.Time
+	var gaps []time.Duration
+	start := time.Now()
+	drainNodes(nodes, budgets, 1, 50*time.Millisecond, func(d *nodeDrain) error {
+		if !finished.IsZero() {
+			gaps = append(gaps, time.Since(finished))
+		}
+		finished = time.Now()
+		return nil
+	})
+
+	if len(gaps) != 2 {
+		t.Fatalf("expected 2 pauses, got %v", gaps)
+	}
+	for _, gap := range gaps {
+		if gap < 50*time.Millisecond {
+			t.Errorf("a node was drained %s after the previous one, expected at least 50ms", gap)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("the drain took %s, expected at least 100ms", elapsed)
+	}
+}
+
+func Test_printDrainSummary(t *testing.T) {
+	pods := []corev1.Pod{testPod("a", "web"), testPod("a", "db")}
+	drains := []*nodeDrain{
+		{Name: "node-0", Pods: pods, Duration: 61 * time.Second, started: true},
+		{Name: "node-1", Pods: pods[:1], Duration: 5 * time.Minute, started: true, Err: errors.New("global timeout reached: 5m0s\nmore")},
+		{Name: "node-2", Err: errors.New("cannot delete DaemonSet-managed Pods")},
+	}
+	out := &bytes.Buffer{}
+	printDrainSummary(out, drains, false)
+	want := "NODE     STATUS       PODS   DURATION   ERROR\n" +
+		"node-0   Drained      2      1m1s       \n" +
+		"node-1   Failed       1      5m0s       global timeout reached: 5m0s\n" +
+		"node-2   NotDrained   0                 cannot delete DaemonSet-managed Pods\n"
+	if out.String() != want {
+		t.Errorf("unexpected summary:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func Test_linePrefixWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := &linePrefixWriter{out: out, prefix: "node/a: ", lock: &sync.Mutex{}}
+	w.Write([]byte("evicting pod a/b\nevicting pod a/c\n"))
+	w.Write([]byte("drained\n"))
+	want := "node/a: evicting pod a/b\nnode/a: evicting pod a/c\nnode/a: drained\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,180 @@
+package node
+
+import (
+	"bufio"
+	"container/ring"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// followInterval is how often the journal of a node is queried again when following it.
+const followInterval = 2 * time.Second
+
+// lineWriter writes whole lines from several goroutines, flushing after each line so that followed logs appear
+// as soon as they are read.
+type lineWriter struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+func (w *lineWriter) WriteLine(prefix []byte, line string) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, err := w.out.Write(prefix); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w.out, line); err != nil {
+		return err
+	}
+	if f, ok := w.out.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// follow streams the journal of the node and then queries it again every interval for the entries written since the
+// previous query, until the context is done. The kubelet journal endpoint does not stream, and only supports
+// selecting entries with a resolution of a second, so every query overlaps the previous one by a second and the
+// lines already returned by the previous query are skipped. Those are the lines at the start of the query that
+// repeat the end of the previous one, so that identical entries logged again are still written.
+func (req *logRequest) follow(ctx context.Context, out *lineWriter, interval time.Duration) error {
+	if req.err != nil {
+		return req.err
+	}
+	var prefix []byte
+	if !req.skipPrefix {
+		prefix = []byte(fmt.Sprintf("%s ", req.node))
+	}
+
+	r := req.req
+	var previous []string
+	for first := true; ; first = false {
+		last := time.Now()
+		var lines []string
+		err := streamLines(ctx, r, func(line string) error {
+			lines = append(lines, line)
+			if first {
+				return out.WriteLine(prefix, line)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !first {
+			for _, line := range lines[overlap(previous, lines):] {
+				if err := out.WriteLine(prefix, line); err != nil {
+					return err
+				}
+			}
+		}
+		previous = lines
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+		since := int(math.Ceil((time.Since(last) + time.Second).Seconds()))
+		r = req.poll(fmt.Sprintf("-%ds", since))
+	}
+}
+
+// overlap returns the number of lines at the start of next that repeat the end of previous.
+func overlap(previous, next []string) int {
+	n := len(next)
+	if len(previous) < n {
+		n = len(previous)
+	}
+	for ; n > 0; n-- {
+		if equalLines(previous[len(previous)-n:], next[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// streamLines calls fn with every line of the optionally compressed response to r.
+func streamLines(ctx context.Context, r *rest.Request, fn func(line string) error) error {
+	in, err := r.Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(optionallyDecompress(pw, in))
+	}()
+	defer pr.Close()
+
+	s := bufio.NewScanner(pr)
+	s.Buffer(make([]byte, 4096), 1024*1024)
+	for s.Scan() {
+		if err := fn(s.Text()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// lastLines returns a reader of the last n lines of in.
+func lastLines(in io.Reader, n int) (io.Reader, error) {
+	lines := ring.New(n)
+	s := bufio.NewScanner(in)
+	s.Buffer(make([]byte, 4096), 1024*1024)
+	for s.Scan() {
+		lines.Value = s.Text()
+		lines = lines.Next()
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	lines.Do(func(line interface{}) {
+		if line != nil {
+			b.WriteString(line.(string))
+			b.WriteString("\n")
+		}
+	})
+	return strings.NewReader(b.String()), nil
+}
+
+// followRequests follows the journal of every request concurrently until every request stopped, and returns their
+// errors prefixed with the node.
+func followRequests(requests []*logRequest, out *lineWriter, skipPrefix bool) []error {
+	var lock sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for i := range requests {
+		req := requests[i]
+		req.skipPrefix = skipPrefix
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := req.follow(context.TODO(), out, followInterval); err != nil {
+				lock.Lock()
+				defer lock.Unlock()
+				errs = append(errs, fmt.Errorf("%s: %v", req.node, err))
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
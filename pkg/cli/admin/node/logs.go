@@ -38,6 +38,10 @@ var (
 		to see a list of log files available under /var/logs/ and view those contents
 		directly.
 
+		Use --follow to keep printing new journal entries as they are written. When logs of several
+		nodes are displayed, for example with a label selector, they are retrieved concurrently and
+		each line is prefixed with the name of its node.
+
 		Node logs may contain sensitive output and so are limited to privileged node
 		administrators. The system:node-admins role grants this permission by default.
 		You check who has that permission via:
@@ -54,6 +58,9 @@ var (
 
 		# Display cron log file from all masters
 		oc adm node-logs --role master --path=cron
+
+		# Follow the crio logs of the workers of a zone, starting with the last 20 entries of each
+		oc adm node-logs -l topology.kubernetes.io/zone=us-east-1a -u crio --tail=20 -f
 	`)
 )
 
@@ -76,6 +83,7 @@ type LogsOptions struct {
 	UntilTime         string
 	Tail              int
 	Output            string
+	Follow            bool
 
 	// output format arguments
 	Raw   bool
@@ -121,7 +129,8 @@ func NewCmdLogs(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.
 	cmd.Flags().StringVar(&o.UntilTime, "until", o.UntilTime, "Return logs before a specific ISO timestamp or relative date. Only applies to node journal logs.")
 	cmd.Flags().IntVar(&o.Boot, "boot", o.Boot, " Show messages from a specific boot. Use negative numbers, allowed [-100, 0], passing invalid boot offset will fail retrieving logs. Only applies to node journal logs.")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Display journal logs in an alternate format (short, cat, json, short-unix). Only applies to node journal logs.")
-	cmd.Flags().IntVar(&o.Tail, "tail", o.Tail, "Return up to this many lines (not more than 100k) from the end of the log. Only applies to node journal logs.")
+	cmd.Flags().IntVar(&o.Tail, "tail", o.Tail, "Return up to this many lines (not more than 100k) from the end of the log.")
+	cmd.Flags().BoolVarP(&o.Follow, "follow", "f", o.Follow, "Keep printing new entries as they are written. Only applies to node journal logs.")
 
 	cmd.Flags().StringVar(&o.Role, "role", o.Role, "Set a label selector by node role.")
 	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on.")
@@ -133,7 +142,7 @@ func NewCmdLogs(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.
 
 func (o *LogsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
 	if !cmd.Flags().Lookup("unify").Changed {
-		o.Unify = o.Path == "journal"
+		o.Unify = o.Path == "journal" && !o.Follow
 	}
 
 	o.Resources = args
@@ -173,6 +182,17 @@ func (o LogsOptions) Validate() error {
 	if o.BootChanaged && (o.Boot < -100 || o.Boot > 0) {
 		return fmt.Errorf("--boot accepts values [-100, 0]")
 	}
+	if o.Follow {
+		if o.Path != "journal" {
+			return fmt.Errorf("--follow only applies to node journal logs")
+		}
+		if o.Unify {
+			return fmt.Errorf("--follow and --unify may not both be specified")
+		}
+		if len(o.UntilTime) > 0 {
+			return fmt.Errorf("--follow and --until may not both be specified")
+		}
+	}
 	return nil
 }
 
@@ -190,6 +210,10 @@ type logRequest struct {
 	// skipPrefix bypasses prefixing if the user knows that a unique identifier is already
 	// in the file
 	skipPrefix bool
+	// tail is the number of lines to display from the end of a file that is not a journal
+	tail int
+	// poll returns a request for the journal entries since a relative time, like -5s, to follow the journal
+	poll func(since string) *rest.Request
 }
 
 // WriteTo prefixes the error message with the current node if necessary
@@ -226,7 +250,19 @@ func (req *logRequest) writeTo(out io.Writer) error {
 		prefix = []byte(fmt.Sprintf("%s ", req.node))
 	}
 
-	return outputDirectoryEntriesOrContent(out, in, prefix)
+	// directory listings are never truncated
+	var r io.Reader = in
+	if req.tail > 0 {
+		buf := bufio.NewReader(in)
+		r = buf
+		if head, _ := buf.Peek(len("<pre>")); !bytes.Equal(head, []byte("<pre>")) {
+			if r, err = lastLines(buf, req.tail); err != nil {
+				return err
+			}
+		}
+	}
+
+	return outputDirectoryEntriesOrContent(out, r, prefix)
 }
 
 // RunLogs retrieves node logs
@@ -255,15 +291,18 @@ func (o LogsOptions) RunLogs() error {
 			path += "/"
 		}
 
-		req := client.Get().RequestURI(path).
-			SetHeader("Accept", "text/plain, */*").
-			SetHeader("Accept-Encoding", "gzip")
-		if o.Path == "journal" {
+		newRequest := func(since string, tail bool) *rest.Request {
+			req := client.Get().RequestURI(path).
+				SetHeader("Accept", "text/plain, */*").
+				SetHeader("Accept-Encoding", "gzip")
+			if o.Path != "journal" {
+				return req
+			}
 			if len(o.UntilTime) > 0 {
 				req.Param("until", o.UntilTime)
 			}
-			if len(o.SinceTime) > 0 {
-				req.Param("since", o.SinceTime)
+			if len(since) > 0 {
+				req.Param("since", since)
 			}
 			if len(o.Output) > 0 {
 				req.Param("output", o.Output)
@@ -280,16 +319,24 @@ func (o LogsOptions) RunLogs() error {
 				req.Param("grep", o.Grep)
 				req.Param("case-sensitive", fmt.Sprintf("%t", o.GrepCaseSensitive))
 			}
-			if o.Tail > 0 {
+			if tail && o.Tail > 0 {
 				req.Param("tail", strconv.Itoa(o.Tail))
 			}
+			return req
 		}
 
-		requests = append(requests, &logRequest{
+		request := &logRequest{
 			node: info.Name,
-			req:  req,
+			req:  newRequest(o.SinceTime, true),
 			raw:  o.Raw || o.Path == "journal",
-		})
+			poll: func(since string) *rest.Request {
+				return newRequest(since, false)
+			},
+		}
+		if o.Path != "journal" {
+			request.tail = o.Tail
+		}
+		requests = append(requests, request)
 		return nil
 	})
 	if err != nil {
@@ -308,7 +355,11 @@ func (o LogsOptions) RunLogs() error {
 	out := bufio.NewWriterSize(o.Out, 1024*16)
 	defer out.Flush()
 
-	if o.Unify {
+	if o.Follow {
+		// followed logs are displayed line by line as they arrive from each node
+		errs = append(errs, followRequests(requests, &lineWriter{out: out}, skipPrefix)...)
+
+	} else if o.Unify {
 		// unified output is each source, interleaved in lexographic order (assumes
 		// the source input is sorted by time)
 		var readers []Reader
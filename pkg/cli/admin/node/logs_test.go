@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"io"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -76,6 +77,57 @@ func Test_outputDirectoryEntriesOrContent(t *testing.T) {
 	}
 }
 
+func Test_lastLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		n       int
+		wantOut string
+	}{
+		{name: "empty", in: "", n: 2, wantOut: ""},
+		{name: "fewer lines", in: "a\nb\n", n: 3, wantOut: "a\nb\n"},
+		{name: "more lines", in: "a\nb\nc\nd\n", n: 2, wantOut: "c\nd\n"},
+		{name: "no trailing newline", in: "a\nb\nc", n: 2, wantOut: "b\nc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := lastLines(bytes.NewBufferString(tt.in), tt.n)
+			if err != nil {
+				t.Fatalf("lastLines() error = %v", err)
+			}
+			out := &bytes.Buffer{}
+			if _, err := io.Copy(out, r); err != nil {
+				t.Fatal(err)
+			}
+			if gotOut := out.String(); gotOut != tt.wantOut {
+				t.Errorf("lastLines() = %q, want %q", gotOut, tt.wantOut)
+			}
+		})
+	}
+}
+
+func Test_lineWriter_WriteLine(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := &lineWriter{out: out}
+	var wg sync.WaitGroup
+	for _, prefix := range []string{"a ", "b "} {
+		prefix := prefix
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				w.WriteLine([]byte(prefix), "line")
+			}
+		}()
+	}
+	wg.Wait()
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		if line != "a line" && line != "b line" {
+			t.Fatalf("unexpected interleaved line %q", line)
+		}
+	}
+}
+
 func Test_mergeReader_WriteTo(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -123,3 +175,26 @@ func readers(all ...string) []Reader {
 	}
 	return out
 }
+
+func Test_overlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []string
+		next     []string
+		want     int
+	}{
+		{name: "first query", next: []string{"a"}, want: 0},
+		{name: "nothing new", previous: []string{"a", "b"}, next: []string{"b"}, want: 1},
+		{name: "new lines", previous: []string{"a", "b"}, next: []string{"b", "c"}, want: 1},
+		{name: "no overlap", previous: []string{"a", "b"}, next: []string{"c", "d"}, want: 0},
+		{name: "repeated line logged again", previous: []string{"a", "b"}, next: []string{"b", "b"}, want: 1},
+		{name: "repeated lines", previous: []string{"a", "b", "b"}, next: []string{"b", "b", "b", "c"}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overlap(tt.previous, tt.next); got != tt.want {
+				t.Errorf("overlap() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+
+	"github.com/openshift/oc/pkg/helpers/conditions"
+	"github.com/openshift/oc/pkg/helpers/hostpod"
+	"github.com/openshift/oc/pkg/helpers/term"
+)
+
+var (
+	restartServiceLong = templates.LongDesc(`
+		Restart %[1]s on nodes.
+
+		The nodes are given by name or selected with --selector, and restarted one at a time. A
+		privileged pod is started on each node in a temporary namespace and restarts the %[1]s
+		systemd unit of the node with chroot. The next node is only restarted once %[1]s was
+		restarted, is active again, and the node is Ready. If a node does not become healthy within
+		--timeout, the remaining nodes are not restarted.
+
+		You are asked to confirm the restart of each node, unless --yes is given.
+	`)
+
+	restartServiceExample = templates.Examples(`
+		# Restart %[1]s on a node
+		oc adm restart-%[1]s worker-0
+
+		# Restart %[1]s on every worker, one at a time, without asking for confirmation
+		oc adm restart-%[1]s -l node-role.kubernetes.io/worker --yes
+	`)
+)
+
+// RestartServiceOptions holds the options to restart a systemd service of nodes.
+type RestartServiceOptions struct {
+	// Service is the systemd unit restarted on the nodes.
+	Service string
+
+	NodeNames []string
+	Selector  string
+	Yes       bool
+	Image     string
+	Timeout   time.Duration
+
+	Client      kubernetes.Interface
+	ImageClient imagev1client.ImageV1Interface
+	Config      *rest.Config
+
+	genericclioptions.IOStreams
+}
+
+func NewRestartServiceOptions(streams genericclioptions.IOStreams, service string) *RestartServiceOptions {
+	return &RestartServiceOptions{
+		Service:   service,
+		Timeout:   10 * time.Minute,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdRestartKubelet creates a command that restarts the kubelet of nodes.
+func NewCmdRestartKubelet(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	return newCmdRestartService(f, streams, "kubelet")
+}
+
+// NewCmdRestartCrio creates a command that restarts the container runtime of nodes.
+func NewCmdRestartCrio(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	return newCmdRestartService(f, streams, "crio")
+}
+
+func newCmdRestartService(f kcmdutil.Factory, streams genericclioptions.IOStreams, service string) *cobra.Command {
+	o := NewRestartServiceOptions(streams, service)
+	cmd := &cobra.Command{
+		Use:     fmt.Sprintf("restart-%s [NODE...] [-l SELECTOR]", service),
+		Short:   fmt.Sprintf("Restart %s on nodes, one at a time", service),
+		Long:    fmt.Sprintf(restartServiceLong, service),
+		Example: fmt.Sprintf(restartServiceExample, service),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) of the nodes to restart.")
+	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", o.Yes, fmt.Sprintf("Restart %s on every node without asking for confirmation.", service))
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "The image of the pod run on each node. Defaults to the openshift/tools image stream, or the RHEL support tools if it does not exist.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, fmt.Sprintf("The time to wait for each node to be healthy after %s was restarted.", service))
+
+	return cmd
+}
+
+func (o *RestartServiceOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.NodeNames = args
+
+	var err error
+	if o.Config, err = f.ToRESTConfig(); err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	if o.ImageClient, err = imagev1client.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	if len(o.Image) == 0 {
+		o.Image = hostpod.Image(o.ImageClient)
+	}
+	return nil
+}
+
+func (o *RestartServiceOptions) Validate() error {
+	if len(o.NodeNames) == 0 && len(o.Selector) == 0 {
+		return fmt.Errorf("at least one node name or a --selector is required")
+	}
+	if len(o.NodeNames) > 0 && len(o.Selector) > 0 {
+		return fmt.Errorf("node names and --selector may not both be specified")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be greater than 0")
+	}
+	return nil
+}
+
+func (o *RestartServiceOptions) Run() error {
+	nodes, err := o.nodes()
+	if err != nil {
+		return err
+	}
+
+	namespace, cleanup, err := hostpod.CreateNamespace(o.Client, fmt.Sprintf("oc adm restart-%s", o.Service), o.ErrOut)
+	if err != nil {
+		return err
+	}
+
+	return interrupt.New(nil, cleanup).Run(func() error {
+		for i, node := range nodes {
+			if !o.Yes {
+				answer := strings.ToLower(term.PromptForString(o.In, o.Out, "Restart %s on node %s? (y/N): ", o.Service, node.Name))
+				if answer != "y" && answer != "yes" {
+					fmt.Fprintf(o.Out, "Skipped node %s\n", node.Name)
+					continue
+				}
+			}
+			if err := o.restart(namespace, node); err != nil {
+				var remaining []string
+				for _, node := range nodes[i+1:] {
+					remaining = append(remaining, node.Name)
+				}
+				if len(remaining) > 0 {
+					return fmt.Errorf("unable to restart %s on node %s: %v\nThe remaining nodes were not restarted: %s", o.Service, node.Name, err, strings.Join(remaining, ", "))
+				}
+				return fmt.Errorf("unable to restart %s on node %s: %v", o.Service, node.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// nodes returns the named or selected nodes, sorted by name.
+func (o *RestartServiceOptions) nodes() ([]*corev1.Node, error) {
+	nodes, err := getNodes(o.Client, o.NodeNames, o.Selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		if node.Labels[corev1.LabelOSStable] == "windows" {
+			// Windows nodes don't yet support privileged containers
+			return nil, fmt.Errorf("can't restart %s on the Windows node %s", o.Service, node.Name)
+		}
+	}
+	return nodes, nil
+}
+
+// restart restarts the service on the node through a host pod, and waits for the service to be active again and
+// the node to be Ready.
+func (o *RestartServiceOptions) restart(namespace string, node *corev1.Node) error {
+	pod, err := hostpod.Start(o.Client, namespace, node.Name, "restart", o.Image, o.Timeout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := o.Client.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{}); err != nil {
+			klog.V(2).Infof("Unable to delete pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}()
+
+	before, err := o.unitState(pod)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "Restarting %s on node %s...\n", o.Service, node.Name)
+	// the restart is queued without waiting for it, since the command is executed through the kubelet and crio
+	errOut := &bytes.Buffer{}
+	if err := hostpod.Exec(o.Client, o.Config, pod, []string{"chroot", "/host", "systemctl", "restart", "--no-block", o.Service}, nil, &bytes.Buffer{}, errOut); err != nil {
+		return hostpod.RemoteError(err, errOut)
+	}
+
+	err = wait.PollImmediate(5*time.Second, o.Timeout, func() (bool, error) {
+		// commands fail while the service is restarting, they are retried until the timeout
+		state, err := o.unitState(pod)
+		if err != nil {
+			klog.V(4).Infof("Unable to get the state of %s on node %s: %v", o.Service, node.Name, err)
+			return false, nil
+		}
+		if !state.restartedSince(before) {
+			return false, nil
+		}
+		switch state.ActiveState {
+		case "active":
+		case "failed":
+			return false, fmt.Errorf("%s failed after it was restarted", o.Service)
+		default:
+			return false, nil
+		}
+
+		current, err := o.Client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+		if err != nil {
+			klog.V(4).Infof("Unable to get node %s: %v", node.Name, err)
+			return false, nil
+		}
+		return conditions.NodeReady(current), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("%s was restarted but the node is not healthy after %s", o.Service, o.Timeout)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Restarted %s on node %s, the node is Ready\n", o.Service, node.Name)
+	return nil
+}
+
+// unitState is the state of a systemd unit.
+type unitState struct {
+	ActiveState string
+	// ActiveEnterTimestampMonotonic is when the unit last became active, which changes when it is restarted.
+	ActiveEnterTimestampMonotonic string
+}
+
+// restartedSince returns true if the unit became active again since the previous state.
+func (s unitState) restartedSince(previous unitState) bool {
+	return len(s.ActiveEnterTimestampMonotonic) > 0 && s.ActiveEnterTimestampMonotonic != "0" && s.ActiveEnterTimestampMonotonic != previous.ActiveEnterTimestampMonotonic
+}
+
+// unitState returns the state of the service on the node of the pod.
+func (o *RestartServiceOptions) unitState(pod *corev1.Pod) (unitState, error) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := hostpod.Exec(o.Client, o.Config, pod, []string{"chroot", "/host", "systemctl", "show", "--property=ActiveState,ActiveEnterTimestampMonotonic", o.Service}, nil, out, errOut); err != nil {
+		return unitState{}, hostpod.RemoteError(err, errOut)
+	}
+	return parseUnitState(out.String()), nil
+}
+
+// parseUnitState parses the properties printed by systemctl show.
+func parseUnitState(out string) unitState {
+	var state unitState
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "ActiveState":
+			state.ActiveState = parts[1]
+		case "ActiveEnterTimestampMonotonic":
+			state.ActiveEnterTimestampMonotonic = parts[1]
+		}
+	}
+	return state
+}
@@ -0,0 +1,52 @@
+package node
+
+import (
+	"testing"
+)
+
+func Test_parseUnitState(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want unitState
+	}{
+		{name: "empty", out: "", want: unitState{}},
+		{
+			name: "active",
+			out:  "ActiveState=active\nActiveEnterTimestampMonotonic=1234567\n",
+			want: unitState{ActiveState: "active", ActiveEnterTimestampMonotonic: "1234567"},
+		},
+		{
+			name: "unknown properties",
+			out:  "Id=kubelet.service\r\nActiveState=activating\r\n",
+			want: unitState{ActiveState: "activating"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseUnitState(tt.out); got != tt.want {
+				t.Errorf("parseUnitState() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_unitState_restartedSince(t *testing.T) {
+	before := unitState{ActiveState: "active", ActiveEnterTimestampMonotonic: "100"}
+	tests := []struct {
+		name  string
+		state unitState
+		want  bool
+	}{
+		{name: "not restarted", state: unitState{ActiveState: "active", ActiveEnterTimestampMonotonic: "100"}},
+		{name: "stopped", state: unitState{ActiveState: "inactive", ActiveEnterTimestampMonotonic: "0"}},
+		{name: "restarted", state: unitState{ActiveState: "active", ActiveEnterTimestampMonotonic: "200"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.restartedSince(before); got != tt.want {
+				t.Errorf("restartedSince() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/client-go/util/retry"
+	kcmdtaint "k8s.io/kubectl/pkg/cmd/taint"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	taintProfilesLong = templates.LongDesc(`
+		Named sets of taints, called profiles, can be applied to or removed from nodes with the
+		apply and remove subcommands. The profiles are defined in a file, see "oc adm taint apply -h".
+	`)
+
+	taintProfilesExample = templates.Examples(`
+		# Apply the taints of the maintenance profile to the nodes of zone a
+		oc adm taint apply maintenance --selector topology.kubernetes.io/zone=a
+	`)
+
+	taintApplyLong = templates.LongDesc(`
+		Apply the taints of a profile to nodes.
+
+		A profile is a named set of taints defined in a profiles file, which defaults to
+		$HOME/.kube/taint-profiles.yaml, for example:
+
+		    profiles:
+		      maintenance:
+		      - key: example.com/maintenance
+		        value: "true"
+		        effect: NoSchedule
+		      - key: example.com/maintenance
+		        value: "true"
+		        effect: NoExecute
+
+		A taint of the profile is added to a node that does not have a taint with the same key and
+		effect, and replaces the value of the taint with the same key and effect otherwise. The other
+		taints of the node are kept. The changes made to each node are printed, prefixed with "+" for
+		an added taint and "~" for a changed one; use --dry-run to only print them.
+	`)
+
+	taintApplyExample = templates.Examples(`
+		# Apply the taints of the maintenance profile to the nodes of zone a
+		oc adm taint apply maintenance --selector topology.kubernetes.io/zone=a
+
+		# Print the changes the gpu profile would make to a node
+		oc adm taint apply gpu worker-3 --dry-run=client
+
+		# Apply a profile defined in another profiles file to all nodes
+		oc adm taint apply maintenance --all --profiles=taint-profiles.yaml
+	`)
+
+	taintRemoveLong = templates.LongDesc(`
+		Remove the taints of a profile from nodes.
+
+		The taints of a node that have the same key and effect as a taint of the profile are removed,
+		whatever their value. The profiles file is described in "oc adm taint apply -h". The removed
+		taints of each node are printed, prefixed with "-"; use --dry-run to only print them.
+	`)
+
+	taintRemoveExample = templates.Examples(`
+		# Remove the taints of the maintenance profile from the nodes of zone a
+		oc adm taint remove maintenance --selector topology.kubernetes.io/zone=a
+
+		# Print the taints of the maintenance profile that would be removed from all nodes
+		oc adm taint remove maintenance --all --dry-run=client
+	`)
+)
+
+// NewCmdTaint extends the kubectl taint command with the apply and remove of taint profiles.
+func NewCmdTaint(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := kcmdtaint.NewCmdTaint(f, streams)
+	cmd.Long = cmd.Long + "\n\n" + taintProfilesLong
+	cmd.Example = cmd.Example + "\n\n" + taintProfilesExample
+	cmd.AddCommand(NewCmdTaintApply(f, streams))
+	cmd.AddCommand(NewCmdTaintRemove(f, streams))
+	return cmd
+}
+
+// taintProfiles is the content of a taint profiles file.
+type taintProfiles struct {
+	Profiles map[string][]corev1.Taint `json:"profiles"`
+}
+
+// TaintProfileOptions holds the options to apply or remove the taints of a profile.
+type TaintProfileOptions struct {
+	Remove       bool
+	ProfilesFile string
+	Profile      string
+	NodeNames    []string
+	Selector     string
+	All          bool
+
+	// Taints are the taints of the profile.
+	Taints []corev1.Taint
+
+	DryRunStrategy kcmdutil.DryRunStrategy
+
+	Client kubernetes.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewTaintProfileOptions(streams genericclioptions.IOStreams, remove bool) *TaintProfileOptions {
+	return &TaintProfileOptions{
+		Remove:       remove,
+		ProfilesFile: filepath.Join(homedir.HomeDir(), ".kube", "taint-profiles.yaml"),
+		IOStreams:    streams,
+	}
+}
+
+// NewCmdTaintApply creates a command that applies the taints of a profile to nodes.
+func NewCmdTaintApply(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewTaintProfileOptions(streams, false)
+	cmd := &cobra.Command{
+		Use:     "apply PROFILE (NODE... | --selector=SELECTOR | --all)",
+		Short:   "Apply the taints of a profile to nodes",
+		Long:    taintApplyLong,
+		Example: taintApplyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+// NewCmdTaintRemove creates a command that removes the taints of a profile from nodes.
+func NewCmdTaintRemove(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewTaintProfileOptions(streams, true)
+	cmd := &cobra.Command{
+		Use:     "remove PROFILE (NODE... | --selector=SELECTOR | --all)",
+		Short:   "Remove the taints of a profile from nodes",
+		Long:    taintRemoveLong,
+		Example: taintRemoveExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *TaintProfileOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.ProfilesFile, "profiles", o.ProfilesFile, "The file defining the taint profiles.")
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, select all nodes in the cluster.")
+	kcmdutil.AddLabelSelectorFlagVar(cmd, &o.Selector)
+	kcmdutil.AddDryRunFlag(cmd)
+}
+
+func (o *TaintProfileOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "a profile is required")
+	}
+	o.Profile, o.NodeNames = args[0], args[1:]
+
+	var err error
+	if o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd); err != nil {
+		return err
+	}
+	if o.Taints, err = loadTaintProfile(o.ProfilesFile, o.Profile); err != nil {
+		return err
+	}
+	o.Client, err = f.KubernetesClientSet()
+	return err
+}
+
+func (o *TaintProfileOptions) Validate() error {
+	selections := 0
+	for _, selected := range []bool{len(o.NodeNames) > 0, len(o.Selector) > 0, o.All} {
+		if selected {
+			selections++
+		}
+	}
+	if selections != 1 {
+		return fmt.Errorf("exactly one of node names, --selector or --all is required")
+	}
+	return nil
+}
+
+func (o *TaintProfileOptions) Run() error {
+	nodes, err := getNodes(o.Client, o.NodeNames, o.Selector)
+	if err != nil {
+		return err
+	}
+
+	verb := "tainted"
+	if o.Remove {
+		verb = "untainted"
+	}
+	switch o.DryRunStrategy {
+	case kcmdutil.DryRunClient:
+		verb += " (dry run)"
+	case kcmdutil.DryRunServer:
+		verb += " (server dry run)"
+	}
+
+	var errs []error
+	for _, node := range nodes {
+		changes, err := o.taintNode(node)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to update the taints of node %q: %v", node.Name, err))
+			continue
+		}
+		if len(changes) == 0 {
+			fmt.Fprintf(o.Out, "node/%s unchanged\n", node.Name)
+			continue
+		}
+		fmt.Fprintf(o.Out, "node/%s %s\n", node.Name, verb)
+		printTaintChanges(o.Out, changes)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// taintNode updates the taints of the node with the profile and returns the changes made to them. The node is
+// fetched again when it was modified since it was listed.
+func (o *TaintProfileOptions) taintNode(node *corev1.Node) ([]taintChange, error) {
+	var changes []taintChange
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var taints []corev1.Taint
+		if o.Remove {
+			taints, changes = removeTaints(node.Spec.Taints, o.Taints)
+		} else {
+			taints, changes = applyTaints(node.Spec.Taints, o.Taints)
+		}
+		if len(changes) == 0 || o.DryRunStrategy == kcmdutil.DryRunClient {
+			return nil
+		}
+
+		updated := node.DeepCopy()
+		updated.Spec.Taints = taints
+		options := metav1.UpdateOptions{}
+		if o.DryRunStrategy == kcmdutil.DryRunServer {
+			options.DryRun = []string{metav1.DryRunAll}
+		}
+		_, err := o.Client.CoreV1().Nodes().Update(context.TODO(), updated, options)
+		if kerrors.IsConflict(err) {
+			latest, getErr := o.Client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+			if getErr != nil {
+				return getErr
+			}
+			node = latest
+		}
+		return err
+	})
+	return changes, err
+}
+
+// loadTaintProfile returns the taints of the profile defined in the profiles file.
+func loadTaintProfile(path, profile string) ([]corev1.Taint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the taint profiles: %v", err)
+	}
+	profiles := &taintProfiles{}
+	if err := yaml.UnmarshalStrict(data, profiles); err != nil {
+		return nil, fmt.Errorf("unable to parse the taint profiles of %s: %v", path, err)
+	}
+	taints, ok := profiles.Profiles[profile]
+	if !ok {
+		var names []string
+		for name := range profiles.Profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("no taint profile %q in %s, the profiles are: %s", profile, path, strings.Join(names, ", "))
+	}
+	if len(taints) == 0 {
+		return nil, fmt.Errorf("the taint profile %q has no taint", profile)
+	}
+	for i, taint := range taints {
+		if err := validateTaint(&taint); err != nil {
+			return nil, fmt.Errorf("invalid taint %d of profile %q: %v", i+1, profile, err)
+		}
+		for _, other := range taints[:i] {
+			if other.MatchTaint(&taint) {
+				return nil, fmt.Errorf("the taint profile %q has several taints with key %q and effect %s", profile, taint.Key, taint.Effect)
+			}
+		}
+	}
+	return taints, nil
+}
+
+// validateTaint validates the key, value and effect of a taint the way kubectl taint does.
+func validateTaint(taint *corev1.Taint) error {
+	if errs := validation.IsQualifiedName(taint.Key); len(errs) > 0 {
+		return fmt.Errorf("invalid key %q: %s", taint.Key, strings.Join(errs, "; "))
+	}
+	if len(taint.Value) > 0 {
+		if errs := validation.IsValidLabelValue(taint.Value); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q: %s", taint.Value, strings.Join(errs, "; "))
+		}
+	}
+	switch taint.Effect {
+	case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+		return nil
+	}
+	return fmt.Errorf("invalid effect %q, must be one of: %s, %s, %s", taint.Effect, corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute)
+}
+
+// taintChange is a change made to the taints of a node.
+type taintChange struct {
+	// Op is "+" for an added taint, "~" for a changed one and "-" for a removed one.
+	Op    string
+	Taint corev1.Taint
+	// Previous is the taint before it was changed.
+	Previous *corev1.Taint
+}
+
+// applyTaints returns the taints updated with the taints of a profile, and the changes made to them. A taint of
+// the profile replaces the taint with the same key and effect.
+func applyTaints(current, profile []corev1.Taint) ([]corev1.Taint, []taintChange) {
+	taints := append([]corev1.Taint(nil), current...)
+	var changes []taintChange
+	for _, taint := range profile {
+		found := false
+		for i := range taints {
+			if !taints[i].MatchTaint(&taint) {
+				continue
+			}
+			found = true
+			if taints[i].Value != taint.Value {
+				previous := taints[i]
+				taints[i].Value = taint.Value
+				changes = append(changes, taintChange{Op: "~", Taint: taints[i], Previous: &previous})
+			}
+			break
+		}
+		if !found {
+			taints = append(taints, taint)
+			changes = append(changes, taintChange{Op: "+", Taint: taint})
+		}
+	}
+	return taints, changes
+}
+
+// removeTaints returns the taints without the taints of a profile, and the removed taints. The taints with the
+// same key and effect as a taint of the profile are removed, whatever their value.
+func removeTaints(current, profile []corev1.Taint) ([]corev1.Taint, []taintChange) {
+	var taints []corev1.Taint
+	var changes []taintChange
+	for _, taint := range current {
+		removed := false
+		for i := range profile {
+			if profile[i].MatchTaint(&taint) {
+				removed = true
+				break
+			}
+		}
+		if removed {
+			changes = append(changes, taintChange{Op: "-", Taint: taint})
+		} else {
+			taints = append(taints, taint)
+		}
+	}
+	return taints, changes
+}
+
+// printTaintChanges prints the changes made to the taints of a node, one per line.
+func printTaintChanges(out io.Writer, changes []taintChange) {
+	for _, change := range changes {
+		if change.Previous != nil {
+			fmt.Fprintf(out, "  %s %s (was %s)\n", change.Op, change.Taint.ToString(), change.Previous.ToString())
+			continue
+		}
+		fmt.Fprintf(out, "  %s %s\n", change.Op, change.Taint.ToString())
+	}
+}
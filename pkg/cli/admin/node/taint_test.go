@@ -0,0 +1,208 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+var (
+	maintenanceNoSchedule = corev1.Taint{Key: "example.com/maintenance", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	maintenanceNoExecute  = corev1.Taint{Key: "example.com/maintenance", Value: "true", Effect: corev1.TaintEffectNoExecute}
+	gpuNoSchedule         = corev1.Taint{Key: "example.com/gpu", Effect: corev1.TaintEffectNoSchedule}
+)
+
+func Test_applyTaints(t *testing.T) {
+	current := []corev1.Taint{
+		gpuNoSchedule,
+		{Key: "example.com/maintenance", Value: "false", Effect: corev1.TaintEffectNoSchedule},
+	}
+	taints, changes := applyTaints(current, []corev1.Taint{maintenanceNoSchedule, maintenanceNoExecute})
+
+	if want := []corev1.Taint{gpuNoSchedule, maintenanceNoSchedule, maintenanceNoExecute}; !reflect.DeepEqual(taints, want) {
+		t.Errorf("unexpected taints %v, want %v", taints, want)
+	}
+	out := &bytes.Buffer{}
+	printTaintChanges(out, changes)
+	want := `  ~ example.com/maintenance=true:NoSchedule (was example.com/maintenance=false:NoSchedule)
+  + example.com/maintenance=true:NoExecute
+`
+	if out.String() != want {
+		t.Errorf("unexpected changes:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if current[1].Value != "false" {
+		t.Errorf("the current taints were modified")
+	}
+
+	if _, changes := applyTaints(taints, []corev1.Taint{maintenanceNoSchedule}); len(changes) != 0 {
+		t.Errorf("unexpected changes when the taints are already applied: %v", changes)
+	}
+}
+
+func Test_removeTaints(t *testing.T) {
+	current := []corev1.Taint{
+		gpuNoSchedule,
+		{Key: "example.com/maintenance", Value: "false", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "example.com/maintenance", Effect: corev1.TaintEffectPreferNoSchedule},
+	}
+	taints, changes := removeTaints(current, []corev1.Taint{maintenanceNoSchedule, maintenanceNoExecute})
+
+	if want := []corev1.Taint{gpuNoSchedule, current[2]}; !reflect.DeepEqual(taints, want) {
+		t.Errorf("unexpected taints %v, want %v", taints, want)
+	}
+	out := &bytes.Buffer{}
+	printTaintChanges(out, changes)
+	if want := "  - example.com/maintenance=false:NoSchedule\n"; out.String() != want {
+		t.Errorf("unexpected changes:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func writeTaintProfiles(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "taint-profiles.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_loadTaintProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		want    []corev1.Taint
+		wantErr string
+	}{
+		{
+			name: "profile",
+			content: `profiles:
+  maintenance:
+  - key: example.com/maintenance
+    value: "true"
+    effect: NoSchedule
+  - key: example.com/maintenance
+    value: "true"
+    effect: NoExecute
+  gpu:
+  - key: example.com/gpu
+    effect: NoSchedule
+`,
+			profile: "maintenance",
+			want:    []corev1.Taint{maintenanceNoSchedule, maintenanceNoExecute},
+		},
+		{
+			name:    "unknown profile",
+			content: "profiles:\n  gpu:\n  - key: example.com/gpu\n    effect: NoSchedule\n  db: []\n",
+			profile: "maintenance",
+			wantErr: `no taint profile "maintenance" in`,
+		},
+		{
+			name:    "empty profile",
+			content: "profiles:\n  maintenance: []\n",
+			profile: "maintenance",
+			wantErr: `the taint profile "maintenance" has no taint`,
+		},
+		{
+			name:    "invalid effect",
+			content: "profiles:\n  maintenance:\n  - key: example.com/maintenance\n    effect: NoRun\n",
+			profile: "maintenance",
+			wantErr: `invalid taint 1 of profile "maintenance": invalid effect "NoRun"`,
+		},
+		{
+			name:    "invalid key",
+			content: "profiles:\n  maintenance:\n  - key: -maintenance\n    effect: NoSchedule\n",
+			profile: "maintenance",
+			wantErr: `invalid key "-maintenance"`,
+		},
+		{
+			name:    "duplicate taints",
+			content: "profiles:\n  maintenance:\n  - key: a\n    value: b\n    effect: NoSchedule\n  - key: a\n    value: c\n    effect: NoSchedule\n",
+			profile: "maintenance",
+			wantErr: `several taints with key "a" and effect NoSchedule`,
+		},
+		{
+			name:    "unknown field",
+			content: "profile:\n  maintenance: []\n",
+			profile: "maintenance",
+			wantErr: "unable to parse the taint profiles",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadTaintProfile(writeTaintProfiles(t, tt.content), tt.profile)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaintProfileOptions_Run(t *testing.T) {
+	newNode := func(name, zone string, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"zone": zone}},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+
+	for _, dryRun := range []kcmdutil.DryRunStrategy{kcmdutil.DryRunNone, kcmdutil.DryRunClient} {
+		client := fake.NewSimpleClientset(
+			newNode("worker-0", "a"),
+			newNode("worker-1", "a", maintenanceNoSchedule),
+			newNode("worker-2", "b"),
+		)
+		streams, _, out, _ := genericclioptions.NewTestIOStreams()
+		o := &TaintProfileOptions{
+			Selector:       "zone=a",
+			Taints:         []corev1.Taint{maintenanceNoSchedule},
+			DryRunStrategy: dryRun,
+			Client:         client,
+			IOStreams:      streams,
+		}
+		if err := o.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		verb := "tainted"
+		if dryRun == kcmdutil.DryRunClient {
+			verb = "tainted (dry run)"
+		}
+		want := "node/worker-0 " + verb + "\n  + example.com/maintenance=true:NoSchedule\nnode/worker-1 unchanged\n"
+		if out.String() != want {
+			t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+		}
+
+		node, err := client.CoreV1().Nodes().Get(context.TODO(), "worker-0", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tainted := len(node.Spec.Taints) > 0; tainted != (dryRun == kcmdutil.DryRunNone) {
+			t.Errorf("dry run %v: unexpected taints of worker-0: %v", dryRun, node.Spec.Taints)
+		}
+		if node, err = client.CoreV1().Nodes().Get(context.TODO(), "worker-2", metav1.GetOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if len(node.Spec.Taints) > 0 {
+			t.Errorf("unexpected taints of worker-2: %v", node.Spec.Taints)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package ocpcertificates
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+	"github.com/openshift/oc/pkg/helpers/hostpod"
+)
+
+var (
+	inspectLong = templates.LongDesc(`
+		Print the expiry of the certificates managed by the cluster.
+
+		The certificates of the TLS secrets of the namespaces whose name starts with openshift- or
+		kube- are inspected: the serving and client certificates and the signers of the API servers,
+		the ingress, etcd and the kubelets, and the certificates issued by the service CA. Every
+		certificate of a secret is reported, including the CA certificates bundled with the leaf
+		certificate.
+
+		The kubelet client and serving certificates are stored on the nodes and are only inspected
+		with --include-nodes, which reads them in a privileged pod on every node.
+
+		The certificates are sorted by expiry, the first to expire first. With --expiring-within
+		only the certificates that expire within the given duration, or have expired, are printed.
+		The duration accepts a number of days, such as 30d, or a Go duration, such as 12h.
+	`)
+
+	inspectExample = templates.Examples(`
+		# Print the expiry of the certificates of the cluster
+		oc adm ocp-certificates inspect
+
+		# Print the certificates that expire within 30 days, including the kubelet certificates
+		oc adm ocp-certificates inspect --expiring-within=30d --include-nodes
+
+		# Print the certificates that expire within 30 days as JSON for monitoring
+		oc adm ocp-certificates inspect --expiring-within=30d -o json
+	`)
+)
+
+// servingCertServiceAnnotation is set by the service CA on the serving certificates it issues.
+const servingCertServiceAnnotation = "service.beta.openshift.io/originating-service-name"
+
+// kubeletCertificates are the current certificates of the kubelet on the nodes, which the kubelet rotates before
+// they expire.
+var kubeletCertificates = []struct {
+	component string
+	path      string
+}{
+	{component: "kubelet-client", path: "/var/lib/kubelet/pki/kubelet-client-current.pem"},
+	{component: "kubelet-serving", path: "/var/lib/kubelet/pki/kubelet-server-current.pem"},
+}
+
+// namespaceComponents are the components of the certificates of the platform namespaces. The certificates of the
+// other namespaces belong to the component "other".
+var namespaceComponents = map[string]string{
+	"openshift-kube-apiserver":                   "kube-apiserver",
+	"openshift-kube-apiserver-operator":          "kube-apiserver",
+	"openshift-apiserver":                        "openshift-apiserver",
+	"openshift-oauth-apiserver":                  "oauth-apiserver",
+	"openshift-ingress":                          "ingress",
+	"openshift-ingress-operator":                 "ingress",
+	"openshift-etcd":                             "etcd",
+	"openshift-etcd-operator":                    "etcd",
+	"openshift-kube-controller-manager":          "kube-controller-manager",
+	"openshift-kube-controller-manager-operator": "kube-controller-manager",
+	"openshift-service-ca":                       "service-ca",
+}
+
+// InspectOptions holds the options to inspect the certificates of the cluster.
+type InspectOptions struct {
+	ExpiringWithin string
+	SortBy         string
+	Output         string
+	IncludeNodes   bool
+	Image          string
+	Timeout        time.Duration
+
+	expiringWithin time.Duration
+
+	Client      kubernetes.Interface
+	ImageClient imagev1client.ImageV1Interface
+	Config      *rest.Config
+
+	genericclioptions.IOStreams
+}
+
+func NewInspectOptions(streams genericclioptions.IOStreams) *InspectOptions {
+	return &InspectOptions{
+		SortBy:    "expiry",
+		Timeout:   5 * time.Minute,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdInspect creates a command that prints the expiry of the certificates of the cluster.
+func NewCmdInspect(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewInspectOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "inspect",
+		Short:   "Print the expiry of the certificates of the cluster",
+		Long:    inspectLong,
+		Example: inspectExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.ExpiringWithin, "expiring-within", o.ExpiringWithin, "Only print the certificates that expire within this duration, such as 30d or 12h, or have expired.")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "Sort the certificates by expiry, component or location.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+	cmd.Flags().BoolVar(&o.IncludeNodes, "include-nodes", o.IncludeNodes, "If true, inspect the kubelet certificates of every node in a privileged pod.")
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "The image of the pods that read the kubelet certificates. Defaults to the openshift/tools image stream, or the RHEL support tools if it does not exist.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The time to wait for the pod of a node to start.")
+
+	return cmd
+}
+
+func (o *InspectOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	if len(o.ExpiringWithin) > 0 {
+		within, err := parseExpiringWithin(o.ExpiringWithin)
+		if err != nil {
+			return kcmdutil.UsageErrorf(cmd, "invalid --expiring-within: %v", err)
+		}
+		o.expiringWithin = within
+	}
+
+	var err error
+	if o.Config, err = f.ToRESTConfig(); err != nil {
+		return err
+	}
+	if o.Client, err = kubernetes.NewForConfig(o.Config); err != nil {
+		return err
+	}
+	if o.IncludeNodes {
+		if o.ImageClient, err = imagev1client.NewForConfig(o.Config); err != nil {
+			return err
+		}
+		if len(o.Image) == 0 {
+			o.Image = hostpod.Image(o.ImageClient)
+		}
+	}
+	return nil
+}
+
+func (o *InspectOptions) Validate() error {
+	switch o.SortBy {
+	case "expiry", "component", "location":
+	default:
+		return fmt.Errorf("--sort-by must be one of: expiry, component, location")
+	}
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be one of: json, yaml")
+	}
+	if o.IncludeNodes && o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be greater than 0")
+	}
+	return nil
+}
+
+func (o *InspectOptions) Run() error {
+	certs, err := o.secretCertificates()
+	if err != nil {
+		return err
+	}
+	if o.IncludeNodes {
+		nodeCerts, err := o.nodeCertificates()
+		if err != nil {
+			return err
+		}
+		certs = append(certs, nodeCerts...)
+	}
+
+	now := time.Now()
+	if len(o.ExpiringWithin) > 0 {
+		certs = expiringCertificates(certs, now.Add(o.expiringWithin))
+	}
+	sortCertificates(certs, o.SortBy)
+
+	if len(o.Output) > 0 {
+		if certs == nil {
+			certs = []certificateInfo{}
+		}
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, certs)
+	}
+
+	if len(certs) == 0 {
+		if len(o.ExpiringWithin) > 0 {
+			fmt.Fprintf(o.Out, "No certificates expire within %s\n", o.ExpiringWithin)
+		} else {
+			fmt.Fprintf(o.Out, "No certificates found\n")
+		}
+		return nil
+	}
+	printCertificates(o.Out, certs, now)
+	return nil
+}
+
+// certificateInfo is the expiry of a certificate of the cluster.
+type certificateInfo struct {
+	// Component is the component the certificate belongs to.
+	Component string `json:"component"`
+	// Location is where the certificate is stored, secret/NAMESPACE/NAME or node/NAME:PATH.
+	Location  string    `json:"location"`
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	CA        bool      `json:"ca"`
+	NotBefore time.Time `json:"notBefore"`
+	NotAfter  time.Time `json:"notAfter"`
+}
+
+// secretCertificates returns the certificates of the TLS secrets of the platform namespaces.
+func (o *InspectOptions) secretCertificates() ([]certificateInfo, error) {
+	secrets, err := o.Client.CoreV1().Secrets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("type", string(corev1.SecretTypeTLS)).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var certs []certificateInfo
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.Type != corev1.SecretTypeTLS || !isPlatformNamespace(secret.Namespace) {
+			continue
+		}
+		data := secret.Data[corev1.TLSCertKey]
+		if len(data) == 0 {
+			continue
+		}
+		location := fmt.Sprintf("secret/%s/%s", secret.Namespace, secret.Name)
+		parsed, err := certutil.ParseCertsPEM(data)
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: unable to parse the certificates of %s: %v\n", location, err)
+			continue
+		}
+		certs = append(certs, newCertificateInfos(secretComponent(secret), location, parsed)...)
+	}
+	return certs, nil
+}
+
+// nodeCertificates returns the kubelet certificates of the nodes, which are read in a host pod on every node.
+// The nodes whose certificates cannot be read are reported.
+func (o *InspectOptions) nodeCertificates() ([]certificateInfo, error) {
+	nodes, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes.Items) == 0 {
+		return nil, nil
+	}
+
+	namespace, cleanup, err := hostpod.CreateNamespace(o.Client, "oc adm ocp-certificates inspect", o.ErrOut)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []certificateInfo
+	err = interrupt.New(nil, cleanup).Run(func() error {
+		for _, node := range nodes.Items {
+			pod, err := hostpod.Start(o.Client, namespace, node.Name, "certificates", o.Image, o.Timeout)
+			if err != nil {
+				fmt.Fprintf(o.ErrOut, "warning: unable to inspect the kubelet certificates of node %s: %v\n", node.Name, err)
+				continue
+			}
+			for _, file := range kubeletCertificates {
+				location := fmt.Sprintf("node/%s:%s", node.Name, file.path)
+				parsed, err := o.readCertificates(pod, file.path)
+				if err != nil {
+					fmt.Fprintf(o.ErrOut, "warning: unable to read the certificates of %s: %v\n", location, err)
+					continue
+				}
+				certs = append(certs, newCertificateInfos(file.component, location, parsed)...)
+			}
+		}
+		return nil
+	})
+	return certs, err
+}
+
+// readCertificates reads the certificates of the file of the node of the host pod. Only the certificates are read,
+// never the private key that the kubelet stores in the same file.
+func (o *InspectOptions) readCertificates(pod *corev1.Pod, path string) ([]*x509.Certificate, error) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	command := []string{"chroot", "/host", "sed", "-n", "/-----BEGIN CERTIFICATE-----/,/-----END CERTIFICATE-----/p", path}
+	if err := hostpod.Exec(o.Client, o.Config, pod, command, nil, out, errOut); err != nil {
+		return nil, hostpod.RemoteError(err, errOut)
+	}
+	return certutil.ParseCertsPEM(out.Bytes())
+}
+
+// newCertificateInfos returns the expiry of the certificates of the location.
+func newCertificateInfos(component, location string, certs []*x509.Certificate) []certificateInfo {
+	var infos []certificateInfo
+	for _, cert := range certs {
+		infos = append(infos, certificateInfo{
+			Component: component,
+			Location:  location,
+			Subject:   nameOf(cert.Subject.CommonName, cert.Subject.String()),
+			Issuer:    nameOf(cert.Issuer.CommonName, cert.Issuer.String()),
+			CA:        cert.IsCA,
+			NotBefore: cert.NotBefore.UTC(),
+			NotAfter:  cert.NotAfter.UTC(),
+		})
+	}
+	return infos
+}
+
+// nameOf returns the common name of a subject or issuer, or its full name if it has no common name.
+func nameOf(commonName, name string) string {
+	if len(commonName) > 0 {
+		return commonName
+	}
+	return name
+}
+
+// isPlatformNamespace returns true if the certificates of the namespace are managed by the cluster.
+func isPlatformNamespace(namespace string) bool {
+	return strings.HasPrefix(namespace, "openshift-") || strings.HasPrefix(namespace, "kube-")
+}
+
+// secretComponent returns the component of the certificates of the secret: the service CA for the serving
+// certificates it issued, otherwise the component of the namespace.
+func secretComponent(secret *corev1.Secret) string {
+	if _, ok := secret.Annotations[servingCertServiceAnnotation]; ok {
+		return "service-ca"
+	}
+	if component, ok := namespaceComponents[secret.Namespace]; ok {
+		return component
+	}
+	if secret.Namespace == "openshift-config" && strings.HasPrefix(secret.Name, "etcd-") {
+		return "etcd"
+	}
+	return "other"
+}
+
+// parseExpiringWithin parses a number of days, such as 30d, or a Go duration.
+func parseExpiringWithin(value string) (time.Duration, error) {
+	var within time.Duration
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number of days", value)
+		}
+		within = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if within, err = time.ParseDuration(value); err != nil {
+			return 0, err
+		}
+	}
+	if within < 0 {
+		return 0, fmt.Errorf("%q is negative", value)
+	}
+	return within, nil
+}
+
+// expiringCertificates returns the certificates that expire before deadline.
+func expiringCertificates(certs []certificateInfo, deadline time.Time) []certificateInfo {
+	var expiring []certificateInfo
+	for _, cert := range certs {
+		if cert.NotAfter.Before(deadline) {
+			expiring = append(expiring, cert)
+		}
+	}
+	return expiring
+}
+
+// sortCertificates sorts the certificates by expiry, component or location. Certificates with the same component
+// or location are sorted by expiry.
+func sortCertificates(certs []certificateInfo, sortBy string) {
+	sort.SliceStable(certs, func(i, j int) bool {
+		a, b := certs[i], certs[j]
+		switch sortBy {
+		case "component":
+			if a.Component != b.Component {
+				return a.Component < b.Component
+			}
+		case "location":
+			if a.Location != b.Location {
+				return a.Location < b.Location
+			}
+		}
+		if !a.NotAfter.Equal(b.NotAfter) {
+			return a.NotAfter.Before(b.NotAfter)
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		return a.Subject < b.Subject
+	})
+}
+
+// printCertificates prints a table of the certificates and when they expire relative to now.
+func printCertificates(out io.Writer, certs []certificateInfo, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tLOCATION\tSUBJECT\tISSUER\tNOT AFTER\tEXPIRES")
+	for _, cert := range certs {
+		var expires string
+		if cert.NotAfter.After(now) {
+			expires = "in " + duration.HumanDuration(cert.NotAfter.Sub(now))
+		} else {
+			expires = "expired " + duration.HumanDuration(now.Sub(cert.NotAfter)) + " ago"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", cert.Component, cert.Location, cert.Subject, cert.Issuer, cert.NotAfter.Format(time.RFC3339), expires)
+	}
+	w.Flush()
+}
@@ -0,0 +1,184 @@
+package ocpcertificates
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// testCertificate returns a PEM encoded self-signed certificate of commonName that expires at notAfter.
+func testCertificate(t *testing.T, commonName string, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func testSecret(namespace, name string, secretType corev1.SecretType, annotations map[string]string, cert []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations},
+		Type:       secretType,
+		Data:       map[string][]byte{corev1.TLSCertKey: cert},
+	}
+}
+
+func TestSecretCertificates(t *testing.T) {
+	expiry := testNow.Add(10 * 24 * time.Hour)
+	serving := testCertificate(t, "api.example.com", expiry)
+	signer := testCertificate(t, "kube-apiserver-lb-signer", expiry.Add(time.Hour))
+
+	client := fake.NewSimpleClientset(
+		testSecret("openshift-kube-apiserver", "external-loadbalancer-serving-certkey", corev1.SecretTypeTLS, nil, append(serving, signer...)),
+		testSecret("openshift-monitoring", "prometheus-k8s-tls", corev1.SecretTypeTLS, map[string]string{servingCertServiceAnnotation: "prometheus-k8s"}, serving),
+		testSecret("openshift-config", "etcd-signer", corev1.SecretTypeTLS, nil, signer),
+		testSecret("kube-system", "initial-client", corev1.SecretTypeTLS, nil, serving),
+		testSecret("openshift-ingress", "invalid", corev1.SecretTypeTLS, nil, []byte("not a certificate")),
+		testSecret("openshift-ingress", "empty", corev1.SecretTypeTLS, nil, nil),
+		testSecret("openshift-ingress", "opaque", corev1.SecretTypeOpaque, nil, serving),
+		testSecret("my-project", "my-tls", corev1.SecretTypeTLS, nil, serving),
+	)
+	streams, _, _, errOut := genericclioptions.NewTestIOStreams()
+	o := &InspectOptions{Client: client, IOStreams: streams}
+
+	certs, err := o.secretCertificates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sortCertificates(certs, "location")
+
+	type summary struct {
+		component, location, subject string
+		ca                           bool
+	}
+	var got []summary
+	for _, cert := range certs {
+		if !cert.NotAfter.Equal(expiry) && !cert.NotAfter.Equal(expiry.Add(time.Hour)) {
+			t.Errorf("unexpected expiry of %s: %s", cert.Subject, cert.NotAfter)
+		}
+		got = append(got, summary{cert.Component, cert.Location, cert.Subject, cert.CA})
+	}
+	expected := []summary{
+		{"other", "secret/kube-system/initial-client", "api.example.com", true},
+		{"etcd", "secret/openshift-config/etcd-signer", "kube-apiserver-lb-signer", true},
+		{"kube-apiserver", "secret/openshift-kube-apiserver/external-loadbalancer-serving-certkey", "api.example.com", true},
+		{"kube-apiserver", "secret/openshift-kube-apiserver/external-loadbalancer-serving-certkey", "kube-apiserver-lb-signer", true},
+		{"service-ca", "secret/openshift-monitoring/prometheus-k8s-tls", "api.example.com", true},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected certificates:\n%v\nexpected:\n%v", got, expected)
+	}
+	if expected := "warning: unable to parse the certificates of secret/openshift-ingress/invalid: data does not contain any valid RSA or ECDSA certificates\n"; errOut.String() != expected {
+		t.Errorf("unexpected warnings %q, expected %q", errOut.String(), expected)
+	}
+}
+
+func TestParseExpiringWithin(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "30d", expected: 30 * 24 * time.Hour},
+		{value: "0d", expected: 0},
+		{value: "12h", expected: 12 * time.Hour},
+		{value: "1h30m", expected: 90 * time.Minute},
+		{value: "d", wantErr: true},
+		{value: "1.5d", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "month", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			within, err := parseExpiringWithin(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if within != test.expected {
+				t.Errorf("parseExpiringWithin() = %s, expected %s", within, test.expected)
+			}
+		})
+	}
+}
+
+func TestExpiringCertificatesAndSort(t *testing.T) {
+	certs := []certificateInfo{
+		{Component: "ingress", Location: "secret/openshift-ingress/router-certs-default", NotAfter: testNow.Add(60 * 24 * time.Hour)},
+		{Component: "etcd", Location: "secret/openshift-etcd/etcd-serving-master-0", NotAfter: testNow.Add(20 * 24 * time.Hour)},
+		{Component: "service-ca", Location: "secret/openshift-service-ca/signing-key", NotAfter: testNow.Add(-time.Hour)},
+		{Component: "etcd", Location: "secret/openshift-etcd/etcd-peer-master-0", NotAfter: testNow.Add(25 * 24 * time.Hour)},
+	}
+
+	expiring := expiringCertificates(certs, testNow.Add(30*24*time.Hour))
+	sortCertificates(expiring, "expiry")
+	var locations []string
+	for _, cert := range expiring {
+		locations = append(locations, cert.Location)
+	}
+	expected := []string{
+		"secret/openshift-service-ca/signing-key",
+		"secret/openshift-etcd/etcd-serving-master-0",
+		"secret/openshift-etcd/etcd-peer-master-0",
+	}
+	if !reflect.DeepEqual(locations, expected) {
+		t.Errorf("unexpected expiring certificates %v, expected %v", locations, expected)
+	}
+
+	sortCertificates(certs, "component")
+	locations = nil
+	for _, cert := range certs {
+		locations = append(locations, cert.Location)
+	}
+	expected = []string{
+		"secret/openshift-etcd/etcd-serving-master-0",
+		"secret/openshift-etcd/etcd-peer-master-0",
+		"secret/openshift-ingress/router-certs-default",
+		"secret/openshift-service-ca/signing-key",
+	}
+	if !reflect.DeepEqual(locations, expected) {
+		t.Errorf("unexpected order %v, expected %v", locations, expected)
+	}
+}
+
+func TestPrintCertificates(t *testing.T) {
+	certs := []certificateInfo{
+		{Component: "service-ca", Location: "secret/openshift-service-ca/signing-key", Subject: "openshift-service-serving-signer@1680000000", Issuer: "openshift-service-serving-signer@1680000000", NotAfter: testNow.Add(-5 * time.Hour)},
+		{Component: "kubelet-serving", Location: "node/master-0:/var/lib/kubelet/pki/kubelet-server-current.pem", Subject: "system:node:master-0", Issuer: "kube-csr-signer", NotAfter: testNow.Add(20 * 24 * time.Hour)},
+	}
+	out := &bytes.Buffer{}
+	printCertificates(out, certs, testNow)
+	expected := "COMPONENT         LOCATION                                                        SUBJECT                                       ISSUER                                        NOT AFTER              EXPIRES\n" +
+		"service-ca        secret/openshift-service-ca/signing-key                         openshift-service-serving-signer@1680000000   openshift-service-serving-signer@1680000000   2024-05-01T07:00:00Z   expired 5h ago\n" +
+		"kubelet-serving   node/master-0:/var/lib/kubelet/pki/kubelet-server-current.pem   system:node:master-0                          kube-csr-signer                               2024-05-21T12:00:00Z   in 20d\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
@@ -0,0 +1,30 @@
+package ocpcertificates
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	ocpCertificatesLong = templates.LongDesc(`
+		Manage the certificates of the cluster
+
+		This command inspects the certificates that the platform manages for the API servers,
+		the ingress, etcd, the kubelets and the service CA.`)
+)
+
+func NewCmdOCPCertificates(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "ocp-certificates",
+		Short: "Manage the certificates of the cluster",
+		Long:  ocpCertificatesLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdInspect(f, streams))
+	return cmds
+}
@@ -0,0 +1,316 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+)
+
+var (
+	applyLong = templates.LongDesc(`
+		Reconcile role bindings with a policy file
+
+		The policy file lists, for every project, the roles to grant and the users, groups and service accounts to
+		grant them to. Roles are cluster roles unless prefixed with Role/. Service accounts are looked up in the
+		project unless given as NAMESPACE:NAME.
+
+		Subjects missing from the project are added to an existing binding of their role, or to a new binding named
+		after the role when there is none. With --prune, subjects bound to a listed role but missing from the file are
+		removed and bindings left without subjects are deleted. Roles that are not listed for a project are never
+		modified. Pruning skips the bindings annotated with rbac.authorization.kubernetes.io/autoupdate=false or
+		policy.openshift.io/protected=true, and those managed by another object or tool. Subjects are never added to
+		these bindings or to temporary ones, and are not considered granted by them.
+	`)
+
+	applyExample = templates.Examples(`
+		# Grant the roles listed in policy.yaml, where policy.yaml contains:
+		#
+		#   namespaces:
+		#     dev:
+		#       admin:
+		#         users: [alice]
+		#       edit:
+		#         groups: [developers]
+		#         serviceAccounts: [builder, ci:deployer]
+		#       Role/log-reader:
+		#         groups: [support]
+		oc adm policy apply -f policy.yaml
+
+		# Show the changes needed to make the projects match policy.yaml exactly
+		oc adm policy apply -f policy.yaml --prune --dry-run=client
+	`)
+)
+
+type ApplyOptions struct {
+	Filename       string
+	Prune          bool
+	DryRunStrategy kcmdutil.DryRunStrategy
+
+	// AuditLogFile, when set, receives a record of every change to a binding made on behalf of Actor.
+	AuditLogFile string
+	Actor        string
+	audit        *auditLog
+
+	RbacClient rbacv1client.RbacV1Interface
+
+	genericclioptions.IOStreams
+}
+
+// policyFile is the compact format read by apply: role to subjects, per namespace.
+type policyFile struct {
+	Namespaces map[string]map[string]policySubjects `json:"namespaces"`
+}
+
+// policySubjects are the subjects a role is granted to.
+type policySubjects struct {
+	Users           []string `json:"users,omitempty"`
+	Groups          []string `json:"groups,omitempty"`
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+func NewApplyOptions(streams genericclioptions.IOStreams) *ApplyOptions {
+	return &ApplyOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdApply implements the OpenShift cli apply command
+func NewCmdApply(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewApplyOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "apply -f FILENAME",
+		Short:   "Reconcile role bindings with a policy file",
+		Long:    applyLong,
+		Example: applyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "Policy file listing the roles to grant in each project.")
+	cmd.Flags().BoolVar(&o.Prune, "prune", o.Prune, "If true, remove subjects bound to the listed roles that are not in the policy file.")
+	cmd.MarkFlagRequired("filename")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+	kcmdutil.AddDryRunFlag(cmd)
+
+	return cmd
+}
+
+func (o *ApplyOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+	if len(o.Filename) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "a policy file must be specified with -f")
+	}
+
+	var err error
+	o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.RbacClient, err = rbacv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.Actor, err = auditActor(clientConfig, o.AuditLogFile)
+	return err
+}
+
+func (o *ApplyOptions) Run() error {
+	data, err := os.ReadFile(o.Filename)
+	if err != nil {
+		return err
+	}
+	policy := &policyFile{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return fmt.Errorf("unable to read %s: %v", o.Filename, err)
+	}
+
+	o.audit, err = newAuditLog(o.AuditLogFile, o.Actor, o.DryRunStrategy != kcmdutil.DryRunNone)
+	if err != nil {
+		return err
+	}
+	defer o.audit.Close()
+
+	errs := []error{}
+	namespaces := sets.StringKeySet(policy.Namespaces).List()
+	for _, namespace := range namespaces {
+		roles := policy.Namespaces[namespace]
+		for _, role := range sets.StringKeySet(roles).List() {
+			if err := o.reconcile(namespace, role, roles[role]); err != nil {
+				errs = append(errs, fmt.Errorf("unable to apply %s in project %s: %v", role, namespace, err))
+			}
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// reconcile makes the bindings of role in namespace grant it to the desired subjects, adding the missing ones and,
+// when pruning, removing the others.
+func (o *ApplyOptions) reconcile(namespace, role string, desired policySubjects) error {
+	roleKind, roleName := "ClusterRole", role
+	if strings.HasPrefix(role, "Role/") {
+		roleKind, roleName = "Role", strings.TrimPrefix(role, "Role/")
+	}
+	if len(roleName) == 0 {
+		return fmt.Errorf("a role name is required")
+	}
+	subjects, err := desired.subjects(namespace)
+	if err != nil {
+		return err
+	}
+
+	bindings, err := getRoleBindingAbstractionsForRole(o.RbacClient, roleName, roleKind, namespace)
+	if err != nil {
+		return err
+	}
+	sort.Slice(bindings, func(i, j int) bool { return bindings[i].Name() < bindings[j].Name() })
+
+	// only permanent bindings that apply is free to change grant the subjects of the policy, the others may expire or
+	// be reverted by their owner
+	originals := map[*roleBindingAbstraction][]rbacv1.Subject{}
+	existing := []rbacv1.Subject{}
+	var target *roleBindingAbstraction
+	for _, binding := range bindings {
+		originals[binding] = binding.Subjects()
+		if len(binding.Annotation(expiresAtAnnotation)) > 0 || len(pruneProtection(binding)) > 0 {
+			continue
+		}
+		existing = append(existing, binding.Subjects()...)
+		if target == nil {
+			target = binding
+		}
+	}
+
+	if missing := subtractSubjects(subjects, existing); len(missing) > 0 {
+		if target != nil {
+			target.SetSubjects(addSubjects(nil, nil, missing, target.Subjects()))
+		} else {
+			name, err := getUniqueName(o.RbacClient, roleName, namespace, roleKind, missing)
+			if err != nil {
+				return err
+			}
+			binding, err := newRoleBindingAbstraction(o.RbacClient, name, namespace, roleName, roleKind)
+			if err != nil {
+				return err
+			}
+			binding.SetSubjects(missing)
+			bindings = append(bindings, binding)
+		}
+	}
+	if o.Prune {
+		for _, binding := range bindings {
+			if reason := pruneProtection(binding); len(reason) > 0 {
+				if extra := subtractSubjects(binding.Subjects(), subjects); len(extra) > 0 {
+					fmt.Fprintf(o.ErrOut, "Warning: not pruning %s from %s %s in project %s %s\n", strings.Join(subjectNames(extra), ", "), binding.Type(), binding.Name(), namespace, reason)
+				}
+				continue
+			}
+			extra := subtractSubjects(binding.Subjects(), subjects)
+			binding.SetSubjects(subtractSubjects(binding.Subjects(), extra))
+		}
+	}
+
+	dryRunText := ""
+	if o.DryRunStrategy != kcmdutil.DryRunNone {
+		dryRunText = " (dry run)"
+	}
+	errs := []error{}
+	for _, binding := range bindings {
+		original, found := originals[binding]
+		added := subtractSubjects(binding.Subjects(), original)
+		removed := subtractSubjects(original, binding.Subjects())
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+
+		var action string
+		switch {
+		case !found:
+			action = "Created"
+		case len(binding.Subjects()) == 0:
+			action = "Deleted"
+		default:
+			action = "Updated"
+		}
+		if len(dryRunText) == 0 {
+			switch action {
+			case "Created":
+				err = binding.Create()
+			case "Deleted":
+				err = binding.Delete()
+			default:
+				err = binding.Update()
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		operation := map[string]string{"Created": "create", "Deleted": "delete", "Updated": "update"}[action]
+		if err := o.audit.record(operation, binding, original); err != nil {
+			return err
+		}
+
+		changes := []string{}
+		if len(added) > 0 {
+			changes = append(changes, "added "+strings.Join(subjectNames(added), ", "))
+		}
+		if len(removed) > 0 {
+			changes = append(changes, "removed "+strings.Join(subjectNames(removed), ", "))
+		}
+		fmt.Fprintf(o.Out, "%s %s %s in project %s: %s%s\n", action, binding.Type(), binding.Name(), namespace, strings.Join(changes, "; "), dryRunText)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// pruneProtection returns why the subjects of the binding must not be pruned, or an empty string if they may be.
+func pruneProtection(binding *roleBindingAbstraction) string {
+	if binding.Annotation(rbacv1.AutoUpdateAnnotationKey) == "false" {
+		return fmt.Sprintf("annotated with %s=false", rbacv1.AutoUpdateAnnotationKey)
+	}
+	if reason := bindingProtection(binding, nil); len(reason) > 0 {
+		return "protected by " + reason
+	}
+	if manager := bindingManager(binding); len(manager) > 0 {
+		return "managed by " + manager
+	}
+	return ""
+}
+
+// subjects returns the RBAC subjects of the policy entry. Service accounts are in namespace unless qualified with
+// NAMESPACE:NAME.
+func (s policySubjects) subjects(namespace string) ([]rbacv1.Subject, error) {
+	subjects := authorizationutil.BuildRBACSubjects(s.Users, s.Groups)
+	for _, sa := range s.ServiceAccounts {
+		saNamespace, name := namespace, sa
+		if parts := strings.Split(sa, ":"); len(parts) == 2 {
+			saNamespace, name = parts[0], parts[1]
+		}
+		if len(saNamespace) == 0 || len(name) == 0 || strings.Contains(name, ":") {
+			return nil, fmt.Errorf("invalid service account %q, expected NAME or NAMESPACE:NAME", sa)
+		}
+		subjects = append(subjects, rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: saNamespace, Name: name})
+	}
+	return subjects, nil
+}
@@ -0,0 +1,260 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestApplyPolicy(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	bob := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"}
+	devs := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "devs"}
+	builder := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "dev", Name: "builder"}
+	deployer := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ci", Name: "deployer"}
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte(`namespaces:
+  dev:
+    admin:
+      users: [alice]
+    edit:
+      groups: [devs]
+      serviceAccounts: [builder, ci:deployer]
+`), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]struct {
+		prune          bool
+		dryRun         bool
+		expectedOutput string
+		expected       map[string][]rbacv1.Subject
+	}{
+		"add": {
+			expectedOutput: `Updated rolebinding admin in project dev: added User/alice
+Created rolebinding edit in project dev: added Group/devs, ServiceAccount/dev/builder, ServiceAccount/ci/deployer
+`,
+			expected: map[string][]rbacv1.Subject{
+				"admin":   {bob, alice},
+				"edit":    {devs, builder, deployer},
+				"view":    {bob},
+				"admin-2": {bob},
+			},
+		},
+		"prune": {
+			prune: true,
+			expectedOutput: `Updated rolebinding admin in project dev: added User/alice; removed User/bob
+Deleted rolebinding admin-2 in project dev: removed User/bob
+Created rolebinding edit in project dev: added Group/devs, ServiceAccount/dev/builder, ServiceAccount/ci/deployer
+`,
+			expected: map[string][]rbacv1.Subject{
+				"admin": {alice},
+				"edit":  {devs, builder, deployer},
+				"view":  {bob},
+			},
+		},
+		"dry run": {
+			prune:  true,
+			dryRun: true,
+			expectedOutput: `Updated rolebinding admin in project dev: added User/alice; removed User/bob (dry run)
+Deleted rolebinding admin-2 in project dev: removed User/bob (dry run)
+Created rolebinding edit in project dev: added Group/devs, ServiceAccount/dev/builder, ServiceAccount/ci/deployer (dry run)
+`,
+			expected: map[string][]rbacv1.Subject{
+				"admin":   {bob},
+				"view":    {bob},
+				"admin-2": {bob},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset(
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "dev"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+					Subjects:   []rbacv1.Subject{bob},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin-2", Namespace: "dev"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+					Subjects:   []rbacv1.Subject{bob},
+				},
+				// roles that are not in the policy file are left alone, even when pruning
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "view", Namespace: "dev"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+					Subjects:   []rbacv1.Subject{bob},
+				},
+			).RbacV1()
+
+			out := &bytes.Buffer{}
+			o := &ApplyOptions{
+				Filename:       file,
+				Prune:          tc.prune,
+				DryRunStrategy: kcmdutil.DryRunNone,
+				RbacClient:     client,
+				IOStreams:      genericclioptions.IOStreams{Out: out, ErrOut: out},
+			}
+			if tc.dryRun {
+				o.DryRunStrategy = kcmdutil.DryRunClient
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expectedOutput {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expectedOutput, out.String())
+			}
+
+			for _, name := range []string{"admin", "admin-2", "edit", "view"} {
+				binding, err := client.RoleBindings("dev").Get(context.TODO(), name, metav1.GetOptions{})
+				if kapierrors.IsNotFound(err) {
+					if _, ok := tc.expected[name]; ok {
+						t.Errorf("expected rolebinding %s to exist", name)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(binding.Subjects, tc.expected[name]) {
+					t.Errorf("rolebinding %s: expected subjects %v, got %v", name, tc.expected[name], binding.Subjects)
+				}
+			}
+
+			// applying again must not change anything
+			if !tc.dryRun {
+				out.Reset()
+				if err := o.Run(); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if out.Len() != 0 {
+					t.Errorf("expected a second apply to be a no-op, got:\n%s", out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestApplyPruneSkipsProtectedBindings(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	bob := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"}
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte(`namespaces:
+  dev:
+    admin:
+      users: [alice]
+`), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	binding := func(name string, meta metav1.ObjectMeta) *rbacv1.RoleBinding {
+		meta.Name, meta.Namespace = name, "dev"
+		return &rbacv1.RoleBinding{
+			ObjectMeta: meta,
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+			Subjects:   []rbacv1.Subject{bob},
+		}
+	}
+	client := fakeclient.NewSimpleClientset(
+		binding("admin", metav1.ObjectMeta{}),
+		binding("admin-autoupdate", metav1.ObjectMeta{Annotations: map[string]string{rbacv1.AutoUpdateAnnotationKey: "false"}}),
+		binding("admin-managed", metav1.ObjectMeta{Labels: map[string]string{"app.kubernetes.io/managed-by": "argocd"}}),
+		binding("admin-protected", metav1.ObjectMeta{Annotations: map[string]string{protectedAnnotation: "true"}}),
+	).RbacV1()
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := &ApplyOptions{
+		Filename:       file,
+		Prune:          true,
+		DryRunStrategy: kcmdutil.DryRunNone,
+		RbacClient:     client,
+		IOStreams:      genericclioptions.IOStreams{Out: out, ErrOut: errOut},
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Updated rolebinding admin in project dev: added User/alice; removed User/bob\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+	for _, name := range []string{"admin-autoupdate", "admin-managed", "admin-protected"} {
+		binding, err := client.RoleBindings("dev").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("expected rolebinding %s to be kept, got %v", name, err)
+		}
+		if !reflect.DeepEqual(binding.Subjects, []rbacv1.Subject{bob}) {
+			t.Errorf("rolebinding %s: expected subjects to be unmodified, got %v", name, binding.Subjects)
+		}
+		if !strings.Contains(errOut.String(), "not pruning User/bob from rolebinding "+name) {
+			t.Errorf("expected a warning for rolebinding %s, got:\n%s", name, errOut.String())
+		}
+	}
+	if binding, _ := client.RoleBindings("dev").Get(context.TODO(), "admin", metav1.GetOptions{}); !reflect.DeepEqual(binding.Subjects, []rbacv1.Subject{alice}) {
+		t.Errorf("rolebinding admin: expected subjects %v, got %v", []rbacv1.Subject{alice}, binding.Subjects)
+	}
+}
+
+func TestApplyAddsToPermanentBindings(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte(`namespaces:
+  dev:
+    admin:
+      users: [alice]
+`), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	binding := func(name string, meta metav1.ObjectMeta) *rbacv1.RoleBinding {
+		meta.Name, meta.Namespace = name, "dev"
+		return &rbacv1.RoleBinding{
+			ObjectMeta: meta,
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+			Subjects:   []rbacv1.Subject{alice},
+		}
+	}
+	client := fakeclient.NewSimpleClientset(
+		binding("admin", metav1.ObjectMeta{Annotations: map[string]string{expiresAtAnnotation: "2000-01-01T00:00:00Z"}}),
+		binding("admin-managed", metav1.ObjectMeta{Labels: map[string]string{"app.kubernetes.io/managed-by": "argocd"}}),
+		binding("admin-protected", metav1.ObjectMeta{Annotations: map[string]string{protectedAnnotation: "true"}}),
+	).RbacV1()
+
+	out := &bytes.Buffer{}
+	o := &ApplyOptions{
+		Filename:       file,
+		DryRunStrategy: kcmdutil.DryRunNone,
+		RbacClient:     client,
+		IOStreams:      genericclioptions.IOStreams{Out: out, ErrOut: out},
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	name := "admin-" + computeRoleBindingHash("admin", "ClusterRole", []rbacv1.Subject{alice})
+	expected := "Created rolebinding " + name + " in project dev: added User/alice\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+	created, err := client.RoleBindings("dev").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(created.Annotations[expiresAtAnnotation]) > 0 || !reflect.DeepEqual(created.Subjects, []rbacv1.Subject{alice}) {
+		t.Errorf("expected a permanent binding of %v, got %#v", alice, created)
+	}
+}
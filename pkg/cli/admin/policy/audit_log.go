@@ -0,0 +1,141 @@
+package policy
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/oc/pkg/helpers/project"
+)
+
+// auditRecord is a single line of the audit log and describes one change to a binding.
+type auditRecord struct {
+	Time      time.Time        `json:"time"`
+	Actor     string           `json:"actor,omitempty"`
+	Operation string           `json:"operation"`
+	Kind      string           `json:"kind"`
+	Namespace string           `json:"namespace,omitempty"`
+	Name      string           `json:"name"`
+	RoleRef   rbacv1.RoleRef   `json:"roleRef"`
+	Before    []rbacv1.Subject `json:"before"`
+	After     []rbacv1.Subject `json:"after"`
+	DryRun    bool             `json:"dryRun"`
+	// PreviousHash is the SHA-256 of the previous line of the log, so that removing or editing a record breaks
+	// the chain of the records that follow it.
+	PreviousHash string `json:"previousHash"`
+}
+
+// auditLog appends an auditRecord in JSON lines format for every change made to a binding.
+type auditLog struct {
+	actor  string
+	dryRun bool
+
+	out      *os.File
+	lastHash string
+}
+
+// addAuditLogFlag adds the --audit-log flag shared by the commands that modify bindings.
+func addAuditLogFlag(cmd *cobra.Command, auditLogFile *string) {
+	cmd.Flags().StringVar(auditLogFile, "audit-log", *auditLogFile, "If set, append a JSON line describing every change to a binding to this file. Each line holds the SHA-256 of the previous one so that edits to the file can be detected.")
+}
+
+// auditActor returns the name of the current user when an audit log is written.
+func auditActor(clientConfig *rest.Config, auditLogFile string) (string, error) {
+	if len(auditLogFile) == 0 {
+		return "", nil
+	}
+	me, err := project.WhoAmI(clientConfig)
+	if err != nil {
+		return "", err
+	}
+	return me.Name, nil
+}
+
+// newAuditLog opens path for appending, or returns nil if it is empty. The log is opened before any binding is
+// modified so that a change is never made without being recorded.
+func newAuditLog(path, actor string, dryRun bool) (*auditLog, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	out, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log: %v", err)
+	}
+
+	l := &auditLog{actor: actor, dryRun: dryRun, out: out}
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Bytes(); len(line) > 0 {
+			l.lastHash = hashLine(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		out.Close()
+		return nil, fmt.Errorf("unable to read audit log: %v", err)
+	}
+	return l, nil
+}
+
+// record appends the change of binding from the before subjects to its current subjects. Deleted bindings are
+// recorded without subjects after the change.
+func (l *auditLog) record(operation string, binding *roleBindingAbstraction, before []rbacv1.Subject) error {
+	if l == nil {
+		return nil
+	}
+
+	kind := "RoleBinding"
+	if len(binding.Namespace()) == 0 {
+		kind = "ClusterRoleBinding"
+	}
+	after := binding.Subjects()
+	if operation == "delete" || after == nil {
+		after = []rbacv1.Subject{}
+	}
+	if before == nil {
+		before = []rbacv1.Subject{}
+	}
+	data, err := json.Marshal(auditRecord{
+		Time:         time.Now().UTC(),
+		Actor:        l.actor,
+		Operation:    operation,
+		Kind:         kind,
+		Namespace:    binding.Namespace(),
+		Name:         binding.Name(),
+		RoleRef:      rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: binding.RoleKind(), Name: binding.RoleName()},
+		Before:       before,
+		After:        after,
+		DryRun:       l.dryRun,
+		PreviousHash: l.lastHash,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := l.out.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("unable to write audit log: %v", err)
+	}
+	l.lastHash = hashLine(data)
+	return nil
+}
+
+// Close closes the log. It is safe to call on a nil log.
+func (l *auditLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.out.Close()
+}
+
+func hashLine(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
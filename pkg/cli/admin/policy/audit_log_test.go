@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestAuditLog(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	bob := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"}
+	client := fakeclient.NewSimpleClientset(
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+			Subjects:   []rbacv1.Subject{alice, bob},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "view", Namespace: "ns"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{alice},
+		},
+	).RbacV1()
+	file := filepath.Join(t.TempDir(), "audit.log")
+
+	remove := func(user string, dryRun kcmdutil.DryRunStrategy) {
+		o := &RemoveFromProjectOptions{
+			PrintFlags:       genericclioptions.NewPrintFlags(""),
+			Printer:          printers.NewDiscardingPrinter(),
+			BindingNamespace: "ns",
+			Client:           client,
+			Users:            []string{user},
+			Actor:            "admin",
+			AuditLogFile:     file,
+			DryRunStrategy:   dryRun,
+			IOStreams:        genericclioptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}},
+		}
+		if err := o.Run(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	remove("alice", kcmdutil.DryRunNone)
+	// a second command appends to the log and continues the chain
+	remove("bob", kcmdutil.DryRunClient)
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	records := []auditRecord{}
+	previousHash := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		record := auditRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if record.PreviousHash != previousHash {
+			t.Errorf("record %d: expected previous hash %q, got %q", len(records), previousHash, record.PreviousHash)
+		}
+		previousHash = hashLine(scanner.Bytes())
+		records = append(records, record)
+	}
+
+	type change struct {
+		operation, name string
+		before, after   []rbacv1.Subject
+		dryRun          bool
+	}
+	expected := []change{
+		{operation: "delete", name: "view", before: []rbacv1.Subject{alice}, after: []rbacv1.Subject{}},
+		{operation: "update", name: "edit", before: []rbacv1.Subject{alice, bob}, after: []rbacv1.Subject{bob}},
+		{operation: "delete", name: "edit", before: []rbacv1.Subject{bob}, after: []rbacv1.Subject{}, dryRun: true},
+	}
+	actual := []change{}
+	for _, record := range records {
+		if record.Actor != "admin" || record.Kind != "RoleBinding" || record.Namespace != "ns" {
+			t.Errorf("unexpected record %#v", record)
+		}
+		actual = append(actual, change{operation: record.Operation, name: record.Name, before: record.Before, after: record.After, dryRun: record.DryRun})
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected changes %v, got %v", expected, actual)
+	}
+}
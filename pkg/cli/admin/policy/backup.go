@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+// restoreSubjectsAnnotation is set on the bindings written to a backup and holds the JSON encoded subjects that
+// were removed from the binding, so that 'oc adm policy restore' can add back exactly those subjects.
+const restoreSubjectsAnnotation = "oc.openshift.io/restore-subjects"
+
+// bindingBackup writes the original version of every binding that is about to be modified either to a single
+// file or to one file per binding in a directory.
+type bindingBackup struct {
+	dir  string
+	file string
+	time time.Time
+
+	out     *os.File
+	printer printers.ResourcePrinter
+}
+
+// newBindingBackup returns a backup writing to dir or to file, or nil if neither is set. Nothing is written until
+// the first binding is saved.
+func newBindingBackup(dir, file string) (*bindingBackup, error) {
+	switch {
+	case len(dir) > 0 && len(file) > 0:
+		return nil, fmt.Errorf("--backup-dir and --backup-file are mutually exclusive")
+	case len(dir) == 0 && len(file) == 0:
+		return nil, nil
+	}
+	return &bindingBackup{dir: dir, file: file, time: time.Now()}, nil
+}
+
+// save writes the original binding, recording the subjects that are removed from it. It must be called before the
+// modified binding is sent to the server.
+func (b *bindingBackup) save(original runtime.Object, removed []rbacv1.Subject) error {
+	obj := original.DeepCopyObject()
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(removed)
+	if err != nil {
+		return err
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[restoreSubjectsAnnotation] = string(data)
+	accessor.SetAnnotations(annotations)
+	accessor.SetManagedFields(nil)
+
+	if len(b.dir) > 0 {
+		if err := os.MkdirAll(b.dir, 0700); err != nil {
+			return err
+		}
+		parts := []string{b.time.UTC().Format("20060102T150405Z"), "clusterrolebinding"}
+		if namespace := accessor.GetNamespace(); len(namespace) > 0 {
+			parts = []string{parts[0], "rolebinding", namespace}
+		}
+		name := strings.Join(append(parts, accessor.GetName()), "-")
+		out, err := os.OpenFile(filepath.Join(b.dir, name+".yaml"), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		return printers.NewTypeSetter(scheme.Scheme).ToPrinter(&printers.YAMLPrinter{}).PrintObj(obj, out)
+	}
+
+	if b.out == nil {
+		if b.out, err = os.OpenFile(b.file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600); err != nil {
+			return err
+		}
+		b.printer = printers.NewTypeSetter(scheme.Scheme).ToPrinter(&printers.YAMLPrinter{})
+	}
+	return b.printer.PrintObj(obj, b.out)
+}
+
+// Close closes the backup file, if any.
+func (b *bindingBackup) Close() error {
+	if b == nil || b.out == nil {
+		return nil
+	}
+	return b.out.Close()
+}
+
+// restoreSubjects returns the subjects recorded as removed on a binding read from a backup.
+func restoreSubjects(obj metav1.Object) ([]rbacv1.Subject, error) {
+	value, ok := obj.GetAnnotations()[restoreSubjectsAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("missing %s annotation", restoreSubjectsAnnotation)
+	}
+	subjects := []rbacv1.Subject{}
+	if err := json.Unmarshal([]byte(value), &subjects); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", restoreSubjectsAnnotation, err)
+	}
+	return subjects, nil
+}
@@ -0,0 +1,279 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/workqueue"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+)
+
+var (
+	canILong = templates.LongDesc(`
+		Check whether a user can perform actions
+
+		Every combination of the given verbs and resources is checked with a subject access review for the user,
+		the given groups, the groups the user is a member of and the groups every authenticated user gets:
+		system:authenticated, system:authenticated:oauth for users logged in through OAuth and, for service
+		accounts, the groups of their namespace. Resources are given as
+		RESOURCE[.GROUP][/SUBRESOURCE]. With --list-namespaces the check is repeated in every namespace and the
+		answers are printed as a matrix, one namespace per row.
+	`)
+
+	canIExample = templates.Examples(`
+		# Check whether user1 can create deployments in the current project
+		oc adm policy can-i create deployments.apps --user=user1
+
+		# List the namespaces in which the builder service account of the current project can get and list secrets
+		oc adm policy can-i get,list secrets -z builder --list-namespaces
+	`)
+)
+
+// canIWorkers is the number of subject access reviews run in parallel.
+const canIWorkers = 8
+
+// authenticatedOAuthGroup is the group of the users authenticated with an OAuth access token.
+const authenticatedOAuthGroup = "system:authenticated:oauth"
+
+type CanIOptions struct {
+	Verbs     []string
+	Resources []string
+
+	User           string
+	Groups         []string
+	ServiceAccount string
+
+	Namespace      string
+	ListNamespaces bool
+
+	SARClient       authorizationv1client.SubjectAccessReviewInterface
+	NamespaceClient corev1client.NamespaceInterface
+	UserClient      userv1client.UserV1Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewCanIOptions(streams genericclioptions.IOStreams) *CanIOptions {
+	return &CanIOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdCanI implements the OpenShift cli can-i command
+func NewCmdCanI(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCanIOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "can-i VERB[,VERB...] RESOURCE[,RESOURCE...] (--user=USER | -z SERVICEACCOUNT)",
+		Short:   "Check whether a user can perform actions",
+		Long:    canILong,
+		Example: canIExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.User, "user", o.User, "User to check.")
+	cmd.Flags().StringSliceVar(&o.Groups, "groups", o.Groups, "Groups the user is a member of.")
+	cmd.Flags().StringVarP(&o.ServiceAccount, "serviceaccount", "z", o.ServiceAccount, "Service account in the current project to check.")
+	cmd.Flags().BoolVar(&o.ListNamespaces, "list-namespaces", o.ListNamespaces, "If true, check every namespace and print a matrix of the answers.")
+
+	return cmd
+}
+
+func (o *CanIOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return kcmdutil.UsageErrorf(cmd, "a verb and a resource are required")
+	}
+	o.Verbs = strings.Split(args[0], ",")
+	o.Resources = strings.Split(args[1], ",")
+
+	var err error
+	if o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+	if len(o.ServiceAccount) > 0 {
+		if len(o.User) > 0 {
+			return kcmdutil.UsageErrorf(cmd, "--user and --serviceaccount are mutually exclusive")
+		}
+		o.User = serviceaccount.MakeUsername(o.Namespace, o.ServiceAccount)
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	authorizationClient, err := authorizationv1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.SARClient = authorizationClient.SubjectAccessReviews()
+	coreClient, err := corev1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.NamespaceClient = coreClient.Namespaces()
+	o.UserClient, err = userv1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *CanIOptions) Validate() error {
+	if len(o.User) == 0 && len(o.Groups) == 0 {
+		return fmt.Errorf("one of --user, --groups or --serviceaccount is required")
+	}
+	for _, verb := range o.Verbs {
+		if len(verb) == 0 {
+			return fmt.Errorf("verbs must not be empty")
+		}
+	}
+	for _, resource := range o.Resources {
+		if len(resource) == 0 || strings.HasPrefix(resource, ".") || strings.HasPrefix(resource, "/") {
+			return fmt.Errorf("invalid resource %q, expected RESOURCE[.GROUP][/SUBRESOURCE]", resource)
+		}
+	}
+	return nil
+}
+
+// canIAction is a verb on a resource given as RESOURCE[.GROUP][/SUBRESOURCE].
+type canIAction struct {
+	verb     string
+	resource string
+}
+
+func (a canIAction) String() string {
+	return fmt.Sprintf("%s %s", a.verb, a.resource)
+}
+
+func (o *CanIOptions) Run() error {
+	namespaces := []string{o.Namespace}
+	if o.ListNamespaces {
+		list, err := o.NamespaceClient.List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		namespaces = []string{}
+		for _, namespace := range list.Items {
+			namespaces = append(namespaces, namespace.Name)
+		}
+		sort.Strings(namespaces)
+	}
+
+	actions := []canIAction{}
+	for _, verb := range o.Verbs {
+		for _, resource := range o.Resources {
+			actions = append(actions, canIAction{verb: verb, resource: resource})
+		}
+	}
+
+	groups, err := o.userGroups()
+	if err != nil {
+		return err
+	}
+
+	// every piece of work is one namespace and action, answers[i][j] is action j in namespace i
+	answers := make([][]string, len(namespaces))
+	for i := range answers {
+		answers[i] = make([]string, len(actions))
+	}
+	errs := make([]error, len(namespaces)*len(actions))
+	workqueue.ParallelizeUntil(context.TODO(), canIWorkers, len(errs), func(piece int) {
+		i, j := piece/len(actions), piece%len(actions)
+		allowed, err := o.review(namespaces[i], actions[j], groups)
+		if err != nil {
+			errs[piece] = fmt.Errorf("unable to check %s in namespace %s: %v", actions[j], namespaces[i], err)
+			answers[i][j] = "?"
+			return
+		}
+		answers[i][j] = map[bool]string{true: "yes", false: "no"}[allowed]
+	})
+
+	w := printers.GetNewTabWriter(o.Out)
+	fmt.Fprintf(w, "NAMESPACE")
+	for _, action := range actions {
+		fmt.Fprintf(w, "\t%s", strings.ToUpper(action.String()))
+	}
+	fmt.Fprintln(w)
+	for i, namespace := range namespaces {
+		fmt.Fprintf(w, "%s\t%s\n", namespace, strings.Join(answers[i], "\t"))
+	}
+	w.Flush()
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// userGroups returns the groups the user gets when authenticated: the given groups, the groups it is a member of
+// and the implicit groups of its kind of user.
+func (o *CanIOptions) userGroups() ([]string, error) {
+	groups := sets.NewString(o.Groups...)
+	if len(o.User) == 0 {
+		return groups.List(), nil
+	}
+	groups.Insert(user.AllAuthenticated)
+	if saNamespace, _, err := serviceaccount.SplitUsername(o.User); err == nil {
+		groups.Insert(serviceaccount.MakeGroupNames(saNamespace)...)
+		return groups.List(), nil
+	}
+	// the users of the system, like system:admin, authenticate with certificates rather than OAuth
+	if !strings.HasPrefix(o.User, "system:") {
+		groups.Insert(authenticatedOAuthGroup)
+	}
+	if o.UserClient == nil {
+		return groups.List(), nil
+	}
+	list, err := o.UserClient.Groups().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the groups of user %s: %v", o.User, err)
+	}
+	for _, group := range list.Items {
+		if sets.NewString(group.Users...).Has(o.User) {
+			groups.Insert(group.Name)
+		}
+	}
+	return groups.List(), nil
+}
+
+// review returns whether the user and groups may perform the action in the namespace.
+func (o *CanIOptions) review(namespace string, action canIAction, groups []string) (bool, error) {
+	resource, subresource := action.resource, ""
+	if parts := strings.SplitN(resource, "/", 2); len(parts) == 2 {
+		resource, subresource = parts[0], parts[1]
+	}
+	groupResource := schema.ParseGroupResource(resource)
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   o.User,
+			Groups: groups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        action.verb,
+				Group:       groupResource.Group,
+				Resource:    groupResource.Resource,
+				Subresource: subresource,
+			},
+		},
+	}
+	response, err := o.SARClient.Create(context.TODO(), sar, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return response.Status.Allowed, nil
+}
@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	userv1 "github.com/openshift/api/user/v1"
+	fakeuserclient "github.com/openshift/client-go/user/clientset/versioned/fake"
+)
+
+func TestCanIListNamespaces(t *testing.T) {
+	client := fakeclient.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ci"}},
+	)
+	// the builder service account may get anything in dev, and list deployments through the groups of its namespace
+	client.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		sar := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attrs := sar.Spec.ResourceAttributes
+		allowed := sar.Spec.User == "system:serviceaccount:dev:builder" && attrs.Namespace == "dev" && attrs.Verb == "get"
+		for _, group := range sar.Spec.Groups {
+			if group == "system:serviceaccounts:dev" && attrs.Verb == "list" && attrs.Group == "apps" && attrs.Resource == "deployments" {
+				allowed = true
+			}
+		}
+		sar.Status.Allowed = allowed
+		return true, sar, nil
+	})
+
+	tests := map[string]struct {
+		listNamespaces bool
+		expected       string
+	}{
+		"current namespace": {
+			expected: `NAMESPACE   GET PODS/LOG   GET DEPLOYMENTS.APPS   LIST PODS/LOG   LIST DEPLOYMENTS.APPS
+dev         yes            yes                    no              yes
+`,
+		},
+		"list namespaces": {
+			listNamespaces: true,
+			expected: `NAMESPACE   GET PODS/LOG   GET DEPLOYMENTS.APPS   LIST PODS/LOG   LIST DEPLOYMENTS.APPS
+ci          no             no                     no              yes
+dev         yes            yes                    no              yes
+prod        no             no                     no              yes
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &CanIOptions{
+				Verbs:           []string{"get", "list"},
+				Resources:       []string{"pods/log", "deployments.apps"},
+				User:            "system:serviceaccount:dev:builder",
+				Namespace:       "dev",
+				ListNamespaces:  tc.listNamespaces,
+				SARClient:       client.AuthorizationV1().SubjectAccessReviews(),
+				NamespaceClient: client.CoreV1().Namespaces(),
+				IOStreams:       genericclioptions.IOStreams{Out: out, ErrOut: out},
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestCanIUserGroups(t *testing.T) {
+	userClient := fakeuserclient.NewSimpleClientset(
+		&userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "developers"}, Users: []string{"alice", "bob"}},
+		&userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "admins"}, Users: []string{"bob"}},
+	)
+	tests := []struct {
+		name     string
+		user     string
+		groups   []string
+		expected []string
+	}{
+		{
+			name:     "user",
+			user:     "alice",
+			expected: []string{"developers", "system:authenticated", "system:authenticated:oauth"},
+		},
+		{
+			name:     "user with given groups",
+			user:     "bob",
+			groups:   []string{"testers"},
+			expected: []string{"admins", "developers", "system:authenticated", "system:authenticated:oauth", "testers"},
+		},
+		{
+			name:     "system user",
+			user:     "system:admin",
+			expected: []string{"system:authenticated"},
+		},
+		{
+			name:     "service account",
+			user:     "system:serviceaccount:dev:builder",
+			expected: []string{"system:authenticated", "system:serviceaccounts", "system:serviceaccounts:dev"},
+		},
+		{
+			name:     "groups only",
+			groups:   []string{"testers"},
+			expected: []string{"testers"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := &CanIOptions{User: test.user, Groups: test.groups, UserClient: userClient.UserV1()}
+			groups, err := o.userGroups()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(groups, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, groups)
+			}
+		})
+	}
+}
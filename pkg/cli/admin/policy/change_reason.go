@@ -0,0 +1,97 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/reference"
+	"k8s.io/kubectl/pkg/scheme"
+)
+
+const (
+	// changeReasonAnnotation holds the reason given with --reason for the last change to a binding.
+	changeReasonAnnotation = "oc.openshift.io/change-reason"
+	// changedByAnnotation holds the user who made the last change to a binding.
+	changedByAnnotation = "oc.openshift.io/changed-by"
+	// changedAtAnnotation holds the RFC3339 time of the last change to a binding.
+	changedAtAnnotation = "oc.openshift.io/changed-at"
+	// removedSubjectsAnnotation lists the subjects removed by the last change to a binding.
+	removedSubjectsAnnotation = "oc.openshift.io/removed-subjects"
+
+	subjectsRemovedEventReason = "SubjectsRemoved"
+)
+
+// bindingChange describes a modification to a role binding made on behalf of a user for a given reason.
+type bindingChange struct {
+	Actor   string
+	Reason  string
+	Time    time.Time
+	Removed []rbacv1.Subject
+}
+
+// annotate records the change on the binding itself.
+func (c *bindingChange) annotate(binding *roleBindingAbstraction) {
+	binding.SetAnnotation(changeReasonAnnotation, c.Reason)
+	binding.SetAnnotation(changedByAnnotation, c.Actor)
+	binding.SetAnnotation(changedAtAnnotation, c.Time.UTC().Format(time.RFC3339))
+	binding.SetAnnotation(removedSubjectsAnnotation, strings.Join(subjectNames(c.Removed), ","))
+}
+
+// recordEvent writes an event for the binding describing the change. Events for cluster role bindings are
+// written to the default namespace.
+func (c *bindingChange) recordEvent(client corev1client.EventsGetter, binding *roleBindingAbstraction) error {
+	ref, err := reference.GetReference(scheme.Scheme, binding.Object())
+	if err != nil {
+		return err
+	}
+	namespace := ref.Namespace
+	if len(namespace) == 0 {
+		namespace = metav1.NamespaceDefault
+	}
+
+	t := metav1.Time{Time: c.Time}
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%v.%x", ref.Name, t.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: *ref,
+		Reason:         subjectsRemovedEventReason,
+		Message: fmt.Sprintf("%s removed %s from %s %s: %s",
+			c.Actor, strings.Join(subjectNames(c.Removed), ", "), binding.Type(), binding.Name(), c.Reason),
+		Source: corev1.EventSource{
+			Component: "oc",
+		},
+		FirstTimestamp: t,
+		LastTimestamp:  t,
+		Count:          1,
+		Type:           corev1.EventTypeNormal,
+	}
+	_, err = client.Events(namespace).Create(context.TODO(), event, metav1.CreateOptions{})
+	return err
+}
+
+// subjectNames returns a kind-qualified name for each subject, e.g. User/alice or ServiceAccount/ns/builder.
+func subjectNames(subjects []rbacv1.Subject) []string {
+	names := []string{}
+	for _, subject := range subjects {
+		if len(subject.Namespace) > 0 {
+			names = append(names, fmt.Sprintf("%s/%s/%s", subject.Kind, subject.Namespace, subject.Name))
+			continue
+		}
+		names = append(names, fmt.Sprintf("%s/%s", subject.Kind, subject.Name))
+	}
+	return names
+}
+
+// subtractSubjects returns the subjects in haystack which are not in needles.
+func subtractSubjects(haystack, needles []rbacv1.Subject) []rbacv1.Subject {
+	remaining, _ := removeSubjects(haystack, needles)
+	return remaining
+}
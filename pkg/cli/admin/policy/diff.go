@@ -0,0 +1,229 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	diffLong = templates.LongDesc(`
+		Compare the role bindings of two projects
+
+		Every role binding grants its role to its subjects. This command collects these grants in two projects, or
+		in a project and a file of role bindings, and reports the ones that are present on only one side. Bindings
+		are compared by role and subject, not by name, and service accounts of the project itself are compared by
+		name only, so that project1's builder service account matches project2's.
+	`)
+
+	diffExample = templates.Examples(`
+		# Compare the role bindings of the current project with those of project stage
+		oc adm policy diff --to stage
+
+		# Compare the role bindings of project dev with those of project prod
+		oc adm policy diff --from dev --to prod -o json
+
+		# Compare the role bindings of the current project with the role bindings in a file
+		oc adm policy diff -f rolebindings.yaml
+	`)
+)
+
+type DiffOptions struct {
+	FromNamespace string
+	ToNamespace   string
+	Filenames     []string
+	Output        string
+
+	RbacClient rbacv1client.RbacV1Interface
+
+	genericclioptions.IOStreams
+}
+
+// roleGrant is a role granted to a subject by a role binding.
+type roleGrant struct {
+	Role    string `json:"role"`
+	Subject string `json:"subject"`
+}
+
+// policyDiff holds the grants present on only one side of a comparison.
+type policyDiff struct {
+	From       string      `json:"from"`
+	To         string      `json:"to"`
+	OnlyInFrom []roleGrant `json:"onlyInFrom"`
+	OnlyInTo   []roleGrant `json:"onlyInTo"`
+}
+
+func NewDiffOptions(streams genericclioptions.IOStreams) *DiffOptions {
+	return &DiffOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdDiff implements the OpenShift cli diff command
+func NewCmdDiff(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewDiffOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "diff (--to PROJECT | -f FILENAME)",
+		Short:   "Compare the role bindings of two projects",
+		Long:    diffLong,
+		Example: diffExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.FromNamespace, "from", o.FromNamespace, "Project to compare from. Defaults to the current project.")
+	cmd.Flags().StringVar(&o.ToNamespace, "to", o.ToNamespace, "Project to compare to.")
+	cmd.Flags().StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames, "File of role bindings to compare to instead of a project.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+
+	return cmd
+}
+
+func (o *DiffOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	var err error
+	if len(o.FromNamespace) == 0 {
+		if o.FromNamespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+			return err
+		}
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.RbacClient, err = rbacv1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *DiffOptions) Validate() error {
+	switch {
+	case len(o.ToNamespace) > 0 && len(o.Filenames) > 0:
+		return fmt.Errorf("--to and --filename are mutually exclusive")
+	case len(o.ToNamespace) == 0 && len(o.Filenames) == 0:
+		return fmt.Errorf("one of --to or --filename is required")
+	}
+	if len(o.Output) > 0 && o.Output != "json" && o.Output != "yaml" {
+		return fmt.Errorf("invalid output format %q, must be one of json or yaml", o.Output)
+	}
+	return nil
+}
+
+func (o *DiffOptions) Run() error {
+	from, err := o.namespaceGrants(o.FromNamespace)
+	if err != nil {
+		return err
+	}
+
+	var to map[roleGrant]bool
+	diff := &policyDiff{From: o.FromNamespace, To: o.ToNamespace}
+	if len(o.Filenames) > 0 {
+		diff.To = strings.Join(o.Filenames, ",")
+		bindings := []*roleBindingAbstraction{}
+		for _, filename := range o.Filenames {
+			f, err := os.Open(filename)
+			if err != nil {
+				return err
+			}
+			read, err := readRoleBindings(f, nil)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("unable to read %s: %v", filename, err)
+			}
+			bindings = append(bindings, read...)
+		}
+		to = grantsFor(bindings)
+	} else if to, err = o.namespaceGrants(o.ToNamespace); err != nil {
+		return err
+	}
+
+	diff.OnlyInFrom = subtractGrants(from, to)
+	diff.OnlyInTo = subtractGrants(to, from)
+
+	if len(o.Output) > 0 {
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, diff)
+	}
+
+	if len(diff.OnlyInFrom) == 0 && len(diff.OnlyInTo) == 0 {
+		fmt.Fprintf(o.Out, "Role bindings in %s and %s grant the same roles.\n", diff.From, diff.To)
+		return nil
+	}
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	fmt.Fprintf(w, "ROLE\tSUBJECT\tONLY IN\n")
+	for _, grant := range diff.OnlyInFrom {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", grant.Role, grant.Subject, diff.From)
+	}
+	for _, grant := range diff.OnlyInTo {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", grant.Role, grant.Subject, diff.To)
+	}
+	return nil
+}
+
+func (o *DiffOptions) namespaceGrants(namespace string) (map[roleGrant]bool, error) {
+	roleBindings, err := o.RbacClient.RoleBindings(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	bindings := []*roleBindingAbstraction{}
+	for i := range roleBindings.Items {
+		bindings = append(bindings, &roleBindingAbstraction{rbacClient: o.RbacClient, roleBinding: &roleBindings.Items[i]})
+	}
+	return grantsFor(bindings), nil
+}
+
+// grantsFor returns the grants made by the role bindings. Service accounts in the namespace of their binding are
+// recorded without the namespace so that they compare equal across projects. Cluster role bindings are ignored.
+func grantsFor(bindings []*roleBindingAbstraction) map[roleGrant]bool {
+	grants := map[roleGrant]bool{}
+	for _, binding := range bindings {
+		if binding.roleBinding == nil {
+			continue
+		}
+		role := fmt.Sprintf("%s/%s", binding.RoleKind(), binding.RoleName())
+		for _, subject := range binding.Subjects() {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == binding.Namespace() {
+				subject.Namespace = ""
+			}
+			grants[roleGrant{Role: role, Subject: strings.Join(subjectNames([]rbacv1.Subject{subject}), "")}] = true
+		}
+	}
+	return grants
+}
+
+// subtractGrants returns the grants in a that are not in b, sorted by role and subject.
+func subtractGrants(a, b map[roleGrant]bool) []roleGrant {
+	ret := []roleGrant{}
+	for grant := range a {
+		if !b[grant] {
+			ret = append(ret, grant)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Role != ret[j].Role {
+			return ret[i].Role < ret[j].Role
+		}
+		return ret[i].Subject < ret[j].Subject
+	})
+	return ret
+}
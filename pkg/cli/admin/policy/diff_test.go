@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPolicyDiff(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	devs := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "devs"}
+
+	client := fakeclient.NewSimpleClientset(
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "dev"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+			Subjects:   []rbacv1.Subject{alice},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "dev"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+			Subjects:   []rbacv1.Subject{devs, {Kind: rbacv1.ServiceAccountKind, Namespace: "dev", Name: "builder"}},
+		},
+		// same grants under different binding names, except that alice is missing
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit-team", Namespace: "prod"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+			Subjects:   []rbacv1.Subject{devs, {Kind: rbacv1.ServiceAccountKind, Namespace: "prod", Name: "builder"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "view", Namespace: "prod"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "monitoring", Name: "prometheus"}},
+		},
+	).RbacV1()
+
+	file := filepath.Join(t.TempDir(), "rolebindings.yaml")
+	if err := os.WriteFile(file, []byte(`apiVersion: v1
+kind: List
+items:
+- apiVersion: rbac.authorization.k8s.io/v1
+  kind: RoleBinding
+  metadata:
+    name: admin
+    namespace: dev
+  roleRef:
+    apiGroup: rbac.authorization.k8s.io
+    kind: ClusterRole
+    name: admin
+  subjects:
+  - apiGroup: rbac.authorization.k8s.io
+    kind: User
+    name: alice
+`), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]struct {
+		to        string
+		filenames []string
+		output    string
+		expected  string
+	}{
+		"namespaces": {
+			to: "prod",
+			expected: `ROLE                SUBJECT                                ONLY IN
+ClusterRole/admin   User/alice                             dev
+ClusterRole/view    ServiceAccount/monitoring/prometheus   prod
+`,
+		},
+		"namespaces json": {
+			to:     "prod",
+			output: "json",
+			expected: `{
+  "from": "dev",
+  "to": "prod",
+  "onlyInFrom": [
+    {
+      "role": "ClusterRole/admin",
+      "subject": "User/alice"
+    }
+  ],
+  "onlyInTo": [
+    {
+      "role": "ClusterRole/view",
+      "subject": "ServiceAccount/monitoring/prometheus"
+    }
+  ]
+}
+`,
+		},
+		"file": {
+			filenames: []string{file},
+			expected: `ROLE               SUBJECT                  ONLY IN
+ClusterRole/edit   Group/devs               dev
+ClusterRole/edit   ServiceAccount/builder   dev
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &DiffOptions{
+				FromNamespace: "dev",
+				ToNamespace:   tc.to,
+				Filenames:     tc.filenames,
+				Output:        tc.output,
+				RbacClient:    client,
+				IOStreams:     genericclioptions.IOStreams{Out: out, ErrOut: out},
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
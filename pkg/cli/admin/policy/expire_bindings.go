@@ -0,0 +1,195 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+// expiresAtAnnotation holds the RFC3339 time after which a temporary grant made with --duration is removed.
+const expiresAtAnnotation = "oc.openshift.io/expires-at"
+
+var (
+	expireBindingsLong = templates.LongDesc(`
+		Remove temporary role bindings that have expired
+
+		Role bindings created with 'add-role-to-user --duration' carry the time they expire at. This command removes
+		the role bindings in the current project, or in all projects and the cluster role bindings with
+		--all-namespaces, whose expiry has passed. It is intended to be run periodically, for example from a cron job.
+
+		By default, the command performs a dry run making no changes. A --confirm flag is needed for changes to be
+		effective.
+	`)
+
+	expireBindingsExample = templates.Examples(`
+		# List the expired role bindings in the current project
+		oc adm policy expire-bindings
+
+		# Remove all expired role bindings and cluster role bindings
+		oc adm policy expire-bindings -A --confirm
+	`)
+)
+
+type ExpireBindingsOptions struct {
+	Confirm       bool
+	AllNamespaces bool
+	Namespace     string
+
+	// AuditLogFile, when set, receives a record of every removed binding on behalf of Actor.
+	AuditLogFile string
+	Actor        string
+
+	RbacClient rbacv1client.RbacV1Interface
+
+	// Now returns the current time and can be replaced in tests.
+	Now func() time.Time
+
+	genericclioptions.IOStreams
+}
+
+func NewExpireBindingsOptions(streams genericclioptions.IOStreams) *ExpireBindingsOptions {
+	return &ExpireBindingsOptions{
+		Now:       time.Now,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdExpireBindings implements the OpenShift cli expire-bindings command
+func NewCmdExpireBindings(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewExpireBindingsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "expire-bindings",
+		Short:   "Remove temporary role bindings that have expired",
+		Long:    expireBindingsLong,
+		Example: expireBindingsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, specify that expired bindings should be removed. Defaults to false, displaying what would be removed but not actually removing anything.")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, remove expired role bindings in all namespaces and expired cluster role bindings.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
+	return cmd
+}
+
+func (o *ExpireBindingsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	var err error
+	if o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.RbacClient, err = rbacv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.Actor, err = auditActor(clientConfig, o.AuditLogFile)
+	return err
+}
+
+func (o *ExpireBindingsOptions) Run() error {
+	roleBindings, err := o.RbacClient.RoleBindings(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	bindings := []*roleBindingAbstraction{}
+	for i := range roleBindings.Items {
+		bindings = append(bindings, &roleBindingAbstraction{rbacClient: o.RbacClient, roleBinding: &roleBindings.Items[i]})
+	}
+	if o.AllNamespaces {
+		clusterRoleBindings, err := o.RbacClient.ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range clusterRoleBindings.Items {
+			bindings = append(bindings, &roleBindingAbstraction{rbacClient: o.RbacClient, clusterRoleBinding: &clusterRoleBindings.Items[i]})
+		}
+	}
+	sort.SliceStable(bindings, func(i, j int) bool {
+		if bindings[i].Namespace() != bindings[j].Namespace() {
+			return bindings[i].Namespace() < bindings[j].Namespace()
+		}
+		return bindings[i].Name() < bindings[j].Name()
+	})
+
+	audit, err := newAuditLog(o.AuditLogFile, o.Actor, false)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	now := o.Now()
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+
+	expired := 0
+	errs := []error{}
+	for _, binding := range bindings {
+		value := binding.Annotation(expiresAtAnnotation)
+		if len(value) == 0 {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "Warning: ignoring %s %s with invalid %s annotation %q\n", binding.Type(), binding.Name(), expiresAtAnnotation, value)
+			continue
+		}
+		if expiresAt.After(now) {
+			continue
+		}
+
+		if o.Confirm {
+			if err := binding.Delete(); err != nil && !kapierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("unable to remove %s %s: %v", binding.Type(), binding.Name(), err))
+				continue
+			}
+			if err := audit.record("delete", binding, binding.Subjects()); err != nil {
+				return err
+			}
+		}
+
+		if expired == 0 {
+			fmt.Fprintf(w, "NAMESPACE\tBINDING\tROLE\tEXPIRED\n")
+		}
+		expired++
+		namespace := binding.Namespace()
+		if len(namespace) == 0 {
+			namespace = "<cluster>"
+		}
+		fmt.Fprintf(w, "%s\t%s/%s\t%s/%s\t%s ago\n", namespace, binding.Type(), binding.Name(), binding.RoleKind(), binding.RoleName(), duration.HumanDuration(now.Sub(expiresAt)))
+	}
+
+	switch {
+	case expired == 0:
+		fmt.Fprintln(o.ErrOut, "No expired bindings found.")
+	case !o.Confirm:
+		fmt.Fprintln(o.ErrOut, "Dry run enabled - no modifications will be made. Add --confirm to remove bindings")
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,111 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestExpireBindings(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiring := func(expiresAt string) map[string]string {
+		return map[string]string{expiresAtAnnotation: expiresAt}
+	}
+
+	tests := map[string]struct {
+		allNamespaces bool
+		confirm       bool
+
+		expectedOut       string
+		expectedRemaining []string
+	}{
+		"dry run": {
+			expectedOut: `NAMESPACE   BINDING                   ROLE                EXPIRED
+ns          rolebinding/admin-break   ClusterRole/admin   120m ago
+`,
+			expectedRemaining: []string{"admin-break", "edit", "invalid", "view-later"},
+		},
+		"confirm": {
+			confirm: true,
+			expectedOut: `NAMESPACE   BINDING                   ROLE                EXPIRED
+ns          rolebinding/admin-break   ClusterRole/admin   120m ago
+`,
+			expectedRemaining: []string{"edit", "invalid", "view-later"},
+		},
+		"all namespaces": {
+			allNamespaces: true,
+			confirm:       true,
+			expectedOut: `NAMESPACE   BINDING                          ROLE                        EXPIRED
+<cluster>   clusterrolebinding/break-glass   ClusterRole/cluster-admin   60s ago
+ns          rolebinding/admin-break          ClusterRole/admin           120m ago
+`,
+			expectedRemaining: []string{"edit", "invalid", "view-later"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset(
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin-break", Namespace: "ns", Annotations: expiring("2021-06-01T10:00:00Z")},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "view-later", Namespace: "ns", Annotations: expiring("2021-06-01T14:00:00Z")},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "ns", Annotations: expiring("tomorrow")},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "break-glass", Annotations: expiring("2021-06-01T11:59:00Z")},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "cluster-admin"},
+				},
+			)
+
+			namespace := "ns"
+			if tc.allNamespaces {
+				namespace = metav1.NamespaceAll
+			}
+			out := &bytes.Buffer{}
+			o := &ExpireBindingsOptions{
+				Confirm:       tc.confirm,
+				AllNamespaces: tc.allNamespaces,
+				Namespace:     namespace,
+				RbacClient:    client.RbacV1(),
+				Now:           func() time.Time { return now },
+				IOStreams:     genericclioptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expectedOut {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expectedOut, out.String())
+			}
+
+			bindings, err := client.RbacV1().RoleBindings("ns").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			remaining := []string{}
+			for _, binding := range bindings.Items {
+				remaining = append(remaining, binding.Name)
+			}
+			if !reflect.DeepEqual(remaining, tc.expectedRemaining) {
+				t.Errorf("expected remaining bindings %v, got %v", tc.expectedRemaining, remaining)
+			}
+		})
+	}
+}
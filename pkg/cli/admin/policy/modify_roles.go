@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -14,6 +15,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -24,6 +26,7 @@ import (
 
 	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
 	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+	"github.com/openshift/oc/pkg/helpers/project"
 )
 
 var (
@@ -33,6 +36,9 @@ var (
 
 		# Add the 'edit' role to serviceaccount1 for the current project
 		oc policy add-role-to-user edit -z serviceaccount1
+
+		# Add the 'admin' role to user1 for the current project for the next 4 hours
+		oc policy add-role-to-user admin user1 --duration=4h
 	`)
 
 	addRoleToUserLongDesc = templates.LongDesc(`
@@ -40,7 +46,9 @@ var (
 
 		This command allows you to grant a user access to specific resources and actions within the current project, by assigning them to a role. It creates or modifies a role binding referencing the specified role adding the user(s) or service account(s) to the list of subjects. The command does not require that the matching role or user/service account resources exist and will create the binding successfully even when the role or user/service account do not exist or when the user does not have access to view them.
 
-		If the --rolebinding-name argument is supplied, it will look for an existing role binding with that name. The role on the matching role binding MUST match the role name supplied to the command. If no role binding name is given, a default name will be used. When --role-namespace argument is specified as a non-empty value, it MUST match the current namespace. When role-namespace is specified, the role binding will reference a namespaced role. Otherwise, the role binding will reference a cluster role resource.
+		If the --rolebinding-name argument is supplied, it will look for an existing role binding with that name. The role on the matching role binding MUST match the role name supplied to the command. If no role binding name is given, the role name will be used, or if a binding with that name already exists, the role name suffixed with a hash of the role and subjects. Repeating the same command therefore always resolves to the same role binding. When --role-namespace argument is specified as a non-empty value, it MUST match the current namespace. When role-namespace is specified, the role binding will reference a namespaced role. Otherwise, the role binding will reference a cluster role resource.
+
+		If the --duration argument is supplied, the grant is temporary: the role binding is annotated with the time it expires at and is removed by 'oc adm policy expire-bindings' once that time has passed. A temporary grant is never merged into a permanent role binding.
 
 		To learn more, see information about RBAC and policy, or use the 'get' and 'describe' commands on the following resources: 'clusterroles', 'clusterrolebindings', 'roles', 'rolebindings', 'users', 'groups', and 'serviceaccounts'.
 	`)
@@ -50,7 +58,9 @@ var (
 
 		This command allows you to grant a group access to specific resources and actions within the current project, by assigning them to a role. It creates or modifies a role binding referencing the specified role adding the group(s) to the list of subjects. The command does not require that the matching role or group resources exist and will create the binding successfully even when the role or group do not exist or when the user does not have access to view them.
 
-		If the --rolebinding-name argument is supplied, it will look for an existing role binding with that name. The role on the matching role binding MUST match the role name supplied to the command. If no role binding name is given, a default name will be used. When --role-namespace argument is specified as a non-empty value, it MUST match the current namespace. When role-namespace is specified, the role binding will reference a namespaced role. Otherwise, the role binding will reference a cluster role resource.
+		If the --rolebinding-name argument is supplied, it will look for an existing role binding with that name. The role on the matching role binding MUST match the role name supplied to the command. If no role binding name is given, the role name will be used, or if a binding with that name already exists, the role name suffixed with a hash of the role and subjects. Repeating the same command therefore always resolves to the same role binding. When --role-namespace argument is specified as a non-empty value, it MUST match the current namespace. When role-namespace is specified, the role binding will reference a namespaced role. Otherwise, the role binding will reference a cluster role resource.
+
+		If the --duration argument is supplied, the grant is temporary: the role binding is annotated with the time it expires at and is removed by 'oc adm policy expire-bindings' once that time has passed. A temporary grant is never merged into a permanent role binding.
 
 		To learn more, see information about RBAC and policy, or use the 'get' and 'describe' commands on the following resources: 'clusterroles', 'clusterrolebindings', 'roles', 'rolebindings', 'users', 'groups', and 'serviceaccounts'.
 	`)
@@ -60,7 +70,7 @@ var (
 
 		This command allows you to grant a user access to specific resources and actions within the cluster, by assigning them to a role. It creates or modifies a cluster role binding referencing the specified cluster role, adding the user(s) or service account(s) to the list of subjects. This command does not require that the matching cluster role or user/service account resources exist and will create the binding successfully even when the role or user/service account do not exist or when the user does not have access to view them.
 
-		If the --rolebinding-name argument is supplied, it will look for an existing cluster role binding with that name. The role on the matching cluster role binding MUST match the role name supplied to the command. If no role binding name is given, a default name will be used.
+		If the --rolebinding-name argument is supplied, it will look for an existing cluster role binding with that name. The role on the matching cluster role binding MUST match the role name supplied to the command. If no role binding name is given, the role name will be used, or if a binding with that name already exists, the role name suffixed with a hash of the role and subjects. Repeating the same command therefore always resolves to the same role binding.
 
 		To learn more, see information about RBAC and policy, or use the 'get' and 'describe' commands on the following resources: 'clusterroles', 'clusterrolebindings', 'roles', 'rolebindings', 'users', 'groups', and 'serviceaccounts'.
 	`)
@@ -70,7 +80,7 @@ var (
 
 		This command creates or modifies a cluster role binding with the named cluster role by adding the named group(s) to the list of subjects. The command does not require the matching role or group resources exist and will create the binding successfully even when the role or group do not exist or when the user does not have access to view them.
 
-		If the --rolebinding-name argument is supplied, it will look for an existing cluster role binding with that name. The role on the matching cluster role binding MUST match the role name supplied to the command. If no rolebinding name is given, a default name will be used.
+		If the --rolebinding-name argument is supplied, it will look for an existing cluster role binding with that name. The role on the matching cluster role binding MUST match the role name supplied to the command. If no role binding name is given, the role name will be used, or if a binding with that name already exists, the role name suffixed with a hash of the role and subjects. Repeating the same command therefore always resolves to the same role binding.
 	`)
 )
 
@@ -90,6 +100,26 @@ type RoleModificationOptions struct {
 	UserClient           userv1client.UserV1Interface
 	ServiceAccountClient corev1client.ServiceAccountsGetter
 
+	// Duration, when set, makes an added grant temporary by stamping its binding with an expiry.
+	Duration time.Duration
+
+	// BackupDir and BackupFile, when set, receive the original version of every binding modified by RemoveRole.
+	BackupDir  string
+	BackupFile string
+
+	// Reason, when set, is recorded together with Actor on every binding modified by RemoveRole and in an event.
+	Reason      string
+	Actor       string
+	EventClient corev1client.EventsGetter
+
+	// AuditLogFile, when set, receives a record of every change to a binding.
+	AuditLogFile string
+
+	// ProtectedRoles and the protected annotation mark bindings that RemoveRole leaves unmodified unless
+	// OverrideProtection is set.
+	ProtectedRoles     []string
+	OverrideProtection bool
+
 	Targets  []string
 	Users    []string
 	Groups   []string
@@ -126,6 +156,9 @@ func NewCmdAddRoleToGroup(f kcmdutil.Factory, streams genericclioptions.IOStream
 	cmd.Flags().StringVar(&o.RoleBindingName, "rolebinding-name", o.RoleBindingName, "Name of the rolebinding to modify or create. If left empty creates a new rolebinding with a default name")
 	cmd.Flags().StringVar(&o.RoleNamespace, "role-namespace", o.RoleNamespace, "namespace where the role is located: empty means a role defined in cluster policy")
 
+	cmd.Flags().DurationVar(&o.Duration, "duration", o.Duration, "If set, the grant expires after this duration, e.g. 4h. Expired bindings are removed by 'oc adm policy expire-bindings'.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -155,6 +188,9 @@ func NewCmdAddRoleToUser(f kcmdutil.Factory, streams genericclioptions.IOStreams
 	cmd.Flags().StringVar(&o.RoleNamespace, "role-namespace", o.RoleNamespace, "namespace where the role is located: empty means a role defined in cluster policy")
 	cmd.Flags().StringSliceVarP(&o.SANames, "serviceaccount", "z", o.SANames, "service account in the current namespace to use as a user")
 
+	cmd.Flags().DurationVar(&o.Duration, "duration", o.Duration, "If set, the grant expires after this duration, e.g. 4h. Expired bindings are removed by 'oc adm policy expire-bindings'.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -177,6 +213,13 @@ func NewCmdRemoveRoleFromGroup(f kcmdutil.Factory, streams genericclioptions.IOS
 	cmd.Flags().StringVar(&o.RoleBindingName, "rolebinding-name", o.RoleBindingName, "Name of the rolebinding to modify. If left empty it will operate on all rolebindings")
 	cmd.Flags().StringVar(&o.RoleNamespace, "role-namespace", o.RoleNamespace, "namespace where the role is located: empty means a role defined in cluster policy")
 
+	cmd.Flags().StringVar(&o.Reason, "reason", o.Reason, "Reason for the change. If set, modified bindings are annotated with the reason, the current user and the removed subjects, and an event is recorded.")
+	cmd.Flags().StringVar(&o.BackupDir, "backup-dir", o.BackupDir, "If set, write the original version of every modified binding to a file in this directory. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringVar(&o.BackupFile, "backup-file", o.BackupFile, "If set, write the original version of every modified binding to this file. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringSliceVar(&o.ProtectedRoles, "protected-roles", o.ProtectedRoles, "Roles whose bindings must not be modified, in addition to bindings annotated with "+protectedAnnotation+"=true.")
+	cmd.Flags().BoolVar(&o.OverrideProtection, "override-protection", o.OverrideProtection, "If true, also modify protected bindings.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -201,6 +244,13 @@ func NewCmdRemoveRoleFromUser(f kcmdutil.Factory, streams genericclioptions.IOSt
 	cmd.Flags().StringVar(&o.RoleNamespace, "role-namespace", o.RoleNamespace, "namespace where the role is located: empty means a role defined in cluster policy")
 	cmd.Flags().StringSliceVarP(&o.SANames, "serviceaccount", "z", o.SANames, "service account in the current namespace to use as a user")
 
+	cmd.Flags().StringVar(&o.Reason, "reason", o.Reason, "Reason for the change. If set, modified bindings are annotated with the reason, the current user and the removed subjects, and an event is recorded.")
+	cmd.Flags().StringVar(&o.BackupDir, "backup-dir", o.BackupDir, "If set, write the original version of every modified binding to a file in this directory. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringVar(&o.BackupFile, "backup-file", o.BackupFile, "If set, write the original version of every modified binding to this file. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringSliceVar(&o.ProtectedRoles, "protected-roles", o.ProtectedRoles, "Roles whose bindings must not be modified, in addition to bindings annotated with "+protectedAnnotation+"=true.")
+	cmd.Flags().BoolVar(&o.OverrideProtection, "override-protection", o.OverrideProtection, "If true, also modify protected bindings.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -222,6 +272,9 @@ func NewCmdAddClusterRoleToGroup(f kcmdutil.Factory, streams genericclioptions.I
 
 	cmd.Flags().StringVar(&o.RoleBindingName, "rolebinding-name", o.RoleBindingName, "Name of the rolebinding to modify or create. If left empty creates a new rolebinding with a default name")
 
+	cmd.Flags().DurationVar(&o.Duration, "duration", o.Duration, "If set, the grant expires after this duration, e.g. 4h. Expired bindings are removed by 'oc adm policy expire-bindings'.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -245,6 +298,9 @@ func NewCmdAddClusterRoleToUser(f kcmdutil.Factory, streams genericclioptions.IO
 	cmd.Flags().StringVar(&o.RoleBindingName, "rolebinding-name", o.RoleBindingName, "Name of the rolebinding to modify or create. If left empty creates a new rolebindo.RoleBindingNameg with a default name")
 	cmd.Flags().StringSliceVarP(&o.SANames, "serviceaccount", "z", o.SANames, "service account in the current namespace to use o.SANamess a user")
 
+	cmd.Flags().DurationVar(&o.Duration, "duration", o.Duration, "If set, the grant expires after this duration, e.g. 4h. Expired bindings are removed by 'oc adm policy expire-bindings'.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -266,6 +322,13 @@ func NewCmdRemoveClusterRoleFromGroup(f kcmdutil.Factory, streams genericcliopti
 
 	cmd.Flags().StringVar(&o.RoleBindingName, "rolebinding-name", o.RoleBindingName, "Name of the rolebinding to modify. If left empty it will operate on all rolebindings")
 
+	cmd.Flags().StringVar(&o.Reason, "reason", o.Reason, "Reason for the change. If set, modified bindings are annotated with the reason, the current user and the removed subjects, and an event is recorded.")
+	cmd.Flags().StringVar(&o.BackupDir, "backup-dir", o.BackupDir, "If set, write the original version of every modified binding to a file in this directory. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringVar(&o.BackupFile, "backup-file", o.BackupFile, "If set, write the original version of every modified binding to this file. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringSliceVar(&o.ProtectedRoles, "protected-roles", o.ProtectedRoles, "Roles whose bindings must not be modified, in addition to bindings annotated with "+protectedAnnotation+"=true.")
+	cmd.Flags().BoolVar(&o.OverrideProtection, "override-protection", o.OverrideProtection, "If true, also modify protected bindings.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -289,6 +352,13 @@ func NewCmdRemoveClusterRoleFromUser(f kcmdutil.Factory, streams genericclioptio
 	cmd.Flags().StringVar(&o.RoleBindingName, "rolebinding-name", o.RoleBindingName, "Name of the rolebinding to modify. If left empty it will operate on all rolebindings")
 	cmd.Flags().StringSliceVarP(&o.SANames, "serviceaccount", "z", o.SANames, "service account in the current namespace to use as a user")
 
+	cmd.Flags().StringVar(&o.Reason, "reason", o.Reason, "Reason for the change. If set, modified bindings are annotated with the reason, the current user and the removed subjects, and an event is recorded.")
+	cmd.Flags().StringVar(&o.BackupDir, "backup-dir", o.BackupDir, "If set, write the original version of every modified binding to a file in this directory. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringVar(&o.BackupFile, "backup-file", o.BackupFile, "If set, write the original version of every modified binding to this file. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringSliceVar(&o.ProtectedRoles, "protected-roles", o.ProtectedRoles, "Roles whose bindings must not be modified, in addition to bindings annotated with "+protectedAnnotation+"=true.")
+	cmd.Flags().BoolVar(&o.OverrideProtection, "override-protection", o.OverrideProtection, "If true, also modify protected bindings.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -330,6 +400,23 @@ func (o *RoleModificationOptions) innerComplete(f kcmdutil.Factory, cmd *cobra.C
 		return err
 	}
 
+	if len(o.Reason) > 0 {
+		me, err := project.WhoAmI(clientConfig)
+		if err != nil {
+			return err
+		}
+		o.Actor = me.Name
+		o.EventClient, err = corev1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+	}
+	if len(o.Actor) == 0 {
+		if o.Actor, err = auditActor(clientConfig, o.AuditLogFile); err != nil {
+			return err
+		}
+	}
+
 	o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd)
 	if err != nil {
 		return err
@@ -416,8 +503,8 @@ func (o *RoleModificationOptions) Complete(f kcmdutil.Factory, cmd *cobra.Comman
 	return nil
 }
 
-func (o *RoleModificationOptions) getRoleBinding() (*roleBindingAbstraction, bool /* isUpdate */, error) {
-	roleBinding, err := getRoleBindingAbstraction(o.RbacClient, o.RoleBindingName, o.RoleBindingNamespace)
+func (o *RoleModificationOptions) getRoleBinding(name string) (*roleBindingAbstraction, bool /* isUpdate */, error) {
+	roleBinding, err := getRoleBindingAbstraction(o.RbacClient, name, o.RoleBindingNamespace)
 	if err != nil {
 		if kapierrors.IsNotFound(err) {
 			return nil, false, nil
@@ -428,17 +515,17 @@ func (o *RoleModificationOptions) getRoleBinding() (*roleBindingAbstraction, boo
 	// Check that we update the rolebinding for the intended role.
 	if roleBinding.RoleName() != o.RoleName {
 		return nil, false, fmt.Errorf("rolebinding %s found for role %s, not %s",
-			o.RoleBindingName, roleBinding.RoleName(), o.RoleName)
+			name, roleBinding.RoleName(), o.RoleName)
 	}
 	if roleBinding.RoleKind() != o.RoleKind {
 		return nil, false, fmt.Errorf("rolebinding %s found for %q, not %q",
-			o.RoleBindingName, roleBinding.RoleKind(), o.RoleKind)
+			name, roleBinding.RoleKind(), o.RoleKind)
 	}
 
 	return roleBinding, true, nil
 }
 
-func (o *RoleModificationOptions) newRoleBinding() (*roleBindingAbstraction, error) {
+func (o *RoleModificationOptions) newRoleBinding() (*roleBindingAbstraction, bool /* isUpdate */, error) {
 	var roleBindingName string
 
 	// Create a new rolebinding with the desired name.
@@ -447,16 +534,29 @@ func (o *RoleModificationOptions) newRoleBinding() (*roleBindingAbstraction, err
 	} else {
 		// If unspecified will always use the default naming
 		var err error
-		roleBindingName, err = getUniqueName(o.RbacClient, o.RoleName, o.RoleBindingNamespace)
+		subjects := addSubjects(o.Users, o.Groups, o.Subjects, nil)
+		roleBindingName, err = getUniqueName(o.RbacClient, o.RoleName, o.RoleBindingNamespace, o.RoleKind, subjects)
 		if err != nil {
-			return nil, err
+			return nil, false, err
+		}
+
+		// The generated name is deterministic, so a previous run may have already created it.
+		// Reuse it as long as it references the same role.
+		if roleBindingName != o.RoleName {
+			roleBinding, isUpdate, err := o.getRoleBinding(roleBindingName)
+			if err != nil {
+				return nil, false, err
+			}
+			if roleBinding != nil {
+				return roleBinding, isUpdate, nil
+			}
 		}
 	}
 	roleBinding, err := newRoleBindingAbstraction(o.RbacClient, roleBindingName, o.RoleBindingNamespace, o.RoleName, o.RoleKind)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
-	return roleBinding, nil
+	return roleBinding, false, nil
 }
 
 func (o *RoleModificationOptions) AddRole() error {
@@ -466,6 +566,10 @@ func (o *RoleModificationOptions) AddRole() error {
 		err         error
 	)
 
+	if o.Duration < 0 {
+		return fmt.Errorf("--duration must not be negative")
+	}
+
 	p, err := o.ToPrinter("added")
 	if err != nil {
 		return err
@@ -475,11 +579,11 @@ func (o *RoleModificationOptions) AddRole() error {
 
 	// Look for an existing rolebinding by name.
 	if len(o.RoleBindingName) > 0 {
-		roleBinding, isUpdate, err = o.getRoleBinding()
+		roleBinding, isUpdate, err = o.getRoleBinding(o.RoleBindingName)
 		if err != nil {
 			return err
 		}
-	} else {
+	} else if o.Duration == 0 {
 		// Check if we already have a role binding that matches
 		checkBindings, err := getRoleBindingAbstractionsForRole(o.RbacClient, o.RoleName, o.RoleKind, o.RoleBindingNamespace)
 		if err != nil {
@@ -487,6 +591,10 @@ func (o *RoleModificationOptions) AddRole() error {
 		}
 		if len(checkBindings) > 0 {
 			for _, checkBinding := range checkBindings {
+				// a temporary grant does not satisfy a permanent one
+				if len(checkBinding.Annotation(expiresAtAnnotation)) > 0 {
+					continue
+				}
 				newSubjects := addSubjects(o.Users, o.Groups, o.Subjects, checkBinding.Subjects())
 				if len(newSubjects) == len(checkBinding.Subjects()) {
 					// we already have a rolebinding that matches
@@ -500,12 +608,25 @@ func (o *RoleModificationOptions) AddRole() error {
 	}
 
 	if roleBinding == nil {
-		roleBinding, err = o.newRoleBinding()
+		roleBinding, isUpdate, err = o.newRoleBinding()
 		if err != nil {
 			return err
 		}
 	}
 
+	if o.Duration > 0 {
+		if isUpdate && len(roleBinding.Annotation(expiresAtAnnotation)) == 0 {
+			return fmt.Errorf("%s %s is a permanent grant, use a different --rolebinding-name for a temporary grant", roleBinding.Type(), roleBinding.Name())
+		}
+		roleBinding.SetAnnotation(expiresAtAnnotation, time.Now().Add(o.Duration).UTC().Format(time.RFC3339))
+	} else if isUpdate && len(roleBinding.Annotation(expiresAtAnnotation)) > 0 {
+		if len(o.RoleBindingName) > 0 {
+			return fmt.Errorf("%s %s is a temporary grant, use a different --rolebinding-name for a permanent grant", roleBinding.Type(), roleBinding.Name())
+		}
+		// The generated name resolved to an earlier temporary grant of the same subjects, make it permanent.
+		roleBinding.RemoveAnnotation(expiresAtAnnotation)
+	}
+
 	// warn if binding to non-existent role
 	if o.PrintErrf != nil {
 		var err error
@@ -546,7 +667,20 @@ func (o *RoleModificationOptions) AddRole() error {
 	}
 	roleBinding.SetSubjects(newSubjects)
 
+	audit, err := newAuditLog(o.AuditLogFile, o.Actor, o.DryRunStrategy == kcmdutil.DryRunClient)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+	operation := "create"
+	if isUpdate {
+		operation = "update"
+	}
+
 	if o.DryRunStrategy == kcmdutil.DryRunClient {
+		if err := audit.record(operation, roleBinding, existingSubjects); err != nil {
+			return err
+		}
 		return p.PrintObj(roleBinding.Object(), o.Out)
 	}
 
@@ -562,6 +696,9 @@ func (o *RoleModificationOptions) AddRole() error {
 	if err != nil {
 		return err
 	}
+	if err := audit.record(operation, roleBinding, existingSubjects); err != nil {
+		return err
+	}
 
 	return p.PrintObj(roleToPrint, o.Out)
 }
@@ -659,15 +796,41 @@ func (o *RoleModificationOptions) RemoveRole() error {
 	subjectsToRemove = append(subjectsToRemove, o.Subjects...)
 
 	var bindingsToUpdate []*roleBindingAbstraction
+	var befores [][]rbacv1.Subject
+	var originals []runtime.Object
+	var removals [][]rbacv1.Subject
+	var changes []*bindingChange
+	var protectionErrs []error
 	for _, roleBinding := range roleBindings {
-		resultingSubjects, removed := removeSubjects(roleBinding.Subjects(), subjectsToRemove)
+		originalSubjects := roleBinding.Subjects()
+		original := roleBinding.Object().DeepCopyObject()
+		resultingSubjects, removed := removeSubjects(originalSubjects, subjectsToRemove)
+		if removed > 0 && !o.OverrideProtection {
+			if reason := bindingProtection(roleBinding, o.ProtectedRoles); len(reason) > 0 {
+				protectionErrs = append(protectionErrs, protectionError(roleBinding, reason))
+				continue
+			}
+		}
 		roleBinding.SetSubjects(resultingSubjects)
 		if removed > 0 {
+			removedSubjects := subtractSubjects(originalSubjects, resultingSubjects)
+			var change *bindingChange
+			if len(o.Reason) > 0 {
+				change = &bindingChange{Actor: o.Actor, Reason: o.Reason, Time: time.Now(), Removed: removedSubjects}
+				change.annotate(roleBinding)
+			}
 			bindingsToUpdate = append(bindingsToUpdate, roleBinding)
+			befores = append(befores, originalSubjects)
+			originals = append(originals, original)
+			removals = append(removals, removedSubjects)
+			changes = append(changes, change)
 		}
 	}
 
 	if len(bindingsToUpdate) == 0 {
+		if len(protectionErrs) > 0 {
+			return utilerrors.NewAggregate(protectionErrs)
+		}
 		return fmt.Errorf("unable to find target %v", o.Targets)
 	}
 
@@ -692,16 +855,51 @@ func (o *RoleModificationOptions) RemoveRole() error {
 			updatedBindings.Items = append(updatedBindings.Items, unstructured.Unstructured{Object: obj})
 		}
 
-		return p.PrintObj(updatedBindings, o.Out)
+		if err := p.PrintObj(updatedBindings, o.Out); err != nil {
+			return err
+		}
+		return utilerrors.NewAggregate(protectionErrs)
 	}
 
+	// bindings that are left without subjects are deleted unless they must not be reconciled
+	operation := func(roleBinding *roleBindingAbstraction) string {
+		if len(roleBinding.Subjects()) > 0 || roleBinding.Annotation(rbacv1.AutoUpdateAnnotationKey) == "false" {
+			return "update"
+		}
+		return "delete"
+	}
+	audit, err := newAuditLog(o.AuditLogFile, o.Actor, o.DryRunStrategy == kcmdutil.DryRunClient)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
 	roleToPrint := o.roleObjectToPrint()
 	if o.DryRunStrategy == kcmdutil.DryRunClient {
-		return p.PrintObj(roleToPrint, o.Out)
+		for i, roleBinding := range bindingsToUpdate {
+			if err := audit.record(operation(roleBinding), roleBinding, befores[i]); err != nil {
+				return err
+			}
+		}
+		if err := p.PrintObj(roleToPrint, o.Out); err != nil {
+			return err
+		}
+		return utilerrors.NewAggregate(protectionErrs)
 	}
 
-	for _, roleBinding := range bindingsToUpdate {
-		if len(roleBinding.Subjects()) > 0 || roleBinding.Annotation(rbacv1.AutoUpdateAnnotationKey) == "false" {
+	backup, err := newBindingBackup(o.BackupDir, o.BackupFile)
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	for i, roleBinding := range bindingsToUpdate {
+		if backup != nil {
+			if err := backup.save(originals[i], removals[i]); err != nil {
+				return fmt.Errorf("unable to back up %s %s: %v", roleBinding.Type(), roleBinding.Name(), err)
+			}
+		}
+		if operation(roleBinding) == "update" {
 			err = roleBinding.Update()
 		} else {
 			err = roleBinding.Delete()
@@ -709,10 +907,21 @@ func (o *RoleModificationOptions) RemoveRole() error {
 		if err != nil {
 			return err
 		}
+		if err := audit.record(operation(roleBinding), roleBinding, befores[i]); err != nil {
+			return err
+		}
 		o.checkRolebindingAutoupdate(roleBinding)
+		if changes[i] != nil && o.EventClient != nil {
+			if err := changes[i].recordEvent(o.EventClient, roleBinding); err != nil && o.PrintErrf != nil {
+				o.PrintErrf("Warning: unable to record event for %s %s: %v\n", roleBinding.Type(), roleBinding.Name(), err)
+			}
+		}
 	}
 
-	return p.PrintObj(roleToPrint, o.Out)
+	if err := p.PrintObj(roleToPrint, o.Out); err != nil {
+		return err
+	}
+	return utilerrors.NewAggregate(protectionErrs)
 }
 
 func removeSubjects(haystack, needles []rbacv1.Subject) ([]rbacv1.Subject, int) {
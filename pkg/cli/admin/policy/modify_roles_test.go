@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	diffutil "k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
@@ -165,14 +167,14 @@ func TestModifyNamedClusterRoleBinding(t *testing.T) {
 			},
 			expectedRoleBindingList: []string{"custom", "edit"},
 		},
-		// no name provided - creates "edit-0"
+		// no name provided - creates "edit-<hash>"
 		"update-default-clusterrolebinding": {
 			action:    "add",
 			inputRole: "edit",
 			inputSubjects: []string{
 				"baz",
 			},
-			expectedRoleBindingName: "edit-0",
+			expectedRoleBindingName: "edit-5f66d687c4",
 			expectedSubjects: []rbacv1.Subject{{
 				APIGroup: rbacv1.GroupName,
 				Name:     "baz",
@@ -206,7 +208,54 @@ func TestModifyNamedClusterRoleBinding(t *testing.T) {
 					}},
 				},
 			},
-			expectedRoleBindingList: []string{"custom", "edit", "edit-0"},
+			expectedRoleBindingList: []string{"custom", "edit", "edit-5f66d687c4"},
+		},
+		// no name provided - reuses the existing "edit-<hash>"
+		"reuse-hashed-clusterrolebinding": {
+			action:    "add",
+			inputRole: "edit",
+			inputSubjects: []string{
+				"baz",
+			},
+			expectedRoleBindingName: "edit-5f66d687c4",
+			expectedSubjects: []rbacv1.Subject{{
+				APIGroup: rbacv1.GroupName,
+				Name:     "qux",
+				Kind:     rbacv1.UserKind,
+			}, {
+				APIGroup: rbacv1.GroupName,
+				Name:     "baz",
+				Kind:     rbacv1.UserKind,
+			}},
+			existingClusterRoleBindings: &rbacv1.ClusterRoleBindingList{
+				Items: []rbacv1.ClusterRoleBinding{{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "edit",
+					},
+					Subjects: []rbacv1.Subject{{
+						APIGroup: rbacv1.GroupName,
+						Name:     "foo",
+						Kind:     rbacv1.UserKind,
+					}},
+					RoleRef: rbacv1.RoleRef{
+						Name: "edit",
+						Kind: "ClusterRole",
+					}}, {
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "edit-5f66d687c4",
+					},
+					Subjects: []rbacv1.Subject{{
+						APIGroup: rbacv1.GroupName,
+						Name:     "qux",
+						Kind:     rbacv1.UserKind,
+					}},
+					RoleRef: rbacv1.RoleRef{
+						Name: "edit",
+						Kind: "ClusterRole",
+					}},
+				},
+			},
+			expectedRoleBindingList: []string{"edit", "edit-5f66d687c4"},
 		},
 		// no name provided - removes "baz"
 		"remove-default-clusterrolebinding": {
@@ -385,7 +434,7 @@ func TestModifyNamedLocalRoleBinding(t *testing.T) {
 			inputSubjects: []string{
 				"baz",
 			},
-			expectedRoleBindingName: "edit-0",
+			expectedRoleBindingName: "edit-bb7cbd8c8",
 			expectedSubjects: []rbacv1.Subject{{
 				APIGroup: rbacv1.GroupName,
 				Name:     "baz",
@@ -421,7 +470,7 @@ func TestModifyNamedLocalRoleBinding(t *testing.T) {
 					}},
 				},
 			},
-			expectedRoleBindingList: []string{"custom", "edit", "edit-0"},
+			expectedRoleBindingList: []string{"custom", "edit", "edit-bb7cbd8c8"},
 		},
 		// no name provided - remove "bar"
 		"remove-default-binding": {
@@ -589,6 +638,49 @@ func TestModifyNamedLocalRoleBinding(t *testing.T) {
 		modifyRoleAndCheck(t, o, tcName, tc.action, tc.expectedRoleBindingName, tc.expectedSubjects, tc.expectedRoleBindingList)
 	}
 }
+func TestAddRoleHashedNameConflict(t *testing.T) {
+	existing := &rbacv1.ClusterRoleBindingList{
+		Items: []rbacv1.ClusterRoleBinding{{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit"},
+			RoleRef:    rbacv1.RoleRef{Name: "edit", Kind: "ClusterRole"},
+		}, {
+			ObjectMeta: metav1.ObjectMeta{Name: "edit-5f66d687c4"},
+			RoleRef:    rbacv1.RoleRef{Name: "view", Kind: "ClusterRole"},
+		}},
+	}
+	o := &RoleModificationOptions{
+		RoleName:   "edit",
+		RoleKind:   "ClusterRole",
+		Users:      []string{"baz"},
+		RbacClient: fakeclient.NewSimpleClientset(existing).RbacV1(),
+		PrintFlags: genericclioptions.NewPrintFlags(""),
+		ToPrinter:  func(string) (printers.ResourcePrinter, error) { return printers.NewDiscardingPrinter(), nil },
+	}
+
+	err := o.AddRole()
+	if err == nil {
+		t.Fatalf("expected an error for a generated name bound to a different role")
+	}
+	if expected := "rolebinding edit-5f66d687c4 found for role view, not edit"; err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestComputeRoleBindingHash(t *testing.T) {
+	foo := rbacv1.Subject{Kind: rbacv1.UserKind, Name: "foo"}
+	bar := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "bar"}
+
+	if a, b := computeRoleBindingHash("edit", "ClusterRole", []rbacv1.Subject{foo, bar}), computeRoleBindingHash("edit", "ClusterRole", []rbacv1.Subject{bar, foo}); a != b {
+		t.Errorf("expected hash to be independent of subject order, got %q and %q", a, b)
+	}
+	if a, b := computeRoleBindingHash("edit", "ClusterRole", []rbacv1.Subject{foo}), computeRoleBindingHash("edit", "Role", []rbacv1.Subject{foo}); a == b {
+		t.Errorf("expected hash to depend on the role kind, got %q for both", a)
+	}
+	if a, b := computeRoleBindingHash("edit", "ClusterRole", []rbacv1.Subject{foo}), computeRoleBindingHash("view", "ClusterRole", []rbacv1.Subject{foo}); a == b {
+		t.Errorf("expected hash to depend on the role name, got %q for both", a)
+	}
+}
+
 func TestModifyRoleBindingWarnings(t *testing.T) {
 	type clusterState struct {
 		roles               *rbacv1.RoleList
@@ -1359,3 +1451,164 @@ func modifyRoleAndCheck(t *testing.T, o *RoleModificationOptions, tcName, action
 		t.Errorf("%s: err expected bindings: %v, actual: %v", tcName, expectedBindings, foundBindings)
 	}
 }
+
+func TestAddRoleDuration(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	bob := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"}
+	hashedName := "admin-" + computeRoleBindingHash("admin", "ClusterRole", []rbacv1.Subject{alice})
+
+	tests := map[string]struct {
+		existing []runtime.Object
+
+		expectedErr      string
+		expectedBindings int
+	}{
+		"separate from permanent binding": {
+			existing: []runtime.Object{&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "ns"},
+				RoleRef:    rbacv1.RoleRef{Name: "admin", Kind: "ClusterRole"},
+				Subjects:   []rbacv1.Subject{alice},
+			}},
+			expectedBindings: 2,
+		},
+		"refresh temporary binding": {
+			existing: []runtime.Object{&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "ns"},
+				RoleRef:    rbacv1.RoleRef{Name: "admin", Kind: "ClusterRole"},
+				Subjects:   []rbacv1.Subject{bob},
+			}, &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: hashedName, Namespace: "ns", Annotations: map[string]string{expiresAtAnnotation: "2000-01-01T00:00:00Z"}},
+				RoleRef:    rbacv1.RoleRef{Name: "admin", Kind: "ClusterRole"},
+				Subjects:   []rbacv1.Subject{alice},
+			}},
+			expectedBindings: 2,
+		},
+		"refuse permanent binding": {
+			existing: []runtime.Object{&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "ns"},
+				RoleRef:    rbacv1.RoleRef{Name: "admin", Kind: "ClusterRole"},
+				Subjects:   []rbacv1.Subject{bob},
+			}, &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: hashedName, Namespace: "ns"},
+				RoleRef:    rbacv1.RoleRef{Name: "admin", Kind: "ClusterRole"},
+				Subjects:   []rbacv1.Subject{alice},
+			}},
+			expectedErr: fmt.Sprintf("rolebinding %s is a permanent grant, use a different --rolebinding-name for a temporary grant", hashedName),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset(tc.existing...)
+			o := &RoleModificationOptions{
+				RoleName:             "admin",
+				RoleKind:             "ClusterRole",
+				RoleBindingNamespace: "ns",
+				Users:                []string{"alice"},
+				Duration:             4 * time.Hour,
+				RbacClient:           client.RbacV1(),
+				PrintFlags:           genericclioptions.NewPrintFlags(""),
+				ToPrinter:            func(string) (printers.ResourcePrinter, error) { return printers.NewDiscardingPrinter(), nil },
+			}
+
+			before := time.Now().Truncate(time.Second)
+			err := o.AddRole()
+			if len(tc.expectedErr) > 0 {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			bindings, err := client.RbacV1().RoleBindings("ns").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(bindings.Items) != tc.expectedBindings {
+				t.Fatalf("expected %d bindings, got %#v", tc.expectedBindings, bindings.Items)
+			}
+			binding, err := client.RbacV1().RoleBindings("ns").Get(context.TODO(), hashedName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expiresAt, err := time.Parse(time.RFC3339, binding.Annotations[expiresAtAnnotation])
+			if err != nil {
+				t.Fatalf("unexpected expiry annotation: %v", err)
+			}
+			if expiresAt.Before(before.Add(4*time.Hour)) || expiresAt.After(time.Now().Add(4*time.Hour)) {
+				t.Errorf("expected expiry 4h from now, got %v", expiresAt)
+			}
+			if !reflect.DeepEqual(binding.Subjects, []rbacv1.Subject{alice}) {
+				t.Errorf("expected subjects %v, got %v", []rbacv1.Subject{alice}, binding.Subjects)
+			}
+		})
+	}
+}
+
+func TestAddRoleRefusesTemporaryBinding(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	client := fakeclient.NewSimpleClientset(&rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "oncall", Namespace: "ns", Annotations: map[string]string{expiresAtAnnotation: "2000-01-01T00:00:00Z"}},
+		RoleRef:    rbacv1.RoleRef{Name: "admin", Kind: "ClusterRole"},
+		Subjects:   []rbacv1.Subject{alice},
+	})
+	o := &RoleModificationOptions{
+		RoleName:             "admin",
+		RoleKind:             "ClusterRole",
+		RoleBindingName:      "oncall",
+		RoleBindingNamespace: "ns",
+		Users:                []string{"bob"},
+		RbacClient:           client.RbacV1(),
+		PrintFlags:           genericclioptions.NewPrintFlags(""),
+		ToPrinter:            func(string) (printers.ResourcePrinter, error) { return printers.NewDiscardingPrinter(), nil },
+	}
+
+	expectedErr := "rolebinding oncall is a temporary grant, use a different --rolebinding-name for a permanent grant"
+	if err := o.AddRole(); err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error %q, got %v", expectedErr, err)
+	}
+	binding, err := client.RbacV1().RoleBindings("ns").Get(context.TODO(), "oncall", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(binding.Subjects, []rbacv1.Subject{alice}) {
+		t.Errorf("expected subjects %v, got %v", []rbacv1.Subject{alice}, binding.Subjects)
+	}
+}
+
+func TestRemoveRoleProtected(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	client := fakeclient.NewSimpleClientset(&rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"},
+		RoleRef:    rbacv1.RoleRef{Name: "cluster-admin", Kind: "ClusterRole"},
+		Subjects:   []rbacv1.Subject{alice},
+	})
+	o := &RoleModificationOptions{
+		RoleName:       "cluster-admin",
+		RoleKind:       "ClusterRole",
+		Users:          []string{"alice"},
+		ProtectedRoles: []string{"cluster-admin"},
+		RbacClient:     client.RbacV1(),
+		PrintFlags:     genericclioptions.NewPrintFlags(""),
+		ToPrinter:      func(string) (printers.ResourcePrinter, error) { return printers.NewDiscardingPrinter(), nil },
+	}
+
+	expectedErr := "refusing to modify clusterrolebinding cluster-admin protected by protected role ClusterRole/cluster-admin, use --override-protection to modify it"
+	if err := o.RemoveRole(); err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error %q, got %v", expectedErr, err)
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Get(context.TODO(), "cluster-admin", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the protected binding to be kept: %v", err)
+	}
+
+	o.OverrideProtection = true
+	if err := o.RemoveRole(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.RbacV1().ClusterRoleBindings().Get(context.TODO(), "cluster-admin", metav1.GetOptions{}); !kapierrors.IsNotFound(err) {
+		t.Fatalf("expected the binding to be deleted, got %v", err)
+	}
+}
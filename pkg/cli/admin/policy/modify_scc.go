@@ -56,6 +56,10 @@ type SCCModificationOptions struct {
 	DryRunStrategy kcmdutil.DryRunStrategy
 	Output         string
 
+	// AuditLogFile, when set, receives a record of every change to a binding made on behalf of Actor.
+	AuditLogFile string
+	Actor        string
+
 	genericclioptions.IOStreams
 }
 
@@ -79,6 +83,7 @@ func NewCmdAddSCCToGroup(f kcmdutil.Factory, streams genericclioptions.IOStreams
 		},
 	}
 
+	addAuditLogFlag(cmd, &o.AuditLogFile)
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -100,6 +105,7 @@ func NewCmdAddSCCToUser(f kcmdutil.Factory, streams genericclioptions.IOStreams)
 
 	cmd.Flags().StringSliceVarP(&o.SANames, "serviceaccount", "z", o.SANames, "service account in the current namespace to use as a user")
 
+	addAuditLogFlag(cmd, &o.AuditLogFile)
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -117,6 +123,7 @@ func NewCmdRemoveSCCFromGroup(f kcmdutil.Factory, streams genericclioptions.IOSt
 		},
 	}
 
+	addAuditLogFlag(cmd, &o.AuditLogFile)
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -137,6 +144,7 @@ func NewCmdRemoveSCCFromUser(f kcmdutil.Factory, streams genericclioptions.IOStr
 
 	cmd.Flags().StringSliceVarP(&o.SANames, "serviceaccount", "z", o.SANames, "service account in the current namespace to use as a user")
 
+	addAuditLogFlag(cmd, &o.AuditLogFile)
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -175,6 +183,9 @@ func (o *SCCModificationOptions) CompleteUsers(f kcmdutil.Factory, cmd *cobra.Co
 	if err != nil {
 		return err
 	}
+	if o.Actor, err = auditActor(clientConfig, o.AuditLogFile); err != nil {
+		return err
+	}
 
 	o.DefaultSubjectNamespace, o.ExplicitNamespace, err = f.ToRawKubeConfigLoader().Namespace()
 	if err != nil {
@@ -218,6 +229,9 @@ func (o *SCCModificationOptions) CompleteGroups(f kcmdutil.Factory, cmd *cobra.C
 	if err != nil {
 		return err
 	}
+	if o.Actor, err = auditActor(clientConfig, o.AuditLogFile); err != nil {
+		return err
+	}
 
 	o.DefaultSubjectNamespace, _, err = f.ToRawKubeConfigLoader().Namespace()
 	if err != nil {
@@ -267,6 +281,8 @@ func (o *SCCModificationOptions) AddSCC() error {
 		PrintFlags:      o.PrintFlags,
 		ToPrinter:       o.ToPrinter,
 		DryRunStrategy:  o.DryRunStrategy,
+		AuditLogFile:    o.AuditLogFile,
+		Actor:           o.Actor,
 		IOStreams:       o.IOStreams,
 	}
 	if o.ExplicitNamespace {
@@ -286,6 +302,8 @@ func (o *SCCModificationOptions) RemoveSCC() error {
 		PrintFlags:      o.PrintFlags,
 		ToPrinter:       o.ToPrinter,
 		DryRunStrategy:  o.DryRunStrategy,
+		AuditLogFile:    o.AuditLogFile,
+		Actor:           o.Actor,
 		IOStreams:       o.IOStreams,
 	}
 	if o.ExplicitNamespace {
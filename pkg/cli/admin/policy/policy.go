@@ -3,14 +3,16 @@ package policy
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"sort"
 
 	"github.com/spf13/cobra"
 	rbacv1 "k8s.io/api/rbac/v1"
 	kapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -45,6 +47,9 @@ func NewCmdPolicy(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 				NewCmdWhoCan(f, streams),
 				NewCmdSccSubjectReview(f, streams, true),
 				NewCmdSccReview(f, streams, true),
+				NewCmdSimulate(f, streams),
+				NewCmdDiff(f, streams),
+				NewCmdCanI(f, streams),
 			},
 		},
 		{
@@ -52,6 +57,10 @@ func NewCmdPolicy(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 			Commands: []*cobra.Command{
 				NewCmdRemoveUserFromProject(f, streams),
 				NewCmdRemoveGroupFromProject(f, streams),
+				NewCmdPruneSubjects(f, streams),
+				NewCmdExpireBindings(f, streams),
+				NewCmdRestore(f, streams),
+				NewCmdApply(f, streams),
 			},
 		},
 		{
@@ -86,7 +95,10 @@ func NewCmdPolicy(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	return cmds
 }
 
-func getUniqueName(rbacClient rbacv1client.RbacV1Interface, basename string, namespace string) (string, error) {
+// getUniqueName returns basename when no binding of that name exists yet. Otherwise it returns basename
+// suffixed with a hash of the role reference and the subjects, so that repeating the same request always
+// resolves to the same binding rather than allocating a new numbered one.
+func getUniqueName(rbacClient rbacv1client.RbacV1Interface, basename string, namespace string, roleKind string, subjects []rbacv1.Subject) (string, error) {
 	existingNames := sets.String{}
 
 	if len(namespace) > 0 {
@@ -111,14 +123,24 @@ func getUniqueName(rbacClient rbacv1client.RbacV1Interface, basename string, nam
 		return basename, nil
 	}
 
-	for i := 0; i < 100; i++ {
-		trialName := fmt.Sprintf("%v-%d", basename, i)
-		if !existingNames.Has(trialName) {
-			return trialName, nil
-		}
+	return fmt.Sprintf("%s-%s", basename, computeRoleBindingHash(basename, roleKind, subjects)), nil
+}
+
+// computeRoleBindingHash returns a short, name-safe hash of the role reference and subjects. Subject order
+// does not affect the result.
+func computeRoleBindingHash(roleName string, roleKind string, subjects []rbacv1.Subject) string {
+	keys := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		keys = append(keys, fmt.Sprintf("%s/%s/%s", subject.Kind, subject.Namespace, subject.Name))
 	}
+	sort.Strings(keys)
 
-	return string(uuid.NewUUID()), nil
+	hasher := fnv.New32a()
+	fmt.Fprintf(hasher, "%s/%s", roleKind, roleName)
+	for _, key := range keys {
+		fmt.Fprintf(hasher, "\n%s", key)
+	}
+	return utilrand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
 }
 
 type roleBindingAbstraction struct {
@@ -222,6 +244,14 @@ func (r roleBindingAbstraction) Name() string {
 	}
 }
 
+func (r roleBindingAbstraction) Namespace() string {
+	if r.roleBinding != nil {
+		return r.roleBinding.Namespace
+	} else {
+		return ""
+	}
+}
+
 func (r roleBindingAbstraction) RoleName() string {
 	if r.roleBinding != nil {
 		return r.roleBinding.RoleRef.Name
@@ -246,6 +276,22 @@ func (r roleBindingAbstraction) Annotation(key string) string {
 	}
 }
 
+func (r roleBindingAbstraction) SetAnnotation(key, value string) {
+	if r.roleBinding != nil {
+		metav1.SetMetaDataAnnotation(&r.roleBinding.ObjectMeta, key, value)
+	} else {
+		metav1.SetMetaDataAnnotation(&r.clusterRoleBinding.ObjectMeta, key, value)
+	}
+}
+
+func (r roleBindingAbstraction) RemoveAnnotation(key string) {
+	if r.roleBinding != nil {
+		delete(r.roleBinding.Annotations, key)
+	} else {
+		delete(r.clusterRoleBinding.Annotations, key)
+	}
+}
+
 func (r roleBindingAbstraction) Subjects() []rbacv1.Subject {
 	if r.roleBinding != nil {
 		return r.roleBinding.Subjects
@@ -0,0 +1,28 @@
+package policy
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// protectedAnnotation marks a binding that the remove commands refuse to modify unless --override-protection is
+// given, such as a break-glass binding that automation must never strip.
+const protectedAnnotation = "policy.openshift.io/protected"
+
+// bindingProtection returns why the binding must not be modified, or an empty string if it may be. Bindings are
+// protected by protectedAnnotation or by referencing one of protectedRoles.
+func bindingProtection(binding *roleBindingAbstraction, protectedRoles []string) string {
+	if binding.Annotation(protectedAnnotation) == "true" {
+		return fmt.Sprintf("annotation %s=true", protectedAnnotation)
+	}
+	if sets.NewString(protectedRoles...).Has(binding.RoleName()) {
+		return fmt.Sprintf("protected role %s/%s", binding.RoleKind(), binding.RoleName())
+	}
+	return ""
+}
+
+// protectionError is returned for a protected binding that was left unmodified.
+func protectionError(binding *roleBindingAbstraction, reason string) error {
+	return fmt.Errorf("refusing to modify %s %s protected by %s, use --override-protection to modify it", binding.Type(), binding.Name(), reason)
+}
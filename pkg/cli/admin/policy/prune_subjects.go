@@ -0,0 +1,276 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	pruneSubjectsLong = templates.LongDesc(`
+		Remove subjects that no longer exist from role bindings
+
+		Role bindings keep referencing users, groups and service accounts after they are deleted. This command
+		looks up every subject of the role bindings in the current project, or in all projects and the cluster role
+		bindings with --all-namespaces, and reports those whose Group or ServiceAccount no longer exists.
+		Virtual subjects such as system:authenticated are never reported.
+
+		Users are only reported with --include-users, when they have no User object. Users of identity providers
+		only get a User object when they first log in, so a missing User object does not prove that the user was
+		removed: only use --include-users when every bound user is known to have logged in.
+
+		By default, the prune operation performs a dry run making no changes. A --confirm flag is needed for
+		changes to be effective. Bindings that are left without subjects are deleted.
+	`)
+
+	pruneSubjectsExample = templates.Examples(`
+		# List the dangling subjects in the current project
+		oc adm policy prune-subjects
+
+		# Remove the dangling subjects from role bindings in all projects and from cluster role bindings
+		oc adm policy prune-subjects -A --confirm
+	`)
+)
+
+type PruneSubjectsOptions struct {
+	Confirm       bool
+	AllNamespaces bool
+	Namespace     string
+	Output        string
+	// IncludeUsers reports the users without a User object, which may also be users who never logged in.
+	IncludeUsers bool
+
+	// AuditLogFile, when set, receives a record of every change to a binding made on behalf of Actor.
+	AuditLogFile string
+	Actor        string
+
+	RbacClient           rbacv1client.RbacV1Interface
+	UserClient           userv1client.UserV1Interface
+	ServiceAccountClient corev1client.ServiceAccountsGetter
+
+	genericclioptions.IOStreams
+}
+
+// danglingSubject is a subject of a binding which references an object that does not exist.
+type danglingSubject struct {
+	Namespace string         `json:"namespace,omitempty"`
+	Binding   string         `json:"binding"`
+	Subject   rbacv1.Subject `json:"subject"`
+}
+
+func NewPruneSubjectsOptions(streams genericclioptions.IOStreams) *PruneSubjectsOptions {
+	return &PruneSubjectsOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdPruneSubjects implements the OpenShift cli prune-subjects command
+func NewCmdPruneSubjects(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewPruneSubjectsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "prune-subjects",
+		Short:   "Remove subjects that no longer exist from role bindings",
+		Long:    pruneSubjectsLong,
+		Example: pruneSubjectsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, specify that subject pruning should proceed. Defaults to false, displaying what would be removed but not actually removing anything.")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, prune role bindings in all namespaces and cluster role bindings.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+	cmd.Flags().BoolVar(&o.IncludeUsers, "include-users", o.IncludeUsers, "If true, also prune the users without a User object, including users of identity providers who never logged in.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
+	return cmd
+}
+
+func (o *PruneSubjectsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	var err error
+	if o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.RbacClient, err = rbacv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.UserClient, err = userv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.ServiceAccountClient, err = corev1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.Actor, err = auditActor(clientConfig, o.AuditLogFile)
+	return err
+}
+
+func (o *PruneSubjectsOptions) Validate() error {
+	if len(o.Output) > 0 && o.Output != "json" && o.Output != "yaml" {
+		return fmt.Errorf("invalid output format %q, must be one of json or yaml", o.Output)
+	}
+	return nil
+}
+
+func (o *PruneSubjectsOptions) Run() error {
+	roleBindings, err := o.RbacClient.RoleBindings(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	bindings := []*roleBindingAbstraction{}
+	for i := range roleBindings.Items {
+		bindings = append(bindings, &roleBindingAbstraction{rbacClient: o.RbacClient, roleBinding: &roleBindings.Items[i]})
+	}
+	if o.AllNamespaces {
+		clusterRoleBindings, err := o.RbacClient.ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range clusterRoleBindings.Items {
+			bindings = append(bindings, &roleBindingAbstraction{rbacClient: o.RbacClient, clusterRoleBinding: &clusterRoleBindings.Items[i]})
+		}
+	}
+	sort.SliceStable(bindings, func(i, j int) bool {
+		if bindings[i].Namespace() != bindings[j].Namespace() {
+			return bindings[i].Namespace() < bindings[j].Namespace()
+		}
+		return bindings[i].Name() < bindings[j].Name()
+	})
+
+	audit, err := newAuditLog(o.AuditLogFile, o.Actor, false)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	exists := map[string]bool{}
+	dangling := []danglingSubject{}
+	errs := []error{}
+	for _, binding := range bindings {
+		danglers := []rbacv1.Subject{}
+		for _, subject := range binding.Subjects() {
+			found, err := o.subjectExists(subject, exists)
+			if err != nil {
+				return err
+			}
+			if !found {
+				danglers = append(danglers, subject)
+				dangling = append(dangling, danglingSubject{Namespace: binding.Namespace(), Binding: binding.Name(), Subject: subject})
+			}
+		}
+		if len(danglers) == 0 || !o.Confirm {
+			continue
+		}
+
+		before := binding.Subjects()
+		remaining, _ := removeSubjects(before, danglers)
+		binding.SetSubjects(remaining)
+		operation := "update"
+		if len(remaining) > 0 {
+			err = binding.Update()
+		} else {
+			operation = "delete"
+			err = binding.Delete()
+		}
+		if err != nil && !kapierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("unable to prune %s %s: %v", binding.Type(), binding.Name(), err))
+			continue
+		}
+		if err := audit.record(operation, binding, before); err != nil {
+			return err
+		}
+	}
+
+	if len(o.Output) > 0 {
+		if err := cmdutil.PrintJSONOrYAML(o.Out, o.Output, dangling); err != nil {
+			return err
+		}
+	} else if len(dangling) > 0 {
+		w := printers.GetNewTabWriter(o.Out)
+		fmt.Fprintf(w, "NAMESPACE\tBINDING\tSUBJECT\n")
+		for _, d := range dangling {
+			namespace := d.Namespace
+			if len(namespace) == 0 {
+				namespace = "<cluster>"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", namespace, d.Binding, strings.Join(subjectNames([]rbacv1.Subject{d.Subject}), ""))
+		}
+		w.Flush()
+	} else {
+		fmt.Fprintln(o.ErrOut, "No dangling subjects found.")
+	}
+
+	if !o.Confirm && len(dangling) > 0 {
+		fmt.Fprintln(o.ErrOut, "Dry run enabled - no modifications will be made. Add --confirm to remove subjects")
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// subjectExists returns whether the user, group or service account referenced by the subject exists. Virtual
+// users and groups prefixed with "system:", users unless IncludeUsers is set and subjects of unknown kinds are
+// always considered to exist.
+func (o *PruneSubjectsOptions) subjectExists(subject rbacv1.Subject, cache map[string]bool) (bool, error) {
+	key := fmt.Sprintf("%s/%s/%s", subject.Kind, subject.Namespace, subject.Name)
+	if found, ok := cache[key]; ok {
+		return found, nil
+	}
+
+	var err error
+	switch subject.Kind {
+	case rbacv1.UserKind:
+		if strings.HasPrefix(subject.Name, "system:") || !o.IncludeUsers {
+			return true, nil
+		}
+		_, err = o.UserClient.Users().Get(context.TODO(), subject.Name, metav1.GetOptions{})
+	case rbacv1.GroupKind:
+		if strings.HasPrefix(subject.Name, "system:") {
+			return true, nil
+		}
+		_, err = o.UserClient.Groups().Get(context.TODO(), subject.Name, metav1.GetOptions{})
+	case rbacv1.ServiceAccountKind:
+		_, err = o.ServiceAccountClient.ServiceAccounts(subject.Namespace).Get(context.TODO(), subject.Name, metav1.GetOptions{})
+	default:
+		return true, nil
+	}
+
+	switch {
+	case kapierrors.IsNotFound(err):
+		cache[key] = false
+	case err != nil:
+		return false, err
+	default:
+		cache[key] = true
+	}
+	return cache[key], nil
+}
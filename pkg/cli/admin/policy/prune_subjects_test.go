@@ -0,0 +1,188 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+
+	userv1 "github.com/openshift/api/user/v1"
+	fakeuserclient "github.com/openshift/client-go/user/clientset/versioned/fake"
+)
+
+func TestPruneSubjects(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	bob := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"}
+	devs := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "devs"}
+	authenticated := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "system:authenticated"}
+	builder := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ns", Name: "builder"}
+	deployer := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ns", Name: "deployer"}
+
+	tests := map[string]struct {
+		allNamespaces bool
+		confirm       bool
+		includeUsers  bool
+		output        string
+
+		expectedOut         string
+		expectedSubjects    map[string][]rbacv1.Subject
+		expectedClusterGone bool
+	}{
+		"dry run": {
+			includeUsers: true,
+			expectedOut: `NAMESPACE   BINDING   SUBJECT
+ns          edit      User/bob
+ns          edit      ServiceAccount/ns/deployer
+ns          view      Group/devs
+`,
+			expectedSubjects: map[string][]rbacv1.Subject{
+				"edit": {alice, bob, builder, deployer},
+				"view": {devs, authenticated},
+			},
+		},
+		// users without a User object may not have logged in yet
+		"confirm without users": {
+			confirm: true,
+			expectedOut: `NAMESPACE   BINDING   SUBJECT
+ns          edit      ServiceAccount/ns/deployer
+ns          view      Group/devs
+`,
+			expectedSubjects: map[string][]rbacv1.Subject{
+				"edit": {alice, bob, builder},
+				"view": {authenticated},
+			},
+		},
+		"confirm": {
+			confirm:      true,
+			includeUsers: true,
+			expectedOut: `NAMESPACE   BINDING   SUBJECT
+ns          edit      User/bob
+ns          edit      ServiceAccount/ns/deployer
+ns          view      Group/devs
+`,
+			expectedSubjects: map[string][]rbacv1.Subject{
+				"edit": {alice, builder},
+				"view": {authenticated},
+			},
+		},
+		"all namespaces": {
+			allNamespaces: true,
+			confirm:       true,
+			includeUsers:  true,
+			output:        "json",
+			expectedOut: `[
+  {
+    "binding": "bob-admin",
+    "subject": {
+      "kind": "User",
+      "apiGroup": "rbac.authorization.k8s.io",
+      "name": "bob"
+    }
+  },
+  {
+    "namespace": "ns",
+    "binding": "edit",
+    "subject": {
+      "kind": "User",
+      "apiGroup": "rbac.authorization.k8s.io",
+      "name": "bob"
+    }
+  },
+  {
+    "namespace": "ns",
+    "binding": "edit",
+    "subject": {
+      "kind": "ServiceAccount",
+      "name": "deployer",
+      "namespace": "ns"
+    }
+  },
+  {
+    "namespace": "ns",
+    "binding": "view",
+    "subject": {
+      "kind": "Group",
+      "apiGroup": "rbac.authorization.k8s.io",
+      "name": "devs"
+    }
+  }
+]
+`,
+			expectedSubjects: map[string][]rbacv1.Subject{
+				"edit": {alice, builder},
+				"view": {authenticated},
+			},
+			expectedClusterGone: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			kubeClient := fakeclient.NewSimpleClientset(
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "builder", Namespace: "ns"}},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{alice, bob, builder, deployer},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "view", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+					Subjects:   []rbacv1.Subject{devs, authenticated},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "bob-admin"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+					Subjects:   []rbacv1.Subject{bob},
+				},
+			)
+			userClient := fakeuserclient.NewSimpleClientset(
+				&userv1.User{ObjectMeta: metav1.ObjectMeta{Name: "alice"}},
+			)
+
+			namespace := "ns"
+			if tc.allNamespaces {
+				namespace = metav1.NamespaceAll
+			}
+			out := &bytes.Buffer{}
+			o := &PruneSubjectsOptions{
+				Confirm:              tc.confirm,
+				AllNamespaces:        tc.allNamespaces,
+				IncludeUsers:         tc.includeUsers,
+				Namespace:            namespace,
+				Output:               tc.output,
+				RbacClient:           kubeClient.RbacV1(),
+				UserClient:           userClient.UserV1(),
+				ServiceAccountClient: kubeClient.CoreV1(),
+				IOStreams:            genericclioptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expectedOut {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expectedOut, out.String())
+			}
+
+			for name, expected := range tc.expectedSubjects {
+				binding, err := kubeClient.RbacV1().RoleBindings("ns").Get(context.TODO(), name, metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(binding.Subjects, expected) {
+					t.Errorf("rolebinding %s: expected subjects %v, got %v", name, expected, binding.Subjects)
+				}
+			}
+			_, err := kubeClient.RbacV1().ClusterRoleBindings().Get(context.TODO(), "bob-admin", metav1.GetOptions{})
+			if gone := kapierrors.IsNotFound(err); gone != tc.expectedClusterGone {
+				t.Errorf("expected clusterrolebinding removed %v, got %v (%v)", tc.expectedClusterGone, gone, err)
+			}
+		})
+	}
+}
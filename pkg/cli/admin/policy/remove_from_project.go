@@ -2,37 +2,150 @@ package policy
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/templates"
+	"k8s.io/utils/exec"
+	"sigs.k8s.io/yaml"
 
+	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
 	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+	"github.com/openshift/oc/pkg/helpers/project"
+	"github.com/openshift/oc/pkg/helpers/term"
 )
 
+var (
+	removeUserFromProjectLongDesc = templates.LongDesc(`
+		Remove user from the project
+
+		Removes the users from every role binding in the current project, deleting bindings that are left without
+		subjects. The command exits with status 3 when none of the users were bound to a role, and with status 4 when
+		only some of them were, unless --ignore-not-found is set.
+	`)
+
+	removeGroupFromProjectLongDesc = templates.LongDesc(`
+		Remove group from the project
+
+		Removes the groups from every role binding in the current project, deleting bindings that are left without
+		subjects. The command exits with status 3 when none of the groups were bound to a role, and with status 4 when
+		only some of them were, unless --ignore-not-found is set.
+	`)
+)
+
+const (
+	// RemoveExitCodeNoneRemoved is the exit code of remove-user and remove-group when none of the requested
+	// subjects were bound to a role.
+	RemoveExitCodeNoneRemoved = 3
+	// RemoveExitCodePartiallyRemoved is the exit code of remove-user and remove-group when only some of the
+	// requested subjects were bound to a role.
+	RemoveExitCodePartiallyRemoved = 4
+)
+
+// removalSummary describes which of the requested subjects were removed from at least one binding and which
+// were not bound at all.
+type removalSummary struct {
+	Removed  []string `json:"removed"`
+	NotFound []string `json:"notFound"`
+}
+
+// removalReport is printed with --summary and describes every binding that was modified, which requested subjects
+// were not bound at all and the errors that prevented bindings from being modified.
+type removalReport struct {
+	removalSummary `json:",inline"`
+
+	DryRun   bool             `json:"dryRun,omitempty"`
+	Bindings []bindingRemoval `json:"bindings"`
+	Errors   []string         `json:"errors"`
+}
+
+// bindingRemoval describes the subjects removed from a single binding.
+type bindingRemoval struct {
+	Kind      string           `json:"kind"`
+	Namespace string           `json:"namespace,omitempty"`
+	Name      string           `json:"name"`
+	RoleRef   rbacv1.RoleRef   `json:"roleRef"`
+	Removed   []rbacv1.Subject `json:"removed"`
+	Deleted   bool             `json:"deleted"`
+}
+
 type RemoveFromProjectOptions struct {
 	PrintFlags *genericclioptions.PrintFlags
 
 	Printer printers.ResourcePrinter
 
 	BindingNamespace string
-	Client           rbacv1client.RoleBindingsGetter
+	Client           rbacv1client.RbacV1Interface
+
+	IncludeClusterBindings bool
+
+	// Interactive prompts for every binding before any of them is modified.
+	Interactive bool
+	// interactiveAnswer remembers an answer that applies to all remaining bindings.
+	interactiveAnswer string
+
+	// IncludeManaged allows modifying bindings that are owned by another object or labeled as managed by a tool.
+	IncludeManaged bool
+
+	// ProtectedRoles and the protected annotation mark bindings that are left unmodified, and reported as an error,
+	// unless OverrideProtection is set.
+	ProtectedRoles     []string
+	OverrideProtection bool
+
+	// CheckGroups reports the groups that contain a removed user and are still bound to a role. EditGroups also
+	// removes the user from those groups.
+	CheckGroups bool
+	EditGroups  bool
+	UserClient  userv1client.UserV1Interface
+
+	// RoleBindingNames restricts the removal to the bindings with these names.
+	RoleBindingNames []string
+	ChunkSize        int64
+
+	// BackupDir and BackupFile, when set, receive the original version of every binding before it is modified.
+	BackupDir  string
+	BackupFile string
+
+	// Reason, when set, is recorded together with Actor on every modified binding and in an event.
+	Reason      string
+	Actor       string
+	EventClient corev1client.EventsGetter
+
+	// AuditLogFile, when set, receives a record of every change to a binding.
+	AuditLogFile string
 
 	Groups []string
 	Users  []string
 
+	// IgnoreNotFound treats subjects that are not bound to any role as already removed.
+	IgnoreNotFound bool
+
 	DryRunStrategy kcmdutil.DryRunStrategy
 
 	Output string
+	// Summary, when set to json or yaml, prints a removalReport instead of the human readable messages.
+	Summary string
 
 	genericclioptions.IOStreams
 }
@@ -40,6 +153,7 @@ type RemoveFromProjectOptions struct {
 func NewRemoveFromProjectOptions(streams genericclioptions.IOStreams) *RemoveFromProjectOptions {
 	return &RemoveFromProjectOptions{
 		PrintFlags: genericclioptions.NewPrintFlags("").WithTypeSetter(scheme.Scheme),
+		ChunkSize:  500,
 		IOStreams:  streams,
 	}
 }
@@ -50,7 +164,7 @@ func NewCmdRemoveGroupFromProject(f kcmdutil.Factory, streams genericclioptions.
 	cmd := &cobra.Command{
 		Use:   "remove-group GROUP [GROUP ...]",
 		Short: "Remove group from the project",
-		Long:  `Remove group from the project`,
+		Long:  removeGroupFromProjectLongDesc,
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args, &o.Groups, "group"))
 			kcmdutil.CheckErr(o.Validate(f, cmd, args))
@@ -58,6 +172,20 @@ func NewCmdRemoveGroupFromProject(f kcmdutil.Factory, streams genericclioptions.
 		},
 	}
 
+	cmd.Flags().BoolVar(&o.IncludeClusterBindings, "include-cluster-bindings", o.IncludeClusterBindings, "If true, also remove the subjects from cluster role bindings.")
+	cmd.Flags().BoolVarP(&o.Interactive, "interactive", "i", o.Interactive, "If true, ask for confirmation before modifying each binding.")
+	cmd.Flags().BoolVar(&o.IncludeManaged, "include-managed", o.IncludeManaged, "If true, also modify bindings that are managed by an operator or another tool. Such bindings are skipped by default because they are usually re-created.")
+	cmd.Flags().StringSliceVar(&o.RoleBindingNames, "rolebinding", o.RoleBindingNames, "Only remove the subjects from the bindings with these names.")
+	cmd.Flags().StringVar(&o.Reason, "reason", o.Reason, "Reason for the change. If set, modified bindings are annotated with the reason, the current user and the removed subjects, and an event is recorded.")
+	cmd.Flags().StringVar(&o.BackupDir, "backup-dir", o.BackupDir, "If set, write the original version of every modified binding to a file in this directory. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringVar(&o.BackupFile, "backup-file", o.BackupFile, "If set, write the original version of every modified binding to this file. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringSliceVar(&o.ProtectedRoles, "protected-roles", o.ProtectedRoles, "Roles whose bindings must not be modified, in addition to bindings annotated with "+protectedAnnotation+"=true.")
+	cmd.Flags().BoolVar(&o.OverrideProtection, "override-protection", o.OverrideProtection, "If true, also modify protected bindings.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+	cmd.Flags().StringVar(&o.Summary, "summary", o.Summary, "If set to json or yaml, print a summary of the subjects removed from every binding, the deleted bindings, the subjects that were not found and any errors instead of the usual messages. Failing bindings do not stop the command in this mode.")
+	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound, "If true, exit successfully and do not report the subjects that are not bound to any role.")
+
+	kcmdutil.AddChunkSizeFlag(cmd, &o.ChunkSize)
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -69,7 +197,7 @@ func NewCmdRemoveUserFromProject(f kcmdutil.Factory, streams genericclioptions.I
 	cmd := &cobra.Command{
 		Use:   "remove-user USER [USER ...]",
 		Short: "Remove user from the project",
-		Long:  `Remove user from the project`,
+		Long:  removeUserFromProjectLongDesc,
 		Run: func(cmd *cobra.Command, args []string) {
 			kcmdutil.CheckErr(o.Complete(f, cmd, args, &o.Users, "user"))
 			kcmdutil.CheckErr(o.Validate(f, cmd, args))
@@ -77,6 +205,23 @@ func NewCmdRemoveUserFromProject(f kcmdutil.Factory, streams genericclioptions.I
 		},
 	}
 
+	cmd.Flags().BoolVar(&o.IncludeClusterBindings, "include-cluster-bindings", o.IncludeClusterBindings, "If true, also remove the subjects from cluster role bindings.")
+	cmd.Flags().BoolVarP(&o.Interactive, "interactive", "i", o.Interactive, "If true, ask for confirmation before modifying each binding.")
+	cmd.Flags().BoolVar(&o.IncludeManaged, "include-managed", o.IncludeManaged, "If true, also modify bindings that are managed by an operator or another tool. Such bindings are skipped by default because they are usually re-created.")
+	cmd.Flags().StringSliceVar(&o.RoleBindingNames, "rolebinding", o.RoleBindingNames, "Only remove the subjects from the bindings with these names.")
+	cmd.Flags().StringVar(&o.Reason, "reason", o.Reason, "Reason for the change. If set, modified bindings are annotated with the reason, the current user and the removed subjects, and an event is recorded.")
+	cmd.Flags().StringVar(&o.BackupDir, "backup-dir", o.BackupDir, "If set, write the original version of every modified binding to a file in this directory. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringVar(&o.BackupFile, "backup-file", o.BackupFile, "If set, write the original version of every modified binding to this file. Use 'oc adm policy restore' to undo the removal.")
+	cmd.Flags().StringSliceVar(&o.ProtectedRoles, "protected-roles", o.ProtectedRoles, "Roles whose bindings must not be modified, in addition to bindings annotated with "+protectedAnnotation+"=true.")
+	cmd.Flags().BoolVar(&o.OverrideProtection, "override-protection", o.OverrideProtection, "If true, also modify protected bindings.")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+	cmd.Flags().StringVar(&o.Summary, "summary", o.Summary, "If set to json or yaml, print a summary of the subjects removed from every binding, the deleted bindings, the subjects that were not found and any errors instead of the usual messages. Failing bindings do not stop the command in this mode.")
+	cmd.Flags().BoolVar(&o.IgnoreNotFound, "ignore-not-found", o.IgnoreNotFound, "If true, exit successfully and do not report the subjects that are not bound to any role.")
+
+	cmd.Flags().BoolVar(&o.CheckGroups, "check-groups", o.CheckGroups, "If true, report the groups that contain the users and are still bound to a role, since members keep the access granted to the group.")
+	cmd.Flags().BoolVar(&o.EditGroups, "edit-groups", o.EditGroups, "If true, also remove the users from the groups that are still bound to a role. Implies --check-groups.")
+
+	kcmdutil.AddChunkSizeFlag(cmd, &o.ChunkSize)
 	kcmdutil.AddDryRunFlag(cmd)
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
@@ -116,21 +261,58 @@ func (o *RemoveFromProjectOptions) Complete(f kcmdutil.Factory, cmd *cobra.Comma
 		return err
 	}
 
+	if o.CheckGroups || o.EditGroups {
+		o.UserClient, err = userv1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(o.Reason) > 0 {
+		me, err := project.WhoAmI(clientConfig)
+		if err != nil {
+			return err
+		}
+		o.Actor = me.Name
+		o.EventClient, err = corev1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+	}
+	if len(o.Actor) == 0 {
+		if o.Actor, err = auditActor(clientConfig, o.AuditLogFile); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (o *RemoveFromProjectOptions) Validate(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if o.Interactive && len(o.Output) > 0 {
+		return fmt.Errorf("--interactive cannot be used with --output")
+	}
+	if len(o.BackupDir) > 0 && len(o.BackupFile) > 0 {
+		return fmt.Errorf("--backup-dir and --backup-file are mutually exclusive")
+	}
+	if o.EditGroups && len(o.Output) > 0 {
+		return fmt.Errorf("--edit-groups cannot be used with --output")
+	}
+	if len(o.Summary) > 0 {
+		if o.Summary != "json" && o.Summary != "yaml" {
+			return fmt.Errorf("--summary must be one of json or yaml")
+		}
+		if len(o.Output) > 0 {
+			return fmt.Errorf("--summary cannot be used with --output")
+		}
+		if o.Interactive {
+			return fmt.Errorf("--summary cannot be used with --interactive")
+		}
+	}
 	return nil
 }
 
 func (o *RemoveFromProjectOptions) Run() error {
-	roleBindings, err := o.Client.RoleBindings(o.BindingNamespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return err
-	}
-	// maintain David's hack from #1973 (see #1975, #1976 and https://bugzilla.redhat.com/show_bug.cgi?id=1215969)
-	sort.Sort(sort.Reverse(roleBindingSorter(roleBindings.Items)))
-
 	usersRemoved := sets.String{}
 	groupsRemoved := sets.String{}
 	sasRemoved := sets.String{}
@@ -140,83 +322,558 @@ func (o *RemoveFromProjectOptions) Run() error {
 		dryRunText = " (dry client run)"
 	}
 
-	updatedBindings := &rbacv1.RoleBindingList{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "List",
-			APIVersion: "v1",
+	updatedBindings := &unstructured.UnstructuredList{
+		Object: map[string]interface{}{
+			"kind":       "List",
+			"apiVersion": "v1",
+			"metadata":   map[string]interface{}{},
 		},
-		ListMeta: metav1.ListMeta{},
 	}
 
 	subjectsToRemove := authorizationutil.BuildRBACSubjects(o.Users, o.Groups)
+	removedSubjects := []rbacv1.Subject{}
+	report := &removalReport{
+		DryRun:   o.DryRunStrategy == kcmdutil.DryRunClient,
+		Bindings: []bindingRemoval{},
+		Errors:   []string{},
+	}
+
+	protectionErrs := []error{}
+
+	backup, err := newBindingBackup(o.BackupDir, o.BackupFile)
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	audit, err := newAuditLog(o.AuditLogFile, o.Actor, o.DryRunStrategy == kcmdutil.DryRunClient)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	// planRemoval returns the removal of the subjects from the binding, or nil if the binding is left unmodified.
+	planRemoval := func(currBinding *roleBindingAbstraction) *subjectRemoval {
+		originalSubjects := currBinding.Subjects()
+		newSubjects, _ := removeSubjects(originalSubjects, subjectsToRemove)
+		if len(newSubjects) == len(originalSubjects) {
+			return nil
+		}
+		if !o.IncludeManaged {
+			if manager := bindingManager(currBinding); len(manager) > 0 {
+				fmt.Fprintf(o.ErrOut, "Warning: skipping %s %s managed by %s, use --include-managed to modify it\n", currBinding.Type(), currBinding.Name(), manager)
+				return nil
+			}
+		}
+		if !o.OverrideProtection {
+			if reason := bindingProtection(currBinding, o.ProtectedRoles); len(reason) > 0 {
+				if len(o.Summary) > 0 {
+					report.Errors = append(report.Errors, protectionError(currBinding, reason).Error())
+				} else {
+					protectionErrs = append(protectionErrs, protectionError(currBinding, reason))
+				}
+				return nil
+			}
+		}
+		return &subjectRemoval{
+			binding:     currBinding,
+			newSubjects: newSubjects,
+			removed:     subtractSubjects(originalSubjects, newSubjects),
+		}
+	}
 
-	for _, currBinding := range roleBindings.Items {
-		originalSubjects := make([]rbacv1.Subject, len(currBinding.Subjects))
-		copy(originalSubjects, currBinding.Subjects)
+	removeFromBinding := func(r *subjectRemoval) error {
+		currBinding, newSubjects, removed := r.binding, r.newSubjects, r.removed
+		originalSubjects := currBinding.Subjects()
 		oldUsers, oldGroups, oldSAs, oldOthers := subjectsStrings(originalSubjects)
 		oldUsersSet, oldGroupsSet, oldSAsSet, oldOtherSet := sets.NewString(oldUsers...), sets.NewString(oldGroups...), sets.NewString(oldSAs...), sets.NewString(oldOthers...)
-
-		currBinding.Subjects, _ = removeSubjects(currBinding.Subjects, subjectsToRemove)
-		newUsers, newGroups, newSAs, newOthers := subjectsStrings(currBinding.Subjects)
+		newUsers, newGroups, newSAs, newOthers := subjectsStrings(newSubjects)
 		newUsersSet, newGroupsSet, newSAsSet, newOtherSet := sets.NewString(newUsers...), sets.NewString(newGroups...), sets.NewString(newSAs...), sets.NewString(newOthers...)
 
-		if len(currBinding.Subjects) == len(originalSubjects) {
-			continue
+		original := currBinding.Object().DeepCopyObject()
+		currBinding.SetSubjects(newSubjects)
+
+		var change *bindingChange
+		if len(o.Reason) > 0 {
+			change = &bindingChange{Actor: o.Actor, Reason: o.Reason, Time: time.Now(), Removed: removed}
+			change.annotate(currBinding)
 		}
 
 		if len(o.Output) > 0 {
-			updatedBindings.Items = append(updatedBindings.Items, currBinding)
-			continue
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(currBinding.Object())
+			if err != nil {
+				return err
+			}
+			updatedBindings.Items = append(updatedBindings.Items, unstructured.Unstructured{Object: obj})
+			removedSubjects = append(removedSubjects, removed...)
+			return nil
 		}
 
 		if o.DryRunStrategy != kcmdutil.DryRunClient {
-			if len(currBinding.Subjects) > 0 {
-				_, err = o.Client.RoleBindings(o.BindingNamespace).Update(context.TODO(), &currBinding, metav1.UpdateOptions{})
+			if backup != nil {
+				if err := backup.save(original, removed); err != nil {
+					return fmt.Errorf("unable to back up %s %s: %v", currBinding.Type(), currBinding.Name(), err)
+				}
+			}
+			var err error
+			if len(newSubjects) > 0 {
+				err = currBinding.Update()
 			} else {
-				err = o.Client.RoleBindings(o.BindingNamespace).Delete(context.TODO(), currBinding.Name, metav1.DeleteOptions{})
+				err = currBinding.Delete()
+			}
+			if err != nil && len(o.Summary) > 0 {
+				report.Errors = append(report.Errors, fmt.Sprintf("unable to modify %s %s: %v", currBinding.Type(), currBinding.Name(), err))
+				return nil
 			}
 			if err != nil {
 				return err
 			}
+			if change != nil && o.EventClient != nil {
+				if err := change.recordEvent(o.EventClient, currBinding); err != nil {
+					fmt.Fprintf(o.ErrOut, "Warning: unable to record event for %s %s: %v\n", currBinding.Type(), currBinding.Name(), err)
+				}
+			}
+		}
+		operation := "update"
+		if len(newSubjects) == 0 {
+			operation = "delete"
+		}
+		if err := audit.record(operation, currBinding, originalSubjects); err != nil {
+			return err
 		}
+		removedSubjects = append(removedSubjects, removed...)
 
-		roleDisplayName := fmt.Sprintf("%s/%s", currBinding.Namespace, currBinding.RoleRef.Name)
-		if currBinding.RoleRef.Kind == "ClusterRole" {
-			roleDisplayName = currBinding.RoleRef.Name
+		if len(o.Summary) > 0 {
+			kind := "RoleBinding"
+			if len(currBinding.Namespace()) == 0 {
+				kind = "ClusterRoleBinding"
+			}
+			report.Bindings = append(report.Bindings, bindingRemoval{
+				Kind:      kind,
+				Namespace: currBinding.Namespace(),
+				Name:      currBinding.Name(),
+				RoleRef:   rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: currBinding.RoleKind(), Name: currBinding.RoleName()},
+				Removed:   removed,
+				Deleted:   len(newSubjects) == 0,
+			})
+			return nil
+		}
+
+		roleDisplayName := fmt.Sprintf("%s/%s", currBinding.Namespace(), currBinding.RoleName())
+		if currBinding.RoleKind() == "ClusterRole" {
+			roleDisplayName = currBinding.RoleName()
+		}
+		scope := fmt.Sprintf("in project %s", o.BindingNamespace)
+		if len(currBinding.Namespace()) == 0 {
+			scope = "cluster-wide"
 		}
 
 		if diff := oldUsersSet.Difference(newUsersSet); len(diff) != 0 {
-			fmt.Fprintf(o.Out, "Removing %s from users %v in project %s%s.\n", roleDisplayName, diff.List(), o.BindingNamespace, dryRunText)
+			fmt.Fprintf(o.Out, "Removing %s from users %v %s%s.\n", roleDisplayName, diff.List(), scope, dryRunText)
 			usersRemoved.Insert(diff.List()...)
 		}
 		if diff := oldGroupsSet.Difference(newGroupsSet); len(diff) != 0 {
-			fmt.Fprintf(o.Out, "Removing %s from groups %v in project %s%s.\n", roleDisplayName, diff.List(), o.BindingNamespace, dryRunText)
+			fmt.Fprintf(o.Out, "Removing %s from groups %v %s%s.\n", roleDisplayName, diff.List(), scope, dryRunText)
 			groupsRemoved.Insert(diff.List()...)
 		}
 		if diff := oldSAsSet.Difference(newSAsSet); len(diff) != 0 {
-			fmt.Fprintf(o.Out, "Removing %s from serviceaccounts %v in project %s%s.\n", roleDisplayName, diff.List(), o.BindingNamespace, dryRunText)
+			fmt.Fprintf(o.Out, "Removing %s from serviceaccounts %v %s%s.\n", roleDisplayName, diff.List(), scope, dryRunText)
 			sasRemoved.Insert(diff.List()...)
 		}
 		if diff := oldOtherSet.Difference(newOtherSet); len(diff) != 0 {
-			fmt.Fprintf(o.Out, "Removing %s from subjects %v in project %s%s.\n", roleDisplayName, diff.List(), o.BindingNamespace, dryRunText)
+			fmt.Fprintf(o.Out, "Removing %s from subjects %v %s%s.\n", roleDisplayName, diff.List(), scope, dryRunText)
 			othersRemoved.Insert(diff.List()...)
 		}
+		return nil
+	}
+
+	// maintain David's hack from #1973 (see #1975, #1976 and https://bugzilla.redhat.com/show_bug.cgi?id=1215969):
+	// bindings to the admin role are processed last so that the caller does not lose the ability to modify the
+	// remaining bindings halfway through.
+	groupBindings := map[string][]string{}
+	// with Interactive, every removal is planned and confirmed before any binding is modified, so that quitting
+	// leaves them all unmodified
+	interactiveRemovals := []*subjectRemoval{}
+	visit := func(binding *roleBindingAbstraction) error {
+		removal := planRemoval(binding)
+		switch {
+		case removal == nil:
+		case o.Interactive:
+			// the roles granted to the groups of the binding are recorded once the removal is confirmed
+			interactiveRemovals = append(interactiveRemovals, removal)
+			return nil
+		default:
+			if err := removeFromBinding(removal); err != nil {
+				return err
+			}
+		}
+		recordGroupBindings(groupBindings, binding)
+		return nil
+	}
+	adminBindings := []*roleBindingAbstraction{}
+	err = o.visitBindings(func(page []*roleBindingAbstraction) error {
+		for _, binding := range page {
+			if binding.RoleKind() == "ClusterRole" && binding.RoleName() == "admin" {
+				adminBindings = append(adminBindings, binding)
+				continue
+			}
+			if err := visit(binding); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, binding := range adminBindings {
+		if err := visit(binding); err != nil {
+			return err
+		}
+	}
+
+	// the subjects the user chose to keep are neither removed nor reported as not bound
+	declinedSubjects := []rbacv1.Subject{}
+	if o.Interactive {
+		confirmed, declined := o.confirmRemovals(interactiveRemovals)
+		if o.interactiveAnswer == "q" {
+			fmt.Fprintln(o.Out, "No bindings were modified.")
+			return nil
+		}
+		for _, removal := range confirmed {
+			if err := removeFromBinding(removal); err != nil {
+				return err
+			}
+			recordGroupBindings(groupBindings, removal.binding)
+		}
+		for _, removal := range declined {
+			declinedSubjects = append(declinedSubjects, removal.removed...)
+			recordGroupBindings(groupBindings, removal.binding)
+		}
+	}
+	declinedUsers, declinedGroups, _, _ := subjectsStrings(declinedSubjects)
+
+	if o.CheckGroups || o.EditGroups {
+		if err := o.checkGroups(groupBindings, dryRunText); err != nil {
+			return err
+		}
 	}
 
 	if len(o.Output) > 0 {
-		return o.Printer.PrintObj(updatedBindings, o.Out)
+		if err := o.Printer.PrintObj(updatedBindings, o.Out); err != nil {
+			return err
+		}
+		if len(protectionErrs) > 0 {
+			return utilerrors.NewAggregate(protectionErrs)
+		}
+		return o.removalResult(subjectsToRemove, removedSubjects)
 	}
 
-	if diff := sets.NewString(o.Users...).Difference(usersRemoved); len(diff) != 0 {
-		fmt.Fprintf(o.Out, "Users %v were not bound to roles in project %s%s.\n", diff.List(), o.BindingNamespace, dryRunText)
+	if len(o.Summary) > 0 {
+		report.removalSummary = o.summarize(subjectsToRemove, removedSubjects)
+		if err := cmdutil.PrintJSONOrYAML(o.Out, o.Summary, report); err != nil {
+			return err
+		}
+		if len(report.Errors) > 0 {
+			return fmt.Errorf("unable to modify %d binding(s)", len(report.Errors))
+		}
+		return o.removalResult(subjectsToRemove, removedSubjects)
+	}
+
+	scope := fmt.Sprintf("in project %s", o.BindingNamespace)
+	if o.IncludeClusterBindings {
+		scope = fmt.Sprintf("in project %s or cluster-wide", o.BindingNamespace)
+	}
+	if len(protectionErrs) > 0 {
+		return utilerrors.NewAggregate(protectionErrs)
+	}
+	if o.IgnoreNotFound {
+		return nil
+	}
+	if diff := sets.NewString(o.Users...).Difference(usersRemoved).Delete(declinedUsers...); len(diff) != 0 {
+		fmt.Fprintf(o.Out, "Users %v were not bound to roles %s%s.\n", diff.List(), scope, dryRunText)
+	}
+	if diff := sets.NewString(o.Groups...).Difference(groupsRemoved).Delete(declinedGroups...); len(diff) != 0 {
+		fmt.Fprintf(o.Out, "Groups %v were not bound to roles %s%s.\n", diff.List(), scope, dryRunText)
 	}
-	if diff := sets.NewString(o.Groups...).Difference(groupsRemoved); len(diff) != 0 {
-		fmt.Fprintf(o.Out, "Groups %v were not bound to roles in project %s%s.\n", diff.List(), o.BindingNamespace, dryRunText)
+
+	// the declined subjects were bound to a role, so they don't count as not found in the exit code
+	return o.removalResult(subjectsToRemove, append(removedSubjects, declinedSubjects...))
+}
+
+// recordGroupBindings records, for every group that is a subject of the binding, that the binding grants it a role.
+func recordGroupBindings(groupBindings map[string][]string, binding *roleBindingAbstraction) {
+	for _, subject := range binding.Subjects() {
+		if subject.Kind == rbacv1.GroupKind {
+			groupBindings[subject.Name] = append(groupBindings[subject.Name], fmt.Sprintf("%s/%s", binding.Type(), binding.Name()))
+		}
 	}
+}
 
+// checkGroups reports the groups that contain one of the removed users and are still bound to a role through one
+// of groupBindings, and removes the users from them with EditGroups.
+func (o *RemoveFromProjectOptions) checkGroups(groupBindings map[string][]string, dryRunText string) error {
+	if len(o.Users) == 0 || len(groupBindings) == 0 {
+		return nil
+	}
+	out := o.Out
+	if len(o.Output) > 0 || len(o.Summary) > 0 {
+		out = o.ErrOut
+	}
+
+	groups, err := o.UserClient.Groups().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	users := sets.NewString(o.Users...)
+	for i := range groups.Items {
+		group := &groups.Items[i]
+		bindings, bound := groupBindings[group.Name]
+		if !bound {
+			continue
+		}
+		members := users.Intersection(sets.NewString(group.Users...))
+		if members.Len() == 0 {
+			continue
+		}
+
+		if !o.EditGroups {
+			fmt.Fprintf(o.ErrOut, "Warning: users %v keep access through group %s bound by %s, use --edit-groups to remove them from the group\n", members.List(), group.Name, strings.Join(bindings, ", "))
+			continue
+		}
+		remaining := []string{}
+		for _, user := range group.Users {
+			if !members.Has(user) {
+				remaining = append(remaining, user)
+			}
+		}
+		group.Users = remaining
+		if o.DryRunStrategy != kcmdutil.DryRunClient {
+			updateOptions := metav1.UpdateOptions{}
+			if o.DryRunStrategy == kcmdutil.DryRunServer {
+				updateOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			if _, err := o.UserClient.Groups().Update(context.TODO(), group, updateOptions); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(out, "Removing users %v from group %s%s.\n", members.List(), group.Name, dryRunText)
+	}
 	return nil
 }
 
+// visitBindings lists the role bindings in the project, and the cluster role bindings when requested, one page
+// of ChunkSize items at a time and passes each page to fn in reverse name order. When RoleBindingNames is set
+// only bindings with those names are listed.
+func (o *RemoveFromProjectOptions) visitBindings(fn func([]*roleBindingAbstraction) error) error {
+	names := sets.NewString(o.RoleBindingNames...)
+	selectors := []string{""}
+	if names.Len() > 0 {
+		selectors = []string{}
+		for _, name := range names.List() {
+			selectors = append(selectors, fields.OneTermEqualSelector("metadata.name", name).String())
+		}
+	}
+
+	for _, selector := range selectors {
+		options := metav1.ListOptions{FieldSelector: selector, Limit: o.ChunkSize}
+		for {
+			roleBindings, err := o.Client.RoleBindings(o.BindingNamespace).List(context.TODO(), options)
+			if err != nil {
+				return err
+			}
+			sort.Sort(sort.Reverse(roleBindingSorter(roleBindings.Items)))
+			page := []*roleBindingAbstraction{}
+			for i := range roleBindings.Items {
+				if names.Len() > 0 && !names.Has(roleBindings.Items[i].Name) {
+					continue
+				}
+				page = append(page, &roleBindingAbstraction{rbacClient: o.Client, roleBinding: &roleBindings.Items[i]})
+			}
+			if err := fn(page); err != nil {
+				return err
+			}
+			if len(roleBindings.Continue) == 0 {
+				break
+			}
+			options.Continue = roleBindings.Continue
+		}
+	}
+
+	if !o.IncludeClusterBindings {
+		return nil
+	}
+
+	for _, selector := range selectors {
+		options := metav1.ListOptions{FieldSelector: selector, Limit: o.ChunkSize}
+		for {
+			clusterRoleBindings, err := o.Client.ClusterRoleBindings().List(context.TODO(), options)
+			if err != nil {
+				return err
+			}
+			sort.Sort(sort.Reverse(clusterRoleBindingSorter(clusterRoleBindings.Items)))
+			page := []*roleBindingAbstraction{}
+			for i := range clusterRoleBindings.Items {
+				if names.Len() > 0 && !names.Has(clusterRoleBindings.Items[i].Name) {
+					continue
+				}
+				page = append(page, &roleBindingAbstraction{rbacClient: o.Client, clusterRoleBinding: &clusterRoleBindings.Items[i]})
+			}
+			if err := fn(page); err != nil {
+				return err
+			}
+			if len(clusterRoleBindings.Continue) == 0 {
+				break
+			}
+			options.Continue = clusterRoleBindings.Continue
+		}
+	}
+
+	return nil
+}
+
+// summarize splits the requested subjects into those that were removed from at least one binding and those that
+// were not found in any.
+func (o *RemoveFromProjectOptions) summarize(requested, removed []rbacv1.Subject) removalSummary {
+	// requested subjects are built from users followed by groups, in order
+	names := append(append([]string{}, o.Users...), o.Groups...)
+
+	summary := removalSummary{Removed: []string{}, NotFound: []string{}}
+	for i, subject := range requested {
+		if _, found := removeSubjects(removed, []rbacv1.Subject{subject}); found > 0 {
+			summary.Removed = append(summary.Removed, names[i])
+		} else {
+			summary.NotFound = append(summary.NotFound, names[i])
+		}
+	}
+	return summary
+}
+
+// removalResult returns an error carrying RemoveExitCodeNoneRemoved or RemoveExitCodePartiallyRemoved when some
+// of the requested subjects were not bound to any role, unless IgnoreNotFound is set. With -o json or -o yaml the error message is the
+// summary in that format.
+func (o *RemoveFromProjectOptions) removalResult(requested, removed []rbacv1.Subject) error {
+	if o.IgnoreNotFound {
+		return nil
+	}
+	summary := o.summarize(requested, removed)
+
+	var code int
+	var message string
+	switch {
+	case len(summary.NotFound) == 0:
+		return nil
+	case len(summary.Removed) == 0:
+		code = RemoveExitCodeNoneRemoved
+		message = fmt.Sprintf("none of %v were bound to a role", summary.NotFound)
+	default:
+		code = RemoveExitCodePartiallyRemoved
+		message = fmt.Sprintf("%v were not bound to a role", summary.NotFound)
+	}
+
+	switch o.Output {
+	case "json":
+		data, err := json.Marshal(summary)
+		if err != nil {
+			return err
+		}
+		message = string(data)
+	case "yaml":
+		data, err := yaml.Marshal(summary)
+		if err != nil {
+			return err
+		}
+		message = string(data)
+	}
+
+	return exec.CodeExitError{Err: errors.New(message), Code: code}
+}
+
+const interactiveHelp = `y - remove the subjects from this binding
+n - do not modify this binding
+a - remove the subjects from this and all remaining bindings
+q - quit without modifying any binding
+? - print help
+`
+
+// subjectRemoval is the removal of subjects from a binding, leaving newSubjects.
+type subjectRemoval struct {
+	binding     *roleBindingAbstraction
+	newSubjects []rbacv1.Subject
+	removed     []rbacv1.Subject
+}
+
+// confirmRemovals asks for every removal whether it should be made, and returns the confirmed and the declined
+// ones. It stops asking when the user quits, leaving interactiveAnswer set to "q".
+func (o *RemoveFromProjectOptions) confirmRemovals(removals []*subjectRemoval) (confirmed, declined []*subjectRemoval) {
+	for _, removal := range removals {
+		if o.interactiveAnswer == "q" {
+			return nil, nil
+		}
+		if o.confirmBinding(removal.binding, removal.removed) {
+			confirmed = append(confirmed, removal)
+		} else {
+			declined = append(declined, removal)
+		}
+	}
+	return confirmed, declined
+}
+
+// confirmBinding asks whether the subjects should be removed from the binding. Answers that apply to all
+// remaining bindings are remembered. An empty answer leaves the binding unmodified.
+func (o *RemoveFromProjectOptions) confirmBinding(binding *roleBindingAbstraction, removed []rbacv1.Subject) bool {
+	switch o.interactiveAnswer {
+	case "a":
+		return true
+	case "q":
+		return false
+	}
+
+	for {
+		answer := term.PromptForString(o.In, o.Out, "Remove %s from %s %s (%s %s) [y,n,a,q,?]? ",
+			strings.Join(subjectNames(removed), ", "), binding.Type(), binding.Name(), binding.RoleKind(), binding.RoleName())
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true
+		case "", "n", "no":
+			return false
+		case "a":
+			o.interactiveAnswer = "a"
+			return true
+		case "q":
+			o.interactiveAnswer = "q"
+			return false
+		default:
+			fmt.Fprint(o.Out, interactiveHelp)
+		}
+	}
+}
+
+// managedByLabels are the labels used by operators and deployment tools to mark the objects they reconcile.
+var managedByLabels = []string{
+	"app.kubernetes.io/managed-by",
+	"olm.owner",
+}
+
+// bindingManager returns a description of what manages the binding, or an empty string if nothing does.
+// Changes to managed bindings are typically reverted as soon as the manager reconciles them.
+func bindingManager(binding *roleBindingAbstraction) string {
+	accessor, err := meta.Accessor(binding.Object())
+	if err != nil {
+		return ""
+	}
+	if refs := accessor.GetOwnerReferences(); len(refs) > 0 {
+		return fmt.Sprintf("%s/%s", strings.ToLower(refs[0].Kind), refs[0].Name)
+	}
+	labels := accessor.GetLabels()
+	for _, key := range managedByLabels {
+		if value := labels[key]; len(value) > 0 {
+			return value
+		}
+	}
+	return ""
+}
+
 func subjectsStrings(subjects []rbacv1.Subject) ([]string, []string, []string, []string) {
 	users := []string{}
 	groups := []string{}
@@ -253,3 +910,15 @@ func (s roleBindingSorter) Less(i, j int) bool {
 func (s roleBindingSorter) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
+
+type clusterRoleBindingSorter []rbacv1.ClusterRoleBinding
+
+func (s clusterRoleBindingSorter) Len() int {
+	return len(s)
+}
+func (s clusterRoleBindingSorter) Less(i, j int) bool {
+	return s[i].Name < s[j].Name
+}
+func (s clusterRoleBindingSorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
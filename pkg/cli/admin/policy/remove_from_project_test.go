@@ -0,0 +1,762 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	clienttesting "k8s.io/client-go/testing"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/utils/exec"
+
+	userv1 "github.com/openshift/api/user/v1"
+	fakeuserclient "github.com/openshift/client-go/user/clientset/versioned/fake"
+)
+
+func TestRemoveUserFromProject(t *testing.T) {
+	userSubject := func(name string) rbacv1.Subject {
+		return rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: name}
+	}
+
+	tests := map[string]struct {
+		includeClusterBindings bool
+		includeManaged         bool
+		dryRun                 bool
+		ignoreNotFound         bool
+		users                  []string
+		existing               []runtime.Object
+
+		expectedRoleBindings        map[string][]rbacv1.Subject
+		expectedClusterRoleBindings map[string][]rbacv1.Subject
+		expectedOutput              []string
+		unexpectedOutput            []string
+		expectedExitCode            int
+	}{
+		"namespaced only": {
+			users: []string{"foo"},
+			existing: []runtime.Object{
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{userSubject("foo"), userSubject("bar")},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+					Subjects:   []rbacv1.Subject{userSubject("foo")},
+				},
+			},
+			expectedRoleBindings:        map[string][]rbacv1.Subject{"edit": {userSubject("bar")}},
+			expectedClusterRoleBindings: map[string][]rbacv1.Subject{"admin": {userSubject("foo")}},
+			expectedOutput:              []string{"Removing edit from users [foo] in project ns."},
+		},
+		"include cluster bindings": {
+			includeClusterBindings: true,
+			users:                  []string{"foo"},
+			existing: []runtime.Object{
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{userSubject("foo"), userSubject("bar")},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+					Subjects:   []rbacv1.Subject{userSubject("foo")},
+				},
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "view"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+					Subjects:   []rbacv1.Subject{userSubject("foo"), userSubject("baz")},
+				},
+			},
+			expectedRoleBindings:        map[string][]rbacv1.Subject{"edit": {userSubject("bar")}},
+			expectedClusterRoleBindings: map[string][]rbacv1.Subject{"view": {userSubject("baz")}},
+			expectedOutput: []string{
+				"Removing edit from users [foo] in project ns.",
+				"Removing admin from users [foo] cluster-wide.",
+				"Removing view from users [foo] cluster-wide.",
+			},
+		},
+		"include cluster bindings dry run": {
+			includeClusterBindings: true,
+			dryRun:                 true,
+			users:                  []string{"foo"},
+			existing: []runtime.Object{
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+					Subjects:   []rbacv1.Subject{userSubject("foo")},
+				},
+			},
+			expectedRoleBindings:        map[string][]rbacv1.Subject{},
+			expectedClusterRoleBindings: map[string][]rbacv1.Subject{"admin": {userSubject("foo")}},
+			expectedOutput:              []string{"Removing admin from users [foo] cluster-wide (dry client run)."},
+		},
+		"user not bound": {
+			includeClusterBindings: true,
+			users:                  []string{"missing"},
+			existing: []runtime.Object{
+				&rbacv1.ClusterRoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+					Subjects:   []rbacv1.Subject{userSubject("foo")},
+				},
+			},
+			expectedRoleBindings:        map[string][]rbacv1.Subject{},
+			expectedClusterRoleBindings: map[string][]rbacv1.Subject{"admin": {userSubject("foo")}},
+			expectedOutput:              []string{"Users [missing] were not bound to roles in project ns or cluster-wide."},
+			expectedExitCode:            RemoveExitCodeNoneRemoved,
+		},
+		"managed bindings are skipped": {
+			users: []string{"foo"},
+			existing: []runtime.Object{
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{userSubject("foo"), userSubject("bar")},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: "ns", OwnerReferences: []metav1.OwnerReference{{Kind: "ClusterServiceVersion", Name: "my-operator"}}},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+					Subjects:   []rbacv1.Subject{userSubject("foo")},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "helm", Namespace: "ns", Labels: map[string]string{"app.kubernetes.io/managed-by": "Helm"}},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+					Subjects:   []rbacv1.Subject{userSubject("foo")},
+				},
+			},
+			expectedRoleBindings: map[string][]rbacv1.Subject{
+				"edit":     {userSubject("bar")},
+				"operator": {userSubject("foo")},
+				"helm":     {userSubject("foo")},
+			},
+			expectedOutput: []string{
+				"Warning: skipping rolebinding operator managed by clusterserviceversion/my-operator, use --include-managed to modify it",
+				"Warning: skipping rolebinding helm managed by Helm, use --include-managed to modify it",
+				"Removing edit from users [foo] in project ns.",
+			},
+		},
+		"managed bindings are included": {
+			includeManaged: true,
+			users:          []string{"foo"},
+			existing: []runtime.Object{
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "operator", Namespace: "ns", OwnerReferences: []metav1.OwnerReference{{Kind: "ClusterServiceVersion", Name: "my-operator"}}},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+					Subjects:   []rbacv1.Subject{userSubject("foo"), userSubject("bar")},
+				},
+			},
+			expectedRoleBindings: map[string][]rbacv1.Subject{"operator": {userSubject("bar")}},
+			expectedOutput:       []string{"Removing view from users [foo] in project ns."},
+		},
+		"some users not bound": {
+			users: []string{"foo", "missing"},
+			existing: []runtime.Object{
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{userSubject("foo"), userSubject("bar")},
+				},
+			},
+			expectedRoleBindings: map[string][]rbacv1.Subject{"edit": {userSubject("bar")}},
+			expectedOutput: []string{
+				"Removing edit from users [foo] in project ns.",
+				"Users [missing] were not bound to roles in project ns.",
+			},
+			expectedExitCode: RemoveExitCodePartiallyRemoved,
+		},
+		"some users not bound ignored": {
+			ignoreNotFound: true,
+			users:          []string{"foo", "missing"},
+			existing: []runtime.Object{
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{userSubject("foo"), userSubject("bar")},
+				},
+			},
+			expectedRoleBindings: map[string][]rbacv1.Subject{"edit": {userSubject("bar")}},
+			expectedOutput:       []string{"Removing edit from users [foo] in project ns."},
+			unexpectedOutput:     []string{"were not bound"},
+		},
+		"user not bound ignored": {
+			ignoreNotFound:       true,
+			users:                []string{"missing"},
+			expectedRoleBindings: map[string][]rbacv1.Subject{},
+			unexpectedOutput:     []string{"were not bound"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset(tc.existing...).RbacV1()
+			out := &bytes.Buffer{}
+			o := &RemoveFromProjectOptions{
+				PrintFlags:             genericclioptions.NewPrintFlags(""),
+				Printer:                printers.NewDiscardingPrinter(),
+				BindingNamespace:       "ns",
+				Client:                 client,
+				IncludeClusterBindings: tc.includeClusterBindings,
+				IncludeManaged:         tc.includeManaged,
+				IgnoreNotFound:         tc.ignoreNotFound,
+				Users:                  tc.users,
+				IOStreams:              genericclioptions.IOStreams{Out: out, ErrOut: out},
+			}
+			if tc.dryRun {
+				o.DryRunStrategy = kcmdutil.DryRunClient
+			}
+
+			err := o.Run()
+			switch {
+			case tc.expectedExitCode == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tc.expectedExitCode != 0:
+				exitErr, ok := err.(exec.ExitError)
+				if !ok {
+					t.Fatalf("expected exit error with code %d, got %v", tc.expectedExitCode, err)
+				}
+				if exitErr.ExitStatus() != tc.expectedExitCode {
+					t.Errorf("expected exit code %d, got %d", tc.expectedExitCode, exitErr.ExitStatus())
+				}
+			}
+
+			for _, line := range tc.expectedOutput {
+				if !strings.Contains(out.String(), line) {
+					t.Errorf("expected output to contain %q, got:\n%s", line, out.String())
+				}
+			}
+			for _, line := range tc.unexpectedOutput {
+				if strings.Contains(out.String(), line) {
+					t.Errorf("expected output not to contain %q, got:\n%s", line, out.String())
+				}
+			}
+
+			roleBindings, err := client.RoleBindings("ns").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(roleBindings.Items) != len(tc.expectedRoleBindings) {
+				t.Errorf("expected %d rolebindings, got %d", len(tc.expectedRoleBindings), len(roleBindings.Items))
+			}
+			for bindingName, subjects := range tc.expectedRoleBindings {
+				binding, err := client.RoleBindings("ns").Get(context.TODO(), bindingName, metav1.GetOptions{})
+				if err != nil {
+					t.Errorf("rolebinding %s: %v", bindingName, err)
+					continue
+				}
+				if !reflect.DeepEqual(subjects, binding.Subjects) {
+					t.Errorf("rolebinding %s: expected subjects %v, got %v", bindingName, subjects, binding.Subjects)
+				}
+			}
+
+			for _, obj := range tc.existing {
+				crb, ok := obj.(*rbacv1.ClusterRoleBinding)
+				if !ok {
+					continue
+				}
+				binding, err := client.ClusterRoleBindings().Get(context.TODO(), crb.Name, metav1.GetOptions{})
+				subjects, expected := tc.expectedClusterRoleBindings[crb.Name]
+				switch {
+				case !expected && kapierrors.IsNotFound(err):
+				case !expected:
+					t.Errorf("expected clusterrolebinding %s to be deleted, got %v", crb.Name, err)
+				case err != nil:
+					t.Errorf("clusterrolebinding %s: %v", crb.Name, err)
+				case !reflect.DeepEqual(subjects, binding.Subjects):
+					t.Errorf("clusterrolebinding %s: expected subjects %v, got %v", crb.Name, subjects, binding.Subjects)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveUserFromProjectReason(t *testing.T) {
+	kubeClient := fakeclient.NewSimpleClientset(
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+			Subjects: []rbacv1.Subject{
+				{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"},
+				{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bar"},
+			},
+		},
+	)
+	out := &bytes.Buffer{}
+	o := &RemoveFromProjectOptions{
+		PrintFlags:       genericclioptions.NewPrintFlags(""),
+		Printer:          printers.NewDiscardingPrinter(),
+		BindingNamespace: "ns",
+		Client:           kubeClient.RbacV1(),
+		EventClient:      kubeClient.CoreV1(),
+		Reason:           "JIRA-1234 offboarding",
+		Actor:            "admin",
+		Users:            []string{"foo"},
+		IOStreams:        genericclioptions.IOStreams{Out: out, ErrOut: out},
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	binding, err := kubeClient.RbacV1().RoleBindings("ns").Get(context.TODO(), "edit", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]string{
+		changeReasonAnnotation:    "JIRA-1234 offboarding",
+		changedByAnnotation:       "admin",
+		removedSubjectsAnnotation: "User/foo",
+	} {
+		if actual := binding.Annotations[key]; actual != expected {
+			t.Errorf("expected annotation %s=%q, got %q", key, expected, actual)
+		}
+	}
+	if len(binding.Annotations[changedAtAnnotation]) == 0 {
+		t.Errorf("expected annotation %s to be set", changedAtAnnotation)
+	}
+
+	events, err := kubeClient.CoreV1().Events("ns").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events.Items))
+	}
+	event := events.Items[0]
+	if event.Reason != subjectsRemovedEventReason || event.InvolvedObject.Kind != "RoleBinding" || event.InvolvedObject.Name != "edit" {
+		t.Errorf("unexpected event: %#v", event)
+	}
+	if expected := "admin removed User/foo from rolebinding edit: JIRA-1234 offboarding"; event.Message != expected {
+		t.Errorf("expected event message %q, got %q", expected, event.Message)
+	}
+}
+
+func TestRemoveUserFromProjectJSONSummary(t *testing.T) {
+	client := fakeclient.NewSimpleClientset(&rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+		RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+		Subjects:   []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"}},
+	}).RbacV1()
+	out := &bytes.Buffer{}
+	o := &RemoveFromProjectOptions{
+		PrintFlags:       genericclioptions.NewPrintFlags(""),
+		Printer:          printers.NewDiscardingPrinter(),
+		BindingNamespace: "ns",
+		Client:           client,
+		Users:            []string{"foo", "missing"},
+		Groups:           []string{"devs"},
+		Output:           "json",
+		IOStreams:        genericclioptions.IOStreams{Out: out, ErrOut: out},
+	}
+
+	err := o.Run()
+	exitErr, ok := err.(exec.ExitError)
+	if !ok {
+		t.Fatalf("expected exit error, got %v", err)
+	}
+	if exitErr.ExitStatus() != RemoveExitCodePartiallyRemoved {
+		t.Errorf("expected exit code %d, got %d", RemoveExitCodePartiallyRemoved, exitErr.ExitStatus())
+	}
+	if expected := `{"removed":["foo"],"notFound":["missing","devs"]}`; err.Error() != expected {
+		t.Errorf("expected summary %s, got %s", expected, err.Error())
+	}
+}
+
+// pagingRbacClient serves role bindings from fixed pages keyed by continue token and records every list request.
+type pagingRbacClient struct {
+	rbacv1client.RbacV1Interface
+	pages    map[string]*rbacv1.RoleBindingList
+	requests []metav1.ListOptions
+}
+
+func (c *pagingRbacClient) RoleBindings(namespace string) rbacv1client.RoleBindingInterface {
+	return &pagingRoleBindings{RoleBindingInterface: c.RbacV1Interface.RoleBindings(namespace), client: c}
+}
+
+type pagingRoleBindings struct {
+	rbacv1client.RoleBindingInterface
+	client *pagingRbacClient
+}
+
+func (r *pagingRoleBindings) List(ctx context.Context, opts metav1.ListOptions) (*rbacv1.RoleBindingList, error) {
+	r.client.requests = append(r.client.requests, opts)
+	return r.client.pages[opts.Continue].DeepCopy(), nil
+}
+
+func TestRemoveUserFromProjectPaging(t *testing.T) {
+	binding := func(name, role string) rbacv1.RoleBinding {
+		return rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: role},
+			Subjects:   []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"}},
+		}
+	}
+
+	tests := map[string]struct {
+		roleBindingNames []string
+		expectedOutput   string
+		expectedRequests []metav1.ListOptions
+	}{
+		"all pages": {
+			expectedOutput: "Removing edit from users [foo] in project ns (dry client run).\n" +
+				"Removing view from users [foo] in project ns (dry client run).\n" +
+				"Removing admin from users [foo] in project ns (dry client run).\n",
+			expectedRequests: []metav1.ListOptions{
+				{Limit: 2},
+				{Limit: 2, Continue: "page-2"},
+			},
+		},
+		"named bindings": {
+			roleBindingNames: []string{"view", "view"},
+			expectedOutput:   "Removing view from users [foo] in project ns (dry client run).\n",
+			expectedRequests: []metav1.ListOptions{
+				{Limit: 2, FieldSelector: "metadata.name=view"},
+				{Limit: 2, FieldSelector: "metadata.name=view", Continue: "page-2"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &pagingRbacClient{
+				RbacV1Interface: fakeclient.NewSimpleClientset().RbacV1(),
+				pages: map[string]*rbacv1.RoleBindingList{
+					"": {
+						ListMeta: metav1.ListMeta{Continue: "page-2"},
+						Items:    []rbacv1.RoleBinding{binding("admin", "admin"), binding("edit", "edit")},
+					},
+					"page-2": {
+						Items: []rbacv1.RoleBinding{binding("view", "view")},
+					},
+				},
+			}
+
+			out := &bytes.Buffer{}
+			o := &RemoveFromProjectOptions{
+				PrintFlags:       genericclioptions.NewPrintFlags(""),
+				Printer:          printers.NewDiscardingPrinter(),
+				BindingNamespace: "ns",
+				Client:           client,
+				RoleBindingNames: tc.roleBindingNames,
+				ChunkSize:        2,
+				Users:            []string{"foo"},
+				DryRunStrategy:   kcmdutil.DryRunClient,
+				IOStreams:        genericclioptions.IOStreams{Out: out, ErrOut: out},
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if out.String() != tc.expectedOutput {
+				t.Errorf("expected output:\n%s\ngot:\n%s", tc.expectedOutput, out.String())
+			}
+			if !reflect.DeepEqual(tc.expectedRequests, client.requests) {
+				t.Errorf("expected list requests %#v, got %#v", tc.expectedRequests, client.requests)
+			}
+		})
+	}
+}
+
+func TestRemoveUserFromProjectInteractive(t *testing.T) {
+	foo := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"}
+	binding := func(name string) *rbacv1.RoleBinding {
+		return &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: name},
+			Subjects:   []rbacv1.Subject{foo},
+		}
+	}
+
+	tests := map[string]struct {
+		input           string
+		expectedRemoved []string
+		expectedKept    []string
+	}{
+		"skip and accept": {
+			input:           "n\ny\n",
+			expectedRemoved: []string{"edit"},
+			expectedKept:    []string{"view"},
+		},
+		"help then all": {
+			input:           "?\na\n",
+			expectedRemoved: []string{"view", "edit"},
+		},
+		"quit": {
+			input:        "q\n",
+			expectedKept: []string{"view", "edit"},
+		},
+		"accept then quit": {
+			input:        "y\nq\n",
+			expectedKept: []string{"view", "edit"},
+		},
+		"no input": {
+			expectedKept: []string{"view", "edit"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset(binding("edit"), binding("view")).RbacV1()
+			out := &bytes.Buffer{}
+			o := &RemoveFromProjectOptions{
+				PrintFlags:       genericclioptions.NewPrintFlags(""),
+				Printer:          printers.NewDiscardingPrinter(),
+				BindingNamespace: "ns",
+				Client:           client,
+				Interactive:      true,
+				Users:            []string{"foo"},
+				IOStreams:        genericclioptions.IOStreams{In: strings.NewReader(tc.input), Out: out, ErrOut: out},
+			}
+			// declined bindings leave the user bound, which is not reported as the user not being found
+			if err := o.Run(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if strings.Contains(out.String(), "were not bound") {
+				t.Errorf("expected declined subjects not to be reported as not bound, got:\n%s", out.String())
+			}
+			if quit := strings.Contains(tc.input, "q"); quit != strings.Contains(out.String(), "No bindings were modified.") {
+				t.Errorf("expected quitting to be reported, got:\n%s", out.String())
+			}
+			if !strings.Contains(out.String(), "Remove User/foo from rolebinding view (ClusterRole view) [y,n,a,q,?]? ") {
+				t.Errorf("expected a prompt for rolebinding view, got:\n%s", out.String())
+			}
+			for _, name := range tc.expectedRemoved {
+				if _, err := client.RoleBindings("ns").Get(context.TODO(), name, metav1.GetOptions{}); !kapierrors.IsNotFound(err) {
+					t.Errorf("expected rolebinding %s to be deleted, got %v", name, err)
+				}
+			}
+			for _, name := range tc.expectedKept {
+				if _, err := client.RoleBindings("ns").Get(context.TODO(), name, metav1.GetOptions{}); err != nil {
+					t.Errorf("expected rolebinding %s to be kept, got %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRemoveUserFromProjectSummary(t *testing.T) {
+	foo := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"}
+	bar := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bar"}
+	client := fakeclient.NewSimpleClientset(
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+			Subjects:   []rbacv1.Subject{foo, bar},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "view", Namespace: "ns"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{foo},
+		},
+	)
+	client.PrependReactor("update", "rolebindings", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("conflict")
+	})
+
+	out := &bytes.Buffer{}
+	o := &RemoveFromProjectOptions{
+		PrintFlags:       genericclioptions.NewPrintFlags(""),
+		Printer:          printers.NewDiscardingPrinter(),
+		BindingNamespace: "ns",
+		Client:           client.RbacV1(),
+		Users:            []string{"foo", "missing"},
+		Summary:          "json",
+		IOStreams:        genericclioptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+	}
+	if err := o.Run(); err == nil || err.Error() != "unable to modify 1 binding(s)" {
+		t.Fatalf("expected an error for the failed binding, got %v", err)
+	}
+
+	expected := `{
+  "removed": [
+    "foo"
+  ],
+  "notFound": [
+    "missing"
+  ],
+  "bindings": [
+    {
+      "kind": "RoleBinding",
+      "namespace": "ns",
+      "name": "view",
+      "roleRef": {
+        "apiGroup": "rbac.authorization.k8s.io",
+        "kind": "ClusterRole",
+        "name": "view"
+      },
+      "removed": [
+        {
+          "kind": "User",
+          "apiGroup": "rbac.authorization.k8s.io",
+          "name": "foo"
+        }
+      ],
+      "deleted": true
+    }
+  ],
+  "errors": [
+    "unable to modify rolebinding edit: conflict"
+  ]
+}
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestRemoveUserFromProjectGroups(t *testing.T) {
+	for _, edit := range []bool{false, true} {
+		t.Run(fmt.Sprintf("edit=%v", edit), func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset(&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+				Subjects: []rbacv1.Subject{
+					{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "foo"},
+					{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "devs"},
+				},
+			}).RbacV1()
+			userClient := fakeuserclient.NewSimpleClientset(
+				&userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "devs"}, Users: []string{"bar", "foo"}},
+				&userv1.Group{ObjectMeta: metav1.ObjectMeta{Name: "unbound"}, Users: []string{"foo"}},
+			).UserV1()
+
+			out := &bytes.Buffer{}
+			errOut := &bytes.Buffer{}
+			o := &RemoveFromProjectOptions{
+				PrintFlags:       genericclioptions.NewPrintFlags(""),
+				Printer:          printers.NewDiscardingPrinter(),
+				BindingNamespace: "ns",
+				Client:           client,
+				UserClient:       userClient,
+				CheckGroups:      true,
+				EditGroups:       edit,
+				Users:            []string{"foo"},
+				IOStreams:        genericclioptions.IOStreams{Out: out, ErrOut: errOut},
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			devs, err := userClient.Groups().Get(context.TODO(), "devs", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			unbound, err := userClient.Groups().Get(context.TODO(), "unbound", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(unbound.Users, userv1.OptionalNames{"foo"}) {
+				t.Errorf("expected the unbound group to be left alone, got %v", unbound.Users)
+			}
+
+			if edit {
+				if !reflect.DeepEqual(devs.Users, userv1.OptionalNames{"bar"}) {
+					t.Errorf("expected foo to be removed from devs, got %v", devs.Users)
+				}
+				if expected := "Removing users [foo] from group devs.\n"; !strings.HasSuffix(out.String(), expected) {
+					t.Errorf("expected output to end with %q, got %q", expected, out.String())
+				}
+				return
+			}
+			if !reflect.DeepEqual(devs.Users, userv1.OptionalNames{"bar", "foo"}) {
+				t.Errorf("expected devs to be left alone, got %v", devs.Users)
+			}
+			if expected := "Warning: users [foo] keep access through group devs bound by rolebinding/edit, use --edit-groups to remove them from the group\n"; errOut.String() != expected {
+				t.Errorf("expected %q, got %q", expected, errOut.String())
+			}
+		})
+	}
+}
+
+func TestRemoveUserFromProjectProtected(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	bob := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"}
+
+	tests := map[string]struct {
+		override bool
+
+		expectedErr      string
+		expectedBindings map[string][]rbacv1.Subject
+	}{
+		"protected": {
+			expectedErr: "[refusing to modify rolebinding break-glass protected by annotation policy.openshift.io/protected=true, use --override-protection to modify it, " +
+				"refusing to modify rolebinding admin protected by protected role ClusterRole/admin, use --override-protection to modify it]",
+			expectedBindings: map[string][]rbacv1.Subject{
+				"admin":       {alice, bob},
+				"break-glass": {alice},
+				"edit":        {bob},
+			},
+		},
+		"override": {
+			override: true,
+			expectedBindings: map[string][]rbacv1.Subject{
+				"admin": {bob},
+				"edit":  {bob},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset(
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+					Subjects:   []rbacv1.Subject{alice, bob},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "break-glass", Namespace: "ns", Annotations: map[string]string{protectedAnnotation: "true"}},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{alice},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{alice, bob},
+				},
+			).RbacV1()
+
+			o := &RemoveFromProjectOptions{
+				PrintFlags:         genericclioptions.NewPrintFlags(""),
+				Printer:            printers.NewDiscardingPrinter(),
+				BindingNamespace:   "ns",
+				Client:             client,
+				ProtectedRoles:     []string{"admin"},
+				OverrideProtection: tc.override,
+				Users:              []string{"alice"},
+				IOStreams:          genericclioptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}},
+			}
+			err := o.Run()
+			switch {
+			case len(tc.expectedErr) == 0 && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case len(tc.expectedErr) > 0 && (err == nil || err.Error() != tc.expectedErr):
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+
+			roleBindings, err := client.RoleBindings("ns").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := map[string][]rbacv1.Subject{}
+			for _, binding := range roleBindings.Items {
+				actual[binding.Name] = binding.Subjects
+			}
+			if !reflect.DeepEqual(actual, tc.expectedBindings) {
+				t.Errorf("expected bindings %v, got %v", tc.expectedBindings, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	restoreLong = templates.LongDesc(`
+		Restore subjects removed from role bindings
+
+		The remove-user, remove-group and remove-role-from-* commands write the original version of every binding
+		they modify when --backup-dir or --backup-file is given. This command reads such backups and adds back
+		exactly the subjects that were removed, leaving any other change made to the bindings since in place.
+		Bindings that were deleted because they were left without subjects are re-created.
+	`)
+
+	restoreExample = templates.Examples(`
+		# Undo a removal that was backed up to a file
+		oc adm policy remove-user user1 --backup-file=removed.yaml
+		oc adm policy restore -f removed.yaml
+
+		# Undo all removals backed up to a directory
+		oc adm policy restore -f backups/
+	`)
+)
+
+type RestoreOptions struct {
+	Filenames []string
+
+	// AuditLogFile, when set, receives a record of every change to a binding made on behalf of Actor.
+	AuditLogFile string
+	Actor        string
+	audit        *auditLog
+
+	RbacClient rbacv1client.RbacV1Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewRestoreOptions(streams genericclioptions.IOStreams) *RestoreOptions {
+	return &RestoreOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdRestore implements the OpenShift cli restore command
+func NewCmdRestore(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewRestoreOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "restore -f FILENAME",
+		Short:   "Restore subjects removed from role bindings",
+		Long:    restoreLong,
+		Example: restoreExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames, "Backup file, or directory of backup files, written by --backup-file or --backup-dir.")
+	cmd.MarkFlagRequired("filename")
+	addAuditLogFlag(cmd, &o.AuditLogFile)
+
+	return cmd
+}
+
+func (o *RestoreOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+	if len(o.Filenames) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "at least one backup must be specified with -f")
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.RbacClient, err = rbacv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.Actor, err = auditActor(clientConfig, o.AuditLogFile)
+	return err
+}
+
+func (o *RestoreOptions) Run() error {
+	bindings := []*roleBindingAbstraction{}
+	for _, filename := range o.Filenames {
+		paths, err := backupPaths(filename)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			read, err := o.readBackup(path)
+			if err != nil {
+				return fmt.Errorf("unable to read %s: %v", path, err)
+			}
+			bindings = append(bindings, read...)
+		}
+	}
+
+	audit, err := newAuditLog(o.AuditLogFile, o.Actor, false)
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+	o.audit = audit
+
+	errs := []error{}
+	for _, binding := range bindings {
+		if err := o.restore(binding); err != nil {
+			errs = append(errs, fmt.Errorf("unable to restore %s %s: %v", binding.Type(), binding.Name(), err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// restore adds the subjects recorded in the backed up binding to the binding on the server, re-creating it if it no
+// longer exists.
+func (o *RestoreOptions) restore(backup *roleBindingAbstraction) error {
+	accessor := backup.Object().(metav1.Object)
+	removed, err := restoreSubjects(accessor)
+	if err != nil {
+		return err
+	}
+	scope := fmt.Sprintf("in project %s", backup.Namespace())
+	if len(backup.Namespace()) == 0 {
+		scope = "cluster-wide"
+	}
+
+	current, err := getRoleBindingAbstraction(o.RbacClient, backup.Name(), backup.Namespace())
+	if kapierrors.IsNotFound(err) {
+		delete(accessor.GetAnnotations(), restoreSubjectsAnnotation)
+		accessor.SetResourceVersion("")
+		accessor.SetUID("")
+		accessor.SetCreationTimestamp(metav1.Time{})
+		if err := backup.Create(); err != nil {
+			return err
+		}
+		if err := o.audit.record("create", backup, nil); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "Re-created %s %s with %s %s.\n", backup.Type(), backup.Name(), strings.Join(subjectNames(backup.Subjects()), ", "), scope)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if current.RoleKind() != backup.RoleKind() || current.RoleName() != backup.RoleName() {
+		return fmt.Errorf("it now references %s %s instead of %s %s", current.RoleKind(), current.RoleName(), backup.RoleKind(), backup.RoleName())
+	}
+	existing := current.Subjects()
+	subjects := addSubjects(nil, nil, removed, existing)
+	if len(subjects) == len(existing) {
+		fmt.Fprintf(o.Out, "%s %s already contains %s %s.\n", current.Type(), current.Name(), strings.Join(subjectNames(removed), ", "), scope)
+		return nil
+	}
+	current.SetSubjects(subjects)
+	if err := current.Update(); err != nil {
+		return err
+	}
+	if err := o.audit.record("update", current, existing); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Restored %s to %s %s %s.\n", strings.Join(subjectNames(subjects[len(existing):]), ", "), current.Type(), current.Name(), scope)
+	return nil
+}
+
+// readBackup decodes the role bindings and cluster role bindings in a YAML or JSON file.
+func (o *RestoreOptions) readBackup(path string) ([]*roleBindingAbstraction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readRoleBindings(f, o.RbacClient)
+}
+
+// readRoleBindings decodes the role bindings and cluster role bindings in a stream of YAML or JSON documents,
+// each of which may also be a List of them.
+func readRoleBindings(r io.Reader, client rbacv1client.RbacV1Interface) ([]*roleBindingAbstraction, error) {
+	bindings := []*roleBindingAbstraction{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		obj := map[string]interface{}{}
+		if err := decoder.Decode(&obj); err == io.EOF {
+			return bindings, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(obj) == 0 {
+			continue
+		}
+
+		objs := []map[string]interface{}{obj}
+		if obj["kind"] == "List" {
+			objs = nil
+			items, _ := obj["items"].([]interface{})
+			for _, item := range items {
+				if item, ok := item.(map[string]interface{}); ok {
+					objs = append(objs, item)
+				}
+			}
+		}
+		for _, obj := range objs {
+			switch kind := obj["kind"]; kind {
+			case "RoleBinding":
+				roleBinding := &rbacv1.RoleBinding{}
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, roleBinding); err != nil {
+					return nil, err
+				}
+				bindings = append(bindings, &roleBindingAbstraction{rbacClient: client, roleBinding: roleBinding})
+			case "ClusterRoleBinding":
+				clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, clusterRoleBinding); err != nil {
+					return nil, err
+				}
+				bindings = append(bindings, &roleBindingAbstraction{rbacClient: client, clusterRoleBinding: clusterRoleBinding})
+			default:
+				return nil, fmt.Errorf("unexpected kind %v, only RoleBinding and ClusterRoleBinding are supported", kind)
+			}
+		}
+	}
+}
+
+// backupPaths returns the filename itself, or the YAML and JSON files in it when it is a directory.
+func backupPaths(filename string) ([]string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{filename}, nil
+	}
+
+	entries, err := os.ReadDir(filename)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(filename, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRestoreRemovedSubjects(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	bob := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "bob"}
+	carol := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "carol"}
+
+	for _, useDir := range []bool{false, true} {
+		t.Run(map[bool]string{false: "file", true: "directory"}[useDir], func(t *testing.T) {
+			client := fakeclient.NewSimpleClientset(
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+					Subjects:   []rbacv1.Subject{alice, bob},
+				},
+				&rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: "view", Namespace: "ns"},
+					RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+					Subjects:   []rbacv1.Subject{alice},
+				},
+			).RbacV1()
+
+			backupPath := filepath.Join(t.TempDir(), "backup")
+			remove := &RemoveFromProjectOptions{
+				PrintFlags:       genericclioptions.NewPrintFlags(""),
+				Printer:          printers.NewDiscardingPrinter(),
+				BindingNamespace: "ns",
+				Client:           client,
+				Users:            []string{"alice"},
+				IOStreams:        genericclioptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}},
+			}
+			if useDir {
+				remove.BackupDir = backupPath
+			} else {
+				remove.BackupFile = backupPath
+			}
+			if err := remove.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if useDir {
+				entries, err := os.ReadDir(backupPath)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(entries) != 2 {
+					t.Fatalf("expected one backup file per binding, got %d", len(entries))
+				}
+			}
+
+			// a change made after the removal must survive the restore
+			edit, err := client.RoleBindings("ns").Get(context.TODO(), "edit", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			edit.Subjects = append(edit.Subjects, carol)
+			if _, err := client.RoleBindings("ns").Update(context.TODO(), edit, metav1.UpdateOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			out := &bytes.Buffer{}
+			restore := &RestoreOptions{
+				Filenames:  []string{backupPath},
+				RbacClient: client,
+				IOStreams:  genericclioptions.IOStreams{Out: out, ErrOut: out},
+			}
+			if err := restore.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := map[string][]rbacv1.Subject{
+				"edit": {bob, carol, alice},
+				"view": {alice},
+			}
+			for name, subjects := range expected {
+				binding, err := client.RoleBindings("ns").Get(context.TODO(), name, metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(binding.Subjects, subjects) {
+					t.Errorf("rolebinding %s: expected subjects %v, got %v", name, subjects, binding.Subjects)
+				}
+				if _, ok := binding.Annotations[restoreSubjectsAnnotation]; ok {
+					t.Errorf("rolebinding %s: expected the restore annotation not to be copied to the server", name)
+				}
+			}
+
+			// restoring twice is a no-op
+			if err := restore.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			binding, err := client.RoleBindings("ns").Get(context.TODO(), "edit", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(binding.Subjects, expected["edit"]) {
+				t.Errorf("expected subjects %v after a second restore, got %v", expected["edit"], binding.Subjects)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/authentication/serviceaccount"
@@ -32,7 +33,9 @@ import (
 
 var (
 	reviewLong = templates.LongDesc(`Check which service account can create a pod.
-		The pod is inferred from the pod template spec in the provided resource.
+		The pod is inferred from the pod template spec in the provided resource. Any resource with a pod template
+		is supported, including deployments, stateful sets, daemon sets, cron jobs and custom workload resources
+		such as rollouts that keep their template at spec.template or spec.jobTemplate.spec.template.
 		If no service account is provided the one specified in podTemplateSpec.spec.serviceAccountName is used,
 		unless it is empty, in which case "default" is used.
 		If service accounts are provided, the podTemplateSpec.spec.serviceAccountName is ignored.
@@ -176,7 +179,7 @@ func (o *SCCReviewOptions) Complete(f kcmdutil.Factory, args []string, cmd *cobr
 
 func (o *SCCReviewOptions) Run(args []string) error {
 	r := o.builder.
-		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		Unstructured().
 		NamespaceParam(o.namespace).
 		FilenameParam(o.enforceNamespace, &o.FilenameOptions).
 		ResourceTypeOrNameArgs(true, args...).
@@ -193,11 +196,15 @@ func (o *SCCReviewOptions) Run(args []string) error {
 			return err
 		}
 		objectName := info.Name
-		podTemplateSpec, err := GetPodTemplateForObject(info.Object)
+		obj, err := typedObject(info.Object)
+		if err != nil {
+			return err
+		}
+		podTemplateSpec, err := GetPodTemplateForObject(obj)
 		if err != nil {
 			return fmt.Errorf(" %q cannot create pod: %v", objectName, err)
 		}
-		err = CheckStatefulSetWithWolumeClaimTemplates(info.Object)
+		err = CheckStatefulSetWithWolumeClaimTemplates(obj)
 		if err != nil {
 			return err
 		}
@@ -246,14 +253,66 @@ func CheckStatefulSetWithWolumeClaimTemplates(obj runtime.Object) error {
 	return nil
 }
 
+// GetPodTemplateForObject returns the pod template of an object that creates pods. Objects that are not known to
+// the scheme, such as custom workload resources, are searched for a template at spec.template and
+// spec.jobTemplate.spec.template. The service account of the returned template is the one pods will run as,
+// resolving the deprecated serviceAccount field.
 func GetPodTemplateForObject(obj runtime.Object) (*corev1.PodTemplateSpec, error) {
 	podSpec, _, err := ometa.GetPodSpecV1(obj)
 	if err != nil {
-		return nil, err
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, err
+		}
+		if podSpec, err = getPodSpecForUnstructured(u); err != nil {
+			return nil, err
+		}
+	}
+	podSpec = podSpec.DeepCopy()
+	if len(podSpec.ServiceAccountName) == 0 {
+		podSpec.ServiceAccountName = podSpec.DeprecatedServiceAccount
 	}
 	return &corev1.PodTemplateSpec{Spec: *podSpec}, nil
 }
 
+func getPodSpecForUnstructured(obj *unstructured.Unstructured) (*corev1.PodSpec, error) {
+	for _, path := range [][]string{
+		{"spec", "template"},
+		{"spec", "jobTemplate", "spec", "template"},
+	} {
+		template, found, err := unstructured.NestedMap(obj.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		if _, ok := template["spec"]; !ok {
+			continue
+		}
+		podTemplateSpec := &corev1.PodTemplateSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(template, podTemplateSpec); err != nil {
+			return nil, fmt.Errorf("%s is not a pod template: %v", strings.Join(path, "."), err)
+		}
+		return &podTemplateSpec.Spec, nil
+	}
+	return nil, fmt.Errorf("%s does not contain a pod template", obj.GetKind())
+}
+
+// typedObject converts an unstructured object to its typed form when the kind is registered in the scheme, so that
+// built-in workload kinds are handled the same way whether they are read from files or from the server.
+func typedObject(obj runtime.Object) (runtime.Object, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || !scheme.Scheme.Recognizes(u.GroupVersionKind()) {
+		return obj, nil
+	}
+	typed, err := scheme.Scheme.New(u.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+		return nil, fmt.Errorf("unable to convert %s %q: %v", u.GetKind(), u.GetName(), err)
+	}
+	return typed, nil
+}
+
 func sccReviewHumanPrintFunc(info *resource.Info, obj runtime.Object, noHeaders *bool, out io.Writer) error {
 	w := tabwriter.NewWriter(out, tabWriterMinWidth, tabWriterWidth, tabWriterPadding, tabWriterPadChar, tabWriterFlags)
 	defer w.Flush()
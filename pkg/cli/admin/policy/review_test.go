@@ -0,0 +1,138 @@
+package policy
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGetPodTemplateForObject(t *testing.T) {
+	tests := map[string]struct {
+		obj runtime.Object
+
+		expectedServiceAccount string
+		expectedImage          string
+		expectErr              bool
+	}{
+		"deployment": {
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "app"},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"serviceAccountName": "builder",
+							"containers":         []interface{}{map[string]interface{}{"name": "app", "image": "app:latest"}},
+						},
+					},
+				},
+			}},
+			expectedServiceAccount: "builder",
+			expectedImage:          "app:latest",
+		},
+		"cronjob with deprecated service account": {
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "batch/v1",
+				"kind":       "CronJob",
+				"metadata":   map[string]interface{}{"name": "job"},
+				"spec": map[string]interface{}{
+					"jobTemplate": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"template": map[string]interface{}{
+								"spec": map[string]interface{}{
+									"serviceAccount": "runner",
+									"containers":     []interface{}{map[string]interface{}{"name": "job", "image": "job:latest"}},
+								},
+							},
+						},
+					},
+				},
+			}},
+			expectedServiceAccount: "runner",
+			expectedImage:          "job:latest",
+		},
+		"rollout": {
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "argoproj.io/v1alpha1",
+				"kind":       "Rollout",
+				"metadata":   map[string]interface{}{"name": "canary"},
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{map[string]interface{}{"name": "canary", "image": "canary:latest"}},
+						},
+					},
+				},
+			}},
+			expectedImage: "canary:latest",
+		},
+		"custom resource without template": {
+			obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]interface{}{"name": "widget"},
+				"spec":       map[string]interface{}{"size": int64(3)},
+			}},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			obj, err := typedObject(tc.obj)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			template, err := GetPodTemplateForObject(obj)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got template %#v", template)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if template.Spec.ServiceAccountName != tc.expectedServiceAccount {
+				t.Errorf("expected service account %q, got %q", tc.expectedServiceAccount, template.Spec.ServiceAccountName)
+			}
+			if len(template.Spec.Containers) != 1 || template.Spec.Containers[0].Image != tc.expectedImage {
+				t.Errorf("expected image %q, got %#v", tc.expectedImage, template.Spec.Containers)
+			}
+		})
+	}
+}
+
+func TestTypedObjectStatefulSetVolumeClaimTemplates(t *testing.T) {
+	obj, err := typedObject(&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "StatefulSet",
+		"metadata":   map[string]interface{}{"name": "db"},
+		"spec": map[string]interface{}{
+			"volumeClaimTemplates": []interface{}{map[string]interface{}{"metadata": map[string]interface{}{"name": "data"}}},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := obj.(*appsv1.StatefulSet); !ok {
+		t.Fatalf("expected a typed StatefulSet, got %T", obj)
+	}
+	if err := CheckStatefulSetWithWolumeClaimTemplates(obj); err == nil {
+		t.Errorf("expected an error for a StatefulSet with volumeClaimTemplates")
+	}
+}
+
+func TestGetPodTemplateForObjectDoesNotMutate(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{DeprecatedServiceAccount: "legacy"}}
+	if _, err := GetPodTemplateForObject(pod); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pod.Spec.ServiceAccountName) != 0 {
+		t.Errorf("expected the object to be left unmodified, got serviceAccountName %q", pod.Spec.ServiceAccountName)
+	}
+}
@@ -0,0 +1,321 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	rbacv1client "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	"k8s.io/component-helpers/auth/rbac/validation"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/library-go/pkg/authorization/authorizationutil"
+)
+
+var (
+	simulateLong = templates.LongDesc(`
+		Preview how a change to role bindings affects access
+
+		These commands compute the access a subject has in the current project from its role bindings and cluster
+		role bindings, apply the proposed change in memory, and print every verb and resource the changed bindings
+		granted. Each entry is reported as removed, or as retained together with the bindings that still grant it.
+		Nothing is modified on the server.
+
+		Like remove-user and remove-group, the preview leaves the bindings managed by another object or tool
+		unchanged unless --include-managed is given, and the protected bindings unless --override-protection is
+		given.
+
+		Only bindings that name the subject directly are considered; access granted through group membership is not
+		resolved.
+	`)
+
+	simulateRemoveUserExample = templates.Examples(`
+		# Preview the effect of removing user1 from the current project
+		oc adm policy simulate remove-user user1
+
+		# Preview the effect of removing user1 from the current project and from all cluster role bindings
+		oc adm policy simulate remove-user user1 --include-cluster-bindings
+	`)
+
+	simulateRemoveGroupExample = templates.Examples(`
+		# Preview the effect of removing group1 from the admin binding in the current project
+		oc adm policy simulate remove-group group1 --rolebinding=admin
+	`)
+)
+
+type SimulateOptions struct {
+	BindingNamespace string
+	Client           rbacv1client.RbacV1Interface
+
+	IncludeClusterBindings bool
+	RoleBindingNames       []string
+
+	// IncludeManaged, ProtectedRoles and OverrideProtection select the bindings that are modified, as they do for
+	// RemoveFromProjectOptions.
+	IncludeManaged     bool
+	ProtectedRoles     []string
+	OverrideProtection bool
+
+	Users  []string
+	Groups []string
+
+	genericclioptions.IOStreams
+}
+
+func NewSimulateOptions(streams genericclioptions.IOStreams) *SimulateOptions {
+	return &SimulateOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdSimulate implements the OpenShift cli simulate command
+func NewCmdSimulate(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Preview how a change to role bindings affects access",
+		Long:  simulateLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmd.AddCommand(NewCmdSimulateRemoveUser(f, streams))
+	cmd.AddCommand(NewCmdSimulateRemoveGroup(f, streams))
+	return cmd
+}
+
+// NewCmdSimulateRemoveUser implements the OpenShift cli simulate remove-user command
+func NewCmdSimulateRemoveUser(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewSimulateOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "remove-user USER [USER ...]",
+		Short:   "Preview the access users lose when removed from the project",
+		Long:    simulateLong,
+		Example: simulateRemoveUserExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, args, &o.Users, "user"))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+// NewCmdSimulateRemoveGroup implements the OpenShift cli simulate remove-group command
+func NewCmdSimulateRemoveGroup(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewSimulateOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "remove-group GROUP [GROUP ...]",
+		Short:   "Preview the access groups lose when removed from the project",
+		Long:    simulateLong,
+		Example: simulateRemoveGroupExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, args, &o.Groups, "group"))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *SimulateOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.IncludeClusterBindings, "include-cluster-bindings", o.IncludeClusterBindings, "If true, simulate removing the subjects from cluster role bindings as well.")
+	cmd.Flags().StringSliceVar(&o.RoleBindingNames, "rolebinding", o.RoleBindingNames, "Only simulate removing the subjects from the bindings with these names.")
+	cmd.Flags().BoolVar(&o.IncludeManaged, "include-managed", o.IncludeManaged, "If true, also simulate removing the subjects from bindings that are managed by an operator or another tool.")
+	cmd.Flags().StringSliceVar(&o.ProtectedRoles, "protected-roles", o.ProtectedRoles, "Roles whose bindings must not be modified, in addition to bindings annotated with "+protectedAnnotation+"=true.")
+	cmd.Flags().BoolVar(&o.OverrideProtection, "override-protection", o.OverrideProtection, "If true, also simulate removing the subjects from protected bindings.")
+}
+
+func (o *SimulateOptions) Complete(f kcmdutil.Factory, args []string, target *[]string, targetName string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("you must specify at least one argument: <%s> [%s]...", targetName, targetName)
+	}
+	*target = append(*target, args...)
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.Client, err = rbacv1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	if o.BindingNamespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// boundRules pairs a binding with the rules of the role it references.
+type boundRules struct {
+	binding *roleBindingAbstraction
+	rules   []rbacv1.PolicyRule
+}
+
+func (o *SimulateOptions) Run() error {
+	roleBindings, err := o.Client.RoleBindings(o.BindingNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	clusterRoleBindings, err := o.Client.ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	bindings := []*roleBindingAbstraction{}
+	for i := range roleBindings.Items {
+		bindings = append(bindings, &roleBindingAbstraction{rbacClient: o.Client, roleBinding: &roleBindings.Items[i]})
+	}
+	for i := range clusterRoleBindings.Items {
+		bindings = append(bindings, &roleBindingAbstraction{rbacClient: o.Client, clusterRoleBinding: &clusterRoleBindings.Items[i]})
+	}
+
+	rules := map[string][]rbacv1.PolicyRule{}
+	for i, subject := range authorizationutil.BuildRBACSubjects(o.Users, o.Groups) {
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+		if err := o.simulate(subject, bindings, rules); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *SimulateOptions) simulate(subject rbacv1.Subject, bindings []*roleBindingAbstraction, ruleCache map[string][]rbacv1.PolicyRule) error {
+	removed := []boundRules{}
+	remaining := []boundRules{}
+	for _, binding := range bindings {
+		if _, found := removeSubjects(binding.Subjects(), []rbacv1.Subject{subject}); found == 0 {
+			continue
+		}
+		rules, err := o.rulesFor(binding, ruleCache)
+		if err != nil {
+			return err
+		}
+		if o.changes(binding) {
+			removed = append(removed, boundRules{binding: binding, rules: rules})
+		} else {
+			remaining = append(remaining, boundRules{binding: binding, rules: rules})
+		}
+	}
+
+	fmt.Fprintf(o.Out, "%s in project %s:\n", strings.Join(subjectNames([]rbacv1.Subject{subject}), ""), o.BindingNamespace)
+	if len(removed) == 0 {
+		fmt.Fprintf(o.Out, "No bindings would change.\n")
+		return nil
+	}
+
+	changed := []string{}
+	subrules := map[string]rbacv1.PolicyRule{}
+	for _, r := range removed {
+		changed = append(changed, fmt.Sprintf("%s/%s", r.binding.Type(), r.binding.Name()))
+		for _, rule := range r.rules {
+			for _, subrule := range validation.BreakdownRule(rule) {
+				subrules[describeRule(subrule)] = subrule
+			}
+		}
+	}
+	fmt.Fprintf(o.Out, "Bindings that would change: %s\n\n", strings.Join(changed, ", "))
+
+	keys := make([]string, 0, len(subrules))
+	for key := range subrules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	fmt.Fprintf(w, "ACCESS\tRESULT\tGRANTED BY\n")
+	for _, key := range keys {
+		grantedBy := []string{}
+		for _, r := range remaining {
+			if covered, _ := validation.Covers(r.rules, []rbacv1.PolicyRule{subrules[key]}); covered {
+				grantedBy = append(grantedBy, fmt.Sprintf("%s/%s", r.binding.Type(), r.binding.Name()))
+			}
+		}
+		if len(grantedBy) == 0 {
+			fmt.Fprintf(w, "%s\tremoved\t\n", key)
+			continue
+		}
+		fmt.Fprintf(w, "%s\tretained\t%s\n", key, strings.Join(grantedBy, ","))
+	}
+	return nil
+}
+
+// changes returns true if the proposed change removes the subjects from the binding. Managed and protected bindings
+// are skipped the same way remove-user and remove-group skip them.
+func (o *SimulateOptions) changes(binding *roleBindingAbstraction) bool {
+	if len(binding.Namespace()) == 0 && !o.IncludeClusterBindings {
+		return false
+	}
+	if len(o.RoleBindingNames) > 0 && !sets.NewString(o.RoleBindingNames...).Has(binding.Name()) {
+		return false
+	}
+	if !o.IncludeManaged && len(bindingManager(binding)) > 0 {
+		return false
+	}
+	if !o.OverrideProtection && len(bindingProtection(binding, o.ProtectedRoles)) > 0 {
+		return false
+	}
+	return true
+}
+
+// rulesFor returns the rules of the role referenced by the binding. Missing roles grant nothing.
+func (o *SimulateOptions) rulesFor(binding *roleBindingAbstraction, cache map[string][]rbacv1.PolicyRule) ([]rbacv1.PolicyRule, error) {
+	key := fmt.Sprintf("%s/%s/%s", binding.RoleKind(), binding.Namespace(), binding.RoleName())
+	if rules, ok := cache[key]; ok {
+		return rules, nil
+	}
+
+	var rules []rbacv1.PolicyRule
+	if binding.RoleKind() == "Role" {
+		role, err := o.Client.Roles(binding.Namespace()).Get(context.TODO(), binding.RoleName(), metav1.GetOptions{})
+		switch {
+		case kapierrors.IsNotFound(err):
+		case err != nil:
+			return nil, err
+		default:
+			rules = role.Rules
+		}
+	} else {
+		clusterRole, err := o.Client.ClusterRoles().Get(context.TODO(), binding.RoleName(), metav1.GetOptions{})
+		switch {
+		case kapierrors.IsNotFound(err):
+		case err != nil:
+			return nil, err
+		default:
+			rules = clusterRole.Rules
+		}
+	}
+	if rules == nil {
+		fmt.Fprintf(o.ErrOut, "Warning: %s %q referenced by %s %s not found\n", binding.RoleKind(), binding.RoleName(), binding.Type(), binding.Name())
+	}
+
+	cache[key] = rules
+	return rules, nil
+}
+
+// describeRule formats a rule with a single verb and a single resource or non-resource URL, e.g.
+// "get deployments.apps" or "get /healthz".
+func describeRule(rule rbacv1.PolicyRule) string {
+	verb := strings.Join(rule.Verbs, ",")
+	if len(rule.NonResourceURLs) > 0 {
+		return fmt.Sprintf("%s %s", verb, strings.Join(rule.NonResourceURLs, ","))
+	}
+	resource := strings.Join(rule.Resources, ",")
+	if group := strings.Join(rule.APIGroups, ","); len(group) > 0 {
+		resource += "." + group
+	}
+	if len(rule.ResourceNames) > 0 {
+		resource += "/" + strings.Join(rule.ResourceNames, ",")
+	}
+	return fmt.Sprintf("%s %s", verb, resource)
+}
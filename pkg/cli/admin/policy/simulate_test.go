@@ -0,0 +1,160 @@
+package policy
+
+import (
+	"bytes"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSimulateRemoveUser(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	client := fakeclient.NewSimpleClientset(
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "delete"}},
+			},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "view"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+			},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+			Subjects:   []rbacv1.Subject{alice},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "view-all"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{alice},
+		},
+	).RbacV1()
+
+	tests := map[string]struct {
+		includeClusterBindings bool
+		expected               string
+	}{
+		"cluster binding retains access": {
+			expected: `User/alice in project ns:
+Bindings that would change: rolebinding/edit
+
+ACCESS        RESULT     GRANTED BY
+delete pods   removed    
+get pods      retained   clusterrolebinding/view-all
+`,
+		},
+		"cluster bindings included": {
+			includeClusterBindings: true,
+			expected: `User/alice in project ns:
+Bindings that would change: rolebinding/edit, clusterrolebinding/view-all
+
+ACCESS        RESULT    GRANTED BY
+delete pods   removed   
+get pods      removed   
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &SimulateOptions{
+				BindingNamespace:       "ns",
+				Client:                 client,
+				IncludeClusterBindings: tc.includeClusterBindings,
+				Users:                  []string{"alice"},
+				IOStreams:              genericclioptions.IOStreams{Out: out, ErrOut: out},
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestSimulateSkipsManagedAndProtected(t *testing.T) {
+	alice := rbacv1.Subject{APIGroup: rbacv1.GroupName, Kind: rbacv1.UserKind, Name: "alice"}
+	client := fakeclient.NewSimpleClientset(
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "delete"}},
+			},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "view"},
+			Rules: []rbacv1.PolicyRule{
+				{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get"}},
+			},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "edit", Namespace: "ns", Annotations: map[string]string{protectedAnnotation: "true"}},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "edit"},
+			Subjects:   []rbacv1.Subject{alice},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "view", Namespace: "ns", Labels: map[string]string{"app.kubernetes.io/managed-by": "argocd"}},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "view"},
+			Subjects:   []rbacv1.Subject{alice},
+		},
+	).RbacV1()
+
+	tests := map[string]struct {
+		includeManaged     bool
+		overrideProtection bool
+		expected           string
+	}{
+		"skipped": {
+			expected: "User/alice in project ns:\nNo bindings would change.\n",
+		},
+		"managed included": {
+			includeManaged: true,
+			expected: `User/alice in project ns:
+Bindings that would change: rolebinding/view
+
+ACCESS     RESULT     GRANTED BY
+get pods   retained   rolebinding/edit
+`,
+		},
+		"protection overridden": {
+			overrideProtection: true,
+			expected: `User/alice in project ns:
+Bindings that would change: rolebinding/edit
+
+ACCESS        RESULT     GRANTED BY
+delete pods   removed    
+get pods      retained   rolebinding/view
+`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &SimulateOptions{
+				BindingNamespace:   "ns",
+				Client:             client,
+				IncludeManaged:     tc.includeManaged,
+				OverrideProtection: tc.overrideProtection,
+				Users:              []string{"alice"},
+				IOStreams:          genericclioptions.IOStreams{Out: out, ErrOut: out},
+			}
+			if err := o.Run(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
@@ -28,6 +28,8 @@ import (
 
 var (
 	subjectReviewLong = templates.LongDesc(`Check whether a user, service account or group can create a pod.
+		The pod is inferred from the pod template spec in the provided resource, which can be any resource with a pod
+		template such as a deployment, stateful set, daemon set, cron job or rollout.
 		It returns a list of security context constraints that will admit the resource.
 		If user is specified but not groups, it is interpreted as "what if the user is not a member of any groups".
 		If user and groups are empty, then the check is performed using the current user.
@@ -149,7 +151,7 @@ func (o *SCCSubjectReviewOptions) Run(args []string) error {
 		userOrSA = o.serviceAccount
 	}
 	r := o.builder.
-		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		Unstructured().
 		NamespaceParam(o.namespace).
 		FilenameParam(o.enforceNamespace, &o.FilenameOptions).
 		ResourceTypeOrNameArgs(true, args...).
@@ -168,11 +170,15 @@ func (o *SCCSubjectReviewOptions) Run(args []string) error {
 		}
 		var response runtime.Object
 		objectName := info.Name
-		podTemplateSpec, err := GetPodTemplateForObject(info.Object)
+		obj, err := typedObject(info.Object)
+		if err != nil {
+			return err
+		}
+		podTemplateSpec, err := GetPodTemplateForObject(obj)
 		if err != nil {
 			return fmt.Errorf(" %q cannot create pod: %v", objectName, err)
 		}
-		err = CheckStatefulSetWithWolumeClaimTemplates(info.Object)
+		err = CheckStatefulSetWithWolumeClaimTemplates(obj)
 		if err != nil {
 			return err
 		}
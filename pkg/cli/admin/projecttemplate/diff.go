@@ -0,0 +1,315 @@
+package projecttemplate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/api/annotations"
+	"github.com/openshift/oc/pkg/cli/admin/createbootstrapprojecttemplate"
+)
+
+var (
+	diffLong = templates.LongDesc(`
+		Compare projects with what the project request template creates.
+
+		The template configured for the cluster, or the template file given with --filename, is
+		processed with the name, display name, description and requester of each project, and every
+		object it creates is compared with the object of the project. An object is reported Missing
+		when the project does not have it, and Differs when a field set by the template has another
+		value in the project; fields the template does not set, like the status, are ignored.
+
+		The command fails when a project does not match the template, which happens when the objects
+		were modified after the project was created, or when the template changed since.
+	`)
+
+	diffExample = templates.Examples(`
+		# Compare a project with the project request template of the cluster
+		oc adm project-template diff my-project
+
+		# Compare several projects with a new version of the template
+		oc adm project-template diff my-project other-project -f template.yaml
+	`)
+)
+
+const (
+	objectMatches = "OK"
+	objectMissing = "Missing"
+	objectDiffers = "Differs"
+)
+
+// DiffOptions holds the options to compare projects with the project request template.
+type DiffOptions struct {
+	Source   templateSource
+	Projects []string
+
+	Client        kubernetes.Interface
+	DynamicClient dynamic.Interface
+	Mapper        meta.RESTMapper
+
+	genericclioptions.IOStreams
+}
+
+func NewDiffOptions(streams genericclioptions.IOStreams) *DiffOptions {
+	return &DiffOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdDiff creates a command that compares projects with the project request template.
+func NewCmdDiff(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewDiffOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "diff PROJECT...",
+		Short:   "Compare projects with what the project request template creates",
+		Long:    diffLong,
+		Example: diffExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.Source.AddFlags(cmd)
+	return cmd
+}
+
+func (o *DiffOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "at least one project is required")
+	}
+	o.Projects = args
+
+	if err := o.Source.Complete(f); err != nil {
+		return err
+	}
+	var err error
+	if o.Client, err = f.KubernetesClientSet(); err != nil {
+		return err
+	}
+	if o.DynamicClient, err = f.DynamicClient(); err != nil {
+		return err
+	}
+	o.Mapper, err = f.ToRESTMapper()
+	return err
+}
+
+// objectDiff is the comparison of an object created by the template with the object of a project.
+type objectDiff struct {
+	Object string
+	Status string
+	// Differences are the fields set by the template with another value in the project.
+	Differences []string
+}
+
+func (o *DiffOptions) Run() error {
+	template, source, err := o.Source.Load()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Comparing with %s.\n", source)
+
+	drifted := 0
+	for _, name := range o.Projects {
+		namespace, err := o.Client.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		requester := namespace.Annotations[createbootstrapprojecttemplate.ProjectRequester]
+		objects, err := processTemplate(template, map[string]string{
+			createbootstrapprojecttemplate.ProjectNameParam:        name,
+			createbootstrapprojecttemplate.ProjectDisplayNameParam: namespace.Annotations[annotations.OpenShiftDisplayName],
+			createbootstrapprojecttemplate.ProjectDescriptionParam: namespace.Annotations[annotations.OpenShiftDescription],
+			createbootstrapprojecttemplate.ProjectAdminUserParam:   requester,
+			createbootstrapprojecttemplate.ProjectRequesterParam:   requester,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to process %s for project %s: %v", source, name, err)
+		}
+
+		diffs, err := o.diffProject(name, objects)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "\nProject %s:\n", name)
+		if len(requester) == 0 {
+			fmt.Fprintf(o.Out, "  The project has no requester, it was not created by a project request.\n")
+		}
+		if printDiffs(o.Out, diffs) {
+			drifted++
+		}
+	}
+
+	if drifted > 0 {
+		return fmt.Errorf("%d of %d project(s) do not match %s", drifted, len(o.Projects), source)
+	}
+	return nil
+}
+
+// diffProject compares the objects created by the template, except the project, with those of the project.
+func (o *DiffOptions) diffProject(project string, objects []*unstructured.Unstructured) ([]objectDiff, error) {
+	var diffs []objectDiff
+	for _, object := range objects {
+		gvk := object.GroupVersionKind()
+		if gvk.Kind == "Project" {
+			continue
+		}
+		mapping, err := o.Mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, err
+		}
+		d := objectDiff{Object: fmt.Sprintf("%s/%s", strings.ToLower(gvk.GroupKind().String()), object.GetName())}
+
+		var actual *unstructured.Unstructured
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			actual, err = o.DynamicClient.Resource(mapping.Resource).Namespace(project).Get(context.TODO(), object.GetName(), metav1.GetOptions{})
+		} else {
+			actual, err = o.DynamicClient.Resource(mapping.Resource).Get(context.TODO(), object.GetName(), metav1.GetOptions{})
+		}
+		switch {
+		case kerrors.IsNotFound(err):
+			d.Status = objectMissing
+		case err != nil:
+			return nil, err
+		default:
+			d.Differences = compareObject(object.Object, actual.Object)
+			d.Status = objectMatches
+			if len(d.Differences) > 0 {
+				d.Status = objectDiffers
+			}
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs, nil
+}
+
+// compareObject returns the fields set in the expected object with another value in the actual one. Only the labels
+// and annotations of the metadata are compared, and the status is ignored.
+func compareObject(expected, actual map[string]interface{}) []string {
+	var differences []string
+	for key, value := range expected {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			expectedMetadata, _ := value.(map[string]interface{})
+			actualMetadata, _ := actual[key].(map[string]interface{})
+			for _, field := range []string{"labels", "annotations"} {
+				if expectedField, ok := expectedMetadata[field]; ok {
+					differences = append(differences, compareValue("metadata."+field, expectedField, actualMetadata[field])...)
+				}
+			}
+			continue
+		}
+		differences = append(differences, compareValue(key, value, actual[key])...)
+	}
+	sort.Strings(differences)
+	return differences
+}
+
+// compareValue returns the paths of the values set in expected with another value in actual.
+func compareValue(path string, expected, actual interface{}) []string {
+	switch expected := expected.(type) {
+	case map[string]interface{}:
+		actual, ok := actual.(map[string]interface{})
+		if !ok {
+			if len(expected) == 0 {
+				return nil
+			}
+			return []string{fmt.Sprintf("%s: %s in the template, %s in the project", path, formatValue(expected), formatValue(actual))}
+		}
+		var differences []string
+		for key, value := range expected {
+			differences = append(differences, compareValue(path+"."+key, value, actual[key])...)
+		}
+		return differences
+	case []interface{}:
+		actual, ok := actual.([]interface{})
+		if !ok || len(actual) != len(expected) {
+			return []string{fmt.Sprintf("%s: %s in the template, %s in the project", path, formatValue(expected), formatValue(actual))}
+		}
+		var differences []string
+		for i := range expected {
+			differences = append(differences, compareValue(fmt.Sprintf("%s[%d]", path, i), expected[i], actual[i])...)
+		}
+		return differences
+	case nil:
+		return nil
+	case string:
+		// quantities are normalized by the API server, 1024Mi is stored as 1Gi
+		if actual, ok := actual.(string); ok && equalQuantities(expected, actual) {
+			return nil
+		}
+	}
+	if reflect.DeepEqual(expected, actual) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: %s in the template, %s in the project", path, formatValue(expected), formatValue(actual))}
+}
+
+// equalQuantities returns true if both values are equal quantities.
+func equalQuantities(a, b string) bool {
+	qa, err := resource.ParseQuantity(a)
+	if err != nil {
+		return false
+	}
+	qb, err := resource.ParseQuantity(b)
+	if err != nil {
+		return false
+	}
+	return qa.Cmp(qb) == 0
+}
+
+// formatValue formats a value of an object for a difference.
+func formatValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return fmt.Sprintf("%q", value)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// printDiffs prints a table of the compared objects followed by their differences, and returns true if an object is
+// missing or differs.
+func printDiffs(out io.Writer, diffs []objectDiff) bool {
+	if len(diffs) == 0 {
+		fmt.Fprintf(out, "  The template creates no object other than the project.\n")
+		return false
+	}
+	drifted := false
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "  OBJECT\tSTATUS")
+	for _, d := range diffs {
+		fmt.Fprintf(w, "  %s\t%s\n", d.Object, d.Status)
+		drifted = drifted || d.Status != objectMatches
+	}
+	w.Flush()
+	for _, d := range diffs {
+		if len(d.Differences) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "  %s:\n", d.Object)
+		for _, difference := range d.Differences {
+			fmt.Fprintf(out, "    %s\n", difference)
+		}
+	}
+	return drifted
+}
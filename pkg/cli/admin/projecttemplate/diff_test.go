@@ -0,0 +1,125 @@
+package projecttemplate
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift/oc/pkg/cli/admin/createbootstrapprojecttemplate"
+)
+
+func TestCompareObject(t *testing.T) {
+	expected := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ResourceQuota",
+		"metadata": map[string]interface{}{
+			"name":              "quota",
+			"creationTimestamp": nil,
+			"labels":            map[string]interface{}{"team": "a"},
+		},
+		"spec": map[string]interface{}{
+			"hard": map[string]interface{}{"pods": "20", "requests.memory": "1024Mi", "limits.cpu": "8"},
+		},
+		"status": map[string]interface{}{},
+	}
+	actual := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ResourceQuota",
+		"metadata": map[string]interface{}{
+			"name":              "quota",
+			"creationTimestamp": "2023-10-15T10:00:00Z",
+			"labels":            map[string]interface{}{"team": "b", "other": "label"},
+		},
+		"spec": map[string]interface{}{
+			"hard": map[string]interface{}{"pods": "40", "requests.memory": "1Gi"},
+		},
+		"status": map[string]interface{}{"used": map[string]interface{}{"pods": "3"}},
+	}
+	want := []string{
+		`metadata.labels.team: "a" in the template, "b" in the project`,
+		`spec.hard.limits.cpu: "8" in the template, <none> in the project`,
+		`spec.hard.pods: "20" in the template, "40" in the project`,
+	}
+	if got := compareObject(expected, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDiffProject(t *testing.T) {
+	o := NewGenerateOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.NetworkPolicies = false
+	template, err := o.generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, err := processTemplate(template, map[string]string{
+		createbootstrapprojecttemplate.ProjectNameParam:      "my-project",
+		createbootstrapprojecttemplate.ProjectAdminUserParam: "alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := rbacv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "my-project", Name: "project-quota"},
+		Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+			corev1.ResourcePods:                   resource.MustParse("40"),
+			corev1.ResourceRequestsCPU:            resource.MustParse("4"),
+			corev1.ResourceRequestsMemory:         resource.MustParse("8192Mi"),
+			corev1.ResourceLimitsCPU:              resource.MustParse("8"),
+			corev1.ResourceLimitsMemory:           resource.MustParse("16Gi"),
+			corev1.ResourcePersistentVolumeClaims: resource.MustParse("10"),
+		}},
+	}
+	binding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "my-project", Name: "admin"},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}},
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ResourceQuota"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("LimitRange"), meta.RESTScopeNamespace)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("RoleBinding"), meta.RESTScopeNamespace)
+
+	d := &DiffOptions{
+		Client:        fake.NewSimpleClientset(),
+		DynamicClient: dynamicfake.NewSimpleDynamicClient(scheme, quota, binding),
+		Mapper:        mapper,
+	}
+	diffs, err := d.diffProject("my-project", objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	if !printDiffs(out, diffs) {
+		t.Errorf("expected the project to not match")
+	}
+	want := `  OBJECT                                        STATUS
+  rolebinding.rbac.authorization.k8s.io/admin   OK
+  resourcequota/project-quota                   Differs
+  limitrange/project-limits                     Missing
+  resourcequota/project-quota:
+    spec.hard.pods: "20" in the template, "40" in the project
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,201 @@
+package projecttemplate
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/openshift/oc/pkg/cli/admin/createbootstrapprojecttemplate"
+)
+
+var (
+	generateLong = templates.LongDesc(`
+		Generate a project request template.
+
+		The template creates the project and binds its requester to the admin role, like the default
+		template, and by default also creates:
+
+		* Network policies that allow the traffic from the pods of the project, from the ingress
+		  controllers and from the cluster monitoring, and deny any other incoming traffic.
+		* A resource quota limiting the pods, compute resources and persistent volume claims of the
+		  project.
+		* A limit range giving default requests and limits to the containers that set none.
+
+		Edit the generated template to fit the needs of the cluster, check it with
+		"oc adm project-template validate -f", then create it in the openshift-config namespace and
+		reference it from the projectRequestTemplate of the project.config.openshift.io/cluster
+		resource.
+	`)
+
+	generateExample = templates.Examples(`
+		# Generate a project request template with network policies, a quota and a limit range
+		oc adm project-template generate > template.yaml
+
+		# Generate a project request template with network policies only
+		oc adm project-template generate --quota=false --limit-range=false
+	`)
+)
+
+// GenerateOptions holds the options to generate a project request template.
+type GenerateOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
+	Name            string
+	NetworkPolicies bool
+	Quota           bool
+	LimitRange      bool
+
+	Printer printers.ResourcePrinter
+
+	genericclioptions.IOStreams
+}
+
+func NewGenerateOptions(streams genericclioptions.IOStreams) *GenerateOptions {
+	return &GenerateOptions{
+		PrintFlags:      genericclioptions.NewPrintFlags("generated").WithTypeSetter(scheme.Scheme).WithDefaultOutput("yaml"),
+		Name:            createbootstrapprojecttemplate.DefaultTemplateName,
+		NetworkPolicies: true,
+		Quota:           true,
+		LimitRange:      true,
+		IOStreams:       streams,
+	}
+}
+
+// NewCmdGenerate creates a command that generates a project request template.
+func NewCmdGenerate(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewGenerateOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "generate",
+		Short:   "Generate a project request template",
+		Long:    generateLong,
+		Example: generateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Name, "name", o.Name, "The name of the template to generate.")
+	cmd.Flags().BoolVar(&o.NetworkPolicies, "network-policies", o.NetworkPolicies, "If true, the template creates network policies isolating the project.")
+	cmd.Flags().BoolVar(&o.Quota, "quota", o.Quota, "If true, the template creates a resource quota.")
+	cmd.Flags().BoolVar(&o.LimitRange, "limit-range", o.LimitRange, "If true, the template creates a limit range.")
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *GenerateOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	var err error
+	o.Printer, err = o.PrintFlags.ToPrinter()
+	return err
+}
+
+func (o *GenerateOptions) Validate() error {
+	if len(o.Name) == 0 {
+		return errors.New("--name must be provided")
+	}
+	return nil
+}
+
+func (o *GenerateOptions) Run() error {
+	template, err := o.generate()
+	if err != nil {
+		return err
+	}
+	return o.Printer.PrintObj(template, o.Out)
+}
+
+// generate returns the default template extended with the selected objects.
+func (o *GenerateOptions) generate() (*templatev1.Template, error) {
+	template := createbootstrapprojecttemplate.DefaultTemplate()
+	template.Name = o.Name
+
+	namespace := "${" + createbootstrapprojecttemplate.ProjectNameParam + "}"
+	var objects []runtime.Object
+	if o.NetworkPolicies {
+		objects = append(objects,
+			networkPolicy(namespace, "allow-same-namespace", networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{}}),
+			networkPolicy(namespace, "allow-from-openshift-ingress", networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"policy-group.network.openshift.io/ingress": ""},
+			}}),
+			networkPolicy(namespace, "allow-from-openshift-monitoring", networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"network.openshift.io/policy-group": "monitoring"},
+			}}),
+		)
+	}
+	if o.Quota {
+		objects = append(objects, &corev1.ResourceQuota{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ResourceQuota"},
+			ObjectMeta: metav1.ObjectMeta{Name: "project-quota", Namespace: namespace},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard: corev1.ResourceList{
+					corev1.ResourcePods:                   resource.MustParse("20"),
+					corev1.ResourceRequestsCPU:            resource.MustParse("4"),
+					corev1.ResourceRequestsMemory:         resource.MustParse("8Gi"),
+					corev1.ResourceLimitsCPU:              resource.MustParse("8"),
+					corev1.ResourceLimitsMemory:           resource.MustParse("16Gi"),
+					corev1.ResourcePersistentVolumeClaims: resource.MustParse("10"),
+				},
+			},
+		})
+	}
+	if o.LimitRange {
+		objects = append(objects, &corev1.LimitRange{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "LimitRange"},
+			ObjectMeta: metav1.ObjectMeta{Name: "project-limits", Namespace: namespace},
+			Spec: corev1.LimitRangeSpec{
+				Limits: []corev1.LimitRangeItem{{
+					Type: corev1.LimitTypeContainer,
+					Default: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+					DefaultRequest: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("256Mi"),
+					},
+				}},
+			},
+		})
+	}
+
+	for _, object := range objects {
+		raw, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		template.Objects = append(template.Objects, runtime.RawExtension{Raw: raw})
+	}
+	return template, nil
+}
+
+// networkPolicy returns a network policy selecting all the pods of the namespace and allowing the traffic from the
+// peer.
+func networkPolicy(namespace, name string, from networkingv1.NetworkPolicyPeer) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: []networkingv1.NetworkPolicyPeer{from}}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
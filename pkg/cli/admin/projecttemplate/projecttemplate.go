@@ -0,0 +1,141 @@
+package projecttemplate
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	templatev1client "github.com/openshift/client-go/template/clientset/versioned"
+	"github.com/openshift/library-go/pkg/template/generator"
+	"github.com/openshift/library-go/pkg/template/templateprocessing"
+	"github.com/openshift/oc/pkg/cli/admin/createbootstrapprojecttemplate"
+)
+
+var (
+	projectTemplateLong = templates.LongDesc(`
+		Manage the project request template
+
+		The project request template defines the objects created with each project requested with
+		"oc new-project". These commands generate a template, validate it, and compare the objects
+		of existing projects with what the template creates.`)
+)
+
+// templateNamespace is the namespace of the project request template configured for the cluster.
+const templateNamespace = "openshift-config"
+
+func NewCmdProjectTemplate(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "project-template",
+		Short: "Generate, validate and compare the project request template",
+		Long:  projectTemplateLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdGenerate(f, streams))
+	cmds.AddCommand(NewCmdValidate(f, streams))
+	cmds.AddCommand(NewCmdDiff(f, streams))
+	return cmds
+}
+
+// templateSource loads the project request template from a file or from the configuration of the cluster.
+type templateSource struct {
+	Filename string
+
+	ConfigClient   configv1client.Interface
+	TemplateClient templatev1client.Interface
+}
+
+func (s *templateSource) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&s.Filename, "filename", "f", s.Filename, "A file containing the template, instead of the template configured for the cluster.")
+}
+
+func (s *templateSource) Complete(f kcmdutil.Factory) error {
+	if len(s.Filename) > 0 {
+		return nil
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if s.ConfigClient, err = configv1client.NewForConfig(config); err != nil {
+		return err
+	}
+	s.TemplateClient, err = templatev1client.NewForConfig(config)
+	return err
+}
+
+// Load returns the template and a description of where it comes from. The default template of the cluster is
+// returned when no template is configured.
+func (s *templateSource) Load() (*templatev1.Template, string, error) {
+	if len(s.Filename) > 0 {
+		data, err := ioutil.ReadFile(s.Filename)
+		if err != nil {
+			return nil, "", err
+		}
+		template := &templatev1.Template{}
+		if err := yaml.Unmarshal(data, template); err != nil {
+			return nil, "", fmt.Errorf("unable to parse the template of %s: %v", s.Filename, err)
+		}
+		return template, s.Filename, nil
+	}
+
+	var name string
+	config, err := s.ConfigClient.ConfigV1().Projects().Get(context.TODO(), "cluster", metav1.GetOptions{})
+	switch {
+	case kerrors.IsNotFound(err):
+	case err != nil:
+		return nil, "", err
+	default:
+		name = config.Spec.ProjectRequestTemplate.Name
+	}
+	if len(name) == 0 {
+		return createbootstrapprojecttemplate.DefaultTemplate(), "the default template (no project request template is configured)", nil
+	}
+	template, err := s.TemplateClient.TemplateV1().Templates(templateNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get the project request template %s/%s: %v", templateNamespace, name, err)
+	}
+	return template, fmt.Sprintf("template %s/%s", templateNamespace, name), nil
+}
+
+// processTemplate processes the template locally with the given parameter values and returns its objects.
+func processTemplate(template *templatev1.Template, values map[string]string) ([]*unstructured.Unstructured, error) {
+	template = template.DeepCopy()
+	for i := range template.Parameters {
+		if value, ok := values[template.Parameters[i].Name]; ok {
+			template.Parameters[i].Value = value
+			template.Parameters[i].Generate = ""
+		}
+	}
+	processor := templateprocessing.NewProcessor(map[string]generator.Generator{
+		"expression": generator.NewExpressionValueGenerator(rand.New(rand.NewSource(time.Now().UnixNano()))),
+	})
+	if errs := processor.Process(template); len(errs) > 0 {
+		return nil, errs.ToAggregate()
+	}
+
+	var objects []*unstructured.Unstructured
+	for i, item := range template.Objects {
+		object, ok := item.Object.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unable to process object %d of the template", i+1)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
@@ -0,0 +1,174 @@
+package projecttemplate
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/openshift/oc/pkg/cli/admin/createbootstrapprojecttemplate"
+)
+
+var (
+	validateLong = templates.LongDesc(`
+		Validate a project request template.
+
+		The template configured for the cluster is validated, unless a template file is given with
+		--filename. The template is reported invalid when a project request would fail with it: it
+		does not create exactly one project named after the PROJECT_NAME parameter, it references a
+		parameter it does not define, one of its objects has no name, or it requires a parameter that
+		a project request does not provide. A warning is printed for the objects with a fixed
+		namespace, which are created in the requested project anyway.
+	`)
+
+	validateExample = templates.Examples(`
+		# Validate the project request template of the cluster
+		oc adm project-template validate
+
+		# Validate a template file before configuring it
+		oc adm project-template validate -f template.yaml
+	`)
+)
+
+// projectParameters are the parameters a project request provides to the template.
+var projectParameters = sets.NewString(
+	createbootstrapprojecttemplate.ProjectNameParam,
+	createbootstrapprojecttemplate.ProjectDisplayNameParam,
+	createbootstrapprojecttemplate.ProjectDescriptionParam,
+	createbootstrapprojecttemplate.ProjectAdminUserParam,
+	createbootstrapprojecttemplate.ProjectRequesterParam,
+)
+
+// parameterReferenceRegexp matches the ${NAME} and ${{NAME}} parameter references of a template.
+var parameterReferenceRegexp = regexp.MustCompile(`\$\{\{?([a-zA-Z0-9_]+)\}?\}`)
+
+// ValidateOptions holds the options to validate a project request template.
+type ValidateOptions struct {
+	Source templateSource
+
+	genericclioptions.IOStreams
+}
+
+func NewValidateOptions(streams genericclioptions.IOStreams) *ValidateOptions {
+	return &ValidateOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdValidate creates a command that validates a project request template.
+func NewCmdValidate(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewValidateOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "validate",
+		Short:   "Validate a project request template",
+		Long:    validateLong,
+		Example: validateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.Source.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ValidateOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	return o.Source.Complete(f)
+}
+
+func (o *ValidateOptions) Run() error {
+	template, source, err := o.Source.Load()
+	if err != nil {
+		return err
+	}
+	errs, warnings := validateTemplate(template)
+	for _, warning := range warnings {
+		fmt.Fprintf(o.ErrOut, "warning: %s\n", warning)
+	}
+	for _, err := range errs {
+		fmt.Fprintf(o.ErrOut, "error: %s\n", err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s is invalid, project requests would fail", source)
+	}
+	fmt.Fprintf(o.Out, "%s is valid\n", source)
+	return nil
+}
+
+// validateTemplate returns the problems of the template that make project requests fail, and the warnings.
+func validateTemplate(template *templatev1.Template) ([]string, []string) {
+	var errs, warnings []string
+
+	defined := sets.NewString()
+	for _, parameter := range template.Parameters {
+		defined.Insert(parameter.Name)
+		if parameter.Required && len(parameter.Value) == 0 && len(parameter.Generate) == 0 && !projectParameters.Has(parameter.Name) {
+			errs = append(errs, fmt.Sprintf("parameter %s is required and has no value, project requests do not provide it", parameter.Name))
+		}
+	}
+
+	projects := 0
+	for i, item := range template.Objects {
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(item.Raw); err != nil {
+			errs = append(errs, fmt.Sprintf("object %d is invalid: %v", i+1, err))
+			continue
+		}
+		description := fmt.Sprintf("%s %s", object.GetKind(), object.GetName())
+		if len(object.GetName()) == 0 && len(object.GetGenerateName()) == 0 {
+			description = fmt.Sprintf("object %d (%s)", i+1, object.GetKind())
+			errs = append(errs, fmt.Sprintf("%s has no name", description))
+		}
+
+		referenced := sets.NewString()
+		for _, match := range parameterReferenceRegexp.FindAllStringSubmatch(string(item.Raw), -1) {
+			referenced.Insert(match[1])
+		}
+		for _, name := range referenced.Difference(defined).List() {
+			errs = append(errs, fmt.Sprintf("%s references parameter %s, which is not defined", description, name))
+		}
+
+		if object.GetKind() == "Project" {
+			projects++
+			if object.GetName() != "${"+createbootstrapprojecttemplate.ProjectNameParam+"}" {
+				errs = append(errs, fmt.Sprintf("%s must be named ${%s}", description, createbootstrapprojecttemplate.ProjectNameParam))
+			}
+			continue
+		}
+		if namespace := object.GetNamespace(); len(namespace) > 0 && !parameterReferenceRegexp.MatchString(namespace) {
+			warnings = append(warnings, fmt.Sprintf("%s has namespace %s, it is created in the requested project instead", description, namespace))
+		}
+	}
+	switch {
+	case projects == 0:
+		errs = append(errs, "the template has no Project object")
+	case projects > 1:
+		errs = append(errs, fmt.Sprintf("the template has %d Project objects, it must have one", projects))
+	}
+
+	if len(errs) == 0 {
+		if _, err := processTemplate(template, sampleParameterValues); err != nil {
+			errs = append(errs, fmt.Sprintf("the template cannot be processed: %v", err))
+		}
+	}
+	return errs, warnings
+}
+
+// sampleParameterValues are parameter values like those of a project request.
+var sampleParameterValues = map[string]string{
+	createbootstrapprojecttemplate.ProjectNameParam:        "sample-project",
+	createbootstrapprojecttemplate.ProjectDisplayNameParam: "Sample Project",
+	createbootstrapprojecttemplate.ProjectDescriptionParam: "A sample project",
+	createbootstrapprojecttemplate.ProjectAdminUserParam:   "sample-user",
+	createbootstrapprojecttemplate.ProjectRequesterParam:   "sample-user",
+}
@@ -0,0 +1,113 @@
+package projecttemplate
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/openshift/oc/pkg/cli/admin/createbootstrapprojecttemplate"
+)
+
+func testTemplate(parameters []string, objects ...string) *templatev1.Template {
+	template := &templatev1.Template{}
+	for _, name := range parameters {
+		template.Parameters = append(template.Parameters, templatev1.Parameter{Name: name})
+	}
+	for _, object := range objects {
+		template.Objects = append(template.Objects, runtime.RawExtension{Raw: []byte(object)})
+	}
+	return template
+}
+
+const testProject = `{"apiVersion":"project.openshift.io/v1","kind":"Project","metadata":{"name":"${PROJECT_NAME}"}}`
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name         string
+		template     *templatev1.Template
+		wantErrs     []string
+		wantWarnings []string
+	}{
+		{
+			name:     "default template",
+			template: createbootstrapprojecttemplate.DefaultTemplate(),
+		},
+		{
+			name: "no project",
+			template: testTemplate([]string{"PROJECT_NAME"},
+				`{"apiVersion":"v1","kind":"ResourceQuota","metadata":{"name":"quota","namespace":"${PROJECT_NAME}"}}`,
+			),
+			wantErrs: []string{"the template has no Project object"},
+		},
+		{
+			name: "project with a fixed name",
+			template: testTemplate([]string{"PROJECT_NAME"},
+				`{"apiVersion":"project.openshift.io/v1","kind":"Project","metadata":{"name":"fixed"}}`,
+			),
+			wantErrs: []string{"Project fixed must be named ${PROJECT_NAME}"},
+		},
+		{
+			name: "undefined parameter and fixed namespace",
+			template: testTemplate([]string{"PROJECT_NAME"},
+				testProject,
+				`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"owner","namespace":"default"},"data":{"team":"${TEAM}","replicas":"${{REPLICAS}}"}}`,
+			),
+			wantErrs: []string{
+				"ConfigMap owner references parameter REPLICAS, which is not defined",
+				"ConfigMap owner references parameter TEAM, which is not defined",
+			},
+			wantWarnings: []string{"ConfigMap owner has namespace default, it is created in the requested project instead"},
+		},
+		{
+			name: "required parameter and unnamed object",
+			template: func() *templatev1.Template {
+				template := testTemplate([]string{"PROJECT_NAME"},
+					testProject,
+					`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"namespace":"${PROJECT_NAME}"}}`,
+				)
+				template.Parameters = append(template.Parameters, templatev1.Parameter{Name: "TEAM", Required: true})
+				return template
+			}(),
+			wantErrs: []string{
+				"parameter TEAM is required and has no value, project requests do not provide it",
+				"object 2 (ConfigMap) has no name",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, warnings := validateTemplate(tt.template)
+			if !reflect.DeepEqual(errs, tt.wantErrs) {
+				t.Errorf("got errors %q, want %q", errs, tt.wantErrs)
+			}
+			if !reflect.DeepEqual(warnings, tt.wantWarnings) {
+				t.Errorf("got warnings %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	o := NewGenerateOptions(genericclioptions.NewTestIOStreamsDiscard())
+	template, err := o.generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(template.Objects) != 7 {
+		t.Errorf("expected 7 objects, got %d", len(template.Objects))
+	}
+	if errs, warnings := validateTemplate(template); len(errs) > 0 || len(warnings) > 0 {
+		t.Errorf("the generated template is invalid: %q %q", errs, warnings)
+	}
+
+	o.NetworkPolicies, o.Quota = false, false
+	if template, err = o.generate(); err != nil {
+		t.Fatal(err)
+	}
+	if len(template.Objects) != 3 {
+		t.Errorf("expected 3 objects, got %d", len(template.Objects))
+	}
+}
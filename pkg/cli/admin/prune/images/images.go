@@ -99,7 +99,10 @@ var (
 	  oc adm prune images --registry-url=http://registry.example.org --confirm
 
 	  # Force a secure connection with a custom certificate authority to the particular registry host name
-	  oc adm prune images --registry-url=registry.example.org --certificate-authority=/path/to/custom/ca.crt --confirm`)
+	  oc adm prune images --registry-url=registry.example.org --certificate-authority=/path/to/custom/ca.crt --confirm
+
+	  # Report the image streams, tags, layers and blob sizes the prune would remove, with totals per namespace
+	  oc adm prune images --keep-tag-revisions=3 --keep-younger-than=60m -o json`)
 )
 
 var (
@@ -124,6 +127,7 @@ type PruneImagesOptions struct {
 	PruneRegistry       *bool
 	IgnoreInvalidRefs   bool
 	NumWorkers          *int
+	Output              string
 
 	ClientConfig       *restclient.Config
 	AppsClient         appsv1client.AppsV1Interface
@@ -173,6 +177,7 @@ func NewCmdPruneImages(f kcmdutil.Factory, streams genericclioptions.IOStreams)
 	cmd.Flags().BoolVar(opts.PruneRegistry, "prune-registry", *opts.PruneRegistry, "If false, the prune operation will clean up image API objects, but the none of the associated content in the registry is removed.  Note, if only image API objects are cleaned up through use of this flag, the only means for subsequently cleaning up registry data corresponding to those image API objects is to employ the 'hard prune' administrative task.")
 	cmd.Flags().BoolVar(&opts.IgnoreInvalidRefs, "ignore-invalid-refs", opts.IgnoreInvalidRefs, "If true, the pruning process will ignore all errors while parsing image references. This means that the pruning process will ignore the intended connection between the object and the referenced image. As a result an image may be incorrectly deleted as unused.")
 	cmd.Flags().IntVar(opts.NumWorkers, "num-workers", *opts.NumWorkers, "Specify the number of parallel workers to use when running prune operations.")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", opts.Output, "Output format. One of: json|yaml. Prints a report of the image streams, tags, images, layers and blobs that would be, or with --confirm were, pruned instead of describing every deletion.")
 
 	return cmd
 }
@@ -267,6 +272,11 @@ func (o PruneImagesOptions) Validate() error {
 	if len(o.CABundle) > 0 && strings.HasPrefix(o.RegistryUrlOverride, "http://") {
 		return fmt.Errorf("--cerificate-authority cannot be specified for insecure http protocol")
 	}
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be one of json or yaml")
+	}
 	return nil
 }
 
@@ -498,11 +508,19 @@ func (o PruneImagesOptions) Run() error {
 		return fmt.Errorf("failed to build graph - no changes made")
 	}
 
-	imageStreamDeleter := &describingImageStreamDeleter{w: o.Out, errOut: o.ErrOut}
-	layerLinkDeleter := &describingLayerLinkDeleter{w: o.Out, errOut: o.ErrOut}
-	manifestDeleter := &describingManifestDeleter{w: o.Out, errOut: o.ErrOut}
-	blobDeleter := &describingBlobDeleter{w: o.Out, errOut: o.ErrOut}
-	imageDeleter := &describingImageDeleter{w: o.Out, errOut: o.ErrOut}
+	// with a structured report, the deletions are only described in the report
+	out, summaryOut := o.Out, o.Out
+	var report *pruneReport
+	if len(o.Output) > 0 {
+		report = newPruneReport(allImages, allStreamsMap, !o.Confirm)
+		out, summaryOut = io.Discard, o.ErrOut
+	}
+
+	imageStreamDeleter := &describingImageStreamDeleter{w: out, errOut: o.ErrOut, report: report}
+	layerLinkDeleter := &describingLayerLinkDeleter{w: out, errOut: o.ErrOut, report: report}
+	manifestDeleter := &describingManifestDeleter{w: out, errOut: o.ErrOut, report: report}
+	blobDeleter := &describingBlobDeleter{w: out, errOut: o.ErrOut, report: report}
+	imageDeleter := &describingImageDeleter{w: out, errOut: o.ErrOut, report: report}
 
 	if o.Confirm {
 		imageStreamDeleter.delegate = imageprune.NewImageStreamDeleter(o.ImageClient)
@@ -515,7 +533,7 @@ func (o PruneImagesOptions) Run() error {
 	}
 
 	if o.PruneRegistry != nil && !*o.PruneRegistry {
-		fmt.Fprintln(o.Out, "Only API objects will be removed.  No modifications to the image registry will be made.")
+		fmt.Fprintln(summaryOut, "Only API objects will be removed.  No modifications to the image registry will be made.")
 	}
 
 	stats, errs := pruner.Prune(
@@ -525,7 +543,12 @@ func (o PruneImagesOptions) Run() error {
 		blobDeleter,
 		imageDeleter,
 	)
-	fmt.Fprintf(o.Out, "Summary: %s\n", stats)
+	fmt.Fprintf(summaryOut, "Summary: %s\n", stats)
+	if report != nil {
+		if err := report.print(o.Out, o.Output); err != nil {
+			return err
+		}
+	}
 	return errs
 }
 
@@ -565,6 +588,7 @@ type describingImageStreamDeleter struct {
 	w        io.Writer
 	delegate imageprune.ImageStreamDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.ImageStreamDeleter = &describingImageStreamDeleter{}
@@ -581,6 +605,7 @@ func (p *describingImageStreamDeleter) UpdateImageStream(stream *imagev1.ImageSt
 	fmt.Fprintf(p.w, "Deleting %d items from image stream %s/%s\n", deletedItems, stream.Namespace, stream.Name)
 
 	if p.delegate == nil {
+		p.report.addImageStream(stream, nil)
 		return stream, nil
 	}
 
@@ -588,6 +613,7 @@ func (p *describingImageStreamDeleter) UpdateImageStream(stream *imagev1.ImageSt
 	if err != nil {
 		fmt.Fprintf(p.errOut, "error updating image stream %s/%s to remove image references: %v\n", stream.Namespace, stream.Name, err)
 	}
+	p.report.addImageStream(stream, err)
 
 	return updatedStream, err
 }
@@ -598,6 +624,7 @@ type describingImageDeleter struct {
 	w        io.Writer
 	delegate imageprune.ImageDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.ImageDeleter = &describingImageDeleter{}
@@ -606,6 +633,7 @@ func (p *describingImageDeleter) DeleteImage(image *imagev1.Image) error {
 	fmt.Fprintf(p.w, "Deleting image %s\n", image.Name)
 
 	if p.delegate == nil {
+		p.report.addImage(image, nil)
 		return nil
 	}
 
@@ -613,6 +641,7 @@ func (p *describingImageDeleter) DeleteImage(image *imagev1.Image) error {
 	if err != nil {
 		fmt.Fprintf(p.errOut, "error deleting image %s from server: %v\n", image.Name, err)
 	}
+	p.report.addImage(image, err)
 
 	return err
 }
@@ -623,6 +652,7 @@ type describingLayerLinkDeleter struct {
 	w        io.Writer
 	delegate imageprune.LayerLinkDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.LayerLinkDeleter = &describingLayerLinkDeleter{}
@@ -631,6 +661,7 @@ func (p *describingLayerLinkDeleter) DeleteLayerLink(repo, name string) error {
 	fmt.Fprintf(p.w, "Deleting layer link %s in repository %s\n", name, repo)
 
 	if p.delegate == nil {
+		p.report.addLayerLink(repo, name, nil)
 		return nil
 	}
 
@@ -638,6 +669,7 @@ func (p *describingLayerLinkDeleter) DeleteLayerLink(repo, name string) error {
 	if err != nil {
 		fmt.Fprintf(p.errOut, "error deleting repository %s layer link %s from the registry: %v\n", repo, name, err)
 	}
+	p.report.addLayerLink(repo, name, err)
 
 	return err
 }
@@ -648,6 +680,7 @@ type describingBlobDeleter struct {
 	w        io.Writer
 	delegate imageprune.BlobDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.BlobDeleter = &describingBlobDeleter{}
@@ -656,6 +689,7 @@ func (p *describingBlobDeleter) DeleteBlob(layer string) error {
 	fmt.Fprintf(p.w, "Deleting blob %s\n", layer)
 
 	if p.delegate == nil {
+		p.report.addBlob(layer, nil)
 		return nil
 	}
 
@@ -663,6 +697,7 @@ func (p *describingBlobDeleter) DeleteBlob(layer string) error {
 	if err != nil {
 		fmt.Fprintf(p.errOut, "error deleting blob %s from the registry: %v\n", layer, err)
 	}
+	p.report.addBlob(layer, err)
 
 	return err
 }
@@ -674,6 +709,7 @@ type describingManifestDeleter struct {
 	w        io.Writer
 	delegate imageprune.ManifestDeleter
 	errOut   io.Writer
+	report   *pruneReport
 }
 
 var _ imageprune.ManifestDeleter = &describingManifestDeleter{}
@@ -682,6 +718,7 @@ func (p *describingManifestDeleter) DeleteManifest(repo, manifest string) error
 	fmt.Fprintf(p.w, "Deleting manifest link %s in repository %s\n", manifest, repo)
 
 	if p.delegate == nil {
+		p.report.addManifest(repo, manifest, nil)
 		return nil
 	}
 
@@ -689,6 +726,7 @@ func (p *describingManifestDeleter) DeleteManifest(repo, manifest string) error
 	if err != nil {
 		fmt.Fprintf(p.errOut, "error deleting manifest link %s from repository %s: %v\n", manifest, repo, err)
 	}
+	p.report.addManifest(repo, manifest, err)
 
 	return err
 }
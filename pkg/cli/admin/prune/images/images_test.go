@@ -14,6 +14,7 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/kubectl/pkg/scheme"
 
 	"github.com/openshift/api"
+	imagev1 "github.com/openshift/api/image/v1"
 	fakeappsclient "github.com/openshift/client-go/apps/clientset/versioned/fake"
 	fakeappsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1/fake"
 	fakebuildclient "github.com/openshift/client-go/build/clientset/versioned/fake"
@@ -317,3 +319,90 @@ func TestValidateRegistryURL(t *testing.T) {
 		}
 	}
 }
+
+func TestPruneReport(t *testing.T) {
+	images := map[string]*imagev1.Image{
+		"sha256:old": {
+			ObjectMeta:        metav1.ObjectMeta{Name: "sha256:old"},
+			DockerImageLayers: []imagev1.ImageLayer{{Name: "sha256:layer-a", LayerSize: 100}, {Name: "sha256:layer-b", LayerSize: 20}},
+		},
+	}
+	streams := map[string]*imagev1.ImageStream{
+		"foo/app": {
+			ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "app"},
+			Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+				{Tag: "latest", Items: []imagev1.TagEvent{{Image: "sha256:new"}, {Image: "sha256:old"}}},
+				{Tag: "old", Items: []imagev1.TagEvent{{Image: "sha256:old"}}},
+			}},
+		},
+	}
+	report := newPruneReport(images, streams, true)
+
+	updated := streams["foo/app"].DeepCopy()
+	updated.Status.Tags = []imagev1.NamedTagEventList{{Tag: "latest", Items: []imagev1.TagEvent{{Image: "sha256:new"}}}}
+	if _, err := (&describingImageStreamDeleter{w: io.Discard, report: report}).UpdateImageStream(updated, 2); err != nil {
+		t.Fatal(err)
+	}
+	blobDeleter := &describingBlobDeleter{w: io.Discard, report: report}
+	for _, blob := range []string{"sha256:layer-b", "sha256:layer-a"} {
+		if err := blobDeleter.DeleteBlob(blob); err != nil {
+			t.Fatal(err)
+		}
+	}
+	layerLinkDeleter := &describingLayerLinkDeleter{w: io.Discard, report: report}
+	for _, link := range []string{"sha256:layer-b", "sha256:layer-a"} {
+		if err := layerLinkDeleter.DeleteLayerLink("foo/app", link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := (&describingManifestDeleter{w: io.Discard, report: report}).DeleteManifest("foo/app", "sha256:old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&describingImageDeleter{w: io.Discard, report: report}).DeleteImage(images["sha256:old"]); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if err := report.print(out, "yaml"); err != nil {
+		t.Fatal(err)
+	}
+	expected := `blobs:
+- digest: sha256:layer-a
+  size: 100
+- digest: sha256:layer-b
+  size: 20
+dryRun: true
+imageStreams:
+- name: app
+  namespace: foo
+  tags:
+  - deleted: false
+    images:
+    - sha256:old
+    tag: latest
+  - deleted: true
+    images:
+    - sha256:old
+    tag: old
+images:
+- name: sha256:old
+  size: 120
+layerLinks:
+- digest: sha256:layer-a
+  repository: foo/app
+  size: 100
+- digest: sha256:layer-b
+  repository: foo/app
+  size: 20
+manifests:
+- digest: sha256:old
+  repository: foo/app
+namespaces:
+- bytes: 120
+  namespace: foo
+totalBytes: 120
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,228 @@
+package images
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+// pruneReport records what the prune deleted, or would delete on a dry run, for printing with --output.
+// The deleters are called from several workers, so it is safe for concurrent use.
+type pruneReport struct {
+	lock sync.Mutex
+	// streams and layerSizes are the image streams and blob sizes known before pruning
+	streams    map[string]*imagev1.ImageStream
+	layerSizes map[string]int64
+
+	DryRun       bool                   `json:"dryRun"`
+	ImageStreams []imageStreamReport    `json:"imageStreams"`
+	Images       []imageReport          `json:"images"`
+	LayerLinks   []layerLinkReport      `json:"layerLinks"`
+	Manifests    []manifestReport       `json:"manifests"`
+	Blobs        []blobReport           `json:"blobs"`
+	Namespaces   []namespaceTotalReport `json:"namespaces"`
+	// TotalBytes is the size of the deleted blobs, the storage reclaimed in the registry.
+	TotalBytes int64 `json:"totalBytes"`
+}
+
+type imageStreamReport struct {
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Tags      []tagReport `json:"tags"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// tagReport lists the images removed from the history of a tag. Deleted is true if the whole tag was removed.
+type tagReport struct {
+	Tag     string   `json:"tag"`
+	Images  []string `json:"images"`
+	Deleted bool     `json:"deleted"`
+}
+
+type imageReport struct {
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	Error string `json:"error,omitempty"`
+}
+
+type layerLinkReport struct {
+	Repository string `json:"repository"`
+	Digest     string `json:"digest"`
+	Size       int64  `json:"size"`
+	Error      string `json:"error,omitempty"`
+}
+
+type manifestReport struct {
+	Repository string `json:"repository"`
+	Digest     string `json:"digest"`
+	Error      string `json:"error,omitempty"`
+}
+
+type blobReport struct {
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+	Error  string `json:"error,omitempty"`
+}
+
+// namespaceTotalReport is the size of the layers unlinked from the repositories of a namespace. A blob shared by
+// several namespaces counts towards each of them.
+type namespaceTotalReport struct {
+	Namespace string `json:"namespace"`
+	Bytes     int64  `json:"bytes"`
+}
+
+func newPruneReport(images map[string]*imagev1.Image, streams map[string]*imagev1.ImageStream, dryRun bool) *pruneReport {
+	r := &pruneReport{
+		streams:      streams,
+		layerSizes:   map[string]int64{},
+		DryRun:       dryRun,
+		ImageStreams: []imageStreamReport{},
+		Images:       []imageReport{},
+		LayerLinks:   []layerLinkReport{},
+		Manifests:    []manifestReport{},
+		Blobs:        []blobReport{},
+		Namespaces:   []namespaceTotalReport{},
+	}
+	for _, image := range images {
+		for _, layer := range image.DockerImageLayers {
+			r.layerSizes[layer.Name] = layer.LayerSize
+		}
+	}
+	return r
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// addImageStream records the tag history removed from stream, comparing it with the stream before pruning.
+func (r *pruneReport) addImageStream(stream *imagev1.ImageStream, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	remaining := map[string]map[string]bool{}
+	for _, tag := range stream.Status.Tags {
+		remaining[tag.Tag] = map[string]bool{}
+		for _, item := range tag.Items {
+			remaining[tag.Tag][item.Image] = true
+		}
+	}
+	report := imageStreamReport{Namespace: stream.Namespace, Name: stream.Name, Tags: []tagReport{}, Error: errorString(err)}
+	if original, ok := r.streams[fmt.Sprintf("%s/%s", stream.Namespace, stream.Name)]; ok {
+		for _, tag := range original.Status.Tags {
+			images, found := remaining[tag.Tag]
+			removed := tagReport{Tag: tag.Tag, Images: []string{}, Deleted: !found}
+			for _, item := range tag.Items {
+				if !images[item.Image] {
+					removed.Images = append(removed.Images, item.Image)
+				}
+			}
+			if removed.Deleted || len(removed.Images) > 0 {
+				report.Tags = append(report.Tags, removed)
+			}
+		}
+	}
+	r.ImageStreams = append(r.ImageStreams, report)
+}
+
+func (r *pruneReport) addImage(image *imagev1.Image, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var size int64
+	for _, layer := range image.DockerImageLayers {
+		size += layer.LayerSize
+	}
+	r.Images = append(r.Images, imageReport{Name: image.Name, Size: size, Error: errorString(err)})
+}
+
+func (r *pruneReport) addLayerLink(repo, digest string, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.LayerLinks = append(r.LayerLinks, layerLinkReport{Repository: repo, Digest: digest, Size: r.layerSizes[digest], Error: errorString(err)})
+}
+
+func (r *pruneReport) addManifest(repo, digest string, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.Manifests = append(r.Manifests, manifestReport{Repository: repo, Digest: digest, Error: errorString(err)})
+}
+
+func (r *pruneReport) addBlob(digest string, err error) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.Blobs = append(r.Blobs, blobReport{Digest: digest, Size: r.layerSizes[digest], Error: errorString(err)})
+}
+
+// complete sorts the entries and computes the totals of the deletions that succeeded.
+func (r *pruneReport) complete() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	sort.Slice(r.ImageStreams, func(i, j int) bool {
+		a, b := r.ImageStreams[i], r.ImageStreams[j]
+		return a.Namespace < b.Namespace || (a.Namespace == b.Namespace && a.Name < b.Name)
+	})
+	sort.Slice(r.Images, func(i, j int) bool { return r.Images[i].Name < r.Images[j].Name })
+	sort.Slice(r.LayerLinks, func(i, j int) bool {
+		a, b := r.LayerLinks[i], r.LayerLinks[j]
+		return a.Repository < b.Repository || (a.Repository == b.Repository && a.Digest < b.Digest)
+	})
+	sort.Slice(r.Manifests, func(i, j int) bool {
+		a, b := r.Manifests[i], r.Manifests[j]
+		return a.Repository < b.Repository || (a.Repository == b.Repository && a.Digest < b.Digest)
+	})
+	sort.Slice(r.Blobs, func(i, j int) bool { return r.Blobs[i].Digest < r.Blobs[j].Digest })
+
+	namespaces := map[string]int64{}
+	for _, link := range r.LayerLinks {
+		if len(link.Error) > 0 {
+			continue
+		}
+		namespace := link.Repository
+		if i := strings.Index(namespace, "/"); i >= 0 {
+			namespace = namespace[:i]
+		}
+		namespaces[namespace] += link.Size
+	}
+	r.Namespaces = []namespaceTotalReport{}
+	for namespace, bytes := range namespaces {
+		r.Namespaces = append(r.Namespaces, namespaceTotalReport{Namespace: namespace, Bytes: bytes})
+	}
+	sort.Slice(r.Namespaces, func(i, j int) bool { return r.Namespaces[i].Namespace < r.Namespaces[j].Namespace })
+
+	r.TotalBytes = 0
+	for _, blob := range r.Blobs {
+		if len(blob.Error) == 0 {
+			r.TotalBytes += blob.Size
+		}
+	}
+}
+
+// print writes the report in the output format, json or yaml.
+func (r *pruneReport) print(out io.Writer, format string) error {
+	r.complete()
+	return cmdutil.PrintJSONOrYAML(out, format, r)
+}
@@ -0,0 +1,224 @@
+package pods
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	podsLongDesc = templates.LongDesc(`
+		Prune completed, failed and evicted pods.
+
+		Pods that succeeded or failed, including evicted pods, are removed once they finished longer ago than
+		--keep-younger-than. The candidates can be limited to the namespaces matching --namespace-selector and to
+		the pods with one of the given status reasons, like Evicted. --max-per-namespace removes at most that many
+		pods, the oldest first, from each namespace in one run. Static pods are never removed.
+
+		By default, the prune operation performs a dry run making no changes. A --confirm flag is needed for
+		changes to be effective.
+	`)
+
+	podsExample = templates.Examples(`
+		# Dry run deleting the pods that completed or failed more than an hour ago
+		oc adm prune pods
+
+		# Delete the evicted pods of the namespaces labeled team=web that were evicted more than a day ago
+		oc adm prune pods --reason=Evicted --namespace-selector=team=web --keep-younger-than=24h --confirm
+
+		# Delete at most 100 completed pods per namespace
+		oc adm prune pods --phase=Succeeded --max-per-namespace=100 --confirm
+	`)
+)
+
+// PrunePodsOptions holds all the required options for pruning pods.
+type PrunePodsOptions struct {
+	Confirm           bool
+	KeepYoungerThan   time.Duration
+	Phases            []string
+	Reasons           []string
+	NamespaceSelector string
+	MaxPerNamespace   int
+	Namespace         string
+
+	PodClient       corev1client.PodsGetter
+	NamespaceClient corev1client.NamespacesGetter
+
+	genericclioptions.IOStreams
+}
+
+func NewPrunePodsOptions(streams genericclioptions.IOStreams) *PrunePodsOptions {
+	return &PrunePodsOptions{
+		Confirm:         false,
+		KeepYoungerThan: 60 * time.Minute,
+		Phases:          []string{string(corev1.PodSucceeded), string(corev1.PodFailed)},
+		IOStreams:       streams,
+	}
+}
+
+// NewCmdPrunePods implements the OpenShift cli prune pods command.
+func NewCmdPrunePods(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewPrunePodsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "pods",
+		Short:   "Remove completed, failed and evicted pods",
+		Long:    podsLongDesc,
+		Example: podsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, specify that pod pruning should proceed. Defaults to false, displaying what would be deleted but not actually deleting anything.")
+	cmd.Flags().DurationVar(&o.KeepYoungerThan, "keep-younger-than", o.KeepYoungerThan, "Specify the minimum time since a pod finished for it to be considered a candidate for pruning.")
+	cmd.Flags().StringSliceVar(&o.Phases, "phase", o.Phases, "Pod phases to prune, Succeeded and/or Failed. Evicted pods are in the Failed phase.")
+	cmd.Flags().StringSliceVar(&o.Reasons, "reason", o.Reasons, "Only prune the pods with one of these status reasons, like Evicted.")
+	cmd.Flags().StringVar(&o.NamespaceSelector, "namespace-selector", o.NamespaceSelector, "Only prune the pods in the namespaces matching this label selector.")
+	cmd.Flags().IntVar(&o.MaxPerNamespace, "max-per-namespace", o.MaxPerNamespace, "Prune at most this many pods, the oldest first, in each namespace. Defaults to no limit.")
+
+	return cmd
+}
+
+// Complete turns a partially defined PrunePodsOptions into a solvent structure
+// which can be validated and used for pruning pods.
+func (o *PrunePodsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+
+	o.Namespace = metav1.NamespaceAll
+	if cmd.Flags().Lookup("namespace").Changed {
+		var err error
+		o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return err
+		}
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	client, err := corev1client.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.PodClient = client
+	o.NamespaceClient = client
+
+	return nil
+}
+
+// Validate ensures that a PrunePodsOptions is valid and can be used to execute pruning.
+func (o PrunePodsOptions) Validate() error {
+	if o.KeepYoungerThan < 0 {
+		return fmt.Errorf("--keep-younger-than must be greater than or equal to 0")
+	}
+	if o.MaxPerNamespace < 0 {
+		return fmt.Errorf("--max-per-namespace must be greater than or equal to 0")
+	}
+	if len(o.Phases) == 0 {
+		return fmt.Errorf("--phase must list at least one phase")
+	}
+	for _, phase := range o.Phases {
+		if phase != string(corev1.PodSucceeded) && phase != string(corev1.PodFailed) {
+			return fmt.Errorf("--phase must be Succeeded or Failed, not %q", phase)
+		}
+	}
+	return nil
+}
+
+// Run contains all the necessary functionality for the OpenShift cli prune pods command.
+func (o PrunePodsOptions) Run() error {
+	var namespaces sets.String
+	if len(o.NamespaceSelector) > 0 {
+		namespaceList, err := o.NamespaceClient.Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: o.NamespaceSelector})
+		if err != nil {
+			return err
+		}
+		namespaces = sets.NewString()
+		for _, namespace := range namespaceList.Items {
+			namespaces.Insert(namespace.Name)
+		}
+	}
+
+	podList, err := o.PodClient.Pods(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	pods := []*corev1.Pod{}
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+
+	phases := []corev1.PodPhase{}
+	for _, phase := range o.Phases {
+		phases = append(phases, corev1.PodPhase(phase))
+	}
+	options := PrunerOptions{
+		KeepYoungerThan: o.KeepYoungerThan,
+		Phases:          phases,
+		Reasons:         o.Reasons,
+		Namespaces:      namespaces,
+		MaxPerNamespace: o.MaxPerNamespace,
+		Pods:            pods,
+	}
+	pruner := NewPruner(options)
+
+	w := tabwriter.NewWriter(o.Out, 10, 4, 3, ' ', 0)
+	defer w.Flush()
+
+	podDeleter := &describingPodDeleter{w: w}
+
+	if o.Confirm {
+		podDeleter.delegate = NewPodDeleter(o.PodClient)
+	} else {
+		fmt.Fprintln(o.ErrOut, "Dry run enabled - no modifications will be made. Add --confirm to remove pods")
+	}
+
+	return pruner.Prune(podDeleter)
+}
+
+// describingPodDeleter prints information about each pod it removes.
+// If a delegate exists, its DeletePod function is invoked prior to returning.
+type describingPodDeleter struct {
+	w             io.Writer
+	delegate      PodDeleter
+	headerPrinted bool
+}
+
+var _ PodDeleter = &describingPodDeleter{}
+
+func (p *describingPodDeleter) DeletePod(pod *corev1.Pod) error {
+	if !p.headerPrinted {
+		p.headerPrinted = true
+		fmt.Fprintln(p.w, "NAMESPACE\tNAME\tPHASE\tREASON\tFINISHED")
+	}
+
+	fmt.Fprintf(p.w, "%s\t%s\t%s\t%s\t%s ago\n", pod.Namespace, pod.Name, pod.Status.Phase, pod.Status.Reason, duration.HumanDuration(time.Since(finishedAt(pod))))
+
+	if p.delegate == nil {
+		return nil
+	}
+
+	if err := p.delegate.DeletePod(pod); err != nil {
+		return err
+	}
+
+	return nil
+}
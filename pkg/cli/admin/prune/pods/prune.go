@@ -0,0 +1,141 @@
+package pods
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// mirrorPodAnnotation marks the API objects of static pods, which the kubelet recreates when they are deleted.
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+type Pruner interface {
+	// Prune is responsible for actual removal of pods identified as candidates
+	// for pruning based on pruning algorithm.
+	Prune(deleter PodDeleter) error
+}
+
+type PodDeleter interface {
+	DeletePod(pod *corev1.Pod) error
+}
+
+// PrunerOptions contains the fields used to initialize a new Pruner.
+type PrunerOptions struct {
+	// KeepYoungerThan is the minimum time since a pod finished for it to be a candidate for pruning.
+	KeepYoungerThan time.Duration
+	// Phases are the pod phases that are candidates for pruning.
+	Phases []corev1.PodPhase
+	// Reasons, if not empty, limits the candidates to the pods with one of these status reasons, like Evicted.
+	Reasons []string
+	// Namespaces, if not nil, limits the candidates to the pods in these namespaces.
+	Namespaces sets.String
+	// MaxPerNamespace, if greater than zero, is the number of pods pruned at most in each namespace, oldest first.
+	MaxPerNamespace int
+	// Pods is the entire list of pods to consider.
+	Pods []*corev1.Pod
+}
+
+// pruner is an object that knows how to prune a data set
+type pruner struct {
+	pods []*corev1.Pod
+}
+
+var _ Pruner = &pruner{}
+
+// NewPruner returns a Pruner over specified data using specified options.
+func NewPruner(options PrunerOptions) Pruner {
+	klog.V(1).Infof("Creating pod pruner with keepYoungerThan=%v, phases=%v, reasons=%v, maxPerNamespace=%v",
+		options.KeepYoungerThan, options.Phases, options.Reasons, options.MaxPerNamespace)
+
+	phases := sets.NewString()
+	for _, phase := range options.Phases {
+		phases.Insert(string(phase))
+	}
+	reasons := sets.NewString(options.Reasons...)
+	threshold := time.Now().Add(-options.KeepYoungerThan)
+
+	candidates := []*corev1.Pod{}
+	for _, pod := range options.Pods {
+		switch {
+		case !phases.Has(string(pod.Status.Phase)):
+		case reasons.Len() > 0 && !reasons.Has(pod.Status.Reason):
+		case options.Namespaces != nil && !options.Namespaces.Has(pod.Namespace):
+		case len(pod.Annotations[mirrorPodAnnotation]) > 0:
+		case !finishedAt(pod).Before(threshold):
+		default:
+			candidates = append(candidates, pod)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return finishedAt(a).Before(finishedAt(b))
+	})
+
+	if options.MaxPerNamespace > 0 {
+		limited := []*corev1.Pod{}
+		perNamespace := map[string]int{}
+		for _, pod := range candidates {
+			if perNamespace[pod.Namespace] < options.MaxPerNamespace {
+				perNamespace[pod.Namespace]++
+				limited = append(limited, pod)
+			}
+		}
+		candidates = limited
+	}
+
+	return &pruner{pods: candidates}
+}
+
+// finishedAt returns when the last container of the pod terminated. Pods evicted before their containers started
+// have no termination time and use their start or creation time instead.
+func finishedAt(pod *corev1.Pod) time.Time {
+	var finished time.Time
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if terminated := status.State.Terminated; terminated != nil && terminated.FinishedAt.After(finished) {
+			finished = terminated.FinishedAt.Time
+		}
+	}
+	if !finished.IsZero() {
+		return finished
+	}
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+	return pod.CreationTimestamp.Time
+}
+
+// Prune will visit each item in the prunable set and invoke the associated PodDeleter.
+func (p *pruner) Prune(deleter PodDeleter) error {
+	for _, pod := range p.pods {
+		if err := deleter.DeletePod(pod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewPodDeleter creates a new podDeleter.
+func NewPodDeleter(client corev1client.PodsGetter) PodDeleter {
+	return &podDeleter{
+		client: client,
+	}
+}
+
+type podDeleter struct {
+	client corev1client.PodsGetter
+}
+
+var _ PodDeleter = &podDeleter{}
+
+func (c *podDeleter) DeletePod(pod *corev1.Pod) error {
+	return c.client.Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})
+}
@@ -0,0 +1,122 @@
+package pods
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type mockDeleteRecorder struct {
+	set sets.String
+}
+
+var _ PodDeleter = &mockDeleteRecorder{}
+
+func (m *mockDeleteRecorder) DeletePod(pod *corev1.Pod) error {
+	m.set.Insert(pod.Namespace + "/" + pod.Name)
+	return nil
+}
+
+func mockPod(namespace, name string, phase corev1.PodPhase, reason string, finished time.Time) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, CreationTimestamp: metav1.NewTime(finished.Add(-time.Hour))},
+		Status:     corev1.PodStatus{Phase: phase, Reason: reason},
+	}
+	if reason == "Evicted" {
+		pod.Status.StartTime = &metav1.Time{Time: finished}
+		return pod
+	}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(finished.Add(-time.Minute))}}},
+		{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(finished)}}},
+	}
+	return pod
+}
+
+func TestPruneTask(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+	older := now.Add(-3 * time.Hour)
+
+	mirror := mockPod("kube-system", "static", corev1.PodSucceeded, "", old)
+	mirror.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+
+	pods := []*corev1.Pod{
+		mockPod("a", "succeeded", corev1.PodSucceeded, "", old),
+		mockPod("a", "failed", corev1.PodFailed, "Error", older),
+		mockPod("a", "evicted", corev1.PodFailed, "Evicted", old),
+		mockPod("a", "recent", corev1.PodSucceeded, "", now.Add(-time.Minute)),
+		mockPod("a", "running", corev1.PodRunning, "", old),
+		mockPod("b", "evicted", corev1.PodFailed, "Evicted", older),
+		mirror,
+	}
+
+	tests := []struct {
+		name     string
+		options  PrunerOptions
+		expected []string
+	}{
+		{
+			name:     "completed and failed",
+			options:  PrunerOptions{Phases: []corev1.PodPhase{corev1.PodSucceeded, corev1.PodFailed}},
+			expected: []string{"a/succeeded", "a/failed", "a/evicted", "a/recent", "b/evicted"},
+		},
+		{
+			name:     "keep younger than",
+			options:  PrunerOptions{KeepYoungerThan: time.Hour, Phases: []corev1.PodPhase{corev1.PodSucceeded, corev1.PodFailed}},
+			expected: []string{"a/succeeded", "a/failed", "a/evicted", "b/evicted"},
+		},
+		{
+			name:     "succeeded only",
+			options:  PrunerOptions{KeepYoungerThan: time.Hour, Phases: []corev1.PodPhase{corev1.PodSucceeded}},
+			expected: []string{"a/succeeded"},
+		},
+		{
+			name:     "evicted only",
+			options:  PrunerOptions{Phases: []corev1.PodPhase{corev1.PodFailed}, Reasons: []string{"Evicted"}},
+			expected: []string{"a/evicted", "b/evicted"},
+		},
+		{
+			name:     "selected namespaces",
+			options:  PrunerOptions{Phases: []corev1.PodPhase{corev1.PodFailed}, Namespaces: sets.NewString("b")},
+			expected: []string{"b/evicted"},
+		},
+		{
+			name:     "oldest first per namespace",
+			options:  PrunerOptions{KeepYoungerThan: time.Hour, Phases: []corev1.PodPhase{corev1.PodSucceeded, corev1.PodFailed}, MaxPerNamespace: 1},
+			expected: []string{"a/failed", "b/evicted"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := test.options
+			options.Pods = pods
+			recorder := &mockDeleteRecorder{set: sets.NewString()}
+			if err := NewPruner(options).Prune(recorder); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected := sets.NewString(test.expected...); !recorder.set.Equal(expected) {
+				t.Errorf("expected deletions %v, got %v", expected.List(), recorder.set.List())
+			}
+		})
+	}
+}
+
+func TestFinishedAt(t *testing.T) {
+	finished := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	for _, reason := range []string{"", "Evicted"} {
+		pod := mockPod("a", "pod", corev1.PodFailed, reason, finished)
+		if got := finishedAt(pod); !reflect.DeepEqual(got, finished) {
+			t.Errorf("reason %q: expected %v, got %v", reason, finished, got)
+		}
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(finished)}}
+	if got := finishedAt(pod); !got.Equal(finished) {
+		t.Errorf("expected creation time %v, got %v", finished, got)
+	}
+}
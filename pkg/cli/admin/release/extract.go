@@ -80,7 +80,10 @@ func NewExtract(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.
 			You may pass a PGP private key file with --signing-key which will create an ASCII
 			armored sha256sum.txt.asc file describing the content that was extracted that is
 			signed by the key. For more advanced signing, use the generated sha256sum.txt and an
-			external tool like gpg.
+			external tool like gpg. To extract the tools of several platforms in one run, pass
+			--platforms with a list of OS/ARCH pairs like linux/amd64,darwin/arm64,windows/amd64.
+			An archive is created for each tool and platform, and all of them are listed in
+			sha256sum.txt.
 
 			The --credentials-requests flag filters extracted manifests to only cloud credential
 			requests. The --cloud flag further filters credential requests to a specific cloud.
@@ -100,6 +103,9 @@ func NewExtract(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.
 			# Use git to check out the source code for the current cluster release to DIR
 			oc adm release extract --git=DIR
 
+			# Extract the client and installer archives for Linux, Apple silicon Macs and Windows
+			oc adm release extract --tools --platforms=linux/amd64,darwin/arm64,windows/amd64 --to=DIR
+
 			# Extract cloud credential requests for AWS
 			oc adm release extract --credentials-requests --cloud=aws
 
@@ -130,6 +136,7 @@ func NewExtract(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.
 
 	flags.StringVar(&o.Command, "command", o.Command, "Specify 'oc' or 'openshift-install' to extract the client for your operating system.")
 	flags.StringVar(&o.CommandOperatingSystem, "command-os", o.CommandOperatingSystem, "Override which operating system command is extracted (mac, windows, linux). You map specify '*' to extract all tool archives.")
+	flags.StringSliceVar(&o.Platforms, "platforms", o.Platforms, "Extract archives of the tools, or of --command, for these OS/ARCH platforms, like linux/amd64,darwin/arm64,windows/amd64.")
 	flags.StringVar(&o.FileDir, "dir", o.FileDir, "The directory on disk that file:// images will be copied under.")
 
 	flags.BoolVar(&o.CredentialsRequests, "credentials-requests", o.CredentialsRequests, "Extract credential request manifests only")
@@ -156,6 +163,7 @@ type ExtractOptions struct {
 	Tools                  bool
 	Command                string
 	CommandOperatingSystem string
+	Platforms              []string
 	SigningKey             string
 
 	// CredentialsRequests if true, results in only credential request manifests getting extracted.
@@ -222,6 +230,18 @@ func (o *ExtractOptions) Run() error {
 		return fmt.Errorf("--output is only supported with --git")
 	}
 
+	if len(o.Platforms) > 0 {
+		if !o.Tools && len(o.Command) == 0 {
+			return fmt.Errorf("--platforms is only supported with --tools or --command")
+		}
+		if len(o.CommandOperatingSystem) > 0 {
+			return fmt.Errorf("--platforms and --command-os may not both be specified")
+		}
+		if _, err := parseToolsPlatforms(o.Platforms); err != nil {
+			return err
+		}
+	}
+
 	if !o.CredentialsRequests && len(o.Cloud) > 0 {
 		return fmt.Errorf("--cloud is only supported with --credentials-requests")
 	}
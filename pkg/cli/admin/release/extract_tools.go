@@ -55,6 +55,41 @@ type extractTarget struct {
 	Mapping extract.Mapping
 }
 
+// toolsPlatform is an operating system and architecture to extract tools for.
+type toolsPlatform struct {
+	OS   string
+	Arch string
+}
+
+func (p toolsPlatform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// parseToolsPlatforms parses a list of OS/ARCH platforms. The mac operating system is accepted as an alias of darwin.
+func parseToolsPlatforms(platforms []string) ([]toolsPlatform, error) {
+	var parsed []toolsPlatform
+	for _, platform := range platforms {
+		parts := strings.Split(platform, "/")
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("--platforms must be a list of OS/ARCH pairs, like linux/amd64: %q", platform)
+		}
+		if parts[0] == "mac" {
+			parts[0] = "darwin"
+		}
+		parsed = append(parsed, toolsPlatform{OS: parts[0], Arch: parts[1]})
+	}
+	return parsed, nil
+}
+
+// matches returns true if the target provides the tool for the platform. Targets with the release architecture
+// match that architecture.
+func (t extractTarget) matches(platform toolsPlatform, releaseArch string) bool {
+	if t.OS != platform.OS {
+		return false
+	}
+	return t.Arch == platform.Arch || (t.Arch == targetReleaseArch && platform.Arch == releaseArch)
+}
+
 // extractTools extracts all referenced commands as archives in the target dir.
 func (o *ExtractOptions) extractTools() error {
 	return o.extractCommand("")
@@ -310,6 +345,10 @@ func (o *ExtractOptions) extractCommand(command string) error {
 	if currentOS == "mac" {
 		currentOS = "darwin"
 	}
+	platforms, err := parseToolsPlatforms(o.Platforms)
+	if err != nil {
+		return err
+	}
 
 	// Select the subset of targets based on command line input
 	var willArchive bool
@@ -330,9 +369,9 @@ func (o *ExtractOptions) extractCommand(command string) error {
 		}
 	}
 
-	// If the user didn't specify a command, or the operating system is set
-	// to '*', we'll produce an archive
-	if len(command) == 0 || o.CommandOperatingSystem == "*" {
+	// If the user didn't specify a command, the operating system is set
+	// to '*' or several platforms are requested, we'll produce an archive
+	if len(command) == 0 || o.CommandOperatingSystem == "*" || len(platforms) > 0 {
 		for i := range targets {
 			targets[i].AsArchive = true
 			targets[i].AsZip = targets[i].OS == "windows"
@@ -415,16 +454,34 @@ func (o *ExtractOptions) extractCommand(command string) error {
 	// resolve target image references to their pull specs
 	missing := sets.NewString()
 	var validTargets []extractTarget
+	unavailable := sets.NewString()
+	for _, platform := range platforms {
+		unavailable.Insert(platform.String())
+	}
 	for _, target := range targets {
-		if currentOS != "*" && target.OS != currentOS {
-			klog.V(2).Infof("Skipping %s, does not match current OS %s", target.ArchiveFormat, target.OS)
-			continue
-		}
-		if currentArch != "*" && target.Arch != currentArch {
-			if currentArch != releaseArch || target.Arch != targetReleaseArch {
-				klog.V(2).Infof("Skipping %s, does not match current architecture %s", target.ArchiveFormat, target.Arch)
+		if len(platforms) > 0 {
+			var matched bool
+			for _, platform := range platforms {
+				if target.matches(platform, releaseArch) {
+					matched = true
+					unavailable.Delete(platform.String())
+				}
+			}
+			if !matched {
+				klog.V(2).Infof("Skipping %s, does not match the requested platforms", target.ArchiveFormat)
 				continue
 			}
+		} else {
+			if currentOS != "*" && target.OS != currentOS {
+				klog.V(2).Infof("Skipping %s, does not match current OS %s", target.ArchiveFormat, target.OS)
+				continue
+			}
+			if currentArch != "*" && target.Arch != currentArch {
+				if currentArch != releaseArch || target.Arch != targetReleaseArch {
+					klog.V(2).Infof("Skipping %s, does not match current architecture %s", target.ArchiveFormat, target.Arch)
+					continue
+				}
+			}
 		}
 		if target.OS == "linux" && target.Arch == releaseArch {
 			klog.V(2).Infof("Skipping duplicate %s", target.ArchiveFormat)
@@ -453,6 +510,10 @@ func (o *ExtractOptions) extractCommand(command string) error {
 		validTargets = append(validTargets, target)
 	}
 
+	if unavailable.Len() > 0 {
+		return fmt.Errorf("the release has no tools for the platforms: %s", strings.Join(unavailable.List(), ", "))
+	}
+
 	if len(validTargets) == 0 {
 		if len(missing) == 1 {
 			return fmt.Errorf("the image %q containing the desired command is not available", missing.List()[0])
@@ -2,6 +2,7 @@ package release
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 )
 
@@ -233,3 +234,37 @@ func Test_copyAndReplace(t *testing.T) {
 		})
 	}
 }
+
+func Test_toolsPlatforms(t *testing.T) {
+	platforms, err := parseToolsPlatforms([]string{"linux/amd64", "mac/arm64", "windows/amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []toolsPlatform{{OS: "linux", Arch: "amd64"}, {OS: "darwin", Arch: "arm64"}, {OS: "windows", Arch: "amd64"}}
+	if !reflect.DeepEqual(platforms, expected) {
+		t.Fatalf("expected %v, got %v", expected, platforms)
+	}
+	for _, invalid := range []string{"linux", "linux/", "/amd64", "linux/amd64/v2"} {
+		if _, err := parseToolsPlatforms([]string{invalid}); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+
+	tests := []struct {
+		target      extractTarget
+		platform    toolsPlatform
+		releaseArch string
+		expected    bool
+	}{
+		{target: extractTarget{OS: "darwin", Arch: "arm64"}, platform: toolsPlatform{OS: "darwin", Arch: "arm64"}, releaseArch: "amd64", expected: true},
+		{target: extractTarget{OS: "darwin", Arch: "amd64"}, platform: toolsPlatform{OS: "darwin", Arch: "arm64"}, releaseArch: "amd64"},
+		{target: extractTarget{OS: "linux", Arch: targetReleaseArch}, platform: toolsPlatform{OS: "linux", Arch: "s390x"}, releaseArch: "s390x", expected: true},
+		{target: extractTarget{OS: "linux", Arch: targetReleaseArch}, platform: toolsPlatform{OS: "linux", Arch: "arm64"}, releaseArch: "amd64"},
+		{target: extractTarget{OS: "windows", Arch: "amd64"}, platform: toolsPlatform{OS: "linux", Arch: "amd64"}, releaseArch: "amd64"},
+	}
+	for _, test := range tests {
+		if actual := test.target.matches(test.platform, test.releaseArch); actual != test.expected {
+			t.Errorf("%s/%s for %s with release architecture %s: expected %t, got %t", test.target.OS, test.target.Arch, test.platform, test.releaseArch, test.expected, actual)
+		}
+	}
+}
@@ -73,6 +73,7 @@ func NewMirrorOptions(streams genericclioptions.IOStreams) *MirrorOptions {
 	return &MirrorOptions{
 		IOStreams:       streams,
 		ParallelOptions: imagemanifest.ParallelOptions{MaxPerRegistry: 6},
+		MaxRegistry:     4,
 	}
 }
 
@@ -121,6 +122,13 @@ func NewMirror(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.C
 			The --overwrite option only applies when --apply-release-image-signature is specified
 			and indicates to update an exisiting config map if one is found. A config map written to a
 			directory will always replace onethat already exists.
+
+			Blobs are uploaded concurrently: --max-registry limits the number of registries used at
+			once and --max-per-registry the number of concurrent requests to each of them. With
+			--progress-file the images are mirrored in batches and the mirrored images are recorded in
+			the file after each batch, so that an interrupted mirror of the same release resumes where
+			it stopped when it is run again with the same file. With --verify every mirrored tag is
+			checked to point to the digest of its source image once mirroring completes.
 		`),
 		Example: templates.Examples(`
 			# Perform a dry run showing what would be mirrored, including the mirror objects
@@ -138,6 +146,10 @@ func NewMirror(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.C
 			oc adm release mirror --from file://openshift/release --to myregistry.com/openshift/release \
 				--release-image-signature-to-dir /tmp/releases
 
+			# Mirror a release with more concurrent uploads, resuming a previous attempt if it was interrupted
+			oc adm release mirror 4.3.0 --to myregistry.local/openshift/release \
+				--max-per-registry=12 --progress-file=/tmp/releases/progress.json
+
 			# Mirror the 4.3.0 release to repository registry.example.com and apply signatures to connected cluster
 			oc adm release mirror --from=quay.io/openshift-release-dev/ocp-release:4.3.0-x86_64 \
 				--to=registry.example.com/your/repository --apply-release-image-signature
@@ -166,6 +178,9 @@ func NewMirror(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.C
 	flags.BoolVar(&o.SkipRelease, "skip-release-image", o.SkipRelease, "Do not push the release image.")
 	flags.StringVar(&o.ToRelease, "to-release-image", o.ToRelease, "Specify an alternate locations for the release image instead as tag 'release' in --to.")
 	flags.BoolVar(&o.Overwrite, "overwrite", o.Overwrite, "Used with --apply-release-image-signature to update an existing signature configmap.")
+	flags.IntVar(&o.MaxRegistry, "max-registry", o.MaxRegistry, "Number of concurrent registries to connect to at any one time.")
+	flags.StringVar(&o.ProgressFile, "progress-file", o.ProgressFile, "A file recording the mirrored images. If it holds the progress of the same release, the images already mirrored are skipped.")
+	flags.BoolVar(&o.Verify, "verify", o.Verify, "If true, check that every mirrored tag points to the digest of its source image after mirroring.")
 	return cmd
 }
 
@@ -174,6 +189,7 @@ type MirrorOptions struct {
 
 	SecurityOptions imagemanifest.SecurityOptions
 	ParallelOptions imagemanifest.ParallelOptions
+	MaxRegistry     int
 
 	From    string
 	FromDir string
@@ -194,6 +210,9 @@ type MirrorOptions struct {
 	ReleaseImageSignatureToDir string
 	Overwrite                  bool
 
+	ProgressFile string
+	Verify       bool
+
 	DryRun                        bool
 	PrintImageContentInstructions bool
 
@@ -293,6 +312,13 @@ func (o *MirrorOptions) Validate() error {
 	if o.Overwrite && !o.ApplyReleaseImageSignature {
 		return fmt.Errorf("--overwite is only valid when --apply-release-image-signature is specified")
 	}
+
+	if o.MaxRegistry < 1 {
+		return fmt.Errorf("--max-registry must be at least 1")
+	}
+	if len(o.ProgressFile) > 0 && (o.ToMirror || len(o.ToImageStream) > 0) {
+		return fmt.Errorf("--progress-file may not be used with --to-mirror or --to-image-stream")
+	}
 	return nil
 }
 
@@ -763,15 +789,24 @@ func (o *MirrorOptions) Run() error {
 	opts := mirror.NewMirrorImageOptions(genericclioptions.IOStreams{Out: o.Out, ErrOut: o.ErrOut})
 	opts.SecurityOptions = o.SecurityOptions
 	opts.ParallelOptions = o.ParallelOptions
+	opts.MaxRegistry = o.MaxRegistry
 	opts.Mappings = mappings
 	opts.FromFileDir = o.FromDir
 	opts.FileDir = o.ToDir
 	opts.DryRun = o.DryRun
 	opts.KeepManifestList = o.KeepManifestList
-	opts.ManifestUpdateCallback = func(registry string, manifests map[digest.Digest]digest.Digest) error {
+	conversions := make(map[string]map[digest.Digest]digest.Digest)
+	updateManifests := func(registry string, manifests map[digest.Digest]digest.Digest) error {
 		lock.Lock()
 		defer lock.Unlock()
 
+		if conversions[registry] == nil {
+			conversions[registry] = make(map[digest.Digest]digest.Digest)
+		}
+		for from, to := range manifests {
+			conversions[registry][from] = to
+		}
+
 		// when uploading to a schema1 registry, manifest ids change and we must remap them
 		for i := range is.Spec.Tags {
 			tag := &is.Spec.Tags[i]
@@ -793,10 +828,38 @@ func (o *MirrorOptions) Run() error {
 		}
 		return nil
 	}
-	if err := opts.Run(); err != nil {
+	opts.ManifestUpdateCallback = updateManifests
+	if len(o.ProgressFile) > 0 && !o.DryRun {
+		key := releaseDigest
+		if len(key) == 0 {
+			key = o.From
+		}
+		if err := o.mirrorWithProgress(opts, mappings, key, updateManifests); err != nil {
+			return err
+		}
+	} else if err := opts.Run(); err != nil {
 		return err
 	}
 
+	if o.Verify && !o.DryRun {
+		failures, skipped, err := verifyMirror(ctx, opts, mappings, func(registry string) map[digest.Digest]digest.Digest {
+			return conversions[registry]
+		})
+		if err != nil {
+			return fmt.Errorf("unable to verify the mirrored images: %v", err)
+		}
+		for _, failure := range failures {
+			fmt.Fprintf(o.ErrOut, "error: %s\n", failure)
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("%d of %d mirrored images failed verification", len(failures), len(mappings))
+		}
+		if skipped > 0 {
+			fmt.Fprintf(o.ErrOut, "info: Skipped verification of %d images mirrored from a manifest list, use --keep-manifest-list to verify them\n", skipped)
+		}
+		fmt.Fprintf(o.ErrOut, "info: Verified the digests of %d mirrored images\n", len(mappings)-skipped)
+	}
+
 	to := o.ToRelease
 	if len(to) == 0 {
 		to = targetFn("").Ref.Exact()
@@ -854,6 +917,49 @@ func (o *MirrorOptions) Run() error {
 	return nil
 }
 
+// mirrorWithProgress mirrors the mappings that the progress file does not record as mirrored, in batches, and
+// records each batch in the progress file once it is mirrored. The manifest conversions recorded by previous runs
+// are replayed into updateManifests.
+func (o *MirrorOptions) mirrorWithProgress(opts *mirror.MirrorImageOptions, mappings []mirror.Mapping, release string, updateManifests func(string, map[digest.Digest]digest.Digest) error) error {
+	progress, resumed, err := loadMirrorProgress(o.ProgressFile, release)
+	if err != nil {
+		return err
+	}
+
+	var remaining []mirror.Mapping
+	for _, mapping := range mappings {
+		if !progress.Done(mapping) {
+			remaining = append(remaining, mapping)
+		}
+	}
+	if resumed {
+		fmt.Fprintf(o.ErrOut, "info: Resuming from %s, %d of %d images were already mirrored\n", o.ProgressFile, len(mappings)-len(remaining), len(mappings))
+	}
+	for registry := range progress.Manifests {
+		if err := updateManifests(registry, progress.Conversions(registry)); err != nil {
+			return err
+		}
+	}
+
+	for _, batch := range mappingBatches(remaining, mirrorBatchSize) {
+		batchConversions := make(map[string]map[digest.Digest]digest.Digest)
+		opts.Mappings = batch
+		opts.ManifestUpdateCallback = func(registry string, manifests map[digest.Digest]digest.Digest) error {
+			batchConversions[registry] = manifests
+			return updateManifests(registry, manifests)
+		}
+		if err := opts.Run(); err != nil {
+			return err
+		}
+		if err := progress.Record(batch, batchConversions); err != nil {
+			return fmt.Errorf("unable to save the mirror progress to %s: %v", o.ProgressFile, err)
+		}
+	}
+	opts.Mappings = mappings
+	opts.ManifestUpdateCallback = updateManifests
+	return nil
+}
+
 // printImageContentInstructions provides examples to the user for using the new repository mirror
 // https://github.com/openshift/installer/blob/master/docs/dev/alternative_release_image_sources.md
 func printImageContentInstructions(out io.Writer, from string, toList []string, signatureToDir string, repositories map[string]struct{}) error {
@@ -0,0 +1,219 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/opencontainers/go-digest"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+	"github.com/openshift/oc/pkg/cli/image/mirror"
+	"github.com/openshift/oc/pkg/cli/image/workqueue"
+)
+
+// mirrorBatchSize is the number of images mirrored between two updates of the progress file.
+const mirrorBatchSize = 10
+
+// mirrorProgress records which images of a release were mirrored, so that an interrupted mirror can be resumed
+// with the same --progress-file instead of starting over.
+type mirrorProgress struct {
+	// Release is the digest of the release image being mirrored. Progress of another release is discarded.
+	Release string `json:"release"`
+	// Completed are the mirrored mappings, as SOURCE=DESTINATION.
+	Completed []string `json:"completed"`
+	// Manifests are the manifests whose digest changed when they were uploaded, by registry.
+	Manifests map[string]map[string]string `json:"manifests,omitempty"`
+
+	path      string
+	completed sets.String
+}
+
+// loadMirrorProgress reads the progress of mirroring release from path. A missing file or the progress of another
+// release yields an empty progress.
+func loadMirrorProgress(path, release string) (*mirrorProgress, bool, error) {
+	p := &mirrorProgress{path: path, completed: sets.NewString(), Manifests: map[string]map[string]string{}}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		p.Release = release
+		return p, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, false, fmt.Errorf("unable to read mirror progress from %s: %v", path, err)
+	}
+	if p.Release != release {
+		p.Release, p.Completed, p.Manifests = release, nil, map[string]map[string]string{}
+		return p, false, nil
+	}
+	if p.Manifests == nil {
+		p.Manifests = map[string]map[string]string{}
+	}
+	p.completed.Insert(p.Completed...)
+	return p, true, nil
+}
+
+func mappingKey(m mirror.Mapping) string {
+	return fmt.Sprintf("%s=%s", m.Source, m.Destination)
+}
+
+// Done returns true if the mapping was mirrored by a previous run.
+func (p *mirrorProgress) Done(m mirror.Mapping) bool {
+	return p.completed.Has(mappingKey(m))
+}
+
+// Record marks the mappings as mirrored, along with the manifest conversions that happened while mirroring them,
+// and saves the progress.
+func (p *mirrorProgress) Record(mappings []mirror.Mapping, conversions map[string]map[digest.Digest]digest.Digest) error {
+	for _, m := range mappings {
+		p.completed.Insert(mappingKey(m))
+	}
+	for registry, manifests := range conversions {
+		if p.Manifests[registry] == nil {
+			p.Manifests[registry] = map[string]string{}
+		}
+		for from, to := range manifests {
+			p.Manifests[registry][from.String()] = to.String()
+		}
+	}
+	p.Completed = p.completed.List()
+	return p.save()
+}
+
+// Conversions returns the recorded manifest conversions of registry.
+func (p *mirrorProgress) Conversions(registry string) map[digest.Digest]digest.Digest {
+	conversions := make(map[digest.Digest]digest.Digest)
+	for from, to := range p.Manifests[registry] {
+		conversions[digest.Digest(from)] = digest.Digest(to)
+	}
+	return conversions
+}
+
+// save writes the progress to a temporary file that replaces the previous one, so that an interruption never
+// leaves a truncated file behind.
+func (p *mirrorProgress) save() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(p.path); len(dir) > 0 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := p.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
+
+// mappingBatches splits the mappings into batches of at most size mappings.
+func mappingBatches(mappings []mirror.Mapping, size int) [][]mirror.Mapping {
+	var batches [][]mirror.Mapping
+	for len(mappings) > size {
+		batches = append(batches, mappings[:size])
+		mappings = mappings[size:]
+	}
+	if len(mappings) > 0 {
+		batches = append(batches, mappings)
+	}
+	return batches
+}
+
+// verifyMirror checks that the tag of every destination points to the digest of its source, or to the digest its
+// manifest was converted to when it was uploaded. Unless opts.KeepManifestList is set, a source that is a manifest
+// list is mirrored as one of its images, whose digest is not known here, so it is skipped. It returns a
+// description of every mismatch and the number of skipped mappings.
+func verifyMirror(ctx context.Context, opts *mirror.MirrorImageOptions, mappings []mirror.Mapping, conversions func(registry string) map[digest.Digest]digest.Digest) ([]string, int, error) {
+	regContext, err := opts.SecurityOptions.Context()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var lock sync.Mutex
+	var failures []string
+	var skipped int
+	fail := func(format string, args ...interface{}) {
+		lock.Lock()
+		defer lock.Unlock()
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+	skip := func() {
+		lock.Lock()
+		defer lock.Unlock()
+		skipped++
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	q := workqueue.New(opts.ParallelOptions.MaxPerRegistry, stopCh)
+	q.Batch(func(w workqueue.Work) {
+		for i := range mappings {
+			m := mappings[i]
+			w.Parallel(func() {
+				expected := digest.Digest(m.Source.Ref.ID)
+				if len(expected) == 0 || !opts.KeepManifestList {
+					repo, err := opts.Repository(ctx, regContext, m.Source, true)
+					if err != nil {
+						fail("%s: unable to connect to source: %v", m.Source, err)
+						return
+					}
+					if len(expected) == 0 {
+						desc, err := repo.Tags(ctx).Get(ctx, m.Source.Ref.Tag)
+						if err != nil {
+							fail("%s: unable to resolve source tag: %v", m.Source, err)
+							return
+						}
+						expected = desc.Digest
+					}
+					if !opts.KeepManifestList {
+						manifests, err := repo.Manifests(ctx)
+						if err != nil {
+							fail("%s: unable to access source manifests: %v", m.Source, err)
+							return
+						}
+						srcManifest, err := manifests.Get(ctx, expected, imagemanifest.PreferManifestList)
+						if err != nil {
+							fail("%s: unable to retrieve source manifest: %v", m.Source, err)
+							return
+						}
+						if _, ok := srcManifest.(*manifestlist.DeserializedManifestList); ok {
+							skip()
+							return
+						}
+					}
+				}
+				if converted, ok := conversions(m.Destination.Ref.Registry)[expected]; ok {
+					expected = converted
+				}
+
+				repo, err := opts.Repository(ctx, regContext, m.Destination, false)
+				if err != nil {
+					fail("%s: unable to connect to destination: %v", m.Destination, err)
+					return
+				}
+				desc, err := repo.Tags(ctx).Get(ctx, m.Destination.Ref.Tag)
+				if err != nil {
+					fail("%s: unable to resolve destination tag: %v", m.Destination, err)
+					return
+				}
+				if desc.Digest != expected {
+					fail("%s: expected digest %s, found %s", m.Destination, expected, desc.Digest)
+				}
+			})
+		}
+	})
+	sort.Strings(failures)
+	return failures, skipped, nil
+}
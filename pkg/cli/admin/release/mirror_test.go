@@ -1,12 +1,18 @@
 package release
 
 import (
+	"path/filepath"
 	"reflect"
 	"testing"
 
+	"github.com/opencontainers/go-digest"
+
 	operatorv1alpha1 "github.com/openshift/api/operator/v1alpha1"
 
 	"k8s.io/apimachinery/pkg/util/diff"
+
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	"github.com/openshift/oc/pkg/cli/image/mirror"
 )
 
 func Test_dedupeSortSources(t *testing.T) {
@@ -118,3 +124,57 @@ func Test_dedupeSortSources(t *testing.T) {
 		})
 	}
 }
+
+func Test_mirrorProgress(t *testing.T) {
+	var mappings []mirror.Mapping
+	for _, name := range []string{"a", "b", "c"} {
+		src, err := imagesource.ParseReference("quay.io/ocp/release@sha256:" + name + "000000000000000000000000000000000000000000000000000000000000000")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dst, err := imagesource.ParseReference("registry.local/ocp/release:4.3.0-" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mappings = append(mappings, mirror.Mapping{Source: src, Destination: dst, Name: name})
+	}
+
+	if batches := mappingBatches(mappings, 2); len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+
+	path := filepath.Join(t.TempDir(), "progress", "mirror.json")
+	progress, resumed, err := loadMirrorProgress(path, "sha256:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed {
+		t.Fatalf("expected a new progress file")
+	}
+	from, to := digest.Digest("sha256:from"), digest.Digest("sha256:to")
+	if err := progress.Record(mappings[:2], map[string]map[digest.Digest]digest.Digest{"registry.local": {from: to}}); err != nil {
+		t.Fatal(err)
+	}
+
+	progress, resumed, err = loadMirrorProgress(path, "sha256:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resumed {
+		t.Fatalf("expected the progress to be resumed")
+	}
+	if !progress.Done(mappings[0]) || !progress.Done(mappings[1]) || progress.Done(mappings[2]) {
+		t.Errorf("unexpected completed mappings: %v", progress.Completed)
+	}
+	if conversions := progress.Conversions("registry.local"); conversions[from] != to {
+		t.Errorf("unexpected conversions: %v", conversions)
+	}
+
+	progress, resumed, err = loadMirrorProgress(path, "sha256:2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed || progress.Done(mappings[0]) || len(progress.Conversions("registry.local")) > 0 {
+		t.Errorf("expected the progress of another release to be discarded")
+	}
+}
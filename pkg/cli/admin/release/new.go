@@ -1073,6 +1073,7 @@ func (o *NewOptions) mirrorImages(is *imageapi.ImageStream) error {
 	opts.SkipRelease = true
 	opts.ParallelOptions = o.ParallelOptions
 	opts.SecurityOptions = o.SecurityOptions
+	opts.Verify = false
 	opts.KeepManifestList = o.KeepManifestList
 
 	if err := opts.Run(); err != nil {
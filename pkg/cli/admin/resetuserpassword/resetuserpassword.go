@@ -0,0 +1,297 @@
+package resetuserpassword
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/bcrypt"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	"github.com/openshift/oc/pkg/helpers/term"
+)
+
+var (
+	resetUserPasswordLong = templates.LongDesc(`
+		Reset the password of a user of an htpasswd identity provider.
+
+		The entry of the user in the htpasswd file of the identity provider, which is stored in a
+		secret of the openshift-config namespace, is replaced with a bcrypt hash of the new password.
+		The identity provider is found in the OAuth configuration of the cluster, and must be given
+		with --identity-provider when several htpasswd identity providers are configured.
+
+		The new password is read from --password-file, or prompted for. The OAuth servers use it once
+		the authentication operator has copied the updated secret, which usually takes a minute. The
+		tokens the user already has remain valid unless --logout is set, which deletes the OAuth
+		access tokens of the user to end all of their sessions.
+	`)
+
+	resetUserPasswordExample = templates.Examples(`
+		# Reset the password of a user, prompting for the new password
+		oc adm reset-user-password alice
+
+		# Reset the password of a user of the identity provider named "local" and end their sessions
+		oc adm reset-user-password alice --identity-provider=local --logout
+
+		# Reset the password of a user with a password read from stdin
+		generate-password | oc adm reset-user-password alice --password-file=-
+	`)
+)
+
+// configNamespace is the namespace of the secrets referenced by the OAuth configuration.
+const configNamespace = "openshift-config"
+
+// ResetUserPasswordOptions holds the options to reset the password of a user of an htpasswd identity provider.
+type ResetUserPasswordOptions struct {
+	Username         string
+	IdentityProvider string
+	PasswordFile     string
+	Logout           bool
+
+	ConfigClient configv1client.Interface
+	KubeClient   kubernetes.Interface
+	OAuthClient  oauthv1client.OauthV1Interface
+	UserClient   userv1client.UserV1Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewResetUserPasswordOptions(streams genericclioptions.IOStreams) *ResetUserPasswordOptions {
+	return &ResetUserPasswordOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdResetUserPassword creates a command that resets the password of a user of an htpasswd identity provider.
+func NewCmdResetUserPassword(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewResetUserPasswordOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "reset-user-password USERNAME",
+		Short:   "Reset the password of a user of an htpasswd identity provider",
+		Long:    resetUserPasswordLong,
+		Example: resetUserPasswordExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.IdentityProvider, "identity-provider", o.IdentityProvider, "The name of the htpasswd identity provider of the user. Required when several htpasswd identity providers are configured.")
+	cmd.Flags().StringVar(&o.PasswordFile, "password-file", o.PasswordFile, "A file whose first line is the new password. Use - to read from stdin. If unset, the password is prompted for.")
+	cmd.Flags().BoolVar(&o.Logout, "logout", o.Logout, "If true, delete the OAuth access tokens of the user to end their sessions.")
+
+	return cmd
+}
+
+func (o *ResetUserPasswordOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "exactly one username is required")
+	}
+	o.Username = args[0]
+	if strings.Contains(o.Username, ":") {
+		return fmt.Errorf("invalid username %q, htpasswd usernames cannot contain ':'", o.Username)
+	}
+
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.ConfigClient, err = configv1client.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.OAuthClient, err = oauthv1client.NewForConfig(config); err != nil {
+		return err
+	}
+	o.UserClient, err = userv1client.NewForConfig(config)
+	return err
+}
+
+func (o *ResetUserPasswordOptions) Run() error {
+	oauth, err := o.ConfigClient.ConfigV1().OAuths().Get(context.TODO(), "cluster", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	provider, err := findIdentityProvider(oauth, o.IdentityProvider)
+	if err != nil {
+		return err
+	}
+
+	password, err := o.readPassword()
+	if err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	secretName := provider.HTPasswd.FileData.Name
+	if err := o.updateSecret(secretName, string(hash)); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "password of user %s of identity provider %s reset in secret %s/%s\n", o.Username, provider.Name, configNamespace, secretName)
+
+	if !o.Logout {
+		return nil
+	}
+	deleted, err := o.deleteTokens(provider.Name)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "%d OAuth access token(s) of user %s deleted\n", deleted, o.Username)
+	return nil
+}
+
+// findIdentityProvider returns the htpasswd identity provider with the given name, or the only one when the name is
+// empty.
+func findIdentityProvider(oauth *configv1.OAuth, name string) (*configv1.IdentityProvider, error) {
+	var providers []*configv1.IdentityProvider
+	var names []string
+	for i := range oauth.Spec.IdentityProviders {
+		provider := &oauth.Spec.IdentityProviders[i]
+		if provider.Type != configv1.IdentityProviderTypeHTPasswd || provider.HTPasswd == nil {
+			continue
+		}
+		if provider.Name == name {
+			return provider, nil
+		}
+		providers = append(providers, provider)
+		names = append(names, provider.Name)
+	}
+	switch {
+	case len(name) > 0:
+		return nil, fmt.Errorf("no htpasswd identity provider named %s is configured", name)
+	case len(providers) == 0:
+		return nil, errors.New("no htpasswd identity provider is configured")
+	case len(providers) > 1:
+		return nil, fmt.Errorf("several htpasswd identity providers are configured, use --identity-provider with one of: %s", strings.Join(names, ", "))
+	}
+	return providers[0], nil
+}
+
+// readPassword returns the password read from the password file, or prompted for twice.
+func (o *ResetUserPasswordOptions) readPassword() (string, error) {
+	if len(o.PasswordFile) == 0 {
+		password := term.PromptForPasswordString(o.In, o.Out, "New password: ")
+		if len(password) == 0 {
+			return "", errors.New("the password cannot be empty")
+		}
+		if term.PromptForPasswordString(o.In, o.Out, "Confirm new password: ") != password {
+			return "", errors.New("the passwords do not match")
+		}
+		return password, nil
+	}
+
+	var in io.Reader = o.In
+	if o.PasswordFile != "-" {
+		f, err := os.Open(o.PasswordFile)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		in = f
+	}
+	password, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) == 0 {
+		return "", fmt.Errorf("the password read from %s is empty", o.PasswordFile)
+	}
+	return password, nil
+}
+
+// updateSecret replaces the hash of the user in the htpasswd secret, which is fetched again when it was modified
+// since it was read.
+func (o *ResetUserPasswordOptions) updateSecret(name, hash string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		secret, err := o.KubeClient.CoreV1().Secrets(configNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		data, err := setPassword(secret.Data[configv1.HTPasswdDataKey], o.Username, hash)
+		if err != nil {
+			return fmt.Errorf("secret %s/%s: %v", configNamespace, name, err)
+		}
+		secret.Data[configv1.HTPasswdDataKey] = data
+		_, err = o.KubeClient.CoreV1().Secrets(configNamespace).Update(context.TODO(), secret, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// setPassword returns the htpasswd data with the hash of the user replaced, keeping the other lines unchanged.
+func setPassword(data []byte, username, hash string) ([]byte, error) {
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	found := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, username+":") {
+			lines[i] = username + ":" + hash
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("user %s is not in the htpasswd file", username)
+	}
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// deleteTokens deletes the OAuth access tokens of the user the identity of the username is mapped to, and returns
+// how many were deleted. There is no token when the user never logged in.
+func (o *ResetUserPasswordOptions) deleteTokens(provider string) (int, error) {
+	identity, err := o.UserClient.Identities().Get(context.TODO(), provider+":"+o.Username, metav1.GetOptions{})
+	switch {
+	case kerrors.IsNotFound(err):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	user := identity.User.Name
+
+	tokens, err := o.OAuthClient.OAuthAccessTokens().List(context.TODO(), metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("userName", user).String(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, token := range tokens.Items {
+		if token.UserName != user {
+			continue
+		}
+		err := o.OAuthClient.OAuthAccessTokens().Delete(context.TODO(), token.Name, metav1.DeleteOptions{})
+		switch {
+		case kerrors.IsNotFound(err):
+		case err != nil:
+			return deleted, err
+		default:
+			deleted++
+		}
+	}
+	return deleted, nil
+}
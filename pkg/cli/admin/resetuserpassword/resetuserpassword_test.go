@@ -0,0 +1,158 @@
+package resetuserpassword
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+	userfake "github.com/openshift/client-go/user/clientset/versioned/fake"
+)
+
+func TestSetPassword(t *testing.T) {
+	data := "alice:$2y$05$old\nbob:$apr1$bob\nalicia:$2y$05$alicia"
+	got, err := setPassword([]byte(data), "alice", "$2a$10$new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "alice:$2a$10$new\nbob:$apr1$bob\nalicia:$2y$05$alicia\n"
+	if string(got) != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := setPassword([]byte(data), "carol", "$2a$10$new"); err == nil {
+		t.Errorf("expected an error for a user that is not in the file")
+	}
+}
+
+func TestFindIdentityProvider(t *testing.T) {
+	htpasswd := func(name string) configv1.IdentityProvider {
+		return configv1.IdentityProvider{
+			Name: name,
+			IdentityProviderConfig: configv1.IdentityProviderConfig{
+				Type:     configv1.IdentityProviderTypeHTPasswd,
+				HTPasswd: &configv1.HTPasswdIdentityProvider{FileData: configv1.SecretNameReference{Name: name + "-secret"}},
+			},
+		}
+	}
+	github := configv1.IdentityProvider{
+		Name:                   "github",
+		IdentityProviderConfig: configv1.IdentityProviderConfig{Type: configv1.IdentityProviderTypeGitHub},
+	}
+	tests := []struct {
+		name      string
+		providers []configv1.IdentityProvider
+		provider  string
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "only htpasswd provider",
+			providers: []configv1.IdentityProvider{github, htpasswd("local")},
+			want:      "local",
+		},
+		{
+			name:      "named provider",
+			providers: []configv1.IdentityProvider{htpasswd("local"), htpasswd("admins")},
+			provider:  "admins",
+			want:      "admins",
+		},
+		{
+			name:      "several providers",
+			providers: []configv1.IdentityProvider{htpasswd("local"), htpasswd("admins")},
+			wantErr:   "several htpasswd identity providers are configured, use --identity-provider with one of: local, admins",
+		},
+		{
+			name:      "not an htpasswd provider",
+			providers: []configv1.IdentityProvider{github, htpasswd("local")},
+			provider:  "github",
+			wantErr:   "no htpasswd identity provider named github is configured",
+		},
+		{
+			name:      "no provider",
+			providers: []configv1.IdentityProvider{github},
+			wantErr:   "no htpasswd identity provider is configured",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oauth := &configv1.OAuth{Spec: configv1.OAuthSpec{IdentityProviders: tt.providers}}
+			provider, err := findIdentityProvider(oauth, tt.provider)
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if provider.Name != tt.want {
+				t.Errorf("got provider %s, want %s", provider.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateSecretAndDeleteTokens(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: configNamespace, Name: "htpass-secret"},
+		Data:       map[string][]byte{configv1.HTPasswdDataKey: []byte("alice:$2y$05$old\nbob:$2y$05$bob\n")},
+	}
+	identity := &userv1.Identity{
+		ObjectMeta: metav1.ObjectMeta{Name: "local:alice"},
+		User:       corev1.ObjectReference{Name: "alice-user"},
+	}
+	o := NewResetUserPasswordOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Username = "alice"
+	o.KubeClient = fake.NewSimpleClientset(secret)
+	o.UserClient = userfake.NewSimpleClientset(identity).UserV1()
+	o.OAuthClient = oauthfake.NewSimpleClientset(
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "sha256~a"}, UserName: "alice-user"},
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "sha256~b"}, UserName: "bob"},
+		&oauthv1.OAuthAccessToken{ObjectMeta: metav1.ObjectMeta{Name: "sha256~c"}, UserName: "alice-user"},
+	).OauthV1()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("new-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.updateSecret("htpass-secret", string(hash)); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := o.KubeClient.CoreV1().Secrets(configNamespace).Get(context.TODO(), "htpass-secret", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "alice:" + string(hash) + "\nbob:$2y$05$bob\n"; string(updated.Data[configv1.HTPasswdDataKey]) != want {
+		t.Errorf("got htpasswd data %q, want %q", updated.Data[configv1.HTPasswdDataKey], want)
+	}
+
+	deleted, err := o.deleteTokens("local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted tokens, got %d", deleted)
+	}
+	tokens, err := o.OAuthClient.OAuthAccessTokens().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens.Items) != 1 || tokens.Items[0].Name != "sha256~b" {
+		t.Errorf("unexpected remaining tokens %v", tokens.Items)
+	}
+
+	if deleted, err := o.deleteTokens("other"); err != nil || deleted != 0 {
+		t.Errorf("expected no token deleted for a user that never logged in, got %d, %v", deleted, err)
+	}
+}
@@ -0,0 +1,127 @@
+package tokens
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	listLong = templates.LongDesc(`
+		List the OAuth access and authorize tokens of a user, or of all users.
+
+		The tokens can be filtered by age with --older-than and --newer-than. The names of the tokens
+		are those of the token objects, which cannot be used to authenticate.
+	`)
+
+	listExample = templates.Examples(`
+		# List the tokens of a user
+		oc adm tokens list --user=alice
+
+		# List the tokens of all users created more than 30 days ago
+		oc adm tokens list --all-users --older-than=720h
+	`)
+)
+
+// ListOptions holds the options to list OAuth tokens.
+type ListOptions struct {
+	Selection tokenSelection
+	Output    string
+
+	genericclioptions.IOStreams
+}
+
+func NewListOptions(streams genericclioptions.IOStreams) *ListOptions {
+	return &ListOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdList creates a command that lists OAuth tokens.
+func NewCmdList(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewListOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the OAuth tokens of users",
+		Long:    listLong,
+		Example: listExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.Selection.AddFlags(cmd)
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml")
+	return cmd
+}
+
+func (o *ListOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	return o.Selection.Complete(f)
+}
+
+func (o *ListOptions) Validate() error {
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be 'json' or 'yaml'")
+	}
+	return o.Selection.Validate()
+}
+
+func (o *ListOptions) Run() error {
+	now := time.Now()
+	tokens, err := o.Selection.find(now)
+	if err != nil {
+		return err
+	}
+
+	switch o.Output {
+	case "json", "yaml":
+		if tokens == nil {
+			tokens = []token{}
+		}
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, tokens)
+	}
+
+	if len(tokens) == 0 {
+		fmt.Fprintln(o.ErrOut, "No tokens found.")
+		return nil
+	}
+	printTokens(o.Out, now, tokens)
+	return nil
+}
+
+// printTokens prints a table of the tokens.
+func printTokens(out io.Writer, now time.Time, tokens []token) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "USER\tTYPE\tNAME\tCLIENT\tAGE\tEXPIRES")
+	for _, t := range tokens {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.User, t.Type, t.Name, t.Client, duration.HumanDuration(now.Sub(t.Created)), expiresIn(now, t))
+	}
+}
+
+// expiresIn returns how long until the token expires.
+func expiresIn(now time.Time, t token) string {
+	switch {
+	case t.Expires == nil:
+		return "never"
+	case !t.Expires.After(now):
+		return "expired"
+	}
+	return duration.HumanDuration(t.Expires.Sub(now))
+}
@@ -0,0 +1,145 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	revokeLong = templates.LongDesc(`
+		Revoke the OAuth access and authorize tokens of a user, or of all users.
+
+		The tokens are deleted, which ends the sessions they authenticate; the user has to log in
+		again. Revoking the tokens of a user whose credentials are compromised does not prevent new
+		logins, the credentials must also be changed in the identity provider.
+
+		The tokens of all users are only revoked by age: --all-users requires --older-than, to sweep
+		the tokens that are older than the given duration.
+	`)
+
+	revokeExample = templates.Examples(`
+		# Revoke all the tokens of a user
+		oc adm tokens revoke --user=alice
+
+		# Revoke the tokens of a user created in the last 2 hours
+		oc adm tokens revoke --user=alice --newer-than=2h
+
+		# Show the tokens of all users that would be revoked for being older than 90 days
+		oc adm tokens revoke --all-users --older-than=2160h --dry-run=client
+	`)
+)
+
+// RevokeOptions holds the options to revoke OAuth tokens.
+type RevokeOptions struct {
+	Selection      tokenSelection
+	DryRunStrategy kcmdutil.DryRunStrategy
+
+	genericclioptions.IOStreams
+}
+
+func NewRevokeOptions(streams genericclioptions.IOStreams) *RevokeOptions {
+	return &RevokeOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdRevoke creates a command that revokes OAuth tokens.
+func NewCmdRevoke(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewRevokeOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "revoke",
+		Short:   "Revoke the OAuth tokens of users",
+		Long:    revokeLong,
+		Example: revokeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.Selection.AddFlags(cmd)
+	kcmdutil.AddDryRunFlag(cmd)
+	return cmd
+}
+
+func (o *RevokeOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+	var err error
+	if o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd); err != nil {
+		return err
+	}
+	return o.Selection.Complete(f)
+}
+
+func (o *RevokeOptions) Validate() error {
+	if err := o.Selection.Validate(); err != nil {
+		return err
+	}
+	if o.Selection.AllUsers && o.Selection.OlderThan == 0 {
+		return errors.New("--all-users requires --older-than, the tokens of all users are only revoked by age")
+	}
+	return nil
+}
+
+func (o *RevokeOptions) Run() error {
+	tokens, err := o.Selection.find(time.Now())
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		fmt.Fprintln(o.ErrOut, "No tokens found.")
+		return nil
+	}
+
+	verb := "revoked"
+	options := metav1.DeleteOptions{}
+	switch o.DryRunStrategy {
+	case kcmdutil.DryRunClient:
+		verb += " (dry run)"
+	case kcmdutil.DryRunServer:
+		verb += " (server dry run)"
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+
+	var errs []error
+	for _, t := range tokens {
+		if o.DryRunStrategy != kcmdutil.DryRunClient {
+			err := o.revoke(t, options)
+			switch {
+			case kerrors.IsNotFound(err):
+				// the token expired or was revoked since it was listed
+				continue
+			case err != nil:
+				errs = append(errs, fmt.Errorf("unable to revoke the %s token %s of user %s: %v", t.Type, t.Name, t.User, err))
+				continue
+			}
+		}
+		resource := "oauthaccesstoken.oauth.openshift.io"
+		if t.Type == authorizeToken {
+			resource = "oauthauthorizetoken.oauth.openshift.io"
+		}
+		fmt.Fprintf(o.Out, "%s/%s of user %s %s\n", resource, t.Name, t.User, verb)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// revoke deletes the access or authorize token.
+func (o *RevokeOptions) revoke(t token, options metav1.DeleteOptions) error {
+	if t.Type == authorizeToken {
+		return o.Selection.Client.OAuthAuthorizeTokens().Delete(context.TODO(), t.Name, options)
+	}
+	return o.Selection.Client.OAuthAccessTokens().Delete(context.TODO(), t.Name, options)
+}
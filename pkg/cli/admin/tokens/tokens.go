@@ -0,0 +1,167 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+)
+
+var (
+	tokensLong = templates.LongDesc(`
+		Manage the OAuth tokens of users
+
+		The OAuth access tokens authenticate the sessions of the users, and the OAuth authorize
+		tokens are the codes exchanged for access tokens while logging in. These commands list the
+		tokens of a user, or of all users, and revoke them, for example when the credentials of a
+		user are compromised or to remove the tokens that have been unused for a long time.`)
+)
+
+const (
+	accessToken    = "access"
+	authorizeToken = "authorize"
+)
+
+func NewCmdTokens(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	// Parent command to which all subcommands are added.
+	cmds := &cobra.Command{
+		Use:   "tokens",
+		Short: "List and revoke the OAuth tokens of users",
+		Long:  tokensLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmds.AddCommand(NewCmdList(f, streams))
+	cmds.AddCommand(NewCmdRevoke(f, streams))
+	return cmds
+}
+
+// token is an OAuth access or authorize token.
+type token struct {
+	Type    string    `json:"type"`
+	Name    string    `json:"name"`
+	User    string    `json:"user"`
+	Client  string    `json:"client"`
+	Created time.Time `json:"created"`
+	// Expires is nil when the token does not expire.
+	Expires *time.Time `json:"expires,omitempty"`
+	Scopes  []string   `json:"scopes,omitempty"`
+}
+
+// tokenSelection selects the tokens of a user or of all users, by age.
+type tokenSelection struct {
+	User      string
+	AllUsers  bool
+	OlderThan time.Duration
+	NewerThan time.Duration
+
+	Client oauthv1client.OauthV1Interface
+}
+
+func (s *tokenSelection) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.User, "user", s.User, "The name of the user whose tokens are selected.")
+	cmd.Flags().BoolVar(&s.AllUsers, "all-users", s.AllUsers, "If true, select the tokens of all users.")
+	cmd.Flags().DurationVar(&s.OlderThan, "older-than", s.OlderThan, "Only select the tokens created longer ago than this duration, like 720h.")
+	cmd.Flags().DurationVar(&s.NewerThan, "newer-than", s.NewerThan, "Only select the tokens created less long ago than this duration, like 1h.")
+}
+
+func (s *tokenSelection) Complete(f kcmdutil.Factory) error {
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	s.Client, err = oauthv1client.NewForConfig(config)
+	return err
+}
+
+func (s *tokenSelection) Validate() error {
+	if (len(s.User) > 0) == s.AllUsers {
+		return errors.New("exactly one of --user or --all-users is required")
+	}
+	if s.OlderThan < 0 || s.NewerThan < 0 {
+		return errors.New("--older-than and --newer-than cannot be negative")
+	}
+	return nil
+}
+
+// selected returns true if the token is selected by the user and age filters.
+func (s *tokenSelection) selected(now time.Time, t token) bool {
+	age := now.Sub(t.Created)
+	if s.OlderThan > 0 && age < s.OlderThan {
+		return false
+	}
+	if s.NewerThan > 0 && age >= s.NewerThan {
+		return false
+	}
+	return len(s.User) == 0 || t.User == s.User
+}
+
+// find returns the selected access and authorize tokens, sorted by user and creation time.
+func (s *tokenSelection) find(now time.Time) ([]token, error) {
+	options := metav1.ListOptions{}
+	if len(s.User) > 0 {
+		options.FieldSelector = fields.OneTermEqualSelector("userName", s.User).String()
+	}
+
+	var tokens []token
+	accessTokens, err := s.Client.OAuthAccessTokens().List(context.TODO(), options)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range accessTokens.Items {
+		t := token{
+			Type:    accessToken,
+			Name:    item.Name,
+			User:    item.UserName,
+			Client:  item.ClientName,
+			Created: item.CreationTimestamp.Time,
+			Scopes:  item.Scopes,
+		}
+		if item.ExpiresIn > 0 {
+			expires := t.Created.Add(time.Duration(item.ExpiresIn) * time.Second)
+			t.Expires = &expires
+		}
+		if s.selected(now, t) {
+			tokens = append(tokens, t)
+		}
+	}
+	authorizeTokens, err := s.Client.OAuthAuthorizeTokens().List(context.TODO(), options)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range authorizeTokens.Items {
+		t := token{
+			Type:    authorizeToken,
+			Name:    item.Name,
+			User:    item.UserName,
+			Client:  item.ClientName,
+			Created: item.CreationTimestamp.Time,
+			Scopes:  item.Scopes,
+		}
+		if item.ExpiresIn > 0 {
+			expires := t.Created.Add(time.Duration(item.ExpiresIn) * time.Second)
+			t.Expires = &expires
+		}
+		if s.selected(now, t) {
+			tokens = append(tokens, t)
+		}
+	}
+
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if tokens[i].User != tokens[j].User {
+			return tokens[i].User < tokens[j].User
+		}
+		return tokens[i].Created.Before(tokens[j].Created)
+	})
+	return tokens, nil
+}
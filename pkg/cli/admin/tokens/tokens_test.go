@@ -0,0 +1,150 @@
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+)
+
+// testNow is the current time, which Run uses to select the tokens by age
+var testNow = time.Now()
+
+func testClient() oauthv1client.OauthV1Interface {
+	created := func(age time.Duration) metav1.ObjectMeta {
+		return metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(testNow.Add(-age))}
+	}
+	accessToken := func(name, user string, age time.Duration, expiresIn int64) *oauthv1.OAuthAccessToken {
+		token := &oauthv1.OAuthAccessToken{ObjectMeta: created(age), UserName: user, ClientName: "openshift-browser-client", ExpiresIn: expiresIn}
+		token.Name = name
+		return token
+	}
+	authorizeToken := &oauthv1.OAuthAuthorizeToken{ObjectMeta: created(time.Minute), UserName: "alice", ClientName: "openshift-challenging-client", ExpiresIn: 300}
+	authorizeToken.Name = "sha256~code"
+	return oauthfake.NewSimpleClientset(
+		accessToken("sha256~old", "alice", 40*24*time.Hour, 86400),
+		accessToken("sha256~new", "alice", 3*time.Hour, 0),
+		accessToken("sha256~bob", "bob", 60*24*time.Hour, 86400),
+		authorizeToken,
+	).OauthV1()
+}
+
+func tokenNames(tokens []token) []string {
+	var names []string
+	for _, t := range tokens {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection tokenSelection
+		want      []string
+	}{
+		{
+			name:      "user",
+			selection: tokenSelection{User: "alice"},
+			want:      []string{"sha256~old", "sha256~new", "sha256~code"},
+		},
+		{
+			name:      "all users older than",
+			selection: tokenSelection{AllUsers: true, OlderThan: 30 * 24 * time.Hour},
+			want:      []string{"sha256~old", "sha256~bob"},
+		},
+		{
+			name:      "user newer than",
+			selection: tokenSelection{User: "alice", NewerThan: time.Hour},
+			want:      []string{"sha256~code"},
+		},
+		{
+			name:      "unknown user",
+			selection: tokenSelection{User: "carol"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.selection.Client = testClient()
+			tokens, err := tt.selection.find(testNow)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tokenNames(tokens); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got tokens %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintTokens(t *testing.T) {
+	s := tokenSelection{User: "alice", Client: testClient()}
+	tokens, err := s.find(testNow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	printTokens(out, testNow, tokens)
+	want := `USER    TYPE        NAME          CLIENT                         AGE   EXPIRES
+alice   access      sha256~old    openshift-browser-client       40d   expired
+alice   access      sha256~new    openshift-browser-client       3h    never
+alice   authorize   sha256~code   openshift-challenging-client   60s   4m
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := NewRevokeOptions(genericclioptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.Selection = tokenSelection{AllUsers: true, Client: testClient()}
+	if err := o.Validate(); err == nil {
+		t.Fatalf("expected --all-users without --older-than to be rejected")
+	}
+
+	o.Selection.OlderThan = 30 * 24 * time.Hour
+	o.DryRunStrategy = kcmdutil.DryRunClient
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := listAccessTokens(t, o.Selection.Client); len(remaining) != 3 {
+		t.Errorf("expected the dry run to keep the tokens, got %q", remaining)
+	}
+
+	out.Reset()
+	o.DryRunStrategy = kcmdutil.DryRunNone
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	want := `oauthaccesstoken.oauth.openshift.io/sha256~old of user alice revoked
+oauthaccesstoken.oauth.openshift.io/sha256~bob of user bob revoked
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+	if remaining := listAccessTokens(t, o.Selection.Client); !reflect.DeepEqual(remaining, []string{"sha256~new"}) {
+		t.Errorf("unexpected remaining tokens %q", remaining)
+	}
+}
+
+func listAccessTokens(t *testing.T, client oauthv1client.OauthV1Interface) []string {
+	tokens, err := client.OAuthAccessTokens().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, token := range tokens.Items {
+		names = append(names, token.Name)
+	}
+	return names
+}
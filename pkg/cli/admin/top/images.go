@@ -44,10 +44,19 @@ var (
 	topImagesExample = templates.Examples(`
 		# Show usage statistics for images
 		oc adm top images
+
+		# Show the largest images first, as JSON
+		oc adm top images --sort-by=storage -o json
 	`)
 )
 
+// imageSortKeys are the values of --sort-by of the images.
+var imageSortKeys = []string{"imagestreamtags", "storage", "name"}
+
 type TopImagesOptions struct {
+	SortBy string
+	Output string
+
 	// internal values
 	Images  *imagev1.ImageList
 	Streams *imagev1.ImageStreamList
@@ -77,6 +86,9 @@ func NewCmdTopImages(f kcmdutil.Factory, streams genericclioptions.IOStreams) *c
 		},
 	}
 
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, fmt.Sprintf("Sort by one of: %s. Defaults to imagestreamtags, the images referenced by the most image stream tags first.", strings.Join(imageSortKeys, "|")))
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+
 	return cmd
 }
 
@@ -124,12 +136,18 @@ func (o *TopImagesOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args
 
 // Validate ensures that a TopImagesOptions is valid and can be used to execute command.
 func (o TopImagesOptions) Validate(cmd *cobra.Command) error {
-	return nil
+	if len(o.SortBy) > 0 && !sets.NewString(imageSortKeys...).Has(o.SortBy) {
+		return fmt.Errorf("--sort-by must be one of: %s", strings.Join(imageSortKeys, ", "))
+	}
+	return validateOutput(o.Output)
 }
 
 // Run contains all the necessary functionality to show current image references.
 func (o TopImagesOptions) Run() error {
 	infos := o.imagesTop()
+	if len(o.Output) > 0 {
+		return PrintObjects(o.Out, o.Output, infos)
+	}
 	Print(o.Out, ImageColumns, infos)
 	return nil
 }
@@ -138,12 +156,12 @@ var ImageColumns = []string{"NAME", "IMAGESTREAMTAG", "PARENTS", "USAGE", "METAD
 
 // imageInfo contains statistic information about Image usage.
 type imageInfo struct {
-	Image           string
-	ImageStreamTags []string
-	Parents         []string
-	Usage           []string
-	Metadata        bool
-	Storage         int64
+	Image           string   `json:"image"`
+	ImageStreamTags []string `json:"imageStreamTags"`
+	Parents         []string `json:"parents"`
+	Usage           []string `json:"usage"`
+	Metadata        bool     `json:"metadata"`
+	Storage         int64    `json:"storage"`
 }
 
 var _ Info = &imageInfo{}
@@ -194,6 +212,15 @@ func (o TopImagesOptions) imagesTop() []Info {
 	}
 	sort.Slice(infos, func(i, j int) bool {
 		a, b := infos[i].(imageInfo), infos[j].(imageInfo)
+		switch o.SortBy {
+		case "storage":
+			if a.Storage != b.Storage {
+				return a.Storage > b.Storage
+			}
+			return a.Image < b.Image
+		case "name":
+			return a.Image < b.Image
+		}
 		if len(a.ImageStreamTags) < len(b.ImageStreamTags) {
 			return false
 		}
@@ -207,27 +234,56 @@ func (o TopImagesOptions) imagesTop() []Info {
 }
 
 func getStorage(image *imagev1.Image) int64 {
-	storage := int64(0)
-	blobSet := sets.NewString()
+	usage := newImageStorage()
+	usage.add(image)
+	return usage.storage
+}
+
+// imageStorage sums up the size of the blobs of images.
+type imageStorage struct {
+	images sets.String
+	blobs  sets.String
+	// storage is the size of the unique blobs of the images.
+	storage int64
+	// raw is the size of the blobs of every image, including the blobs shared with other images.
+	raw    int64
+	layers int
+}
+
+func newImageStorage() *imageStorage {
+	return &imageStorage{
+		images: sets.NewString(),
+		blobs:  sets.NewString(),
+	}
+}
+
+// add adds the layers and config of the image, unless it was already added.
+func (s *imageStorage) add(image *imagev1.Image) {
+	if s.images.Has(image.Name) {
+		return
+	}
+	s.images.Insert(image.Name)
+	s.layers += len(image.DockerImageLayers)
 	for _, layer := range image.DockerImageLayers {
-		if blobSet.Has(layer.Name) {
+		s.raw += layer.LayerSize
+		if s.blobs.Has(layer.Name) {
 			continue
 		}
-		blobSet.Insert(layer.Name)
-		storage += layer.LayerSize
+		s.blobs.Insert(layer.Name)
+		s.storage += layer.LayerSize
 	}
 	if err := imageutil.ImageWithMetadata(image); err != nil {
-		return storage
+		return
 	}
 	dockerImage, ok := image.DockerImageMetadata.Object.(*dockerv10.DockerImage)
-	if !ok {
-		return storage
+	if !ok || len(image.DockerImageConfig) == 0 {
+		return
 	}
-	if len(image.DockerImageConfig) > 0 && !blobSet.Has(dockerImage.ID) {
-		blobSet.Insert(dockerImage.ID)
-		storage += int64(len(image.DockerImageConfig))
+	s.raw += int64(len(image.DockerImageConfig))
+	if !s.blobs.Has(dockerImage.ID) {
+		s.blobs.Insert(dockerImage.ID)
+		s.storage += int64(len(image.DockerImageConfig))
 	}
-	return storage
 }
 
 func getImageStreamTags(g genericgraph.Graph, node *imagegraph.ImageNode) []string {
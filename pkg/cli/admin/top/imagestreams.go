@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	units "github.com/docker/go-units"
 	gonum "github.com/gonum/graph"
@@ -16,10 +17,8 @@ import (
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 
-	dockerv10 "github.com/openshift/api/image/docker10"
 	imagev1 "github.com/openshift/api/image/v1"
 	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
-	"github.com/openshift/library-go/pkg/image/imageutil"
 	"github.com/openshift/oc/pkg/helpers/graph/genericgraph"
 	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
 )
@@ -32,15 +31,34 @@ var (
 
 		This command analyzes all the image streams managed by the platform and presents current
 		usage statistics.
+
+		STORAGE is the size of the blobs of the images of an image stream, counting the blobs shared
+		by several of its images once, which is about the storage the image stream uses in the
+		registry. RAW is the size of the blobs of every image, as if no blob was shared. With
+		--by-namespace, the usage of the image streams of each namespace is summed up, counting the
+		blobs shared by image streams of a namespace once.
 	`)
 
 	topImageStreamsExample = templates.Examples(`
 		# Show usage statistics for image streams
 		oc adm top imagestreams
+
+		# Show the namespaces using the most storage in the registry
+		oc adm top imagestreams --by-namespace
+
+		# Show the image streams with the most images, as JSON
+		oc adm top imagestreams --sort-by=images -o json
 	`)
 )
 
+// imageStreamSortKeys are the values of --sort-by of the image streams and namespaces.
+var imageStreamSortKeys = []string{"storage", "raw", "images", "layers", "name"}
+
 type TopImageStreamsOptions struct {
+	ByNamespace bool
+	SortBy      string
+	Output      string
+
 	// internal values
 	Images  *imagev1.ImageList
 	Streams *imagev1.ImageStreamList
@@ -70,6 +88,10 @@ func NewCmdTopImageStreams(f kcmdutil.Factory, streams genericclioptions.IOStrea
 		Aliases: []string{"imagestreams", "is"},
 	}
 
+	cmd.Flags().BoolVar(&o.ByNamespace, "by-namespace", o.ByNamespace, "Show the usage of the image streams of each namespace.")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, fmt.Sprintf("Sort by one of: %s. Defaults to storage.", strings.Join(imageStreamSortKeys, "|")))
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml.")
+
 	return cmd
 }
 
@@ -106,24 +128,36 @@ func (o *TopImageStreamsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command
 
 // Validate ensures that a TopImageStreamsOptions is valid and can be used to execute command.
 func (o TopImageStreamsOptions) Validate(cmd *cobra.Command) error {
-	return nil
+	if len(o.SortBy) > 0 && !sets.NewString(imageStreamSortKeys...).Has(o.SortBy) {
+		return fmt.Errorf("--sort-by must be one of: %s", strings.Join(imageStreamSortKeys, ", "))
+	}
+	return validateOutput(o.Output)
 }
 
 // Run contains all the necessary functionality to show current image references.
 func (o TopImageStreamsOptions) Run() error {
-	infos := o.imageStreamsTop()
-	Print(o.Out, ImageStreamColumns, infos)
+	infos, columns := o.imageStreamsTop(), ImageStreamColumns
+	if o.ByNamespace {
+		infos, columns = o.namespacesTop(), NamespaceColumns
+	}
+	if len(o.Output) > 0 {
+		return PrintObjects(o.Out, o.Output, infos)
+	}
+	Print(o.Out, columns, infos)
 	return nil
 }
 
-var ImageStreamColumns = []string{"NAME", "STORAGE", "IMAGES", "LAYERS"}
+var ImageStreamColumns = []string{"NAME", "STORAGE", "RAW", "IMAGES", "LAYERS"}
+
+var NamespaceColumns = []string{"NAMESPACE", "STORAGE", "RAW", "IMAGESTREAMS", "IMAGES", "LAYERS"}
 
 // imageStreamInfo contains contains statistic information about ImageStream usage.
 type imageStreamInfo struct {
-	ImageStream string
-	Storage     int64
-	Images      int
-	Layers      int
+	ImageStream string `json:"imageStream"`
+	Storage     int64  `json:"storage"`
+	Raw         int64  `json:"raw"`
+	Images      int    `json:"images"`
+	Layers      int    `json:"layers"`
 }
 
 var _ Info = &imageStreamInfo{}
@@ -131,6 +165,28 @@ var _ Info = &imageStreamInfo{}
 func (i imageStreamInfo) PrintLine(out io.Writer) {
 	printValue(out, i.ImageStream)
 	printValue(out, units.BytesSize(float64(i.Storage)))
+	printValue(out, units.BytesSize(float64(i.Raw)))
+	printValue(out, i.Images)
+	printValue(out, i.Layers)
+}
+
+// namespaceInfo contains statistic information about the ImageStream usage of a namespace.
+type namespaceInfo struct {
+	Namespace    string `json:"namespace"`
+	Storage      int64  `json:"storage"`
+	Raw          int64  `json:"raw"`
+	ImageStreams int    `json:"imageStreams"`
+	Images       int    `json:"images"`
+	Layers       int    `json:"layers"`
+}
+
+var _ Info = &namespaceInfo{}
+
+func (i namespaceInfo) PrintLine(out io.Writer) {
+	printValue(out, i.Namespace)
+	printValue(out, units.BytesSize(float64(i.Storage)))
+	printValue(out, units.BytesSize(float64(i.Raw)))
+	printValue(out, i.ImageStreams)
 	printValue(out, i.Images)
 	printValue(out, i.Layers)
 }
@@ -145,63 +201,110 @@ func (o TopImageStreamsOptions) imageStreamsTop() []Info {
 	infos := []Info{}
 	streamNodes := getImageStreamNodes(g.Nodes())
 	for _, sn := range streamNodes {
-		storage, images, layers := getImageStreamSize(g, sn)
+		// we're counting only unique layers per the entire stream
+		usage := newImageStorage()
+		addImageStreamImages(g, sn, usage)
 		infos = append(infos, imageStreamInfo{
 			ImageStream: fmt.Sprintf("%s/%s", sn.ImageStream.Namespace, sn.ImageStream.Name),
-			Storage:     storage,
-			Images:      images,
-			Layers:      layers,
+			Storage:     usage.storage,
+			Raw:         usage.raw,
+			Images:      usage.images.Len(),
+			Layers:      usage.layers,
 		})
 	}
-	sort.Slice(infos, func(i, j int) bool {
-		a, b := infos[i].(imageStreamInfo), infos[j].(imageStreamInfo)
-		if a.Storage < b.Storage {
-			return false
-		}
-		if a.Storage > b.Storage {
-			return true
+	sortStorageInfos(infos, o.SortBy)
+
+	return infos
+}
+
+// namespacesTop generates the ImageStream information of each namespace from a
+// graph and returns this as a list of namespaceInfo array.
+func (o TopImageStreamsOptions) namespacesTop() []Info {
+	g := genericgraph.New()
+	addImagesToGraph(g, o.Images)
+	addImageStreamsToGraph(g, o.Streams)
+
+	usages := map[string]*imageStorage{}
+	streams := map[string]int{}
+	for _, sn := range getImageStreamNodes(g.Nodes()) {
+		namespace := sn.ImageStream.Namespace
+		// we're counting only unique layers per the entire namespace
+		if _, ok := usages[namespace]; !ok {
+			usages[namespace] = newImageStorage()
 		}
-		return a.Images > b.Images
-	})
+		addImageStreamImages(g, sn, usages[namespace])
+		streams[namespace]++
+	}
+
+	infos := []Info{}
+	for namespace, usage := range usages {
+		infos = append(infos, namespaceInfo{
+			Namespace:    namespace,
+			Storage:      usage.storage,
+			Raw:          usage.raw,
+			ImageStreams: streams[namespace],
+			Images:       usage.images.Len(),
+			Layers:       usage.layers,
+		})
+	}
+	sortStorageInfos(infos, o.SortBy)
 
 	return infos
 }
 
-func getImageStreamSize(g genericgraph.Graph, node *imagegraph.ImageStreamNode) (int64, int, int) {
-	imageEdges := g.OutboundEdges(node, ImageStreamImageEdgeKind)
-	storage := int64(0)
-	images := len(imageEdges)
-	layers := 0
-	blobSet := sets.NewString()
-	for _, e := range imageEdges {
+// addImageStreamImages adds the images of the image stream to the storage usage.
+func addImageStreamImages(g genericgraph.Graph, node *imagegraph.ImageStreamNode, usage *imageStorage) {
+	for _, e := range g.OutboundEdges(node, ImageStreamImageEdgeKind) {
 		imageNode, ok := e.To().(*imagegraph.ImageNode)
 		if !ok {
 			continue
 		}
-		image := imageNode.Image
-		layers += len(image.DockerImageLayers)
-		// we're counting only unique layers per the entire stream
-		for _, layer := range image.DockerImageLayers {
-			if blobSet.Has(layer.Name) {
-				continue
-			}
-			blobSet.Insert(layer.Name)
-			storage += layer.LayerSize
-		}
-		if err := imageutil.ImageWithMetadata(image); err != nil {
-			continue
-		}
-		dockerImage, ok := image.DockerImageMetadata.Object.(*dockerv10.DockerImage)
-		if !ok {
-			continue
-		}
-		if len(image.DockerImageConfig) > 0 && !blobSet.Has(dockerImage.ID) {
-			blobSet.Insert(dockerImage.ID)
-			storage += int64(len(image.DockerImageConfig))
-		}
+		usage.add(imageNode.Image)
 	}
+}
 
-	return storage, images, layers
+// storageValues returns the name and usage of an imageStreamInfo or namespaceInfo.
+func storageValues(info Info) (name string, storage, raw int64, images, layers int) {
+	switch i := info.(type) {
+	case imageStreamInfo:
+		return i.ImageStream, i.Storage, i.Raw, i.Images, i.Layers
+	case namespaceInfo:
+		return i.Namespace, i.Storage, i.Raw, i.Images, i.Layers
+	}
+	return "", 0, 0, 0, 0
+}
+
+// sortStorageInfos sorts image stream or namespace infos by the key, in decreasing order of usage
+// or by name. The default is by storage, then by images.
+func sortStorageInfos(infos []Info, key string) {
+	sort.SliceStable(infos, func(i, j int) bool {
+		aName, aStorage, aRaw, aImages, aLayers := storageValues(infos[i])
+		bName, bStorage, bRaw, bImages, bLayers := storageValues(infos[j])
+		switch key {
+		case "raw":
+			if aRaw != bRaw {
+				return aRaw > bRaw
+			}
+		case "images":
+			if aImages != bImages {
+				return aImages > bImages
+			}
+		case "layers":
+			if aLayers != bLayers {
+				return aLayers > bLayers
+			}
+		case "name":
+			return aName < bName
+		default:
+			if aStorage != bStorage {
+				return aStorage > bStorage
+			}
+			if aImages != bImages {
+				return aImages > bImages
+			}
+		}
+		return aName < bName
+	})
 }
 
 func getImageStreamNodes(nodes []gonum.Node) []*imagegraph.ImageStreamNode {
@@ -1,6 +1,9 @@
 package top
 
 import (
+	"bytes"
+	"reflect"
+	"strings"
 	"testing"
 
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
@@ -102,6 +105,7 @@ func TestImageStreamsTop(t *testing.T) {
 				imageStreamInfo{
 					ImageStream: "ns1/stream1",
 					Storage:     int64(1024),
+					Raw:         int64(1024),
 					Images:      1,
 					Layers:      1,
 				},
@@ -138,6 +142,7 @@ func TestImageStreamsTop(t *testing.T) {
 				imageStreamInfo{
 					ImageStream: "ns1/stream1",
 					Storage:     int64(1536),
+					Raw:         int64(1536),
 					Images:      1,
 					Layers:      2,
 				},
@@ -182,6 +187,7 @@ func TestImageStreamsTop(t *testing.T) {
 				imageStreamInfo{
 					ImageStream: "ns1/stream1",
 					Storage:     int64(1152),
+					Raw:         int64(2176),
 					Images:      2,
 					Layers:      3,
 				},
@@ -238,6 +244,7 @@ func TestImageStreamsTop(t *testing.T) {
 				imageStreamInfo{
 					ImageStream: "ns1/stream1",
 					Storage:     int64(1152 + len("raw image config")),
+					Raw:         int64(2176 + 2*len("raw image config")),
 					Images:      2,
 					Layers:      3,
 				},
@@ -278,6 +285,7 @@ func TestImageStreamsTop(t *testing.T) {
 				imageStreamInfo{
 					ImageStream: "ns1/stream1",
 					Storage:     int64(1024),
+					Raw:         int64(1024),
 					Images:      1,
 					Layers:      1,
 				},
@@ -297,6 +305,107 @@ func TestImageStreamsTop(t *testing.T) {
 	}
 }
 
+func TestNamespacesTop(t *testing.T) {
+	images := &imagev1.ImageList{
+		Items: []imagev1.Image{
+			{
+				ObjectMeta:        metav1.ObjectMeta{Name: "image1"},
+				DockerImageLayers: []imagev1.ImageLayer{{Name: "layer1", LayerSize: int64(1024)}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "image2"},
+				DockerImageLayers: []imagev1.ImageLayer{
+					{Name: "layer1", LayerSize: int64(1024)},
+					{Name: "layer2", LayerSize: int64(128)},
+				},
+			},
+		},
+	}
+	stream := func(namespace, name string, images ...string) imagev1.ImageStream {
+		stream := imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		for _, image := range images {
+			stream.Status.Tags = append(stream.Status.Tags, imagev1.NamedTagEventList{
+				Tag:   image,
+				Items: []imagev1.TagEvent{{Image: image}},
+			})
+		}
+		return stream
+	}
+	streams := &imagev1.ImageStreamList{
+		Items: []imagev1.ImageStream{
+			stream("ns1", "stream1", "image1"),
+			stream("ns1", "stream2", "image1", "image2"),
+			stream("ns2", "stream1", "image2"),
+			stream("ns3", "stream1"),
+		},
+	}
+
+	o := TopImageStreamsOptions{Images: images, Streams: streams}
+	infos := o.namespacesTop()
+	expected := []Info{
+		// image1 is counted once, and layer1 is shared by image1 and image2
+		namespaceInfo{Namespace: "ns1", Storage: 1152, Raw: 2176, ImageStreams: 2, Images: 2, Layers: 3},
+		namespaceInfo{Namespace: "ns2", Storage: 1152, Raw: 1152, ImageStreams: 1, Images: 1, Layers: 2},
+		namespaceInfo{Namespace: "ns3", ImageStreams: 1},
+	}
+	if !apiequality.Semantic.DeepEqual(infos, expected) {
+		t.Errorf("unexpected infos, expected %#v, got %#v", expected, infos)
+	}
+}
+
+func TestSortStorageInfos(t *testing.T) {
+	infos := []Info{
+		imageStreamInfo{ImageStream: "ns1/a", Storage: 100, Raw: 300, Images: 3, Layers: 1},
+		imageStreamInfo{ImageStream: "ns1/b", Storage: 200, Raw: 200, Images: 1, Layers: 3},
+		imageStreamInfo{ImageStream: "ns1/c", Storage: 100, Raw: 100, Images: 4, Layers: 2},
+	}
+	for key, expected := range map[string][]string{
+		"":       {"ns1/b", "ns1/c", "ns1/a"},
+		"raw":    {"ns1/a", "ns1/b", "ns1/c"},
+		"images": {"ns1/c", "ns1/a", "ns1/b"},
+		"layers": {"ns1/b", "ns1/c", "ns1/a"},
+		"name":   {"ns1/a", "ns1/b", "ns1/c"},
+	} {
+		sortStorageInfos(infos, key)
+		var names []string
+		for _, info := range infos {
+			names = append(names, info.(imageStreamInfo).ImageStream)
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("sorted by %q: expected %v, got %v", key, expected, names)
+		}
+	}
+}
+
+func TestPrintObjects(t *testing.T) {
+	infos := []Info{imageStreamInfo{ImageStream: "ns1/stream1", Storage: 1024, Raw: 2048, Images: 2, Layers: 2}}
+	out := &bytes.Buffer{}
+	if err := PrintObjects(out, "json", infos); err != nil {
+		t.Fatal(err)
+	}
+	expected := `[
+  {
+    "imageStream": "ns1/stream1",
+    "storage": 1024,
+    "raw": 2048,
+    "images": 2,
+    "layers": 2
+  }
+]
+`
+	if out.String() != expected {
+		t.Errorf("unexpected JSON:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := PrintObjects(out, "yaml", infos); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "- imageStream: ns1/stream1\n") {
+		t.Errorf("unexpected YAML:\n%s", out.String())
+	}
+}
+
 func infosEqual(actual, expected []Info) bool {
 	if len(actual) != len(expected) {
 		return false
@@ -6,6 +6,8 @@ import (
 	"io"
 	"strings"
 	"text/tabwriter"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 )
 
 type Info interface {
@@ -23,6 +25,20 @@ func Print(out io.Writer, headers []string, infos []Info) {
 	fmt.Fprintf(out, "%s", s)
 }
 
+// PrintObjects prints the infos as a JSON or YAML list.
+func PrintObjects(out io.Writer, format string, infos []Info) error {
+	return cmdutil.PrintJSONOrYAML(out, format, infos)
+}
+
+// validateOutput returns an error unless the output format is empty, json or yaml.
+func validateOutput(output string) error {
+	switch output {
+	case "", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("--output must be one of: json, yaml")
+}
+
 func printHeader(out io.Writer, columns []string) {
 	for _, col := range columns {
 		printValue(out, col)
@@ -0,0 +1,133 @@
+// Package history contains a command for displaying the update history of a cluster.
+package history
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+func NewOptions(streams genericclioptions.IOStreams) *Options {
+	return &Options{
+		IOStreams: streams,
+	}
+}
+
+func New(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewOptions(streams)
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Display the update history of the cluster",
+		Long: templates.LongDesc(`
+			Display the update history of the cluster.
+
+			This command lists the versions the cluster was installed with or updated to, most recent
+			first, with when each update started and completed. An update is Completed once it was
+			fully applied, and Partial while it is being applied or if it never was fully applied.
+		`),
+		Example: templates.Examples(`
+			# Display the update history
+			oc adm upgrade history
+
+			# Display the update history with the release images
+			oc adm upgrade history -o wide
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: wide. Wide output includes the release images.")
+	return cmd
+}
+
+type Options struct {
+	genericclioptions.IOStreams
+
+	Output string
+
+	Client configv1client.Interface
+}
+
+func (o *Options) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "positional arguments given")
+	}
+	if o.Output != "" && o.Output != "wide" {
+		return fmt.Errorf("--output must be wide or empty")
+	}
+
+	cfg, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	client, err := configv1client.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	o.Client = client
+	return nil
+}
+
+func (o *Options) Run() error {
+	cv, err := o.Client.ConfigV1().ClusterVersions().Get(context.TODO(), "version", metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("no cluster version information available - you must be connected to an OpenShift version 4 server to fetch the current version")
+		}
+		return err
+	}
+
+	if len(cv.Status.History) == 0 {
+		fmt.Fprintf(o.Out, "info: The cluster has no update history\n")
+		return nil
+	}
+	return printHistory(o.Out, cv.Status.History, o.Output == "wide", time.Now())
+}
+
+// printHistory prints a table of the history, which the cluster version operator keeps sorted from the most recent
+// update.
+func printHistory(out io.Writer, history []configv1.UpdateHistory, wide bool, now time.Time) error {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	if wide {
+		fmt.Fprintln(w, "VERSION\tSTATE\tSTARTED\tCOMPLETED\tDURATION\tVERIFIED\tIMAGE")
+	} else {
+		fmt.Fprintln(w, "VERSION\tSTATE\tSTARTED\tCOMPLETED\tDURATION\tVERIFIED")
+	}
+	for _, update := range history {
+		version := update.Version
+		if len(version) == 0 {
+			version = "<unknown>"
+		}
+		completed, took := "-", "-"
+		if update.CompletionTime != nil {
+			completed = update.CompletionTime.UTC().Format(time.RFC3339)
+		}
+		switch {
+		case update.State == configv1.CompletedUpdate && update.CompletionTime != nil:
+			took = duration.HumanDuration(update.CompletionTime.Sub(update.StartedTime.Time))
+		case update.CompletionTime == nil:
+			// the update is being applied
+			took = duration.HumanDuration(now.Sub(update.StartedTime.Time))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t", version, update.State, update.StartedTime.UTC().Format(time.RFC3339), completed, took, update.Verified)
+		if wide {
+			fmt.Fprintf(w, "\t%s", update.Image)
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,31 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPrintHistory(t *testing.T) {
+	start := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	completed := metav1.NewTime(start.Add(50 * time.Minute))
+	history := []configv1.UpdateHistory{
+		{State: configv1.PartialUpdate, Version: "4.10.5", Image: "quay.io/release@sha256:5", StartedTime: metav1.NewTime(start.Add(2 * time.Hour)), Verified: true},
+		{State: configv1.CompletedUpdate, Version: "4.10.3", Image: "quay.io/release@sha256:3", StartedTime: metav1.NewTime(start), CompletionTime: &completed},
+	}
+
+	out := &bytes.Buffer{}
+	if err := printHistory(out, history, false, start.Add(150*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	expected := `VERSION   STATE       STARTED                COMPLETED              DURATION   VERIFIED
+4.10.5    Partial     2022-06-01T12:00:00Z   -                      30m        true
+4.10.3    Completed   2022-06-01T10:00:00Z   2022-06-01T10:50:00Z   50m        false
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
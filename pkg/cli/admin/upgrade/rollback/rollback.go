@@ -0,0 +1,159 @@
+// Package rollback contains a command for rolling a cluster back to its previous release.
+package rollback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver"
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+func NewOptions(streams genericclioptions.IOStreams) *Options {
+	return &Options{
+		IOStreams: streams,
+	}
+}
+
+func New(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewOptions(streams)
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Roll the cluster back to its previous release",
+		Long: templates.LongDesc(`
+			Roll the cluster back to its previous release.
+
+			This command requests an update to the most recent release of the update history that
+			was completely applied, other than the current one, typically to abandon a failing
+			update. The cluster version operator then attempts the rollback like any other update.
+			Not all rollbacks succeed.
+
+			Only rollbacks to a previous patch (z stream) version of the same minor version, like
+			4.1.2 -> 4.1.1, are requested: rolling back to a previous minor version (4.2 -> 4.1) is
+			likely to cause data corruption or to completely break a cluster, and is refused.
+
+			Pass --dry-run to display the release the cluster would be rolled back to without
+			requesting the rollback.
+		`),
+		Example: templates.Examples(`
+			# Display the release the cluster would be rolled back to
+			oc adm upgrade rollback --dry-run
+
+			# Roll the cluster back to its previous release
+			oc adm upgrade rollback
+		`),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	flags := cmd.Flags()
+	flags.BoolVar(&o.DryRun, "dry-run", o.DryRun, "Display the release the cluster would be rolled back to, without requesting the rollback.")
+	return cmd
+}
+
+type Options struct {
+	genericclioptions.IOStreams
+
+	DryRun bool
+
+	Client configv1client.Interface
+}
+
+func (o *Options) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "positional arguments given")
+	}
+
+	cfg, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	client, err := configv1client.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	o.Client = client
+	return nil
+}
+
+func (o *Options) Run() error {
+	ctx := context.TODO()
+	cv, err := o.Client.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("no cluster version information available - you must be connected to an OpenShift version 4 server to fetch the current version")
+		}
+		return err
+	}
+
+	for _, c := range cv.Status.Conditions {
+		if c.Type == "Invalid" && c.Status == configv1.ConditionTrue {
+			return fmt.Errorf("the cluster version object is invalid, you must correct the invalid state first:\n\n  Reason: %s\n  Message: %s\n", c.Reason, strings.ReplaceAll(c.Message, "\n", "\n  "))
+		}
+	}
+
+	previous, err := rollbackTarget(cv)
+	if err != nil {
+		return err
+	}
+
+	if o.DryRun {
+		fmt.Fprintf(o.Out, "info: The cluster would be rolled back from %s to %s (%s)\n", cv.Status.Desired.Version, previous.Version, previous.Image)
+		return nil
+	}
+
+	// the image of the history is used, which the cluster version operator verified when it was applied
+	updateJSON, err := json.Marshal(&configv1.Update{Version: previous.Version, Image: previous.Image})
+	if err != nil {
+		return fmt.Errorf("marshal ClusterVersion patch: %v", err)
+	}
+	patch := []byte(fmt.Sprintf(`{"spec":{"desiredUpdate": %s}}`, updateJSON))
+	if _, err := o.Client.ConfigV1().ClusterVersions().Patch(ctx, cv.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("unable to roll back: %v", err)
+	}
+	fmt.Fprintf(o.Out, "Requesting rollback from %s to %s\n", cv.Status.Desired.Version, previous.Version)
+	return nil
+}
+
+// rollbackTarget returns the most recent completed update of the history other than the desired release, if it is a
+// previous patch version of the same minor version.
+func rollbackTarget(cv *configv1.ClusterVersion) (*configv1.UpdateHistory, error) {
+	var previous *configv1.UpdateHistory
+	for i := range cv.Status.History {
+		update := &cv.Status.History[i]
+		if update.State == configv1.CompletedUpdate && update.Image != cv.Status.Desired.Image {
+			previous = update
+			break
+		}
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("there is no previous completed release in the update history to roll back to")
+	}
+
+	current, err := semver.Parse(cv.Status.Desired.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to roll back, the current version %q is not a semantic version: %v", cv.Status.Desired.Version, err)
+	}
+	target, err := semver.Parse(previous.Version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to roll back, the previous version %q is not a semantic version: %v", previous.Version, err)
+	}
+	if current.Major != target.Major || current.Minor != target.Minor {
+		return nil, fmt.Errorf("the previous release %s is not of the same minor version as the current release %s, rolling back across minor versions is not supported", previous.Version, cv.Status.Desired.Version)
+	}
+	if !target.LT(current) {
+		return nil, fmt.Errorf("the previous release %s is not older than the current release %s, use 'oc adm upgrade --to=%s' to update to it", previous.Version, cv.Status.Desired.Version, previous.Version)
+	}
+	return previous, nil
+}
@@ -0,0 +1,85 @@
+package rollback
+
+import (
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func TestRollbackTarget(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		desired  configv1.Release
+		history  []configv1.UpdateHistory
+		expected string
+		err      string
+	}{
+		{
+			name:    "completed update",
+			desired: configv1.Release{Version: "4.10.5", Image: "quay.io/release@sha256:5"},
+			history: []configv1.UpdateHistory{
+				{State: configv1.CompletedUpdate, Version: "4.10.5", Image: "quay.io/release@sha256:5"},
+				{State: configv1.CompletedUpdate, Version: "4.10.3", Image: "quay.io/release@sha256:3"},
+				{State: configv1.CompletedUpdate, Version: "4.10.1", Image: "quay.io/release@sha256:1"},
+			},
+			expected: "4.10.3",
+		},
+		{
+			name:    "partial update",
+			desired: configv1.Release{Version: "4.10.5", Image: "quay.io/release@sha256:5"},
+			history: []configv1.UpdateHistory{
+				{State: configv1.PartialUpdate, Version: "4.10.5", Image: "quay.io/release@sha256:5"},
+				{State: configv1.PartialUpdate, Version: "4.10.4", Image: "quay.io/release@sha256:4"},
+				{State: configv1.CompletedUpdate, Version: "4.10.3", Image: "quay.io/release@sha256:3"},
+			},
+			expected: "4.10.3",
+		},
+		{
+			name:    "previous minor version",
+			desired: configv1.Release{Version: "4.11.0", Image: "quay.io/release@sha256:110"},
+			history: []configv1.UpdateHistory{
+				{State: configv1.PartialUpdate, Version: "4.11.0", Image: "quay.io/release@sha256:110"},
+				{State: configv1.CompletedUpdate, Version: "4.10.3", Image: "quay.io/release@sha256:3"},
+			},
+			err: "rolling back across minor versions is not supported",
+		},
+		{
+			name:    "newer version",
+			desired: configv1.Release{Version: "4.10.3", Image: "quay.io/release@sha256:3"},
+			history: []configv1.UpdateHistory{
+				{State: configv1.PartialUpdate, Version: "4.10.3", Image: "quay.io/release@sha256:3"},
+				{State: configv1.CompletedUpdate, Version: "4.10.5", Image: "quay.io/release@sha256:5"},
+			},
+			err: "is not older than the current release",
+		},
+		{
+			name:    "installed version",
+			desired: configv1.Release{Version: "4.10.3", Image: "quay.io/release@sha256:3"},
+			history: []configv1.UpdateHistory{
+				{State: configv1.CompletedUpdate, Version: "4.10.3", Image: "quay.io/release@sha256:3"},
+			},
+			err: "there is no previous completed release",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			cv := &configv1.ClusterVersion{}
+			cv.Status.Desired = testCase.desired
+			cv.Status.History = testCase.history
+
+			previous, err := rollbackTarget(cv)
+			if len(testCase.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), testCase.err) {
+					t.Fatalf("expected error containing %q, got %v", testCase.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if previous.Version != testCase.expected {
+				t.Errorf("%s != %s", previous.Version, testCase.expected)
+			}
+		})
+	}
+}
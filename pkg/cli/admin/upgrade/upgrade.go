@@ -26,6 +26,8 @@ import (
 	imagereference "github.com/openshift/library-go/pkg/image/reference"
 
 	"github.com/openshift/oc/pkg/cli/admin/upgrade/channel"
+	"github.com/openshift/oc/pkg/cli/admin/upgrade/history"
+	"github.com/openshift/oc/pkg/cli/admin/upgrade/rollback"
 )
 
 var upgradeExample = templates.Examples(`
@@ -70,7 +72,8 @@ func New(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command
 			recommended for the current version. While rolling back to a previous patch (z stream) version
 			(4.1.2 -> 4.1.1) may be safe, upgrading more than one minor version ahead (4.1 -> 4.3) or
 			downgrading one minor version (4.2 -> 4.1) is likely to cause data corruption or to
-			completely break a cluster.
+			completely break a cluster. Use "oc adm upgrade rollback" to roll back to the previous
+			patch version.
 
 			If the cluster is already being upgraded, or if the cluster is reporting a failure or
 			other error, the update will not be triggered.  It is usually best to give these conditions
@@ -100,6 +103,8 @@ func New(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command
 	flags.BoolVar(&o.AllowNotRecommended, "allow-not-recommended", o.AllowNotRecommended, "Allows upgrade to a version when it is supported but not recommended for updates")
 
 	cmd.AddCommand(channel.New(f, streams))
+	cmd.AddCommand(history.New(f, streams))
+	cmd.AddCommand(rollback.New(f, streams))
 
 	return cmd
 }
@@ -422,7 +427,9 @@ func (o *Options) Run() error {
 			fmt.Fprintf(o.Out, "\nAdditional updates which are not recommended based on your cluster configuration are available, to view those re-run the command with --include-not-recommended.\n")
 		}
 
-		// TODO: print previous versions
+		if len(cv.Status.History) > 1 {
+			fmt.Fprintf(o.Out, "\nTo view the versions the cluster was updated from, run 'oc adm upgrade history'.\n")
+		}
 	}
 
 	return nil
@@ -0,0 +1,346 @@
+package usage
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	usageLong = templates.LongDesc(`
+		Report the usage of the resource quotas and limit ranges of the projects.
+
+		For every resource of a resource quota, the usage is the amount used relative to the hard
+		limit. For the maximum of a limit range of type Container or Pod, the usage is the largest
+		limit of a container or pod of the project relative to the maximum, which shows how close
+		the workloads are to the constraints of the project. Limit ranges of other types are not
+		reported.
+
+		The projects are selected by their labels with --selector, all projects by default. The
+		usages at or above --threshold percent are highlighted and listed after the table.
+
+		The report can be exported as CSV, JSON or YAML with --output for capacity planning.
+	`)
+
+	usageExample = templates.Examples(`
+		# Report the usage of the quotas and limit ranges of all projects
+		oc adm usage
+
+		# Report the usage of the projects of a team, highlighting usages above 90%
+		oc adm usage -l team=payments --threshold=90
+
+		# Export the usage of all projects as CSV
+		oc adm usage -o csv > usage.csv
+	`)
+)
+
+// UsageOptions holds the options to report the usage of the quotas and limit ranges of the projects.
+type UsageOptions struct {
+	Selector  string
+	Threshold int
+	Output    string
+
+	Client kubernetes.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewUsageOptions(streams genericclioptions.IOStreams) *UsageOptions {
+	return &UsageOptions{
+		Threshold: 80,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdUsage creates a command that reports the usage of the quotas and limit ranges of the projects.
+func NewCmdUsage(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewUsageOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "usage",
+		Short:   "Report the usage of the quotas and limit ranges of the projects",
+		Long:    usageLong,
+		Example: usageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Label selector of the projects to report.")
+	cmd.Flags().IntVar(&o.Threshold, "threshold", o.Threshold, "The percentage of usage at or above which a usage is highlighted.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: csv|json|yaml.")
+
+	return cmd
+}
+
+func (o *UsageOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	o.Client, err = f.KubernetesClientSet()
+	return err
+}
+
+func (o *UsageOptions) Validate() error {
+	if o.Threshold <= 0 {
+		return fmt.Errorf("--threshold must be greater than 0")
+	}
+	switch o.Output {
+	case "", "csv", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("--output must be one of: csv, json, yaml")
+}
+
+func (o *UsageOptions) Run() error {
+	namespaces, err := o.Client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: o.Selector})
+	if err != nil {
+		return err
+	}
+	selected := sets.NewString()
+	for _, ns := range namespaces.Items {
+		selected.Insert(ns.Name)
+	}
+
+	quotas, err := o.Client.CoreV1().ResourceQuotas(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	limitRanges, err := o.Client.CoreV1().LimitRanges(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var pods []corev1.Pod
+	if len(limitRanges.Items) > 0 {
+		list, err := o.Client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		pods = list.Items
+	}
+
+	var usages []resourceUsage
+	for i := range quotas.Items {
+		if selected.Has(quotas.Items[i].Namespace) {
+			usages = append(usages, quotaUsages(&quotas.Items[i])...)
+		}
+	}
+	podsByNamespace := map[string][]*corev1.Pod{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		podsByNamespace[pod.Namespace] = append(podsByNamespace[pod.Namespace], pod)
+	}
+	for i := range limitRanges.Items {
+		limitRange := &limitRanges.Items[i]
+		if selected.Has(limitRange.Namespace) {
+			usages = append(usages, limitRangeUsages(limitRange, podsByNamespace[limitRange.Namespace])...)
+		}
+	}
+	for i := range usages {
+		usages[i].AboveThreshold = usages[i].Percent >= o.Threshold
+	}
+	sortUsages(usages)
+
+	switch o.Output {
+	case "csv":
+		return printCSV(o.Out, usages)
+	case "json", "yaml":
+		if usages == nil {
+			usages = []resourceUsage{}
+		}
+		return cmdutil.PrintJSONOrYAML(o.Out, o.Output, usages)
+	}
+
+	if len(usages) == 0 {
+		fmt.Fprintf(o.Out, "No resource quotas or limit ranges found in %d project(s)\n", selected.Len())
+		return nil
+	}
+	printUsages(o.Out, usages)
+	printAboveThreshold(o.Out, usages, o.Threshold)
+	return nil
+}
+
+// resourceUsage is the usage of a resource of a resource quota or limit range.
+type resourceUsage struct {
+	Namespace string `json:"namespace"`
+	// Kind is ResourceQuota or LimitRange.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Resource is the resource of a quota, or the type and resource of a limit range, such as Container/memory.
+	Resource string `json:"resource"`
+	// Used is the amount used of a quota, or the largest limit of a container or pod for a limit range.
+	Used string `json:"used"`
+	// Hard is the hard limit of a quota, or the maximum of a limit range.
+	Hard           string `json:"hard"`
+	Percent        int    `json:"percent"`
+	AboveThreshold bool   `json:"aboveThreshold"`
+}
+
+// quotaUsages returns the usage of every hard limit of the quota.
+func quotaUsages(quota *corev1.ResourceQuota) []resourceUsage {
+	var usages []resourceUsage
+	for name, hard := range quota.Status.Hard {
+		used := quota.Status.Used[name]
+		usages = append(usages, resourceUsage{
+			Namespace: quota.Namespace,
+			Kind:      "ResourceQuota",
+			Name:      quota.Name,
+			Resource:  string(name),
+			Used:      used.String(),
+			Hard:      hard.String(),
+			Percent:   percent(used, hard),
+		})
+	}
+	return usages
+}
+
+// limitRangeUsages returns the usage of every maximum of the Container and Pod limits of the limit range by the
+// pods.
+func limitRangeUsages(limitRange *corev1.LimitRange, pods []*corev1.Pod) []resourceUsage {
+	var usages []resourceUsage
+	for _, item := range limitRange.Spec.Limits {
+		if item.Type != corev1.LimitTypeContainer && item.Type != corev1.LimitTypePod {
+			continue
+		}
+		for name, max := range item.Max {
+			var largest resource.Quantity
+			for _, pod := range pods {
+				if item.Type == corev1.LimitTypePod {
+					if total := podLimit(pod, name); total.Cmp(largest) > 0 {
+						largest = total
+					}
+					continue
+				}
+				for i := range pod.Spec.Containers {
+					if limit := containerLimit(&pod.Spec.Containers[i], name); limit.Cmp(largest) > 0 {
+						largest = limit
+					}
+				}
+			}
+			usages = append(usages, resourceUsage{
+				Namespace: limitRange.Namespace,
+				Kind:      "LimitRange",
+				Name:      limitRange.Name,
+				Resource:  fmt.Sprintf("%s/%s", item.Type, name),
+				Used:      largest.String(),
+				Hard:      max.String(),
+				Percent:   percent(largest, max),
+			})
+		}
+	}
+	return usages
+}
+
+// containerLimit returns the limit of the resource of the container, or its request if it has no limit.
+func containerLimit(container *corev1.Container, name corev1.ResourceName) resource.Quantity {
+	if limit, ok := container.Resources.Limits[name]; ok {
+		return limit
+	}
+	return container.Resources.Requests[name]
+}
+
+// podLimit returns the sum of the limits of the resource of the containers of the pod.
+func podLimit(pod *corev1.Pod, name corev1.ResourceName) resource.Quantity {
+	var total resource.Quantity
+	for i := range pod.Spec.Containers {
+		total.Add(containerLimit(&pod.Spec.Containers[i], name))
+	}
+	return total
+}
+
+// percent returns the percentage of hard that is used, 100 if something is used of a zero hard limit.
+func percent(used, hard resource.Quantity) int {
+	if hard.IsZero() {
+		if used.IsZero() {
+			return 0
+		}
+		return 100
+	}
+	return int(float64(used.MilliValue()) * 100 / float64(hard.MilliValue()))
+}
+
+// sortUsages sorts the usages by namespace, kind, name and resource.
+func sortUsages(usages []resourceUsage) {
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			// resource quotas first
+			return a.Kind > b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Resource < b.Resource
+	})
+}
+
+// printUsages prints a table of the usages, in which the usages above the threshold are marked with an asterisk.
+func printUsages(out io.Writer, usages []resourceUsage) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tKIND\tNAME\tRESOURCE\tUSED\tHARD\tUSAGE")
+	for _, u := range usages {
+		usage := fmt.Sprintf("%d%%", u.Percent)
+		if u.AboveThreshold {
+			usage += " *"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", u.Namespace, u.Kind, u.Name, u.Resource, u.Used, u.Hard, usage)
+	}
+	w.Flush()
+}
+
+// printAboveThreshold lists the projects with a usage above the threshold.
+func printAboveThreshold(out io.Writer, usages []resourceUsage, threshold int) {
+	namespaces := sets.NewString()
+	for _, u := range usages {
+		if u.AboveThreshold {
+			namespaces.Insert(u.Namespace)
+		}
+	}
+	if namespaces.Len() == 0 {
+		fmt.Fprintf(out, "\nNo project uses %d%% or more of a quota or limit range\n", threshold)
+		return
+	}
+	fmt.Fprintf(out, "\n* %d project(s) use %d%% or more of a quota or limit range: %s\n", namespaces.Len(), threshold, strings.Join(namespaces.List(), ", "))
+}
+
+// printCSV prints the usages as CSV with a header row.
+func printCSV(out io.Writer, usages []resourceUsage) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"namespace", "kind", "name", "resource", "used", "hard", "percent", "above_threshold"}); err != nil {
+		return err
+	}
+	for _, u := range usages {
+		if err := w.Write([]string{u.Namespace, u.Kind, u.Name, u.Resource, u.Used, u.Hard, strconv.Itoa(u.Percent), strconv.FormatBool(u.AboveThreshold)}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,117 @@
+package usage
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testNamespace(name, team string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"team": team}}}
+}
+
+func testQuota(namespace, name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func testPod(namespace, name string, phase corev1.PodPhase, limits ...corev1.ResourceList) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+	for _, limit := range limits {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Resources: corev1.ResourceRequirements{Limits: limit}})
+	}
+	return pod
+}
+
+func memory(value string) corev1.ResourceList {
+	return corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(value)}
+}
+
+func newTestClient() *fake.Clientset {
+	return fake.NewSimpleClientset(
+		testNamespace("payments", "a"),
+		testNamespace("orders", "a"),
+		testNamespace("search", "b"),
+		testQuota("payments", "compute", corev1.ResourceList{
+			corev1.ResourceLimitsCPU: resource.MustParse("10"),
+			corev1.ResourcePods:      resource.MustParse("20"),
+		}, corev1.ResourceList{
+			corev1.ResourceLimitsCPU: resource.MustParse("8500m"),
+			corev1.ResourcePods:      resource.MustParse("4"),
+		}),
+		testQuota("search", "compute", corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}),
+		&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "orders", Name: "limits"},
+			Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{
+				{Type: corev1.LimitTypeContainer, Max: memory("1Gi")},
+				{Type: corev1.LimitTypePod, Max: memory("2Gi")},
+				{Type: corev1.LimitTypePersistentVolumeClaim, Max: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")}},
+			}},
+		},
+		testPod("orders", "api", corev1.PodRunning, memory("512Mi"), memory("256Mi")),
+		testPod("orders", "worker", corev1.PodRunning, memory("900Mi")),
+		testPod("orders", "job", corev1.PodSucceeded, memory("1Gi"), memory("1Gi")),
+	)
+}
+
+func TestRun(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &UsageOptions{Threshold: 80, Client: newTestClient(), IOStreams: streams}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "NAMESPACE   KIND            NAME      RESOURCE           USED    HARD   USAGE\n" +
+		"orders      LimitRange      limits    Container/memory   900Mi   1Gi    87% *\n" +
+		"orders      LimitRange      limits    Pod/memory         900Mi   2Gi    43%\n" +
+		"payments    ResourceQuota   compute   limits.cpu         8500m   10     85% *\n" +
+		"payments    ResourceQuota   compute   pods               4       20     20%\n" +
+		"search      ResourceQuota   compute   pods               10      10     100% *\n" +
+		"\n" +
+		"* 3 project(s) use 80% or more of a quota or limit range: orders, payments, search\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRun_selectorCSV(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &UsageOptions{Selector: "team=a", Threshold: 90, Output: "csv", Client: newTestClient(), IOStreams: streams}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "namespace,kind,name,resource,used,hard,percent,above_threshold\n" +
+		"orders,LimitRange,limits,Container/memory,900Mi,1Gi,87,false\n" +
+		"orders,LimitRange,limits,Pod/memory,900Mi,2Gi,43,false\n" +
+		"payments,ResourceQuota,compute,limits.cpu,8500m,10,85,false\n" +
+		"payments,ResourceQuota,compute,pods,4,20,20,false\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		used, hard string
+		expected   int
+	}{
+		{used: "0", hard: "0", expected: 0},
+		{used: "1", hard: "0", expected: 100},
+		{used: "500m", hard: "2", expected: 25},
+		{used: "3Gi", hard: "2Gi", expected: 150},
+		{used: "1Ti", hard: "4Ti", expected: 25},
+	}
+	for _, test := range tests {
+		if got := percent(resource.MustParse(test.used), resource.MustParse(test.hard)); got != test.expected {
+			t.Errorf("percent(%s, %s) = %d, expected %d", test.used, test.hard, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package verifyimagesignature
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containers/image/v5/signature"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imageref "github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/workqueue"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+// imageToVerify is an image listed in --from-file.
+type imageToVerify struct {
+	// Name is the name of the image object, i.e. its digest.
+	Name             string
+	ExpectedIdentity string
+}
+
+// imageVerification is the result of the verification of an image.
+type imageVerification struct {
+	Image            string `json:"image"`
+	ExpectedIdentity string `json:"expectedIdentity"`
+	// Verified is true if at least one signature of the image is accepted.
+	Verified bool `json:"verified"`
+	// SignedBy are the short identifiers of the keys of the accepted signatures.
+	SignedBy []string `json:"signedBy,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// verificationReport is the result of the verification of the images listed in --from-file.
+type verificationReport struct {
+	Images   []*imageVerification `json:"images"`
+	Verified int                  `json:"verified"`
+	Failed   int                  `json:"failed"`
+}
+
+// readImages reads the images to verify from --from-file.
+func (o *VerifyImageSignatureOptions) readImages() ([]imageToVerify, error) {
+	if o.FromFile == "-" {
+		return parseImageList(o.In, o.ExpectedIdentity)
+	}
+	f, err := os.Open(o.FromFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read --from-file: %v", err)
+	}
+	defer f.Close()
+	return parseImageList(f, o.ExpectedIdentity)
+}
+
+// parseImageList parses a list of images, one per line, optionally followed by the expected identity
+// of the image. The lines without an expected identity use the default identity. Images can be given
+// by name or by a pull spec referencing them by digest.
+func parseImageList(r io.Reader, defaultIdentity string) ([]imageToVerify, error) {
+	var images []imageToVerify
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected an image optionally followed by its expected identity", lineNumber)
+		}
+
+		image := imageToVerify{Name: fields[0], ExpectedIdentity: defaultIdentity}
+		if strings.Contains(image.Name, "@") {
+			ref, err := imageref.Parse(image.Name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q is not a valid image reference: %v", lineNumber, image.Name, err)
+			}
+			image.Name = ref.ID
+		}
+		if len(fields) == 2 {
+			image.ExpectedIdentity = fields[1]
+		}
+		if len(image.ExpectedIdentity) == 0 {
+			return nil, fmt.Errorf("line %d: no expected identity for image %s and no --expected-identity given", lineNumber, image.Name)
+		}
+		if _, err := imageref.Parse(image.ExpectedIdentity); err != nil {
+			return nil, fmt.Errorf("line %d: the expected identity %q must be valid image reference", lineNumber, image.ExpectedIdentity)
+		}
+		images = append(images, image)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no image to verify")
+	}
+	return images, nil
+}
+
+// runBulk verifies the images listed in --from-file concurrently and prints the result.
+func (o *VerifyImageSignatureOptions) runBulk(policy *signature.Policy) error {
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	q := workqueue.New(o.ParallelOptions.MaxPerRegistry, stopCh)
+
+	report := &verificationReport{Images: make([]*imageVerification, len(o.images))}
+	q.Batch(func(w workqueue.Work) {
+		for i := range o.images {
+			i := i
+			w.Parallel(func() {
+				report.Images[i] = o.verifyImage(policy, o.images[i])
+			})
+		}
+	})
+	for _, result := range report.Images {
+		if result.Verified {
+			report.Verified++
+		} else {
+			report.Failed++
+		}
+	}
+
+	if err := printReport(o.Out, o.Output, report); err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d images failed signature verification", report.Failed, len(report.Images))
+	}
+	return nil
+}
+
+// verifyImage verifies the signatures of an image, the image is verified if at least one of its
+// signatures is accepted by the policy.
+func (o *VerifyImageSignatureOptions) verifyImage(policy *signature.Policy, image imageToVerify) *imageVerification {
+	result := &imageVerification{Image: image.Name, ExpectedIdentity: image.ExpectedIdentity}
+	img, err := o.ImageClient.Images().Get(context.TODO(), image.Name, metav1.GetOptions{})
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	if len(img.Signatures) == 0 {
+		result.Errors = append(result.Errors, "the image does not have any signature")
+		return result
+	}
+
+	// a policy context cannot be used concurrently, each image uses its own
+	pc, err := signature.NewPolicyContext(policy)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("unable to setup policy: %v", err))
+		return result
+	}
+	defer pc.Destroy()
+
+	for _, s := range img.Signatures {
+		signedBy, err := o.verifySignature(pc, img, image.ExpectedIdentity, s.Content)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("signature %s: %v", s.Name, err))
+			continue
+		}
+		result.SignedBy = append(result.SignedBy, signedBy)
+	}
+	result.Verified = len(result.SignedBy) > 0
+	return result
+}
+
+// printReport prints the report in the given output format, or as a table if none is given.
+func printReport(out io.Writer, output string, report *verificationReport) error {
+	if len(output) > 0 {
+		return cmdutil.PrintJSONOrYAML(out, output, report)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "IMAGE\tEXPECTED IDENTITY\tSTATUS\tDETAILS")
+	for _, result := range report.Images {
+		status, details := "Failed", strings.Join(result.Errors, "; ")
+		if result.Verified {
+			status, details = "Verified", fmt.Sprintf("signed by %s", strings.Join(result.SignedBy, ", "))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Image, result.ExpectedIdentity, status, details)
+	}
+	w.Flush()
+	fmt.Fprintf(out, "\n%d of %d images verified\n", report.Verified, len(report.Images))
+	return nil
+}
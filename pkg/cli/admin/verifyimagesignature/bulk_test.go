@@ -0,0 +1,150 @@
+package verifyimagesignature
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/containers/image/v5/signature"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagefake "github.com/openshift/client-go/image/clientset/versioned/fake"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+)
+
+const (
+	digest1 = "sha256:c841e9b64e4579bd56c794bdd7c36e1c257110fd2404bebbb8b613e4935228c4"
+	digest2 = "sha256:0000000000000000000000000000000000000000000000000000000000000001"
+)
+
+func TestParseImageList(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		defaultIdentity string
+		expected        []imageToVerify
+		expectedErr     string
+	}{
+		{
+			name: "images with identities",
+			input: `# images of the release
+` + digest1 + ` registry.local:5000/foo/bar:v1
+
+registry.local:5000/foo/baz@` + digest2 + ` registry.local:5000/foo/baz:v2
+`,
+			expected: []imageToVerify{
+				{Name: digest1, ExpectedIdentity: "registry.local:5000/foo/bar:v1"},
+				{Name: digest2, ExpectedIdentity: "registry.local:5000/foo/baz:v2"},
+			},
+		},
+		{
+			name:            "default identity",
+			input:           digest1 + "\n" + digest2 + " registry.local:5000/foo/baz:v2\n",
+			defaultIdentity: "registry.local:5000/foo/bar:v1",
+			expected: []imageToVerify{
+				{Name: digest1, ExpectedIdentity: "registry.local:5000/foo/bar:v1"},
+				{Name: digest2, ExpectedIdentity: "registry.local:5000/foo/baz:v2"},
+			},
+		},
+		{
+			name:        "missing identity",
+			input:       digest1 + " registry.local:5000/foo/bar:v1\n" + digest2 + "\n",
+			expectedErr: "line 2: no expected identity for image " + digest2,
+		},
+		{
+			name:        "invalid identity",
+			input:       digest1 + " Invalid:Identity\n",
+			expectedErr: `line 1: the expected identity "Invalid:Identity" must be valid image reference`,
+		},
+		{
+			name:        "too many fields",
+			input:       digest1 + " registry.local:5000/foo/bar:v1 extra\n",
+			expectedErr: "line 1: expected an image optionally followed by its expected identity",
+		},
+		{
+			name:        "no image",
+			input:       "# nothing to verify\n\n",
+			expectedErr: "no image to verify",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			images, err := parseImageList(strings.NewReader(test.input), test.defaultIdentity)
+			if len(test.expectedErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error containing %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(images, test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, images)
+			}
+		})
+	}
+}
+
+func TestRunBulk(t *testing.T) {
+	client := imagefake.NewSimpleClientset(&imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: digest1}})
+	out := &bytes.Buffer{}
+	o := &VerifyImageSignatureOptions{
+		Output:          "json",
+		ParallelOptions: imagemanifest.ParallelOptions{MaxPerRegistry: 2},
+		images: []imageToVerify{
+			{Name: digest1, ExpectedIdentity: "registry.local:5000/foo/bar:v1"},
+			{Name: digest2, ExpectedIdentity: "registry.local:5000/foo/baz:v2"},
+		},
+		ImageClient: client.ImageV1(),
+	}
+	o.Out = out
+
+	policy := &signature.Policy{Default: []signature.PolicyRequirement{signature.NewPRReject()}}
+	err := o.runBulk(policy)
+	if err == nil || err.Error() != "2 of 2 images failed signature verification" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report := &verificationReport{}
+	if err := json.Unmarshal(out.Bytes(), report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Verified != 0 || report.Failed != 2 || len(report.Images) != 2 {
+		t.Fatalf("unexpected report: %s", out.String())
+	}
+	if errs := report.Images[0].Errors; len(errs) != 1 || errs[0] != "the image does not have any signature" {
+		t.Errorf("unexpected errors for %s: %v", digest1, errs)
+	}
+	if errs := report.Images[1].Errors; len(errs) != 1 || !strings.Contains(errs[0], "not found") {
+		t.Errorf("unexpected errors for %s: %v", digest2, errs)
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	report := &verificationReport{
+		Images: []*imageVerification{
+			{Image: digest1, ExpectedIdentity: "registry.local:5000/foo/bar:v1", Verified: true, SignedBy: []string{"A1B2C3"}},
+			{Image: digest2, ExpectedIdentity: "registry.local:5000/foo/baz:v2", Errors: []string{"signature a: signature rejected", "signature b: signature rejected"}},
+		},
+		Verified: 1,
+		Failed:   1,
+	}
+	out := &bytes.Buffer{}
+	if err := printReport(out, "", report); err != nil {
+		t.Fatal(err)
+	}
+	expected := `IMAGE                                                                     EXPECTED IDENTITY                STATUS     DETAILS
+` + digest1 + `   registry.local:5000/foo/bar:v1   Verified   signed by A1B2C3
+` + digest2 + `   registry.local:5000/foo/baz:v2   Failed     signature a: signature rejected; signature b: signature rejected
+
+1 of 2 images verified
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	imagev1typedclient "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
 	userv1typedclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
 	imageref "github.com/openshift/library-go/pkg/image/reference"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
 )
 
 var (
@@ -49,6 +50,16 @@ var (
 	with the public URL of image registry.
 
 	To remove all verifications, users can use the "--remove-all" flag.
+
+	To verify several images at once, list them in a file passed with "--from-file", one image per
+	line, optionally followed by the expected identity of the image; the "--expected-identity" flag is
+	used for the lines without one. Images can be given by name or by a pull spec referencing them by
+	digest. Empty lines and lines starting with "#" are ignored. The images are verified concurrently,
+	the result is not saved and the command fails if any image cannot be verified. Use "-o json" to get
+	a report of the verification, e.g. to check the images before they are admitted from a CI job.
+
+	The "--policy" flag replaces the public GPG key with a containers signature policy file, see
+	containers-policy.json(5), for example to trust several keys or different keys per repository.
 	`)
 
 	verifyImageSignatureExample = templates.Examples(`
@@ -67,6 +78,9 @@ var (
 
 	# Remove all signature verifications from the image
 	oc adm verify-image-signature sha256:c841e9b64e4579bd56c794bdd7c36e1c257110fd2404bebbb8b613e4935228c4 --remove-all
+
+	# Verify the images listed in a file against a signature policy and print a JSON report
+	oc adm verify-image-signature --from-file=images.txt --policy=policy.json -o json
 	`)
 )
 
@@ -81,6 +95,14 @@ type VerifyImageSignatureOptions struct {
 	CurrentUserToken  string
 	RegistryURL       string
 	Insecure          bool
+	PolicyFilename    string
+	FromFile          string
+	Output            string
+
+	ParallelOptions imagemanifest.ParallelOptions
+
+	// images are the images to verify read from FromFile.
+	images []imageToVerify
 
 	ImageClient imagev1typedclient.ImageV1Interface
 
@@ -93,6 +115,7 @@ func NewVerifyImageSignatureOptions(streams genericclioptions.IOStreams) *Verify
 		// to locate the pubring.gpg file (which is default).
 		// This should be improved/fixed in containers/image.
 		PublicKeyFilename: filepath.Join(os.Getenv("GNUPGHOME"), "pubring.gpg"),
+		ParallelOptions:   imagemanifest.ParallelOptions{MaxPerRegistry: 4},
 		IOStreams:         streams,
 	}
 }
@@ -100,7 +123,7 @@ func NewVerifyImageSignatureOptions(streams genericclioptions.IOStreams) *Verify
 func NewCmdVerifyImageSignature(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
 	o := NewVerifyImageSignatureOptions(streams)
 	cmd := &cobra.Command{
-		Use:     "verify-image-signature IMAGE --expected-identity=EXPECTED_IDENTITY [--save]",
+		Use:     "verify-image-signature (IMAGE --expected-identity=EXPECTED_IDENTITY [--save] | --from-file=FILE)",
 		Short:   "Verify the image identity contained in the image signature",
 		Long:    verifyImageSignatureLongDesc,
 		Example: verifyImageSignatureExample,
@@ -117,10 +140,33 @@ func NewCmdVerifyImageSignature(f kcmdutil.Factory, streams genericclioptions.IO
 	cmd.Flags().StringVar(&o.PublicKeyFilename, "public-key", o.PublicKeyFilename, fmt.Sprintf("A path to a public GPG key to be used for verification. (defaults to %q)", o.PublicKeyFilename))
 	cmd.Flags().StringVar(&o.RegistryURL, "registry-url", o.RegistryURL, "The address to use when contacting the registry, instead of using the internal cluster address. This is useful if you can't resolve or reach the internal registry address.")
 	cmd.Flags().BoolVar(&o.Insecure, "insecure", o.Insecure, "If set, use the insecure protocol for registry communication.")
+	cmd.Flags().StringVar(&o.PolicyFilename, "policy", o.PolicyFilename, "A path to a signature policy file to verify the signatures against, instead of --public-key.")
+	cmd.Flags().StringVar(&o.FromFile, "from-file", o.FromFile, "A file listing the images to verify, one per line with an optional expected identity. Use - to read from standard input.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format of the verification of --from-file. One of: json|yaml.")
+	o.ParallelOptions.Bind(cmd.Flags())
 	return cmd
 }
 
 func (o *VerifyImageSignatureOptions) Validate() error {
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return errors.New("the --output must be one of: json, yaml")
+	}
+	if len(o.FromFile) > 0 {
+		if o.Save || o.RemoveAll {
+			return errors.New("the --save and --remove-all cannot be used with --from-file")
+		}
+		if len(o.ExpectedIdentity) > 0 {
+			if _, err := imageref.Parse(o.ExpectedIdentity); err != nil {
+				return errors.New("the --expected-identity must be valid image reference")
+			}
+		}
+		return nil
+	}
+	if len(o.Output) > 0 {
+		return errors.New("the --output can only be used with --from-file")
+	}
 	if !o.RemoveAll {
 		if len(o.ExpectedIdentity) == 0 {
 			return errors.New("the --expected-identity is required")
@@ -135,13 +181,22 @@ func (o *VerifyImageSignatureOptions) Validate() error {
 	return nil
 }
 func (o *VerifyImageSignatureOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
-	if len(args) != 1 {
-		return kcmdutil.UsageErrorf(cmd, "exactly one image must be specified")
-	}
-	o.InputImage = args[0]
 	var err error
+	if len(o.FromFile) > 0 {
+		if len(args) > 0 {
+			return kcmdutil.UsageErrorf(cmd, "no image can be specified with --from-file")
+		}
+		if o.images, err = o.readImages(); err != nil {
+			return err
+		}
+	} else {
+		if len(args) != 1 {
+			return kcmdutil.UsageErrorf(cmd, "exactly one image must be specified")
+		}
+		o.InputImage = args[0]
+	}
 
-	if len(o.PublicKeyFilename) > 0 {
+	if len(o.PolicyFilename) == 0 && len(o.PublicKeyFilename) > 0 {
 		if o.PublicKey, err = ioutil.ReadFile(o.PublicKeyFilename); err != nil {
 			return fmt.Errorf("unable to read --public-key: %v", err)
 		}
@@ -181,6 +236,14 @@ func (o *VerifyImageSignatureOptions) Complete(f kcmdutil.Factory, cmd *cobra.Co
 }
 
 func (o VerifyImageSignatureOptions) Run() error {
+	policy, err := o.policy()
+	if err != nil {
+		return err
+	}
+	if len(o.FromFile) > 0 {
+		return o.runBulk(policy)
+	}
+
 	img, err := o.ImageClient.Images().Get(context.TODO(), o.InputImage, metav1.GetOptions{})
 	if err != nil {
 		return err
@@ -189,12 +252,7 @@ func (o VerifyImageSignatureOptions) Run() error {
 		return fmt.Errorf("%s does not have any signature", img.Name)
 	}
 
-	pr, err := signature.NewPRSignedByKeyPath(signature.SBKeyTypeGPGKeys, o.PublicKeyFilename, signature.NewPRMMatchRepoDigestOrExact())
-	if err != nil {
-		return fmt.Errorf("unable to prepare verification policy requirements: %v", err)
-	}
-	policy := signature.Policy{Default: []signature.PolicyRequirement{pr}}
-	pc, err := signature.NewPolicyContext(&policy)
+	pc, err := signature.NewPolicyContext(policy)
 	if err != nil {
 		return fmt.Errorf("unable to setup policy: %v", err)
 	}
@@ -206,7 +264,7 @@ func (o VerifyImageSignatureOptions) Run() error {
 
 	for i, s := range img.Signatures {
 		// Verify the signature against the policy
-		signedBy, err := o.verifySignature(pc, img, s.Content)
+		signedBy, err := o.verifySignature(pc, img, o.ExpectedIdentity, s.Content)
 		if err != nil {
 			fmt.Fprintf(o.ErrOut, "error verifying signature %s for image %s (verification status will be removed): %v\n", img.Signatures[i].Name, o.InputImage, err)
 			img.Signatures[i] = imagev1.ImageSignature{}
@@ -247,6 +305,23 @@ func (o VerifyImageSignatureOptions) Run() error {
 	return nil
 }
 
+// policy returns the policy the signatures are verified against: the policy file if given,
+// otherwise a policy accepting the signatures made by the public key.
+func (o *VerifyImageSignatureOptions) policy() (*signature.Policy, error) {
+	if len(o.PolicyFilename) > 0 {
+		policy, err := signature.NewPolicyFromFile(o.PolicyFilename)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read --policy: %v", err)
+		}
+		return policy, nil
+	}
+	pr, err := signature.NewPRSignedByKeyPath(signature.SBKeyTypeGPGKeys, o.PublicKeyFilename, signature.NewPRMMatchRepoDigestOrExact())
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare verification policy requirements: %v", err)
+	}
+	return &signature.Policy{Default: []signature.PolicyRequirement{pr}}, nil
+}
+
 // getImageManifest fetches the manifest for provided image from the integrated registry.
 func (o *VerifyImageSignatureOptions) getImageManifest(img *imagev1.Image) ([]byte, error) {
 	parsed, err := imageref.Parse(img.DockerImageReference)
@@ -271,12 +346,12 @@ func (o *VerifyImageSignatureOptions) getImageManifest(img *imagev1.Image) ([]by
 // signature message and the manifest matches as well.
 // In case the image identity is confirmed, this function returns the matching GPG key in
 // short form, otherwise it returns rejection reason.
-func (o *VerifyImageSignatureOptions) verifySignature(pc *signature.PolicyContext, img *imagev1.Image, sigBlob []byte) (string, error) {
+func (o *VerifyImageSignatureOptions) verifySignature(pc *signature.PolicyContext, img *imagev1.Image, expectedIdentity string, sigBlob []byte) (string, error) {
 	manifest, err := o.getImageManifest(img)
 	if err != nil {
 		return "", fmt.Errorf("failed to get image %q manifest: %v", img.Name, err)
 	}
-	allowed, err := pc.IsRunningImageAllowed(context.TODO(), newUnparsedImage(o.ExpectedIdentity, sigBlob, manifest))
+	allowed, err := pc.IsRunningImageAllowed(context.TODO(), newUnparsedImage(expectedIdentity, sigBlob, manifest))
 	if !allowed && err == nil {
 		return "", errors.New("signature rejected but no error set")
 	}
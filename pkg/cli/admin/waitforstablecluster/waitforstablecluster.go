@@ -0,0 +1,400 @@
+package waitforstablecluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	waitLong = templates.LongDesc(`
+		Wait for the cluster to be stable.
+
+		The cluster is stable when every cluster operator is Available, not Progressing and not
+		Degraded, every node is Ready, and every machine config pool is Updated, not Updating and
+		not Degraded. This command returns once the cluster has been stable for at least
+		--minimum-stable-period, and fails if it did not become stable within --timeout, which makes
+		it a convenient gate after an upgrade or a configuration change.
+
+		The resources that are not stable yet are reported whenever they change. With --output, a
+		summary of the wait is printed when it ends, listing the resources that were not stable and
+		their conditions, and the progress is reported on the standard error instead.
+	`)
+
+	waitExample = templates.Examples(`
+		# Wait up to an hour for the cluster to be stable for five minutes
+		oc adm wait-for-stable-cluster
+
+		# Wait up to 2 hours for the cluster to be stable for ten minutes, printing a JSON summary
+		oc adm wait-for-stable-cluster --minimum-stable-period=10m --timeout=2h -o json
+	`)
+)
+
+var machineConfigPoolsResource = schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigpools"}
+
+type WaitForStableClusterOptions struct {
+	MinimumStablePeriod time.Duration
+	Timeout             time.Duration
+	Interval            time.Duration
+	Output              string
+
+	ConfigClient  configv1client.Interface
+	KubeClient    kubernetes.Interface
+	DynamicClient dynamic.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewWaitForStableClusterOptions(streams genericclioptions.IOStreams) *WaitForStableClusterOptions {
+	return &WaitForStableClusterOptions{
+		MinimumStablePeriod: 5 * time.Minute,
+		Timeout:             time.Hour,
+		Interval:            10 * time.Second,
+		IOStreams:           streams,
+	}
+}
+
+// NewCmdWaitForStableCluster creates a command that waits for the cluster operators, nodes and machine config pools
+// to be stable.
+func NewCmdWaitForStableCluster(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewWaitForStableClusterOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "wait-for-stable-cluster",
+		Short:   "Wait for the cluster operators, nodes and machine config pools to be stable",
+		Long:    waitLong,
+		Example: waitExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run(context.Background()))
+		},
+	}
+
+	cmd.Flags().DurationVar(&o.MinimumStablePeriod, "minimum-stable-period", o.MinimumStablePeriod, "The time the cluster must remain stable before the command returns.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The maximum time to wait for the cluster to be stable.")
+	cmd.Flags().DurationVar(&o.Interval, "interval", o.Interval, "How often the state of the cluster is checked.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json|yaml. Prints a summary of the wait when it ends.")
+
+	return cmd
+}
+
+func (o *WaitForStableClusterOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed to this command")
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.ConfigClient, err = configv1client.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(config); err != nil {
+		return err
+	}
+	if o.DynamicClient, err = dynamic.NewForConfig(config); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *WaitForStableClusterOptions) Validate() error {
+	if o.MinimumStablePeriod < 0 {
+		return fmt.Errorf("--minimum-stable-period must be greater than or equal to 0")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be greater than 0")
+	}
+	if o.Interval <= 0 {
+		return fmt.Errorf("--interval must be greater than 0")
+	}
+	if o.MinimumStablePeriod >= o.Timeout {
+		return fmt.Errorf("--minimum-stable-period must be shorter than --timeout")
+	}
+	switch o.Output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("--output must be one of json or yaml")
+	}
+	return nil
+}
+
+// waitSummary is the machine readable summary of a wait.
+type waitSummary struct {
+	Stable bool `json:"stable"`
+	// Waited is how long the command waited, like 12m30s.
+	Waited string `json:"waited"`
+	// StableFor is how long the cluster has been stable when the wait ended, like 5m0s.
+	StableFor string `json:"stableFor,omitempty"`
+	// Unstable are the resources that were not stable when the wait ended.
+	Unstable []unstableResource `json:"unstable"`
+	// Error is the last error that prevented the state of the cluster from being checked.
+	Error string `json:"error,omitempty"`
+}
+
+// unstableResource is a resource that is not stable, with the reasons why.
+type unstableResource struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Reasons []string `json:"reasons"`
+}
+
+func (r unstableResource) String() string {
+	return fmt.Sprintf("%s/%s (%s)", r.Kind, r.Name, strings.Join(r.Reasons, ", "))
+}
+
+func (o *WaitForStableClusterOptions) Run(ctx context.Context) error {
+	progress := o.Out
+	if len(o.Output) > 0 {
+		progress = o.ErrOut
+	}
+
+	start := time.Now()
+	var stableSince time.Time
+	var unstable []unstableResource
+	var lastErr error
+	var lastReport string
+	err := wait.PollImmediate(o.Interval, o.Timeout, func() (bool, error) {
+		var err error
+		unstable, err = o.unstableResources(ctx)
+		if err != nil {
+			// the API may not be available while the cluster is changing, keep waiting
+			klog.V(2).Infof("Unable to check the state of the cluster: %v", err)
+			if lastErr == nil || err.Error() != lastErr.Error() {
+				fmt.Fprintf(progress, "Unable to check the state of the cluster: %v\n", err)
+			}
+			lastErr = err
+			stableSince = time.Time{}
+			return false, nil
+		}
+		lastErr = nil
+
+		if len(unstable) > 0 {
+			stableSince = time.Time{}
+			if report := describeUnstable(unstable); report != lastReport {
+				fmt.Fprintf(progress, "%s Waiting for %d resources to be stable:\n%s", time.Now().Format(time.RFC3339), len(unstable), report)
+				lastReport = report
+			}
+			return false, nil
+		}
+
+		if stableSince.IsZero() {
+			stableSince = time.Now()
+			lastReport = ""
+			fmt.Fprintf(progress, "%s The cluster is stable, waiting for it to remain stable for %s\n", time.Now().Format(time.RFC3339), o.MinimumStablePeriod)
+		}
+		return time.Since(stableSince) >= o.MinimumStablePeriod, nil
+	})
+
+	summary := waitSummary{
+		Stable:   err == nil,
+		Waited:   time.Since(start).Round(time.Second).String(),
+		Unstable: unstable,
+	}
+	if !stableSince.IsZero() {
+		summary.StableFor = time.Since(stableSince).Round(time.Second).String()
+	}
+	if summary.Unstable == nil {
+		summary.Unstable = []unstableResource{}
+	}
+	if lastErr != nil {
+		summary.Error = lastErr.Error()
+	}
+	if len(o.Output) > 0 {
+		if err := printSummary(o.Out, summary, o.Output); err != nil {
+			return err
+		}
+	}
+
+	if err == wait.ErrWaitTimeout {
+		if !stableSince.IsZero() {
+			return fmt.Errorf("the cluster was only stable for %s after waiting %s", summary.StableFor, o.Timeout)
+		}
+		return fmt.Errorf("the cluster did not become stable within %s", o.Timeout)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(progress, "The cluster is stable after %s\n", summary.Waited)
+	return nil
+}
+
+// unstableResources returns the cluster operators, nodes and machine config pools that are not stable.
+func (o *WaitForStableClusterOptions) unstableResources(ctx context.Context) ([]unstableResource, error) {
+	operators, err := o.ConfigClient.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list cluster operators: %v", err)
+	}
+	nodes, err := o.KubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %v", err)
+	}
+	pools, err := o.DynamicClient.Resource(machineConfigPoolsResource).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		// clusters without the machine config operator have no pools
+		pools = &unstructured.UnstructuredList{}
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to list machine config pools: %v", err)
+	}
+
+	var unstable []unstableResource
+	unstable = append(unstable, unstableClusterOperators(operators.Items)...)
+	unstable = append(unstable, unstableNodes(nodes.Items)...)
+	unstable = append(unstable, unstableMachineConfigPools(pools.Items)...)
+	return unstable, nil
+}
+
+// unstableClusterOperators returns the operators that are not Available, Progressing or Degraded.
+func unstableClusterOperators(operators []configv1.ClusterOperator) []unstableResource {
+	var unstable []unstableResource
+	for _, operator := range operators {
+		var reasons []string
+		for _, expected := range []struct {
+			condition configv1.ClusterStatusConditionType
+			status    configv1.ConditionStatus
+		}{
+			{condition: configv1.OperatorAvailable, status: configv1.ConditionTrue},
+			{condition: configv1.OperatorProgressing, status: configv1.ConditionFalse},
+			{condition: configv1.OperatorDegraded, status: configv1.ConditionFalse},
+		} {
+			condition := findClusterOperatorCondition(operator.Status.Conditions, expected.condition)
+			switch {
+			case condition == nil && expected.condition == configv1.OperatorAvailable:
+				reasons = append(reasons, fmt.Sprintf("%s is unknown", expected.condition))
+			case condition == nil:
+				// operators may omit Progressing and Degraded when they are false
+			case condition.Status != expected.status:
+				reasons = append(reasons, describeCondition(string(condition.Type), string(condition.Status), condition.Reason, condition.Message))
+			}
+		}
+		if len(reasons) > 0 {
+			unstable = append(unstable, unstableResource{Kind: "clusteroperator", Name: operator.Name, Reasons: reasons})
+		}
+	}
+	return sortUnstable(unstable)
+}
+
+// unstableNodes returns the nodes that are not Ready.
+func unstableNodes(nodes []corev1.Node) []unstableResource {
+	var unstable []unstableResource
+	for _, node := range nodes {
+		ready := false
+		reason := fmt.Sprintf("%s is unknown", corev1.NodeReady)
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady {
+				ready = condition.Status == corev1.ConditionTrue
+				reason = describeCondition(string(condition.Type), string(condition.Status), condition.Reason, condition.Message)
+				break
+			}
+		}
+		if !ready {
+			unstable = append(unstable, unstableResource{Kind: "node", Name: node.Name, Reasons: []string{reason}})
+		}
+	}
+	return sortUnstable(unstable)
+}
+
+// unstableMachineConfigPools returns the pools that are not Updated, Updating or Degraded.
+func unstableMachineConfigPools(pools []unstructured.Unstructured) []unstableResource {
+	var unstable []unstableResource
+	for _, pool := range pools {
+		conditions, _, _ := unstructured.NestedSlice(pool.Object, "status", "conditions")
+		statuses := map[string]map[string]interface{}{}
+		for _, c := range conditions {
+			if condition, ok := c.(map[string]interface{}); ok {
+				if conditionType, ok := condition["type"].(string); ok {
+					statuses[conditionType] = condition
+				}
+			}
+		}
+		var reasons []string
+		for _, expected := range []struct {
+			condition string
+			status    string
+		}{
+			{condition: "Updated", status: "True"},
+			{condition: "Updating", status: "False"},
+			{condition: "Degraded", status: "False"},
+		} {
+			condition, ok := statuses[expected.condition]
+			if !ok {
+				if expected.condition == "Updated" {
+					reasons = append(reasons, "Updated is unknown")
+				}
+				continue
+			}
+			status, _ := condition["status"].(string)
+			if status != expected.status {
+				reason, _ := condition["reason"].(string)
+				message, _ := condition["message"].(string)
+				reasons = append(reasons, describeCondition(expected.condition, status, reason, message))
+			}
+		}
+		if len(reasons) > 0 {
+			unstable = append(unstable, unstableResource{Kind: "machineconfigpool", Name: pool.GetName(), Reasons: reasons})
+		}
+	}
+	return sortUnstable(unstable)
+}
+
+func findClusterOperatorCondition(conditions []configv1.ClusterOperatorStatusCondition, name configv1.ClusterStatusConditionType) *configv1.ClusterOperatorStatusCondition {
+	for i := range conditions {
+		if conditions[i].Type == name {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// describeCondition describes a condition as Type=Status, followed by its reason and message if any.
+func describeCondition(conditionType, status, reason, message string) string {
+	description := fmt.Sprintf("%s=%s", conditionType, status)
+	if len(reason) > 0 {
+		description += " " + reason
+	}
+	if len(message) > 0 {
+		description += ": " + message
+	}
+	return description
+}
+
+func sortUnstable(unstable []unstableResource) []unstableResource {
+	sort.Slice(unstable, func(i, j int) bool { return unstable[i].Name < unstable[j].Name })
+	return unstable
+}
+
+// describeUnstable lists the unstable resources, one per line.
+func describeUnstable(unstable []unstableResource) string {
+	var b strings.Builder
+	for _, r := range unstable {
+		fmt.Fprintf(&b, "  %s\n", r)
+	}
+	return b.String()
+}
+
+// printSummary writes the summary in the output format, json or yaml.
+func printSummary(out io.Writer, summary waitSummary, format string) error {
+	return cmdutil.PrintJSONOrYAML(out, format, summary)
+}
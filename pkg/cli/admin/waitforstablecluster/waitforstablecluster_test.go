@@ -0,0 +1,115 @@
+package waitforstablecluster
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+func clusterOperator(name string, available, progressing, degraded configv1.ConditionStatus) configv1.ClusterOperator {
+	operator := configv1.ClusterOperator{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	for conditionType, status := range map[configv1.ClusterStatusConditionType]configv1.ConditionStatus{
+		configv1.OperatorAvailable:   available,
+		configv1.OperatorProgressing: progressing,
+		configv1.OperatorDegraded:    degraded,
+	} {
+		if len(status) > 0 {
+			operator.Status.Conditions = append(operator.Status.Conditions, configv1.ClusterOperatorStatusCondition{Type: conditionType, Status: status})
+		}
+	}
+	return operator
+}
+
+func TestUnstableClusterOperators(t *testing.T) {
+	operators := []configv1.ClusterOperator{
+		clusterOperator("stable", configv1.ConditionTrue, configv1.ConditionFalse, configv1.ConditionFalse),
+		clusterOperator("quiet", configv1.ConditionTrue, "", ""),
+		clusterOperator("unknown", "", "", ""),
+		clusterOperator("progressing", configv1.ConditionTrue, configv1.ConditionTrue, configv1.ConditionFalse),
+	}
+	operators[3].Status.Conditions[1].Reason = "Rolling"
+	operators[3].Status.Conditions[1].Message = "2 of 3 updated"
+	expected := []unstableResource{
+		{Kind: "clusteroperator", Name: "progressing", Reasons: []string{"Progressing=True Rolling: 2 of 3 updated"}},
+		{Kind: "clusteroperator", Name: "unknown", Reasons: []string{"Available is unknown"}},
+	}
+	if got := unstableClusterOperators(operators); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestUnstableNodes(t *testing.T) {
+	nodes := []corev1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "ready"}, Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "not-ready"}, Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Reason: "KubeletNotReady"}}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "new"}},
+	}
+	expected := []unstableResource{
+		{Kind: "node", Name: "new", Reasons: []string{"Ready is unknown"}},
+		{Kind: "node", Name: "not-ready", Reasons: []string{"Ready=False KubeletNotReady"}},
+	}
+	if got := unstableNodes(nodes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func machineConfigPool(name string, conditions ...map[string]interface{}) unstructured.Unstructured {
+	var c []interface{}
+	for _, condition := range conditions {
+		c = append(c, condition)
+	}
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name},
+		"status":   map[string]interface{}{"conditions": c},
+	}}
+}
+
+func TestUnstableMachineConfigPools(t *testing.T) {
+	pools := []unstructured.Unstructured{
+		machineConfigPool("master",
+			map[string]interface{}{"type": "Updated", "status": "True"},
+			map[string]interface{}{"type": "Updating", "status": "False"},
+			map[string]interface{}{"type": "Degraded", "status": "False"},
+		),
+		machineConfigPool("worker",
+			map[string]interface{}{"type": "Updated", "status": "False"},
+			map[string]interface{}{"type": "Updating", "status": "True", "message": "All nodes are updating to rendered-worker-1"},
+		),
+		machineConfigPool("infra"),
+	}
+	expected := []unstableResource{
+		{Kind: "machineconfigpool", Name: "infra", Reasons: []string{"Updated is unknown"}},
+		{Kind: "machineconfigpool", Name: "worker", Reasons: []string{"Updated=False", "Updating=True: All nodes are updating to rendered-worker-1"}},
+	}
+	if got := unstableMachineConfigPools(pools); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	summary := waitSummary{
+		Waited:   "1h0m0s",
+		Unstable: []unstableResource{{Kind: "node", Name: "worker-0", Reasons: []string{"Ready=False"}}},
+	}
+	out := &bytes.Buffer{}
+	if err := printSummary(out, summary, "yaml"); err != nil {
+		t.Fatal(err)
+	}
+	expected := `stable: false
+unstable:
+- kind: node
+  name: worker-0
+  reasons:
+  - Ready=False
+waited: 1h0m0s
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
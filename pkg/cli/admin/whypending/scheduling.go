@@ -0,0 +1,403 @@
+package whypending
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+)
+
+// unschedulableTaint is the taint of cordoned nodes, which is reported as such.
+const unschedulableTaint = "node.kubernetes.io/unschedulable"
+
+// cluster is the state of the cluster against which a pod is evaluated.
+type cluster struct {
+	nodes []corev1.Node
+	// pods are the pods that are not terminated
+	pods       []corev1.Pod
+	namespaces []corev1.Namespace
+	// volumeNodeAffinities are the node affinities of the persistent volumes of the pod, by name of the volume
+	volumeNodeAffinities map[string]*corev1.NodeSelector
+}
+
+// nodeReasons returns the reasons the pod cannot be scheduled on each node, by name of the node. The nodes without
+// reason are eligible.
+func nodeReasons(pod *corev1.Pod, c *cluster) map[string][]string {
+	reasons := map[string][]string{}
+	requests, _ := resourcehelper.PodRequestsAndLimits(pod)
+	volumes := make([]string, 0, len(c.volumeNodeAffinities))
+	for volume := range c.volumeNodeAffinities {
+		volumes = append(volumes, volume)
+	}
+	sort.Strings(volumes)
+	for i := range c.nodes {
+		node := &c.nodes[i]
+		var r []string
+		if node.Spec.Unschedulable && !toleratesTaint(pod, &corev1.Taint{Key: unschedulableTaint, Effect: corev1.TaintEffectNoSchedule}) {
+			r = append(r, "node is cordoned")
+		}
+		r = append(r, taintReasons(pod, node)...)
+		r = append(r, nodeSelectorReasons(pod, node)...)
+		r = append(r, resourceReasons(requests, node, c.podsOn(node.Name))...)
+		for _, volume := range volumes {
+			if !nodeSelectorMatches(c.volumeNodeAffinities[volume], node) {
+				r = append(r, fmt.Sprintf("persistent volume %s is not accessible from node", volume))
+			}
+		}
+		reasons[node.Name] = r
+	}
+	for name, r := range topologySpreadReasons(pod, c) {
+		reasons[name] = append(reasons[name], r...)
+	}
+	for name, r := range podAffinityReasons(pod, c) {
+		reasons[name] = append(reasons[name], r...)
+	}
+	return reasons
+}
+
+// podsOn returns the pods bound to the node.
+func (c *cluster) podsOn(nodeName string) []*corev1.Pod {
+	var pods []*corev1.Pod
+	for i := range c.pods {
+		if c.pods[i].Spec.NodeName == nodeName {
+			pods = append(pods, &c.pods[i])
+		}
+	}
+	return pods
+}
+
+// toleratesTaint returns true if a toleration of the pod tolerates the taint.
+func toleratesTaint(pod *corev1.Pod, taint *corev1.Taint) bool {
+	for i := range pod.Spec.Tolerations {
+		if pod.Spec.Tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// taintReasons reports the NoSchedule and NoExecute taints of the node that the pod does not tolerate.
+func taintReasons(pod *corev1.Pod, node *corev1.Node) []string {
+	var reasons []string
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == corev1.TaintEffectPreferNoSchedule || taint.Key == unschedulableTaint {
+			continue
+		}
+		if !toleratesTaint(pod, taint) {
+			reasons = append(reasons, fmt.Sprintf("untolerated taint %s", taint.ToString()))
+		}
+	}
+	return reasons
+}
+
+// nodeSelectorReasons reports the node selector labels and the required node affinity that the node does not
+// match.
+func nodeSelectorReasons(pod *corev1.Pod, node *corev1.Node) []string {
+	var reasons []string
+	keys := make([]string, 0, len(pod.Spec.NodeSelector))
+	for key := range pod.Spec.NodeSelector {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value, ok := node.Labels[key]; !ok || value != pod.Spec.NodeSelector[key] {
+			reasons = append(reasons, fmt.Sprintf("node selector %s=%s does not match", key, pod.Spec.NodeSelector[key]))
+		}
+	}
+	if affinity := requiredNodeAffinity(pod); affinity != nil && !nodeSelectorMatches(affinity, node) {
+		reasons = append(reasons, "required node affinity does not match")
+	}
+	return reasons
+}
+
+// requiredNodeAffinity returns the node selector of the required node affinity of the pod, if any.
+func requiredNodeAffinity(pod *corev1.Pod) *corev1.NodeSelector {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil {
+		return nil
+	}
+	return pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+}
+
+// matchesNodeSelection returns true if the node matches the node selector and the required node affinity of the pod.
+func matchesNodeSelection(pod *corev1.Pod, node *corev1.Node) bool {
+	return len(nodeSelectorReasons(pod, node)) == 0
+}
+
+// nodeSelectorMatches returns true if the node matches any term of the node selector. An empty term matches no node.
+func nodeSelectorMatches(nodeSelector *corev1.NodeSelector, node *corev1.Node) bool {
+	for _, term := range nodeSelector.NodeSelectorTerms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		if requirementsMatch(term.MatchExpressions, labels.Set(node.Labels)) && requirementsMatch(term.MatchFields, labels.Set{"metadata.name": node.Name}) {
+			return true
+		}
+	}
+	return false
+}
+
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// requirementsMatch returns true if the set matches all the requirements. Invalid requirements match nothing.
+func requirementsMatch(requirements []corev1.NodeSelectorRequirement, set labels.Set) bool {
+	for _, requirement := range requirements {
+		operator, ok := nodeSelectorOperators[requirement.Operator]
+		if !ok {
+			return false
+		}
+		r, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil || !r.Matches(set) {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceReasons reports the resources requested by the pod that are not available on the node, given the requests
+// of the pods bound to it.
+func resourceReasons(requests corev1.ResourceList, node *corev1.Node, pods []*corev1.Pod) []string {
+	var reasons []string
+	if allocatable, ok := node.Status.Allocatable[corev1.ResourcePods]; ok && int64(len(pods)) >= allocatable.Value() {
+		reasons = append(reasons, fmt.Sprintf("too many pods: %d of %d", len(pods), allocatable.Value()))
+	}
+
+	used := corev1.ResourceList{}
+	for _, pod := range pods {
+		podRequests, _ := resourcehelper.PodRequestsAndLimits(pod)
+		for name, quantity := range podRequests {
+			total := used[name]
+			total.Add(quantity)
+			used[name] = total
+		}
+	}
+
+	names := make([]string, 0, len(requests))
+	for name := range requests {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		name := corev1.ResourceName(n)
+		requested := requests[name]
+		if requested.IsZero() {
+			continue
+		}
+		allocatable, ok := node.Status.Allocatable[name]
+		if !ok {
+			reasons = append(reasons, fmt.Sprintf("no %s allocatable", name))
+			continue
+		}
+		available := allocatable.DeepCopy()
+		available.Sub(used[name])
+		if available.Cmp(requested) < 0 {
+			if available.Sign() < 0 {
+				available.Set(0)
+			}
+			reasons = append(reasons, fmt.Sprintf("insufficient %s: requests %s, %s of %s available", name, requested.String(), available.String(), allocatable.String()))
+		}
+	}
+	return reasons
+}
+
+// topologySpreadReasons reports the nodes on which the pod would violate a DoNotSchedule topology spread constraint.
+// The domains are those of the nodes that match the node selection of the pod.
+func topologySpreadReasons(pod *corev1.Pod, c *cluster) map[string][]string {
+	reasons := map[string][]string{}
+	for _, constraint := range pod.Spec.TopologySpreadConstraints {
+		if constraint.WhenUnsatisfiable != corev1.DoNotSchedule {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+		if err != nil {
+			selector = labels.Nothing()
+		}
+
+		counts := map[string]int{}
+		domainOf := map[string]string{}
+		for i := range c.nodes {
+			node := &c.nodes[i]
+			value, ok := node.Labels[constraint.TopologyKey]
+			if !ok || !matchesNodeSelection(pod, node) {
+				continue
+			}
+			counts[value] += 0
+			domainOf[node.Name] = value
+		}
+		for i := range c.pods {
+			other := &c.pods[i]
+			if other.Namespace != pod.Namespace || other.UID == pod.UID || !selector.Matches(labels.Set(other.Labels)) {
+				continue
+			}
+			if domain, ok := domainOf[other.Spec.NodeName]; ok {
+				counts[domain]++
+			}
+		}
+		minCount := -1
+		for _, count := range counts {
+			if minCount < 0 || count < minCount {
+				minCount = count
+			}
+		}
+		if constraint.MinDomains != nil && int32(len(counts)) < *constraint.MinDomains {
+			minCount = 0
+		}
+		self := 0
+		if selector.Matches(labels.Set(pod.Labels)) {
+			self = 1
+		}
+
+		for i := range c.nodes {
+			node := &c.nodes[i]
+			domain, ok := node.Labels[constraint.TopologyKey]
+			if !ok {
+				reasons[node.Name] = append(reasons[node.Name], fmt.Sprintf("node has no label %s for topology spread", constraint.TopologyKey))
+				continue
+			}
+			if skew := counts[domain] + self - minCount; skew > int(constraint.MaxSkew) {
+				reasons[node.Name] = append(reasons[node.Name], fmt.Sprintf("topology spread on %s: skew would be %d, max is %d", constraint.TopologyKey, skew, constraint.MaxSkew))
+			}
+		}
+	}
+	return reasons
+}
+
+// podAffinityReasons reports the nodes that are not in the topology of the pods the pod requires to be close to, or
+// that are in the topology of the pods the pod requires to be away from.
+func podAffinityReasons(pod *corev1.Pod, c *cluster) map[string][]string {
+	reasons := map[string][]string{}
+	if pod.Spec.Affinity == nil {
+		return reasons
+	}
+	var affinityTerms, antiAffinityTerms []corev1.PodAffinityTerm
+	if pod.Spec.Affinity.PodAffinity != nil {
+		affinityTerms = pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	}
+	if pod.Spec.Affinity.PodAntiAffinity != nil {
+		antiAffinityTerms = pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	}
+
+	nodesByName := map[string]*corev1.Node{}
+	for i := range c.nodes {
+		nodesByName[c.nodes[i].Name] = &c.nodes[i]
+	}
+	// domains returns the values of the topology key of the nodes of the pods matching the term, and whether any
+	// pod matches
+	domains := func(term *corev1.PodAffinityTerm) (sets.String, bool) {
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			return sets.NewString(), false
+		}
+		namespaces := c.termNamespaces(pod, term)
+		values := sets.NewString()
+		matched := false
+		for i := range c.pods {
+			other := &c.pods[i]
+			if other.UID == pod.UID || !namespaces.Has(other.Namespace) || !selector.Matches(labels.Set(other.Labels)) {
+				continue
+			}
+			node, ok := nodesByName[other.Spec.NodeName]
+			if !ok {
+				continue
+			}
+			matched = true
+			if value, ok := node.Labels[term.TopologyKey]; ok {
+				values.Insert(value)
+			}
+		}
+		return values, matched
+	}
+
+	for i := range affinityTerms {
+		term := &affinityTerms[i]
+		values, matched := domains(term)
+		if !matched {
+			// the first pod of a group that matches its own term can be scheduled anywhere
+			if selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector); err == nil && selector.Matches(labels.Set(pod.Labels)) && c.termNamespaces(pod, term).Has(pod.Namespace) {
+				continue
+			}
+		}
+		for _, node := range c.nodes {
+			if value, ok := node.Labels[term.TopologyKey]; !ok || !values.Has(value) {
+				reasons[node.Name] = append(reasons[node.Name], fmt.Sprintf("pod affinity on %s: no matching pod in the same topology", term.TopologyKey))
+			}
+		}
+	}
+	for i := range antiAffinityTerms {
+		term := &antiAffinityTerms[i]
+		values, _ := domains(term)
+		for _, node := range c.nodes {
+			if value, ok := node.Labels[term.TopologyKey]; ok && values.Has(value) {
+				reasons[node.Name] = append(reasons[node.Name], fmt.Sprintf("pod anti-affinity on %s: a matching pod runs in the same topology", term.TopologyKey))
+			}
+		}
+	}
+	return reasons
+}
+
+// termNamespaces returns the namespaces of the pods an affinity term applies to: the namespaces of the term and those
+// selected by its namespace selector, or the namespace of the pod if it has neither.
+func (c *cluster) termNamespaces(pod *corev1.Pod, term *corev1.PodAffinityTerm) sets.String {
+	namespaces := sets.NewString(term.Namespaces...)
+	if term.NamespaceSelector == nil {
+		if namespaces.Len() == 0 {
+			namespaces.Insert(pod.Namespace)
+		}
+		return namespaces
+	}
+	selector, err := metav1.LabelSelectorAsSelector(term.NamespaceSelector)
+	if err != nil {
+		return namespaces
+	}
+	for _, ns := range c.namespaces {
+		if selector.Matches(labels.Set(ns.Labels)) {
+			namespaces.Insert(ns.Name)
+		}
+	}
+	return namespaces
+}
+
+// summarizeReasons counts the nodes excluded by each reason, with the details after a colon omitted.
+func summarizeReasons(reasons map[string][]string) []string {
+	counts := map[string]int{}
+	for _, r := range reasons {
+		seen := sets.NewString()
+		for _, reason := range r {
+			if i := strings.Index(reason, ":"); i > 0 && !strings.HasPrefix(reason, "untolerated taint") {
+				reason = reason[:i]
+			}
+			if !seen.Has(reason) {
+				seen.Insert(reason)
+				counts[reason]++
+			}
+		}
+	}
+	ordered := make([]string, 0, len(counts))
+	for reason := range counts {
+		ordered = append(ordered, reason)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if counts[ordered[i]] != counts[ordered[j]] {
+			return counts[ordered[i]] > counts[ordered[j]]
+		}
+		return ordered[i] < ordered[j]
+	})
+	var summary []string
+	for _, reason := range ordered {
+		summary = append(summary, fmt.Sprintf("%d node(s): %s", counts[reason], reason))
+	}
+	return summary
+}
@@ -0,0 +1,162 @@
+package whypending
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func testNode(name, zone string, cpu string, taints ...corev1.Taint) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"topology.kubernetes.io/zone": zone, "kubernetes.io/hostname": name}},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:  resource.MustParse(cpu),
+			corev1.ResourcePods: resource.MustParse("110"),
+		}},
+	}
+}
+
+func testPod(namespace, name, node, app, cpu string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(namespace + "/" + name), Labels: map[string]string{"app": app}},
+		Spec:       corev1.PodSpec{NodeName: node, Containers: []corev1.Container{{Name: "c"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if len(cpu) > 0 {
+		pod.Spec.Containers[0].Resources.Requests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+	}
+	if len(node) == 0 {
+		pod.Status.Phase = corev1.PodPending
+	}
+	return pod
+}
+
+func TestNodeReasons(t *testing.T) {
+	infra := corev1.Taint{Key: "node-role.kubernetes.io/infra", Effect: corev1.TaintEffectNoSchedule}
+	c := &cluster{
+		nodes: []corev1.Node{
+			testNode("a", "zone-1", "4"),
+			testNode("b", "zone-2", "2"),
+			testNode("c", "zone-1", "4", infra),
+			testNode("d", "zone-2", "4", corev1.Taint{Key: "soft", Effect: corev1.TaintEffectPreferNoSchedule}),
+		},
+		pods: []corev1.Pod{testPod("app", "busy", "b", "other", "1500m")},
+	}
+	c.nodes[3].Spec.Unschedulable = true
+
+	pod := testPod("app", "web", "", "web", "1")
+	pod.Spec.NodeSelector = map[string]string{"kubernetes.io/hostname": "a"}
+	pod.Spec.Tolerations = []corev1.Toleration{{Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists}}
+
+	expected := map[string][]string{
+		"a": nil,
+		"b": {"node selector kubernetes.io/hostname=a does not match", "insufficient cpu: requests 1, 500m of 2 available"},
+		"c": {"node selector kubernetes.io/hostname=a does not match"},
+		"d": {"node is cordoned", "node selector kubernetes.io/hostname=a does not match"},
+	}
+	if got := nodeReasons(&pod, c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected reasons:\n%v\nexpected:\n%v", got, expected)
+	}
+
+	pod.Spec.NodeSelector = nil
+	pod.Spec.Tolerations = nil
+	pod.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+		NodeSelectorTerms: []corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-2"}}}},
+			{MatchFields: []corev1.NodeSelectorRequirement{{Key: "metadata.name", Operator: corev1.NodeSelectorOpIn, Values: []string{"c"}}}},
+		},
+	}}}
+	expected = map[string][]string{
+		"a": {"required node affinity does not match"},
+		"b": {"insufficient cpu: requests 1, 500m of 2 available"},
+		"c": {"untolerated taint node-role.kubernetes.io/infra:NoSchedule"},
+		"d": {"node is cordoned"},
+	}
+	if got := nodeReasons(&pod, c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected reasons with node affinity:\n%v\nexpected:\n%v", got, expected)
+	}
+}
+
+func TestTopologySpreadReasons(t *testing.T) {
+	c := &cluster{
+		nodes: []corev1.Node{testNode("a", "zone-1", "4"), testNode("b", "zone-1", "4"), testNode("c", "zone-2", "4"), testNode("d", "", "4")},
+		pods: []corev1.Pod{
+			testPod("app", "web-1", "a", "web", ""),
+			testPod("app", "web-2", "b", "web", ""),
+			testPod("other", "web-3", "c", "web", ""),
+		},
+	}
+	delete(c.nodes[3].Labels, "topology.kubernetes.io/zone")
+
+	pod := testPod("app", "web-4", "", "web", "")
+	pod.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+		MaxSkew:           1,
+		TopologyKey:       "topology.kubernetes.io/zone",
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+	}}
+	expected := map[string][]string{
+		"a": {"topology spread on topology.kubernetes.io/zone: skew would be 3, max is 1"},
+		"b": {"topology spread on topology.kubernetes.io/zone: skew would be 3, max is 1"},
+		"d": {"node has no label topology.kubernetes.io/zone for topology spread"},
+	}
+	if got := topologySpreadReasons(&pod, c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected reasons:\n%v\nexpected:\n%v", got, expected)
+	}
+}
+
+func TestPodAffinityReasons(t *testing.T) {
+	c := &cluster{
+		nodes: []corev1.Node{testNode("a", "zone-1", "4"), testNode("b", "zone-1", "4"), testNode("c", "zone-2", "4")},
+		pods: []corev1.Pod{
+			testPod("app", "db-1", "a", "db", ""),
+			testPod("app", "web-1", "c", "web", ""),
+		},
+	}
+
+	pod := testPod("app", "web-2", "", "web", "")
+	pod.Spec.Affinity = &corev1.Affinity{
+		PodAffinity: &corev1.PodAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			TopologyKey:   "topology.kubernetes.io/zone",
+		}}},
+		PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			TopologyKey:   "kubernetes.io/hostname",
+		}}},
+	}
+	expected := map[string][]string{
+		"c": {
+			"pod affinity on topology.kubernetes.io/zone: no matching pod in the same topology",
+			"pod anti-affinity on kubernetes.io/hostname: a matching pod runs in the same topology",
+		},
+	}
+	if got := podAffinityReasons(&pod, c); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected reasons:\n%v\nexpected:\n%v", got, expected)
+	}
+
+	// the first pod of a group that requires affinity to itself can be scheduled anywhere
+	pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchLabels["app"] = "web"
+	pod.Spec.Affinity.PodAntiAffinity = nil
+	c.pods = c.pods[:1]
+	if got := podAffinityReasons(&pod, c); len(got) != 0 {
+		t.Errorf("unexpected reasons for the first pod of a group: %v", got)
+	}
+}
+
+func TestSummarizeReasons(t *testing.T) {
+	summary := summarizeReasons(map[string][]string{
+		"a": nil,
+		"b": {"insufficient cpu: requests 1, 500m of 2 available", "untolerated taint infra:NoSchedule"},
+		"c": {"insufficient cpu: requests 1, 0 of 4 available"},
+	})
+	expected := []string{"2 node(s): insufficient cpu", "1 node(s): untolerated taint infra:NoSchedule"}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("summarizeReasons() = %v, expected %v", summary, expected)
+	}
+}
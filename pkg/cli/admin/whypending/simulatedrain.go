@@ -0,0 +1,283 @@
+package whypending
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	simulateDrainLong = templates.LongDesc(`
+		Simulate the drain of nodes without evicting any pod.
+
+		The pods of the nodes are placed, one after the other and by decreasing priority, on the
+		other nodes as the scheduler would place them, given the requests of the pods already
+		running and of the pods placed before them. The pods that fit on no node are reported
+		Unschedulable along with the reasons the nodes are excluded, as "oc adm why-pending" would
+		report them. The pods of daemon sets and the static pods stay on the nodes and are ignored,
+		and the pods not managed by a controller are reported NotRescheduled: drain deletes them
+		with --force and nothing creates them again.
+
+		The simulation is a diagnostic aid with the same limits as "oc adm why-pending": it ignores
+		preferred affinities and scoring, so the scheduler may place the pods differently, and
+		the command fails when a pod cannot be rescheduled.
+	`)
+
+	simulateDrainExample = templates.Examples(`
+		# Check that the pods of a node can be rescheduled before draining it
+		oc adm simulate-drain worker-1
+
+		# Check that the pods of two nodes can be rescheduled on the other nodes
+		oc adm simulate-drain worker-1 worker-2
+	`)
+)
+
+const (
+	podRescheduled    = "Rescheduled"
+	podUnschedulable  = "Unschedulable"
+	podNotRescheduled = "NotRescheduled"
+)
+
+// SimulateDrainOptions holds the options to simulate the drain of nodes.
+type SimulateDrainOptions struct {
+	NodeNames []string
+
+	Client kubernetes.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewSimulateDrainOptions(streams genericclioptions.IOStreams) *SimulateDrainOptions {
+	return &SimulateDrainOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdSimulateDrain creates a command that simulates the drain of nodes.
+func NewCmdSimulateDrain(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewSimulateDrainOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "simulate-drain NODE...",
+		Short:   "Check whether the pods of nodes can be rescheduled before draining them",
+		Long:    simulateDrainLong,
+		Example: simulateDrainExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+func (o *SimulateDrainOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "at least one node is required")
+	}
+	o.NodeNames = args
+
+	var err error
+	o.Client, err = f.KubernetesClientSet()
+	return err
+}
+
+// podPlacement is the result of the simulation for a pod of a drained node.
+type podPlacement struct {
+	Pod    *corev1.Pod
+	Result string
+	// Node is the node the pod is placed on when it is rescheduled.
+	Node string
+	// Reasons are why the pod is not rescheduled, summarized over the nodes for an unschedulable pod.
+	Reasons []string
+}
+
+func (o *SimulateDrainOptions) Run() error {
+	nodes, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	drained := sets.NewString(o.NodeNames...)
+	c := &cluster{}
+	found := sets.NewString()
+	for _, node := range nodes.Items {
+		if drained.Has(node.Name) {
+			found.Insert(node.Name)
+			continue
+		}
+		c.nodes = append(c.nodes, node)
+	}
+	if missing := drained.Difference(found); missing.Len() > 0 {
+		return fmt.Errorf("node(s) not found: %s", strings.Join(missing.List(), ", "))
+	}
+
+	pods, err := o.Client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	var evicted []*corev1.Pod
+	staying := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if !drained.Has(pod.Spec.NodeName) {
+			c.pods = append(c.pods, *pod)
+			continue
+		}
+		if isDaemonSetPod(pod) || isMirrorPod(pod) {
+			staying++
+			continue
+		}
+		evicted = append(evicted, pod)
+	}
+	for _, pod := range evicted {
+		if hasNamespaceSelector(pod) {
+			namespaces, err := o.Client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			c.namespaces = namespaces.Items
+			break
+		}
+	}
+
+	placements, err := simulateDrain(o.Client, c, evicted)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "Draining %s would evict %d pod(s) to reschedule on %d other node(s)", strings.Join(drained.List(), ", "), len(evicted), len(c.nodes))
+	if staying > 0 {
+		fmt.Fprintf(o.Out, ", %d daemon set and static pod(s) stay", staying)
+	}
+	fmt.Fprintln(o.Out, ".")
+	if len(placements) == 0 {
+		return nil
+	}
+	fmt.Fprintln(o.Out)
+	failed := printPlacements(o.Out, placements)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pod(s) cannot be rescheduled", failed, len(placements))
+	}
+	fmt.Fprintf(o.Out, "\nAll %d pod(s) can be rescheduled.\n", len(placements))
+	return nil
+}
+
+// simulateDrain places the evicted pods on the nodes of the cluster by decreasing priority, adding each placed pod to
+// the cluster so that the next pods account for it.
+func simulateDrain(client kubernetes.Interface, c *cluster, evicted []*corev1.Pod) ([]podPlacement, error) {
+	sort.SliceStable(evicted, func(i, j int) bool {
+		if a, b := podPriority(evicted[i]), podPriority(evicted[j]); a != b {
+			return a > b
+		}
+		if evicted[i].Namespace != evicted[j].Namespace {
+			return evicted[i].Namespace < evicted[j].Namespace
+		}
+		return evicted[i].Name < evicted[j].Name
+	})
+
+	var placements []podPlacement
+	for _, pod := range evicted {
+		placement := podPlacement{Pod: pod}
+		if metav1.GetControllerOf(pod) == nil {
+			placement.Result = podNotRescheduled
+			placement.Reasons = []string{"not managed by a controller, drain deletes it with --force"}
+			placements = append(placements, placement)
+			continue
+		}
+
+		c.volumeNodeAffinities = map[string]*corev1.NodeSelector{}
+		problems, err := volumeProblems(client, pod, c)
+		if err != nil {
+			return nil, err
+		}
+
+		reasons := nodeReasons(pod, c)
+		var eligible []string
+		for _, node := range c.nodes {
+			if len(reasons[node.Name]) == 0 {
+				eligible = append(eligible, node.Name)
+			}
+		}
+		if len(eligible) == 0 || len(problems) > 0 {
+			placement.Result = podUnschedulable
+			placement.Reasons = append(problems, summarizeReasons(reasons)...)
+			placements = append(placements, placement)
+			continue
+		}
+
+		// spread the pods on the eligible nodes that have the fewest pods
+		sort.SliceStable(eligible, func(i, j int) bool {
+			return len(c.podsOn(eligible[i])) < len(c.podsOn(eligible[j]))
+		})
+		placement.Result, placement.Node = podRescheduled, eligible[0]
+		placed := pod.DeepCopy()
+		placed.Spec.NodeName = placement.Node
+		c.pods = append(c.pods, *placed)
+		placements = append(placements, placement)
+	}
+	return placements, nil
+}
+
+// podPriority returns the priority of the pod, 0 if it has none.
+func podPriority(pod *corev1.Pod) int32 {
+	if pod.Spec.Priority == nil {
+		return 0
+	}
+	return *pod.Spec.Priority
+}
+
+// isDaemonSetPod returns true if the pod is controlled by a daemon set.
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	controller := metav1.GetControllerOf(pod)
+	return controller != nil && controller.Kind == "DaemonSet"
+}
+
+// isMirrorPod returns true if the pod is the mirror of a static pod.
+func isMirrorPod(pod *corev1.Pod) bool {
+	_, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]
+	return ok
+}
+
+// printPlacements prints a table of the placements followed by the reasons of the pods that are not rescheduled, and
+// returns how many pods are not rescheduled.
+func printPlacements(out io.Writer, placements []podPlacement) int {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "POD\tFROM\tRESULT\tTO")
+	failed := 0
+	for _, p := range placements {
+		to := p.Node
+		if len(to) == 0 {
+			to = "-"
+		}
+		fmt.Fprintf(w, "%s/%s\t%s\t%s\t%s\n", p.Pod.Namespace, p.Pod.Name, p.Pod.Spec.NodeName, p.Result, to)
+		if p.Result != podRescheduled {
+			failed++
+		}
+	}
+	w.Flush()
+
+	for _, p := range placements {
+		if p.Result == podRescheduled {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s/%s is %s:\n", p.Pod.Namespace, p.Pod.Name, p.Result)
+		for _, reason := range p.Reasons {
+			fmt.Fprintf(out, "  %s\n", reason)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,71 @@
+package whypending
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSimulateDrain(t *testing.T) {
+	node := func(n corev1.Node) *corev1.Node { return &n }
+	pod := func(p corev1.Pod, controller string, priority int32) *corev1.Pod {
+		if len(controller) > 0 {
+			isController := true
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: controller, Name: p.Name, Controller: &isController}}
+		}
+		p.Spec.Priority = &priority
+		return &p
+	}
+	mirror := testPod("kube-system", "etcd", "a", "etcd", "")
+	mirror.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "hash"}
+	pinned := testPod("app", "pinned", "a", "pinned", "")
+	pinned.Spec.NodeSelector = map[string]string{"kubernetes.io/hostname": "a"}
+
+	client := fake.NewSimpleClientset(
+		node(testNode("a", "zone-1", "4")),
+		node(testNode("b", "zone-1", "4")),
+		node(testNode("c", "zone-2", "4")),
+		pod(testPod("app", "busy", "b", "busy", "2"), "ReplicaSet", 0),
+		pod(testPod("app", "web", "a", "web", "2"), "ReplicaSet", 0),
+		pod(testPod("app", "db", "a", "db", "3"), "StatefulSet", 100),
+		pod(testPod("app", "batch", "a", "batch", "3"), "Job", 0),
+		pod(pinned, "ReplicaSet", 0),
+		pod(testPod("app", "debug", "a", "debug", ""), "", 0),
+		pod(testPod("openshift-dns", "dns", "a", "dns", "1"), "DaemonSet", 0),
+		&mirror,
+	)
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &SimulateDrainOptions{NodeNames: []string{"a"}, Client: client, IOStreams: streams}
+	err := o.Run()
+	if err == nil || err.Error() != "3 of 5 pod(s) cannot be rescheduled" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "Draining a would evict 5 pod(s) to reschedule on 2 other node(s), 2 daemon set and static pod(s) stay.\n" +
+		"\n" +
+		"POD          FROM   RESULT           TO\n" +
+		"app/db       a      Rescheduled      c\n" +
+		"app/batch    a      Unschedulable    -\n" +
+		"app/debug    a      NotRescheduled   -\n" +
+		"app/pinned   a      Unschedulable    -\n" +
+		"app/web      a      Rescheduled      b\n" +
+		"\n" +
+		"app/batch is Unschedulable:\n" +
+		"  2 node(s): insufficient cpu\n" +
+		"\n" +
+		"app/debug is NotRescheduled:\n" +
+		"  not managed by a controller, drain deletes it with --force\n" +
+		"\n" +
+		"app/pinned is Unschedulable:\n" +
+		"  2 node(s): node selector kubernetes.io/hostname=a does not match\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+
+	o.NodeNames = []string{"a", "z"}
+	if err := o.Run(); err == nil || err.Error() != "node(s) not found: z" {
+		t.Errorf("unexpected error for a missing node: %v", err)
+	}
+}
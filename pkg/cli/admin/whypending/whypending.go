@@ -0,0 +1,321 @@
+package whypending
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	eventhelpers "github.com/openshift/oc/pkg/helpers/events"
+)
+
+var (
+	whyPendingLong = templates.LongDesc(`
+		Explain why a pending pod is not scheduled.
+
+		The pod is evaluated against every node as the scheduler would, and the reasons each node
+		is excluded are printed: untolerated taints, cordoned nodes, node selectors and required
+		node affinity, resources requested beyond what is allocatable and not requested by the
+		pods of the node, topology spread constraints, required pod affinity and anti-affinity,
+		and persistent volumes that are not accessible from the node. Problems of the pod itself,
+		such as persistent volume claims that do not exist or are not bound, are printed first,
+		along with the last scheduling failure reported by the scheduler.
+
+		The evaluation is a diagnostic aid: it does not cover every scheduler plugin, such as host
+		ports, and ignores preferred affinities and scoring.
+	`)
+
+	whyPendingExample = templates.Examples(`
+		# Explain why the pod web-1 is not scheduled
+		oc adm why-pending web-1
+
+		# Explain why a pod of another namespace is not scheduled
+		oc adm why-pending -n my-project web-1
+	`)
+)
+
+// WhyPendingOptions holds the options to explain why a pending pod is not scheduled.
+type WhyPendingOptions struct {
+	PodName   string
+	Namespace string
+
+	Client kubernetes.Interface
+
+	genericclioptions.IOStreams
+}
+
+func NewWhyPendingOptions(streams genericclioptions.IOStreams) *WhyPendingOptions {
+	return &WhyPendingOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdWhyPending creates a command that explains why a pending pod is not scheduled.
+func NewCmdWhyPending(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewWhyPendingOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "why-pending POD",
+		Short:   "Explain why a pending pod is not scheduled",
+		Long:    whyPendingLong,
+		Example: whyPendingExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+func (o *WhyPendingOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "exactly one pod name is required")
+	}
+	o.PodName = args[0]
+
+	var err error
+	if o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+	o.Client, err = f.KubernetesClientSet()
+	return err
+}
+
+func (o *WhyPendingOptions) Run() error {
+	pod, err := o.Client.CoreV1().Pods(o.Namespace).Get(context.TODO(), o.PodName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if len(pod.Spec.NodeName) > 0 {
+		fmt.Fprintf(o.Out, "Pod %s/%s is scheduled on node %s.\n", pod.Namespace, pod.Name, pod.Spec.NodeName)
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Waiting != nil && len(status.State.Waiting.Reason) > 0 {
+				fmt.Fprintf(o.Out, "Container %s is waiting: %s %s\n", status.Name, status.State.Waiting.Reason, status.State.Waiting.Message)
+			}
+		}
+		return nil
+	}
+	if pod.Status.Phase != corev1.PodPending {
+		fmt.Fprintf(o.Out, "Pod %s/%s is not pending, it is %s.\n", pod.Namespace, pod.Name, pod.Status.Phase)
+		return nil
+	}
+
+	c := &cluster{volumeNodeAffinities: map[string]*corev1.NodeSelector{}}
+	nodes, err := o.Client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	c.nodes = nodes.Items
+	pods, err := o.Client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, p := range pods.Items {
+		if p.Status.Phase != corev1.PodSucceeded && p.Status.Phase != corev1.PodFailed {
+			c.pods = append(c.pods, p)
+		}
+	}
+	if hasNamespaceSelector(pod) {
+		namespaces, err := o.Client.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		c.namespaces = namespaces.Items
+	}
+	problems, err := volumeProblems(o.Client, pod, c)
+	if err != nil {
+		return err
+	}
+	if len(pod.Spec.SchedulerName) > 0 && pod.Spec.SchedulerName != corev1.DefaultSchedulerName {
+		problems = append(problems, fmt.Sprintf("the pod is scheduled by %s, the evaluation is that of the default scheduler", pod.Spec.SchedulerName))
+	}
+	lastFailure, err := o.lastSchedulingFailure(pod)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "Pod %s/%s is pending and not scheduled.\n", pod.Namespace, pod.Name)
+	if len(lastFailure) > 0 {
+		fmt.Fprintf(o.Out, "\nScheduler: %s\n", lastFailure)
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(o.Out, "\nProblems of the pod:\n")
+		for _, problem := range problems {
+			fmt.Fprintf(o.Out, "  %s\n", problem)
+		}
+	}
+	if len(c.nodes) == 0 {
+		fmt.Fprintf(o.Out, "\nThe cluster has no nodes.\n")
+		return nil
+	}
+
+	reasons := nodeReasons(pod, c)
+	fmt.Fprintln(o.Out)
+	printNodeReasons(o.Out, c.nodes, reasons)
+
+	eligible := 0
+	for _, r := range reasons {
+		if len(r) == 0 {
+			eligible++
+		}
+	}
+	if summary := summarizeReasons(reasons); len(summary) > 0 {
+		fmt.Fprintf(o.Out, "\nExcluded nodes:\n")
+		for _, line := range summary {
+			fmt.Fprintf(o.Out, "  %s\n", line)
+		}
+	}
+	fmt.Fprintf(o.Out, "\n%d of %d node(s) are eligible.\n", eligible, len(c.nodes))
+	if eligible > 0 && len(problems) == 0 {
+		fmt.Fprintf(o.Out, "The pod fits on %d node(s), the scheduler may not have retried it yet.\n", eligible)
+	}
+	return nil
+}
+
+// volumeProblems returns the problems of the persistent volume claims of the pod, and adds the node affinity of
+// their bound persistent volumes to the cluster.
+func volumeProblems(client kubernetes.Interface, pod *corev1.Pod, c *cluster) ([]string, error) {
+	var problems []string
+	for _, volume := range pod.Spec.Volumes {
+		var claimName string
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			claimName = volume.PersistentVolumeClaim.ClaimName
+		case volume.Ephemeral != nil:
+			claimName = pod.Name + "-" + volume.Name
+		default:
+			continue
+		}
+
+		claim, err := client.CoreV1().PersistentVolumeClaims(pod.Namespace).Get(context.TODO(), claimName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			problems = append(problems, fmt.Sprintf("persistent volume claim %s does not exist", claimName))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if claim.DeletionTimestamp != nil {
+			problems = append(problems, fmt.Sprintf("persistent volume claim %s is being deleted", claimName))
+			continue
+		}
+		if claim.Status.Phase != corev1.ClaimBound {
+			waits, err := waitsForFirstConsumer(client, claim)
+			if err != nil {
+				return nil, err
+			}
+			if !waits {
+				problems = append(problems, fmt.Sprintf("persistent volume claim %s is not bound", claimName))
+			}
+			continue
+		}
+
+		pv, err := client.CoreV1().PersistentVolumes().Get(context.TODO(), claim.Spec.VolumeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			problems = append(problems, fmt.Sprintf("persistent volume %s of claim %s does not exist", claim.Spec.VolumeName, claimName))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if pv.Spec.NodeAffinity != nil && pv.Spec.NodeAffinity.Required != nil {
+			c.volumeNodeAffinities[pv.Name] = pv.Spec.NodeAffinity.Required
+		}
+	}
+	return problems, nil
+}
+
+// waitsForFirstConsumer returns true if the claim is provisioned once its pod is scheduled.
+func waitsForFirstConsumer(client kubernetes.Interface, claim *corev1.PersistentVolumeClaim) (bool, error) {
+	if claim.Spec.StorageClassName == nil || len(*claim.Spec.StorageClassName) == 0 {
+		return false, nil
+	}
+	class, err := client.StorageV1().StorageClasses().Get(context.TODO(), *claim.Spec.StorageClassName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return class.VolumeBindingMode != nil && *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer, nil
+}
+
+// lastSchedulingFailure returns the message of the last FailedScheduling event of the pod, if any.
+func (o *WhyPendingOptions) lastSchedulingFailure(pod *corev1.Pod) (string, error) {
+	events, err := o.Client.CoreV1().Events(pod.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	var last *corev1.Event
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.Reason != "FailedScheduling" || event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != pod.Name || (len(event.InvolvedObject.UID) > 0 && event.InvolvedObject.UID != pod.UID) {
+			continue
+		}
+		if last == nil || eventhelpers.LastTime(event).After(eventhelpers.LastTime(last)) {
+			last = event
+		}
+	}
+	if last == nil {
+		return "", nil
+	}
+	return strings.TrimSpace(last.Message), nil
+}
+
+// hasNamespaceSelector returns true if a required pod affinity or anti-affinity term of the pod has a namespace
+// selector.
+func hasNamespaceSelector(pod *corev1.Pod) bool {
+	if pod.Spec.Affinity == nil {
+		return false
+	}
+	var terms []corev1.PodAffinityTerm
+	if pod.Spec.Affinity.PodAffinity != nil {
+		terms = append(terms, pod.Spec.Affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+	}
+	if pod.Spec.Affinity.PodAntiAffinity != nil {
+		terms = append(terms, pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+	}
+	for _, term := range terms {
+		if term.NamespaceSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// printNodeReasons prints a table of the nodes and the reasons they are excluded, the eligible nodes first.
+func printNodeReasons(out io.Writer, nodes []corev1.Node, reasons map[string][]string) {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if a, b := len(reasons[names[i]]) == 0, len(reasons[names[j]]) == 0; a != b {
+			return a
+		}
+		return names[i] < names[j]
+	})
+
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "NODE\tSTATUS\tREASONS")
+	for _, name := range names {
+		status := "Eligible"
+		if len(reasons[name]) > 0 {
+			status = "Excluded"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, status, strings.Join(reasons[name], "; "))
+	}
+	w.Flush()
+}
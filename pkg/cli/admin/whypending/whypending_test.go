@@ -0,0 +1,112 @@
+package whypending
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRun(t *testing.T) {
+	pending := testPod("app", "web", "", "web", "3")
+	pending.Spec.Volumes = []corev1.Volume{
+		{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+		{Name: "cache", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "cache"}}},
+		{Name: "logs", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "logs"}}},
+		{Name: "missing", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "missing"}}},
+	}
+	node := func(n corev1.Node) *corev1.Node { return &n }
+	pod := func(p corev1.Pod) *corev1.Pod { return &p }
+	local, immediate := "local", "standard"
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	now := time.Now()
+
+	client := fake.NewSimpleClientset(
+		pod(pending),
+		pod(testPod("app", "busy", "b", "other", "3")),
+		node(testNode("a", "zone-1", "4")),
+		node(testNode("b", "zone-2", "4")),
+		node(testNode("c", "zone-1", "2")),
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "data"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-data"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-data"},
+			Spec: corev1.PersistentVolumeSpec{NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-1"}}}},
+			}}}},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "cache"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &local},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "local"}, VolumeBindingMode: &waitForFirstConsumer},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "app", Name: "logs"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &immediate},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "app", Name: "web.1"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", UID: pending.UID},
+			Reason:         "FailedScheduling",
+			Message:        "old failure",
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Hour)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "app", Name: "web.2"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web", UID: pending.UID},
+			Reason:         "FailedScheduling",
+			Message:        "0/3 nodes are available: 1 Insufficient cpu, 2 node(s) had volume node affinity conflict.",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+	)
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &WhyPendingOptions{PodName: "web", Namespace: "app", Client: client, IOStreams: streams}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "Pod app/web is pending and not scheduled.\n" +
+		"\n" +
+		"Scheduler: 0/3 nodes are available: 1 Insufficient cpu, 2 node(s) had volume node affinity conflict.\n" +
+		"\n" +
+		"Problems of the pod:\n" +
+		"  persistent volume claim logs is not bound\n" +
+		"  persistent volume claim missing does not exist\n" +
+		"\n" +
+		"NODE   STATUS     REASONS\n" +
+		"a      Eligible   \n" +
+		"b      Excluded   insufficient cpu: requests 3, 1 of 4 available; persistent volume pv-data is not accessible from node\n" +
+		"c      Excluded   insufficient cpu: requests 3, 2 of 2 available\n" +
+		"\n" +
+		"Excluded nodes:\n" +
+		"  2 node(s): insufficient cpu\n" +
+		"  1 node(s): persistent volume pv-data is not accessible from node\n" +
+		"\n" +
+		"1 of 3 node(s) are eligible.\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestRun_scheduled(t *testing.T) {
+	scheduled := testPod("app", "web", "a", "web", "")
+	scheduled.Status.Phase = corev1.PodPending
+	scheduled.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "c", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}}}}
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &WhyPendingOptions{PodName: "web", Namespace: "app", Client: fake.NewSimpleClientset(&scheduled), IOStreams: streams}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "Pod app/web is scheduled on node a.\nContainer c is waiting: ImagePullBackOff Back-off pulling image\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
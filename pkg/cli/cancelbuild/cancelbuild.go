@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -40,6 +41,11 @@ var (
 
 		This command requests a graceful shutdown of the build. There may be a delay between requesting
 		the build and the time the build is terminated.
+
+		Pass --all instead of names to cancel many builds at once: all the builds of the namespace, of all
+		the namespaces with --all-namespaces, or the builds matching --selector. The builds can be limited
+		to those in the states of --state, and to those created more than --older-than ago. A table
+		summarizing the cancellation of the builds is printed at the end.
 	`)
 
 	cancelBuildExample = templates.Examples(`
@@ -57,6 +63,12 @@ var (
 
 		# Cancel all builds created from the 'ruby-build' build config that are in the 'new' state
 		oc cancel-build bc/ruby-build --state=new
+
+		# Cancel all the pending builds of the current namespace created more than an hour ago
+		oc cancel-build --all --state=pending --older-than=1h
+
+		# Cancel all the running builds with the label 'app=ruby' in all namespaces
+		oc cancel-build --all --all-namespaces --selector=app=ruby --state=running
 	`)
 )
 
@@ -68,6 +80,17 @@ type CancelBuildOptions struct {
 	Namespace  string
 	BuildNames []string
 
+	// All cancels the builds of the namespace, or of all namespaces with AllNamespaces, matching Selector
+	All           bool
+	AllNamespaces bool
+	Selector      string
+	// OlderThan only cancels the builds created more than this duration ago
+	OlderThan time.Duration
+
+	// results are the results of the cancellations of the builds, summarized when All is set
+	results     map[string]string
+	resultsLock sync.Mutex
+
 	HasError                bool
 	ReportError             func(error)
 	PrinterCancel           printers.ResourcePrinter
@@ -113,14 +136,24 @@ func NewCmdCancelBuild(f kcmdutil.Factory, streams genericclioptions.IOStreams)
 	cmd.Flags().StringSliceVar(&o.States, "state", o.States, "Only cancel builds in this state")
 	cmd.Flags().BoolVar(&o.DumpLogs, "dump-logs", o.DumpLogs, "Specify if the build logs for the cancelled build should be shown.")
 	cmd.Flags().BoolVar(&o.Restart, "restart", o.Restart, "Specify if a new build should be created after the current build is cancelled.")
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "Cancel all the builds of the namespace in the states of --state instead of the given builds.")
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "When using the --all option: cancel the builds of all namespaces.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "When using the --all option: only cancel the builds matching this label selector.")
+	cmd.Flags().DurationVar(&o.OlderThan, "older-than", o.OlderThan, "Only cancel the builds created more than this duration ago, like 30m or 2h.")
 
 	return cmd
 }
 
 // Complete completes all the required options.
 func (o *CancelBuildOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("build or a buildconfig name is required")
+	if o.All && len(args) > 0 {
+		return fmt.Errorf("builds or buildconfigs can't be given with --all")
+	}
+	if !o.All && len(args) == 0 {
+		return fmt.Errorf("build or a buildconfig name is required, or --all to cancel the builds of the namespace")
+	}
+	if !o.All && (o.AllNamespaces || len(o.Selector) > 0) {
+		return fmt.Errorf("--all-namespaces and --selector can only be used with --all")
 	}
 
 	o.ReportError = func(err error) {
@@ -145,6 +178,13 @@ func (o *CancelBuildOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, ar
 		return err
 	}
 
+	if o.All {
+		// the builds are summarized in a table instead
+		o.PrinterCancel = printers.NewDiscardingPrinter()
+		o.PrinterCancelInProgress = printers.NewDiscardingPrinter()
+		o.PrinterRestart = printers.NewDiscardingPrinter()
+	}
+
 	if o.timeout.Seconds() == 0 {
 		o.timeout = 30 * time.Second
 	}
@@ -243,20 +283,47 @@ func (o *CancelBuildOptions) Validate() error {
 			return fmt.Errorf("invalid --state flag value, must be one of 'new', 'pending', or 'running'")
 		}
 	}
+	if o.OlderThan < 0 {
+		return fmt.Errorf("--older-than must be a positive duration")
+	}
+	if len(o.Selector) > 0 {
+		if _, err := labels.Parse(o.Selector); err != nil {
+			return fmt.Errorf("invalid --selector: %v", err)
+		}
+	}
 
 	return nil
 }
 
-// RunCancelBuild implements all the necessary functionality for CancelBuild.
-func (o *CancelBuildOptions) RunCancelBuild() error {
-	var builds []*buildv1.Build
+// cancellableBuilds returns the given builds, or all the builds with All, which are in a cancellable state of
+// States and are older than OlderThan.
+func (o *CancelBuildOptions) cancellableBuilds() ([]*buildv1.Build, error) {
+	var candidates []*buildv1.Build
+	if o.All {
+		namespace := o.Namespace
+		if o.AllNamespaces {
+			namespace = metav1.NamespaceAll
+		}
+		list, err := o.Client.Builds(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: o.Selector})
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			candidates = append(candidates, &list.Items[i])
+		}
+	}
 	for _, name := range o.BuildNames {
 		build, err := o.BuildClient.Get(context.TODO(), name, metav1.GetOptions{})
 		if err != nil {
 			o.ReportError(fmt.Errorf("build %s/%s not found", o.Namespace, name))
 			continue
 		}
+		candidates = append(candidates, build)
+	}
 
+	var builds []*buildv1.Build
+	now := time.Now()
+	for _, build := range candidates {
 		stateMatch := false
 		for _, state := range o.States {
 			if strings.ToLower(string(build.Status.Phase)) == state {
@@ -264,11 +331,68 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 				break
 			}
 		}
+		if o.OlderThan > 0 && now.Sub(build.CreationTimestamp.Time) < o.OlderThan {
+			continue
+		}
 
 		if stateMatch && !ocbuildutil.IsTerminalPhase(build.Status.Phase) {
 			builds = append(builds, build)
 		}
 	}
+	return builds, nil
+}
+
+// buildClient returns the client of the builds of the namespace.
+func (o *CancelBuildOptions) buildClient(namespace string) buildtv1client.BuildInterface {
+	if o.BuildClient != nil && (len(namespace) == 0 || namespace == o.Namespace) {
+		return o.BuildClient
+	}
+	return o.Client.Builds(namespace)
+}
+
+// recordResult records the result of the cancellation of the build for the summary.
+func (o *CancelBuildOptions) recordResult(build *buildv1.Build, result string) {
+	o.resultsLock.Lock()
+	defer o.resultsLock.Unlock()
+	if o.results == nil {
+		o.results = map[string]string{}
+	}
+	o.results[build.Namespace+"/"+build.Name] = result
+}
+
+// result returns the result of the cancellation of the build.
+func (o *CancelBuildOptions) result(build *buildv1.Build) string {
+	o.resultsLock.Lock()
+	defer o.resultsLock.Unlock()
+	return o.results[build.Namespace+"/"+build.Name]
+}
+
+// printSummary prints a table of the builds with the results of their cancellation.
+func (o *CancelBuildOptions) printSummary(builds []*buildv1.Build) {
+	if len(builds) == 0 {
+		fmt.Fprintf(o.Out, "No builds to cancel\n")
+		return
+	}
+	w := printers.GetNewTabWriter(o.Out)
+	defer w.Flush()
+	now := time.Now()
+	fmt.Fprintf(w, "NAMESPACE\tNAME\tPHASE\tAGE\tRESULT\n")
+	for _, b := range builds {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", b.Namespace, b.Name, b.Status.Phase, duration.HumanDuration(now.Sub(b.CreationTimestamp.Time)), o.result(b))
+	}
+}
+
+// RunCancelBuild implements all the necessary functionality for CancelBuild.
+func (o *CancelBuildOptions) RunCancelBuild() error {
+	builds, err := o.cancellableBuilds()
+	if err != nil {
+		return err
+	}
+	// the phases before the cancellation are summarized
+	summarized := make([]*buildv1.Build, len(builds))
+	for i, b := range builds {
+		summarized[i] = b.DeepCopy()
+	}
 
 	if o.DumpLogs {
 		for _, b := range builds {
@@ -276,7 +400,7 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 			if b.Status.Phase == buildv1.BuildPhaseNew {
 				continue
 			}
-			logClient := buildclientv1.NewBuildLogClient(o.Client.RESTClient(), o.Namespace, scheme.Scheme)
+			logClient := buildclientv1.NewBuildLogClient(o.Client.RESTClient(), b.Namespace, scheme.Scheme)
 			opts := buildv1.BuildLogOptions{NoWait: true}
 			response, err := logClient.Logs(b.Name, opts).Do(context.TODO()).Raw()
 			if err != nil {
@@ -293,19 +417,21 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 		wg.Add(1)
 		go func(build *buildv1.Build) {
 			defer wg.Done()
+			client := o.buildClient(build.Namespace)
 			err := wait.Poll(500*time.Millisecond, o.timeout, func() (bool, error) {
 				build.Status.Cancelled = true
-				_, err := o.BuildClient.Update(context.TODO(), build, metav1.UpdateOptions{})
+				_, err := client.Update(context.TODO(), build, metav1.UpdateOptions{})
 				switch {
 				case err == nil:
 					return true, nil
 				case kapierrors.IsConflict(err):
-					build, err = o.BuildClient.Get(context.TODO(), build.Name, metav1.GetOptions{})
+					build, err = client.Get(context.TODO(), build.Name, metav1.GetOptions{})
 					return false, err
 				}
 				return true, err
 			})
 			if err != nil {
+				o.recordResult(build, "update failed")
 				o.ReportError(fmt.Errorf("build %s/%s failed to update: %v", build.Namespace, build.Name, err))
 				return
 			}
@@ -322,16 +448,18 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 				timeout = timeout + (3 * time.Minute)
 			}
 			err = wait.Poll(500*time.Millisecond, timeout, func() (bool, error) {
-				updatedBuild, err := o.BuildClient.Get(context.TODO(), build.Name, metav1.GetOptions{})
+				updatedBuild, err := client.Get(context.TODO(), build.Name, metav1.GetOptions{})
 				if err != nil {
 					return true, err
 				}
 				return updatedBuild.Status.Phase == buildv1.BuildPhaseCancelled, nil
 			})
 			if err != nil {
+				o.recordResult(build, "cancellation timed out")
 				o.ReportError(fmt.Errorf("build %s/%s failed to cancel: %v", build.Namespace, build.Name, err))
 				return
 			}
+			o.recordResult(build, "cancelled")
 
 			if err := o.PrinterCancel.PrintObj(build, o.Out); err != nil {
 				o.ReportError(fmt.Errorf("build %s/%s failed to print: %v", build.Namespace, build.Name, err))
@@ -344,11 +472,13 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 	if o.Restart {
 		for _, b := range builds {
 			request := &buildv1.BuildRequest{ObjectMeta: metav1.ObjectMeta{Namespace: b.Namespace, Name: b.Name}}
-			build, err := o.BuildClient.Clone(context.TODO(), request.Name, request, metav1.CreateOptions{})
+			build, err := o.buildClient(b.Namespace).Clone(context.TODO(), request.Name, request, metav1.CreateOptions{})
 			if err != nil {
+				o.recordResult(b, o.result(b)+", restart failed")
 				o.ReportError(fmt.Errorf("build %s/%s failed to restart: %v", b.Namespace, b.Name, err))
 				continue
 			}
+			o.recordResult(b, o.result(b)+", restarted as "+build.Name)
 			if err := o.PrinterRestart.PrintObj(b, o.Out); err != nil {
 				o.ReportError(fmt.Errorf("build %s/%s failed to print: %v", build.Namespace, build.Name, err))
 				continue
@@ -356,6 +486,10 @@ func (o *CancelBuildOptions) RunCancelBuild() error {
 		}
 	}
 
+	if o.All {
+		o.printSummary(summarized)
+	}
+
 	if o.HasError {
 		return errors.New("failure during the build cancellation")
 	}
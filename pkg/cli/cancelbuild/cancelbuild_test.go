@@ -1,6 +1,7 @@
 package cancelbuild
 
 import (
+	"context"
 	"io"
 	"strconv"
 	"strings"
@@ -225,3 +226,76 @@ func genBuild(phase buildv1.BuildPhase) *buildv1.Build {
 type testAction struct {
 	verb, resource string
 }
+
+// TestCancelBuildAll ensures that the builds of all namespaces are filtered by state and age, and summarized.
+func TestCancelBuildAll(t *testing.T) {
+	newBuild := func(namespace, name string, phase buildv1.BuildPhase, age time.Duration) *buildv1.Build {
+		b := genBuild(phase)
+		b.Namespace, b.Name = namespace, name
+		b.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		return b
+	}
+	client := buildfake.NewSimpleClientset(
+		newBuild("test", "ruby-1", buildv1.BuildPhaseRunning, 2*time.Hour),
+		newBuild("test", "ruby-2", buildv1.BuildPhaseRunning, time.Minute),
+		newBuild("other", "python-1", buildv1.BuildPhasePending, 3*time.Hour),
+		newBuild("other", "python-2", buildv1.BuildPhaseComplete, 3*time.Hour),
+	)
+	client.PrependReactor("update", "builds", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
+		build := action.(clientgotesting.UpdateAction).GetObject().(*buildv1.Build)
+		if build.Status.Cancelled {
+			build.Status.Phase = buildv1.BuildPhaseCancelled
+		}
+		return false, nil, nil
+	})
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := NewCancelBuildOptions(streams)
+	o.All = true
+	o.AllNamespaces = true
+	o.OlderThan = time.Hour
+	o.Namespace = "test"
+	o.timeout = time.Second
+	o.Client = client.BuildV1()
+	o.BuildClient = client.BuildV1().Builds(o.Namespace)
+	o.PrinterCancel = &discardingPrinter{}
+	o.PrinterCancelInProgress = &discardingPrinter{}
+	o.PrinterRestart = &discardingPrinter{}
+	o.ReportError = func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunCancelBuild(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range []struct {
+		namespace, name string
+		phase           buildv1.BuildPhase
+	}{
+		{"test", "ruby-1", buildv1.BuildPhaseCancelled},
+		{"test", "ruby-2", buildv1.BuildPhaseRunning},
+		{"other", "python-1", buildv1.BuildPhaseCancelled},
+		{"other", "python-2", buildv1.BuildPhaseComplete},
+	} {
+		build, err := client.BuildV1().Builds(b.namespace).Get(context.TODO(), b.name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if build.Status.Phase != b.phase {
+			t.Errorf("expected build %s/%s to be %s, got %s", b.namespace, b.name, b.phase, build.Status.Phase)
+		}
+	}
+
+	summary := out.String()
+	for _, expected := range []string{"NAMESPACE", "ruby-1", "Running", "python-1", "Pending", "cancelled"} {
+		if !strings.Contains(summary, expected) {
+			t.Errorf("expected the summary to contain %q, got:\n%s", expected, summary)
+		}
+	}
+	if strings.Contains(summary, "ruby-2") || strings.Contains(summary, "python-2") {
+		t.Errorf("expected the summary to only contain the cancelled builds, got:\n%s", summary)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/openshift/oc/pkg/cli/idle"
 	"github.com/openshift/oc/pkg/cli/image"
 	"github.com/openshift/oc/pkg/cli/importimage"
+	"github.com/openshift/oc/pkg/cli/kubecontext"
 	"github.com/openshift/oc/pkg/cli/kubectlwrappers"
 	"github.com/openshift/oc/pkg/cli/login"
 	"github.com/openshift/oc/pkg/cli/logout"
@@ -38,9 +39,11 @@ import (
 	"github.com/openshift/oc/pkg/cli/observe"
 	"github.com/openshift/oc/pkg/cli/options"
 	"github.com/openshift/oc/pkg/cli/policy"
+	"github.com/openshift/oc/pkg/cli/portforward"
 	"github.com/openshift/oc/pkg/cli/process"
 	"github.com/openshift/oc/pkg/cli/project"
 	"github.com/openshift/oc/pkg/cli/projects"
+	"github.com/openshift/oc/pkg/cli/proxytunnel"
 	"github.com/openshift/oc/pkg/cli/recycle"
 	"github.com/openshift/oc/pkg/cli/registry"
 	"github.com/openshift/oc/pkg/cli/requestproject"
@@ -173,6 +176,7 @@ func NewOcCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 				status.NewCmdStatus(f, ioStreams),
 				project.NewCmdProject(f, ioStreams),
 				projects.NewCmdProjects(f, ioStreams),
+				kubecontext.NewCmdContext(f, ioStreams),
 				kubectlwrappers.NewCmdExplain(f, ioStreams),
 			},
 		},
@@ -213,7 +217,8 @@ func NewOcCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 				logs.NewCmdLogs(f, ioStreams),
 				rsh.NewCmdRsh(f, ioStreams),
 				rsync.NewCmdRsync(f, ioStreams),
-				kubectlwrappers.NewCmdPortForward(f, ioStreams),
+				portforward.NewCmdPortForward(f, ioStreams),
+				proxytunnel.NewCmdProxyTunnel(f, ioStreams),
 				debug.NewCmdDebug(f, ioStreams),
 				kubectlwrappers.NewCmdExec(f, ioStreams),
 				kubectlwrappers.NewCmdProxy(f, ioStreams),
@@ -0,0 +1,374 @@
+package create
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	externalSecretLong = templates.LongDesc(`
+		Create an external secret synchronizing a secret from an external secret manager.
+
+		The ExternalSecret is read by the external-secrets operator, which fetches the remote keys given with
+		--data and --data-from from the secret store and writes them to a secret named after the external
+		secret, or --target. The secret store, a SecretStore or with --cluster-store a ClusterSecretStore,
+		is created too with --create-store, from the settings of its --provider:
+
+		* vault: the --server of HashiCorp Vault, authenticating with its kubernetes auth method as --role,
+		  reading the key value engine mounted at --path
+		* aws: the AWS Secrets Manager, or Parameter Store with --aws-service, of --region, optionally
+		  assuming --role
+		* azure: the Azure Key Vault of URL --server, authenticating with workload identity
+
+		The service account given with --service-account authenticates to the provider, the operator's own
+		one is used when omitted. Use --dry-run=client -o yaml to generate manifests to commit to git instead.
+	`)
+
+	externalSecretExample = templates.Examples(`
+		# Create an external secret with the password property of the Vault secret db/creds and the secret store to read it
+		oc create externalsecret db-creds --provider=vault --create-store --server=https://vault.example.com:8200 \
+		  --role=myapp --service-account=myapp --data=password=db/creds#password
+
+		# Generate the manifests of an external secret with all the keys of an AWS secret, read from an existing cluster secret store
+		oc create externalsecret app-config --store=aws --cluster-store --data-from=prod/app-config --dry-run=client -o yaml
+
+		# Create an external secret and its secret store reading the Azure Key Vault secret api-key
+		oc create externalsecret api-key --provider=azure --create-store --server=https://myvault.vault.azure.net \
+		  --service-account=myapp --data=api-key=api-key
+	`)
+)
+
+var (
+	externalSecretsGroupVersion = schema.GroupVersion{Group: "external-secrets.io", Version: "v1beta1"}
+
+	externalSecretProviders = []string{"vault", "aws", "azure"}
+)
+
+type CreateExternalSecretOptions struct {
+	CreateSubcommandOptions *CreateSubcommandOptions
+
+	Data            []string
+	DataFrom        []string
+	Target          string
+	RefreshInterval time.Duration
+
+	Store        string
+	ClusterStore bool
+	CreateStore  bool
+
+	Provider       string
+	Server         string
+	Path           string
+	Role           string
+	AuthMount      string
+	Region         string
+	AWSService     string
+	TenantID       string
+	ServiceAccount string
+
+	Client dynamic.Interface
+}
+
+func NewCreateExternalSecretOptions(streams genericclioptions.IOStreams) *CreateExternalSecretOptions {
+	return &CreateExternalSecretOptions{
+		CreateSubcommandOptions: NewCreateSubcommandOptions(streams),
+		RefreshInterval:         time.Hour,
+		Path:                    "secret",
+		AuthMount:               "kubernetes",
+		AWSService:              "SecretsManager",
+	}
+}
+
+// NewCmdCreateExternalSecret is a macro command to create a new external secret and its secret store.
+func NewCmdCreateExternalSecret(f genericclioptions.RESTClientGetter, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewCreateExternalSecretOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "externalsecret NAME (--store=NAME | --provider=vault|aws|azure --create-store) [--data=KEY=REMOTE_KEY[#PROPERTY]]... [--data-from=REMOTE_KEY]...",
+		Short:   "Create an external secret synchronizing a secret from an external secret manager",
+		Long:    externalSecretLong,
+		Example: externalSecretExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(cmd, f, args))
+			cmdutil.CheckErr(o.Validate())
+			cmdutil.CheckErr(o.Run())
+		},
+		Aliases: []string{"es"},
+	}
+	cmd.Flags().StringArrayVar(&o.Data, "data", o.Data, "A key of the secret and the remote key to read it from, with the property of the remote secret to read: KEY=REMOTE_KEY[#PROPERTY]")
+	cmd.Flags().StringArrayVar(&o.DataFrom, "data-from", o.DataFrom, "A remote key whose properties are all added to the secret")
+	cmd.Flags().StringVar(&o.Target, "target", o.Target, "The name of the secret to write, defaults to the name of the external secret")
+	cmd.Flags().DurationVar(&o.RefreshInterval, "refresh-interval", o.RefreshInterval, "How often the secret is refreshed from the provider, zero to only fetch it once")
+
+	cmd.Flags().StringVar(&o.Store, "store", o.Store, "The name of the secret store to read from, defaults to the name of the provider")
+	cmd.Flags().BoolVar(&o.ClusterStore, "cluster-store", o.ClusterStore, "If true, read from a ClusterSecretStore rather than a SecretStore of the namespace")
+	cmd.Flags().BoolVar(&o.CreateStore, "create-store", o.CreateStore, "If true, create the secret store from --provider and its settings too")
+
+	cmd.Flags().StringVar(&o.Provider, "provider", o.Provider, "The provider of the secret store: "+strings.Join(externalSecretProviders, ", "))
+	cmd.Flags().StringVar(&o.Server, "server", o.Server, "The address of the Vault server or the URL of the Azure Key Vault")
+	cmd.Flags().StringVar(&o.Path, "path", o.Path, "The mount path of the Vault key value engine")
+	cmd.Flags().StringVar(&o.Role, "role", o.Role, "The role of the Vault kubernetes auth method or the ARN of the AWS role to assume")
+	cmd.Flags().StringVar(&o.AuthMount, "auth-mount", o.AuthMount, "The mount path of the Vault kubernetes auth method")
+	cmd.Flags().StringVar(&o.Region, "region", o.Region, "The AWS region of the secrets")
+	cmd.Flags().StringVar(&o.AWSService, "aws-service", o.AWSService, "The AWS service storing the secrets: SecretsManager or ParameterStore")
+	cmd.Flags().StringVar(&o.TenantID, "tenant-id", o.TenantID, "The Azure tenant of the Key Vault")
+	cmd.Flags().StringVar(&o.ServiceAccount, "service-account", o.ServiceAccount, "The service account authenticating to the provider")
+
+	o.CreateSubcommandOptions.AddFlags(cmd)
+	cmdutil.AddDryRunFlag(cmd)
+
+	return cmd
+}
+
+func (o *CreateExternalSecretOptions) Complete(cmd *cobra.Command, f genericclioptions.RESTClientGetter, args []string) error {
+	if len(o.Store) == 0 {
+		o.Store = o.Provider
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.Client, err = dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	return o.CreateSubcommandOptions.Complete(f, cmd, args)
+}
+
+func (o *CreateExternalSecretOptions) Validate() error {
+	if len(o.Data) == 0 && len(o.DataFrom) == 0 {
+		return fmt.Errorf("at least one --data or --data-from is required")
+	}
+	for _, data := range o.Data {
+		if _, _, _, err := parseExternalSecretData(data); err != nil {
+			return err
+		}
+	}
+	if len(o.Store) == 0 {
+		return fmt.Errorf("--store or --provider is required")
+	}
+	if o.RefreshInterval < 0 {
+		return fmt.Errorf("--refresh-interval must be a positive duration")
+	}
+
+	if !o.CreateStore {
+		if len(o.Server) > 0 || len(o.Role) > 0 || len(o.Region) > 0 || len(o.TenantID) > 0 || len(o.ServiceAccount) > 0 {
+			return fmt.Errorf("--server, --role, --region, --tenant-id and --service-account may only be used with --create-store")
+		}
+		return nil
+	}
+
+	switch o.Provider {
+	case "vault":
+		if len(o.Server) == 0 || len(o.Role) == 0 {
+			return fmt.Errorf("--server and --role are required to create a vault secret store")
+		}
+		if len(o.Region) > 0 || len(o.TenantID) > 0 {
+			return fmt.Errorf("--region and --tenant-id may not be used with a vault secret store")
+		}
+	case "aws":
+		if len(o.Region) == 0 {
+			return fmt.Errorf("--region is required to create an aws secret store")
+		}
+		if o.AWSService != "SecretsManager" && o.AWSService != "ParameterStore" {
+			return fmt.Errorf("--aws-service must be SecretsManager or ParameterStore")
+		}
+		if len(o.Server) > 0 || len(o.TenantID) > 0 {
+			return fmt.Errorf("--server and --tenant-id may not be used with an aws secret store")
+		}
+	case "azure":
+		if len(o.Server) == 0 || len(o.ServiceAccount) == 0 {
+			return fmt.Errorf("--server and --service-account are required to create an azure secret store")
+		}
+		if len(o.Role) > 0 || len(o.Region) > 0 {
+			return fmt.Errorf("--role and --region may not be used with an azure secret store")
+		}
+	case "":
+		return fmt.Errorf("--provider is required with --create-store")
+	default:
+		return fmt.Errorf("--provider must be one of %s", strings.Join(externalSecretProviders, ", "))
+	}
+	return nil
+}
+
+func (o *CreateExternalSecretOptions) Run() error {
+	objects := []*unstructured.Unstructured{}
+	if o.CreateStore {
+		objects = append(objects, o.secretStore())
+	}
+	objects = append(objects, o.externalSecret())
+
+	for _, obj := range objects {
+		if o.CreateSubcommandOptions.EnforceNamespace && obj.GetKind() != "ClusterSecretStore" {
+			obj.SetNamespace(o.CreateSubcommandOptions.Namespace)
+		}
+		if err := util.CreateOrUpdateAnnotation(o.CreateSubcommandOptions.CreateAnnotation, obj, scheme.DefaultJSONEncoder()); err != nil {
+			return err
+		}
+
+		if o.CreateSubcommandOptions.DryRunStrategy != cmdutil.DryRunClient {
+			createOptions := metav1.CreateOptions{}
+			if o.CreateSubcommandOptions.DryRunStrategy == cmdutil.DryRunServer {
+				createOptions.DryRun = []string{metav1.DryRunAll}
+			}
+			gvr := externalSecretsGroupVersion.WithResource(strings.ToLower(obj.GetKind()) + "s")
+			var client dynamic.ResourceInterface = o.Client.Resource(gvr)
+			if obj.GetKind() != "ClusterSecretStore" {
+				client = o.Client.Resource(gvr).Namespace(o.CreateSubcommandOptions.Namespace)
+			}
+			created, err := client.Create(context.TODO(), obj, createOptions)
+			if err != nil {
+				return err
+			}
+			obj = created
+		}
+
+		if err := o.CreateSubcommandOptions.Printer.PrintObj(obj, o.CreateSubcommandOptions.Out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeKind returns the kind of the secret store the external secret reads from.
+func (o *CreateExternalSecretOptions) storeKind() string {
+	if o.ClusterStore {
+		return "ClusterSecretStore"
+	}
+	return "SecretStore"
+}
+
+func (o *CreateExternalSecretOptions) externalSecret() *unstructured.Unstructured {
+	target := o.Target
+	if len(target) == 0 {
+		target = o.CreateSubcommandOptions.Name
+	}
+	spec := map[string]interface{}{
+		"refreshInterval": o.RefreshInterval.String(),
+		"secretStoreRef": map[string]interface{}{
+			"name": o.Store,
+			"kind": o.storeKind(),
+		},
+		"target": map[string]interface{}{
+			"name":           target,
+			"creationPolicy": "Owner",
+		},
+	}
+
+	if len(o.Data) > 0 {
+		data := []interface{}{}
+		for _, d := range o.Data {
+			// validated before
+			secretKey, remoteKey, property, _ := parseExternalSecretData(d)
+			remoteRef := map[string]interface{}{"key": remoteKey}
+			if len(property) > 0 {
+				remoteRef["property"] = property
+			}
+			data = append(data, map[string]interface{}{"secretKey": secretKey, "remoteRef": remoteRef})
+		}
+		spec["data"] = data
+	}
+	if len(o.DataFrom) > 0 {
+		dataFrom := []interface{}{}
+		for _, key := range o.DataFrom {
+			dataFrom = append(dataFrom, map[string]interface{}{"extract": map[string]interface{}{"key": key}})
+		}
+		spec["dataFrom"] = dataFrom
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": externalSecretsGroupVersion.String(),
+		"kind":       "ExternalSecret",
+		"metadata":   map[string]interface{}{"name": o.CreateSubcommandOptions.Name},
+		"spec":       spec,
+	}}
+}
+
+func (o *CreateExternalSecretOptions) secretStore() *unstructured.Unstructured {
+	var serviceAccountRef map[string]interface{}
+	if len(o.ServiceAccount) > 0 {
+		serviceAccountRef = map[string]interface{}{"name": o.ServiceAccount}
+		// cluster secret stores are used from any namespace
+		if o.ClusterStore {
+			serviceAccountRef["namespace"] = o.CreateSubcommandOptions.Namespace
+		}
+	}
+
+	var provider map[string]interface{}
+	switch o.Provider {
+	case "vault":
+		kubernetesAuth := map[string]interface{}{
+			"mountPath": o.AuthMount,
+			"role":      o.Role,
+		}
+		if serviceAccountRef != nil {
+			kubernetesAuth["serviceAccountRef"] = serviceAccountRef
+		}
+		provider = map[string]interface{}{"vault": map[string]interface{}{
+			"server":  o.Server,
+			"path":    o.Path,
+			"version": "v2",
+			"auth":    map[string]interface{}{"kubernetes": kubernetesAuth},
+		}}
+	case "aws":
+		aws := map[string]interface{}{
+			"service": o.AWSService,
+			"region":  o.Region,
+		}
+		if len(o.Role) > 0 {
+			aws["role"] = o.Role
+		}
+		if serviceAccountRef != nil {
+			aws["auth"] = map[string]interface{}{"jwt": map[string]interface{}{"serviceAccountRef": serviceAccountRef}}
+		}
+		provider = map[string]interface{}{"aws": aws}
+	case "azure":
+		azure := map[string]interface{}{
+			"vaultUrl":          o.Server,
+			"authType":          "WorkloadIdentity",
+			"serviceAccountRef": serviceAccountRef,
+		}
+		if len(o.TenantID) > 0 {
+			azure["tenantId"] = o.TenantID
+		}
+		provider = map[string]interface{}{"azurekv": azure}
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": externalSecretsGroupVersion.String(),
+		"kind":       o.storeKind(),
+		"metadata":   map[string]interface{}{"name": o.Store},
+		"spec":       map[string]interface{}{"provider": provider},
+	}}
+}
+
+// parseExternalSecretData parses a KEY=REMOTE_KEY[#PROPERTY] data source of an external secret.
+func parseExternalSecretData(data string) (string, string, string, error) {
+	parts := strings.SplitN(data, "=", 2)
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", "", fmt.Errorf("--data %q must be of the form KEY=REMOTE_KEY[#PROPERTY]", data)
+	}
+	remoteKey, property := parts[1], ""
+	if pos := strings.LastIndex(remoteKey, "#"); pos != -1 {
+		remoteKey, property = remoteKey[:pos], remoteKey[pos+1:]
+		if len(remoteKey) == 0 || len(property) == 0 {
+			return "", "", "", fmt.Errorf("--data %q must be of the form KEY=REMOTE_KEY[#PROPERTY]", data)
+		}
+	}
+	return parts[0], remoteKey, property, nil
+}
@@ -0,0 +1,162 @@
+package create
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func TestCreateExternalSecretValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		o    CreateExternalSecretOptions
+		err  string
+	}{
+		{
+			name: "existing store",
+			o:    CreateExternalSecretOptions{Store: "vault", DataFrom: []string{"db/creds"}},
+		},
+		{
+			name: "no data",
+			o:    CreateExternalSecretOptions{Store: "vault"},
+			err:  "at least one --data or --data-from is required",
+		},
+		{
+			name: "invalid data",
+			o:    CreateExternalSecretOptions{Store: "vault", Data: []string{"password=db/creds#"}},
+			err:  "must be of the form KEY=REMOTE_KEY[#PROPERTY]",
+		},
+		{
+			name: "no store",
+			o:    CreateExternalSecretOptions{DataFrom: []string{"db/creds"}},
+			err:  "--store or --provider is required",
+		},
+		{
+			name: "store settings without creating it",
+			o:    CreateExternalSecretOptions{Store: "vault", DataFrom: []string{"db/creds"}, Server: "https://vault:8200"},
+			err:  "may only be used with --create-store",
+		},
+		{
+			name: "vault store",
+			o:    CreateExternalSecretOptions{Store: "vault", Provider: "vault", CreateStore: true, DataFrom: []string{"db/creds"}, Server: "https://vault:8200", Role: "app"},
+		},
+		{
+			name: "vault store without role",
+			o:    CreateExternalSecretOptions{Store: "vault", Provider: "vault", CreateStore: true, DataFrom: []string{"db/creds"}, Server: "https://vault:8200"},
+			err:  "--server and --role are required",
+		},
+		{
+			name: "aws store with invalid service",
+			o:    CreateExternalSecretOptions{Store: "aws", Provider: "aws", CreateStore: true, DataFrom: []string{"prod/app"}, Region: "us-east-1", AWSService: "S3"},
+			err:  "--aws-service must be SecretsManager or ParameterStore",
+		},
+		{
+			name: "azure store without service account",
+			o:    CreateExternalSecretOptions{Store: "azure", Provider: "azure", CreateStore: true, DataFrom: []string{"api-key"}, Server: "https://myvault.vault.azure.net"},
+			err:  "--server and --service-account are required",
+		},
+		{
+			name: "unknown provider",
+			o:    CreateExternalSecretOptions{Store: "gcp", Provider: "gcp", CreateStore: true, DataFrom: []string{"api-key"}},
+			err:  "--provider must be one of vault, aws, azure",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.o.Validate()
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestCreateExternalSecret(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	out := &bytes.Buffer{}
+	o := &CreateExternalSecretOptions{
+		CreateSubcommandOptions: &CreateSubcommandOptions{
+			Name:           "db-creds",
+			Namespace:      "test",
+			DryRunStrategy: cmdutil.DryRunNone,
+			Printer:        &printers.NamePrinter{},
+			IOStreams:      genericclioptions.IOStreams{Out: out},
+		},
+		Data:            []string{"password=db/creds#password", "username=db/creds#user"},
+		DataFrom:        []string{"db/settings"},
+		RefreshInterval: 15 * time.Minute,
+		Store:           "vault",
+		ClusterStore:    true,
+		CreateStore:     true,
+		Provider:        "vault",
+		Server:          "https://vault:8200",
+		Path:            "secret",
+		Role:            "app",
+		AuthMount:       "kubernetes",
+		ServiceAccount:  "app",
+		Client:          client,
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "clustersecretstore.external-secrets.io/vault\nexternalsecret.external-secrets.io/db-creds\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	store, err := client.Resource(externalSecretsGroupVersion.WithResource("clustersecretstores")).Get(context.TODO(), "vault", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	auth, _, _ := unstructured.NestedMap(store.Object, "spec", "provider", "vault", "auth", "kubernetes")
+	expectedAuth := map[string]interface{}{
+		"mountPath":         "kubernetes",
+		"role":              "app",
+		"serviceAccountRef": map[string]interface{}{"name": "app", "namespace": "test"},
+	}
+	if !equality.Semantic.DeepEqual(auth, expectedAuth) {
+		t.Errorf("unexpected vault auth %v", auth)
+	}
+
+	externalSecret, err := client.Resource(externalSecretsGroupVersion.WithResource("externalsecrets")).Namespace("test").Get(context.TODO(), "db-creds", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if kind, _, _ := unstructured.NestedString(externalSecret.Object, "spec", "secretStoreRef", "kind"); kind != "ClusterSecretStore" {
+		t.Errorf("expected a reference to a ClusterSecretStore, got %q", kind)
+	}
+	if target, _, _ := unstructured.NestedString(externalSecret.Object, "spec", "target", "name"); target != "db-creds" {
+		t.Errorf("expected the secret to be named after the external secret, got %q", target)
+	}
+	data, _, _ := unstructured.NestedSlice(externalSecret.Object, "spec", "data")
+	expectedData := []interface{}{
+		map[string]interface{}{"secretKey": "password", "remoteRef": map[string]interface{}{"key": "db/creds", "property": "password"}},
+		map[string]interface{}{"secretKey": "username", "remoteRef": map[string]interface{}{"key": "db/creds", "property": "user"}},
+	}
+	if !equality.Semantic.DeepEqual(data, expectedData) {
+		t.Errorf("unexpected data %v", data)
+	}
+	dataFrom, _, _ := unstructured.NestedSlice(externalSecret.Object, "spec", "dataFrom")
+	if len(dataFrom) != 1 {
+		t.Errorf("unexpected data from %v", dataFrom)
+	}
+}
@@ -23,17 +23,42 @@ var (
 
 		Three types of secured routes are supported: edge, passthrough, and reencrypt.
 		If you want to create unsecured routes, see "oc expose -h".
+
+		The certificate and key of an existing route can be renewed with --renew-cert, from files
+		or from a TLS secret. The new certificate is validated before the route is updated: it must
+		match its key, be valid now and for the host of the route, and be signed by the CA certificate.
+	`)
+
+	routeExample = templates.Examples(`
+		# Renew the certificate and key of the route my-route
+		oc create route my-route --renew-cert --cert=tls.crt --key=tls.key
+
+		# Renew the certificate of the route my-route from the TLS secret my-route-tls
+		oc create route my-route --renew-cert --from-secret=my-route-tls
 	`)
 )
 
 // NewCmdCreateRoute is a macro command to create a secured route.
 func NewCmdCreateRoute(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	renew := NewRenewRouteCertOptions(streams)
+	renewCert := false
 	cmd := &cobra.Command{
-		Use:   "route",
-		Short: "Expose containers externally via secured routes",
-		Long:  routeLong,
-		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+		Use:     "route",
+		Short:   "Expose containers externally via secured routes",
+		Long:    routeLong,
+		Example: routeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !renewCert {
+				kcmdutil.DefaultSubCommandRun(streams.ErrOut)(cmd, args)
+				return
+			}
+			kcmdutil.CheckErr(renew.Complete(f, cmd, args))
+			kcmdutil.CheckErr(renew.Validate())
+			kcmdutil.CheckErr(renew.Run())
+		},
 	}
+	cmd.Flags().BoolVar(&renewCert, "renew-cert", renewCert, "Replace the certificate and key of the existing route NAME")
+	renew.AddFlags(cmd)
 
 	cmd.AddCommand(NewCmdCreateEdgeRoute(f, streams))
 	cmd.AddCommand(NewCmdCreatePassthroughRoute(f, streams))
@@ -0,0 +1,163 @@
+package route
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	routev1 "github.com/openshift/api/route/v1"
+	fileutil "github.com/openshift/oc/pkg/helpers/file"
+)
+
+// HSTSAnnotation is the annotation of the routes setting the Strict-Transport-Security header of their responses.
+const HSTSAnnotation = "haproxy.router.openshift.io/hsts_header"
+
+// HSTSOptions are the HTTP Strict Transport Security settings of a route.
+type HSTSOptions struct {
+	MaxAge            time.Duration
+	IncludeSubdomains bool
+	Preload           bool
+}
+
+// AddFlags adds the HSTS flags to the command.
+func (o *HSTSOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&o.MaxAge, "hsts-max-age", o.MaxAge, "Set the max-age of the HTTP Strict Transport Security header of the route, like 8760h for a year; requires edge or reencrypt termination")
+	cmd.Flags().BoolVar(&o.IncludeSubdomains, "hsts-include-subdomains", o.IncludeSubdomains, "Apply the HTTP Strict Transport Security header of the route to the subdomains of its host")
+	cmd.Flags().BoolVar(&o.Preload, "hsts-preload", o.Preload, "Allow browsers to preload the HTTP Strict Transport Security header of the route")
+}
+
+// Header returns the value of the HSTS annotation, or an empty string if HSTS is not set.
+func (o *HSTSOptions) Header() string {
+	if o.MaxAge <= 0 {
+		return ""
+	}
+	header := fmt.Sprintf("max-age=%d", int64(o.MaxAge/time.Second))
+	if o.IncludeSubdomains {
+		header += ";includeSubDomains"
+	}
+	if o.Preload {
+		header += ";preload"
+	}
+	return header
+}
+
+// Apply sets the HSTS annotation of the route, which must terminate TLS at the router.
+func (o *HSTSOptions) Apply(route *routev1.Route) error {
+	if o.MaxAge < 0 {
+		return fmt.Errorf("--hsts-max-age must be a positive duration")
+	}
+	header := o.Header()
+	if len(header) == 0 {
+		if o.IncludeSubdomains || o.Preload {
+			return fmt.Errorf("--hsts-include-subdomains and --hsts-preload require --hsts-max-age")
+		}
+		return nil
+	}
+	if route.Spec.TLS == nil || route.Spec.TLS.Termination == routev1.TLSTerminationPassthrough {
+		return fmt.Errorf("HTTP Strict Transport Security requires edge or reencrypt termination")
+	}
+	if route.Annotations == nil {
+		route.Annotations = map[string]string{}
+	}
+	route.Annotations[HSTSAnnotation] = header
+	return nil
+}
+
+// TLSFiles are the files of the certificates and key of a route.
+type TLSFiles struct {
+	Cert       string
+	Key        string
+	CACert     string
+	DestCACert string
+}
+
+// Load sets the certificates and key read from the files in the TLS configuration.
+func (f *TLSFiles) Load(config *routev1.TLSConfig) error {
+	for _, file := range []struct {
+		path  string
+		field *string
+	}{
+		{f.Cert, &config.Certificate},
+		{f.Key, &config.Key},
+		{f.CACert, &config.CACertificate},
+		{f.DestCACert, &config.DestinationCACertificate},
+	} {
+		data, err := fileutil.LoadData(file.path)
+		if err != nil {
+			return err
+		}
+		*file.field = string(data)
+	}
+	return nil
+}
+
+// ValidateTLS validates the certificate of the route before it is created: it must match its key, be valid now,
+// be signed through the CA certificate if one is given, and be valid for the host of the route if one is set.
+func ValidateTLS(route *routev1.Route, now time.Time) error {
+	config := route.Spec.TLS
+	if config == nil {
+		return nil
+	}
+	if config.Termination == routev1.TLSTerminationPassthrough {
+		if len(config.Certificate) > 0 || len(config.Key) > 0 || len(config.CACertificate) > 0 || len(config.DestinationCACertificate) > 0 {
+			return fmt.Errorf("passthrough routes can't have certificates, the TLS connections are terminated by the service")
+		}
+		return nil
+	}
+	if len(config.DestinationCACertificate) > 0 && config.Termination != routev1.TLSTerminationReencrypt {
+		return fmt.Errorf("a destination CA certificate can only be set with reencrypt termination")
+	}
+	if len(config.Certificate) == 0 && len(config.Key) == 0 {
+		if len(config.CACertificate) > 0 {
+			return fmt.Errorf("a CA certificate requires a certificate and a key")
+		}
+		// the default certificate of the router is used
+		return nil
+	}
+	if len(config.Certificate) == 0 || len(config.Key) == 0 {
+		return fmt.Errorf("both a certificate and a key are required")
+	}
+
+	pair, err := tls.X509KeyPair([]byte(config.Certificate), []byte(config.Key))
+	if err != nil {
+		return fmt.Errorf("invalid certificate or key: %v", err)
+	}
+	chain := make([]*x509.Certificate, 0, len(pair.Certificate))
+	for _, der := range pair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("invalid certificate: %v", err)
+		}
+		chain = append(chain, cert)
+	}
+	leaf := chain[0]
+	if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("the certificate is not valid before %s", leaf.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("the certificate expired on %s", leaf.NotAfter.Format(time.RFC3339))
+	}
+	if host := route.Spec.Host; len(host) > 0 {
+		if err := leaf.VerifyHostname(host); err != nil {
+			return fmt.Errorf("the certificate is not valid for the host of the route: %v", err)
+		}
+	}
+
+	if len(config.CACertificate) > 0 {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(config.CACertificate)) {
+			return fmt.Errorf("the CA certificate contains no valid certificate")
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range chain[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: now}); err != nil {
+			return fmt.Errorf("the certificate chain is not valid: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package route
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	routev1 "github.com/openshift/api/route/v1"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM string
+	keyPEM  string
+}
+
+func newTestCert(t *testing.T, host string, notAfter time.Time, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    notAfter.Add(-48 * time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		template.DNSNames = []string{host}
+		signer, signerKey = parent.cert, parent.key
+	} else {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+func TestValidateTLS(t *testing.T) {
+	now := time.Now()
+	ca := newTestCert(t, "ca", now.Add(24*time.Hour), nil)
+	otherCA := newTestCert(t, "other-ca", now.Add(24*time.Hour), nil)
+	cert := newTestCert(t, "www.example.com", now.Add(24*time.Hour), ca)
+	expired := newTestCert(t, "www.example.com", now.Add(-time.Hour), ca)
+
+	tests := []struct {
+		name   string
+		host   string
+		config routev1.TLSConfig
+		err    string
+	}{
+		{
+			name:   "default certificate",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationEdge},
+		},
+		{
+			name:   "valid chain",
+			host:   "www.example.com",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: cert.certPEM, Key: cert.keyPEM, CACertificate: ca.certPEM},
+		},
+		{
+			name:   "key mismatch",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: cert.certPEM, Key: ca.keyPEM},
+			err:    "invalid certificate or key",
+		},
+		{
+			name:   "missing key",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: cert.certPEM},
+			err:    "both a certificate and a key are required",
+		},
+		{
+			name:   "wrong host",
+			host:   "www.example.org",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: cert.certPEM, Key: cert.keyPEM},
+			err:    "not valid for the host",
+		},
+		{
+			name:   "expired",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: expired.certPEM, Key: expired.keyPEM},
+			err:    "expired",
+		},
+		{
+			name:   "other CA",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationReencrypt, Certificate: cert.certPEM, Key: cert.keyPEM, CACertificate: otherCA.certPEM},
+			err:    "chain is not valid",
+		},
+		{
+			name:   "destination CA with edge",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, DestinationCACertificate: ca.certPEM},
+			err:    "only be set with reencrypt",
+		},
+		{
+			name:   "passthrough with certificate",
+			config: routev1.TLSConfig{Termination: routev1.TLSTerminationPassthrough, Certificate: cert.certPEM},
+			err:    "passthrough routes can't have certificates",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			route := &routev1.Route{Spec: routev1.RouteSpec{Host: test.host, TLS: &test.config}}
+			err := ValidateTLS(route, now)
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestHSTSOptionsApply(t *testing.T) {
+	route := &routev1.Route{Spec: routev1.RouteSpec{TLS: &routev1.TLSConfig{Termination: routev1.TLSTerminationEdge}}}
+	hsts := HSTSOptions{MaxAge: 365 * 24 * time.Hour, IncludeSubdomains: true, Preload: true}
+	if err := hsts.Apply(route); err != nil {
+		t.Fatal(err)
+	}
+	if header := route.Annotations[HSTSAnnotation]; header != "max-age=31536000;includeSubDomains;preload" {
+		t.Errorf("unexpected HSTS header %q", header)
+	}
+
+	if err := (&HSTSOptions{Preload: true}).Apply(route); err == nil {
+		t.Errorf("expected --hsts-preload to require --hsts-max-age")
+	}
+	route.Spec.TLS.Termination = routev1.TLSTerminationPassthrough
+	if err := hsts.Apply(route); err == nil {
+		t.Errorf("expected HSTS to be rejected with passthrough termination")
+	}
+}
@@ -3,6 +3,7 @@ package create
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -16,9 +17,8 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	routev1 "github.com/openshift/api/route/v1"
-	"github.com/openshift/oc/pkg/cli/create/route"
+	routehelpers "github.com/openshift/oc/pkg/cli/create/route"
 	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
-	fileutil "github.com/openshift/oc/pkg/helpers/file"
 )
 
 var (
@@ -36,6 +36,10 @@ var (
 		# Create an edge route that exposes the frontend service and specify a path
 		# If the route name is omitted, the service name will be used
 		oc create route edge --service=frontend --path /assets
+
+		# Create an edge route with the certificate of its host, which must match its key and be signed by the CA
+		# certificate, asking browsers to only use HTTPS for a year
+		oc create route edge --service=frontend --hostname=www.example.com --cert=tls.crt --key=tls.key --ca-cert=ca.crt --hsts-max-age=8760h
 	`)
 )
 
@@ -51,6 +55,7 @@ type CreateEdgeRouteOptions struct {
 	Key            string
 	CACert         string
 	WildcardPolicy string
+	HSTS           routehelpers.HSTSOptions
 }
 
 // NewCmdCreateEdgeRoute is a macro command to create an edge route.
@@ -83,6 +88,8 @@ func NewCmdCreateEdgeRoute(f kcmdutil.Factory, streams genericclioptions.IOStrea
 	cmd.MarkFlagFilename("ca-cert")
 	cmd.Flags().StringVar(&o.WildcardPolicy, "wildcard-policy", o.WildcardPolicy, "Sets the WilcardPolicy for the hostname, the default is \"None\". valid values are \"None\" and \"Subdomain\"")
 
+	o.HSTS.AddFlags(cmd)
+
 	kcmdutil.AddValidateFlags(cmd)
 	o.CreateRouteSubcommandOptions.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
@@ -99,7 +106,7 @@ func (o *CreateEdgeRouteOptions) Run() error {
 	if err != nil {
 		return err
 	}
-	route, err := route.UnsecuredRoute(o.CreateRouteSubcommandOptions.CoreClient, o.CreateRouteSubcommandOptions.Namespace, o.CreateRouteSubcommandOptions.Name, serviceName, o.Port, false, o.CreateRouteSubcommandOptions.EnforceNamespace)
+	route, err := routehelpers.UnsecuredRoute(o.CreateRouteSubcommandOptions.CoreClient, o.CreateRouteSubcommandOptions.Namespace, o.CreateRouteSubcommandOptions.Name, serviceName, o.Port, false, o.CreateRouteSubcommandOptions.EnforceNamespace)
 	if err != nil {
 		return err
 	}
@@ -113,25 +120,20 @@ func (o *CreateEdgeRouteOptions) Run() error {
 
 	route.Spec.TLS = new(routev1.TLSConfig)
 	route.Spec.TLS.Termination = routev1.TLSTerminationEdge
-	cert, err := fileutil.LoadData(o.Cert)
-	if err != nil {
+	files := routehelpers.TLSFiles{Cert: o.Cert, Key: o.Key, CACert: o.CACert}
+	if err := files.Load(route.Spec.TLS); err != nil {
 		return err
 	}
-	route.Spec.TLS.Certificate = string(cert)
-	key, err := fileutil.LoadData(o.Key)
-	if err != nil {
-		return err
-	}
-	route.Spec.TLS.Key = string(key)
-	caCert, err := fileutil.LoadData(o.CACert)
-	if err != nil {
-		return err
-	}
-	route.Spec.TLS.CACertificate = string(caCert)
 
 	if len(o.InsecurePolicy) > 0 {
 		route.Spec.TLS.InsecureEdgeTerminationPolicy = routev1.InsecureEdgeTerminationPolicyType(o.InsecurePolicy)
 	}
+	if err := o.HSTS.Apply(route); err != nil {
+		return err
+	}
+	if err := routehelpers.ValidateTLS(route, time.Now()); err != nil {
+		return err
+	}
 
 	if err := util.CreateOrUpdateAnnotation(o.CreateRouteSubcommandOptions.CreateAnnotation, route, scheme.DefaultJSONEncoder()); err != nil {
 		return err
@@ -2,6 +2,7 @@ package create
 
 import (
 	"context"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -13,8 +14,7 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	routev1 "github.com/openshift/api/route/v1"
-	"github.com/openshift/oc/pkg/cli/create/route"
-	fileutil "github.com/openshift/oc/pkg/helpers/file"
+	routehelpers "github.com/openshift/oc/pkg/cli/create/route"
 )
 
 var (
@@ -52,6 +52,7 @@ type CreateReencryptRouteOptions struct {
 	CACert         string
 	DestCACert     string
 	WildcardPolicy string
+	HSTS           routehelpers.HSTSOptions
 }
 
 // NewCmdCreateReencryptRoute is a macro command to create a reencrypt route.
@@ -86,6 +87,8 @@ func NewCmdCreateReencryptRoute(f kcmdutil.Factory, streams genericclioptions.IO
 	cmd.MarkFlagFilename("dest-ca-cert")
 	cmd.Flags().StringVar(&o.WildcardPolicy, "wildcard-policy", o.WildcardPolicy, "Sets the WilcardPolicy for the hostname, the default is \"None\". valid values are \"None\" and \"Subdomain\"")
 
+	o.HSTS.AddFlags(cmd)
+
 	kcmdutil.AddValidateFlags(cmd)
 	o.CreateRouteSubcommandOptions.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
@@ -102,7 +105,7 @@ func (o *CreateReencryptRouteOptions) Run() error {
 	if err != nil {
 		return err
 	}
-	route, err := route.UnsecuredRoute(o.CreateRouteSubcommandOptions.CoreClient, o.CreateRouteSubcommandOptions.Namespace, o.CreateRouteSubcommandOptions.Name, serviceName, o.Port, false, o.CreateRouteSubcommandOptions.EnforceNamespace)
+	route, err := routehelpers.UnsecuredRoute(o.CreateRouteSubcommandOptions.CoreClient, o.CreateRouteSubcommandOptions.Namespace, o.CreateRouteSubcommandOptions.Name, serviceName, o.Port, false, o.CreateRouteSubcommandOptions.EnforceNamespace)
 	if err != nil {
 		return err
 	}
@@ -123,30 +126,20 @@ func (o *CreateReencryptRouteOptions) Run() error {
 	route.Spec.TLS = new(routev1.TLSConfig)
 	route.Spec.TLS.Termination = routev1.TLSTerminationReencrypt
 
-	cert, err := fileutil.LoadData(o.Cert)
-	if err != nil {
+	files := routehelpers.TLSFiles{Cert: o.Cert, Key: o.Key, CACert: o.CACert, DestCACert: o.DestCACert}
+	if err := files.Load(route.Spec.TLS); err != nil {
 		return err
 	}
-	route.Spec.TLS.Certificate = string(cert)
-	key, err := fileutil.LoadData(o.Key)
-	if err != nil {
-		return err
+
+	if len(o.InsecurePolicy) > 0 {
+		route.Spec.TLS.InsecureEdgeTerminationPolicy = routev1.InsecureEdgeTerminationPolicyType(o.InsecurePolicy)
 	}
-	route.Spec.TLS.Key = string(key)
-	caCert, err := fileutil.LoadData(o.CACert)
-	if err != nil {
+	if err := o.HSTS.Apply(route); err != nil {
 		return err
 	}
-	route.Spec.TLS.CACertificate = string(caCert)
-	destCACert, err := fileutil.LoadData(o.DestCACert)
-	if err != nil {
+	if err := routehelpers.ValidateTLS(route, time.Now()); err != nil {
 		return err
 	}
-	route.Spec.TLS.DestinationCACertificate = string(destCACert)
-
-	if len(o.InsecurePolicy) > 0 {
-		route.Spec.TLS.InsecureEdgeTerminationPolicy = routev1.InsecureEdgeTerminationPolicyType(o.InsecurePolicy)
-	}
 
 	if err := util.CreateOrUpdateAnnotation(o.CreateRouteSubcommandOptions.CreateAnnotation, route, scheme.DefaultJSONEncoder()); err != nil {
 		return err
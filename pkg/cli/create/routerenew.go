@@ -0,0 +1,184 @@
+package create
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	routehelpers "github.com/openshift/oc/pkg/cli/create/route"
+)
+
+// secretCACertKey is the key of the CA certificate in the TLS secrets, as set by cert-manager.
+const secretCACertKey = "ca.crt"
+
+// RenewRouteCertOptions replaces the certificate and key of an existing secured route.
+type RenewRouteCertOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	Printer    printers.ResourcePrinter
+
+	Name       string
+	Cert       string
+	Key        string
+	CACert     string
+	FromSecret string
+
+	DryRunStrategy kcmdutil.DryRunStrategy
+	Namespace      string
+
+	Client     routev1client.RoutesGetter
+	CoreClient corev1client.CoreV1Interface
+
+	genericclioptions.IOStreams
+}
+
+// NewRenewRouteCertOptions returns the options of oc create route --renew-cert.
+func NewRenewRouteCertOptions(streams genericclioptions.IOStreams) *RenewRouteCertOptions {
+	return &RenewRouteCertOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("certificate renewed").WithTypeSetter(scheme.Scheme),
+		IOStreams:  streams,
+	}
+}
+
+// AddFlags adds the flags of the certificate renewal to the route command.
+func (o *RenewRouteCertOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.Cert, "cert", o.Cert, "Path to the new certificate file of the route")
+	cmd.MarkFlagFilename("cert")
+	cmd.Flags().StringVar(&o.Key, "key", o.Key, "Path to the new key file of the route")
+	cmd.MarkFlagFilename("key")
+	cmd.Flags().StringVar(&o.CACert, "ca-cert", o.CACert, "Path to the new CA certificate file of the route, the current one is kept if not set")
+	cmd.MarkFlagFilename("ca-cert")
+	cmd.Flags().StringVar(&o.FromSecret, "from-secret", o.FromSecret, "Name of a TLS secret holding the new certificate and key of the route in tls.crt and tls.key, and optionally its CA certificate in ca.crt")
+	o.PrintFlags.AddFlags(cmd)
+	kcmdutil.AddDryRunFlag(cmd)
+}
+
+func (o *RenewRouteCertOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Name, err = resolveRouteName(args)
+	if err != nil {
+		return err
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.CoreClient, err = corev1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.Client, err = routev1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	kcmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	o.Printer, err = o.PrintFlags.ToPrinter()
+	return err
+}
+
+func (o *RenewRouteCertOptions) Validate() error {
+	if len(o.Name) == 0 {
+		return fmt.Errorf("the name of the route to renew the certificate of is required")
+	}
+	fromFiles := len(o.Cert) > 0 || len(o.Key) > 0 || len(o.CACert) > 0
+	switch {
+	case fromFiles && len(o.FromSecret) > 0:
+		return fmt.Errorf("--from-secret can't be used with --cert, --key or --ca-cert")
+	case len(o.FromSecret) > 0:
+	case len(o.Cert) == 0 || len(o.Key) == 0:
+		return fmt.Errorf("--cert and --key, or --from-secret, are required to renew the certificate")
+	}
+	return nil
+}
+
+func (o *RenewRouteCertOptions) Run() error {
+	route, err := o.Client.Routes(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if route.Spec.TLS == nil || route.Spec.TLS.Termination == routev1.TLSTerminationPassthrough {
+		return fmt.Errorf("route %q doesn't terminate TLS at the router and has no certificate to renew", o.Name)
+	}
+
+	renewed, err := o.loadCertificate()
+	if err != nil {
+		return err
+	}
+	updated := route.DeepCopy()
+	updated.Spec.TLS.Certificate = renewed.Certificate
+	updated.Spec.TLS.Key = renewed.Key
+	if len(renewed.CACertificate) > 0 {
+		updated.Spec.TLS.CACertificate = renewed.CACertificate
+	}
+	if err := routehelpers.ValidateTLS(updated, time.Now()); err != nil {
+		return fmt.Errorf("the new certificate of route %q is not valid: %v", o.Name, err)
+	}
+
+	if o.DryRunStrategy == kcmdutil.DryRunClient {
+		return o.Printer.PrintObj(updated, o.Out)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"tls": map[string]interface{}{
+				"certificate":   updated.Spec.TLS.Certificate,
+				"key":           updated.Spec.TLS.Key,
+				"caCertificate": updated.Spec.TLS.CACertificate,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	options := metav1.PatchOptions{}
+	if o.DryRunStrategy == kcmdutil.DryRunServer {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	updated, err = o.Client.Routes(o.Namespace).Patch(context.TODO(), o.Name, types.MergePatchType, patch, options)
+	if err != nil {
+		return err
+	}
+	return o.Printer.PrintObj(updated, o.Out)
+}
+
+// loadCertificate reads the new certificate, key and CA certificate from the files or the secret.
+func (o *RenewRouteCertOptions) loadCertificate() (*routev1.TLSConfig, error) {
+	config := &routev1.TLSConfig{}
+	if len(o.FromSecret) == 0 {
+		files := routehelpers.TLSFiles{Cert: o.Cert, Key: o.Key, CACert: o.CACert}
+		return config, files.Load(config)
+	}
+	secret, err := o.CoreClient.Secrets(o.Namespace).Get(context.TODO(), o.FromSecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(secret.Data[corev1.TLSCertKey]) == 0 || len(secret.Data[corev1.TLSPrivateKeyKey]) == 0 {
+		return nil, fmt.Errorf("secret %q has no %s or %s", o.FromSecret, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+	}
+	config.Certificate = string(secret.Data[corev1.TLSCertKey])
+	config.Key = string(secret.Data[corev1.TLSPrivateKeyKey])
+	config.CACertificate = string(secret.Data[secretCACertKey])
+	return config, nil
+}
@@ -0,0 +1,159 @@
+package create
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	routev1 "github.com/openshift/api/route/v1"
+	routefake "github.com/openshift/client-go/route/clientset/versioned/fake"
+)
+
+// selfSignedCert returns a PEM self-signed certificate and key for the host, valid until notAfter.
+func selfSignedCert(t *testing.T, host string, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestRenewRouteCert(t *testing.T) {
+	now := time.Now()
+	oldCert, oldKey := selfSignedCert(t, "www.example.com", now.Add(time.Hour))
+	newCert, newKey := selfSignedCert(t, "www.example.com", now.Add(90*24*time.Hour))
+	wrongCert, wrongKey := selfSignedCert(t, "www.example.org", now.Add(90*24*time.Hour))
+	expiredCert, expiredKey := selfSignedCert(t, "www.example.com", now.Add(-time.Hour))
+
+	tests := []struct {
+		name    string
+		secret  map[string][]byte
+		route   routev1.TLSConfig
+		err     string
+		renewed bool
+	}{
+		{
+			name:    "renewed",
+			secret:  map[string][]byte{corev1.TLSCertKey: []byte(newCert), corev1.TLSPrivateKeyKey: []byte(newKey)},
+			route:   routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: oldCert, Key: oldKey},
+			renewed: true,
+		},
+		{
+			name:   "wrong host",
+			secret: map[string][]byte{corev1.TLSCertKey: []byte(wrongCert), corev1.TLSPrivateKeyKey: []byte(wrongKey)},
+			route:  routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: oldCert, Key: oldKey},
+			err:    "not valid for the host",
+		},
+		{
+			name:   "expired",
+			secret: map[string][]byte{corev1.TLSCertKey: []byte(expiredCert), corev1.TLSPrivateKeyKey: []byte(expiredKey)},
+			route:  routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: oldCert, Key: oldKey},
+			err:    "expired",
+		},
+		{
+			name:   "key mismatch",
+			secret: map[string][]byte{corev1.TLSCertKey: []byte(newCert), corev1.TLSPrivateKeyKey: []byte(oldKey)},
+			route:  routev1.TLSConfig{Termination: routev1.TLSTerminationEdge, Certificate: oldCert, Key: oldKey},
+			err:    "invalid certificate or key",
+		},
+		{
+			name:   "passthrough",
+			secret: map[string][]byte{corev1.TLSCertKey: []byte(newCert), corev1.TLSPrivateKeyKey: []byte(newKey)},
+			route:  routev1.TLSConfig{Termination: routev1.TLSTerminationPassthrough},
+			err:    "no certificate to renew",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			routeClient := routefake.NewSimpleClientset(&routev1.Route{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-route", Namespace: "test"},
+				Spec:       routev1.RouteSpec{Host: "www.example.com", TLS: &test.route},
+			})
+			kubeClient := kubefake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-route-tls", Namespace: "test"},
+				Type:       corev1.SecretTypeTLS,
+				Data:       test.secret,
+			})
+			o := &RenewRouteCertOptions{
+				Name:       "my-route",
+				FromSecret: "my-route-tls",
+				Namespace:  "test",
+				Client:     routeClient.RouteV1(),
+				CoreClient: kubeClient.CoreV1(),
+				Printer:    printers.NewDiscardingPrinter(),
+				IOStreams:  genericclioptions.NewTestIOStreamsDiscard(),
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			err := o.Run()
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error %q, got %v", test.err, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			route, err := routeClient.RouteV1().Routes("test").Get(context.TODO(), "my-route", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if renewed := route.Spec.TLS.Certificate == newCert && route.Spec.TLS.Key == newKey; renewed != test.renewed {
+				t.Errorf("expected the certificate to be renewed: %t, got %t", test.renewed, renewed)
+			}
+		})
+	}
+}
+
+func TestRenewRouteCertValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		o    RenewRouteCertOptions
+		err  string
+	}{
+		{name: "files", o: RenewRouteCertOptions{Name: "r", Cert: "tls.crt", Key: "tls.key"}},
+		{name: "secret", o: RenewRouteCertOptions{Name: "r", FromSecret: "tls"}},
+		{name: "no name", o: RenewRouteCertOptions{Cert: "tls.crt", Key: "tls.key"}, err: "name of the route"},
+		{name: "no key", o: RenewRouteCertOptions{Name: "r", Cert: "tls.crt"}, err: "are required"},
+		{name: "files and secret", o: RenewRouteCertOptions{Name: "r", Cert: "tls.crt", FromSecret: "tls"}, err: "can't be used with"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.o.Validate()
+			if len(test.err) == 0 && err != nil || len(test.err) > 0 && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Errorf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package create
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc/pkg/helpers/env"
+)
+
+const createSecretSOPSExample = `
+  # Create a new secret named my-secret from the keys of a SOPS encrypted dotenv, YAML or JSON file,
+  # decrypted with the age key of SOPS_AGE_KEY_FILE
+  oc create secret generic my-secret --from-sops-file=secrets.enc.yaml
+
+  # Create a new secret named my-secret with the decrypted contents of a SOPS encrypted file as key ssh-privatekey
+  oc create secret generic my-secret --from-sops-file=ssh-privatekey=id_rsa.enc`
+
+// CreateSecretSOPSOptions decrypts SOPS encrypted files for the kubectl create secret generic command, whose
+// contents are passed to it as literal sources so that they are never written to disk in plaintext.
+type CreateSecretSOPSOptions struct {
+	SOPSFileSources []string
+
+	// Decrypt returns the decrypted contents of a SOPS encrypted file, it runs the sops binary by default.
+	Decrypt func(path string) ([]byte, error)
+}
+
+// AddCreateSecretSOPSFlags adds --from-sops-file to the kubectl create secret generic command.
+func AddCreateSecretSOPSFlags(cmd *cobra.Command) {
+	o := &CreateSecretSOPSOptions{Decrypt: decryptSOPSFile}
+
+	cmd.Example += "\n" + createSecretSOPSExample
+	cmd.Flags().StringArrayVar(&o.SOPSFileSources, "from-sops-file", o.SOPSFileSources, "Specify a SOPS encrypted file to decrypt with the sops binary, as [key=]source. Without a key, each entry of a dotenv, YAML or JSON file becomes a key of the secret and other files are stored under their base name.")
+
+	delegate := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if len(o.SOPSFileSources) > 0 {
+			kcmdutil.CheckErr(o.Validate(cmd))
+			kcmdutil.CheckErr(o.AddLiteralSources(cmd))
+		}
+		delegate(cmd, args)
+	}
+}
+
+// Validate ensures that the SOPS files can be combined with the other sources of the secret.
+func (o *CreateSecretSOPSOptions) Validate(cmd *cobra.Command) error {
+	if len(kcmdutil.GetFlagStringSlice(cmd, "from-env-file")) > 0 {
+		return fmt.Errorf("from-sops-file cannot be combined with from-env-file")
+	}
+	return nil
+}
+
+// AddLiteralSources decrypts the SOPS files and adds their entries to the literal sources of the command.
+func (o *CreateSecretSOPSOptions) AddLiteralSources(cmd *cobra.Command) error {
+	for _, source := range o.SOPSFileSources {
+		data, err := o.decryptSource(source)
+		if err != nil {
+			return err
+		}
+		for _, entry := range data {
+			if err := cmd.Flags().Set("from-literal", entry.key+"="+entry.value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type secretEntry struct {
+	key   string
+	value string
+}
+
+// decryptSource returns the entries of the secret for a [key=]path SOPS file source.
+func (o *CreateSecretSOPSOptions) decryptSource(source string) ([]secretEntry, error) {
+	key, path := "", source
+	if pos := strings.Index(source, "="); pos != -1 {
+		key, path = source[:pos], source[pos+1:]
+		if len(key) == 0 || len(path) == 0 {
+			return nil, fmt.Errorf("invalid SOPS file source %s, expected [key=]source", source)
+		}
+	}
+
+	contents, err := o.Decrypt(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []secretEntry
+	switch {
+	case len(key) > 0:
+		entries = []secretEntry{{key: key, value: string(contents)}}
+	case filepath.Ext(path) == ".env":
+		vars, err := env.ParseDotEnv(bytes.NewReader(contents))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		for _, e := range vars {
+			entries = append(entries, secretEntry{key: e.Name, value: e.Value})
+		}
+	case filepath.Ext(path) == ".yaml" || filepath.Ext(path) == ".yml" || filepath.Ext(path) == ".json":
+		entries, err = structuredEntries(contents)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	default:
+		entries = []secretEntry{{key: sopsFileKey(path), value: string(contents)}}
+	}
+
+	// validate the keys here as invalid literal sources are reported with their values
+	for _, entry := range entries {
+		if errs := validation.IsConfigMapKey(entry.key); len(errs) > 0 {
+			return nil, fmt.Errorf("%s: %q is not a valid key name for a secret: %s", path, entry.key, strings.Join(errs, "; "))
+		}
+	}
+	return entries, nil
+}
+
+// structuredEntries returns the top level entries of a decrypted YAML or JSON document, which must all be scalars.
+func structuredEntries(contents []byte) ([]secretEntry, error) {
+	jsonContents, err := yaml.YAMLToJSON(contents)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonContents))
+	decoder.UseNumber()
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("expected a map of keys to values: %v", err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := []secretEntry{}
+	for _, key := range keys {
+		switch v := values[key].(type) {
+		case string:
+			entries = append(entries, secretEntry{key: key, value: v})
+		case json.Number, bool:
+			entries = append(entries, secretEntry{key: key, value: fmt.Sprint(v)})
+		case nil:
+			entries = append(entries, secretEntry{key: key})
+		default:
+			return nil, fmt.Errorf("the value of %q is not a scalar, use key=source to store the whole file", key)
+		}
+	}
+	return entries, nil
+}
+
+// sopsFileKey returns the key of a whole SOPS file, its base name without the conventional .enc suffix.
+func sopsFileKey(path string) string {
+	key := filepath.Base(path)
+	key = strings.Replace(key, ".enc.", ".", 1)
+	return strings.TrimSuffix(key, ".enc")
+}
+
+// decryptSOPSFile decrypts a SOPS encrypted file with the sops binary, which picks up the age, PGP or KMS keys
+// from its usual configuration and environment.
+func decryptSOPSFile(path string) ([]byte, error) {
+	sops, err := exec.LookPath("sops")
+	if err != nil {
+		return nil, fmt.Errorf("the sops binary must be installed to decrypt %s: %v", path, err)
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(sops, "--decrypt", path)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return nil, fmt.Errorf("unable to decrypt %s: %s", path, msg)
+		}
+		return nil, fmt.Errorf("unable to decrypt %s: %v", path, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -55,6 +55,7 @@ import (
 	"github.com/openshift/oc/pkg/helpers/conditions"
 	utilenv "github.com/openshift/oc/pkg/helpers/env"
 	generateapp "github.com/openshift/oc/pkg/helpers/newapp/app"
+	octerm "github.com/openshift/oc/pkg/helpers/term"
 )
 
 const (
@@ -96,6 +97,22 @@ var (
 
 		The debug pod is deleted when the remote command completes or the user interrupts
 		the shell.
+
+		Debugging a node with '--persistent' keeps a long-lived debug pod, the toolbox of the node,
+		and runs the command in it, so that the next invocations reuse the pulled image and the
+		shell history. The toolbox stops after '--ttl', and a stopped toolbox, or a toolbox running
+		another image, is replaced by a new one. When the current namespace is not privileged, the
+		toolbox runs in the namespace openshift-debug-toolbox.
+
+		A copy of a pod does not have the in-memory state of the running pod. To debug the running
+		pod itself, pass '--ephemeral' with a pod: instead of creating a copy, an ephemeral container
+		running the debug image is added to the pod, sharing the process namespace of the targeted
+		container so that its processes can be inspected. The cluster must support ephemeral
+		containers, and an ephemeral container cannot be removed from the pod once added: it stops
+		when the command completes.
+
+		The session can be recorded with '--record FILE' in the asciicast format, to be played
+		back with 'asciinema play FILE', and is ended after '--idle-timeout' without input or output.
 	`)
 
 	debugExample = templates.Examples(`
@@ -108,6 +125,12 @@ var (
 		# Debug a node as an administrator
 		oc debug node/master-1
 
+		# Debug a node in its toolbox, which is kept for the next debug sessions of the node for a day
+		oc debug node/master-1 --persistent --ttl=24h
+
+		# Debug a node, recording the session and ending it after 30 minutes without activity
+		oc debug node/master-1 --record=master-1.cast --idle-timeout=30m
+
 		# Launch a shell in a pod using the provided image stream tag
 		oc debug istag/mysql:latest -n openshift
 
@@ -117,6 +140,9 @@ var (
 		# Debug a specific failing container by running the env command in the 'second' container
 		oc debug daemonset/test -c second -- /bin/env
 
+		# Debug the 'app' container of a running pod in place, with an ephemeral container running the tools image
+		oc debug pod/mypod-9xbc --ephemeral -c app --image=registry.redhat.io/rhel8/support-tools
+
 		# See the pod that would be created to debug
 		oc debug mypod-9xbc -o yaml
 
@@ -172,6 +198,10 @@ type DebugOptions struct {
 	Image              string
 	ImageStream        string
 	ToNamespace        string
+	Ephemeral          bool
+	Persistent         bool
+	TTL                time.Duration
+	Session            octerm.SessionOptions
 
 	// IsNode is set after we see the object we're debugging.  We use it to be able to print pertinent advice.
 	IsNode bool
@@ -188,6 +218,7 @@ func NewDebugOptions(streams genericclioptions.IOStreams) *DebugOptions {
 		PrintFlags:         genericclioptions.NewPrintFlags("").WithTypeSetter(scheme.Scheme),
 		IOStreams:          streams,
 		Timeout:            15 * time.Minute,
+		TTL:                8 * time.Hour,
 		KeepInitContainers: true,
 		AsUser:             -1,
 		Attach:             *attachOpts,
@@ -248,6 +279,10 @@ func NewCmdDebug(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra
 	cmd.Flags().StringVar(&o.ImageStream, "image-stream", o.ImageStream, "Specify an image stream (namespace/name:tag) containing a debug image to run.")
 	cmd.Flags().StringVar(&o.ToNamespace, "to-namespace", o.ToNamespace, "Override the namespace to create the pod into (instead of using --namespace).")
 	cmd.Flags().BoolVar(&o.PreservePod, "preserve-pod", o.PreservePod, "If true, the pod will not be deleted after the debug command exits.")
+	cmd.Flags().BoolVar(&o.Persistent, "persistent", o.Persistent, "If true, debug a node in its toolbox, a debug pod kept and reused by the next debug sessions of the node until --ttl expires.")
+	cmd.Flags().DurationVar(&o.TTL, "ttl", o.TTL, "How long the toolbox of a node debugged with --persistent runs before it stops.")
+	o.Session.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.Ephemeral, "ephemeral", o.Ephemeral, "If true, debug the running pod by adding an ephemeral container to it, targeting the container set with -c, instead of creating a copy of the pod.")
 
 	o.PrintFlags.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
@@ -348,6 +383,8 @@ func (o *DebugOptions) Complete(cmd *cobra.Command, f kcmdutil.Factory, args []s
 		return err
 	}
 	o.Attach.Config = config
+	o.Session.Title = strings.TrimSpace("oc debug " + strings.Join(o.Resources, " "))
+	o.Attach.Attach = &octerm.SessionAttach{RemoteAttach: o.Attach.Attach, Session: &o.Session}
 
 	o.CoreClient, err = corev1client.NewForConfig(config)
 	if err != nil {
@@ -371,6 +408,33 @@ func (o DebugOptions) Validate() error {
 	if (o.AsRoot || o.AsNonRoot) && o.AsUser > 0 {
 		return fmt.Errorf("you may not specify --as-root and --as-user=%d at the same time", o.AsUser)
 	}
+	if err := o.Session.Validate(); err != nil {
+		return err
+	}
+	if o.Persistent {
+		switch {
+		case o.Ephemeral:
+			return fmt.Errorf("you may not specify --persistent and --ephemeral at the same time")
+		case o.PreservePod:
+			return fmt.Errorf("you may not specify --persistent and --preserve-pod at the same time, the toolbox is kept until --ttl expires")
+		case o.TTL <= 0:
+			return fmt.Errorf("--ttl must be greater than zero")
+		}
+	}
+	if o.Ephemeral {
+		switch {
+		case len(o.Resources) == 0 && len(o.FilenameOptions.Filenames) == 0:
+			return fmt.Errorf("you must identify the pod to debug with --ephemeral")
+		case len(o.ToNamespace) > 0:
+			return fmt.Errorf("you may not specify --ephemeral and --to-namespace at the same time, the ephemeral container runs in the pod")
+		case o.NodeNameSet:
+			return fmt.Errorf("you may not specify --ephemeral and --node-name at the same time, the ephemeral container runs on the node of the pod")
+		case o.OneContainer:
+			return fmt.Errorf("you may not specify --ephemeral and --one-container at the same time, the containers of the pod keep running")
+		case o.PreservePod:
+			return fmt.Errorf("you may not specify --ephemeral and --preserve-pod at the same time, the debugged pod is never deleted")
+		}
+	}
 	return nil
 }
 
@@ -433,6 +497,9 @@ func (o *DebugOptions) RunDebug() error {
 		klog.V(4).Infof("Objects: %#v", infos)
 		return fmt.Errorf("you must identify a single resource with a pod template to debug")
 	}
+	if o.Ephemeral {
+		return o.runEphemeral(infos[0])
+	}
 
 	template, err := o.approximatePodTemplateForObject(infos[0].Object)
 	if err != nil && template == nil {
@@ -491,6 +558,13 @@ func (o *DebugOptions) RunDebug() error {
 		commandString = ""
 	}
 
+	if o.Persistent {
+		if !o.IsNode {
+			return fmt.Errorf("--persistent can only be used to debug nodes")
+		}
+		return o.runPersistent(pod)
+	}
+
 	if o.Printer != nil {
 		return o.Printer.PrintObj(pod, o.Out)
 	}
@@ -543,93 +617,106 @@ func (o *DebugOptions) RunDebug() error {
 			}
 		}
 
-		fieldSelector := fields.OneTermEqualSelector("metadata.name", pod.Name).String()
-		lw := &cache.ListWatch{
-			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-				options.FieldSelector = fieldSelector
-				return o.CoreClient.Pods(ns).List(context.TODO(), options)
-			},
-			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-				options.FieldSelector = fieldSelector
-				return o.CoreClient.Pods(ns).Watch(context.TODO(), options)
-			},
-		}
-		preconditionFunc := func(store cache.Store) (bool, error) {
-			_, exists, err := store.Get(&metav1.ObjectMeta{Namespace: ns, Name: pod.Name})
-			if err != nil {
-				return true, err
-			}
-			if !exists {
-				// We need to make sure we see the object in the cache before we start waiting for events
-				// or we would be waiting for the timeout if such object didn't exist.
-				// (e.g. it was deleted before we started informers so they wouldn't even see the delete event)
-				return true, kapierrors.NewNotFound(corev1.Resource("pods"), pod.Name)
-			}
+		return o.waitAndAttach(pod)
+	})
+}
 
-			return false, nil
-		}
+// waitAndAttach waits for the debug container of pod to run, and attaches to it, or prints its logs if it
+// already completed or runs without stdin.
+func (o *DebugOptions) waitAndAttach(pod *corev1.Pod) error {
+	containerRunningEvent, err := o.waitForContainer(pod)
 
-		notifyFn := func(pod *corev1.Pod, container corev1.ContainerStatus) error {
-			// TODO: instead of reporting to the user a message, accumulate a certain amount of time in
-			// the error state, then exit early
-			if o.Attach.Quiet {
-				return nil
-			}
-			if container.State.Waiting != nil {
-				switch container.State.Waiting.Reason {
-				case "CreateContainerError", "ImagePullBackOff":
-					fmt.Fprintf(o.Attach.ErrOut, "warning: Container %s is unable to start due to an error: %s\n", container.Name, container.State.Waiting.Message)
-				}
+	switch {
+	// api didn't error right away but the pod wasn't even created
+	case kapierrors.IsNotFound(err):
+		msg := fmt.Sprintf("unable to create the debug pod %q", pod.Name)
+		if len(o.NodeName) > 0 {
+			msg += fmt.Sprintf(" on node %q", o.NodeName)
+		}
+		return fmt.Errorf(msg)
+		// switch to logging output
+	case err == krun.ErrPodCompleted, err == conditions.ErrContainerTerminated:
+		resultPod, ok := containerRunningEvent.Object.(*corev1.Pod)
+		if ok && resultPod.Status.Reason == "NodeAffinity" && len(resultPod.Spec.NodeSelector) != 0 {
+			return fmt.Errorf("debug pod could not be scheduled: %v. To fix this you may want to create a new namespace with empty node selector and run the debug there. For example: oc adm new-project --node-selector=\"\" debug", resultPod.Status.Message)
+		}
+		return o.getLogs(pod)
+	case err == conditions.ErrNonZeroExitCode:
+		if err = o.getLogs(pod); err != nil {
+			return err
+		}
+		return conditions.ErrNonZeroExitCode
+	case err != nil:
+		return err
+	case !o.Attach.Stdin:
+		return o.getLogs(pod)
+	default:
+		if !o.Attach.Quiet {
+			// TODO this doesn't do us much good for remote debugging sessions, but until we get a local port
+			// set up to proxy, this is what we've got.
+			if podWithStatus, ok := containerRunningEvent.Object.(*corev1.Pod); ok {
+				fmt.Fprintf(o.Attach.ErrOut, "Pod IP: %s\n", podWithStatus.Status.PodIP)
 			}
-			return nil
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
-		defer cancel()
-		containerRunningEvent, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, preconditionFunc, conditions.PodContainerRunning(o.Attach.ContainerName, o.CoreClient, notifyFn))
-		if err == nil {
-			klog.V(4).Infof("Stopped waiting for pod: %s %#v", containerRunningEvent.Type, containerRunningEvent.Object)
-		} else {
-			klog.V(4).Infof("Stopped waiting for pod: %v", err)
+		// TODO: attach can race with pod completion, allow attach to switch to logs
+		return o.Attach.Run()
+	}
+}
+
+// waitForContainer waits for the debug container of pod to run, and returns the last event of the pod.
+func (o *DebugOptions) waitForContainer(pod *corev1.Pod) (*watch.Event, error) {
+	ns := pod.Namespace
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", pod.Name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return o.CoreClient.Pods(ns).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return o.CoreClient.Pods(ns).Watch(context.TODO(), options)
+		},
+	}
+	preconditionFunc := func(store cache.Store) (bool, error) {
+		_, exists, err := store.Get(&metav1.ObjectMeta{Namespace: ns, Name: pod.Name})
+		if err != nil {
+			return true, err
+		}
+		if !exists {
+			// We need to make sure we see the object in the cache before we start waiting for events
+			// or we would be waiting for the timeout if such object didn't exist.
+			// (e.g. it was deleted before we started informers so they wouldn't even see the delete event)
+			return true, kapierrors.NewNotFound(corev1.Resource("pods"), pod.Name)
 		}
 
-		switch {
-		// api didn't error right away but the pod wasn't even created
-		case kapierrors.IsNotFound(err):
-			msg := fmt.Sprintf("unable to create the debug pod %q", pod.Name)
-			if len(o.NodeName) > 0 {
-				msg += fmt.Sprintf(" on node %q", o.NodeName)
-			}
-			return fmt.Errorf(msg)
-			// switch to logging output
-		case err == krun.ErrPodCompleted, err == conditions.ErrContainerTerminated:
-			resultPod, ok := containerRunningEvent.Object.(*corev1.Pod)
-			if ok && resultPod.Status.Reason == "NodeAffinity" && len(resultPod.Spec.NodeSelector) != 0 {
-				return fmt.Errorf("debug pod could not be scheduled: %v. To fix this you may want to create a new namespace with empty node selector and run the debug there. For example: oc adm new-project --node-selector=\"\" debug", resultPod.Status.Message)
-			}
-			return o.getLogs(pod)
-		case err == conditions.ErrNonZeroExitCode:
-			if err = o.getLogs(pod); err != nil {
-				return err
-			}
-			return conditions.ErrNonZeroExitCode
-		case err != nil:
-			return err
-		case !o.Attach.Stdin:
-			return o.getLogs(pod)
-		default:
-			if !o.Attach.Quiet {
-				// TODO this doesn't do us much good for remote debugging sessions, but until we get a local port
-				// set up to proxy, this is what we've got.
-				if podWithStatus, ok := containerRunningEvent.Object.(*corev1.Pod); ok {
-					fmt.Fprintf(o.Attach.ErrOut, "Pod IP: %s\n", podWithStatus.Status.PodIP)
-				}
-			}
+		return false, nil
+	}
 
-			// TODO: attach can race with pod completion, allow attach to switch to logs
-			return o.Attach.Run()
+	notifyFn := func(pod *corev1.Pod, container corev1.ContainerStatus) error {
+		// TODO: instead of reporting to the user a message, accumulate a certain amount of time in
+		// the error state, then exit early
+		if o.Attach.Quiet {
+			return nil
 		}
-	})
+		if container.State.Waiting != nil {
+			switch container.State.Waiting.Reason {
+			case "CreateContainerError", "ImagePullBackOff":
+				fmt.Fprintf(o.Attach.ErrOut, "warning: Container %s is unable to start due to an error: %s\n", container.Name, container.State.Waiting.Message)
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	containerRunningEvent, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, preconditionFunc, conditions.PodContainerRunning(o.Attach.ContainerName, o.CoreClient, notifyFn))
+	if err == nil {
+		klog.V(4).Infof("Stopped waiting for pod: %s %#v", containerRunningEvent.Type, containerRunningEvent.Object)
+	} else {
+		klog.V(4).Infof("Stopped waiting for pod: %v", err)
+	}
+	return containerRunningEvent, err
 }
 
 // getContainerImageViaDeploymentConfig attempts to return an Image for a given
@@ -804,18 +891,7 @@ func (o *DebugOptions) transformPodForDebug(annotations map[string]string) (*cor
 	if container.SecurityContext == nil {
 		container.SecurityContext = &corev1.SecurityContext{}
 	}
-	switch {
-	case o.AsNonRoot:
-		b := true
-		container.SecurityContext.RunAsNonRoot = &b
-	case o.AsRoot:
-		zero := int64(0)
-		container.SecurityContext.RunAsUser = &zero
-		container.SecurityContext.RunAsNonRoot = nil
-	case o.AsUser != -1:
-		container.SecurityContext.RunAsUser = &o.AsUser
-		container.SecurityContext.RunAsNonRoot = nil
-	}
+	o.setRunAsUser(container.SecurityContext)
 
 	// if DebugOptions set container.SecurityContext.RunAsNonRoot to nil,
 	// pod.Spec.SecurityContext.runAsNonRoot should be nil also.
@@ -963,6 +1039,22 @@ func clearHostPorts(pod *corev1.Pod) {
 	}
 }
 
+// setRunAsUser sets the user of securityContext according to --as-root and --as-user.
+func (o *DebugOptions) setRunAsUser(securityContext *corev1.SecurityContext) {
+	switch {
+	case o.AsNonRoot:
+		b := true
+		securityContext.RunAsNonRoot = &b
+	case o.AsRoot:
+		zero := int64(0)
+		securityContext.RunAsUser = &zero
+		securityContext.RunAsNonRoot = nil
+	case o.AsUser != -1:
+		securityContext.RunAsUser = &o.AsUser
+		securityContext.RunAsNonRoot = nil
+	}
+}
+
 func (o *DebugOptions) getContainerCommand() []string {
 	if len(o.Command) == 1 && o.Command[0] == commandLinuxShell {
 		pod := o.Attach.Pod
@@ -1221,6 +1313,10 @@ func (o *DebugOptions) getNamespace(infoNs string) (string, func(), error) {
 			},
 		}
 
+		if o.Persistent {
+			return o.toolboxNamespace(tmpNS)
+		}
+
 		ns, err := o.CoreClient.Namespaces().Create(context.TODO(), tmpNS, metav1.CreateOptions{})
 		if err != nil {
 			return "", nil, fmt.Errorf("unable to create temporary namespace %s: %v", tmpNS.Name, err)
@@ -1,12 +1,17 @@
 package debug
 
 import (
+	"context"
+	"reflect"
+	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/kubectl/pkg/cmd/attach"
 	"k8s.io/kubectl/pkg/cmd/exec"
 	"k8s.io/pod-security-admission/api"
@@ -201,3 +206,252 @@ func TestGetNamespace(t *testing.T) {
 		}
 	}
 }
+
+func TestEphemeralDebugPod(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "test"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app:latest"}},
+		},
+	}
+	o := NewDebugOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Command = []string{"/bin/sh"}
+	o.AddEnv = []corev1.EnvVar{{Name: "DEBUG", Value: "1"}}
+	o.AsRoot = true
+	o.Attach.Pod = pod
+
+	debugPod := o.ephemeralDebugPod(pod, "tools:latest", "app")
+	if len(pod.Spec.EphemeralContainers) != 0 {
+		t.Fatalf("expected the pod to be left unchanged")
+	}
+	if len(debugPod.Spec.EphemeralContainers) != 1 {
+		t.Fatalf("expected one ephemeral container, got %#v", debugPod.Spec.EphemeralContainers)
+	}
+	container := debugPod.Spec.EphemeralContainers[0]
+	if !strings.HasPrefix(container.Name, ephemeralContainerPrefix) || container.TargetContainerName != "app" || container.Image != "tools:latest" {
+		t.Errorf("unexpected ephemeral container: %#v", container)
+	}
+	if !reflect.DeepEqual(container.Command, []string{"/bin/sh"}) || !container.Stdin || !container.TTY || !reflect.DeepEqual(container.Env, o.AddEnv) {
+		t.Errorf("unexpected ephemeral container command: %#v", container)
+	}
+	if container.SecurityContext == nil || container.SecurityContext.RunAsUser == nil || *container.SecurityContext.RunAsUser != 0 {
+		t.Errorf("expected the ephemeral container to run as root: %#v", container.SecurityContext)
+	}
+
+	// the name of the next ephemeral container differs
+	if next := o.ephemeralDebugPod(debugPod, "tools:latest", "app"); next.Spec.EphemeralContainers[1].Name == container.Name {
+		t.Errorf("expected a new ephemeral container name, got %s twice", container.Name)
+	}
+}
+
+func TestRunEphemeralErrors(t *testing.T) {
+	running := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-1", Namespace: "test"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "app"}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	completed := running.DeepCopy()
+	completed.Status.Phase = corev1.PodSucceeded
+
+	tests := []struct {
+		name          string
+		object        runtime.Object
+		container     string
+		expectedError string
+	}{
+		{
+			name:          "not a pod",
+			object:        &corev1.ReplicationController{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+			expectedError: "--ephemeral can only debug pods, not replicationcontrollers/app",
+		},
+		{
+			name:          "completed pod",
+			object:        completed,
+			expectedError: "cannot add an ephemeral container to pod/app-1, its phase is Succeeded",
+		},
+		{
+			name:          "init container",
+			object:        running,
+			container:     "init",
+			expectedError: `the container "init" is not a valid target container; must be one of [app]`,
+		},
+		{
+			name:   "dry run",
+			object: running,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewDebugOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.Image = "tools:latest"
+			o.DryRun = true
+			o.Attach.ContainerName = tt.container
+			info := &resource.Info{
+				Mapping: &meta.RESTMapping{Resource: corev1.SchemeGroupVersion.WithResource("replicationcontrollers")},
+				Name:    "app",
+				Object:  tt.object,
+			}
+			err := o.runEphemeral(info)
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedError {
+				t.Errorf("expected error %q, got %q", tt.expectedError, errStr)
+			}
+		})
+	}
+}
+
+func TestValidateEphemeral(t *testing.T) {
+	o := NewDebugOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Ephemeral = true
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "you must identify the pod to debug") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	o.Resources = []string{"pod/app-1"}
+	if err := o.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	o.ToNamespace = "other"
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "--to-namespace") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func testToolbox(image string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "master-1-debug-toolbox",
+			Namespace:   "openshift-debug-toolbox",
+			Labels:      map[string]string{toolboxLabel: "true"},
+			Annotations: map[string]string{debugPodAnnotationSourceResource: "nodes/master-1"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:   "master-1",
+			Containers: []corev1.Container{{Name: "container-00", Image: image}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestToolboxPod(t *testing.T) {
+	pod := testToolbox("tools:latest", "")
+	pod.Name = "master-1-debug"
+	pod.Labels = map[string]string{}
+	pod.Spec.Containers[0].Stdin = true
+	pod.Spec.Containers[0].Command = []string{"/bin/sh"}
+
+	o := NewDebugOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.Attach.ContainerName = "container-00"
+	toolbox := o.toolboxPod(pod)
+	if toolbox.Name != "master-1-debug-toolbox" || toolbox.Labels[toolboxLabel] != "true" {
+		t.Errorf("unexpected toolbox metadata: %#v", toolbox.ObjectMeta)
+	}
+	if toolbox.Spec.ActiveDeadlineSeconds == nil || *toolbox.Spec.ActiveDeadlineSeconds != 8*60*60 {
+		t.Errorf("expected the toolbox to stop after 8h, got %v", toolbox.Spec.ActiveDeadlineSeconds)
+	}
+	container := toolbox.Spec.Containers[0]
+	if !reflect.DeepEqual(container.Command, []string{"/bin/sh", "-c", toolboxCommand}) || container.Stdin {
+		t.Errorf("unexpected toolbox container: %#v", container)
+	}
+	if !pod.Spec.Containers[0].Stdin {
+		t.Errorf("expected the debug pod to be left unchanged")
+	}
+}
+
+func TestEnsureToolbox(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      *corev1.Pod
+		expectedError string
+		expectCreate  bool
+		expectDelete  bool
+	}{
+		{
+			name:         "no toolbox",
+			expectCreate: true,
+		},
+		{
+			name:     "running toolbox",
+			existing: testToolbox("tools:latest", corev1.PodRunning),
+		},
+		{
+			name:         "stopped toolbox",
+			existing:     testToolbox("tools:latest", corev1.PodFailed),
+			expectCreate: true,
+			expectDelete: true,
+		},
+		{
+			name:         "toolbox of another image",
+			existing:     testToolbox("tools:old", corev1.PodRunning),
+			expectCreate: true,
+			expectDelete: true,
+		},
+		{
+			name: "other pod",
+			existing: func() *corev1.Pod {
+				pod := testToolbox("tools:latest", corev1.PodRunning)
+				pod.Labels = nil
+				return pod
+			}(),
+			expectedError: `a pod already exists named "master-1-debug-toolbox", please delete it before running debug`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var client *fakekubeclient.Clientset
+			if tt.existing != nil {
+				client = fakekubeclient.NewSimpleClientset(tt.existing)
+			} else {
+				client = fakekubeclient.NewSimpleClientset()
+			}
+			o := NewDebugOptions(genericclioptions.NewTestIOStreamsDiscard())
+			o.CoreClient = client.CoreV1()
+			o.Attach.ContainerName = "container-00"
+
+			_, err := o.ensureToolbox(testToolbox("tools:latest", ""))
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedError {
+				t.Fatalf("expected error %q, got %q", tt.expectedError, errStr)
+			}
+			var created, deleted bool
+			for _, action := range client.Actions() {
+				created = created || action.Matches("create", "pods")
+				deleted = deleted || action.Matches("delete", "pods")
+			}
+			if created != tt.expectCreate || deleted != tt.expectDelete {
+				t.Errorf("expected create %t and delete %t, got %t and %t", tt.expectCreate, tt.expectDelete, created, deleted)
+			}
+		})
+	}
+}
+
+func TestToolboxNamespace(t *testing.T) {
+	client := fakekubeclient.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	o := NewDebugOptions(genericclioptions.NewTestIOStreamsDiscard())
+	o.CoreClient = client.CoreV1()
+	o.IsNode = true
+	o.Persistent = true
+	o.Namespace = "default"
+
+	for i := 0; i < 2; i++ {
+		ns, cleanup, err := o.getNamespace("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		cleanup()
+		if ns != toolboxNamespace {
+			t.Errorf("expected the namespace %s, got %s", toolboxNamespace, ns)
+		}
+	}
+	if _, err := client.CoreV1().Namespaces().Get(context.TODO(), toolboxNamespace, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the namespace to be kept: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/util/interrupt"
+)
+
+// ephemeralContainerPrefix is the prefix of the names of the ephemeral debug containers
+const ephemeralContainerPrefix = "debugger-"
+
+// runEphemeral adds an ephemeral debug container to the running pod of info, and attaches to it.
+func (o *DebugOptions) runEphemeral(info *resource.Info) error {
+	pod, ok := info.Object.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("--ephemeral can only debug pods, not %s/%s", info.Mapping.Resource.Resource, info.Name)
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return fmt.Errorf("cannot add an ephemeral container to pod/%s, its phase is %s", pod.Name, pod.Status.Phase)
+	}
+
+	target := o.Attach.ContainerName
+	if len(target) == 0 {
+		if len(pod.Spec.Containers) == 0 {
+			return fmt.Errorf("the provided pod must have at least one container")
+		}
+		target = pod.Spec.Containers[0].Name
+		klog.V(4).Infof("Defaulting the target container to %s", target)
+	}
+	if !isTargetContainer(pod, target) {
+		var targetNames []string
+		for _, c := range pod.Spec.Containers {
+			targetNames = append(targetNames, c.Name)
+		}
+		return fmt.Errorf("the container %q is not a valid target container; must be one of %v", target, targetNames)
+	}
+
+	image := o.Image
+	if len(image) == 0 {
+		imageStream := o.ImageStream
+		if len(imageStream) == 0 {
+			imageStream = "openshift/tools:latest"
+		}
+		imageFromStream, err := o.resolveImageStreamTagString(imageStream)
+		if err != nil {
+			return fmt.Errorf("unable to resolve the debug image from image stream %s, set one with --image: %v", imageStream, err)
+		}
+		image = imageFromStream
+		klog.V(4).Infof("Defaulted image from imagestream %s: %s", imageStream, image)
+	}
+
+	o.Attach.Pod = pod
+	debugPod := o.ephemeralDebugPod(pod, image, target)
+	container := debugPod.Spec.EphemeralContainers[len(debugPod.Spec.EphemeralContainers)-1]
+
+	if o.Printer != nil {
+		return o.Printer.PrintObj(debugPod, o.Out)
+	}
+
+	if o.DryRun {
+		return nil
+	}
+
+	klog.V(5).Infof("Adding ephemeral container: %#v", container)
+	updated, err := o.CoreClient.Pods(pod.Namespace).UpdateEphemeralContainers(context.TODO(), pod.Name, debugPod, metav1.UpdateOptions{})
+	if err != nil {
+		if kapierrors.IsNotFound(err) {
+			return fmt.Errorf("unable to add an ephemeral container to pod/%s, the cluster may not support ephemeral containers: %v", pod.Name, err)
+		}
+		return err
+	}
+	o.Attach.Pod = updated
+	o.Attach.ContainerName = container.Name
+
+	// the ephemeral container can't be removed, it stops when the command completes
+	o.Attach.InterruptParent = interrupt.New(func(os.Signal) { os.Exit(1) })
+
+	klog.V(5).Infof("Created attach arguments: %#v", o.Attach)
+	return o.Attach.InterruptParent.Run(func() error {
+		if !o.Attach.Quiet {
+			fmt.Fprintf(o.ErrOut, "Starting ephemeral container %s in pod/%s, targeting container %s ...\n", container.Name, updated.Name, target)
+		}
+		return o.waitAndAttach(updated)
+	})
+}
+
+// ephemeralDebugPod returns a copy of pod with an ephemeral container running image added, which shares the process
+// namespace of the target container.
+func (o *DebugOptions) ephemeralDebugPod(pod *corev1.Pod, image, target string) *corev1.Pod {
+	name := ephemeralContainerPrefix + utilrand.String(5)
+	for containerForName(pod, name) != nil || isEphemeralContainer(pod, name) {
+		name = ephemeralContainerPrefix + utilrand.String(5)
+	}
+
+	container := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:                     name,
+			Image:                    image,
+			ImagePullPolicy:          corev1.PullIfNotPresent,
+			Command:                  o.getContainerCommand(),
+			Env:                      o.AddEnv,
+			TTY:                      o.Attach.Stdin && o.Attach.TTY,
+			Stdin:                    o.Attach.Stdin,
+			StdinOnce:                o.Attach.Stdin,
+			TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+		},
+		TargetContainerName: target,
+	}
+	if o.AsRoot || o.AsNonRoot || o.AsUser != -1 {
+		container.SecurityContext = &corev1.SecurityContext{}
+		o.setRunAsUser(container.SecurityContext)
+	}
+
+	debugPod := pod.DeepCopy()
+	debugPod.Spec.EphemeralContainers = append(debugPod.Spec.EphemeralContainers, container)
+	return debugPod
+}
+
+// isTargetContainer returns true if name is the name of a container of pod that an ephemeral container can target.
+func isTargetContainer(pod *corev1.Pod, name string) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isEphemeralContainer returns true if name is the name of an ephemeral container of pod.
+func isEphemeralContainer(pod *corev1.Pod, name string) bool {
+	for _, c := range pod.Spec.EphemeralContainers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
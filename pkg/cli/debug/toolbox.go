@@ -0,0 +1,150 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/cmd/exec"
+
+	octerm "github.com/openshift/oc/pkg/helpers/term"
+)
+
+const (
+	// toolboxLabel is set on the toolbox pods of the nodes
+	toolboxLabel = "debug.openshift.io/toolbox"
+	// toolboxNamespace is the namespace of the toolbox pods when the current namespace is not privileged
+	toolboxNamespace = "openshift-debug-toolbox"
+	// toolboxCommand keeps the toolbox running until it is stopped
+	toolboxCommand = "trap 'exit 0' TERM; sleep infinity & wait"
+)
+
+// runPersistent runs the debug command in the toolbox of the node, a long-lived debug pod that is reused by the next
+// invocations until its TTL expires.
+func (o *DebugOptions) runPersistent(pod *corev1.Pod) error {
+	toolbox := o.toolboxPod(pod)
+
+	if o.Printer != nil {
+		return o.Printer.PrintObj(toolbox, o.Out)
+	}
+
+	if o.DryRun {
+		return nil
+	}
+
+	toolbox, err := o.ensureToolbox(toolbox)
+	if err != nil {
+		return err
+	}
+	o.Attach.Pod = toolbox
+	if _, err := o.waitForContainer(toolbox); err != nil {
+		return fmt.Errorf("the toolbox pod/%s is not running: %v", toolbox.Name, err)
+	}
+
+	if !o.Attach.Quiet {
+		fmt.Fprintf(o.ErrOut, "To use host binaries, run `chroot /host`\n")
+	}
+	e := &exec.ExecOptions{
+		StreamOptions: exec.StreamOptions{
+			Namespace:     toolbox.Namespace,
+			PodName:       toolbox.Name,
+			ContainerName: o.Attach.ContainerName,
+			Stdin:         o.Attach.Stdin,
+			TTY:           o.Attach.TTY,
+			Quiet:         o.Attach.Quiet,
+			IOStreams:     o.IOStreams,
+		},
+		Command:   o.getContainerCommand(),
+		Executor:  &octerm.SessionExecutor{RemoteExecutor: &exec.DefaultRemoteExecutor{}, Session: &o.Session},
+		PodClient: o.CoreClient,
+		Config:    o.Attach.Config,
+	}
+	return e.Run()
+}
+
+// toolboxPod returns the toolbox of the node from the debug pod of the node: the debug container keeps running
+// until the TTL expires, and the debug commands are executed in it.
+func (o *DebugOptions) toolboxPod(pod *corev1.Pod) *corev1.Pod {
+	toolbox := pod.DeepCopy()
+	toolbox.Name = fmt.Sprintf("%s-toolbox", pod.Name)
+	toolbox.Labels[toolboxLabel] = "true"
+
+	deadline := int64(o.TTL / time.Second)
+	toolbox.Spec.ActiveDeadlineSeconds = &deadline
+
+	container := containerForName(toolbox, o.Attach.ContainerName)
+	container.Command = []string{"/bin/sh", "-c", toolboxCommand}
+	container.Args = nil
+	container.TTY = false
+	container.Stdin = false
+	container.StdinOnce = false
+	return toolbox
+}
+
+// ensureToolbox returns the existing toolbox if it is still running the same image, and creates it otherwise,
+// replacing the existing toolbox.
+func (o *DebugOptions) ensureToolbox(toolbox *corev1.Pod) (*corev1.Pod, error) {
+	namespace, name := toolbox.Namespace, toolbox.Name
+
+	existing, err := o.CoreClient.Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	switch {
+	case kapierrors.IsNotFound(err):
+	case err != nil:
+		return nil, err
+	case existing.Labels[toolboxLabel] != "true" || existing.Annotations[debugPodAnnotationSourceResource] != toolbox.Annotations[debugPodAnnotationSourceResource]:
+		return nil, fmt.Errorf("a pod already exists named %q, please delete it before running debug", name)
+	case isToolboxReusable(existing, toolbox, o.Attach.ContainerName):
+		if !o.Attach.Quiet {
+			fmt.Fprintf(o.ErrOut, "Reusing the toolbox pod/%s of node %s", name, toolbox.Spec.NodeName)
+			if expiry := toolboxExpiry(existing); !expiry.IsZero() {
+				fmt.Fprintf(o.ErrOut, ", it stops at %s", expiry.Format(time.RFC3339))
+			}
+			fmt.Fprintf(o.ErrOut, " ...\n")
+		}
+		return existing, nil
+	default:
+		klog.V(4).Infof("Replacing the toolbox pod %s in phase %s", name, existing.Status.Phase)
+		if err := o.CoreClient.Pods(namespace).Delete(context.TODO(), name, *metav1.NewDeleteOptions(0)); err != nil && !kapierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("unable to delete the toolbox pod %q: %v", name, err)
+		}
+	}
+
+	if !o.Attach.Quiet {
+		fmt.Fprintf(o.ErrOut, "Starting the toolbox pod/%s of node %s, it stops after %s ...\n", name, toolbox.Spec.NodeName, o.TTL)
+	}
+	klog.V(5).Infof("Creating pod: %#v", toolbox)
+	return o.CoreClient.Pods(namespace).Create(context.TODO(), toolbox, metav1.CreateOptions{})
+}
+
+// toolboxNamespace returns the namespace of the toolbox pods, which is created from ns if it doesn't exist and is
+// kept like the toolbox pods.
+func (o *DebugOptions) toolboxNamespace(ns *corev1.Namespace) (string, func(), error) {
+	ns = ns.DeepCopy()
+	ns.GenerateName = ""
+	ns.Name = toolboxNamespace
+	if _, err := o.CoreClient.Namespaces().Create(context.TODO(), ns, metav1.CreateOptions{}); err != nil && !kapierrors.IsAlreadyExists(err) {
+		return "", nil, fmt.Errorf("unable to create the namespace %s: %v", ns.Name, err)
+	}
+	return ns.Name, func() {}, nil
+}
+
+// isToolboxReusable returns true if the existing toolbox is running, or about to, the image of the toolbox.
+func isToolboxReusable(existing, toolbox *corev1.Pod, containerName string) bool {
+	if existing.DeletionTimestamp != nil || existing.Status.Phase == corev1.PodSucceeded || existing.Status.Phase == corev1.PodFailed {
+		return false
+	}
+	existingContainer, container := containerForName(existing, containerName), containerForName(toolbox, containerName)
+	return existingContainer != nil && existingContainer.Image == container.Image
+}
+
+// toolboxExpiry returns when the toolbox stops, or the zero time if it did not start yet.
+func toolboxExpiry(toolbox *corev1.Pod) time.Time {
+	if toolbox.Status.StartTime == nil || toolbox.Spec.ActiveDeadlineSeconds == nil {
+		return time.Time{}
+	}
+	return toolbox.Status.StartTime.Add(time.Duration(*toolbox.Spec.ActiveDeadlineSeconds) * time.Second)
+}
@@ -3,6 +3,7 @@ package expose
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -51,6 +52,14 @@ var (
 
 		# Expose a service as a route in the specified path
 		oc expose service nginx --path=/nginx
+
+		# Expose a service as a secured route terminating TLS at the router with the certificate and key of its host,
+		# redirecting HTTP requests to HTTPS and asking browsers to use HTTPS for a year
+		oc expose service nginx --hostname=www.example.com --tls=edge --cert=tls.crt --key=tls.key \
+		  --insecure-policy=Redirect --hsts-max-age=8760h
+
+		# Expose a service as a route passing the TLS connections through to the service
+		oc expose service nginx --tls=passthrough
 	`)
 )
 
@@ -58,6 +67,10 @@ type ExposeOptions struct {
 	Hostname       string
 	Path           string
 	WildcardPolicy string
+	TLSTermination string
+	InsecurePolicy string
+	TLSFiles       route.TLSFiles
+	HSTS           route.HSTSOptions
 
 	Args        []string
 	Cmd         *cobra.Command
@@ -95,6 +108,17 @@ func NewCmdExpose(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	cmd.Flags().StringVar(&o.Hostname, "hostname", o.Hostname, "Set a hostname for the new route")
 	cmd.Flags().StringVar(&o.Path, "path", o.Path, "Set a path for the new route")
 	cmd.Flags().StringVar(&o.WildcardPolicy, "wildcard-policy", o.WildcardPolicy, "Sets the WildcardPolicy for the hostname, the default is \"None\". Valid values are \"None\" and \"Subdomain\"")
+	cmd.Flags().StringVar(&o.TLSTermination, "tls", o.TLSTermination, "Secure the new route with TLS termination: edge, reencrypt or passthrough")
+	cmd.Flags().StringVar(&o.InsecurePolicy, "insecure-policy", o.InsecurePolicy, "Set the policy of the HTTP requests to the secured route: Allow, Redirect or None")
+	cmd.Flags().StringVar(&o.TLSFiles.Cert, "cert", o.TLSFiles.Cert, "Path to a certificate file of the secured route")
+	cmd.MarkFlagFilename("cert")
+	cmd.Flags().StringVar(&o.TLSFiles.Key, "key", o.TLSFiles.Key, "Path to a key file of the secured route")
+	cmd.MarkFlagFilename("key")
+	cmd.Flags().StringVar(&o.TLSFiles.CACert, "ca-cert", o.TLSFiles.CACert, "Path to a CA certificate file of the secured route")
+	cmd.MarkFlagFilename("ca-cert")
+	cmd.Flags().StringVar(&o.TLSFiles.DestCACert, "dest-ca-cert", o.TLSFiles.DestCACert, "Path to a CA certificate file used for securing the connection from the router to the service with reencrypt termination")
+	cmd.MarkFlagFilename("dest-ca-cert")
+	o.HSTS.AddFlags(cmd)
 
 	return cmd
 }
@@ -142,6 +166,25 @@ func (o *ExposeOptions) Validate() error {
 	if len(o.WildcardPolicy) > 0 && (o.WildcardPolicy != string(routev1.WildcardPolicySubdomain) && o.WildcardPolicy != string(routev1.WildcardPolicyNone)) {
 		return fmt.Errorf("only \"Subdomain\" or \"None\" are supported for wildcard-policy")
 	}
+
+	switch routev1.TLSTerminationType(o.TLSTermination) {
+	case "":
+		if len(o.InsecurePolicy) > 0 || o.TLSFiles != (route.TLSFiles{}) || o.HSTS != (route.HSTSOptions{}) {
+			return fmt.Errorf("--insecure-policy, --cert, --key, --ca-cert, --dest-ca-cert and the HSTS flags require --tls")
+		}
+	case routev1.TLSTerminationEdge, routev1.TLSTerminationReencrypt:
+	case routev1.TLSTerminationPassthrough:
+		if o.InsecurePolicy == string(routev1.InsecureEdgeTerminationPolicyAllow) {
+			return fmt.Errorf("--insecure-policy=Allow is not supported with passthrough termination")
+		}
+	default:
+		return fmt.Errorf("only \"edge\", \"reencrypt\" or \"passthrough\" are supported for tls")
+	}
+	switch routev1.InsecureEdgeTerminationPolicyType(o.InsecurePolicy) {
+	case "", routev1.InsecureEdgeTerminationPolicyAllow, routev1.InsecureEdgeTerminationPolicyRedirect, routev1.InsecureEdgeTerminationPolicyNone:
+	default:
+		return fmt.Errorf("only \"Allow\", \"Redirect\" or \"None\" are supported for insecure-policy")
+	}
 	return nil
 }
 
@@ -179,6 +222,9 @@ func (o *ExposeOptions) Run() error {
 		route.Spec.Host = o.Hostname
 		route.Spec.Path = o.Path
 		route.Spec.WildcardPolicy = routev1.WildcardPolicyType(o.WildcardPolicy)
+		if err := o.secureRoute(route); err != nil {
+			return err
+		}
 		if err := util.CreateOrUpdateAnnotation(kcmdutil.GetFlagBool(o.Cmd, kcmdutil.ApplyAnnotationsFlag), route, scheme.DefaultJSONEncoder()); err != nil {
 			return err
 		}
@@ -200,3 +246,21 @@ func (o *ExposeOptions) Run() error {
 
 	return o.ExposeServiceOptions.RunExpose(o.Cmd, o.Args)
 }
+
+// secureRoute sets the TLS configuration of the route from the TLS flags, and validates its certificate.
+func (o *ExposeOptions) secureRoute(r *routev1.Route) error {
+	if len(o.TLSTermination) == 0 {
+		return nil
+	}
+	r.Spec.TLS = &routev1.TLSConfig{
+		Termination:                   routev1.TLSTerminationType(o.TLSTermination),
+		InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyType(o.InsecurePolicy),
+	}
+	if err := o.TLSFiles.Load(r.Spec.TLS); err != nil {
+		return err
+	}
+	if err := o.HSTS.Apply(r); err != nil {
+		return err
+	}
+	return route.ValidateTLS(r, time.Now())
+}
@@ -134,6 +134,12 @@ type destination struct {
 	tags []string
 }
 
+// ParseMappingFile parses a file of SRC=DST mappings as accepted by --filename, reading from in when the filename
+// is "-". Wildcards in the sources are not expanded.
+func ParseMappingFile(filename string, in io.Reader) ([]Mapping, error) {
+	return parseFile(filename, map[string]string{}, in, nil)
+}
+
 func contextKeyForReference(t imagesource.TypedImageReference) contextKey {
 	return contextKey{t: t.Type, registry: t.Ref.Registry}
 }
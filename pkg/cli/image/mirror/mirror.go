@@ -7,7 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/distribution"
@@ -16,12 +16,14 @@ import (
 	"github.com/docker/distribution/manifest/schema1"
 	"github.com/docker/distribution/manifest/schema2"
 	"github.com/docker/distribution/reference"
+	"github.com/docker/distribution/registry/api/errcode"
 	"github.com/docker/distribution/registry/client"
 
 	units "github.com/docker/go-units"
 	godigest "github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
@@ -64,6 +66,14 @@ var (
 
 		Images in manifest list format will be copied as-is unless you use --filter-by-os to restrict
 		the allowed images to copy in a manifest list. This flag has no effect on regular images.
+
+		Before uploading, the destination is asked for every layer to copy and the layers it already
+		has are skipped, unless --force is passed. Layers that fail to upload are retried up to
+		--max-retries times, waiting --retry-delay before the first retry and twice as long before
+		each following one. --max-registry limits the number of registries written to at once and
+		--max-per-registry the number of concurrent requests to each of them, which may be changed
+		for some registries with --max-per-registry-override. The amount of data copied, skipped
+		and mounted is reported once mirroring completes.
 	`)
 
 	mirrorExample = templates.Examples(`
@@ -112,6 +122,11 @@ var (
 		# Note the above command is equivalent to
 		oc image mirror myregistry.com/myimage:latest=myregistry.com/other:test \
 			--filter-by-os=.*
+
+		# Copy many images with more concurrent uploads, except to a throttled registry, and
+		# retry failed layer uploads more often
+		oc image mirror -f mappings.txt --max-per-registry=12 \
+			--max-per-registry-override=quay.io=4 --max-retries=10
 	`)
 )
 
@@ -129,8 +144,12 @@ type MirrorImageOptions struct {
 	KeepManifestList   bool
 	ContinueOnError    bool
 
-	MaxRegistry     int
-	ParallelOptions imagemanifest.ParallelOptions
+	MaxRegistry             int
+	ParallelOptions         imagemanifest.ParallelOptions
+	MaxPerRegistryOverrides map[string]int
+
+	MaxRetries int
+	RetryDelay time.Duration
 
 	AttemptS3BucketCopy []string
 	FileDir             string
@@ -148,6 +167,8 @@ func NewMirrorImageOptions(streams genericclioptions.IOStreams) *MirrorImageOpti
 		IOStreams:       streams,
 		ParallelOptions: imagemanifest.ParallelOptions{MaxPerRegistry: 6},
 		MaxRegistry:     4,
+		MaxRetries:      5,
+		RetryDelay:      time.Second,
 	}
 }
 
@@ -180,6 +201,9 @@ func NewCmdMirrorImage(streams genericclioptions.IOStreams) *cobra.Command {
 	flag.BoolVar(&o.Force, "force", o.Force, "Attempt to write all layers and manifests even if they exist in the remote repository.")
 	flag.BoolVar(&o.KeepManifestList, "keep-manifest-list", o.KeepManifestList, "If an image is part of a manifest list, always mirror the list even if only one image is found. The default is to mirror the specific image unless unless --filter-by-os is passed. This flag is equivalent to setting --filter-by-os to '.*' since you cannot preserve the manifest list digest while filtering out any of the manifests included in the list.")
 	flag.IntVar(&o.MaxRegistry, "max-registry", o.MaxRegistry, "Number of concurrent registries to connect to at any one time.")
+	flag.StringToIntVar(&o.MaxPerRegistryOverrides, "max-per-registry-override", o.MaxPerRegistryOverrides, "Number of concurrent requests allowed to a registry, as REGISTRY=COUNT, instead of --max-per-registry. May be specified multiple times.")
+	flag.IntVar(&o.MaxRetries, "max-retries", o.MaxRetries, "Number of times to retry a layer that failed to upload. Set to 0 to disable retries.")
+	flag.DurationVar(&o.RetryDelay, "retry-delay", o.RetryDelay, "Time to wait before retrying a layer upload, doubled for each further retry.")
 	flag.StringSliceVar(&o.AttemptS3BucketCopy, "s3-source-bucket", o.AttemptS3BucketCopy, "A list of bucket/path locations on S3 that may contain already uploaded blobs. Add [store] to the end to use the container image registry path convention.")
 	flag.StringSliceVarP(&o.Filenames, "filename", "f", o.Filenames, "One or more files to read SRC=DST or SRC DST [DST ...] mappings from.")
 	flag.StringVar(&o.FileDir, "dir", o.FileDir, "The directory on disk that file:// images will be copied under.")
@@ -263,9 +287,41 @@ func (o *MirrorImageOptions) Validate() error {
 	if o.KeepManifestList && len(o.FilterOptions.FilterByOS) > 0 && !o.FilterOptions.IsWildcardFilter() {
 		return fmt.Errorf("--keep-manifest-list=true cannot be passed with --filter-by-os, unless --filter-by-os=.*")
 	}
+	if o.ParallelOptions.MaxPerRegistry < 1 {
+		return fmt.Errorf("--max-per-registry must be at least 1")
+	}
+	for registry, count := range o.MaxPerRegistryOverrides {
+		if count < 1 {
+			return fmt.Errorf("--max-per-registry-override for %s must be at least 1", registry)
+		}
+	}
+	if o.MaxRetries < 0 {
+		return fmt.Errorf("--max-retries may not be negative")
+	}
+	if o.RetryDelay < 0 {
+		return fmt.Errorf("--retry-delay may not be negative")
+	}
 	return o.FilterOptions.Validate()
 }
 
+// maxPerRegistry returns the number of concurrent requests allowed to the registry.
+func (o *MirrorImageOptions) maxPerRegistry(registry string) int {
+	if count, ok := o.MaxPerRegistryOverrides[registry]; ok {
+		return count
+	}
+	return o.ParallelOptions.MaxPerRegistry
+}
+
+// blobBackoff returns how often and after how long a failed blob upload is retried.
+func (o *MirrorImageOptions) blobBackoff() wait.Backoff {
+	return wait.Backoff{
+		Steps:    o.MaxRetries + 1,
+		Duration: o.RetryDelay,
+		Factor:   2,
+		Jitter:   0.1,
+	}
+}
+
 func (o *MirrorImageOptions) Run() error {
 	var continuedOnFailure bool
 	start := time.Now()
@@ -308,16 +364,21 @@ func (o *MirrorImageOptions) Run() error {
 	q := workqueue.New(o.MaxRegistry, stopCh)
 	registryWorkers := make(map[string]workqueue.Interface)
 	for name := range p.RegistryNames() {
-		registryWorkers[name] = workqueue.New(o.ParallelOptions.MaxPerRegistry, stopCh)
+		registryWorkers[name] = workqueue.New(o.maxPerRegistry(name), stopCh)
 	}
 
 	next := time.Now()
 	defer func() {
 		d := time.Now().Sub(next)
 		fmt.Fprintf(o.ErrOut, "info: Mirroring completed in %s (%s/s)\n", d.Truncate(10*time.Millisecond), units.HumanSize(float64(work.stats.bytes)/d.Seconds()))
+		work.PrintTransferStats(o.ErrOut)
 	}()
 
 	ctx := apirequest.NewContext()
+	if !o.Force {
+		skipExistingBlobs(ctx, work, q, registryWorkers, o.ErrOut)
+	}
+	backoff := o.blobBackoff()
 	for j := range work.phases {
 		phase := &work.phases[j]
 		q.Batch(func(w workqueue.Work) {
@@ -332,7 +393,7 @@ func (o *MirrorImageOptions) Run() error {
 								digest := godigest.Digest(digestString)
 								blob := op.parent.parent.parent.GetBlob(digest)
 								w.Parallel(func() {
-									if err := copyBlob(ctx, work, op, blob, referentialClient, o.Force, o.SkipMount, o.ErrOut); err != nil {
+									if err := copyBlob(ctx, work, op, blob, referentialClient, o.SkipMount, backoff, o.ErrOut); err != nil {
 										phase.ExecutionFailure(err)
 										return
 									}
@@ -471,7 +532,7 @@ func (o *MirrorImageOptions) plan() (*plan, error) {
 	registryWorkers := make(map[string]workqueue.Interface)
 	for name := range tree {
 		if _, ok := registryWorkers[name.registry]; !ok {
-			registryWorkers[name.registry] = workqueue.New(o.ParallelOptions.MaxPerRegistry, stopCh)
+			registryWorkers[name.registry] = workqueue.New(o.maxPerRegistry(name.registry), stopCh)
 		}
 	}
 
@@ -652,22 +713,108 @@ func (o *MirrorImageOptions) plan() (*plan, error) {
 	return plan, nil
 }
 
-func copyBlob(ctx context.Context, plan *workPlan, c *repositoryBlobCopy, blob distribution.Descriptor, referentialClient *http.Client, force, skipMount bool, errOut io.Writer) error {
-	// if we aren't forcing upload, check to see if the blob aleady exists
-	if !force {
-		_, err := c.to.Stat(ctx, blob.Digest)
-		if err == nil {
-			// blob exists, skip
-			klog.V(5).Infof("Server reports blob exists %#v", blob)
-			c.parent.parent.AssociateBlob(c.parent.name, blob)
-			c.parent.ExpectBlob(blob.Digest)
-			return nil
+// skipExistingBlobs asks the destinations for every blob to copy before any is uploaded and removes the blobs they
+// already have from the work, so that the remaining transfer is known up front.
+func skipExistingBlobs(ctx context.Context, work *workPlan, q workqueue.Interface, registryWorkers map[string]workqueue.Interface, errOut io.Writer) {
+	var lock sync.Mutex
+	var count, existingCount int
+	var size, existingSize int64
+	for j := range work.phases {
+		phase := &work.phases[j]
+		q.Batch(func(w workqueue.Work) {
+			for i := range phase.independent {
+				unit := phase.independent[i]
+				w.Parallel(func() {
+					registryWorkers[unit.registry.name].Batch(func(w workqueue.Work) {
+						for i := range unit.repository.blobs {
+							op := unit.repository.blobs[i]
+							for _, digestString := range op.blobs.List() {
+								blob := op.parent.parent.parent.GetBlob(godigest.Digest(digestString))
+								w.Parallel(func() {
+									_, err := op.to.Stat(ctx, blob.Digest)
+									if err != nil && err != distribution.ErrBlobUnknown {
+										klog.V(5).Infof("Server was unable to check whether blob exists %s: %v", blob.Digest, err)
+									}
+									exists := err == nil
+									if exists {
+										klog.V(5).Infof("Server reports blob exists %#v", blob)
+										op.AlreadyExists(blob)
+										work.BytesSkipped(blob.Size)
+									}
+
+									lock.Lock()
+									defer lock.Unlock()
+									count++
+									size += blob.Size
+									if exists {
+										existingCount++
+										existingSize += blob.Size
+									}
+								})
+							}
+						}
+					})
+				})
+			}
+		})
+	}
+	if existingCount > 0 {
+		fmt.Fprintf(errOut, "info: %d of %d blobs (%s of %s) already exist on the destination and will not be copied\n", existingCount, count, units.BytesSize(float64(existingSize)), units.BytesSize(float64(size)))
+	}
+}
+
+// blobDigestMismatch is returned when the destination reports a different digest than the one of the copied blob.
+type blobDigestMismatch struct {
+	msg string
+}
+
+func (e *blobDigestMismatch) Error() string {
+	return e.msg
+}
+
+// isRetriableBlobError returns true unless copying the blob failed because the registry denied it or returned a
+// different blob, which another attempt cannot fix.
+func isRetriableBlobError(err error) bool {
+	switch t := err.(type) {
+	case errcode.Errors:
+		for _, err := range t {
+			if !isRetriableBlobError(err) {
+				return false
+			}
 		}
-		if err != distribution.ErrBlobUnknown {
-			klog.V(5).Infof("Server was unable to check whether blob exists %s: %v", blob.Digest, err)
+		return true
+	case errcode.Error:
+		return t.Code != errcode.ErrorCodeUnauthorized && t.Code != errcode.ErrorCodeDenied
+	case *blobDigestMismatch:
+		return false
+	default:
+		if wrapped := errors.Unwrap(err); wrapped != nil {
+			return isRetriableBlobError(wrapped)
 		}
+		return true
 	}
+}
+
+// retryBlobCopy runs copyfn until it succeeds, fails in a way that cannot be retried or runs out of attempts.
+func retryBlobCopy(backoff wait.Backoff, c *repositoryBlobCopy, blob distribution.Descriptor, errOut io.Writer, copyfn func() error) error {
+	attempt := 0
+	return retry.OnError(
+		backoff,
+		func(err error) bool {
+			if !isRetriableBlobError(err) {
+				return false
+			}
+			attempt++
+			if attempt < backoff.Steps {
+				fmt.Fprintf(errOut, "warning: Retrying blob %s to %s (%d/%d): %v\n", blob.Digest, c.toRef, attempt, backoff.Steps-1, err)
+			}
+			return true
+		},
+		copyfn,
+	)
+}
 
+func copyBlob(ctx context.Context, plan *workPlan, c *repositoryBlobCopy, blob distribution.Descriptor, referentialClient *http.Client, skipMount bool, backoff wait.Backoff, errOut io.Writer) error {
 	var expectMount string
 	var options []distribution.BlobCreateOption
 	if !skipMount {
@@ -689,19 +836,21 @@ func copyBlob(ctx context.Context, plan *workPlan, c *repositoryBlobCopy, blob d
 
 	// if the object is small enough, put directly
 	if blob.Size > 0 && blob.Size < 16384 {
-		data, err := from.Get(ctx, blob)
-		if err != nil {
-			return fmt.Errorf("unable to push %s: failed to retrieve blob %s: %s", c.fromRef, blob.Digest, err)
-		}
-		desc, err := c.to.Put(ctx, blob.MediaType, data)
-		if err != nil {
-			return fmt.Errorf("unable to push %s: failed to upload blob %s: %s", c.fromRef, blob.Digest, err)
-		}
-		if desc.Digest != blob.Digest {
-			return fmt.Errorf("unable to push %s: tried to copy blob %s and got back a different digest %s", c.fromRef, blob.Digest, desc.Digest)
-		}
-		plan.BytesCopied(blob.Size)
-		return nil
+		return retryBlobCopy(backoff, c, blob, errOut, func() error {
+			data, err := from.Get(ctx, blob)
+			if err != nil {
+				return fmt.Errorf("unable to push %s: failed to retrieve blob %s: %w", c.fromRef, blob.Digest, err)
+			}
+			desc, err := c.to.Put(ctx, blob.MediaType, data)
+			if err != nil {
+				return fmt.Errorf("unable to push %s: failed to upload blob %s: %w", c.fromRef, blob.Digest, err)
+			}
+			if desc.Digest != blob.Digest {
+				return &blobDigestMismatch{msg: fmt.Sprintf("unable to push %s: tried to copy blob %s and got back a different digest %s", c.fromRef, blob.Digest, desc.Digest)}
+			}
+			plan.BytesCopied(blob.Size)
+			return nil
+		})
 	}
 
 	if c.toRef.Type != imagesource.DestinationRegistry {
@@ -713,6 +862,7 @@ func copyBlob(ctx context.Context, plan *workPlan, c *repositoryBlobCopy, blob d
 		// no-op
 		if err == ErrAlreadyExists {
 			klog.V(5).Infof("Blob already exists %#v", blob)
+			plan.BytesSkipped(blob.Size)
 			return nil
 		}
 
@@ -720,13 +870,14 @@ func copyBlob(ctx context.Context, plan *workPlan, c *repositoryBlobCopy, blob d
 		if ebm, ok := err.(distribution.ErrBlobMounted); ok {
 			klog.V(5).Infof("Blob mounted %#v", blob)
 			if ebm.From.Digest() != blob.Digest {
-				return fmt.Errorf("unable to push %s: tried to mount blob %s source and got back a different digest %s", c.fromRef, blob.Digest, ebm.From.Digest())
+				return &blobDigestMismatch{msg: fmt.Sprintf("unable to push %s: tried to mount blob %s source and got back a different digest %s", c.fromRef, blob.Digest, ebm.From.Digest())}
 			}
 			fmt.Fprintf(errOut, "mounted: %s %s %s\n", c.toRef, blob.Digest, units.BytesSize(float64(blob.Size)))
+			plan.BytesMounted(blob.Size)
 			return nil
 		}
 		if err != nil {
-			return fmt.Errorf("unable to upload blob %s to %s: %v", blob.Digest, c.toRef, err)
+			return fmt.Errorf("unable to upload blob %s to %s: %w", blob.Digest, c.toRef, err)
 		}
 		defer w.Cancel(ctx)
 
@@ -737,7 +888,7 @@ func copyBlob(ctx context.Context, plan *workPlan, c *repositoryBlobCopy, blob d
 		klog.V(5).Infof("Uploading blob %s (%v)", blob.Digest, blob.URLs)
 		r, err := from.Open(ctx, blob)
 		if err != nil {
-			return fmt.Errorf("unable to open source layer %s to copy to %s: %v", blob.Digest, c.toRef, err)
+			return fmt.Errorf("unable to open source layer %s to copy to %s: %w", blob.Digest, c.toRef, err)
 		}
 		defer r.Close()
 
@@ -746,25 +897,19 @@ func copyBlob(ctx context.Context, plan *workPlan, c *repositoryBlobCopy, blob d
 		n, err := w.ReadFrom(r)
 		if err != nil {
 			klog.V(6).Infof("unable to copy layer %s to %s: %v", blob.Digest, c.toRef, err)
-			return fmt.Errorf("unable to copy layer %s to %s: %v", blob.Digest, c.toRef, err)
+			return fmt.Errorf("unable to copy layer %s to %s: %w", blob.Digest, c.toRef, err)
 		}
 		if n != blob.Size {
 			fmt.Fprintf(errOut, "warning: Layer size mismatch for %s: had %d, wrote %d\n", blob.Digest, blob.Size, n)
 		}
 		if _, err := w.Commit(ctx, blob); err != nil {
-			return fmt.Errorf("failed to commit blob %s from %s to %s: %v", blob.Digest, c.location, c.toRef, err)
+			return fmt.Errorf("failed to commit blob %s from %s to %s: %w", blob.Digest, c.location, c.toRef, err)
 		}
 		plan.BytesCopied(n)
 		return nil
 	}
 
-	return retry.OnError(
-		retry.DefaultRetry,
-		func(err error) bool {
-			return strings.Contains(err.Error(), "REFUSED_STREAM")
-		},
-		copyfn,
-	)
+	return retryBlobCopy(backoff, c, blob, errOut, copyfn)
 }
 
 // descriptorBlobSource abstracts copying blob contents from either a blob service or
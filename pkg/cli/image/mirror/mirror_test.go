@@ -0,0 +1,173 @@
+package mirror
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/registry/api/errcode"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func TestMirrorImageOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		o    func(o *MirrorImageOptions)
+		err  string
+	}{
+		{
+			name: "defaults",
+			o:    func(o *MirrorImageOptions) {},
+		},
+		{
+			name: "no concurrency",
+			o:    func(o *MirrorImageOptions) { o.ParallelOptions.MaxPerRegistry = 0 },
+			err:  "--max-per-registry must be at least 1",
+		},
+		{
+			name: "invalid registry concurrency",
+			o:    func(o *MirrorImageOptions) { o.MaxPerRegistryOverrides = map[string]int{"quay.io": 0} },
+			err:  "--max-per-registry-override for quay.io must be at least 1",
+		},
+		{
+			name: "negative retries",
+			o:    func(o *MirrorImageOptions) { o.MaxRetries = -1 },
+			err:  "--max-retries may not be negative",
+		},
+		{
+			name: "negative retry delay",
+			o:    func(o *MirrorImageOptions) { o.RetryDelay = -time.Second },
+			err:  "--retry-delay may not be negative",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := NewMirrorImageOptions(genericclioptions.IOStreams{})
+			test.o(o)
+			err := o.Validate()
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestMaxPerRegistry(t *testing.T) {
+	o := NewMirrorImageOptions(genericclioptions.IOStreams{})
+	o.MaxPerRegistryOverrides = map[string]int{"quay.io": 2}
+	if count := o.maxPerRegistry("quay.io"); count != 2 {
+		t.Errorf("expected the override to be used, got %d", count)
+	}
+	if count := o.maxPerRegistry("registry.example.com"); count != 6 {
+		t.Errorf("expected the default to be used, got %d", count)
+	}
+}
+
+func TestIsRetriableBlobError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retriable bool
+	}{
+		{
+			name:      "network error",
+			err:       fmt.Errorf("unable to copy layer: %w", fmt.Errorf("stream error: stream ID 3; REFUSED_STREAM")),
+			retriable: true,
+		},
+		{
+			name:      "server error",
+			err:       fmt.Errorf("unable to upload blob: %w", errcode.Errors{errcode.ErrorCodeUnavailable.WithMessage("try again")}),
+			retriable: true,
+		},
+		{
+			name: "denied",
+			err:  fmt.Errorf("unable to upload blob: %w", errcode.Errors{errcode.ErrorCodeDenied.WithMessage("denied")}),
+		},
+		{
+			name: "unauthorized",
+			err:  errcode.ErrorCodeUnauthorized.WithMessage("authentication required"),
+		},
+		{
+			name: "digest mismatch",
+			err:  &blobDigestMismatch{msg: "got back a different digest"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if retriable := isRetriableBlobError(test.err); retriable != test.retriable {
+				t.Errorf("expected retriable=%t, got %t", test.retriable, retriable)
+			}
+		})
+	}
+}
+
+func TestRetryBlobCopy(t *testing.T) {
+	backoff := wait.Backoff{Steps: 3, Duration: time.Millisecond, Factor: 2}
+	c := &repositoryBlobCopy{}
+	blob := distribution.Descriptor{Digest: "sha256:0000000000000000000000000000000000000000000000000000000000000000"}
+
+	errOut := &bytes.Buffer{}
+	attempts := 0
+	err := retryBlobCopy(backoff, c, blob, errOut, func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("connection reset by peer")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+	if retries := strings.Count(errOut.String(), "warning: Retrying blob"); retries != 2 {
+		t.Errorf("expected 2 retries to be reported, got:\n%s", errOut.String())
+	}
+
+	attempts = 0
+	err = retryBlobCopy(backoff, c, blob, &bytes.Buffer{}, func() error {
+		attempts++
+		return fmt.Errorf("connection reset by peer")
+	})
+	if err == nil || attempts != 3 {
+		t.Errorf("expected the copy to fail after 3 attempts, got %d attempts and error %v", attempts, err)
+	}
+
+	attempts = 0
+	err = retryBlobCopy(backoff, c, blob, &bytes.Buffer{}, func() error {
+		attempts++
+		return &blobDigestMismatch{msg: "got back a different digest"}
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("expected the copy to fail without retrying, got %d attempts and error %v", attempts, err)
+	}
+}
+
+func TestPrintTransferStats(t *testing.T) {
+	work := &workPlan{}
+	out := &bytes.Buffer{}
+	work.PrintTransferStats(out)
+	if out.Len() != 0 {
+		t.Errorf("expected nothing to be reported without transfers, got %q", out.String())
+	}
+
+	work.BytesCopied(1000)
+	work.BytesSkipped(2000)
+	work.BytesMounted(1000)
+	work.PrintTransferStats(out)
+	expected := "info: Copied 1000B, skipped 1.953KiB already on the destination and mounted 1000B (75.0% not transferred)\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
@@ -93,7 +93,9 @@ type workPlan struct {
 
 	lock  sync.Mutex
 	stats struct {
-		bytes int64
+		bytes        int64
+		skippedBytes int64
+		mountedBytes int64
 	}
 }
 
@@ -110,6 +112,32 @@ func (w *workPlan) BytesCopied(bytes int64) {
 	w.stats.bytes += bytes
 }
 
+// BytesSkipped records a blob that was not copied because the destination already has it.
+func (w *workPlan) BytesSkipped(bytes int64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.stats.skippedBytes += bytes
+}
+
+// BytesMounted records a blob the destination mounted from another repository instead of receiving it.
+func (w *workPlan) BytesMounted(bytes int64) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.stats.mountedBytes += bytes
+}
+
+// PrintTransferStats reports how much data was copied and how much was saved by skipping and mounting blobs.
+func (w *workPlan) PrintTransferStats(out io.Writer) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	saved := w.stats.skippedBytes + w.stats.mountedBytes
+	total := w.stats.bytes + saved
+	if total == 0 {
+		return
+	}
+	fmt.Fprintf(out, "info: Copied %s, skipped %s already on the destination and mounted %s (%.1f%% not transferred)\n", units.BytesSize(float64(w.stats.bytes)), units.BytesSize(float64(w.stats.skippedBytes)), units.BytesSize(float64(w.stats.mountedBytes)), float64(saved)*100/float64(total))
+}
+
 func (w *workPlan) Print(out io.Writer) {
 	tabw := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
 	for i := range w.phases {
@@ -0,0 +1,326 @@
+package kubecontext
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kclientcmd "k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/homedir"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc/pkg/helpers/term"
+)
+
+var (
+	contextLong = templates.LongDesc(`
+		Switch between the contexts of your configuration
+
+		The contexts of the configuration file hold the clusters, users and namespaces you use. With
+		a name, this command switches to the context of that name, or to the only context whose name
+		matches the name fuzzily: the characters of the name must appear in order in the name of the
+		context. When several contexts match, you are asked to pick one.
+
+		Without a name, the command asks you to pick a context among all the contexts when it runs in a
+		terminal, and lists the contexts otherwise. The previous context is recorded in
+		$HOME/.kube/oc-previous-context when you switch, and 'oc context -' switches back to it.
+
+		To manage the contents of your config file, use the 'config' command.
+	`)
+
+	contextExample = templates.Examples(`
+		# Pick the context to switch to
+		oc context
+
+		# Switch to the context whose name matches "prod" fuzzily
+		oc context prod
+
+		# Switch back to the previous context
+		oc context -
+
+		# List the contexts whose name matches "aws"
+		oc context --list aws
+
+		# Rename the current context
+		oc context --rename dev
+	`)
+)
+
+// previousContext is the argument that switches to the previous context.
+const previousContext = "-"
+
+type ContextOptions struct {
+	Query  string
+	List   bool
+	Rename string
+
+	Config              clientcmdapi.Config
+	PathOptions         *kclientcmd.PathOptions
+	PreviousContextFile string
+	Interactive         bool
+
+	genericclioptions.IOStreams
+}
+
+func NewContextOptions(streams genericclioptions.IOStreams) *ContextOptions {
+	return &ContextOptions{
+		IOStreams:           streams,
+		PathOptions:         kclientcmd.NewDefaultPathOptions(),
+		PreviousContextFile: filepath.Join(homedir.HomeDir(), ".kube", "oc-previous-context"),
+	}
+}
+
+// NewCmdContext implements the OpenShift cli context command
+func NewCmdContext(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewContextOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "context [NAME | -]",
+		Short:   "Switch to another context",
+		Long:    contextLong,
+		Example: contextExample,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			config, err := f.ToRawKubeConfigLoader().RawConfig()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return matchContexts(config, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.List, "list", "l", o.List, "List the contexts, or the contexts that match NAME, instead of switching.")
+	cmd.Flags().StringVar(&o.Rename, "rename", o.Rename, "Rename the context NAME, or the current context, to this name instead of switching.")
+
+	return cmd
+}
+
+func (o *ContextOptions) Complete(f genericclioptions.RESTClientGetter, cmd *cobra.Command, args []string) error {
+	switch len(args) {
+	case 0:
+	case 1:
+		o.Query = args[0]
+	default:
+		return kcmdutil.UsageErrorf(cmd, "only one context name is supported")
+	}
+
+	var err error
+	o.Config, err = f.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return err
+	}
+	// we need to set explicit path if one was specified, since NewDefaultPathOptions doesn't do it for us
+	o.PathOptions.LoadingRules.ExplicitPath = kcmdutil.GetFlagString(cmd, kclientcmd.RecommendedConfigPathFlag)
+	o.Interactive = term.IsTerminalReader(o.In)
+	return nil
+}
+
+func (o *ContextOptions) Run() error {
+	if len(o.Config.Contexts) == 0 {
+		return errors.New("there are no contexts in your configuration, use 'oc login' to log in to a server")
+	}
+
+	if o.List {
+		matches := matchContexts(o.Config, o.Query)
+		if len(matches) == 0 {
+			return fmt.Errorf("no context matches %q", o.Query)
+		}
+		printContexts(o.Out, o.Config, matches, false)
+		return nil
+	}
+
+	if len(o.Rename) > 0 {
+		name := o.Config.CurrentContext
+		if len(o.Query) > 0 {
+			var err error
+			if name, err = o.selectContext(o.Query); err != nil {
+				return err
+			}
+		}
+		return o.renameContext(name, o.Rename)
+	}
+
+	if o.Query == previousContext {
+		previous, err := o.previousContext()
+		if err != nil {
+			return err
+		}
+		return o.switchContext(previous)
+	}
+
+	if len(o.Query) == 0 && !o.Interactive {
+		printContexts(o.Out, o.Config, matchContexts(o.Config, ""), false)
+		return nil
+	}
+
+	name, err := o.selectContext(o.Query)
+	if err != nil {
+		return err
+	}
+	return o.switchContext(name)
+}
+
+// selectContext returns the context named query, or the only context that matches query fuzzily. When several
+// contexts match, the user picks one if the command runs in a terminal.
+func (o *ContextOptions) selectContext(query string) (string, error) {
+	if _, exists := o.Config.Contexts[query]; exists {
+		return query, nil
+	}
+	matches := matchContexts(o.Config, query)
+	switch {
+	case len(matches) == 0:
+		return "", fmt.Errorf("no context matches %q", query)
+	case len(matches) == 1:
+		return matches[0], nil
+	case !o.Interactive:
+		return "", fmt.Errorf("%d contexts match %q: %s", len(matches), query, strings.Join(matches, ", "))
+	}
+	return o.pickContext(matches)
+}
+
+// pickContext asks the user to pick one of the matches.
+func (o *ContextOptions) pickContext(matches []string) (string, error) {
+	name := term.Pick(o.In, o.Out, o.ErrOut, "context", matches, func(matches []string) {
+		printContexts(o.Out, o.Config, matches, true)
+	})
+	if len(name) == 0 {
+		return "", errors.New("no context selected")
+	}
+	return name, nil
+}
+
+// switchContext makes name the current context, and records the current context as the previous one.
+func (o *ContextOptions) switchContext(name string) error {
+	current := o.Config.CurrentContext
+	if name != current {
+		o.Config.CurrentContext = name
+		if err := kclientcmd.ModifyConfig(o.PathOptions, o.Config, true); err != nil {
+			return err
+		}
+		if len(current) > 0 {
+			if err := o.recordPreviousContext(current); err != nil {
+				fmt.Fprintf(o.ErrOut, "warning: unable to record the previous context: %v\n", err)
+			}
+		}
+	}
+
+	context := o.Config.Contexts[name]
+	namespace := context.Namespace
+	if len(namespace) == 0 {
+		namespace = "default"
+	}
+	server := ""
+	if cluster, exists := o.Config.Clusters[context.Cluster]; exists {
+		server = cluster.Server
+	}
+	fmt.Fprintf(o.Out, "Switched to context %q (namespace %q on server %q).\n", name, namespace, server)
+	return nil
+}
+
+// renameContext renames the context from to to, and keeps it current or previous.
+func (o *ContextOptions) renameContext(from, to string) error {
+	context, exists := o.Config.Contexts[from]
+	if !exists {
+		return fmt.Errorf("there is no context named %q", from)
+	}
+	if _, exists := o.Config.Contexts[to]; exists {
+		return fmt.Errorf("a context named %q already exists", to)
+	}
+
+	o.Config.Contexts[to] = context
+	delete(o.Config.Contexts, from)
+	if o.Config.CurrentContext == from {
+		o.Config.CurrentContext = to
+	}
+	if err := kclientcmd.ModifyConfig(o.PathOptions, o.Config, true); err != nil {
+		return err
+	}
+	if previous, err := o.readPreviousContext(); err == nil && previous == from {
+		if err := o.recordPreviousContext(to); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: unable to record the previous context: %v\n", err)
+		}
+	}
+	fmt.Fprintf(o.Out, "Context %q renamed to %q.\n", from, to)
+	return nil
+}
+
+// previousContext returns the recorded previous context, if it still exists.
+func (o *ContextOptions) previousContext() (string, error) {
+	previous, err := o.readPreviousContext()
+	if os.IsNotExist(err) {
+		return "", errors.New("no previous context was recorded")
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, exists := o.Config.Contexts[previous]; !exists {
+		return "", fmt.Errorf("the previous context %q no longer exists", previous)
+	}
+	return previous, nil
+}
+
+func (o *ContextOptions) readPreviousContext() (string, error) {
+	data, err := ioutil.ReadFile(o.PreviousContextFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (o *ContextOptions) recordPreviousContext(name string) error {
+	if err := os.MkdirAll(filepath.Dir(o.PreviousContextFile), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(o.PreviousContextFile, []byte(name+"\n"), 0600)
+}
+
+// matchContexts returns the contexts that match query fuzzily, best matches first.
+func matchContexts(config clientcmdapi.Config, query string) []string {
+	var names []string
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return term.FuzzyMatch(names, query)
+}
+
+// printContexts prints the matches, numbered if numbered is true, with the current context marked.
+func printContexts(out io.Writer, config clientcmdapi.Config, matches []string, numbered bool) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	if numbered {
+		fmt.Fprint(w, "#\t")
+	}
+	fmt.Fprintln(w, "CURRENT\tNAME\tCLUSTER\tSERVER\tUSER\tNAMESPACE")
+	for i, name := range matches {
+		context := config.Contexts[name]
+		current := ""
+		if name == config.CurrentContext {
+			current = "*"
+		}
+		server := ""
+		if cluster, exists := config.Clusters[context.Cluster]; exists {
+			server = cluster.Server
+		}
+		if numbered {
+			fmt.Fprintf(w, "%d\t", i+1)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", current, name, context.Cluster, server, context.AuthInfo, context.Namespace)
+	}
+	w.Flush()
+}
@@ -0,0 +1,181 @@
+package kubecontext
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	kclientcmd "k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func testConfig() *clientcmdapi.Config {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["aws"] = &clientcmdapi.Cluster{Server: "https://api.aws.example.com:6443"}
+	config.Clusters["gcp"] = &clientcmdapi.Cluster{Server: "https://api.gcp.example.com:6443"}
+	config.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "token"}
+	config.Contexts["dev-aws"] = &clientcmdapi.Context{Cluster: "aws", AuthInfo: "admin", Namespace: "dev"}
+	config.Contexts["prod-aws"] = &clientcmdapi.Context{Cluster: "aws", AuthInfo: "admin", Namespace: "prod"}
+	config.Contexts["prod-gcp"] = &clientcmdapi.Context{Cluster: "gcp", AuthInfo: "admin"}
+	config.CurrentContext = "dev-aws"
+	return config
+}
+
+// newTestOptions writes config to a temporary kubeconfig and returns options that use it.
+func newTestOptions(t *testing.T, config *clientcmdapi.Config, query string) *ContextOptions {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	if err := kclientcmd.WriteToFile(*config, path); err != nil {
+		t.Fatal(err)
+	}
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	o := NewContextOptions(streams)
+	o.Query = query
+	o.Config = *config
+	o.PathOptions.LoadingRules.ExplicitPath = path
+	o.PreviousContextFile = filepath.Join(dir, "previous-context")
+	return o
+}
+
+func loadConfig(t *testing.T, o *ContextOptions) *clientcmdapi.Config {
+	config, err := kclientcmd.LoadFromFile(o.PathOptions.LoadingRules.ExplicitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestMatchContexts(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "", expected: []string{"dev-aws", "prod-aws", "prod-gcp"}},
+		{query: "prod", expected: []string{"prod-aws", "prod-gcp"}},
+		{query: "aws", expected: []string{"dev-aws", "prod-aws"}},
+		{query: "pgcp", expected: []string{"prod-gcp"}},
+		{query: "PROD-AWS", expected: []string{"prod-aws"}},
+		{query: "azure"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if names := matchContexts(*testConfig(), tt.query); !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestSwitchContext(t *testing.T) {
+	o := newTestOptions(t, testConfig(), "pgcp")
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if current := loadConfig(t, o).CurrentContext; current != "prod-gcp" {
+		t.Errorf("expected to switch to prod-gcp, got %s", current)
+	}
+	if out := o.Out.(interface{ String() string }).String(); !strings.Contains(out, `Switched to context "prod-gcp" (namespace "default" on server "https://api.gcp.example.com:6443").`) {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	// switching back to the previous context
+	o.Query = "-"
+	o.Config = *loadConfig(t, o)
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if current := loadConfig(t, o).CurrentContext; current != "dev-aws" {
+		t.Errorf("expected to switch back to dev-aws, got %s", current)
+	}
+	if previous, err := o.previousContext(); err != nil || previous != "prod-gcp" {
+		t.Errorf("expected prod-gcp to be the previous context, got %q: %v", previous, err)
+	}
+}
+
+func TestSwitchContextErrors(t *testing.T) {
+	tests := []struct {
+		query         string
+		expectedError string
+	}{
+		{query: "prod", expectedError: `2 contexts match "prod": prod-aws, prod-gcp`},
+		{query: "azure", expectedError: `no context matches "azure"`},
+		{query: "-", expectedError: "no previous context was recorded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			o := newTestOptions(t, testConfig(), tt.query)
+			err := o.Run()
+			if err == nil || err.Error() != tt.expectedError {
+				t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+			}
+			if current := loadConfig(t, o).CurrentContext; current != "dev-aws" {
+				t.Errorf("expected the current context to be unchanged, got %s", current)
+			}
+		})
+	}
+}
+
+func TestPickContext(t *testing.T) {
+	o := newTestOptions(t, testConfig(), "prod")
+	o.Interactive = true
+	o.In = strings.NewReader("3\ngcp\n")
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if current := loadConfig(t, o).CurrentContext; current != "prod-gcp" {
+		t.Errorf("expected to switch to prod-gcp, got %s", current)
+	}
+
+	o = newTestOptions(t, testConfig(), "")
+	o.Interactive = true
+	o.In = strings.NewReader("2\n")
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if current := loadConfig(t, o).CurrentContext; current != "prod-aws" {
+		t.Errorf("expected to switch to prod-aws, got %s", current)
+	}
+}
+
+func TestListContexts(t *testing.T) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := newTestOptions(t, testConfig(), "aws")
+	o.IOStreams = streams
+	o.List = true
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `CURRENT   NAME       CLUSTER   SERVER                             USER    NAMESPACE
+*         dev-aws    aws       https://api.aws.example.com:6443   admin   dev
+          prod-aws   aws       https://api.aws.example.com:6443   admin   prod
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestRenameContext(t *testing.T) {
+	o := newTestOptions(t, testConfig(), "")
+	o.Rename = "dev"
+	if err := o.recordPreviousContext("dev-aws"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	config := loadConfig(t, o)
+	if _, exists := config.Contexts["dev-aws"]; exists || config.Contexts["dev"] == nil || config.CurrentContext != "dev" {
+		t.Errorf("expected dev-aws to be renamed to dev and to stay current, got %v, current %s", config.Contexts, config.CurrentContext)
+	}
+	if previous, err := o.previousContext(); err != nil || previous != "dev" {
+		t.Errorf("expected dev to be the previous context, got %q: %v", previous, err)
+	}
+
+	o = newTestOptions(t, testConfig(), "gcp")
+	o.Rename = "prod-aws"
+	if err := o.Run(); err == nil || err.Error() != `a context named "prod-aws" already exists` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -30,7 +30,6 @@ import (
 	"k8s.io/kubectl/pkg/cmd/label"
 	"k8s.io/kubectl/pkg/cmd/patch"
 	"k8s.io/kubectl/pkg/cmd/plugin"
-	"k8s.io/kubectl/pkg/cmd/portforward"
 	"k8s.io/kubectl/pkg/cmd/proxy"
 	"k8s.io/kubectl/pkg/cmd/replace"
 	"k8s.io/kubectl/pkg/cmd/run"
@@ -99,6 +98,11 @@ func NewCmdCreate(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	cmd.AddCommand(create.NewCmdCreateImageStream(f, streams))
 	cmd.AddCommand(create.NewCmdCreateImageStreamTag(f, streams))
 	cmd.AddCommand(create.NewCmdCreateBuild(f, streams))
+	cmd.AddCommand(create.NewCmdCreateExternalSecret(f, streams))
+
+	if generic, _, err := cmd.Find([]string{"secret", "generic"}); err == nil {
+		create.AddCreateSecretSOPSFlags(generic)
+	}
 
 	adjustCmdExamples(cmd, "create")
 
@@ -126,11 +130,6 @@ func NewCmdExec(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.
 	return cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(exec.NewCmdExec(f, streams)))
 }
 
-// NewCmdPortForward is a wrapper for the Kubernetes cli port-forward command
-func NewCmdPortForward(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
-	return cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(portforward.NewCmdPortForward(f, streams)))
-}
-
 // NewCmdDescribe is a wrapper for the Kubernetes cli describe command
 func NewCmdDescribe(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
 	return cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(describe.NewCmdDescribe("oc", f, streams)))
@@ -0,0 +1,181 @@
+package login
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+	restclient "k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc/pkg/helpers/keychain"
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
+)
+
+const (
+	// execInfoEnv is the environment variable in which the cluster information is passed to exec plugins
+	execInfoEnv = "KUBERNETES_EXEC_INFO"
+
+	// tokenExpiryMargin is how long before their expiry the access tokens are refreshed
+	tokenExpiryMargin = time.Minute
+)
+
+var (
+	execCredentialLong = templates.LongDesc(`
+		Print a token for the cluster as an ExecCredential
+
+		This command is the exec credential plugin configured by 'oc login --credential-store=keychain'
+		and 'oc login --web --setup-exec-plugin'. The tokens are kept in the keychain of the operating
+		system. The access token is refreshed with the refresh token when it expires, and the login in
+		the browser is started again when the token can't be refreshed and the command is run
+		interactively.
+	`)
+)
+
+// tokenRequester requests the tokens of the OAuth server.
+type tokenRequester interface {
+	RequestTokens() (*tokencmd.OAuthTokens, error)
+	RefreshTokens(refreshToken string) (*tokencmd.OAuthTokens, error)
+}
+
+// ExecCredentialOptions holds the options of the exec credential plugin.
+type ExecCredentialOptions struct {
+	Server      string
+	Interactive bool
+
+	Keychain keychain.Keychain
+	Tokens   tokenRequester
+
+	genericclioptions.IOStreams
+}
+
+// NewCmdExecCredential implements the exec credential plugin configured by login --credential-store=keychain.
+func NewCmdExecCredential(streams genericclioptions.IOStreams) *cobra.Command {
+	o := &ExecCredentialOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:    "exec-credential",
+		Short:  "Print a token for the cluster as an ExecCredential",
+		Long:   execCredentialLong,
+		Hidden: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(os.Getenv(execInfoEnv)))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+// Complete reads the cluster information client-go passes to the plugin in execInfo.
+func (o *ExecCredentialOptions) Complete(execInfo string) error {
+	if len(execInfo) == 0 {
+		return fmt.Errorf("%s is not set, this command must be run as an exec credential plugin", execInfoEnv)
+	}
+	credential := &clientauthenticationv1.ExecCredential{}
+	if err := json.Unmarshal([]byte(execInfo), credential); err != nil {
+		return fmt.Errorf("unable to decode %s: %v", execInfoEnv, err)
+	}
+	if credential.Spec.Cluster == nil {
+		return fmt.Errorf("%s does not contain the cluster, provideClusterInfo must be set in the kubeconfig", execInfoEnv)
+	}
+	cluster := credential.Spec.Cluster
+	o.Server = cluster.Server
+	o.Interactive = credential.Spec.Interactive
+
+	var err error
+	if o.Keychain, err = keychain.New(tokencmd.KeychainService); err != nil {
+		return err
+	}
+
+	clientConfig := &restclient.Config{
+		Host: cluster.Server,
+		TLSClientConfig: restclient.TLSClientConfig{
+			Insecure:   cluster.InsecureSkipTLSVerify,
+			ServerName: cluster.TLSServerName,
+			CAData:     cluster.CertificateAuthorityData,
+		},
+	}
+	// stdout holds the credential, the login messages go to stderr
+	o.Tokens = &tokencmd.WebLoginOptions{
+		ClientConfig: clientConfig,
+		OpenBrowser:  openBrowser,
+		Timeout:      webLoginTimeout,
+		Out:          o.ErrOut,
+	}
+	return nil
+}
+
+func (o *ExecCredentialOptions) Run() error {
+	tokens, err := o.tokens()
+	if err != nil {
+		return err
+	}
+
+	credential := &clientauthenticationv1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clientauthenticationv1.SchemeGroupVersion.String(),
+			Kind:       "ExecCredential",
+		},
+		Status: &clientauthenticationv1.ExecCredentialStatus{Token: tokens.AccessToken},
+	}
+	if !tokens.Expiry.IsZero() {
+		expiry := metav1.NewTime(tokens.Expiry.Add(-tokenExpiryMargin))
+		credential.Status.ExpirationTimestamp = &expiry
+	}
+	return json.NewEncoder(o.Out).Encode(credential)
+}
+
+// tokens returns the stored tokens if the access token is still valid, or new tokens, refreshed or requested with
+// a login in the browser, which are stored in the keychain.
+func (o *ExecCredentialOptions) tokens() (*tokencmd.OAuthTokens, error) {
+	stored, err := tokencmd.LoadTokens(o.Keychain, o.Server)
+	if err != nil {
+		return nil, err
+	}
+	if stored != nil && (stored.Expiry.IsZero() || time.Until(stored.Expiry) > tokenExpiryMargin) {
+		return stored, nil
+	}
+
+	var tokens *tokencmd.OAuthTokens
+	if stored != nil && len(stored.RefreshToken) > 0 {
+		if tokens, err = o.Tokens.RefreshTokens(stored.RefreshToken); err != nil {
+			fmt.Fprintf(o.ErrOut, "Unable to refresh the token for %s: %v\n", o.Server, err)
+		}
+	}
+	if tokens == nil {
+		if !o.Interactive {
+			return nil, fmt.Errorf("the session for %s expired, log in again with 'oc login %s'", o.Server, o.Server)
+		}
+		if tokens, err = o.Tokens.RequestTokens(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tokencmd.StoreTokens(o.Keychain, o.Server, tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// execPluginConfig returns the exec credential plugin configuration that runs this oc binary.
+func execPluginConfig() (*clientcmdapi.ExecConfig, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return &clientcmdapi.ExecConfig{
+		APIVersion:         clientauthenticationv1.SchemeGroupVersion.String(),
+		Command:            executable,
+		Args:               append([]string{}, tokencmd.ExecCredentialArgs...),
+		InstallHint:        "The oc binary that logged in to this cluster provides its credentials from the keychain, log in again with 'oc login'.",
+		ProvideClusterInfo: true,
+		InteractiveMode:    clientcmdapi.IfAvailableExecInteractiveMode,
+	}, nil
+}
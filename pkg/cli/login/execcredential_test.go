@@ -0,0 +1,138 @@
+package login
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientauthenticationv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+
+	"github.com/openshift/oc/pkg/helpers/keychain"
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
+)
+
+type fakeKeychain map[string]string
+
+func (k fakeKeychain) Get(account string) (string, error) {
+	secret, ok := k[account]
+	if !ok {
+		return "", keychain.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k fakeKeychain) Set(account, secret string) error {
+	k[account] = secret
+	return nil
+}
+
+func (k fakeKeychain) Delete(account string) error {
+	if _, ok := k[account]; !ok {
+		return keychain.ErrNotFound
+	}
+	delete(k, account)
+	return nil
+}
+
+type fakeTokenRequester struct {
+	refreshErr error
+	requested  bool
+	refreshed  string
+}
+
+func (r *fakeTokenRequester) RequestTokens() (*tokencmd.OAuthTokens, error) {
+	r.requested = true
+	return &tokencmd.OAuthTokens{AccessToken: "login", RefreshToken: "login-refresh", Expiry: time.Now().Add(24 * time.Hour)}, nil
+}
+
+func (r *fakeTokenRequester) RefreshTokens(refreshToken string) (*tokencmd.OAuthTokens, error) {
+	r.refreshed = refreshToken
+	if r.refreshErr != nil {
+		return nil, r.refreshErr
+	}
+	return &tokencmd.OAuthTokens{AccessToken: "refreshed", RefreshToken: refreshToken, Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func TestExecCredential(t *testing.T) {
+	const server = "https://api.example.com:6443"
+	valid := &tokencmd.OAuthTokens{AccessToken: "valid", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	expired := &tokencmd.OAuthTokens{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+	expiredWithoutRefresh := &tokencmd.OAuthTokens{AccessToken: "expired", Expiry: time.Now().Add(-time.Hour)}
+
+	tests := []struct {
+		name          string
+		stored        *tokencmd.OAuthTokens
+		interactive   bool
+		refreshErr    error
+		expectedToken string
+		expectedError string
+		expectRefresh bool
+		expectLogin   bool
+	}{
+		{name: "valid token", stored: valid, expectedToken: "valid"},
+		{name: "expired token is refreshed", stored: expired, expectedToken: "refreshed", expectRefresh: true},
+		{name: "failed refresh logs in again", stored: expired, interactive: true, refreshErr: errors.New("invalid_grant"), expectedToken: "login", expectRefresh: true, expectLogin: true},
+		{name: "no refresh token logs in again", stored: expiredWithoutRefresh, interactive: true, expectedToken: "login", expectLogin: true},
+		{name: "no token logs in", interactive: true, expectedToken: "login", expectLogin: true},
+		{
+			name:          "no refresh token without a terminal",
+			stored:        expiredWithoutRefresh,
+			expectedError: "the session for https://api.example.com:6443 expired, log in again with 'oc login https://api.example.com:6443'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := fakeKeychain{}
+			if tt.stored != nil {
+				if err := tokencmd.StoreTokens(k, server, tt.stored); err != nil {
+					t.Fatal(err)
+				}
+			}
+			requester := &fakeTokenRequester{refreshErr: tt.refreshErr}
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			o := &ExecCredentialOptions{Server: server, Interactive: tt.interactive, Keychain: k, Tokens: requester, IOStreams: streams}
+
+			err := o.Run()
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedError {
+				t.Fatalf("expected error %q, got %q", tt.expectedError, errStr)
+			}
+			if requester.requested != tt.expectLogin || (len(requester.refreshed) > 0) != tt.expectRefresh {
+				t.Errorf("unexpected requests: login %t, refresh %q", requester.requested, requester.refreshed)
+			}
+			if err != nil {
+				return
+			}
+
+			credential := &clientauthenticationv1.ExecCredential{}
+			if err := json.Unmarshal(out.Bytes(), credential); err != nil {
+				t.Fatal(err)
+			}
+			if credential.Kind != "ExecCredential" || credential.Status.Token != tt.expectedToken || credential.Status.ExpirationTimestamp == nil {
+				t.Errorf("unexpected credential: %s", out.String())
+			}
+			stored, err := tokencmd.LoadTokens(k, server)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.AccessToken != tt.expectedToken {
+				t.Errorf("expected the token %q to be stored, got %q", tt.expectedToken, stored.AccessToken)
+			}
+		})
+	}
+}
+
+func TestExecCredentialComplete(t *testing.T) {
+	o := &ExecCredentialOptions{}
+	if err := o.Complete(""); err == nil {
+		t.Errorf("expected an error without the exec info")
+	}
+	if err := o.Complete(`{"kind":"ExecCredential","apiVersion":"client.authentication.k8s.io/v1","spec":{"interactive":true}}`); err == nil {
+		t.Errorf("expected an error without the cluster")
+	}
+}
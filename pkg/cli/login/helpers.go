@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"runtime"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -114,3 +116,17 @@ func hasExistingInsecureCluster(clientConfigToTest restclient.Config, kubeconfig
 	matchingClusters := getMatchingClusters(clientConfigToTest, kubeconfig)
 	return len(matchingClusters) > 0
 }
+
+// openBrowser opens url with the default browser of the desktop.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
@@ -43,6 +43,18 @@ var (
 
 		# Log in to the given server with the given credentials (will not prompt interactively)
 		oc login localhost:8443 --username=myuser --password=mypass
+
+		# Log in to the given server with a browser
+		oc login localhost:8443 --web
+
+		# Log in with a browser on another device, from a host without a browser
+		oc login localhost:8443 --web --no-browser
+
+		# Log in with a browser and let oc refresh the token when it expires
+		oc login localhost:8443 --web --setup-exec-plugin
+
+		# Log in and keep the token in the keychain of the operating system instead of the config file
+		oc login localhost:8443 --username=myuser --credential-store=keychain
 	`)
 )
 
@@ -83,6 +95,14 @@ func NewCmdLogin(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra
 	cmds.Flags().StringVarP(&o.Username, "username", "u", o.Username, "Username for server")
 	cmds.Flags().StringVarP(&o.Password, "password", "p", o.Password, "Password for server")
 
+	cmds.Flags().BoolVar(&o.WebLogin, "web", o.WebLogin, "Log in with a browser, which is redirected to a callback on localhost after the login.")
+	cmds.Flags().BoolVar(&o.NoBrowser, "no-browser", o.NoBrowser, "With --web, do not open a browser: print the login URL instead, or the code to enter on another device if the server supports the device authorization grant.")
+	cmds.Flags().IntVar(&o.CallbackPort, "callback-port", o.CallbackPort, "With --web, the port of the localhost callback. Defaults to a random port.")
+	cmds.Flags().BoolVar(&o.SetupExecPlugin, "setup-exec-plugin", o.SetupExecPlugin, "With --web, keep the tokens in the keychain of the operating system and configure oc as the exec credential plugin of the cluster, which refreshes the token when it expires.")
+	cmds.Flags().StringVar(&o.CredentialStore, "credential-store", o.CredentialStore, "Where to save the token: kubeconfig, or keychain to keep it in the keychain of the operating system (macOS Keychain, Windows Credential Manager or Secret Service on Linux) with the config file referencing it. Defaults to kubeconfig, or keychain with --setup-exec-plugin.")
+
+	cmds.AddCommand(NewCmdExecCredential(streams))
+
 	return cmds
 }
 
@@ -163,6 +183,28 @@ func (o LoginOptions) Validate(cmd *cobra.Command, serverFlag string, args []str
 		return errors.New("--token and --username are mutually exclusive")
 	}
 
+	if o.WebLogin {
+		if len(o.Token) > 0 || len(o.Username) > 0 || len(o.Password) > 0 {
+			return errors.New("--web is mutually exclusive with --token, --username and --password")
+		}
+	} else if o.NoBrowser || o.CallbackPort != 0 || o.SetupExecPlugin {
+		return errors.New("--no-browser, --callback-port and --setup-exec-plugin require --web")
+	}
+
+	switch o.CredentialStore {
+	case credentialStoreKubeconfig:
+		if o.SetupExecPlugin {
+			return errors.New("--setup-exec-plugin keeps the tokens in the keychain and can't be used with --credential-store=kubeconfig")
+		}
+	case "", credentialStoreKeychain:
+	default:
+		return fmt.Errorf("--credential-store must be %s or %s", credentialStoreKubeconfig, credentialStoreKeychain)
+	}
+
+	if o.CallbackPort < 0 || o.CallbackPort > 65535 {
+		return errors.New("--callback-port must be between 0 and 65535")
+	}
+
 	if o.StartingKubeConfig == nil {
 		return errors.New("Must have a config file already created")
 	}
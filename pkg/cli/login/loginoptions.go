@@ -23,6 +23,7 @@ import (
 
 	projectv1typedclient "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
 	"github.com/openshift/oc/pkg/helpers/errors"
+	"github.com/openshift/oc/pkg/helpers/keychain"
 	cliconfig "github.com/openshift/oc/pkg/helpers/kubeconfig"
 	"github.com/openshift/oc/pkg/helpers/motd"
 	"github.com/openshift/oc/pkg/helpers/project"
@@ -35,6 +36,14 @@ const defaultClusterURL = "https://localhost:8443"
 
 const projectsItemsSuppressThreshold = 50
 
+// webLoginTimeout is how long to wait for the user to log in with a browser
+const webLoginTimeout = 5 * time.Minute
+
+const (
+	credentialStoreKubeconfig = "kubeconfig"
+	credentialStoreKeychain   = "keychain"
+)
+
 // LoginOptions is a helper for the login and setup process, gathers all information required for a
 // successful login and eventual update of config files.
 // Depending on the Reader present it can be interactive, asking for terminal input in
@@ -62,6 +71,17 @@ type LoginOptions struct {
 
 	Token string
 
+	// web login, in a browser
+	WebLogin     bool
+	NoBrowser    bool
+	CallbackPort int
+	// SetupExecPlugin saves the exec credential plugin that refreshes the tokens in the config, instead of the token
+	SetupExecPlugin bool
+	// CredentialStore is where the token is saved: in the config file, or in the keychain of the operating system
+	// with the exec credential plugin in the config file
+	CredentialStore string
+	execProvider    *kclientcmdapi.ExecConfig
+
 	PathOptions *kclientcmd.PathOptions
 
 	CommandName    string
@@ -206,6 +226,11 @@ func (o *LoginOptions) gatherAuthInfo() error {
 		o.Username = me.Name
 		o.Config = clientConfig
 
+		if o.useKeychain() {
+			if err := o.setupExecPlugin(&tokencmd.OAuthTokens{AccessToken: o.Token}); err != nil {
+				return err
+			}
+		}
 		fmt.Fprintf(o.Out, "Logged into %q as %q using the token provided.\n\n", o.Config.Host, o.Username)
 		return nil
 	}
@@ -246,11 +271,11 @@ func (o *LoginOptions) gatherAuthInfo() error {
 	clientConfig.CertFile = o.CertFile
 	clientConfig.KeyFile = o.KeyFile
 
-	token, err := tokencmd.RequestToken(o.Config, o.In, o.Username, o.Password)
+	tokens, err := o.requestToken()
 	if err != nil {
 		return err
 	}
-	clientConfig.BearerToken = token
+	clientConfig.BearerToken = tokens.AccessToken
 
 	me, err := project.WhoAmI(clientConfig)
 	if err != nil {
@@ -258,11 +283,59 @@ func (o *LoginOptions) gatherAuthInfo() error {
 	}
 	o.Username = me.Name
 	o.Config = clientConfig
+
+	if o.useKeychain() {
+		if err := o.setupExecPlugin(tokens); err != nil {
+			return err
+		}
+	}
 	fmt.Fprint(o.Out, "Login successful.\n\n")
 
 	return nil
 }
 
+// requestToken requests a token from the auth server, with the login in a browser if requested, or with the
+// challenge handlers otherwise.
+func (o *LoginOptions) requestToken() (*tokencmd.OAuthTokens, error) {
+	if !o.WebLogin {
+		token, err := tokencmd.RequestToken(o.Config, o.In, o.Username, o.Password)
+		if err != nil {
+			return nil, err
+		}
+		return &tokencmd.OAuthTokens{AccessToken: token}, nil
+	}
+
+	webLogin := &tokencmd.WebLoginOptions{
+		ClientConfig: o.Config,
+		CallbackPort: o.CallbackPort,
+		Timeout:      webLoginTimeout,
+		Out:          o.Out,
+	}
+	if !o.NoBrowser {
+		webLogin.OpenBrowser = openBrowser
+	}
+	return webLogin.RequestTokens()
+}
+
+// setupExecPlugin stores the tokens in the keychain, for the exec credential plugin that is saved in the config
+// instead of the token.
+func (o *LoginOptions) setupExecPlugin(tokens *tokencmd.OAuthTokens) error {
+	k, err := keychain.New(tokencmd.KeychainService)
+	if err != nil {
+		return fmt.Errorf("unable to set up the exec plugin: %v", err)
+	}
+	if err := tokencmd.StoreTokens(k, o.Config.Host, tokens); err != nil {
+		return fmt.Errorf("unable to store the tokens in the keychain: %v", err)
+	}
+	if o.execProvider, err = execPluginConfig(); err != nil {
+		return err
+	}
+	if o.SetupExecPlugin && len(tokens.RefreshToken) == 0 {
+		fmt.Fprintf(o.ErrOut, "warning: the server did not issue a refresh token, the login in the browser will start again when the token expires\n")
+	}
+	return nil
+}
+
 // Discover the projects available for the established session and take one to use. It
 // fails in case of no existing projects, and print out useful information in case of
 // multiple projects.
@@ -380,7 +453,14 @@ func (o *LoginOptions) SaveConfig() (bool, error) {
 		globalExistedBefore = false
 	}
 
-	newConfig, err := cliconfig.CreateConfig(o.Project, o.Username, o.Config)
+	clientConfig := o.Config
+	if o.execProvider != nil {
+		// the exec plugin provides the token, which is not saved
+		clientConfig = restclient.CopyConfig(o.Config)
+		clientConfig.BearerToken = ""
+		clientConfig.ExecProvider = o.execProvider
+	}
+	newConfig, err := cliconfig.CreateConfig(o.Project, o.Username, clientConfig)
 	if err != nil {
 		return false, err
 	}
@@ -432,6 +512,10 @@ func (o *LoginOptions) serverProvided() bool {
 	return (len(o.Server) > 0)
 }
 
+func (o *LoginOptions) useKeychain() bool {
+	return o.SetupExecPlugin || o.CredentialStore == credentialStoreKeychain
+}
+
 func (o *LoginOptions) tokenProvided() bool {
 	return len(o.Token) > 0
 }
@@ -2,11 +2,8 @@ package logout
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
 	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -20,14 +17,16 @@ import (
 	"k8s.io/kubectl/pkg/util/templates"
 
 	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	"github.com/openshift/oc/pkg/helpers/keychain"
 	"github.com/openshift/oc/pkg/helpers/project"
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
 )
 
-const sha256Prefix = "sha256~"
-
 type LogoutOptions struct {
 	StartingKubeConfig *kclientcmdapi.Config
 	Config             *restclient.Config
+	// Keychain is set when the token of the session is kept in the keychain of the operating system
+	Keychain keychain.Keychain
 
 	PathOptions *kclientcmd.PathOptions
 
@@ -39,7 +38,8 @@ var (
 		Log out of the active session out by clearing saved tokens.
 
 		An authentication token is stored in the config file after login - this command will delete
-		that token on the server, and then remove the token from the configuration file.
+		that token on the server, and then remove the token from the configuration file. When the
+		token is kept in the keychain of the operating system, it is removed from the keychain.
 
 		If you are using an alternative authentication method like Kerberos or client certificates,
 		your ticket or client certificate will not be removed from the current system since these
@@ -93,6 +93,21 @@ func (o *LogoutOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []
 		return err
 	}
 
+	if tokencmd.UsesKeychain(o.Config.ExecProvider) {
+		if o.Keychain, err = keychain.New(tokencmd.KeychainService); err != nil {
+			return err
+		}
+		tokens, err := tokencmd.LoadTokens(o.Keychain, o.Config.Host)
+		if err != nil {
+			return err
+		}
+		if tokens != nil {
+			o.Config.BearerToken = tokens.AccessToken
+		}
+		// use the token directly rather than through the exec plugin, which could start a new login
+		o.Config.ExecProvider = nil
+	}
+
 	o.PathOptions = kclientcmd.NewDefaultPathOptions()
 	// we need to set explicit path if one was specified, since NewDefaultPathOptions doesn't do it for us
 	o.PathOptions.LoadingRules.ExplicitPath = kcmdutil.GetFlagString(cmd, kclientcmd.RecommendedConfigPathFlag)
@@ -118,7 +133,6 @@ func (o LogoutOptions) Validate(args []string) error {
 
 func (o LogoutOptions) RunLogout() error {
 	token := o.Config.BearerToken
-	tokenName := o.Config.BearerToken
 
 	client, err := oauthv1client.NewForConfig(o.Config)
 	if err != nil {
@@ -130,15 +144,19 @@ func (o LogoutOptions) RunLogout() error {
 		return err
 	}
 
-	if strings.HasPrefix(tokenName, sha256Prefix) {
-		tokenName = tokenToObjectName(tokenName)
-	}
-
-	if err := client.OAuthAccessTokens().Delete(context.TODO(), tokenName, metav1.DeleteOptions{}); err != nil {
+	if err := client.OAuthAccessTokens().Delete(context.TODO(), tokencmd.AccessTokenName(token), metav1.DeleteOptions{}); err != nil {
 		klog.V(1).Infof("%v", err)
 	}
 
-	configErr := deleteTokenFromConfig(*o.StartingKubeConfig, o.PathOptions, token)
+	var configErr error
+	if o.Keychain != nil {
+		if err := o.Keychain.Delete(o.Config.Host); err != nil && !errors.Is(err, keychain.ErrNotFound) {
+			return err
+		}
+		configErr = deleteExecPluginFromConfig(*o.StartingKubeConfig, o.PathOptions, o.Config.Host)
+	} else {
+		configErr = deleteTokenFromConfig(*o.StartingKubeConfig, o.PathOptions, token)
+	}
 	if configErr == nil {
 		klog.V(1).Infof("Removed token from your local configuration.")
 
@@ -161,10 +179,17 @@ func deleteTokenFromConfig(config kclientcmdapi.Config, pathOptions *kclientcmd.
 	return kclientcmd.ModifyConfig(pathOptions, config, true)
 }
 
-// tokenToObjectName returns the oauthaccesstokens object name for the given raw token,
-// i.e. the sha256 hash prefixed with "sha256~".
-func tokenToObjectName(token string) string {
-	name := strings.TrimPrefix(token, sha256Prefix)
-	h := sha256.Sum256([]byte(name))
-	return sha256Prefix + base64.RawURLEncoding.EncodeToString(h[0:])
+// deleteExecPluginFromConfig removes the exec plugin that provides the tokens of server stored in the keychain from
+// the users of the contexts of server.
+func deleteExecPluginFromConfig(config kclientcmdapi.Config, pathOptions *kclientcmd.PathOptions, server string) error {
+	for _, kubeContext := range config.Contexts {
+		cluster, ok := config.Clusters[kubeContext.Cluster]
+		if !ok || cluster.Server != server {
+			continue
+		}
+		if authInfo, ok := config.AuthInfos[kubeContext.AuthInfo]; ok && tokencmd.UsesKeychain(authInfo.Exec) {
+			authInfo.Exec = nil
+		}
+	}
+	return kclientcmd.ModifyConfig(pathOptions, config, true)
 }
@@ -3,6 +3,8 @@ package logs
 import (
 	"context"
 	"fmt"
+	"io"
+	"time"
 
 	"k8s.io/kubectl/pkg/cmd/logs"
 
@@ -11,6 +13,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -19,8 +24,12 @@ import (
 	buildv1 "github.com/openshift/api/build/v1"
 	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 	buildhelpers "github.com/openshift/oc/pkg/helpers/build"
+	"github.com/openshift/oc/pkg/helpers/term"
 )
 
+// buildStatusTimeout is how long the status of a build is awaited after its logs were followed.
+const buildStatusTimeout = 10 * time.Second
+
 var (
 	logsLong = templates.LongDesc(`
 		Print the logs for a resource.
@@ -32,6 +41,20 @@ var (
 
 		If your pod is failing to start, you may need to use the --previous option to see the
 		logs of the last attempt.
+
+		With a label selector (-l), the logs of all the pods that match the selector are printed,
+		each line prefixed with the pod and container names, in color in a terminal. When
+		following the logs (-f), the logs of the pods are streamed concurrently, and the pods that
+		start later, for instance during a rollout, are followed as they start running.
+
+		The log lines that are JSON objects can be rendered with only some of their fields with
+		--fields, nested fields being separated by dots, and filtered by level with --level-filter,
+		which keeps the lines of that level and of more important levels. Lines that are not JSON
+		objects, or that have no level, are printed as they are.
+
+		The logs of builds are annotated with the durations of their clone, build and push stages,
+		and the errors found in the logs of a failed build are summarized at the end, unless
+		--build-summary=false is passed.
 	`)
 
 	logsExample = templates.Examples(`
@@ -51,6 +74,12 @@ var (
 
 		# Start streaming of ruby-container logs from pod backend
 		oc logs -f pod/backend -c ruby-container
+
+		# Start streaming the logs of all the containers of the pods labeled app=frontend, including new pods
+		oc logs -f -l app=frontend --all-containers
+
+		# Print the level, message and timestamp of the warnings and errors of the JSON logs of pod backend
+		oc logs backend --fields level,msg,ts --level-filter warn
 	`)
 )
 
@@ -59,16 +88,28 @@ type LogsOptions struct {
 	// Client enables access to the Build object when processing
 	// build logs for Jenkins Pipeline Strategy builds
 	Client buildv1client.BuildV1Interface
+	// KubeClient streams the logs of the pods that match the selector
+	KubeClient kubernetes.Interface
 
 	Version int64
 
+	// Fields are the fields of JSON log lines to print
+	Fields []string
+	// LevelFilter is the least important level of the JSON log lines to print
+	LevelFilter string
+	structured  *structuredLogs
+
+	// BuildSummary annotates the logs of builds with the durations of their stages, and summarizes their errors
+	BuildSummary bool
+
 	// Embed kubectl's LogsOptions directly.
 	*logs.LogsOptions
 }
 
 func NewLogsOptions(streams genericclioptions.IOStreams) *LogsOptions {
 	return &LogsOptions{
-		LogsOptions: logs.NewLogsOptions(streams, false),
+		BuildSummary: true,
+		LogsOptions:  logs.NewLogsOptions(streams, false),
 	}
 }
 
@@ -90,6 +131,9 @@ func NewCmdLogs(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.
 
 	o.LogsOptions.AddFlags(cmd)
 	cmd.Flags().Int64Var(&o.Version, "version", o.Version, "View the logs of a particular build or deployment by version if greater than zero")
+	cmd.Flags().StringSliceVar(&o.Fields, "fields", o.Fields, "Print only these comma-separated fields of the log lines that are JSON objects, e.g. level,msg,ts")
+	cmd.Flags().StringVar(&o.LevelFilter, "level-filter", o.LevelFilter, "Print only the JSON log lines of this level or of a more important one: trace, debug, info, warn, error or fatal")
+	cmd.Flags().BoolVar(&o.BuildSummary, "build-summary", o.BuildSummary, "Annotate the logs of builds with the durations of their stages, and summarize the errors of failed builds")
 
 	return cmd
 }
@@ -106,7 +150,22 @@ func (o *LogsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []st
 	if err != nil {
 		return err
 	}
-	return o.LogsOptions.Complete(f, cmd, args)
+	o.KubeClient, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	if err := o.LogsOptions.Complete(f, cmd, args); err != nil {
+		return err
+	}
+
+	o.structured, err = newStructuredLogs(o.Fields, o.LevelFilter)
+	if err != nil {
+		return err
+	}
+	if o.structured != nil {
+		o.LogsOptions.ConsumeRequestFn = o.structured.ConsumeRequest
+	}
+	return nil
 }
 
 // Validate runs the upstream validation for the logs command and then it
@@ -119,18 +178,24 @@ func (o *LogsOptions) Validate(args []string) error {
 // logOptions object.
 func (o *LogsOptions) RunLog() error {
 	podLogOptions := o.LogsOptions.Options.(*corev1.PodLogOptions)
+	if len(o.LogsOptions.Selector) > 0 {
+		return o.selectorLogs(podLogOptions).Run(context.Background())
+	}
 	var (
 		isPipeline bool
 		build      *buildv1.Build
+		// buildName is the build whose logs are printed
+		buildName string
 	)
 	switch t := o.LogsOptions.Object.(type) {
 	case *buildv1.Build:
 		build = t
+		buildName = t.Name
 		isPipeline = t.Spec.CommonSpec.Strategy.JenkinsPipelineStrategy != nil
 		o.LogsOptions.Options = o.buildLogOptions(podLogOptions)
 
 	case *buildv1.BuildConfig:
-		buildName := buildhelpers.BuildNameForConfigVersion(t.ObjectMeta.Name, int(t.Status.LastVersion))
+		buildName = buildhelpers.BuildNameForConfigVersion(t.ObjectMeta.Name, int(t.Status.LastVersion))
 		isPipeline = t.Spec.CommonSpec.Strategy.JenkinsPipelineStrategy != nil
 		if isPipeline {
 			build, _ = o.Client.Builds(o.LogsOptions.Namespace).Get(context.TODO(), buildName, metav1.GetOptions{})
@@ -139,12 +204,18 @@ func (o *LogsOptions) RunLog() error {
 			}
 		}
 		o.LogsOptions.Options = o.buildLogOptions(podLogOptions)
+		if o.Version != 0 {
+			buildName = buildhelpers.BuildNameForConfigVersion(t.Name, int(o.Version))
+		}
 
 	case *appsv1.DeploymentConfig:
 		o.LogsOptions.Options = o.deployLogOptions(podLogOptions)
 	}
 
 	if !isPipeline {
+		if len(buildName) > 0 && o.BuildSummary {
+			return o.runBuildLogs(buildName)
+		}
 		return o.LogsOptions.RunLogs()
 	}
 
@@ -157,6 +228,61 @@ func (o *LogsOptions) RunLog() error {
 	return nil
 }
 
+// runBuildLogs prints the logs of the build annotated with the durations of its stages, which are computed with the
+// timestamps of the log lines, and the summary of its errors if it failed.
+func (o *LogsOptions) runBuildLogs(name string) error {
+	options := o.LogsOptions.Options.(*buildv1.BuildLogOptions)
+	timestamps := options.Timestamps
+	options.Timestamps = true
+
+	var annotator *buildhelpers.LogAnnotator
+	o.LogsOptions.ConsumeRequestFn = func(request rest.ResponseWrapper, out io.Writer) error {
+		if o.structured != nil {
+			out = o.structured.Writer(out)
+		}
+		annotator = buildhelpers.NewLogAnnotator(out, timestamps)
+		err := logs.DefaultConsumeRequest(request, annotator)
+		if closeErr := annotator.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+	if err := o.LogsOptions.RunLogs(); err != nil {
+		return err
+	}
+	if annotator == nil {
+		return nil
+	}
+
+	timeout := time.Duration(0)
+	if options.Follow {
+		timeout = buildStatusTimeout
+	}
+	build, err := buildhelpers.GetFinishedBuild(context.TODO(), o.Client.Builds(o.LogsOptions.Namespace), name, timeout)
+	if err != nil {
+		klog.V(4).Infof("Unable to get the status of the build %s: %v", name, err)
+		return nil
+	}
+	annotator.PrintFailureSummary(o.LogsOptions.Out, build)
+	return nil
+}
+
+func (o *LogsOptions) selectorLogs(podLogOptions *corev1.PodLogOptions) *selectorLogs {
+	return &selectorLogs{
+		client:        o.KubeClient.CoreV1(),
+		namespace:     o.LogsOptions.Namespace,
+		selector:      o.LogsOptions.Selector,
+		options:       podLogOptions,
+		allContainers: o.LogsOptions.AllContainers,
+		maxStreams:    o.LogsOptions.MaxFollowConcurrency,
+		ignoreErrors:  o.LogsOptions.IgnoreLogErrors,
+		color:         term.IsTerminalWriter(o.LogsOptions.Out),
+		structured:    o.structured,
+		out:           o.LogsOptions.Out,
+		errOut:        o.LogsOptions.ErrOut,
+	}
+}
+
 func (o *LogsOptions) buildLogOptions(podLogOptions *corev1.PodLogOptions) *buildv1.BuildLogOptions {
 	bopts := &buildv1.BuildLogOptions{
 		Container:                    podLogOptions.Container,
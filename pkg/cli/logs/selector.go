@@ -0,0 +1,278 @@
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// defaultContainerAnnotation names the container to use when no container is specified
+const defaultContainerAnnotation = "kubectl.kubernetes.io/default-container"
+
+// prefixColors are the ANSI colors of the prefixes of the log lines, picked by pod and container
+var prefixColors = []int{31, 32, 33, 34, 35, 36, 91, 92, 93, 94, 95, 96}
+
+// selectorLogs streams the logs of the containers of the pods that match a label selector, each line prefixed with
+// the pod and container names. When following the logs, the logs of all the containers are streamed concurrently,
+// and the containers of the pods that start later are streamed as they start running.
+type selectorLogs struct {
+	client        corev1client.PodsGetter
+	namespace     string
+	selector      string
+	options       *corev1.PodLogOptions
+	allContainers bool
+	maxStreams    int
+	ignoreErrors  bool
+	color         bool
+	// structured processes the log lines, if set
+	structured *structuredLogs
+
+	out    io.Writer
+	errOut io.Writer
+
+	lock sync.Mutex
+	// streaming holds the containers streamed, by pod UID and container name
+	streaming map[string]bool
+	// restarts holds the restart count of the containers when their stream started
+	restarts map[string]int32
+	// skipped holds the containers not streamed because too many streams are active
+	skipped map[string]bool
+	active  int
+}
+
+func (s *selectorLogs) Run(ctx context.Context) error {
+	pods, err := s.client.Pods(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: s.selector})
+	if err != nil {
+		return err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	if !s.options.Follow {
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			for _, container := range s.containers(pod) {
+				if status := containerStatus(pod, container); status == nil || (status.State.Running == nil && status.State.Terminated == nil) {
+					fmt.Fprintf(s.errOut, "Container %s in pod/%s is waiting to start\n", container, pod.Name)
+					continue
+				}
+				if err := s.stream(ctx, pod, container); err != nil {
+					if !s.ignoreErrors {
+						return err
+					}
+					fmt.Fprintf(s.errOut, "error: %v\n", err)
+				}
+			}
+		}
+		return nil
+	}
+
+	s.streaming, s.restarts, s.skipped = map[string]bool{}, map[string]int32{}, map[string]bool{}
+	errs := make(chan error, 1)
+	for i := range pods.Items {
+		s.attach(ctx, &pods.Items[i], errs)
+	}
+
+	resourceVersion := pods.ResourceVersion
+	for {
+		w, err := s.client.Pods(s.namespace).Watch(ctx, metav1.ListOptions{LabelSelector: s.selector, ResourceVersion: resourceVersion})
+		if err != nil {
+			return err
+		}
+		resourceVersion, err = s.watch(ctx, w, resourceVersion, errs)
+		w.Stop()
+		if err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// watch attaches to the pods added or modified until the watch w ends, and returns the resource version to
+// watch from next.
+func (s *selectorLogs) watch(ctx context.Context, w watch.Interface, resourceVersion string, errs chan error) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion, nil
+		case err := <-errs:
+			return resourceVersion, err
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion, nil
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if pod, ok := event.Object.(*corev1.Pod); ok {
+					resourceVersion = pod.ResourceVersion
+					s.attach(ctx, pod, errs)
+				}
+			case watch.Error:
+				err := kapierrors.FromObject(event.Object)
+				if !kapierrors.IsResourceExpired(err) && !kapierrors.IsGone(err) {
+					return resourceVersion, err
+				}
+				// the resource version is too old, attach to the pods that started in between
+				pods, err := s.client.Pods(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: s.selector})
+				if err != nil {
+					return resourceVersion, err
+				}
+				for i := range pods.Items {
+					s.attach(ctx, &pods.Items[i], errs)
+				}
+				return pods.ResourceVersion, nil
+			}
+		}
+	}
+}
+
+// attach starts streaming the logs of the running containers of pod that are not streamed yet, or that restarted
+// since their stream ended.
+func (s *selectorLogs) attach(ctx context.Context, pod *corev1.Pod, errs chan error) {
+	for _, container := range s.containers(pod) {
+		status := containerStatus(pod, container)
+		if status == nil || status.State.Running == nil {
+			continue
+		}
+		key := string(pod.UID) + "/" + pod.Name + "/" + container
+
+		s.lock.Lock()
+		restarts, seen := s.restarts[key]
+		if s.streaming[key] || (seen && status.RestartCount <= restarts) {
+			s.lock.Unlock()
+			continue
+		}
+		if s.active >= s.maxStreams {
+			if !s.skipped[key] {
+				s.skipped[key] = true
+				fmt.Fprintf(s.errOut, "warning: not following container %s in pod/%s, %d logs are already followed, use --max-log-requests to increase the limit\n", container, pod.Name, s.active)
+			}
+			s.lock.Unlock()
+			continue
+		}
+		s.streaming[key], s.restarts[key] = true, status.RestartCount
+		delete(s.skipped, key)
+		s.active++
+		s.lock.Unlock()
+
+		go func(pod *corev1.Pod, container string) {
+			err := s.stream(ctx, pod, container)
+
+			s.lock.Lock()
+			s.streaming[key] = false
+			s.active--
+			s.lock.Unlock()
+
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			if s.ignoreErrors {
+				fmt.Fprintf(s.errOut, "error: %v\n", err)
+				return
+			}
+			select {
+			case errs <- err:
+			default:
+			}
+		}(pod, container)
+	}
+}
+
+// stream writes the logs of container in pod to the output, line by line, with the prefix of the container.
+func (s *selectorLogs) stream(ctx context.Context, pod *corev1.Pod, container string) error {
+	options := s.options.DeepCopy()
+	options.Container = container
+	logs, err := s.client.Pods(pod.Namespace).GetLogs(pod.Name, options).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	prefix := s.prefix(pod.Name, container)
+	r := bufio.NewReader(logs)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && s.structured != nil {
+			var keep bool
+			if line, keep = s.structured.process(line); !keep {
+				line = nil
+			}
+		}
+		if len(line) > 0 {
+			if line[len(line)-1] != '\n' {
+				line = append(line, '\n')
+			}
+			s.lock.Lock()
+			_, writeErr := s.out.Write(append([]byte(prefix), line...))
+			s.lock.Unlock()
+			if writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// prefix returns the prefix of the log lines of container in pod, colored if the output supports it.
+func (s *selectorLogs) prefix(pod, container string) string {
+	prefix := fmt.Sprintf("[pod/%s/%s]", pod, container)
+	if !s.color {
+		return prefix + " "
+	}
+	h := fnv.New32a()
+	h.Write([]byte(pod + "/" + container))
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m ", prefixColors[h.Sum32()%uint32(len(prefixColors))], prefix)
+}
+
+// containers returns the containers of pod to stream: the requested container, all the containers, or the
+// default container of the pod.
+func (s *selectorLogs) containers(pod *corev1.Pod) []string {
+	var names []string
+	for _, container := range pod.Spec.InitContainers {
+		if s.allContainers || container.Name == s.options.Container {
+			names = append(names, container.Name)
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		if s.allContainers || container.Name == s.options.Container {
+			names = append(names, container.Name)
+		}
+	}
+	if s.allContainers || len(s.options.Container) > 0 || len(pod.Spec.Containers) == 0 {
+		return names
+	}
+
+	if name := pod.Annotations[defaultContainerAnnotation]; len(name) > 0 {
+		for _, container := range pod.Spec.Containers {
+			if container.Name == name {
+				return []string{name}
+			}
+		}
+	}
+	return []string{pod.Spec.Containers[0].Name}
+}
+
+// containerStatus returns the status of container in pod, or nil if it has none yet.
+func containerStatus(pod *corev1.Pod, container string) *corev1.ContainerStatus {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for i := range statuses {
+			if statuses[i].Name == container {
+				return &statuses[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+)
+
+// syncBuffer is a buffer that can be read while the logs are written.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func testPod(name string, running bool, restarts int32, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", UID: types.UID("uid-" + name), Labels: map[string]string{"app": "frontend"}},
+	}
+	for _, container := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: container})
+		status := corev1.ContainerStatus{Name: container, RestartCount: restarts}
+		if running {
+			status.State.Running = &corev1.ContainerStateRunning{}
+		} else {
+			status.State.Waiting = &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}
+		}
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, status)
+	}
+	return pod
+}
+
+func TestSelectorLogs(t *testing.T) {
+	withDefault := testPod("pod-1", true, 0, "sidecar", "app")
+	withDefault.Annotations = map[string]string{defaultContainerAnnotation: "app"}
+	pods := []*corev1.Pod{withDefault, testPod("pod-2", true, 0, "app"), testPod("pod-3", false, 0, "app")}
+
+	tests := []struct {
+		name          string
+		allContainers bool
+		container     string
+		expected      string
+	}{
+		{
+			name:     "default containers",
+			expected: "[pod/pod-1/app] fake logs\n[pod/pod-2/app] fake logs\n",
+		},
+		{
+			name:          "all containers",
+			allContainers: true,
+			expected:      "[pod/pod-1/sidecar] fake logs\n[pod/pod-1/app] fake logs\n[pod/pod-2/app] fake logs\n",
+		},
+		{
+			name:      "container",
+			container: "sidecar",
+			expected:  "[pod/pod-1/sidecar] fake logs\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(pods[0], pods[1], pods[2])
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			s := &selectorLogs{
+				client:        client.CoreV1(),
+				namespace:     "test",
+				selector:      "app=frontend",
+				options:       &corev1.PodLogOptions{Container: tt.container},
+				allContainers: tt.allContainers,
+				out:           out,
+				errOut:        errOut,
+			}
+			if err := s.Run(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, out.String())
+			}
+			if len(tt.container) == 0 && !strings.Contains(errOut.String(), "Container app in pod/pod-3 is waiting to start") {
+				t.Errorf("expected a message about pod-3, got %q", errOut.String())
+			}
+		})
+	}
+}
+
+func TestSelectorLogsFollowsNewPods(t *testing.T) {
+	client := fake.NewSimpleClientset(testPod("pod-1", true, 0, "app"))
+	pods := watch.NewFake()
+	client.PrependWatchReactor("pods", clientgotesting.DefaultWatchReactor(pods, nil))
+
+	out, errOut := &syncBuffer{}, &syncBuffer{}
+	s := &selectorLogs{
+		client:     client.CoreV1(),
+		namespace:  "test",
+		selector:   "app=frontend",
+		options:    &corev1.PodLogOptions{Follow: true},
+		maxStreams: 5,
+		out:        out,
+		errOut:     errOut,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	// a pod that is not running yet, then running, a pod that did not restart and a pod that restarted
+	pods.Add(testPod("pod-2", false, 0, "app"))
+	pods.Modify(testPod("pod-2", true, 0, "app"))
+	waitForOutput(t, out, "[pod/pod-2/app] fake logs\n", 1)
+	pods.Modify(testPod("pod-1", true, 0, "app"))
+	pods.Modify(testPod("pod-1", true, 1, "app"))
+	waitForOutput(t, out, "[pod/pod-1/app] fake logs\n", 2)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(out.String(), "\n"); lines != 3 {
+		t.Errorf("expected 3 lines of logs, got:\n%s", out.String())
+	}
+	if len(errOut.String()) > 0 {
+		t.Errorf("unexpected errors: %s", errOut.String())
+	}
+}
+
+func TestSelectorLogsPrefixColor(t *testing.T) {
+	s := &selectorLogs{color: true}
+	prefix := s.prefix("pod-1", "app")
+	if !strings.HasPrefix(prefix, "\x1b[") || !strings.Contains(prefix, "[pod/pod-1/app]\x1b[0m ") {
+		t.Errorf("unexpected prefix %q", prefix)
+	}
+	if prefix != s.prefix("pod-1", "app") {
+		t.Errorf("expected the same color for the same container")
+	}
+}
+
+// waitForOutput waits until the output contains count lines equal to line.
+func waitForOutput(t *testing.T, out *syncBuffer, line string, count int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if strings.Count(out.String(), line) == count {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected %d times %q in the output, got:\n%s", count, line, out.String())
+}
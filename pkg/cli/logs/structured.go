@@ -0,0 +1,188 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/cmd/logs"
+)
+
+// logLevels orders the levels of structured logs, from the least to the most important.
+var logLevels = map[string]int{
+	"trace":    1,
+	"debug":    2,
+	"info":     3,
+	"warn":     4,
+	"warning":  4,
+	"error":    5,
+	"err":      5,
+	"fatal":    6,
+	"panic":    6,
+	"critical": 6,
+}
+
+// levelKeys are the keys that usually hold the level of structured logs.
+var levelKeys = []string{"level", "lvl", "severity", "log.level"}
+
+// structuredLogs renders the log lines that are JSON objects, with only some of their fields, and drops the lines
+// of structured logs below a level. The lines that are not JSON objects are kept as they are.
+type structuredLogs struct {
+	// fields are the fields of the JSON log lines to print, all the line if empty
+	fields []string
+	// minLevel is the level of the least important lines to keep, 0 to keep all the lines
+	minLevel int
+}
+
+// newStructuredLogs returns the processing of the log lines for the fields and the level filter, or nil if the
+// lines do not need to be processed.
+func newStructuredLogs(fields []string, levelFilter string) (*structuredLogs, error) {
+	if len(fields) == 0 && len(levelFilter) == 0 {
+		return nil, nil
+	}
+	s := &structuredLogs{}
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); len(field) > 0 {
+			s.fields = append(s.fields, field)
+		}
+	}
+	if len(levelFilter) > 0 {
+		level, ok := logLevels[strings.ToLower(levelFilter)]
+		if !ok {
+			var levels []string
+			for name := range logLevels {
+				levels = append(levels, name)
+			}
+			sort.Strings(levels)
+			return nil, fmt.Errorf("invalid level %q for --level-filter, must be one of: %s", levelFilter, strings.Join(levels, ", "))
+		}
+		s.minLevel = level
+	}
+	return s, nil
+}
+
+// process returns the line to print for line, and false if the line must be dropped.
+func (s *structuredLogs) process(line []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return line, true
+	}
+	entry := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	if err := decoder.Decode(&entry); err != nil {
+		return line, true
+	}
+
+	if s.minLevel > 0 {
+		if level, ok := entryLevel(entry); ok && level < s.minLevel {
+			return nil, false
+		}
+	}
+	if len(s.fields) == 0 {
+		return line, true
+	}
+
+	var values []string
+	for _, field := range s.fields {
+		value, ok := lookupField(entry, field)
+		if !ok {
+			continue
+		}
+		values = append(values, field+"="+formatValue(value))
+	}
+	return []byte(strings.Join(values, " ") + "\n"), true
+}
+
+// Writer returns a writer that processes each write as a log line before writing it to out.
+func (s *structuredLogs) Writer(out io.Writer) io.Writer {
+	return &structuredLogsWriter{logs: s, out: out}
+}
+
+// ConsumeRequest consumes the logs of request like the upstream logs command, processing the lines.
+func (s *structuredLogs) ConsumeRequest(request rest.ResponseWrapper, out io.Writer) error {
+	return logs.DefaultConsumeRequest(request, s.Writer(out))
+}
+
+type structuredLogsWriter struct {
+	logs *structuredLogs
+	out  io.Writer
+}
+
+// Write processes p, which holds one line as written by logs.DefaultConsumeRequest.
+func (w *structuredLogsWriter) Write(p []byte) (int, error) {
+	line, keep := w.logs.process(p)
+	if !keep || len(line) == 0 {
+		return len(p), nil
+	}
+	if _, err := w.out.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// entryLevel returns the level of a structured log entry, textual or numeric like the levels of bunyan and pino.
+func entryLevel(entry map[string]interface{}) (int, bool) {
+	for _, key := range levelKeys {
+		value, ok := lookupField(entry, key)
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			level, ok := logLevels[strings.ToLower(v)]
+			return level, ok
+		case json.Number:
+			n, err := v.Int64()
+			if err != nil || n < 10 {
+				return 0, false
+			}
+			// 10 is trace, 20 debug, 30 info, 40 warn, 50 error and 60 fatal
+			return int(n / 10), true
+		}
+	}
+	return 0, false
+}
+
+// lookupField returns the value of field in entry, either a key of entry or a path of keys separated by dots.
+func lookupField(entry map[string]interface{}, field string) (interface{}, bool) {
+	if value, ok := entry[field]; ok {
+		return value, true
+	}
+	var value interface{} = entry
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		if len(v) == 0 || strings.ContainsAny(v, " \t\n\"=") {
+			return strconv.Quote(v)
+		}
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	}
+}
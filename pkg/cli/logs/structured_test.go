@@ -0,0 +1,119 @@
+package logs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStructuredLogs(t *testing.T) {
+	tests := []struct {
+		name        string
+		fields      []string
+		levelFilter string
+		line        string
+		expected    string
+		dropped     bool
+	}{
+		{
+			name:     "fields",
+			fields:   []string{"level", "msg", "ts"},
+			line:     `{"level":"info","msg":"server started","ts":1700000000.5,"port":8080}` + "\n",
+			expected: `level=info msg="server started" ts=1700000000.5` + "\n",
+		},
+		{
+			name:     "nested and missing fields",
+			fields:   []string{"msg", "http.status", "caller"},
+			line:     `{"msg":"done","http":{"status":200,"path":"/"}}` + "\n",
+			expected: "msg=done http.status=200\n",
+		},
+		{
+			name:     "object field",
+			fields:   []string{"http"},
+			line:     `{"http":{"status":200}}` + "\n",
+			expected: `http={"status":200}` + "\n",
+		},
+		{
+			name:     "line that is not JSON",
+			fields:   []string{"msg"},
+			line:     "plain text line\n",
+			expected: "plain text line\n",
+		},
+		{
+			name:        "level below the filter",
+			levelFilter: "warn",
+			line:        `{"level":"INFO","msg":"ok"}` + "\n",
+			dropped:     true,
+		},
+		{
+			name:        "level above the filter",
+			levelFilter: "warn",
+			line:        `{"severity":"error","msg":"failed"}` + "\n",
+			expected:    `{"severity":"error","msg":"failed"}` + "\n",
+		},
+		{
+			name:        "numeric level",
+			levelFilter: "error",
+			line:        `{"level":40,"msg":"slow"}` + "\n",
+			dropped:     true,
+		},
+		{
+			name:        "unknown level",
+			levelFilter: "error",
+			line:        `{"level":"notice","msg":"kept"}` + "\n",
+			expected:    `{"level":"notice","msg":"kept"}` + "\n",
+		},
+		{
+			name:        "no level",
+			levelFilter: "error",
+			fields:      []string{"msg"},
+			line:        `{"msg":"kept"}` + "\n",
+			expected:    "msg=kept\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newStructuredLogs(tt.fields, tt.levelFilter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			line, keep := s.process([]byte(tt.line))
+			if keep == tt.dropped {
+				t.Fatalf("expected the line to be dropped: %t, got %t", tt.dropped, !keep)
+			}
+			if keep && string(line) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, string(line))
+			}
+		})
+	}
+}
+
+func TestNewStructuredLogs(t *testing.T) {
+	if s, err := newStructuredLogs(nil, ""); s != nil || err != nil {
+		t.Errorf("expected no processing, got %v: %v", s, err)
+	}
+	if _, err := newStructuredLogs(nil, "verbose"); err == nil {
+		t.Errorf("expected an error for an invalid level")
+	}
+}
+
+func TestStructuredLogsWriter(t *testing.T) {
+	s, err := newStructuredLogs([]string{"msg"}, "warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	w := s.Writer(out)
+	for _, line := range []string{
+		`{"level":"debug","msg":"starting"}` + "\n",
+		`{"level":"warn","msg":"retrying"}` + "\n",
+		"panic: runtime error\n",
+	} {
+		if n, err := w.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("unexpected write of %d bytes: %v", n, err)
+		}
+	}
+	expected := "msg=retrying\npanic: runtime error\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
@@ -0,0 +1,117 @@
+package newapp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubectl/pkg/cmd/diff"
+	"k8s.io/utils/exec"
+)
+
+// dryRunDiffer creates the objects with a server-side dry run, and records them with the objects existing on the
+// server to print their differences as 'oc diff' does.
+type dryRunDiffer struct {
+	Client     dynamic.Interface
+	RESTMapper meta.RESTMapper
+
+	lock    sync.Mutex
+	objects []diffObject
+}
+
+// diffObject is an object existing on the server, if any, and the object created by the dry run.
+type diffObject struct {
+	name   string
+	live   runtime.Object
+	merged runtime.Object
+}
+
+func (o diffObject) Live() runtime.Object            { return o.live }
+func (o diffObject) Merged() (runtime.Object, error) { return o.merged, nil }
+func (o diffObject) Name() string                    { return o.name }
+
+// Create creates the object with a server-side dry run. An object that already exists is replaced with a dry run
+// to show its differences, and fails as its creation would.
+func (d *dryRunDiffer) Create(obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+	if len(obj.GetNamespace()) > 0 {
+		namespace = obj.GetNamespace()
+	}
+	gvk := obj.GroupVersionKind()
+	mapping, err := d.RESTMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		namespace = ""
+	}
+	client := d.Client.Resource(mapping.Resource).Namespace(namespace)
+	dryRun := []string{metav1.DryRunAll}
+
+	name := fmt.Sprintf("%s.%s.%s.%s", gvk.Version, gvk.Kind, namespace, obj.GetName())
+	if len(gvk.Group) > 0 {
+		name = gvk.Group + "." + name
+	}
+
+	live, err := client.Get(context.TODO(), obj.GetName(), metav1.GetOptions{})
+	switch {
+	case kapierrors.IsNotFound(err):
+		merged, err := client.Create(context.TODO(), obj, metav1.CreateOptions{DryRun: dryRun})
+		if err != nil {
+			return nil, err
+		}
+		d.record(diffObject{name: name, merged: merged})
+		return merged, nil
+	case err != nil:
+		return nil, err
+	}
+
+	updated := obj.DeepCopy()
+	updated.SetResourceVersion(live.GetResourceVersion())
+	merged, err := client.Update(context.TODO(), updated, metav1.UpdateOptions{DryRun: dryRun})
+	if err != nil {
+		return nil, err
+	}
+	d.record(diffObject{name: name, live: live, merged: merged})
+	return nil, kapierrors.NewAlreadyExists(mapping.Resource.GroupResource(), obj.GetName())
+}
+
+func (d *dryRunDiffer) record(obj diffObject) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.objects = append(d.objects, obj)
+}
+
+// PrintDiff prints the differences of the objects created by the dry run with the objects existing on the server,
+// with the diff program of 'oc diff'.
+func (d *dryRunDiffer) PrintDiff(streams genericclioptions.IOStreams) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(d.objects) == 0 {
+		return nil
+	}
+
+	differ, err := diff.NewDiffer("LIVE", "MERGED")
+	if err != nil {
+		return err
+	}
+	defer differ.TearDown()
+	printer := diff.Printer{}
+	for _, obj := range d.objects {
+		if err := differ.Diff(obj, printer); err != nil {
+			return err
+		}
+	}
+	err = differ.Run(&diff.DiffProgram{Exec: exec.New(), IOStreams: streams})
+	// the diff program exits with 1 when there are differences
+	if exitErr, ok := err.(exec.ExitError); ok && exitErr.ExitStatus() == 1 {
+		return nil
+	}
+	return err
+}
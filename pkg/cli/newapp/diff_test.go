@@ -0,0 +1,63 @@
+package newapp
+
+import (
+	"strings"
+	"testing"
+
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func testService(name, port string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "test"},
+		"spec": map[string]interface{}{
+			"ports": []interface{}{map[string]interface{}{"port": port}},
+		},
+	}}
+}
+
+func TestDryRunDiffer(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Service"}, meta.RESTScopeNamespace)
+	existing := testService("database", "5432")
+	existing.SetResourceVersion("10")
+	d := &dryRunDiffer{
+		Client:     fake.NewSimpleDynamicClient(runtime.NewScheme(), existing),
+		RESTMapper: mapper,
+	}
+
+	if _, err := d.Create(testService("frontend", "8080"), "test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Create(testService("database", "3306"), "test"); !kapierrors.IsAlreadyExists(err) {
+		t.Fatalf("expected the existing service to fail as its creation would, got %v", err)
+	}
+	if len(d.objects) != 2 {
+		t.Fatalf("expected 2 objects, got %d", len(d.objects))
+	}
+	if d.objects[0].name != "v1.Service.test.frontend" || d.objects[0].live != nil {
+		t.Errorf("unexpected new object %#v", d.objects[0])
+	}
+	if d.objects[1].name != "v1.Service.test.database" || d.objects[1].live == nil {
+		t.Errorf("unexpected existing object %#v", d.objects[1])
+	}
+
+	streams, _, out, errOut := genericclioptions.NewTestIOStreams()
+	if err := d.PrintDiff(streams); err != nil {
+		t.Fatalf("unexpected error: %v %s", err, errOut.String())
+	}
+	diff := out.String()
+	for _, expected := range []string{"-  - port: \"5432\"", "+  - port: \"3306\"", "+  - port: \"8080\"", "+  name: frontend"} {
+		if !strings.Contains(diff, expected) {
+			t.Errorf("expected the diff to contain %q, got:\n%s", expected, diff)
+		}
+	}
+}
@@ -77,6 +77,20 @@ var (
 		You may either specify components using the various existing flags or let oc new-app autodetect
 		what kind of components you have provided.
 
+		A Helm chart can be deployed with '--helm' alongside the other components: the chart is
+		rendered with 'helm template', which must be installed, using the values set with '--set',
+		and its objects are created with the rest of the application. The release of the chart is
+		named after the chart unless '--name' is set.
+
+		With '--dry-run=server', the objects are submitted to the server with a dry run, which validates
+		and defaults them without persisting them, and their differences with the objects existing in
+		the project are printed as 'oc diff' does. An object that already exists would not be created.
+
+		With '--strategy=buildpacks', source code without a Dockerfile is built on the cluster with Cloud
+		Native Buildpacks: a Shipwright build using the buildpacks-v3 cluster build strategy is created
+		instead of a build config, with a build run starting its first build. Shipwright must be
+		installed on the cluster.
+
 		If you provide source code, a new build will be automatically triggered.
 		You can use 'oc status' to check the progress.`)
 
@@ -108,12 +122,22 @@ var (
 		# Create an application from a remote repository and specify a context directory
 		oc new-app https://github.com/youruser/yourgitrepo --context-dir=src/build
 
+		# Create an application from a remote repository built with Cloud Native Buildpacks
+		oc new-app https://github.com/sclorg/nodejs-ex.git --strategy=buildpacks
+
 		# Create an application from a remote private repository and specify which existing secret to use
 		oc new-app https://github.com/youruser/yourgitrepo --source-secret=yoursecret
 
 		# Create an application based on a template file, explicitly setting a parameter value
 		oc new-app --file=./example/myapp/template.json --param=MYSQL_USER=admin
 
+		# Create an application from the Helm chart redis of the repository bitnami, setting a value of the chart
+		oc new-app --helm=bitnami/redis --set=architecture=standalone
+
+		# Preview the objects created for an application with a server-side dry run, and their differences with the
+		# objects of the project
+		oc new-app mysql --dry-run=server
+
 		# Search all templates, image streams, and container images for the ones that match "ruby"
 		oc new-app --search ruby
 
@@ -157,6 +181,8 @@ type ObjectGeneratorOptions struct {
 	LogsForObject polymorphichelpers.LogsForObjectFunc
 	Printer       printers.ResourcePrinter
 
+	differ *dryRunDiffer
+
 	genericclioptions.IOStreams
 }
 
@@ -204,6 +230,8 @@ func (o *ObjectGeneratorOptions) Complete(f kcmdutil.Factory, c *cobra.Command,
 
 	o.Action.Bulk.Scheme = newAppScheme
 	o.Action.Bulk.Op = bulk.Creator{Client: dynamicClient, RESTMapper: mapper}.Create
+	o.differ = &dryRunDiffer{Client: dynamicClient, RESTMapper: mapper}
+	o.Action.Bulk.DryRunOp = o.differ.Create
 	// Retry is used to support previous versions of the API server that will
 	// consider the presence of an unknown trigger type to be an error.
 	o.Action.Bulk.Retry = retryBuildConfig
@@ -278,6 +306,9 @@ func NewCmdNewApplication(f kcmdutil.Factory, streams genericclioptions.IOStream
 	cmd.Flags().StringArrayVarP(&o.Config.TemplateParameters, "param", "p", o.Config.TemplateParameters, "Specify a key-value pair (e.g., -p FOO=BAR) to set/override a parameter value in the template.")
 	cmd.Flags().StringArrayVar(&o.Config.TemplateParameterFiles, "param-file", o.Config.TemplateParameterFiles, "File containing parameter values to set/override in the template.")
 	cmd.MarkFlagFilename("param-file")
+	cmd.Flags().StringVar(&o.Config.HelmChart, "helm", o.Config.HelmChart, "Helm chart to deploy in the app, as REPO/CHART, a chart directory or archive, or a URL.")
+	cmd.Flags().StringArrayVar(&o.Config.HelmValues, "set", o.Config.HelmValues, "Specify a key-value pair (e.g., --set image.tag=1.2) to set a value of the Helm chart.")
+	cmd.Flags().StringVar(&o.Config.HelmVersion, "helm-version", o.Config.HelmVersion, "Version of the Helm chart to deploy, defaults to the latest version.")
 	cmd.Flags().StringSliceVar(&o.Config.Groups, "group", o.Config.Groups, "Indicate components that should be grouped together as <comp1>+<comp2>.")
 	cmd.Flags().StringArrayVarP(&o.Config.Environment, "env", "e", o.Config.Environment, "Specify a key-value pair for an environment variable to set into each container.")
 	cmd.Flags().StringArrayVar(&o.Config.EnvironmentFiles, "env-file", o.Config.EnvironmentFiles, "File containing key-value pairs of environment variables to set into each container.")
@@ -286,7 +317,7 @@ func NewCmdNewApplication(f kcmdutil.Factory, streams genericclioptions.IOStream
 	cmd.Flags().StringArrayVar(&o.Config.BuildEnvironmentFiles, "build-env-file", o.Config.BuildEnvironmentFiles, "File containing key-value pairs of environment variables to set into each build image.")
 	cmd.MarkFlagFilename("build-env-file")
 	cmd.Flags().StringVar(&o.Config.Name, "name", o.Config.Name, "Set name to use for generated application artifacts")
-	cmd.Flags().Var(&o.Config.Strategy, "strategy", "Specify the build strategy to use if you don't want to detect (docker|pipeline|source). NOTICE: the pipeline strategy is deprecated; consider using Jenkinsfiles directly on Jenkins or OpenShift Pipelines.")
+	cmd.Flags().Var(&o.Config.Strategy, "strategy", "Specify the build strategy to use if you don't want to detect (buildpacks|docker|pipeline|source). NOTICE: the pipeline strategy is deprecated; consider using Jenkinsfiles directly on Jenkins or OpenShift Pipelines.")
 	cmd.Flags().StringP("labels", "l", "", "Label to set in all resources for this application.")
 	cmd.Flags().BoolVar(&o.Config.IgnoreUnknownParameters, "ignore-unknown-parameters", o.Config.IgnoreUnknownParameters, "If true, will not stop processing if a provided parameter does not exist in the template.")
 	cmd.Flags().BoolVar(&o.Config.InsecureRegistry, "insecure-registry", o.Config.InsecureRegistry, "If true, indicates that the referenced container images are on insecure registries and should bypass certificate checking")
@@ -410,7 +441,11 @@ func (o *AppOptions) RunNewApp() error {
 		o.Action.Compact()
 	}
 
-	if errs := o.Action.WithMessage(bulk.CreateMessage(config.Labels), "created").Run(result.List, result.Namespace); len(errs) > 0 {
+	errs := o.Action.WithMessage(bulk.CreateMessage(config.Labels), "created").Run(result.List, result.Namespace)
+	if err := o.PrintServerDryRunDiff(result.Namespace); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
 		return kcmdutil.ErrExit
 	}
 
@@ -433,6 +468,11 @@ func (o *AppOptions) RunNewApp() error {
 		// these are all unstructured
 		unstructuredObj := item.(*unstructured.Unstructured)
 
+		if unstructuredObj.GroupVersionKind() == newappapp.ShipwrightBuildRunGVK {
+			fmt.Fprintf(out, "%[1]sBuild run %[2]q started, use 'oc logs -f -l buildrun.shipwright.io/name=%[2]s --all-containers' to track its progress.\n", indent, unstructuredObj.GetName())
+			continue
+		}
+
 		// Determine if dealing with a "known" resource, containing a switch case below.
 		// If so, go through with a conversion attempt, and fail if necessary.
 		if supported := supportedTypes[unstructuredObj.GroupVersionKind()]; !supported {
@@ -525,6 +565,18 @@ func (o *AppOptions) RunNewApp() error {
 	return nil
 }
 
+// PrintServerDryRunDiff prints the differences of the objects created with --dry-run=server with the objects existing
+// on the server.
+func (o *ObjectGeneratorOptions) PrintServerDryRunDiff(namespace string) error {
+	if !o.Action.ServerDryRun || o.differ == nil {
+		return nil
+	}
+	if o.Action.Verbose() {
+		fmt.Fprintf(o.Action.Out, "--> Differences with the objects of project %s ...\n", namespace)
+	}
+	return o.differ.PrintDiff(o.IOStreams)
+}
+
 func getServices(items []runtime.Object) []*corev1.Service {
 	var svc []*corev1.Service
 	for _, i := range items {
@@ -750,10 +802,18 @@ func CompleteAppConfig(config *newcmd.AppConfig, f kcmdutil.Factory, c *cobra.Co
 		return kcmdutil.UsageErrorf(c, "--source-image must be specified when --source-image-path is specified.")
 	}
 
+	if len(config.HelmChart) == 0 && (len(config.HelmValues) > 0 || len(config.HelmVersion) > 0) {
+		return kcmdutil.UsageErrorf(c, "--set and --helm-version can only be used with --helm.")
+	}
+
 	if config.BinaryBuild && config.Strategy == newapp.StrategyPipeline {
 		return kcmdutil.UsageErrorf(c, "specifying binary builds and the pipeline strategy at the same time is not allowed.")
 	}
 
+	if config.BinaryBuild && config.Strategy == newapp.StrategyBuildpacks {
+		return kcmdutil.UsageErrorf(c, "specifying binary builds and the buildpacks strategy at the same time is not allowed.")
+	}
+
 	if len(config.BuildArgs) > 0 && config.Strategy != newapp.StrategyUnspecified && config.Strategy != newapp.StrategyDocker {
 		return kcmdutil.UsageErrorf(c, "Cannot use '--build-arg' without a Docker build")
 	}
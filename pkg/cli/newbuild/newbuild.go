@@ -9,6 +9,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -36,6 +37,11 @@ var (
 		it into an image that can run inside of a pod. Local source must be in a git repository that has a
 		remote repository that the server can see.
 
+		With '--strategy=buildpacks', source code without a Dockerfile is built with Cloud Native
+		Buildpacks: a Shipwright build using the buildpacks-v3 cluster build strategy is created instead
+		of a build configuration, with a build run starting its first build. Shipwright must be
+		installed on the cluster.
+
 		Once the build configuration is created a new build will be automatically triggered.
 		You can use '%[1]s status' to check the progress.`)
 
@@ -50,6 +56,9 @@ var (
 		# Create a build config from a remote repository using its beta2 branch
 		oc new-build https://github.com/openshift/ruby-hello-world#beta2
 
+		# Create a Shipwright build of a remote repository with Cloud Native Buildpacks
+		oc new-build https://github.com/sclorg/nodejs-ex.git --strategy=buildpacks
+
 		# Create a build config using a Dockerfile specified as an argument
 		oc new-build -D $'FROM centos:7\nRUN yum install -y httpd'
 
@@ -142,7 +151,7 @@ func NewCmdNewBuild(f kcmdutil.Factory, streams genericclioptions.IOStreams) *co
 	cmd.Flags().MarkHidden("build-env-file")
 	cmd.Flags().StringArrayVar(&o.Config.BuildEnvironmentFiles, "env-file", o.Config.BuildEnvironmentFiles, "File containing key-value pairs of environment variables to set into each container.")
 	cmd.MarkFlagFilename("env-file")
-	cmd.Flags().Var(&o.Config.Strategy, "strategy", "Specify the build strategy to use if you don't want to detect (docker|pipeline|source). NOTICE: the pipeline strategy is deprecated; consider using Jenkinsfiles directly on Jenkins or OpenShift Pipelines.")
+	cmd.Flags().Var(&o.Config.Strategy, "strategy", "Specify the build strategy to use if you don't want to detect (buildpacks|docker|pipeline|source). NOTICE: the pipeline strategy is deprecated; consider using Jenkinsfiles directly on Jenkins or OpenShift Pipelines.")
 	cmd.Flags().StringVarP(&o.Config.Dockerfile, "dockerfile", "D", o.Config.Dockerfile, "Specify the contents of a Dockerfile to build directly, implies --strategy=docker. Pass '-' to read from STDIN.")
 	cmd.Flags().StringArrayVar(&o.Config.BuildArgs, "build-arg", o.Config.BuildArgs, "Specify a key-value pair to pass to Docker during the build.")
 	cmd.Flags().BoolVar(&o.Config.BinaryBuild, "binary", o.Config.BinaryBuild, "Instead of expecting a source URL, set the build to expect binary contents. Will disable triggers.")
@@ -217,7 +226,11 @@ func (o *BuildOptions) RunNewBuild() error {
 		return o.Printer.PrintObj(printableList, o.Out)
 	}
 
-	if errs := o.Action.WithMessage(configcmd.CreateMessage(config.Labels), "created").Run(result.List, result.Namespace); len(errs) > 0 {
+	errs := o.Action.WithMessage(configcmd.CreateMessage(config.Labels), "created").Run(result.List, result.Namespace)
+	if err := o.PrintServerDryRunDiff(result.Namespace); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
 		return kcmdutil.ErrExit
 	}
 
@@ -236,6 +249,12 @@ func (o *BuildOptions) RunNewBuild() error {
 				fmt.Fprintf(out, "%sBuild configuration %q created and build triggered.\n", indent, t.Name)
 				fmt.Fprintf(out, "%sRun 'oc logs -f buildconfig/%s' to stream the build progress.\n", indent, t.Name)
 			}
+		case *unstructured.Unstructured:
+			if t.GroupVersionKind() == newapp.ShipwrightBuildRunGVK {
+				build, _, _ := unstructured.NestedString(t.Object, "spec", "buildRef", "name")
+				fmt.Fprintf(out, "%sBuild %q created and build run %q started.\n", indent, build, t.GetName())
+				fmt.Fprintf(out, "%sRun 'oc logs -f -l buildrun.shipwright.io/name=%s --all-containers' to stream the build progress.\n", indent, t.GetName())
+			}
 		}
 	}
 
@@ -0,0 +1,342 @@
+package portforward
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/klog/v2"
+)
+
+// forwarder listens on the local ports, and forwards each connection to the next pod it forwards to. The listeners
+// outlive the connections to the pods, so that the pods can be replaced without the clients noticing.
+type forwarder struct {
+	o         *PortForwardOptions
+	ports     []portSpec
+	listeners []net.Listener
+	requestID int32
+
+	// lost is signaled when the connection to a pod is lost
+	lost chan struct{}
+
+	// lock guards the targets below
+	lock    sync.Mutex
+	targets []*target
+	next    int
+}
+
+// target is a pod forwarded to, with the port of the pod that each port forwards to.
+type target struct {
+	pod   *corev1.Pod
+	ports []uint16
+	conn  httpstream.Connection
+}
+
+func newForwarder(o *PortForwardOptions, ports []portSpec) *forwarder {
+	return &forwarder{
+		o:     o,
+		ports: ports,
+		lost:  make(chan struct{}, 1),
+	}
+}
+
+// run waits until stop is closed, looking up the pods to forward to again when a connection is lost with --retry.
+func (f *forwarder) run(stop <-chan struct{}) error {
+	defer f.close()
+	for {
+		var retry <-chan time.Time
+		if f.o.Retry {
+			switch {
+			case len(f.currentTargets()) == 0:
+				retry = time.After(retryInterval)
+			case f.o.AllPods:
+				retry = time.After(refreshInterval)
+			}
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-f.lost:
+			if !f.o.Retry && len(f.currentTargets()) == 0 {
+				return fmt.Errorf("lost connection to pod")
+			}
+		case <-retry:
+		}
+		if !f.o.Retry {
+			continue
+		}
+		if err := f.resolve(); err != nil {
+			klog.V(2).Infof("Unable to forward to a pod of %s: %v", f.o.ResourceName, err)
+		}
+	}
+}
+
+// resolve looks up the pods to forward to, keeps forwarding to the pods it already forwards to, connects to the new
+// ones and disconnects from the pods that are gone.
+func (f *forwarder) resolve() error {
+	obj, pods, err := f.o.resolvePods()
+	if err != nil {
+		return err
+	}
+
+	existing := map[types.UID]*target{}
+	for _, t := range f.currentTargets() {
+		existing[t.pod.UID] = t
+	}
+	var targets []*target
+	var errs []string
+	for _, pod := range pods {
+		if t, ok := existing[pod.UID]; ok {
+			targets = append(targets, t)
+			delete(existing, pod.UID)
+			continue
+		}
+		ports, err := remotePorts(obj, pod, f.ports)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("pod/%s: %v", pod.Name, err))
+			continue
+		}
+		conn, err := f.o.Dial(pod)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("pod/%s: %v", pod.Name, err))
+			continue
+		}
+		t := &target{pod: pod, ports: ports, conn: conn}
+		go f.watch(t)
+		if f.o.Retry || f.o.AllPods {
+			fmt.Fprintf(f.o.Out, "Forwarding to pod/%s\n", pod.Name)
+		}
+		targets = append(targets, t)
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("unable to forward to the pods of %s: %s", f.o.ResourceName, strings.Join(errs, ", "))
+	}
+	for _, e := range errs {
+		fmt.Fprintf(f.o.ErrOut, "Unable to forward to %s\n", e)
+	}
+
+	f.lock.Lock()
+	f.targets = targets
+	f.lock.Unlock()
+	for _, t := range existing {
+		klog.V(4).Infof("Stopping forwarding to pod %s", t.pod.Name)
+		t.conn.Close()
+	}
+	return nil
+}
+
+// watch removes the target when its connection is closed, and signals that it is lost.
+func (f *forwarder) watch(t *target) {
+	<-t.conn.CloseChan()
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for i := range f.targets {
+		if f.targets[i] == t {
+			f.targets = append(f.targets[:i], f.targets[i+1:]...)
+			fmt.Fprintf(f.o.ErrOut, "Lost connection to pod/%s\n", t.pod.Name)
+			select {
+			case f.lost <- struct{}{}:
+			default:
+			}
+			return
+		}
+	}
+}
+
+func (f *forwarder) currentTargets() []*target {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]*target(nil), f.targets...)
+}
+
+// nextTarget returns the pod to forward the next connection to, in turn, or nil if there is none.
+func (f *forwarder) nextTarget() *target {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if len(f.targets) == 0 {
+		return nil
+	}
+	t := f.targets[f.next%len(f.targets)]
+	f.next++
+	return t
+}
+
+// listen listens on the local ports on the addresses, the local ports that are the remote port names being the
+// ports of the first pod, and the local ports that are zero being chosen at random.
+func (f *forwarder) listen(addresses []listenAddress) error {
+	first := f.currentTargets()[0]
+	listening := false
+	for i := range f.ports {
+		port := &f.ports[i]
+		if port.sameLocal && port.local == 0 {
+			port.local = first.ports[i]
+		}
+		if err := f.listenOnPort(i, addresses, first.ports[i]); err != nil {
+			fmt.Fprintf(f.o.ErrOut, "Unable to listen on port %d: %v\n", port.local, err)
+			continue
+		}
+		listening = true
+	}
+	if !listening {
+		return fmt.Errorf("unable to listen on any of the requested ports: %v", f.o.Ports)
+	}
+	return nil
+}
+
+func (f *forwarder) listenOnPort(i int, addresses []listenAddress, remote uint16) error {
+	port := &f.ports[i]
+	var errs []error
+	failures, successes := map[string]int{}, map[string]int{}
+	for _, addr := range addresses {
+		listener, err := net.Listen(addr.protocol, net.JoinHostPort(addr.address, strconv.Itoa(int(port.local))))
+		if err != nil {
+			errs = append(errs, err)
+			failures[addr.failureMode]++
+			continue
+		}
+		successes[addr.failureMode]++
+		if port.local == 0 {
+			_, local, _ := net.SplitHostPort(listener.Addr().String())
+			number, _ := strconv.ParseUint(local, 10, 16)
+			port.local = uint16(number)
+		}
+		fmt.Fprintf(f.o.Out, "Forwarding from %s -> %d\n", net.JoinHostPort(addr.address, strconv.Itoa(int(port.local))), remote)
+		f.listeners = append(f.listeners, listener)
+		go f.accept(listener, i)
+	}
+	if (successes["all"] == 0 && failures["all"] > 0) || failures["any"] > 0 {
+		return fmt.Errorf("Listeners failed to create with the following errors: %v", errs)
+	}
+	return nil
+}
+
+// accept forwards the connections to the listener of the port to the pods.
+func (f *forwarder) accept(listener net.Listener, i int) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !strings.Contains(strings.ToLower(err.Error()), "use of closed network connection") {
+				runtime.HandleError(fmt.Errorf("error accepting connection on port %d: %v", f.ports[i].local, err))
+			}
+			return
+		}
+		go f.handle(conn, i)
+	}
+}
+
+// handle forwards the connection to the port to the next pod, like the client-go port forwarder.
+func (f *forwarder) handle(conn net.Conn, i int) {
+	defer conn.Close()
+
+	local := f.ports[i].local
+	t := f.nextTarget()
+	if t == nil {
+		fmt.Fprintf(f.o.ErrOut, "No pod to forward the connection for %d to\n", local)
+		return
+	}
+	remote := t.ports[i]
+	if f.o.AllPods {
+		fmt.Fprintf(f.o.Out, "Handling connection for %d with pod/%s\n", local, t.pod.Name)
+	} else {
+		fmt.Fprintf(f.o.Out, "Handling connection for %d\n", local)
+	}
+
+	requestID := atomic.AddInt32(&f.requestID, 1) - 1
+
+	// create error stream
+	headers := http.Header{}
+	headers.Set(corev1.StreamType, corev1.StreamTypeError)
+	headers.Set(corev1.PortHeader, strconv.Itoa(int(remote)))
+	headers.Set(corev1.PortForwardRequestIDHeader, strconv.Itoa(int(requestID)))
+	errorStream, err := t.conn.CreateStream(headers)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error creating error stream for port %d -> %d: %v", local, remote, err))
+		return
+	}
+	// we're not writing to this stream
+	errorStream.Close()
+
+	errorChan := make(chan error)
+	go func() {
+		message, err := ioutil.ReadAll(errorStream)
+		switch {
+		case err != nil:
+			errorChan <- fmt.Errorf("error reading from error stream for port %d -> %d: %v", local, remote, err)
+		case len(message) > 0:
+			errorChan <- fmt.Errorf("an error occurred forwarding %d -> %d: %v", local, remote, string(message))
+		}
+		close(errorChan)
+	}()
+
+	// create data stream
+	headers.Set(corev1.StreamType, corev1.StreamTypeData)
+	dataStream, err := t.conn.CreateStream(headers)
+	if err != nil {
+		runtime.HandleError(fmt.Errorf("error creating forwarding stream for port %d -> %d: %v", local, remote, err))
+		return
+	}
+
+	localError := make(chan struct{})
+	remoteDone := make(chan struct{})
+
+	go func() {
+		// Copy from the remote side to the local port.
+		if _, err := io.Copy(conn, dataStream); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+			runtime.HandleError(fmt.Errorf("error copying from remote stream to local connection: %v", err))
+		}
+		// inform the select below that the remote copy is done
+		close(remoteDone)
+	}()
+
+	go func() {
+		// inform server we're not sending any more data after copy unblocks
+		defer dataStream.Close()
+
+		// Copy from the local port to the remote side.
+		if _, err := io.Copy(dataStream, conn); err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+			runtime.HandleError(fmt.Errorf("error copying from local connection to remote stream: %v", err))
+			// break out of the select below without waiting for the other copy to finish
+			close(localError)
+		}
+	}()
+
+	// wait for either a local->remote error or for copying from remote->local to finish
+	select {
+	case <-remoteDone:
+	case <-localError:
+	}
+
+	// always expect something on errorChan (it may be nil)
+	if err := <-errorChan; err != nil {
+		runtime.HandleError(err)
+		t.conn.Close()
+	}
+}
+
+// close stops listening and disconnects from the pods.
+func (f *forwarder) close() {
+	for _, l := range f.listeners {
+		if err := l.Close(); err != nil {
+			runtime.HandleError(fmt.Errorf("error closing listener: %v", err))
+		}
+	}
+	f.lock.Lock()
+	targets := f.targets
+	f.targets = nil
+	f.lock.Unlock()
+	for _, t := range targets {
+		t.conn.Close()
+	}
+}
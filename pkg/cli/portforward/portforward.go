@@ -0,0 +1,442 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util"
+	"k8s.io/kubectl/pkg/util/completion"
+	"k8s.io/kubectl/pkg/util/podutils"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc/pkg/helpers/originpolymorphichelpers"
+)
+
+const (
+	// defaultPodPortForwardWaitTimeout is the time to wait until at least one pod is running
+	defaultPodPortForwardWaitTimeout = 60 * time.Second
+	// retryInterval is the interval between the attempts to forward to a pod again when none is forwarded to
+	retryInterval = 2 * time.Second
+	// refreshInterval is the interval between the lookups of new pods to forward to with --all-pods
+	refreshInterval = 30 * time.Second
+)
+
+var (
+	portForwardLong = templates.LongDesc(`
+		Forward one or more local ports to a pod.
+
+		Use resource type/name such as deployment/mydeployment to select a pod. Resource type defaults to 'pod'
+		if omitted. If there are multiple pods matching the criteria, a pod will be selected automatically.
+
+		By default, the forwarding session ends when the selected pod terminates. With --retry, the local
+		ports keep listening and the forwarding is re-established with a running pod of the resource, which
+		is looked up again, for instance when the pod restarts or a deployment rolls out.
+
+		With --all-pods, the connections to the local ports are forwarded to all the running pods of the
+		resource in turn, for instance the pods behind a service. With --retry, the pods that start later
+		are forwarded to as well.
+	`)
+
+	portForwardExample = templates.Examples(`
+		# Listen on ports 5000 and 6000 locally, forwarding data to/from ports 5000 and 6000 in the pod
+		oc port-forward pod/mypod 5000 6000
+
+		# Listen on ports 5000 and 6000 locally, forwarding data to/from ports 5000 and 6000 in a pod selected by the deployment
+		oc port-forward deployment/mydeployment 5000 6000
+
+		# Listen on port 8443 locally, forwarding to the targetPort of the service's port named "https" in a pod selected by the service
+		oc port-forward service/myservice 8443:https
+
+		# Listen on port 8888 locally, forwarding to 5000 in the pod
+		oc port-forward pod/mypod 8888:5000
+
+		# Listen on port 8888 on all addresses, forwarding to 5000 in the pod
+		oc port-forward --address 0.0.0.0 pod/mypod 8888:5000
+
+		# Listen on a random port locally, forwarding to 5000 in the pod
+		oc port-forward pod/mypod :5000
+
+		# Keep forwarding port 8080 to a pod of the deployment when its pods restart
+		oc port-forward deployment/mydeployment 8080 --retry
+
+		# Forward the connections to port 8443 to all the pods behind the service in turn
+		oc port-forward service/myservice 8443:https --all-pods --retry
+	`)
+)
+
+// PortForwardOptions contains all the options for running the port-forward cli command.
+type PortForwardOptions struct {
+	Namespace         string
+	ResourceName      string
+	Ports             []string
+	Address           []string
+	Retry             bool
+	AllPods           bool
+	PodRunningTimeout time.Duration
+
+	// Object returns the resource to forward to, it is called again to look up its pods
+	Object func() (runtime.Object, error)
+	// Dial opens a port forwarding connection to the pod
+	Dial func(pod *corev1.Pod) (httpstream.Connection, error)
+
+	KubeClient   kubernetes.Interface
+	Config       *restclient.Config
+	StopChannel  chan struct{}
+	ReadyChannel chan struct{}
+
+	genericclioptions.IOStreams
+}
+
+func NewPortForwardOptions(streams genericclioptions.IOStreams) *PortForwardOptions {
+	return &PortForwardOptions{
+		Address:   []string{"localhost"},
+		IOStreams: streams,
+	}
+}
+
+// NewCmdPortForward creates a command that forwards local ports to pods.
+func NewCmdPortForward(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewPortForwardOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "port-forward TYPE/NAME [options] [LOCAL_PORT:]REMOTE_PORT [...[LOCAL_PORT_N:]REMOTE_PORT_N]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Forward one or more local ports to a pod",
+		Long:                  portForwardLong,
+		Example:               portForwardExample,
+		ValidArgsFunction:     completion.PodResourceNameCompletionFunc(f),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	kcmdutil.AddPodRunningTimeoutFlag(cmd, defaultPodPortForwardWaitTimeout)
+	cmd.Flags().StringSliceVar(&o.Address, "address", o.Address, "Addresses to listen on (comma separated). Only accepts IP addresses or localhost as a value. When localhost is supplied, oc will try to bind on both 127.0.0.1 and ::1 and will fail if neither of these addresses are available to bind.")
+	cmd.Flags().BoolVar(&o.Retry, "retry", o.Retry, "If true, keep listening when the forwarding to a pod is lost, and forward to a running pod of the resource again")
+	cmd.Flags().BoolVar(&o.AllPods, "all-pods", o.AllPods, "If true, forward the connections to all the running pods of the resource in turn")
+	return cmd
+}
+
+// Complete completes all the required options for port-forward cmd.
+func (o *PortForwardOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) < 2 {
+		return kcmdutil.UsageErrorf(cmd, "TYPE/NAME and list of ports are required for port-forward")
+	}
+	o.ResourceName = args[0]
+	o.Ports = args[1:]
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.PodRunningTimeout, err = kcmdutil.GetPodRunningTimeoutFlag(cmd)
+	if err != nil {
+		return kcmdutil.UsageErrorf(cmd, err.Error())
+	}
+
+	o.Config, err = f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+
+	o.Object = func() (runtime.Object, error) {
+		return f.NewBuilder().
+			WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+			NamespaceParam(o.Namespace).DefaultNamespace().
+			ResourceNames("pods", o.ResourceName).
+			SingleResourceType().
+			Do().Object()
+	}
+	o.Dial = o.dial
+
+	o.StopChannel = make(chan struct{}, 1)
+	o.ReadyChannel = make(chan struct{})
+	return nil
+}
+
+// Validate validates all the required options for port-forward cmd.
+func (o *PortForwardOptions) Validate() error {
+	if len(o.ResourceName) == 0 {
+		return fmt.Errorf("pod name or resource type/name must be specified")
+	}
+	if len(o.Ports) < 1 {
+		return fmt.Errorf("at least 1 PORT is required for port-forward")
+	}
+	if _, err := parsePorts(o.Ports); err != nil {
+		return err
+	}
+	if _, err := parseAddresses(o.Address); err != nil {
+		return err
+	}
+	if o.Object == nil || o.Dial == nil || o.KubeClient == nil {
+		return fmt.Errorf("client, resource lookup and dialer must be provided")
+	}
+	return nil
+}
+
+// Run forwards the local ports to the pods of the resource until it is interrupted, or until the forwarding is
+// lost without --retry.
+func (o *PortForwardOptions) Run() error {
+	ports, err := parsePorts(o.Ports)
+	if err != nil {
+		return err
+	}
+	addresses, err := parseAddresses(o.Address)
+	if err != nil {
+		return err
+	}
+	f := newForwarder(o, ports)
+
+	// wait for a running pod to forward to
+	var lastErr error
+	err = wait.PollImmediate(time.Second, o.PodRunningTimeout, func() (bool, error) {
+		lastErr = f.resolve()
+		if _, ok := lastErr.(*podNotRunningError); ok {
+			return false, nil
+		}
+		return true, lastErr
+	})
+	if err == wait.ErrWaitTimeout {
+		err = lastErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := f.listen(addresses); err != nil {
+		f.close()
+		return err
+	}
+	if o.ReadyChannel != nil {
+		close(o.ReadyChannel)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		if o.StopChannel != nil {
+			close(o.StopChannel)
+		}
+	}()
+
+	return f.run(o.StopChannel)
+}
+
+// dial opens a port forwarding connection to the pod.
+func (o *PortForwardOptions) dial(pod *corev1.Pod) (httpstream.Connection, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(o.Config)
+	if err != nil {
+		return nil, err
+	}
+	req := o.KubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, "POST", req.URL())
+	conn, _, err := dialer.Dial(portforward.PortForwardProtocolV1Name)
+	if err != nil {
+		return nil, fmt.Errorf("error upgrading connection: %v", err)
+	}
+	return conn, nil
+}
+
+// resolvePods returns the resource and its running pods, the most active first, or only the most active one if
+// AllPods is false.
+func (o *PortForwardOptions) resolvePods() (runtime.Object, []*corev1.Pod, error) {
+	obj, err := o.Object()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pods []*corev1.Pod
+	if pod, ok := obj.(*corev1.Pod); ok {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			return nil, nil, &podNotRunningError{fmt.Sprintf("unable to forward port because pod is not running. Current status=%v", pod.Status.Phase)}
+		}
+		return obj, []*corev1.Pod{pod}, nil
+	}
+
+	namespace, selector, err := selectorForObject(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot find the pods of the resource: %v", err)
+	}
+	list, err := o.KubeClient.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range list.Items {
+		pod := &list.Items[i]
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			pods = append(pods, pod)
+		}
+	}
+	if len(pods) == 0 {
+		return nil, nil, &podNotRunningError{fmt.Sprintf("no running pod found for %s", o.ResourceName)}
+	}
+	sort.Sort(sort.Reverse(podutils.ActivePods(pods)))
+	if !o.AllPods {
+		pods = pods[:1]
+	}
+	return obj, pods, nil
+}
+
+// podNotRunningError is returned when the resource has no running pod to forward to.
+type podNotRunningError struct {
+	message string
+}
+
+func (e *podNotRunningError) Error() string {
+	return e.message
+}
+
+// selectorForObject returns the namespace and the selector of the pods of the object, refusing the services
+// without a selector which would otherwise select every pod.
+func selectorForObject(obj runtime.Object) (string, labels.Selector, error) {
+	if service, ok := obj.(*corev1.Service); ok && len(service.Spec.Selector) == 0 {
+		return "", nil, fmt.Errorf("service %s has no selector", service.Name)
+	}
+	return originpolymorphichelpers.SelectorsForObject(obj)
+}
+
+// portSpec is a [LOCAL_PORT:]REMOTE_PORT argument, the remote port being a number or a name.
+type portSpec struct {
+	local  uint16
+	remote string
+	// sameLocal is true if the local port is the remote port, which can be a name
+	sameLocal bool
+}
+
+func parsePorts(ports []string) ([]portSpec, error) {
+	var specs []portSpec
+	for _, port := range ports {
+		parts := strings.Split(port, ":")
+		spec := portSpec{remote: parts[len(parts)-1]}
+		switch {
+		case len(parts) > 2:
+			return nil, fmt.Errorf("invalid port format '%s'", port)
+		case len(parts) == 1:
+			spec.sameLocal = true
+		case len(parts[0]) > 0:
+			local, err := strconv.ParseUint(parts[0], 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing local port '%s': %v", parts[0], err)
+			}
+			spec.local = uint16(local)
+		}
+		if len(spec.remote) == 0 {
+			return nil, fmt.Errorf("remote port must be specified in '%s'", port)
+		}
+		if remote, err := strconv.ParseUint(spec.remote, 10, 16); err == nil {
+			if remote == 0 {
+				return nil, fmt.Errorf("remote port must be > 0")
+			}
+			if spec.sameLocal {
+				spec.local = uint16(remote)
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// remotePorts returns the port of the pod that each port forwards to, translating the ports of a service to its
+// target ports and the port names to numbers.
+func remotePorts(obj runtime.Object, pod *corev1.Pod, ports []portSpec) ([]uint16, error) {
+	var remote []uint16
+	for _, port := range ports {
+		number, err := strconv.Atoi(port.remote)
+		if svc, ok := obj.(*corev1.Service); ok {
+			if err != nil {
+				svcPort, err := util.LookupServicePortNumberByName(*svc, port.remote)
+				if err != nil {
+					return nil, err
+				}
+				number = int(svcPort)
+			}
+			containerPort, err := util.LookupContainerPortNumberByServicePort(*svc, *pod, int32(number))
+			if err != nil {
+				return nil, err
+			}
+			number = int(containerPort)
+		} else if err != nil {
+			containerPort, err := util.LookupContainerPortNumberByName(*pod, port.remote)
+			if err != nil {
+				return nil, err
+			}
+			number = int(containerPort)
+		}
+		remote = append(remote, uint16(number))
+	}
+	return remote, nil
+}
+
+// listenAddress is an address to listen on, the listeners of the "all" failure mode failing only if all of them
+// fail, like localhost that may not have both an IPv4 and an IPv6 address.
+type listenAddress struct {
+	address     string
+	protocol    string
+	failureMode string
+}
+
+func parseAddresses(addresses []string) ([]listenAddress, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("you must specify at least 1 address")
+	}
+	var parsed []listenAddress
+	seen := map[string]bool{}
+	for _, address := range addresses {
+		var candidates []listenAddress
+		if address == "localhost" {
+			candidates = []listenAddress{
+				{address: "127.0.0.1", protocol: "tcp4", failureMode: "all"},
+				{address: "::1", protocol: "tcp6", failureMode: "all"},
+			}
+		} else if ip := net.ParseIP(address); ip != nil {
+			protocol := "tcp6"
+			if ip.To4() != nil {
+				protocol = "tcp4"
+			}
+			candidates = []listenAddress{{address: ip.String(), protocol: protocol, failureMode: "any"}}
+		} else {
+			return nil, fmt.Errorf("%s is not a valid IP", address)
+		}
+		for _, candidate := range candidates {
+			if !seen[candidate.address] {
+				seen[candidate.address] = true
+				parsed = append(parsed, candidate)
+			}
+		}
+	}
+	return parsed, nil
+}
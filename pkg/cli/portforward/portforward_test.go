@@ -0,0 +1,295 @@
+package portforward
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeConnection is a port forwarding connection to a pod, whose ports reply with the name of the pod and the port.
+type fakeConnection struct {
+	pod     string
+	closeCh chan bool
+	once    sync.Once
+}
+
+func (c *fakeConnection) CreateStream(headers http.Header) (httpstream.Stream, error) {
+	if headers.Get(corev1.StreamType) == corev1.StreamTypeError {
+		return &fakeStream{Conn: &eofConn{}, headers: headers}, nil
+	}
+	local, remote := net.Pipe()
+	go func() {
+		fmt.Fprintf(remote, "%s:%s", c.pod, headers.Get(corev1.PortHeader))
+		remote.Close()
+	}()
+	return &fakeStream{Conn: local, headers: headers}, nil
+}
+
+func (c *fakeConnection) Close() error {
+	c.once.Do(func() { close(c.closeCh) })
+	return nil
+}
+
+func (c *fakeConnection) CloseChan() <-chan bool                     { return c.closeCh }
+func (c *fakeConnection) SetIdleTimeout(timeout time.Duration)       {}
+func (c *fakeConnection) RemoveStreams(streams ...httpstream.Stream) {}
+
+type fakeStream struct {
+	net.Conn
+	headers http.Header
+}
+
+func (s *fakeStream) Reset() error         { return s.Close() }
+func (s *fakeStream) Headers() http.Header { return s.headers }
+func (s *fakeStream) Identifier() uint32   { return 0 }
+
+// eofConn is an error stream without error.
+type eofConn struct {
+	net.Conn
+}
+
+func (c *eofConn) Read(p []byte) (int, error) { return 0, io.EOF }
+func (c *eofConn) Close() error               { return nil }
+
+// fakeDialer dials fake connections and counts them by pod.
+type fakeDialer struct {
+	lock  sync.Mutex
+	conns map[string][]*fakeConnection
+}
+
+func (d *fakeDialer) Dial(pod *corev1.Pod) (httpstream.Connection, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	conn := &fakeConnection{pod: pod.Name, closeCh: make(chan bool)}
+	d.conns[pod.Name] = append(d.conns[pod.Name], conn)
+	return conn, nil
+}
+
+func (d *fakeDialer) connections(pod string) []*fakeConnection {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.conns[pod]
+}
+
+func testPod(name string, phase corev1.PodPhase, port int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", UID: types.UID("uid-" + name), Labels: map[string]string{"app": "frontend"}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Ports: []corev1.ContainerPort{{Name: "web", ContainerPort: port}}}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+var testService = &corev1.Service{
+	ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "test"},
+	Spec: corev1.ServiceSpec{
+		Selector: map[string]string{"app": "frontend"},
+		Ports:    []corev1.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromString("web")}},
+	},
+}
+
+// request connects to the local port and returns the reply.
+func request(t *testing.T, port uint16) string {
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reply, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(reply)
+}
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		ports    []string
+		expected []portSpec
+		err      bool
+	}{
+		{ports: []string{"5000", "8888:5000", ":5000"}, expected: []portSpec{
+			{local: 5000, remote: "5000", sameLocal: true},
+			{local: 8888, remote: "5000"},
+			{local: 0, remote: "5000"},
+		}},
+		{ports: []string{"https", "8443:https"}, expected: []portSpec{
+			{remote: "https", sameLocal: true},
+			{local: 8443, remote: "https"},
+		}},
+		{ports: []string{"1:2:3"}, err: true},
+		{ports: []string{"8080:"}, err: true},
+		{ports: []string{"0"}, err: true},
+		{ports: []string{"70000:80"}, err: true},
+	}
+	for _, tt := range tests {
+		specs, err := parsePorts(tt.ports)
+		if (err != nil) != tt.err {
+			t.Errorf("%v: unexpected error: %v", tt.ports, err)
+			continue
+		}
+		if !tt.err && !reflect.DeepEqual(specs, tt.expected) {
+			t.Errorf("%v: expected %#v, got %#v", tt.ports, tt.expected, specs)
+		}
+	}
+}
+
+func TestRemotePorts(t *testing.T) {
+	pod := testPod("pod-1", corev1.PodRunning, 8080)
+	tests := []struct {
+		name     string
+		obj      runtime.Object
+		ports    []string
+		expected []uint16
+		err      bool
+	}{
+		{name: "pod port numbers", obj: pod, ports: []string{"5000", "8888:5001"}, expected: []uint16{5000, 5001}},
+		{name: "pod port name", obj: pod, ports: []string{"web"}, expected: []uint16{8080}},
+		{name: "unknown pod port name", obj: pod, ports: []string{"metrics"}, err: true},
+		{name: "service port name", obj: testService, ports: []string{"8443:http"}, expected: []uint16{8080}},
+		{name: "service port number", obj: testService, ports: []string{"80"}, expected: []uint16{8080}},
+		{name: "unknown service port", obj: testService, ports: []string{"81"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, err := parsePorts(tt.ports)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ports, err := remotePorts(tt.obj, pod, specs)
+			if (err != nil) != tt.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.err && !reflect.DeepEqual(ports, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, ports)
+			}
+		})
+	}
+}
+
+func TestForwardAllPodsWithRetry(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		testPod("pod-1", corev1.PodRunning, 8080),
+		testPod("pod-2", corev1.PodRunning, 8081),
+		testPod("pod-3", corev1.PodPending, 8082),
+	)
+	dialer := &fakeDialer{conns: map[string][]*fakeConnection{}}
+	o := &PortForwardOptions{
+		ResourceName: "service/frontend",
+		Ports:        []string{":http"},
+		Retry:        true,
+		AllPods:      true,
+		Object:       func() (runtime.Object, error) { return testService, nil },
+		Dial:         dialer.Dial,
+		KubeClient:   client,
+		IOStreams:    genericclioptions.NewTestIOStreamsDiscard(),
+	}
+	ports, err := parsePorts(o.Ports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newForwarder(o, ports)
+	if err := f.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.listen([]listenAddress{{address: "127.0.0.1", protocol: "tcp4", failureMode: "any"}}); err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- f.run(stop) }()
+
+	local := f.ports[0].local
+	expected := []string{"pod-1:8080", "pod-2:8081"}
+	replies := []string{request(t, local), request(t, local)}
+	sort.Strings(replies)
+	if !reflect.DeepEqual(replies, expected) {
+		t.Errorf("expected the connections to be forwarded to %v, got %v", expected, replies)
+	}
+
+	// the forwarding to pod-1 is established again when it is lost
+	dialer.connections("pod-1")[0].Close()
+	err = wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return len(dialer.connections("pod-1")) == 2 && len(f.currentTargets()) == 2, nil
+	})
+	if err != nil {
+		t.Fatalf("pod-1 was not forwarded to again: %v", err)
+	}
+	replies = []string{request(t, local), request(t, local)}
+	sort.Strings(replies)
+	if !reflect.DeepEqual(replies, expected) {
+		t.Errorf("expected the connections to be forwarded to %v, got %v", expected, replies)
+	}
+	if n := len(dialer.connections("pod-2")); n != 1 {
+		t.Errorf("expected the connection to pod-2 to be kept, got %d connections", n)
+	}
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(local)))); err == nil {
+		t.Errorf("expected the local port to be closed")
+	}
+}
+
+func TestForwardLostWithoutRetry(t *testing.T) {
+	pod := testPod("pod-1", corev1.PodRunning, 8080)
+	dialer := &fakeDialer{conns: map[string][]*fakeConnection{}}
+	o := &PortForwardOptions{
+		ResourceName: "pod-1",
+		Ports:        []string{"0:web"},
+		Object:       func() (runtime.Object, error) { return pod, nil },
+		Dial:         dialer.Dial,
+		KubeClient:   fake.NewSimpleClientset(pod),
+		IOStreams:    genericclioptions.NewTestIOStreamsDiscard(),
+	}
+	ports, err := parsePorts(o.Ports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := newForwarder(o, ports)
+	if err := f.resolve(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.listen([]listenAddress{{address: "127.0.0.1", protocol: "tcp4", failureMode: "any"}}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- f.run(make(chan struct{})) }()
+
+	if reply := request(t, f.ports[0].local); reply != "pod-1:8080" {
+		t.Errorf("unexpected reply %q", reply)
+	}
+	dialer.connections("pod-1")[0].Close()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Errorf("expected an error when the connection is lost")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the forwarding did not stop when the connection was lost")
+	}
+	if n := len(dialer.connections("pod-1")); n != 1 {
+		t.Errorf("expected no new connection without --retry, got %d connections", n)
+	}
+}
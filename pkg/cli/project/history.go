@@ -0,0 +1,87 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxProjectHistory is the number of projects kept in the history file.
+const maxProjectHistory = 100
+
+// projectHistoryEntry is a project used on a server.
+type projectHistoryEntry struct {
+	server  string
+	project string
+}
+
+// readProjectHistory returns the projects recorded in the history file at path, most recently used first. The
+// history is empty if the file does not exist.
+func readProjectHistory(path string) ([]projectHistoryEntry, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var history []projectHistoryEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		history = append(history, projectHistoryEntry{server: fields[0], project: fields[1]})
+	}
+	return history, scanner.Err()
+}
+
+// recordProjects moves the projects of server to the top of the history file at path, in order, so that the last
+// one is the most recently used.
+func recordProjects(path, server string, projects ...string) error {
+	history, err := readProjectHistory(path)
+	if err != nil {
+		return err
+	}
+	for _, project := range projects {
+		if len(project) == 0 {
+			continue
+		}
+		entry := projectHistoryEntry{server: server, project: project}
+		updated := []projectHistoryEntry{entry}
+		for _, e := range history {
+			if e != entry {
+				updated = append(updated, e)
+			}
+		}
+		history = updated
+	}
+	if len(history) > maxProjectHistory {
+		history = history[:maxProjectHistory]
+	}
+
+	buf := &bytes.Buffer{}
+	for _, e := range history {
+		fmt.Fprintf(buf, "%s %s\n", e.server, e.project)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// recentProjects returns the projects of server in the history, most recently used first.
+func recentProjects(history []projectHistoryEntry, server string) []string {
+	var projects []string
+	for _, e := range history {
+		if e.server == server {
+			projects = append(projects, e.project)
+		}
+	}
+	return projects
+}
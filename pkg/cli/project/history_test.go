@@ -0,0 +1,53 @@
+package project
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProjectHistory(t *testing.T) {
+	const (
+		server      = "https://api.example.com:6443"
+		otherServer = "https://api.other.example.com:6443"
+	)
+	path := filepath.Join(t.TempDir(), ".kube", "oc-project-history")
+
+	history, err := readProjectHistory(path)
+	if err != nil || len(history) != 0 {
+		t.Fatalf("expected an empty history, got %v: %v", history, err)
+	}
+
+	for _, record := range []struct {
+		server   string
+		projects []string
+	}{
+		{server: server, projects: []string{"default", "dev"}},
+		{server: otherServer, projects: []string{"", "dev"}},
+		{server: server, projects: []string{"dev", "prod"}},
+		{server: server, projects: []string{"prod", "dev"}},
+	} {
+		if err := recordProjects(path, record.server, record.projects...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err = readProjectHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if projects, expected := recentProjects(history, server), []string{"dev", "prod", "default"}; !reflect.DeepEqual(projects, expected) {
+		t.Errorf("expected %v on %s, got %v", expected, server, projects)
+	}
+	if projects, expected := recentProjects(history, otherServer), []string{"dev"}; !reflect.DeepEqual(projects, expected) {
+		t.Errorf("expected %v on %s, got %v", expected, otherServer, projects)
+	}
+
+	o := &ProjectOptions{HistoryFile: path}
+	if previous, err := o.previousProject(server, "dev"); err != nil || previous != "prod" {
+		t.Errorf("expected prod to be the previous project, got %q: %v", previous, err)
+	}
+	if _, err := o.previousProject(otherServer, "dev"); err == nil {
+		t.Errorf("expected no previous project on %s", otherServer)
+	}
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
@@ -16,6 +20,7 @@ import (
 	"k8s.io/client-go/rest"
 	kclientcmd "k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/homedir"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -25,8 +30,12 @@ import (
 	projectv1client "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
 	cliconfig "github.com/openshift/oc/pkg/helpers/kubeconfig"
 	"github.com/openshift/oc/pkg/helpers/project"
+	"github.com/openshift/oc/pkg/helpers/term"
 )
 
+// previousProject is the argument that switches to the previous project.
+const previousProject = "-"
+
 type ProjectOptions struct {
 	Config      clientcmdapi.Config
 	RESTConfig  *rest.Config
@@ -39,6 +48,11 @@ type ProjectOptions struct {
 
 	Context string
 
+	// Interactive means that the user picks the project to switch to when no project is specified
+	Interactive bool
+	// HistoryFile records the projects used on each server, most recently used first
+	HistoryFile string
+
 	// SkipAccessValidation means that if a specific name is requested, don't bother checking for access to the project
 	SkipAccessValidation bool
 
@@ -49,8 +63,14 @@ var (
 	projectLong = templates.LongDesc(`
 		Switch to another project and make it the default in your configuration.
 
-		If no project was specified on the command line, display information about the current active
-		project. Since you can use this command to connect to projects on different servers, you will
+		If no project was specified on the command line and the command runs in a terminal, pick the
+		project to switch to among your projects, most recently used first, by number or by typing part
+		of its name. Enter nothing to keep the current project. Without a terminal, display information
+		about the current active project. The projects you switch to are recorded in
+		$HOME/.kube/oc-project-history, and 'oc project -' switches back to the previous project on the
+		server.
+
+		Since you can use this command to connect to projects on different servers, you will
 		occasionally encounter projects of the same name on different servers. When switching to that
 		project, a new local context will be created that will have a unique name - for instance,
 		'myapp-2'. If you have previously created a context with a different name than the project
@@ -64,8 +84,14 @@ var (
 		# Switch to the 'myapp' project
 		oc project myapp
 
-		# Display the project currently in use
+		# Pick the project to switch to, or display the project currently in use without a terminal
 		oc project
+
+		# Switch back to the previous project
+		oc project -
+
+		# Display the project currently in use
+		oc project --short
 	`)
 )
 
@@ -73,6 +99,7 @@ func NewProjectOptions(streams genericclioptions.IOStreams) *ProjectOptions {
 	return &ProjectOptions{
 		IOStreams:   streams,
 		PathOptions: kclientcmd.NewDefaultPathOptions(),
+		HistoryFile: filepath.Join(homedir.HomeDir(), ".kube", "oc-project-history"),
 	}
 }
 
@@ -81,7 +108,7 @@ func NewCmdProject(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cob
 	o := NewProjectOptions(streams)
 
 	cmd := &cobra.Command{
-		Use:               "project [NAME]",
+		Use:               "project [NAME | -]",
 		Short:             "Switch to another project",
 		Long:              projectLong,
 		Example:           projectExample,
@@ -122,6 +149,8 @@ func (o *ProjectOptions) Complete(f genericclioptions.RESTClientGetter, cmd *cob
 	// we need to set explicit path if one was specified, since NewDefaultPathOptions doesn't do it for us
 	o.PathOptions.LoadingRules.ExplicitPath = kcmdutil.GetFlagString(cmd, kclientcmd.RecommendedConfigPathFlag)
 
+	o.Interactive = term.IsTerminalReader(o.In) && !o.DisplayShort
+
 	o.RESTConfig, err = f.ToRESTConfig()
 	if err != nil {
 		contextNameExists := false
@@ -176,6 +205,23 @@ func (o ProjectOptions) Run() error {
 		currentProject = currentContext.Namespace
 	}
 
+	if o.ProjectName == previousProject {
+		previous, err := o.previousProject(clientCfg.Host, currentProject)
+		if err != nil {
+			return err
+		}
+		o.ProjectName = previous
+	}
+
+	// Let the user pick the project, they keep the current project if they pick none
+	if len(o.ProjectName) == 0 && o.Interactive {
+		picked, err := o.pickProject(clientCfg.Host, currentProject)
+		if err != nil {
+			return err
+		}
+		o.ProjectName = picked
+	}
+
 	// No argument provided, we will just print info
 	if len(o.ProjectName) == 0 {
 		if len(currentProject) > 0 {
@@ -294,6 +340,19 @@ func (o ProjectOptions) Run() error {
 		return err
 	}
 
+	// record the previous and the new project for 'oc project -' and the picker
+	serverInUse := clientCfg.Host
+	if cluster, exists := config.Clusters[config.Contexts[contextInUse].Cluster]; exists {
+		serverInUse = cluster.Server
+	}
+	err := recordProjects(o.HistoryFile, clientCfg.Host, currentProject)
+	if err == nil {
+		err = recordProjects(o.HistoryFile, serverInUse, namespaceInUse)
+	}
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: unable to record the project history: %v\n", err)
+	}
+
 	if o.DisplayShort {
 		fmt.Fprintln(o.Out, namespaceInUse)
 		return nil
@@ -326,6 +385,80 @@ func (o ProjectOptions) Run() error {
 	return nil
 }
 
+// previousProject returns the most recently used project of server other than the current project.
+func (o *ProjectOptions) previousProject(server, currentProject string) (string, error) {
+	history, err := readProjectHistory(o.HistoryFile)
+	if err != nil {
+		return "", err
+	}
+	for _, project := range recentProjects(history, server) {
+		if project != currentProject {
+			return project, nil
+		}
+	}
+	return "", fmt.Errorf("no previous project was recorded on server %q", server)
+}
+
+// pickProject asks the user to pick one of their projects, most recently used first. It returns an empty name if
+// the user picks none.
+func (o *ProjectOptions) pickProject(server, currentProject string) (string, error) {
+	client, kubeclient, err := o.ClientFn()
+	if err != nil {
+		return "", err
+	}
+	projects, err := GetProjects(client, kubeclient)
+	if err != nil || len(projects) == 0 {
+		return "", err
+	}
+	history, err := readProjectHistory(o.HistoryFile)
+	if err != nil {
+		return "", err
+	}
+
+	rank := map[string]int{}
+	for i, project := range recentProjects(history, server) {
+		rank[project] = i + 1
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		ri, rj := rank[projects[i].Name], rank[projects[j].Name]
+		switch {
+		case ri > 0 && rj > 0:
+			return ri < rj
+		case ri > 0 || rj > 0:
+			return ri > 0
+		}
+		return projects[i].Name < projects[j].Name
+	})
+
+	byName := map[string]*projectv1.Project{}
+	names := make([]string, 0, len(projects))
+	for i := range projects {
+		byName[projects[i].Name] = &projects[i]
+		names = append(names, projects[i].Name)
+	}
+	return term.Pick(o.In, o.Out, o.ErrOut, "project", names, func(names []string) {
+		printProjects(o.Out, names, byName, currentProject)
+	}), nil
+}
+
+// printProjects prints the numbered list of projects to pick from, with the current project marked.
+func printProjects(out io.Writer, names []string, projects map[string]*projectv1.Project, currentProject string) {
+	w := tabwriter.NewWriter(out, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(w, "#\tCURRENT\tNAME\tDISPLAY NAME")
+	for i, name := range names {
+		current := ""
+		if name == currentProject {
+			current = "*"
+		}
+		displayName := projects[name].Annotations[annotations.OpenShiftDisplayName]
+		if len(displayName) == 0 {
+			displayName = projects[name].Annotations["displayName"]
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, current, name, displayName)
+	}
+	w.Flush()
+}
+
 // returns a context by the given contextName and a boolean true if the context exists
 func (o *ProjectOptions) GetContextFromName(contextName string) (*clientcmdapi.Context, bool) {
 	if context, contextExists := o.Config.Contexts[contextName]; !o.ProjectOnly && contextExists {
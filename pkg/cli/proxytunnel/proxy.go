@@ -0,0 +1,201 @@
+package proxytunnel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	socksVersion = 0x05
+
+	socksMethodNoAuth       = 0x00
+	socksMethodNoAcceptable = 0xff
+
+	socksCommandConnect = 0x01
+
+	socksAddressIPv4   = 0x01
+	socksAddressDomain = 0x03
+	socksAddressIPv6   = 0x04
+
+	socksReplySucceeded          = 0x00
+	socksReplyGeneralFailure     = 0x01
+	socksReplyCommandUnsupported = 0x07
+	socksReplyAddressUnsupported = 0x08
+)
+
+// proxy accepts SOCKS5 and HTTP CONNECT proxy connections, and tunnels them through the tunnel pod.
+type proxy struct {
+	pod    *corev1.Pod
+	tunnel func(pod *corev1.Pod, host, port string, in io.Reader, out, errOut io.Writer) error
+	out    io.Writer
+	errOut io.Writer
+}
+
+func newProxy(pod *corev1.Pod, tunnel func(pod *corev1.Pod, host, port string, in io.Reader, out, errOut io.Writer) error, out, errOut io.Writer) *proxy {
+	return &proxy{pod: pod, tunnel: tunnel, out: out, errOut: errOut}
+}
+
+// Serve handles the connections to the listener until it is closed.
+func (p *proxy) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if strings.Contains(err.Error(), "use of closed network connection") {
+				return nil
+			}
+			return err
+		}
+		go p.handle(conn)
+	}
+}
+
+// handle reads the destination of the connection with the proxy protocol the client uses, and tunnels it.
+func (p *proxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	first, err := r.Peek(1)
+	if err != nil {
+		return
+	}
+	var address string
+	var reply func(ok bool) error
+	if first[0] == socksVersion {
+		address, reply, err = socksHandshake(r, conn)
+	} else {
+		address, reply, err = httpConnectHandshake(r, conn)
+	}
+	if err != nil {
+		klog.V(2).Infof("Rejecting proxy connection from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err == nil && (len(host) == 0 || strings.HasPrefix(host, "-")) {
+		err = fmt.Errorf("invalid host %q", host)
+	}
+	if err != nil {
+		reply(false)
+		klog.V(2).Infof("Rejecting proxy connection to %s: %v", address, err)
+		return
+	}
+	// the connection is opened in the pod after the reply, a failure closes the client connection
+	if err := reply(true); err != nil {
+		return
+	}
+
+	fmt.Fprintf(p.out, "Tunneling connection to %s\n", address)
+	errBuf := &bytes.Buffer{}
+	if err := p.tunnel(p.pod, host, port, r, conn, errBuf); err != nil {
+		fmt.Fprintf(p.errOut, "error: connection to %s failed: %v %s\n", address, err, strings.TrimSpace(errBuf.String()))
+	}
+}
+
+// socksHandshake negotiates a SOCKS5 connection without authentication, and returns the address to connect to
+// and the function sending the reply to the client.
+func socksHandshake(r *bufio.Reader, w io.Writer) (string, func(ok bool) error, error) {
+	// version, methods
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", nil, err
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(r, methods); err != nil {
+		return "", nil, err
+	}
+	if bytes.IndexByte(methods, socksMethodNoAuth) < 0 {
+		w.Write([]byte{socksVersion, socksMethodNoAcceptable})
+		return "", nil, errors.New("the SOCKS client requires authentication")
+	}
+	if _, err := w.Write([]byte{socksVersion, socksMethodNoAuth}); err != nil {
+		return "", nil, err
+	}
+
+	// version, command, reserved, address type
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(r, request); err != nil {
+		return "", nil, err
+	}
+	if request[0] != socksVersion {
+		return "", nil, fmt.Errorf("unsupported SOCKS version %d", request[0])
+	}
+	var host string
+	switch request[3] {
+	case socksAddressIPv4, socksAddressIPv6:
+		ip := make([]byte, net.IPv4len)
+		if request[3] == socksAddressIPv6 {
+			ip = make([]byte, net.IPv6len)
+		}
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return "", nil, err
+		}
+		host = net.IP(ip).String()
+	case socksAddressDomain:
+		length, err := r.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+		domain := make([]byte, length)
+		if _, err := io.ReadFull(r, domain); err != nil {
+			return "", nil, err
+		}
+		host = string(domain)
+	default:
+		socksReply(w, socksReplyAddressUnsupported)
+		return "", nil, fmt.Errorf("unsupported SOCKS address type %d", request[3])
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(r, port); err != nil {
+		return "", nil, err
+	}
+	if request[1] != socksCommandConnect {
+		socksReply(w, socksReplyCommandUnsupported)
+		return "", nil, fmt.Errorf("unsupported SOCKS command %d", request[1])
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+	return address, func(ok bool) error {
+		if !ok {
+			return socksReply(w, socksReplyGeneralFailure)
+		}
+		return socksReply(w, socksReplySucceeded)
+	}, nil
+}
+
+// socksReply sends a reply without bound address to the client.
+func socksReply(w io.Writer, reply byte) error {
+	_, err := w.Write([]byte{socksVersion, reply, 0, socksAddressIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// httpConnectHandshake reads an HTTP CONNECT request, and returns the address to connect to and the function sending
+// the response to the client.
+func httpConnectHandshake(r *bufio.Reader, w io.Writer) (string, func(ok bool) error, error) {
+	req, err := http.ReadRequest(r)
+	if err != nil {
+		return "", nil, err
+	}
+	if req.Method != http.MethodConnect {
+		fmt.Fprintf(w, "HTTP/1.1 405 Method Not Allowed\r\nAllow: CONNECT\r\nConnection: close\r\n\r\n")
+		return "", nil, fmt.Errorf("unsupported HTTP method %s, only CONNECT is supported", req.Method)
+	}
+	return req.Host, func(ok bool) error {
+		if !ok {
+			_, err := fmt.Fprintf(w, "HTTP/1.1 400 Bad Request\r\nConnection: close\r\n\r\n")
+			return err
+		}
+		_, err := fmt.Fprintf(w, "HTTP/1.1 200 Connection established\r\n\r\n")
+		return err
+	}, nil
+}
@@ -0,0 +1,196 @@
+package proxytunnel
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// startProxy serves a proxy whose tunnel replies with the address it connects to and the data it receives.
+func startProxy(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	tunnel := func(pod *corev1.Pod, host, port string, in io.Reader, out, errOut io.Writer) error {
+		data, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s %s:%s %s", pod.Name, host, port, data)
+		return err
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "proxy-tunnel-abcde"}}
+	go newProxy(pod, tunnel, ioutil.Discard, ioutil.Discard).Serve(listener)
+	return listener.Addr().String()
+}
+
+func TestSOCKSProxy(t *testing.T) {
+	address := startProxy(t)
+
+	tests := []struct {
+		name     string
+		methods  []byte
+		request  []byte
+		reply    []byte
+		expected string
+	}{
+		{
+			name:     "domain",
+			methods:  []byte{5, 1, 0},
+			request:  append(append([]byte{5, 1, 0, 3, 20}, "api.backend.svc.test"...), 0x1f, 0x90),
+			reply:    []byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0},
+			expected: "proxy-tunnel-abcde api.backend.svc.test:8080 hello\n",
+		},
+		{
+			name:     "IPv4",
+			methods:  []byte{5, 2, 2, 0},
+			request:  []byte{5, 1, 0, 1, 172, 30, 0, 10, 0x15, 0x38},
+			reply:    []byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0},
+			expected: "proxy-tunnel-abcde 172.30.0.10:5432 hello\n",
+		},
+		{
+			name:     "IPv6",
+			methods:  []byte{5, 1, 0},
+			request:  []byte{5, 1, 0, 4, 0xfd, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 80},
+			reply:    []byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0},
+			expected: "proxy-tunnel-abcde fd00::1:80 hello\n",
+		},
+		{
+			name:    "bind is not supported",
+			methods: []byte{5, 1, 0},
+			request: []byte{5, 2, 0, 1, 127, 0, 0, 1, 0, 80},
+			reply:   []byte{5, 7, 0, 1, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name:    "invalid host",
+			methods: []byte{5, 1, 0},
+			request: append(append([]byte{5, 1, 0, 3, 2}, "-e"...), 0, 80),
+			reply:   []byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			conn.Write(tt.methods)
+			method := make([]byte, 2)
+			if _, err := io.ReadFull(conn, method); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(method, []byte{5, 0}) {
+				t.Fatalf("expected no authentication, got %v", method)
+			}
+			conn.Write(tt.request)
+			reply := make([]byte, len(tt.reply))
+			if _, err := io.ReadFull(conn, reply); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(reply, tt.reply) {
+				t.Fatalf("expected reply %v, got %v", tt.reply, reply)
+			}
+
+			if len(tt.expected) == 0 {
+				return
+			}
+			fmt.Fprintf(conn, "hello\n")
+			data, err := ioutil.ReadAll(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, string(data))
+			}
+		})
+	}
+}
+
+func TestSOCKSProxyRequiresAuthentication(t *testing.T) {
+	conn, err := net.Dial("tcp", startProxy(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte{5, 1, 2})
+	data, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{5, 0xff}) {
+		t.Errorf("expected no acceptable method, got %v", data)
+	}
+}
+
+func TestHTTPConnectProxy(t *testing.T) {
+	address := startProxy(t)
+
+	tests := []struct {
+		name     string
+		request  string
+		status   int
+		expected string
+	}{
+		{
+			name:     "connect",
+			request:  "CONNECT api.backend.svc:8443 HTTP/1.1\r\nHost: api.backend.svc:8443\r\n\r\n",
+			status:   http.StatusOK,
+			expected: "proxy-tunnel-abcde api.backend.svc:8443 hello\n",
+		},
+		{
+			name:    "missing port",
+			request: "CONNECT api.backend.svc HTTP/1.1\r\nHost: api.backend.svc\r\n\r\n",
+			status:  http.StatusBadRequest,
+		},
+		{
+			name:    "plain HTTP is not supported",
+			request: "GET http://api.backend.svc/ HTTP/1.1\r\nHost: api.backend.svc\r\n\r\n",
+			status:  http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			fmt.Fprint(conn, tt.request)
+			r := bufio.NewReader(conn)
+			resp, err := http.ReadResponse(r, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+
+			if len(tt.expected) == 0 {
+				return
+			}
+			fmt.Fprintf(conn, "hello\n")
+			data, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, string(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package proxytunnel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	kapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/remotecommand"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/interrupt"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/oc/pkg/helpers/conditions"
+)
+
+const (
+	// tunnelLabel is set on the tunnel pods
+	tunnelLabel = "proxy-tunnel.openshift.io/tunnel"
+	// tunnelContainer is the name of the container of the tunnel pod that connects to the destinations
+	tunnelContainer = "tunnel"
+	// tunnelCommand keeps the tunnel pod running until it is stopped
+	tunnelCommand = "trap 'exit 0' TERM; sleep infinity & wait"
+	// defaultImageStreamNamespace, defaultImageStream and defaultImageStreamTag are the image stream tag of the
+	// default image of the tunnel pod
+	defaultImageStreamNamespace = "openshift"
+	defaultImageStream          = "tools"
+	defaultImageStreamTag       = "latest"
+	// fallbackImage is the image of the tunnel pod when the default image stream cannot be resolved
+	fallbackImage = "registry.redhat.io/rhel8/support-tools"
+)
+
+var (
+	proxyTunnelLong = templates.LongDesc(`
+		Run a local proxy that tunnels connections through the cluster network.
+
+		This command starts a pod in the current namespace, and runs a SOCKS5 and HTTP CONNECT
+		proxy on a local port. The TCP connections made through the proxy are opened from the
+		pod, so in-cluster services such as databases or internal APIs can be reached with their
+		service names without creating routes or port forwards. Each connection runs the nc
+		command in the pod, which the image of the pod must provide.
+
+		The pod is deleted when the command is interrupted. It is stopped after --ttl anyway,
+		in case the command cannot delete it.
+	`)
+
+	proxyTunnelExample = templates.Examples(`
+		# Run a SOCKS5 proxy on local port 1080 tunneling through the current namespace
+		oc proxy-tunnel
+
+		# Reach a service of the backend namespace through the tunnel
+		oc proxy-tunnel -n backend --port 9050
+		curl --socks5-hostname localhost:9050 http://api.backend.svc:8080/healthz
+
+		# Use the tunnel as an HTTP proxy
+		oc proxy-tunnel
+		https_proxy=http://localhost:1080 curl https://api.backend.svc:8443/healthz
+	`)
+)
+
+// ProxyTunnelOptions holds the options of the proxy-tunnel command.
+type ProxyTunnelOptions struct {
+	Namespace string
+	Address   string
+	Port      int
+	Image     string
+	TTL       time.Duration
+	Timeout   time.Duration
+
+	// Tunnel connects to the address from the tunnel pod, and streams the connection
+	Tunnel func(pod *corev1.Pod, host, port string, in io.Reader, out, errOut io.Writer) error
+
+	KubeClient  kubernetes.Interface
+	ImageClient imagev1client.ImageV1Interface
+	Config      *restclient.Config
+
+	genericclioptions.IOStreams
+}
+
+func NewProxyTunnelOptions(streams genericclioptions.IOStreams) *ProxyTunnelOptions {
+	return &ProxyTunnelOptions{
+		Address:   "127.0.0.1",
+		Port:      1080,
+		TTL:       8 * time.Hour,
+		Timeout:   5 * time.Minute,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdProxyTunnel creates a command that runs a local proxy tunneling through a pod.
+func NewCmdProxyTunnel(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewProxyTunnelOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "proxy-tunnel [--port=PORT] [--image=IMAGE]",
+		Short:   "Run a local SOCKS5 and HTTP proxy tunneling through the cluster network",
+		Long:    proxyTunnelLong,
+		Example: proxyTunnelExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().StringVar(&o.Address, "address", o.Address, "The IP address to listen on")
+	cmd.Flags().IntVar(&o.Port, "port", o.Port, "The local port of the proxy, 0 for a random port")
+	cmd.Flags().StringVar(&o.Image, "image", o.Image, "The image of the tunnel pod, which must provide the nc command; defaults to the openshift/tools image stream")
+	cmd.Flags().DurationVar(&o.TTL, "ttl", o.TTL, "The time after which the tunnel pod stops")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The time to wait for the tunnel pod to start")
+	return cmd
+}
+
+func (o *ProxyTunnelOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.Config, err = f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+	o.ImageClient, err = imagev1client.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+	o.Tunnel = o.execTunnel
+	return nil
+}
+
+func (o *ProxyTunnelOptions) Validate() error {
+	if net.ParseIP(o.Address) == nil {
+		return fmt.Errorf("--address must be an IP address")
+	}
+	if o.Port < 0 || o.Port > 65535 {
+		return fmt.Errorf("--port must be between 0 and 65535")
+	}
+	if o.TTL <= 0 {
+		return fmt.Errorf("--ttl must be positive")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	return nil
+}
+
+// Run starts the tunnel pod, and serves the proxy until the command is interrupted.
+func (o *ProxyTunnelOptions) Run() error {
+	image := o.Image
+	if len(image) == 0 {
+		image = o.defaultImage()
+	}
+	pod, err := o.KubeClient.CoreV1().Pods(o.Namespace).Create(context.TODO(), o.tunnelPod(image), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to create the tunnel pod: %v", err)
+	}
+
+	// ensure the pod is deleted on shutdown
+	return interrupt.New(
+		func(os.Signal) { os.Exit(0) },
+		func() {
+			fmt.Fprintf(o.ErrOut, "\nRemoving tunnel pod/%s ...\n", pod.Name)
+			if err := o.KubeClient.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, *metav1.NewDeleteOptions(0)); err != nil && !kapierrors.IsNotFound(err) {
+				fmt.Fprintf(o.ErrOut, "error: unable to delete the tunnel pod %q: %v\n", pod.Name, err)
+			}
+		},
+	).Run(func() error {
+		fmt.Fprintf(o.ErrOut, "Starting tunnel pod/%s in namespace %s ...\n", pod.Name, pod.Namespace)
+		if err := o.waitForPod(pod); err != nil {
+			return fmt.Errorf("the tunnel pod/%s did not start: %v", pod.Name, err)
+		}
+
+		listener, err := net.Listen("tcp", net.JoinHostPort(o.Address, strconv.Itoa(o.Port)))
+		if err != nil {
+			return err
+		}
+		defer listener.Close()
+		fmt.Fprintf(o.Out, "Proxying through pod/%s on %s, press Ctrl+C to stop\n", pod.Name, listener.Addr())
+		return newProxy(pod, o.Tunnel, o.Out, o.ErrOut).Serve(listener)
+	})
+}
+
+// defaultImage returns the image of the default image stream, or the fallback image if it cannot be resolved.
+func (o *ProxyTunnelOptions) defaultImage() string {
+	imageStream, err := o.ImageClient.ImageStreams(defaultImageStreamNamespace).Get(context.TODO(), defaultImageStream, metav1.GetOptions{})
+	if err == nil {
+		var image string
+		if image, _, _, _, err = imageutil.ResolveRecentPullSpecForTag(imageStream, defaultImageStreamTag, false); err == nil {
+			klog.V(4).Infof("Defaulted image from imagestream %s/%s:%s: %s", defaultImageStreamNamespace, defaultImageStream, defaultImageStreamTag, image)
+			return image
+		}
+	}
+	klog.V(2).Infof("Unable to resolve the default image stream, falling back to %s: %v", fallbackImage, err)
+	return fallbackImage
+}
+
+// tunnelPod returns the pod the connections are opened from.
+func (o *ProxyTunnelOptions) tunnelPod(image string) *corev1.Pod {
+	deadline := int64(o.TTL / time.Second)
+	zero := int64(0)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "proxy-tunnel-",
+			Namespace:    o.Namespace,
+			Labels:       map[string]string{tunnelLabel: "true"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:    tunnelContainer,
+					Image:   image,
+					Command: []string{"/bin/sh", "-c", tunnelCommand},
+				},
+			},
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds:         &deadline,
+			TerminationGracePeriodSeconds: &zero,
+		},
+	}
+}
+
+// waitForPod waits until the container of the tunnel pod is running.
+func (o *ProxyTunnelOptions) waitForPod(pod *corev1.Pod) error {
+	ns := pod.Namespace
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", pod.Name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return o.KubeClient.CoreV1().Pods(ns).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return o.KubeClient.CoreV1().Pods(ns).Watch(context.TODO(), options)
+		},
+	}
+	notifyFn := func(pod *corev1.Pod, container corev1.ContainerStatus) error {
+		if container.State.Waiting != nil {
+			switch container.State.Waiting.Reason {
+			case "CreateContainerError", "ImagePullBackOff":
+				fmt.Fprintf(o.ErrOut, "warning: Container %s is unable to start due to an error: %s\n", container.Name, container.State.Waiting.Message)
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	_, err := watchtools.UntilWithSync(ctx, lw, &corev1.Pod{}, nil, conditions.PodContainerRunning(tunnelContainer, o.KubeClient.CoreV1(), notifyFn))
+	return err
+}
+
+// execTunnel runs nc in the tunnel pod to connect to the address, streaming the connection to in and out.
+func (o *ProxyTunnelOptions) execTunnel(pod *corev1.Pod, host, port string, in io.Reader, out, errOut io.Writer) error {
+	req := o.KubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: tunnelContainer,
+			Command:   []string{"nc", host, port},
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	exec, err := remotecommand.NewSPDYExecutor(o.Config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	return exec.Stream(remotecommand.StreamOptions{
+		Stdin:  in,
+		Stdout: out,
+		Stderr: errOut,
+	})
+}
@@ -0,0 +1,97 @@
+package login
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	helperclient "github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// newHelperProgram runs the docker-credential-<helper> binary of a credential helper.
+var newHelperProgram = func(helper string) helperclient.ProgramFunc {
+	return helperclient.NewShellProgramFunc("docker-credential-" + helper)
+}
+
+// credentialHelper returns the credential helper to store the credentials of the registry with: the one requested
+// with --credential-helper, or else the one the credentials file configures for the registry or, like Docker does,
+// for all of them.
+func (o *LoginOptions) credentialHelper(hostPort string) (string, error) {
+	if len(o.CredentialHelper) > 0 {
+		return o.CredentialHelper, nil
+	}
+
+	config := struct {
+		CredsStore  string            `json:"credsStore"`
+		CredHelpers map[string]string `json:"credHelpers"`
+	}{}
+	data, err := ioutil.ReadFile(o.ConfigFile)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return "", nil
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", fmt.Errorf("unable to read the credentials file %s: %v", o.ConfigFile, err)
+	}
+	if helper, ok := config.CredHelpers[hostPort]; ok {
+		return helper, nil
+	}
+	return config.CredsStore, nil
+}
+
+// storeInCredentialHelper stores the credentials of the registry with the credential helper.
+func storeInCredentialHelper(helper, hostPort, username, password string) error {
+	creds := &credentials.Credentials{
+		ServerURL: hostPort,
+		Username:  username,
+		Secret:    password,
+	}
+	if err := helperclient.Store(newHelperProgram(helper), creds); err != nil {
+		return fmt.Errorf("unable to store your credentials with credential helper %s: %v", helper, err)
+	}
+	return nil
+}
+
+// recordCredentialHelper records the credential helper of the registry in the credentials file, so that podman and
+// the other tools ignoring credsStore find the credentials too, and removes any credentials stored in plain text for
+// the registry. The other settings of the file are kept as is.
+func recordCredentialHelper(configFile, hostPort, helper string) error {
+	config := map[string]interface{}{}
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return fmt.Errorf("unable to read the credentials file %s: %v", configFile, err)
+		}
+	}
+
+	helpers, ok := config["credHelpers"].(map[string]interface{})
+	if !ok {
+		helpers = map[string]interface{}{}
+	}
+	helpers[hostPort] = helper
+	config["credHelpers"] = helpers
+	if auths, ok := config["auths"].(map[string]interface{}); ok {
+		delete(auths, hostPort)
+	}
+
+	data, err = json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configFile), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(configFile, data, 0600)
+}
@@ -0,0 +1,152 @@
+package login
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	helperclient "github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+type fakeHelperProgram struct {
+	stored *[]credentials.Credentials
+	input  io.Reader
+}
+
+func (p *fakeHelperProgram) Input(in io.Reader) {
+	p.input = in
+}
+
+func (p *fakeHelperProgram) Output() ([]byte, error) {
+	creds := credentials.Credentials{}
+	if err := json.NewDecoder(p.input).Decode(&creds); err != nil {
+		return nil, err
+	}
+	*p.stored = append(*p.stored, creds)
+	return nil, nil
+}
+
+func readConfig(t *testing.T, path string) map[string]interface{} {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestLoginWithCredentialHelper(t *testing.T) {
+	stored := []credentials.Credentials{}
+	helpers := []string{}
+	newHelperProgram = func(helper string) helperclient.ProgramFunc {
+		return func(args ...string) helperclient.Program {
+			helpers = append(helpers, helper)
+			return &fakeHelperProgram{stored: &stored}
+		}
+	}
+	defer func() {
+		newHelperProgram = func(helper string) helperclient.ProgramFunc {
+			return helperclient.NewShellProgramFunc("docker-credential-" + helper)
+		}
+	}()
+
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	config := `{"auths": {"quay.io": {"auth": "dXNlcjpwYXNz"}, "registry.example.com": {}}, "credsStore": "desktop", "credHelpers": {"quay.io": "pass"}, "psFormat": "table"}`
+	if err := ioutil.WriteFile(configFile, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	o := &LoginOptions{
+		ConfigFile:  configFile,
+		Credentials: newCredentials("user", "token"),
+		HostPorts:   []string{"quay.io", "registry.example.com"},
+		SkipCheck:   true,
+		IOStreams:   genericclioptions.IOStreams{Out: out, ErrOut: ioutil.Discard},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(helpers, []string{"pass", "desktop"}) {
+		t.Errorf("expected the registry and default credential helpers to be used, got %v", helpers)
+	}
+	if len(stored) != 2 || stored[0].ServerURL != "quay.io" || stored[1].Username != "user" || stored[1].Secret != "token" {
+		t.Errorf("unexpected stored credentials %v", stored)
+	}
+	for _, expected := range []string{"Saved credentials for quay.io with credential helper pass", "Saved credentials for registry.example.com with credential helper desktop", "Logged in to 2 of 2 registries"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, out.String())
+		}
+	}
+
+	result := readConfig(t, configFile)
+	if auths := result["auths"].(map[string]interface{}); len(auths) != 0 {
+		t.Errorf("expected no credentials in plain text, got %v", auths)
+	}
+	expectedHelpers := map[string]interface{}{"quay.io": "pass", "registry.example.com": "desktop"}
+	if !reflect.DeepEqual(result["credHelpers"], expectedHelpers) {
+		t.Errorf("expected the credential helpers to be recorded, got %v", result["credHelpers"])
+	}
+	if result["psFormat"] != "table" || result["credsStore"] != "desktop" {
+		t.Errorf("expected the other settings to be kept, got %v", result)
+	}
+
+	// an explicit credential helper takes precedence over the configured ones
+	stored, helpers = nil, nil
+	o.CredentialHelper = "osxkeychain"
+	o.HostPorts = []string{"quay.io"}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(helpers, []string{"osxkeychain"}) {
+		t.Errorf("expected the requested credential helper to be used, got %v", helpers)
+	}
+	if helper := readConfig(t, configFile)["credHelpers"].(map[string]interface{})["quay.io"]; helper != "osxkeychain" {
+		t.Errorf("expected the requested credential helper to be recorded, got %v", helper)
+	}
+}
+
+func TestLoginWithoutCredentialHelper(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	o := &LoginOptions{
+		ConfigFile:  configFile,
+		Credentials: newCredentials("user", "token"),
+		HostPorts:   []string{"registry.example.com"},
+		SkipCheck:   true,
+		IOStreams:   genericclioptions.IOStreams{Out: ioutil.Discard, ErrOut: ioutil.Discard},
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	auths := readConfig(t, configFile)["auths"].(map[string]interface{})
+	if _, ok := auths["registry.example.com"]; !ok {
+		t.Errorf("expected the credentials to be stored in the file, got %v", auths)
+	}
+}
+
+func TestLoginValidateCredentialHelper(t *testing.T) {
+	o := &LoginOptions{
+		ConfigFile:       "-",
+		Credentials:      newCredentials("user", "token"),
+		HostPorts:        []string{"registry.example.com"},
+		CredentialHelper: "pass",
+	}
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "standard output") {
+		t.Errorf("expected an error about standard output, got %v", err)
+	}
+}
@@ -58,6 +58,13 @@ var (
 		custom DNS name, or to an external registry. Note that in absence of --auth-basic=USER:PASSWORD,
 		the authentication token from the connected kubeconfig file will be recorded as the auth entry
 		in the credentials file (defaults to Docker config.json) for the passed registry value.
+		The --registry flag may be repeated to log in to several registries at once, with the same
+		credentials.
+
+		When the credentials file configures a Docker credential helper for the registry with
+		credHelpers, or for all the registries with credsStore, the credentials are stored with the
+		helper rather than in plain text. Pass --credential-helper to store them with a given helper,
+		like osxkeychain, wincred or pass, which is then recorded for the registry in the credentials file.
 
 		Experimental: This command is under active development and may change without notice.`)
 
@@ -67,6 +74,9 @@ var (
 
 		# Log in to different registry using BASIC auth credentials
 		oc registry login --registry quay.io/myregistry --auth-basic=USER:PASS
+
+		# Log in to the integrated registry under two host names, storing the credentials in the macOS keychain
+		oc registry login --registry=registry.example.com --registry=default-route-openshift-image-registry.apps.example.com --credential-helper=osxkeychain
 	`)
 )
 
@@ -89,11 +99,12 @@ func (c Credentials) Empty() bool {
 }
 
 type LoginOptions struct {
-	ConfigFile  string
-	Credentials Credentials
-	HostPort    string
-	SkipCheck   bool
-	Insecure    bool
+	ConfigFile       string
+	Credentials      Credentials
+	HostPorts        []string
+	SkipCheck        bool
+	Insecure         bool
+	CredentialHelper string
 
 	AuthBasic      string
 	ServiceAccount string
@@ -130,7 +141,8 @@ func NewRegistryLoginCmd(f kcmdutil.Factory, streams genericclioptions.IOStreams
 	flag.StringVar(&o.ConfigFile, "to", o.ConfigFile, "The location of the file your credentials will be stored in. Alternatively REGISTRY_AUTH_FILE env variable can be also specified. Default is Docker config.json (deprecated). Default can be changed via REGISTRY_AUTH_PREFERENCE env variable to docker or podman.")
 	flag.StringVarP(&o.ServiceAccount, "service-account", "z", o.ServiceAccount, "Log in as the specified service account name in the specified namespace.")
 	flag.MarkDeprecated("service-account", "and will be removed in the future version. Use oc create token instead.")
-	flag.StringVar(&o.HostPort, "registry", o.HostPort, "An alternate domain name and port to use for the registry, defaults to the cluster's configured external hostname.")
+	flag.StringSliceVar(&o.HostPorts, "registry", o.HostPorts, "An alternate domain name and port to use for the registry, defaults to the cluster's configured external hostname. May be repeated to log in to several registries.")
+	flag.StringVar(&o.CredentialHelper, "credential-helper", o.CredentialHelper, "The Docker credential helper to store the credentials with, like osxkeychain, wincred or pass. Defaults to the one configured in the credentials file, if any.")
 	flag.BoolVar(&o.SkipCheck, "skip-check", o.SkipCheck, "Skip checking the credentials against the registry.")
 	flag.BoolVar(&o.Insecure, "insecure", o.Insecure, "Bypass HTTPS certificate verification when checking the registry login.")
 
@@ -210,7 +222,7 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, args []string) error {
 		o.Credentials = newCredentials("user", cfg.BearerToken)
 	}
 
-	if len(o.HostPort) == 0 {
+	if len(o.HostPorts) == 0 {
 		client, err := imageclient.NewForConfig(cfg)
 		if err != nil {
 			return err
@@ -226,11 +238,11 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, args []string) error {
 		}
 		if len(registry) > 0 {
 			if ref, err := reference.Parse(registry); err == nil {
-				o.HostPort = ref.Registry
+				o.HostPorts = []string{ref.Registry}
 				if internal {
-					fmt.Fprintf(o.ErrOut, "info: Using internal registry hostname %s\n", o.HostPort)
+					fmt.Fprintf(o.ErrOut, "info: Using internal registry hostname %s\n", ref.Registry)
 				} else {
-					fmt.Fprintf(o.ErrOut, "info: Using registry public hostname %s\n", o.HostPort)
+					fmt.Fprintf(o.ErrOut, "info: Using registry public hostname %s\n", ref.Registry)
 				}
 			}
 		}
@@ -270,31 +282,24 @@ func findPublicHostname(client *imageclient.Clientset, namespaces ...string) (na
 }
 
 func (o *LoginOptions) Validate() error {
-	if len(o.HostPort) == 0 {
+	if len(o.HostPorts) == 0 {
 		return fmt.Errorf("The public hostname of the integrated registry could not be determined. Please specify one with --registry.")
 	}
 	if o.Credentials.Empty() {
 		return fmt.Errorf("Unable to determine registry credentials, please log into the cluster.")
 	}
-	return nil
-}
-
-func (o *LoginOptions) Run() error {
-	if !o.SkipCheck {
-		ctx := apirequest.NewContext()
-		creds := registryclient.NewBasicCredentials()
-		hostPortURL := &url.URL{Host: o.HostPort}
-		creds.Add(hostPortURL, o.Credentials.Username, o.Credentials.Password)
-		insecureRT, err := rest.TransportFor(&rest.Config{TLSClientConfig: rest.TLSClientConfig{Insecure: true}, UserAgent: rest.DefaultKubernetesUserAgent()})
-		if err != nil {
-			return err
+	if len(o.CredentialHelper) > 0 {
+		if o.ConfigFile == "-" {
+			return fmt.Errorf("--credential-helper may not be used when writing the credentials to standard output")
 		}
-		c := registryclient.NewContext(http.DefaultTransport, insecureRT).WithCredentials(creds)
-		if _, err := c.Repository(ctx, hostPortURL, "does_not_exist", o.Insecure); err != nil {
-			return fmt.Errorf("unable to check your credentials - pass --skip-check to bypass this error: %v", err)
+		if len(o.ConfigFile) == 0 {
+			return fmt.Errorf("--credential-helper requires a Docker credentials file to record the helper in, please specify one with --registry-config")
 		}
 	}
+	return nil
+}
 
+func (o *LoginOptions) Run() error {
 	var err error
 	authFilePath := o.ConfigFile
 
@@ -317,9 +322,26 @@ func (o *LoginOptions) Run() error {
 		}
 	}
 
-	ctx := &containertypes.SystemContext{AuthFilePath: authFilePath}
-	if err := dockerconfig.SetAuthentication(ctx, o.HostPort, o.Credentials.Username, o.Credentials.Password); err != nil {
-		return err
+	failed := []string{}
+	for _, hostPort := range o.HostPorts {
+		helper, err := o.login(hostPort, authFilePath)
+		if err != nil {
+			// a single registry keeps failing with its own error
+			if len(o.HostPorts) == 1 {
+				return err
+			}
+			fmt.Fprintf(o.ErrOut, "error: %s: %v\n", hostPort, err)
+			failed = append(failed, hostPort)
+			continue
+		}
+		if o.ConfigFile == "-" {
+			continue
+		}
+		if len(helper) > 0 {
+			fmt.Fprintf(o.Out, "Saved credentials for %s with credential helper %s\n", hostPort, helper)
+		} else {
+			fmt.Fprintf(o.Out, "Saved credentials for %s\n", hostPort)
+		}
 	}
 
 	if o.ConfigFile == "-" {
@@ -328,12 +350,52 @@ func (o *LoginOptions) Run() error {
 			return err
 		}
 		fmt.Fprintln(o.Out, string(bytes))
-	} else {
-		fmt.Fprintf(o.Out, "Saved credentials for %s\n", o.HostPort)
+	}
+
+	if len(o.HostPorts) > 1 {
+		fmt.Fprintf(o.Out, "Logged in to %d of %d registries\n", len(o.HostPorts)-len(failed), len(o.HostPorts))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to log in to %s", strings.Join(failed, ", "))
 	}
 	return nil
 }
 
+// login checks the credentials against the registry and saves them, returning the credential helper
+// they were stored with, if any.
+func (o *LoginOptions) login(hostPort, authFilePath string) (string, error) {
+	if !o.SkipCheck {
+		ctx := apirequest.NewContext()
+		creds := registryclient.NewBasicCredentials()
+		hostPortURL := &url.URL{Host: hostPort}
+		creds.Add(hostPortURL, o.Credentials.Username, o.Credentials.Password)
+		insecureRT, err := rest.TransportFor(&rest.Config{TLSClientConfig: rest.TLSClientConfig{Insecure: true}, UserAgent: rest.DefaultKubernetesUserAgent()})
+		if err != nil {
+			return "", err
+		}
+		c := registryclient.NewContext(http.DefaultTransport, insecureRT).WithCredentials(creds)
+		if _, err := c.Repository(ctx, hostPortURL, "does_not_exist", o.Insecure); err != nil {
+			return "", fmt.Errorf("unable to check your credentials - pass --skip-check to bypass this error: %v", err)
+		}
+	}
+
+	if o.ConfigFile != "-" && len(o.ConfigFile) > 0 {
+		helper, err := o.credentialHelper(hostPort)
+		if err != nil {
+			return "", err
+		}
+		if len(helper) > 0 {
+			if err := storeInCredentialHelper(helper, hostPort, o.Credentials.Username, o.Credentials.Password); err != nil {
+				return "", err
+			}
+			return helper, recordCredentialHelper(o.ConfigFile, hostPort, helper)
+		}
+	}
+
+	ctx := &containertypes.SystemContext{AuthFilePath: authFilePath}
+	return "", dockerconfig.SetAuthentication(ctx, hostPort, o.Credentials.Username, o.Credentials.Password)
+}
+
 func (o *LoginOptions) ensureEmptyAuthFileInitialized(configFile string) error {
 	fileInfo, err := os.Stat(configFile)
 	if err != nil && !os.IsNotExist(err) {
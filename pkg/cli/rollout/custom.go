@@ -0,0 +1,177 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/openshift/oc/pkg/helpers/originpolymorphichelpers/customrollouts"
+)
+
+// customRollout is a custom resource whose rollouts are viewed by a registered viewer.
+type customRollout struct {
+	viewer     customrollouts.Viewer
+	namespace  string
+	name       string
+	client     dynamic.NamespaceableResourceInterface
+	kubeClient kubernetes.Interface
+
+	genericclioptions.IOStreams
+}
+
+// withCustomRollouts runs the kubectl rollout command with run instead when its argument is a custom resource
+// with a registered rollout viewer, since kubectl can't decode custom resources.
+func withCustomRollouts(f kcmdutil.Factory, streams genericclioptions.IOStreams, cmd *cobra.Command, run func(*cobra.Command, *customRollout) error) *cobra.Command {
+	delegate := cmd.Run
+	cmd.Run = func(c *cobra.Command, args []string) {
+		rollout, err := resolveCustomRollout(f, args)
+		kcmdutil.CheckErr(err)
+		if rollout == nil {
+			delegate(c, args)
+			return
+		}
+		rollout.IOStreams = streams
+		kcmdutil.CheckErr(run(c, rollout))
+	}
+	return cmd
+}
+
+// resolveCustomRollout returns the custom resource of the arguments, TYPE/NAME or TYPE NAME, if its kind has a
+// registered rollout viewer, or nil otherwise.
+func resolveCustomRollout(f kcmdutil.Factory, args []string) (*customRollout, error) {
+	var resourceType, name string
+	switch {
+	case len(args) == 1 && strings.Contains(args[0], "/"):
+		parts := strings.SplitN(args[0], "/", 2)
+		resourceType, name = parts[0], parts[1]
+	case len(args) == 2 && !strings.Contains(args[0], "/"):
+		resourceType, name = args[0], args[1]
+	default:
+		return nil, nil
+	}
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	gvk, err := mapper.KindFor(schema.ParseGroupResource(resourceType).WithVersion(""))
+	if err != nil {
+		// kubectl reports the unknown resource types
+		return nil, nil
+	}
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	viewer, ok, err := customrollouts.Discover(dynamicClient, mapping)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &customRollout{
+		viewer:     viewer,
+		namespace:  namespace,
+		name:       name,
+		client:     dynamicClient.Resource(mapping.Resource),
+		kubeClient: kubeClient,
+	}, nil
+}
+
+// runCustomRestart restarts the rollout of the custom resource with a JSON merge patch.
+func runCustomRestart(cmd *cobra.Command, r *customRollout) error {
+	dryRun, err := kcmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	obj, err := r.client.Namespace(r.namespace).Get(context.TODO(), r.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	updated := obj.DeepCopy()
+	if err := r.viewer.Restart(updated); err != nil {
+		return fmt.Errorf("failed to restart %s: %v", r.name, err)
+	}
+	patch, err := customrollouts.MergePatch(obj, updated)
+	if err != nil {
+		return err
+	}
+	operation, options := "restarted", metav1.PatchOptions{}
+	switch dryRun {
+	case kcmdutil.DryRunClient:
+		return (&printers.NamePrinter{Operation: "restarted (dry run)"}).PrintObj(obj, r.Out)
+	case kcmdutil.DryRunServer:
+		operation, options.DryRun = "restarted (server dry run)", []string{metav1.DryRunAll}
+	}
+	if _, err := r.client.Namespace(r.namespace).Patch(context.TODO(), r.name, types.MergePatchType, patch, options); err != nil {
+		return fmt.Errorf("failed to patch: %v", err)
+	}
+	return (&printers.NamePrinter{Operation: operation}).PrintObj(obj, r.Out)
+}
+
+// runCustomHistory prints the revisions of the custom resource, or the pod template of the --revision.
+func runCustomHistory(cmd *cobra.Command, r *customRollout) error {
+	revision, err := cmd.Flags().GetInt64("revision")
+	if err != nil {
+		return err
+	}
+	obj, err := r.client.Namespace(r.namespace).Get(context.TODO(), r.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	history, err := customrollouts.NewHistoryViewer(r.viewer, r.client, r.kubeClient).ViewHistory(r.namespace, r.name, revision)
+	if err != nil {
+		return err
+	}
+	withRevision := ""
+	if revision > 0 {
+		withRevision = fmt.Sprintf("with revision #%d", revision)
+	}
+	return (&printers.NamePrinter{Operation: fmt.Sprintf("%s\n%s", withRevision, history)}).PrintObj(obj, r.Out)
+}
+
+// runCustomUndo rolls the custom resource back to the --to-revision, or to its previous revision.
+func runCustomUndo(cmd *cobra.Command, r *customRollout) error {
+	toRevision, err := cmd.Flags().GetInt64("to-revision")
+	if err != nil {
+		return err
+	}
+	dryRun, err := kcmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	obj, err := r.client.Namespace(r.namespace).Get(context.TODO(), r.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	result, err := customrollouts.NewRollbacker(r.viewer, r.client, r.kubeClient).Rollback(obj, nil, toRevision, dryRun)
+	if err != nil {
+		return err
+	}
+	return (&printers.NamePrinter{Operation: result}).PrintObj(obj, r.Out)
+}
@@ -0,0 +1,58 @@
+package rollout
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/openshift/oc/pkg/helpers/originpolymorphichelpers/customrollouts"
+)
+
+func TestRunCustomRestartDryRun(t *testing.T) {
+	resource := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+	for _, dryRun := range []string{"client", "server"} {
+		t.Run(dryRun, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "argoproj.io/v1alpha1",
+				"kind":       "Rollout",
+				"metadata":   map[string]interface{}{"name": "nginx", "namespace": "test"},
+			}}
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), obj)
+			viewer, _ := customrollouts.ViewerFor(customrollouts.ArgoRolloutGroupKind)
+
+			cmd := &cobra.Command{}
+			kcmdutil.AddDryRunFlag(cmd)
+			if err := cmd.Flags().Set("dry-run", dryRun); err != nil {
+				t.Fatal(err)
+			}
+			out := &bytes.Buffer{}
+			err := runCustomRestart(cmd, &customRollout{
+				viewer:    viewer,
+				namespace: "test",
+				name:      "nginx",
+				client:    client.Resource(resource),
+				IOStreams: genericclioptions.IOStreams{Out: out},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(out.String(), "dry run") {
+				t.Errorf("expected a dry run to be reported, got %q", out.String())
+			}
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "patch" && dryRun == "client" {
+					t.Errorf("expected no patch on a client dry run")
+				}
+			}
+		})
+	}
+}
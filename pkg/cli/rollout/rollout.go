@@ -29,7 +29,14 @@ var (
 		* Recreate - scales the old replication controller down to zero, then scales the new replication
 			controller up to full. Use when your application cannot tolerate two versions of code running
 			at the same time
-		* Custom - run your own deployment process inside a container using your own scripts.`)
+		* Custom - run your own deployment process inside a container using your own scripts.
+
+		The status, restart, history and undo commands also support the custom resources whose rollouts
+		follow the conventions of deployments, like the rollouts of Argo Rollouts. Other custom resources
+		are supported when their custom resource definition has the rollout.openshift.io/generic=true
+		annotation, and optionally the rollout.openshift.io/revision-annotation,
+		rollout.openshift.io/pod-template-hash-label and rollout.openshift.io/restart-field annotations
+		when they differ from those of deployments.`)
 )
 
 // NewCmdRollout facilitates kubectl rollout subcommands
@@ -50,7 +57,7 @@ func NewCmdRollout(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cob
 	cmd.AddCommand(NewCmdRolloutStatus(f, streams))
 	cmd.AddCommand(NewCmdRolloutCancel(f, streams))
 	cmd.AddCommand(NewCmdRolloutRetry(f, streams))
-	cmd.AddCommand(cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(rollout.NewCmdRolloutRestart(f, streams))))
+	cmd.AddCommand(NewCmdRolloutRestart(f, streams))
 
 	return cmd
 }
@@ -67,12 +74,15 @@ var (
 		oc rollout history dc/nginx
 
 	  # View the details of deployment revision 3
-		oc rollout history dc/nginx --revision=3`)
+		oc rollout history dc/nginx --revision=3
+
+		# View the rollout history of an Argo Rollouts rollout
+		oc rollout history rollouts.argoproj.io/nginx`)
 )
 
 // NewCmdRolloutHistory is a wrapper for the Kubernetes cli rollout history command
 func NewCmdRolloutHistory(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
-	cmd := rollout.NewCmdRolloutHistory(f, streams)
+	cmd := withCustomRollouts(f, streams, rollout.NewCmdRolloutHistory(f, streams), runCustomHistory)
 	cmd.Long = rolloutHistoryLong
 	cmd.Example = rolloutHistoryExample
 	validArgs := []string{"deployment", "replicaset", "replicationcontroller", "statefulset", "deploymentconfig"}
@@ -152,12 +162,15 @@ var (
     oc rollout undo dc/nginx
 
     # Roll back to deployment revision 3. The replication controller for that version must exist
-    oc rollout undo dc/nginx --to-revision=3`)
+    oc rollout undo dc/nginx --to-revision=3
+
+    # Roll back an Argo Rollouts rollout to its previous revision
+    oc rollout undo rollouts.argoproj.io/nginx`)
 )
 
 // NewCmdRolloutUndo is a wrapper for the Kubernetes cli rollout undo command
 func NewCmdRolloutUndo(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
-	cmd := rollout.NewCmdRolloutUndo(f, streams)
+	cmd := withCustomRollouts(f, streams, rollout.NewCmdRolloutUndo(f, streams), runCustomUndo)
 	cmd.Long = rolloutUndoLong
 	cmd.Example = rolloutUndoExample
 	validArgs := []string{"deployment", "replicaset", "replicationcontroller", "statefulset", "deploymentconfig"}
@@ -167,11 +180,25 @@ func NewCmdRolloutUndo(f kcmdutil.Factory, streams genericclioptions.IOStreams)
 
 var (
 	rolloutStatusLong = templates.LongDesc(`
-		Watch the status of the latest rollout, until it's done.`)
+		Watch the status of the latest rollout, until it's done.
+
+		Pass --watch-all to watch the rollouts of several resources at once, given as arguments, with
+		--filename or with --selector. Their progress is printed as it changes, and the command exits
+		once all of them finished, with an error if any of them failed or did not finish before the
+		--timeout.`)
 
 	rolloutStatusExample = templates.Examples(`
 		# Watch the status of the latest rollout
-		oc rollout status dc/nginx`)
+		oc rollout status dc/nginx
+
+		# Watch the status of the latest rollout of an Argo Rollouts rollout
+		oc rollout status rollouts.argoproj.io/nginx
+
+		# Watch the rollouts of all the deployments with the label app=shop for at most 10 minutes
+		oc rollout status deployments -l app=shop --watch-all --timeout=10m
+
+		# Watch the rollouts of several resources at once
+		oc rollout status deployment/frontend deployment/backend dc/database --watch-all`)
 )
 
 // NewCmdRolloutStatus is a wrapper for the Kubernetes cli rollout status command
@@ -181,5 +208,28 @@ func NewCmdRolloutStatus(f kcmdutil.Factory, streams genericclioptions.IOStreams
 	cmd.Example = rolloutStatusExample
 	validArgs := []string{"deployment", "replicaset", "replicationcontroller", "statefulset", "deploymentconfig"}
 	cmd.ValidArgsFunction = completion.SpecifiedResourceTypeAndNameCompletionFunc(f, validArgs)
+
+	o := &StatusAllOptions{IOStreams: streams}
+	cmd.Flags().BoolVar(&o.WatchAll, "watch-all", o.WatchAll, "Watch the rollouts of all the given resources and of the resources matching the selector concurrently, until they all finish")
+	delegate := cmd.Run
+	cmd.Run = func(c *cobra.Command, args []string) {
+		if !o.WatchAll {
+			// kubectl can't decode the custom resources
+			custom, err := resolveCustomRollout(f, args)
+			kcmdutil.CheckErr(err)
+			if custom == nil {
+				delegate(c, args)
+				return
+			}
+		}
+		kcmdutil.CheckErr(o.Complete(f, c, args))
+		kcmdutil.CheckErr(o.Validate())
+		kcmdutil.CheckErr(o.Run())
+	}
 	return cmd
 }
+
+// NewCmdRolloutRestart is a wrapper for the Kubernetes cli rollout restart command
+func NewCmdRolloutRestart(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	return withCustomRollouts(f, streams, cmdutil.ReplaceCommandName("kubectl", "oc", templates.Normalize(rollout.NewCmdRolloutRestart(f, streams))), runCustomRestart)
+}
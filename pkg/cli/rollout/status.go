@@ -0,0 +1,256 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"k8s.io/kubectl/pkg/util/interrupt"
+
+	"github.com/openshift/oc/pkg/helpers/originpolymorphichelpers/customrollouts"
+)
+
+// StatusAllOptions watches the rollouts of several resources at once. The resources are read as unstructured
+// objects, which supports the custom resources with a rollout viewer.
+type StatusAllOptions struct {
+	WatchAll bool
+
+	Watch           bool
+	Revision        int64
+	Timeout         time.Duration
+	LabelSelector   string
+	FilenameOptions resource.FilenameOptions
+
+	Namespace        string
+	EnforceNamespace bool
+	Args             []string
+	Builder          func() *resource.Builder
+	StatusViewerFn   func(*meta.RESTMapping) (polymorphichelpers.StatusViewer, error)
+	DynamicClient    dynamic.Interface
+
+	genericclioptions.IOStreams
+}
+
+// rolloutResult is the end of the watch of a rollout.
+type rolloutResult struct {
+	name string
+	done bool
+	err  error
+}
+
+// Complete reads the flags of the kubectl rollout status command.
+func (o *StatusAllOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	if o.Watch, err = cmd.Flags().GetBool("watch"); err != nil {
+		return err
+	}
+	if o.Revision, err = cmd.Flags().GetInt64("revision"); err != nil {
+		return err
+	}
+	if o.Timeout, err = cmd.Flags().GetDuration("timeout"); err != nil {
+		return err
+	}
+	if o.LabelSelector, err = cmd.Flags().GetString("selector"); err != nil {
+		return err
+	}
+	if o.FilenameOptions.Filenames, err = cmd.Flags().GetStringSlice("filename"); err != nil {
+		return err
+	}
+	if o.FilenameOptions.Kustomize, err = cmd.Flags().GetString("kustomize"); err != nil {
+		return err
+	}
+	if o.FilenameOptions.Recursive, err = cmd.Flags().GetBool("recursive"); err != nil {
+		return err
+	}
+
+	o.Args = args
+	o.Builder = f.NewBuilder
+	o.StatusViewerFn = polymorphichelpers.StatusViewerFn
+	o.Namespace, o.EnforceNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = dynamic.NewForConfig(config)
+	return err
+}
+
+// Validate validates the options.
+func (o *StatusAllOptions) Validate() error {
+	if len(o.Args) == 0 && len(o.LabelSelector) == 0 && kcmdutil.IsFilenameSliceEmpty(o.FilenameOptions.Filenames, o.FilenameOptions.Kustomize) {
+		return fmt.Errorf("required resource not specified")
+	}
+	if o.Revision < 0 {
+		return fmt.Errorf("revision must be a positive integer: %v", o.Revision)
+	}
+	return nil
+}
+
+// Run watches the rollouts of all the resources concurrently, and fails if any of them fails or doesn't complete
+// before the timeout.
+func (o *StatusAllOptions) Run() error {
+	r := o.Builder().
+		Unstructured().
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		LabelSelectorParam(o.LabelSelector).
+		FilenameParam(o.EnforceNamespace, &o.FilenameOptions).
+		ResourceTypeOrNameArgs(true, o.Args...).
+		ContinueOnError().
+		Latest().
+		Flatten().
+		Do()
+	infos, err := r.Infos()
+	if err != nil {
+		return err
+	}
+	if len(infos) == 0 {
+		return fmt.Errorf("no resources found")
+	}
+
+	ctx, cancel := watchtools.ContextWithOptionalTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	var results []rolloutResult
+	err = interrupt.New(nil, cancel).Run(func() error {
+		results = o.watchAll(ctx, infos)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	failed, finished := []string{}, 0
+	for _, result := range results {
+		if result.done {
+			finished++
+		}
+		if result.err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", result.name, result.err))
+		}
+	}
+	if len(infos) > 1 {
+		fmt.Fprintf(o.Out, "%d of %d rollouts finished\n", finished, len(results))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d rollouts did not finish:\n  %s", len(failed), len(results), strings.Join(failed, "\n  "))
+	}
+	return nil
+}
+
+// watchAll watches the rollouts of the resources concurrently, printing the changes of their status prefixed with
+// the count of finished rollouts.
+func (o *StatusAllOptions) watchAll(ctx context.Context, infos []*resource.Info) []rolloutResult {
+	var (
+		lock     sync.Mutex
+		finished int
+		wg       sync.WaitGroup
+	)
+	results := make([]rolloutResult, len(infos))
+	report := func(name, status string, done bool) {
+		lock.Lock()
+		defer lock.Unlock()
+		if done {
+			finished++
+		}
+		if len(infos) == 1 {
+			fmt.Fprintf(o.Out, "%s\n", status)
+			return
+		}
+		fmt.Fprintf(o.Out, "[%d/%d] %s: %s\n", finished, len(infos), name, status)
+	}
+
+	for i, info := range infos {
+		wg.Add(1)
+		go func(i int, info *resource.Info) {
+			defer wg.Done()
+			name := resourceName(info)
+			results[i] = rolloutResult{name: name}
+			done, err := o.watchRollout(ctx, info, func(status string, done bool) {
+				report(name, status, done)
+			})
+			if err == nil && !done && o.Watch {
+				err = fmt.Errorf("the rollout did not finish")
+			}
+			results[i].done, results[i].err = done, err
+		}(i, info)
+	}
+	wg.Wait()
+	return results
+}
+
+// watchRollout watches the rollout of the resource until it is done, and reports the changes of its status.
+func (o *StatusAllOptions) watchRollout(ctx context.Context, info *resource.Info, report func(string, bool)) (bool, error) {
+	if _, _, err := customrollouts.Discover(o.DynamicClient, info.Mapping); err != nil {
+		return false, err
+	}
+	statusViewer, err := o.StatusViewerFn(info.Mapping)
+	if err != nil {
+		return false, err
+	}
+	client := o.DynamicClient.Resource(info.Mapping.Resource).Namespace(info.Namespace)
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", info.Name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return client.List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return client.Watch(ctx, options)
+		},
+	}
+
+	last, done := "", false
+	_, err = watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, nil, func(e watch.Event) (bool, error) {
+		if obj, ok := e.Object.(metav1.Object); ok && obj.GetName() != info.Name {
+			return false, nil
+		}
+		switch e.Type {
+		case watch.Added, watch.Modified:
+			status, rolledOut, err := statusViewer.Status(e.Object.(runtime.Unstructured), o.Revision)
+			if err != nil {
+				return false, err
+			}
+			done = rolledOut
+			if status = strings.TrimSpace(status); status != last || done {
+				last = status
+				report(status, done)
+			}
+			return done || !o.Watch, nil
+		case watch.Deleted:
+			return true, fmt.Errorf("object has been deleted")
+		default:
+			return true, fmt.Errorf("internal error: unexpected event %#v", e)
+		}
+	})
+	return done, err
+}
+
+// resourceName returns the name of the resource as printed by the name printer, like deployment.apps/nginx.
+func resourceName(info *resource.Info) string {
+	gvk := info.Mapping.GroupVersionKind
+	kind := strings.ToLower(gvk.Kind)
+	if len(gvk.Group) > 0 {
+		kind += "." + gvk.Group
+	}
+	return kind + "/" + info.Name
+}
@@ -0,0 +1,70 @@
+package rollout
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+func testDeployment(name string, updated int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "test", "generation": int64(1)},
+		"spec":       map[string]interface{}{"replicas": int64(2)},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(1),
+			"replicas":           updated,
+			"updatedReplicas":    updated,
+			"availableReplicas":  updated,
+		},
+	}}
+}
+
+func TestStatusAllWatchAll(t *testing.T) {
+	mapping := &meta.RESTMapping{
+		Resource:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), testDeployment("frontend", 2), testDeployment("backend", 1))
+	infos := []*resource.Info{
+		{Name: "frontend", Namespace: "test", Mapping: mapping},
+		{Name: "backend", Namespace: "test", Mapping: mapping},
+	}
+
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	o := &StatusAllOptions{
+		Watch:          true,
+		StatusViewerFn: polymorphichelpers.StatusViewerFn,
+		DynamicClient:  client,
+		IOStreams:      streams,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	results := o.watchAll(ctx, infos)
+
+	if !results[0].done || results[0].err != nil {
+		t.Errorf("expected the frontend rollout to finish, got %#v", results[0])
+	}
+	if results[1].done || results[1].err == nil {
+		t.Errorf("expected the backend rollout to time out, got %#v", results[1])
+	}
+	for _, expected := range []string{
+		`[1/2] deployment.apps/frontend: deployment "frontend" successfully rolled out`,
+		`deployment.apps/backend: Waiting for deployment "backend" rollout to finish: 1 out of 2 new replicas have been updated...`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected the output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
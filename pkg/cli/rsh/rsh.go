@@ -13,6 +13,8 @@ import (
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/templates"
 	"k8s.io/kubectl/pkg/util/term"
+
+	octerm "github.com/openshift/oc/pkg/helpers/term"
 )
 
 const (
@@ -39,6 +41,10 @@ var (
 		the shell (or command) will be executed. By default its value is the same as the TERM
 		variable from the local environment; if not set, 'xterm' is used.
 
+		The session can be recorded with '--record FILE' in the asciicast format, to be played
+		back with 'asciinema play FILE'. A session without input or output for '--idle-timeout'
+		is ended, so that forgotten shells do not stay open.
+
 		Note, some containers may not include a shell - use 'oc exec' if you need to run commands
 		directly.`)
 
@@ -58,6 +64,9 @@ var (
 
 		# Open a shell session on the container named 'index' inside a pod of your job
 		oc rsh -c index job/sheduled
+
+		# Record a shell session in pod 'foo', and end it after 15 minutes without activity
+		oc rsh --record=session.cast --idle-timeout=15m foo
 	`)
 )
 
@@ -66,6 +75,7 @@ type RshOptions struct {
 	ForceTTY   bool
 	DisableTTY bool
 	Executable string
+	Session    octerm.SessionOptions
 	*exec.ExecOptions
 }
 
@@ -108,6 +118,7 @@ func NewCmdRsh(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.C
 	cmd.Flags().BoolVarP(&o.DisableTTY, "no-tty", "T", o.DisableTTY, "Disable pseudo-terminal allocation")
 	cmd.Flags().StringVar(&o.Executable, "shell", o.Executable, "Path to the shell command")
 	cmd.Flags().StringVarP(&o.ContainerName, "container", "c", o.ContainerName, "Container name; defaults to first container")
+	o.Session.AddFlags(cmd.Flags())
 	// For consistencty with rsh API (https://linux.die.net/man/1/rsh) we don't
 	// allow '--' and we need this flag enabled explicitly, otherwise two things
 	// will break:
@@ -169,11 +180,16 @@ func (o *RshOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []str
 		o.Command = []string{o.Executable}
 	}
 
+	o.Session.Title = "oc rsh " + o.ResourceName
+	o.Executor = &octerm.SessionExecutor{RemoteExecutor: o.Executor, Session: &o.Session}
 	return nil
 }
 
 // Validate ensures that RshOptions are valid
 func (o *RshOptions) Validate() error {
+	if err := o.Session.Validate(); err != nil {
+		return err
+	}
 	return o.ExecOptions.Validate()
 }
 
@@ -1,7 +1,9 @@
 package rsync
 
 import (
+	archivetar "archive/tar"
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -10,7 +12,9 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/docker/go-units"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/klog/v2"
 
@@ -23,14 +27,24 @@ import (
 // and then streaming them to/from the container to the destination to a tar
 // command waiting for STDIN input. If the --delete flag is specified, the
 // contents of the destination directory are first cleared before the copy.
-// The tar strategy requires that the remote container contain the tar command.
+// The tar strategy requires that the remote container contain the tar command,
+// and the gzip command to compress the transfer. The include and exclude patterns
+// are applied locally, with the semantics of rsync.
+// Local directories can be copied with concurrent tar streams, each one extracting
+// a batch of files in the container.
 type tarStrategy struct {
 	Quiet          bool
 	Delete         bool
+	Compress       bool
+	Progress       bool
+	Owner          bool
+	Parallel       int
+	Retries        int
 	Tar            tar.Tar
 	RemoteExecutor executor
 	Includes       []string
 	Excludes       []string
+	Filter         *pathFilter
 	IgnoredFlags   []string
 	Flags          []string
 }
@@ -48,8 +62,14 @@ func NewTarStrategy(o *RsyncOptions) CopyStrategy {
 	return &tarStrategy{
 		Quiet:          o.Quiet,
 		Delete:         o.Delete,
+		Compress:       o.Compress,
+		Progress:       o.RsyncProgress,
+		Owner:          o.RsyncOwner,
+		Parallel:       o.Parallel,
+		Retries:        o.Retries,
 		Includes:       o.RsyncInclude,
 		Excludes:       o.RsyncExclude,
+		Filter:         newPathFilter(o.RsyncInclude, o.RsyncExclude),
 		Tar:            tarHelper,
 		RemoteExecutor: remoteExec,
 		IgnoredFlags:   ignoredFlags,
@@ -121,6 +141,9 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 	if len(r.IgnoredFlags) > 0 {
 		fmt.Fprintf(errOut, "Ignoring the following flags because they only apply to rsync: %s\n", strings.Join(r.IgnoredFlags, ", "))
 	}
+	if r.Owner && destination.Local() {
+		fmt.Fprintf(errOut, "WARNING: the owner of the files is not preserved when copying to a local directory with tar\n")
+	}
 
 	if r.Delete {
 		// Implement the rsync --delete flag as a separate call to first delete directory contents
@@ -129,6 +152,38 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 			return fmt.Errorf("unable to delete files in destination: %v", err)
 		}
 	}
+
+	compress := r.Compress
+	if compress && checkGzip(r.RemoteExecutor) != nil {
+		if checkTar(r.RemoteExecutor) != nil {
+			return strategySetupError("tar not available in container")
+		}
+		fmt.Fprintf(errOut, "WARNING: gzip not available in container, copying without compression\n")
+		compress = false
+	}
+
+	if source.Local() && r.Parallel > 1 {
+		upload := &parallelTarUpload{
+			Streams:        r.Parallel,
+			Retries:        r.Retries,
+			RetryDelay:     time.Second,
+			Compress:       compress,
+			Progress:       r.Progress,
+			Quiet:          r.Quiet,
+			Flags:          r.Flags,
+			Filter:         r.Filter,
+			RemoteExecutor: r.RemoteExecutor,
+		}
+		if err := upload.Upload(source, destination, out, errOut); err != nil {
+			if checkTar(r.RemoteExecutor) != nil {
+				return strategySetupError("tar not available in container")
+			}
+			return err
+		}
+		return nil
+	}
+
+	start := time.Now()
 	tmp, err := ioutil.TempFile("", "rsync")
 	if err != nil {
 		return fmt.Errorf("cannot create local temporary file for tar: %v", err)
@@ -139,14 +194,19 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 	// Create tar
 	if source.Local() {
 		klog.V(4).Infof("Creating local tar file %s from local path %s", tmp.Name(), source.Path)
-		err = tarLocal(r.Tar, source.Path, tmp)
+		err = tarLocal(source.Path, tmp, compress, r.Filter)
 		if err != nil {
 			return fmt.Errorf("error creating local tar of source directory: %v", err)
 		}
 	} else {
 		klog.V(4).Infof("Creating local tar file %s from remote path %s", tmp.Name(), source.Path)
 		errBuf := &bytes.Buffer{}
-		err = tarRemote(r.RemoteExecutor, source.Path, r.Includes, r.Excludes, tmp, errBuf)
+		// the excludes only spare transferring files that are filtered out locally, unless an include overrides them
+		var excludes []string
+		if len(r.Includes) == 0 {
+			excludes = r.Excludes
+		}
+		err = tarRemote(r.RemoteExecutor, source.Path, excludes, compress, tmp, errBuf)
 		if err != nil {
 			if checkTar(r.RemoteExecutor) != nil {
 				return strategySetupError("tar not available in container")
@@ -156,6 +216,10 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 		}
 	}
 
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("error reading position in a temporary tar file %s: %v", tmp.Name(), err)
+	}
 	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
 		return fmt.Errorf("error resetting position in a temporary tar file %s: %v", tmp.Name(), err)
 	}
@@ -163,11 +227,15 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 	// Extract tar
 	if destination.Local() {
 		klog.V(4).Infof("Untarring temp file %s to local directory %s", tmp.Name(), destination.Path)
-		err = untarLocal(r.Tar, destination.Path, tmp, r.Quiet, out)
+		err = untarLocal(r.Tar, destination.Path, tmp, compress, r.Filter, r.Quiet, out)
 	} else {
 		klog.V(4).Infof("Untarring temp file %s to remote directory %s", tmp.Name(), destination.Path)
+		flags := r.Flags
+		if compress {
+			flags = append([]string{"-z"}, flags...)
+		}
 		errBuf := &bytes.Buffer{}
-		err = untarRemote(r.RemoteExecutor, destination.Path, r.Flags, tmp, out, errBuf)
+		err = untarRemote(r.RemoteExecutor, destination.Path, flags, tmp, out, errBuf)
 		if err != nil {
 			if checkTar(r.RemoteExecutor) != nil {
 				return strategySetupError("tar not available in container")
@@ -178,11 +246,20 @@ func (r *tarStrategy) Copy(source, destination *PathSpec, out, errOut io.Writer)
 	if err != nil {
 		return fmt.Errorf("error extracting tar at destination directory: %v", err)
 	}
+	if r.Progress {
+		fmt.Fprintf(out, "Transferred %s in %s\n", units.HumanSize(float64(size)), time.Since(start).Round(time.Millisecond))
+	}
 	return nil
 }
 
 func (r *tarStrategy) Validate() error {
 	errs := []error{}
+	if r.Parallel < 1 {
+		errs = append(errs, errors.New("the number of parallel streams must be at least 1"))
+	}
+	if r.Retries < 0 {
+		errs = append(errs, errors.New("the number of retries must not be negative"))
+	}
 	if r.Tar == nil {
 		errs = append(errs, errors.New("tar helper must be provided"))
 	}
@@ -199,7 +276,7 @@ func (r *tarStrategy) String() string {
 	return "tar"
 }
 
-func tarRemote(exec executor, sourceDir string, includes, excludes []string, out, errOut io.Writer) error {
+func tarRemote(exec executor, sourceDir string, excludes []string, compress bool, out, errOut io.Writer) error {
 	klog.V(4).Infof("Tarring %s remotely", sourceDir)
 
 	exclude := []string{}
@@ -207,50 +284,52 @@ func tarRemote(exec executor, sourceDir string, includes, excludes []string, out
 		exclude = append(exclude, fmt.Sprintf("--exclude=%s", pattern))
 	}
 
+	create := "-c"
+	if compress {
+		create = "-cz"
+	}
+
 	var cmd []string
 	if strings.HasSuffix(sourceDir, "/") {
-		include := []string{"."}
-		include = append(include, includes...)
-
-		cmd = []string{"tar", "-C", sourceDir, "-c"}
-		cmd = append(cmd, append(include, exclude...)...)
+		cmd = []string{"tar", "-C", sourceDir, create, "."}
 	} else {
-		include := []string{}
-
-		for _, pattern := range includes {
-			include = append(include, path.Join(path.Base(sourceDir), pattern))
-		}
-
-		cmd = []string{"tar", "-C", path.Dir(sourceDir), "-c", path.Base(sourceDir)}
-		cmd = append(cmd, append(include, exclude...)...)
+		cmd = []string{"tar", "-C", path.Dir(sourceDir), create, path.Base(sourceDir)}
 	}
+	cmd = append(cmd, exclude...)
 	klog.V(4).Infof("Remote tar command: %s", strings.Join(cmd, " "))
 	return exec.Execute(cmd, nil, out, errOut)
 }
 
-func tarLocal(tar tar.Tar, sourceDir string, w io.Writer) error {
+func tarLocal(sourceDir string, w io.Writer, compress bool, filter *pathFilter) error {
 	klog.V(4).Infof("Tarring %s locally", sourceDir)
-	// includeParent mimics rsync's behavior. When the source path ends in a path
+	// collectUploadFiles mimics rsync's behavior. When the source path ends in a path
 	// separator, then only the contents of the directory are copied. Otherwise,
 	// the directory itself is copied.
-	includeParent := true
-	if strings.HasSuffix(sourceDir, string(filepath.Separator)) {
-		includeParent = false
-		sourceDir = sourceDir[:len(sourceDir)-1]
+	files, err := collectUploadFiles(sourceDir, filter, true)
+	if err != nil {
+		return err
 	}
-	return tar.CreateTarStream(sourceDir, includeParent, w)
+	return writeUploadTar(w, files, compress)
 }
 
-func untarLocal(tar tar.Tar, destinationDir string, r io.Reader, quiet bool, logger io.Writer) error {
+func untarLocal(tar tar.Tar, destinationDir string, r io.Reader, compressed bool, filter *pathFilter, quiet bool, logger io.Writer) error {
 	klog.V(4).Infof("Extracting tar locally to %s", destinationDir)
+	if compressed {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
 	if quiet {
-		return tar.ExtractTarStream(destinationDir, r)
+		logger = nil
 	}
-	return tar.ExtractTarStreamWithLogging(destinationDir, r, logger)
+	return tar.ExtractTarStreamFromTarReader(destinationDir, &filteredTarReader{Reader: archivetar.NewReader(r), filter: filter}, logger)
 }
 
 func untarRemote(exec executor, destinationDir string, flags []string, in io.Reader, out, errOut io.Writer) error {
-	cmd := []string{"tar", "-C", destinationDir, "-ox"}
+	cmd := []string{"tar", "-C", destinationDir, "-x"}
 	cmd = append(cmd, flags...)
 	klog.V(4).Infof("Extracting tar remotely with command: %s", strings.Join(cmd, " "))
 	return exec.Execute(cmd, in, out, errOut)
@@ -0,0 +1,303 @@
+package rsync
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/go-units"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// uploadBatchFiles and uploadBatchBytes bound the number of files and the size of the files of a batch
+	uploadBatchFiles = 64
+	uploadBatchBytes = 8 * 1024 * 1024
+)
+
+// parallelTarUpload copies a local directory to a container with concurrent tar streams, each one extracting a
+// batch of files in the container. When a batch fails, its files are retried one by one.
+type parallelTarUpload struct {
+	Streams        int
+	Retries        int
+	RetryDelay     time.Duration
+	Compress       bool
+	Progress       bool
+	Quiet          bool
+	Flags          []string
+	Filter         *pathFilter
+	RemoteExecutor executor
+
+	// lock guards the outputs and the counters below
+	lock        sync.Mutex
+	out, errOut io.Writer
+	total       int
+	totalBytes  int64
+	copied      int
+	copiedBytes int64
+	failed      []string
+}
+
+// uploadFile is a local file to copy, with the name it has in the destination directory.
+type uploadFile struct {
+	path     string
+	name     string
+	size     int64
+	failures int
+}
+
+// Upload copies the files of source to destination.
+func (u *parallelTarUpload) Upload(source, destination *PathSpec, out, errOut io.Writer) error {
+	// directories are only created with the files they contain, unless they are empty, since a batch could not
+	// extract its files in a read-only directory created by another batch
+	files, err := collectUploadFiles(source.Path, u.Filter, false)
+	if err != nil {
+		return fmt.Errorf("error reading the source directory: %v", err)
+	}
+	u.out, u.errOut = out, errOut
+	u.total = len(files)
+	for _, f := range files {
+		u.totalBytes += f.size
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	klog.V(3).Infof("Copying %d files with %d tar streams", len(files), u.Streams)
+
+	// each file is in at most one queued batch at a time
+	batches := make(chan []*uploadFile, len(files))
+	pending := &sync.WaitGroup{}
+	for _, batch := range batchUploadFiles(files) {
+		pending.Add(1)
+		batches <- batch
+	}
+
+	start := time.Now()
+	workers := &sync.WaitGroup{}
+	for i := 0; i < u.Streams; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for batch := range batches {
+				u.copyBatch(batch, destination, batches, pending)
+				pending.Done()
+			}
+		}()
+	}
+	pending.Wait()
+	close(batches)
+	workers.Wait()
+
+	if u.Progress {
+		fmt.Fprintf(out, "Copied %d files (%s) in %s with %d streams\n", u.copied, units.HumanSize(float64(u.copiedBytes)), time.Since(start).Round(time.Millisecond), u.Streams)
+	}
+	if len(u.failed) > 0 {
+		return fmt.Errorf("unable to copy %d files: %s", len(u.failed), strings.Join(u.failed, ", "))
+	}
+	return nil
+}
+
+// copyBatch extracts batch in the container, and queues its files again one by one when it fails.
+func (u *parallelTarUpload) copyBatch(batch []*uploadFile, destination *PathSpec, batches chan<- []*uploadFile, pending *sync.WaitGroup) {
+	outBuf, errBuf := &bytes.Buffer{}, &bytes.Buffer{}
+	err := u.extract(batch, destination, outBuf, errBuf)
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if err == nil {
+		io.Copy(u.out, outBuf)
+		u.copied += len(batch)
+		for _, f := range batch {
+			u.copiedBytes += f.size
+		}
+		if u.Progress {
+			fmt.Fprintf(u.out, "Copied %d/%d files (%s/%s)\n", u.copied, u.total, units.HumanSize(float64(u.copiedBytes)), units.HumanSize(float64(u.totalBytes)))
+		}
+		return
+	}
+
+	klog.V(4).Infof("Error copying a batch of %d files: %v\n%s", len(batch), err, errBuf.String())
+	failed := false
+	for _, f := range batch {
+		f.failures++
+		if f.failures > u.Retries {
+			u.failed = append(u.failed, f.name)
+			fmt.Fprintf(u.errOut, "error: unable to copy %s: %v\n", f.name, err)
+			failed = true
+			continue
+		}
+		if !u.Quiet && len(batch) == 1 {
+			fmt.Fprintf(u.errOut, "Retrying %s after error: %v\n", f.name, err)
+		}
+		pending.Add(1)
+		go func(f *uploadFile) {
+			time.Sleep(time.Duration(f.failures) * u.RetryDelay)
+			batches <- []*uploadFile{f}
+		}(f)
+	}
+	if failed {
+		io.Copy(u.errOut, errBuf)
+	}
+}
+
+// extract streams the tar of batch to a tar command extracting it in the destination directory.
+func (u *parallelTarUpload) extract(batch []*uploadFile, destination *PathSpec, out, errOut io.Writer) error {
+	r, w := io.Pipe()
+	written := make(chan error, 1)
+	go func() {
+		err := writeUploadTar(w, batch, u.Compress)
+		w.CloseWithError(err)
+		written <- err
+	}()
+
+	flags := u.Flags
+	if u.Compress {
+		flags = append([]string{"-z"}, flags...)
+	}
+	err := untarRemote(u.RemoteExecutor, destination.Path, flags, r, out, errOut)
+	// unblock the writer if the command exited before reading the whole tar
+	r.CloseWithError(io.ErrClosedPipe)
+	if writeErr := <-written; err == nil && writeErr != nil && writeErr != io.ErrClosedPipe {
+		err = writeErr
+	}
+	return err
+}
+
+// writeUploadTar writes the tar of the files to w, compressed with gzip if compress is true.
+func writeUploadTar(w io.Writer, files []*uploadFile, compress bool) error {
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+	for _, f := range files {
+		if err := writeUploadFile(tw, f); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if gz != nil {
+		return gz.Close()
+	}
+	return nil
+}
+
+func writeUploadFile(tw *tar.Writer, f *uploadFile) error {
+	info, err := os.Lstat(f.path)
+	if err != nil {
+		return err
+	}
+	link := ""
+	if info.Mode()&os.ModeSymlink != 0 {
+		if link, err = os.Readlink(f.path); err != nil {
+			return err
+		}
+	}
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = f.name
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.CopyN(tw, file, header.Size)
+	return err
+}
+
+// collectUploadFiles returns the regular files, symbolic links and directories of sourceDir, or only its empty
+// directories if allDirs is false, that filter does not exclude. Like rsync, the names of the files are relative to
+// sourceDir when it ends with a path separator, and to its parent otherwise.
+func collectUploadFiles(sourceDir string, filter *pathFilter, allDirs bool) ([]*uploadFile, error) {
+	prefix := ""
+	if strings.HasSuffix(sourceDir, string(filepath.Separator)) {
+		sourceDir = sourceDir[:len(sourceDir)-1]
+	} else {
+		prefix = filepath.Base(sourceDir)
+	}
+
+	var files []*uploadFile
+	err := filepath.Walk(sourceDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(sourceDir, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(prefix, filepath.ToSlash(rel))
+		if len(name) == 0 || name == "." {
+			return nil
+		}
+		if filter.Excluded(name, info.IsDir()) {
+			klog.V(5).Infof("Excluding %s", name)
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case info.IsDir() && !allDirs:
+			entries, err := os.ReadDir(p)
+			if err != nil {
+				return err
+			}
+			if len(entries) > 0 {
+				return nil
+			}
+		case info.IsDir():
+		case info.Mode().IsRegular(), info.Mode()&os.ModeSymlink != 0:
+		default:
+			klog.V(4).Infof("Skipping %s, it is not a regular file", p)
+			return nil
+		}
+		file := &uploadFile{path: p, name: name}
+		if info.Mode().IsRegular() {
+			file.size = info.Size()
+		}
+		files = append(files, file)
+		return nil
+	})
+	return files, err
+}
+
+// batchUploadFiles groups files in batches of at most uploadBatchFiles files and about uploadBatchBytes bytes.
+func batchUploadFiles(files []*uploadFile) [][]*uploadFile {
+	var batches [][]*uploadFile
+	var batch []*uploadFile
+	var size int64
+	for _, f := range files {
+		if len(batch) > 0 && (len(batch) == uploadBatchFiles || size+f.size > uploadBatchBytes) {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, f)
+		size += f.size
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
@@ -0,0 +1,207 @@
+package rsync
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeTarExecutor extracts the tar streams of tar -x commands in memory, failing the streams that contain a file
+// as long as its failures are positive.
+type fakeTarExecutor struct {
+	lock     sync.Mutex
+	files    map[string]string
+	failures map[string]int
+	commands int
+}
+
+func (e *fakeTarExecutor) Execute(command []string, in io.Reader, out, errOut io.Writer) error {
+	e.lock.Lock()
+	e.commands++
+	e.lock.Unlock()
+
+	if in == nil {
+		return nil
+	}
+	if command[0] != "tar" || command[3] != "-x" {
+		return fmt.Errorf("unexpected command %v", command)
+	}
+	for _, flag := range command[4:] {
+		if flag == "-z" {
+			gz, err := gzip.NewReader(in)
+			if err != nil {
+				return err
+			}
+			in = gz
+		}
+	}
+
+	files := map[string]string{}
+	tr := tar.NewReader(in)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		files[command[2]+"/"+header.Name] = string(data)
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for name := range files {
+		if e.failures[name] > 0 {
+			e.failures[name]--
+			fmt.Fprintf(errOut, "tar: %s: Cannot open: Permission denied\n", name)
+			return errors.New("command terminated with exit code 2")
+		}
+	}
+	for name, data := range files {
+		e.files[name] = data
+	}
+	return nil
+}
+
+func writeTestTree(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		p := filepath.Join(dir, "src", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "src", "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "src")
+}
+
+func TestCollectUploadFiles(t *testing.T) {
+	source := writeTestTree(t, map[string]string{"a.txt": "a", "dir/b.txt": "bb"})
+
+	for _, tt := range []struct {
+		source   string
+		filter   *pathFilter
+		allDirs  bool
+		expected []string
+	}{
+		{source: source + string(filepath.Separator), expected: []string{"a.txt", "dir/b.txt", "empty"}},
+		{source: source, expected: []string{"src/a.txt", "src/dir/b.txt", "src/empty"}},
+		{source: source, allDirs: true, expected: []string{"src", "src/a.txt", "src/dir", "src/dir/b.txt", "src/empty"}},
+		{source: source, filter: newPathFilter(nil, []string{"dir/"}), expected: []string{"src/a.txt", "src/empty"}},
+		{source: source, filter: newPathFilter([]string{"*/", "b.txt"}, []string{"*"}), allDirs: true, expected: []string{"src", "src/dir", "src/dir/b.txt", "src/empty"}},
+	} {
+		files, err := collectUploadFiles(tt.source, tt.filter, tt.allDirs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, f := range files {
+			names = append(names, f.name)
+		}
+		if !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.source, tt.expected, names)
+		}
+	}
+}
+
+func TestBatchUploadFiles(t *testing.T) {
+	var files []*uploadFile
+	for i := 0; i < uploadBatchFiles+1; i++ {
+		files = append(files, &uploadFile{name: fmt.Sprintf("small-%d", i), size: 1})
+	}
+	files = append(files, &uploadFile{name: "large-1", size: uploadBatchBytes}, &uploadFile{name: "large-2", size: uploadBatchBytes})
+
+	var sizes []int
+	for _, batch := range batchUploadFiles(files) {
+		sizes = append(sizes, len(batch))
+	}
+	if expected := []int{uploadBatchFiles, 1, 1, 1}; !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("expected batches of %v files, got %v", expected, sizes)
+	}
+}
+
+func TestParallelTarUpload(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i < 200; i++ {
+		files[fmt.Sprintf("dir-%d/file-%d.txt", i%7, i)] = strings.Repeat("x", i)
+	}
+	source := writeTestTree(t, files)
+
+	tests := []struct {
+		name          string
+		compress      bool
+		retries       int
+		failures      map[string]int
+		expectedError string
+	}{
+		{name: "copy"},
+		{name: "compressed copy", compress: true},
+		{name: "retried files", retries: 2, failures: map[string]int{"/dest/dir-1/file-8.txt": 2, "/dest/dir-3/file-10.txt": 1}},
+		{
+			name:          "failed file",
+			retries:       1,
+			failures:      map[string]int{"/dest/dir-1/file-8.txt": 3},
+			expectedError: "unable to copy 1 files: dir-1/file-8.txt",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := &fakeTarExecutor{files: map[string]string{}, failures: map[string]int{}}
+			for name, failures := range tt.failures {
+				executor.failures[name] = failures
+			}
+			upload := &parallelTarUpload{Streams: 4, Retries: tt.retries, Compress: tt.compress, Progress: true, RemoteExecutor: executor}
+			out, errOut := &strings.Builder{}, &strings.Builder{}
+			err := upload.Upload(&PathSpec{Path: source + string(filepath.Separator)}, &PathSpec{PodName: "pod", Path: "/dest"}, out, errOut)
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedError {
+				t.Fatalf("expected error %q, got %q", tt.expectedError, errStr)
+			}
+
+			expected := map[string]string{"/dest/empty/": ""}
+			for name, data := range files {
+				// the files that fail more than the retries are not copied, the other files of their batch are
+				if tt.failures["/dest/"+name] > tt.retries {
+					continue
+				}
+				expected["/dest/"+name] = data
+			}
+			var missing []string
+			for name, data := range expected {
+				if executor.files[name] != data {
+					missing = append(missing, name)
+				}
+			}
+			sort.Strings(missing)
+			if len(missing) > 0 {
+				t.Errorf("files not copied: %v", missing)
+			}
+			if !strings.Contains(out.String(), fmt.Sprintf("Copied %d files", len(expected))) {
+				t.Errorf("unexpected summary: %s", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package rsync
+
+import (
+	"archive/tar"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	s2itar "github.com/openshift/oc/pkg/helpers/source-to-image/tar"
+)
+
+// pathFilter selects the files to copy with the include and exclude patterns, like rsync: the includes are checked
+// before the excludes, the first matching pattern decides, and the files that match no pattern are copied. The
+// contents of an excluded directory are excluded.
+//
+// As with rsync, a pattern matches the name of the file unless it contains a slash, in which case it matches the end
+// of its path, or the whole path when the pattern starts with a slash. A pattern ending with a slash only matches
+// directories. '*' matches anything but a slash, '**' matches anything and a trailing '/***' matches a directory
+// and its contents.
+type pathFilter struct {
+	rules []filterRule
+}
+
+type filterRule struct {
+	include bool
+	dirOnly bool
+	pattern *regexp.Regexp
+}
+
+// newPathFilter returns the filter of the patterns, or nil if there are none.
+func newPathFilter(includes, excludes []string) *pathFilter {
+	if len(includes) == 0 && len(excludes) == 0 {
+		return nil
+	}
+	f := &pathFilter{}
+	for _, pattern := range includes {
+		f.rules = append(f.rules, newFilterRule(pattern, true))
+	}
+	for _, pattern := range excludes {
+		f.rules = append(f.rules, newFilterRule(pattern, false))
+	}
+	return f
+}
+
+func newFilterRule(pattern string, include bool) filterRule {
+	rule := filterRule{include: include}
+	contents := false
+	if strings.HasSuffix(pattern, "/***") {
+		pattern = strings.TrimSuffix(pattern, "/***")
+		contents = true
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern = strings.TrimSuffix(pattern, "/")
+		rule.dirOnly = true
+	}
+
+	expr := "(^|/)"
+	if strings.HasPrefix(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+		expr = "^"
+	}
+	expr += globToRegexp(pattern)
+	if contents {
+		expr += "(/.*)?"
+		rule.dirOnly = false
+	}
+	rule.pattern = regexp.MustCompile(expr + "$")
+	return rule
+}
+
+// globToRegexp converts the wildcards of an rsync pattern to a regular expression.
+func globToRegexp(pattern string) string {
+	expr := &strings.Builder{}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				expr.WriteString(".*")
+				i++
+				continue
+			}
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
+
+// Excluded returns true if the file of the path, relative to the root of the copy with slashes, is excluded.
+func (f *pathFilter) Excluded(name string, isDir bool) bool {
+	if f == nil {
+		return false
+	}
+	for _, rule := range f.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(name) {
+			return !rule.include
+		}
+	}
+	return false
+}
+
+// filteredTarReader skips the entries of a tar stream that are excluded by a filter, and the contents of the
+// excluded directories.
+type filteredTarReader struct {
+	*tar.Reader
+	filter       *pathFilter
+	excludedDirs []string
+}
+
+var _ s2itar.Reader = &filteredTarReader{}
+
+// Next returns the header of the next entry that is not excluded.
+func (r *filteredTarReader) Next() (*tar.Header, error) {
+	for {
+		header, err := r.Reader.Next()
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if len(name) == 0 {
+			return header, nil
+		}
+		if r.inExcludedDir(name) {
+			continue
+		}
+		isDir := header.Typeflag == tar.TypeDir
+		if !r.filter.Excluded(name, isDir) {
+			return header, nil
+		}
+		klog.V(5).Infof("Excluding %s", name)
+		if isDir {
+			r.excludedDirs = append(r.excludedDirs, name+"/")
+		}
+	}
+}
+
+func (r *filteredTarReader) inExcludedDir(name string) bool {
+	for _, dir := range r.excludedDirs {
+		if strings.HasPrefix(name, dir) {
+			return true
+		}
+	}
+	return false
+}
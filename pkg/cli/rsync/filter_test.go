@@ -0,0 +1,84 @@
+package rsync
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestPathFilter(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		includes []string
+		excludes []string
+		path     string
+		isDir    bool
+		excluded bool
+	}{
+		{name: "no patterns", path: "src/a.go"},
+		{name: "name at any depth", excludes: []string{"*.log"}, path: "src/logs/a.log", excluded: true},
+		{name: "name does not match a directory part", excludes: []string{"*.log"}, path: "src/a.log/b.txt"},
+		{name: "wildcard does not match slashes", excludes: []string{"src*"}, path: "src/a.go"},
+		{name: "path matches the end", excludes: []string{"logs/*.log"}, path: "src/logs/a.log", excluded: true},
+		{name: "path matches whole components", excludes: []string{"logs/*.log"}, path: "src/oldlogs/a.log"},
+		{name: "anchored path", excludes: []string{"/src/logs"}, path: "src/logs", isDir: true, excluded: true},
+		{name: "anchored path at another depth", excludes: []string{"/logs"}, path: "src/logs", isDir: true},
+		{name: "double wildcard matches slashes", excludes: []string{"/src/**.log"}, path: "src/logs/a.log", excluded: true},
+		{name: "directory pattern matches a directory", excludes: []string{"build/"}, path: "src/build", isDir: true, excluded: true},
+		{name: "directory pattern does not match a file", excludes: []string{"build/"}, path: "src/build"},
+		{name: "directory and contents", excludes: []string{"build/***"}, path: "build/bin/a", excluded: true},
+		{name: "character class", excludes: []string{"a[0-9].txt"}, path: "a1.txt", excluded: true},
+		{name: "negated character class", excludes: []string{"a[!0-9].txt"}, path: "a1.txt"},
+		{name: "escaped wildcard", excludes: []string{`a\*.txt`}, path: "ab.txt"},
+		{name: "include before exclude", includes: []string{"*.go"}, excludes: []string{"*"}, path: "a.go"},
+		{name: "exclude everything else", includes: []string{"*.go"}, excludes: []string{"*"}, path: "a.txt", excluded: true},
+		{name: "only includes", includes: []string{"*.go"}, path: "a.txt"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newPathFilter(tt.includes, tt.excludes)
+			if excluded := filter.Excluded(tt.path, tt.isDir); excluded != tt.excluded {
+				t.Errorf("expected excluded %t for %s, got %t", tt.excluded, tt.path, excluded)
+			}
+		})
+	}
+}
+
+func TestFilteredTarReader(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+	for _, header := range []*tar.Header{
+		{Name: "./", Typeflag: tar.TypeDir},
+		{Name: "./src/", Typeflag: tar.TypeDir},
+		{Name: "./src/a.go", Typeflag: tar.TypeReg},
+		{Name: "./src/a.log", Typeflag: tar.TypeReg},
+		{Name: "./src/link", Typeflag: tar.TypeSymlink, Linkname: "a.go"},
+		{Name: "./build/", Typeflag: tar.TypeDir},
+		{Name: "./build/a.go", Typeflag: tar.TypeReg},
+	} {
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &filteredTarReader{Reader: tar.NewReader(buf), filter: newPathFilter(nil, []string{"*.log", "build/"})}
+	var names []string
+	for {
+		header, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	expected := []string{"./", "./src/", "./src/a.go", "./src/link"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
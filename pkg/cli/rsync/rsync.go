@@ -43,6 +43,25 @@ var (
 
 		The following flags are passed to rsync by default:
 		--archive --no-owner --no-group --omit-dir-times --numeric-ids
+
+		Symbolic links are copied as symbolic links, and the permissions of the
+		files are preserved unless --no-perms is set. With --owner, the owner
+		and group of the files are preserved when permitted, which requires
+		running as root at the destination. With --xattrs, rsync also
+		preserves the extended attributes. The --include and --exclude
+		patterns follow rsync: the includes are checked before the excludes,
+		a pattern without a slash matches the name of a file at any depth,
+		and excluding a directory excludes its contents.
+
+		When rsync is available both locally and in the container, only the
+		changed parts of the files are transferred. Otherwise the files are
+		copied with tar, which requires the tar command in the container. The
+		tar strategy can copy a local directory with several concurrent tar
+		streams (--parallel), each one extracting a batch of files, and the
+		files of a batch that fails are copied again one by one (--retries).
+		With --compress, the tar strategy compresses the transfer with gzip if
+		the container has it. With --progress, it prints the progress of the
+		copy and a summary.
 	`)
 
 	rsyncExample = templates.Examples(`
@@ -51,6 +70,12 @@ var (
 
 		# Synchronize a pod directory with a local directory
 		oc rsync POD:/remote/dir/ ./local/dir
+
+		# Synchronize the Go sources of a local directory with a pod directory, preserving their owner
+		oc rsync ./local/dir/ POD:/remote/dir --include='*/' --include='*.go' --exclude='*' --owner
+
+		# Copy a large local directory to a pod without rsync with 8 compressed tar streams
+		oc rsync ./local/dir/ POD:/remote/dir --strategy=tar --parallel=8 --compress --progress
 	`)
 
 	rsyncDefaultFlags = []string{"--archive", "--no-owner", "--no-group", "--omit-dir-times", "--numeric-ids"}
@@ -97,6 +122,12 @@ type RsyncOptions struct {
 	RsyncExclude  []string
 	RsyncProgress bool
 	RsyncNoPerms  bool
+	RsyncPartial  bool
+	RsyncOwner    bool
+	RsyncXattrs   bool
+
+	Parallel int
+	Retries  int
 
 	Config *rest.Config
 	Client kubernetes.Interface
@@ -106,6 +137,8 @@ type RsyncOptions struct {
 func NewRsyncOptions(streams genericclioptions.IOStreams) *RsyncOptions {
 	return &RsyncOptions{
 		IOStreams: streams,
+		Parallel:  1,
+		Retries:   3,
 	}
 }
 
@@ -137,6 +170,11 @@ func NewCmdRsync(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra
 	cmd.Flags().BoolVar(&o.RsyncNoPerms, "no-perms", false, "If true, do not transfer permissions")
 	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", false, "Watch directory for changes and resync automatically")
 	cmd.Flags().BoolVar(&o.Compress, "compress", false, "compress file data during the transfer")
+	cmd.Flags().BoolVar(&o.RsyncPartial, "partial", false, "If true, keep partially transferred files to resume their transfer")
+	cmd.Flags().BoolVar(&o.RsyncOwner, "owner", false, "If true, preserve the owner and group of the files when permitted")
+	cmd.Flags().BoolVar(&o.RsyncXattrs, "xattrs", false, "If true, preserve the extended attributes of the files, only with rsync")
+	cmd.Flags().IntVar(&o.Parallel, "parallel", o.Parallel, "Number of concurrent tar streams copying a local directory with the tar strategy")
+	cmd.Flags().IntVar(&o.Retries, "retries", o.Retries, "Number of times the files that failed to copy with the tar strategy are copied again")
 
 	return cmd
 }
@@ -19,6 +19,7 @@ import (
 var (
 	testRsyncCommand = []string{"rsync", "--version"}
 	testTarCommand   = []string{"tar", "--version"}
+	testGzipCommand  = []string{"gzip", "--version"}
 )
 
 // executeWithLogging will execute a command and log its output
@@ -60,6 +61,10 @@ func checkTar(e executor) error {
 	return executeWithLogging(e, testTarCommand)
 }
 
+func checkGzip(e executor) error {
+	return executeWithLogging(e, testGzipCommand)
+}
+
 func rsyncFlagsFromOptions(o *RsyncOptions) []string {
 	flags := []string{}
 	if o.Quiet {
@@ -89,42 +94,42 @@ func rsyncFlagsFromOptions(o *RsyncOptions) []string {
 	if o.RsyncNoPerms {
 		flags = append(flags, "--no-perms")
 	}
+	if o.RsyncPartial {
+		flags = append(flags, "--partial")
+	}
+	if o.RsyncOwner {
+		// override the --no-owner and --no-group default flags
+		flags = append(flags, "--owner", "--group")
+	}
+	if o.RsyncXattrs {
+		flags = append(flags, "--xattrs")
+	}
 	return flags
 }
 
+// tarFlagsFromOptions returns the flags of the tar command extracting the files in the container. The include and
+// exclude patterns are not passed to tar, they are applied locally.
 func tarFlagsFromOptions(o *RsyncOptions) []string {
 	flags := []string{}
 	if !o.Quiet {
 		flags = append(flags, "-v")
 	}
-	if len(o.RsyncInclude) > 0 {
-		for _, include := range o.RsyncInclude {
-			flags = append(flags, fmt.Sprintf("**/%s", include))
-		}
-
-		// if we have explicit files or a pattern of filenames to include,
-		// maintain similar behavior to tar, and include anything else
-		// that would have otherwise been included
-		flags = append(flags, "*")
+	if !o.RsyncOwner {
+		flags = append(flags, "-o")
 	}
-	if len(o.RsyncExclude) > 0 {
-		for _, exclude := range o.RsyncExclude {
-			flags = append(flags, fmt.Sprintf("--exclude=%s", exclude))
-		}
+	if !o.RsyncNoPerms {
+		flags = append(flags, "-p")
 	}
 	return flags
 }
 
 func rsyncSpecificFlags(o *RsyncOptions) []string {
 	flags := []string{}
-	if o.RsyncProgress {
-		flags = append(flags, "--progress")
+	if o.RsyncPartial {
+		flags = append(flags, "--partial")
 	}
-	if o.RsyncNoPerms {
-		flags = append(flags, "--no-perms")
-	}
-	if o.Compress {
-		flags = append(flags, "-z")
+	if o.RsyncXattrs {
+		flags = append(flags, "--xattrs")
 	}
 	return flags
 }
@@ -8,6 +8,7 @@ import (
 
 	coreapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
@@ -20,6 +21,9 @@ var (
 		Link secrets to a service account.
 
 		Linking a secret enables a service account to automatically use that secret for some forms of authentication.
+
+		The secrets are verified before being linked: they must exist, secrets linked for pull must be image pull
+		secrets and the token secrets of other service accounts can't be linked for mount.
 	`)
 
 	linkSecretExample = templates.Examples(`
@@ -28,6 +32,9 @@ var (
 
 		# Add an image pull secret to a service account to automatically use it for both pulling and pushing build images
 		oc secrets link builder builder-image-secret --for=pull,mount
+
+		# Add an image pull secret to all the service accounts of the current project
+		oc secrets link --all-serviceaccounts pull-secret --for=pull
 	`)
 )
 
@@ -51,7 +58,7 @@ func NewCmdLinkSecret(f kcmdutil.Factory, streams genericclioptions.IOStreams) *
 	o := NewLinkSecretOptions(streams)
 
 	cmd := &cobra.Command{
-		Use:     "link serviceaccounts-name secret-name [another-secret-name]...",
+		Use:     "link (serviceaccounts-name | --all-serviceaccounts) secret-name [another-secret-name]...",
 		Short:   "Link secrets to a service account",
 		Long:    linkSecretLong,
 		Example: linkSecretExample,
@@ -63,6 +70,7 @@ func NewCmdLinkSecret(f kcmdutil.Factory, streams genericclioptions.IOStreams) *
 	}
 
 	cmd.Flags().StringSliceVar(&o.typeFlags, "for", []string{"mount"}, "type of secret to link: mount or pull")
+	cmd.Flags().BoolVar(&o.AllServiceAccounts, "all-serviceaccounts", o.AllServiceAccounts, "If true, link the secrets to all the service accounts in the namespace")
 
 	return cmd
 }
@@ -104,29 +112,44 @@ func (o LinkSecretOptions) Validate() error {
 }
 
 func (o LinkSecretOptions) LinkSecrets() error {
-	serviceaccount, err := o.GetServiceAccount()
+	serviceaccounts, err := o.GetServiceAccounts()
 	if err != nil {
 		return err
 	}
-
-	err = o.linkSecretsToServiceAccount(serviceaccount)
+	newSecrets, hasNotFound, err := o.GetSecrets(false)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	errs := []error{}
+	for _, serviceaccount := range serviceaccounts {
+		if err := o.linkSecretsToServiceAccount(serviceaccount, newSecrets); err != nil {
+			errs = append(errs, fmt.Errorf("serviceaccount/%s: %v", serviceaccount.Name, err))
+		}
+	}
+	if hasNotFound {
+		errs = append(errs, errors.New("Some secrets could not be linked"))
+	}
+
+	return utilerrors.NewAggregate(errs)
 }
 
 // TODO: when Secrets in kapi.ServiceAccount get changed to MountSecrets and represented by LocalObjectReferences, this can be
 // refactored to reuse the addition code better
 // linkSecretsToServiceAccount links secrets to the service account, either as pull secrets, mount secrets, or both.
-func (o LinkSecretOptions) linkSecretsToServiceAccount(serviceaccount *coreapiv1.ServiceAccount) error {
+// Secrets the service account can't use are reported and not linked.
+func (o LinkSecretOptions) linkSecretsToServiceAccount(serviceaccount *coreapiv1.ServiceAccount, secrets []*coreapiv1.Secret) error {
 	updated := false
-	newSecrets, hasNotFound, err := o.GetSecrets(false)
-	if err != nil {
-		return err
+	errs := []error{}
+	usableSecrets := []*coreapiv1.Secret{}
+	for _, secret := range secrets {
+		if err := validateSecretForServiceAccount(secret, serviceaccount.Name, o.ForPull, o.ForMount); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		usableSecrets = append(usableSecrets, secret)
 	}
-	newSecretNames := o.GetSecretNames(newSecrets)
+	newSecretNames := o.GetSecretNames(usableSecrets)
 
 	if o.ForMount {
 		currentSecrets := o.GetMountSecretNames(serviceaccount)
@@ -145,13 +168,10 @@ func (o LinkSecretOptions) linkSecretsToServiceAccount(serviceaccount *coreapiv1
 		}
 	}
 	if updated {
-		_, err = o.KubeClient.ServiceAccounts(o.Namespace).Update(context.TODO(), serviceaccount, metav1.UpdateOptions{})
-		return err
-	}
-
-	if hasNotFound {
-		return errors.New("Some secrets could not be linked")
+		if _, err := o.KubeClient.ServiceAccounts(o.Namespace).Update(context.TODO(), serviceaccount, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
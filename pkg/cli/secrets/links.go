@@ -0,0 +1,230 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	secretLinksLong = templates.LongDesc(`
+		Show the service accounts and pods referencing a secret.
+
+		Service accounts reference the secrets linked to them for mount or pull. Pods reference secrets
+		from their volumes, including projected volumes, from the environment of their containers and as
+		image pull secrets. Use this command to find what would be affected before deleting or rotating a
+		secret, or which service accounts still link a deleted secret.
+	`)
+
+	secretLinksExample = templates.Examples(`
+		# Show the service accounts and pods referencing the secret 'pull-secret'
+		oc secrets links pull-secret
+	`)
+)
+
+// SecretLinksOptions holds the state of the reverse lookup of the references to a secret.
+type SecretLinksOptions struct {
+	SecretName string
+	Namespace  string
+
+	KubeClient corev1client.CoreV1Interface
+
+	genericclioptions.IOStreams
+}
+
+// secretReference is a service account or pod referencing the secret, with the ways it is referenced.
+type secretReference struct {
+	kind   string
+	name   string
+	usages []string
+}
+
+func NewSecretLinksOptions(streams genericclioptions.IOStreams) *SecretLinksOptions {
+	return &SecretLinksOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdSecretLinks creates a command object for showing the service accounts and pods referencing a secret
+func NewCmdSecretLinks(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewSecretLinksOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "links secret-name",
+		Short:   "Show the service accounts and pods referencing a secret",
+		Long:    secretLinksLong,
+		Example: secretLinksExample,
+		Run: func(c *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	return cmd
+}
+
+func (o *SecretLinksOptions) Complete(f kcmdutil.Factory, args []string) error {
+	if len(args) != 1 {
+		return errors.New("must have exactly one secret name")
+	}
+	o.SecretName = args[0]
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.KubeClient, err = corev1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o SecretLinksOptions) Validate() error {
+	if len(o.SecretName) == 0 {
+		return errors.New("secret name must be present")
+	}
+	if segs := strings.Split(o.SecretName, "/"); len(segs) > 1 {
+		if segs[0] != "secret" && segs[0] != "secrets" {
+			return fmt.Errorf("expected resource of type secret, got %q", o.SecretName)
+		}
+	}
+	if o.KubeClient == nil {
+		return errors.New("KubeClient must be present")
+	}
+	return nil
+}
+
+func (o SecretLinksOptions) Run() error {
+	secretName := parseSecretName(o.SecretName)
+	// a secret that no longer exists may still be referenced, which is worth knowing about
+	if _, err := o.KubeClient.Secrets(o.Namespace).Get(context.TODO(), secretName, metav1.GetOptions{}); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		fmt.Fprintf(o.ErrOut, "warning: secret %q not found\n", secretName)
+	}
+
+	references, err := o.findReferences(secretName)
+	if err != nil {
+		return err
+	}
+	if len(references) == 0 {
+		fmt.Fprintf(o.ErrOut, "No service accounts or pods reference secret %q in namespace %s.\n", secretName, o.Namespace)
+		return nil
+	}
+	return printSecretReferences(o.Out, references)
+}
+
+// findReferences returns the service accounts and then the pods of the namespace referencing the secret.
+func (o SecretLinksOptions) findReferences(secretName string) ([]secretReference, error) {
+	references := []secretReference{}
+
+	serviceaccounts, err := o.KubeClient.ServiceAccounts(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, serviceaccount := range serviceaccounts.Items {
+		if usages := serviceAccountSecretUsages(&serviceaccount, secretName); len(usages) > 0 {
+			references = append(references, secretReference{kind: "serviceaccount", name: serviceaccount.Name, usages: usages})
+		}
+	}
+
+	pods, err := o.KubeClient.Pods(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if usages := podSecretUsages(&pod.Spec, secretName); len(usages) > 0 {
+			references = append(references, secretReference{kind: "pod", name: pod.Name, usages: usages})
+		}
+	}
+
+	return references, nil
+}
+
+// serviceAccountSecretUsages returns whether the secret is linked to the service account for mount, pull or both.
+func serviceAccountSecretUsages(serviceaccount *corev1.ServiceAccount, secretName string) []string {
+	usages := []string{}
+	for _, secret := range serviceaccount.Secrets {
+		if secret.Name == secretName {
+			usages = append(usages, "mount")
+			break
+		}
+	}
+	for _, secret := range serviceaccount.ImagePullSecrets {
+		if secret.Name == secretName {
+			usages = append(usages, "pull")
+			break
+		}
+	}
+	return usages
+}
+
+// podSecretUsages returns the volumes, environment variables and image pull secrets of the pod referencing the secret.
+func podSecretUsages(spec *corev1.PodSpec, secretName string) []string {
+	usages := []string{}
+	for _, volume := range spec.Volumes {
+		switch {
+		case volume.Secret != nil && volume.Secret.SecretName == secretName:
+			usages = append(usages, fmt.Sprintf("volume/%s", volume.Name))
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil && source.Secret.Name == secretName {
+					usages = append(usages, fmt.Sprintf("volume/%s", volume.Name))
+					break
+				}
+			}
+		}
+	}
+
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil && envFrom.SecretRef.Name == secretName {
+				usages = append(usages, fmt.Sprintf("envFrom/%s", container.Name))
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == secretName {
+				usages = append(usages, fmt.Sprintf("env/%s:%s", container.Name, env.Name))
+			}
+		}
+	}
+
+	for _, secret := range spec.ImagePullSecrets {
+		if secret.Name == secretName {
+			usages = append(usages, "pull")
+			break
+		}
+	}
+	return usages
+}
+
+func printSecretReferences(out io.Writer, references []secretReference) error {
+	w := printers.GetNewTabWriter(out)
+	fmt.Fprintln(w, "KIND\tNAME\tUSAGE")
+	for _, reference := range references {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", reference.kind, reference.name, strings.Join(reference.usages, ","))
+	}
+	return w.Flush()
+}
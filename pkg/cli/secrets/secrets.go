@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -41,6 +42,7 @@ func NewCmdSecrets(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cob
 
 	cmds.AddCommand(NewCmdLinkSecret(f, streams))
 	cmds.AddCommand(NewCmdUnlinkSecret(f, streams))
+	cmds.AddCommand(NewCmdSecretLinks(f, streams))
 
 	return cmds
 }
@@ -52,6 +54,9 @@ type SecretOptions struct {
 	SecretNames []string
 	typeFlags   []string
 
+	// AllServiceAccounts links or unlinks the secrets for all the service accounts of the namespace
+	AllServiceAccounts bool
+
 	Namespace string
 
 	BuilderFunc func() *resource.Builder
@@ -60,11 +65,18 @@ type SecretOptions struct {
 
 // Complete Parses the command line arguments and populates SecretOptions
 func (o *SecretOptions) Complete(f kcmdutil.Factory, args []string) error {
-	if len(args) < 2 {
-		return errors.New("must have service account name and at least one secret name")
+	if o.AllServiceAccounts {
+		if len(args) < 1 {
+			return errors.New("must have at least one secret name with --all-serviceaccounts")
+		}
+		o.SecretNames = args
+	} else {
+		if len(args) < 2 {
+			return errors.New("must have service account name and at least one secret name")
+		}
+		o.TargetName = args[0]
+		o.SecretNames = args[1:]
 	}
-	o.TargetName = args[0]
-	o.SecretNames = args[1:]
 
 	o.BuilderFunc = f.NewBuilder
 
@@ -87,7 +99,7 @@ func (o *SecretOptions) Complete(f kcmdutil.Factory, args []string) error {
 
 // Validate Ensures that all arguments have appropriate values
 func (o SecretOptions) Validate() error {
-	if len(o.TargetName) == 0 {
+	if len(o.TargetName) == 0 && !o.AllServiceAccounts {
 		return errors.New("service account name must be present")
 	}
 	if len(o.SecretNames) == 0 {
@@ -134,6 +146,31 @@ func (o SecretOptions) GetServiceAccount() (*corev1.ServiceAccount, error) {
 	}
 }
 
+// GetServiceAccounts Retrieve the service account specified by the command, or all the
+// service accounts of the namespace with --all-serviceaccounts
+func (o SecretOptions) GetServiceAccounts() ([]*corev1.ServiceAccount, error) {
+	if !o.AllServiceAccounts {
+		serviceaccount, err := o.GetServiceAccount()
+		if err != nil {
+			return nil, err
+		}
+		return []*corev1.ServiceAccount{serviceaccount}, nil
+	}
+
+	list, err := o.KubeClient.ServiceAccounts(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	serviceaccounts := []*corev1.ServiceAccount{}
+	for i := range list.Items {
+		serviceaccounts = append(serviceaccounts, &list.Items[i])
+	}
+	if len(serviceaccounts) == 0 {
+		return nil, fmt.Errorf("no service accounts found in namespace %s", o.Namespace)
+	}
+	return serviceaccounts, nil
+}
+
 // GetSecretNames Get a list of the names of the secrets in a set of them
 func (o SecretOptions) GetSecretNames(secrets []*corev1.Secret) sets.String {
 	names := sets.String{}
@@ -224,3 +261,17 @@ func (o SecretOptions) GetSecrets(allowNonExisting bool) ([]*corev1.Secret, bool
 
 	return secrets, hasNotFound, nil
 }
+
+// validateSecretForServiceAccount ensures that the secret is of a type the service account can use
+// to pull images or mount in its pods.
+func validateSecretForServiceAccount(secret *corev1.Secret, serviceaccount string, forPull, forMount bool) error {
+	if forPull && secret.Type != corev1.SecretTypeDockerConfigJson && secret.Type != corev1.SecretTypeDockercfg {
+		return fmt.Errorf("secret %q of type %s cannot be used to pull images, expected %s or %s", secret.Name, secret.Type, corev1.SecretTypeDockerConfigJson, corev1.SecretTypeDockercfg)
+	}
+	if forMount && secret.Type == corev1.SecretTypeServiceAccountToken {
+		if owner := secret.Annotations[corev1.ServiceAccountNameKey]; len(owner) > 0 && owner != serviceaccount {
+			return fmt.Errorf("secret %q is the token of service account %q", secret.Name, owner)
+		}
+	}
+	return nil
+}
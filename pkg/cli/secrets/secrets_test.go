@@ -0,0 +1,163 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateSecretForServiceAccount(t *testing.T) {
+	pullSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "pull"}, Type: corev1.SecretTypeDockerConfigJson}
+	opaqueSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "opaque"}, Type: corev1.SecretTypeOpaque}
+	tokenSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployer-token", Annotations: map[string]string{corev1.ServiceAccountNameKey: "deployer"}},
+		Type:       corev1.SecretTypeServiceAccountToken,
+	}
+
+	tests := []struct {
+		name     string
+		secret   *corev1.Secret
+		forPull  bool
+		forMount bool
+		err      string
+	}{
+		{name: "pull secret for pull", secret: pullSecret, forPull: true},
+		{name: "pull secret for mount", secret: pullSecret, forMount: true},
+		{name: "opaque secret for mount", secret: opaqueSecret, forMount: true},
+		{name: "opaque secret for pull", secret: opaqueSecret, forPull: true, err: "cannot be used to pull images"},
+		{name: "token of another service account", secret: tokenSecret, forMount: true, err: `is the token of service account "deployer"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateSecretForServiceAccount(test.secret, "builder", test.forPull, test.forMount)
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestLinkSecretsToAllServiceAccounts(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "builder", Namespace: "test"}},
+		&corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "default", Namespace: "test"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pull"}},
+		},
+	)
+	o := LinkSecretOptions{
+		SecretOptions: SecretOptions{Namespace: "test", KubeClient: client.CoreV1(), AllServiceAccounts: true},
+		ForPull:       true,
+	}
+	serviceaccounts, err := o.GetServiceAccounts()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secrets := []*corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pull"}, Type: corev1.SecretTypeDockerConfigJson},
+		{ObjectMeta: metav1.ObjectMeta{Name: "opaque"}, Type: corev1.SecretTypeOpaque},
+	}
+	for _, serviceaccount := range serviceaccounts {
+		err := o.linkSecretsToServiceAccount(serviceaccount, secrets)
+		if err == nil || !strings.Contains(err.Error(), `"opaque"`) {
+			t.Errorf("expected the opaque secret to be rejected, got %v", err)
+		}
+	}
+
+	for _, name := range []string{"builder", "default"} {
+		serviceaccount, err := client.CoreV1().ServiceAccounts("test").Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(serviceaccount.ImagePullSecrets) != 1 || serviceaccount.ImagePullSecrets[0].Name != "pull" {
+			t.Errorf("expected only the pull secret to be linked to %s, got %v", name, serviceaccount.ImagePullSecrets)
+		}
+	}
+}
+
+func TestUnlinkSecretsFromServiceAccount(t *testing.T) {
+	serviceaccount := &corev1.ServiceAccount{
+		ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "test"},
+		Secrets:          []corev1.ObjectReference{{Name: "mount"}, {Name: "pull"}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pull"}},
+	}
+	client := fake.NewSimpleClientset(serviceaccount)
+	o := UnlinkSecretOptions{SecretOptions: SecretOptions{Namespace: "test", KubeClient: client.CoreV1()}}
+
+	updated, err := o.unlinkSecretsFromServiceAccount(serviceaccount, sets.NewString("other"))
+	if err != nil || updated {
+		t.Fatalf("expected the service account not to be updated, got %v, %v", updated, err)
+	}
+	updated, err = o.unlinkSecretsFromServiceAccount(serviceaccount, sets.NewString("pull"))
+	if err != nil || !updated {
+		t.Fatalf("expected the service account to be updated, got %v, %v", updated, err)
+	}
+	if len(serviceaccount.Secrets) != 1 || len(serviceaccount.ImagePullSecrets) != 0 {
+		t.Errorf("expected the pull secret to be unlinked, got %v and %v", serviceaccount.Secrets, serviceaccount.ImagePullSecrets)
+	}
+}
+
+func TestSecretLinks(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "test"},
+			Secrets:          []corev1.ObjectReference{{Name: "creds"}},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "creds"}},
+		},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "test"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+			Spec: corev1.PodSpec{
+				Volumes: []corev1.Volume{{
+					Name: "certs",
+					VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+						{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}}},
+					}}},
+				}},
+				Containers: []corev1.Container{{
+					Name: "web",
+					Env: []corev1.EnvVar{{
+						Name:      "PASSWORD",
+						ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}, Key: "password"}},
+					}},
+				}},
+			},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test"}},
+	)
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := SecretLinksOptions{SecretName: "secret/creds", Namespace: "test", KubeClient: client.CoreV1()}
+	o.Out, o.ErrOut = out, errOut
+	if err := o.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(errOut.String(), `secret "creds" not found`) {
+		t.Errorf("expected a warning about the missing secret, got %q", errOut.String())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and two references, got:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[1]); len(fields) != 3 || fields[0] != "serviceaccount" || fields[1] != "builder" || fields[2] != "mount,pull" {
+		t.Errorf("unexpected service account reference %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); len(fields) != 3 || fields[0] != "pod" || fields[1] != "web" || fields[2] != "volume/certs,env/web:PASSWORD" {
+		t.Errorf("unexpected pod reference %q", lines[2])
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	coreapiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -20,12 +21,16 @@ var (
 	unlinkSecretLong = templates.LongDesc(`
 		Unlink (detach) secrets from a service account.
 
-		If a secret is no longer valid for a pod, build or image pull, you may unlink it from a service account.
+		If a secret is no longer valid for a pod, build or image pull, you may unlink it from a service account,
+		or from all the service accounts of the namespace that link it with --all-serviceaccounts.
 	`)
 
 	unlinkSecretExample = templates.Examples(`
 		# Unlink a secret currently associated with a service account
 		oc secrets unlink serviceaccount-name secret-name another-secret-name ...
+
+		# Unlink a secret from all the service accounts of the current project
+		oc secrets unlink --all-serviceaccounts secret-name
 	`)
 )
 
@@ -51,7 +56,7 @@ func NewCmdUnlinkSecret(f kcmdutil.Factory, streams genericclioptions.IOStreams)
 	o := NewUnlinkSecretOptions(streams)
 
 	cmd := &cobra.Command{
-		Use:     "unlink serviceaccount-name secret-name [another-secret-name] ...",
+		Use:     "unlink (serviceaccount-name | --all-serviceaccounts) secret-name [another-secret-name] ...",
 		Short:   "Detach secrets from a service account",
 		Long:    unlinkSecretLong,
 		Example: unlinkSecretExample,
@@ -62,6 +67,7 @@ func NewCmdUnlinkSecret(f kcmdutil.Factory, streams genericclioptions.IOStreams)
 		},
 	}
 
+	cmd.Flags().BoolVar(&o.AllServiceAccounts, "all-serviceaccounts", o.AllServiceAccounts, "If true, unlink the secrets from all the service accounts in the namespace")
 	o.PrintFlags.AddFlags(cmd)
 	return cmd
 }
@@ -81,20 +87,11 @@ func (o *UnlinkSecretOptions) Complete(f kcmdutil.Factory, args []string) error
 }
 
 func (o UnlinkSecretOptions) Run() error {
-	serviceaccount, err := o.GetServiceAccount()
+	serviceaccounts, err := o.GetServiceAccounts()
 	if err != nil {
 		return err
 	}
 
-	if err = o.unlinkSecretsFromServiceAccount(serviceaccount); err != nil {
-		return err
-	}
-
-	return o.Printer.PrintObj(serviceaccount, o.Out)
-}
-
-// unlinkSecretsFromServiceAccount detaches pull and mount secrets from the service account.
-func (o UnlinkSecretOptions) unlinkSecretsFromServiceAccount(serviceaccount *coreapiv1.ServiceAccount) error {
 	// All of the requested secrets must be present in either the Mount or Pull secrets
 	// If any of them are not present, we'll return an error and push no changes.
 	rmSecrets, hasNotFound, err := o.GetSecrets(true)
@@ -103,6 +100,39 @@ func (o UnlinkSecretOptions) unlinkSecretsFromServiceAccount(serviceaccount *cor
 	}
 	rmSecretNames := o.GetSecretNames(rmSecrets)
 
+	unlinked := []*coreapiv1.ServiceAccount{}
+	for _, serviceaccount := range serviceaccounts {
+		updated, err := o.unlinkSecretsFromServiceAccount(serviceaccount, rmSecretNames)
+		if err != nil {
+			return err
+		}
+		if updated {
+			unlinked = append(unlinked, serviceaccount)
+		}
+	}
+
+	if len(unlinked) == 0 {
+		return errors.New("No valid secrets found or secrets not linked to service account")
+	}
+	if hasNotFound {
+		if !o.AllServiceAccounts {
+			return fmt.Errorf("Unlinked deleted secrets from %s/%s service account", o.Namespace, unlinked[0].Name)
+		}
+		return fmt.Errorf("Unlinked deleted secrets from %d service accounts in %s", len(unlinked), o.Namespace)
+	}
+
+	for _, serviceaccount := range unlinked {
+		if err := o.Printer.PrintObj(serviceaccount, o.Out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unlinkSecretsFromServiceAccount detaches pull and mount secrets from the service account, returning
+// whether it unlinked any of them.
+func (o UnlinkSecretOptions) unlinkSecretsFromServiceAccount(serviceaccount *coreapiv1.ServiceAccount, rmSecretNames sets.String) (bool, error) {
+
 	newMountSecrets := []coreapiv1.ObjectReference{}
 	newPullSecrets := []coreapiv1.LocalObjectReference{}
 	updated := false
@@ -127,19 +157,15 @@ func (o UnlinkSecretOptions) unlinkSecretsFromServiceAccount(serviceaccount *cor
 		}
 	}
 
-	if updated {
-		// Save the updated Secret lists back to the server
-		serviceaccount.Secrets = newMountSecrets
-		serviceaccount.ImagePullSecrets = newPullSecrets
-		_, err = o.KubeClient.ServiceAccounts(o.Namespace).Update(context.TODO(), serviceaccount, metav1.UpdateOptions{})
-		if err != nil {
-			return err
-		}
-		if hasNotFound {
-			return fmt.Errorf("Unlinked deleted secrets from %s/%s service account", o.Namespace, serviceaccount.Name)
-		}
-		return nil
-	} else {
-		return errors.New("No valid secrets found or secrets not linked to service account")
+	if !updated {
+		return false, nil
+	}
+
+	// Save the updated Secret lists back to the server
+	serviceaccount.Secrets = newMountSecrets
+	serviceaccount.ImagePullSecrets = newPullSecrets
+	if _, err := o.KubeClient.ServiceAccounts(o.Namespace).Update(context.TODO(), serviceaccount, metav1.UpdateOptions{}); err != nil {
+		return false, err
 	}
+	return true, nil
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
@@ -46,7 +48,12 @@ var (
 		specified pods or pod templates, or just those that match a wildcard.
 
 		If "--env -" is passed, environment variables can be read from STDIN using the standard env
-		syntax.`)
+		syntax.
+
+		Environment variables can also be read from dotenv files with "--from-env-file", which may be
+		repeated: the variables of the later files and of the command line replace the earlier ones.
+		Use "--show-diff" to print the changes of the environment of each container to standard error
+		before they are applied, and combine it with "--dry-run" to only preview them.`)
 
 	envExample = templates.Examples(`
 		# Update deployment config 'myapp' with a new environment variable
@@ -79,6 +86,10 @@ var (
 
 		# Set some of the local shell environment into a deployment config on the server
 		oc set env | grep RAILS_ | oc env -e - dc/myapp
+
+		# Import environment from dotenv files with a prefix, the variables of .env.production replacing
+		# the ones of .env, and preview the changes without applying them
+		oc set env deployment/myapp --from-env-file=.env --from-env-file=.env.production --prefix=APP_ --show-diff --dry-run=client -o name
 	`)
 )
 
@@ -94,6 +105,7 @@ type EnvOptions struct {
 	List           bool
 	Local          bool
 	Overwrite      bool
+	ShowDiff       bool
 	DryRunStrategy kcmdutil.DryRunStrategy
 	FieldManager   string
 
@@ -101,6 +113,7 @@ type EnvOptions struct {
 	ContainerSelector string
 	Selector          string
 	From              string
+	EnvFiles          []string
 	Prefix            string
 
 	UpdatePodSpecForObject polymorphichelpers.UpdatePodSpecForObjectFunc
@@ -145,6 +158,8 @@ func NewCmdEnv(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.C
 	kcmdutil.AddFilenameOptionFlags(cmd, &o.FilenameOptions, usage)
 	cmd.Flags().StringVarP(&o.ContainerSelector, "containers", "c", o.ContainerSelector, "The names of containers in the selected pod templates to change - may use wildcards")
 	cmd.Flags().StringVar(&o.From, "from", o.From, "The name of a resource from which to inject environment variables")
+	cmd.Flags().StringArrayVar(&o.EnvFiles, "from-env-file", o.EnvFiles, "Path to a dotenv file of KEY=VALUE lines to read environment variables from, may be repeated")
+	cmd.MarkFlagFilename("from-env-file")
 	cmd.Flags().StringVar(&o.Prefix, "prefix", o.Prefix, "Prefix to append to variable names")
 	cmd.Flags().StringArrayVarP(&o.EnvParams, "env", "e", o.EnvParams, "Specify a key-value pair for an environment variable to set into each container.")
 	cmd.Flags().BoolVar(&o.List, "list", o.List, "If true, display the environment and any changes in the standard format")
@@ -153,6 +168,7 @@ func NewCmdEnv(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.C
 	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, set image will NOT contact api-server but run locally.")
 	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, select all resources in the namespace of the specified resource types")
 	cmd.Flags().BoolVar(&o.Overwrite, "overwrite", o.Overwrite, "If true, allow environment to be overwritten, otherwise reject updates that overwrite existing environment.")
+	cmd.Flags().BoolVar(&o.ShowDiff, "show-diff", o.ShowDiff, "If true, print the changes of the environment of each container to standard error before applying them")
 	cmd.Flags().StringVar(&o.ResourceVersion, "resource-version", o.ResourceVersion, "If non-empty, the labels update will only succeed if this is the current resource-version for the object. Only valid when specifying a single resource.")
 
 	kcmdutil.AddDryRunFlag(cmd)
@@ -233,6 +249,9 @@ func (o *EnvOptions) Validate() error {
 	if o.Local && o.DryRunStrategy == kcmdutil.DryRunServer {
 		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
 	}
+	if o.List && o.ShowDiff {
+		return fmt.Errorf("--list and --show-diff may not be specified together")
+	}
 
 	cmdutil.WarnAboutCommaSeparation(o.ErrOut, o.EnvParams, "--env")
 
@@ -247,6 +266,18 @@ func (o *EnvOptions) RunEnv() error {
 	if err != nil {
 		return err
 	}
+	if len(o.EnvFiles) != 0 {
+		fileEnv, err := utilenv.ParseEnvFiles(o.EnvFiles)
+		if err != nil {
+			return err
+		}
+		for _, name := range remove {
+			if _, found := findEnv(fileEnv, name); found {
+				return fmt.Errorf("can not both modify and remove the environment variable %s in the same command", name)
+			}
+		}
+		env = utilenv.MergeEnv(append(fileEnv, env...))
+	}
 
 	if len(o.From) != 0 {
 		b := o.Builder().
@@ -371,7 +402,11 @@ func (o *EnvOptions) RunEnv() error {
 					}
 				}
 
-				c.Env = updateEnv(c.Env, env, remove)
+				updated := updateEnv(c.Env, env, remove)
+				if o.ShowDiff {
+					printEnvDiff(o.ErrOut, fmt.Sprintf("%s, container %s", name, c.Name), c.Env, updated)
+				}
+				c.Env = updated
 
 				if o.List {
 					resolveErrors := map[string][]string{}
@@ -435,7 +470,11 @@ func (o *EnvOptions) RunEnv() error {
 						return err
 					}
 				}
-				*vars = updateEnv(*vars, env, remove)
+				updated := updateEnv(*vars, env, remove)
+				if o.ShowDiff {
+					printEnvDiff(o.ErrOut, name, *vars, updated)
+				}
+				*vars = updated
 				if o.List {
 					fmt.Fprintf(o.Out, "# %s\n", name)
 					for _, env := range *vars {
@@ -515,6 +554,41 @@ updates:
 	return utilerrors.NewAggregate(allErrs)
 }
 
+// printEnvDiff prints the environment variables added (+), changed (~) and removed (-) by the update.
+func printEnvDiff(out io.Writer, header string, before, after []corev1.EnvVar) {
+	fmt.Fprintf(out, "# %s\n", header)
+	changes := 0
+	for _, e := range after {
+		old, found := findEnv(before, e.Name)
+		switch {
+		case !found:
+			fmt.Fprintf(out, "+ %s=%s\n", e.Name, envValueString(e))
+		case !reflect.DeepEqual(old, e):
+			fmt.Fprintf(out, "~ %s=%s (was %s)\n", e.Name, envValueString(e), envValueString(old))
+		default:
+			continue
+		}
+		changes++
+	}
+	for _, e := range before {
+		if _, found := findEnv(after, e.Name); !found {
+			fmt.Fprintf(out, "- %s\n", e.Name)
+			changes++
+		}
+	}
+	if changes == 0 {
+		fmt.Fprintf(out, "# no changes\n")
+	}
+}
+
+// envValueString returns the value of the environment variable, or the reference to its value.
+func envValueString(e corev1.EnvVar) string {
+	if e.ValueFrom != nil {
+		return "<" + envresolve.GetEnvVarRefString(e.ValueFrom) + ">"
+	}
+	return e.Value
+}
+
 // UpdateObjectEnvironment update the environment variables in object specification.
 func updateObjectEnvironment(obj runtime.Object, fn func(*[]corev1.EnvVar) error) (bool, error) {
 	switch t := obj.(type) {
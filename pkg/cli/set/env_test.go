@@ -0,0 +1,39 @@
+package set
+
+import (
+	"bytes"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPrintEnvDiff(t *testing.T) {
+	before := []corev1.EnvVar{
+		{Name: "KEEP", Value: "1"},
+		{Name: "CHANGE", Value: "old"},
+		{Name: "REMOVE", Value: "x"},
+	}
+	after := updateEnv(before, []corev1.EnvVar{
+		{Name: "CHANGE", Value: "new"},
+		{Name: "ADD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}, Key: "password",
+		}}},
+	}, []string{"REMOVE"})
+
+	out := &bytes.Buffer{}
+	printEnvDiff(out, "deployment/app, container app", before, after)
+	expected := `# deployment/app, container app
+~ CHANGE=new (was old)
++ ADD=<secret creds, key password>
+- REMOVE
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	printEnvDiff(out, "bc/app", before, before)
+	if out.String() != "# bc/app\n# no changes\n" {
+		t.Errorf("unexpected diff without changes:\n%s", out.String())
+	}
+}
@@ -0,0 +1,127 @@
+package set
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubectl/pkg/cmd/rollout"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+
+	"github.com/openshift/library-go/pkg/image/reference"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+	utilenv "github.com/openshift/oc/pkg/helpers/env"
+)
+
+// ImageOptions are the options added to the kubectl set image command to pin the images to their digests and to
+// wait for the rollouts of the updated resources.
+type ImageOptions struct {
+	PinDigest       bool
+	SecurityOptions imagemanifest.SecurityOptions
+
+	Wait    bool
+	Timeout time.Duration
+}
+
+// AddFlags adds the flags of the options to the set image command.
+func (o *ImageOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.PinDigest, "pin-digest", o.PinDigest, "If true, set the images by the digest their tag currently points to, resolved with the image stream tag or the registry")
+	cmd.Flags().StringVar(&o.SecurityOptions.RegistryConfig, "registry-config", o.SecurityOptions.RegistryConfig, "Path to your registry credentials to resolve the digests of the images with --pin-digest (defaults to ~/.docker/config.json)")
+	cmd.Flags().BoolVar(&o.SecurityOptions.Insecure, "insecure", o.SecurityOptions.Insecure, "Allow the digests of the images to be resolved over HTTP with --pin-digest")
+	cmd.Flags().BoolVar(&o.Wait, "wait", o.Wait, "If true, wait for the rollouts of the updated resources to complete")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "The length of time to wait for each rollout with --wait, zero means never")
+}
+
+// Validate checks that the updated resources can be waited for.
+func (o *ImageOptions) Validate(cmd *cobra.Command) error {
+	if !o.Wait {
+		return nil
+	}
+	dryRun, err := kcmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	if kcmdutil.GetFlagBool(cmd, "local") || dryRun != kcmdutil.DryRunNone {
+		return fmt.Errorf("--wait can't be used with --local or --dry-run")
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("--timeout must be a positive duration")
+	}
+	return nil
+}
+
+// pinDigest returns the image by the digest its tag points to in its registry, unless it already is a digest.
+func (o *ImageOptions) pinDigest(image string) (string, error) {
+	ref, err := reference.Parse(image)
+	if err != nil {
+		return "", err
+	}
+	if len(ref.ID) > 0 {
+		return image, nil
+	}
+	tag := ref.Tag
+	if len(tag) == 0 {
+		tag = "latest"
+	}
+
+	ctx := context.Background()
+	registryContext, err := o.SecurityOptions.Context()
+	if err != nil {
+		return "", err
+	}
+	repo, err := registryContext.Repository(ctx, ref.DockerClientDefaults().RegistryURL(), ref.RepositoryName(), o.SecurityOptions.Insecure)
+	if err != nil {
+		return "", fmt.Errorf("unable to connect to the registry of %s: %v", image, err)
+	}
+	desc, err := repo.Tags(ctx).Get(ctx, tag)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve the digest of %s: %v", image, err)
+	}
+	ref.Tag, ref.ID = "", desc.Digest.String()
+	return ref.Exact(), nil
+}
+
+// waitForRollouts waits for the rollouts of the resources updated by set image, one after the other.
+func (o *ImageOptions) waitForRollouts(f kcmdutil.Factory, cmd *cobra.Command, args []string, streams genericclioptions.IOStreams) error {
+	// the arguments after the resources are the CONTAINER=IMAGE pairs
+	resources, _, ok := utilenv.SplitEnvironmentFromResources(args)
+	if !ok {
+		return fmt.Errorf("all resources must be specified before the images: %v", args)
+	}
+	namespace, enforceNamespace, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	filenames := kcmdutil.GetFlagStringSlice(cmd, "filename")
+	infos, err := f.NewBuilder().
+		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		ContinueOnError().
+		NamespaceParam(namespace).DefaultNamespace().
+		FilenameParam(enforceNamespace, &resource.FilenameOptions{Filenames: filenames, Recursive: kcmdutil.GetFlagBool(cmd, "recursive"), Kustomize: kcmdutil.GetFlagString(cmd, "kustomize")}).
+		LabelSelectorParam(kcmdutil.GetFlagString(cmd, "selector")).
+		ResourceTypeOrNameArgs(kcmdutil.GetFlagBool(cmd, "all"), resources...).
+		Flatten().
+		Do().
+		Infos()
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		status := rollout.NewRolloutStatusOptions(streams)
+		status.Timeout = o.Timeout
+		if err := status.Complete(f, []string{fmt.Sprintf("%s/%s", info.Mapping.Resource.GroupResource(), info.Name)}); err != nil {
+			return err
+		}
+		status.Namespace = info.Namespace
+		if err := status.Run(); err != nil {
+			return fmt.Errorf("%s: %v", getObjectName(info), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package set
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestImageOptionsPinDigest(t *testing.T) {
+	const digest = "sha256:4b2e5a55ea5d4f6fbfc09a2e6c6b5ac7a2bc3b6f1e5d3c2b1a0f9e8d7c6b5a49"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/library/nginx/manifests/1.9.1":
+			w.Header().Set("Docker-Content-Digest", digest)
+			w.Header().Set("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := &ImageOptions{PinDigest: true}
+	o.SecurityOptions.Insecure = true
+	pinned, err := o.pinDigest(u.Host + "/library/nginx:1.9.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := u.Host + "/library/nginx@" + digest; pinned != expected {
+		t.Errorf("expected %s, got %s", expected, pinned)
+	}
+
+	byDigest := "quay.io/openshift/origin-cli@" + digest
+	if pinned, err := o.pinDigest(byDigest); err != nil || pinned != byDigest {
+		t.Errorf("expected an image by digest to be kept, got %s: %v", pinned, err)
+	}
+	if _, err := o.pinDigest(u.Host + "/library/nginx:missing"); err == nil {
+		t.Errorf("expected an error for a missing tag")
+	}
+}
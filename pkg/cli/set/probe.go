@@ -39,11 +39,17 @@ var (
 		is started.
 		Setting both liveness and readiness probes for each container is highly recommended.
 
-		The three probe types are:
+		The four probe types are:
 
 		1. Open a TCP socket on the pod IP
-		2. Perform an HTTP GET against a URL on a container that must return 200 OK
+		2. Perform an HTTP GET against a URL on a container that must return 200 OK, optionally
+		   with custom HTTP headers
 		3. Run a command in the container that must return exit code 0
+		4. Call the gRPC health checking service of the container, optionally for a named service
+
+		The timing and threshold flags apply to all the selected probes. Use --liveness-settings,
+		--readiness-settings and --startup-settings to set different values for each probe, like a
+		startup probe with a high failure threshold in front of a strict liveness probe.
 
 		Containers that take a variable amount of time to start should set generous
 		initial-delay-seconds values, otherwise as your application evolves you may suddenly begin
@@ -70,6 +76,17 @@ var (
 
 		# Set only the initial-delay-seconds field on all deployments
 		oc set probe dc --all --readiness --initial-delay-seconds=30
+
+		# Set an HTTP liveness probe sending a Host header and an authorization token
+		oc set probe deployment/webapp --liveness --get-url=http://:8080/healthz \
+		  --http-header=Host:webapp.example.com --http-header="Authorization:Bearer token"
+
+		# Set a gRPC readiness probe calling the health checking service of the 'orders' service on port 9090
+		oc set probe deployment/orders --readiness --grpc=9090 --grpc-service=orders
+
+		# Set liveness and startup probes for the same endpoint, giving the container up to 5 minutes to start
+		oc set probe deployment/webapp --liveness --startup --get-url=http://:8080/healthz \
+		  --period-seconds=10 --startup-settings=failure-threshold=30
 	`)
 )
 
@@ -86,6 +103,9 @@ type ProbeOptions struct {
 	Local             bool
 	OpenTCPSocket     string
 	HTTPGet           string
+	HTTPHeaders       []string
+	GRPC              string
+	GRPCService       string
 
 	Printer                printers.ResourcePrinter
 	Builder                func() *resource.Builder
@@ -100,6 +120,7 @@ type ProbeOptions struct {
 
 	FlagSet       func(string) bool
 	HTTPGetAction *corev1.HTTPGetAction
+	GRPCAction    *corev1.GRPCAction
 
 	// Length of time before health checking is activated.  In seconds.
 	InitialDelaySeconds *int
@@ -112,6 +133,13 @@ type ProbeOptions struct {
 	SuccessThreshold *int
 	// Minimum consecutive failures for the probe to be considered failed after having succeeded.
 	FailureThreshold *int
+	// Length of time the pod is given to terminate gracefully after the probe failed.  In seconds.
+	TerminationGracePeriodSeconds *int
+
+	// Settings of the timing and thresholds of each probe, overriding the ones of all the probes.
+	LivenessSettings  map[string]int
+	ReadinessSettings map[string]int
+	StartupSettings   map[string]int
 
 	resource.FilenameOptions
 	genericclioptions.IOStreams
@@ -130,7 +158,7 @@ func NewProbeOptions(streams genericclioptions.IOStreams) *ProbeOptions {
 func NewCmdProbe(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
 	o := NewProbeOptions(streams)
 	cmd := &cobra.Command{
-		Use:     "probe RESOURCE/NAME --readiness|--liveness [flags] (--get-url=URL|--open-tcp=PORT|-- CMD)",
+		Use:     "probe RESOURCE/NAME --readiness|--liveness|--startup [flags] (--get-url=URL|--open-tcp=PORT|--grpc=PORT|-- CMD)",
 		Short:   "Update a probe on a pod template",
 		Long:    probeLong,
 		Example: probeExample,
@@ -152,12 +180,19 @@ func NewCmdProbe(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra
 	cmd.Flags().BoolVar(&o.Local, "local", o.Local, "If true, set image will NOT contact api-server but run locally.")
 	cmd.Flags().StringVar(&o.OpenTCPSocket, "open-tcp", o.OpenTCPSocket, "A port number or port name to attempt to open via TCP.")
 	cmd.Flags().StringVar(&o.HTTPGet, "get-url", o.HTTPGet, "A URL to perform an HTTP GET on (you can omit the host, have a string port, or omit the scheme.")
+	cmd.Flags().StringArrayVar(&o.HTTPHeaders, "http-header", o.HTTPHeaders, "A header NAME:VALUE to send with the HTTP GET of --get-url, may be repeated")
+	cmd.Flags().StringVar(&o.GRPC, "grpc", o.GRPC, "A port number to call the gRPC health checking service on")
+	cmd.Flags().StringVar(&o.GRPCService, "grpc-service", o.GRPCService, "The name of the service to check with the gRPC health checking service of --grpc")
 
 	o.InitialDelaySeconds = cmd.Flags().Int("initial-delay-seconds", 0, "The time in seconds to wait before the probe begins checking")
 	o.SuccessThreshold = cmd.Flags().Int("success-threshold", 0, "The number of successes required before the probe is considered successful")
 	o.FailureThreshold = cmd.Flags().Int("failure-threshold", 0, "The number of failures before the probe is considered to have failed")
 	o.PeriodSeconds = cmd.Flags().Int("period-seconds", 0, "The time in seconds between attempts")
 	o.TimeoutSeconds = cmd.Flags().Int("timeout-seconds", 0, "The time in seconds to wait before considering the probe to have failed")
+	o.TerminationGracePeriodSeconds = cmd.Flags().Int("termination-grace-period-seconds", 0, "The time in seconds given to the pod to terminate gracefully after a liveness or startup probe failed")
+	cmd.Flags().StringToIntVar(&o.LivenessSettings, "liveness-settings", o.LivenessSettings, "The timing and thresholds of the liveness probe, overriding the flags of all the probes, like failure-threshold=3,period-seconds=10")
+	cmd.Flags().StringToIntVar(&o.ReadinessSettings, "readiness-settings", o.ReadinessSettings, "The timing and thresholds of the readiness probe, overriding the flags of all the probes, like success-threshold=2,timeout-seconds=5")
+	cmd.Flags().StringToIntVar(&o.StartupSettings, "startup-settings", o.StartupSettings, "The timing and thresholds of the startup probe, overriding the flags of all the probes, like failure-threshold=30")
 
 	o.PrintFlags.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
@@ -210,6 +245,9 @@ func (o *ProbeOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []s
 	if !cmd.Flags().Lookup("failure-threshold").Changed {
 		o.FailureThreshold = nil
 	}
+	if !cmd.Flags().Lookup("termination-grace-period-seconds").Changed {
+		o.TerminationGracePeriodSeconds = nil
+	}
 
 	if len(o.HTTPGet) > 0 {
 		url, err := url.Parse(o.HTTPGet)
@@ -231,6 +269,28 @@ func (o *ProbeOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []s
 			Port:   intOrString(port),
 			Path:   url.RequestURI(),
 		}
+		for _, header := range o.HTTPHeaders {
+			parts := strings.SplitN(header, ":", 2)
+			if len(parts) != 2 || len(strings.TrimSpace(parts[0])) == 0 {
+				return fmt.Errorf("--http-header must be of the form NAME:VALUE, but is %q", header)
+			}
+			o.HTTPGetAction.HTTPHeaders = append(o.HTTPGetAction.HTTPHeaders, corev1.HTTPHeader{
+				Name:  strings.TrimSpace(parts[0]),
+				Value: strings.TrimSpace(parts[1]),
+			})
+		}
+	}
+
+	if len(o.GRPC) > 0 {
+		port, err := strconv.ParseInt(o.GRPC, 10, 32)
+		if err != nil {
+			return fmt.Errorf("--grpc must be a port number: %v", err)
+		}
+		o.GRPCAction = &corev1.GRPCAction{Port: int32(port)}
+		if len(o.GRPCService) > 0 {
+			service := o.GRPCService
+			o.GRPCAction.Service = &service
+		}
 	}
 
 	return nil
@@ -250,14 +310,23 @@ func (o *ProbeOptions) Validate() error {
 	if len(o.HTTPGet) > 0 {
 		count++
 	}
+	if len(o.GRPC) > 0 {
+		count++
+	}
 
 	switch {
 	case o.Remove && count != 0:
-		return fmt.Errorf("--remove may not be used with any flag except --readiness or --liveness")
+		return fmt.Errorf("--remove may not be used with any flag except --readiness, --liveness or --startup")
 	case count > 1:
-		return fmt.Errorf("you may only set one of --get-url, --open-tcp, or command")
+		return fmt.Errorf("you may only set one of --get-url, --open-tcp, --grpc, or command")
 	case len(o.OpenTCPSocket) > 0 && intOrString(o.OpenTCPSocket).IntVal > 65535:
 		return fmt.Errorf("--open-tcp must be a port number between 1 and 65535 or an IANA port name")
+	case len(o.HTTPHeaders) > 0 && len(o.HTTPGet) == 0:
+		return fmt.Errorf("--http-header may only be used with --get-url")
+	case len(o.GRPCService) > 0 && len(o.GRPC) == 0:
+		return fmt.Errorf("--grpc-service may only be used with --grpc")
+	case o.GRPCAction != nil && (o.GRPCAction.Port < 1 || o.GRPCAction.Port > 65535):
+		return fmt.Errorf("--grpc must be a port number between 1 and 65535")
 	}
 	if o.FailureThreshold != nil && *o.FailureThreshold < 1 {
 		return fmt.Errorf("--failure-threshold may not be less than one")
@@ -274,6 +343,26 @@ func (o *ProbeOptions) Validate() error {
 	if o.PeriodSeconds != nil && *o.PeriodSeconds < 0 {
 		return fmt.Errorf("--period-seconds may not be negative")
 	}
+	if o.TerminationGracePeriodSeconds != nil && *o.TerminationGracePeriodSeconds < 1 {
+		return fmt.Errorf("--termination-grace-period-seconds may not be less than one")
+	}
+	if o.TerminationGracePeriodSeconds != nil && o.Readiness && !o.Liveness && !o.Startup {
+		return fmt.Errorf("--termination-grace-period-seconds may not be set on readiness probes")
+	}
+	for probe, settings := range map[string]map[string]int{"liveness": o.LivenessSettings, "readiness": o.ReadinessSettings, "startup": o.StartupSettings} {
+		for name, value := range settings {
+			minimum, ok := probeSettingMinimums[name]
+			if !ok {
+				return fmt.Errorf("--%s-settings: unknown setting %q, valid settings are initial-delay-seconds, timeout-seconds, period-seconds, success-threshold and failure-threshold", probe, name)
+			}
+			if value < minimum {
+				return fmt.Errorf("--%s-settings: %s may not be less than %d", probe, name, minimum)
+			}
+		}
+	}
+	if len(o.LivenessSettings) > 0 && !o.Liveness || len(o.ReadinessSettings) > 0 && !o.Readiness || len(o.StartupSettings) > 0 && !o.Startup {
+		return fmt.Errorf("the settings of a probe may only be used when setting the probe with --liveness, --readiness or --startup")
+	}
 	if len(o.HTTPGet) > 0 && len(o.HTTPGetAction.Port.String()) == 0 {
 		return fmt.Errorf("port must be specified as part of a url")
 	}
@@ -384,18 +473,23 @@ func (o *ProbeOptions) updateContainer(container *corev1.Container) {
 			container.ReadinessProbe = &corev1.Probe{}
 		}
 		o.updateProbe(container.ReadinessProbe)
+		updateProbeSettings(container.ReadinessProbe, o.ReadinessSettings)
 	}
 	if o.Liveness {
 		if container.LivenessProbe == nil {
 			container.LivenessProbe = &corev1.Probe{}
 		}
 		o.updateProbe(container.LivenessProbe)
+		o.updateTerminationGracePeriod(container.LivenessProbe)
+		updateProbeSettings(container.LivenessProbe, o.LivenessSettings)
 	}
 	if o.Startup {
 		if container.StartupProbe == nil {
 			container.StartupProbe = &corev1.Probe{}
 		}
 		o.updateProbe(container.StartupProbe)
+		o.updateTerminationGracePeriod(container.StartupProbe)
+		updateProbeSettings(container.StartupProbe, o.StartupSettings)
 	}
 }
 
@@ -408,6 +502,8 @@ func (o *ProbeOptions) updateProbe(probe *corev1.Probe) {
 		probe.ProbeHandler = corev1.ProbeHandler{HTTPGet: o.HTTPGetAction}
 	case len(o.OpenTCPSocket) > 0:
 		probe.ProbeHandler = corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intOrString(o.OpenTCPSocket)}}
+	case o.GRPCAction != nil:
+		probe.ProbeHandler = corev1.ProbeHandler{GRPC: o.GRPCAction}
 	}
 	if o.InitialDelaySeconds != nil {
 		probe.InitialDelaySeconds = int32(*o.InitialDelaySeconds)
@@ -426,6 +522,42 @@ func (o *ProbeOptions) updateProbe(probe *corev1.Probe) {
 	}
 }
 
+// updateTerminationGracePeriod sets the termination grace period of the liveness and startup probes, which is not
+// allowed on readiness probes.
+func (o *ProbeOptions) updateTerminationGracePeriod(probe *corev1.Probe) {
+	if o.TerminationGracePeriodSeconds != nil {
+		seconds := int64(*o.TerminationGracePeriodSeconds)
+		probe.TerminationGracePeriodSeconds = &seconds
+	}
+}
+
+// probeSettingMinimums are the settings of a single probe, and their minimum values.
+var probeSettingMinimums = map[string]int{
+	"initial-delay-seconds": 0,
+	"timeout-seconds":       1,
+	"period-seconds":        1,
+	"success-threshold":     1,
+	"failure-threshold":     1,
+}
+
+// updateProbeSettings sets the timing and thresholds of the settings of a single probe.
+func updateProbeSettings(probe *corev1.Probe, settings map[string]int) {
+	for name, value := range settings {
+		switch name {
+		case "initial-delay-seconds":
+			probe.InitialDelaySeconds = int32(value)
+		case "timeout-seconds":
+			probe.TimeoutSeconds = int32(value)
+		case "period-seconds":
+			probe.PeriodSeconds = int32(value)
+		case "success-threshold":
+			probe.SuccessThreshold = int32(value)
+		case "failure-threshold":
+			probe.FailureThreshold = int32(value)
+		}
+	}
+}
+
 func intOrString(s string) intstr.IntOrString {
 	if i, err := strconv.Atoi(s); err == nil {
 		return intstr.FromInt(i)
@@ -0,0 +1,124 @@
+package set
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestProbeOptionsValidate(t *testing.T) {
+	one := 1
+	tests := []struct {
+		name string
+		o    ProbeOptions
+		err  string
+	}{
+		{
+			name: "grpc",
+			o:    ProbeOptions{Readiness: true, GRPC: "9090", GRPCAction: &corev1.GRPCAction{Port: 9090}},
+		},
+		{
+			name: "grpc and tcp",
+			o:    ProbeOptions{Readiness: true, GRPC: "9090", GRPCAction: &corev1.GRPCAction{Port: 9090}, OpenTCPSocket: "8080"},
+			err:  "you may only set one of",
+		},
+		{
+			name: "grpc port out of range",
+			o:    ProbeOptions{Readiness: true, GRPC: "70000", GRPCAction: &corev1.GRPCAction{Port: 70000}},
+			err:  "between 1 and 65535",
+		},
+		{
+			name: "grpc service without grpc",
+			o:    ProbeOptions{Readiness: true, GRPCService: "orders", OpenTCPSocket: "8080"},
+			err:  "--grpc-service may only be used with --grpc",
+		},
+		{
+			name: "http headers without url",
+			o:    ProbeOptions{Liveness: true, HTTPHeaders: []string{"Host:example.com"}, OpenTCPSocket: "8080"},
+			err:  "--http-header may only be used with --get-url",
+		},
+		{
+			name: "termination grace period on readiness",
+			o:    ProbeOptions{Readiness: true, TerminationGracePeriodSeconds: &one},
+			err:  "may not be set on readiness probes",
+		},
+		{
+			name: "settings",
+			o:    ProbeOptions{Liveness: true, Startup: true, StartupSettings: map[string]int{"failure-threshold": 30}},
+		},
+		{
+			name: "unknown setting",
+			o:    ProbeOptions{Startup: true, StartupSettings: map[string]int{"failures": 30}},
+			err:  "unknown setting",
+		},
+		{
+			name: "invalid setting",
+			o:    ProbeOptions{Liveness: true, LivenessSettings: map[string]int{"period-seconds": 0}},
+			err:  "period-seconds may not be less than 1",
+		},
+		{
+			name: "settings of another probe",
+			o:    ProbeOptions{Liveness: true, ReadinessSettings: map[string]int{"period-seconds": 5}},
+			err:  "may only be used when setting the probe",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.o.Validate()
+			if len(test.err) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("expected error %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestProbeOptionsUpdateContainer(t *testing.T) {
+	period, grace := 10, 60
+	service := "orders"
+	o := &ProbeOptions{
+		Liveness:                      true,
+		Readiness:                     true,
+		Startup:                       true,
+		GRPCAction:                    &corev1.GRPCAction{Port: 9090, Service: &service},
+		PeriodSeconds:                 &period,
+		TerminationGracePeriodSeconds: &grace,
+		StartupSettings:               map[string]int{"failure-threshold": 30},
+		ReadinessSettings:             map[string]int{"period-seconds": 5},
+	}
+	container := &corev1.Container{}
+	o.updateContainer(container)
+
+	for name, probe := range map[string]*corev1.Probe{"liveness": container.LivenessProbe, "readiness": container.ReadinessProbe, "startup": container.StartupProbe} {
+		if probe == nil || probe.GRPC == nil || probe.GRPC.Port != 9090 || *probe.GRPC.Service != "orders" {
+			t.Errorf("expected a gRPC %s probe, got %#v", name, probe)
+		}
+	}
+	if container.LivenessProbe.PeriodSeconds != 10 || container.StartupProbe.PeriodSeconds != 10 || container.ReadinessProbe.PeriodSeconds != 5 {
+		t.Errorf("expected the readiness settings to override the period of all the probes")
+	}
+	if container.StartupProbe.FailureThreshold != 30 || container.LivenessProbe.FailureThreshold != 0 {
+		t.Errorf("expected the failure threshold to only be set on the startup probe")
+	}
+	if container.ReadinessProbe.TerminationGracePeriodSeconds != nil || *container.LivenessProbe.TerminationGracePeriodSeconds != 60 {
+		t.Errorf("expected the termination grace period to only be set on the liveness and startup probes")
+	}
+
+	o = &ProbeOptions{
+		Liveness: true,
+		HTTPGetAction: &corev1.HTTPGetAction{
+			Path:        "/healthz",
+			HTTPHeaders: []corev1.HTTPHeader{{Name: "Host", Value: "example.com"}},
+		},
+	}
+	o.updateContainer(container)
+	if probe := container.LivenessProbe; probe.HTTPGet == nil || probe.GRPC != nil || len(probe.HTTPGet.HTTPHeaders) != 1 || probe.PeriodSeconds != 10 {
+		t.Errorf("expected the handler of the liveness probe to be replaced, got %#v", probe)
+	}
+}
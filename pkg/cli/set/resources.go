@@ -0,0 +1,450 @@
+package set
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	kresource "k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/dynamic"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+	"k8s.io/kubectl/pkg/scheme"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/openshift/oc/pkg/helpers/originpolymorphichelpers"
+)
+
+// verticalPodAutoscalersResource is the resource of the VerticalPodAutoscalers, whose API isn't vendored.
+var verticalPodAutoscalersResource = schema.GroupVersionResource{Group: "autoscaling.k8s.io", Version: "v1", Resource: "verticalpodautoscalers"}
+
+// ResourcesRecommendOptions recommends the resource requests and limits of the containers of workloads from the
+// recommendations of their VerticalPodAutoscaler, or from the usage of their pods, and optionally applies them.
+type ResourcesRecommendOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+	Printer    printers.ResourcePrinter
+
+	Apply    bool
+	Headroom int
+
+	Args              []string
+	All               bool
+	Selector          string
+	ContainerSelector string
+	DryRunStrategy    kcmdutil.DryRunStrategy
+	FieldManager      string
+	OutputFormat      string
+
+	Namespace         string
+	ExplicitNamespace bool
+
+	Builder                func() *kresource.Builder
+	UpdatePodSpecForObject polymorphichelpers.UpdatePodSpecForObjectFunc
+	DynamicClient          dynamic.Interface
+	MetricsClient          metricsclient.Interface
+
+	genericclioptions.IOStreams
+	kresource.FilenameOptions
+}
+
+// containerRecommendation is the recommended resources of a container of a workload.
+type containerRecommendation struct {
+	workload    string
+	container   string
+	source      string
+	current     corev1.ResourceRequirements
+	recommended corev1.ResourceRequirements
+}
+
+func NewResourcesRecommendOptions(streams genericclioptions.IOStreams) *ResourcesRecommendOptions {
+	return &ResourcesRecommendOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("resource requirements updated").WithTypeSetter(scheme.Scheme),
+		IOStreams:  streams,
+		Headroom:   15,
+	}
+}
+
+// AddFlags adds the recommendation flags to the kubectl set resources command.
+func (o *ResourcesRecommendOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.Apply, "apply-recommendation", o.Apply, "If true, set the recommended resource requests and limits on the containers")
+	cmd.Flags().IntVar(&o.Headroom, "headroom", o.Headroom, "The percentage added to the observed usage of the containers to recommend their requests when the workload has no VerticalPodAutoscaler")
+}
+
+func (o *ResourcesRecommendOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	// the flags are bound by the upstream command
+	o.Args = args
+	o.All = kcmdutil.GetFlagBool(cmd, "all")
+	o.Selector = kcmdutil.GetFlagString(cmd, "selector")
+	o.ContainerSelector = kcmdutil.GetFlagString(cmd, "containers")
+	o.FilenameOptions.Filenames = kcmdutil.GetFlagStringSlice(cmd, "filename")
+	o.FilenameOptions.Recursive = kcmdutil.GetFlagBool(cmd, "recursive")
+	o.FilenameOptions.Kustomize = kcmdutil.GetFlagString(cmd, "kustomize")
+	o.FieldManager = kcmdutil.GetFieldManagerFlag(cmd)
+	o.OutputFormat = kcmdutil.GetFlagString(cmd, "output")
+	o.PrintFlags.OutputFormat = &o.OutputFormat
+
+	if kcmdutil.GetFlagBool(cmd, "local") {
+		return fmt.Errorf("--recommend requires the usage of the containers and can't be used with --local")
+	}
+	if len(kcmdutil.GetFlagString(cmd, "limits")) > 0 || len(kcmdutil.GetFlagString(cmd, "requests")) > 0 {
+		return fmt.Errorf("--recommend can't be used with --limits or --requests")
+	}
+
+	var err error
+	o.Namespace, o.ExplicitNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	kcmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	o.Printer, err = o.PrintFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+
+	o.Builder = f.NewBuilder
+	o.UpdatePodSpecForObject = polymorphichelpers.UpdatePodSpecForObjectFn
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.MetricsClient, err = metricsclient.NewForConfig(config)
+	return err
+}
+
+func (o *ResourcesRecommendOptions) Validate() error {
+	if len(o.Args) == 0 && len(o.Selector) == 0 && !o.All && kcmdutil.IsFilenameSliceEmpty(o.FilenameOptions.Filenames, o.FilenameOptions.Kustomize) {
+		return fmt.Errorf("one or more resources must be specified as <resource> <name> or <resource>/<name>")
+	}
+	if o.Headroom < 0 {
+		return fmt.Errorf("--headroom must be a positive percentage")
+	}
+	return nil
+}
+
+// Run prints the recommended resources of the containers, and sets them with --apply-recommendation.
+func (o *ResourcesRecommendOptions) Run() error {
+	infos, err := o.Builder().
+		WithScheme(scheme.Scheme, scheme.Scheme.PrioritizedVersionsAllGroups()...).
+		ContinueOnError().
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		FilenameParam(o.ExplicitNamespace, &o.FilenameOptions).
+		LabelSelectorParam(o.Selector).
+		ResourceTypeOrNameArgs(o.All, o.Args...).
+		Latest().
+		Flatten().
+		Do().
+		Infos()
+	if err != nil {
+		return err
+	}
+
+	vpas := map[string][]unstructured.Unstructured{}
+	allErrs := []error{}
+	recommendations := []containerRecommendation{}
+	patches := map[*kresource.Info][]containerRecommendation{}
+	for _, info := range infos {
+		if _, ok := vpas[info.Namespace]; !ok {
+			vpas[info.Namespace] = o.verticalPodAutoscalers(info.Namespace)
+		}
+		recommended, err := o.recommend(info, vpas[info.Namespace])
+		if err != nil {
+			allErrs = append(allErrs, fmt.Errorf("%s: %v", getObjectName(info), err))
+			continue
+		}
+		recommendations = append(recommendations, recommended...)
+		if len(recommended) > 0 {
+			patches[info] = recommended
+		}
+	}
+
+	if len(o.OutputFormat) == 0 || !o.Apply {
+		printRecommendations(o.Out, recommendations)
+	}
+	if o.Apply {
+		for _, info := range infos {
+			if recommended, ok := patches[info]; ok {
+				if err := o.apply(info, recommended); err != nil {
+					allErrs = append(allErrs, fmt.Errorf("%s: %v", getObjectName(info), err))
+				}
+			}
+		}
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// verticalPodAutoscalers returns the VerticalPodAutoscalers of the namespace, or none if their API isn't installed.
+func (o *ResourcesRecommendOptions) verticalPodAutoscalers(namespace string) []unstructured.Unstructured {
+	list, err := o.DynamicClient.Resource(verticalPodAutoscalersResource).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		if !kerrors.IsNotFound(err) {
+			fmt.Fprintf(o.ErrOut, "warning: unable to list the VerticalPodAutoscalers, the recommendations are based on the usage of the pods: %v\n", err)
+		}
+		return nil
+	}
+	return list.Items
+}
+
+// recommend returns the recommended resources of the selected containers of the workload.
+func (o *ResourcesRecommendOptions) recommend(info *kresource.Info, vpas []unstructured.Unstructured) ([]containerRecommendation, error) {
+	name := getObjectName(info)
+	var containers []corev1.Container
+	ok, err := o.UpdatePodSpecForObject(info.Object.DeepCopyObject(), func(spec *corev1.PodSpec) error {
+		containers = spec.Containers
+		return nil
+	})
+	if !ok {
+		return nil, fmt.Errorf("is not a pod or does not have a pod template")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vpa := vpaForObject(vpas, info.Mapping.GroupVersionKind.Kind, info.Name)
+	var usage map[string]corev1.ResourceList
+	var pods int
+	if vpa == nil {
+		namespace, selector, err := originpolymorphichelpers.SelectorsForObject(info.Object)
+		if err != nil {
+			return nil, err
+		}
+		metrics, err := o.MetricsClient.MetricsV1beta1().PodMetricses(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the usage of the pods: %v", err)
+		}
+		usage, pods = averageUsage(metrics.Items), len(metrics.Items)
+	}
+
+	recommendations := []containerRecommendation{}
+	selected, _ := selectContainers(containers, o.ContainerSelector)
+	for _, c := range selected {
+		var requests corev1.ResourceList
+		var source string
+		if vpa != nil {
+			requests = vpaTarget(vpa, c.Name)
+			source = "verticalpodautoscaler/" + vpa.GetName()
+		} else if containerUsage, ok := usage[c.Name]; ok {
+			requests = withHeadroom(containerUsage, o.Headroom)
+			source = fmt.Sprintf("usage of %d pods", pods)
+		}
+		if len(requests) == 0 {
+			fmt.Fprintf(o.ErrOut, "warning: no recommendation for container %s of %s\n", c.Name, name)
+			continue
+		}
+		recommendations = append(recommendations, containerRecommendation{
+			workload:    name,
+			container:   c.Name,
+			source:      source,
+			current:     c.Resources,
+			recommended: recommendedResources(c.Resources, requests),
+		})
+	}
+	return recommendations, nil
+}
+
+// apply sets the recommended resources on the containers of the workload.
+func (o *ResourcesRecommendOptions) apply(info *kresource.Info, recommendations []containerRecommendation) error {
+	oldData, err := json.Marshal(info.Object)
+	if err != nil {
+		return err
+	}
+	_, err = o.UpdatePodSpecForObject(info.Object, func(spec *corev1.PodSpec) error {
+		for i := range spec.Containers {
+			for _, r := range recommendations {
+				if r.container == spec.Containers[i].Name {
+					spec.Containers[i].Resources = r.recommended
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if o.DryRunStrategy == kcmdutil.DryRunClient {
+		return o.Printer.PrintObj(info.Object, o.Out)
+	}
+
+	newData, err := json.Marshal(info.Object)
+	if err != nil {
+		return err
+	}
+	patch, err := strategicpatch.CreateTwoWayMergePatch(oldData, newData, info.Object)
+	if err != nil {
+		return err
+	}
+	actual, err := kresource.NewHelper(info.Client, info.Mapping).
+		DryRun(o.DryRunStrategy == kcmdutil.DryRunServer).
+		WithFieldManager(o.FieldManager).
+		Patch(info.Namespace, info.Name, types.StrategicMergePatchType, patch, &metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to set the resources: %v", err)
+	}
+	return o.Printer.PrintObj(actual, o.Out)
+}
+
+// vpaForObject returns the VerticalPodAutoscaler targeting the object, or nil.
+func vpaForObject(vpas []unstructured.Unstructured, kind, name string) *unstructured.Unstructured {
+	for i := range vpas {
+		targetKind, _, _ := unstructured.NestedString(vpas[i].Object, "spec", "targetRef", "kind")
+		targetName, _, _ := unstructured.NestedString(vpas[i].Object, "spec", "targetRef", "name")
+		if targetKind == kind && targetName == name {
+			return &vpas[i]
+		}
+	}
+	return nil
+}
+
+// vpaTarget returns the target resources recommended by the VerticalPodAutoscaler for the container.
+func vpaTarget(vpa *unstructured.Unstructured, container string) corev1.ResourceList {
+	recommendations, _, _ := unstructured.NestedSlice(vpa.Object, "status", "recommendation", "containerRecommendations")
+	for _, r := range recommendations {
+		recommendation, ok := r.(map[string]interface{})
+		if !ok || recommendation["containerName"] != container {
+			continue
+		}
+		target, _, _ := unstructured.NestedStringMap(recommendation, "target")
+		requests := corev1.ResourceList{}
+		for name, value := range target {
+			if quantity, err := resource.ParseQuantity(value); err == nil {
+				requests[corev1.ResourceName(name)] = quantity
+			}
+		}
+		return requests
+	}
+	return nil
+}
+
+// averageUsage returns the average CPU and memory usage of each container of the pods.
+func averageUsage(pods []metricsv1beta1.PodMetrics) map[string]corev1.ResourceList {
+	sums := map[string]map[corev1.ResourceName]int64{}
+	counts := map[string]int64{}
+	for _, pod := range pods {
+		for _, c := range pod.Containers {
+			if _, ok := sums[c.Name]; !ok {
+				sums[c.Name] = map[corev1.ResourceName]int64{}
+			}
+			sums[c.Name][corev1.ResourceCPU] += c.Usage.Cpu().MilliValue()
+			sums[c.Name][corev1.ResourceMemory] += c.Usage.Memory().Value()
+			counts[c.Name]++
+		}
+	}
+	usage := map[string]corev1.ResourceList{}
+	for name, sum := range sums {
+		usage[name] = corev1.ResourceList{
+			corev1.ResourceCPU:    *resource.NewMilliQuantity(sum[corev1.ResourceCPU]/counts[name], resource.DecimalSI),
+			corev1.ResourceMemory: *resource.NewQuantity(sum[corev1.ResourceMemory]/counts[name], resource.BinarySI),
+		}
+	}
+	return usage
+}
+
+// withHeadroom returns the usage increased by the headroom percentage, rounded up to the millicore and the MiB.
+func withHeadroom(usage corev1.ResourceList, headroom int) corev1.ResourceList {
+	const mebibyte = 1024 * 1024
+	factor := 1 + float64(headroom)/100
+	cpu := int64(math.Ceil(float64(usage.Cpu().MilliValue()) * factor))
+	if cpu < 1 {
+		cpu = 1
+	}
+	memory := int64(math.Ceil(float64(usage.Memory().Value())*factor/mebibyte)) * mebibyte
+	if memory < mebibyte {
+		memory = mebibyte
+	}
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+		corev1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+	}
+}
+
+// recommendedResources returns the resources with the recommended requests. The limits keep their ratio to the
+// requests, as the VerticalPodAutoscaler does, and are raised to the requests when they only were set.
+func recommendedResources(current corev1.ResourceRequirements, requests corev1.ResourceList) corev1.ResourceRequirements {
+	recommended := *current.DeepCopy()
+	if recommended.Requests == nil {
+		recommended.Requests = corev1.ResourceList{}
+	}
+	for name, request := range requests {
+		recommended.Requests[name] = request
+		limit, hasLimit := current.Limits[name]
+		if !hasLimit {
+			continue
+		}
+		if oldRequest, ok := current.Requests[name]; ok && oldRequest.MilliValue() > 0 {
+			ratio := float64(limit.MilliValue()) / float64(oldRequest.MilliValue())
+			if name == corev1.ResourceCPU {
+				recommended.Limits[name] = *resource.NewMilliQuantity(int64(math.Ceil(float64(request.MilliValue())*ratio)), request.Format)
+				continue
+			}
+			// memory is rounded up to whole bytes
+			recommended.Limits[name] = *resource.NewQuantity(int64(math.Ceil(float64(request.Value())*ratio)), resource.BinarySI)
+			continue
+		}
+		if limit.Cmp(request) < 0 {
+			recommended.Limits[name] = request
+		}
+	}
+	return recommended
+}
+
+// printRecommendations prints the current and recommended resources of the containers.
+func printRecommendations(out io.Writer, recommendations []containerRecommendation) {
+	if len(recommendations) == 0 {
+		fmt.Fprintln(out, "No recommendations")
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "WORKLOAD\tCONTAINER\tRESOURCE\tREQUEST\tLIMIT\tSOURCE")
+	for _, r := range recommendations {
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if _, ok := r.recommended.Requests[name]; !ok {
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.workload, r.container, name,
+				quantityChange(r.current.Requests, r.recommended.Requests, name),
+				quantityChange(r.current.Limits, r.recommended.Limits, name),
+				r.source)
+		}
+	}
+	w.Flush()
+}
+
+// quantityChange returns the current and recommended quantity of the resource, like 100m -> 150m.
+func quantityChange(current, recommended corev1.ResourceList, name corev1.ResourceName) string {
+	from, to := "<none>", "<none>"
+	if q, ok := current[name]; ok {
+		from = q.String()
+	}
+	if q, ok := recommended[name]; ok {
+		to = q.String()
+	}
+	if from == to {
+		return from
+	}
+	return strings.Join([]string{from, to}, " -> ")
+}
@@ -0,0 +1,129 @@
+package set
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+func resourceList(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func TestRecommendFromUsage(t *testing.T) {
+	pods := []metricsv1beta1.PodMetrics{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-1"},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "nginx", Usage: resourceList("100m", "100Mi")}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-2"},
+			Containers: []metricsv1beta1.ContainerMetrics{{Name: "nginx", Usage: resourceList("300m", "200Mi")}},
+		},
+	}
+	usage := averageUsage(pods)["nginx"]
+	if cpu, memory := usage.Cpu().String(), usage.Memory().String(); cpu != "200m" || memory != "150Mi" {
+		t.Errorf("expected an average usage of 200m and 150Mi, got %s and %s", cpu, memory)
+	}
+
+	requests := withHeadroom(usage, 20)
+	if cpu, memory := requests.Cpu().String(), requests.Memory().String(); cpu != "240m" || memory != "180Mi" {
+		t.Errorf("expected requests of 240m and 180Mi, got %s and %s", cpu, memory)
+	}
+}
+
+func TestRecommendedResources(t *testing.T) {
+	current := corev1.ResourceRequirements{
+		Requests: resourceList("100m", "128Mi"),
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("256Mi")},
+	}
+	recommended := recommendedResources(current, resourceList("250m", "256Mi"))
+	if cpu := recommended.Limits.Cpu().String(); cpu != "500m" {
+		t.Errorf("expected the CPU limit to keep its ratio to the request, got %s", cpu)
+	}
+	if memory := recommended.Limits.Memory().String(); memory != "512Mi" {
+		t.Errorf("expected the memory limit to keep its ratio to the request, got %s", memory)
+	}
+	if current.Requests.Cpu().String() != "100m" {
+		t.Errorf("expected the current resources not to be modified")
+	}
+
+	fractional := corev1.ResourceRequirements{
+		Requests: resourceList("100m", "1000Mi"),
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1200Mi")},
+	}
+	recommended = recommendedResources(fractional, resourceList("100m", "1Gi"))
+	if memory := recommended.Limits.Memory().String(); memory != "1288490189" {
+		t.Errorf("expected the memory limit to be rounded up to whole bytes, got %s", memory)
+	}
+
+	limitOnly := corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}}
+	recommended = recommendedResources(limitOnly, corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("150m")})
+	if cpu := recommended.Limits.Cpu().String(); cpu != "150m" {
+		t.Errorf("expected the CPU limit to be raised to the request, got %s", cpu)
+	}
+
+	recommended = recommendedResources(corev1.ResourceRequirements{}, resourceList("250m", "256Mi"))
+	if len(recommended.Limits) != 0 || recommended.Requests.Cpu().String() != "250m" {
+		t.Errorf("expected only requests, got %#v", recommended)
+	}
+}
+
+func TestVPARecommendation(t *testing.T) {
+	vpas := []unstructured.Unstructured{{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "nginx-vpa"},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "nginx"},
+		},
+		"status": map[string]interface{}{
+			"recommendation": map[string]interface{}{
+				"containerRecommendations": []interface{}{
+					map[string]interface{}{
+						"containerName": "nginx",
+						"target":        map[string]interface{}{"cpu": "25m", "memory": "262144k"},
+					},
+				},
+			},
+		},
+	}}}
+
+	if vpa := vpaForObject(vpas, "StatefulSet", "nginx"); vpa != nil {
+		t.Errorf("expected no VerticalPodAutoscaler for another kind")
+	}
+	vpa := vpaForObject(vpas, "Deployment", "nginx")
+	if vpa == nil {
+		t.Fatalf("expected the VerticalPodAutoscaler of the deployment")
+	}
+	target := vpaTarget(vpa, "nginx")
+	if cpu, memory := target.Cpu().String(), target.Memory().String(); cpu != "25m" || memory != "262144k" {
+		t.Errorf("unexpected target %s and %s", cpu, memory)
+	}
+	if target := vpaTarget(vpa, "sidecar"); target != nil {
+		t.Errorf("expected no target for another container, got %v", target)
+	}
+}
+
+func TestPrintRecommendations(t *testing.T) {
+	out := &bytes.Buffer{}
+	printRecommendations(out, []containerRecommendation{{
+		workload:    "deployments/nginx",
+		container:   "nginx",
+		source:      "verticalpodautoscaler/nginx-vpa",
+		current:     corev1.ResourceRequirements{Requests: resourceList("100m", "128Mi")},
+		recommended: corev1.ResourceRequirements{Requests: resourceList("25m", "128Mi")},
+	}})
+	for _, expected := range []string{"WORKLOAD", "100m -> 25m", "128Mi ", "<none>", "verticalpodautoscaler/nginx-vpa"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in:\n%s", expected, out.String())
+		}
+	}
+}
@@ -83,7 +83,12 @@ func NewCmdSet(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.C
 
 var (
 	setImageLong = ktemplates.LongDesc(`
-Update existing container image(s) of resources.`)
+Update existing container image(s) of resources.
+
+With --pin-digest, the images are set by the digest their tag currently points to, resolved with
+the image stream tag or from the registry, so that later pushes to the tag don't change what is
+deployed. Image stream tags with a local reference policy are always set by tag. With --wait, the command waits for the rollouts of the updated resources to complete and
+fails if any of them fails or doesn't complete before the --timeout.`)
 
 	setImageExample = ktemplates.Examples(`
 	  # Set a deployment configs's nginx container image to 'nginx:1.9.1', and its busybox container image to 'busybox'.
@@ -99,16 +104,31 @@ Update existing container image(s) of resources.`)
 	  oc set image daemonset abc *=nginx:1.9.1
 
 	  # Print result (in yaml format) of updating nginx container image from local file, without hitting the server
-	  oc set image -f path/to/file.yaml nginx=nginx:1.9.1 --local -o yaml`)
+	  oc set image -f path/to/file.yaml nginx=nginx:1.9.1 --local -o yaml
+
+	  # Set the nginx container image of the deployment nginx to the current digest of 'nginx:1.9.1', and wait for the rollout
+	  oc set image deployment/nginx nginx=nginx:1.9.1 --pin-digest --wait --timeout=5m`)
 )
 
 // NewCmdImage is a wrapper for the Kubernetes CLI set image command
 func NewCmdImage(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &ImageOptions{}
+
 	cmd := set.NewCmdImage(f, streams)
 	cmd.Long = setImageLong
 	cmd.Example = setImageExample
 	cmd.Flags().String("source", "docker", "The image source type; valid types are 'imagestreamtag', 'istag', 'imagestreamimage', 'isimage', and 'docker'")
-	set.ImageResolver = resolveImageFactory(f, cmd)
+	o.AddFlags(cmd)
+	set.ImageResolver = resolveImageFactory(f, cmd, o)
+
+	delegate := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		kcmdutil.CheckErr(o.Validate(cmd))
+		delegate(cmd, args)
+		if o.Wait {
+			kcmdutil.CheckErr(o.waitForRollouts(f, cmd, args, streams))
+		}
+	}
 
 	return cmd
 }
@@ -120,7 +140,12 @@ Specify compute resource requirements (cpu, memory) for any resource that define
 For each compute resource, if a limit is specified and a request is omitted, the request will default to the limit.
 
 Possible resources include (case insensitive):
-"ReplicationController", "Deployment", "DaemonSet", "Job", "ReplicaSet", "DeploymentConfigs"`)
+"ReplicationController", "Deployment", "DaemonSet", "Job", "ReplicaSet", "DeploymentConfigs"
+
+With --recommend, the requests of the containers are recommended from the target of the VerticalPodAutoscaler of
+the resource if there is one, or from the average usage of its pods reported by the metrics API plus a --headroom
+percentage. The limits keep their ratio to the requests. The recommendations are printed, and set on the containers
+with --apply-recommendation.`)
 
 	setResourcesExample = ktemplates.Examples(`
 # Set a deployments nginx container CPU limits to "200m and memory to 512Mi"
@@ -133,14 +158,35 @@ oc set resources deployment nginx --limits=cpu=200m,memory=512Mi --requests=cpu=
 oc set resources deployment nginx --limits=cpu=0,memory=0 --requests=cpu=0,memory=0
 
 # Print the result (in YAML format) of updating nginx container limits locally, without hitting the server
-oc set resources -f path/to/file.yaml --limits=cpu=200m,memory=512Mi --local -o yaml`)
+oc set resources -f path/to/file.yaml --limits=cpu=200m,memory=512Mi --local -o yaml
+
+# Print the recommended resources of the containers of all the deployments
+oc set resources deployments --all --recommend
+
+# Set the recommended resources on the containers of nginx, with 30% of headroom over their usage
+oc set resources deployment nginx --recommend --apply-recommendation --headroom=30`)
 )
 
 // NewCmdResources is a wrapper for the Kubernetes CLI set resources command
 func NewCmdResources(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewResourcesRecommendOptions(streams)
+	recommend := false
+
 	cmd := set.NewCmdResources(f, streams)
 	cmd.Long = setResourcesLong
 	cmd.Example = setResourcesExample
+	delegate := cmd.Run
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		if !recommend && !o.Apply {
+			delegate(cmd, args)
+			return
+		}
+		kcmdutil.CheckErr(o.Complete(f, cmd, args))
+		kcmdutil.CheckErr(o.Validate())
+		kcmdutil.CheckErr(o.Run())
+	}
+	cmd.Flags().BoolVar(&recommend, "recommend", recommend, "If true, print the recommended resource requests and limits of the containers from their VerticalPodAutoscaler or their usage")
+	o.AddFlags(cmd)
 
 	return cmd
 }
@@ -216,8 +262,11 @@ func NewCmdSubject(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cob
 	return cmd
 }
 
-func resolveImageFactory(f kcmdutil.Factory, cmd *cobra.Command) set.ImageResolverFunc {
+func resolveImageFactory(f kcmdutil.Factory, cmd *cobra.Command, o *ImageOptions) set.ImageResolverFunc {
 	resolveImageFn := func(in string) (string, error) {
+		if o.PinDigest {
+			return o.pinDigest(in)
+		}
 		return in, nil
 	}
 	return func(image string) (string, error) {
@@ -241,6 +290,8 @@ func resolveImageFactory(f kcmdutil.Factory, cmd *cobra.Command) set.ImageResolv
 			return "", err
 		}
 
+		// image stream tags are resolved to the digests of their images, unless their images are referenced
+		// by tag with a local reference policy, so the resolved reference is used as is
 		return resolveImagePullSpec(imageClient.ImageV1(), source, image, namespace)
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -64,6 +65,12 @@ var (
 			if your claim hasn't been bound, your pods will not start.
 		* secret (mounted secret): Secret volumes mount a named secret to the provided
 		  directory.
+		* projected (projected volume): Project the keys of secrets and config maps, and
+		  a service account token into the same directory.
+		* csi (CSI inline volume): A volume provided by a CSI driver for the lifetime of
+		  the pod.
+		* ephemeral (generic ephemeral volume): A persistent volume claim created from
+		  --claim-size, --claim-class and --claim-mode with the pod and deleted with it.
 
 		For descriptions on other volume types, see https://docs.openshift.com`)
 
@@ -91,6 +98,18 @@ var (
 		# (and by removing the volume "v1" if no other containers have volume mounts that reference it)
 		oc set volume dc/myapp --remove --name=v1 --containers=c1
 
+		# Project the key 'tls.crt' of secret 'certs', config map 'settings' and a service
+		# account token for the audience 'vault' under /etc/app
+		oc set volume dc/myapp --add -t projected -m /etc/app --projected-secret=certs:tls.crt=certs/tls.crt \
+		  --projected-configmap=settings --token-path=token --token-audience=vault
+
+		# Add a CSI inline volume provided by the secrets store driver
+		oc set volume dc/myapp --add -t csi -m /mnt/secrets --csi-driver=secrets-store.csi.k8s.io \
+		  --csi-attribute=secretProviderClass=vault --read-only
+
+		# Add a 10G generic ephemeral volume created with each pod from the storage class 'fast'
+		oc set volume dc/myapp --add -t ephemeral -m /scratch --claim-size=10G --claim-class=fast
+
 		# Add new volume based on a more complex volume source (AWS EBS, GCE PD,
 		# Ceph, Gluster, NFS, ISCSI, ...)
 		oc set volume dc/myapp --add -m /data --source=<json-string>
@@ -151,6 +170,17 @@ type AddVolumeOptions struct {
 	ClaimMode   string
 	ClaimClass  string
 
+	ProjectedSecrets    []string
+	ProjectedConfigMaps []string
+	TokenPath           string
+	TokenAudience       string
+	TokenExpiration     time.Duration
+
+	CSIDriver            string
+	CSIFSType            string
+	CSIAttributes        []string
+	CSINodePublishSecret string
+
 	TypeChanged  bool
 	ClassChanged bool
 }
@@ -192,7 +222,7 @@ func NewCmdVolume(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	cmd.Flags().StringVarP(&o.Containers, "containers", "c", o.Containers, "The names of containers in the selected pod templates to change - may use wildcards")
 	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, confirm that you really want to remove multiple volumes")
 
-	cmd.Flags().StringVarP(&o.AddOpts.Type, "type", "t", o.AddOpts.Type, "Type of the volume source for add operation. Supported options: emptyDir, hostPath, secret, configmap, persistentVolumeClaim")
+	cmd.Flags().StringVarP(&o.AddOpts.Type, "type", "t", o.AddOpts.Type, "Type of the volume source for add operation. Supported options: emptyDir, hostPath, secret, configmap, persistentVolumeClaim, projected, csi, ephemeral")
 	cmd.Flags().StringVarP(&o.AddOpts.MountPath, "mount-path", "m", o.AddOpts.MountPath, "Mount path inside the container. Optional param for --add or --remove")
 	cmd.Flags().StringVar(&o.AddOpts.SubPath, "sub-path", o.AddOpts.SubPath, "Path within the local volume from which the container's volume should be mounted. Optional param for --add or --remove")
 	cmd.Flags().StringVar(&o.AddOpts.DefaultMode, "default-mode", o.AddOpts.DefaultMode, "The default mode bits to create files with. Can be between 0000 and 0777. Defaults to 0644.")
@@ -205,6 +235,15 @@ func NewCmdVolume(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	cmd.Flags().StringVar(&o.AddOpts.ClaimClass, "claim-class", o.AddOpts.ClaimClass, "StorageClass to use for the persistent volume claim")
 	cmd.Flags().StringVar(&o.AddOpts.ClaimSize, "claim-size", o.AddOpts.ClaimSize, "If specified along with a persistent volume type, create a new claim with the given size in bytes. Accepts SI notation: 10, 10G, 10Gi")
 	cmd.Flags().StringVar(&o.AddOpts.ClaimMode, "claim-mode", o.AddOpts.ClaimMode, "Set the access mode of the claim to be created. Valid values are ReadWriteOnce (rwo), ReadWriteMany (rwm), or ReadOnlyMany (rom)")
+	cmd.Flags().StringArrayVar(&o.AddOpts.ProjectedSecrets, "projected-secret", o.AddOpts.ProjectedSecrets, "Secret to project for projected volume type, as NAME or NAME:KEY=PATH,... to only project some of its keys. May be repeated")
+	cmd.Flags().StringArrayVar(&o.AddOpts.ProjectedConfigMaps, "projected-configmap", o.AddOpts.ProjectedConfigMaps, "Config map to project for projected volume type, as NAME or NAME:KEY=PATH,... to only project some of its keys. May be repeated")
+	cmd.Flags().StringVar(&o.AddOpts.TokenPath, "token-path", o.AddOpts.TokenPath, "Path relative to the mount point of the service account token to project for projected volume type")
+	cmd.Flags().StringVar(&o.AddOpts.TokenAudience, "token-audience", o.AddOpts.TokenAudience, "Intended audience of the projected service account token. Defaults to the audience of the API server")
+	cmd.Flags().DurationVar(&o.AddOpts.TokenExpiration, "token-expiration", o.AddOpts.TokenExpiration, "Requested validity of the projected service account token, at least 10m. Defaults to 1h")
+	cmd.Flags().StringVar(&o.AddOpts.CSIDriver, "csi-driver", o.AddOpts.CSIDriver, "Name of the CSI driver. Must be provided for csi volume type")
+	cmd.Flags().StringVar(&o.AddOpts.CSIFSType, "csi-fs-type", o.AddOpts.CSIFSType, "Filesystem type to mount for csi volume type, passed to the driver")
+	cmd.Flags().StringArrayVar(&o.AddOpts.CSIAttributes, "csi-attribute", o.AddOpts.CSIAttributes, "Driver specific attribute as KEY=VALUE for csi volume type. May be repeated")
+	cmd.Flags().StringVar(&o.AddOpts.CSINodePublishSecret, "csi-node-publish-secret", o.AddOpts.CSINodePublishSecret, "Name of the secret passed to the CSI driver when publishing the volume")
 	cmd.Flags().StringVar(&o.AddOpts.Source, "source", o.AddOpts.Source, "Details of volume source as json string. This can be used if the required volume type is not supported by --type option. (e.g.: '{\"nfs\": {\"path\": \"/tmp\",\"server\":\"172.17.0.2\"}}')")
 
 	o.PrintFlags.AddFlags(cmd)
@@ -240,6 +279,9 @@ func (o *VolumeOptions) Validate() error {
 		len(o.AddOpts.ConfigMapName) > 0 || len(o.AddOpts.ClaimName) > 0 || len(o.AddOpts.DefaultMode) > 0 ||
 		o.AddOpts.Overwrite {
 		return errors.New("--type|--path|--configmap-name|--secret-name|--claim-name|--source|--default-mode|--overwrite are only valid for --add operation")
+	} else if o.AddOpts.hasProjectedSources() || len(o.AddOpts.CSIDriver) > 0 || len(o.AddOpts.CSIFSType) > 0 ||
+		len(o.AddOpts.CSIAttributes) > 0 || len(o.AddOpts.CSINodePublishSecret) > 0 {
+		return errors.New("--projected-secret|--projected-configmap|--token-path|--csi-driver|--csi-attribute are only valid for --add operation")
 	}
 	// Removing all volumes for the resource type needs confirmation
 	if o.Remove && len(o.Name) == 0 && !o.Confirm {
@@ -284,13 +326,39 @@ func (a *AddVolumeOptions) Validate() error {
 			if len(a.ClaimName) == 0 && len(a.ClaimSize) == 0 {
 				return errors.New("must provide --claim-name or --claim-size (to create a new claim) for --type=pvc")
 			}
+		case "projected":
+			if err := a.validateProjected(); err != nil {
+				return err
+			}
+		case "csi":
+			if len(a.CSIDriver) == 0 {
+				return errors.New("must provide --csi-driver for --type=csi")
+			}
+			if _, err := parseCSIAttributes(a.CSIAttributes); err != nil {
+				return err
+			}
+		case "ephemeral":
+			if len(a.ClaimSize) == 0 {
+				return errors.New("must provide --claim-size for --type=ephemeral")
+			}
+			if len(a.ClaimName) > 0 {
+				return errors.New("--claim-name may not be used with --type=ephemeral, the claim is named after the pod and the volume")
+			}
 		default:
-			return errors.New("invalid volume type. Supported types: emptyDir, hostPath, secret, persistentVolumeClaim")
+			return errors.New("invalid volume type. Supported types: emptyDir, hostPath, secret, configmap, persistentVolumeClaim, projected, csi, ephemeral")
 		}
 	} else if len(a.Path) > 0 || len(a.SecretName) > 0 || len(a.ClaimName) > 0 {
 		return errors.New("--path|--secret-name|--claim-name are only valid for --type option")
 	}
 
+	lowerType := strings.ToLower(a.Type)
+	if lowerType != "projected" && a.hasProjectedSources() {
+		return errors.New("--projected-secret|--projected-configmap|--token-path|--token-audience|--token-expiration are only valid for --type=projected")
+	}
+	if lowerType != "csi" && (len(a.CSIDriver) > 0 || len(a.CSIFSType) > 0 || len(a.CSIAttributes) > 0 || len(a.CSINodePublishSecret) > 0) {
+		return errors.New("--csi-driver|--csi-fs-type|--csi-attribute|--csi-node-publish-secret are only valid for --type=csi")
+	}
+
 	if len(a.Source) > 0 {
 		var source map[string]interface{}
 		err := json.Unmarshal([]byte(a.Source), &source)
@@ -309,8 +377,8 @@ func (a *AddVolumeOptions) Validate() error {
 	}
 	if len(a.ClaimClass) > 0 {
 		selectedLowerType := strings.ToLower(a.Type)
-		if selectedLowerType != "persistentvolumeclaim" && selectedLowerType != "pvc" {
-			return errors.New("must provide --type as persistentVolumeClaim")
+		if selectedLowerType != "persistentvolumeclaim" && selectedLowerType != "pvc" && selectedLowerType != "ephemeral" {
+			return errors.New("must provide --type as persistentVolumeClaim or ephemeral")
 		}
 		if len(a.ClaimSize) == 0 {
 			return errors.New("must provide --claim-size to create new pvc with claim-class")
@@ -398,17 +466,26 @@ func (a *AddVolumeOptions) Complete() error {
 		case len(a.Path) > 0:
 			a.Type = "hostpath"
 			a.TypeChanged = true
+		case a.hasProjectedSources():
+			a.Type = "projected"
+			a.TypeChanged = true
+		case len(a.CSIDriver) > 0:
+			a.Type = "csi"
+			a.TypeChanged = true
 		default:
 			a.Type = "emptydir"
 		}
 	}
-	if a.Type == "configmap" || a.Type == "secret" {
+	switch strings.ToLower(a.Type) {
+	case "configmap", "secret":
 		if len(a.DefaultMode) == 0 {
 			a.DefaultMode = "644"
 		}
-	} else {
+	case "projected":
+		// the mode of the projected files is left to the server unless requested
+	default:
 		if len(a.DefaultMode) != 0 {
-			return errors.New("--default-mode is only available for secrets and configmaps")
+			return errors.New("--default-mode is only available for secrets, configmaps and projected volumes")
 		}
 	}
 
@@ -417,9 +494,12 @@ func (a *AddVolumeOptions) Complete() error {
 		a.Type = ""
 	}
 	if len(a.ClaimSize) > 0 {
-		a.CreateClaim = true
-		if len(a.ClaimName) == 0 {
-			a.ClaimName = names.SimpleNameGenerator.GenerateName("pvc-")
+		// the claims of ephemeral volumes are created with the pods from the claim template
+		if strings.ToLower(a.Type) != "ephemeral" {
+			a.CreateClaim = true
+			if len(a.ClaimName) == 0 {
+				a.ClaimName = names.SimpleNameGenerator.GenerateName("pvc-")
+			}
 		}
 		q, err := kresource.ParseQuantity(a.ClaimSize)
 		if err != nil {
@@ -613,12 +693,157 @@ func setVolumeSourceByType(kv *corev1.Volume, opts *AddVolumeOptions) error {
 		kv.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
 			ClaimName: opts.ClaimName,
 		}
+	case "projected":
+		projected, err := opts.projectedVolumeSource()
+		if err != nil {
+			return err
+		}
+		kv.Projected = projected
+	case "csi":
+		attributes, err := parseCSIAttributes(opts.CSIAttributes)
+		if err != nil {
+			return err
+		}
+		kv.CSI = &corev1.CSIVolumeSource{
+			Driver:           opts.CSIDriver,
+			VolumeAttributes: attributes,
+		}
+		if len(opts.CSIFSType) > 0 {
+			fsType := opts.CSIFSType
+			kv.CSI.FSType = &fsType
+		}
+		if opts.ReadOnly {
+			readOnly := true
+			kv.CSI.ReadOnly = &readOnly
+		}
+		if len(opts.CSINodePublishSecret) > 0 {
+			kv.CSI.NodePublishSecretRef = &corev1.LocalObjectReference{Name: opts.CSINodePublishSecret}
+		}
+	case "ephemeral":
+		kv.Ephemeral = &corev1.EphemeralVolumeSource{
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+				Spec: opts.claimSpec(),
+			},
+		}
 	default:
 		return fmt.Errorf("invalid volume type: %s", opts.Type)
 	}
 	return nil
 }
 
+// hasProjectedSources returns true if any of the sources of a projected volume were provided.
+func (a *AddVolumeOptions) hasProjectedSources() bool {
+	return len(a.ProjectedSecrets) > 0 || len(a.ProjectedConfigMaps) > 0 || len(a.TokenPath) > 0 ||
+		len(a.TokenAudience) > 0 || a.TokenExpiration != 0
+}
+
+func (a *AddVolumeOptions) validateProjected() error {
+	if len(a.ProjectedSecrets) == 0 && len(a.ProjectedConfigMaps) == 0 && len(a.TokenPath) == 0 {
+		return errors.New("must provide --projected-secret, --projected-configmap or --token-path for --type=projected")
+	}
+	if len(a.DefaultMode) > 0 {
+		if ok, _ := regexp.MatchString(`\b0?[0-7]{3}\b`, a.DefaultMode); !ok {
+			return errors.New("--default-mode must be between 0000 and 0777")
+		}
+	}
+	if len(a.TokenPath) == 0 && (len(a.TokenAudience) > 0 || a.TokenExpiration != 0) {
+		return errors.New("must provide --token-path to project a service account token")
+	}
+	if a.TokenExpiration != 0 && a.TokenExpiration < 10*time.Minute {
+		return errors.New("--token-expiration must be at least 10m")
+	}
+	_, err := a.projectedVolumeSource()
+	return err
+}
+
+// projectedVolumeSource returns the projected volume of the secrets, config maps and service account token
+// requested by the options.
+func (a *AddVolumeOptions) projectedVolumeSource() (*corev1.ProjectedVolumeSource, error) {
+	projected := &corev1.ProjectedVolumeSource{}
+	if len(a.DefaultMode) > 0 {
+		defaultMode, err := strconv.ParseUint(a.DefaultMode, 8, 32)
+		if err != nil {
+			return nil, err
+		}
+		defaultMode32 := int32(defaultMode)
+		projected.DefaultMode = &defaultMode32
+	}
+	for _, value := range a.ProjectedSecrets {
+		name, items, err := parseProjectedSource(value)
+		if err != nil {
+			return nil, fmt.Errorf("--projected-secret %v", err)
+		}
+		projected.Sources = append(projected.Sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Items:                items,
+			},
+		})
+	}
+	for _, value := range a.ProjectedConfigMaps {
+		name, items, err := parseProjectedSource(value)
+		if err != nil {
+			return nil, fmt.Errorf("--projected-configmap %v", err)
+		}
+		projected.Sources = append(projected.Sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Items:                items,
+			},
+		})
+	}
+	if len(a.TokenPath) > 0 {
+		token := &corev1.ServiceAccountTokenProjection{
+			Audience: a.TokenAudience,
+			Path:     a.TokenPath,
+		}
+		if a.TokenExpiration != 0 {
+			seconds := int64(a.TokenExpiration.Seconds())
+			token.ExpirationSeconds = &seconds
+		}
+		projected.Sources = append(projected.Sources, corev1.VolumeProjection{ServiceAccountToken: token})
+	}
+	return projected, nil
+}
+
+// parseProjectedSource parses a projected secret or config map given as NAME or NAME:KEY=PATH,... into its name and
+// the keys to project, all the keys being projected when none are given.
+func parseProjectedSource(value string) (string, []corev1.KeyToPath, error) {
+	parts := strings.SplitN(value, ":", 2)
+	name := parts[0]
+	if len(name) == 0 {
+		return "", nil, fmt.Errorf("%q must be of the form NAME or NAME:KEY=PATH,...", value)
+	}
+	if len(parts) == 1 {
+		return name, nil, nil
+	}
+	items := []corev1.KeyToPath{}
+	for _, item := range strings.Split(parts[1], ",") {
+		keyPath := strings.SplitN(item, "=", 2)
+		if len(keyPath) != 2 || len(keyPath[0]) == 0 || len(keyPath[1]) == 0 {
+			return "", nil, fmt.Errorf("%q must be of the form NAME or NAME:KEY=PATH,...", value)
+		}
+		items = append(items, corev1.KeyToPath{Key: keyPath[0], Path: keyPath[1]})
+	}
+	return name, items, nil
+}
+
+// parseCSIAttributes parses the KEY=VALUE attributes of a CSI volume.
+func parseCSIAttributes(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	attributes := map[string]string{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("--csi-attribute %q must be of the form KEY=VALUE", value)
+		}
+		attributes[parts[0]] = parts[1]
+	}
+	return attributes, nil
+}
+
 func (o *VolumeOptions) printVolumes(infos []*resource.Info) []error {
 	listingErrors := []error{}
 	for _, info := range infos {
@@ -634,24 +859,29 @@ func (o *VolumeOptions) printVolumes(infos []*resource.Info) []error {
 }
 
 func (a *AddVolumeOptions) createClaim() *corev1.PersistentVolumeClaim {
-	pvc := &corev1.PersistentVolumeClaim{
+	return &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: a.ClaimName,
 		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.PersistentVolumeAccessMode(a.ClaimMode)},
-			Resources: corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceName(corev1.ResourceStorage): kresource.MustParse(a.ClaimSize),
-				},
+		Spec: a.claimSpec(),
+	}
+}
+
+// claimSpec returns the spec of the claim to create, either directly or from the template of an ephemeral volume.
+func (a *AddVolumeOptions) claimSpec() corev1.PersistentVolumeClaimSpec {
+	spec := corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.PersistentVolumeAccessMode(a.ClaimMode)},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceName(corev1.ResourceStorage): kresource.MustParse(a.ClaimSize),
 			},
 		},
 	}
 	if a.ClassChanged {
 		claimClass := a.ClaimClass
-		pvc.Spec.StorageClassName = &claimClass
+		spec.StorageClassName = &claimClass
 	}
-	return pvc
+	return spec
 }
 
 func (o *VolumeOptions) setVolumeSource(kv *corev1.Volume) error {
@@ -918,6 +1148,30 @@ func describeVolumeSource(source *corev1.VolumeSource) string {
 		return fmt.Sprintf("secret/%s", source.Secret.SecretName)
 	case source.ConfigMap != nil:
 		return fmt.Sprintf("configMap/%s", source.ConfigMap.Name)
+	case source.Projected != nil:
+		projected := []string{}
+		for _, p := range source.Projected.Sources {
+			switch {
+			case p.Secret != nil:
+				projected = append(projected, fmt.Sprintf("secret/%s", p.Secret.Name))
+			case p.ConfigMap != nil:
+				projected = append(projected, fmt.Sprintf("configMap/%s", p.ConfigMap.Name))
+			case p.ServiceAccountToken != nil:
+				projected = append(projected, fmt.Sprintf("serviceAccountToken/%s", p.ServiceAccountToken.Path))
+			case p.DownwardAPI != nil:
+				projected = append(projected, "downwardAPI")
+			}
+		}
+		return fmt.Sprintf("projected %s", strings.Join(projected, ","))
+	case source.CSI != nil:
+		return fmt.Sprintf("CSI %s%s", source.CSI.Driver, sourceAccessMode(source.CSI.ReadOnly != nil && *source.CSI.ReadOnly))
+	case source.Ephemeral != nil:
+		if template := source.Ephemeral.VolumeClaimTemplate; template != nil {
+			if val, ok := template.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+				return fmt.Sprintf("ephemeral pvc %sB", val.String())
+			}
+		}
+		return "ephemeral pvc"
 	default:
 		return "unknown"
 	}
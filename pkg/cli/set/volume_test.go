@@ -3,6 +3,7 @@ package set
 import (
 	"errors"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -384,6 +385,51 @@ func TestValidateAddOptions(t *testing.T) {
 			&AddVolumeOptions{Type: "configmap", ConfigMapName: "sandbox-pv", DefaultMode: "07777"},
 			errors.New("--default-mode must be between 0000 and 0777"),
 		},
+		{
+			"creating projected volume",
+			&AddVolumeOptions{Type: "projected", ProjectedSecrets: []string{"certs:tls.crt=tls.crt"}, TokenPath: "token", TokenExpiration: time.Hour},
+			nil,
+		},
+		{
+			"creating projected volume without sources",
+			&AddVolumeOptions{Type: "projected"},
+			errors.New("must provide --projected-secret, --projected-configmap or --token-path for --type=projected"),
+		},
+		{
+			"creating projected volume with bad items",
+			&AddVolumeOptions{Type: "projected", ProjectedConfigMaps: []string{"settings:app.yaml"}},
+			errors.New(`--projected-configmap "settings:app.yaml" must be of the form NAME or NAME:KEY=PATH,...`),
+		},
+		{
+			"creating projected volume with short token expiration",
+			&AddVolumeOptions{Type: "projected", TokenPath: "token", TokenExpiration: time.Minute},
+			errors.New("--token-expiration must be at least 10m"),
+		},
+		{
+			"creating csi volume without driver",
+			&AddVolumeOptions{Type: "csi", CSIAttributes: []string{"secretProviderClass=vault"}},
+			errors.New("must provide --csi-driver for --type=csi"),
+		},
+		{
+			"creating csi volume with bad attribute",
+			&AddVolumeOptions{Type: "csi", CSIDriver: "secrets-store.csi.k8s.io", CSIAttributes: []string{"vault"}},
+			errors.New(`--csi-attribute "vault" must be of the form KEY=VALUE`),
+		},
+		{
+			"creating emptydir with csi options",
+			&AddVolumeOptions{Type: "emptyDir", CSIDriver: "secrets-store.csi.k8s.io"},
+			errors.New("--csi-driver|--csi-fs-type|--csi-attribute|--csi-node-publish-secret are only valid for --type=csi"),
+		},
+		{
+			"creating ephemeral volume with storage class",
+			&AddVolumeOptions{Type: "ephemeral", ClaimSize: "5G", ClaimClass: "fast"},
+			nil,
+		},
+		{
+			"creating ephemeral volume without size",
+			&AddVolumeOptions{Type: "ephemeral"},
+			errors.New("must provide --claim-size for --type=ephemeral"),
+		},
 	}
 
 	for _, testCase := range tests {
@@ -407,3 +453,60 @@ func TestValidateAddOptions(t *testing.T) {
 
 	}
 }
+
+func TestSetVolumeSourceByType(t *testing.T) {
+	projected := &AddVolumeOptions{
+		Type:                "projected",
+		DefaultMode:         "0440",
+		ProjectedSecrets:    []string{"certs:tls.crt=certs/tls.crt,tls.key=certs/tls.key"},
+		ProjectedConfigMaps: []string{"settings"},
+		TokenPath:           "token",
+		TokenAudience:       "vault",
+		TokenExpiration:     2 * time.Hour,
+	}
+	volume := &corev1.Volume{}
+	if err := setVolumeSourceByType(volume, projected); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sources := volume.Projected.Sources
+	if len(sources) != 3 || *volume.Projected.DefaultMode != 0440 {
+		t.Fatalf("unexpected projected volume: %#v", volume.Projected)
+	}
+	if sources[0].Secret.Name != "certs" || len(sources[0].Secret.Items) != 2 || sources[0].Secret.Items[1].Path != "certs/tls.key" {
+		t.Errorf("unexpected projected secret: %#v", sources[0].Secret)
+	}
+	if sources[1].ConfigMap.Name != "settings" || sources[1].ConfigMap.Items != nil {
+		t.Errorf("unexpected projected config map: %#v", sources[1].ConfigMap)
+	}
+	if token := sources[2].ServiceAccountToken; token.Path != "token" || token.Audience != "vault" || *token.ExpirationSeconds != 7200 {
+		t.Errorf("unexpected projected token: %#v", token)
+	}
+	if description := describeVolumeSource(&volume.VolumeSource); description != "projected secret/certs,configMap/settings,serviceAccountToken/token" {
+		t.Errorf("unexpected description %q", description)
+	}
+
+	csi := &AddVolumeOptions{Type: "csi", CSIDriver: "secrets-store.csi.k8s.io", CSIAttributes: []string{"secretProviderClass=vault"}, ReadOnly: true}
+	volume = &corev1.Volume{}
+	if err := setVolumeSourceByType(volume, csi); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if volume.CSI.Driver != "secrets-store.csi.k8s.io" || volume.CSI.VolumeAttributes["secretProviderClass"] != "vault" || !*volume.CSI.ReadOnly {
+		t.Errorf("unexpected CSI volume: %#v", volume.CSI)
+	}
+
+	ephemeral := &AddVolumeOptions{Type: "ephemeral", ClaimSize: "5G", ClaimMode: "ReadWriteOnce", ClaimClass: "fast", ClassChanged: true}
+	if err := ephemeral.Complete(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ephemeral.CreateClaim || len(ephemeral.ClaimName) > 0 {
+		t.Errorf("expected no claim to be created for an ephemeral volume")
+	}
+	volume = &corev1.Volume{}
+	if err := setVolumeSourceByType(volume, ephemeral); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec := volume.Ephemeral.VolumeClaimTemplate.Spec
+	if *spec.StorageClassName != "fast" || spec.Resources.Requests.Storage().String() != "5G" || spec.AccessModes[0] != corev1.ReadWriteOnce {
+		t.Errorf("unexpected claim template: %#v", spec)
+	}
+}
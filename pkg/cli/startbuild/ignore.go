@@ -0,0 +1,139 @@
+package startbuild
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/pkg/fileutils"
+
+	s2ifs "github.com/openshift/oc/pkg/helpers/source-to-image/fs"
+)
+
+// ignoreFiles are the files at the root of a directory listing the paths excluded from its archive, with the
+// syntax of their name. The patterns of the later files take precedence.
+var ignoreFiles = []string{".gitignore", ".dockerignore"}
+
+// ignoreFileSystem walks a directory without the paths matched by its ignore files. As with git, a path can't be
+// included again when its parent directory is excluded.
+type ignoreFileSystem struct {
+	s2ifs.FileSystem
+	matcher *fileutils.PatternMatcher
+}
+
+// newIgnoreFileSystem returns a file system ignoring the paths matched by the ignore files of the directory, and
+// the names of the ignore files found. The file system is returned unchanged without any ignore file.
+func newIgnoreFileSystem(fs s2ifs.FileSystem, dir string) (s2ifs.FileSystem, []string, error) {
+	patterns, found := []string{}, []string{}
+	for _, name := range ignoreFiles {
+		lines, err := readIgnoreFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, line := range lines {
+			if name == ".gitignore" {
+				line = gitignorePattern(line)
+			} else {
+				line = dockerignorePattern(line)
+			}
+			if len(line) > 0 {
+				patterns = append(patterns, line)
+			}
+		}
+		found = append(found, name)
+	}
+	if len(found) == 0 {
+		return fs, nil, nil
+	}
+	matcher, err := fileutils.NewPatternMatcher(patterns)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ignoreFileSystem{FileSystem: fs, matcher: matcher}, found, nil
+}
+
+// Walk walks the file tree rooted at root, skipping the ignored paths.
+func (f *ignoreFileSystem) Walk(root string, walkFn filepath.WalkFunc) error {
+	return f.FileSystem.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == root {
+			return walkFn(path, info, err)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		ignored, err := f.matcher.Matches(rel)
+		if err != nil {
+			return err
+		}
+		if ignored {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return walkFn(path, info, nil)
+	})
+}
+
+func readIgnoreFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// dockerignorePattern returns the pattern of a line of a .dockerignore file, or an empty string for comments.
+func dockerignorePattern(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "#") {
+		return ""
+	}
+	negate := strings.HasPrefix(line, "!")
+	line = strings.TrimPrefix(strings.TrimPrefix(line, "!"), "/")
+	if len(line) == 0 {
+		return ""
+	}
+	if negate {
+		return "!" + line
+	}
+	return line
+}
+
+// gitignorePattern converts a line of a .gitignore file to the syntax of .dockerignore, or returns an empty
+// string for comments. The patterns without a slash match at any depth, the others are relative to the root.
+func gitignorePattern(line string) string {
+	line = strings.TrimRight(line, " \t")
+	if len(line) == 0 || strings.HasPrefix(line, "#") {
+		return ""
+	}
+	negate := strings.HasPrefix(line, "!")
+	line = strings.TrimPrefix(line, "!")
+	// a leading backslash escapes a leading # or !
+	line = strings.TrimPrefix(line, `\`)
+	// the patterns of directories also match files, their contents are excluded anyway
+	line = strings.TrimSuffix(line, "/")
+	if len(line) == 0 {
+		return ""
+	}
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		line = "**/" + line
+	}
+	if negate {
+		return "!" + line
+	}
+	return line
+}
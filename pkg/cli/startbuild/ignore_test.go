@@ -0,0 +1,38 @@
+package startbuild
+
+import "testing"
+
+func TestGitignorePattern(t *testing.T) {
+	tests := map[string]string{
+		"# comment":      "",
+		"":               "",
+		"   ":            "",
+		"*.log":          "**/*.log",
+		"node_modules/":  "**/node_modules",
+		"/build":         "build",
+		"docs/*.md":      "docs/*.md",
+		"!keep.log":      "!**/keep.log",
+		`\#file`:         "**/#file",
+		"trailing.txt  ": "**/trailing.txt",
+	}
+	for line, expected := range tests {
+		if pattern := gitignorePattern(line); pattern != expected {
+			t.Errorf("%q: expected %q, got %q", line, expected, pattern)
+		}
+	}
+}
+
+func TestDockerignorePattern(t *testing.T) {
+	tests := map[string]string{
+		"# comment":    "",
+		"/tmp":         "tmp",
+		"!/tmp/keep":   "!tmp/keep",
+		" **/*.go ":    "**/*.go",
+		"node_modules": "node_modules",
+	}
+	for line, expected := range tests {
+		if pattern := dockerignorePattern(line); pattern != expected {
+			t.Errorf("%q: expected %q, got %q", line, expected, pattern)
+		}
+	}
+}
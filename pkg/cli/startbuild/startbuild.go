@@ -16,8 +16,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 
@@ -65,6 +67,16 @@ var (
 		pass a http or https url to --from-file and --from-archive, however authentication is not supported
 		and in case of https the certificate must be valid and recognized by your system.
 
+		The directories passed with --from-dir or --from-repo are archived without the files matched by the
+		.gitignore and .dockerignore files at their root, unless --ignore-files=false is passed. The archive
+		is compressed with the level set with --compress-level. An upload interrupted by a network error can
+		be retried with --upload-retries: the binary input is then copied to a temporary file first, to send
+		it again with each retry, which starts a new build.
+
+		The logs printed with --follow are annotated with the durations of the clone, build and push stages
+		of the build, and the errors found in the logs of a failed build are summarized at the end, unless
+		--build-summary=false is passed.
+
 		Note that builds triggered from binary input will not preserve the source on the server, so rebuilds
 		triggered by base image changes will use the source specified on the build config.`)
 
@@ -78,6 +90,9 @@ var (
 		# Use the contents of a directory as build input
 		oc start-build hello-world --from-dir=src/
 
+		# Upload a large directory with the best compression, and retry the upload up to 3 times if it is interrupted
+		oc start-build hello-world --from-dir=. --compress-level=9 --upload-retries=3
+
 		# Send the contents of a Git repository to the server from tag 'v2'
 		oc start-build hello-world --from-repo=../hello-world --commit=v2
 
@@ -110,11 +125,16 @@ type StartBuildOptions struct {
 	FromRepo      string
 	FromArchive   string
 	ExcludeRegExp string
+	IgnoreFiles   bool
+	CompressLevel int
+	UploadRetries int
 
 	Env  []string
 	Args []string
 
 	Follow              bool
+	Timestamps          bool
+	BuildSummary        bool
 	WaitForComplete     bool
 	IncrementalOverride bool
 	Incremental         bool
@@ -143,8 +163,11 @@ type StartBuildOptions struct {
 
 func NewStartBuildOptions(streams genericclioptions.IOStreams) *StartBuildOptions {
 	return &StartBuildOptions{
-		PrintFlags: genericclioptions.NewPrintFlags("started").WithTypeSetter(scheme.Scheme),
-		IOStreams:  streams,
+		PrintFlags:    genericclioptions.NewPrintFlags("started").WithTypeSetter(scheme.Scheme),
+		IgnoreFiles:   true,
+		CompressLevel: gzip.DefaultCompression,
+		BuildSummary:  true,
+		IOStreams:     streams,
 	}
 }
 
@@ -172,6 +195,8 @@ func NewCmdStartBuild(f kcmdutil.Factory, streams genericclioptions.IOStreams) *
 	cmd.Flags().StringVar(&o.FromBuild, "from-build", o.FromBuild, "Specify the name of a build which should be re-run")
 
 	cmd.Flags().BoolVarP(&o.Follow, "follow", "F", o.Follow, "Start a build and watch its logs until it completes or fails")
+	cmd.Flags().BoolVar(&o.Timestamps, "timestamps", o.Timestamps, "When using the --follow option: include the timestamps of the log lines")
+	cmd.Flags().BoolVar(&o.BuildSummary, "build-summary", o.BuildSummary, "When using the --follow option: annotate the logs with the durations of the build stages, and summarize the errors of a failed build")
 	cmd.Flags().BoolVarP(&o.WaitForComplete, "wait", "w", o.WaitForComplete, "Wait for a build to complete and exit with a non-zero return code if the build fails")
 	cmd.Flags().BoolVar(&o.Incremental, "incremental", o.Incremental, "Overrides the incremental setting in a source-strategy build, ignored if not specified")
 	cmd.Flags().BoolVar(&o.NoCache, "no-cache", o.NoCache, "Overrides the noCache setting in a docker-strategy build, ignored if not specified")
@@ -182,6 +207,9 @@ func NewCmdStartBuild(f kcmdutil.Factory, streams genericclioptions.IOStreams) *
 	cmd.Flags().StringVar(&o.FromRepo, "from-repo", o.FromRepo, "The path to a local source code repository to use as the binary input for a build.")
 	cmd.Flags().StringVar(&o.Commit, "commit", o.Commit, "Specify the source code commit identifier the build should use; requires a build based on a Git repository")
 	cmd.Flags().StringVarP(&o.ExcludeRegExp, "exclude", "", tar.DefaultExclusionPattern.String(), "When using the --from-dir option: regular expression for selecting files from the source tree to exclude from the build; the default excludes the '.git' directory (see https://golang.org/pkg/regexp for syntax, but note that \"\" will be interpreted as allow all files and exclude no files)")
+	cmd.Flags().BoolVar(&o.IgnoreFiles, "ignore-files", o.IgnoreFiles, "When using the --from-dir or --from-repo option: exclude the files matched by the .gitignore and .dockerignore files at the root of the directory from the build")
+	cmd.Flags().IntVar(&o.CompressLevel, "compress-level", o.CompressLevel, "When using the --from-dir or --from-repo option: the gzip compression level of the archive, from 0 (no compression) to 9 (best compression), or -1 for the default level")
+	cmd.Flags().IntVar(&o.UploadRetries, "upload-retries", o.UploadRetries, "The number of times an upload of binary input interrupted by a network error is retried; each retry starts a new build")
 
 	cmd.Flags().StringVar(&o.ListWebhooks, "list-webhooks", o.ListWebhooks, "List the webhooks for the specified build config or build; accepts 'all', 'generic', or 'github'")
 	cmd.Flags().StringVar(&o.FromWebhook, "from-webhook", o.FromWebhook, "Specify a generic webhook URL for an existing build config to trigger")
@@ -232,6 +260,12 @@ func (o *StartBuildOptions) Complete(ctx context.Context, f kcmdutil.Factory, cm
 	if cmd.Flags().Lookup("exclude").Changed && len(o.FromDir) == 0 {
 		return fmt.Errorf("the --exclude flag is only supported with --from-dir")
 	}
+	if (cmd.Flags().Lookup("ignore-files").Changed || cmd.Flags().Lookup("compress-level").Changed) && ((len(o.FromDir) == 0 && len(o.FromRepo) == 0) || len(o.FromArchive) > 0) {
+		return fmt.Errorf("the --ignore-files and --compress-level flags are only supported with --from-dir or --from-repo")
+	}
+	if cmd.Flags().Lookup("upload-retries").Changed && !o.AsBinary {
+		return fmt.Errorf("the --upload-retries flag is only supported with --from-file, --from-dir, --from-repo or --from-archive")
+	}
 
 	o.Printer, err = o.PrintFlags.ToPrinter()
 	if err != nil {
@@ -364,6 +398,13 @@ func (o *StartBuildOptions) Validate() error {
 		}
 	}
 
+	if o.CompressLevel < gzip.DefaultCompression || o.CompressLevel > gzip.BestCompression {
+		return fmt.Errorf("--compress-level must be between -1 and 9")
+	}
+	if o.UploadRetries < 0 {
+		return fmt.Errorf("--upload-retries must be a positive number")
+	}
+
 	if len(o.FromBuild) != 0 && o.AsBinary {
 		// TODO: we should support this, it should be possible to clone a build to run again with new uploaded artifacts.
 		// Doing so requires introducing a new clonebinary endpoint.
@@ -457,7 +498,13 @@ func (o *StartBuildOptions) Run(ctx context.Context) error {
 		}
 
 		instantiateClient := buildclientmanual.NewBuildInstantiateBinaryClient(o.BuildClient.RESTClient(), o.Namespace)
-		if newBuild, err = streamPathToBuild(o.Git, o.In, o.ErrOut, instantiateClient, o.FromDir, o.FromFile, o.FromRepo, o.ExcludeRegExp, request); err != nil {
+		upload := &uploadOptions{
+			ExcludeRegExp: o.ExcludeRegExp,
+			IgnoreFiles:   o.IgnoreFiles,
+			CompressLevel: o.CompressLevel,
+			Retries:       o.UploadRetries,
+		}
+		if newBuild, err = streamPathToBuild(o.Git, o.In, o.ErrOut, instantiateClient, o.FromDir, o.FromFile, o.FromRepo, upload, request); err != nil {
 			if kerrors.IsAlreadyExists(err) {
 				return transformIsAlreadyExistsError(err, o.Name)
 			}
@@ -506,8 +553,9 @@ func (o *StartBuildOptions) Run(ctx context.Context) error {
 
 func (o *StartBuildOptions) streamBuildLogs(ctx context.Context, build *buildv1.Build) error {
 	opts := buildv1.BuildLogOptions{
-		Follow: true,
-		NoWait: false,
+		Follow:     true,
+		NoWait:     false,
+		Timestamps: o.Timestamps || o.BuildSummary,
 	}
 	var err error
 	for {
@@ -521,15 +569,45 @@ func (o *StartBuildOptions) streamBuildLogs(ctx context.Context, build *buildv1.
 			break
 		}
 		defer rd.Close()
-		if _, streamErr := io.Copy(o.Out, rd); streamErr != nil {
-			err = ocerrors.NewError("unable to stream the build logs").WithCause(streamErr)
-			klog.V(4).Infof("Error: %v", err)
+		if !o.BuildSummary {
+			if _, streamErr := io.Copy(o.Out, rd); streamErr != nil {
+				err = ocerrors.NewError("unable to stream the build logs").WithCause(streamErr)
+				klog.V(4).Infof("Error: %v", err)
+			}
+			break
 		}
+		err = o.streamAnnotatedBuildLogs(ctx, build, rd)
 		break
 	}
 	return err
 }
 
+// buildStatusTimeout is how long the status of a build is awaited after the end of its logs.
+const buildStatusTimeout = 10 * time.Second
+
+// streamAnnotatedBuildLogs prints the logs of the build with the durations of its stages, and the summary of its
+// errors if it failed.
+func (o *StartBuildOptions) streamAnnotatedBuildLogs(ctx context.Context, build *buildv1.Build, rd io.Reader) error {
+	annotator := buildhelpers.NewLogAnnotator(o.Out, o.Timestamps)
+	_, streamErr := io.Copy(annotator, rd)
+	if closeErr := annotator.Close(); streamErr == nil {
+		streamErr = closeErr
+	}
+	if streamErr != nil {
+		err := ocerrors.NewError("unable to stream the build logs").WithCause(streamErr)
+		klog.V(4).Infof("Error: %v", err)
+		return err
+	}
+
+	finished, err := buildhelpers.GetFinishedBuild(ctx, o.BuildClient.Builds(o.Namespace), build.Name, buildStatusTimeout)
+	if err != nil {
+		klog.V(4).Infof("Unable to get the status of the build %s: %v", build.Name, err)
+		return nil
+	}
+	annotator.PrintFailureSummary(o.Out, finished)
+	return nil
+}
+
 // checkNonExistantResources checks for Resources (config maps, secrets, etc) listed in the BuildConfig
 // and returns error in case any of them are missing, or in case of unexpected behavior
 func (o *StartBuildOptions) checkNonExistantResources(ctx context.Context) error {
@@ -675,7 +753,19 @@ func (o *StartBuildOptions) RunListBuildWebHooks(ctx context.Context) error {
 	return nil
 }
 
-func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client buildclientmanual.BuildInstantiateBinaryInterface, fromDir, fromFile, fromRepo string, excludeRegExp string, options *buildv1.BinaryBuildRequestOptions) (*buildv1.Build, error) {
+// uploadOptions control how the binary input of a build is archived and uploaded.
+type uploadOptions struct {
+	// ExcludeRegExp excludes the matching paths from the archive of a directory
+	ExcludeRegExp string
+	// IgnoreFiles excludes the paths matched by the ignore files of a directory from its archive
+	IgnoreFiles bool
+	// CompressLevel is the gzip compression level of the archive of a directory
+	CompressLevel int
+	// Retries is the number of times an interrupted upload is sent again
+	Retries int
+}
+
+func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client buildclientmanual.BuildInstantiateBinaryInterface, fromDir, fromFile, fromRepo string, upload *uploadOptions, options *buildv1.BinaryBuildRequestOptions) (*buildv1.Build, error) {
 	asDir, asFile, asRepo := len(fromDir) > 0, len(fromFile) > 0, len(fromRepo) > 0
 
 	if asRepo && !git.IsGitInstalled() {
@@ -693,6 +783,7 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 	}
 
 	var r io.Reader
+	size := int64(-1)
 	switch {
 	case fromFile == "-":
 		return nil, fmt.Errorf("--from-file=- is not supported")
@@ -810,15 +901,29 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 				fmt.Fprintf(out, "Uploading directory %q as binary input for the build ...\n", clean)
 			}
 
-			re, err := regexp.Compile(excludeRegExp)
+			re, err := regexp.Compile(upload.ExcludeRegExp)
+			if err != nil {
+				return nil, err
+			}
+			fs := s2ifs.NewFileSystem()
+			if upload.IgnoreFiles {
+				var found []string
+				if fs, found, err = newIgnoreFileSystem(fs, path); err != nil {
+					return nil, err
+				}
+				if len(found) > 0 {
+					fmt.Fprintf(out, "Excluding the files matched by %s\n", strings.Join(found, " and "))
+				}
+			}
+			w, err := gzip.NewWriterLevel(nil, upload.CompressLevel)
 			if err != nil {
 				return nil, err
 			}
 
 			pr, pw := io.Pipe()
 			go func() {
-				w := gzip.NewWriter(pw)
-				t := tar.New(s2ifs.NewFileSystem())
+				w.Reset(pw)
+				t := tar.New(fs)
 				t.SetExclusionPattern(re)
 				if err := t.CreateTarStream(path, false, w); err != nil {
 					pw.CloseWithError(err)
@@ -841,6 +946,7 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 			defer f.Close()
 
 			r = f
+			size = stat.Size()
 
 			if asFile {
 				options.AsFile = filepath.Base(path)
@@ -859,28 +965,88 @@ func streamPathToBuild(repo git.Repository, in io.Reader, out io.Writer, client
 		}
 	}
 
-	stopProgress := progress(out)
-	defer stopProgress()
-	return client.InstantiateBinary(options.Name, options, r)
+	return instantiateBinary(out, client, options, r, size, upload.Retries)
+}
+
+// instantiateBinary uploads the binary input of the build. To retry the interrupted uploads, the input is copied to
+// a temporary file first, as the server can't resume an upload.
+func instantiateBinary(out io.Writer, client buildclientmanual.BuildInstantiateBinaryInterface, options *buildv1.BinaryBuildRequestOptions, r io.Reader, size int64, retries int) (*buildv1.Build, error) {
+	if retries == 0 {
+		r, stopProgress := progress(out, r, size)
+		defer stopProgress()
+		return client.InstantiateBinary(options.Name, options, r)
+	}
+
+	spool, err := ioutil.TempFile("", "oc-binary-input-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+	if size, err = io.Copy(spool, r); err != nil {
+		return nil, fmt.Errorf("unable to copy the binary input: %v", err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		r, stopProgress := progress(out, spool, size)
+		build, err := client.InstantiateBinary(options.Name, options, r)
+		stopProgress()
+		if err == nil || attempt > retries || !isInterruptedUpload(err) {
+			return build, err
+		}
+		fmt.Fprintf(out, "Upload interrupted: %v\nRetrying the upload (%d/%d) ...\n", err, attempt, retries)
+	}
+}
+
+// isInterruptedUpload returns true if the upload failed without a response of the server.
+func isInterruptedUpload(err error) bool {
+	_, isStatus := err.(kerrors.APIStatus)
+	return !isStatus
 }
 
-func progress(out io.Writer) func() {
+// uploadProgress counts the bytes read from an upload.
+type uploadProgress struct {
+	io.Reader
+	bytes int64
+}
+
+func (p *uploadProgress) Read(b []byte) (int, error) {
+	n, err := p.Reader.Read(b)
+	atomic.AddInt64(&p.bytes, int64(n))
+	return n, err
+}
+
+// progress returns a reader reporting periodically the bytes read from r, out of size if it is known, until the
+// returned function is called.
+func progress(out io.Writer, r io.Reader, size int64) (io.Reader, func()) {
+	p := &uploadProgress{Reader: r}
 	stop := make(chan bool)
 	done := make(chan bool)
 	go func() {
-		tick := time.Tick(5 * time.Second)
+		start := time.Now()
+		tick := time.NewTicker(5 * time.Second)
+		defer tick.Stop()
 		for {
 			select {
-			case <-tick:
-				fmt.Fprintf(out, ".")
+			case <-tick.C:
+				sent := atomic.LoadInt64(&p.bytes)
+				rate := units.BytesSize(float64(sent) / time.Since(start).Seconds())
+				if size > 0 {
+					fmt.Fprintf(out, "Uploaded %s of %s (%d%%, %s/s)\n", units.BytesSize(float64(sent)), units.BytesSize(float64(size)), sent*100/size, rate)
+				} else {
+					fmt.Fprintf(out, "Uploaded %s (%s/s)\n", units.BytesSize(float64(sent)), rate)
+				}
 			case <-stop:
-				fmt.Fprintf(out, "\nUploading finished\n")
+				fmt.Fprintf(out, "Uploading finished, %s sent\n", units.BytesSize(float64(atomic.LoadInt64(&p.bytes))))
 				done <- true
 				return
 			}
 		}
 	}()
-	return func() {
+	return p, func() {
 		stop <- true
 		<-done
 	}
@@ -1,7 +1,9 @@
 package startbuild
 
 import (
+	archivetar "archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
@@ -11,7 +13,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"testing"
 
@@ -286,7 +291,7 @@ func TestHttpBinary(t *testing.T) {
 
 		defaultExclusionPattern := tar.DefaultExclusionPattern.String()
 
-		build, err := streamPathToBuild(nil, stdin, stdout, &FakeBuildConfigs{t: t, expectAsFile: tc.fromFile}, fromDir, fromFile, "", defaultExclusionPattern, &options)
+		build, err := streamPathToBuild(nil, stdin, stdout, &FakeBuildConfigs{t: t, expectAsFile: tc.fromFile}, fromDir, fromFile, "", &uploadOptions{ExcludeRegExp: defaultExclusionPattern}, &options)
 
 		if len(tc.expectedError) > 0 {
 			if err == nil {
@@ -317,6 +322,129 @@ func TestHttpBinary(t *testing.T) {
 	}
 }
 
+// archiveBuildConfigs records the files of the archives uploaded, and fails the first uploads.
+type archiveBuildConfigs struct {
+	failures int
+	uploads  int
+	files    []string
+}
+
+func (c *archiveBuildConfigs) InstantiateBinary(name string, options *buildv1.BinaryBuildRequestOptions, r io.Reader) (*buildv1.Build, error) {
+	c.uploads++
+	if c.uploads <= c.failures {
+		io.CopyN(ioutil.Discard, r, 10)
+		return nil, io.ErrUnexpectedEOF
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	c.files = nil
+	tr := archivetar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return &buildv1.Build{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.files = append(c.files, hdr.Name)
+	}
+}
+
+func TestStreamDirWithIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":          "# dependencies\nnode_modules/\n*.log\n!keep.log\n/build\n",
+		".dockerignore":       "secrets\n",
+		"node_modules/lib.js": "",
+		"src/app.js":          "",
+		"src/debug.log":       "",
+		"src/build/main.js":   "",
+		"keep.log":            "",
+		"build/app":           "",
+		"secrets":             "",
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		ignoreFiles bool
+		failures    int
+		retries     int
+		expected    []string
+		err         bool
+	}{
+		{
+			name:        "ignore files",
+			ignoreFiles: true,
+			expected:    []string{".dockerignore", ".gitignore", "keep.log", "src", "src/app.js", "src/build", "src/build/main.js"},
+		},
+		{
+			name:     "without ignore files",
+			expected: []string{".dockerignore", ".gitignore", "build", "build/app", "keep.log", "node_modules", "node_modules/lib.js", "secrets", "src", "src/app.js", "src/build", "src/build/main.js", "src/debug.log"},
+		},
+		{
+			name:        "interrupted uploads are retried",
+			ignoreFiles: true,
+			failures:    2,
+			retries:     2,
+			expected:    []string{".dockerignore", ".gitignore", "keep.log", "src", "src/app.js", "src/build", "src/build/main.js"},
+		},
+		{
+			name:        "too many interrupted uploads",
+			ignoreFiles: true,
+			failures:    2,
+			retries:     1,
+			err:         true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			client := &archiveBuildConfigs{failures: tc.failures}
+			upload := &uploadOptions{
+				ExcludeRegExp: tar.DefaultExclusionPattern.String(),
+				IgnoreFiles:   tc.ignoreFiles,
+				CompressLevel: gzip.BestCompression,
+				Retries:       tc.retries,
+			}
+			_, err := streamPathToBuild(nil, nil, out, client, dir, "", "", upload, &buildv1.BinaryBuildRequestOptions{})
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected the upload to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v\n%s", err, out.String())
+			}
+			if client.uploads != tc.failures+1 {
+				t.Errorf("expected %d uploads, got %d", tc.failures+1, client.uploads)
+			}
+			sort.Strings(client.files)
+			if !reflect.DeepEqual(client.files, tc.expected) {
+				t.Errorf("expected files %v, got %v", tc.expected, client.files)
+			}
+			if !strings.Contains(out.String(), "Uploading finished, ") {
+				t.Errorf("expected the size of the upload, got:\n%s", out.String())
+			}
+			if tc.failures > 0 && !strings.Contains(out.String(), fmt.Sprintf("Retrying the upload (%d/%d)", tc.failures, tc.retries)) {
+				t.Errorf("expected the upload to be retried, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
 type logTestCase struct {
 	RequestErr     error
 	IOErr          error
@@ -1,6 +1,7 @@
 package status
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -20,8 +21,10 @@ import (
 	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
 	projectv1client "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
 	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 	"github.com/openshift/oc/pkg/helpers/describe"
 	dotutil "github.com/openshift/oc/pkg/helpers/dot"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
 	loginutil "github.com/openshift/oc/pkg/helpers/project"
 )
 
@@ -35,7 +38,12 @@ var (
 		oc describe deploymentconfig, oc describe service).
 
 		You can specify an output format of "-o dot" to have this command output the generated status
-		graph in DOT format that is suitable for use by the "dot" command.`)
+		graph in DOT format that is suitable for use by the "dot" command. The output formats "-o json"
+		and "-o yaml" print the graph, with the issues identified in it and the suggestions to resolve
+		them.
+
+		Each issue has a severity: error, warning or info. Use --fail-on to exit with an error when
+		issues of a severity or of a more important one are identified, for instance in a CI job.`)
 
 	statusExample = templates.Examples(`
 		# See an overview of the current project
@@ -45,7 +53,13 @@ var (
 		oc status -o dot | dot -T svg -o project.svg
 
 		# See an overview of the current project including details for any identified issues
-		oc status --suggest`)
+		oc status --suggest
+
+		# Print the graph and the identified issues of the current project as JSON
+		oc status -o json
+
+		# Fail when errors are identified in the current project, ignoring warnings and infos
+		oc status --fail-on=error`)
 )
 
 // StatusOptions contains all the necessary options for the Openshift cli status command.
@@ -55,6 +69,7 @@ type StatusOptions struct {
 	outputFormat  string
 	describer     *describe.ProjectStatusDescriber
 	suggest       bool
+	failOn        string
 
 	logsCommandName             string
 	securityPolicyCommandFormat string
@@ -75,7 +90,7 @@ func NewStatusOptions(streams genericclioptions.IOStreams) *StatusOptions {
 func NewCmdStatus(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
 	o := NewStatusOptions(streams)
 	cmd := &cobra.Command{
-		Use:     "status [-o dot | --suggest ]",
+		Use:     "status [-o dot | -o json | -o yaml | --suggest ]",
 		Short:   "Show an overview of the current project",
 		Long:    statusLong,
 		Example: statusExample,
@@ -85,8 +100,9 @@ func NewCmdStatus(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 			kcmdutil.CheckErr(o.RunStatus())
 		},
 	}
-	cmd.Flags().StringVarP(&o.outputFormat, "output", "o", o.outputFormat, "Output format. One of: dot.")
+	cmd.Flags().StringVarP(&o.outputFormat, "output", "o", o.outputFormat, "Output format. One of: dot|json|yaml.")
 	cmd.Flags().BoolVar(&o.suggest, "suggest", o.suggest, "See details for resolving issues.")
+	cmd.Flags().StringVar(&o.failOn, "fail-on", o.failOn, "Exit with an error if issues of this severity or a more important one are identified. One of: error|warning|info.")
 	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", o.allNamespaces, "If true, display status for all namespaces (must have cluster admin)")
 
 	return cmd
@@ -192,11 +208,17 @@ func (o *StatusOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []
 
 // Validate validates the options for the Openshift cli status command.
 func (o StatusOptions) Validate() error {
-	if len(o.outputFormat) != 0 && o.outputFormat != "dot" {
+	if len(o.outputFormat) != 0 && o.outputFormat != "dot" && o.outputFormat != "json" && o.outputFormat != "yaml" {
 		return fmt.Errorf("invalid output format provided: %s", o.outputFormat)
 	}
 	if len(o.outputFormat) > 0 && o.suggest {
-		return errors.New("cannot provide suggestions when output format is dot")
+		return fmt.Errorf("cannot provide suggestions when output format is %s", o.outputFormat)
+	}
+	if len(o.failOn) > 0 && !osgraph.Severity(o.failOn).IsValid() {
+		return fmt.Errorf("invalid severity provided to --fail-on: %s", o.failOn)
+	}
+	if len(o.failOn) > 0 && o.outputFormat == "dot" {
+		return errors.New("cannot identify issues when output format is dot")
 	}
 	return nil
 }
@@ -204,16 +226,20 @@ func (o StatusOptions) Validate() error {
 // RunStatus contains all the necessary functionality for the OpenShift cli status command.
 func (o StatusOptions) RunStatus() error {
 	var (
-		s   string
-		err error
+		s          string
+		severities []osgraph.Severity
 	)
 
 	switch o.outputFormat {
 	case "":
-		s, err = o.describer.Describe(o.namespace, "")
+		out, markers, err := o.describer.DescribeWithMarkers(o.namespace, "")
 		if err != nil {
 			return err
 		}
+		s = out
+		for _, marker := range markers {
+			severities = append(severities, marker.Severity)
+		}
 	case "dot":
 		g, _, err := o.describer.MakeGraph(o.namespace)
 		if err != nil {
@@ -224,10 +250,36 @@ func (o StatusOptions) RunStatus() error {
 			return err
 		}
 		s = string(data)
+	case "json", "yaml":
+		report, err := o.describer.Report(o.namespace)
+		if err != nil {
+			return err
+		}
+		buf := &bytes.Buffer{}
+		if err := cmdutil.PrintJSONOrYAML(buf, o.outputFormat, report); err != nil {
+			return err
+		}
+		s = buf.String()
+		for _, marker := range report.Markers {
+			severities = append(severities, marker.Severity)
+		}
 	default:
 		return fmt.Errorf("invalid output format provided: %s", o.outputFormat)
 	}
 
-	fmt.Fprintf(o.Out, s)
+	fmt.Fprint(o.Out, s)
+
+	if len(o.failOn) == 0 {
+		return nil
+	}
+	failures := 0
+	for _, severity := range severities {
+		if severity.AtLeast(osgraph.Severity(o.failOn)) {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d issues of severity %s or more important identified", failures, o.failOn)
+	}
 	return nil
 }
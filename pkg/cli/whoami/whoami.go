@@ -2,7 +2,11 @@ package whoami
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -12,11 +16,15 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	userv1 "github.com/openshift/api/user/v1"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	userv1typedclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	"github.com/openshift/oc/pkg/helpers/keychain"
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
 )
 
 const (
@@ -29,15 +37,27 @@ var whoamiLong = templates.LongDesc(`
 
 	The default options for this command will return the currently authenticated user name
 	or an empty string.  Other flags support returning the currently used token or the
-	user context.`)
+	user context.
+
+	The groups of the user include the groups the server resolved for the session, which
+	are the groups used by the authorization rules. With --output=json, the command prints
+	the user, their groups, the identities and identity providers they logged in with, and
+	the scopes and expiry of the token in use.`)
 
 var whoamiExample = templates.Examples(`
 	# Display the currently authenticated user
 	oc whoami
+
+	# Display the groups of the currently authenticated user
+	oc whoami --show-groups
+
+	# Display the user, groups, identity provider, token scopes and token expiry as JSON
+	oc whoami -o json
 `)
 
 type WhoAmIOptions struct {
-	UserInterface userv1typedclient.UserV1Interface
+	UserInterface  userv1typedclient.UserV1Interface
+	OAuthInterface oauthv1client.OauthV1Interface
 
 	ClientConfig *rest.Config
 	KubeClient   kubernetes.Interface
@@ -47,6 +67,8 @@ type WhoAmIOptions struct {
 	ShowContext    bool
 	ShowServer     bool
 	ShowConsoleUrl bool
+	ShowGroups     bool
+	Output         string
 
 	genericclioptions.IOStreams
 }
@@ -76,6 +98,8 @@ func NewCmdWhoAmI(f kcmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	cmd.Flags().BoolVarP(&o.ShowContext, "show-context", "c", o.ShowContext, "Print the current user context name")
 	cmd.Flags().BoolVar(&o.ShowServer, "show-server", o.ShowServer, "If true, print the current server's REST API URL")
 	cmd.Flags().BoolVar(&o.ShowConsoleUrl, "show-console", o.ShowConsoleUrl, "If true, print the current server's web console URL")
+	cmd.Flags().BoolVar(&o.ShowGroups, "show-groups", o.ShowGroups, "If true, print the groups of the currently authenticated user, one per line")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: json. Prints the user, their groups, identity providers, and the scopes and expiry of the token in use.")
 
 	return cmd
 }
@@ -97,6 +121,21 @@ func (o *WhoAmIOptions) Complete(f kcmdutil.Factory) error {
 		return err
 	}
 
+	// the token of a session that keeps it in the keychain is not in the config
+	if o.ShowToken && len(o.ClientConfig.BearerToken) == 0 && tokencmd.UsesKeychain(o.ClientConfig.ExecProvider) {
+		k, err := keychain.New(tokencmd.KeychainService)
+		if err != nil {
+			return err
+		}
+		tokens, err := tokencmd.LoadTokens(k, o.ClientConfig.Host)
+		if err != nil {
+			return err
+		}
+		if tokens != nil {
+			o.ClientConfig.BearerToken = tokens.AccessToken
+		}
+	}
+
 	kubeClient, err := kubernetes.NewForConfig(o.ClientConfig)
 	if err != nil {
 		return err
@@ -114,6 +153,9 @@ func (o *WhoAmIOptions) Validate() error {
 	if o.ShowContext && len(o.RawConfig.CurrentContext) == 0 {
 		return fmt.Errorf("no context has been set")
 	}
+	if len(o.Output) > 0 && o.Output != "json" {
+		return fmt.Errorf("invalid output format %q, only json is supported", o.Output)
+	}
 
 	return nil
 }
@@ -156,11 +198,108 @@ func (o *WhoAmIOptions) Run() error {
 	}
 
 	var err error
-	o.UserInterface, err = userv1typedclient.NewForConfig(o.ClientConfig)
-	if err != nil {
-		return err
+	if o.UserInterface == nil {
+		o.UserInterface, err = userv1typedclient.NewForConfig(o.ClientConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case o.Output == "json":
+		identity, err := o.identity()
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(identity, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+		return nil
+	case o.ShowGroups:
+		me, err := o.UserInterface.Users().Get(context.TODO(), "~", metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		for _, group := range sortedGroups(me) {
+			fmt.Fprintln(o.Out, group)
+		}
+		return nil
 	}
 
 	_, err = o.WhoAmI()
 	return err
 }
+
+// identity is the identity of the current session printed with --output=json.
+type identity struct {
+	User              string     `json:"user"`
+	UID               string     `json:"uid,omitempty"`
+	Groups            []string   `json:"groups"`
+	Identities        []string   `json:"identities,omitempty"`
+	IdentityProviders []string   `json:"identityProviders,omitempty"`
+	Token             *tokenInfo `json:"token,omitempty"`
+	Server            string     `json:"server"`
+	Context           string     `json:"context,omitempty"`
+}
+
+// tokenInfo describes the OAuth access token of the current session.
+type tokenInfo struct {
+	Client    string     `json:"client,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// identity returns the identity of the current session: the user and the groups resolved by the server, the
+// identity providers of the user, and the OAuth access token in use if the server issued it.
+func (o *WhoAmIOptions) identity() (*identity, error) {
+	me, err := o.UserInterface.Users().Get(context.TODO(), "~", metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	id := &identity{
+		User:       me.Name,
+		UID:        string(me.UID),
+		Groups:     sortedGroups(me),
+		Identities: me.Identities,
+		Server:     o.ClientConfig.Host,
+		Context:    o.RawConfig.CurrentContext,
+	}
+	providers := map[string]bool{}
+	for _, userIdentity := range me.Identities {
+		provider := strings.SplitN(userIdentity, ":", 2)[0]
+		if !providers[provider] {
+			providers[provider] = true
+			id.IdentityProviders = append(id.IdentityProviders, provider)
+		}
+	}
+
+	if len(o.ClientConfig.BearerToken) == 0 {
+		return id, nil
+	}
+	if o.OAuthInterface == nil {
+		if o.OAuthInterface, err = oauthv1client.NewForConfig(o.ClientConfig); err != nil {
+			return nil, err
+		}
+	}
+	// tokens that the OAuth server did not issue, like the tokens of service accounts, are not found
+	token, err := o.OAuthInterface.UserOAuthAccessTokens().Get(context.TODO(), tokencmd.AccessTokenName(o.ClientConfig.BearerToken), metav1.GetOptions{})
+	if err != nil {
+		klog.V(4).Infof("Unable to get the OAuth access token of the session: %v", err)
+		return id, nil
+	}
+	id.Token = &tokenInfo{Client: token.ClientName, Scopes: token.Scopes}
+	if token.ExpiresIn > 0 {
+		expiresAt := token.CreationTimestamp.Add(time.Duration(token.ExpiresIn) * time.Second).UTC()
+		id.Token.ExpiresAt = &expiresAt
+	}
+	return id, nil
+}
+
+// sortedGroups returns the groups of user, sorted.
+func sortedGroups(user *userv1.User) []string {
+	groups := append([]string{}, user.Groups...)
+	sort.Strings(groups)
+	return groups
+}
@@ -0,0 +1,136 @@
+package whoami
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	fakeoauthclient "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+	fakeuserclient "github.com/openshift/client-go/user/clientset/versioned/fake"
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
+)
+
+func TestWhoAmIGroupsAndIdentity(t *testing.T) {
+	const token = "sha256~token"
+	created := metav1.NewTime(time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC))
+	me := &userv1.User{
+		ObjectMeta: metav1.ObjectMeta{Name: "~", UID: "1234"},
+		Identities: []string{"htpasswd:alice", "github:12345", "htpasswd:alice2"},
+		Groups:     []string{"system:authenticated:oauth", "developers", "system:authenticated"},
+	}
+	accessToken := &oauthv1.UserOAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: tokencmd.AccessTokenName(token), CreationTimestamp: created},
+		ClientName: "openshift-browser-client",
+		Scopes:     []string{"user:full"},
+		ExpiresIn:  86400,
+	}
+
+	tests := []struct {
+		name        string
+		showGroups  bool
+		output      string
+		bearerToken string
+		expected    string
+	}{
+		{
+			name:       "groups",
+			showGroups: true,
+			expected:   "developers\nsystem:authenticated\nsystem:authenticated:oauth\n",
+		},
+		{
+			name:        "identity",
+			output:      "json",
+			bearerToken: token,
+			expected: `{
+  "user": "~",
+  "uid": "1234",
+  "groups": [
+    "developers",
+    "system:authenticated",
+    "system:authenticated:oauth"
+  ],
+  "identities": [
+    "htpasswd:alice",
+    "github:12345",
+    "htpasswd:alice2"
+  ],
+  "identityProviders": [
+    "htpasswd",
+    "github"
+  ],
+  "token": {
+    "client": "openshift-browser-client",
+    "scopes": [
+      "user:full"
+    ],
+    "expiresAt": "2021-06-02T10:00:00Z"
+  },
+  "server": "https://api.example.com:6443",
+  "context": "admin"
+}
+`,
+		},
+		{
+			name:        "identity with a token the OAuth server did not issue",
+			output:      "json",
+			bearerToken: "service-account-token",
+			expected: `{
+  "user": "~",
+  "uid": "1234",
+  "groups": [
+    "developers",
+    "system:authenticated",
+    "system:authenticated:oauth"
+  ],
+  "identities": [
+    "htpasswd:alice",
+    "github:12345",
+    "htpasswd:alice2"
+  ],
+  "identityProviders": [
+    "htpasswd",
+    "github"
+  ],
+  "server": "https://api.example.com:6443",
+  "context": "admin"
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			o := &WhoAmIOptions{
+				UserInterface:  fakeuserclient.NewSimpleClientset(me).UserV1(),
+				OAuthInterface: fakeoauthclient.NewSimpleClientset(accessToken).OauthV1(),
+				ClientConfig:   &rest.Config{Host: "https://api.example.com:6443", BearerToken: tt.bearerToken},
+				RawConfig:      api.Config{CurrentContext: "admin"},
+				ShowGroups:     tt.showGroups,
+				Output:         tt.output,
+				IOStreams:      streams,
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestWhoAmIValidateOutput(t *testing.T) {
+	o := &WhoAmIOptions{ClientConfig: &rest.Config{}, Output: "yaml"}
+	if err := o.Validate(); err == nil {
+		t.Errorf("expected an error for an unsupported output format")
+	}
+}
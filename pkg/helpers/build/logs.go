@@ -0,0 +1,210 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+)
+
+const (
+	// maxSummaryLines is the number of lines of the error summary of a failed build
+	maxSummaryLines = 20
+	// tailLines is the number of last lines of the log summarized when no error was found
+	tailLines = 10
+)
+
+// buildStage is a stage of a build, entered with the first log line matching its pattern.
+type buildStage struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// buildStages are the stages of a build, in their order.
+var buildStages = []buildStage{
+	{name: "clone", pattern: regexp.MustCompile(`^(Cloning "|Receiving source from STDIN)`)},
+	{name: "build", pattern: regexp.MustCompile(`^((\[\d+/\d+\] )?STEP \d+|Generating dockerfile with builder image|Replaced Dockerfile FROM image)`)},
+	{name: "push", pattern: regexp.MustCompile(`^Pushing image `)},
+}
+
+var (
+	errorLinePattern = regexp.MustCompile(`(?i)(^|\W)(error|fatal|failed|failure|denied|not found|no such|cannot|unable to)(\W|$)`)
+	ansiPattern      = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+)
+
+// LogAnnotator writes the lines of a build log annotated with the durations of the stages of the build: clone,
+// build and push. The lines are expected to be prefixed with the timestamps of the server, which are removed unless
+// they are requested, and the time of reception is used for the lines without a timestamp. The lines that look
+// like errors are collected to summarize the failure of the build.
+type LogAnnotator struct {
+	out        io.Writer
+	timestamps bool
+	now        func() time.Time
+
+	partial    []byte
+	stage      int
+	stageStart time.Time
+	last       time.Time
+	errors     []string
+	tail       []string
+}
+
+// NewLogAnnotator returns an annotator writing the build log to out, with the timestamps of its lines if
+// timestamps is true.
+func NewLogAnnotator(out io.Writer, timestamps bool) *LogAnnotator {
+	return &LogAnnotator{out: out, timestamps: timestamps, now: time.Now, stage: -1}
+}
+
+// Write annotates the complete lines of p, and keeps the last partial line until it is complete.
+func (a *LogAnnotator) Write(p []byte) (int, error) {
+	data := append(a.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if err := a.line(data[:i+1]); err != nil {
+			return 0, err
+		}
+		data = data[i+1:]
+	}
+	a.partial = append([]byte{}, data...)
+	return len(p), nil
+}
+
+// Close writes the last partial line and the duration of the last stage. It must be called once.
+func (a *LogAnnotator) Close() error {
+	if len(a.partial) > 0 {
+		line := append(a.partial, '\n')
+		a.partial = nil
+		if err := a.line(line); err != nil {
+			return err
+		}
+	}
+	return a.finishStage()
+}
+
+func (a *LogAnnotator) line(line []byte) error {
+	at, message := a.now(), line
+	if i := bytes.IndexByte(line, ' '); i > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, string(line[:i])); err == nil {
+			at, message = t, line[i+1:]
+		}
+	}
+	text := strings.TrimSpace(ansiPattern.ReplaceAllString(string(message), ""))
+
+	// the time before the first stage, like the set up of the build, is counted in the first stage
+	if a.stageStart.IsZero() {
+		a.stageStart = at
+	}
+	for i := a.stage + 1; i < len(buildStages); i++ {
+		if !buildStages[i].pattern.MatchString(text) {
+			continue
+		}
+		if a.stage >= 0 {
+			// a stage lasts until the next one starts
+			a.last = at
+			if err := a.finishStage(); err != nil {
+				return err
+			}
+			a.stageStart = at
+		}
+		a.stage = i
+		break
+	}
+	a.last = at
+	a.collect(text)
+
+	if !a.timestamps {
+		line = message
+	}
+	_, err := a.out.Write(line)
+	return err
+}
+
+func (a *LogAnnotator) finishStage() error {
+	if a.stage < 0 {
+		return nil
+	}
+	duration := a.last.Sub(a.stageStart).Round(100 * time.Millisecond)
+	_, err := fmt.Fprintf(a.out, "--> The %s stage took %s\n", buildStages[a.stage].name, duration)
+	return err
+}
+
+// collect keeps the distinct error lines of the log, and the last lines.
+func (a *LogAnnotator) collect(text string) {
+	if len(text) == 0 {
+		return
+	}
+	a.tail = append(a.tail, text)
+	if len(a.tail) > tailLines {
+		a.tail = a.tail[1:]
+	}
+	if !errorLinePattern.MatchString(text) {
+		return
+	}
+	for _, seen := range a.errors {
+		if seen == text {
+			return
+		}
+	}
+	a.errors = append(a.errors, text)
+	if len(a.errors) > maxSummaryLines {
+		a.errors = a.errors[1:]
+	}
+}
+
+// PrintFailureSummary prints the reason of the failure of the build and the errors of its log, or its last lines
+// when no error was found. Nothing is printed for a build that did not fail.
+func (a *LogAnnotator) PrintFailureSummary(out io.Writer, build *buildv1.Build) {
+	if build.Status.Phase != buildv1.BuildPhaseFailed && build.Status.Phase != buildv1.BuildPhaseError {
+		return
+	}
+	reason := string(build.Status.Reason)
+	if len(build.Status.Message) > 0 {
+		reason = fmt.Sprintf("%s (%s)", reason, build.Status.Message)
+	}
+	if len(reason) == 0 {
+		reason = string(build.Status.Phase)
+	}
+	fmt.Fprintf(out, "--> Build %s failed: %s\n", build.Name, reason)
+	lines := a.errors
+	if len(lines) > 0 {
+		fmt.Fprintf(out, "    Errors in the build log:\n")
+	} else {
+		lines = a.tail
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(out, "    Last lines of the build log:\n")
+	}
+	for _, line := range lines {
+		fmt.Fprintf(out, "      %s\n", line)
+	}
+}
+
+// GetFinishedBuild returns the build once it is complete, or as it is after the timeout. The status of a build may
+// be updated shortly after the end of its log.
+func GetFinishedBuild(ctx context.Context, client buildv1client.BuildInterface, name string, timeout time.Duration) (*buildv1.Build, error) {
+	var build *buildv1.Build
+	err := wait.PollImmediate(time.Second, timeout, func() (bool, error) {
+		var err error
+		if build, err = client.Get(ctx, name, metav1.GetOptions{}); err != nil {
+			return false, err
+		}
+		return IsBuildComplete(build), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return build, nil
+	}
+	return build, err
+}
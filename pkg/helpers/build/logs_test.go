@@ -0,0 +1,137 @@
+package build
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+)
+
+const testBuildLog = `2026-01-02T10:00:00.000000000Z Adding cluster TLS certificate authority to trust store
+2026-01-02T10:00:01.000000000Z Cloning "https://github.com/openshift/ruby-hello-world" ...
+2026-01-02T10:00:04.500000000Z 	Commit:	4fd2d0d (Merge pull request #1)
+2026-01-02T10:00:05.000000000Z STEP 1/9: FROM registry/ruby
+2026-01-02T10:00:50.000000000Z STEP 9/9: RUN bundle install
+2026-01-02T10:01:05.000000000Z error: failed to fetch gem rack
+2026-01-02T10:01:05.100000000Z error: failed to fetch gem rack
+2026-01-02T10:01:06.000000000Z Pushing image registry/test/ruby:latest ...
+2026-01-02T10:01:08.000000000Z Push successful
+`
+
+func writeInChunks(t *testing.T, w io.Writer, data string, size int) {
+	for len(data) > 0 {
+		n := size
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err := w.Write([]byte(data[:n])); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+}
+
+func TestLogAnnotator(t *testing.T) {
+	out := &bytes.Buffer{}
+	a := NewLogAnnotator(out, false)
+	writeInChunks(t, a, strings.TrimSuffix(testBuildLog, "\n"), 7)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Adding cluster TLS certificate authority to trust store
+Cloning "https://github.com/openshift/ruby-hello-world" ...
+	Commit:	4fd2d0d (Merge pull request #1)
+--> The clone stage took 5s
+STEP 1/9: FROM registry/ruby
+STEP 9/9: RUN bundle install
+error: failed to fetch gem rack
+error: failed to fetch gem rack
+--> The build stage took 1m1s
+Pushing image registry/test/ruby:latest ...
+Push successful
+--> The push stage took 2s
+`
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestLogAnnotatorTimestamps(t *testing.T) {
+	out := &bytes.Buffer{}
+	a := NewLogAnnotator(out, true)
+	writeInChunks(t, a, testBuildLog, len(testBuildLog))
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "2026-01-02T10:00:00.000000000Z Adding cluster") {
+		t.Errorf("expected the timestamps to be kept, got:\n%s", out.String())
+	}
+}
+
+func TestLogAnnotatorWithoutTimestamps(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	a := NewLogAnnotator(out, false)
+	a.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	writeInChunks(t, a, "Cloning \"https://example.com/repo\" ...\nSTEP 1/2: FROM ubi\nSTEP 2/2: RUN make\n", 5)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "Cloning \"https://example.com/repo\" ...\n--> The clone stage took 1s\nSTEP 1/2: FROM ubi\nSTEP 2/2: RUN make\n--> The build stage took 1s\n"
+	if out.String() != expected {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestPrintFailureSummary(t *testing.T) {
+	a := NewLogAnnotator(io.Discard, false)
+	writeInChunks(t, a, testBuildLog, len(testBuildLog))
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	build := &buildv1.Build{ObjectMeta: metav1.ObjectMeta{Name: "ruby-1"}}
+	build.Status.Phase = buildv1.BuildPhaseComplete
+	out := &bytes.Buffer{}
+	a.PrintFailureSummary(out, build)
+	if out.Len() > 0 {
+		t.Errorf("expected no summary for a complete build, got:\n%s", out.String())
+	}
+
+	build.Status.Phase = buildv1.BuildPhaseFailed
+	build.Status.Reason = buildv1.StatusReasonPushImageToRegistryFailed
+	build.Status.Message = "Failed to push the image to the registry."
+	a.PrintFailureSummary(out, build)
+	expected := `--> Build ruby-1 failed: PushImageToRegistryFailed (Failed to push the image to the registry.)
+    Errors in the build log:
+      error: failed to fetch gem rack
+`
+	if out.String() != expected {
+		t.Errorf("unexpected summary:\n%s", out.String())
+	}
+
+	a = NewLogAnnotator(io.Discard, false)
+	writeInChunks(t, a, "STEP 1/1: FROM ubi\nexit status 2\n", 100)
+	a.Close()
+	out.Reset()
+	build.Status.Phase = buildv1.BuildPhaseError
+	build.Status.Reason, build.Status.Message = "", ""
+	a.PrintFailureSummary(out, build)
+	expected = `--> Build ruby-1 failed: Error
+    Last lines of the build log:
+      STEP 1/1: FROM ubi
+      exit status 2
+`
+	if out.String() != expected {
+		t.Errorf("unexpected summary:\n%s", out.String())
+	}
+}
@@ -46,7 +46,9 @@ type IgnoreErrorFunc func(e error) bool
 type Bulk struct {
 	Scheme *runtime.Scheme
 
-	Op          OpFunc
+	Op OpFunc
+	// DryRunOp performs the operation with a server-side dry run, when the action is run with --dry-run=server
+	DryRunOp    OpFunc
 	After       AfterFunc
 	Retry       RetryFunc
 	IgnoreError IgnoreErrorFunc
@@ -181,6 +183,16 @@ type Creator struct {
 
 // Create is the default create operation for a generic resource.
 func (c Creator) Create(obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+	return c.create(obj, namespace, metav1.CreateOptions{})
+}
+
+// ServerDryRunCreate creates a generic resource with a server-side dry run: the server validates, defaults and admits
+// the resource without persisting it.
+func (c Creator) ServerDryRunCreate(obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+	return c.create(obj, namespace, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+}
+
+func (c Creator) create(obj *unstructured.Unstructured, namespace string, options metav1.CreateOptions) (*unstructured.Unstructured, error) {
 	if len(obj.GetNamespace()) > 0 {
 		namespace = obj.GetNamespace()
 	}
@@ -192,7 +204,7 @@ func (c Creator) Create(obj *unstructured.Unstructured, namespace string) (*unst
 		namespace = ""
 	}
 
-	return c.Client.Resource(mapping.Resource).Namespace(namespace).Create(context.TODO(), obj, metav1.CreateOptions{})
+	return c.Client.Resource(mapping.Resource).Namespace(namespace).Create(context.TODO(), obj, options)
 }
 
 func NoOp(obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
@@ -215,9 +227,11 @@ type BulkAction struct {
 	Bulk Bulk
 
 	// flags
-	Output      string
-	DryRun      bool
-	StopOnError bool
+	Output string
+	DryRun bool
+	// ServerDryRun is set with DryRun when the operation is performed on the server with a dry run
+	ServerDryRun bool
+	StopOnError  bool
 
 	// output modifiers
 	Action string
@@ -229,7 +243,7 @@ type BulkAction struct {
 // Passing -o is changing the default output format.
 func (b *BulkAction) BindForAction(flags *pflag.FlagSet) {
 	flags.StringVarP(&b.Output, "output", "o", "", "Output mode. Use \"-o name\" for shorter output (resource/name).")
-	flags.BoolVar(&b.DryRun, "dry-run", false, "If true, show the result of the operation without performing it.")
+	b.bindDryRun(flags)
 }
 
 // BindForOutput sets flags on this action for when setting -o will not execute the action (the point of the action is
@@ -241,7 +255,7 @@ func (b *BulkAction) BindForOutput(flags *pflag.FlagSet, skippedFlags ...string)
 		flags.StringVarP(&b.Output, "output", "o", "", "Output results as yaml or json instead of executing, or use name for succint output (resource/name).")
 	}
 	if !skipped.Has("dry-run") {
-		flags.BoolVar(&b.DryRun, "dry-run", false, "If true, show the result of the operation without performing it.")
+		b.bindDryRun(flags)
 	}
 	if !skipped.Has("no-headers") {
 		flags.Bool("no-headers", false, "Omit table headers for default output.")
@@ -264,6 +278,47 @@ func (b *BulkAction) BindForOutput(flags *pflag.FlagSet, skippedFlags ...string)
 	}
 }
 
+// bindDryRun binds the --dry-run flag, which accepts the none, client and server strategies as well as the booleans
+// of its previous versions.
+func (b *BulkAction) bindDryRun(flags *pflag.FlagSet) {
+	flag := flags.VarPF(&dryRunValue{action: b}, "dry-run", "", `Must be "none", "client", or "server". If client, show the result of the operation without performing it. If server, also submit the objects to the server with a dry run, which validates them without persisting them.`)
+	flag.NoOptDefVal = "client"
+}
+
+// dryRunValue sets the dry run strategy of an action.
+type dryRunValue struct {
+	action *BulkAction
+}
+
+func (v *dryRunValue) String() string {
+	switch {
+	case v.action == nil || !v.action.DryRun:
+		return "none"
+	case v.action.ServerDryRun:
+		return "server"
+	default:
+		return "client"
+	}
+}
+
+func (v *dryRunValue) Set(s string) error {
+	switch s {
+	case "none", "false":
+		v.action.DryRun, v.action.ServerDryRun = false, false
+	case "client", "true":
+		v.action.DryRun, v.action.ServerDryRun = true, false
+	case "server":
+		v.action.DryRun, v.action.ServerDryRun = true, true
+	default:
+		return fmt.Errorf(`invalid dry run strategy %q, must be "none", "client", or "server"`, s)
+	}
+	return nil
+}
+
+func (v *dryRunValue) Type() string {
+	return "string"
+}
+
 // Compact sets the output to a minimal set
 func (b *BulkAction) Compact() {
 	b.Output = "compact"
@@ -318,7 +373,14 @@ func (b *BulkAction) Run(list *metainternalversion.List, namespace string) []err
 	}
 
 	var modifier string
-	if b.DryRun {
+	switch {
+	case b.ServerDryRun:
+		if run.DryRunOp == nil {
+			return []error{fmt.Errorf("a server-side dry run is not supported")}
+		}
+		run.Op = run.DryRunOp
+		modifier = " (server dry run)"
+	case b.DryRun:
 		run.Op = NoOp
 		modifier = " (dry run)"
 	}
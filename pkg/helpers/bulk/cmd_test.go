@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/apitesting"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -123,3 +124,63 @@ func TestBulkActionCompact(t *testing.T) {
 		t.Fatalf("unexpected: %s", err.String())
 	}
 }
+
+func TestBulkActionDryRunFlag(t *testing.T) {
+	tests := []struct {
+		args         []string
+		dryRun       bool
+		serverDryRun bool
+		err          bool
+	}{
+		{args: []string{}},
+		{args: []string{"--dry-run"}, dryRun: true},
+		{args: []string{"--dry-run=true"}, dryRun: true},
+		{args: []string{"--dry-run=false"}},
+		{args: []string{"--dry-run=client"}, dryRun: true},
+		{args: []string{"--dry-run=server"}, dryRun: true, serverDryRun: true},
+		{args: []string{"--dry-run=none"}},
+		{args: []string{"--dry-run=all"}, err: true},
+	}
+	for _, tt := range tests {
+		b := &BulkAction{}
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		b.BindForOutput(flags)
+		err := flags.Parse(tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("%v: unexpected error: %v", tt.args, err)
+			continue
+		}
+		if b.DryRun != tt.dryRun || b.ServerDryRun != tt.serverDryRun {
+			t.Errorf("%v: expected dry run %t and server dry run %t, got %t and %t", tt.args, tt.dryRun, tt.serverDryRun, b.DryRun, b.ServerDryRun)
+		}
+	}
+}
+
+func TestBulkActionServerDryRun(t *testing.T) {
+	bt := &bulkTester{
+		mapping: &meta.RESTMapping{},
+	}
+	dryRun := &bulkTester{
+		mapping: &meta.RESTMapping{},
+	}
+
+	ioStreams, _, out, _ := genericclioptions.NewTestIOStreams()
+	scheme, _ := apitesting.SchemeForOrDie(api.InstallKube)
+	bulk := Bulk{Scheme: scheme, Op: bt.Record, DryRunOp: dryRun.Record}
+	b := &BulkAction{Bulk: bulk, Output: "", DryRun: true, ServerDryRun: true, IOStreams: ioStreams}
+	b2 := b.WithMessage("test1", "test2")
+
+	in := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "obj1"}}
+	if errs := b2.Run(&metainternalversion.List{Items: []runtime.Object{in}}, "test_namespace"); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if len(bt.recorded) != 0 || len(dryRun.recorded) != 1 {
+		t.Fatalf("expected the dry run operation to be performed, got %#v and %#v", bt.recorded, dryRun.recorded)
+	}
+	if out.String() != `--> test1 ...
+    pod "obj1" test2 (dry run)
+--> Success (server dry run)
+` {
+		t.Fatalf("unexpected: %s", out.String())
+	}
+}
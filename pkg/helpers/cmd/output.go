@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+
+	"sigs.k8s.io/yaml"
+)
+
+// PrintJSONOrYAML writes v to out as indented JSON, or as YAML if format is "yaml". It is meant for the reports
+// of commands that are not API objects, and so can't be printed with the printers of genericclioptions.PrintFlags.
+func PrintJSONOrYAML(out io.Writer, format string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if format == "yaml" {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	_, err = out.Write(data)
+	return err
+}
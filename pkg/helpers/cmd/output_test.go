@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintJSONOrYAML(t *testing.T) {
+	report := struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}{Name: "test", Count: 2}
+
+	tests := map[string]string{
+		"json": "{\n  \"name\": \"test\",\n  \"count\": 2\n}\n",
+		"yaml": "count: 2\nname: test\n",
+	}
+	for format, expected := range tests {
+		t.Run(format, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			if err := PrintJSONOrYAML(out, format, report); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != expected {
+				t.Errorf("expected %q, got %q", expected, out.String())
+			}
+		})
+	}
+}
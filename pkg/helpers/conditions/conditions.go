@@ -21,6 +21,7 @@ type PodWaitNotifyFunc func(pod *corev1.Pod, container corev1.ContainerStatus) e
 
 // PodContainerRunning returns false until the named container has ContainerStatus running (at least once),
 // and will return an error if the pod is deleted, runs to completion, or the container pod is not available.
+// The named container may be an init, regular or ephemeral container.
 func PodContainerRunning(containerName string, coreClient corev1client.CoreV1Interface, notifyFn PodWaitNotifyFunc) watchtools.ConditionFunc {
 	return func(event watch.Event) (bool, error) {
 		switch event.Type {
@@ -43,7 +44,7 @@ func PodContainerRunning(containerName string, coreClient corev1client.CoreV1Int
 						// only the first waiting init container is relevant
 						break
 					}
-					for _, s := range t.Status.ContainerStatuses {
+					for _, s := range append(append([]corev1.ContainerStatus{}, t.Status.ContainerStatuses...), t.Status.EphemeralContainerStatuses...) {
 						if s.Name != containerName {
 							continue
 						}
@@ -66,7 +67,7 @@ func PodContainerRunning(containerName string, coreClient corev1client.CoreV1Int
 				return false, krun.ErrPodCompleted
 			}
 
-			for _, s := range append(append([]corev1.ContainerStatus{}, t.Status.InitContainerStatuses...), t.Status.ContainerStatuses...) {
+			for _, s := range append(append(append([]corev1.ContainerStatus{}, t.Status.InitContainerStatuses...), t.Status.ContainerStatuses...), t.Status.EphemeralContainerStatuses...) {
 				if s.Name != containerName {
 					continue
 				}
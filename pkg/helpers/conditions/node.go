@@ -0,0 +1,15 @@
+package conditions
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeReady returns true if the Ready condition of the node is true.
+func NodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package conditions
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNodeReady(t *testing.T) {
+	node := &corev1.Node{}
+	if NodeReady(node) {
+		t.Errorf("a node without conditions is not ready")
+	}
+	node.Status.Conditions = []corev1.NodeCondition{
+		{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+		{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+	}
+	if !NodeReady(node) {
+		t.Errorf("expected the node to be ready")
+	}
+}
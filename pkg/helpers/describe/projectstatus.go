@@ -185,11 +185,18 @@ func createSelector(values map[string]string) labels.Selector {
 
 // Describe returns the description of a project
 func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error) {
+	out, _, err := d.DescribeWithMarkers(namespace, name)
+	return out, err
+}
+
+// DescribeWithMarkers returns the description of a project, and the markers of the issues identified in the
+// project, sorted.
+func (d *ProjectStatusDescriber) DescribeWithMarkers(namespace, name string) (string, osgraph.Markers, error) {
 	var f formatter = namespacedFormatter{}
 
 	g, forbiddenResources, err := d.MakeGraph(namespace)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	allNamespaces := namespace == metav1.NamespaceAll
@@ -201,10 +208,10 @@ func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error
 			// the user has not created any projects, and is therefore using a
 			// default namespace that they cannot list projects from.
 			if kapierrors.IsForbidden(err) && len(d.RequestedNamespace) == 0 && len(d.CurrentNamespace) == 0 {
-				return loginerrors.NoProjectsExistMessage(d.CanRequestProjects), nil
+				return loginerrors.NoProjectsExistMessage(d.CanRequestProjects), nil, nil
 			}
 			if !kapierrors.IsNotFound(err) {
-				return "", err
+				return "", nil, err
 			}
 			p = &projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
 		}
@@ -260,7 +267,9 @@ func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error
 	standalonePods, coveredByPods := graphview.AllPods(g, coveredNodes)
 	coveredNodes.Insert(coveredByPods.List()...)
 
-	return tabbedString(func(out *tabwriter.Writer) error {
+	allMarkers := d.markers(g, f, namespace, forbiddenResources)
+
+	out, err := tabbedString(func(out *tabwriter.Writer) error {
 		indent := "  "
 		if allNamespaces {
 			fmt.Fprintf(out, describeAllProjectsOnServer(f, d.Server))
@@ -463,20 +472,8 @@ func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error
 			printLines(out, indent, 0, describeMonopod(f, monopod.Pod)...)
 		}
 
-		allMarkers := osgraph.Markers{}
-		allMarkers = append(allMarkers, createForbiddenMarkers(forbiddenResources)...)
-		for _, scanner := range getMarkerScanners(d.LogsCommandName, d.SecurityPolicyCommandFormat, d.SetProbeCommandName, forbiddenResources) {
-			allMarkers = append(allMarkers, scanner(g, f)...)
-		}
-
-		// TODO: Provide an option to chase these hidden markers.
-		allMarkers = allMarkers.FilterByNamespace(namespace)
-
 		fmt.Fprintln(out)
 
-		sort.Stable(osgraph.ByKey(allMarkers))
-		sort.Stable(osgraph.ByNodeID(allMarkers))
-
 		errorMarkers := allMarkers.BySeverity(osgraph.ErrorSeverity)
 		errorSuggestions := 0
 		if len(errorMarkers) > 0 {
@@ -558,6 +555,23 @@ func (d *ProjectStatusDescriber) Describe(namespace, name string) (string, error
 
 		return nil
 	})
+	return out, allMarkers, err
+}
+
+// markers returns the markers of the issues identified in the graph of namespace, sorted.
+func (d *ProjectStatusDescriber) markers(g osgraph.Graph, f formatter, namespace string, forbiddenResources sets.String) osgraph.Markers {
+	allMarkers := osgraph.Markers{}
+	allMarkers = append(allMarkers, createForbiddenMarkers(forbiddenResources)...)
+	for _, scanner := range getMarkerScanners(d.LogsCommandName, d.SecurityPolicyCommandFormat, d.SetProbeCommandName, forbiddenResources) {
+		allMarkers = append(allMarkers, scanner(g, f)...)
+	}
+
+	// TODO: Provide an option to chase these hidden markers.
+	allMarkers = allMarkers.FilterByNamespace(namespace)
+
+	sort.Stable(osgraph.ByKey(allMarkers))
+	sort.Stable(osgraph.ByNodeID(allMarkers))
+	return allMarkers
 }
 
 // printMarkerSuggestions prints a formatted list of marker suggestions
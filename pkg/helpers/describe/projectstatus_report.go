@@ -0,0 +1,151 @@
+package describe
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/gonum/graph"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+// ProjectStatusReport is the machine readable status of a project: the graph of the applications and the issues
+// identified in it.
+type ProjectStatusReport struct {
+	// Namespace is the namespace of the project, empty for all the namespaces
+	Namespace string `json:"namespace,omitempty"`
+	// Server is the server the project is on
+	Server string `json:"server"`
+
+	Nodes   []StatusNode   `json:"nodes"`
+	Edges   []StatusEdge   `json:"edges"`
+	Markers []StatusMarker `json:"markers"`
+	Summary StatusSummary  `json:"summary"`
+}
+
+// StatusNode is an object of the graph of the applications.
+type StatusNode struct {
+	ID        int    `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Missing is true when the object is referenced by other objects but does not exist
+	Missing bool `json:"missing,omitempty"`
+}
+
+// StatusEdge is a relation between two objects of the graph.
+type StatusEdge struct {
+	From  int      `json:"from"`
+	To    int      `json:"to"`
+	Kinds []string `json:"kinds"`
+}
+
+// StatusMarker is an issue identified in the graph, with the suggestion to resolve it.
+type StatusMarker struct {
+	Severity   osgraph.Severity `json:"severity"`
+	Key        string           `json:"key"`
+	Message    string           `json:"message,omitempty"`
+	Suggestion string           `json:"suggestion,omitempty"`
+	// Node is the ID of the node the marker is about
+	Node *int `json:"node,omitempty"`
+	// RelatedNodes are the IDs of the other nodes involved
+	RelatedNodes []int `json:"relatedNodes,omitempty"`
+}
+
+// StatusSummary counts the markers by severity.
+type StatusSummary struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+}
+
+// Report returns the machine readable status of the project namespace, or of all the namespaces.
+func (d *ProjectStatusDescriber) Report(namespace string) (*ProjectStatusReport, error) {
+	g, forbiddenResources, err := d.MakeGraph(namespace)
+	if err != nil {
+		return nil, err
+	}
+	var f formatter = namespacedFormatter{}
+	if namespace != metav1.NamespaceAll {
+		f = namespacedFormatter{currentNamespace: namespace}
+	}
+	return newProjectStatusReport(g, d.markers(g, f, namespace, forbiddenResources), namespace, d.Server), nil
+}
+
+func newProjectStatusReport(g osgraph.Graph, markers osgraph.Markers, namespace, server string) *ProjectStatusReport {
+	report := &ProjectStatusReport{
+		Namespace: namespace,
+		Server:    server,
+		Nodes:     []StatusNode{},
+		Edges:     []StatusEdge{},
+		Markers:   []StatusMarker{},
+	}
+
+	nodes := g.Nodes()
+	sort.Sort(osgraph.ByID(nodes))
+	for _, node := range nodes {
+		statusNode := StatusNode{ID: node.ID(), Kind: g.Kind(node)}
+		if obj := g.Object(node); obj != nil {
+			if v := reflect.ValueOf(obj); v.Kind() != reflect.Ptr || !v.IsNil() {
+				if m, err := meta.Accessor(obj); err == nil {
+					statusNode.Namespace, statusNode.Name = m.GetNamespace(), m.GetName()
+				}
+			}
+		}
+		if len(statusNode.Name) == 0 {
+			statusNode.Name = g.Name(node)
+		}
+		if checker, ok := node.(osgraph.ExistenceChecker); ok && !checker.Found() {
+			statusNode.Missing = true
+		}
+		report.Nodes = append(report.Nodes, statusNode)
+	}
+
+	for _, edge := range g.Edges() {
+		report.Edges = append(report.Edges, StatusEdge{From: edge.From().ID(), To: edge.To().ID(), Kinds: g.EdgeKinds(edge).List()})
+	}
+	sort.Slice(report.Edges, func(i, j int) bool {
+		if report.Edges[i].From != report.Edges[j].From {
+			return report.Edges[i].From < report.Edges[j].From
+		}
+		return report.Edges[i].To < report.Edges[j].To
+	})
+
+	for _, marker := range markers {
+		statusMarker := StatusMarker{
+			Severity:   marker.Severity,
+			Key:        marker.Key,
+			Message:    marker.Message,
+			Suggestion: marker.Suggestion.String(),
+		}
+		if marker.Node != nil {
+			id := marker.Node.ID()
+			statusMarker.Node = &id
+		}
+		statusMarker.RelatedNodes = nodeIDs(marker.RelatedNodes)
+		report.Markers = append(report.Markers, statusMarker)
+
+		switch marker.Severity {
+		case osgraph.ErrorSeverity:
+			report.Summary.Errors++
+		case osgraph.WarningSeverity:
+			report.Summary.Warnings++
+		case osgraph.InfoSeverity:
+			report.Summary.Infos++
+		}
+	}
+	return report
+}
+
+func nodeIDs(nodes []graph.Node) []int {
+	var ids []int
+	for _, node := range nodes {
+		if node != nil {
+			ids = append(ids, node.ID())
+		}
+	}
+	return ids
+}
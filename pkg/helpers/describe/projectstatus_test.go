@@ -605,6 +605,74 @@ func TestProjectStatusErrors(t *testing.T) {
 	}
 }
 
+func TestProjectStatusReport(t *testing.T) {
+	objs, err := readObjectsFromPath("../graph/genericgraph/test/unpushable-build.yaml", "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	objs = append(objs, &projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "example"}})
+
+	appsScheme := runtime.NewScheme()
+	appsv1.Install(appsScheme)
+	buildScheme := runtime.NewScheme()
+	buildv1.Install(buildScheme)
+	imageScheme := runtime.NewScheme()
+	imagev1.Install(imageScheme)
+	projectScheme := runtime.NewScheme()
+	projectv1.Install(projectScheme)
+	routeScheme := runtime.NewScheme()
+	routev1.Install(routeScheme)
+	kubeScheme := runtime.NewScheme()
+	kubernetesscheme.AddToScheme(kubeScheme)
+
+	d := ProjectStatusDescriber{
+		KubeClient:    fakekubernetes.NewSimpleClientset(filterByScheme(kubeScheme, objs...)...),
+		ProjectClient: &fakeprojectv1client.FakeProjectV1{Fake: &(fakeprojectclient.NewSimpleClientset(filterByScheme(projectScheme, objs...)...).Fake)},
+		BuildClient:   &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildScheme, objs...)...).Fake)},
+		ImageClient:   &fakeimagev1client.FakeImageV1{Fake: &(fakeimageclient.NewSimpleClientset(filterByScheme(imageScheme, objs...)...).Fake)},
+		AppsClient:    &fakeappsv1client.FakeAppsV1{Fake: &(fakeappsclient.NewSimpleClientset(filterByScheme(appsScheme, objs...)...).Fake)},
+		RouteClient:   &fakeroutev1client.FakeRouteV1{Fake: &(fakerouteclient.NewSimpleClientset(filterByScheme(routeScheme, objs...)...).Fake)},
+		Server:        "https://example.com:8443",
+		RESTMapper:    testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme),
+	}
+	report, err := d.Report("example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Namespace != "example" || report.Server != "https://example.com:8443" {
+		t.Errorf("unexpected report of %q on %q", report.Namespace, report.Server)
+	}
+
+	nodes := map[int]StatusNode{}
+	for _, node := range report.Nodes {
+		nodes[node.ID] = node
+	}
+	for _, edge := range report.Edges {
+		if _, ok := nodes[edge.From]; !ok {
+			t.Errorf("edge from unknown node %d", edge.From)
+		}
+		if _, ok := nodes[edge.To]; !ok {
+			t.Errorf("edge to unknown node %d", edge.To)
+		}
+	}
+
+	if report.Summary.Errors+report.Summary.Warnings+report.Summary.Infos != len(report.Markers) {
+		t.Errorf("summary %#v does not count the %d markers", report.Summary, len(report.Markers))
+	}
+	found := false
+	for _, marker := range report.Markers {
+		if marker.Severity != osgraph.ErrorSeverity || marker.Node == nil {
+			continue
+		}
+		if node := nodes[*marker.Node]; node.Kind == "BuildConfig" && node.Namespace == "example" && node.Name == "ruby-hello-world" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected an error about bc/ruby-hello-world, got %#v", report.Markers)
+	}
+}
+
 func TestPrintMarkerSuggestions(t *testing.T) {
 	testCases := []struct {
 		markers  []osgraph.Marker
@@ -0,0 +1,105 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ParseEnvFiles reads the environment variables of the dotenv files in order, the variables of the later files
+// replacing the ones of the earlier files.
+func ParseEnvFiles(paths []string) ([]corev1.EnvVar, error) {
+	env := []corev1.EnvVar{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		fileEnv, err := ParseDotEnv(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		env = append(env, fileEnv...)
+	}
+	return MergeEnv(env), nil
+}
+
+// ParseDotEnv reads environment variables in the dotenv format: KEY=VALUE lines, optionally prefixed by export,
+// with blank lines and comments starting with # ignored. Values may be single quoted, taken literally, or double
+// quoted, with escape sequences like \n interpreted; unquoted values end at an inline comment.
+func ParseDotEnv(r io.Reader) ([]corev1.EnvVar, error) {
+	env := []corev1.EnvVar{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: environment variables must be of the form KEY=VALUE, but is %q", line, text)
+		}
+		name := strings.TrimSpace(parts[0])
+		if errs := validation.IsEnvVarName(name); len(errs) != 0 {
+			return nil, fmt.Errorf("line %d: environment variable %s is invalid, %s", line, name, strings.Join(errs, "; "))
+		}
+		value, err := dotEnvValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		env = append(env, corev1.EnvVar{Name: name, Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func dotEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end == -1 {
+			return "", fmt.Errorf("unterminated quoted value %s", value)
+		}
+		return value[1 : end+1], nil
+	case strings.HasPrefix(value, `"`):
+		for end := 1; end < len(value); end++ {
+			switch value[end] {
+			case '\\':
+				end++
+			case '"':
+				return strconv.Unquote(value[:end+1])
+			}
+		}
+		return "", fmt.Errorf("unterminated quoted value %s", value)
+	}
+	if pos := strings.Index(value, " #"); pos != -1 {
+		value = value[:pos]
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// MergeEnv removes the duplicated environment variables, keeping the last value of each variable at the position
+// of its first occurrence.
+func MergeEnv(env []corev1.EnvVar) []corev1.EnvVar {
+	positions := map[string]int{}
+	merged := []corev1.EnvVar{}
+	for _, e := range env {
+		if i, ok := positions[e.Name]; ok {
+			merged[i] = e
+			continue
+		}
+		positions[e.Name] = len(merged)
+		merged = append(merged, e)
+	}
+	return merged
+}
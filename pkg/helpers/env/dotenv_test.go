@@ -0,0 +1,63 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	content := `# database settings
+export DB_HOST=db.example.com
+DB_PORT = 5432 # the default port
+DB_PASSWORD='p@ss #word'
+GREETING="hello\nworld"
+EMPTY=
+`
+	env, err := ParseDotEnv(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []corev1.EnvVar{
+		{Name: "DB_HOST", Value: "db.example.com"},
+		{Name: "DB_PORT", Value: "5432"},
+		{Name: "DB_PASSWORD", Value: "p@ss #word"},
+		{Name: "GREETING", Value: "hello\nworld"},
+		{Name: "EMPTY", Value: ""},
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %v, got %v", expected, env)
+	}
+
+	for _, invalid := range []string{"NO_VALUE", "1INVALID=name", `QUOTE="unterminated`, "SINGLE='unterminated"} {
+		if _, err := ParseDotEnv(strings.NewReader(invalid)); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestParseEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	base, override := filepath.Join(dir, ".env"), filepath.Join(dir, ".env.production")
+	if err := os.WriteFile(base, []byte("A=1\nB=2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(override, []byte("B=3\nC=4\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env, err := ParseEnvFiles([]string{base, override})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []corev1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "3"}, {Name: "C", Value: "4"}}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %v, got %v", expected, env)
+	}
+	if _, err := ParseEnvFiles([]string{filepath.Join(dir, "missing")}); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,19 @@
+package events
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LastTime returns the last time the event occurred: the last observation of its series, its last timestamp, or
+// its event time for the events that set neither.
+func LastTime(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	}
+	return event.EventTime.Time
+}
@@ -36,6 +36,24 @@ const (
 	ErrorSeverity Severity = "error"
 )
 
+// severityLevels orders the severities, from the least to the most important.
+var severityLevels = map[Severity]int{
+	InfoSeverity:    1,
+	WarningSeverity: 2,
+	ErrorSeverity:   3,
+}
+
+// IsValid returns true if s is a known severity.
+func (s Severity) IsValid() bool {
+	_, ok := severityLevels[s]
+	return ok
+}
+
+// AtLeast returns true if s is as important as severity or more important.
+func (s Severity) AtLeast(severity Severity) bool {
+	return severityLevels[s] >= severityLevels[severity]
+}
+
 type Markers []Marker
 
 // MarkerScanner is a function for analyzing a graph and finding interesting things in it
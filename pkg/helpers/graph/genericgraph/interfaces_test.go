@@ -0,0 +1,25 @@
+package genericgraph
+
+import "testing"
+
+func TestSeverityAtLeast(t *testing.T) {
+	tests := []struct {
+		severity Severity
+		atLeast  Severity
+		expected bool
+	}{
+		{severity: ErrorSeverity, atLeast: ErrorSeverity, expected: true},
+		{severity: ErrorSeverity, atLeast: InfoSeverity, expected: true},
+		{severity: WarningSeverity, atLeast: ErrorSeverity, expected: false},
+		{severity: WarningSeverity, atLeast: WarningSeverity, expected: true},
+		{severity: InfoSeverity, atLeast: WarningSeverity, expected: false},
+	}
+	for _, tt := range tests {
+		if actual := tt.severity.AtLeast(tt.atLeast); actual != tt.expected {
+			t.Errorf("expected %s at least %s to be %t", tt.severity, tt.atLeast, tt.expected)
+		}
+	}
+	if Severity("critical").IsValid() {
+		t.Errorf("expected critical not to be a valid severity")
+	}
+}
@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// azureADGroup is a group of the Microsoft Graph API.
+type azureADGroup struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+}
+
+// azureADProvider lists the groups of an Azure Active Directory tenant through the Microsoft Graph API. The members
+// of nested groups are members of the group.
+type azureADProvider struct {
+	client *client
+	host   string
+	filter string
+
+	groups map[string]*azureADGroup
+}
+
+var _ Interface = &azureADProvider{}
+
+func newAzureADProvider(client *client, host, filter string) *azureADProvider {
+	return &azureADProvider{
+		client: client,
+		host:   host,
+		filter: filter,
+		groups: map[string]*azureADGroup{},
+	}
+}
+
+func (p *azureADProvider) Host() string {
+	return p.host
+}
+
+func (p *azureADProvider) ListGroups() ([]string, error) {
+	var groupUIDs []string
+	query := url.Values{"$select": {"id,displayName"}, "$top": {"999"}}
+	if len(p.filter) > 0 {
+		query.Set("$filter", p.filter)
+	}
+	err := p.list("/groups", query, func(item map[string]interface{}) {
+		group := &azureADGroup{}
+		group.ID, _ = item["id"].(string)
+		group.DisplayName, _ = item["displayName"].(string)
+		p.groups[group.ID] = group
+		groupUIDs = append(groupUIDs, group.ID)
+	})
+	return groupUIDs, err
+}
+
+// list calls fn with every item of a list, following the links to the next pages.
+func (p *azureADProvider) list(path string, query url.Values, fn func(item map[string]interface{})) error {
+	for len(path) > 0 {
+		var page struct {
+			Value    []map[string]interface{} `json:"value"`
+			NextLink string                   `json:"@odata.nextLink"`
+		}
+		if err := p.client.get(path, query, &page); err != nil {
+			return err
+		}
+		for _, item := range page.Value {
+			fn(item)
+		}
+		// the next link includes the query
+		path, query = page.NextLink, nil
+	}
+	return nil
+}
+
+func (p *azureADProvider) getGroup(groupUID string) (*azureADGroup, error) {
+	if group, ok := p.groups[groupUID]; ok {
+		return group, nil
+	}
+	group := &azureADGroup{}
+	if err := p.client.get("/groups/"+url.PathEscape(groupUID), url.Values{"$select": {"id,displayName"}}, group); err != nil {
+		return nil, err
+	}
+	p.groups[groupUID] = group
+	return group, nil
+}
+
+func (p *azureADProvider) GroupNameFor(groupUID string) (string, error) {
+	group, err := p.getGroup(groupUID)
+	if err != nil {
+		return "", err
+	}
+	if len(group.DisplayName) == 0 {
+		return "", fmt.Errorf("the Azure AD group %q has no displayName", groupUID)
+	}
+	return group.DisplayName, nil
+}
+
+func (p *azureADProvider) ExtractMembers(groupUID string) ([]*ldap.Entry, error) {
+	var members []*ldap.Entry
+	path := "/groups/" + url.PathEscape(groupUID) + "/transitiveMembers/microsoft.graph.user"
+	err := p.list(path, url.Values{"$top": {"999"}}, func(user map[string]interface{}) {
+		id, _ := user["id"].(string)
+		members = append(members, newUserEntry(id, user))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (p *azureADProvider) Exists(groupUID string) (bool, error) {
+	if _, err := p.getGroup(groupUID); err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
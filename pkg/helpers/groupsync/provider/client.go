@@ -0,0 +1,199 @@
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"k8s.io/klog/v2"
+)
+
+// client makes authenticated requests to the REST API of a group provider.
+type client struct {
+	http    *http.Client
+	baseURL string
+	// token returns the bearer token of the requests.
+	token func() (string, error)
+}
+
+func newHTTPClient(ca string, insecure bool) (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if len(ca) > 0 {
+		data, err := ioutil.ReadFile(ca)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA bundle %s: %v", ca, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", ca)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: time.Minute}, nil
+}
+
+// statusError is returned when the provider responds with an unexpected status.
+type statusError struct {
+	url     string
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string {
+	if len(e.message) > 0 {
+		return fmt.Sprintf("GET %s returned %d: %s", e.url, e.code, e.message)
+	}
+	return fmt.Sprintf("GET %s returned %d", e.url, e.code)
+}
+
+// isNotFound returns true if the provider responded that the requested resource does not exist.
+func isNotFound(err error) bool {
+	e, ok := err.(*statusError)
+	return ok && e.code == http.StatusNotFound
+}
+
+// get decodes the JSON response to a GET request of the path, relative to the base URL, or of an absolute URL
+// returned by the provider to get the next page of a list.
+func (c *client) get(path string, query url.Values, out interface{}) error {
+	location := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		location = strings.TrimSuffix(c.baseURL, "/") + path
+	}
+	if len(query) > 0 {
+		location += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return err
+	}
+	token, err := c.token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	klog.V(4).Infof("GET %s", location)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &statusError{url: location, code: resp.StatusCode, message: strings.TrimSpace(string(body))}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// staticToken returns a token source of a fixed token.
+func staticToken(token string) func() (string, error) {
+	return func() (string, error) {
+		return token, nil
+	}
+}
+
+// clientCredentials returns a token source that requests tokens with the OAuth 2.0 client credentials grant, and
+// caches them until shortly before they expire.
+func clientCredentials(httpClient *http.Client, tokenURL, clientID, clientSecret, scope string) func() (string, error) {
+	var lock sync.Mutex
+	var token string
+	var expiry time.Time
+	return func() (string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		if len(token) > 0 && time.Now().Before(expiry) {
+			return token, nil
+		}
+
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientID},
+			"client_secret": {clientSecret},
+		}
+		if len(scope) > 0 {
+			form.Set("scope", scope)
+		}
+		resp, err := httpClient.PostForm(tokenURL, form)
+		if err != nil {
+			return "", fmt.Errorf("could not request a token: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+			return "", fmt.Errorf("could not request a token: %s returned %d: %s", tokenURL, resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		var response struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int64  `json:"expires_in"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return "", fmt.Errorf("could not decode the token response: %v", err)
+		}
+		if len(response.AccessToken) == 0 {
+			return "", fmt.Errorf("no access token returned by %s", tokenURL)
+		}
+		token = response.AccessToken
+		// renew the token a minute before it expires
+		expiry = time.Now().Add(time.Duration(response.ExpiresIn)*time.Second - time.Minute)
+		return token, nil
+	}
+}
+
+// hostPort returns the host and port of the URL, which identify the groups synced from the provider.
+func hostPort(location string) (string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	if len(u.Port()) > 0 {
+		return u.Host, nil
+	}
+	port := "443"
+	if u.Scheme == "http" {
+		port = "80"
+	}
+	return net.JoinHostPort(u.Hostname(), port), nil
+}
+
+// newUserEntry returns an LDAP entry of a user of the provider, with the user id as DN. The attributes of the entry
+// are the string attributes of the user, and the values of its multi-valued attributes, like the emails of a SCIM
+// user.
+func newUserEntry(id string, user map[string]interface{}) *ldap.Entry {
+	attributes := map[string][]string{}
+	for name, value := range user {
+		switch value := value.(type) {
+		case string:
+			attributes[name] = []string{value}
+		case []interface{}:
+			for _, item := range value {
+				switch item := item.(type) {
+				case string:
+					attributes[name] = append(attributes[name], item)
+				case map[string]interface{}:
+					if v, ok := item["value"].(string); ok {
+						attributes[name] = append(attributes[name], v)
+					}
+				}
+			}
+		}
+	}
+	entry := ldap.NewEntry(id, attributes)
+	// entries are built from maps, sort their attributes to be deterministic
+	sort.Slice(entry.Attributes, func(i, j int) bool { return entry.Attributes[i].Name < entry.Attributes[j].Name })
+	return entry
+}
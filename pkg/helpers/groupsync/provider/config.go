@@ -0,0 +1,181 @@
+package provider
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	legacyconfigv1 "github.com/openshift/api/legacyconfig/v1"
+	"github.com/openshift/library-go/pkg/config/validation"
+	"github.com/openshift/oc/pkg/helpers/groupsync/ldap"
+)
+
+// GroupSyncConfigKind is the kind of the sync config of the group providers that are not LDAP servers.
+const GroupSyncConfigKind = "GroupSyncConfig"
+
+const (
+	defaultAzureADURL      = "https://graph.microsoft.com/v1.0"
+	defaultAzureADLoginURL = "https://login.microsoftonline.com"
+)
+
+// GroupSyncConfig holds the configuration to sync groups with a group provider that is not an LDAP server.
+// Exactly one of the providers must be configured.
+type GroupSyncConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// URL is the base URL of the API of the provider.
+	URL string `json:"url"`
+	// Insecure, if true, indicates that the certificate of the provider is not verified.
+	Insecure bool `json:"insecure"`
+	// CA is the optional trusted certificate authority bundle to use when making requests to the provider.
+	// If empty, the default system roots are used
+	CA string `json:"ca"`
+
+	// GroupUIDToOpenShiftGroupNameMapping is an optional direct mapping of the group UIDs of the provider to
+	// OpenShift Group names
+	GroupUIDToOpenShiftGroupNameMapping map[string]string `json:"groupUIDNameMapping"`
+
+	// SCIM holds the configuration of a SCIM 2.0 service provider.
+	SCIM *SCIMConfig `json:"scim,omitempty"`
+	// Keycloak holds the configuration of the admin API of a Keycloak realm.
+	Keycloak *KeycloakConfig `json:"keycloak,omitempty"`
+	// AzureAD holds the configuration of the Microsoft Graph API of an Azure Active Directory tenant.
+	AzureAD *AzureADConfig `json:"azureAD,omitempty"`
+}
+
+// SCIMConfig holds the configuration of a SCIM 2.0 service provider. The group UIDs are the ids of the SCIM groups,
+// which are named after their displayName.
+type SCIMConfig struct {
+	// BearerToken is the token used to authenticate to the provider.
+	BearerToken legacyconfigv1.StringSource `json:"bearerToken"`
+	// UserNameAttributes are the attributes of a SCIM user, in order, that the OpenShift User name is taken from.
+	// Defaults to userName.
+	UserNameAttributes []string `json:"userNameAttributes"`
+}
+
+// KeycloakConfig holds the configuration of the admin API of a Keycloak realm, which is accessed with the client
+// credentials of a client that has the view-users role of the realm-management client. The group UIDs are the ids
+// of the Keycloak groups, subgroups included, which are named after their name.
+type KeycloakConfig struct {
+	// Realm is the realm whose groups are synced.
+	Realm string `json:"realm"`
+	// ClientID is the id of the client used to authenticate to the admin API.
+	ClientID string `json:"clientID"`
+	// ClientSecret is the secret of the client.
+	ClientSecret legacyconfigv1.StringSource `json:"clientSecret"`
+	// UserNameAttributes are the attributes of a Keycloak user, in order, that the OpenShift User name is taken
+	// from. Defaults to username.
+	UserNameAttributes []string `json:"userNameAttributes"`
+}
+
+// AzureADConfig holds the configuration of the Microsoft Graph API of an Azure Active Directory tenant, which is
+// accessed with the client credentials of an application that has the GroupMember.Read.All and User.Read.All
+// application permissions. The URL defaults to https://graph.microsoft.com/v1.0. The group UIDs are the object ids
+// of the groups, which are named after their displayName. The members of nested groups are members of the group.
+type AzureADConfig struct {
+	// TenantID is the id of the tenant.
+	TenantID string `json:"tenantID"`
+	// ClientID is the application (client) id used to authenticate to the API.
+	ClientID string `json:"clientID"`
+	// ClientSecret is the secret of the application.
+	ClientSecret legacyconfigv1.StringSource `json:"clientSecret"`
+	// LoginURL is the base URL of the Microsoft identity platform. Defaults to https://login.microsoftonline.com.
+	LoginURL string `json:"loginURL"`
+	// GroupFilter is an optional OData filter of the groups to sync, for instance "startswith(displayName,'ocp-')".
+	GroupFilter string `json:"groupFilter"`
+	// UserNameAttributes are the attributes of a user, in order, that the OpenShift User name is taken from.
+	// Defaults to userPrincipalName.
+	UserNameAttributes []string `json:"userNameAttributes"`
+}
+
+// UserNameAttributes returns the attributes of the users of the provider that the OpenShift User name is taken from.
+func (c *GroupSyncConfig) UserNameAttributes() []string {
+	switch {
+	case c.SCIM != nil:
+		if len(c.SCIM.UserNameAttributes) > 0 {
+			return c.SCIM.UserNameAttributes
+		}
+		return []string{"userName"}
+	case c.Keycloak != nil:
+		if len(c.Keycloak.UserNameAttributes) > 0 {
+			return c.Keycloak.UserNameAttributes
+		}
+		return []string{"username"}
+	case c.AzureAD != nil:
+		if len(c.AzureAD.UserNameAttributes) > 0 {
+			return c.AzureAD.UserNameAttributes
+		}
+		return []string{"userPrincipalName"}
+	default:
+		return nil
+	}
+}
+
+// GetStringSourceFileReferences returns the references to the files of the config, to resolve them relative to the
+// config file.
+func GetStringSourceFileReferences(c *GroupSyncConfig) []*string {
+	refs := []*string{&c.CA}
+	if c.SCIM != nil {
+		refs = append(refs, ldap.GetStringSourceFileReferences(&c.SCIM.BearerToken)...)
+	}
+	if c.Keycloak != nil {
+		refs = append(refs, ldap.GetStringSourceFileReferences(&c.Keycloak.ClientSecret)...)
+	}
+	if c.AzureAD != nil {
+		refs = append(refs, ldap.GetStringSourceFileReferences(&c.AzureAD.ClientSecret)...)
+	}
+	return refs
+}
+
+func ValidateGroupSyncConfig(config *GroupSyncConfig) validation.ValidationResults {
+	validationResults := validation.ValidationResults{}
+
+	providers := 0
+	for _, configured := range []bool{config.SCIM != nil, config.Keycloak != nil, config.AzureAD != nil} {
+		if configured {
+			providers++
+		}
+	}
+	if providers != 1 {
+		validationResults.AddErrors(field.Invalid(field.NewPath("scim"), "", "exactly one of scim, keycloak or azureAD must be configured"))
+		return validationResults
+	}
+
+	if len(config.URL) == 0 && config.AzureAD == nil {
+		validationResults.AddErrors(field.Required(field.NewPath("url"), ""))
+	} else if len(config.URL) > 0 {
+		_, urlErrs := validation.ValidateURL(config.URL, field.NewPath("url"))
+		validationResults.AddErrors(urlErrs...)
+	}
+	if len(config.CA) > 0 {
+		validationResults.AddErrors(validation.ValidateFile(config.CA, field.NewPath("ca"))...)
+	}
+
+	switch {
+	case config.SCIM != nil:
+		path := field.NewPath("scim")
+		validationResults.Append(ldap.ValidateStringSource(config.SCIM.BearerToken, path.Child("bearerToken")))
+	case config.Keycloak != nil:
+		path := field.NewPath("keycloak")
+		if len(config.Keycloak.Realm) == 0 {
+			validationResults.AddErrors(field.Required(path.Child("realm"), ""))
+		}
+		if len(config.Keycloak.ClientID) == 0 {
+			validationResults.AddErrors(field.Required(path.Child("clientID"), ""))
+		}
+		validationResults.Append(ldap.ValidateStringSource(config.Keycloak.ClientSecret, path.Child("clientSecret")))
+	case config.AzureAD != nil:
+		path := field.NewPath("azureAD")
+		if len(config.AzureAD.TenantID) == 0 {
+			validationResults.AddErrors(field.Required(path.Child("tenantID"), ""))
+		}
+		if len(config.AzureAD.ClientID) == 0 {
+			validationResults.AddErrors(field.Required(path.Child("clientID"), ""))
+		}
+		validationResults.Append(ldap.ValidateStringSource(config.AzureAD.ClientSecret, path.Child("clientSecret")))
+		if len(config.AzureAD.LoginURL) > 0 {
+			_, urlErrs := validation.ValidateSecureURL(config.AzureAD.LoginURL, path.Child("loginURL"))
+			validationResults.AddErrors(urlErrs...)
+		}
+	}
+
+	return validationResults
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// keycloakPageSize is the number of groups or members requested per page.
+const keycloakPageSize = 100
+
+// keycloakGroup is a group of a Keycloak realm.
+type keycloakGroup struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	SubGroups []*keycloakGroup `json:"subGroups"`
+}
+
+// keycloakProvider lists the groups of a Keycloak realm through the admin API.
+type keycloakProvider struct {
+	client *client
+	host   string
+
+	groups map[string]*keycloakGroup
+}
+
+var _ Interface = &keycloakProvider{}
+
+func newKeycloakProvider(client *client, host string) *keycloakProvider {
+	return &keycloakProvider{
+		client: client,
+		host:   host,
+		groups: map[string]*keycloakGroup{},
+	}
+}
+
+func (p *keycloakProvider) Host() string {
+	return p.host
+}
+
+func (p *keycloakProvider) ListGroups() ([]string, error) {
+	var groupUIDs []string
+	var add func(groups []*keycloakGroup)
+	add = func(groups []*keycloakGroup) {
+		for _, group := range groups {
+			p.groups[group.ID] = group
+			groupUIDs = append(groupUIDs, group.ID)
+			add(group.SubGroups)
+		}
+	}
+
+	for first := 0; ; first += keycloakPageSize {
+		var page []*keycloakGroup
+		query := url.Values{"first": {strconv.Itoa(first)}, "max": {strconv.Itoa(keycloakPageSize)}}
+		if err := p.client.get("/groups", query, &page); err != nil {
+			return nil, err
+		}
+		add(page)
+		if len(page) < keycloakPageSize {
+			return groupUIDs, nil
+		}
+	}
+}
+
+func (p *keycloakProvider) getGroup(groupUID string) (*keycloakGroup, error) {
+	if group, ok := p.groups[groupUID]; ok {
+		return group, nil
+	}
+	group := &keycloakGroup{}
+	if err := p.client.get("/groups/"+url.PathEscape(groupUID), nil, group); err != nil {
+		return nil, err
+	}
+	p.groups[groupUID] = group
+	return group, nil
+}
+
+func (p *keycloakProvider) GroupNameFor(groupUID string) (string, error) {
+	group, err := p.getGroup(groupUID)
+	if err != nil {
+		return "", err
+	}
+	if len(group.Name) == 0 {
+		return "", fmt.Errorf("the Keycloak group %q has no name", groupUID)
+	}
+	return group.Name, nil
+}
+
+func (p *keycloakProvider) ExtractMembers(groupUID string) ([]*ldap.Entry, error) {
+	var members []*ldap.Entry
+	for first := 0; ; first += keycloakPageSize {
+		var page []map[string]interface{}
+		query := url.Values{"first": {strconv.Itoa(first)}, "max": {strconv.Itoa(keycloakPageSize)}}
+		if err := p.client.get("/groups/"+url.PathEscape(groupUID)+"/members", query, &page); err != nil {
+			return nil, err
+		}
+		for _, user := range page {
+			id, _ := user["id"].(string)
+			members = append(members, newUserEntry(id, user))
+		}
+		if len(page) < keycloakPageSize {
+			return members, nil
+		}
+	}
+}
+
+func (p *keycloakProvider) Exists(groupUID string) (bool, error) {
+	if _, err := p.getGroup(groupUID); err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
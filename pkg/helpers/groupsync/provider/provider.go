@@ -0,0 +1,94 @@
+// Package provider syncs groups with group providers that are not LDAP servers, through their REST APIs. The groups
+// of a provider are identified by their UID in the provider, like LDAP groups, and their members are represented as
+// LDAP entries whose attributes are the attributes of the users, so that the providers plug into the LDAP group sync
+// and prune jobs.
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift/oc/pkg/helpers/groupsync/interfaces"
+	"github.com/openshift/oc/pkg/helpers/groupsync/ldap"
+)
+
+// Interface is a group provider.
+type Interface interface {
+	interfaces.LDAPGroupLister
+	interfaces.LDAPMemberExtractor
+	interfaces.LDAPGroupNameMapper
+	interfaces.LDAPGroupDetector
+
+	// Host returns the host:port of the provider, which the groups synced from it are labeled with.
+	Host() string
+}
+
+// New returns the group provider of the config.
+func New(config *GroupSyncConfig) (Interface, error) {
+	httpClient, err := newHTTPClient(config.CA, config.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case config.SCIM != nil:
+		token, err := ldap.ResolveStringValue(config.SCIM.BearerToken)
+		if err != nil {
+			return nil, err
+		}
+		host, err := hostPort(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		return newSCIMProvider(&client{http: httpClient, baseURL: config.URL, token: staticToken(strings.TrimSpace(token))}, host), nil
+
+	case config.Keycloak != nil:
+		secret, err := ldap.ResolveStringValue(config.Keycloak.ClientSecret)
+		if err != nil {
+			return nil, err
+		}
+		host, err := hostPort(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		base := strings.TrimSuffix(config.URL, "/")
+		tokenURL := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/token", base, config.Keycloak.Realm)
+		return newKeycloakProvider(&client{
+			http:    httpClient,
+			baseURL: fmt.Sprintf("%s/admin/realms/%s", base, config.Keycloak.Realm),
+			token:   clientCredentials(httpClient, tokenURL, config.Keycloak.ClientID, strings.TrimSpace(secret), ""),
+		}, host), nil
+
+	case config.AzureAD != nil:
+		secret, err := ldap.ResolveStringValue(config.AzureAD.ClientSecret)
+		if err != nil {
+			return nil, err
+		}
+		apiURL := config.URL
+		if len(apiURL) == 0 {
+			apiURL = defaultAzureADURL
+		}
+		loginURL := config.AzureAD.LoginURL
+		if len(loginURL) == 0 {
+			loginURL = defaultAzureADLoginURL
+		}
+		host, err := hostPort(apiURL)
+		if err != nil {
+			return nil, err
+		}
+		tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(loginURL, "/"), config.AzureAD.TenantID)
+		// the scope of the client credentials grant is the resource, that is the scheme and host of the API
+		scope := "https://graph.microsoft.com/.default"
+		if parts := strings.SplitN(apiURL, "/", 4); len(parts) >= 3 {
+			scope = strings.Join(parts[:3], "/") + "/.default"
+		}
+		return newAzureADProvider(&client{
+			http:    httpClient,
+			baseURL: apiURL,
+			token:   clientCredentials(httpClient, tokenURL, config.AzureAD.ClientID, strings.TrimSpace(secret), scope),
+		}, host, config.AzureAD.GroupFilter), nil
+
+	default:
+		return nil, fmt.Errorf("invalid group sync config: no provider configured")
+	}
+}
@@ -0,0 +1,268 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+
+	legacyconfigv1 "github.com/openshift/api/legacyconfig/v1"
+)
+
+// fakeAPI serves JSON responses by path to requests with a bearer token, and issues client credentials tokens on /token.
+type fakeAPI struct {
+	responses map[string]func(r *http.Request) interface{}
+	tokens    int
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		f.tokens++
+		if r.FormValue("grant_type") != "client_credentials" || r.FormValue("client_id") != "sync" || r.FormValue("client_secret") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "expires_in": 300})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	response, ok := f.responses[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(response(r))
+}
+
+func newFakeAPI(t *testing.T, responses map[string]func(r *http.Request) interface{}) (*fakeAPI, *httptest.Server) {
+	api := &fakeAPI{responses: responses}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return api, server
+}
+
+func static(response interface{}) func(r *http.Request) interface{} {
+	return func(*http.Request) interface{} { return response }
+}
+
+func userNames(entries []*ldap.Entry, attribute string) []string {
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.GetAttributeValue(attribute))
+	}
+	return names
+}
+
+func TestSCIMProvider(t *testing.T) {
+	_, server := newFakeAPI(t, map[string]func(r *http.Request) interface{}{
+		"/scim/Groups": func(r *http.Request) interface{} {
+			// one group per page
+			start, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+			groups := []map[string]interface{}{{"id": "g1"}, {"id": "g2"}, {"id": "g3"}}
+			return map[string]interface{}{"totalResults": 3, "Resources": groups[start-1 : start]}
+		},
+		"/scim/Groups/g1": static(map[string]interface{}{
+			"id": "g1", "displayName": "admins",
+			"members": []map[string]interface{}{{"value": "u1", "type": "User"}, {"value": "g2", "type": "Group"}},
+		}),
+		"/scim/Groups/g2": static(map[string]interface{}{
+			"id": "g2", "displayName": "operators",
+			"members": []map[string]interface{}{{"value": "u2"}, {"value": "u1"}, {"value": "g1", "type": "Group"}},
+		}),
+		"/scim/Users/u1": static(map[string]interface{}{"id": "u1", "userName": "alice", "emails": []map[string]interface{}{{"value": "alice@example.com"}}}),
+		"/scim/Users/u2": static(map[string]interface{}{"id": "u2", "userName": "bob"}),
+	})
+
+	p, err := New(&GroupSyncConfig{URL: server.URL + "/scim", SCIM: &SCIMConfig{BearerToken: legacyconfigv1.StringSource{StringSourceSpec: legacyconfigv1.StringSourceSpec{Value: "token\n"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := p.ListGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(groups, []string{"g1", "g2", "g3"}) {
+		t.Errorf("ListGroups() = %v", groups)
+	}
+
+	name, err := p.GroupNameFor("g2")
+	if err != nil || name != "operators" {
+		t.Errorf("GroupNameFor() = %q, %v", name, err)
+	}
+
+	// nested groups are expanded, cycles included
+	members, err := p.ExtractMembers("g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := userNames(members, "userName"); !reflect.DeepEqual(got, []string{"alice", "bob"}) {
+		t.Errorf("ExtractMembers() = %v", got)
+	}
+	if got := members[0].GetAttributeValue("emails"); got != "alice@example.com" {
+		t.Errorf("emails = %q", got)
+	}
+
+	for groupUID, expected := range map[string]bool{"g1": true, "g4": false} {
+		exists, err := p.Exists(groupUID)
+		if err != nil || exists != expected {
+			t.Errorf("Exists(%s) = %v, %v", groupUID, exists, err)
+		}
+	}
+}
+
+func TestKeycloakProvider(t *testing.T) {
+	api, server := newFakeAPI(t, map[string]func(r *http.Request) interface{}{
+		"/admin/realms/example/groups": static([]map[string]interface{}{
+			{"id": "g1", "name": "admins", "subGroups": []map[string]interface{}{{"id": "g2", "name": "operators"}}},
+		}),
+		"/admin/realms/example/groups/g1/members": func(r *http.Request) interface{} {
+			first, _ := strconv.Atoi(r.URL.Query().Get("first"))
+			var users []map[string]interface{}
+			for i := first; i < first+keycloakPageSize && i < 150; i++ {
+				users = append(users, map[string]interface{}{"id": strconv.Itoa(i), "username": "user" + strconv.Itoa(i)})
+			}
+			return users
+		},
+	})
+	// the token endpoint of the realm is served by the fake API too
+	mux := http.NewServeMux()
+	mux.HandleFunc("/realms/example/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = "/token"
+		api.ServeHTTP(w, r)
+	})
+	mux.Handle("/", api)
+	server.Config.Handler = mux
+
+	p, err := New(&GroupSyncConfig{URL: server.URL, Keycloak: &KeycloakConfig{Realm: "example", ClientID: "sync", ClientSecret: legacyconfigv1.StringSource{StringSourceSpec: legacyconfigv1.StringSourceSpec{Value: "secret"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := p.ListGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(groups, []string{"g1", "g2"}) {
+		t.Errorf("ListGroups() = %v", groups)
+	}
+	if name, err := p.GroupNameFor("g2"); err != nil || name != "operators" {
+		t.Errorf("GroupNameFor() = %q, %v", name, err)
+	}
+
+	members, err := p.ExtractMembers("g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 150 || members[149].GetAttributeValue("username") != "user149" {
+		t.Errorf("ExtractMembers() returned %d members", len(members))
+	}
+	if exists, err := p.Exists("g3"); err != nil || exists {
+		t.Errorf("Exists(g3) = %v, %v", exists, err)
+	}
+	if api.tokens != 1 {
+		t.Errorf("requested %d tokens, expected the token to be cached", api.tokens)
+	}
+}
+
+func TestAzureADProvider(t *testing.T) {
+	var serverURL string
+	api, server := newFakeAPI(t, map[string]func(r *http.Request) interface{}{
+		"/v1.0/groups": func(r *http.Request) interface{} {
+			if r.URL.Query().Get("page") == "2" {
+				return map[string]interface{}{"value": []map[string]interface{}{{"id": "g2", "displayName": "operators"}}}
+			}
+			if r.URL.Query().Get("$filter") != "startswith(displayName,'ocp-')" {
+				t.Errorf("unexpected filter %q", r.URL.Query().Get("$filter"))
+			}
+			return map[string]interface{}{
+				"value":           []map[string]interface{}{{"id": "g1", "displayName": "admins"}},
+				"@odata.nextLink": serverURL + "/v1.0/groups?page=2",
+			}
+		},
+		"/v1.0/groups/g1/transitiveMembers/microsoft.graph.user": static(map[string]interface{}{
+			"value": []map[string]interface{}{{"id": "u1", "userPrincipalName": "alice@example.com", "mail": "alice@example.com"}},
+		}),
+	})
+	serverURL = server.URL
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		if scope := r.FormValue("scope"); scope != server.URL+"/.default" {
+			t.Errorf("unexpected scope %q", scope)
+		}
+		r.URL.Path = "/token"
+		api.ServeHTTP(w, r)
+	})
+	mux.Handle("/", api)
+	server.Config.Handler = mux
+
+	p, err := New(&GroupSyncConfig{URL: server.URL + "/v1.0", AzureAD: &AzureADConfig{
+		TenantID:     "tenant",
+		ClientID:     "sync",
+		ClientSecret: legacyconfigv1.StringSource{StringSourceSpec: legacyconfigv1.StringSourceSpec{Value: "secret"}},
+		LoginURL:     server.URL,
+		GroupFilter:  "startswith(displayName,'ocp-')",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := p.ListGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(groups, []string{"g1", "g2"}) {
+		t.Errorf("ListGroups() = %v", groups)
+	}
+	if name, err := p.GroupNameFor("g2"); err != nil || name != "operators" {
+		t.Errorf("GroupNameFor() = %q, %v", name, err)
+	}
+	members, err := p.ExtractMembers("g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := userNames(members, "userPrincipalName"); !reflect.DeepEqual(got, []string{"alice@example.com"}) {
+		t.Errorf("ExtractMembers() = %v", got)
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	for location, expected := range map[string]string{
+		"https://keycloak.example.com":      "keycloak.example.com:443",
+		"http://keycloak.example.com/auth":  "keycloak.example.com:80",
+		"https://scim.example.com:8443/v2/": "scim.example.com:8443",
+	} {
+		if host, err := hostPort(location); err != nil || host != expected {
+			t.Errorf("hostPort(%s) = %s, %v, expected %s", location, host, err, expected)
+		}
+	}
+}
+
+func TestValidateGroupSyncConfig(t *testing.T) {
+	secret := legacyconfigv1.StringSource{StringSourceSpec: legacyconfigv1.StringSourceSpec{Value: "secret"}}
+	for name, testCase := range map[string]struct {
+		config GroupSyncConfig
+		errors int
+	}{
+		"scim":             {config: GroupSyncConfig{URL: "https://scim.example.com/v2", SCIM: &SCIMConfig{BearerToken: secret}}},
+		"azure defaults":   {config: GroupSyncConfig{AzureAD: &AzureADConfig{TenantID: "tenant", ClientID: "client", ClientSecret: secret}}},
+		"no provider":      {config: GroupSyncConfig{URL: "https://scim.example.com/v2"}, errors: 1},
+		"two providers":    {config: GroupSyncConfig{URL: "https://scim.example.com/v2", SCIM: &SCIMConfig{BearerToken: secret}, Keycloak: &KeycloakConfig{}}, errors: 1},
+		"keycloak missing": {config: GroupSyncConfig{Keycloak: &KeycloakConfig{ClientSecret: secret}}, errors: 3},
+		"invalid url":      {config: GroupSyncConfig{URL: "scim.example.com", SCIM: &SCIMConfig{BearerToken: secret}}, errors: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			results := ValidateGroupSyncConfig(&testCase.config)
+			if len(results.Errors) != testCase.errors {
+				t.Errorf("expected %d errors, got %v", testCase.errors, results.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// scimPageSize is the number of groups requested per page.
+const scimPageSize = 100
+
+// scimGroup is a SCIM 2.0 group.
+type scimGroup struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Members     []struct {
+		Value string `json:"value"`
+		// Type is User or Group, or empty if the provider doesn't support nested groups.
+		Type string `json:"type"`
+	} `json:"members"`
+}
+
+// scimProvider lists the groups of a SCIM 2.0 service provider. Nested groups are expanded to their members.
+type scimProvider struct {
+	client *client
+	host   string
+
+	groups map[string]*scimGroup
+	users  map[string]*ldap.Entry
+}
+
+var _ Interface = &scimProvider{}
+
+func newSCIMProvider(client *client, host string) *scimProvider {
+	return &scimProvider{
+		client: client,
+		host:   host,
+		groups: map[string]*scimGroup{},
+		users:  map[string]*ldap.Entry{},
+	}
+}
+
+func (p *scimProvider) Host() string {
+	return p.host
+}
+
+func (p *scimProvider) ListGroups() ([]string, error) {
+	var groupUIDs []string
+	for start := 1; ; {
+		var page struct {
+			TotalResults int          `json:"totalResults"`
+			Resources    []*scimGroup `json:"Resources"`
+		}
+		query := url.Values{"startIndex": {strconv.Itoa(start)}, "count": {strconv.Itoa(scimPageSize)}, "attributes": {"id,displayName"}}
+		if err := p.client.get("/Groups", query, &page); err != nil {
+			return nil, err
+		}
+		for _, group := range page.Resources {
+			groupUIDs = append(groupUIDs, group.ID)
+		}
+		start += len(page.Resources)
+		if len(page.Resources) == 0 || start > page.TotalResults {
+			return groupUIDs, nil
+		}
+	}
+}
+
+func (p *scimProvider) getGroup(groupUID string) (*scimGroup, error) {
+	if group, ok := p.groups[groupUID]; ok {
+		return group, nil
+	}
+	group := &scimGroup{}
+	if err := p.client.get("/Groups/"+url.PathEscape(groupUID), nil, group); err != nil {
+		return nil, err
+	}
+	p.groups[groupUID] = group
+	return group, nil
+}
+
+func (p *scimProvider) GroupNameFor(groupUID string) (string, error) {
+	group, err := p.getGroup(groupUID)
+	if err != nil {
+		return "", err
+	}
+	if len(group.DisplayName) == 0 {
+		return "", fmt.Errorf("the SCIM group %q has no displayName", groupUID)
+	}
+	return group.DisplayName, nil
+}
+
+func (p *scimProvider) ExtractMembers(groupUID string) ([]*ldap.Entry, error) {
+	var members []*ldap.Entry
+	seen := map[string]bool{}
+	visited := map[string]bool{}
+
+	var extract func(groupUID string) error
+	extract = func(groupUID string) error {
+		if visited[groupUID] {
+			return nil
+		}
+		visited[groupUID] = true
+		group, err := p.getGroup(groupUID)
+		if err != nil {
+			return err
+		}
+		for _, member := range group.Members {
+			if member.Type == "Group" {
+				if err := extract(member.Value); err != nil {
+					return err
+				}
+				continue
+			}
+			if seen[member.Value] {
+				continue
+			}
+			seen[member.Value] = true
+			user, err := p.getUser(member.Value)
+			if err != nil {
+				return err
+			}
+			members = append(members, user)
+		}
+		return nil
+	}
+
+	if err := extract(groupUID); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (p *scimProvider) getUser(id string) (*ldap.Entry, error) {
+	if user, ok := p.users[id]; ok {
+		return user, nil
+	}
+	user := map[string]interface{}{}
+	if err := p.client.get("/Users/"+url.PathEscape(id), nil, &user); err != nil {
+		return nil, fmt.Errorf("could not get SCIM user %q: %v", id, err)
+	}
+	entry := newUserEntry(id, user)
+	p.users[id] = entry
+	return entry, nil
+}
+
+func (p *scimProvider) Exists(groupUID string) (bool, error) {
+	if _, err := p.getGroup(groupUID); err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
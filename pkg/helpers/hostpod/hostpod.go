@@ -0,0 +1,190 @@
+// Package hostpod runs host pods, privileged pods with the file system of their node mounted at /host, in which
+// commands are executed to act on the node, usually with chroot /host. They run in a temporary namespace that allows
+// privileged pods.
+package hostpod
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	kexec "k8s.io/kubectl/pkg/cmd/exec"
+	admissionapi "k8s.io/pod-security-admission/api"
+
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/library-go/pkg/operator/resource/retry"
+)
+
+// Image returns the image of host pods, the openshift/tools image stream or the RHEL support tools if it does
+// not exist.
+func Image(imageClient imagev1client.ImageV1Interface) string {
+	istag, err := imageClient.ImageStreamTags("openshift").Get(context.TODO(), "tools:latest", metav1.GetOptions{})
+	if err != nil {
+		klog.V(2).Infof("Unable to resolve image stream 'openshift/tools:latest': %v", err)
+		return "registry.redhat.io/rhel8/support-tools"
+	}
+	return istag.Image.DockerImageReference
+}
+
+// CreateNamespace creates a temporary namespace for the host pods of command and returns the function that
+// removes it. The creation and removal of the namespace are reported to log.
+func CreateNamespace(client kubernetes.Interface, command string, log io.Writer) (string, func(), error) {
+	ns, err := client.CoreV1().Namespaces().Create(context.TODO(), &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "openshift-debug-",
+			Labels: map[string]string{
+				admissionapi.EnforceLevelLabel:                   string(admissionapi.LevelPrivileged),
+				admissionapi.AuditLevelLabel:                     string(admissionapi.LevelPrivileged),
+				admissionapi.WarnLevelLabel:                      string(admissionapi.LevelPrivileged),
+				"security.openshift.io/scc.podSecurityLabelSync": "false",
+			},
+			Annotations: map[string]string{
+				"oc.openshift.io/command":    command,
+				"openshift.io/node-selector": "",
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create temporary namespace: %v", err)
+	}
+	fmt.Fprintf(log, "Temporary namespace %s is created for %s...\n", ns.Name, command)
+
+	cleanup := func() {
+		if err := client.CoreV1().Namespaces().Delete(context.TODO(), ns.Name, metav1.DeleteOptions{}); err != nil {
+			klog.V(2).Infof("Unable to delete temporary namespace %s: %v", ns.Name, err)
+		} else {
+			fmt.Fprintf(log, "Temporary namespace %s was removed.\n", ns.Name)
+		}
+	}
+	return ns.Name, cleanup, nil
+}
+
+// Start creates a host pod on the node and waits up to timeout for it to run.
+func Start(client kubernetes.Interface, namespace, nodeName, purpose, image string, timeout time.Duration) (*corev1.Pod, error) {
+	pod, err := client.CoreV1().Pods(namespace).Create(context.TODO(), newPod(nodeName, purpose, image), metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	err = wait.PollImmediate(2*time.Second, timeout, func() (bool, error) {
+		pod, err := client.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		if err != nil {
+			if retry.IsHTTPClientError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if len(pod.Status.ContainerStatuses) == 0 {
+			return false, nil
+		}
+		state := pod.Status.ContainerStatuses[0].State
+		if state.Waiting != nil {
+			switch state.Waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+				return true, fmt.Errorf("unable to pull image: %v: %v", state.Waiting.Reason, state.Waiting.Message)
+			}
+		}
+		if state.Terminated != nil {
+			return true, fmt.Errorf("the container terminated: %s", state.Terminated.Reason)
+		}
+		return state.Running != nil, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("the pod %s/%s did not start: %v", pod.Namespace, pod.Name, err)
+	}
+	return pod, nil
+}
+
+// newPod creates a host pod on the node that waits for commands to be executed in it.
+func newPod(nodeName, purpose, image string) *corev1.Pod {
+	zero := int64(0)
+	isTrue := true
+	hostPathType := corev1.HostPathDirectory
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-%s-", nodeName, purpose),
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                      nodeName,
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: &zero,
+			Tolerations: []corev1.Toleration{
+				{
+					// tolerate every taint, the pod must run on the node
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "host",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{
+							Path: "/",
+							Type: &hostPathType,
+						},
+					},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "container-00",
+					Image:   image,
+					Command: []string{"/bin/sh", "-c", "trap : TERM INT; sleep infinity & wait"},
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &isTrue,
+						RunAsUser:  &zero,
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "host",
+							MountPath: "/host",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Exec runs command in the host pod.
+func Exec(client kubernetes.Interface, config *rest.Config, pod *corev1.Pod, command []string, in io.Reader, out, errOut io.Writer) error {
+	klog.V(3).Infof("Running command in pod %s/%s: %s", pod.Namespace, pod.Name, strings.Join(command, " "))
+	execOptions := &kexec.ExecOptions{
+		StreamOptions: kexec.StreamOptions{
+			Namespace:     pod.Namespace,
+			PodName:       pod.Name,
+			ContainerName: pod.Spec.Containers[0].Name,
+			IOStreams: genericclioptions.IOStreams{
+				In:     in,
+				Out:    out,
+				ErrOut: errOut,
+			},
+			Stdin: in != nil,
+		},
+		Executor:  &kexec.DefaultRemoteExecutor{},
+		PodClient: client.CoreV1(),
+		Config:    config,
+		Command:   command,
+	}
+	if err := execOptions.Validate(); err != nil {
+		return err
+	}
+	return execOptions.Run()
+}
+
+// RemoteError adds the error output of a command run on the node to its error.
+func RemoteError(err error, errOut *bytes.Buffer) error {
+	if msg := strings.TrimSpace(errOut.String()); len(msg) > 0 {
+		return fmt.Errorf("%v: %s", err, msg)
+	}
+	return err
+}
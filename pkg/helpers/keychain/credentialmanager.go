@@ -0,0 +1,112 @@
+//go:build windows
+// +build windows
+
+package keychain
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+var (
+	advapi32        = windows.NewLazySystemDLL("advapi32.dll")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+// credential is the CREDENTIALW structure of wincred.h.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialManager stores the secrets as generic credentials of the Windows Credential Manager, named
+// SERVICE:ACCOUNT.
+type credentialManager struct {
+	service string
+}
+
+func newCredentialManager(service string) (Keychain, error) {
+	if err := procCredReadW.Find(); err != nil {
+		return nil, err
+	}
+	return &credentialManager{service: service}, nil
+}
+
+func (k *credentialManager) target(account string) (*uint16, error) {
+	return windows.UTF16PtrFromString(k.service + ":" + account)
+}
+
+func (k *credentialManager) Get(account string) (string, error) {
+	target, err := k.target(account)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	if ret, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred))); ret == 0 {
+		if err == windows.ERROR_NOT_FOUND {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (k *credentialManager) Set(account, secret string) error {
+	target, err := k.target(account)
+	if err != nil {
+		return err
+	}
+	userName, err := windows.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := &credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           userName,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if ret, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(cred)), 0); ret == 0 {
+		return err
+	}
+	return nil
+}
+
+func (k *credentialManager) Delete(account string) error {
+	target, err := k.target(account)
+	if err != nil {
+		return err
+	}
+	if ret, _, err := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); ret == 0 {
+		if err == windows.ERROR_NOT_FOUND {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
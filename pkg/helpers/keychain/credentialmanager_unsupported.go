@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package keychain
+
+import "fmt"
+
+func newCredentialManager(service string) (Keychain, error) {
+	return nil, fmt.Errorf("the Credential Manager is only available on Windows")
+}
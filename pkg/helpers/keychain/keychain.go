@@ -0,0 +1,139 @@
+// Package keychain stores secrets in the keychain of the operating system: the Credential Manager on Windows, and
+// with the command line tools of the keychain, security on macOS and secret-tool (libsecret) on Linux.
+package keychain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNotFound is returned by Get and Delete when the keychain holds no secret for the account.
+var ErrNotFound = errors.New("secret not found in the keychain")
+
+// Keychain stores the secrets of the accounts of a service.
+type Keychain interface {
+	// Get returns the secret of account, or ErrNotFound.
+	Get(account string) (string, error)
+	// Set stores the secret of account, replacing the previous one.
+	Set(account, secret string) error
+	// Delete removes the secret of account, or returns ErrNotFound.
+	Delete(account string) error
+}
+
+// New returns the keychain of the operating system for service, or an error if there is none.
+func New(service string) (Keychain, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err != nil {
+			return nil, fmt.Errorf("the macOS keychain is not available: %v", err)
+		}
+		return &macOSKeychain{service: service}, nil
+	case "linux", "freebsd", "openbsd":
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return nil, fmt.Errorf("the secret service is not available, install secret-tool (libsecret): %v", err)
+		}
+		return &secretServiceKeychain{service: service}, nil
+	case "windows":
+		return newCredentialManager(service)
+	default:
+		return nil, fmt.Errorf("storing secrets in the keychain is not supported on %s", runtime.GOOS)
+	}
+}
+
+type macOSKeychain struct {
+	service string
+}
+
+func (k *macOSKeychain) Get(account string) (string, error) {
+	out, exitCode, err := run(nil, "security", "find-generic-password", "-s", k.service, "-a", account, "-w")
+	if exitCode == 44 {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func (k *macOSKeychain) Set(account, secret string) error {
+	if strings.ContainsAny(secret, "\r\n") {
+		return fmt.Errorf("secrets with line breaks can not be stored in the macOS keychain")
+	}
+	// the command is read from stdin by the interactive mode so that the secret does not show in the arguments of
+	// the process
+	command := []string{"add-generic-password", "-U", "-s", k.service, "-a", account, "-w", secret}
+	for i := range command {
+		command[i] = quoteSecurityArg(command[i])
+	}
+	_, _, err := run(strings.NewReader(strings.Join(command, " ")+"\n"), "security", "-i")
+	return err
+}
+
+// quoteSecurityArg quotes an argument of a command of the interactive mode of security.
+func quoteSecurityArg(arg string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+func (k *macOSKeychain) Delete(account string) error {
+	_, exitCode, err := run(nil, "security", "delete-generic-password", "-s", k.service, "-a", account)
+	if exitCode == 44 {
+		return ErrNotFound
+	}
+	return err
+}
+
+type secretServiceKeychain struct {
+	service string
+}
+
+func (k *secretServiceKeychain) Get(account string) (string, error) {
+	out, exitCode, err := run(nil, "secret-tool", "lookup", "service", k.service, "account", account)
+	if exitCode == 1 && len(out) == 0 {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func (k *secretServiceKeychain) Set(account, secret string) error {
+	// the secret is read from stdin so that it does not show in the arguments of the process
+	_, _, err := run(strings.NewReader(secret), "secret-tool", "store", "--label", k.service+" "+account, "service", k.service, "account", account)
+	return err
+}
+
+func (k *secretServiceKeychain) Delete(account string) error {
+	// secret-tool clear succeeds when there is no secret
+	if _, err := k.Get(account); err != nil {
+		return err
+	}
+	_, _, err := run(nil, "secret-tool", "clear", "service", k.service, "account", account)
+	return err
+}
+
+// run runs name with args and returns its output and its exit code, with an error that includes the error output
+// of the command if it failed.
+func run(stdin *strings.Reader, name string, args ...string) (string, int, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return stdout.String(), exitErr.ExitCode(), fmt.Errorf("%s failed: %s", name, msg)
+		}
+		return stdout.String(), exitErr.ExitCode(), fmt.Errorf("%s failed: %v", name, err)
+	}
+	if err != nil {
+		return "", -1, err
+	}
+	return stdout.String(), 0, nil
+}
@@ -254,6 +254,8 @@ type BuildRef struct {
 	Output                *ImageRef
 	Env                   Environment
 	Binary                bool
+	// Namespace is the namespace of the output image stream, required when the build is not a build config
+	Namespace string
 }
 
 // BuildConfig creates a buildConfig resource from the build configuration reference
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/openshift/library-go/pkg/image/imageutil"
+)
+
+const (
+	// BuildpacksClusterBuildStrategy is the Shipwright cluster build strategy building source code with Cloud
+	// Native Buildpacks.
+	BuildpacksClusterBuildStrategy = "buildpacks-v3"
+
+	// internalRegistryHostname is the address of the integrated image registry, which the builds of image streams
+	// push to.
+	internalRegistryHostname = "image-registry.openshift-image-registry.svc:5000"
+
+	// buildpacksServiceAccount is the service account running the build runs, which may push to the image
+	// streams of the namespace.
+	buildpacksServiceAccount = "builder"
+)
+
+var (
+	// ShipwrightBuildGVK is the kind of the Shipwright builds.
+	ShipwrightBuildGVK = schema.GroupVersionKind{Group: "shipwright.io", Version: "v1alpha1", Kind: "Build"}
+	// ShipwrightBuildRunGVK is the kind of the Shipwright build runs.
+	ShipwrightBuildRunGVK = schema.GroupVersionKind{Group: "shipwright.io", Version: "v1alpha1", Kind: "BuildRun"}
+)
+
+// IsShipwrightBuild returns the object if it is a Shipwright build.
+func IsShipwrightBuild(obj runtime.Object) (*unstructured.Unstructured, bool) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok || u.GroupVersionKind() != ShipwrightBuildGVK {
+		return nil, false
+	}
+	return u, true
+}
+
+// ShipwrightBuild creates a Shipwright build building the source with the buildpacks of the cluster build
+// strategy, and a build run starting its first build, from the build configuration reference.
+func (r *BuildRef) ShipwrightBuild() ([]runtime.Object, error) {
+	if r.Binary || r.Source == nil || r.Source.URL == nil {
+		return nil, fmt.Errorf("the buildpacks strategy requires a source repository with a remote URL")
+	}
+	name, ok := NameSuggestions{r.Source, r.Output}.SuggestName()
+	if !ok {
+		return nil, fmt.Errorf("unable to suggest a name for this Build from %q", r.Source.URL)
+	}
+	if len(r.Source.Secrets) > 0 || len(r.Source.ConfigMaps) > 0 {
+		return nil, fmt.Errorf("build secrets and config maps are not supported with the buildpacks strategy")
+	}
+	image, err := r.shipwrightOutputImage()
+	if err != nil {
+		return nil, err
+	}
+
+	source := map[string]interface{}{
+		"url": r.Source.URL.StringNoFragment(),
+	}
+	if revision := r.Source.URL.URL.Fragment; len(revision) > 0 {
+		source["revision"] = revision
+	}
+	if len(r.Source.ContextDir) > 0 {
+		source["contextDir"] = r.Source.ContextDir
+	}
+	spec := map[string]interface{}{
+		"source": source,
+		"strategy": map[string]interface{}{
+			"name": BuildpacksClusterBuildStrategy,
+			"kind": "ClusterBuildStrategy",
+		},
+		"output": map[string]interface{}{
+			"image": image,
+		},
+	}
+	if len(r.Env) > 0 {
+		env := []interface{}{}
+		for _, v := range r.Env.List() {
+			env = append(env, map[string]interface{}{"name": v.Name, "value": v.Value})
+		}
+		spec["env"] = env
+	}
+
+	build := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name},
+		"spec":     spec,
+	}}
+	build.SetGroupVersionKind(ShipwrightBuildGVK)
+	run := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name + "-1"},
+		"spec": map[string]interface{}{
+			"buildRef":       map[string]interface{}{"name": name},
+			"serviceAccount": map[string]interface{}{"name": buildpacksServiceAccount},
+		},
+	}}
+	run.SetGroupVersionKind(ShipwrightBuildRunGVK)
+	return []runtime.Object{build, run}, nil
+}
+
+// shipwrightOutputImage returns the image the Shipwright build pushes to. The image streams are pushed to
+// through the integrated registry.
+func (r *BuildRef) shipwrightOutputImage() (string, error) {
+	if r.Output == nil {
+		return "", fmt.Errorf("the buildpacks strategy requires an output image")
+	}
+	if !r.Output.AsImageStream {
+		return r.Output.Reference.String(), nil
+	}
+	if len(r.Namespace) == 0 {
+		return "", fmt.Errorf("the namespace of the output image stream is required with the buildpacks strategy")
+	}
+	stream, err := r.Output.ImageStream()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s/%s", internalRegistryHostname, r.Namespace, imageutil.JoinImageStreamTag(stream.Name, r.Output.Reference.Tag)), nil
+}
@@ -202,7 +202,8 @@ func (r UniqueExactOrInexactMatchResolver) Resolve(value string) (*ComponentMatc
 }
 
 // PipelineResolver returns a dummy ComponentMatch for any value input.  It is
-// used to provide a dummy component for for the pipeline/Jenkinsfile strategy.
+// used to provide a dummy component for for the pipeline/Jenkinsfile and the
+// buildpacks strategies.
 type PipelineResolver struct {
 }
 
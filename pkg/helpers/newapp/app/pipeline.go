@@ -256,7 +256,17 @@ func (p *Pipeline) Objects(accept, objectAccept Acceptor) (Objects, error) {
 			}
 		}
 	}
-	if p.Build != nil && accept.Accept(p.Build) {
+	if p.Build != nil && accept.Accept(p.Build) && p.Build.Strategy != nil && p.Build.Strategy.Strategy == newapp.StrategyBuildpacks {
+		builds, err := p.Build.ShipwrightBuild()
+		if err != nil {
+			return nil, err
+		}
+		for _, build := range builds {
+			if objectAccept.Accept(build) {
+				objects = append(objects, build)
+			}
+		}
+	} else if p.Build != nil && accept.Accept(p.Build) {
 		build, err := p.Build.BuildConfig()
 		if err != nil {
 			return nil, err
@@ -7,12 +7,14 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	appsv1 "github.com/openshift/api/apps/v1"
 	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/oc/pkg/helpers/newapp"
 )
 
 type portDesc struct {
@@ -154,6 +156,70 @@ func TestAcceptUnique(t *testing.T) {
 	}
 }
 
+func TestBuildpacksPipelineObjects(t *testing.T) {
+	repo, err := NewSourceRepository("https://github.com/sclorg/nodejs-ex.git#main", newapp.StrategyBuildpacks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.SetContextDir("app")
+	pipeline, err := NewPipelineBuilder("", Environment{"NODE_ENV": "production"}, nil, false).NewBuildPipeline("buildpacks", nil, repo, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline.Build.Namespace = "test"
+
+	objects, err := pipeline.Objects(AcceptAll, AcceptAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expected an image stream, a build and a build run, got %d objects", len(objects))
+	}
+	if is, ok := objects[0].(*imagev1.ImageStream); !ok || is.Name != "nodejs-ex" {
+		t.Errorf("unexpected image stream %#v", objects[0])
+	}
+	build, ok := IsShipwrightBuild(objects[1])
+	if !ok {
+		t.Fatalf("expected a Shipwright build, got %#v", objects[1])
+	}
+	expectedSpec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"url":        "https://github.com/sclorg/nodejs-ex.git",
+			"revision":   "main",
+			"contextDir": "app",
+		},
+		"strategy": map[string]interface{}{"name": "buildpacks-v3", "kind": "ClusterBuildStrategy"},
+		"output": map[string]interface{}{
+			"image": "image-registry.openshift-image-registry.svc:5000/test/nodejs-ex:latest",
+		},
+		"env": []interface{}{map[string]interface{}{"name": "NODE_ENV", "value": "production"}},
+	}
+	if build.GetName() != "nodejs-ex" || !reflect.DeepEqual(build.Object["spec"], expectedSpec) {
+		t.Errorf("unexpected build %s: %s", build.GetName(), diff.ObjectReflectDiff(expectedSpec, build.Object["spec"]))
+	}
+	run, ok := objects[2].(*unstructured.Unstructured)
+	if !ok || run.GroupVersionKind() != ShipwrightBuildRunGVK || run.GetName() != "nodejs-ex-1" {
+		t.Fatalf("expected a Shipwright build run, got %#v", objects[2])
+	}
+	if ref, _, _ := unstructured.NestedString(run.Object, "spec", "buildRef", "name"); ref != "nodejs-ex" {
+		t.Errorf("expected the build run to run the build, got %q", ref)
+	}
+}
+
+func TestBuildpacksPipelineRequiresRemoteSource(t *testing.T) {
+	repo, err := NewSourceRepository("https://github.com/sclorg/nodejs-ex.git", newapp.StrategyBuildpacks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline, err := NewPipelineBuilder("", nil, nil, false).NewBuildPipeline("buildpacks", nil, repo, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pipeline.Objects(AcceptAll, AcceptAll); err == nil {
+		t.Errorf("expected a binary build to be rejected")
+	}
+}
+
 func TestAddServices(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+	"k8s.io/kubectl/pkg/scheme"
+
+	"github.com/openshift/oc/pkg/helpers/newapp/app"
+)
+
+// HelmRenderer renders the manifests of a Helm chart released with the name in the namespace.
+type HelmRenderer func(release, namespace, chart, version string, values []string) ([]byte, error)
+
+// RenderHelmChart renders the manifests of a Helm chart with 'helm template', which must be installed.
+func RenderHelmChart(release, namespace, chart, version string, values []string) ([]byte, error) {
+	helm, err := exec.LookPath("helm")
+	if err != nil {
+		return nil, fmt.Errorf("the helm binary is required to deploy a Helm chart, see https://helm.sh/docs/intro/install/: %v", err)
+	}
+	args := []string{"template", release, chart, "--namespace", namespace, "--skip-tests"}
+	if len(version) > 0 {
+		args = append(args, "--version", version)
+	}
+	for _, value := range values {
+		args = append(args, "--set", value)
+	}
+	klog.V(4).Infof("Running %s %s", helm, strings.Join(args, " "))
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(helm, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("unable to render the Helm chart %q: %v: %s", chart, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// helmReleaseName returns the name of the release of a chart reference, a chart in a repository, a directory, an
+// archive or an OCI reference.
+func helmReleaseName(chart string) string {
+	name := path.Base(strings.TrimSuffix(chart, "/"))
+	name = strings.TrimSuffix(name, ".tgz")
+	return strings.ToLower(name)
+}
+
+// buildHelmChart renders the Helm chart into the objects of the app, and returns the name of its release.
+func (c *AppConfig) buildHelmChart(environment, buildEnvironment app.Environment) (string, []runtime.Object, error) {
+	if len(c.HelmChart) == 0 {
+		return "", nil, nil
+	}
+	if len(c.ContextDir) > 0 {
+		return "", nil, fmt.Errorf("--context-dir is not supported when using a Helm chart")
+	}
+	for _, value := range c.HelmValues {
+		if !strings.Contains(value, "=") {
+			return "", nil, fmt.Errorf("the Helm value %q must be specified as key=value", value)
+		}
+	}
+	release := c.Name
+	if len(release) == 0 {
+		release = helmReleaseName(c.HelmChart)
+	}
+	if err := validateEnforcedName(release); err != nil {
+		return "", nil, fmt.Errorf("the release of the Helm chart %q needs another name, set it with --name: %v", c.HelmChart, err)
+	}
+
+	render := c.HelmRenderer
+	if render == nil {
+		render = RenderHelmChart
+	}
+	klog.V(4).Infof("rendering Helm chart %s as release %s/%s", c.HelmChart, c.OriginNamespace, release)
+	manifests, err := render(release, c.OriginNamespace, c.HelmChart, c.HelmVersion, c.HelmValues)
+	if err != nil {
+		return "", nil, err
+	}
+	objects, err := decodeHelmManifests(manifests)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read the manifests of the Helm chart %q: %v", c.HelmChart, err)
+	}
+	if len(objects) == 0 {
+		return "", nil, fmt.Errorf("the Helm chart %q has no manifests", c.HelmChart)
+	}
+	setObjectsEnvironment(objects, environment, buildEnvironment)
+
+	DescribeGeneratedHelmChart(c.Out, c.HelmChart, release, c.HelmValues, c.OriginNamespace)
+	return release, objects, nil
+}
+
+// decodeHelmManifests decodes the YAML documents rendered by Helm. The objects of types unknown to the client are
+// kept unstructured.
+func decodeHelmManifests(manifests []byte) ([]runtime.Object, error) {
+	objects := []runtime.Object{}
+	r := kyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(manifests)))
+	for {
+		doc, err := r.Read()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := kyaml.ToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		// documents with only comments, like the sources of the templates
+		if len(data) == 0 || string(data) == "null" {
+			continue
+		}
+		obj, err := runtime.Decode(scheme.Codecs.UniversalDeserializer(), data)
+		if runtime.IsNotRegisteredError(err) {
+			obj, err = runtime.Decode(unstructured.UnstructuredJSONScheme, data)
+		}
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+}
+
+// DescribeGeneratedHelmChart writes a description of the Helm chart release to out.
+func DescribeGeneratedHelmChart(out io.Writer, chart, release string, values []string, baseNamespace string) {
+	fmt.Fprintf(out, "--> Deploying Helm chart %q as %q to project %s\n", chart, release, baseNamespace)
+	fmt.Fprintln(out)
+	if len(values) > 0 {
+		fmt.Fprintf(out, "     * With values:\n")
+		for _, value := range values {
+			fmt.Fprintf(out, "        * %s\n", value)
+		}
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/openshift/oc/pkg/helpers/newapp/app"
+)
+
+const helmManifests = `---
+# Source: redis/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: %[1]s-redis
+  namespace: %[2]s
+spec:
+  ports:
+  - port: 6379
+---
+# Source: redis/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: %[1]s-redis
+spec:
+  template:
+    spec:
+      containers:
+      - name: redis
+        image: redis:%[3]s
+        env:
+        - name: REDIS_PORT
+          value: "6379"
+---
+# Source: redis/templates/monitor.yaml
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: %[1]s-redis
+`
+
+func TestBuildHelmChart(t *testing.T) {
+	tests := []struct {
+		name            string
+		chart           string
+		appName         string
+		values          []string
+		env             app.Environment
+		expectedRelease string
+		expectedArgs    string
+		err             string
+	}{
+		{
+			name:            "repository chart",
+			chart:           "bitnami/redis",
+			values:          []string{"image.tag=6.2"},
+			env:             app.Environment{"REDIS_PASSWORD": "secret"},
+			expectedRelease: "redis",
+			expectedArgs:    "redis test bitnami/redis 1.0.0 [image.tag=6.2]",
+		},
+		{
+			name:            "chart archive with a name",
+			chart:           "https://charts.example.com/redis-16.8.5.tgz",
+			appName:         "cache",
+			expectedRelease: "cache",
+			expectedArgs:    "cache test https://charts.example.com/redis-16.8.5.tgz 1.0.0 []",
+		},
+		{
+			name:   "invalid value",
+			chart:  "bitnami/redis",
+			values: []string{"image.tag"},
+			err:    "must be specified as key=value",
+		},
+		{
+			name:  "invalid release name",
+			chart: "./charts/Redis_Cache/",
+			err:   "set it with --name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args string
+			out := &bytes.Buffer{}
+			c := &AppConfig{
+				ComponentInputs:  ComponentInputs{HelmChart: tt.chart},
+				GenerationInputs: GenerationInputs{Name: tt.appName, HelmValues: tt.values, HelmVersion: "1.0.0"},
+				OriginNamespace:  "test",
+				Out:              out,
+				HelmRenderer: func(release, namespace, chart, version string, values []string) ([]byte, error) {
+					args = fmt.Sprintf("%s %s %s %s %v", release, namespace, chart, version, values)
+					tag := "latest"
+					if len(values) > 0 {
+						tag = strings.TrimPrefix(values[0], "image.tag=")
+					}
+					return []byte(fmt.Sprintf(helmManifests, release, namespace, tag)), nil
+				},
+			}
+			release, objects, err := c.buildHelmChart(tt.env, app.Environment{})
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if release != tt.expectedRelease {
+				t.Errorf("expected release %q, got %q", tt.expectedRelease, release)
+			}
+			if args != tt.expectedArgs {
+				t.Errorf("expected the chart to be rendered with %q, got %q", tt.expectedArgs, args)
+			}
+			if !strings.Contains(out.String(), fmt.Sprintf("Deploying Helm chart %q as %q", tt.chart, release)) {
+				t.Errorf("unexpected description:\n%s", out.String())
+			}
+
+			if len(objects) != 3 {
+				t.Fatalf("expected 3 objects, got %d", len(objects))
+			}
+			svc, ok := objects[0].(*corev1.Service)
+			if !ok || svc.Name != release+"-redis" || svc.Namespace != "test" {
+				t.Errorf("unexpected service %#v", objects[0])
+			}
+			deployment, ok := objects[1].(*appsv1.Deployment)
+			if !ok {
+				t.Fatalf("expected a deployment, got %T", objects[1])
+			}
+			expectedEnv := []corev1.EnvVar{{Name: "REDIS_PORT", Value: "6379"}}
+			if len(tt.env) > 0 {
+				expectedEnv = append(tt.env.List(), expectedEnv...)
+			}
+			if env := deployment.Spec.Template.Spec.Containers[0].Env; !reflect.DeepEqual(env, expectedEnv) {
+				t.Errorf("expected environment %v, got %v", expectedEnv, env)
+			}
+			if monitor, ok := objects[2].(*unstructured.Unstructured); !ok || monitor.GetKind() != "ServiceMonitor" {
+				t.Errorf("expected the service monitor to be unstructured, got %#v", objects[2])
+			}
+		})
+	}
+}
+
+func TestBuildHelmChartWithoutManifests(t *testing.T) {
+	c := &AppConfig{
+		ComponentInputs: ComponentInputs{HelmChart: "bitnami/redis"},
+		Out:             &bytes.Buffer{},
+		HelmRenderer: func(release, namespace, chart, version string, values []string) ([]byte, error) {
+			return []byte("---\n# Source: redis/templates/NOTES.txt\n"), nil
+		},
+	}
+	if _, _, err := c.buildHelmChart(app.Environment{}, app.Environment{}); err == nil || !strings.Contains(err.Error(), "no manifests") {
+		t.Errorf("expected an error for a chart without manifests, got %v", err)
+	}
+}
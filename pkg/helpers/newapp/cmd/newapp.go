@@ -19,6 +19,7 @@ import (
 	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	kutilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -68,6 +69,8 @@ type GenerationInputs struct {
 	Labels             map[string]string
 
 	TemplateParameterFiles []string
+	HelmValues             []string
+	HelmVersion            string
 	EnvironmentFiles       []string
 	BuildEnvironmentFiles  []string
 
@@ -114,6 +117,7 @@ type AppConfig struct {
 	SourceSecret   string
 	PushSecret     string
 	SecretAccessor app.SecretAccessor
+	HelmRenderer   HelmRenderer
 
 	AsSearch bool
 	AsList   bool
@@ -488,7 +492,11 @@ func (c *AppConfig) buildPipelines(components app.ComponentReferences, environme
 					image *app.ImageRef
 					err   error
 				)
-				if refInput.ResolvedMatch != nil {
+				buildpacks := refInput.Uses.GetStrategy() == newapp.StrategyBuildpacks
+				if buildpacks && refInput.ResolvedMatch != nil && (refInput.ResolvedMatch.DockerImage != nil || refInput.ResolvedMatch.ImageStream != nil) {
+					return nil, fmt.Errorf("can't build %q: the buildpacks strategy builds with the builder of the %q cluster build strategy, an image can't be specified", refInput.Uses, app.BuildpacksClusterBuildStrategy)
+				}
+				if refInput.ResolvedMatch != nil && !buildpacks {
 					inputImage, err := app.InputImageFromMatch(refInput.ResolvedMatch)
 					if err != nil {
 						return nil, fmt.Errorf("can't build %q: %v", from, err)
@@ -504,6 +512,9 @@ func (c *AppConfig) buildPipelines(components app.ComponentReferences, environme
 				if pipeline, err = pipelineBuilder.NewBuildPipeline(from, image, refInput.Uses, c.BinaryBuild); err != nil {
 					return nil, fmt.Errorf("can't build %q: %v", refInput.Uses, err)
 				}
+				if buildpacks {
+					pipeline.Build.Namespace = c.OriginNamespace
+				}
 			default:
 				inputImage, err := app.InputImageFromMatch(refInput.ResolvedMatch)
 				if err != nil {
@@ -585,33 +596,35 @@ func (c *AppConfig) buildTemplates(components app.ComponentReferences, parameter
 		}
 
 		objects = append(objects, resultObjects...)
-		if len(resultObjects) > 0 {
-			// if environment variables were passed in, let's apply the environment variables
-			// to every pod template object
-			for _, obj := range resultObjects {
-				if bc, ok := obj.(*buildv1.BuildConfig); ok {
-					buildEnv := getBuildConfigEnv(bc)
-					buildEnv = app.JoinEnvironment(buildEnv, buildEnvironment.List())
-					setBuildConfigEnv(bc, buildEnv)
-				}
-				podSpec, _, err := ometa.GetPodSpecV1(obj)
-				if err == nil {
-					for ii := range podSpec.Containers {
-						if podSpec.Containers[ii].Env != nil {
-							podSpec.Containers[ii].Env = app.JoinEnvironment(environment.List(), podSpec.Containers[ii].Env)
-						} else {
-							podSpec.Containers[ii].Env = environment.List()
-						}
-					}
-				}
-			}
-		}
+		setObjectsEnvironment(resultObjects, environment, buildEnvironment)
 
 		DescribeGeneratedTemplate(c.Out, ref.Input().String(), result, c.OriginNamespace)
 	}
 	return name, objects, nil
 }
 
+// setObjectsEnvironment applies the environment variables passed in to every pod template object, and the build
+// environment variables to every build config.
+func setObjectsEnvironment(objects []runtime.Object, environment app.Environment, buildEnvironment app.Environment) {
+	for _, obj := range objects {
+		if bc, ok := obj.(*buildv1.BuildConfig); ok {
+			buildEnv := getBuildConfigEnv(bc)
+			buildEnv = app.JoinEnvironment(buildEnv, buildEnvironment.List())
+			setBuildConfigEnv(bc, buildEnv)
+		}
+		podSpec, _, err := ometa.GetPodSpecV1(obj)
+		if err == nil {
+			for ii := range podSpec.Containers {
+				if podSpec.Containers[ii].Env != nil {
+					podSpec.Containers[ii].Env = app.JoinEnvironment(environment.List(), podSpec.Containers[ii].Env)
+				} else {
+					podSpec.Containers[ii].Env = environment.List()
+				}
+			}
+		}
+	}
+}
+
 // fakeSecretAccessor is used during dry runs of installation
 type fakeSecretAccessor struct {
 	token string
@@ -877,7 +890,7 @@ func (c *AppConfig) Run() (*AppResult, error) {
 	repositories := resolved.Repositories
 	components := resolved.Components
 
-	if len(repositories) == 0 && len(components) == 0 {
+	if len(repositories) == 0 && len(components) == 0 && len(c.HelmChart) == 0 {
 		return nil, ErrNoInputs
 	}
 
@@ -990,12 +1003,21 @@ func (c *AppConfig) Run() (*AppResult, error) {
 		}
 	}
 
+	helmRelease, helmObjects, err := c.buildHelmChart(env, buildenv)
+	if err != nil {
+		return nil, err
+	}
+
 	objects = append(objects, templateObjects...)
+	objects = append(objects, helmObjects...)
 
 	name = c.Name
 	if len(name) == 0 {
 		name = templateName
 	}
+	if len(name) == 0 {
+		name = helmRelease
+	}
 	if len(name) == 0 {
 		for _, pipeline := range pipelines {
 			if pipeline.Deployment != nil {
@@ -1014,6 +1036,10 @@ func (c *AppConfig) Run() (*AppResult, error) {
 				name = bc.Name
 				break
 			}
+			if build, ok := app.IsShipwrightBuild(obj); ok {
+				name = build.GetName()
+				break
+			}
 		}
 	}
 	if len(c.SourceSecret) > 0 {
@@ -1026,6 +1052,13 @@ func (c *AppConfig) Run() (*AppResult, error) {
 				bc.Spec.Source.SourceSecret = &corev1.LocalObjectReference{Name: c.SourceSecret}
 				break
 			}
+			if build, ok := app.IsShipwrightBuild(obj); ok {
+				klog.V(4).Infof("Setting source secret for build to: %v", c.SourceSecret)
+				if err := unstructured.SetNestedField(build.Object, c.SourceSecret, "spec", "source", "credentials", "name"); err != nil {
+					return nil, err
+				}
+				break
+			}
 		}
 	}
 	if len(c.PushSecret) > 0 {
@@ -1038,6 +1071,13 @@ func (c *AppConfig) Run() (*AppResult, error) {
 				bc.Spec.Output.PushSecret = &corev1.LocalObjectReference{Name: c.PushSecret}
 				break
 			}
+			if build, ok := app.IsShipwrightBuild(obj); ok {
+				klog.V(4).Infof("Setting push secret for build to: %v", c.PushSecret)
+				if err := unstructured.SetNestedField(build.Object, c.PushSecret, "spec", "output", "credentials", "name"); err != nil {
+					return nil, err
+				}
+				break
+			}
 		}
 	}
 
@@ -1426,7 +1466,8 @@ func (c *AppConfig) HasArguments() bool {
 		len(c.ImageStreams) > 0 ||
 		len(c.DockerImages) > 0 ||
 		len(c.Templates) > 0 ||
-		len(c.TemplateFiles) > 0
+		len(c.TemplateFiles) > 0 ||
+		len(c.HelmChart) > 0
 }
 
 // getBuildConfigEnv gets the buildconfig strategy environment
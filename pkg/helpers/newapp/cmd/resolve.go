@@ -84,6 +84,7 @@ type ComponentInputs struct {
 	DockerImages  []string
 	Templates     []string
 	TemplateFiles []string
+	HelmChart     string
 
 	Groups []string
 }
@@ -264,7 +265,7 @@ func AddDockerfileToSourceRepositories(b *app.ReferenceBuilder, dockerfile strin
 func DetectSource(repositories []*app.SourceRepository, d app.Detector, g *GenerationInputs) error {
 	errs := []error{}
 	for _, repo := range repositories {
-		err := repo.Detect(d, g.Strategy == newapp.StrategyDocker || g.Strategy == newapp.StrategyPipeline)
+		err := repo.Detect(d, g.Strategy == newapp.StrategyDocker || g.Strategy == newapp.StrategyPipeline || g.Strategy == newapp.StrategyBuildpacks)
 		if err != nil {
 			errs = append(errs, err)
 			continue
@@ -278,6 +279,8 @@ func DetectSource(repositories []*app.SourceRepository, d app.Detector, g *Gener
 			if !repo.Info().Jenkinsfile {
 				errs = append(errs, errors.New("No Jenkinsfile was found in the repository and the requested build strategy is 'pipeline'"))
 			}
+		case newapp.StrategyBuildpacks:
+			// the buildpacks detect the language of the source when building it
 		default:
 			if repo.Info().Dockerfile == nil && !repo.Info().Jenkinsfile && len(repo.Info().Types) == 0 {
 				errs = append(errs, errors.New("No language matched the source repository"))
@@ -544,6 +547,17 @@ func AddMissingComponentsToRefBuilder(
 			errs = append(errs, fmt.Errorf("source not detected for repository %q", repo))
 			continue
 
+		case g.Strategy == newapp.StrategyBuildpacks:
+			refs := b.AddComponents([]string{"buildpacks"}, func(input *app.ComponentInput) app.ComponentReference {
+				input.Resolver = pipelineResolver
+				input.Use(repo)
+				input.ExpectToBuild = true
+				repo.UsedBy(input)
+				repo.SetStrategy(newapp.StrategyBuildpacks)
+				return input
+			})
+			result = append(result, refs...)
+
 		case info.Jenkinsfile && (g.Strategy == newapp.StrategyUnspecified || g.Strategy == newapp.StrategyPipeline):
 			refs := b.AddComponents([]string{"pipeline"}, func(input *app.ComponentInput) app.ComponentReference {
 				input.Resolver = pipelineResolver
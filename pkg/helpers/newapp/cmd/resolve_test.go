@@ -54,6 +54,22 @@ func TestResolveDockerfileAndSource(t *testing.T) {
 	checkResolveResult(t, componentrefs, err, newapp.StrategyDocker)
 }
 
+// TestResolveBuildpacksAndDockerfile ensures that if the buildpacks strategy
+// is requested, we ignore the Dockerfile of the repo.
+func TestResolveBuildpacksAndDockerfile(t *testing.T) {
+	dockerfile, _ := app.NewDockerfile("FROM centos\n")
+	i := app.SourceRepositoryInfo{Dockerfile: dockerfile}
+
+	repo := app.SourceRepository{}
+	repo.SetInfo(&i)
+	repositories := app.SourceRepositories{&repo}
+
+	resolvers := Resolvers{}
+	componentrefs, err := AddMissingComponentsToRefBuilder(&app.ReferenceBuilder{}, repositories, resolvers.DockerfileResolver(), resolvers.SourceResolver(), resolvers.PipelineResolver(), &GenerationInputs{Strategy: newapp.StrategyBuildpacks})
+
+	checkResolveResult(t, componentrefs, err, newapp.StrategyBuildpacks)
+}
+
 func TestBinaryContentFlagGeneratesDummySource(t *testing.T) {
 	component := app.ComponentInput{
 		Value:    "foo",
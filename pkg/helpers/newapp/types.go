@@ -18,6 +18,7 @@ const (
 	StrategySource
 	StrategyDocker
 	StrategyPipeline
+	StrategyBuildpacks
 )
 
 func (s Strategy) String() string {
@@ -30,6 +31,8 @@ func (s Strategy) String() string {
 		return "Docker"
 	case StrategyPipeline:
 		return "pipeline"
+	case StrategyBuildpacks:
+		return "buildpacks"
 	}
 	klog.Error("unknown strategy")
 	return ""
@@ -49,8 +52,10 @@ func (s *Strategy) Set(str string) error {
 		*s = StrategyPipeline
 	case "source":
 		*s = StrategySource
+	case "buildpacks":
+		*s = StrategyBuildpacks
 	default:
-		return fmt.Errorf("invalid strategy: %s. Must be 'docker', 'pipeline' or 'source'.", str)
+		return fmt.Errorf("invalid strategy: %s. Must be 'buildpacks', 'docker', 'pipeline' or 'source'.", str)
 	}
 	return nil
 }
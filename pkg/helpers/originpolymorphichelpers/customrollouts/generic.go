@@ -0,0 +1,175 @@
+package customrollouts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	defaultRevisionAnnotation   = "deployment.kubernetes.io/revision"
+	defaultPodTemplateHashLabel = "pod-template-hash"
+	restartedAtAnnotation       = "kubectl.kubernetes.io/restartedAt"
+
+	progressDeadlineExceededReason = "ProgressDeadlineExceeded"
+)
+
+// GenericViewer views the rollouts of the custom resources following the conventions of deployments: a pod
+// template in spec.template, the counts of replicas in spec.replicas and status, conditions in status.conditions,
+// and revisions of the pod template as the replica sets owned by the resource.
+type GenericViewer struct {
+	// RevisionAnnotation is the annotation of the resource and its replica sets holding their revision,
+	// deployment.kubernetes.io/revision if empty.
+	RevisionAnnotation string
+	// PodTemplateHashLabel is the label added by the controller to the pod templates of the replica sets, removed
+	// on rollbacks, pod-template-hash if empty.
+	PodTemplateHashLabel string
+	// RestartField is the path of the field set to the current time to restart the rollout. The restartedAt
+	// annotation of the pod template is set if empty, as for deployments.
+	RestartField []string
+}
+
+var _ Viewer = &GenericViewer{}
+
+// Status returns the status of the rollout as the status of a deployment, and the state of its conditions.
+func (v *GenericViewer) Status(obj *unstructured.Unstructured, revision int64) (string, bool, error) {
+	kind, name := strings.ToLower(obj.GetKind()), obj.GetName()
+	if revision > 0 {
+		current, err := strconv.ParseInt(obj.GetAnnotations()[v.revisionAnnotation()], 10, 64)
+		if err != nil {
+			return "", false, fmt.Errorf("cannot get the revision of %s %q: %v", kind, name, err)
+		}
+		if revision > current {
+			return "", false, fmt.Errorf("desired revision (%d) is different from the running revision (%d)", revision, current)
+		}
+	}
+
+	if !observed(obj) {
+		return fmt.Sprintf("Waiting for %s spec update to be observed...\n", kind), false, nil
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Progressing" && condition["reason"] == progressDeadlineExceededReason {
+			return "", false, fmt.Errorf("%s %q exceeded its progress deadline", kind, name)
+		}
+		if condition["type"] == "Paused" && condition["status"] == string(metav1.ConditionTrue) {
+			return fmt.Sprintf("Waiting for %s %q rollout to finish: the rollout is paused...\n", kind, name), false, nil
+		}
+	}
+	if paused, _, _ := unstructured.NestedBool(obj.Object, "spec", "paused"); paused {
+		return fmt.Sprintf("Waiting for %s %q rollout to finish: the rollout is paused...\n", kind, name), false, nil
+	}
+
+	desired, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		desired = 1
+	}
+	replicas, _, _ := unstructured.NestedInt64(obj.Object, "status", "replicas")
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	switch {
+	case updated < desired:
+		return fmt.Sprintf("Waiting for %s %q rollout to finish: %d out of %d new replicas have been updated...\n", kind, name, updated, desired), false, nil
+	case replicas > updated:
+		return fmt.Sprintf("Waiting for %s %q rollout to finish: %d old replicas are pending termination...\n", kind, name, replicas-updated), false, nil
+	case available < updated:
+		return fmt.Sprintf("Waiting for %s %q rollout to finish: %d of %d updated replicas are available...\n", kind, name, available, updated), false, nil
+	}
+	return fmt.Sprintf("%s %q successfully rolled out\n", kind, name), true, nil
+}
+
+// observed returns whether the controller observed the last generation of the object. The observed generation
+// may be a number or, like with Argo Rollouts, a string which is not always a number.
+func observed(obj *unstructured.Unstructured) bool {
+	value, found, _ := unstructured.NestedFieldNoCopy(obj.Object, "status", "observedGeneration")
+	if !found {
+		return true
+	}
+	var generation int64
+	switch t := value.(type) {
+	case int64:
+		generation = t
+	case float64:
+		generation = int64(t)
+	case string:
+		parsed, err := strconv.ParseInt(t, 10, 64)
+		if err != nil {
+			return true
+		}
+		generation = parsed
+	default:
+		return true
+	}
+	return obj.GetGeneration() <= generation
+}
+
+// Restart sets the restart field to the current time, or the restartedAt annotation of the pod template.
+func (v *GenericViewer) Restart(obj *unstructured.Unstructured) error {
+	if paused, _, _ := unstructured.NestedBool(obj.Object, "spec", "paused"); paused {
+		return fmt.Errorf("can't restart paused %s (run rollout resume first)", strings.ToLower(obj.GetKind()))
+	}
+	now := time.Now().Format(time.RFC3339)
+	if len(v.RestartField) > 0 {
+		return unstructured.SetNestedField(obj.Object, now, v.RestartField...)
+	}
+	annotations, _, err := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+	if err != nil {
+		return err
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[restartedAtAnnotation] = now
+	return unstructured.SetNestedStringMap(obj.Object, annotations, "spec", "template", "metadata", "annotations")
+}
+
+// Revisions returns the replica sets controlled by the object by their revision annotation.
+func (v *GenericViewer) Revisions(obj *unstructured.Unstructured, replicaSets []appsv1.ReplicaSet) map[int64]*appsv1.ReplicaSet {
+	revisions := map[int64]*appsv1.ReplicaSet{}
+	for i := range replicaSets {
+		rs := &replicaSets[i]
+		owner := metav1.GetControllerOf(rs)
+		if owner == nil || owner.UID != obj.GetUID() {
+			continue
+		}
+		revision, err := strconv.ParseInt(rs.Annotations[v.revisionAnnotation()], 10, 64)
+		if err != nil {
+			continue
+		}
+		revisions[revision] = rs
+	}
+	return revisions
+}
+
+// Rollback replaces the pod template of the object with the one of the replica set, without the pod template hash
+// label added by the controller.
+func (v *GenericViewer) Rollback(obj *unstructured.Unstructured, to *appsv1.ReplicaSet) error {
+	template := to.Spec.Template.DeepCopy()
+	hashLabel := v.PodTemplateHashLabel
+	if len(hashLabel) == 0 {
+		hashLabel = defaultPodTemplateHashLabel
+	}
+	delete(template.Labels, hashLabel)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+	if err != nil {
+		return err
+	}
+	return unstructured.SetNestedField(obj.Object, content, "spec", "template")
+}
+
+func (v *GenericViewer) revisionAnnotation() string {
+	if len(v.RevisionAnnotation) == 0 {
+		return defaultRevisionAnnotation
+	}
+	return v.RevisionAnnotation
+}
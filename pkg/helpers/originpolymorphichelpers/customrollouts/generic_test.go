@@ -0,0 +1,283 @@
+package customrollouts
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func testRollout(image string, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata": map[string]interface{}{
+			"name":        "nginx",
+			"namespace":   "test",
+			"uid":         "1234",
+			"generation":  int64(2),
+			"annotations": map[string]interface{}{"rollout.argoproj.io/revision": "2"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(2),
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "nginx"}},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{"name": "nginx", "image": image}},
+				},
+			},
+		},
+	}}
+	if status != nil {
+		obj.Object["status"] = status
+	}
+	return obj
+}
+
+func testReplicaSet(name, revision, image string) *appsv1.ReplicaSet {
+	controller := true
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "test",
+			Annotations:     map[string]string{"rollout.argoproj.io/revision": revision},
+			OwnerReferences: []metav1.OwnerReference{{UID: "1234", Controller: &controller}},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "nginx", "rollouts-pod-template-hash": name}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: image}}},
+			},
+		},
+	}
+}
+
+func TestGenericViewerStatus(t *testing.T) {
+	viewer, _ := ViewerFor(ArgoRolloutGroupKind)
+	tests := []struct {
+		name     string
+		status   map[string]interface{}
+		expected string
+		done     bool
+		err      string
+	}{
+		{
+			name:     "not observed",
+			status:   map[string]interface{}{"observedGeneration": "1"},
+			expected: "Waiting for rollout spec update to be observed",
+		},
+		{
+			name:     "observed generation hash",
+			status:   map[string]interface{}{"observedGeneration": "5b8f6c9d4", "replicas": int64(2), "updatedReplicas": int64(2), "availableReplicas": int64(2)},
+			expected: `rollout "nginx" successfully rolled out`,
+			done:     true,
+		},
+		{
+			name:     "updating",
+			status:   map[string]interface{}{"observedGeneration": int64(2), "replicas": int64(3), "updatedReplicas": int64(1)},
+			expected: "1 out of 2 new replicas have been updated",
+		},
+		{
+			name:     "terminating",
+			status:   map[string]interface{}{"observedGeneration": int64(2), "replicas": int64(3), "updatedReplicas": int64(2)},
+			expected: "1 old replicas are pending termination",
+		},
+		{
+			name: "paused",
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Paused", "status": "True"},
+			}},
+			expected: "the rollout is paused",
+		},
+		{
+			name: "deadline exceeded",
+			status: map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Progressing", "status": "False", "reason": "ProgressDeadlineExceeded"},
+			}},
+			err: "exceeded its progress deadline",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message, done, err := viewer.Status(testRollout("nginx:2", test.status), 0)
+			if len(test.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("expected error %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(message, test.expected) || done != test.done {
+				t.Errorf("expected %q and done=%t, got %q and done=%t", test.expected, test.done, message, done)
+			}
+		})
+	}
+
+	if _, _, err := viewer.Status(testRollout("nginx:2", nil), 3); err == nil {
+		t.Errorf("expected an error for a revision not rolled out yet")
+	}
+}
+
+func TestGenericViewerRestart(t *testing.T) {
+	argo, _ := ViewerFor(ArgoRolloutGroupKind)
+	obj := testRollout("nginx:2", nil)
+	if err := argo.Restart(obj); err != nil {
+		t.Fatal(err)
+	}
+	if restartAt, _, _ := unstructured.NestedString(obj.Object, "spec", "restartAt"); len(restartAt) == 0 {
+		t.Errorf("expected spec.restartAt to be set, got %#v", obj.Object["spec"])
+	}
+
+	obj = testRollout("nginx:2", nil)
+	if err := (&GenericViewer{}).Restart(obj); err != nil {
+		t.Fatal(err)
+	}
+	if restartedAt, _, _ := unstructured.NestedString(obj.Object, "spec", "template", "metadata", "annotations", restartedAtAnnotation); len(restartedAt) == 0 {
+		t.Errorf("expected the restartedAt annotation to be set, got %#v", obj.Object["spec"])
+	}
+
+	unstructured.SetNestedField(obj.Object, true, "spec", "paused")
+	if err := argo.Restart(obj); err == nil {
+		t.Errorf("expected a paused rollout not to be restarted")
+	}
+}
+
+func TestRollbackerAndHistoryViewer(t *testing.T) {
+	viewer, _ := ViewerFor(ArgoRolloutGroupKind)
+	obj := testRollout("nginx:2", nil)
+	kubeClient := kubefake.NewSimpleClientset(
+		testReplicaSet("nginx-1", "1", "nginx:1"),
+		testReplicaSet("nginx-2", "2", "nginx:2"),
+		testReplicaSet("other-1", "1", "other:1"),
+	)
+	other, _ := kubeClient.AppsV1().ReplicaSets("test").Get(context.TODO(), "other-1", metav1.GetOptions{})
+	other.OwnerReferences[0].UID = "5678"
+	kubeClient.AppsV1().ReplicaSets("test").Update(context.TODO(), other, metav1.UpdateOptions{})
+
+	resource := schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "rollouts"}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{resource: "RolloutList"}, obj)
+
+	history, err := NewHistoryViewer(viewer, dynamicClient.Resource(resource), kubeClient).ViewHistory("test", "nginx", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(history, "REVISION") || !strings.Contains(history, "1 ") || !strings.Contains(history, "2 ") {
+		t.Errorf("unexpected history:\n%s", history)
+	}
+	template, err := NewHistoryViewer(viewer, dynamicClient.Resource(resource), kubeClient).ViewHistory("test", "nginx", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(template, "nginx:1") {
+		t.Errorf("expected the template of the revision 1, got:\n%s", template)
+	}
+
+	result, err := NewRollbacker(viewer, dynamicClient.Resource(resource), kubeClient).Rollback(obj, nil, 0, cmdutil.DryRunNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "rolled back" {
+		t.Errorf("unexpected result %q", result)
+	}
+	updated, err := dynamicClient.Resource(resource).Namespace("test").Get(context.TODO(), "nginx", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	containers, _, _ := unstructured.NestedSlice(updated.Object, "spec", "template", "spec", "containers")
+	if len(containers) != 1 || containers[0].(map[string]interface{})["image"] != "nginx:1" {
+		t.Errorf("expected the template of the revision 1, got %#v", containers)
+	}
+	labels, _, _ := unstructured.NestedStringMap(updated.Object, "spec", "template", "metadata", "labels")
+	if _, ok := labels["rollouts-pod-template-hash"]; ok {
+		t.Errorf("expected the pod template hash label to be removed, got %v", labels)
+	}
+
+	result, err = NewRollbacker(viewer, dynamicClient.Resource(resource), kubeClient).Rollback(obj, nil, 1, cmdutil.DryRunNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "skipped rollback") {
+		t.Errorf("expected the rollback to the current template to be skipped, got %q", result)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	crd := func(name string, annotations map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": name, "annotations": annotations},
+		}}
+	}
+	mapping := func(group, resource, kind string) *meta.RESTMapping {
+		return &meta.RESTMapping{
+			Resource:         schema.GroupVersionResource{Group: group, Version: "v1", Resource: resource},
+			GroupVersionKind: schema.GroupVersionKind{Group: group, Version: "v1", Kind: kind},
+		}
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdResource: "CustomResourceDefinitionList"},
+		crd("canaries.example.com", map[string]interface{}{
+			GenericRolloutAnnotation:       "true",
+			RevisionAnnotationAnnotation:   "example.com/revision",
+			PodTemplateHashLabelAnnotation: "example.com/hash",
+			RestartFieldAnnotation:         "spec.restartAt",
+		}),
+		crd("widgets.example.com", map[string]interface{}{}),
+	)
+
+	tests := []struct {
+		name     string
+		mapping  *meta.RESTMapping
+		expected Viewer
+	}{
+		{
+			name:    "annotated",
+			mapping: mapping("example.com", "canaries", "Canary"),
+			expected: &GenericViewer{
+				RevisionAnnotation:   "example.com/revision",
+				PodTemplateHashLabel: "example.com/hash",
+				RestartField:         []string{"spec", "restartAt"},
+			},
+		},
+		{
+			name:    "not annotated",
+			mapping: mapping("example.com", "widgets", "Widget"),
+		},
+		{
+			name:    "no definition",
+			mapping: mapping("example.com", "gadgets", "Gadget"),
+		},
+		{
+			name:    "built-in",
+			mapping: mapping("apps", "deployments", "Deployment"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viewer, ok, err := Discover(client, test.mapping)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != (test.expected != nil) || !reflect.DeepEqual(viewer, test.expected) {
+				t.Fatalf("expected %#v, got %#v", test.expected, viewer)
+			}
+			if registered, _ := ViewerFor(test.mapping.GroupVersionKind.GroupKind()); !reflect.DeepEqual(registered, test.expected) {
+				t.Errorf("expected %#v to be registered, got %#v", test.expected, registered)
+			}
+		})
+	}
+}
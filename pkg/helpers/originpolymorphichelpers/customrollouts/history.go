@@ -0,0 +1,91 @@
+package customrollouts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/kubectl/pkg/describe"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+// NewHistoryViewer returns the history viewer of kubectl listing the revisions of the custom resources of the
+// dynamic client with the viewer.
+func NewHistoryViewer(viewer Viewer, client dynamic.NamespaceableResourceInterface, kubeClient kubernetes.Interface) polymorphichelpers.HistoryViewer {
+	return &historyViewer{viewer: viewer, client: client, kubeClient: kubeClient}
+}
+
+type historyViewer struct {
+	viewer     Viewer
+	client     dynamic.NamespaceableResourceInterface
+	kubeClient kubernetes.Interface
+}
+
+// ViewHistory returns the revisions of the custom resource with their change cause, or the pod template of the
+// revision if it is not zero.
+func (h *historyViewer) ViewHistory(namespace, name string, revision int64) (string, error) {
+	obj, err := h.client.Namespace(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve %s: %v", name, err)
+	}
+	revisions, err := revisionsOf(h.viewer, h.kubeClient, obj)
+	if err != nil {
+		return "", err
+	}
+	if len(revisions) == 0 {
+		return "No rollout history found.", nil
+	}
+
+	if revision > 0 {
+		rs, ok := revisions[revision]
+		if !ok {
+			return "", fmt.Errorf("unable to find the specified revision")
+		}
+		return printTemplate(&rs.Spec.Template), nil
+	}
+
+	buf := &bytes.Buffer{}
+	w := printers.GetNewTabWriter(buf)
+	fmt.Fprintf(w, "REVISION\tCHANGE-CAUSE\n")
+	for _, r := range sortedRevisions(revisions) {
+		changeCause := revisions[r].Annotations[polymorphichelpers.ChangeCauseAnnotation]
+		if len(changeCause) == 0 {
+			changeCause = "<none>"
+		}
+		fmt.Fprintf(w, "%d\t%s\n", r, changeCause)
+	}
+	w.Flush()
+	return buf.String(), nil
+}
+
+// revisionsOf returns the replica sets of the custom resource by revision.
+func revisionsOf(viewer Viewer, kubeClient kubernetes.Interface, obj *unstructured.Unstructured) (map[int64]*appsv1.ReplicaSet, error) {
+	list, err := kubeClient.AppsV1().ReplicaSets(obj.GetNamespace()).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the replica sets of %s %s: %v", obj.GetKind(), obj.GetName(), err)
+	}
+	return viewer.Revisions(obj, list.Items), nil
+}
+
+func sortedRevisions(revisions map[int64]*appsv1.ReplicaSet) []int64 {
+	sorted := make([]int64, 0, len(revisions))
+	for r := range revisions {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+func printTemplate(template *corev1.PodTemplateSpec) string {
+	buf := &bytes.Buffer{}
+	describe.DescribePodTemplate(template, describe.NewPrefixWriter(buf))
+	return buf.String()
+}
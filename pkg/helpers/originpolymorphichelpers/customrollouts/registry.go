@@ -0,0 +1,119 @@
+package customrollouts
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Viewer views and changes the rollouts of a kind of custom resource, whose revisions are replica sets owned by
+// the resources, like the rollouts of deployments.
+type Viewer interface {
+	// Status returns a message describing the status of the rollout of the object, and whether it is done.
+	Status(obj *unstructured.Unstructured, revision int64) (string, bool, error)
+	// Restart changes the object to restart its rollout.
+	Restart(obj *unstructured.Unstructured) error
+	// Revisions returns the replica sets of the object by revision, among the replica sets of its namespace.
+	Revisions(obj *unstructured.Unstructured, replicaSets []appsv1.ReplicaSet) map[int64]*appsv1.ReplicaSet
+	// Rollback changes the object to roll back to the revision of the replica set.
+	Rollback(obj *unstructured.Unstructured, to *appsv1.ReplicaSet) error
+}
+
+const (
+	// GenericRolloutAnnotation is the annotation of the custom resource definitions whose resources follow the
+	// rollout conventions of deployments, set to "true" to view their rollouts with a GenericViewer.
+	GenericRolloutAnnotation = "rollout.openshift.io/generic"
+	// RevisionAnnotationAnnotation is the annotation of the custom resource definitions overriding the
+	// RevisionAnnotation of their GenericViewer.
+	RevisionAnnotationAnnotation = "rollout.openshift.io/revision-annotation"
+	// PodTemplateHashLabelAnnotation is the annotation of the custom resource definitions overriding the
+	// PodTemplateHashLabel of their GenericViewer.
+	PodTemplateHashLabelAnnotation = "rollout.openshift.io/pod-template-hash-label"
+	// RestartFieldAnnotation is the annotation of the custom resource definitions overriding the RestartField of
+	// their GenericViewer, as a path separated by dots like spec.restartAt.
+	RestartFieldAnnotation = "rollout.openshift.io/restart-field"
+)
+
+var (
+	// ArgoRolloutGroupKind is the kind of the rollouts of Argo Rollouts.
+	ArgoRolloutGroupKind = schema.GroupKind{Group: "argoproj.io", Kind: "Rollout"}
+
+	viewersLock sync.RWMutex
+	viewers     = map[schema.GroupKind]Viewer{
+		ArgoRolloutGroupKind: &GenericViewer{
+			RevisionAnnotation:   "rollout.argoproj.io/revision",
+			PodTemplateHashLabel: "rollouts-pod-template-hash",
+			RestartField:         []string{"spec", "restartAt"},
+		},
+	}
+)
+
+// Register registers the viewer of the rollouts of the kind of custom resource, replacing the registered one if
+// any.
+func Register(kind schema.GroupKind, viewer Viewer) {
+	viewersLock.Lock()
+	defer viewersLock.Unlock()
+	viewers[kind] = viewer
+}
+
+// ViewerFor returns the viewer registered for the kind of custom resource, if any.
+func ViewerFor(kind schema.GroupKind) (Viewer, bool) {
+	viewersLock.RLock()
+	defer viewersLock.RUnlock()
+	viewer, ok := viewers[kind]
+	return viewer, ok
+}
+
+var crdResource = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// Discover returns the viewer of the kind of the mapping, registering a GenericViewer for it first if it has none
+// and its custom resource definition has the GenericRolloutAnnotation. Built-in kinds and definitions that can't be
+// read are ignored.
+func Discover(client dynamic.Interface, mapping *meta.RESTMapping) (Viewer, bool, error) {
+	kind := mapping.GroupVersionKind.GroupKind()
+	if viewer, ok := ViewerFor(kind); ok {
+		return viewer, true, nil
+	}
+	// the groups of the custom resources have at least one dot
+	if !strings.Contains(kind.Group, ".") {
+		return nil, false, nil
+	}
+	crd, err := client.Resource(crdResource).Get(context.TODO(), mapping.Resource.GroupResource().String(), metav1.GetOptions{})
+	switch {
+	case kerrors.IsNotFound(err), kerrors.IsForbidden(err):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	}
+	viewer, ok := viewerFromCRD(crd)
+	if !ok {
+		return nil, false, nil
+	}
+	Register(kind, viewer)
+	return viewer, true, nil
+}
+
+// viewerFromCRD returns the GenericViewer described by the annotations of the custom resource definition, if it
+// has the GenericRolloutAnnotation.
+func viewerFromCRD(crd *unstructured.Unstructured) (Viewer, bool) {
+	annotations := crd.GetAnnotations()
+	if annotations[GenericRolloutAnnotation] != "true" {
+		return nil, false
+	}
+	viewer := &GenericViewer{
+		RevisionAnnotation:   annotations[RevisionAnnotationAnnotation],
+		PodTemplateHashLabel: annotations[PodTemplateHashLabelAnnotation],
+	}
+	if field := annotations[RestartFieldAnnotation]; len(field) > 0 {
+		viewer.RestartField = strings.Split(field, ".")
+	}
+	return viewer, true
+}
@@ -0,0 +1,127 @@
+package customrollouts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+// NewRollbacker returns the rollbacker of kubectl rolling back the custom resources of the dynamic client with the
+// viewer.
+func NewRollbacker(viewer Viewer, client dynamic.NamespaceableResourceInterface, kubeClient kubernetes.Interface) polymorphichelpers.Rollbacker {
+	return &rollbacker{viewer: viewer, client: client, kubeClient: kubeClient}
+}
+
+type rollbacker struct {
+	viewer     Viewer
+	client     dynamic.NamespaceableResourceInterface
+	kubeClient kubernetes.Interface
+}
+
+// Rollback restores the pod template of the revision of the custom resource, or of its previous revision if
+// toRevision is zero.
+func (r *rollbacker) Rollback(obj runtime.Object, updatedAnnotations map[string]string, toRevision int64, dryRunStrategy cmdutil.DryRunStrategy) (string, error) {
+	if toRevision < 0 {
+		return "", fmt.Errorf("unable to find specified revision %d in history", toRevision)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	current, err := r.client.Namespace(accessor.GetNamespace()).Get(context.TODO(), accessor.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve %s: %v", accessor.GetName(), err)
+	}
+	revisions, err := revisionsOf(r.viewer, r.kubeClient, current)
+	if err != nil {
+		return "", err
+	}
+	if toRevision == 0 {
+		sorted := sortedRevisions(revisions)
+		if len(sorted) < 2 {
+			return "", fmt.Errorf("no rollout history found for %s %q", strings.ToLower(current.GetKind()), current.GetName())
+		}
+		toRevision = sorted[len(sorted)-2]
+	}
+	rs, ok := revisions[toRevision]
+	if !ok {
+		return "", fmt.Errorf("unable to find specified revision %d in history", toRevision)
+	}
+	if dryRunStrategy == cmdutil.DryRunClient {
+		return printTemplate(&rs.Spec.Template), nil
+	}
+	if paused, _, _ := unstructured.NestedBool(current.Object, "spec", "paused"); paused {
+		return "", fmt.Errorf("you cannot rollback a paused %s; resume it first with 'oc rollout resume' and try again", strings.ToLower(current.GetKind()))
+	}
+
+	updated := current.DeepCopy()
+	if err := r.viewer.Rollback(updated, rs); err != nil {
+		return "", err
+	}
+	if sameTemplate(current, updated) {
+		return fmt.Sprintf("skipped rollback (current template already matches revision %d)", toRevision), nil
+	}
+	if len(updatedAnnotations) > 0 {
+		annotations := updated.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		for k, v := range updatedAnnotations {
+			annotations[k] = v
+		}
+		updated.SetAnnotations(annotations)
+	}
+
+	patch, err := MergePatch(current, updated)
+	if err != nil {
+		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	}
+	options := metav1.PatchOptions{}
+	if dryRunStrategy == cmdutil.DryRunServer {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	if _, err := r.client.Namespace(current.GetNamespace()).Patch(context.TODO(), current.GetName(), types.MergePatchType, patch, options); err != nil {
+		return "", fmt.Errorf("failed restoring revision %d: %v", toRevision, err)
+	}
+	return "rolled back", nil
+}
+
+// sameTemplate returns whether the objects have the same pod template.
+func sameTemplate(a, b *unstructured.Unstructured) bool {
+	templates := [2]corev1.PodTemplateSpec{}
+	for i, obj := range []*unstructured.Unstructured{a, b} {
+		content, _, _ := unstructured.NestedMap(obj.Object, "spec", "template")
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, &templates[i]); err != nil {
+			return false
+		}
+	}
+	return equality.Semantic.DeepEqual(templates[0], templates[1])
+}
+
+// MergePatch returns the JSON merge patch changing the original object to the modified one, which the custom
+// resources support unlike strategic merge patches.
+func MergePatch(original, modified *unstructured.Unstructured) ([]byte, error) {
+	before, err := original.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	after, err := modified.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return jsonpatch.CreateMergePatch(before, after)
+}
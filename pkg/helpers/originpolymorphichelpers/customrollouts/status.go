@@ -0,0 +1,20 @@
+package customrollouts
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+)
+
+// NewStatusViewer returns the status viewer of kubectl showing the status of the rollouts with the viewer.
+func NewStatusViewer(viewer Viewer) polymorphichelpers.StatusViewer {
+	return &statusViewer{viewer: viewer}
+}
+
+type statusViewer struct {
+	viewer Viewer
+}
+
+func (s *statusViewer) Status(obj runtime.Unstructured, revision int64) (string, bool, error) {
+	return s.viewer.Status(&unstructured.Unstructured{Object: obj.UnstructuredContent()}, revision)
+}
@@ -3,10 +3,13 @@ package originpolymorphichelpers
 import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/kubectl/pkg/polymorphichelpers"
 
 	appsv1 "github.com/openshift/api/apps/v1"
 	appsclient "github.com/openshift/client-go/apps/clientset/versioned"
+	"github.com/openshift/oc/pkg/helpers/originpolymorphichelpers/customrollouts"
 	deploymentcmd "github.com/openshift/oc/pkg/helpers/originpolymorphichelpers/deploymentconfigs"
 )
 
@@ -23,6 +26,21 @@ func NewRollbackerFn(delegate polymorphichelpers.RollbackerFunc) polymorphichelp
 			}
 			return deploymentcmd.NewDeploymentConfigRollbacker(appsClient), nil
 		}
+		if viewer, ok := customrollouts.ViewerFor(mapping.GroupVersionKind.GroupKind()); ok {
+			config, err := restClientGetter.ToRESTConfig()
+			if err != nil {
+				return nil, err
+			}
+			dynamicClient, err := dynamic.NewForConfig(config)
+			if err != nil {
+				return nil, err
+			}
+			kubeClient, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return nil, err
+			}
+			return customrollouts.NewRollbacker(viewer, dynamicClient.Resource(mapping.Resource), kubeClient), nil
+		}
 		return delegate(restClientGetter, mapping)
 	}
 }
@@ -0,0 +1,18 @@
+package originpolymorphichelpers
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kubectl/pkg/polymorphichelpers"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+)
+
+// SelectorsForObject returns the namespace and the selector of the pods of the object, supporting deployment
+// configs in addition to the objects supported by polymorphichelpers.SelectorsForObject.
+func SelectorsForObject(object runtime.Object) (string, labels.Selector, error) {
+	if dc, ok := object.(*appsv1.DeploymentConfig); ok {
+		return dc.Namespace, labels.SelectorFromSet(dc.Spec.Selector), nil
+	}
+	return polymorphichelpers.SelectorsForObject(object)
+}
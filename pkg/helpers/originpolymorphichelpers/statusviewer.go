@@ -5,6 +5,7 @@ import (
 	"k8s.io/kubectl/pkg/polymorphichelpers"
 
 	appsv1 "github.com/openshift/api/apps/v1"
+	"github.com/openshift/oc/pkg/helpers/originpolymorphichelpers/customrollouts"
 	deploymentcmd "github.com/openshift/oc/pkg/helpers/originpolymorphichelpers/deploymentconfigs"
 )
 
@@ -13,6 +14,9 @@ func NewStatusViewerFn(delegate polymorphichelpers.StatusViewerFunc) polymorphic
 		if appsv1.SchemeGroupVersion.WithKind("DeploymentConfig").GroupKind() == mapping.GroupVersionKind.GroupKind() {
 			return deploymentcmd.NewDeploymentConfigStatusViewer(), nil
 		}
+		if viewer, ok := customrollouts.ViewerFor(mapping.GroupVersionKind.GroupKind()); ok {
+			return customrollouts.NewStatusViewer(viewer), nil
+		}
 
 		return delegate(mapping)
 	}
@@ -0,0 +1,81 @@
+package term
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FuzzyMatch returns the candidates that match query, ignoring case, best matches first: the candidates equal to
+// query, then the candidates that start with query, then the candidates that contain query, then the candidates
+// that contain the characters of query in order. Candidates that match equally keep their order. All the
+// candidates match an empty query.
+func FuzzyMatch(candidates []string, query string) []string {
+	query = strings.ToLower(query)
+	var matches []string
+	scores := map[string]int{}
+	for _, candidate := range candidates {
+		if score := fuzzyScore(strings.ToLower(candidate), query); score > 0 {
+			matches = append(matches, candidate)
+			scores[candidate] = score
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return scores[matches[i]] > scores[matches[j]]
+	})
+	return matches
+}
+
+// fuzzyScore returns how well candidate matches query, or 0 if it does not match.
+func fuzzyScore(candidate, query string) int {
+	switch {
+	case len(query) == 0:
+		return 1
+	case candidate == query:
+		return 4
+	case strings.HasPrefix(candidate, query):
+		return 3
+	case strings.Contains(candidate, query):
+		return 2
+	}
+	rest := candidate
+	for _, r := range query {
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return 0
+		}
+		rest = rest[i+len(string(r)):]
+	}
+	return 1
+}
+
+// Pick prints the items with printItems and asks the user to pick one by number, or to type text that filters
+// the items fuzzily until one is left. It returns an empty string if the user enters nothing. noun names an item
+// in the prompt and the messages.
+func Pick(r io.Reader, w, errOut io.Writer, noun string, items []string, printItems func(items []string)) string {
+	for {
+		printItems(items)
+		input := strings.TrimSpace(PromptForString(r, w, "\nSelect a %s by number, or type to filter: ", noun))
+		if len(input) == 0 {
+			return ""
+		}
+		if i, err := strconv.Atoi(input); err == nil {
+			if i < 1 || i > len(items) {
+				fmt.Fprintf(errOut, "%d is not between 1 and %d\n\n", i, len(items))
+				continue
+			}
+			return items[i-1]
+		}
+
+		switch filtered := FuzzyMatch(items, input); len(filtered) {
+		case 0:
+			fmt.Fprintf(errOut, "No %s matches %q\n\n", noun, input)
+		case 1:
+			return filtered[0]
+		default:
+			items = filtered
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package term
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	candidates := []string{"p-r-o-d", "a-prod-b", "production", "Prod", "dev"}
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "", expected: candidates},
+		{query: "prod", expected: []string{"Prod", "production", "a-prod-b", "p-r-o-d"}},
+		{query: "PRD", expected: []string{"p-r-o-d", "a-prod-b", "production", "Prod"}},
+		{query: "dv", expected: []string{"dev"}},
+		{query: "test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if matches := FuzzyMatch(candidates, tt.query); !reflect.DeepEqual(matches, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, matches)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package term
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/docker/docker/pkg/term"
+	"github.com/spf13/pflag"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/kubectl/pkg/cmd/attach"
+	"k8s.io/kubectl/pkg/cmd/exec"
+)
+
+// SessionOptions record the interactive sessions in a file in the asciicast v2 format of asciinema, and end the
+// sessions that have no input or output for the idle timeout.
+type SessionOptions struct {
+	RecordFile  string
+	IdleTimeout time.Duration
+	// Title is the title of the recording
+	Title string
+}
+
+// SessionFunc runs a session with the streams.
+type SessionFunc func(stdin io.Reader, stdout, stderr io.Writer, sizes remotecommand.TerminalSizeQueue) error
+
+// AddFlags adds the --record and --idle-timeout flags.
+func (o *SessionOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.RecordFile, "record", o.RecordFile, "Record the session in this file, in the asciicast format of asciinema")
+	flags.DurationVar(&o.IdleTimeout, "idle-timeout", o.IdleTimeout, "End the session when it has no input or output for this long, 0 to never end it")
+}
+
+// Validate checks the idle timeout.
+func (o *SessionOptions) Validate() error {
+	if o.IdleTimeout < 0 {
+		return fmt.Errorf("--idle-timeout must not be negative")
+	}
+	return nil
+}
+
+// Run runs fn, recording the output of the session and ending it when it is idle.
+func (o *SessionOptions) Run(stdin io.Reader, stdout, stderr io.Writer, sizes remotecommand.TerminalSizeQueue, fn SessionFunc) error {
+	if len(o.RecordFile) == 0 && o.IdleTimeout == 0 {
+		return fn(stdin, stdout, stderr, sizes)
+	}
+
+	s := &session{}
+	s.touch()
+	if len(o.RecordFile) > 0 {
+		f, err := os.OpenFile(o.RecordFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("unable to record the session: %v", err)
+		}
+		s.recorder = &recorder{w: f, start: time.Now()}
+		// the session may still write when it is ended
+		defer func() {
+			s.recorder.close()
+			f.Close()
+		}()
+		width, height := terminalSize(stdout)
+		if err := s.recorder.header(width, height, o.Title); err != nil {
+			return fmt.Errorf("unable to record the session: %v", err)
+		}
+		if sizes != nil {
+			sizes = &recordedSizes{TerminalSizeQueue: sizes, recorder: s.recorder}
+		}
+	}
+	if stdin != nil {
+		stdin = &sessionReader{Reader: stdin, session: s}
+	}
+	if stdout != nil {
+		stdout = &sessionWriter{Writer: stdout, session: s}
+	}
+	if stderr != nil {
+		stderr = &sessionWriter{Writer: stderr, session: s}
+	}
+
+	if o.IdleTimeout == 0 {
+		return fn(stdin, stdout, stderr, sizes)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(stdin, stdout, stderr, sizes)
+	}()
+	for {
+		idle := s.idle()
+		if idle >= o.IdleTimeout {
+			return fmt.Errorf("the session was ended after being idle for %s", o.IdleTimeout)
+		}
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(o.IdleTimeout - idle):
+		}
+	}
+}
+
+// session tracks the activity of a session, and records its output.
+type session struct {
+	// lastActivity is the time of the last input or output in nanoseconds
+	lastActivity int64
+	recorder     *recorder
+}
+
+func (s *session) touch() {
+	atomic.StoreInt64(&s.lastActivity, time.Now().UnixNano())
+}
+
+func (s *session) idle() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&s.lastActivity)))
+}
+
+type sessionReader struct {
+	io.Reader
+	session *session
+}
+
+func (r *sessionReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.session.touch()
+	}
+	return n, err
+}
+
+type sessionWriter struct {
+	io.Writer
+	session *session
+}
+
+func (w *sessionWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	if n > 0 {
+		w.session.touch()
+		if w.session.recorder != nil {
+			w.session.recorder.output(p[:n])
+		}
+	}
+	return n, err
+}
+
+// recordedSizes records the terminal resizes.
+type recordedSizes struct {
+	remotecommand.TerminalSizeQueue
+	recorder *recorder
+}
+
+func (q *recordedSizes) Next() *remotecommand.TerminalSize {
+	size := q.TerminalSizeQueue.Next()
+	if size != nil {
+		q.recorder.event("r", fmt.Sprintf("%dx%d", size.Width, size.Height))
+	}
+	return size
+}
+
+// recorder writes the asciicast v2 header and events, one JSON document per line.
+type recorder struct {
+	lock  sync.Mutex
+	w     io.Writer
+	start time.Time
+	// pending is the start of a UTF-8 character whose end was not written yet
+	pending []byte
+	closed  bool
+}
+
+func (r *recorder) header(width, height uint16, title string) error {
+	header := map[string]interface{}{
+		"version":   2,
+		"width":     width,
+		"height":    height,
+		"timestamp": r.start.Unix(),
+	}
+	if len(title) > 0 {
+		header["title"] = title
+	}
+	if value := os.Getenv("TERM"); len(value) > 0 {
+		header["env"] = map[string]string{"TERM": value}
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(r.w, "%s\n", data)
+	return err
+}
+
+// output records the output, keeping the incomplete UTF-8 character at its end for the next output.
+func (r *recorder) output(p []byte) {
+	r.lock.Lock()
+	data := append(r.pending, p...)
+	r.pending = nil
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				r.pending = append([]byte(nil), data[i:]...)
+				data = data[:i]
+			}
+			break
+		}
+	}
+	r.lock.Unlock()
+	if len(data) > 0 {
+		r.event("o", string(data))
+	}
+}
+
+func (r *recorder) event(kind, data string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return
+	}
+	elapsed := math.Round(time.Since(r.start).Seconds()*1e6) / 1e6
+	line, err := json.Marshal([]interface{}{elapsed, kind, data})
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(r.w, "%s\n", line)
+}
+
+func (r *recorder) close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.closed = true
+}
+
+// terminalSize returns the size of the terminal of w, or 80x24 if w is not a terminal.
+func terminalSize(w io.Writer) (uint16, uint16) {
+	if f, ok := w.(interface{ Fd() uintptr }); ok && term.IsTerminal(f.Fd()) {
+		if size, err := term.GetWinsize(f.Fd()); err == nil && size.Width > 0 && size.Height > 0 {
+			return size.Width, size.Height
+		}
+	}
+	return 80, 24
+}
+
+// SessionExecutor runs the sessions of RemoteExecutor with Session.
+type SessionExecutor struct {
+	exec.RemoteExecutor
+	Session *SessionOptions
+}
+
+func (e *SessionExecutor) Execute(method string, url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
+	return e.Session.Run(stdin, stdout, stderr, terminalSizeQueue, func(stdin io.Reader, stdout, stderr io.Writer, sizes remotecommand.TerminalSizeQueue) error {
+		return e.RemoteExecutor.Execute(method, url, config, stdin, stdout, stderr, tty, sizes)
+	})
+}
+
+// SessionAttach runs the sessions of RemoteAttach with Session.
+type SessionAttach struct {
+	attach.RemoteAttach
+	Session *SessionOptions
+}
+
+func (a *SessionAttach) Attach(method string, url *url.URL, config *restclient.Config, stdin io.Reader, stdout, stderr io.Writer, tty bool, terminalSizeQueue remotecommand.TerminalSizeQueue) error {
+	return a.Session.Run(stdin, stdout, stderr, terminalSizeQueue, func(stdin io.Reader, stdout, stderr io.Writer, sizes remotecommand.TerminalSizeQueue) error {
+		return a.RemoteAttach.Attach(method, url, config, stdin, stdout, stderr, tty, sizes)
+	})
+}
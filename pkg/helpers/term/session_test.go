@@ -0,0 +1,123 @@
+package term
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+type fakeSizeQueue struct {
+	sizes []remotecommand.TerminalSize
+}
+
+func (q *fakeSizeQueue) Next() *remotecommand.TerminalSize {
+	if len(q.sizes) == 0 {
+		return nil
+	}
+	size := q.sizes[0]
+	q.sizes = q.sizes[1:]
+	return &size
+}
+
+func TestSessionRecord(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "session.cast")
+	o := &SessionOptions{RecordFile: file, Title: "oc rsh foo"}
+	out := &bytes.Buffer{}
+	sizes := &fakeSizeQueue{sizes: []remotecommand.TerminalSize{{Width: 120, Height: 40}}}
+
+	err := o.Run(strings.NewReader("ls\n"), out, ioutil.Discard, sizes, func(stdin io.Reader, stdout, stderr io.Writer, sizes remotecommand.TerminalSizeQueue) error {
+		if input, _ := ioutil.ReadAll(stdin); string(input) != "ls\n" {
+			t.Errorf("unexpected input %q", input)
+		}
+		sizes.Next()
+		stdout.Write([]byte("$ ls\r\n"))
+		// a character split between two writes
+		euro := []byte("€\r\n")
+		stdout.Write(euro[:2])
+		stdout.Write(euro[2:])
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "$ ls\r\n€\r\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected a header and 3 events, got:\n%s", data)
+	}
+	header := struct {
+		Version int    `json:"version"`
+		Width   int    `json:"width"`
+		Height  int    `json:"height"`
+		Title   string `json:"title"`
+	}{}
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Width != 80 || header.Height != 24 || header.Title != "oc rsh foo" {
+		t.Errorf("unexpected header %s", lines[0])
+	}
+	expected := [][2]string{{"r", "120x40"}, {"o", "$ ls\r\n"}, {"o", "€\r\n"}}
+	for i, line := range lines[1:] {
+		var event []interface{}
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		if len(event) != 3 {
+			t.Fatalf("unexpected event %s", line)
+		}
+		if _, ok := event[0].(float64); !ok {
+			t.Errorf("expected the time of the event, got %s", line)
+		}
+		if event[1] != expected[i][0] || event[2] != expected[i][1] {
+			t.Errorf("expected event %v, got %s", expected[i], line)
+		}
+	}
+}
+
+func TestSessionIdleTimeout(t *testing.T) {
+	o := &SessionOptions{IdleTimeout: 200 * time.Millisecond}
+	block := make(chan struct{})
+	defer close(block)
+
+	start := time.Now()
+	err := o.Run(nil, ioutil.Discard, ioutil.Discard, nil, func(stdin io.Reader, stdout, stderr io.Writer, sizes remotecommand.TerminalSizeQueue) error {
+		// the activity delays the end of the session
+		for i := 0; i < 3; i++ {
+			time.Sleep(100 * time.Millisecond)
+			stdout.Write([]byte("."))
+		}
+		<-block
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "idle") {
+		t.Fatalf("expected the idle session to be ended, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("the session was ended after %s despite its activity", elapsed)
+	}
+}
+
+func TestSessionCompletes(t *testing.T) {
+	o := &SessionOptions{IdleTimeout: time.Minute}
+	err := o.Run(nil, ioutil.Discard, ioutil.Discard, nil, func(stdin io.Reader, stdout, stderr io.Writer, sizes remotecommand.TerminalSizeQueue) error {
+		return io.ErrUnexpectedEOF
+	})
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("expected the error of the session, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package tokencmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/openshift/oc/pkg/helpers/keychain"
+)
+
+// KeychainService is the service of the tokens stored in the keychain of the operating system, by server.
+const KeychainService = "openshift-oc"
+
+// ExecCredentialArgs are the arguments of oc for the exec credential plugin that provides the tokens stored in
+// the keychain.
+var ExecCredentialArgs = []string{"login", "exec-credential"}
+
+// UsesKeychain returns true if the exec credential plugin exec provides the tokens stored in the keychain.
+func UsesKeychain(exec *clientcmdapi.ExecConfig) bool {
+	return exec != nil && reflect.DeepEqual(exec.Args, ExecCredentialArgs)
+}
+
+// LoadTokens returns the tokens of server stored in the keychain, or nil if there are none.
+func LoadTokens(k keychain.Keychain, server string) (*OAuthTokens, error) {
+	data, err := k.Get(server)
+	if errors.Is(err, keychain.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	tokens := &OAuthTokens{}
+	if err := json.Unmarshal([]byte(data), tokens); err != nil {
+		return nil, fmt.Errorf("unable to decode the tokens of %s stored in the keychain: %v", server, err)
+	}
+	return tokens, nil
+}
+
+// StoreTokens stores the tokens of server in the keychain.
+func StoreTokens(k keychain.Keychain, server string, tokens *OAuthTokens) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	return k.Set(server, string(data))
+}
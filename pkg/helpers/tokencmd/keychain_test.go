@@ -0,0 +1,72 @@
+package tokencmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/openshift/oc/pkg/helpers/keychain"
+)
+
+type fakeKeychain map[string]string
+
+func (k fakeKeychain) Get(account string) (string, error) {
+	secret, ok := k[account]
+	if !ok {
+		return "", keychain.ErrNotFound
+	}
+	return secret, nil
+}
+
+func (k fakeKeychain) Set(account, secret string) error {
+	k[account] = secret
+	return nil
+}
+
+func (k fakeKeychain) Delete(account string) error {
+	delete(k, account)
+	return nil
+}
+
+func TestStoreTokens(t *testing.T) {
+	k := fakeKeychain{}
+	tokens, err := LoadTokens(k, "https://api.example.com:6443")
+	if err != nil || tokens != nil {
+		t.Fatalf("expected no tokens, got %#v, %v", tokens, err)
+	}
+
+	stored := &OAuthTokens{AccessToken: "sha256~access", RefreshToken: "refresh", Expiry: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	if err := StoreTokens(k, "https://api.example.com:6443", stored); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err = LoadTokens(k, "https://api.example.com:6443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, stored) {
+		t.Errorf("expected %#v, got %#v", stored, tokens)
+	}
+
+	k["https://other.example.com:6443"] = "not json"
+	if _, err := LoadTokens(k, "https://other.example.com:6443"); err == nil {
+		t.Errorf("expected an error for invalid tokens")
+	}
+}
+
+func TestUsesKeychain(t *testing.T) {
+	tests := []struct {
+		exec     *clientcmdapi.ExecConfig
+		expected bool
+	}{
+		{exec: nil},
+		{exec: &clientcmdapi.ExecConfig{Command: "/usr/bin/oc", Args: []string{"login", "exec-credential"}}, expected: true},
+		{exec: &clientcmdapi.ExecConfig{Command: "aws", Args: []string{"eks", "get-token"}}},
+	}
+	for _, tt := range tests {
+		if got := UsesKeychain(tt.exec); got != tt.expected {
+			t.Errorf("UsesKeychain(%#v) = %t, expected %t", tt.exec, got, tt.expected)
+		}
+	}
+}
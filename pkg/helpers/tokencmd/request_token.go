@@ -122,28 +122,10 @@ func (o *RequestTokenOptions) SetDefaultOsinConfig() error {
 		return fmt.Errorf("osin config is already set to: %#v", *o.OsinConfig)
 	}
 
-	// get the OAuth metadata directly from the api server
-	// we only want to use the ca data from our config
-	rt, err := restclient.TransportFor(o.ClientConfig)
-	if err != nil {
-		return err
-	}
-
-	requestURL := strings.TrimRight(o.ClientConfig.Host, "/") + oauthMetadataEndpoint
-	resp, err := request(rt, requestURL, nil)
+	metadata, err := getOAuthMetadata(o.ClientConfig)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("couldn't get %v: unexpected response status %v", requestURL, resp.StatusCode)
-	}
-
-	metadata := &oauthdiscovery.OauthAuthorizationServerMetadata{}
-	if err := json.NewDecoder(resp.Body).Decode(metadata); err != nil {
-		return err
-	}
 
 	// use the metadata to build the osin config
 	config := &osincli.ClientConfig{
@@ -163,6 +145,40 @@ func (o *RequestTokenOptions) SetDefaultOsinConfig() error {
 	return nil
 }
 
+// oauthMetadata is the OAuth 2.0 Authorization Server Metadata, with the device authorization endpoint that is only
+// set by the servers that support the device authorization grant (RFC8628).
+type oauthMetadata struct {
+	oauthdiscovery.OauthAuthorizationServerMetadata
+
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint,omitempty"`
+}
+
+// getOAuthMetadata gets the OAuth metadata directly from the api server.
+func getOAuthMetadata(clientConfig *restclient.Config) (*oauthMetadata, error) {
+	// we only want to use the ca data from our config
+	rt, err := restclient.TransportFor(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	requestURL := strings.TrimRight(clientConfig.Host, "/") + oauthMetadataEndpoint
+	resp, err := request(rt, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("couldn't get %v: unexpected response status %v", requestURL, resp.StatusCode)
+	}
+
+	metadata := &oauthMetadata{}
+	if err := json.NewDecoder(resp.Body).Decode(metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
 // RequestToken locates an openshift oauth server and attempts to authenticate.
 // It returns the access token if it gets one, or an error if it does not.
 // It should only be invoked once on a given RequestTokenOptions instance.
@@ -365,18 +381,27 @@ func oauthCodeFlow(client *osincli.Client, authorizeRequest *osincli.AuthorizeRe
 	}
 
 	// any errors after this are fatal because we are committed to an OAuth flow now
+	accessData, err := exchangeCode(client, authorizeRequest, req)
+	if err != nil {
+		return "", err
+	}
+
+	return accessData.AccessToken, nil
+}
+
+// exchangeCode exchanges the code of the authorization response req for an access token.
+func exchangeCode(client *osincli.Client, authorizeRequest *osincli.AuthorizeRequest, req *http.Request) (*osincli.AccessData, error) {
 	authorizeData, err := authorizeRequest.HandleRequest(req)
 	if err != nil {
-		return "", osinToOAuthError(err)
+		return nil, osinToOAuthError(err)
 	}
 
 	accessRequest := client.NewAccessRequest(osincli.AUTHORIZATION_CODE, authorizeData)
 	accessData, err := accessRequest.GetToken()
 	if err != nil {
-		return "", osinToOAuthError(err)
+		return nil, osinToOAuthError(err)
 	}
-
-	return accessData.AccessToken, nil
+	return accessData, nil
 }
 
 // osinToOAuthError creates a better error message for osincli.Error
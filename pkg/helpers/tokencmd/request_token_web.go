@@ -0,0 +1,355 @@
+package tokencmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/RangelReale/osincli"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// openShiftCLIBrowserClientID is the name of the CLI OAuth client that redirects to a callback on the loopback
+	// interface, for the logins in a browser
+	openShiftCLIBrowserClientID = "openshift-cli-client"
+
+	// deviceCodeGrantType is the grant type of the device authorization grant, see RFC8628
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// defaultDeviceCodeInterval is the interval between two token requests of the device authorization grant when
+	// the server does not set it
+	defaultDeviceCodeInterval = 5 * time.Second
+)
+
+// WebLoginOptions holds the options to request a token with a login in a browser.
+type WebLoginOptions struct {
+	ClientConfig *restclient.Config
+	// OpenBrowser opens the URL to log in with a browser. When nil, the URL is printed to Out, or the device
+	// authorization grant is used if the server supports it.
+	OpenBrowser func(url string) error
+	// CallbackPort is the port of the callback listener on the loopback interface, or 0 for a random port.
+	CallbackPort int
+	// Timeout is how long to wait for the login to complete.
+	Timeout time.Duration
+
+	Out io.Writer
+}
+
+// OAuthTokens holds the tokens returned by the token endpoint of the OAuth server.
+type OAuthTokens struct {
+	AccessToken string `json:"accessToken"`
+	// RefreshToken is only set if the server issued one.
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// Expiry is zero if the server did not tell when the access token expires.
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+func newOAuthTokens(accessToken, refreshToken string, expiresIn int64) *OAuthTokens {
+	tokens := &OAuthTokens{AccessToken: accessToken, RefreshToken: refreshToken}
+	if expiresIn > 0 {
+		tokens.Expiry = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return tokens
+}
+
+// RequestToken requests a token with the OAuth authorization code flow, where the user logs in with a browser and
+// is redirected to a callback listening on the loopback interface. Without a browser, it uses the device
+// authorization grant if the server supports it. It returns the access token if it gets one or an error if it
+// does not.
+func (o *WebLoginOptions) RequestToken() (string, error) {
+	tokens, err := o.RequestTokens()
+	if err != nil {
+		return "", err
+	}
+	return tokens.AccessToken, nil
+}
+
+// RequestTokens is RequestToken, returning the refresh token and the expiry of the access token as well.
+func (o *WebLoginOptions) RequestTokens() (*OAuthTokens, error) {
+	metadata, rt, err := o.oauthServer()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+	defer cancel()
+
+	if o.OpenBrowser == nil && len(metadata.DeviceAuthorizationEndpoint) > 0 {
+		return o.deviceCodeFlow(ctx, rt, metadata)
+	}
+	return o.localCallbackFlow(ctx, rt, metadata)
+}
+
+// RefreshTokens exchanges a refresh token for a new access token, without any interaction with the user.
+func (o *WebLoginOptions) RefreshTokens(refreshToken string) (*OAuthTokens, error) {
+	metadata, rt, err := o.oauthServer()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+	defer cancel()
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {openShiftCLIBrowserClientID},
+	}
+	response := &tokenResponse{}
+	if _, err := postForm(ctx, rt, metadata.TokenEndpoint, form, response, response); err != nil {
+		return nil, err
+	}
+	if len(response.Error) > 0 {
+		return nil, createOAuthError(response.Error, response.ErrorDescription)
+	}
+	if len(response.AccessToken) == 0 {
+		return nil, fmt.Errorf("the token endpoint %v did not return an access token", metadata.TokenEndpoint)
+	}
+	if len(response.RefreshToken) == 0 {
+		// the server may keep the refresh token valid instead of rotating it
+		response.RefreshToken = refreshToken
+	}
+	return newOAuthTokens(response.AccessToken, response.RefreshToken, response.ExpiresIn), nil
+}
+
+// oauthServer returns the metadata of the OAuth server and the transport to talk to it.
+func (o *WebLoginOptions) oauthServer() (*oauthMetadata, http.RoundTripper, error) {
+	metadata, err := getOAuthMetadata(o.ClientConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the oauth server may not be the api server, see RequestTokenOptions.RequestToken
+	rt, err := transportWithSystemRoots(metadata.Issuer, o.ClientConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return metadata, rt, nil
+}
+
+// localCallbackFlow performs the OAuth code flow with a redirect to a callback listening on the loopback interface.
+func (o *WebLoginOptions) localCallbackFlow(ctx context.Context, rt http.RoundTripper, metadata *oauthMetadata) (*OAuthTokens, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", o.CallbackPort))
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen for the login callback: %v", err)
+	}
+	defer listener.Close()
+	redirectURL := fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	config := &osincli.ClientConfig{
+		ClientId:     openShiftCLIBrowserClientID,
+		AuthorizeUrl: metadata.AuthorizationEndpoint,
+		TokenUrl:     metadata.TokenEndpoint,
+		RedirectUrl:  redirectURL,
+	}
+	if sets.NewString(metadata.CodeChallengeMethodsSupported...).Has(pkce_s256) {
+		if err := osincli.PopulatePKCE(config); err != nil {
+			return nil, err
+		}
+	}
+	client, err := osincli.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	client.Transport = rt
+	authorizeRequest := client.NewAuthorizeRequest(osincli.CODE)
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		tokens *OAuthTokens
+		err    error
+	}
+	results := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, req *http.Request) {
+		var r result
+		if req.URL.Query().Get("state") != state {
+			r.err = fmt.Errorf("the login callback was called with an unexpected state")
+		} else {
+			r.tokens, r.err = o.exchangeCallbackCode(client, authorizeRequest, req)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if r.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Login failed: %v\n", r.err)
+		} else {
+			fmt.Fprintln(w, "Login successful. You can close this window and return to the terminal.")
+		}
+
+		select {
+		case results <- r:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authorizeURL := authorizeRequest.GetAuthorizeUrlWithParams(state).String()
+	if o.OpenBrowser != nil {
+		if err := o.OpenBrowser(authorizeURL); err != nil {
+			klog.V(4).Infof("unable to open a browser: %v", err)
+			fmt.Fprintf(o.Out, "Unable to open a browser, open the following URL to log in:\n\n  %s\n\n", authorizeURL)
+		} else {
+			fmt.Fprintf(o.Out, "Opening the login page in your browser. If it does not open, visit:\n\n  %s\n\n", authorizeURL)
+		}
+	} else {
+		fmt.Fprintf(o.Out, "Open the following URL in a browser to log in:\n\n  %s\n\n", authorizeURL)
+		fmt.Fprintf(o.Out, "The browser is redirected to %s after the login; forward this port when the browser runs on another host.\n\n", redirectURL)
+	}
+
+	select {
+	case r := <-results:
+		return r.tokens, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out after %v waiting for the login in the browser", o.Timeout)
+	}
+}
+
+// exchangeCallbackCode exchanges the code the callback req was called with for the tokens.
+func (o *WebLoginOptions) exchangeCallbackCode(client *osincli.Client, authorizeRequest *osincli.AuthorizeRequest, req *http.Request) (*OAuthTokens, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+	if oauthErr := oauthErrFromValues(req.Form); oauthErr != nil {
+		return nil, oauthErr
+	}
+	if len(req.Form.Get("code")) == 0 {
+		return nil, fmt.Errorf("the login callback was called without an authorization code")
+	}
+	accessData, err := exchangeCode(client, authorizeRequest, req)
+	if err != nil {
+		return nil, err
+	}
+	var expiresIn int64
+	if accessData.Expiration != nil {
+		expiresIn = int64(*accessData.Expiration)
+	}
+	return newOAuthTokens(accessData.AccessToken, accessData.RefreshToken, expiresIn), nil
+}
+
+// deviceAuthorization is the response of the device authorization endpoint, see RFC8628 section 3.2.
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	Interval                int    `json:"interval"`
+}
+
+// tokenResponse is the response of the token endpoint, or its error, see RFC6749 section 5.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// deviceCodeFlow performs the device authorization grant: the user logs in with a browser on any device, with
+// the code printed to Out, while the token endpoint is polled until the login is complete.
+func (o *WebLoginOptions) deviceCodeFlow(ctx context.Context, rt http.RoundTripper, metadata *oauthMetadata) (*OAuthTokens, error) {
+	authorization := &deviceAuthorization{}
+	errResponse := &tokenResponse{}
+	status, err := postForm(ctx, rt, metadata.DeviceAuthorizationEndpoint, url.Values{"client_id": {openShiftCLIBrowserClientID}}, authorization, errResponse)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		if len(errResponse.Error) > 0 {
+			return nil, createOAuthError(errResponse.Error, errResponse.ErrorDescription)
+		}
+		return nil, fmt.Errorf("couldn't get a device code from %v: unexpected response status %v", metadata.DeviceAuthorizationEndpoint, status)
+	}
+
+	if len(authorization.VerificationURIComplete) > 0 {
+		fmt.Fprintf(o.Out, "Open the following URL in a browser on any device to log in:\n\n  %s\n\nand confirm the code %s\n\n", authorization.VerificationURIComplete, authorization.UserCode)
+	} else {
+		fmt.Fprintf(o.Out, "Open the following URL in a browser on any device to log in:\n\n  %s\n\nand enter the code %s\n\n", authorization.VerificationURI, authorization.UserCode)
+	}
+
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceCodeInterval
+	}
+	form := url.Values{
+		"grant_type":  {deviceCodeGrantType},
+		"device_code": {authorization.DeviceCode},
+		"client_id":   {openShiftCLIBrowserClientID},
+	}
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out after %v waiting for the login with the device code", o.Timeout)
+		}
+
+		response := &tokenResponse{}
+		if _, err := postForm(ctx, rt, metadata.TokenEndpoint, form, response, response); err != nil {
+			return nil, err
+		}
+		switch response.Error {
+		case "":
+			if len(response.AccessToken) == 0 {
+				return nil, fmt.Errorf("the token endpoint %v did not return an access token", metadata.TokenEndpoint)
+			}
+			return newOAuthTokens(response.AccessToken, response.RefreshToken, response.ExpiresIn), nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += defaultDeviceCodeInterval
+		default:
+			return nil, createOAuthError(response.Error, response.ErrorDescription)
+		}
+	}
+}
+
+// postForm posts form to endpoint and decodes the JSON response into ok when the response status is 200, and into
+// failed otherwise. It returns the status of the response.
+func postForm(ctx context.Context, rt http.RoundTripper, endpoint string, form url.Values, ok, failed interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	into := ok
+	if resp.StatusCode != http.StatusOK {
+		into = failed
+	}
+	if err := json.NewDecoder(resp.Body).Decode(into); err != nil && resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("unable to decode the response of %v: %v", endpoint, err)
+	}
+	return resp.StatusCode, nil
+}
+
+// randomState returns a random value for the state parameter of the authorization request.
+func randomState() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
@@ -0,0 +1,214 @@
+package tokencmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	restclient "k8s.io/client-go/rest"
+
+	"github.com/openshift/library-go/pkg/oauth/oauthdiscovery"
+)
+
+// testOAuthServer returns a TLS server that serves the OAuth metadata, authorizes any request with the code "code"
+// and exchanges it for the token "web-token" and the refresh token "refresh", which is exchanged for the token
+// "refreshed-token". The device authorization endpoint is only advertised when
+// deviceTokenResponses is not empty; the token endpoint returns these responses in turn for the device code.
+func testOAuthServer(t *testing.T, deviceTokenResponses ...tokenResponse) *httptest.Server {
+	var s *httptest.Server
+	s = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/":
+			// the probe of transportWithSystemRoots
+		case oauthMetadataEndpoint:
+			metadata := oauthMetadata{
+				OauthAuthorizationServerMetadata: oauthdiscovery.OauthAuthorizationServerMetadata{
+					Issuer:                        s.URL,
+					AuthorizationEndpoint:         s.URL + "/oauth/authorize",
+					TokenEndpoint:                 s.URL + "/oauth/token",
+					CodeChallengeMethodsSupported: []string{pkce_s256},
+				},
+			}
+			if len(deviceTokenResponses) > 0 {
+				metadata.DeviceAuthorizationEndpoint = s.URL + "/oauth/device"
+			}
+			json.NewEncoder(w).Encode(metadata)
+		case "/oauth/authorize":
+			query := req.URL.Query()
+			if query.Get("client_id") != openShiftCLIBrowserClientID || len(query.Get("code_challenge")) == 0 {
+				t.Errorf("unexpected authorize request: %s", req.URL)
+			}
+			redirect, err := url.Parse(query.Get("redirect_uri"))
+			if err != nil {
+				t.Errorf("unexpected redirect URI: %v", err)
+				return
+			}
+			redirect.RawQuery = url.Values{"code": {"code"}, "state": {query.Get("state")}}.Encode()
+			http.Redirect(w, req, redirect.String(), http.StatusFound)
+		case "/oauth/device":
+			json.NewEncoder(w).Encode(deviceAuthorization{DeviceCode: "device", UserCode: "ABCD-EFGH", VerificationURI: s.URL + "/oauth/device/verify", Interval: 1})
+		case "/oauth/token":
+			req.ParseForm()
+			if req.Form.Get("grant_type") == deviceCodeGrantType {
+				response := deviceTokenResponses[0]
+				deviceTokenResponses = deviceTokenResponses[1:]
+				if len(response.Error) > 0 {
+					w.WriteHeader(http.StatusBadRequest)
+				}
+				json.NewEncoder(w).Encode(response)
+				return
+			}
+			if req.Form.Get("grant_type") == "refresh_token" {
+				if req.Form.Get("refresh_token") != "refresh" {
+					w.WriteHeader(http.StatusBadRequest)
+					json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant", ErrorDescription: "the refresh token is invalid"})
+					return
+				}
+				json.NewEncoder(w).Encode(tokenResponse{AccessToken: "refreshed-token", ExpiresIn: 3600})
+				return
+			}
+			if req.Form.Get("code") != "code" || len(req.Form.Get("code_verifier")) == 0 {
+				t.Errorf("unexpected token request: %v", req.Form)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "web-token", "token_type": "Bearer", "refresh_token": "refresh", "expires_in": 86400})
+		default:
+			t.Errorf("unexpected request: %s", req.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return s
+}
+
+func testClientConfig(s *httptest.Server) *restclient.Config {
+	return &restclient.Config{
+		Host: s.URL,
+		TLSClientConfig: restclient.TLSClientConfig{
+			CAData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw}),
+		},
+	}
+}
+
+func TestWebLoginLocalCallback(t *testing.T) {
+	s := testOAuthServer(t)
+	defer s.Close()
+
+	// the browser follows the redirect of the oauth server to the callback
+	browser := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	out := &bytes.Buffer{}
+	o := &WebLoginOptions{
+		ClientConfig: testClientConfig(s),
+		OpenBrowser: func(authorizeURL string) error {
+			go func() {
+				resp, err := browser.Get(authorizeURL)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					t.Errorf("unexpected callback status: %d", resp.StatusCode)
+				}
+			}()
+			return nil
+		},
+		Timeout: 10 * time.Second,
+		Out:     out,
+	}
+	tokens, err := o.RequestTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.AccessToken != "web-token" || tokens.RefreshToken != "refresh" {
+		t.Errorf("expected tokens web-token and refresh, got %#v", tokens)
+	}
+	if expiry := time.Until(tokens.Expiry); expiry < 23*time.Hour || expiry > 24*time.Hour {
+		t.Errorf("unexpected expiry: %v", tokens.Expiry)
+	}
+	if !strings.Contains(out.String(), "Opening the login page in your browser") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
+
+func TestWebLoginTimeout(t *testing.T) {
+	s := testOAuthServer(t)
+	defer s.Close()
+
+	out := &bytes.Buffer{}
+	o := &WebLoginOptions{ClientConfig: testClientConfig(s), Timeout: 100 * time.Millisecond, Out: out}
+	_, err := o.RequestToken()
+	if err == nil || err.Error() != "timed out after 100ms waiting for the login in the browser" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), s.URL+"/oauth/authorize?") {
+		t.Errorf("expected the authorize URL to be printed, got: %s", out.String())
+	}
+}
+
+func TestWebLoginDeviceCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		responses     []tokenResponse
+		expectedToken string
+		expectedError string
+	}{
+		{
+			name:          "approved",
+			responses:     []tokenResponse{{Error: "authorization_pending"}, {AccessToken: "device-token"}},
+			expectedToken: "device-token",
+		},
+		{
+			name:          "denied",
+			responses:     []tokenResponse{{Error: "access_denied", ErrorDescription: "the user denied the request"}},
+			expectedError: "access_denied the user denied the request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testOAuthServer(t, tt.responses...)
+			defer s.Close()
+
+			out := &bytes.Buffer{}
+			o := &WebLoginOptions{ClientConfig: testClientConfig(s), Timeout: 10 * time.Second, Out: out}
+			token, err := o.RequestToken()
+			errStr := ""
+			if err != nil {
+				errStr = err.Error()
+			}
+			if errStr != tt.expectedError {
+				t.Errorf("expected error %q, got %q", tt.expectedError, errStr)
+			}
+			if token != tt.expectedToken {
+				t.Errorf("expected token %q, got %q", tt.expectedToken, token)
+			}
+			if !strings.Contains(out.String(), "and enter the code ABCD-EFGH") {
+				t.Errorf("unexpected output: %s", out.String())
+			}
+		})
+	}
+}
+
+func TestWebLoginRefreshTokens(t *testing.T) {
+	s := testOAuthServer(t)
+	defer s.Close()
+
+	o := &WebLoginOptions{ClientConfig: testClientConfig(s), Timeout: 10 * time.Second, Out: &bytes.Buffer{}}
+	tokens, err := o.RefreshTokens("refresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the refresh token is kept when the server does not rotate it
+	if tokens.AccessToken != "refreshed-token" || tokens.RefreshToken != "refresh" || tokens.Expiry.IsZero() {
+		t.Errorf("unexpected tokens: %#v", tokens)
+	}
+
+	if _, err := o.RefreshTokens("revoked"); err == nil || err.Error() != "invalid_grant the refresh token is invalid" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package tokencmd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+const sha256Prefix = "sha256~"
+
+// AccessTokenName returns the name of the oauthaccesstokens and useroauthaccesstokens object of token: the sha256
+// hash of the token prefixed with "sha256~", or the token itself if it is not a sha256 token.
+func AccessTokenName(token string) string {
+	if !strings.HasPrefix(token, sha256Prefix) {
+		return token
+	}
+	h := sha256.Sum256([]byte(strings.TrimPrefix(token, sha256Prefix)))
+	return sha256Prefix + base64.RawURLEncoding.EncodeToString(h[0:])
+}